@@ -5,26 +5,28 @@ import (
 	"os"
 
 	"github.com/joeblew999/goup-util/pkg/adb"
+	"github.com/joeblew999/goup-util/pkg/history"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 func newADBClient() (*adb.Client, error) {
 	client := adb.New()
 	if !client.Available() {
-		return nil, fmt.Errorf("adb not found at %s\nInstall with: goup-util install platform-tools", client.ADBPath())
+		return nil, fmt.Errorf(i18n.T("adb not found at %s\nInstall with: goup-util install platform-tools"), client.ADBPath())
 	}
 	return client, nil
 }
 
 var androidCmd = &cobra.Command{
 	Use:   "android",
-	Short: "Android device and emulator management",
-	Long:  `Manage Android devices, emulators, and apps using goup-util's managed SDK.`,
+	Short: i18n.T("Android device and emulator management"),
+	Long:  i18n.T(`Manage Android devices, emulators, and apps using goup-util's managed SDK.`),
 }
 
 var androidDevicesCmd = &cobra.Command{
 	Use:   "devices",
-	Short: "List connected Android devices",
+	Short: i18n.T("List connected Android devices"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newADBClient()
 		if err != nil {
@@ -52,7 +54,7 @@ var androidDevicesCmd = &cobra.Command{
 
 var androidInstallCmd = &cobra.Command{
 	Use:   "install [apk-path]",
-	Short: "Install an APK on the connected device",
+	Short: i18n.T("Install an APK on the connected device"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newADBClient()
@@ -61,11 +63,11 @@ var androidInstallCmd = &cobra.Command{
 		}
 		apkPath := args[0]
 		if _, err := os.Stat(apkPath); os.IsNotExist(err) {
-			return fmt.Errorf("APK not found: %s", apkPath)
+			return fmt.Errorf(i18n.T("APK not found: %s"), apkPath)
 		}
 		fmt.Printf("Installing %s...\n", apkPath)
 		if err := client.Install(apkPath); err != nil {
-			return fmt.Errorf("install failed: %w", err)
+			return fmt.Errorf(i18n.T("install failed: %w"), err)
 		}
 		fmt.Println("✓ Installed successfully")
 		return nil
@@ -74,7 +76,7 @@ var androidInstallCmd = &cobra.Command{
 
 var androidUninstallCmd = &cobra.Command{
 	Use:   "uninstall [package-name]",
-	Short: "Uninstall an app from the connected device",
+	Short: i18n.T("Uninstall an app from the connected device"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newADBClient()
@@ -83,7 +85,7 @@ var androidUninstallCmd = &cobra.Command{
 		}
 		fmt.Printf("Uninstalling %s...\n", args[0])
 		if err := client.Uninstall(args[0]); err != nil {
-			return fmt.Errorf("uninstall failed: %w", err)
+			return fmt.Errorf(i18n.T("uninstall failed: %w"), err)
 		}
 		fmt.Println("✓ Uninstalled")
 		return nil
@@ -92,7 +94,7 @@ var androidUninstallCmd = &cobra.Command{
 
 var androidLaunchCmd = &cobra.Command{
 	Use:   "launch [package-name]",
-	Short: "Launch an app on the connected device",
+	Short: i18n.T("Launch an app on the connected device"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newADBClient()
@@ -106,7 +108,7 @@ var androidLaunchCmd = &cobra.Command{
 
 var androidScreenshotCmd = &cobra.Command{
 	Use:   "screenshot [output-file]",
-	Short: "Capture a screenshot from the connected device",
+	Short: i18n.T("Capture a screenshot from the connected device"),
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newADBClient()
@@ -118,8 +120,11 @@ var androidScreenshotCmd = &cobra.Command{
 			output = args[0]
 		}
 		fmt.Printf("Capturing screenshot...\n")
-		if err := client.Screenshot(output); err != nil {
-			return fmt.Errorf("screenshot failed: %w", err)
+		span := history.Start(history.Screenshot, output, "android")
+		err = client.Screenshot(output)
+		span.End(err, output)
+		if err != nil {
+			return fmt.Errorf(i18n.T("screenshot failed: %w"), err)
 		}
 		fmt.Printf("✓ Screenshot saved to %s\n", output)
 		return nil
@@ -128,9 +133,9 @@ var androidScreenshotCmd = &cobra.Command{
 
 var androidLogsCmd = &cobra.Command{
 	Use:   "logs",
-	Short: "Stream Android logs for Gio apps (Ctrl+C to stop)",
-	Long: `Stream filtered logcat output showing only Gio/Go-related log messages.
-Use --all to show all device logs instead of just Gio-filtered ones.`,
+	Short: i18n.T("Stream Android logs for Gio apps (Ctrl+C to stop)"),
+	Long: i18n.T(`Stream filtered logcat output showing only Gio/Go-related log messages.
+Use --all to show all device logs instead of just Gio-filtered ones.`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newADBClient()
 		if err != nil {
@@ -148,7 +153,7 @@ Use --all to show all device logs instead of just Gio-filtered ones.`,
 
 var androidWebviewCmd = &cobra.Command{
 	Use:   "webview",
-	Short: "Show WebView version on the connected device",
+	Short: i18n.T("Show WebView version on the connected device"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newADBClient()
 		if err != nil {
@@ -156,7 +161,7 @@ var androidWebviewCmd = &cobra.Command{
 		}
 		version, err := client.WebViewVersion()
 		if err != nil {
-			return fmt.Errorf("failed to get webview version: %w", err)
+			return fmt.Errorf(i18n.T("failed to get webview version: %w"), err)
 		}
 		fmt.Println(version)
 		return nil
@@ -167,16 +172,16 @@ var androidWebviewCmd = &cobra.Command{
 
 var androidEmulatorCmd = &cobra.Command{
 	Use:   "emulator",
-	Short: "Manage Android emulators",
+	Short: i18n.T("Manage Android emulators"),
 }
 
 var androidEmulatorListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List available Android emulators (AVDs)",
+	Short: i18n.T("List available Android emulators (AVDs)"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client := adb.New()
 		if !client.EmulatorAvailable() {
-			return fmt.Errorf("emulator not found at %s\nInstall with: goup-util install emulator", client.EmulatorPath())
+			return fmt.Errorf(i18n.T("emulator not found at %s\nInstall with: goup-util install emulator"), client.EmulatorPath())
 		}
 		avds, err := client.EmulatorList()
 		if err != nil {
@@ -197,12 +202,12 @@ var androidEmulatorListCmd = &cobra.Command{
 
 var androidEmulatorStartCmd = &cobra.Command{
 	Use:   "start [avd-name]",
-	Short: "Start an Android emulator",
+	Short: i18n.T("Start an Android emulator"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client := adb.New()
 		if !client.EmulatorAvailable() {
-			return fmt.Errorf("emulator not found at %s\nInstall with: goup-util install emulator", client.EmulatorPath())
+			return fmt.Errorf(i18n.T("emulator not found at %s\nInstall with: goup-util install emulator"), client.EmulatorPath())
 		}
 		avdName := args[0]
 		fmt.Printf("Starting emulator %s...\n", avdName)
@@ -213,7 +218,7 @@ var androidEmulatorStartCmd = &cobra.Command{
 		fmt.Printf("Emulator started (PID: %d)\n", pid)
 		fmt.Println("Waiting for device to come online...")
 		if err := client.WaitForDevice(); err != nil {
-			return fmt.Errorf("device did not come online: %w", err)
+			return fmt.Errorf(i18n.T("device did not come online: %w"), err)
 		}
 		fmt.Println("✓ Emulator is ready")
 		return nil
@@ -222,7 +227,7 @@ var androidEmulatorStartCmd = &cobra.Command{
 
 func init() {
 	// Logs flags
-	androidLogsCmd.Flags().Bool("all", false, "Show all device logs (not just Gio-filtered)")
+	androidLogsCmd.Flags().Bool("all", false, i18n.T("Show all device logs (not just Gio-filtered)"))
 
 	// Emulator subcommands
 	androidEmulatorCmd.AddCommand(androidEmulatorListCmd)
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joeblew999/goup-util/pkg/assets"
+	"github.com/joeblew999/goup-util/pkg/hooks"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var assetsCmd = &cobra.Command{
+	Use:   "assets",
+	Short: i18n.T("List and extract static assets embedded into an app"),
+	Long: `Embed static files (offline pages, fonts, images) into an app instead of
+shipping them next to the executable. Declare them in goup.json:
+
+  {
+    "assets": {
+      "include": ["offline.html", "fonts", "images/*.png"]
+    }
+  }
+
+Patterns are relative to the app directory and follow go:embed rules.
+'goup-util build' writes ` + assets.FileName + ` into the app, declaring an
+embed.FS named ` + assets.DefaultVar + ` (or "var") that the app reads with
+` + assets.DefaultVar + `.ReadFile("offline.html").`,
+}
+
+var assetsListCmd = &cobra.Command{
+	Use:   "list [app-directory]",
+	Short: i18n.T("Show the files that will be embedded"),
+	Example: `  goup-util assets list examples/gio-plugin-webviewer
+  goup-util assets list --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appDir := appDirArg(args)
+		c, err := loadAssets(appDir)
+		if err != nil {
+			return err
+		}
+		files, err := c.Files(appDir)
+		if err != nil {
+			return err
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(files)
+		}
+		var total int64
+		for _, f := range files {
+			fmt.Printf("  %-50s %s\n", f.Path, formatBytes(f.Size))
+			total += f.Size
+		}
+		fmt.Printf("📦 %d files, %s in %s\n", len(files), formatBytes(total), c.Var)
+		return nil
+	},
+}
+
+var assetsExtractCmd = &cobra.Command{
+	Use:   "extract <app-directory> <output-directory>",
+	Short: i18n.T("Copy the embedded files to a directory"),
+	Long: i18n.T(`Copy the files an app embeds to a directory, with the paths the app sees
+in its embed.FS. Useful to check what ships, or to serve the same files
+during development.`),
+	Example: `  goup-util assets extract ./myshell /tmp/assets`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadAssets(args[0])
+		if err != nil {
+			return err
+		}
+		files, err := c.Extract(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Extracted %d files to %s\n", len(files), args[1])
+		return nil
+	},
+}
+
+var assetsGenerateCmd = &cobra.Command{
+	Use:   "generate [app-directory]",
+	Short: "Write " + assets.FileName + " without building",
+	Long: i18n.T(`Write the generated go:embed file, as 'goup-util build' does, so 'go build'
+and editors see the assets variable.`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appDir := appDirArg(args)
+		if _, err := loadAssets(appDir); err != nil {
+			return err
+		}
+		return generateAssets(appDir)
+	},
+}
+
+func appDirArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return "."
+}
+
+func loadAssets(appDir string) (*assets.Config, error) {
+	c, err := assets.Load(appDir)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf(i18n.T("no assets declared: add an \"assets\" section to %s"), hooks.ConfigFileName)
+	}
+	return c, nil
+}
+
+// generateAssets writes the go:embed file for the assets in goup.json,
+// if it declares any.
+func generateAssets(appDir string) error {
+	c, err := assets.Load(appDir)
+	if err != nil || c == nil {
+		return err
+	}
+	written, err := c.Generate(appDir)
+	if err != nil {
+		return fmt.Errorf(i18n.T("failed to embed assets: %w"), err)
+	}
+	if written {
+		fmt.Printf("📦 Wrote %s\n", filepath.Join(appDir, assets.FileName))
+	}
+	return nil
+}
+
+func init() {
+	assetsListCmd.Flags().Bool("json", false, i18n.T("Print the files as JSON"))
+
+	assetsCmd.AddCommand(assetsListCmd)
+	assetsCmd.AddCommand(assetsExtractCmd)
+	assetsCmd.AddCommand(assetsGenerateCmd)
+	assetsCmd.GroupID = "build"
+	rootCmd.AddCommand(assetsCmd)
+}
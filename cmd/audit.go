@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/capabilities"
+	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/giocompat"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/joeblew999/goup-util/pkg/secheaders"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: i18n.T("Audit an app for problems the build does not catch"),
+}
+
+var auditCapabilitiesCmd = &cobra.Command{
+	Use:   "capabilities <app-directory>",
+	Short: i18n.T("Check bundles for the permissions and entitlements the app needs"),
+	Long: i18n.T(`Find what the app uses that the OS gates behind a permission (webviews,
+camera, microphone, local network, file pickers), from its gio-plugins and
+gioui.org/app/permission imports and its app.json permissions, and check
+the built bundles for the entries each one needs:
+
+  - app.json permission reasons (they drive the generated entries)
+  - macOS and iOS Info.plist usage descriptions
+  - macOS entitlements (hardened runtime and App Store sandbox)
+  - Android manifest permissions
+  - MSIX device capabilities and the bundled WebView2 runtime
+
+Bundles are looked for in .dist (goup-util bundle) and .bin (goup-util
+build); platforms without one are reported as not built. The exit status
+is non-zero when a required entry is missing.`),
+	Example: `  goup-util audit capabilities examples/gio-plugin-webviewer
+  goup-util audit capabilities . --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		proj, err := project.NewGioProject(args[0])
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to create project: %w"), err)
+		}
+
+		report, err := capabilities.Audit(proj.RootDir, proj.Name)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			printCapabilityReport(report)
+		}
+		if !report.OK() {
+			return errors.New(i18n.T("bundles are missing entries the app needs"))
+		}
+		return nil
+	},
+}
+
+var auditGioCmd = &cobra.Command{
+	Use:   "gio [app-directory]",
+	Short: i18n.T("Check the Gio and gio-plugins versions in go.mod"),
+	Long: i18n.T(`Check the gioui.org and github.com/gioui-plugins/gio-plugins versions an
+app's go.mod selects against the compatibility matrix, and check that
+local replacements (replace gioui.org => ../gio) are checked out at the
+commit go.mod requires. Mismatches otherwise surface as compile errors
+inside the plugins or as webview panics at runtime.
+
+The matrix is fetched from the goup-util repository and cached for a day
+(GOUP_GIO_MATRIX_URL overrides the address); offline, the cached or the
+built-in copy is used. Builds print the same warnings.`),
+	Example: `  goup-util audit gio examples/gio-plugin-webviewer
+  goup-util audit gio . --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		goMod, err := giocompat.FindGoMod(dir)
+		if err != nil {
+			return err
+		}
+		versions, err := giocompat.ReadVersions(goMod)
+		if err != nil {
+			return err
+		}
+		matrix := giocompat.Load(config.GetCacheDir())
+		problems := matrix.Check(versions)
+
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(map[string]any{"versions": versions, "recommended": matrix.Recommended, "problems": problems}); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("📂 %s\n", versions.GoMod)
+			fmt.Printf("  %-40s %s\n", giocompat.GioModule, orNone(versions.Gio))
+			fmt.Printf("  %-40s %s\n", giocompat.PluginsModule, orNone(versions.Plugins))
+			if len(problems) == 0 {
+				fmt.Println("✅ No known incompatibilities")
+			}
+			printGioProblems(problems)
+		}
+		if giocompat.Errors(problems) {
+			return errors.New(i18n.T("incompatible Gio versions"))
+		}
+		return nil
+	},
+}
+
+var auditSecurityCmd = &cobra.Command{
+	Use:   "security <url|app-directory>",
+	Short: i18n.T("Check the security headers of the site a shell wraps"),
+	Long: i18n.T(`Fetch a site, following redirects, and check the security headers of the
+page it lands on: HTTPS and Strict-Transport-Security,
+Content-Security-Policy, framing protection (X-Frame-Options or
+frame-ancestors), X-Content-Type-Options, Referrer-Policy,
+Permissions-Policy, and the Secure, HttpOnly and SameSite attributes of
+its cookies.
+
+Given an app directory, the site is the "url" in its app.json. A site
+without a policy of its own can get one from the shell: set csp.policy in
+app.json and the shell adds it to the site's pages as a <meta> tag. The
+exit status is non-zero when a required check fails.`),
+	Example: `  goup-util audit security https://example.com
+  goup-util audit security examples/gio-plugin-webviewer --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		target, injected := args[0], ""
+		if info, err := os.Stat(target); err == nil && info.IsDir() {
+			cfg, err := appconfig.Load(target)
+			if err != nil {
+				return err
+			}
+			if cfg.URL == "" {
+				return fmt.Errorf(i18n.T("%s has no url in app.json"), target)
+			}
+			target, injected = cfg.URL, cfg.CSP.Policy
+		}
+
+		client := &http.Client{Timeout: 30 * time.Second}
+		report, err := secheaders.Audit(cmd.Context(), client, target)
+		if err != nil {
+			return err
+		}
+		if injected != "" {
+			for i, f := range report.Findings {
+				if f.Check == "Content-Security-Policy" && f.Status == secheaders.Missing {
+					report.Findings[i] = secheaders.CheckCSP(injected)
+					report.Findings[i].Check = "csp.policy (app.json)"
+				}
+			}
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			printSecurityReport(report)
+		}
+		if !report.OK() {
+			return errors.New(i18n.T("the site is missing required security headers"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	auditCapabilitiesCmd.Flags().Bool("json", false, i18n.T("Print the report as JSON"))
+	auditGioCmd.Flags().Bool("json", false, i18n.T("Print the versions and problems as JSON"))
+	auditSecurityCmd.Flags().Bool("json", false, i18n.T("Print the report as JSON"))
+	auditCmd.AddCommand(auditCapabilitiesCmd, auditGioCmd, auditSecurityCmd)
+	auditCmd.GroupID = "build"
+	rootCmd.AddCommand(auditCmd)
+}
+
+func printCapabilityReport(r *capabilities.Report) {
+	if len(r.Capabilities) == 0 {
+		fmt.Printf("ℹ️  %s uses no capabilities that need permissions\n", r.App)
+		return
+	}
+	for _, c := range r.Capabilities {
+		fmt.Printf("🔎 %s (%s)\n", c.Name, c.Source)
+		for _, f := range r.Findings {
+			if f.Capability != c.Name {
+				continue
+			}
+			mark := "✓"
+			switch {
+			case f.Status == capabilities.NotBuilt:
+				mark = "·"
+			case f.Status == capabilities.Missing && f.Optional:
+				mark = "⚠️ "
+			case f.Status == capabilities.Missing:
+				mark = "❌"
+			}
+			fmt.Printf("  %s %-8s %-18s %s", mark, f.Platform, f.Kind, f.Key)
+			if f.Status == capabilities.NotBuilt {
+				fmt.Print(" (not built)")
+			}
+			fmt.Println()
+			if f.Status == capabilities.Missing && f.Fix != "" {
+				fmt.Printf("       → %s\n", f.Fix)
+			}
+		}
+	}
+}
+
+func printSecurityReport(r *secheaders.Report) {
+	fmt.Printf("🔎 %s", r.FinalURL)
+	if r.FinalURL != r.URL {
+		fmt.Printf(" (from %s)", r.URL)
+	}
+	fmt.Printf(" → %d\n", r.Status)
+	for _, f := range r.Findings {
+		mark := "✓"
+		switch {
+		case f.Status == secheaders.OK:
+		case f.Optional:
+			mark = "⚠️ "
+		default:
+			mark = "❌"
+		}
+		fmt.Printf("  %s %-36s", mark, f.Check)
+		if f.Status == secheaders.OK {
+			fmt.Println(f.Value)
+			continue
+		}
+		fmt.Printf("%s: %s\n", f.Status, f.Message)
+		if f.Fix != "" {
+			fmt.Printf("       → %s\n", f.Fix)
+		}
+	}
+}
+
+func printGioProblems(problems []giocompat.Problem) {
+	for _, p := range problems {
+		mark := "⚠️ "
+		if p.Severity == "error" {
+			mark = "❌"
+		}
+		fmt.Printf("%s %s\n", mark, p.Message)
+		if p.Fix != "" {
+			fmt.Printf("   → %s\n", p.Fix)
+		}
+	}
+}
+
+// warnGioCompat prints known Gio version problems before a build; the
+// compiler errors they cause rarely point at go.mod.
+func warnGioCompat(appDir string) {
+	goMod, err := giocompat.FindGoMod(appDir)
+	if err != nil {
+		return
+	}
+	versions, err := giocompat.ReadVersions(goMod)
+	if err != nil || versions.Gio == "" {
+		return
+	}
+	printGioProblems(giocompat.Load(config.GetCacheDir()).Check(versions))
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/bench"
+	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/progress"
+	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/joeblew999/goup-util/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: i18n.T("Benchmark goup-util itself"),
+}
+
+var benchBuildsCmd = &cobra.Command{
+	Use:   "builds [platform] [app-directory]",
+	Short: i18n.T("Time repeated builds and compare them with a baseline"),
+	Long: i18n.T(`Build an app repeatedly and report the median, 90th percentile, minimum
+and maximum time of each build phase (icons, compile, link, package,
+sign) and of the whole build.
+
+Every build is forced. Cold builds use an empty Go build cache; warm
+builds reuse the cache, after one untimed build to fill it.
+
+The results are compared with the stored baseline for the app and
+platform, or with --baseline. A median more than --threshold slower (and
+at least half a second) is a regression and fails the command. Save a
+baseline with --save-baseline, e.g. before upgrading gogio.`),
+	Example: `  goup-util bench builds macos examples/hybrid-dashboard --iterations 5 --save-baseline
+  goup-util bench builds android examples/hybrid-dashboard --mode warm --threshold 0.2
+  goup-util bench builds macos . --baseline bench/macos.json --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platform, appDir := args[0], args[1]
+		iterations, _ := cmd.Flags().GetInt("iterations")
+		modes, _ := cmd.Flags().GetStringSlice("mode")
+		baselinePath, _ := cmd.Flags().GetString("baseline")
+		save, _ := cmd.Flags().GetBool("save-baseline")
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		validPlatforms := []string{"macos", "android", "ios", "ios-simulator", "windows", "linux"}
+		if !utils.Contains(validPlatforms, platform) {
+			return fmt.Errorf(i18n.T("invalid platform: %s. Valid platforms: %v"), platform, validPlatforms)
+		}
+		for _, m := range modes {
+			if m != bench.Cold && m != bench.Warm {
+				return fmt.Errorf(i18n.T("invalid mode %q: use cold or warm"), m)
+			}
+		}
+		if iterations < 1 {
+			return errors.New(i18n.T("--iterations must be at least 1"))
+		}
+		proj, err := project.NewGioProject(appDir)
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to create project: %w"), err)
+		}
+		if err := proj.Validate(); err != nil {
+			return fmt.Errorf(i18n.T("invalid project: %w"), err)
+		}
+		if platform != "linux" {
+			if err := ensureGogio(); err != nil {
+				return err
+			}
+		}
+
+		// Build output would mix with the JSON
+		out := os.Stdout
+		if asJSON {
+			os.Stdout = os.Stderr
+			defer func() { os.Stdout = out }()
+		}
+		var samples []bench.Sample
+		for _, mode := range modes {
+			s, err := benchMode(proj, platform, mode, iterations)
+			if err != nil {
+				return err
+			}
+			samples = append(samples, s...)
+		}
+		result := &bench.Result{
+			App:      proj.Name,
+			Platform: platform,
+			Date:     time.Now().UTC(),
+			Version:  rootCmd.Version,
+			Modes:    bench.Summarize(samples),
+		}
+
+		if baselinePath == "" {
+			baselinePath = bench.BaselinePath(config.GetCacheDir(), proj.Name, platform)
+		}
+		var regressions []bench.Regression
+		baseline, err := bench.Load(baselinePath)
+		if err == nil {
+			regressions = bench.Compare(baseline, result, threshold)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if save {
+			if err := result.Save(baselinePath); err != nil {
+				return fmt.Errorf(i18n.T("failed to save baseline: %w"), err)
+			}
+		}
+
+		if asJSON {
+			os.Stdout = out
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(map[string]any{"result": result, "baseline": baseline, "regressions": regressions}); err != nil {
+				return err
+			}
+		} else {
+			printBench(result, baseline)
+			switch {
+			case baseline == nil:
+				fmt.Printf("\nℹ️  No baseline at %s; save one with --save-baseline\n", baselinePath)
+			case len(regressions) == 0:
+				fmt.Printf("\n✅ No regressions against %s (%s)\n", baselinePath, baseline.Date.Format("2006-01-02"))
+			default:
+				fmt.Printf("\n❌ Regressions against %s (%s):\n", baselinePath, baseline.Date.Format("2006-01-02"))
+				for _, r := range regressions {
+					fmt.Printf("  %s\n", r)
+				}
+			}
+			if save {
+				fmt.Printf("✓ Baseline saved: %s\n", baselinePath)
+			}
+		}
+		if len(regressions) > 0 {
+			return fmt.Errorf(i18n.T("%d build timing regression(s)"), len(regressions))
+		}
+		return nil
+	},
+}
+
+// benchMode runs the timed builds for one cache mode.
+func benchMode(proj *project.GioProject, platform, mode string, iterations int) ([]bench.Sample, error) {
+	opts := BuildOptions{Force: true}
+	if mode == bench.Warm {
+		fmt.Printf("🔥 Warming the build cache...\n")
+		if err := buildOne(proj, platform, opts); err != nil {
+			return nil, fmt.Errorf(i18n.T("build failed: %w"), err)
+		}
+	}
+
+	var samples []bench.Sample
+	for i := 1; i <= iterations; i++ {
+		sample, err := benchBuild(proj, platform, mode, fmt.Sprintf("%s %d/%d", mode, i, iterations), opts)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+func benchBuild(proj *project.GioProject, platform, mode, label string, opts BuildOptions) (bench.Sample, error) {
+	if mode == bench.Cold {
+		dir, err := os.MkdirTemp("", "goup-bench-gocache-")
+		if err != nil {
+			return bench.Sample{}, err
+		}
+		defer os.RemoveAll(dir)
+		prev, had := os.LookupEnv("GOCACHE")
+		os.Setenv("GOCACHE", dir)
+		defer func() {
+			if had {
+				os.Setenv("GOCACHE", prev)
+			} else {
+				os.Unsetenv("GOCACHE")
+			}
+		}()
+	}
+
+	opts.Progress = progress.New(proj.Name+" "+platform+" "+label, 0)
+	err := buildOne(proj, platform, opts)
+	timings := opts.Progress.Finish(err)
+	if err != nil {
+		return bench.Sample{}, fmt.Errorf(i18n.T("build failed: %w"), err)
+	}
+	sample := bench.Sample{Mode: mode, Total: opts.Progress.Total(), Phases: map[string]time.Duration{}}
+	for _, t := range timings {
+		sample.Phases[t.Phase] += t.Duration
+	}
+	return sample, nil
+}
+
+func printBench(r *bench.Result, baseline *bench.Result) {
+	ms := func(v int64) string {
+		return (time.Duration(v) * time.Millisecond).Round(10 * time.Millisecond).String()
+	}
+	fmt.Printf("\n📊 %s %s\n", r.App, r.Platform)
+	fmt.Printf("  %-6s %-9s %9s %9s %9s %9s %9s\n", "MODE", "PHASE", "MEDIAN", "P90", "MIN", "MAX", "BASELINE")
+	modes := make([]string, 0, len(r.Modes))
+	for m := range r.Modes {
+		modes = append(modes, m)
+	}
+	sort.Strings(modes)
+	for _, mode := range modes {
+		m := r.Modes[mode]
+		rows := append([]string{}, progress.Phases...)
+		rows = append(rows, "total")
+		for _, phase := range rows {
+			s, ok := m.Phases[phase]
+			if phase == "total" {
+				s, ok = m.Total, true
+			}
+			if !ok {
+				continue
+			}
+			base := "-"
+			if baseline != nil {
+				if bm, ok := baseline.Modes[mode]; ok {
+					if phase == "total" {
+						base = ms(bm.Total.Median)
+					} else if bs, ok := bm.Phases[phase]; ok {
+						base = ms(bs.Median)
+					}
+				}
+			}
+			fmt.Printf("  %-6s %-9s %9s %9s %9s %9s %9s\n", mode, phase, ms(s.Median), ms(s.P90), ms(s.Min), ms(s.Max), base)
+		}
+		fmt.Printf("  %s\n", strings.Repeat("─", 66))
+	}
+}
+
+func init() {
+	benchBuildsCmd.Flags().Int("iterations", 5, i18n.T("Timed builds per cache mode"))
+	benchBuildsCmd.Flags().StringSlice("mode", []string{bench.Cold, bench.Warm}, i18n.T("Cache modes to measure: cold, warm"))
+	benchBuildsCmd.Flags().String("baseline", "", i18n.T("Baseline file to compare with and save to (default: in the cache directory)"))
+	benchBuildsCmd.Flags().Bool("save-baseline", false, i18n.T("Save these results as the baseline"))
+	benchBuildsCmd.Flags().Float64("threshold", 0.10, i18n.T("Slowdown of a median, as a fraction, that counts as a regression"))
+	benchBuildsCmd.Flags().Bool("json", false, i18n.T("Print the results, baseline and regressions as JSON; build output goes to stderr"))
+
+	benchCmd.AddCommand(benchBuildsCmd)
+	benchCmd.GroupID = "tools"
+	rootCmd.AddCommand(benchCmd)
+}
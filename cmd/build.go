@@ -1,19 +1,40 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/joeblew999/goup-util/pkg/appconfig"
 	"github.com/joeblew999/goup-util/pkg/buildcache"
+	"github.com/joeblew999/goup-util/pkg/builders"
+	"github.com/joeblew999/goup-util/pkg/buildnumber"
 	"github.com/joeblew999/goup-util/pkg/config"
 	"github.com/joeblew999/goup-util/pkg/constants"
+	"github.com/joeblew999/goup-util/pkg/dockerbuild"
+	"github.com/joeblew999/goup-util/pkg/flavors"
+	"github.com/joeblew999/goup-util/pkg/history"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/icons"
 	"github.com/joeblew999/goup-util/pkg/installer"
+	"github.com/joeblew999/goup-util/pkg/packaging"
+	"github.com/joeblew999/goup-util/pkg/progress"
 	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/joeblew999/goup-util/pkg/sbom"
+	"github.com/joeblew999/goup-util/pkg/schema"
+	"github.com/joeblew999/goup-util/pkg/secrets"
 	"github.com/joeblew999/goup-util/pkg/utils"
+	"github.com/joeblew999/goup-util/pkg/xcode"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // BuildOptions contains options for build commands
@@ -25,6 +46,102 @@ type BuildOptions struct {
 	Schemes string // Deep linking URI schemes (e.g., "myapp://,https://example.com")
 	Queries string // Android app queries (e.g., "com.google.android.apps.maps")
 	SignKey string // Signing key (keystore path for Android, Keychain key name for macOS, or provisioning profile for iOS/macOS)
+
+	AppID       string // Bundle/package ID passed to gogio as -appid ("" = gogio default)
+	Version     string // App version (e.g., "1.2.0")
+	BuildNumber int    // Android versionCode / CFBundleVersion (0 = gogio default)
+
+	SBOM      string    // SBOM format written next to the artifact ("" = none)
+	StartedOn time.Time // For provenance
+
+	ViaDocker bool // Windows: build (and sign) in a container instead of on the host
+	Yes       bool // Linux: install missing system packages instead of stopping
+
+	Progress *progress.Tracker // Phase progress for the platform being built (nil = none)
+}
+
+// sbomParameters lists the flags that shaped the build for provenance.
+// Signing material is recorded as present, never by path.
+func (opts BuildOptions) sbomParameters() map[string]string {
+	params := make(map[string]string)
+	for k, v := range map[string]string{"schemes": opts.Schemes, "queries": opts.Queries, "version": opts.Version, "appId": opts.AppID} {
+		if v != "" {
+			params[k] = v
+		}
+	}
+	if opts.BuildNumber > 0 {
+		params["buildNumber"] = strconv.Itoa(opts.BuildNumber)
+	}
+	if opts.SignKey != "" {
+		params["signed"] = "true"
+	}
+	return params
+}
+
+// androidSigningArgs returns the -signkey and -signpass flags for gogio.
+// Without --signkey the keystore comes from the ANDROID_KEYSTORE secret and
+// is passed to gogioCmd through a pipe rather than a plaintext file; call
+// release once gogioCmd has started.
+func androidSigningArgs(gogioCmd *exec.Cmd, signKey string) (args []string, release func(), err error) {
+	release = func() {}
+	store := secrets.Open("")
+	if signKey == "" {
+		keystore, err := store.Get("ANDROID_KEYSTORE")
+		if err != nil {
+			return nil, release, err
+		}
+		if keystore == nil {
+			return nil, release, nil
+		}
+		if signKey, release, err = secrets.FilePath(gogioCmd, keystore); err != nil {
+			return nil, func() {}, err
+		}
+		fmt.Printf("🔑 Signing with ANDROID_KEYSTORE from %s\n", keystore.Source)
+	}
+	args = []string{"-signkey", signKey}
+	password, err := store.Text("ANDROID_KEYSTORE_PASSWORD")
+	if err != nil {
+		return nil, release, err
+	}
+	if password != "" {
+		args = append(args, "-signpass", password)
+	}
+	return args, release, nil
+}
+
+// gogioProgressArgs makes gogio echo the commands it runs, which is how
+// the progress tracker follows its phases.
+func gogioProgressArgs(opts BuildOptions) []string {
+	if opts.Progress == nil {
+		return nil
+	}
+	return []string{"-x"}
+}
+
+// gogioVersionArgs returns the -version flag gogio expects
+// ("major.minor.patch.versioncode"), or nothing if neither was set.
+func gogioVersionArgs(opts BuildOptions) []string {
+	if opts.Version == "" && opts.BuildNumber == 0 {
+		return nil
+	}
+	version := opts.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+	code := opts.BuildNumber
+	if code == 0 {
+		code = 1
+	}
+	return []string{"-version", fmt.Sprintf("%s.%d", version, code)}
+}
+
+// gogioAppIDArgs returns the -appid flag, or nothing to keep gogio's
+// default derived from the module path.
+func gogioAppIDArgs(opts BuildOptions) []string {
+	if opts.AppID == "" {
+		return nil
+	}
+	return []string{"-appid", opts.AppID}
 }
 
 // Global build cache
@@ -45,8 +162,8 @@ func getBuildCache() *buildcache.Cache {
 
 var buildCmd = &cobra.Command{
 	Use:   "build [platform] [app-directory]",
-	Short: "Build Gio applications for different platforms",
-	Long: `Build Gio applications for various platforms with deep linking and native features.
+	Short: i18n.T("Build Gio applications for different platforms"),
+	Long: i18n.T(`Build Gio applications for various platforms with deep linking and native features.
 
 Platforms: macos, android, ios, ios-simulator, windows, all
 
@@ -55,11 +172,33 @@ New gogio features (Dec 2025):
   --queries    Android app package queries for intent launching
   --signkey    Signing: keystore (Android), Keychain key (macOS), or provisioning profile (iOS/macOS)
 
+Store versions:
+  --version       App version (e.g. 1.2.0)
+  --build-number  Android versionCode / iOS CFBundleVersion; "auto" takes
+                  the next number from 'goup-util version' (forces a rebuild)
+
+White-label variants:
+  --flavor        Build with a flavor's name, URL, bundle ID, icon and colors
+                  from goup.json; each flavor gets its own artifact
+  --env           Layer app.<env>.json (e.g. app.staging.json) over app.json
+
+Supply chain:
+  --sbom          Write a CycloneDX (default) or SPDX SBOM and SLSA provenance
+                  next to the artifact (or set $GOUP_SBOM)
+
+Windows without Windows:
+  --via-docker    Build the .exe in a Docker/Podman container with mingw-w64 and
+                  sign it with osslsigncode if WINDOWS_CERTIFICATE is set
+                  (see 'goup-util secrets')
+
 Examples:
   goup-util build macos ./myapp
   goup-util build android ./myapp --schemes "myapp://,https://example.com"
   goup-util build android ./myapp --queries "com.google.android.apps.maps"
-  goup-util build ios ./myapp --signkey /path/to/profile.mobileprovision`,
+  goup-util build ios ./myapp --signkey /path/to/profile.mobileprovision
+  goup-util build android ./myapp --version 1.2.0 --build-number auto
+  goup-util build macos ./myapp --flavor customerA
+  goup-util build android ./myapp --env staging`),
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		platform := args[0]
@@ -68,15 +207,46 @@ Examples:
 		// Validate platform
 		validPlatforms := []string{"macos", "android", "ios", "ios-simulator", "windows", "linux", "all"}
 		if !utils.Contains(validPlatforms, platform) {
-			return fmt.Errorf("invalid platform: %s. Valid platforms: %v", platform, validPlatforms)
+			return fmt.Errorf(i18n.T("invalid platform: %s. Valid platforms: %v"), platform, validPlatforms)
 		}
 
 		// Check for custom output directory flag first
 		customOutput, _ := cmd.Flags().GetString("output")
+		var err error
+
+		// A flavor or environment builds from a staged copy carrying its
+		// app.json and icon; the artifacts still land next to the app
+		var appID string
+		flavor, _ := cmd.Flags().GetString("flavor")
+		env, _ := cmd.Flags().GetString("env")
+		if flavor != "" || env != "" {
+			var cfg *flavors.Config
+			if flavor != "" {
+				if cfg, err = flavors.Load(appDir); err != nil {
+					return err
+				}
+				f, err := cfg.Get(flavor)
+				if err != nil {
+					return err
+				}
+				appID = f.BundleID
+				fmt.Printf("🎨 Flavor %s\n", flavor)
+			}
+			if env != "" {
+				fmt.Printf("🌐 Environment %s\n", env)
+			}
+			staged, err := cfg.Stage(appDir, flavor, env)
+			if err != nil {
+				return fmt.Errorf(i18n.T("failed to stage %s: %w"), appDir, err)
+			}
+			if customOutput == "" {
+				customOutput = appDir
+			}
+			appDir = staged
+		}
 
 		// Create and validate project with potential custom output
 		var proj *project.GioProject
-		var err error
 
 		if customOutput != "" {
 			// Use custom output directory
@@ -87,11 +257,16 @@ Examples:
 		}
 
 		if err != nil {
-			return fmt.Errorf("failed to create project: %w", err)
+			return fmt.Errorf(i18n.T("failed to create project: %w"), err)
 		}
 
 		if err := proj.Validate(); err != nil {
-			return fmt.Errorf("invalid project: %w", err)
+			return fmt.Errorf(i18n.T("invalid project: %w"), err)
+		}
+
+		// Static assets declared in goup.json are compiled in
+		if err := generateAssets(proj.RootDir); err != nil {
+			return err
 		}
 
 		// Get flags
@@ -101,6 +276,14 @@ Examples:
 		schemes, _ := cmd.Flags().GetString("schemes")
 		queries, _ := cmd.Flags().GetString("queries")
 		signKey, _ := cmd.Flags().GetString("signkey")
+		version, _ := cmd.Flags().GetString("version")
+		buildNumber, _ := cmd.Flags().GetString("build-number")
+		buildRemote, _ := cmd.Flags().GetString("build-remote")
+		sbomFlag, _ := cmd.Flags().GetString("sbom")
+		sbomFormat, err := sbom.Resolve(sbomFlag)
+		if err != nil {
+			return err
+		}
 
 		// Create build options
 		opts := BuildOptions{
@@ -110,40 +293,222 @@ Examples:
 			Schemes:   schemes,
 			Queries:   queries,
 			SignKey:   signKey,
+			AppID:     appID,
+			Version:   version,
+			SBOM:      sbomFormat,
+			StartedOn: time.Now(),
+		}
+
+		// A new build number means a new artifact, so never reuse the cached one
+		if buildNumber != "" && !checkOnly {
+			store := buildnumber.Open(buildRemote, proj.RootDir)
+			n, err := buildnumber.Resolve(store, proj.Name, buildNumber)
+			if err != nil {
+				return fmt.Errorf(i18n.T("failed to get build number: %w"), err)
+			}
+			opts.BuildNumber = n
+			opts.Force = true
+			fmt.Printf("🔢 Build number %d\n", n)
 		}
 
-		// Ensure gogio is available (needed for all platforms except linux)
-		if platform != "linux" {
+		opts.ViaDocker, _ = cmd.Flags().GetBool("via-docker")
+		opts.Yes, _ = cmd.Flags().GetBool("yes")
+		if opts.ViaDocker && platform != "windows" {
+			return errors.New(i18n.T("--via-docker is only supported for windows builds"))
+		}
+
+		if buildJSONOut != nil && !checkOnly {
+			defer writeBuildResults(platform)
+		}
+
+		// Targets this host cannot build (or an explicit --builder) go to a
+		// registered remote builder.
+		builderName, _ := cmd.Flags().GetString("builder")
+		if platform != "all" && (builderName != "" || !builders.LocalSupports(platform, runtime.GOOS)) {
+			if opts.ViaDocker {
+				return errors.New(i18n.T("--via-docker and --builder cannot be combined"))
+			}
+			span := history.Start(history.Build, proj.Name, platform)
+			if builderName != "" {
+				span.Detail = "builder " + builderName
+			} else {
+				span.Detail = "remote builder"
+			}
+			err := dispatchBuild(cmd, proj, platform, builderName, opts)
+			span.End(err, proj.GetOutputPath(platform))
+			out := schema.BuildOutput{
+				Success:    err == nil,
+				Platform:   platform,
+				OutputPath: proj.GetOutputPath(platform),
+				Duration:   time.Since(span.Started).Round(time.Millisecond).String(),
+			}
+			if err != nil {
+				out.Error = err.Error()
+			}
+			buildResults = append(buildResults, out)
+			return err
+		}
+
+		// Ensure gogio is available (needed for all platforms except linux;
+		// --via-docker uses the one in the container)
+		if platform != "linux" && !opts.ViaDocker {
 			if err := ensureGogio(); err != nil {
 				return err
 			}
 		}
 
-		switch platform {
-		case "macos":
-			return buildMacOS(proj, platform, opts)
-		case "android":
-			return buildAndroid(proj, platform, opts)
-		case "ios":
-			return buildIOS(proj, platform, opts, false)
-		case "ios-simulator":
-			return buildIOS(proj, "ios-simulator", opts, true)
-		case "windows":
-			return buildWindows(proj, platform, opts)
-		case "linux":
-			return buildLinux(proj, platform, opts)
-		case "all":
-			return buildAll(proj, opts)
+		if checkOnly {
+			return buildPlatform(proj, platform, opts)
 		}
-
-		return nil
+		warnGioCompat(proj.RootDir)
+		span := history.Start(history.Build, proj.Name, platform)
+		if opts.ViaDocker {
+			span.Detail = "via-docker"
+		}
+		err = buildPlatform(proj, platform, opts)
+		artifact := ""
+		if platform != "all" {
+			artifact = proj.GetOutputPath(platform)
+		}
+		span.End(err, artifact)
+		return err
 	},
 }
 
+func buildPlatform(proj *project.GioProject, platform string, opts BuildOptions) error {
+	if platform == "all" {
+		return buildAll(proj, opts)
+	}
+	return trackBuild(proj, platform, opts)
+}
+
+// buildResults collects the BuildOutput of each platform built, for --json.
+var buildResults []schema.BuildOutput
+
+// buildJSONOut is the real stdout while --json sends everything else to
+// stderr.
+var buildJSONOut *os.File
+
+// writeBuildResults prints the BuildOutput for --json: one object for a
+// single platform, an array for "all".
+func writeBuildResults(platform string) error {
+	enc := json.NewEncoder(buildJSONOut)
+	enc.SetIndent("", "  ")
+	if platform != "all" && len(buildResults) == 1 {
+		return enc.Encode(buildResults[0])
+	}
+	return enc.Encode(buildResults)
+}
+
+// trackBuild builds one platform with phase progress and records its
+// BuildOutput.
+func trackBuild(proj *project.GioProject, platform string, opts BuildOptions) error {
+	if opts.CheckOnly {
+		return buildOne(proj, platform, opts)
+	}
+
+	opts.Progress = progress.New(proj.Name+" "+platform, history.Typical(history.Build, proj.Name, platform))
+	err := buildOne(proj, platform, opts)
+	timings := opts.Progress.Finish(err)
+
+	out := schema.BuildOutput{
+		Success:    err == nil,
+		Platform:   platform,
+		OutputPath: proj.GetOutputPath(platform),
+		Cached:     err == nil && len(timings) == 0,
+		Duration:   opts.Progress.Total().Round(time.Millisecond).String(),
+	}
+	for _, t := range timings {
+		out.Phases = append(out.Phases, schema.BuildPhase{Phase: t.Phase, Duration: t.Duration.Round(time.Millisecond).String()})
+	}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	buildResults = append(buildResults, out)
+	return err
+}
+
+// buildOne builds one platform, without progress tracking.
+func buildOne(proj *project.GioProject, platform string, opts BuildOptions) error {
+	switch platform {
+	case "macos":
+		return buildMacOS(proj, platform, opts)
+	case "android":
+		return buildAndroid(proj, platform, opts)
+	case "ios":
+		return buildIOS(proj, platform, opts, false)
+	case "ios-simulator":
+		return buildIOS(proj, "ios-simulator", opts, true)
+	case "windows":
+		return buildWindows(proj, platform, opts)
+	case "linux":
+		return buildLinux(proj, platform, opts)
+	}
+	return nil
+}
+
+// dispatchBuild runs "goup-util build" for platform on a remote builder and
+// copies the platform output directory back. The whole repository is sent,
+// so replace directives pointing at sibling modules still resolve.
+func dispatchBuild(cmd *cobra.Command, proj *project.GioProject, platform, name string, opts BuildOptions) error {
+	reg, err := builders.Load("")
+	if err != nil {
+		return err
+	}
+	var b *builders.Builder
+	if name != "" {
+		b, err = reg.Get(name)
+	} else {
+		b, err = reg.Find(platform, "")
+		if err != nil {
+			err = fmt.Errorf(i18n.T("%s cannot be built on %s: %w"), platform, runtime.GOOS, err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !b.Supports(platform, "") {
+		return fmt.Errorf(i18n.T("builder %s does not build %s (platforms: %s)"), b.Name, platform, strings.Join(b.Platforms, ", "))
+	}
+
+	root := builders.RepoRoot(proj.RootDir)
+	relApp, err := filepath.Rel(root, proj.RootDir)
+	if err != nil {
+		return fmt.Errorf(i18n.T("failed to resolve app directory: %w"), err)
+	}
+	relApp = filepath.ToSlash(relApp)
+
+	// Forward the flags that were set. The builder writes to its default
+	// output directory, the build number is already resolved here, and
+	// goup.json hooks run here rather than on the builder.
+	args := []string{"build", platform, relApp, "--no-hooks"}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		switch f.Name {
+		case "builder", "output", "build-remote", "via-docker", "no-hooks", "notify", "json":
+		case "build-number":
+			args = append(args, "--build-number", strconv.Itoa(opts.BuildNumber))
+		default:
+			args = append(args, "--"+f.Name+"="+f.Value.String())
+		}
+	})
+
+	if name == "" {
+		fmt.Printf("🛰️  %s cannot be built on %s; dispatching to builder %s\n", platform, runtime.GOOS, b.Name)
+	} else {
+		fmt.Printf("🛰️  Dispatching %s build to builder %s\n", platform, b.Name)
+	}
+	return b.Run(builders.Job{
+		Args:        args,
+		SourceDir:   root,
+		ArtifactDir: path.Join(relApp, constants.BinDir, platform),
+		Dest:        proj.GetPlatformDir(platform),
+	})
+}
+
 // ensureGogio checks that gogio is available and provides install instructions if not.
 func ensureGogio() error {
 	if _, err := exec.LookPath("gogio"); err != nil {
-		return fmt.Errorf("gogio not found in PATH\n\nInstall it with:\n  go install gioui.org/cmd/gogio@latest\n\nThen ensure $GOPATH/bin (or $GOBIN) is in your PATH")
+		return errors.New(i18n.T("gogio not found in PATH\n\nInstall it with:\n  go install gioui.org/cmd/gogio@latest\n\nThen ensure $GOPATH/bin (or $GOBIN) is in your PATH"))
 	}
 	return nil
 }
@@ -160,35 +525,40 @@ func buildMacOS(proj *project.GioProject, platform string, opts BuildOptions) er
 
 		if opts.CheckOnly {
 			if needsRebuild {
-				fmt.Printf("Rebuild needed: %s\n", reason)
+				fmt.Println(i18n.T("Rebuild needed: %s", reason))
 				os.Exit(1)
 			} else {
-				fmt.Printf("Up to date: %s\n", appPath)
+				fmt.Println(i18n.T("Up to date: %s", appPath))
 				os.Exit(0)
 			}
 		}
 
 		if !needsRebuild {
-			fmt.Printf("✓ %s for %s is up-to-date (use --force to rebuild)\n", proj.Name, platform)
+			fmt.Println(i18n.T("✓ %s for %s is up-to-date (use --force to rebuild)", proj.Name, platform))
 			return nil
 		}
 
-		fmt.Printf("Rebuilding: %s\n", reason)
+		fmt.Println(i18n.T("Rebuilding: %s", reason))
+	}
+
+	if err := xcodePreflight(xcode.MacOSX); err != nil {
+		return err
 	}
 
 	fmt.Printf("Building %s for macOS...\n", proj.Name)
 
 	// Generate icons
 	if !opts.SkipIcons {
+		opts.Progress.Phase(progress.Icons)
 		if err := generateIcons(proj.RootDir, "macos"); err != nil {
 			cache.RecordBuild(proj.Name, platform, proj.RootDir, appPath, false)
-			return fmt.Errorf("failed to generate icons: %w", err)
+			return fmt.Errorf(i18n.T("failed to generate icons: %w"), err)
 		}
 	}
 
 	// Create output directory
 	if err := os.MkdirAll(platformDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 	}
 
 	// Remove existing app bundle only if it exists
@@ -201,6 +571,9 @@ func buildMacOS(proj *project.GioProject, platform string, opts BuildOptions) er
 	iconPath := proj.Paths().SourceIcon
 
 	args := []string{"-target", "macos", "-arch", "arm64", "-icon", iconPath, "-o", appPath}
+	args = append(args, gogioVersionArgs(opts)...)
+	args = append(args, gogioAppIDArgs(opts)...)
+	args = append(args, gogioProgressArgs(opts)...)
 
 	// Add deep linking schemes if specified
 	if opts.Schemes != "" {
@@ -217,16 +590,20 @@ func buildMacOS(proj *project.GioProject, platform string, opts BuildOptions) er
 	gogioCmd.Dir = proj.RootDir // Run from app directory so its go.mod is used
 	// Set GOWORK=off to avoid workspace interference with example modules
 	gogioCmd.Env = append(os.Environ(), "GOWORK=off")
-	gogioCmd.Stdout = os.Stdout
-	gogioCmd.Stderr = os.Stderr
+	gogioCmd.Stdout = opts.Progress.Writer(os.Stdout)
+	gogioCmd.Stderr = opts.Progress.Writer(os.Stderr)
 
+	opts.Progress.Phase(progress.Compile)
 	if err := gogioCmd.Run(); err != nil {
 		cache.RecordBuild(proj.Name, platform, proj.RootDir, appPath, false)
-		return fmt.Errorf("gogio build failed: %w", err)
+		return fmt.Errorf(i18n.T("gogio build failed: %w"), err)
 	}
 
 	// Record successful build
 	cache.RecordBuild(proj.Name, platform, proj.RootDir, appPath, true)
+	if err := writeSBOM(proj, platform, appPath, opts.SBOM, opts.sbomParameters(), opts.StartedOn); err != nil {
+		return err
+	}
 
 	fmt.Printf("✓ Built %s for macOS: %s\n", proj.Name, appPath)
 	return nil
@@ -244,35 +621,36 @@ func buildAndroid(proj *project.GioProject, platform string, opts BuildOptions)
 
 		if opts.CheckOnly {
 			if needsRebuild {
-				fmt.Printf("Rebuild needed: %s\n", reason)
+				fmt.Println(i18n.T("Rebuild needed: %s", reason))
 				os.Exit(1)
 			} else {
-				fmt.Printf("Up to date: %s\n", apkPath)
+				fmt.Println(i18n.T("Up to date: %s", apkPath))
 				os.Exit(0)
 			}
 		}
 
 		if !needsRebuild {
-			fmt.Printf("✓ %s for %s is up-to-date (use --force to rebuild)\n", proj.Name, platform)
+			fmt.Println(i18n.T("✓ %s for %s is up-to-date (use --force to rebuild)", proj.Name, platform))
 			return nil
 		}
 
-		fmt.Printf("Rebuilding: %s\n", reason)
+		fmt.Println(i18n.T("Rebuilding: %s", reason))
 	}
 
 	fmt.Printf("Building %s for Android...\n", proj.Name)
 
 	// Generate icons
 	if !opts.SkipIcons {
+		opts.Progress.Phase(progress.Icons)
 		if err := generateIcons(proj.RootDir, "android"); err != nil {
 			cache.RecordBuild(proj.Name, platform, proj.RootDir, apkPath, false)
-			return fmt.Errorf("failed to generate icons: %w", err)
+			return fmt.Errorf(i18n.T("failed to generate icons: %w"), err)
 		}
 	}
 
 	// Create output directory
 	if err := os.MkdirAll(platformDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 	}
 
 	// Use OS-specific SDK directory only
@@ -285,7 +663,7 @@ func buildAndroid(proj *project.GioProject, platform string, opts BuildOptions)
 		// Auto-install NDK
 		if err := installNDK(sdkRoot); err != nil {
 			cache.RecordBuild(proj.Name, platform, proj.RootDir, apkPath, false)
-			return fmt.Errorf("failed to install NDK: %w", err)
+			return fmt.Errorf(i18n.T("failed to install NDK: %w"), err)
 		}
 	}
 
@@ -302,6 +680,9 @@ func buildAndroid(proj *project.GioProject, platform string, opts BuildOptions)
 	// Use minSdk from SDK config (centralized in sdk-android-list.json)
 	minSdk := config.GetAndroidMinSdk()
 	args := []string{"-target", "android", "-minsdk", minSdk, "-o", apkPath}
+	args = append(args, gogioVersionArgs(opts)...)
+	args = append(args, gogioAppIDArgs(opts)...)
+	args = append(args, gogioProgressArgs(opts)...)
 
 	// Add deep linking schemes if specified
 	if opts.Schemes != "" {
@@ -313,25 +694,38 @@ func buildAndroid(proj *project.GioProject, platform string, opts BuildOptions)
 		args = append(args, "-queries", opts.Queries)
 	}
 
-	// Add signing key if specified
-	if opts.SignKey != "" {
-		args = append(args, "-signkey", opts.SignKey)
+	// Add signing key: --signkey, else ANDROID_KEYSTORE from secrets
+	gogioCmd := exec.Command("gogio")
+	signArgs, release, err := androidSigningArgs(gogioCmd, opts.SignKey)
+	defer release()
+	if err != nil {
+		return err
 	}
+	args = append(args, signArgs...)
 
 	args = append(args, ".") // Build current directory
-	gogioCmd := exec.Command("gogio", args...)
+	gogioCmd.Args = append(gogioCmd.Args, args...)
 	gogioCmd.Dir = proj.RootDir // Run from app directory so its go.mod is used
 	gogioCmd.Env = env
-	gogioCmd.Stdout = os.Stdout
-	gogioCmd.Stderr = os.Stderr
-
-	if err := gogioCmd.Run(); err != nil {
+	gogioCmd.Stdout = opts.Progress.Writer(os.Stdout)
+	gogioCmd.Stderr = opts.Progress.Writer(os.Stderr)
+
+	opts.Progress.Phase(progress.Compile)
+	err = gogioCmd.Start()
+	release() // gogio has its own copy of the keystore pipe now
+	if err == nil {
+		err = gogioCmd.Wait()
+	}
+	if err != nil {
 		cache.RecordBuild(proj.Name, platform, proj.RootDir, apkPath, false)
-		return fmt.Errorf("gogio build failed: %w", err)
+		return fmt.Errorf(i18n.T("gogio build failed: %w"), err)
 	}
 
 	// Record successful build
 	cache.RecordBuild(proj.Name, platform, proj.RootDir, apkPath, true)
+	if err := writeSBOM(proj, platform, apkPath, opts.SBOM, opts.sbomParameters(), opts.StartedOn); err != nil {
+		return err
+	}
 
 	fmt.Printf("✓ Built %s for Android: %s\n", proj.Name, apkPath)
 	return nil
@@ -354,41 +748,53 @@ func buildIOS(proj *project.GioProject, platform string, opts BuildOptions, simu
 
 		if opts.CheckOnly {
 			if needsRebuild {
-				fmt.Printf("Rebuild needed: %s\n", reason)
+				fmt.Println(i18n.T("Rebuild needed: %s", reason))
 				os.Exit(1)
 			} else {
-				fmt.Printf("Up to date: %s\n", appPath)
+				fmt.Println(i18n.T("Up to date: %s", appPath))
 				os.Exit(0)
 			}
 		}
 
 		if !needsRebuild {
-			fmt.Printf("✓ %s for %s is up-to-date (use --force to rebuild)\n", proj.Name, platform)
+			fmt.Println(i18n.T("✓ %s for %s is up-to-date (use --force to rebuild)", proj.Name, platform))
 			return nil
 		}
 
-		fmt.Printf("Rebuilding: %s\n", reason)
+		fmt.Println(i18n.T("Rebuilding: %s", reason))
+	}
+
+	sdk := xcode.IPhoneOS
+	if simulator {
+		sdk = xcode.IPhoneSimulator
+	}
+	if err := xcodePreflight(sdk); err != nil {
+		return err
 	}
 
 	fmt.Printf("Building %s for %s...\n", proj.Name, target)
 
 	// Generate icons
 	if !opts.SkipIcons {
+		opts.Progress.Phase(progress.Icons)
 		if err := generateIcons(proj.RootDir, "ios"); err != nil {
 			cache.RecordBuild(proj.Name, platform, proj.RootDir, appPath, false)
-			return fmt.Errorf("failed to generate icons: %w", err)
+			return fmt.Errorf(i18n.T("failed to generate icons: %w"), err)
 		}
 	}
 
 	// Create output directory
 	if err := os.MkdirAll(platformDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 	}
 
 	// Build with gogio - project paths are already absolute
 	// Use minOS from SDK config (centralized in sdk-ios-list.json)
 	minOS := config.GetIOSMinOS()
 	args := []string{"-target", "ios", "-minsdk", minOS, "-o", appPath}
+	args = append(args, gogioVersionArgs(opts)...)
+	args = append(args, gogioAppIDArgs(opts)...)
+	args = append(args, gogioProgressArgs(opts)...)
 
 	// Add deep linking schemes if specified
 	if opts.Schemes != "" {
@@ -405,16 +811,28 @@ func buildIOS(proj *project.GioProject, platform string, opts BuildOptions, simu
 	gogioCmd.Dir = proj.RootDir // Run from app directory so its go.mod is used
 	// Set GOWORK=off to avoid workspace interference with example modules
 	gogioCmd.Env = append(os.Environ(), "GOWORK=off")
-	gogioCmd.Stdout = os.Stdout
-	gogioCmd.Stderr = os.Stderr
+	gogioCmd.Stdout = opts.Progress.Writer(os.Stdout)
+	gogioCmd.Stderr = opts.Progress.Writer(os.Stderr)
 
+	opts.Progress.Phase(progress.Compile)
 	if err := gogioCmd.Run(); err != nil {
 		cache.RecordBuild(proj.Name, platform, proj.RootDir, appPath, false)
-		return fmt.Errorf("gogio build failed: %w", err)
+		return fmt.Errorf(i18n.T("gogio build failed: %w"), err)
+	}
+
+	// Export compliance from app.json, so TestFlight does not hold the build
+	if ec := appconfig.LoadOrDefault(proj.RootDir).ExportCompliance; ec != nil {
+		if err := packaging.SetIOSExportCompliance(appPath, ec.UsesNonExemptEncryption, ec.ComplianceCode); err != nil {
+			cache.RecordBuild(proj.Name, platform, proj.RootDir, appPath, false)
+			return fmt.Errorf(i18n.T("failed to set export compliance: %w"), err)
+		}
 	}
 
 	// Record successful build
 	cache.RecordBuild(proj.Name, platform, proj.RootDir, appPath, true)
+	if err := writeSBOM(proj, platform, appPath, opts.SBOM, opts.sbomParameters(), opts.StartedOn); err != nil {
+		return err
+	}
 
 	fmt.Printf("✓ Built %s for %s: %s\n", proj.Name, target, appPath)
 	return nil
@@ -432,35 +850,50 @@ func buildWindows(proj *project.GioProject, platform string, opts BuildOptions)
 
 		if opts.CheckOnly {
 			if needsRebuild {
-				fmt.Printf("Rebuild needed: %s\n", reason)
+				fmt.Println(i18n.T("Rebuild needed: %s", reason))
 				os.Exit(1)
 			} else {
-				fmt.Printf("Up to date: %s\n", exePath)
+				fmt.Println(i18n.T("Up to date: %s", exePath))
 				os.Exit(0)
 			}
 		}
 
 		if !needsRebuild {
-			fmt.Printf("✓ %s for %s is up-to-date (use --force to rebuild)\n", proj.Name, platform)
+			fmt.Println(i18n.T("✓ %s for %s is up-to-date (use --force to rebuild)", proj.Name, platform))
 			return nil
 		}
 
-		fmt.Printf("Rebuilding: %s\n", reason)
+		fmt.Println(i18n.T("Rebuilding: %s", reason))
 	}
 
 	fmt.Printf("Building %s for Windows...\n", proj.Name)
 
 	// Generate icons
 	if !opts.SkipIcons {
+		opts.Progress.Phase(progress.Icons)
 		if err := generateIcons(proj.RootDir, "windows"); err != nil {
 			cache.RecordBuild(proj.Name, platform, proj.RootDir, exePath, false)
-			return fmt.Errorf("failed to generate icons: %w", err)
+			return fmt.Errorf(i18n.T("failed to generate icons: %w"), err)
 		}
 	}
 
 	// Create output directory
 	if err := os.MkdirAll(platformDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
+	}
+
+	if opts.ViaDocker {
+		opts.Progress.Phase(progress.Compile)
+		if err := buildWindowsInContainer(proj, exePath, opts); err != nil {
+			cache.RecordBuild(proj.Name, platform, proj.RootDir, exePath, false)
+			return err
+		}
+		cache.RecordBuild(proj.Name, platform, proj.RootDir, exePath, true)
+		if err := writeSBOM(proj, platform, exePath, opts.SBOM, opts.sbomParameters(), opts.StartedOn); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Built %s for Windows in a container: %s\n", proj.Name, exePath)
+		return nil
 	}
 
 	// Set Windows environment
@@ -472,24 +905,66 @@ func buildWindows(proj *project.GioProject, platform string, opts BuildOptions)
 	// Build with gogio - project paths are already absolute
 	iconPath := proj.Paths().SourceIcon
 
-	gogioCmd := exec.Command("gogio", "-o", exePath, "-target", "windows", "-icon", iconPath, ".")
+	args := []string{"-o", exePath, "-target", "windows", "-icon", iconPath}
+	args = append(args, gogioVersionArgs(opts)...)
+	args = append(args, gogioAppIDArgs(opts)...)
+	args = append(args, gogioProgressArgs(opts)...)
+	args = append(args, ".")
+	gogioCmd := exec.Command("gogio", args...)
 	gogioCmd.Dir = proj.RootDir // Run from app directory so its go.mod is used
 	gogioCmd.Env = env
-	gogioCmd.Stdout = os.Stdout
-	gogioCmd.Stderr = os.Stderr
+	gogioCmd.Stdout = opts.Progress.Writer(os.Stdout)
+	gogioCmd.Stderr = opts.Progress.Writer(os.Stderr)
 
+	opts.Progress.Phase(progress.Compile)
 	if err := gogioCmd.Run(); err != nil {
 		cache.RecordBuild(proj.Name, platform, proj.RootDir, exePath, false)
-		return fmt.Errorf("gogio build failed: %w", err)
+		return fmt.Errorf(i18n.T("gogio build failed: %w"), err)
 	}
 
 	// Record successful build
 	cache.RecordBuild(proj.Name, platform, proj.RootDir, exePath, true)
+	if err := writeSBOM(proj, platform, exePath, opts.SBOM, opts.sbomParameters(), opts.StartedOn); err != nil {
+		return err
+	}
 
 	fmt.Printf("✓ Built %s for Windows: %s\n", proj.Name, exePath)
 	return nil
 }
 
+// buildWindowsInContainer builds the .exe with the container toolchain and
+// signs it with the WINDOWS_CERTIFICATE secret when one is set.
+func buildWindowsInContainer(proj *project.GioProject, exePath string, opts BuildOptions) error {
+	buildOpts := dockerbuild.WindowsOptions{
+		AppDir:    proj.RootDir,
+		Output:    exePath,
+		Icon:      proj.Paths().SourceIcon,
+		GogioArgs: append(append(gogioVersionArgs(opts), gogioAppIDArgs(opts)...), gogioProgressArgs(opts)...),
+		Stdout:    opts.Progress.Writer(os.Stdout),
+		Stderr:    opts.Progress.Writer(os.Stderr),
+	}
+
+	store := secrets.Open("")
+	cert, err := store.Get("WINDOWS_CERTIFICATE")
+	if err != nil {
+		return err
+	}
+	if cert != nil {
+		password, err := store.Text("WINDOWS_CERTIFICATE_PASSWORD")
+		if err != nil {
+			return err
+		}
+		buildOpts.Signing = &dockerbuild.Signing{Path: cert.Path, Password: password, Name: proj.Name}
+		if cert.Path == "" {
+			buildOpts.Signing.Data = cert.Value
+		}
+		fmt.Printf("🔑 Signing with WINDOWS_CERTIFICATE from %s\n", cert.Source)
+	}
+
+	fmt.Println("🐳 Building in container...")
+	return dockerbuild.Windows(buildOpts)
+}
+
 func buildLinux(proj *project.GioProject, platform string, opts BuildOptions) error {
 	// Use project's centralized path methods
 	platformDir := proj.GetPlatformDir(platform)
@@ -502,27 +977,31 @@ func buildLinux(proj *project.GioProject, platform string, opts BuildOptions) er
 
 		if opts.CheckOnly {
 			if needsRebuild {
-				fmt.Printf("Rebuild needed: %s\n", reason)
+				fmt.Println(i18n.T("Rebuild needed: %s", reason))
 				os.Exit(1)
 			} else {
-				fmt.Printf("Up to date: %s\n", binPath)
+				fmt.Println(i18n.T("Up to date: %s", binPath))
 				os.Exit(0)
 			}
 		}
 
 		if !needsRebuild {
-			fmt.Printf("✓ %s for %s is up-to-date (use --force to rebuild)\n", proj.Name, platform)
+			fmt.Println(i18n.T("✓ %s for %s is up-to-date (use --force to rebuild)", proj.Name, platform))
 			return nil
 		}
 
-		fmt.Printf("Rebuilding: %s\n", reason)
+		fmt.Println(i18n.T("Rebuilding: %s", reason))
+	}
+
+	if err := linuxPreflight(opts.Yes); err != nil {
+		return err
 	}
 
 	fmt.Printf("Building %s for Linux...\n", proj.Name)
 
 	// Create output directory
 	if err := os.MkdirAll(platformDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 	}
 
 	// Build with go build (Linux doesn't need gogio for basic builds)
@@ -536,16 +1015,20 @@ func buildLinux(proj *project.GioProject, platform string, opts BuildOptions) er
 	buildCmd := exec.Command("go", "build", "-o", binPath, ".")
 	buildCmd.Env = env
 	buildCmd.Dir = proj.RootDir
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
+	buildCmd.Stdout = opts.Progress.Writer(os.Stdout)
+	buildCmd.Stderr = opts.Progress.Writer(os.Stderr)
 
+	opts.Progress.Phase(progress.Compile)
 	if err := buildCmd.Run(); err != nil {
 		cache.RecordBuild(proj.Name, platform, proj.RootDir, binPath, false)
-		return fmt.Errorf("go build failed: %w", err)
+		return fmt.Errorf(i18n.T("go build failed: %w"), err)
 	}
 
 	// Record successful build
 	cache.RecordBuild(proj.Name, platform, proj.RootDir, binPath, true)
+	if err := writeSBOM(proj, platform, binPath, opts.SBOM, opts.sbomParameters(), opts.StartedOn); err != nil {
+		return err
+	}
 
 	fmt.Printf("✓ Built %s for Linux: %s\n", proj.Name, binPath)
 	return nil
@@ -554,19 +1037,19 @@ func buildLinux(proj *project.GioProject, platform string, opts BuildOptions) er
 // installNDK installs the Android NDK if not present
 func installNDK(sdkRoot string) error {
 	fmt.Printf("📦 Installing Android NDK...\n")
-	
+
 	// Use the installer package to install NDK
 	ndkSDK := &installer.SDK{
 		Name:        "Android NDK",
 		Version:     "latest",
 		InstallPath: "ndk-bundle",
 	}
-	
+
 	cache, err := installer.NewCache(filepath.Join(config.GetCacheDir(), "cache.json"))
 	if err != nil {
-		return fmt.Errorf("failed to create cache: %w", err)
+		return fmt.Errorf(i18n.T("failed to create cache: %w"), err)
 	}
-	
+
 	return installer.Install(ndkSDK, cache)
 }
 
@@ -577,23 +1060,8 @@ func buildAll(proj *project.GioProject, opts BuildOptions) error {
 
 	for _, platform := range platforms {
 		fmt.Printf("\n--- Building for %s ---\n", platform)
-		switch platform {
-		case "macos":
-			if err := buildMacOS(proj, platform, opts); err != nil {
-				fmt.Printf("❌ Failed to build for %s: %v\n", platform, err)
-			}
-		case "android":
-			if err := buildAndroid(proj, platform, opts); err != nil {
-				fmt.Printf("❌ Failed to build for %s: %v\n", platform, err)
-			}
-		case "ios-simulator":
-			if err := buildIOS(proj, platform, opts, true); err != nil {
-				fmt.Printf("❌ Failed to build for %s: %v\n", platform, err)
-			}
-		case "windows":
-			if err := buildWindows(proj, platform, opts); err != nil {
-				fmt.Printf("❌ Failed to build for %s: %v\n", platform, err)
-			}
+		if err := trackBuild(proj, platform, opts); err != nil {
+			fmt.Printf("❌ Failed to build for %s: %v\n", platform, err)
 		}
 	}
 
@@ -622,6 +1090,20 @@ func generateIcons(appDir, platform string) error {
 		return nil // Skip icon generation for unknown platforms
 	}
 
+	// Fail before a long build on an icon the store would reject
+	report, err := icons.Validate(sourceIconPath, platform)
+	if err != nil {
+		return err
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity == icons.SeverityWarning && issue.Code != "placeholder" {
+			fmt.Printf("⚠️  %s\n", issue.Message)
+		}
+	}
+	if err := report.Err(); err != nil {
+		return err
+	}
+
 	fmt.Printf("Generating %s icons...\n", platform)
 	return icons.Generate(icons.Config{
 		InputPath:  sourceIconPath,
@@ -630,19 +1112,77 @@ func generateIcons(appDir, platform string) error {
 	})
 }
 
+// sbomTargets maps goup-util platforms to the GOOS/GOARCH gogio builds.
+var sbomTargets = map[string][]string{
+	"macos":         {"GOOS=darwin", "GOARCH=arm64"},
+	"android":       {"GOOS=android", "GOARCH=arm64"},
+	"ios":           {"GOOS=ios", "GOARCH=arm64"},
+	"ios-simulator": {"GOOS=ios", "GOARCH=arm64"},
+	"windows":       {"GOOS=windows", "GOARCH=amd64"},
+	"linux":         {"GOOS=linux", "GOARCH=amd64"},
+}
+
+// writeSBOM writes the SBOM and provenance sidecars for an artifact when
+// format is set (--sbom or $GOUP_SBOM).
+func writeSBOM(proj *project.GioProject, platform, artifactPath, format string, params map[string]string, started time.Time) error {
+	if format == "" {
+		return nil
+	}
+	files, err := sbom.Write(sbom.Options{
+		Format:     format,
+		Artifact:   artifactPath,
+		AppDir:     proj.RootDir,
+		Name:       proj.Name,
+		Version:    params["version"],
+		Env:        append([]string{"CGO_ENABLED=1"}, sbomTargets[platform]...),
+		Builder:    rootCmd.Version,
+		Platform:   platform,
+		Parameters: params,
+		StartedOn:  started,
+	})
+	if err != nil {
+		return fmt.Errorf(i18n.T("failed to write SBOM: %w"), err)
+	}
+	for _, f := range files {
+		fmt.Printf("✓ Wrote %s\n", filepath.Base(f))
+	}
+	return nil
+}
+
 // Remove the old generateTestIcon function since it's now in the icons package
 // contains() moved to pkg/utils/slice.go
 
 func init() {
-	buildCmd.Flags().BoolVar(&skipIcons, "skip-icons", false, "Skip icon generation")
-	buildCmd.Flags().String("output", "", "Custom output directory for build artifacts")
-	buildCmd.Flags().Bool("force", false, "Force rebuild even if up-to-date")
-	buildCmd.Flags().Bool("check", false, "Check if rebuild needed (exit 0=no, 1=yes)")
+	buildCmd.Flags().BoolVar(&skipIcons, "skip-icons", false, i18n.T("Skip icon generation"))
+	buildCmd.Flags().String("output", "", i18n.T("Custom output directory for build artifacts"))
+	buildCmd.Flags().Bool("force", false, i18n.T("Force rebuild even if up-to-date"))
+	buildCmd.Flags().Bool("check", false, i18n.T("Check if rebuild needed (exit 0=no, 1=yes)"))
 
 	// New gogio flags (Dec 2025)
-	buildCmd.Flags().String("schemes", "", "Deep linking URI schemes (comma-separated, e.g., 'myapp://,https://example.com')")
-	buildCmd.Flags().String("queries", "", "Android app package queries (comma-separated, e.g., 'com.google.android.apps.maps')")
-	buildCmd.Flags().String("signkey", "", "Signing key: keystore path (Android), Keychain key name (macOS), or provisioning profile (iOS/macOS)")
+	buildCmd.Flags().String("schemes", "", i18n.T("Deep linking URI schemes (comma-separated, e.g., 'myapp://,https://example.com')"))
+	buildCmd.Flags().String("queries", "", i18n.T("Android app package queries (comma-separated, e.g., 'com.google.android.apps.maps')"))
+	buildCmd.Flags().String("signkey", "", i18n.T("Signing key: keystore path (Android), Keychain key name (macOS), or provisioning profile (iOS/macOS)"))
+
+	buildCmd.Flags().String("flavor", "", i18n.T("Build a white-label variant from the \"flavors\" section of goup.json"))
+	buildCmd.Flags().String("env", "", i18n.T("Layer app.<env>.json over app.json (e.g. staging, prod) and bake the environment into the app"))
+	buildCmd.Flags().String("version", "", i18n.T("App version passed to gogio (e.g., '1.2.0')"))
+	buildCmd.Flags().String("build-number", "", i18n.T("Android versionCode / iOS CFBundleVersion: a number or 'auto' for the next one"))
+	buildCmd.Flags().String("sbom", "", "Write an SBOM (cyclonedx or spdx) and SLSA provenance next to the artifact (default $"+sbom.FormatEnv+")")
+	buildCmd.Flags().Lookup("sbom").NoOptDefVal = sbom.CycloneDX
+	buildCmd.Flags().String("build-remote", "", "Git remote holding build number tags (default $"+buildnumber.RemoteEnv+", else local)")
+	buildCmd.Flags().Bool("via-docker", false, i18n.T("Windows: build and sign in a Docker/Podman container (no Windows machine or VM needed)"))
+	buildCmd.Flags().BoolP("yes", "y", false, i18n.T("Linux: install missing system packages (Gio's C dependencies) instead of stopping"))
+	buildCmd.Flags().Bool("json", false, i18n.T("Print the result (path, cache hit, duration and phase timings) as JSON; other output goes to stderr"))
+	buildCmd.Flags().String("builder", "", i18n.T("Build on this remote builder from builders.json (default: a capable builder when the host cannot build the target)"))
+
+	// --json swaps stdout before hooks and notifications run, so only the
+	// result reaches it.
+	buildCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			buildJSONOut, os.Stdout = os.Stdout, os.Stderr
+		}
+		return nil
+	}
 
 	// Command group for help organization
 	buildCmd.GroupID = "build"
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/builders"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var buildersCmd = &cobra.Command{
+	Use:   "builders",
+	Short: i18n.T("Manage remote builders for targets this host cannot build"),
+	Long: `Manage the registry of remote builders (builders.json in the goup-util
+config directory, or $` + builders.RegistryEnv + `).
+
+'goup-util build' dispatches targets the host cannot build (macOS and iOS
+anywhere but macOS) to the first builder that lists the platform, or to
+the one named with --builder. The repository is sent to the builder,
+goup-util runs there, and the platform output directory is copied back.
+
+Builder types:
+  utm     A local UTM VM, reached through its QEMU guest agent
+  ssh     Any machine with goup-util in its PATH, over SSH
+  daemon  A goup-util daemon, over HTTP
+
+Examples:
+  goup-util builders add win-vm --type utm --vm "Windows 11" --platforms windows
+  goup-util builders add mac-mini --type ssh --host ci@mac-mini.local --os darwin --platforms macos,ios
+  goup-util builders add farm --type daemon --url https://builds.example.com --token-env FARM_TOKEN --platforms android,windows
+  goup-util builders check
+  goup-util build windows ./myapp --builder win-vm`,
+}
+
+var buildersAdd builders.Builder
+
+var buildersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("List registered builders"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := builders.Load("")
+		if err != nil {
+			return err
+		}
+		if len(reg.Builders) == 0 {
+			fmt.Printf("No builders in %s\n", reg.Path())
+			fmt.Println("Add one with: goup-util builders add <name> --type utm|ssh|daemon ...")
+			return nil
+		}
+		fmt.Printf("Builders (%s):\n\n", reg.Path())
+		for _, b := range reg.Builders {
+			arch := "any"
+			if len(b.Arch) > 0 {
+				arch = strings.Join(b.Arch, ",")
+			}
+			fmt.Printf("  %-16s %-7s %-28s %s (arch %s)\n", b.Name, b.Kind, b.Target(), strings.Join(b.Platforms, ","), arch)
+		}
+		return nil
+	},
+}
+
+var buildersAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: i18n.T("Add or replace a builder"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := builders.Load("")
+		if err != nil {
+			return err
+		}
+		b := buildersAdd
+		b.Name = args[0]
+		if err := reg.Add(b); err != nil {
+			return err
+		}
+		if err := reg.Save(); err != nil {
+			return fmt.Errorf(i18n.T("failed to save builders: %w"), err)
+		}
+		fmt.Printf("✓ Added builder %s (%s %s) to %s\n", b.Name, b.Kind, b.Target(), reg.Path())
+		return nil
+	},
+}
+
+var buildersRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: i18n.T("Remove a builder"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := builders.Load("")
+		if err != nil {
+			return err
+		}
+		if err := reg.Remove(args[0]); err != nil {
+			return err
+		}
+		if err := reg.Save(); err != nil {
+			return fmt.Errorf(i18n.T("failed to save builders: %w"), err)
+		}
+		fmt.Printf("✓ Removed builder %s\n", args[0])
+		return nil
+	},
+}
+
+var buildersCheckCmd = &cobra.Command{
+	Use:   "check [name]",
+	Short: i18n.T("Check that builders are reachable"),
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := builders.Load("")
+		if err != nil {
+			return err
+		}
+		list := reg.Builders
+		if len(args) == 1 {
+			b, err := reg.Get(args[0])
+			if err != nil {
+				return err
+			}
+			list = []builders.Builder{*b}
+		}
+		failed := 0
+		for i := range list {
+			b := &list[i]
+			if err := builders.Check(b); err != nil {
+				fmt.Printf("❌ %s: %v\n", b.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ %s (%s %s)\n", b.Name, b.Kind, b.Target())
+		}
+		if failed > 0 {
+			return fmt.Errorf(i18n.T("%d of %d builders unreachable"), failed, len(list))
+		}
+		return nil
+	},
+}
+
+func init() {
+	f := buildersAddCmd.Flags()
+	f.StringVar((*string)(&buildersAdd.Kind), "type", "", "Builder type: utm, ssh or daemon")
+	f.StringVar(&buildersAdd.VM, "vm", "", "UTM VM name")
+	f.StringVar(&buildersAdd.Host, "host", "", "SSH destination (user@host[:port])")
+	f.StringVar(&buildersAdd.URL, "url", "", "Daemon base URL")
+	f.StringVar(&buildersAdd.TokenEnv, "token-env", "", "Environment variable holding the daemon token")
+	f.StringVar(&buildersAdd.OS, "os", "", "Builder OS: windows, linux or darwin (UTM default: guessed from the VM)")
+	f.StringSliceVar(&buildersAdd.Platforms, "platforms", nil, "Platforms it builds (comma-separated, e.g. windows,android)")
+	f.StringSliceVar(&buildersAdd.Arch, "arch", nil, "Architectures it builds (default any)")
+	f.StringVar(&buildersAdd.Workdir, "workdir", "", "Where sources are unpacked on the builder")
+	buildersAddCmd.MarkFlagRequired("type")
+	buildersAddCmd.MarkFlagRequired("platforms")
+
+	buildersCmd.AddCommand(buildersListCmd)
+	buildersCmd.AddCommand(buildersAddCmd)
+	buildersCmd.AddCommand(buildersRemoveCmd)
+	buildersCmd.AddCommand(buildersCheckCmd)
+	rootCmd.AddCommand(buildersCmd)
+	buildersCmd.GroupID = "build"
+}
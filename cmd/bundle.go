@@ -1,28 +1,38 @@
 package cmd
 
 import (
-	"github.com/joeblew999/goup-util/pkg/utils"
+	"errors"
 	"fmt"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/utils"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/buildnumber"
 	"github.com/joeblew999/goup-util/pkg/constants"
 	"github.com/joeblew999/goup-util/pkg/packaging"
+	"github.com/joeblew999/goup-util/pkg/permissions"
 	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/joeblew999/goup-util/pkg/sbom"
+	"github.com/joeblew999/goup-util/pkg/secrets"
 	"github.com/spf13/cobra"
 )
 
 var bundleCmd = &cobra.Command{
 	Use:   "bundle [platform] [app-directory]",
-	Short: "Create signed app bundles for distribution",
-	Long: `Create properly signed and structured app bundles for distribution.
+	Short: i18n.T("Create signed app bundles for distribution"),
+	Long: i18n.T(`Create properly signed and structured app bundles for distribution.
 This includes:
 - macOS: .app bundle with Info.plist, code signing, and entitlements
+  (--app-store: sandboxed, validated for the Mac App Store, plus a signed .pkg)
 - Android: Signed APK (future)
 - iOS: Signed IPA (future)
 - Windows: Installer (future)
 
-This is different from 'package' which just creates archives of built apps.`,
+This is different from 'package' which just creates archives of built apps.`),
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		platform := args[0]
@@ -31,7 +41,7 @@ This is different from 'package' which just creates archives of built apps.`,
 		// Validate platform
 		validPlatforms := []string{"macos", "android", "ios", "windows"}
 		if !utils.Contains(validPlatforms, platform) {
-			return fmt.Errorf("invalid platform: %s. Valid platforms: %v", platform, validPlatforms)
+			return fmt.Errorf(i18n.T("invalid platform: %s. Valid platforms: %v"), platform, validPlatforms)
 		}
 
 		// Get flags
@@ -40,35 +50,116 @@ This is different from 'package' which just creates archives of built apps.`,
 		signingIdentity, _ := cmd.Flags().GetString("sign")
 		outputDir, _ := cmd.Flags().GetString("output")
 		entitlements, _ := cmd.Flags().GetBool("entitlements")
+		sbomFlag, _ := cmd.Flags().GetString("sbom")
+		sbomFormat, err := sbom.Resolve(sbomFlag)
+		if err != nil {
+			return err
+		}
+		started := time.Now()
 
 		// Create and validate project
 		proj, err := project.NewGioProject(appDir)
 		if err != nil {
-			return fmt.Errorf("failed to create project: %w", err)
+			return fmt.Errorf(i18n.T("failed to create project: %w"), err)
 		}
 
 		if err := proj.Validate(); err != nil {
-			return fmt.Errorf("invalid project: %w", err)
+			return fmt.Errorf(i18n.T("invalid project: %w"), err)
 		}
 
 		switch platform {
 		case "macos":
-			return bundleMacOS(proj, bundleID, version, signingIdentity, outputDir, entitlements)
+			var store appStoreOptions
+			store.Enabled, _ = cmd.Flags().GetBool("app-store")
+			store.ProvisioningProfile, _ = cmd.Flags().GetString("provisioning-profile")
+			store.InstallerIdentity, _ = cmd.Flags().GetString("installer-sign")
+			store.Category, _ = cmd.Flags().GetString("category")
+			if err := signingFromSecrets(&signingIdentity, "MACOS_SIGNING_IDENTITY"); err != nil {
+				return err
+			}
+			if store.Enabled {
+				if err := signingFromSecrets(&store.InstallerIdentity, "MACOS_INSTALLER_IDENTITY"); err != nil {
+					return err
+				}
+			}
+			buildNumber, _ := cmd.Flags().GetString("build-number")
+			buildRemote, _ := cmd.Flags().GetString("build-remote")
+			n, err := buildnumber.Resolve(buildnumber.Open(buildRemote, proj.RootDir), proj.Name, buildNumber)
+			if err != nil {
+				return fmt.Errorf(i18n.T("failed to get build number: %w"), err)
+			}
+			if err := bundleMacOS(proj, bundleID, version, n, signingIdentity, outputDir, entitlements, store); err != nil {
+				return err
+			}
+			if outputDir == "" {
+				outputDir = filepath.Join(proj.RootDir, constants.DistDir)
+			}
+			params := map[string]string{"version": version, "buildNumber": strconv.Itoa(n), "appStore": strconv.FormatBool(store.Enabled)}
+			artifact := filepath.Join(outputDir, proj.Name+".app")
+			if store.Enabled {
+				artifact = filepath.Join(outputDir, proj.Name+".pkg")
+			}
+			return writeSBOM(proj, platform, artifact, sbomFormat, params, started)
 		case "android":
-			return fmt.Errorf("android bundling not yet implemented")
+			return errors.New(i18n.T("android bundling not yet implemented"))
 		case "ios":
-			return fmt.Errorf("ios bundling not yet implemented")
+			return errors.New(i18n.T("ios bundling not yet implemented"))
 		case "windows":
 			publisher, _ := cmd.Flags().GetString("publisher")
 			createMSIX, _ := cmd.Flags().GetBool("create-msix")
-			return bundleWindows(proj, bundleID, version, publisher, outputDir, createMSIX)
+			var webView2 packaging.WebView2Bundle
+			webView2.Mode, _ = cmd.Flags().GetString("webview2")
+			webView2.Source, _ = cmd.Flags().GetString("webview2-source")
+			if err := bundleWindows(proj, bundleID, version, publisher, outputDir, createMSIX, webView2); err != nil {
+				return err
+			}
+			if sbomFormat == "" {
+				return nil
+			}
+			if outputDir == "" {
+				outputDir = filepath.Join(proj.RootDir, constants.DistDir)
+			}
+			if bundleID == "" {
+				bundleID = proj.Name
+			}
+			msixPath := filepath.Join(outputDir, bundleID+".msix")
+			if _, err := os.Stat(msixPath); err != nil {
+				fmt.Println("ℹ️  No .msix was created, so no SBOM was written (use --create-msix on Windows)")
+				return nil
+			}
+			return writeSBOM(proj, platform, msixPath, sbomFormat, map[string]string{"version": version}, started)
 		}
 
 		return nil
 	},
 }
 
-func bundleMacOS(proj *project.GioProject, bundleID, version, signingIdentity, outputDir string, useEntitlements bool) error {
+// signingFromSecrets fills an identity flag left empty from the named
+// secret (see 'goup-util secrets').
+func signingFromSecrets(flag *string, name string) error {
+	if *flag != "" {
+		return nil
+	}
+	value, err := secrets.Open("").Text(name)
+	if err != nil {
+		return err
+	}
+	if value != "" {
+		fmt.Printf("🔑 Using %s\n", name)
+		*flag = value
+	}
+	return nil
+}
+
+// appStoreOptions holds the --app-store bundle profile flags.
+type appStoreOptions struct {
+	Enabled             bool
+	ProvisioningProfile string
+	InstallerIdentity   string
+	Category            string
+}
+
+func bundleMacOS(proj *project.GioProject, bundleID, version string, buildNumber int, signingIdentity, outputDir string, useEntitlements bool, store appStoreOptions) error {
 	fmt.Printf("Creating macOS bundle for %s...\n", proj.Name)
 
 	// Set defaults
@@ -78,13 +169,17 @@ func bundleMacOS(proj *project.GioProject, bundleID, version, signingIdentity, o
 	if version == "" {
 		version = "1.0.0"
 	}
+	if buildNumber == 0 {
+		buildNumber = 1
+	}
 	if outputDir == "" {
 		outputDir = filepath.Join(proj.RootDir, constants.DistDir)
 	}
+	fmt.Printf("🔢 Version %s (%d)\n", version, buildNumber)
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 	}
 
 	// Find the built binary - check multiple locations
@@ -114,7 +209,7 @@ func bundleMacOS(proj *project.GioProject, bundleID, version, signingIdentity, o
 	} else if _, err := os.Stat(standaloneBinary); err == nil {
 		binaryPath = standaloneBinary
 	} else {
-		return fmt.Errorf("binary not found in:\n  %s\n  %s\n  %s\nRun 'goup-util build macos %s' first",
+		return fmt.Errorf(i18n.T("binary not found in:\n  %s\n  %s\n  %s\nRun 'goup-util build macos %s' first"),
 			platformBinaryInApp, legacyBinaryInApp, standaloneBinary, proj.RootDir)
 	}
 
@@ -133,26 +228,51 @@ func bundleMacOS(proj *project.GioProject, bundleID, version, signingIdentity, o
 		}
 	}
 
+	// Privacy usage descriptions and export compliance from app.json
+	appCfg := appconfig.LoadOrDefault(proj.RootDir)
+	usage, err := permissions.UsageDescriptions(appCfg.Permissions)
+	if err != nil {
+		return fmt.Errorf(i18n.T("invalid %s: %w"), appconfig.ConfigFileName, err)
+	}
+
 	// Create bundle config
 	config := packaging.MacOSBundleConfig{
 		Name:            proj.Name,
 		DisplayName:     toDisplayName(proj.Name),
 		BundleID:        bundleID,
 		Version:         version,
-		BuildNumber:     "1",
+		BuildNumber:     strconv.Itoa(buildNumber),
 		BinaryPath:      binaryPath,
 		OutputDir:       outputDir,
 		IconPath:        iconPath,
 		SigningIdentity: signingIdentity,
 		Entitlements:    useEntitlements,
+
+		UsageDescriptions: usage,
+
+		AppStore:            store.Enabled,
+		ProvisioningProfile: store.ProvisioningProfile,
+		InstallerIdentity:   store.InstallerIdentity,
+		Category:            store.Category,
+	}
+	if ec := appCfg.ExportCompliance; ec != nil {
+		config.UsesNonExemptEncryption = &ec.UsesNonExemptEncryption
+		config.EncryptionComplianceCode = ec.ComplianceCode
 	}
 
 	// Create the bundle
 	if err := packaging.CreateMacOSBundle(config); err != nil {
-		return fmt.Errorf("failed to create bundle: %w", err)
+		return fmt.Errorf(i18n.T("failed to create bundle: %w"), err)
 	}
 
 	fmt.Println()
+	if store.Enabled {
+		fmt.Println("🎯 Next steps:")
+		fmt.Println("   1. Upload", filepath.Join(outputDir, proj.Name+".pkg"), "with Transporter or:")
+		fmt.Println("      xcrun altool --upload-app -t macos -f <pkg> --apiKey <key> --apiIssuer <issuer>")
+		fmt.Println("   2. Submit the build for review in App Store Connect")
+		return nil
+	}
 	fmt.Println("🎯 Next steps:")
 	fmt.Println("   1. Test the app: open", filepath.Join(outputDir, proj.Name+".app"))
 	fmt.Println("   2. Grant permissions if needed (System Settings → Privacy & Security)")
@@ -163,7 +283,7 @@ func bundleMacOS(proj *project.GioProject, bundleID, version, signingIdentity, o
 	return nil
 }
 
-func bundleWindows(proj *project.GioProject, bundleID, version, publisher, outputDir string, createMSIX bool) error {
+func bundleWindows(proj *project.GioProject, bundleID, version, publisher, outputDir string, createMSIX bool, webView2 packaging.WebView2Bundle) error {
 	fmt.Printf("Creating Windows bundle for %s...\n", proj.Name)
 
 	// Set defaults
@@ -182,7 +302,7 @@ func bundleWindows(proj *project.GioProject, bundleID, version, publisher, outpu
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 	}
 
 	// Find the built binary - check multiple locations
@@ -203,7 +323,7 @@ func bundleWindows(proj *project.GioProject, bundleID, version, publisher, outpu
 		binaryPath = legacyBinary
 		fmt.Println("ℹ️  Found binary in .bin/")
 	} else {
-		return fmt.Errorf("binary not found in:\n  %s\n  %s\nRun 'goup-util build windows %s' first",
+		return fmt.Errorf(i18n.T("binary not found in:\n  %s\n  %s\nRun 'goup-util build windows %s' first"),
 			platformBinary, legacyBinary, proj.RootDir)
 	}
 
@@ -213,6 +333,37 @@ func bundleWindows(proj *project.GioProject, bundleID, version, publisher, outpu
 		assetsDir = "" // Will generate placeholders
 	}
 
+	// Signing certificate from secrets. signtool needs a real path, so only
+	// WINDOWS_CERTIFICATE_FILE is used; the password can come from anywhere.
+	var cert, certPassword string
+	if createMSIX {
+		store := secrets.Open("")
+		s, err := store.Get("WINDOWS_CERTIFICATE")
+		if err != nil {
+			return err
+		}
+		if s != nil && s.Path == "" {
+			fmt.Println("⚠️  WINDOWS_CERTIFICATE must be given as WINDOWS_CERTIFICATE_FILE for signtool; MSIX will be unsigned")
+		} else if s != nil {
+			cert = s.Path
+			if certPassword, err = store.Text("WINDOWS_CERTIFICATE_PASSWORD"); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Device capabilities for the permissions declared in app.json
+	appCfg := appconfig.LoadOrDefault(proj.RootDir)
+	if _, err := permissions.UsageDescriptions(appCfg.Permissions); err != nil {
+		return fmt.Errorf(i18n.T("invalid %s: %w"), appconfig.ConfigFileName, err)
+	}
+	var capabilities []string
+	for _, p := range permissions.All {
+		if _, ok := appCfg.Permissions[string(p)]; ok && permissions.MSIXCapability(p) != "" {
+			capabilities = append(capabilities, permissions.MSIXCapability(p))
+		}
+	}
+
 	// Create bundle config
 	config := packaging.WindowsBundleConfig{
 		Name:                 bundleID,
@@ -224,12 +375,17 @@ func bundleWindows(proj *project.GioProject, bundleID, version, publisher, outpu
 		BinaryPath:           binaryPath,
 		OutputDir:            outputDir,
 		AssetsDir:            assetsDir,
+		DeviceCapabilities:   capabilities,
+		StartupTask:          appCfg.StartAtLogin,
 		CreateMSIX:           createMSIX,
+		SigningCertificate:   cert,
+		CertificatePassword:  certPassword,
+		WebView2:             webView2,
 	}
 
 	// Create the bundle
 	if err := packaging.CreateWindowsBundle(config); err != nil {
-		return fmt.Errorf("failed to create bundle: %w", err)
+		return fmt.Errorf(i18n.T("failed to create bundle: %w"), err)
 	}
 
 	fmt.Println()
@@ -254,13 +410,23 @@ func toDisplayName(name string) string {
 }
 
 func init() {
-	bundleCmd.Flags().String("bundle-id", "", "Bundle identifier (e.g., com.example.myapp)")
-	bundleCmd.Flags().String("version", "1.0.0", "Version string")
-	bundleCmd.Flags().String("sign", "", "Code signing identity (empty for auto-detect)")
-	bundleCmd.Flags().String("output", "", "Output directory (default: .dist/)")
-	bundleCmd.Flags().Bool("entitlements", true, "Use entitlements for hardened runtime (macOS)")
-	bundleCmd.Flags().String("publisher", "", "Publisher for Windows MSIX (e.g., CN=MyCompany)")
-	bundleCmd.Flags().Bool("create-msix", false, "Create MSIX package (Windows-only, requires msix toolkit)")
+	bundleCmd.Flags().String("bundle-id", "", i18n.T("Bundle identifier (e.g., com.example.myapp)"))
+	bundleCmd.Flags().String("version", "1.0.0", i18n.T("Version string"))
+	bundleCmd.Flags().String("sign", "", i18n.T("Code signing identity (default $MACOS_SIGNING_IDENTITY secret, else auto-detect)"))
+	bundleCmd.Flags().String("output", "", i18n.T("Output directory (default: .dist/)"))
+	bundleCmd.Flags().Bool("entitlements", true, i18n.T("Use entitlements for hardened runtime (macOS)"))
+	bundleCmd.Flags().String("publisher", "", i18n.T("Publisher for Windows MSIX (e.g., CN=MyCompany)"))
+	bundleCmd.Flags().Bool("create-msix", false, i18n.T("Create MSIX package (Windows-only, requires msix toolkit)"))
+	bundleCmd.Flags().String("webview2", "", i18n.T("Ship the WebView2 runtime: 'bootstrapper' (installed on first launch if missing) or 'fixed' (Windows)"))
+	bundleCmd.Flags().String("webview2-source", "", i18n.T("Bootstrapper .exe to ship (default: downloaded), or the fixed-version runtime folder (Windows)"))
+	bundleCmd.Flags().Bool("app-store", false, i18n.T("Mac App Store profile: sandbox entitlements, validation and a signed .pkg (macOS)"))
+	bundleCmd.Flags().String("provisioning-profile", "", i18n.T("Mac App Store .provisionprofile to embed (with --app-store)"))
+	bundleCmd.Flags().String("installer-sign", "", i18n.T("Installer identity for the .pkg, e.g. \"3rd Party Mac Developer Installer: ...\" (with --app-store)"))
+	bundleCmd.Flags().String("build-number", buildnumber.Auto, i18n.T("CFBundleVersion: 'auto' for the next number, or an explicit number (macOS)"))
+	bundleCmd.Flags().String("sbom", "", "Write an SBOM (cyclonedx or spdx) and SLSA provenance next to the bundle (default $"+sbom.FormatEnv+")")
+	bundleCmd.Flags().Lookup("sbom").NoOptDefVal = sbom.CycloneDX
+	bundleCmd.Flags().String("build-remote", "", "Git remote holding build number tags (default $"+buildnumber.RemoteEnv+", else local)")
+	bundleCmd.Flags().String("category", "", i18n.T("LSApplicationCategoryType (default public.app-category.utilities with --app-store)"))
 
 	// Group for help organization
 	bundleCmd.GroupID = "build"
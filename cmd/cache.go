@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/joeblew999/goup-util/pkg/dlcache"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: i18n.T("Manage the SDK download cache"),
+	Long: i18n.T(`SDK archives are kept in a content-addressed cache under the cache
+directory, so reinstalling an SDK, or installing it for another project
+with GOUP_SDK_DIR, does not download it again. Each archive is checked
+against its SHA-256 before it is reused.
+
+Carry the cache to other machines with 'goup-util export-bundle --downloads'.`),
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("List cached downloads, least recently used first"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store := dlcache.Open()
+		entries, err := store.Entries()
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to read download cache: %w"), err)
+		}
+		if len(entries) == 0 {
+			fmt.Printf("No cached downloads in %s\n", store.Dir)
+			return nil
+		}
+
+		var total int64
+		for _, e := range entries {
+			name := "(unknown URL)"
+			if len(e.URLs) > 0 {
+				name = path.Base(e.URLs[0])
+			}
+			fmt.Printf("  %s  %10s  %s  %s\n", e.Sum[:12], formatBytes(e.Size), e.Used.Format("2006-01-02"), name)
+			total += e.Size
+		}
+		fmt.Printf("\n📦 %d downloads, %s in %s\n", len(entries), formatBytes(total), store.Dir)
+		return nil
+	},
+}
+
+var cacheGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: i18n.T("Remove least recently used downloads above a size limit"),
+	Example: `  goup-util cache gc --max-size 10G
+  goup-util cache gc --max-size 0     # empty the download cache`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, _ := cmd.Flags().GetString("max-size")
+		maxSize, err := dlcache.ParseSize(value)
+		if err != nil {
+			return err
+		}
+
+		removed, err := dlcache.Open().GC(maxSize)
+		var freed int64
+		for _, e := range removed {
+			freed += e.Size
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to clean download cache: %w"), err)
+		}
+		if len(removed) == 0 {
+			fmt.Printf("✓ Download cache is within %s\n", formatBytes(maxSize))
+			return nil
+		}
+		fmt.Printf("✅ Removed %d downloads, freed %s\n", len(removed), formatBytes(freed))
+		return nil
+	},
+}
+
+func init() {
+	cacheGCCmd.Flags().String("max-size", "10G", i18n.T("Size to shrink the download cache to (e.g. 10G, 500M)"))
+
+	cacheCmd.AddCommand(cacheListCmd, cacheGCCmd)
+	cacheCmd.GroupID = "sdk"
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/changed"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+var changedCmd = &cobra.Command{
+	Use:   "changed",
+	Short: i18n.T("List the apps and platforms affected by changes since a git ref"),
+	Long: i18n.T(`List the apps and platforms to rebuild after the changes since a git
+ref, so CI builds only what a commit or pull request touches.
+
+Changed files are those between the merge base of --since and HEAD and the
+working tree, untracked files included. Each file belongs to the innermost
+module containing it (the examples are separate modules). A module is also
+affected when it requires, or replaces with a local directory, an affected
+module. Go files with a GOOS suffix (_android.go, _darwin.go, _windows.go)
+affect only the platforms built from them; Markdown files and tests affect
+nothing.
+
+--matrix prints a GitHub Actions matrix with one entry per app and platform.`),
+	Example: `  goup-util changed --since origin/main
+  goup-util changed --since v1.4.0 --platforms macos,ios,android --json
+  goup-util changed --since origin/main --under examples --matrix`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, _ := cmd.Flags().GetString("since")
+		platforms, _ := cmd.Flags().GetStringSlice("platforms")
+		under, _ := cmd.Flags().GetString("under")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		matrix, _ := cmd.Flags().GetBool("matrix")
+		for _, p := range platforms {
+			if !schema.ValidPlatform(p) {
+				return fmt.Errorf(i18n.T("unknown platform %q (one of %s)"), p, strings.Join(schema.Platforms, ", "))
+			}
+		}
+
+		r, err := changed.Since(".", since, platforms)
+		if err != nil {
+			return err
+		}
+		if under != "" {
+			under = path.Clean(strings.TrimPrefix(filepath.ToSlash(under), "./"))
+			var apps []changed.App
+			for _, a := range r.Apps {
+				if a.Dir == under || strings.HasPrefix(a.Dir, under+"/") {
+					apps = append(apps, a)
+				}
+			}
+			r.Apps = apps
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		switch {
+		case matrix:
+			type entry struct {
+				App      string `json:"app"`
+				Dir      string `json:"dir"`
+				Platform string `json:"platform"`
+			}
+			include := []entry{}
+			for _, a := range r.Apps {
+				for _, p := range a.Platforms {
+					include = append(include, entry{a.Name, a.Dir, p})
+				}
+			}
+			return enc.Encode(map[string]any{"include": include})
+		case asJSON:
+			enc.SetIndent("", "  ")
+			return enc.Encode(r)
+		}
+
+		fmt.Printf("Since %s (%s): %d files changed\n", since, r.Base[:min(12, len(r.Base))], len(r.Files))
+		if len(r.Apps) == 0 {
+			fmt.Println("✓ No apps to rebuild")
+			return nil
+		}
+		for _, a := range r.Apps {
+			fmt.Printf("  → %s: %s (%s)\n", a.Dir, strings.Join(a.Platforms, ", "), a.Reason)
+		}
+		return nil
+	},
+}
+
+func init() {
+	changedCmd.Flags().String("since", "", i18n.T("Git ref to compare with (branch, tag or commit)"))
+	changedCmd.Flags().StringSlice("platforms", schema.Platforms, i18n.T("Platforms to report"))
+	changedCmd.Flags().String("under", "", i18n.T("Only report apps in this directory (relative to the repository)"))
+	changedCmd.Flags().Bool("json", false, i18n.T("Print the files, modules and apps as JSON"))
+	changedCmd.Flags().Bool("matrix", false, i18n.T("Print a GitHub Actions matrix of app and platform"))
+	changedCmd.MarkFlagRequired("since")
+
+	changedCmd.GroupID = "build"
+	rootCmd.AddCommand(changedCmd)
+}
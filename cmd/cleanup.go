@@ -5,19 +5,20 @@ import (
 	"os"
 
 	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 var cleanupCmd = &cobra.Command{
 	Use:   "cleanup",
-	Short: "Clean up goup-util data",
-	Long:  `Clean up various goup-util data directories`,
+	Short: i18n.T("Clean up goup-util data"),
+	Long:  i18n.T(`Clean up various goup-util data directories`),
 }
 
 var cleanupAllCmd = &cobra.Command{
 	Use:   "all",
-	Short: "Remove ALL SDKs and cache (DESTRUCTIVE)",
-	Long:  `WARNING: This will remove ALL installed SDKs and cache files. This is a destructive operation that cannot be undone.`,
+	Short: i18n.T("Remove ALL SDKs and cache (DESTRUCTIVE)"),
+	Long:  i18n.T(`WARNING: This will remove ALL installed SDKs and cache files. This is a destructive operation that cannot be undone.`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sdkDir := config.GetSDKDir()
 		cacheDir := config.GetCacheDir()
@@ -38,7 +39,7 @@ var cleanupAllCmd = &cobra.Command{
 		fmt.Printf("Removing cache directory: %s\n", cacheDir)
 
 		if err := config.CleanDirectories(); err != nil {
-			return fmt.Errorf("cleanup failed: %w", err)
+			return fmt.Errorf(i18n.T("cleanup failed: %w"), err)
 		}
 
 		fmt.Println("✓ Complete cleanup finished.")
@@ -48,15 +49,15 @@ var cleanupAllCmd = &cobra.Command{
 
 var cleanupCacheCmd = &cobra.Command{
 	Use:   "cache-only",
-	Short: "Remove only cache files (keeps SDKs)",
-	Long:  `Removes only the cache directory, keeping all installed SDKs intact.`,
+	Short: i18n.T("Remove only cache files (keeps SDKs)"),
+	Long:  i18n.T(`Removes only the cache directory, keeping all installed SDKs intact.`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cacheDir := config.GetCacheDir()
 		fmt.Printf("Removing cache directory: %s\n", cacheDir)
 
 		if _, err := os.Stat(cacheDir); err == nil {
 			if err := os.RemoveAll(cacheDir); err != nil {
-				return fmt.Errorf("failed to remove cache directory: %w", err)
+				return fmt.Errorf(i18n.T("failed to remove cache directory: %w"), err)
 			}
 		}
 
@@ -67,8 +68,8 @@ var cleanupCacheCmd = &cobra.Command{
 
 var cleanupSDKsCmd = &cobra.Command{
 	Use:   "sdks-only",
-	Short: "Remove only SDKs (keeps cache)",
-	Long:  `WARNING: Removes only the SDK directory, keeping cache files.`,
+	Short: i18n.T("Remove only SDKs (keeps cache)"),
+	Long:  i18n.T(`WARNING: Removes only the SDK directory, keeping cache files.`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sdkDir := config.GetSDKDir()
 
@@ -86,7 +87,7 @@ var cleanupSDKsCmd = &cobra.Command{
 
 		if _, err := os.Stat(sdkDir); err == nil {
 			if err := os.RemoveAll(sdkDir); err != nil {
-				return fmt.Errorf("failed to remove SDK directory: %w", err)
+				return fmt.Errorf(i18n.T("failed to remove SDK directory: %w"), err)
 			}
 		}
 
@@ -105,8 +106,8 @@ func init() {
 	// Keep the old command for backward compatibility but mark as deprecated
 	var deprecatedCleanupCacheCmd = &cobra.Command{
 		Use:        "cleanup-cache",
-		Short:      "Remove all downloaded SDKs and the cache",
-		Long:       `Removes the SDK directory and the cache directory.`,
+		Short:      i18n.T("Remove all downloaded SDKs and the cache"),
+		Long:       i18n.T(`Removes the SDK directory and the cache directory.`),
 		Deprecated: "Use 'cleanup all' instead",
 		Hidden:     true,
 		RunE: func(cmd *cobra.Command, args []string) error {
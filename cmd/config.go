@@ -5,13 +5,14 @@ import (
 	"os"
 
 	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 var configCmd = &cobra.Command{
 	Use:   "config",
-	Short: "Show configuration and directory information",
-	Long:  "Display configuration details including SDK installation paths, cache locations, and current setup.",
+	Short: i18n.T("Show configuration and directory information"),
+	Long:  i18n.T("Display configuration details including SDK installation paths, cache locations, and current setup."),
 	Run:   runConfig,
 }
 
@@ -24,16 +25,16 @@ func runConfig(cmd *cobra.Command, args []string) {
 
 	fmt.Println("=== goup-util Configuration ===")
 	fmt.Println()
-	
+
 	fmt.Println("📁 Directory Locations:")
 	fmt.Printf("  Cache Directory: %s\n", info.CacheDir)
 	fmt.Printf("  SDK Directory:   %s\n", info.SDKDir)
 	fmt.Println()
-	
+
 	fmt.Println("📊 Directory Status:")
 	fmt.Printf("  Cache exists: %t\n", info.CacheExists)
 	fmt.Printf("  SDKs exist:   %t\n", info.SDKExists)
-	
+
 	if info.CacheSize > 0 {
 		fmt.Printf("  Cache size:   %s\n", formatBytes(info.CacheSize))
 	}
@@ -41,17 +42,17 @@ func runConfig(cmd *cobra.Command, args []string) {
 		fmt.Printf("  SDKs size:    %s\n", formatBytes(info.SDKSize))
 	}
 	fmt.Println()
-	
+
 	fmt.Println("🔧 Platform Information:")
 	fmt.Printf("  OS:           %s\n", os.Getenv("GOOS"))
 	fmt.Printf("  Architecture: %s\n", os.Getenv("GOARCH"))
 	fmt.Println()
-	
+
 	// Show actual directory contents if they exist
 	fmt.Println("📂 Current Contents:")
 	showDirectoryContents(info.SDKDir, "SDKs")
 	showDirectoryContents(info.CacheDir, "Cache")
-	
+
 	// Show environment variables that might be relevant
 	fmt.Println("🌍 Relevant Environment Variables:")
 	showEnvVars := []string{"JAVA_HOME", "ANDROID_HOME", "ANDROID_SDK_ROOT", "XCODE_PATH"}
@@ -69,18 +70,18 @@ func showDirectoryContents(path, label string) {
 		fmt.Printf("  %s: Directory does not exist\n", label)
 		return
 	}
-	
+
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		fmt.Printf("  %s: Error reading directory: %v\n", label, err)
 		return
 	}
-	
+
 	if len(entries) == 0 {
 		fmt.Printf("  %s: Directory is empty\n", label)
 		return
 	}
-	
+
 	fmt.Printf("  %s:\n", label)
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -102,4 +103,4 @@ func formatBytes(bytes int64) string {
 		exp++
 	}
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
\ No newline at end of file
+}
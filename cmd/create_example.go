@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/service"
 	"github.com/spf13/cobra"
 )
@@ -11,11 +12,11 @@ import (
 // createExampleCmd represents the create-example command
 var createExampleCmd = &cobra.Command{
 	Use:   "create-example <name>",
-	Short: "Create a new example project",
-	Long: `Create a new example project with proper structure and optionally add it to the workspace.
+	Short: i18n.T("Create a new example project"),
+	Long: i18n.T(`Create a new example project with proper structure and optionally add it to the workspace.
 
 This command creates a new example in the examples/ directory and can automatically
-update the go.work file to include the new module.`,
+update the go.work file to include the new module.`),
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		exampleName := args[0]
@@ -51,10 +52,10 @@ update the go.work file to include the new module.`,
 // ensureWorkspaceCmd ensures a project is in the workspace
 var ensureWorkspaceCmd = &cobra.Command{
 	Use:   "ensure-workspace <module-path>",
-	Short: "Ensure a module is included in the workspace",
-	Long: `Ensure that a specific module is included in the Go workspace.
+	Short: i18n.T("Ensure a module is included in the workspace"),
+	Long: i18n.T(`Ensure that a specific module is included in the Go workspace.
 
-The module path should be relative to the workspace root.`,
+The module path should be relative to the workspace root.`),
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		modulePath := args[0]
@@ -90,9 +91,9 @@ func init() {
 	rootCmd.AddCommand(ensureWorkspaceCmd)
 
 	// Flags for create-example
-	createExampleCmd.Flags().BoolP("workspace", "w", false, "Update workspace to include new example")
-	createExampleCmd.Flags().BoolP("force", "f", false, "Force workspace update without confirmation")
+	createExampleCmd.Flags().BoolP("workspace", "w", false, i18n.T("Update workspace to include new example"))
+	createExampleCmd.Flags().BoolP("force", "f", false, i18n.T("Force workspace update without confirmation"))
 
 	// Flags for ensure-workspace
-	ensureWorkspaceCmd.Flags().BoolP("force", "f", false, "Force addition to workspace")
+	ensureWorkspaceCmd.Flags().BoolP("force", "f", false, i18n.T("Force addition to workspace"))
 }
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/daemon"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// DaemonTokenEnv is the default variable holding the daemon's bearer token.
+const DaemonTokenEnv = "GOUP_DAEMON_TOKEN"
+
+// DaemonAddr is the daemon's default listen address.
+const DaemonAddr = "127.0.0.1:8765"
+
+var (
+	daemonAddr      string
+	daemonDir       string
+	daemonJobs      int
+	daemonMaxQueued int
+	daemonKeep      time.Duration
+	daemonTokenEnv  string
+	daemonStatus    string
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: i18n.T("Run goup-util as a build service with a persistent job queue"),
+	Long: `Run goup-util as a build service for remote builders of type "daemon".
+
+Clients submit jobs over HTTP; a fixed number of workers run them one
+'goup-util build' at a time each, in a separate directory per job. Jobs,
+their status and logs are kept in a SQLite database in the data directory,
+so a restarted daemon requeues interrupted jobs and clients can still fetch
+earlier artifacts.
+
+Set $` + DaemonTokenEnv + ` (or the variable named by --token-env) to require
+a bearer token; clients send it from their builder's tokenEnv. The daemon
+listens on ` + DaemonAddr + ` and refuses any other address until the
+token is set, since whoever can submit a job runs code on this machine.
+
+API:
+  GET    /api/health
+  POST   /api/jobs                 multipart: args, artifactDir, source (tar.gz)
+  GET    /api/jobs                 list (?status=queued&limit=20)
+  GET    /api/jobs/{id}            status
+  GET    /api/jobs/{id}/log        build output
+  GET    /api/jobs/{id}/artifacts  tar.gz of artifactDir
+  DELETE /api/jobs/{id}            cancel
+
+Examples:
+  goup-util daemon --jobs 2
+  GOUP_DAEMON_TOKEN=... goup-util daemon --addr :8765
+  goup-util daemon jobs --status failed`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token := os.Getenv(daemonTokenEnv)
+		if token == "" && !isLoopbackAddr(daemonAddr) {
+			return fmt.Errorf(i18n.T("refusing to listen on %s without a token; set $%s or use a loopback address"), daemonAddr, daemonTokenEnv)
+		}
+		srv, err := daemon.NewServer(daemon.Options{
+			Dir:         daemonDir,
+			Concurrency: daemonJobs,
+			MaxQueued:   daemonMaxQueued,
+			Retention:   daemonKeep,
+			Token:       token,
+		})
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		httpSrv := &http.Server{Addr: daemonAddr, Handler: srv.Handler()}
+		errc := make(chan error, 1)
+		go func() { errc <- httpSrv.ListenAndServe() }()
+
+		fmt.Printf("🛰️  goup-util daemon on %s (%d worker(s), data in %s)\n", daemonAddr, daemonJobs, daemonDir)
+		if token == "" {
+			fmt.Printf("⚠️  $%s is not set; any local user or process can submit builds\n", daemonTokenEnv)
+		}
+
+		workers := make(chan struct{})
+		go func() {
+			srv.Run(ctx)
+			close(workers)
+		}()
+
+		select {
+		case err := <-errc:
+			stop()
+			<-workers
+			return fmt.Errorf(i18n.T("daemon failed: %w"), err)
+		case <-ctx.Done():
+		}
+		log.Println("shutting down; running jobs are requeued on the next start")
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpSrv.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		<-workers
+		return nil
+	},
+}
+
+// isLoopbackAddr reports whether a listen address only accepts connections
+// from this machine. An empty host listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+var daemonJobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: i18n.T("List jobs in the daemon's queue and history"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := daemon.OpenStore(filepath.Join(daemonDir, "jobs.db"))
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		jobs, err := store.List(daemonStatus, 50)
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			fmt.Println("No jobs")
+			return nil
+		}
+		for _, j := range jobs {
+			took := ""
+			if !j.Finished.IsZero() && !j.Started.IsZero() {
+				took = j.Finished.Sub(j.Started).Round(time.Second).String()
+			}
+			fmt.Printf("%-24s %-10s %-8s %s %v\n", j.ID, j.Status, took, j.Created.Format("2006-01-02 15:04"), j.Args)
+			if j.Error != "" {
+				fmt.Printf("    %s\n", j.Error)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	daemonCmd.PersistentFlags().StringVar(&daemonDir, "dir", filepath.Join(config.GetCacheDir(), "daemon"), i18n.T("Data directory (job database, sources and logs)"))
+	daemonCmd.Flags().StringVar(&daemonAddr, "addr", DaemonAddr, i18n.T("Listen address (other than loopback only with a token)"))
+	daemonCmd.Flags().IntVar(&daemonJobs, "jobs", 1, i18n.T("Builds run at the same time"))
+	daemonCmd.Flags().IntVar(&daemonMaxQueued, "max-queued", 50, i18n.T("Queued jobs before new submissions are refused (0 = no limit)"))
+	daemonCmd.Flags().DurationVar(&daemonKeep, "keep", 7*24*time.Hour, i18n.T("Delete finished jobs and their files after this long (0 = keep)"))
+	daemonCmd.Flags().StringVar(&daemonTokenEnv, "token-env", DaemonTokenEnv, i18n.T("Environment variable holding the bearer token"))
+	daemonJobsCmd.Flags().StringVar(&daemonStatus, "status", "", i18n.T("Only jobs in this state (queued, running, succeeded, failed, canceled)"))
+
+	daemonCmd.AddCommand(daemonJobsCmd)
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.GroupID = "build"
+}
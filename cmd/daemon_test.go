@@ -0,0 +1,25 @@
+package cmd
+
+import "testing"
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:8765", true},
+		{"localhost:8765", true},
+		{"[::1]:8765", true},
+		{":8765", false},
+		{"0.0.0.0:8765", false},
+		{"[::]:8765", false},
+		{"192.168.1.10:8765", false},
+		{"build.example.com:8765", false},
+		{"8765", false},
+	}
+	for _, tt := range tests {
+		if got := isLoopbackAddr(tt.addr); got != tt.want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
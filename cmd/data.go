@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/localdb"
+	"github.com/joeblew999/goup-util/pkg/localsync"
+	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/spf13/cobra"
+)
+
+// SyncTokenEnv holds the token sync clients must send to the sync server.
+const SyncTokenEnv = "GOUP_SYNC_TOKEN"
+
+var (
+	dataSyncAddr string
+	dataSyncDB   string
+
+	dataApp   string
+	dataTable string
+	dataSQL   string
+	dataLimit int
+	dataJSON  bool
+)
+
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: i18n.T("Debug the local SQLite data of hybrid apps"),
+	Long: i18n.T(`Debug the SQLite databases hybrid apps keep with pkg/localdb, and run
+the reference server for apps that sync them with pkg/localsync.
+
+An app's database is data.db in its config directory, named after the app
+(the project directory's name).`),
+}
+
+var dataInspectCmd = &cobra.Command{
+	Use:   "inspect [app-directory | database-file]",
+	Short: i18n.T("Show an app's tables, rows and schema version"),
+	Long: i18n.T(`Show the tables, row counts and schema version of an app's local
+database, or the rows of one table, or the result of a query. The database
+is opened read-only, so it is safe to inspect while the app is running.
+
+Examples:
+  goup-util data inspect examples/hybrid-dashboard
+  goup-util data inspect --app hybrid-dashboard --table notes
+  goup-util data inspect --app hybrid-dashboard --sql "SELECT count(*) FROM notes WHERE done"
+  goup-util data inspect ./data.db --table notes --json`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := dataPath(args)
+		if err != nil {
+			return err
+		}
+		db, err := localdb.OpenReadOnly(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf(i18n.T("no database at %s (has the app run yet?)"), path)
+			}
+			return err
+		}
+		defer db.Close()
+
+		query := dataSQL
+		if dataTable != "" {
+			if query != "" {
+				return errors.New(i18n.T("use --table or --sql, not both"))
+			}
+			query = `SELECT * FROM "` + strings.ReplaceAll(dataTable, `"`, `""`) + `"`
+		}
+		if query != "" {
+			if dataLimit > 0 && dataSQL == "" {
+				query += fmt.Sprintf(" LIMIT %d", dataLimit)
+			}
+			if dataJSON {
+				rows, err := db.QueryMaps(query)
+				if err != nil {
+					return err
+				}
+				return writeJSONOut(rows)
+			}
+			cols, rows, err := db.QueryRows(query)
+			if err != nil {
+				return err
+			}
+			printDataRows(cols, rows)
+			return nil
+		}
+
+		version, err := db.Version()
+		if err != nil {
+			return err
+		}
+		tables, err := db.Tables()
+		if err != nil {
+			return err
+		}
+		if dataJSON {
+			return writeJSONOut(map[string]any{"path": path, "version": version, "tables": tables})
+		}
+		var size int64
+		if info, err := os.Stat(path); err == nil {
+			size = info.Size()
+		}
+		fmt.Printf("📦 %s (%s, schema version %d)\n", path, formatBytes(size), version)
+		if len(tables) == 0 {
+			fmt.Println("No tables yet")
+			return nil
+		}
+		for _, t := range tables {
+			fmt.Printf("  %-24s %8d rows  %s\n", t.Name, t.Rows, strings.Join(t.Columns, ", "))
+		}
+		return nil
+	},
+}
+
+var dataSyncServerCmd = &cobra.Command{
+	Use:   "sync-server",
+	Short: i18n.T("Run the reference sync server for local-first apps"),
+	Long: `Run the server pkg/localsync clients push their changes to and pull
+other devices' changes from. Rows resolve last writer wins; the server
+keeps the winning version of each row in a SQLite database and needs no
+knowledge of the apps' tables.
+
+Clients post to /sync. Set $` + SyncTokenEnv + ` to require a bearer token, and put
+the server behind HTTPS before exposing it.
+
+Examples:
+  goup-util data sync-server
+  ` + SyncTokenEnv + `=s3cret goup-util data sync-server --addr :8090 --db /srv/sync.db`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		srv, err := localsync.OpenServer(dataSyncDB)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+		srv.Token = os.Getenv(SyncTokenEnv)
+		rows, err := srv.Rows()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		mux := http.NewServeMux()
+		mux.Handle("/sync", srv)
+		httpSrv := &http.Server{Addr: dataSyncAddr, Handler: mux}
+		errc := make(chan error, 1)
+		go func() { errc <- httpSrv.ListenAndServe() }()
+
+		fmt.Printf("🔄 Sync server on %s/sync, %d row(s) in %s\n", dataSyncAddr, rows, dataSyncDB)
+		if srv.Token == "" {
+			fmt.Printf("⚠️  $%s is not set; anyone who can reach %s can read and write the data\n", SyncTokenEnv, dataSyncAddr)
+		}
+
+		select {
+		case err := <-errc:
+			return fmt.Errorf(i18n.T("sync server failed: %w"), err)
+		case <-ctx.Done():
+		}
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpSrv.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// dataPath resolves the database from a file, a project directory or --app.
+func dataPath(args []string) (string, error) {
+	if len(args) == 0 {
+		if dataApp == "" {
+			return "", errors.New(i18n.T("give an app directory, a database file or --app"))
+		}
+		return localdb.AppPath(dataApp)
+	}
+	if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+		proj, err := project.NewGioProject(args[0])
+		if err != nil {
+			return "", err
+		}
+		return localdb.AppPath(proj.Name)
+	}
+	return args[0], nil
+}
+
+// printDataRows prints query results one tab-separated row per line.
+func printDataRows(cols []string, rows [][]any) {
+	if len(rows) == 0 {
+		fmt.Println("No rows")
+		return
+	}
+	fmt.Println(strings.Join(cols, "\t"))
+	for _, row := range rows {
+		vals := make([]string, len(row))
+		for i, v := range row {
+			switch v := v.(type) {
+			case nil:
+				vals[i] = "NULL"
+			case []byte:
+				vals[i] = fmt.Sprintf("<%d bytes>", len(v))
+			default:
+				vals[i] = fmt.Sprint(v)
+			}
+		}
+		fmt.Println(strings.Join(vals, "\t"))
+	}
+}
+
+func init() {
+	dataInspectCmd.Flags().StringVar(&dataApp, "app", "", i18n.T("App name, to find its database in the config directory"))
+	dataInspectCmd.Flags().StringVar(&dataTable, "table", "", i18n.T("Print the rows of this table"))
+	dataInspectCmd.Flags().StringVar(&dataSQL, "sql", "", i18n.T("Run a read-only query and print the result"))
+	dataInspectCmd.Flags().IntVar(&dataLimit, "limit", 50, i18n.T("Maximum rows to print with --table (0 = all)"))
+	dataInspectCmd.Flags().BoolVar(&dataJSON, "json", false, i18n.T("Print as JSON"))
+
+	dataSyncServerCmd.Flags().StringVar(&dataSyncAddr, "addr", ":8090", i18n.T("Listen address"))
+	dataSyncServerCmd.Flags().StringVar(&dataSyncDB, "db", "sync.db", i18n.T("Server database file"))
+
+	dataCmd.AddCommand(dataInspectCmd)
+	dataCmd.AddCommand(dataSyncServerCmd)
+	rootCmd.AddCommand(dataCmd)
+	dataCmd.GroupID = "tools"
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/joeblew999/goup-util/pkg/artifact"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffTop  int
+	diffJSON bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <artifact-a> <artifact-b>",
+	Short: i18n.T("Compare two build artifacts to explain size changes"),
+	Long: i18n.T(`Compare two APKs, IPAs, MSIX packages, .app bundles or .tar.gz/.zip
+packages and explain why a release grew:
+
+  - total size and size by section (lib/arm64-v8a, res, Contents/MacOS, ...)
+  - added, removed and resized files
+  - manifest changes (AndroidManifest.xml, Info.plist, AppxManifest.xml)
+  - size change of the Go binary by package
+
+Binaries built with -ldflags=-s only have function code attributed (from
+the Go pclntab); fully stripped binaries are reported as such.
+
+Examples:
+  goup-util diff old/myapp.apk examples/myapp/.bin/android/myapp.apk
+  goup-util diff v1.2.0/myapp.app .dist/myapp.app --top 30
+  goup-util diff a.msix b.msix --json`),
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := artifact.Open(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := artifact.Open(args[1])
+		if err != nil {
+			return err
+		}
+		if a.Kind != b.Kind {
+			fmt.Printf("⚠️  Comparing a %s with a %s; paths may not line up\n\n", a.Kind, b.Kind)
+		}
+
+		report := artifact.Diff(a, b)
+		if diffJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(report)
+		}
+		printDiffReport(report, diffTop)
+		return nil
+	},
+}
+
+func printDiffReport(r *artifact.Report, top int) {
+	fmt.Printf("📦 %s → %s\n", r.A, r.B)
+	fmt.Printf("   Total: %s → %s (%s)\n", formatBytes(r.Total.Before), formatBytes(r.Total.After), formatDelta(r.Total.Delta()))
+
+	fmt.Println("\nSections:")
+	printChanges(r.Sections, top, true)
+
+	if len(r.Added) > 0 {
+		fmt.Printf("\nAdded files (%d):\n", len(r.Added))
+		for i, f := range r.Added {
+			if i == top {
+				fmt.Printf("  ... %d more\n", len(r.Added)-top)
+				break
+			}
+			fmt.Printf("  + %-60s %10s\n", f.Path, formatBytes(f.Size))
+		}
+	}
+	if len(r.Removed) > 0 {
+		fmt.Printf("\nRemoved files (%d):\n", len(r.Removed))
+		for i, f := range r.Removed {
+			if i == top {
+				fmt.Printf("  ... %d more\n", len(r.Removed)-top)
+				break
+			}
+			fmt.Printf("  - %-60s %10s\n", f.Path, formatBytes(f.Size))
+		}
+	}
+	if len(r.Changed) > 0 {
+		fmt.Printf("\nResized files (%d):\n", len(r.Changed))
+		printChanges(r.Changed, top, false)
+	}
+
+	if m := r.Manifest; m != nil {
+		fmt.Printf("\nManifest (%s):\n", m.Path)
+		switch {
+		case m.Note != "":
+			fmt.Printf("  ℹ️  %s\n", m.Note)
+		case len(m.Added) == 0 && len(m.Removed) == 0:
+			fmt.Println("  ✓ unchanged")
+		}
+		for _, l := range m.Removed {
+			fmt.Printf("  - %s\n", l)
+		}
+		for _, l := range m.Added {
+			fmt.Printf("  + %s\n", l)
+		}
+	}
+
+	for _, bin := range r.Binaries {
+		fmt.Printf("\nGo packages in %s:\n", bin.Path)
+		if bin.Error != "" {
+			fmt.Printf("  ⚠️  %s\n", bin.Error)
+			continue
+		}
+		if bin.Note != "" {
+			fmt.Printf("  ℹ️  %s\n", bin.Note)
+		}
+		printChanges(bin.Packages, top, false)
+	}
+}
+
+// printChanges lists up to top changes; all lists them with no change too.
+func printChanges(changes []artifact.Change, top int, all bool) {
+	listed := visibleChanges(changes, all)
+	for i, c := range listed {
+		if i == top {
+			fmt.Printf("  ... %d more\n", len(listed)-top)
+			return
+		}
+		fmt.Printf("  %-50s %10s → %-10s %s\n", c.Name, formatBytes(c.Before), formatBytes(c.After), formatDelta(c.Delta()))
+	}
+}
+
+// visibleChanges drops the changes of zero bytes unless all is set, so the
+// "more" count covers only entries that would have been listed.
+func visibleChanges(changes []artifact.Change, all bool) []artifact.Change {
+	if all {
+		return changes
+	}
+	var listed []artifact.Change
+	for _, c := range changes {
+		if c.Delta() != 0 {
+			listed = append(listed, c)
+		}
+	}
+	return listed
+}
+
+func formatDelta(d int64) string {
+	switch {
+	case d > 0:
+		return "+" + formatBytes(d)
+	case d < 0:
+		return "-" + formatBytes(-d)
+	}
+	return "±0"
+}
+
+func init() {
+	diffCmd.Flags().IntVar(&diffTop, "top", 15, i18n.T("Maximum entries per list"))
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, i18n.T("Print the report as JSON"))
+
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.GroupID = "tools"
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// display is one monitor as listed by 'goup-util displays'; Index is what
+// app.json "display.index" takes.
+type display struct {
+	Index   int     `json:"index"`
+	X       int     `json:"x"`
+	Y       int     `json:"y"`
+	Width   int     `json:"width"`
+	Height  int     `json:"height"`
+	Scale   float64 `json:"scale"`
+	Primary bool    `json:"primary"`
+}
+
+var displaysCmd = &cobra.Command{
+	Use:   "displays",
+	Short: i18n.T("List the connected displays for kiosk setup"),
+	Long: i18n.T(`List the displays connected to this machine with their position, size and
+scale factor. The index is what a shell's app.json takes to open on that
+display:
+
+  "display": {"index": 1, "fullscreen": true}
+
+Add "scale" to override the system DPI on panels that report it wrong.
+Use --json in kiosk setup scripts. Needs a build with -tags screenshot.`),
+	Example: `  goup-util displays
+  goup-util displays --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		displays, err := listDisplays()
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(displays)
+		}
+		if len(displays) == 0 {
+			fmt.Println("No displays found")
+			return nil
+		}
+		fmt.Printf("%-6s %-14s %-12s %s\n", "INDEX", "POSITION", "SIZE", "SCALE")
+		for _, d := range displays {
+			primary := ""
+			if d.Primary {
+				primary = "primary"
+			}
+			fmt.Printf("%-6d %-14s %-12s %-6s %s\n", d.Index,
+				fmt.Sprintf("%d,%d", d.X, d.Y), fmt.Sprintf("%dx%d", d.Width, d.Height),
+				fmt.Sprintf("%gx", d.Scale), primary)
+		}
+		return nil
+	},
+}
+
+func init() {
+	displaysCmd.Flags().Bool("json", false, i18n.T("Print the displays as JSON"))
+	displaysCmd.GroupID = "tools"
+	rootCmd.AddCommand(displaysCmd)
+}
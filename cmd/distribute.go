@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/appstore"
+	"github.com/joeblew999/goup-util/pkg/changelog"
+	"github.com/joeblew999/goup-util/pkg/firebase"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/metadata"
+	"github.com/joeblew999/goup-util/pkg/secrets"
+	"github.com/spf13/cobra"
+)
+
+// testFlightNotesLimit is the length App Store Connect allows for
+// "What to Test".
+const testFlightNotesLimit = 4000
+
+var distributeCmd = &cobra.Command{
+	Use:   "distribute <artifact>",
+	Short: i18n.T("Send a build to testers via Firebase App Distribution or TestFlight"),
+	Long: i18n.T(`Upload a build to a beta service and share it with tester groups,
+without a full store release.
+
+  firebase     .apk, .aab or .ipa → Firebase App Distribution
+               needs --app (or $FIREBASE_APP_ID) and FIREBASE_SERVICE_ACCOUNT
+  testflight   .ipa (iOS) or .pkg (macOS) → TestFlight, via Xcode's altool
+               needs --bundle-id and the APPSTORE_API_* secrets
+
+Release notes default to the commit subjects since the previous git tag;
+use --notes or --notes-file to write them yourself. An app with release
+note templates (metadata/<locale>/release_notes.tmpl, see 'publish notes')
+gets them rendered instead: TestFlight gets every locale, Firebase the
+--locale one.
+
+Examples:
+  goup-util distribute my-app/.dist/my-app.apk --service firebase --app 1:1234567890:android:0a1b2c --group qa
+  goup-util distribute my-app/.dist/my-app.ipa --service testflight --bundle-id com.example.myapp --group "QA Team"
+  goup-util distribute my-app/.dist/my-app.apk --service firebase --testers alice@example.com --notes "Try the new sync"`),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artifact := args[0]
+		if _, err := os.Stat(artifact); err != nil {
+			return fmt.Errorf(i18n.T("artifact not found: %s"), artifact)
+		}
+		service, _ := cmd.Flags().GetString("service")
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		notes, localized, err := distributeNotes(cmd, artifact)
+		if err != nil {
+			return err
+		}
+
+		store := secrets.Open("")
+		switch service {
+		case "firebase":
+			return distributeFirebase(cmd, store, artifact, groups, notes)
+		case "testflight":
+			return distributeTestFlight(cmd, store, artifact, groups, notes, localized)
+		}
+		return fmt.Errorf(i18n.T("unknown --service %q (use firebase or testflight)"), service)
+	},
+}
+
+// distributeNotes returns --notes, the --notes-file contents, the app's
+// rendered release note templates, or the changelog of the repository
+// holding the artifact. localized holds the templates' notes by locale;
+// notes is the --locale one.
+func distributeNotes(cmd *cobra.Command, artifact string) (notes string, localized map[string]string, err error) {
+	if notes, _ := cmd.Flags().GetString("notes"); notes != "" {
+		return notes, nil, nil
+	}
+	if file, _ := cmd.Flags().GetString("notes-file"); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", nil, fmt.Errorf(i18n.T("failed to read notes: %w"), err)
+		}
+		return strings.TrimSpace(string(data)), nil, nil
+	}
+	if appDir := metadataAppDir(filepath.Dir(artifact)); appDir != "" {
+		locales, err := metadata.Load(appDir)
+		if err != nil {
+			return "", nil, fmt.Errorf(i18n.T("failed to read metadata: %w"), err)
+		}
+		var templated []metadata.Locale
+		for _, l := range locales {
+			if l.NotesTemplate != "" {
+				l.ReleaseNotes = "" // The template, not the store's release_notes.txt
+				templated = append(templated, l)
+			}
+		}
+		version, _ := cmd.Flags().GetString("version")
+		if err := renderReleaseNotes(appDir, version, templated); err != nil {
+			return "", nil, err
+		}
+		if len(templated) > 0 {
+			localized = map[string]string{}
+			for _, l := range templated {
+				localized[l.Code] = l.ReleaseNotes
+			}
+			locale, _ := cmd.Flags().GetString("locale")
+			if notes, ok := localized[locale]; ok {
+				return notes, localized, nil
+			}
+			return templated[0].ReleaseNotes, localized, nil
+		}
+	}
+	notes, err = changelog.Since(filepath.Dir(artifact))
+	if err != nil {
+		fmt.Printf("⚠️  No release notes: %v\n", err)
+		return "", nil, nil
+	}
+	return notes, nil, nil
+}
+
+// metadataAppDir finds the app directory above an artifact's directory
+// (<app>/.dist or <app>/.bin/<platform>) that has a metadata directory.
+func metadataAppDir(dir string) string {
+	return appDirAbove(dir, metadata.Dir)
+}
+
+// appDirAbove finds the app directory above an artifact's directory that
+// holds the file or directory name, or returns "".
+func appDirAbove(dir, name string) string {
+	for i := 0; i < 3; i++ {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return ""
+}
+
+func distributeFirebase(cmd *cobra.Command, store *secrets.Store, artifact string, groups []string, notes string) error {
+	switch strings.ToLower(filepath.Ext(artifact)) {
+	case ".apk", ".aab", ".ipa":
+	default:
+		return fmt.Errorf(i18n.T("Firebase App Distribution takes .apk, .aab or .ipa files, not %s"), filepath.Base(artifact))
+	}
+	appID, _ := cmd.Flags().GetString("app")
+	if appID == "" {
+		appID = os.Getenv("FIREBASE_APP_ID")
+	}
+	if appID == "" {
+		return errors.New(i18n.T("--app is required (the Firebase app ID from Project settings, or set $FIREBASE_APP_ID)"))
+	}
+	appName, err := firebase.AppName(appID)
+	if err != nil {
+		return err
+	}
+	testers, _ := cmd.Flags().GetStringSlice("testers")
+	if len(groups) == 0 && len(testers) == 0 {
+		return errors.New(i18n.T("nobody to distribute to: set --group or --testers"))
+	}
+	sa, err := store.Get("FIREBASE_SERVICE_ACCOUNT")
+	if err != nil {
+		return err
+	}
+	if sa == nil {
+		return errors.New(i18n.T("Firebase credentials missing: set FIREBASE_SERVICE_ACCOUNT (see 'goup-util secrets')"))
+	}
+	client, err := firebase.New(sa.Value)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📤 Uploading %s to Firebase App Distribution...\n", filepath.Base(artifact))
+	release, err := client.Upload(appName, artifact)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Release %s (%s)\n", release.DisplayVersion, release.BuildVersion)
+	if notes != "" {
+		if err := client.SetNotes(release, notes); err != nil {
+			return err
+		}
+	}
+	if err := client.Distribute(release, groups, testers); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Distributed to %s\n", strings.Join(slices.Concat(groups, testers), ", "))
+	if release.ConsoleURI != "" {
+		fmt.Printf("   %s\n", release.ConsoleURI)
+	}
+	return nil
+}
+
+func distributeTestFlight(cmd *cobra.Command, store *secrets.Store, artifact string, groups []string, notes string, localized map[string]string) error {
+	platform := ""
+	switch strings.ToLower(filepath.Ext(artifact)) {
+	case ".ipa":
+		platform = "ios"
+	case ".pkg":
+		platform = "macos"
+	default:
+		return fmt.Errorf(i18n.T("TestFlight takes an .ipa (iOS) or .pkg (macOS), not %s"), filepath.Base(artifact))
+	}
+	bundleID, _ := cmd.Flags().GetString("bundle-id")
+	if bundleID == "" {
+		return errors.New(i18n.T("--bundle-id is required for TestFlight"))
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	key, keyID, issuer, err := appStoreKey(store)
+	if err != nil {
+		return err
+	}
+	client, err := appstore.New(key, keyID, issuer)
+	if err != nil {
+		return err
+	}
+	appID, err := client.AppID(bundleID)
+	if err != nil {
+		return err
+	}
+	var targets []appstore.Resource
+	for _, name := range groups {
+		group, err := client.BetaGroup(appID, name)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, group)
+	}
+
+	started := time.Now()
+	fmt.Printf("📤 Uploading %s to App Store Connect...\n", filepath.Base(artifact))
+	if err := appstore.Upload(artifact, platform, key, keyID, issuer); err != nil {
+		return err
+	}
+	fmt.Printf("⏳ Waiting for App Store Connect to process the build (up to %s)...\n", timeout)
+	build, err := client.WaitForBuild(appID, started, timeout, 30*time.Second)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Build %s is ready\n", build.Attr("version"))
+	if err := setExportCompliance(client, build, appDirAbove(filepath.Dir(artifact), appconfig.ConfigFileName)); err != nil {
+		return err
+	}
+
+	if len(localized) == 0 && notes != "" {
+		localized = map[string]string{"en-US": notes}
+	}
+	for locale, text := range localized {
+		if r := []rune(text); len(r) > testFlightNotesLimit {
+			text = string(r[:testFlightNotesLimit])
+		}
+		if _, err := client.Localize("betaBuildLocalizations", build, locale, map[string]any{"whatsNew": text}); err != nil {
+			return fmt.Errorf(i18n.T("%s: %w"), locale, err)
+		}
+	}
+	review := false
+	for _, group := range targets {
+		if err := client.AddToGroup(group, build); err != nil {
+			return err
+		}
+		review = review || appstore.IsExternal(group)
+	}
+	if review {
+		if err := client.SubmitForBetaReview(build); err != nil {
+			return err
+		}
+		fmt.Println("✓ Submitted for beta app review (needed for external groups)")
+	}
+	if len(groups) > 0 {
+		fmt.Printf("✅ Build %s shared with %s\n", build.Attr("version"), strings.Join(groups, ", "))
+	} else {
+		fmt.Printf("✅ Build %s is on TestFlight\n", build.Attr("version"))
+	}
+	return nil
+}
+
+func init() {
+	distributeCmd.Flags().String("service", "", i18n.T("Beta service: firebase or testflight"))
+	distributeCmd.Flags().StringSlice("group", nil, i18n.T("Tester groups (Firebase group aliases or TestFlight group names)"))
+	distributeCmd.Flags().StringSlice("testers", nil, i18n.T("Tester emails (Firebase)"))
+	distributeCmd.Flags().String("app", "", i18n.T("Firebase app ID (default $FIREBASE_APP_ID)"))
+	distributeCmd.Flags().String("bundle-id", "", i18n.T("Bundle ID registered in App Store Connect (TestFlight)"))
+	distributeCmd.Flags().String("notes", "", i18n.T("Release notes (default: commits since the previous tag)"))
+	distributeCmd.Flags().String("notes-file", "", i18n.T("Read the release notes from a file"))
+	distributeCmd.Flags().String("locale", "en-US", i18n.T("Locale of the release note template to use for Firebase"))
+	distributeCmd.Flags().String("version", "", i18n.T("Version for release note templates (default: the git tag on HEAD)"))
+	distributeCmd.Flags().Duration("timeout", 30*time.Minute, i18n.T("How long to wait for TestFlight processing"))
+	distributeCmd.MarkFlagRequired("service")
+
+	distributeCmd.GroupID = "build"
+	rootCmd.AddCommand(distributeCmd)
+}
@@ -5,14 +5,15 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 )
 
 var docsCmd = &cobra.Command{
 	Use:   "docs [output-dir]",
-	Short: "Generate CLI documentation",
-	Long: `Generate documentation for all goup-util commands.
+	Short: i18n.T("Generate CLI documentation"),
+	Long: i18n.T(`Generate documentation for all goup-util commands.
 
 Outputs markdown files that can be used in README or documentation sites.
 
@@ -24,7 +25,7 @@ Examples:
   goup-util docs ./my-docs/
 
   # Generate man pages
-  goup-util docs --format man ./man/`,
+  goup-util docs --format man ./man/`),
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		outputDir := "docs/cli"
@@ -36,7 +37,7 @@ Examples:
 
 		// Create output directory
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+			return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 		}
 
 		fmt.Printf("Generating %s documentation to %s/\n", format, outputDir)
@@ -44,7 +45,7 @@ Examples:
 		switch format {
 		case "markdown", "md":
 			if err := doc.GenMarkdownTree(rootCmd, outputDir); err != nil {
-				return fmt.Errorf("failed to generate markdown: %w", err)
+				return fmt.Errorf(i18n.T("failed to generate markdown: %w"), err)
 			}
 		case "man":
 			header := &doc.GenManHeader{
@@ -52,18 +53,18 @@ Examples:
 				Section: "1",
 			}
 			if err := doc.GenManTree(rootCmd, header, outputDir); err != nil {
-				return fmt.Errorf("failed to generate man pages: %w", err)
+				return fmt.Errorf(i18n.T("failed to generate man pages: %w"), err)
 			}
 		case "yaml":
 			if err := doc.GenYamlTree(rootCmd, outputDir); err != nil {
-				return fmt.Errorf("failed to generate YAML: %w", err)
+				return fmt.Errorf(i18n.T("failed to generate YAML: %w"), err)
 			}
 		case "rst":
 			if err := doc.GenReSTTree(rootCmd, outputDir); err != nil {
-				return fmt.Errorf("failed to generate RST: %w", err)
+				return fmt.Errorf(i18n.T("failed to generate RST: %w"), err)
 			}
 		default:
-			return fmt.Errorf("unknown format: %s (use: markdown, man, yaml, rst)", format)
+			return fmt.Errorf(i18n.T("unknown format: %s (use: markdown, man, yaml, rst)"), format)
 		}
 
 		// Count generated files
@@ -75,7 +76,7 @@ Examples:
 }
 
 func init() {
-	docsCmd.Flags().StringP("format", "f", "markdown", "Output format: markdown, man, yaml, rst")
+	docsCmd.Flags().StringP("format", "f", "markdown", i18n.T("Output format: markdown, man, yaml, rst"))
 
 	// Group for help organization
 	docsCmd.GroupID = "tools"
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/joeblew999/goup-util/pkg/projectcheck"
+	"github.com/joeblew999/goup-util/pkg/sysdeps"
+	"github.com/joeblew999/goup-util/pkg/utm"
+	"github.com/joeblew999/goup-util/pkg/wintools"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: i18n.T("Check a machine for what builds need"),
+	Long: i18n.T(`Check a build machine for the tools a platform needs.
+
+With --project, check an app directory instead: go.mod tidiness, the
+source icon, app.json's url for webview apps, the go.work entry, stale
+build cache entries and unsigned bundles in .dist. --fix runs go mod
+tidy, adds the module to go.work, creates a placeholder icon and forgets
+stale cache entries. The exit status is non-zero when a required check
+(go.mod, go.work) fails.
+
+To check goup-util's own installation, use 'goup-util self doctor'.`),
+	Example: `  goup-util doctor linux
+  goup-util doctor --project examples/hybrid-dashboard
+  goup-util doctor --project examples/hybrid-dashboard --fix`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("project")
+		if dir == "" {
+			return cmd.Help()
+		}
+		fix, _ := cmd.Flags().GetBool("fix")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		proj, err := project.NewGioProject(dir)
+		if err != nil {
+			return err
+		}
+		p := projectcheck.Project{Dir: proj.RootDir, Name: proj.Name, Cache: getBuildCache()}
+		checks := projectcheck.Run(p)
+		if fix {
+			fixed := false
+			for _, c := range checks {
+				if c.OK || !c.AutoFix {
+					continue
+				}
+				fmt.Printf("--- Fixing %s ---\n", c.Name)
+				if err := projectcheck.Repair(p, c); err != nil {
+					fmt.Printf("❌ %v\n", err)
+					continue
+				}
+				fixed = true
+			}
+			if fixed {
+				checks = projectcheck.Run(p)
+			}
+		}
+
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(checks); err != nil {
+				return err
+			}
+		} else {
+			printProjectChecks(proj.Name, checks)
+		}
+		if !projectcheck.Healthy(checks) {
+			return fmt.Errorf(i18n.T("%s has problems that break builds"), proj.Name)
+		}
+		return nil
+	},
+}
+
+var doctorWindowsCmd = &cobra.Command{
+	Use:   "windows",
+	Short: i18n.T("Check the Windows toolchain: Go, gogio, WebView2, signtool, MSIX"),
+	Long: i18n.T(`Check a Windows machine (a UTM VM or a CI runner) for what building,
+running and packaging Gio and webview apps needs. No Visual Studio or MSVC
+is required.
+
+With --vm, the check runs inside a UTM VM through the guest agent; the VM
+needs goup-util (see 'goup-util utm provision --recipe windows-build').
+With --fix, missing tools are installed. The exit status is non-zero when
+a required tool (Go, gogio) is missing.`),
+	Example: `  goup-util doctor windows
+  goup-util doctor windows --fix
+  goup-util doctor windows --vm "Windows 11"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vm, _ := cmd.Flags().GetString("vm")
+		fix, _ := cmd.Flags().GetBool("fix")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if vm != "" {
+			if err := requireGuestAgent(vm); err != nil {
+				return err
+			}
+			guestArgs := []string{"doctor", "windows"}
+			if fix {
+				guestArgs = append(guestArgs, "--fix")
+			}
+			if asJSON {
+				guestArgs = append(guestArgs, "--json")
+			}
+			return utm.ExecArgsInVM(vm, "goup-util.exe", guestArgs...)
+		}
+		if runtime.GOOS != "windows" {
+			return errors.New(i18n.T("doctor windows checks the machine it runs on; run it on Windows, or in a VM with --vm <name>"))
+		}
+
+		checks := wintools.Doctor()
+		if fix {
+			installed := false
+			for _, name := range wintools.Names {
+				if _, ok := wintools.Installed(name); ok {
+					continue
+				}
+				fmt.Printf("--- Installing %s ---\n", name)
+				if err := wintools.Install(name); err != nil {
+					fmt.Printf("❌ %v\n", err)
+					continue
+				}
+				installed = true
+			}
+			if installed {
+				checks = wintools.Doctor()
+			}
+		}
+
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(checks); err != nil {
+				return err
+			}
+		} else {
+			printWindowsChecks(checks)
+		}
+		if !wintools.Healthy(checks) {
+			return errors.New(i18n.T("required Windows build tools are missing"))
+		}
+		return nil
+	},
+}
+
+var doctorLinuxCmd = &cobra.Command{
+	Use:   "linux",
+	Short: i18n.T("Check the system packages Gio needs on Linux"),
+	Long: i18n.T(`Check that the Wayland, X11, EGL/GLES and Vulkan development packages,
+a C compiler and pkg-config are installed, using the distribution's
+package manager (apt, dnf, pacman or apk). Prints the command that
+installs whatever is missing; --yes runs it.`),
+	Example: `  goup-util doctor linux
+  goup-util doctor linux --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if runtime.GOOS != "linux" {
+			return errors.New(i18n.T("doctor linux checks the machine it runs on; run it on Linux"))
+		}
+		yes, _ := cmd.Flags().GetBool("yes")
+		m, err := sysdeps.Detect()
+		if err != nil {
+			return err
+		}
+		missing := m.Missing()
+		for _, pkg := range m.Packages() {
+			if slices.Contains(missing, pkg) {
+				fmt.Printf("  ❌ %s\n", pkg)
+			} else {
+				fmt.Printf("  ✓ %s\n", pkg)
+			}
+		}
+		if len(missing) == 0 {
+			fmt.Printf("✅ All Gio build dependencies are installed (%s)\n", m)
+			return nil
+		}
+		return linuxPreflight(yes)
+	},
+}
+
+func init() {
+	doctorLinuxCmd.Flags().BoolP("yes", "y", false, i18n.T("Install missing packages"))
+	doctorCmd.AddCommand(doctorLinuxCmd)
+
+	doctorWindowsCmd.Flags().String("vm", "", i18n.T("Run the check inside this UTM VM"))
+	doctorWindowsCmd.Flags().Bool("fix", false, i18n.T("Install missing tools"))
+	doctorWindowsCmd.Flags().Bool("json", false, i18n.T("Print the checks as JSON"))
+
+	doctorCmd.Flags().String("project", "", i18n.T("Check this app directory instead of the machine"))
+	doctorCmd.Flags().Bool("fix", false, i18n.T("Repair what can be repaired (with --project)"))
+	doctorCmd.Flags().Bool("json", false, i18n.T("Print the checks as JSON (with --project)"))
+
+	doctorCmd.AddCommand(doctorWindowsCmd)
+	doctorCmd.GroupID = "tools"
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func printWindowsChecks(checks []wintools.Check) {
+	fmt.Println("Windows toolchain:")
+	for _, c := range checks {
+		mark := "✓"
+		switch {
+		case !c.OK && c.Required:
+			mark = "❌"
+		case !c.OK:
+			mark = "⚠️ "
+		}
+		fmt.Printf("  %s %-17s %s\n", mark, c.Name, c.Detail)
+		if !c.OK && c.Fix != "" {
+			fmt.Printf("     Fix: %s\n", c.Fix)
+		}
+	}
+}
+
+func printProjectChecks(name string, checks []projectcheck.Check) {
+	fmt.Printf("Project %s:\n", name)
+	for _, c := range checks {
+		mark := "✓"
+		switch {
+		case !c.OK && c.Required:
+			mark = "❌"
+		case !c.OK:
+			mark = "⚠️ "
+		}
+		fmt.Printf("  %s %-17s %s\n", mark, c.Name, c.Detail)
+		if !c.OK && c.Fix != "" {
+			fix := c.Fix
+			if c.AutoFix {
+				fix += " (--fix)"
+			}
+			fmt.Printf("     Fix: %s\n", fix)
+		}
+	}
+}
+
+// isWindowsTool reports whether sdkName is one of the Windows tools.
+func isWindowsTool(sdkName string) bool {
+	return slices.Contains(wintools.Names, sdkName)
+}
+
+// installWindowsTool installs the WebView2 runtime, the Windows SDK or the
+// MSIX packaging tool, unless it is already present.
+func installWindowsTool(name string) error {
+	if runtime.GOOS != "windows" {
+		return fmt.Errorf(i18n.T("%s can only be installed on Windows; run this in the VM, e.g. goup-util utm exec <vm> -- install %s"), name, name)
+	}
+	if where, ok := wintools.Installed(name); ok {
+		fmt.Printf("✓ %s is already installed (%s)\n", name, where)
+		return nil
+	}
+	if err := wintools.Install(name); err != nil {
+		return err
+	}
+	where, ok := wintools.Installed(name)
+	if !ok {
+		return fmt.Errorf(i18n.T("%s was installed but is not found yet; open a new terminal and run: goup-util doctor windows"), name)
+	}
+	fmt.Printf("✅ %s installed (%s)\n", name, where)
+	return nil
+}
+
+// linuxPreflight stops a Linux build before cgo fails on missing headers,
+// printing the exact install command, or runs that command when yes is
+// set. Unknown distributions are left to the build itself.
+func linuxPreflight(yes bool) error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	m, err := sysdeps.Detect()
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		return nil
+	}
+	missing := m.Missing()
+	if len(missing) == 0 {
+		return nil
+	}
+	if !yes {
+		return fmt.Errorf(i18n.T("missing system packages for Gio: %s\nInstall them with:\n  %s\nor rerun with --yes"),
+			strings.Join(missing, " "), strings.Join(m.InstallCommand(missing), " "))
+	}
+	fmt.Printf("📦 Installing %s with %s...\n", strings.Join(missing, " "), m)
+	if err := m.Install(missing); err != nil {
+		return err
+	}
+	if still := m.Missing(); len(still) > 0 {
+		return fmt.Errorf(i18n.T("still missing after install: %s"), strings.Join(still, " "))
+	}
+	fmt.Println("✅ System packages installed")
+	return nil
+}
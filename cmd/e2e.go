@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/e2e"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/spf13/cobra"
+)
+
+var e2eCmd = &cobra.Command{
+	Use:   "e2e",
+	Short: i18n.T("End-to-end tests for apps on emulators and simulators"),
+}
+
+var e2eRunCmd = &cobra.Command{
+	Use:   "run <scenario-file-or-directory>",
+	Short: i18n.T("Run Given/When/Then scenario files against the built app"),
+	Long: i18n.T(`Run scenario files against an app on an Android emulator or device or
+the iOS simulator. Each feature's app is built and installed once; each
+scenario then launches it afresh and runs its steps:
+
+  feature: Settings
+  app: ../examples/hybrid-dashboard   # Relative to the scenario file
+  platform: android                   # Or ios-simulator
+  scenarios:
+    - name: deep link opens settings
+      given: [launch]
+      when:
+        - open: myapp://settings
+        - tap: {text: Dark mode}       # Or {x: 540, y: 1200}
+        - type: hello
+        - wait: 1s
+      then:
+        - see: Settings
+        - not-see: Error
+        - screenshot: {name: settings, tolerance: 0.02}
+
+see, not-see and tap by text read the accessibility tree (Android only)
+and wait up to --timeout. A screenshot is compared with
+baselines/<name>.png next to the scenario file, which is created on the
+first run or with --update-baselines. Failed scenarios are captured to
+<out>/failures. The iOS simulator supports launch, open, wait and
+screenshot steps.`),
+	Example: `  goup-util e2e run tests/
+  goup-util e2e run tests/settings.yaml --platform android --junit report.xml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platform, _ := cmd.Flags().GetString("platform")
+		junit, _ := cmd.Flags().GetString("junit")
+		outDir, _ := cmd.Flags().GetString("out")
+		update, _ := cmd.Flags().GetBool("update-baselines")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		features, err := e2e.Load(args[0])
+		if err != nil {
+			return err
+		}
+		suites := e2e.Run(features, e2e.Options{
+			Platform:        platform,
+			OutDir:          outDir,
+			UpdateBaselines: update,
+			Timeout:         timeout,
+			Prepare:         prepareE2EApp,
+			Device:          e2e.NewDevice,
+		})
+
+		if junit != "" {
+			if err := os.MkdirAll(filepath.Dir(junit), 0755); err != nil {
+				return err
+			}
+			f, err := os.Create(junit)
+			if err != nil {
+				return fmt.Errorf(i18n.T("failed to create %s: %w"), junit, err)
+			}
+			defer f.Close()
+			if err := e2e.WriteJUnit(f, suites); err != nil {
+				return fmt.Errorf(i18n.T("failed to write %s: %w"), junit, err)
+			}
+			fmt.Printf("📄 JUnit report: %s\n", junit)
+		}
+		if e2e.Failed(suites) {
+			return errors.New(i18n.T("end-to-end tests failed"))
+		}
+		fmt.Println("✅ All scenarios passed")
+		return nil
+	},
+}
+
+// prepareE2EApp builds the app like goup-util run does and returns what
+// to install and its gogio package or bundle ID.
+func prepareE2EApp(platform, appDir string) (string, string, error) {
+	proj, err := project.NewGioProject(appDir)
+	if err != nil {
+		return "", "", fmt.Errorf(i18n.T("failed to create project: %w"), err)
+	}
+	if err := proj.Validate(); err != nil {
+		return "", "", fmt.Errorf(i18n.T("invalid project: %w"), err)
+	}
+	switch platform {
+	case "android":
+		err = buildAndroid(proj, platform, BuildOptions{})
+	case "ios-simulator":
+		err = buildIOS(proj, platform, BuildOptions{}, true)
+	default:
+		return "", "", fmt.Errorf(i18n.T("e2e runs on android or ios-simulator, not %s"), platform)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return proj.GetOutputPath(platform), "localhost." + proj.Name, nil
+}
+
+func init() {
+	e2eRunCmd.Flags().String("platform", "", i18n.T("android or ios-simulator (default: the feature's platform, else android)"))
+	e2eRunCmd.Flags().String("junit", "", i18n.T("Write a JUnit XML report to this file"))
+	e2eRunCmd.Flags().String("out", ".e2e", i18n.T("Directory for screenshots and failure captures"))
+	e2eRunCmd.Flags().Bool("update-baselines", false, i18n.T("Replace screenshot baselines with new captures"))
+	e2eRunCmd.Flags().Duration("timeout", 10*time.Second, i18n.T("How long see, not-see and tap wait for the screen"))
+
+	e2eCmd.AddCommand(e2eRunCmd)
+	e2eCmd.GroupID = "build"
+	rootCmd.AddCommand(e2eCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/joeblew999/goup-util/pkg/assets"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var (
+	embedWebDir      string
+	embedWebBase     string
+	embedWebForce    bool
+	embedWebNoVerify bool
+)
+
+var embedWebCmd = &cobra.Command{
+	Use:   "embed-web <app-directory> <site-directory>",
+	Short: i18n.T("Ship a built React/Vue/Svelte app inside a hybrid app"),
+	Long: `Copy the build output of a web app (Vite's dist/, webpack's build/, a
+Next.js export) into a hybrid app and embed it in the binary.
+
+embed-web:
+  1. replaces <app>/web (--dir) with the site,
+  2. rewrites the base path the site was built for (<base href>, or --base)
+     to "/", where the app's embedded server serves it,
+  3. writes ` + assets.SiteFileName + `, declaring the embed.FS ` + assets.SiteVar + ` and
+     ` + assets.SiteVar + `Handler(), which serves it with client-side routes
+     falling back to index.html,
+  4. checks that the app still builds.
+
+Serve the site from the app's embedded server:
+
+  mux.Handle("/", ` + assets.SiteVar + `Handler())
+
+Run it again after every frontend build. A --dir that embed-web did not
+create is only replaced with --force.
+
+Examples:
+  goup-util embed-web examples/hybrid-dashboard ../frontend/dist
+  goup-util embed-web ./myapp ./site/build --base /myapp/ --dir ui`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		site := &assets.Site{AppDir: args[0], SrcDir: args[1], Dir: embedWebDir, Base: embedWebBase}
+		if _, err := os.Stat(filepath.Join(site.AppDir, "go.mod")); err != nil {
+			return fmt.Errorf(i18n.T("%s is not a Go app (no go.mod)"), site.AppDir)
+		}
+		if entries, _ := os.ReadDir(site.Dest()); len(entries) > 0 && !site.Managed() && !embedWebForce {
+			return fmt.Errorf(i18n.T("%s already has files that embed-web did not put there; use --force to replace them"), site.Dest())
+		}
+
+		files, rewritten, err := site.Copy()
+		if err != nil {
+			return err
+		}
+		var total int64
+		for _, f := range files {
+			total += f.Size
+		}
+		fmt.Printf("📦 Copied %d files (%s) to %s\n", len(files), formatBytes(total), site.Dest())
+		if rewritten > 0 {
+			fmt.Printf("   Rewrote the base path to / in %d files\n", rewritten)
+		}
+		if _, err := site.Generate(); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Wrote %s\n", filepath.Join(site.AppDir, assets.SiteFileName))
+
+		if embedWebNoVerify {
+			return nil
+		}
+		fmt.Println("🔨 Checking that the app builds...")
+		build := exec.Command("go", "build", "-o", os.DevNull, ".")
+		build.Dir = site.AppDir
+		build.Stdout = os.Stdout
+		build.Stderr = os.Stderr
+		if err := build.Run(); err != nil {
+			return fmt.Errorf(i18n.T("app does not build with the site embedded (use --no-verify for apps that only build for other platforms): %w"), err)
+		}
+		fmt.Printf("✅ Site embedded; serve it with mux.Handle(\"/\", %sHandler())\n", assets.SiteVar)
+		return nil
+	},
+}
+
+func init() {
+	embedWebCmd.Flags().StringVar(&embedWebDir, "dir", "web", i18n.T("Directory in the app to copy the site to"))
+	embedWebCmd.Flags().StringVar(&embedWebBase, "base", "", i18n.T("Base path the site was built for (default: its <base href>)"))
+	embedWebCmd.Flags().BoolVar(&embedWebForce, "force", false, i18n.T("Replace a directory embed-web did not create"))
+	embedWebCmd.Flags().BoolVar(&embedWebNoVerify, "no-verify", false, i18n.T("Skip the build check"))
+
+	embedWebCmd.GroupID = "build"
+	rootCmd.AddCommand(embedWebCmd)
+}
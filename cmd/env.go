@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/installer"
+	"github.com/joeblew999/goup-util/pkg/shellenv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envApply  bool
+	envRemove bool
+	envShell  string
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: i18n.T("Set JAVA_HOME, ANDROID_HOME and PATH for installed SDKs"),
+	Long: i18n.T(`Print or persist the environment variables for the SDKs goup-util installed.
+
+By default the statements are printed for the current shell, for eval-style use:
+
+  eval "$(goup-util env)"                                # bash, zsh
+  goup-util env --shell fish | source                    # fish
+  goup-util env --shell powershell | Out-String | iex    # PowerShell
+
+--apply writes them into a managed block of your shell startup file
+(~/.zshrc, ~/.bashrc, ~/.bash_profile on macOS, fish config.fish or the
+PowerShell profile). Running it again replaces the block; --remove deletes it.
+
+For per-project settings from goup.json, see 'goup-util env hook'.`),
+	Example: `  goup-util env
+  goup-util env --apply
+  goup-util env --apply --shell bash
+  goup-util env --remove`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell, err := shellenv.Normalize(envShell)
+		if err != nil {
+			return err
+		}
+		if envApply && envRemove {
+			return errors.New(i18n.T("--apply and --remove cannot be used together"))
+		}
+
+		if envRemove {
+			file, err := shellenv.StartupFile(shell)
+			if err != nil {
+				return err
+			}
+			removed, err := shellenv.Remove(file)
+			if err != nil {
+				return err
+			}
+			if removed {
+				fmt.Printf("✅ Removed goup-util environment from %s\n", file)
+			} else {
+				fmt.Printf("✓ No goup-util environment in %s\n", file)
+			}
+			return nil
+		}
+
+		cache, err := installer.NewCache(config.GetCachePath())
+		if err != nil {
+			return fmt.Errorf(i18n.T("could not load cache: %w"), err)
+		}
+		env, err := sdkEnv(cache)
+		if err != nil {
+			return err
+		}
+		if len(env.Vars) == 0 && len(env.Path) == 0 {
+			fmt.Fprintln(os.Stderr, "⚠️  No SDKs installed yet. Run: goup-util setup default-android")
+			if envApply {
+				return errors.New(i18n.T("nothing to apply"))
+			}
+			return nil
+		}
+
+		if !envApply {
+			fmt.Print(shellenv.Render(shell, env))
+			return nil
+		}
+
+		file, err := shellenv.StartupFile(shell)
+		if err != nil {
+			return err
+		}
+		changed, err := shellenv.Apply(file, shellenv.Block(shell, env))
+		if err != nil {
+			return err
+		}
+		if !changed {
+			fmt.Printf("✓ %s is up to date\n", file)
+			return nil
+		}
+		fmt.Printf("✅ Updated %s\n", file)
+		for _, v := range env.Vars {
+			fmt.Printf("   %s=%s\n", v.Name, v.Value)
+		}
+		for _, dir := range env.Path {
+			fmt.Printf("   PATH += %s\n", dir)
+		}
+		fmt.Println("Open a new terminal, or load it now with:")
+		if shell == shellenv.PowerShell {
+			fmt.Printf("   . '%s'\n", file)
+		} else {
+			fmt.Printf("   source %s\n", file)
+		}
+		return nil
+	},
+}
+
+var envHookCmd = &cobra.Command{
+	Use:   "hook <shell>",
+	Short: i18n.T("Print a shell hook that applies goup.json env settings per project"),
+	Long: i18n.T(`Print a hook that, before each prompt, applies the env section of the
+goup.json in the current directory or its parents, and restores the previous
+values when you leave the project. Projects with different SDKs, Go
+toolchains or signing settings stay isolated from each other.
+
+Add it to your shell startup file:
+
+  eval "$(goup-util env hook zsh)"                              # ~/.zshrc
+  eval "$(goup-util env hook bash)"                             # ~/.bashrc
+  goup-util env hook fish | source                              # config.fish
+  goup-util env hook powershell | Out-String | Invoke-Expression  # $PROFILE
+
+goup.json:
+
+  {
+    "env": {
+      "go": "1.23.4",
+      "sdkDir": ".sdks",
+      "signing": {"ANDROID_KEYSTORE_FILE": "keys/release.jks"},
+      "vars": {"GOFLAGS": "-trimpath"}
+    }
+  }
+
+go sets GOTOOLCHAIN. sdkDir gives the project its own SDKs (GOUP_SDK_DIR):
+'goup-util install' puts them there and JAVA_HOME, ANDROID_HOME and PATH
+point at them. signing holds references to signing material (NAME_FILE
+paths, identities), never the secrets themselves.`),
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shellenv.Shells,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell, err := shellenv.Normalize(args[0])
+		if err != nil {
+			return err
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			exe = "goup-util"
+		}
+		hook, err := shellenv.Hook(shell, exe)
+		if err != nil {
+			return err
+		}
+		fmt.Print(hook)
+		return nil
+	},
+}
+
+var envExportCmd = &cobra.Command{
+	Use:   "export <shell>",
+	Short: i18n.T("Print the environment changes for the current directory (run by the hook)"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell, err := shellenv.Normalize(args[0])
+		if err != nil {
+			return err
+		}
+		// Work out the project's environment from what the shell had
+		// before any project was entered
+		shellenv.Restore()
+		lookup := shellenv.Snapshot()
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		proj, err := shellenv.LoadProject(cwd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  goup-util: %v\n", err)
+			proj = nil
+		}
+
+		var env *shellenv.Env
+		var dir string
+		if proj != nil {
+			if sdkDir := proj.SDKPath(); sdkDir != "" {
+				os.Setenv(config.SDKDirEnv, sdkDir)
+			}
+			cache, err := installer.NewCache(config.GetCachePath())
+			if err != nil {
+				return fmt.Errorf(i18n.T("could not load cache: %w"), err)
+			}
+			projectEnv, err := sdkEnv(cache)
+			if err != nil {
+				return err
+			}
+			if sdkDir := proj.SDKPath(); sdkDir != "" {
+				projectEnv.Set(config.SDKDirEnv, sdkDir)
+			}
+			proj.Apply(&projectEnv)
+			env, dir = &projectEnv, proj.Dir
+		}
+		fmt.Print(shellenv.Export(shell, dir, env, lookup))
+		return nil
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envHookCmd, envExportCmd)
+
+	envCmd.Flags().BoolVar(&envApply, "apply", false, i18n.T("Write the environment into your shell startup file"))
+	envCmd.Flags().BoolVar(&envRemove, "remove", false, i18n.T("Remove the goup-util block from your shell startup file"))
+	envCmd.Flags().StringVar(&envShell, "shell", "", i18n.T("Shell to target: bash, zsh, fish, powershell (default: detected)"))
+
+	envCmd.GroupID = "sdk"
+	rootCmd.AddCommand(envCmd)
+}
+
+// sdkEnv collects the variables and PATH entries for the SDKs that are
+// actually installed, so nothing points at a missing directory.
+func sdkEnv(cache *installer.Cache) (shellenv.Env, error) {
+	var env shellenv.Env
+
+	javaHome, err := getJavaHome(cache)
+	if err != nil {
+		return env, err
+	}
+	if javaHome != "" {
+		env.Set("JAVA_HOME", javaHome)
+		env.PrependPath(filepath.Join(javaHome, "bin"))
+	}
+
+	sdkRoot := config.GetSDKDir()
+	if !dirExists(sdkRoot) {
+		return env, nil
+	}
+	env.Set("ANDROID_HOME", sdkRoot)
+	env.Set("ANDROID_SDK_ROOT", sdkRoot)
+	if ndk := filepath.Join(sdkRoot, "ndk-bundle"); dirExists(ndk) {
+		env.Set("ANDROID_NDK_ROOT", ndk)
+	}
+
+	if entry, ok := cache.Entries[cmdLineTools]; ok {
+		if path, err := installer.ResolveInstallPath(entry.InstallPath); err == nil {
+			if bin := filepath.Join(path, "cmdline-tools", "bin"); dirExists(bin) {
+				env.PrependPath(bin)
+			}
+		}
+	}
+	for _, dir := range []string{"platform-tools", "emulator"} {
+		if path := filepath.Join(sdkRoot, dir); dirExists(path) {
+			env.PrependPath(path)
+		}
+	}
+	return env, nil
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/examples"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var examplesCmd = &cobra.Command{
+	Use:   "examples",
+	Short: i18n.T("List and run the bundled example apps"),
+	Long: i18n.T(`Browse the example apps that ship with goup-util, such as the webviewer
+shell and the hybrid dashboard, and build and launch one without knowing
+the repository layout.
+
+Inside a goup-util checkout the examples are used in place; elsewhere the
+repository is cloned into the cache directory on first use.`),
+}
+
+var examplesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("List the bundled example apps"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		root, err := examples.Root(config.GetCacheDir())
+		if err != nil {
+			return err
+		}
+		list, err := examples.List(root)
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(list)
+		}
+		fmt.Printf("📂 %s\n", root)
+		for _, ex := range list {
+			fmt.Printf("  %-24s %s\n", ex.Name, ex.Description)
+		}
+		fmt.Println("\nRun one with: goup-util examples run <name>")
+		return nil
+	},
+}
+
+var examplesRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: i18n.T("Build and launch a bundled example app"),
+	Long: i18n.T(`Build and launch a bundled example app, like goup-util run does for
+your own apps.
+
+The platform defaults to macos on macOS and android elsewhere. For
+android, --device picks the device or emulator by its adb serial.
+
+The examples build against local Gio checkouts under .src when those
+exist, and against the released modules their go.mod requires otherwise.`),
+	Example: `  goup-util examples run gio-plugin-webviewer
+  goup-util examples run hybrid-dashboard --platform android --device emulator-5554
+  goup-util examples run hybrid-dashboard --platform ios-simulator`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: getExampleNameCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platform, _ := cmd.Flags().GetString("platform")
+		device, _ := cmd.Flags().GetString("device")
+		force, _ := cmd.Flags().GetBool("force")
+		if platform == "" {
+			platform = defaultRunPlatform()
+		}
+		if err := checkRunPlatform(platform); err != nil {
+			return err
+		}
+		if device != "" {
+			if platform != "android" {
+				return errors.New(i18n.T("--device only applies to android"))
+			}
+			// adb targets ANDROID_SERIAL when several devices are attached
+			os.Setenv("ANDROID_SERIAL", device)
+		}
+
+		root, err := examples.Root(config.GetCacheDir())
+		if err != nil {
+			return err
+		}
+		ex, err := examples.Get(root, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🔎 %s: %s\n", ex.Name, ex.Description)
+
+		dir, err := examples.Prepare(ex, config.GetCacheDir())
+		if err != nil {
+			return err
+		}
+		return runApp(platform, dir, BuildOptions{Force: force})
+	},
+}
+
+func defaultRunPlatform() string {
+	if runtime.GOOS == "darwin" {
+		return "macos"
+	}
+	return "android"
+}
+
+func getExampleNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	root := examples.FindRoot(".")
+	if root == "" || len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	list, err := examples.List(root)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []string
+	for _, ex := range list {
+		completions = append(completions, ex.Name+"\t"+ex.Description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	examplesListCmd.Flags().Bool("json", false, i18n.T("Print the examples as JSON"))
+	examplesRunCmd.Flags().String("platform", "", i18n.T("Platform to run on: macos, android, ios-simulator (default: host)"))
+	examplesRunCmd.Flags().String("device", "", i18n.T("Android device serial to run on"))
+	examplesRunCmd.Flags().Bool("force", false, i18n.T("Force rebuild even if up-to-date"))
+	examplesRunCmd.RegisterFlagCompletionFunc("platform", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return runPlatforms(), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	examplesCmd.AddCommand(examplesListCmd, examplesRunCmd)
+	examplesCmd.GroupID = "build"
+	rootCmd.AddCommand(examplesCmd)
+}
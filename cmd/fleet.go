@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/fleet"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/licensing"
+	"github.com/joeblew999/goup-util/pkg/secrets"
+	"github.com/spf13/cobra"
+)
+
+// Fleet tokens are read from these variables by 'update-server serve
+// --fleet' and 'goup-util fleet'.
+const (
+	FleetTokenEnv      = "GOUP_FLEET_TOKEN"
+	FleetAdminTokenEnv = "GOUP_FLEET_ADMIN_TOKEN"
+	FleetURLEnv        = "GOUP_FLEET_URL"
+)
+
+var (
+	fleetServer string
+	fleetStale  time.Duration
+	fleetJSON   bool
+)
+
+var fleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: i18n.T("List the installs that check in with an update server"),
+	Long: `See which shells are installed where, which version they run and when they
+were last seen — for kiosk and signage deployments with many devices.
+
+Shells check in when app.json has a "fleet" section:
+
+  "fleet": {"url": "https://updates.example.com", "token": "<enrollment token>"}
+
+The registry is kept by 'goup-util update-server serve --fleet'. Listing it
+needs the admin token in $` + FleetAdminTokenEnv + `.
+
+Operators can also send commands — reload, clear-cache, update, screenshot —
+which devices pick up with their next check-in. Commands are signed with a
+key from 'goup-util fleet keygen'; shells only run commands that verify
+against the public key in their app.json ("fleet": {"commandKey": ...}).`,
+	Example: `  export ` + FleetURLEnv + `=https://updates.example.com
+  goup-util fleet list
+  goup-util fleet list --stale 1h --json
+  goup-util fleet status 3f9a1c0d2b7e
+  goup-util fleet send 3f9a1c0d2b7e reload --url https://kiosk.example.com/menu
+  goup-util fleet send 3f9a1c0d2b7e screenshot && goup-util fleet screenshot 3f9a1c0d2b7e`,
+}
+
+var fleetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("List registered devices, most recently seen first"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := fleetClient()
+		if err != nil {
+			return err
+		}
+		devices, err := client.Devices(cmd.Context())
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to list fleet: %w"), err)
+		}
+		if fleetJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(devices)
+		}
+		if len(devices) == 0 {
+			fmt.Println("No devices have checked in")
+			return nil
+		}
+
+		now := time.Now()
+		online := 0
+		fmt.Printf("%-8s %-20s %-20s %-16s %-10s %-14s %s\n", "STATUS", "DEVICE", "NAME", "APP", "VERSION", "PLATFORM", "LAST SEEN")
+		for _, d := range devices {
+			mark := "offline"
+			if d.Online(now, fleetStale) {
+				mark = "online"
+				online++
+			}
+			fmt.Printf("%-8s %-20s %-20s %-16s %-10s %-14s %s\n", mark, fleetTrunc(d.ID, 20), fleetTrunc(d.Name, 20), fleetTrunc(d.App, 16), d.Version, d.Platform, fleetAgo(now, d.LastSeen))
+		}
+		fmt.Printf("\n%d device(s), %d online, %d offline (no check-in for %s)\n", len(devices), online, len(devices)-online, fleetStale)
+		return nil
+	},
+}
+
+var fleetStatusCmd = &cobra.Command{
+	Use:   "status <device-id>",
+	Short: i18n.T("Show one device"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := fleetClient()
+		if err != nil {
+			return err
+		}
+		d, err := client.Device(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to get device: %w"), err)
+		}
+		if fleetJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(d)
+		}
+
+		now := time.Now()
+		status := "❌ offline"
+		if d.Online(now, fleetStale) {
+			status = "✅ online"
+		}
+		fmt.Printf("Device:      %s\n", d.ID)
+		fmt.Printf("Status:      %s\n", status)
+		fmt.Printf("Name:        %s\n", d.Name)
+		fmt.Printf("App:         %s %s\n", d.App, d.Version)
+		fmt.Printf("Platform:    %s\n", d.Platform)
+		if d.Environment != "" {
+			fmt.Printf("Environment: %s\n", d.Environment)
+		}
+		fmt.Printf("Address:     %s\n", d.Addr)
+		fmt.Printf("First seen:  %s\n", d.FirstSeen.Local().Format("2006-01-02 15:04"))
+		fmt.Printf("Last seen:   %s (%s)\n", d.LastSeen.Local().Format("2006-01-02 15:04"), fleetAgo(now, d.LastSeen))
+		if len(d.Commands) > 0 {
+			fmt.Printf("Queued:      %d command(s), delivered at the next check-in\n", len(d.Commands))
+		}
+		if len(d.Results) > 0 {
+			fmt.Println("\nRecent commands:")
+			for _, r := range d.Results {
+				if r.OK {
+					fmt.Printf("  ✓ %-12s %s  %s\n", r.Type, r.Command, r.At.Local().Format("2006-01-02 15:04"))
+				} else {
+					fmt.Printf("  ❌ %-12s %s  %s  %s\n", r.Type, r.Command, r.At.Local().Format("2006-01-02 15:04"), r.Error)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+var fleetKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: i18n.T("Create a key pair for signing fleet commands"),
+	Long: i18n.T(`Create fleet-public.key, for "fleet": {"commandKey": ...} in app.json, and
+fleet-private.key, to sign commands. Store the private key with
+'goup-util secrets set FLEET_COMMAND_KEY --file fleet-private.key' and
+delete the file.`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, _ := cmd.Flags().GetString("out")
+		pubPath := filepath.Join(out, "fleet-public.key")
+		privPath := filepath.Join(out, "fleet-private.key")
+		for _, p := range []string{pubPath, privPath} {
+			if _, err := os.Stat(p); err == nil {
+				return fmt.Errorf(i18n.T("%s already exists; deployed shells would stop accepting commands signed with a new key"), p)
+			}
+		}
+
+		pub, priv, err := licensing.GenerateKey()
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to generate key: %w"), err)
+		}
+		if err := os.MkdirAll(out, 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(privPath, []byte(priv+"\n"), 0600); err != nil {
+			return err
+		}
+		if err := os.WriteFile(pubPath, []byte(pub+"\n"), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Public key:  %s\n   %s\n", pubPath, pub)
+		fmt.Printf("🔑 Private key: %s\n", privPath)
+		fmt.Printf("   Store it with: goup-util secrets set FLEET_COMMAND_KEY --file %s\n", privPath)
+		return nil
+	},
+}
+
+var fleetSendCmd = &cobra.Command{
+	Use:   "send <device-id> <reload|clear-cache|update|screenshot>",
+	Short: i18n.T("Queue a signed command for a device"),
+	Long: i18n.T(`Sign a command with the FLEET_COMMAND_KEY secret (or --key) and queue it on
+the server. The device runs it after its next check-in and reports the
+result, shown by 'goup-util fleet status'.
+
+  reload       load --url, or the start page, in every tab
+  clear-cache  clear the webview cache and reload
+  update       install the latest release now and restart
+  screenshot   capture the screen; fetch it with 'goup-util fleet screenshot'
+
+Devices ignore commands older than --expires.`),
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keyPath, _ := cmd.Flags().GetString("key")
+		target, _ := cmd.Flags().GetString("url")
+		ttl, _ := cmd.Flags().GetDuration("expires")
+
+		var key string
+		if keyPath != "" {
+			data, err := os.ReadFile(keyPath)
+			if err != nil {
+				return err
+			}
+			key = string(data)
+		} else {
+			var err error
+			if key, err = secrets.Open("").Text("FLEET_COMMAND_KEY"); err != nil {
+				return err
+			}
+			if key == "" {
+				return errors.New(i18n.T("no signing key: set FLEET_COMMAND_KEY (see 'goup-util fleet keygen') or pass --key"))
+			}
+		}
+
+		c, err := fleet.NewCommand(args[0], args[1], ttl)
+		if err != nil {
+			return err
+		}
+		if target != "" && c.Type != fleet.CommandReload {
+			return errors.New(i18n.T("--url only applies to reload"))
+		}
+		c.URL = target
+		sc, err := fleet.Sign(key, c)
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to sign command: %w"), err)
+		}
+		client, err := fleetClient()
+		if err != nil {
+			return err
+		}
+		if err := client.Queue(cmd.Context(), sc); err != nil {
+			return fmt.Errorf(i18n.T("failed to queue command: %w"), err)
+		}
+		fmt.Printf("✓ Queued %s for %s (command %s, expires %s)\n", c.Type, c.Device, c.ID, c.Expires.Local().Format("2006-01-02 15:04"))
+		return nil
+	},
+}
+
+var fleetScreenshotCmd = &cobra.Command{
+	Use:   "screenshot <device-id>",
+	Short: i18n.T("Download the latest screenshot from a device"),
+	Long: i18n.T(`Download the screenshot a device uploaded after a 'goup-util fleet send
+<device-id> screenshot' command.`),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			output = args[0] + ".png"
+		}
+		client, err := fleetClient()
+		if err != nil {
+			return err
+		}
+		png, err := client.Screenshot(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to get screenshot: %w"), err)
+		}
+		if err := os.WriteFile(output, png, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("✓ %s (%s)\n", output, formatBytes(int64(len(png))))
+		return nil
+	},
+}
+
+func fleetClient() (*fleet.Client, error) {
+	if fleetServer == "" {
+		return nil, fmt.Errorf(i18n.T("no fleet server; pass --server or set $%s"), FleetURLEnv)
+	}
+	return fleet.NewClient(fleetServer, os.Getenv(FleetAdminTokenEnv)), nil
+}
+
+func fleetTrunc(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func fleetAgo(now, t time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+func init() {
+	fleetCmd.PersistentFlags().StringVar(&fleetServer, "server", os.Getenv(FleetURLEnv), "Update server URL (default $"+FleetURLEnv+")")
+	fleetCmd.PersistentFlags().DurationVar(&fleetStale, "stale", fleet.DefaultStaleAfter, i18n.T("Count a device offline after this long without a check-in"))
+	fleetCmd.PersistentFlags().BoolVar(&fleetJSON, "json", false, i18n.T("Output JSON"))
+
+	fleetKeygenCmd.Flags().String("out", ".", i18n.T("Directory for the key files"))
+	fleetSendCmd.Flags().String("url", "", i18n.T("Page to load (reload only)"))
+	fleetSendCmd.Flags().Duration("expires", fleet.DefaultCommandTTL, i18n.T("Devices ignore the command after this long"))
+	fleetSendCmd.Flags().String("key", "", i18n.T("Private key file (default: the FLEET_COMMAND_KEY secret)"))
+	fleetScreenshotCmd.Flags().StringP("output", "o", "", i18n.T("Output file (default <device-id>.png)"))
+
+	fleetCmd.AddCommand(fleetListCmd)
+	fleetCmd.AddCommand(fleetStatusCmd)
+	fleetCmd.AddCommand(fleetKeygenCmd)
+	fleetCmd.AddCommand(fleetSendCmd)
+	fleetCmd.AddCommand(fleetScreenshotCmd)
+	rootCmd.AddCommand(fleetCmd)
+	fleetCmd.GroupID = "tools"
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/icons"
 	"github.com/joeblew999/goup-util/pkg/project"
 	"github.com/spf13/cobra"
@@ -12,8 +13,8 @@ import (
 
 var generateTestIconCmd = &cobra.Command{
 	Use:   "generate-test-icon [app-directory]",
-	Short: "Generate a test icon for a Gio project.",
-	Long:  `Generate a test icon for a Gio project. If no directory is specified, uses 'example-gio-app'.`,
+	Short: i18n.T("Generate a test icon for a Gio project."),
+	Long:  i18n.T(`Generate a test icon for a Gio project. If no directory is specified, uses 'example-gio-app'.`),
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Determine app directory
@@ -25,7 +26,7 @@ var generateTestIconCmd = &cobra.Command{
 		// Create project instance
 		proj, err := project.NewGioProject(appDir)
 		if err != nil {
-			return fmt.Errorf("failed to create project: %w", err)
+			return fmt.Errorf(i18n.T("failed to create project: %w"), err)
 		}
 
 		// Generate test icon
@@ -34,11 +35,11 @@ var generateTestIconCmd = &cobra.Command{
 
 		// Ensure directory exists
 		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
+			return fmt.Errorf(i18n.T("failed to create directory: %w"), err)
 		}
 
 		if err := icons.GenerateTestIcon(outputPath); err != nil {
-			return fmt.Errorf("failed to generate test icon: %w", err)
+			return fmt.Errorf(i18n.T("failed to generate test icon: %w"), err)
 		}
 
 		fmt.Printf("Generated test icon: %s\n", outputPath)
@@ -5,14 +5,15 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 )
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
-	Short: "Generate project artifacts (docs, etc.)",
-	Long: `Generate various project artifacts.
+	Short: i18n.T("Generate project artifacts (docs, etc.)"),
+	Long: i18n.T(`Generate various project artifacts.
 
 This command is used internally to keep generated files up-to-date.
 It's typically run as part of CI/CD or release preparation.
@@ -22,20 +23,20 @@ Examples:
   goup-util generate all
 
   # Generate only documentation
-  goup-util generate docs`,
+  goup-util generate docs`),
 }
 
 var generateAllCmd = &cobra.Command{
 	Use:   "all",
-	Short: "Generate all artifacts (docs, etc.)",
-	Long: `Generate all project artifacts.
+	Short: i18n.T("Generate all artifacts (docs, etc.)"),
+	Long: i18n.T(`Generate all project artifacts.
 
 This runs all generation tasks:
 - CLI documentation (markdown)
 
 Examples:
   goup-util generate all
-  goup-util generate all --output-dir ./custom-docs`,
+  goup-util generate all --output-dir ./custom-docs`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		outputDir, _ := cmd.Flags().GetString("output-dir")
 
@@ -45,7 +46,7 @@ Examples:
 		// Generate docs
 		fmt.Println("1. Generating CLI documentation...")
 		if err := generateDocs(outputDir); err != nil {
-			return fmt.Errorf("failed to generate docs: %w", err)
+			return fmt.Errorf(i18n.T("failed to generate docs: %w"), err)
 		}
 
 		fmt.Println()
@@ -56,15 +57,15 @@ Examples:
 
 var generateDocsCmd = &cobra.Command{
 	Use:   "docs [output-dir]",
-	Short: "Generate CLI documentation",
-	Long: `Generate CLI documentation in markdown format.
+	Short: i18n.T("Generate CLI documentation"),
+	Long: i18n.T(`Generate CLI documentation in markdown format.
 
 This generates documentation for all goup-util commands.
 Output is written to docs/cli/ by default.
 
 Examples:
   goup-util generate docs
-  goup-util generate docs ./my-docs`,
+  goup-util generate docs ./my-docs`),
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		outputDir := "docs/cli"
@@ -79,13 +80,13 @@ Examples:
 func generateDocs(outputDir string) error {
 	// Create output directory
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 	}
 
 	fmt.Printf("   Generating markdown to %s/\n", outputDir)
 
 	if err := doc.GenMarkdownTree(rootCmd, outputDir); err != nil {
-		return fmt.Errorf("failed to generate markdown: %w", err)
+		return fmt.Errorf(i18n.T("failed to generate markdown: %w"), err)
 	}
 
 	// Count generated files
@@ -96,7 +97,7 @@ func generateDocs(outputDir string) error {
 }
 
 func init() {
-	generateAllCmd.Flags().String("output-dir", "docs/cli", "Output directory for generated docs")
+	generateAllCmd.Flags().String("output-dir", "docs/cli", i18n.T("Output directory for generated docs"))
 
 	generateCmd.AddCommand(generateAllCmd)
 	generateCmd.AddCommand(generateDocsCmd)
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/securestore"
+	"github.com/spf13/cobra"
+)
+
+var githubCmd = &cobra.Command{
+	Use:   "github",
+	Short: i18n.T("Sign in to GitHub for private releases and higher rate limits"),
+	Long: i18n.T(`Authenticate goup-util's GitHub API calls: release lookups, 'self upgrade',
+asset downloads from private repositories and issues opened by the update
+crash guard.
+
+The token is the first of:
+
+  GITHUB_TOKEN, GH_TOKEN   environment variables (CI)
+  login                    saved by 'github login' in the system credential
+                           store (Keychain, DPAPI, Secret Service)
+
+'github login' runs GitHub's device flow: it prints a code to enter at
+github.com/login/device. It needs the client ID of an OAuth or GitHub App
+with the device flow enabled, from --client-id or $GOUP_GITHUB_CLIENT_ID.
+
+Examples:
+  goup-util github login --client-id Iv1.0123456789abcdef
+  goup-util github status
+  goup-util github logout`),
+}
+
+var githubLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: i18n.T("Sign in with the device flow and save the token"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientID, _ := cmd.Flags().GetString("client-id")
+		scope, _ := cmd.Flags().GetString("scope")
+		if clientID == "" {
+			clientID = os.Getenv(ghapi.ClientIDEnv)
+		}
+		if clientID == "" {
+			return fmt.Errorf(i18n.T("--client-id is required (an OAuth or GitHub App with the device flow enabled, or set $%s)"), ghapi.ClientIDEnv)
+		}
+
+		client := ghapi.New()
+		dc, err := client.StartDeviceFlow(clientID, scope)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("→ Open %s and enter the code %s\n", dc.VerificationURI, dc.UserCode)
+		token, err := client.WaitForToken(clientID, dc)
+		if err != nil {
+			return err
+		}
+		if err := ghapi.SaveToken(ghapi.Service, token); err != nil {
+			return fmt.Errorf(i18n.T("failed to save the token in the %s store: %w"), securestore.Backend(), err)
+		}
+		client.Token = token
+		login, err := client.User()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Signed in as %s (saved in the %s store)\n", login, securestore.Backend())
+		for _, env := range ghapi.TokenEnvs {
+			if os.Getenv(env) != "" {
+				fmt.Printf("⚠️  $%s is set and is used instead\n", env)
+				break
+			}
+		}
+		return nil
+	},
+}
+
+var githubStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: i18n.T("Show which GitHub token is used and whose it is"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		status := struct {
+			Source string `json:"source,omitempty"`
+			Login  string `json:"login,omitempty"`
+			Error  string `json:"error,omitempty"`
+		}{}
+		for _, env := range ghapi.TokenEnvs {
+			if os.Getenv(env) != "" {
+				status.Source = "$" + env
+				break
+			}
+		}
+		if status.Source == "" && ghapi.StoredToken(ghapi.Service) != "" {
+			status.Source = securestore.Backend()
+		}
+
+		client := ghapi.New()
+		if client.Token != "" {
+			var err error
+			if status.Login, err = client.User(); err != nil {
+				status.Error = err.Error()
+			}
+		}
+
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(status)
+		}
+		switch {
+		case status.Source == "":
+			fmt.Println("⚠️  Not signed in: public repositories only, 60 API requests an hour")
+			fmt.Println("   Run 'goup-util github login' or set GITHUB_TOKEN")
+		case status.Error != "":
+			fmt.Printf("❌ Token from %s: %s\n", status.Source, status.Error)
+		default:
+			fmt.Printf("✓ Signed in as %s (token from %s)\n", status.Login, status.Source)
+		}
+		return nil
+	},
+}
+
+var githubLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: i18n.T("Forget the saved token"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := ghapi.DeleteToken(ghapi.Service); err != nil {
+			return err
+		}
+		fmt.Println("✓ Signed out")
+		return nil
+	},
+}
+
+func init() {
+	githubLoginCmd.Flags().String("client-id", "", "OAuth or GitHub App client ID (default $"+ghapi.ClientIDEnv+")")
+	githubLoginCmd.Flags().String("scope", "repo", i18n.T("OAuth scopes to request (repo reads private releases and opens issues)"))
+	githubStatusCmd.Flags().Bool("json", false, i18n.T("Print the status as JSON"))
+
+	githubCmd.AddCommand(githubLoginCmd)
+	githubCmd.AddCommand(githubStatusCmd)
+	githubCmd.AddCommand(githubLogoutCmd)
+	githubCmd.GroupID = "tools"
+	rootCmd.AddCommand(githubCmd)
+}
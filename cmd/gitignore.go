@@ -7,13 +7,14 @@ import (
 
 	"github.com/joeblew999/goup-util/pkg/constants"
 	"github.com/joeblew999/goup-util/pkg/gitignore"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 var gitignoreCmd = &cobra.Command{
 	Use:   "gitignore [project-path]",
-	Short: "Manage .gitignore files for Gio projects",
-	Long:  `Manage .gitignore files for Gio projects. Shows status and can generate appropriate gitignore patterns.`,
+	Short: i18n.T("Manage .gitignore files for Gio projects"),
+	Long:  i18n.T(`Manage .gitignore files for Gio projects. Shows status and can generate appropriate gitignore patterns.`),
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		projectPath := "."
@@ -23,12 +24,12 @@ var gitignoreCmd = &cobra.Command{
 
 		// Check if project path exists
 		if _, err := os.Stat(projectPath); os.IsNotExist(err) {
-			return fmt.Errorf("project path does not exist: %s", projectPath)
+			return fmt.Errorf(i18n.T("project path does not exist: %s"), projectPath)
 		}
 
 		gi := gitignore.New(projectPath)
 		if err := gi.Load(); err != nil {
-			return fmt.Errorf("failed to load .gitignore: %w", err)
+			return fmt.Errorf(i18n.T("failed to load .gitignore: %w"), err)
 		}
 
 		// Show status
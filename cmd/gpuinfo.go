@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/joeblew999/goup-util/pkg/gpuinfo"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var gpuInfoCmd = &cobra.Command{
+	Use:   "gpu-info",
+	Short: i18n.T("Show the GPU, graphics APIs and renderer Gio apps use here"),
+	Long: i18n.T(`Report what a Gio app renders with on this machine: the GPUs and their
+drivers, which of Metal, Direct3D 11, OpenGL ES and Vulkan are available,
+the backend Gio picks from them, and whether the system webview
+(WKWebView, WebView2 or WebKitGTK) is GPU accelerated.
+
+Use it when a user reports a blank or flickering window. If a driver bug
+is the cause, start the shell with --software-render to render the
+webview without the GPU.`),
+	Example: `  goup-util gpu-info
+  goup-util gpu-info --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		r, err := gpuinfo.Detect()
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(r)
+		}
+
+		fmt.Printf("Platform: %s\n", r.Platform)
+		fmt.Println("GPUs:")
+		if len(r.GPUs) == 0 {
+			fmt.Println("  ⚠️  none found")
+		}
+		for _, g := range r.GPUs {
+			fmt.Printf("  %s\n", g.Name)
+			for _, kv := range [][2]string{{"Vendor", g.Vendor}, {"Driver", g.Driver}, {"Memory", g.Memory}, {"Metal", g.Metal}} {
+				if kv[1] != "" {
+					fmt.Printf("    %-7s %s\n", kv[0]+":", kv[1])
+				}
+			}
+		}
+		fmt.Println("Graphics APIs:")
+		for _, a := range r.APIs {
+			mark := "✓"
+			if !a.Available {
+				mark = "❌"
+			}
+			fmt.Printf("  %s %-18s %s\n", mark, a.Name, a.Detail)
+		}
+		fmt.Printf("Gio backend: %s\n", r.Backend)
+
+		mark := "✓"
+		state := "GPU accelerated"
+		if !r.WebView.Accelerated {
+			mark, state = "⚠️ ", "software rendering"
+		}
+		fmt.Printf("WebView: %s %s, %s\n", mark, r.WebView.Engine, state)
+		if r.WebView.Detail != "" {
+			fmt.Printf("  %s\n", r.WebView.Detail)
+		}
+		fmt.Printf("\n--software-render: %s\n", r.SoftwareRender)
+		return nil
+	},
+}
+
+func init() {
+	gpuInfoCmd.Flags().Bool("json", false, i18n.T("Print the report as JSON"))
+	gpuInfoCmd.GroupID = "tools"
+	rootCmd.AddCommand(gpuInfoCmd)
+}
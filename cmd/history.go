@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/history"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyFilter history.Filter
+	historySince  string
+	historyJSON   bool
+	historyBefore string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: i18n.T("Show recorded builds, SDK installs, screenshots and releases"),
+	Long: `Show the history of builds, SDK installs, screenshots and releases
+recorded in a local SQLite database, with timings and outcomes.
+
+The database lives in the goup-util cache directory. Set $` + history.Env + `=off
+to stop recording, or to a path to use another database (e.g. one shared
+by a team's build machines).
+
+Examples:
+  goup-util history
+  goup-util history --kind build --target android --since 7d
+  goup-util history --status failed --limit 10
+  goup-util history --since 30d --json > builds.json
+  goup-util history stats --since 30d
+  goup-util history prune --before 90d`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openHistory()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		events, err := db.Query(historyFilter)
+		if err != nil {
+			return err
+		}
+		if historyJSON {
+			if events == nil {
+				events = []history.Event{}
+			}
+			return writeJSONOut(events)
+		}
+		if len(events) == 0 {
+			fmt.Println("No history recorded yet")
+			return nil
+		}
+		for _, e := range events {
+			mark := "✓"
+			if e.Status == history.Failed {
+				mark = "❌"
+			}
+			target := e.Target
+			if target == "" {
+				target = "-"
+			}
+			fmt.Printf("%s %s  %-10s %-14s %-24s %8s\n", mark, e.Started.Format("2006-01-02 15:04"), e.Kind, target, e.Name, e.Duration.Round(time.Second))
+			if e.Error != "" {
+				fmt.Printf("     %s\n", firstLine(e.Error))
+			}
+		}
+		return nil
+	},
+}
+
+var historyStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: i18n.T("Summarize history by kind and target"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openHistory()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		stats, err := db.Stats(historyFilter)
+		if err != nil {
+			return err
+		}
+		if historyJSON {
+			if stats == nil {
+				stats = []history.Stat{}
+			}
+			return writeJSONOut(stats)
+		}
+		if len(stats) == 0 {
+			fmt.Println("No history recorded yet")
+			return nil
+		}
+		fmt.Printf("%-10s %-14s %6s %7s %9s %9s  %s\n", "KIND", "TARGET", "RUNS", "FAILED", "AVERAGE", "LONGEST", "LAST")
+		for _, s := range stats {
+			fmt.Printf("%-10s %-14s %6d %7d %9s %9s  %s\n", s.Kind, s.Target, s.Count, s.Failed,
+				s.Average.Round(time.Second), s.Longest.Round(time.Second), s.LastRun.Format("2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: i18n.T("Delete old history"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		before, err := parseSince(historyBefore)
+		if err != nil {
+			return err
+		}
+		db, err := openHistory()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		n, err := db.Prune(before)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Deleted %d event(s) from before %s\n", n, before.Format("2006-01-02"))
+		return nil
+	},
+}
+
+func openHistory() (*history.DB, error) {
+	since, err := parseSince(historySince)
+	if err != nil {
+		return nil, err
+	}
+	historyFilter.Since = since
+	return history.Open(history.Path())
+}
+
+// parseSince accepts a date (2026-01-31), a duration (36h) or a number of
+// days (7d), the latter two counted back from now.
+func parseSince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf(i18n.T("invalid time %q (use 2026-01-31, 7d or 36h)"), s)
+}
+
+func writeJSONOut(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func init() {
+	pf := historyCmd.PersistentFlags()
+	pf.StringVar(&historyFilter.Kind, "kind", "", "Only this kind: build, install, screenshot or release")
+	pf.StringVar(&historyFilter.Name, "name", "", "Only this app, SDK or file")
+	pf.StringVar(&historyFilter.Target, "target", "", "Only this platform or device")
+	pf.StringVar(&historyFilter.Status, "status", "", "Only ok or failed")
+	pf.StringVar(&historySince, "since", "", "Only events since a date (2026-01-31) or age (7d, 36h)")
+	pf.BoolVar(&historyJSON, "json", false, "Print as JSON")
+	historyCmd.Flags().IntVar(&historyFilter.Limit, "limit", 50, i18n.T("Maximum events to show (0 = all)"))
+	historyPruneCmd.Flags().StringVar(&historyBefore, "before", "90d", i18n.T("Delete events before a date or age"))
+
+	historyCmd.AddCommand(historyStatsCmd)
+	historyCmd.AddCommand(historyPruneCmd)
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.GroupID = "tools"
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/constants"
+	"github.com/joeblew999/goup-util/pkg/hooks"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/spf13/cobra"
+)
+
+type runE func(cmd *cobra.Command, args []string) error
+
+// withHooks wraps a "<op> <platform> <app-directory>" command with the
+// pre_<op> and post_<op> hooks from the app's goup.json.
+func withHooks(op string, run runE) runE {
+	return func(cmd *cobra.Command, args []string) error {
+		noHooks, _ := cmd.Flags().GetBool("no-hooks")
+		checkOnly, _ := cmd.Flags().GetBool("check")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if noHooks || checkOnly || dryRun || len(args) < 2 {
+			return run(cmd, args)
+		}
+		cfg, err := hooks.Load(args[1])
+		if err != nil {
+			return err
+		}
+		if cfg == nil {
+			return run(cmd, args)
+		}
+
+		ctx := hooks.Context{
+			Operation: op,
+			Platform:  args[0],
+			GoupUtil:  rootCmd.Version,
+		}
+		ctx.AppDir, _ = filepath.Abs(args[1])
+		ctx.Project = filepath.Base(ctx.AppDir)
+		if f := cmd.Flags().Lookup("version"); f != nil {
+			ctx.Version = f.Value.String()
+		}
+		if err := cfg.Pre(ctx); err != nil {
+			return err
+		}
+
+		started := time.Now()
+		err = run(cmd, args)
+		ctx.Artifact = hookArtifact(op, ctx.AppDir, args[0])
+		if herr := cfg.Post(ctx, time.Since(started), err); err == nil {
+			err = herr
+		}
+		return err
+	}
+}
+
+// hookArtifact is what an operation produced: the build output, or the
+// distribution directory for bundle and package.
+func hookArtifact(op, appDir, platform string) string {
+	if op != "build" {
+		return filepath.Join(appDir, constants.DistDir)
+	}
+	proj, err := project.NewGioProject(appDir)
+	if err != nil || platform == "all" {
+		return filepath.Join(appDir, constants.BinDir)
+	}
+	return proj.GetOutputPath(platform)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{buildCmd, bundleCmd, packageCmd} {
+		c.Flags().Bool("no-hooks", false, i18n.T("Skip the hooks in goup.json"))
+		c.RunE = withHooks(c.Name(), c.RunE)
+	}
+}
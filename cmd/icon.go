@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/icons"
 	"github.com/spf13/cobra"
 )
@@ -10,8 +11,8 @@ import (
 // iconCmd represents the icon command (DEPRECATED)
 var iconCmd = &cobra.Command{
 	Use:   "icon",
-	Short: "[DEPRECATED] Generate platform-specific icons from a source image. Use 'icons' instead.",
-	Long: `[DEPRECATED] Generates platform-specific icons from a source PNG image.
+	Short: i18n.T("[DEPRECATED] Generate platform-specific icons from a source image. Use 'icons' instead."),
+	Long: i18n.T(`[DEPRECATED] Generates platform-specific icons from a source PNG image.
 
 This command is deprecated in favor of the project-aware 'icons' command.
 Please use 'goup-util icons [platform] [project-directory]' instead.
@@ -20,7 +21,7 @@ Examples (deprecated):
   goup-util icon --input icon.png --output ./out --platform android
 
 Recommended (new):
-  goup-util icons android ./my-project`,
+  goup-util icons android ./my-project`),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Show deprecation warning
 		fmt.Println("⚠️  WARNING: The 'icon' command is deprecated.")
@@ -46,7 +47,7 @@ Recommended (new):
 
 func init() {
 	rootCmd.AddCommand(iconCmd)
-	iconCmd.Flags().StringP("input", "i", "", "Input PNG image file")
-	iconCmd.Flags().StringP("output", "o", "", "Output file or directory")
-	iconCmd.Flags().StringP("platform", "p", "", "Platform (macos, android, ios, windows-ico, windows-msix)")
+	iconCmd.Flags().StringP("input", "i", "", i18n.T("Input PNG image file"))
+	iconCmd.Flags().StringP("output", "o", "", i18n.T("Output file or directory"))
+	iconCmd.Flags().StringP("platform", "p", "", i18n.T("Platform (macos, android, ios, windows-ico, windows-msix)"))
 }
@@ -1,9 +1,15 @@
 package cmd
 
 import (
-	"github.com/joeblew999/goup-util/pkg/utils"
+	"errors"
 	"fmt"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/utils"
+	"os"
+	"strings"
 
+	"github.com/joeblew999/goup-util/pkg/icons"
+	"github.com/joeblew999/goup-util/pkg/project"
 	"github.com/joeblew999/goup-util/pkg/service"
 	"github.com/spf13/cobra"
 )
@@ -11,24 +17,26 @@ import (
 // iconsCmd represents the project-aware icons command
 var iconsCmd = &cobra.Command{
 	Use:   "icons [platform] [project-directory]",
-	Short: "Generate platform-specific icons for a Gio project",
-	Long: `Generate platform-specific icons for a Gio project using project-aware paths.
+	Short: i18n.T("Generate platform-specific icons for a Gio project"),
+	Long: i18n.T(`Generate platform-specific icons for a Gio project using project-aware paths.
 This command automatically manages source icons and output directories based on the project structure.
 
 Examples:
   goup-util icons android ./my-gio-app
   goup-util icons ios ./my-gio-app
   goup-util icons windows ./my-gio-app
-  goup-util icons macos ./my-gio-app`,
+  goup-util icons macos ./my-gio-app
+  goup-util icons watchos ./my-gio-app
+  goup-util icons tvos ./my-gio-app       # layered: icon-source-back.png / -front.png if present
+  goup-util icons visionos ./my-gio-app   # layered: icon-source-middle.png / -front.png if present`),
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		platform := args[0]
 		projectDir := args[1]
 
 		// Validate platform
-		validPlatforms := []string{"android", "ios", "macos", "windows", "windows-msix", "windows-ico"}
-		if !utils.Contains(validPlatforms, platform) {
-			return fmt.Errorf("invalid platform: %s. Valid platforms: %v", platform, validPlatforms)
+		if err := utils.ValidatePlatform(platform, utils.IconPlatforms); err != nil {
+			return err
 		}
 
 		// Get maintenance flags
@@ -55,11 +63,11 @@ Examples:
 
 		resp, err := svc.GenerateIcons(req)
 		if err != nil {
-			return fmt.Errorf("service error: %w", err)
+			return fmt.Errorf(i18n.T("service error: %w"), err)
 		}
 
 		if !resp.Success {
-			return fmt.Errorf("failed: %s", resp.Error)
+			return fmt.Errorf(i18n.T("failed: %s"), resp.Error)
 		}
 
 		fmt.Printf("✓ %s\n", resp.Message)
@@ -67,14 +75,72 @@ Examples:
 	},
 }
 
+var iconsValidateCmd = &cobra.Command{
+	Use:   "validate [project-directory]",
+	Short: i18n.T("Check the source icon against store requirements"),
+	Long: i18n.T(`Check icon-source.png before building: PNG format, square, at least
+1024x1024 for iOS and macOS (512 for Android, 256 for Windows), opaque for
+the iOS App Store, 8-bit RGB(A) and sRGB. Errors also stop 'goup-util build'
+before it starts; warnings are advisory.
+
+Examples:
+  goup-util icons validate ./my-gio-app
+  goup-util icons validate ./my-gio-app --platform android,windows
+  goup-util icons validate ./my-gio-app --json`),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		proj, err := project.NewGioProject(args[0])
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to create project: %w"), err)
+		}
+		path := proj.Paths().SourceIcon
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf(i18n.T("no source icon at %s (add one, or let 'goup-util icons' create a placeholder)"), path)
+		}
+
+		platforms, _ := cmd.Flags().GetStringSlice("platform")
+		report, err := icons.Validate(path, platforms...)
+		if err != nil {
+			return err
+		}
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if asJSON {
+			if err := writeJSONOut(report); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("%s: %dx%d %s (%s)\n", path, report.Width, report.Height, report.Format, strings.Join(report.Platforms, ", "))
+			for _, issue := range report.Issues {
+				mark := "⚠️ "
+				if issue.Severity == icons.SeverityError {
+					mark = "❌"
+				}
+				fmt.Printf("%s %s\n   → %s\n", mark, issue.Message, issue.Fix)
+			}
+		}
+		if !report.OK() {
+			return errors.New(i18n.T("icon has errors"))
+		}
+		if !asJSON {
+			fmt.Println("✓ Icon is ready for all selected platforms")
+		}
+		return nil
+	},
+}
+
 func init() {
+	iconsValidateCmd.Flags().StringSlice("platform", nil, i18n.T("Platforms to check for (ios, macos, android, windows; default all)"))
+	iconsValidateCmd.Flags().Bool("json", false, i18n.T("Print the report as JSON"))
+	iconsCmd.AddCommand(iconsValidateCmd)
+
 	// Group for help organization
 	iconsCmd.GroupID = "tools"
+	iconsCmd.ValidArgsFunction = getIconPlatformCompletion
 
 	rootCmd.AddCommand(iconsCmd)
 
 	// Add maintenance flags
-	iconsCmd.Flags().Bool("auto-maintain", false, "Enable automatic maintenance checks")
-	iconsCmd.Flags().Bool("auto-fix", false, "Automatically fix issues found (requires --auto-maintain)")
-	iconsCmd.Flags().BoolP("verbose", "v", false, "Show detailed maintenance actions")
+	iconsCmd.Flags().Bool("auto-maintain", false, i18n.T("Enable automatic maintenance checks"))
+	iconsCmd.Flags().Bool("auto-fix", false, i18n.T("Automatically fix issues found (requires --auto-maintain)"))
+	iconsCmd.Flags().BoolP("verbose", "v", false, i18n.T("Show detailed maintenance actions"))
 }
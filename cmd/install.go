@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,35 +9,73 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/joeblew999/goup-util/pkg/androidrepo"
 	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/history"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/installer"
+	"github.com/joeblew999/goup-util/pkg/sdklock"
 	"github.com/joeblew999/goup-util/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
 const cmdLineTools = "cmdline-tools-11.0"
 
+var (
+	installFromLockFlag   bool
+	installSdkManagerFlag bool
+)
+
 var installCmd = &cobra.Command{
 	Use:   "install [sdk-name]",
-	Short: "Install an SDK",
-	Long:  `Install a specified Android or iOS SDK.`,
-	Args:  cobra.ExactArgs(1),
+	Short: i18n.T("Install an SDK"),
+	Long: i18n.T(`Install a specified Android or iOS SDK.
+
+With --from-lock, install exactly the SDKs and gogio version pinned in the
+project's goup.lock (see 'goup-util lock').
+
+Android SDK packages (platforms, build-tools, platform-tools, the NDK,
+system images) are downloaded straight from Google's repository, so no JDK
+is needed to install them. --sdkmanager uses the Java-based sdkmanager
+instead.`),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if installFromLockFlag {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		sdkName := args[0]
-
 		// Ensure directories exist and create cache
 		cache, err := utils.NewCacheWithDirectories()
 		if err != nil {
 			return err
 		}
 
+		if installFromLockFlag {
+			dir, err := lockDirectory("")
+			if err != nil {
+				return err
+			}
+			span := history.Start(history.Install, sdklock.FileName, "")
+			err = installFromLock(dir, cache)
+			span.End(err, "")
+			return err
+		}
+
+		sdkName := args[0]
 		fmt.Printf("Installing SDK: %s...\n", sdkName)
 
-		return installSdk(sdkName, cache)
+		span := history.Start(history.Install, sdkName, "")
+		err = installSdk(sdkName, cache)
+		span.End(err, "")
+		return err
 	},
 }
 
 func init() {
+	installCmd.Flags().BoolVar(&installFromLockFlag, "from-lock", false, i18n.T("Install the SDKs pinned in goup.lock"))
+	installCmd.Flags().BoolVar(&installSdkManagerFlag, "sdkmanager", false, i18n.T("Install Android packages with sdkmanager (needs a JDK)"))
+
 	// Group for help organization
 	installCmd.GroupID = "sdk"
 
@@ -48,6 +87,12 @@ func installSdk(sdkName string, cache *installer.Cache) error {
 	if sdkName == "garble" {
 		return installer.InstallGarble(cache)
 	}
+	if isXcodeCLT(sdkName) {
+		return installXcodeCLT()
+	}
+	if isWindowsTool(sdkName) {
+		return installWindowsTool(sdkName)
+	}
 
 	sdk, sdkManagerName, err := findSdk(sdkName)
 	if err != nil {
@@ -69,12 +114,12 @@ func getJavaHome(cache *installer.Cache) (string, error) {
 
 	jfrPath, err := installer.ResolveInstallPath(jdkEntry.InstallPath)
 	if err != nil {
-		return "", fmt.Errorf("could not resolve openjdk-17 install path: %w", err)
+		return "", fmt.Errorf(i18n.T("could not resolve openjdk-17 install path: %w"), err)
 	}
 
 	files, err := os.ReadDir(jfrPath)
 	if err != nil {
-		return "", fmt.Errorf("could not read openjdk-17 install directory: %w", err)
+		return "", fmt.Errorf(i18n.T("could not read openjdk-17 install directory: %w"), err)
 	}
 
 	for _, f := range files {
@@ -94,7 +139,7 @@ func getJavaHome(cache *installer.Cache) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("could not find a valid JAVA_HOME in %s", jfrPath)
+	return "", fmt.Errorf(i18n.T("could not find a valid JAVA_HOME in %s"), jfrPath)
 }
 
 func installWithSdkManager(sdk *installer.SDK, sdkManagerName string, cache *installer.Cache) error {
@@ -110,11 +155,23 @@ func installWithSdkManager(sdk *installer.SDK, sdkManagerName string, cache *ins
 		fmt.Printf("%s cache entry found, but directory is missing or path is invalid. Re-installing.\n", sdk.Name)
 	}
 
+	if !installSdkManagerFlag {
+		_, err := installer.InstallAndroidPackage(sdkManagerName, config.GetSDKDir())
+		if err == nil {
+			fmt.Printf("%s installed successfully.\n", sdk.Name)
+			return saveSdkManagerEntry(sdk, sdkManagerName, cache)
+		}
+		if !errors.Is(err, androidrepo.ErrNotFound) {
+			return fmt.Errorf(i18n.T("%w\nTo install with sdkmanager instead: goup-util install --sdkmanager %s"), err, sdk.Name)
+		}
+		fmt.Printf("⚠️  %v; falling back to sdkmanager\n", err)
+	}
+
 	// Ensure openjdk-17 is installed for sdkmanager
 	if _, ok := cache.Entries["openjdk-17"]; !ok {
 		fmt.Println("openjdk-17 not found in cache, installing for sdkmanager...")
 		if err := installSdk("openjdk-17", cache); err != nil {
-			return fmt.Errorf("failed to install openjdk-17 for sdkmanager: %w", err)
+			return fmt.Errorf(i18n.T("failed to install openjdk-17 for sdkmanager: %w"), err)
 		}
 	}
 
@@ -123,24 +180,24 @@ func installWithSdkManager(sdk *installer.SDK, sdkManagerName string, cache *ins
 	if !ok {
 		fmt.Println("Command-line tools not found, installing them first...")
 		if err := installSdk(cmdLineTools, cache); err != nil {
-			return fmt.Errorf("failed to install command-line tools: %w", err)
+			return fmt.Errorf(i18n.T("failed to install command-line tools: %w"), err)
 		}
 		cmdToolsEntry, ok = cache.Entries[cmdLineTools]
 		if !ok {
-			return fmt.Errorf("could not find command-line tools in cache even after installation")
+			return errors.New(i18n.T("could not find command-line tools in cache even after installation"))
 		}
 	}
 
 	fmt.Println("Command-line tools found.")
 	cmdToolsPath, err := installer.ResolveInstallPath(cmdToolsEntry.InstallPath)
 	if err != nil {
-		return fmt.Errorf("could not resolve command-line tools install path: %w", err)
+		return fmt.Errorf(i18n.T("could not resolve command-line tools install path: %w"), err)
 	}
 
 	if !filepath.IsAbs(cmdToolsPath) {
 		cwd, err := os.Getwd()
 		if err != nil {
-			return fmt.Errorf("could not get current working directory: %w", err)
+			return fmt.Errorf(i18n.T("could not get current working directory: %w"), err)
 		}
 		cmdToolsPath = filepath.Join(cwd, cmdToolsPath)
 	}
@@ -167,11 +224,15 @@ func installWithSdkManager(sdk *installer.SDK, sdkManagerName string, cache *ins
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run sdkmanager for %s: %w", sdkManagerName, err)
+		return fmt.Errorf(i18n.T("failed to run sdkmanager for %s: %w"), sdkManagerName, err)
 	}
 
 	fmt.Printf("%s installed successfully.\n", sdk.Name)
+	return saveSdkManagerEntry(sdk, sdkManagerName, cache)
+}
 
+// saveSdkManagerEntry records an installed Android package in the cache.
+func saveSdkManagerEntry(sdk *installer.SDK, sdkManagerName string, cache *installer.Cache) error {
 	// After successful installation, update the cache with the correct install path
 	// This is crucial for sdkmanager-installed items where the path is not known beforehand
 	var installPath string
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/joeblew999/goup-util/pkg/history"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/simctl"
 	"github.com/spf13/cobra"
 )
@@ -11,20 +14,20 @@ import (
 func newSimctlClient() (*simctl.Client, error) {
 	client := simctl.New()
 	if !client.Available() {
-		return nil, fmt.Errorf("xcrun simctl not available\nInstall Xcode command line tools: xcode-select --install")
+		return nil, errors.New(i18n.T("xcrun simctl not available\nInstall Xcode command line tools: xcode-select --install"))
 	}
 	return client, nil
 }
 
 var iosCmd = &cobra.Command{
 	Use:   "ios",
-	Short: "iOS simulator management",
-	Long:  `Manage iOS simulators and apps using xcrun simctl.`,
+	Short: i18n.T("iOS simulator management"),
+	Long:  i18n.T(`Manage iOS simulators and apps using xcrun simctl.`),
 }
 
 var iosDevicesCmd = &cobra.Command{
 	Use:   "devices",
-	Short: "List available iOS simulators",
+	Short: i18n.T("List available iOS simulators"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
 		if err != nil {
@@ -49,13 +52,13 @@ var iosDevicesCmd = &cobra.Command{
 
 var iosBootCmd = &cobra.Command{
 	Use:   "boot [udid-or-name]",
-	Short: "Boot an iOS simulator",
-	Long: `Boot a simulator by UDID or device name.
+	Short: i18n.T("Boot an iOS simulator"),
+	Long: i18n.T(`Boot a simulator by UDID or device name.
 Use 'goup-util ios devices' to find available simulators.
 
 Examples:
   goup-util ios boot "iPhone 15"
-  goup-util ios boot XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX`,
+  goup-util ios boot XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX`),
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
@@ -72,7 +75,7 @@ Examples:
 
 		fmt.Printf("Booting simulator %s...\n", identifier)
 		if err := client.Boot(udid); err != nil {
-			return fmt.Errorf("boot failed: %w", err)
+			return fmt.Errorf(i18n.T("boot failed: %w"), err)
 		}
 		// Open the Simulator.app so user can see it
 		client.OpenSimulatorApp()
@@ -83,7 +86,7 @@ Examples:
 
 var iosShutdownCmd = &cobra.Command{
 	Use:   "shutdown [udid-or-name]",
-	Short: "Shutdown a running iOS simulator",
+	Short: i18n.T("Shutdown a running iOS simulator"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
@@ -96,7 +99,7 @@ var iosShutdownCmd = &cobra.Command{
 		}
 		fmt.Printf("Shutting down simulator...\n")
 		if err := client.Shutdown(udid); err != nil {
-			return fmt.Errorf("shutdown failed: %w", err)
+			return fmt.Errorf(i18n.T("shutdown failed: %w"), err)
 		}
 		fmt.Println("Simulator shut down")
 		return nil
@@ -105,7 +108,7 @@ var iosShutdownCmd = &cobra.Command{
 
 var iosInstallCmd = &cobra.Command{
 	Use:   "install [app-path]",
-	Short: "Install an .app bundle on the booted simulator",
+	Short: i18n.T("Install an .app bundle on the booted simulator"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
@@ -113,11 +116,11 @@ var iosInstallCmd = &cobra.Command{
 			return err
 		}
 		if !client.HasBooted() {
-			return fmt.Errorf("no simulator is booted. Boot one with: goup-util ios boot \"iPhone 15\"")
+			return errors.New(i18n.T("no simulator is booted. Boot one with: goup-util ios boot \"iPhone 15\""))
 		}
 		fmt.Printf("Installing %s...\n", args[0])
 		if err := client.Install(args[0]); err != nil {
-			return fmt.Errorf("install failed: %w", err)
+			return fmt.Errorf(i18n.T("install failed: %w"), err)
 		}
 		fmt.Println("Installed successfully")
 		return nil
@@ -126,7 +129,7 @@ var iosInstallCmd = &cobra.Command{
 
 var iosUninstallCmd = &cobra.Command{
 	Use:   "uninstall [bundle-id]",
-	Short: "Uninstall an app from the booted simulator",
+	Short: i18n.T("Uninstall an app from the booted simulator"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
@@ -135,7 +138,7 @@ var iosUninstallCmd = &cobra.Command{
 		}
 		fmt.Printf("Uninstalling %s...\n", args[0])
 		if err := client.Uninstall(args[0]); err != nil {
-			return fmt.Errorf("uninstall failed: %w", err)
+			return fmt.Errorf(i18n.T("uninstall failed: %w"), err)
 		}
 		fmt.Println("Uninstalled")
 		return nil
@@ -144,7 +147,7 @@ var iosUninstallCmd = &cobra.Command{
 
 var iosLaunchCmd = &cobra.Command{
 	Use:   "launch [bundle-id]",
-	Short: "Launch an app on the booted simulator",
+	Short: i18n.T("Launch an app on the booted simulator"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
@@ -152,7 +155,7 @@ var iosLaunchCmd = &cobra.Command{
 			return err
 		}
 		if !client.HasBooted() {
-			return fmt.Errorf("no simulator is booted. Boot one with: goup-util ios boot \"iPhone 15\"")
+			return errors.New(i18n.T("no simulator is booted. Boot one with: goup-util ios boot \"iPhone 15\""))
 		}
 		fmt.Printf("Launching %s...\n", args[0])
 		return client.Launch(args[0])
@@ -161,7 +164,7 @@ var iosLaunchCmd = &cobra.Command{
 
 var iosScreenshotCmd = &cobra.Command{
 	Use:   "screenshot [output-file]",
-	Short: "Capture a screenshot from the booted simulator",
+	Short: i18n.T("Capture a screenshot from the booted simulator"),
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
@@ -169,7 +172,7 @@ var iosScreenshotCmd = &cobra.Command{
 			return err
 		}
 		if !client.HasBooted() {
-			return fmt.Errorf("no simulator is booted. Boot one with: goup-util ios boot \"iPhone 15\"")
+			return errors.New(i18n.T("no simulator is booted. Boot one with: goup-util ios boot \"iPhone 15\""))
 		}
 
 		output := "ios-screenshot.png"
@@ -188,8 +191,11 @@ var iosScreenshotCmd = &cobra.Command{
 		}
 
 		fmt.Println("Capturing screenshot...")
-		if err := client.Screenshot(output); err != nil {
-			return fmt.Errorf("screenshot failed: %w", err)
+		span := history.Start(history.Screenshot, output, "ios-simulator")
+		err = client.Screenshot(output)
+		span.End(err, output)
+		if err != nil {
+			return fmt.Errorf(i18n.T("screenshot failed: %w"), err)
 		}
 		fmt.Printf("Screenshot saved to %s\n", output)
 		return nil
@@ -198,16 +204,16 @@ var iosScreenshotCmd = &cobra.Command{
 
 var iosLogsCmd = &cobra.Command{
 	Use:   "logs",
-	Short: "Stream iOS simulator logs (Ctrl+C to stop)",
-	Long: `Stream filtered log output from the booted iOS simulator.
-Use --all to show all logs instead of just Gio/Go-related ones.`,
+	Short: i18n.T("Stream iOS simulator logs (Ctrl+C to stop)"),
+	Long: i18n.T(`Stream filtered log output from the booted iOS simulator.
+Use --all to show all logs instead of just Gio/Go-related ones.`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
 		if err != nil {
 			return err
 		}
 		if !client.HasBooted() {
-			return fmt.Errorf("no simulator is booted. Boot one with: goup-util ios boot \"iPhone 16\"")
+			return errors.New(i18n.T("no simulator is booted. Boot one with: goup-util ios boot \"iPhone 16\""))
 		}
 		all, _ := cmd.Flags().GetBool("all")
 		if all {
@@ -221,7 +227,7 @@ Use --all to show all logs instead of just Gio/Go-related ones.`,
 
 var iosRuntimesCmd = &cobra.Command{
 	Use:   "runtimes",
-	Short: "List available iOS runtimes",
+	Short: i18n.T("List available iOS runtimes"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := newSimctlClient()
 		if err != nil {
@@ -280,16 +286,16 @@ func resolveSimulatorUDID(client *simctl.Client, identifier string) (string, err
 	for _, d := range devices {
 		names = append(names, fmt.Sprintf("  %s (%s)", d.Name, d.Runtime))
 	}
-	return "", fmt.Errorf("simulator not found: %q\n\nAvailable simulators:\n%s\n\nRun 'goup-util ios devices' for full list",
+	return "", fmt.Errorf(i18n.T("simulator not found: %q\n\nAvailable simulators:\n%s\n\nRun 'goup-util ios devices' for full list"),
 		identifier, strings.Join(names, "\n"))
 }
 
 func init() {
 	// Screenshot flags
-	iosScreenshotCmd.Flags().Bool("clean-status", false, "Set clean status bar (9:41, full battery) for App Store screenshots")
+	iosScreenshotCmd.Flags().Bool("clean-status", false, i18n.T("Set clean status bar (9:41, full battery) for App Store screenshots"))
 
 	// Logs flags
-	iosLogsCmd.Flags().Bool("all", false, "Show all simulator logs (not just Gio-filtered)")
+	iosLogsCmd.Flags().Bool("all", false, i18n.T("Show all simulator logs (not just Gio-filtered)"))
 
 	// iOS subcommands
 	iosCmd.AddCommand(iosDevicesCmd)
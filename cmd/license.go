@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/licensing"
+	"github.com/joeblew999/goup-util/pkg/secrets"
+	"github.com/joeblew999/goup-util/pkg/updater"
+	"github.com/spf13/cobra"
+)
+
+var licenseCmd = &cobra.Command{
+	Use:   "license",
+	Short: i18n.T("Issue and check offline license files for distributed apps"),
+	Long: i18n.T(`Gate a shell behind a license without running a licensing server.
+
+License files are signed with an ed25519 private key that stays with you.
+The app embeds the public key and checks the file offline with
+pkg/licensing:
+
+  lic, err := licensing.ValidateFile(publicKey, "portal", licensing.DefaultPath("acme-portal"))
+
+A license names the licensee and can carry a product, features, seats, an
+expiry date and a machine ID to lock it to one computer.`),
+	Example: `  goup-util license keygen
+  goup-util license generate --licensee "Acme Corp" --features offline --expires 365d -o acme.lic
+  goup-util license verify acme.lic --public-key license-public.key`,
+}
+
+var licenseKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: i18n.T("Create a signing key pair"),
+	Long: i18n.T(`Create license-public.key, to embed in the app, and license-private.key,
+to sign licenses. Store the private key with
+'goup-util secrets set LICENSE_SIGNING_KEY --file license-private.key' and
+delete the file.`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, _ := cmd.Flags().GetString("out")
+		pubPath := filepath.Join(out, "license-public.key")
+		privPath := filepath.Join(out, "license-private.key")
+		for _, p := range []string{pubPath, privPath} {
+			if _, err := os.Stat(p); err == nil {
+				return fmt.Errorf(i18n.T("%s already exists; licenses signed with it would stop validating"), p)
+			}
+		}
+
+		pub, priv, err := licensing.GenerateKey()
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to generate key: %w"), err)
+		}
+		if err := os.MkdirAll(out, 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(privPath, []byte(priv+"\n"), 0600); err != nil {
+			return err
+		}
+		if err := os.WriteFile(pubPath, []byte(pub+"\n"), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Public key:  %s\n   %s\n", pubPath, pub)
+		fmt.Printf("🔑 Private key: %s\n", privPath)
+		fmt.Printf("   Store it with: goup-util secrets set LICENSE_SIGNING_KEY --file %s\n", privPath)
+		return nil
+	},
+}
+
+var licenseGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: i18n.T("Sign a license file"),
+	Long: i18n.T(`Sign a license with the LICENSE_SIGNING_KEY secret (or --key).
+
+--expires takes a date (2027-01-31) or a number of days (365d); without it
+the license never expires. --machine locks the license to the machine ID
+the customer reads with 'goup-util license machine-id'.`),
+	Example: `  goup-util license generate --licensee "Acme Corp" --email it@acme.com -o acme.lic
+  goup-util license generate --licensee "Acme Corp" --product portal --features offline,kiosk --seats 25 --expires 2027-01-31
+  goup-util license generate --licensee "Front desk" --machine 4C4C4544-0042 --key license-private.key`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keyPath, _ := cmd.Flags().GetString("key")
+		output, _ := cmd.Flags().GetString("output")
+		expires, _ := cmd.Flags().GetString("expires")
+
+		var key string
+		if keyPath != "" {
+			data, err := os.ReadFile(keyPath)
+			if err != nil {
+				return err
+			}
+			key = string(data)
+		} else {
+			var err error
+			if key, err = secrets.Open("").Text("LICENSE_SIGNING_KEY"); err != nil {
+				return err
+			}
+			if key == "" {
+				return errors.New(i18n.T("no signing key: set LICENSE_SIGNING_KEY (see 'goup-util license keygen') or pass --key"))
+			}
+		}
+
+		var l licensing.License
+		l.Licensee, _ = cmd.Flags().GetString("licensee")
+		l.Email, _ = cmd.Flags().GetString("email")
+		l.Product, _ = cmd.Flags().GetString("product")
+		l.Features, _ = cmd.Flags().GetStringSlice("features")
+		l.Seats, _ = cmd.Flags().GetInt("seats")
+		l.Machine, _ = cmd.Flags().GetString("machine")
+		if expires != "" {
+			t, err := parseExpiry(expires, time.Now())
+			if err != nil {
+				return err
+			}
+			l.Expires = t
+		}
+
+		data, err := licensing.Sign(key, l)
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to sign license: %w"), err)
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("✅ License for %s written to %s\n", l.Licensee, output)
+		return nil
+	},
+}
+
+var licenseVerifyCmd = &cobra.Command{
+	Use:   "verify <license-file>",
+	Short: i18n.T("Check a license file's signature, expiry and machine"),
+	Long: i18n.T(`Check a license file the way an app does. The machine lock is checked
+against this machine unless --machine is given.`),
+	Example: `  goup-util license verify acme.lic --public-key license-public.key
+  goup-util license verify acme.lic --public-key "MaKQa6UDHeuLNfzt..." --product portal --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pubFlag, _ := cmd.Flags().GetString("public-key")
+		machine, _ := cmd.Flags().GetString("machine")
+		product, _ := cmd.Flags().GetString("product")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if pubFlag == "" {
+			return errors.New(i18n.T("--public-key is required (a key or a file holding one)"))
+		}
+		pub := pubFlag
+		if data, err := os.ReadFile(pubFlag); err == nil {
+			pub = string(data)
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		l, err := licensing.Verify(pub, data)
+		if err != nil {
+			return err
+		}
+		if machine == "" {
+			machine = updater.MachineID()
+		}
+		checkErr := l.Check(time.Now(), machine)
+		if checkErr == nil && product != "" {
+			checkErr = l.CheckProduct(product)
+		}
+
+		if asJSON {
+			out := struct {
+				*licensing.License
+				Valid bool   `json:"valid"`
+				Error string `json:"error,omitempty"`
+			}{License: l, Valid: checkErr == nil}
+			if checkErr != nil {
+				out.Error = checkErr.Error()
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(out); err != nil {
+				return err
+			}
+			return checkErr
+		}
+
+		fmt.Printf("📄 %s (%s)\n", l.Licensee, l.ID)
+		for _, row := range [][2]string{
+			{"Email", l.Email},
+			{"Product", l.Product},
+			{"Features", strings.Join(l.Features, ", ")},
+			{"Seats", seatsString(l.Seats)},
+			{"Machine", l.Machine},
+			{"Issued", l.Issued.Format("2006-01-02")},
+			{"Expires", expiryString(l.Expires)},
+		} {
+			if row[1] != "" {
+				fmt.Printf("   %-9s %s\n", row[0]+":", row[1])
+			}
+		}
+		if checkErr != nil {
+			if errors.Is(checkErr, licensing.ErrMachine) {
+				fmt.Printf("   This machine: %s\n", machine)
+			}
+			return checkErr
+		}
+		fmt.Println("✅ Valid")
+		return nil
+	},
+}
+
+var licenseMachineIDCmd = &cobra.Command{
+	Use:   "machine-id",
+	Short: i18n.T("Print this machine's ID for machine-locked licenses"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(updater.MachineID())
+		return nil
+	},
+}
+
+// parseExpiry reads a date (2027-01-31, valid through that day) or a
+// number of days from now (365d).
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf(i18n.T("invalid --expires %q: use a date (2027-01-31) or days (365d)"), s)
+		}
+		return now.UTC().Truncate(time.Second).AddDate(0, 0, n), nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(i18n.T("invalid --expires %q: use a date (2027-01-31) or days (365d)"), s)
+	}
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+func seatsString(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func expiryString(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02")
+}
+
+func init() {
+	licenseKeygenCmd.Flags().String("out", ".", i18n.T("Directory for the key files"))
+
+	licenseGenerateCmd.Flags().String("licensee", "", i18n.T("Customer the license is issued to (required)"))
+	licenseGenerateCmd.Flags().String("email", "", i18n.T("Customer contact"))
+	licenseGenerateCmd.Flags().String("product", "", i18n.T("Product the license is for (checked by licensing.CheckProduct)"))
+	licenseGenerateCmd.Flags().StringSlice("features", nil, i18n.T("Features the license grants (comma-separated)"))
+	licenseGenerateCmd.Flags().Int("seats", 0, i18n.T("Number of seats (informational)"))
+	licenseGenerateCmd.Flags().String("expires", "", i18n.T("Expiry: a date (2027-01-31) or days from now (365d); default never"))
+	licenseGenerateCmd.Flags().String("machine", "", i18n.T("Lock the license to this machine ID"))
+	licenseGenerateCmd.Flags().String("key", "", i18n.T("Private key file (default: the LICENSE_SIGNING_KEY secret)"))
+	licenseGenerateCmd.Flags().StringP("output", "o", licensing.FileName, i18n.T("License file to write"))
+	licenseGenerateCmd.MarkFlagRequired("licensee")
+
+	licenseVerifyCmd.Flags().String("public-key", "", i18n.T("Public key, or a file holding it"))
+	licenseVerifyCmd.Flags().String("machine", "", i18n.T("Machine ID to check a locked license against (default: this machine)"))
+	licenseVerifyCmd.Flags().String("product", "", i18n.T("Also check the license is for this product"))
+	licenseVerifyCmd.Flags().Bool("json", false, i18n.T("Print the license and result as JSON"))
+
+	licenseCmd.AddCommand(licenseKeygenCmd)
+	licenseCmd.AddCommand(licenseGenerateCmd)
+	licenseCmd.AddCommand(licenseVerifyCmd)
+	licenseCmd.AddCommand(licenseMachineIDCmd)
+	licenseCmd.GroupID = "tools"
+	rootCmd.AddCommand(licenseCmd)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/installer"
 	"github.com/joeblew999/goup-util/pkg/utils"
 	"github.com/spf13/cobra"
@@ -21,8 +22,8 @@ var (
 
 var listCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List available SDKs",
-	Long:  "List all available Android and iOS SDKs from the JSON files, or show cached SDKs.",
+	Short: i18n.T("List available SDKs"),
+	Long:  i18n.T("List all available Android and iOS SDKs from the JSON files, or show cached SDKs."),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if cachedOnly {
 			return listCachedSDKs()
@@ -32,10 +33,10 @@ var listCmd = &cobra.Command{
 }
 
 func init() {
-	listCmd.Flags().StringVarP(&platformFilter, "platform", "p", "", "Filter by platform (android, ios)")
-	listCmd.Flags().StringVarP(&categoryFilter, "category", "c", "", "Filter by category (e.g., openjdk, android, build-tools)")
-	listCmd.Flags().BoolVar(&compactOutput, "compact", false, "Show compact output without categories")
-	listCmd.Flags().BoolVar(&cachedOnly, "cached", false, "Show only cached SDKs from download cache")
+	listCmd.Flags().StringVarP(&platformFilter, "platform", "p", "", i18n.T("Filter by platform (android, ios)"))
+	listCmd.Flags().StringVarP(&categoryFilter, "category", "c", "", i18n.T("Filter by category (e.g., openjdk, android, build-tools)"))
+	listCmd.Flags().BoolVar(&compactOutput, "compact", false, i18n.T("Show compact output without categories"))
+	listCmd.Flags().BoolVar(&cachedOnly, "cached", false, i18n.T("Show only cached SDKs from download cache"))
 
 	// Alias and group
 	listCmd.Aliases = []string{"ls"}
@@ -47,7 +48,7 @@ func init() {
 func listAllSDKs() error {
 	sdkFiles, err := utils.ParseSDKFiles()
 	if err != nil {
-		return fmt.Errorf("failed to parse SDK files: %w", err)
+		return fmt.Errorf(i18n.T("failed to parse SDK files: %w"), err)
 	}
 
 	// Platform names corresponding to SDK files (order must match ParseSDKFiles)
@@ -57,7 +58,7 @@ func listAllSDKs() error {
 	if platformFilter != "" {
 		platformFilter = strings.ToLower(platformFilter)
 		if !utils.Contains(platforms, platformFilter) {
-			return fmt.Errorf("unknown platform: %s (available: android, ios, build-tools)", platformFilter)
+			return fmt.Errorf(i18n.T("unknown platform: %s (available: android, ios, build-tools)"), platformFilter)
 		}
 		// Find the index of the filtered platform
 		for i, p := range platforms {
@@ -138,7 +139,7 @@ func listSDKsFromSDKFile(sdkFile config.SdkFile, platform string) error {
 func listCachedSDKs() error {
 	cache, err := utils.NewCacheWithDirectories()
 	if err != nil {
-		return fmt.Errorf("failed to load cache: %w", err)
+		return fmt.Errorf(i18n.T("failed to load cache: %w"), err)
 	}
 
 	if len(cache.Entries) == 0 {
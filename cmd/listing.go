@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/listing"
+	"github.com/spf13/cobra"
+)
+
+var listingCmd = &cobra.Command{
+	Use:   "listing",
+	Short: i18n.T("Generate store listing graphics and preview frames"),
+	Long: i18n.T(`Generate the non-icon assets a store listing needs, described by
+listing.json in the app directory:
+
+  feature-graphic   Google Play feature graphic (1024x500)
+  tv-banner         Android TV / Google TV banner (1280x720)
+  preview           screenshots in captioned frames, per store
+                    (macos 2880x1800, ios 1290x2796, android 1080x1920,
+                    windows 1920x1080)
+
+Graphics use the app's icon-source.png, the title and tagline, on a solid
+background or a template image. Without a listing.json the feature graphic
+and TV banner are generated from the app name.
+
+Output goes to .dist/listing, where the publish commands pick it up.
+
+Examples:
+  goup-util listing init ./my-gio-app
+  goup-util listing generate ./my-gio-app
+  goup-util listing generate ./my-gio-app --json`),
+}
+
+var listingInitCmd = &cobra.Command{
+	Use:   "init <app-directory>",
+	Short: i18n.T("Write a starter listing.json"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appDir := args[0]
+		path := filepath.Join(appDir, listing.FileName)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf(i18n.T("%s already exists"), path)
+		}
+		l := listing.Default(appDir, filepath.Base(mustAbs(appDir)))
+		l.Tagline = "A Gio app for every platform"
+		l.Background = "#1F2937"
+		l.Assets = []listing.Asset{
+			{Type: listing.FeatureGraphic},
+			{Type: listing.TVBanner},
+			{Type: listing.Preview, Store: "macos", Screenshots: []string{"screenshots/macos/*.png"}},
+		}
+		data, err := json.MarshalIndent(l, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf(i18n.T("failed to write %s: %w"), path, err)
+		}
+		fmt.Printf("✓ Created %s\n", path)
+		fmt.Println("  Edit the title and tagline, then run 'goup-util listing generate'")
+		return nil
+	},
+}
+
+var listingGenerateCmd = &cobra.Command{
+	Use:   "generate <app-directory>",
+	Short: i18n.T("Render the assets in listing.json"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appDir := args[0]
+		l, err := listing.Load(appDir)
+		if err != nil {
+			return err
+		}
+		if l == nil {
+			l = listing.Default(appDir, filepath.Base(mustAbs(appDir)))
+		}
+		files, err := l.Generate()
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to generate listing assets: %w"), err)
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return writeJSONOut(files)
+		}
+		for _, f := range files {
+			fmt.Printf("✓ %s\n", f)
+		}
+		fmt.Printf("✅ %d listing assets in %s\n", len(files), l.OutputDir())
+		return nil
+	},
+}
+
+func mustAbs(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func init() {
+	listingGenerateCmd.Flags().Bool("json", false, i18n.T("Print the generated files as JSON"))
+	listingCmd.AddCommand(listingInitCmd)
+	listingCmd.AddCommand(listingGenerateCmd)
+
+	listingCmd.GroupID = "tools"
+	rootCmd.AddCommand(listingCmd)
+}
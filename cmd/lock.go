@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/hooks"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/installer"
+	"github.com/joeblew999/goup-util/pkg/sdklock"
+	"github.com/joeblew999/goup-util/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lockDir   string
+	lockCheck bool
+	lockSDKs  []string
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: i18n.T("Pin the project's SDK and gogio versions in goup.lock"),
+	Long: i18n.T(`Write goup.lock with the exact SDK versions, checksums and sdkmanager
+revisions, and the gogio version, this project is built with. Commit it,
+then reproduce the environment anywhere with:
+
+  goup-util install --from-lock
+
+Without --sdk, the SDKs already in goup.lock are refreshed; when there is
+no lockfile yet, every installed SDK is locked.
+
+--check verifies that goup.lock matches the SDK catalog of this goup-util,
+for CI: it fails when the catalog has moved on, so an upgrade never changes
+SDKs silently.`),
+	Example: `  goup-util lock
+  goup-util lock --sdk openjdk-17 --sdk ndk-bundle --sdk build-tools-34.0.0
+  goup-util lock --check`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := lockDirectory(lockDir)
+		if err != nil {
+			return err
+		}
+		if lockCheck {
+			return checkLock(dir)
+		}
+		return writeLock(dir, lockSDKs)
+	},
+}
+
+func init() {
+	lockCmd.Flags().StringVar(&lockDir, "dir", "", i18n.T("Project directory (default: the one holding goup.json, else the current directory)"))
+	lockCmd.Flags().BoolVar(&lockCheck, "check", false, i18n.T("Verify goup.lock against the SDK catalog instead of writing it"))
+	lockCmd.Flags().StringArrayVar(&lockSDKs, "sdk", nil, i18n.T("SDK to lock (repeatable)"))
+
+	lockCmd.GroupID = "sdk"
+	rootCmd.AddCommand(lockCmd)
+}
+
+// lockDirectory returns where goup.lock lives: next to goup.json.
+func lockDirectory(dir string) (string, error) {
+	if dir != "" {
+		return filepath.Abs(dir)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	path, _, err := hooks.Find(cwd)
+	if err != nil {
+		return "", err
+	}
+	if path != "" {
+		return filepath.Dir(path), nil
+	}
+	return cwd, nil
+}
+
+func writeLock(dir string, names []string) error {
+	cache, err := installer.NewCache(config.GetCachePath())
+	if err != nil {
+		return fmt.Errorf(i18n.T("could not load cache: %w"), err)
+	}
+
+	existing, err := sdklock.Load(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(names) == 0 {
+		if existing != nil {
+			names = existing.Names()
+		} else {
+			for name := range cache.Entries {
+				if _, err := utils.FindSDKItem(name); err == nil {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		return errors.New(i18n.T("no SDKs to lock: install some first, or pass --sdk"))
+	}
+
+	lock := &sdklock.Lock{SDKs: map[string]sdklock.SDK{}}
+	for _, name := range names {
+		item, err := utils.FindSDKItem(name)
+		if err != nil {
+			return err
+		}
+		entry := sdklock.FromCatalog(item)
+		if entry.Package != "" {
+			cached, ok := cache.Entries[name]
+			if !ok {
+				return fmt.Errorf(i18n.T("%s is not installed, so its revision is unknown\nInstall it with: goup-util install %s"), name, name)
+			}
+			if path, err := installer.ResolveInstallPath(cached.InstallPath); err == nil {
+				entry.Revision = sdklock.Revision(path)
+			}
+		}
+		lock.SDKs[name] = entry
+	}
+
+	if gogio, err := exec.LookPath("gogio"); err == nil {
+		if lock.Gogio, err = sdklock.ToolAt(gogio); err != nil {
+			return err
+		}
+		if !lock.Gogio.Pinnable() {
+			fmt.Printf("⚠️  gogio at %s was not installed with go install; its version cannot be reproduced\n", gogio)
+		}
+	} else if existing != nil && existing.Gogio != nil {
+		fmt.Println("⚠️  gogio not found in PATH; keeping the locked version")
+		lock.Gogio = existing.Gogio
+	}
+
+	if err := lock.Save(dir); err != nil {
+		return fmt.Errorf(i18n.T("failed to write %s: %w"), sdklock.FileName, err)
+	}
+	fmt.Printf("✅ Wrote %s\n", sdklock.Path(dir))
+	for _, name := range lock.Names() {
+		entry := lock.SDKs[name]
+		version := entry.Revision
+		if version == "" {
+			version = entry.Version
+		}
+		fmt.Printf("   %-45s %s\n", name, version)
+	}
+	if lock.Gogio != nil {
+		fmt.Printf("   %-45s %s\n", "gogio", lock.Gogio.Version)
+	}
+	return nil
+}
+
+func checkLock(dir string) error {
+	lock, err := sdklock.Load(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf(i18n.T("no %s in %s (create it with: goup-util lock)"), sdklock.FileName, dir)
+		}
+		return err
+	}
+	if problems := lock.Check(utils.FindSDKItem); len(problems) > 0 {
+		return fmt.Errorf(i18n.T("%s does not match the SDK catalog:\n  %s\nRun 'goup-util lock' to update it, or use the goup-util version it was written with"), sdklock.FileName, strings.Join(problems, "\n  "))
+	}
+	fmt.Printf("✓ %s matches the SDK catalog (%d SDKs)\n", sdklock.FileName, len(lock.SDKs))
+	return nil
+}
+
+// installFromLock installs exactly what goup.lock pins, refusing when this
+// goup-util's catalog disagrees with it.
+func installFromLock(dir string, cache *installer.Cache) error {
+	lock, err := sdklock.Load(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf(i18n.T("no %s in %s (create it with: goup-util lock)"), sdklock.FileName, dir)
+		}
+		return err
+	}
+	if problems := lock.Check(utils.FindSDKItem); len(problems) > 0 {
+		return fmt.Errorf(i18n.T("%s does not match the SDK catalog of this goup-util:\n  %s"), sdklock.FileName, strings.Join(problems, "\n  "))
+	}
+
+	var mismatched []string
+	for _, name := range lock.Names() {
+		fmt.Printf("--- %s ---\n", name)
+		if err := installSdk(name, cache); err != nil {
+			return err
+		}
+		want := lock.SDKs[name].Revision
+		if want == "" {
+			continue
+		}
+		if entry, ok := cache.Entries[name]; ok {
+			path, err := installer.ResolveInstallPath(entry.InstallPath)
+			if err != nil {
+				return err
+			}
+			if got := sdklock.Revision(path); got != want {
+				mismatched = append(mismatched, fmt.Sprintf("%s: locked revision %s, installed %s", name, want, orNone(got)))
+			}
+		}
+	}
+
+	if lock.Gogio != nil {
+		if err := installLockedGogio(lock.Gogio); err != nil {
+			return err
+		}
+	}
+
+	if len(mismatched) > 0 {
+		slices.Sort(mismatched)
+		return fmt.Errorf(i18n.T("installed SDKs differ from %s:\n  %s\nsdkmanager installs the latest revision of these packages; remove them to reinstall, or run 'goup-util lock' to accept them"), sdklock.FileName, strings.Join(mismatched, "\n  "))
+	}
+	fmt.Printf("✅ Environment matches %s\n", sdklock.FileName)
+	return nil
+}
+
+func installLockedGogio(want *sdklock.Tool) error {
+	if path, err := exec.LookPath("gogio"); err == nil {
+		if have, err := sdklock.ToolAt(path); err == nil && have.Version == want.Version {
+			fmt.Printf("gogio %s is already installed.\n", want.Version)
+			return nil
+		}
+	}
+	if !want.Pinnable() {
+		fmt.Printf("⚠️  gogio is locked as %q, which cannot be installed with go install; skipping\n", want.Version)
+		return nil
+	}
+	fmt.Printf("Installing gogio %s...\n", want.Version)
+	c := exec.Command("go", "install", want.Path+"@"+want.Version)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf(i18n.T("failed to install gogio %s: %w"), want.Version, err)
+	}
+	return nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/notify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	notifyURL   string
+	notifyTopic string
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: i18n.T("Configure where --notify sends completion notices"),
+	Long: `Long operations (SDK installs, 'build all', bundles) accept --notify to
+report when they finish. Channels are configured once per user in
+notify.json (see 'notify list' for the path, or set $` + notify.ConfigEnv + `):
+
+  desktop   native notification (osascript, notify-send, PowerShell)
+  slack     Slack incoming webhook
+  discord   Discord webhook
+  ntfy      ntfy.sh (or self-hosted) topic
+
+URLs and topics may use ${VAR} so webhook secrets can stay in the
+environment. With no channels configured, --notify uses the desktop.
+
+Examples:
+  goup-util notify add team --type slack --url '${SLACK_WEBHOOK_URL}'
+  goup-util notify add phone --type ntfy --topic my-builds
+  goup-util notify test
+  goup-util build all examples/hybrid-dashboard --notify
+  goup-util install ndk --notify=phone`,
+}
+
+var notifyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("List notification channels"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := notify.Load("")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Config: %s\n", cfg.Path())
+		if len(cfg.Channels) == 0 {
+			fmt.Println("No channels configured (--notify uses desktop notifications)")
+			return nil
+		}
+		for _, ch := range cfg.Channels {
+			target := ch.URL
+			if ch.Type == notify.Ntfy {
+				target = strings.TrimSpace(ch.URL + " " + ch.Topic)
+			}
+			fmt.Printf("  %-15s %-8s %s\n", ch.Name, ch.Type, target)
+		}
+		return nil
+	},
+}
+
+var notifyAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: i18n.T("Add or replace a notification channel"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := notify.Load("")
+		if err != nil {
+			return err
+		}
+		typ, _ := cmd.Flags().GetString("type")
+		ch := notify.Channel{Name: args[0], Type: typ, URL: notifyURL, Topic: notifyTopic}
+		if err := cfg.Add(ch); err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf(i18n.T("failed to save notify config: %w"), err)
+		}
+		fmt.Printf("✓ Added %s channel %s\n", ch.Type, ch.Name)
+		return nil
+	},
+}
+
+var notifyRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: i18n.T("Remove a notification channel"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := notify.Load("")
+		if err != nil {
+			return err
+		}
+		if err := cfg.Remove(args[0]); err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf(i18n.T("failed to save notify config: %w"), err)
+		}
+		fmt.Printf("✓ Removed %s\n", args[0])
+		return nil
+	},
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test [channels]",
+	Short: i18n.T("Send a test notification (all channels, or a comma-separated list)"),
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names := ""
+		if len(args) == 1 {
+			names = args[0]
+		}
+		cfg, err := notify.Load("")
+		if err != nil {
+			return err
+		}
+		channels, err := cfg.Select(names)
+		if err != nil {
+			return err
+		}
+		if err := notify.Send(channels, notify.Message{Title: "goup-util test notification", OK: true}); err != nil {
+			return fmt.Errorf(i18n.T("failed to notify: %w"), err)
+		}
+		fmt.Printf("✓ Sent to %d channel(s)\n", len(channels))
+		return nil
+	},
+}
+
+// withNotify wraps a long-running command so --notify reports its outcome
+// and duration. Delivery failures are warnings; they never change the
+// command's result.
+func withNotify(run runE) runE {
+	return func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags().Lookup("notify")
+		if f == nil || !f.Changed {
+			return run(cmd, args)
+		}
+		cfg, err := notify.Load("")
+		if err != nil {
+			return err
+		}
+		channels, err := cfg.Select(f.Value.String())
+		if err != nil {
+			return err
+		}
+
+		started := time.Now()
+		err = run(cmd, args)
+		msg := notify.Message{
+			Title:    strings.Join(append([]string{"goup-util", cmd.Name()}, args...), " "),
+			OK:       err == nil,
+			Duration: time.Since(started),
+		}
+		if err != nil {
+			msg.Title += " failed"
+			msg.Body = firstLine(err.Error())
+		} else {
+			msg.Title += " finished"
+		}
+		if nerr := notify.Send(channels, msg); nerr != nil {
+			fmt.Printf("⚠️  Notification failed: %v\n", nerr)
+		}
+		return err
+	}
+}
+
+func init() {
+	notifyAddCmd.Flags().String("type", notify.Desktop, i18n.T("Channel type: desktop, slack, discord or ntfy"))
+	notifyAddCmd.Flags().StringVar(&notifyURL, "url", "", "Webhook URL (slack, discord) or ntfy server (default "+notify.DefaultNtfyServer+")")
+	notifyAddCmd.Flags().StringVar(&notifyTopic, "topic", "", i18n.T("ntfy topic"))
+
+	notifyCmd.AddCommand(notifyListCmd)
+	notifyCmd.AddCommand(notifyAddCmd)
+	notifyCmd.AddCommand(notifyRemoveCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.GroupID = "tools"
+
+	// Wrapped after hooks so the notice covers the hooks too.
+	for _, c := range []*cobra.Command{buildCmd, bundleCmd, packageCmd, installCmd} {
+		c.Flags().String("notify", "", i18n.T("Notify when done (all channels, or a comma-separated list; see 'goup-util notify')"))
+		c.Flags().Lookup("notify").NoOptDefVal = "all"
+		c.RunE = withNotify(c.RunE)
+	}
+}
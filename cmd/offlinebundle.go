@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/dlcache"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/installer"
+	"github.com/joeblew999/goup-util/pkg/sdkbundle"
+	"github.com/joeblew999/goup-util/pkg/sdklock"
+	"github.com/joeblew999/goup-util/pkg/self"
+	"github.com/joeblew999/goup-util/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// bundlePlatforms are the --platforms values; only Android needs SDKs
+// that goup-util installs.
+var bundlePlatforms = []string{"android", "ios", "macos", "windows", "linux"}
+
+var exportBundleCmd = &cobra.Command{
+	Use:   "export-bundle",
+	Short: i18n.T("Package goup-util, gogio and installed SDKs for offline installation"),
+	Long: i18n.T(`Write a single tar with this goup-util binary, gogio and the installed SDKs
+the given platforms need, plus checksums, for machines without network
+access. Install the SDKs on a connected machine of the same OS and
+architecture first; they are archived from their installed directories.
+
+On the offline machine:
+
+  tar -xf bundle.tar goup-util       # goup-util.exe on Windows
+  ./goup-util import-bundle bundle.tar
+  ./goup-util self setup
+
+With --downloads the bundle also carries the download cache (see 'goup-util
+cache'), which import-bundle adds to the cache on the other machine, so
+later installs there need no network either.
+
+iOS and macOS builds also need Xcode, which cannot be bundled.`),
+	Example: `  goup-util export-bundle --platforms android,macos -o bundle.tar
+  goup-util export-bundle --platforms android --no-emulator -o bundle.tar
+  goup-util export-bundle --from-lock -o bundle.tar
+  goup-util export-bundle --downloads -o bundle.tar`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platforms, _ := cmd.Flags().GetStringSlice("platforms")
+		out, _ := cmd.Flags().GetString("output")
+		noEmulator, _ := cmd.Flags().GetBool("no-emulator")
+		fromLock, _ := cmd.Flags().GetBool("from-lock")
+		extra, _ := cmd.Flags().GetStringArray("sdk")
+		downloads, _ := cmd.Flags().GetBool("downloads")
+
+		names, err := bundleSDKNames(platforms, fromLock, extra, noEmulator)
+		if err != nil {
+			return err
+		}
+		if out == "" {
+			out = fmt.Sprintf("goup-util-bundle-%s-%s.tar", runtime.GOOS, runtime.GOARCH)
+		}
+		return exportBundle(out, platforms, names, downloads)
+	},
+}
+
+var importBundleCmd = &cobra.Command{
+	Use:   "import-bundle <bundle.tar>",
+	Short: i18n.T("Install SDKs and gogio from an offline bundle"),
+	Long: i18n.T(`Install the SDKs and gogio from a bundle written by 'goup-util export-bundle',
+without network access. Every archive is checked against the bundle's
+checksums before it replaces anything. SDKs that are already installed are
+kept unless --force is given.`),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		return importBundle(args[0], force)
+	},
+}
+
+func init() {
+	exportBundleCmd.Flags().StringSlice("platforms", []string{"android"}, "Platforms to bundle for: "+strings.Join(bundlePlatforms, ", "))
+	exportBundleCmd.Flags().StringP("output", "o", "", i18n.T("Bundle file (default: goup-util-bundle-<os>-<arch>.tar)"))
+	exportBundleCmd.Flags().Bool("no-emulator", false, i18n.T("Leave out the emulator and system images (Android)"))
+	exportBundleCmd.Flags().Bool("from-lock", false, i18n.T("Bundle the SDKs pinned in goup.lock"))
+	exportBundleCmd.Flags().StringArray("sdk", nil, i18n.T("Additional SDK to bundle (repeatable)"))
+	exportBundleCmd.Flags().Bool("downloads", false, i18n.T("Include the SDK download cache"))
+	importBundleCmd.Flags().Bool("force", false, i18n.T("Replace SDKs that are already installed"))
+
+	exportBundleCmd.GroupID = "sdk"
+	importBundleCmd.GroupID = "sdk"
+	rootCmd.AddCommand(exportBundleCmd, importBundleCmd)
+}
+
+// bundleSDKNames works out which installed SDKs the bundle needs.
+func bundleSDKNames(platforms []string, fromLock bool, extra []string, noEmulator bool) ([]string, error) {
+	var names []string
+	add := func(name string) {
+		if noEmulator && (name == "emulator" || strings.HasPrefix(name, "system-images")) {
+			return
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, platform := range platforms {
+		switch platform {
+		case "android":
+			setup, err := findSetup("default-android")
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range setup {
+				add(name)
+			}
+		case "ios", "macos":
+			fmt.Printf("ℹ️  %s builds need Xcode, which cannot be bundled; install it on the target machine separately\n", platform)
+		case "windows", "linux":
+			// gogio and Go are all they need
+		default:
+			return nil, fmt.Errorf(i18n.T("unknown platform %q (available: %s)"), platform, strings.Join(bundlePlatforms, ", "))
+		}
+	}
+
+	if fromLock {
+		dir, err := lockDirectory("")
+		if err != nil {
+			return nil, err
+		}
+		lock, err := sdklock.Load(dir)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("failed to read %s: %w"), sdklock.FileName, err)
+		}
+		for _, name := range lock.Names() {
+			add(name)
+		}
+	}
+	for _, name := range extra {
+		add(name)
+	}
+	return names, nil
+}
+
+func exportBundle(out string, platforms, names []string, downloads bool) error {
+	cache, err := installer.NewCache(config.GetCachePath())
+	if err != nil {
+		return fmt.Errorf(i18n.T("could not load cache: %w"), err)
+	}
+
+	// Check everything is installed before writing gigabytes
+	type source struct {
+		sdk sdkbundle.SDK
+		dir string
+	}
+	var sources []source
+	var missing []string
+	for _, name := range names {
+		entry, ok := cache.Entries[name]
+		dir, err := installer.ResolveInstallPath(entry.InstallPath)
+		if !ok || err != nil || !dirExists(dir) {
+			missing = append(missing, name)
+			continue
+		}
+		sdk := sdkbundle.SDK{Name: name, Version: entry.Version, Checksum: entry.Checksum, InstallPath: portableInstallPath(entry.InstallPath)}
+		sources = append(sources, source{sdk: sdk, dir: dir})
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf(i18n.T("not installed: %s\nInstall them first, e.g.: goup-util install %s"), strings.Join(missing, ", "), missing[0])
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	w, err := sdkbundle.Create(out, platforms)
+	if err != nil {
+		return fmt.Errorf(i18n.T("failed to create %s: %w"), out, err)
+	}
+	add := func() error {
+		fmt.Println("📦 Adding goup-util...")
+		if err := w.AddGoupUtil(exe, self.CurrentVersion()); err != nil {
+			return err
+		}
+		if gogio, err := exec.LookPath("gogio"); err == nil {
+			version := ""
+			if tool, err := sdklock.ToolAt(gogio); err == nil {
+				version = tool.Version
+			}
+			fmt.Printf("📦 Adding gogio %s...\n", version)
+			if err := w.AddGogio(gogio, version); err != nil {
+				return err
+			}
+		} else {
+			fmt.Println("⚠️  gogio not found in PATH; the bundle will not include it")
+		}
+		for _, s := range sources {
+			fmt.Printf("📦 Adding %s from %s...\n", s.sdk.Name, s.dir)
+			if err := w.AddSDK(s.sdk, s.dir); err != nil {
+				return err
+			}
+		}
+		if !downloads {
+			return nil
+		}
+		entries, err := dlcache.Open().Entries()
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			fmt.Printf("📦 Adding cached download %s (%s)...\n", e.Sum[:12], formatBytes(e.Size))
+			if err := w.AddDownload(e.Path, e.Sum, e.URLs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := add(); err != nil {
+		w.Abort()
+		return fmt.Errorf(i18n.T("failed to write %s: %w"), out, err)
+	}
+	manifest, err := w.Close()
+	if err != nil {
+		os.Remove(out)
+		return fmt.Errorf(i18n.T("failed to write %s: %w"), out, err)
+	}
+
+	size := int64(0)
+	if info, err := os.Stat(out); err == nil {
+		size = info.Size()
+	}
+	fmt.Printf("✅ Wrote %s (%s, %d SDKs, %s/%s)\n", out, formatBytes(size), len(manifest.SDKs), manifest.OS, manifest.Arch)
+	if downloads {
+		fmt.Printf("   Includes %d cached downloads\n", len(manifest.Downloads))
+	}
+	return nil
+}
+
+// portableInstallPath turns an absolute path inside the SDK directory into
+// the sdks/ form, so it resolves on the importing machine.
+func portableInstallPath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(config.GetSDKDir(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return "sdks/" + filepath.ToSlash(rel)
+	}
+	return path
+}
+
+func importBundle(path string, force bool) error {
+	b, err := sdkbundle.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := b.CheckHost(); err != nil {
+		return err
+	}
+	cache, err := utils.NewCacheWithDirectories()
+	if err != nil {
+		return err
+	}
+
+	targets := sdkbundle.Targets{
+		SDK: func(sdk sdkbundle.SDK) (string, error) {
+			dest, err := installer.ResolveInstallPath(sdk.InstallPath)
+			if err != nil {
+				return "", err
+			}
+			if _, ok := cache.Entries[sdk.Name]; ok && dirExists(dest) && !force {
+				fmt.Printf("✓ %s is already installed\n", sdk.Name)
+				return "", nil
+			}
+			fmt.Printf("📂 Installing %s to %s...\n", sdk.Name, dest)
+			return dest, nil
+		},
+	}
+	if g := b.Manifest.Gogio; g != nil {
+		targets.Gogio = gogioTarget(g.Version, force)
+	}
+	store := dlcache.Open()
+	seeded := 0
+	targets.Download = func(d sdkbundle.Download, path string) error {
+		if err := store.Import(path, d.SHA256, d.URLs); err != nil {
+			return err
+		}
+		seeded++
+		return nil
+	}
+
+	installed, err := b.Install(targets)
+	for _, sdk := range installed {
+		cache.Entries[sdk.Name] = installer.CacheEntry{
+			Name:        sdk.Name,
+			Version:     sdk.Version,
+			Checksum:    sdk.Checksum,
+			InstallPath: sdk.InstallPath,
+		}
+	}
+	if saveErr := cache.Save(); saveErr != nil && err == nil {
+		err = fmt.Errorf(i18n.T("failed to save cache: %w"), saveErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Installed %d SDKs from %s\n", len(installed), path)
+	if targets.Gogio != "" {
+		fmt.Printf("✅ Installed gogio to %s\n", targets.Gogio)
+	}
+	if seeded > 0 {
+		fmt.Printf("✅ Added %d downloads to the download cache\n", seeded)
+	}
+	fmt.Println("Set JAVA_HOME and ANDROID_HOME with: goup-util env --apply")
+	return nil
+}
+
+// gogioTarget returns where to put the bundled gogio: next to the one in
+// PATH, else in the Go bin directory. It is "" when the same version is
+// already installed.
+func gogioTarget(version string, force bool) string {
+	if path, err := exec.LookPath("gogio"); err == nil {
+		if tool, err := sdklock.ToolAt(path); err == nil && tool.Version == version && !force {
+			fmt.Printf("✓ gogio %s is already installed\n", version)
+			return ""
+		}
+		return path
+	}
+	name := "gogio"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(goBinDir(), name)
+}
+
+// goBinDir is where go install puts binaries.
+func goBinDir() string {
+	if out, err := exec.Command("go", "env", "GOBIN", "GOPATH").Output(); err == nil {
+		// One line each; GOBIN is usually empty
+		lines := strings.Split(string(out), "\n")
+		if gobin := strings.TrimSpace(lines[0]); gobin != "" {
+			return gobin
+		}
+		if len(lines) > 1 {
+			if gopath := filepath.SplitList(strings.TrimSpace(lines[1])); len(gopath) > 0 && gopath[0] != "" {
+				return filepath.Join(gopath[0], "bin")
+			}
+		}
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "go", "bin")
+}
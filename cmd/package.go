@@ -1,21 +1,23 @@
 package cmd
 
 import (
-	"github.com/joeblew999/goup-util/pkg/utils"
 	"fmt"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/utils"
 	"os"
 	"path/filepath"
 
 	"github.com/joeblew999/goup-util/pkg/constants"
 	"github.com/joeblew999/goup-util/pkg/packaging"
 	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/joeblew999/goup-util/pkg/sbom"
 	"github.com/spf13/cobra"
 )
 
 var packageCmd = &cobra.Command{
 	Use:   "package [platform] [app-directory]",
-	Short: "Package built applications for distribution",
-	Long:  "Create distribution packages from built applications. Takes apps from .bin/ and creates packages in .dist/",
+	Short: i18n.T("Package built applications for distribution"),
+	Long:  i18n.T("Create distribution packages from built applications. Takes apps from .bin/ and creates packages in .dist/"),
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		platform := args[0]
@@ -24,17 +26,17 @@ var packageCmd = &cobra.Command{
 		// Validate platform
 		validPlatforms := []string{"macos", "android", "ios", "windows"}
 		if !utils.Contains(validPlatforms, platform) {
-			return fmt.Errorf("invalid platform: %s. Valid platforms: %v", platform, validPlatforms)
+			return fmt.Errorf(i18n.T("invalid platform: %s. Valid platforms: %v"), platform, validPlatforms)
 		}
 
 		// Create and validate project
 		proj, err := project.NewGioProject(appDir)
 		if err != nil {
-			return fmt.Errorf("failed to create project: %w", err)
+			return fmt.Errorf(i18n.T("failed to create project: %w"), err)
 		}
 
 		if err := proj.Validate(); err != nil {
-			return fmt.Errorf("invalid project: %w", err)
+			return fmt.Errorf(i18n.T("invalid project: %w"), err)
 		}
 
 		switch platform {
@@ -60,23 +62,23 @@ func packageMacOS(appDir, appName string) error {
 
 	// Ensure dist directory exists
 	if err := os.MkdirAll(distDir, 0755); err != nil {
-		return fmt.Errorf("failed to create dist directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create dist directory: %w"), err)
 	}
 
 	// Check if app exists
 	appPath := filepath.Join(binDir, appName+".app")
 	if _, err := os.Stat(appPath); os.IsNotExist(err) {
-		return fmt.Errorf("app not found: %s. Run 'goup-util build macos %s' first", appPath, appDir)
+		return fmt.Errorf(i18n.T("app not found: %s. Run 'goup-util build macos %s' first"), appPath, appDir)
 	}
 
 	// Create tar.gz package using packaging library
 	packagePath := filepath.Join(distDir, appName+"-macos.tar.gz")
 	if err := packaging.CreateArchive(appPath, packagePath, packaging.TarGz); err != nil {
-		return fmt.Errorf("failed to create package: %w", err)
+		return fmt.Errorf(i18n.T("failed to create package: %w"), err)
 	}
 
 	fmt.Printf("✓ Packaged %s for macOS: %s\n", appName, packagePath)
-	return nil
+	return attachSBOM(appPath, packagePath)
 }
 
 func packageAndroid(appDir, appName string) error {
@@ -87,23 +89,23 @@ func packageAndroid(appDir, appName string) error {
 
 	// Ensure dist directory exists
 	if err := os.MkdirAll(distDir, 0755); err != nil {
-		return fmt.Errorf("failed to create dist directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create dist directory: %w"), err)
 	}
 
 	// Check if APK exists
 	apkPath := filepath.Join(binDir, appName+".apk")
 	if _, err := os.Stat(apkPath); os.IsNotExist(err) {
-		return fmt.Errorf("APK not found: %s. Run 'goup-util build android %s' first", apkPath, appDir)
+		return fmt.Errorf(i18n.T("APK not found: %s. Run 'goup-util build android %s' first"), apkPath, appDir)
 	}
 
 	// Copy APK to dist with versioned name using packaging library
 	packagePath := filepath.Join(distDir, appName+"-android.apk")
 	if err := packaging.CopyFile(apkPath, packagePath); err != nil {
-		return fmt.Errorf("failed to create package: %w", err)
+		return fmt.Errorf(i18n.T("failed to create package: %w"), err)
 	}
 
 	fmt.Printf("✓ Packaged %s for Android: %s\n", appName, packagePath)
-	return nil
+	return attachSBOM(apkPath, packagePath)
 }
 
 func packageIOS(appDir, appName string) error {
@@ -114,23 +116,23 @@ func packageIOS(appDir, appName string) error {
 
 	// Ensure dist directory exists
 	if err := os.MkdirAll(distDir, 0755); err != nil {
-		return fmt.Errorf("failed to create dist directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create dist directory: %w"), err)
 	}
 
 	// Check if app exists
 	appPath := filepath.Join(binDir, appName+".app")
 	if _, err := os.Stat(appPath); os.IsNotExist(err) {
-		return fmt.Errorf("app not found: %s. Run 'goup-util build ios %s' first", appPath, appDir)
+		return fmt.Errorf(i18n.T("app not found: %s. Run 'goup-util build ios %s' first"), appPath, appDir)
 	}
 
 	// Create tar.gz package using packaging library
 	packagePath := filepath.Join(distDir, appName+"-ios.tar.gz")
 	if err := packaging.CreateArchive(appPath, packagePath, packaging.TarGz); err != nil {
-		return fmt.Errorf("failed to create package: %w", err)
+		return fmt.Errorf(i18n.T("failed to create package: %w"), err)
 	}
 
 	fmt.Printf("✓ Packaged %s for iOS: %s\n", appName, packagePath)
-	return nil
+	return attachSBOM(appPath, packagePath)
 }
 
 func packageWindows(appDir, appName string) error {
@@ -141,22 +143,35 @@ func packageWindows(appDir, appName string) error {
 
 	// Ensure dist directory exists
 	if err := os.MkdirAll(distDir, 0755); err != nil {
-		return fmt.Errorf("failed to create dist directory: %w", err)
+		return fmt.Errorf(i18n.T("failed to create dist directory: %w"), err)
 	}
 
 	// Check if exe exists
 	exePath := filepath.Join(binDir, appName+".exe")
 	if _, err := os.Stat(exePath); os.IsNotExist(err) {
-		return fmt.Errorf("executable not found: %s. Run 'goup-util build windows %s' first", exePath, appDir)
+		return fmt.Errorf(i18n.T("executable not found: %s. Run 'goup-util build windows %s' first"), exePath, appDir)
 	}
 
 	// Create zip package using packaging library
 	packagePath := filepath.Join(distDir, appName+"-windows.zip")
 	if err := packaging.CreateArchive(exePath, packagePath, packaging.Zip); err != nil {
-		return fmt.Errorf("failed to create package: %w", err)
+		return fmt.Errorf(i18n.T("failed to create package: %w"), err)
 	}
 
 	fmt.Printf("✓ Packaged %s for Windows: %s\n", appName, packagePath)
+	return attachSBOM(exePath, packagePath)
+}
+
+// attachSBOM carries the SBOM and provenance written by 'build --sbom'
+// over to the package, so they can be uploaded with it.
+func attachSBOM(artifact, packagePath string) error {
+	files, err := sbom.Attach(artifact, packagePath)
+	if err != nil {
+		return fmt.Errorf(i18n.T("failed to attach SBOM: %w"), err)
+	}
+	for _, f := range files {
+		fmt.Printf("✓ Attached %s\n", filepath.Base(f))
+	}
 	return nil
 }
 
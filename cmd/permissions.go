@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/permissions"
+	"github.com/spf13/cobra"
+)
+
+var permissionsCmd = &cobra.Command{
+	Use:   "permissions",
+	Short: i18n.T("Check and request OS privacy permissions"),
+	Long: i18n.T(`Check the OS privacy permissions used by goup-util and packaged apps.
+
+Permissions:
+  screen-recording  Needed by 'goup-util screenshot' (macOS, Wayland)
+  camera            Needed by apps that use getUserMedia video
+  microphone        Needed by apps that use getUserMedia audio
+  local-network     Needed by apps that talk to devices on the LAN (macOS 15+)
+  biometrics        Needed by apps that confirm the user with Touch ID, Face ID
+                    or Windows Hello
+  notifications     Needed by apps that show local notifications`),
+}
+
+var permissionsBundleID string
+
+var permissionsCheckCmd = &cobra.Command{
+	Use:   "check [app-directory]",
+	Short: i18n.T("Show which permissions are granted"),
+	Long: i18n.T(`Show the status of OS privacy permissions.
+
+Without arguments all permissions are checked for the app that launched
+goup-util (usually your terminal). With an app directory, only the
+permissions listed in its app.json "permissions" section are checked.
+
+Detection on macOS reads the TCC database, which requires Full Disk Access
+for the terminal; otherwise the status is reported as unknown.
+
+Examples:
+  goup-util permissions check
+  goup-util permissions check examples/gio-plugin-webviewer
+  goup-util permissions check --bundle-id com.example.myapp`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		perms := permissions.All
+		if len(args) > 0 {
+			cfg, err := appconfig.Load(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := permissions.UsageDescriptions(cfg.Permissions); err != nil {
+				return fmt.Errorf(i18n.T("%s: %w"), appconfig.ConfigFileName, err)
+			}
+			perms = nil
+			for _, p := range permissions.All {
+				if _, ok := cfg.Permissions[string(p)]; ok {
+					perms = append(perms, p)
+				}
+			}
+			if len(perms) == 0 {
+				fmt.Printf("ℹ️  %s declares no permissions\n", appconfig.ConfigFileName)
+				return nil
+			}
+		}
+
+		results := permissions.Check(permissions.Options{BundleID: permissionsBundleID}, perms...)
+		for _, r := range results {
+			icon := "❓"
+			switch r.Status {
+			case permissions.Granted, permissions.NotRequired:
+				icon = "✅"
+			case permissions.Denied:
+				icon = "❌"
+			case permissions.NotDetermined:
+				icon = "⚠️ "
+			}
+			fmt.Printf("%s %-17s %s", icon, r.Permission, r.Status)
+			if r.Detail != "" {
+				fmt.Printf(" (%s)", r.Detail)
+			}
+			fmt.Println()
+			if r.Fix != "" {
+				fmt.Printf("   → %s\n", r.Fix)
+			}
+		}
+
+		if !permissions.OK(results) {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+var permissionsOpenCmd = &cobra.Command{
+	Use:       "open <permission>",
+	Short:     i18n.T("Open the OS settings page for a permission"),
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"screen-recording", "camera", "microphone", "local-network"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := permissions.Parse(args[0])
+		if err != nil {
+			return err
+		}
+		return permissions.Open(p)
+	},
+}
+
+func init() {
+	permissionsCheckCmd.Flags().StringVar(&permissionsBundleID, "bundle-id", "", i18n.T("macOS bundle identifier to check (default: the launching app)"))
+
+	permissionsCmd.AddCommand(permissionsCheckCmd)
+	permissionsCmd.AddCommand(permissionsOpenCmd)
+	rootCmd.AddCommand(permissionsCmd)
+	permissionsCmd.GroupID = "tools"
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/plugins"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pluginsJSON  bool
+	pluginsInput string
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: i18n.T("List and run plugin commands (goup-util-<name> executables)"),
+	Long: `Plugins add commands to goup-util without changing it. Any executable
+named goup-util-<name> on the PATH (or in $` + plugins.PathEnv + `) runs as
+'goup-util <name>', with all arguments passed through. Built-in commands
+cannot be overridden.
+
+A plugin can describe its input and output with JSON Schemas, in a sidecar
+goup-util-<name>.json or by answering ` + plugins.ManifestFlag + ` (plugins
+written with plugins.Main in Go do this for free). Those schemas are
+exported as tool definitions by 'plugins schema' and enforced by
+'plugins run', so plugins can be driven by scripts and agents like the
+built-in commands.
+
+Examples:
+  goup-util plugins list
+  goup-util store-upload --track beta app.apk
+  goup-util plugins run store-upload --input '{"artifact": "app.apk"}'
+  goup-util plugins schema > tools.json`,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("List installed plugins"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		found := plugins.Discover()
+		if pluginsJSON {
+			type entry struct {
+				Name  string `json:"name"`
+				Path  string `json:"path"`
+				Short string `json:"short,omitempty"`
+			}
+			list := []entry{}
+			for _, p := range found {
+				m, _ := p.Manifest()
+				e := entry{Name: p.Name, Path: p.Path}
+				if m != nil {
+					e.Short = m.Short
+				}
+				list = append(list, e)
+			}
+			return writeJSONOut(list)
+		}
+		if len(found) == 0 {
+			fmt.Println("No plugins found (executables named " + plugins.Prefix + "<name> on the PATH)")
+			return nil
+		}
+		for _, p := range found {
+			short := ""
+			if m, err := p.Manifest(); err == nil {
+				short = m.Short
+			}
+			shadowed := ""
+			if c, _, err := rootCmd.Find([]string{p.Name}); err == nil && c != rootCmd {
+				shadowed = " (⚠️  hidden by built-in command)"
+			}
+			fmt.Printf("  %-20s %s%s\n      %s\n", p.Name, short, shadowed, p.Path)
+		}
+		return nil
+	},
+}
+
+var pluginsSchemaCmd = &cobra.Command{
+	Use:   "schema [name]",
+	Short: i18n.T("Print plugin tool definitions (name, description, input and output schemas)"),
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		type tool struct {
+			Name         string `json:"name"`
+			Description  string `json:"description,omitempty"`
+			InputSchema  any    `json:"inputSchema"`
+			OutputSchema any    `json:"outputSchema,omitempty"`
+		}
+		tools := []tool{}
+		for _, p := range plugins.Discover() {
+			if len(args) == 1 && p.Name != args[0] {
+				continue
+			}
+			m, err := p.Manifest()
+			if err != nil {
+				return err
+			}
+			t := tool{Name: m.Name, Description: m.Short, InputSchema: map[string]any{"type": "object"}}
+			if m.Description != "" {
+				t.Description = m.Description
+			}
+			if m.Input != nil {
+				t.InputSchema = m.Input
+			}
+			if m.Output != nil {
+				t.OutputSchema = m.Output
+			}
+			tools = append(tools, t)
+		}
+		if len(args) == 1 && len(tools) == 0 {
+			return fmt.Errorf(i18n.T("no plugin named %s"), args[0])
+		}
+		return writeJSONOut(tools)
+	},
+}
+
+var pluginsRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: i18n.T("Run a plugin with JSON input validated against its schema"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, ok := plugins.Find(args[0])
+		if !ok {
+			return fmt.Errorf(i18n.T("no plugin named %s"), args[0])
+		}
+		m, err := p.Manifest()
+		if err != nil {
+			return err
+		}
+		input := []byte(pluginsInput)
+		switch {
+		case pluginsInput == "-":
+			input, err = io.ReadAll(os.Stdin)
+		case strings.HasPrefix(pluginsInput, "@"):
+			input, err = os.ReadFile(pluginsInput[1:])
+		case pluginsInput == "":
+			input = []byte("{}")
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to read input: %w"), err)
+		}
+		out, err := p.RunJSON(rootCmd.Version, m, input)
+		if err != nil {
+			return err
+		}
+		return writeJSONOut(json.RawMessage(out))
+	},
+}
+
+// runPlugin runs goup-util-<name> when the first argument is not a built-in
+// command. It reports whether a plugin ran, with its exit code.
+func runPlugin(args []string) (int, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return 0, false
+	}
+	switch args[0] {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return 0, false
+	}
+	if c, _, err := rootCmd.Find(args[:1]); err == nil && c != rootCmd {
+		return 0, false
+	}
+	p, ok := plugins.Find(args[0])
+	if !ok {
+		return 0, false
+	}
+
+	cmd := p.Command(rootCmd.Version, args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return exit.ExitCode(), true
+		}
+		fmt.Fprintf(os.Stderr, "Error: plugin %s: %v\n", p.Name, err)
+		return 1, true
+	}
+	return 0, true
+}
+
+func init() {
+	pluginsListCmd.Flags().BoolVar(&pluginsJSON, "json", false, i18n.T("Print as JSON"))
+	pluginsRunCmd.Flags().StringVar(&pluginsInput, "input", "", i18n.T("JSON input, @file or - for stdin"))
+
+	pluginsCmd.AddCommand(pluginsListCmd)
+	pluginsCmd.AddCommand(pluginsSchemaCmd)
+	pluginsCmd.AddCommand(pluginsRunCmd)
+	rootCmd.AddCommand(pluginsCmd)
+	pluginsCmd.GroupID = "tools"
+}
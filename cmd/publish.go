@@ -0,0 +1,593 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/appstore"
+	"github.com/joeblew999/goup-util/pkg/artifact"
+	"github.com/joeblew999/goup-util/pkg/capabilities"
+	"github.com/joeblew999/goup-util/pkg/changelog"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/listing"
+	"github.com/joeblew999/goup-util/pkg/metadata"
+	"github.com/joeblew999/goup-util/pkg/playstore"
+	"github.com/joeblew999/goup-util/pkg/precheck"
+	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/joeblew999/goup-util/pkg/secrets"
+	"github.com/spf13/cobra"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: i18n.T("Push store listings and releases to App Store Connect and Google Play"),
+	Long: i18n.T(`Publish store metadata kept in git next to the app.
+
+Credentials come from 'goup-util secrets':
+
+  App Store Connect   APPSTORE_API_KEY, APPSTORE_API_KEY_ID, APPSTORE_API_ISSUER
+  Google Play         GOOGLE_PLAY_SERVICE_ACCOUNT`),
+}
+
+var publishMetadataCmd = &cobra.Command{
+	Use:   "metadata <platform> <app-directory>",
+	Short: i18n.T("Push per-locale titles, descriptions, keywords and release notes"),
+	Long: i18n.T(`Push the listing text in <app-directory>/metadata to the stores.
+
+The directory holds one folder per locale (en-US, de-DE, ...) with one
+text file per field, the same layout as fastlane deliver:
+
+  name.txt               App Store name, Play title (30)
+  subtitle.txt           App Store subtitle (30)
+  description.txt        Both stores (4000)
+  short_description.txt  Play short description (80)
+  keywords.txt           App Store keywords, comma or line separated (100)
+  release_notes.txt      App Store "What's New" (4000), Play release notes (500)
+  release_notes.tmpl     Template for release_notes.txt (see 'publish notes')
+  promotional_text.txt   App Store promotional text (170)
+  support_url.txt, marketing_url.txt, privacy_url.txt   App Store URLs
+
+Missing files leave the store's value unchanged. Lengths are checked before
+anything is sent.
+
+Platforms: ios and macos push to the editable App Store Connect version,
+android to the Play listing (and the newest release on --track); all means
+ios and android.
+
+Examples:
+  goup-util publish metadata init ./my-gio-app
+  goup-util publish metadata all ./my-gio-app --bundle-id com.example.myapp --dry-run
+  goup-util publish metadata ios ./my-gio-app --bundle-id com.example.myapp
+  goup-util publish metadata android ./my-gio-app --bundle-id com.example.myapp --track beta --images`),
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platform, appDir := args[0], args[1]
+		var targets []string
+		switch platform {
+		case "ios", "macos", "android":
+			targets = []string{platform}
+		case "all":
+			targets = []string{"ios", "android"}
+		default:
+			return fmt.Errorf(i18n.T("invalid platform: %s. Valid platforms: [ios macos android all]"), platform)
+		}
+		bundleID, _ := cmd.Flags().GetString("bundle-id")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if bundleID == "" && !dryRun {
+			return errors.New(i18n.T("--bundle-id is required (the bundle ID or package name registered with the store)"))
+		}
+
+		locales, err := metadata.Load(appDir)
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to read metadata: %w"), err)
+		}
+		if len(locales) == 0 {
+			return fmt.Errorf(i18n.T("no metadata in %s (run 'goup-util publish metadata init %s')"), filepath.Join(appDir, metadata.Dir), appDir)
+		}
+		version, _ := cmd.Flags().GetString("version")
+		if err := renderReleaseNotes(appDir, version, locales); err != nil {
+			return err
+		}
+
+		var problems []string
+		for _, t := range targets {
+			for _, l := range locales {
+				for _, p := range l.Check(storeFor(t)) {
+					problems = append(problems, p.Error())
+				}
+			}
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf(i18n.T("metadata is not valid:\n  %s"), strings.Join(problems, "\n  "))
+		}
+
+		if dryRun {
+			for _, l := range locales {
+				fmt.Printf("%s: %s\n", l.Code, strings.Join(metadataFields(l), ", "))
+			}
+			fmt.Printf("✓ Metadata for %d locales is valid for %s\n", len(locales), strings.Join(targets, " and "))
+			return nil
+		}
+
+		store := secrets.Open("")
+		for _, t := range targets {
+			if t == "android" {
+				track, _ := cmd.Flags().GetString("track")
+				images, _ := cmd.Flags().GetBool("images")
+				err = publishPlayMetadata(store, appDir, bundleID, track, images, locales)
+			} else {
+				err = publishAppStoreMetadata(store, appDir, bundleID, t, locales)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var publishMetadataInitCmd = &cobra.Command{
+	Use:   "init <app-directory>",
+	Short: i18n.T("Create metadata/en-US with starter files"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := metadata.Init(args[0], filepath.Base(mustAbs(args[0])))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Created %s\n", dir)
+		fmt.Println("  Fill in the files, add a folder per extra locale, and commit them with the app")
+		return nil
+	},
+}
+
+var publishNotesCmd = &cobra.Command{
+	Use:   "notes <app-directory>",
+	Short: i18n.T("Render the release notes for each locale"),
+	Long: i18n.T(`Print the release notes 'publish metadata' and 'distribute' send, per
+locale: release_notes.txt as written, or release_notes.tmpl filled in from
+the commits since the previous git tag.
+
+A template is a Go text/template with these variables:
+
+  .Version      --version, or the git tag on HEAD without its "v"
+  .Date         today, as a time ({{.Date.Format "2.1.2006"}})
+  .Locale       the locale directory, e.g. de-DE
+  .Highlights   breaking changes, then features, then fixes
+  .Breaking     commits marked "feat!:" or with a BREAKING CHANGE footer
+  .Features     "feat:" commits
+  .Fixes        "fix:" commits
+  .Changes      every commit
+
+Each list holds one line per commit: its subject without the type, or its
+"Release-Note-de: ..." (per locale or language) or "Release-Note: ..."
+trailer. "Release-Note: -" leaves a commit out. {{bullets .Fixes}} lists
+lines as "- line", and {{first 3 .Highlights}} keeps the first three.
+
+Example metadata/de-DE/release_notes.tmpl:
+
+  Version {{.Version}} vom {{.Date.Format "2.1.2006"}}
+  {{bullets (first 5 .Highlights)}}`),
+	Example: `  goup-util publish notes examples/hybrid-dashboard
+  goup-util publish notes . --locale de-DE --version 1.5.0`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appDir := args[0]
+		version, _ := cmd.Flags().GetString("version")
+		only, _ := cmd.Flags().GetString("locale")
+		locales, err := metadata.Load(appDir)
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to read metadata: %w"), err)
+		}
+		written := map[string]bool{}
+		for _, l := range locales {
+			written[l.Code] = l.ReleaseNotes != ""
+		}
+		if err := renderReleaseNotes(appDir, version, locales); err != nil {
+			return err
+		}
+		shown := 0
+		for _, l := range locales {
+			if l.ReleaseNotes == "" || (only != "" && l.Code != only) {
+				continue
+			}
+			source := "release_notes.txt"
+			if !written[l.Code] {
+				source = metadata.NotesTemplate
+			}
+			n := len([]rune(l.ReleaseNotes))
+			fmt.Printf("📝 %s (%s, %d characters)\n%s\n\n", l.Code, source, n, l.ReleaseNotes)
+			for _, f := range metadata.Fields {
+				if f.File == "release_notes.txt" && n > f.Play {
+					fmt.Printf("⚠️  Over Google Play's limit of %d characters; 'publish metadata android' will refuse it\n\n", f.Play)
+				}
+			}
+			shown++
+		}
+		if shown == 0 {
+			return fmt.Errorf(i18n.T("no release notes in %s (add release_notes.txt or %s to a locale)"), filepath.Join(appDir, metadata.Dir), metadata.NotesTemplate)
+		}
+		return nil
+	},
+}
+
+var publishPrecheckCmd = &cobra.Command{
+	Use:   "precheck <platform> <app-directory>",
+	Short: i18n.T("Check a store build for common review rejections before uploading it"),
+	Long: `Check the built app for what App Store and Google Play review reject most
+often, before it costs a review cycle:
+
+  ios, macos  Info.plist usage descriptions for the APIs the binary uses
+              and the app's capabilities, export compliance
+              (ITSAppUsesNonExemptEncryption), arm64 code, opaque icons
+              (iOS) and a privacy manifest
+  android     targetSdkVersion (at least ` + fmt.Sprint(precheck.PlayMinTargetSdk) + `), a 64-bit library for every
+              32-bit ABI, 16 KB aligned native libraries, not debuggable
+
+The build is looked for in .dist (goup-util bundle) and .bin (goup-util
+build), like 'audit capabilities'; --artifact names one instead. The exit
+status is non-zero when a required check fails.`,
+	Example: `  goup-util publish precheck ios examples/hybrid-dashboard
+  goup-util publish precheck android . --artifact .dist/myapp.aab --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platform, appDir := args[0], args[1]
+		asJSON, _ := cmd.Flags().GetBool("json")
+		path, _ := cmd.Flags().GetString("artifact")
+		proj, err := project.NewGioProject(appDir)
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to create project: %w"), err)
+		}
+
+		var a *artifact.Artifact
+		if path != "" {
+			if a, err = artifact.Open(path); err != nil {
+				return err
+			}
+		} else if a = capabilities.OpenBundle(proj.RootDir, proj.Name, platform); a == nil {
+			return fmt.Errorf(i18n.T("no %s build of %s (run 'goup-util build %s %s')"), platform, proj.Name, platform, appDir)
+		}
+
+		// Usage descriptions the app's capabilities need, whether or not
+		// the binary shows them
+		var opts precheck.Options
+		caps, err := capabilities.Detect(proj.RootDir, appconfig.LoadOrDefault(proj.RootDir))
+		if err != nil {
+			return err
+		}
+		for _, req := range capabilities.Requirements(caps) {
+			if req.Kind == capabilities.InfoPlist && req.Platform == platform && !req.Optional {
+				opts.UsageKeys = append(opts.UsageKeys, req.Key)
+			}
+		}
+
+		report, err := precheck.Check(a, platform, opts)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			printPrecheckReport(report)
+		}
+		if !report.OK() {
+			return errors.New(i18n.T("the build would likely be rejected"))
+		}
+		return nil
+	},
+}
+
+func printPrecheckReport(r *precheck.Report) {
+	fmt.Printf("🔎 %s (%s)\n", r.Artifact, r.Platform)
+	for _, f := range r.Findings {
+		mark := "✓"
+		switch {
+		case f.Status == precheck.Skipped:
+			mark = "·"
+		case f.Status == precheck.Pass:
+		case f.Optional:
+			mark = "⚠️ "
+		default:
+			mark = "❌"
+		}
+		fmt.Printf("  %s %-36s %s\n", mark, f.Check, f.Detail)
+		if f.Status == precheck.Fail && f.Fix != "" {
+			fmt.Printf("       → %s\n", f.Fix)
+		}
+	}
+}
+
+// renderReleaseNotes fills in the release notes of locales that have a
+// template and no release_notes.txt, from the commits in appDir since the
+// previous tag. version defaults to the tag on HEAD.
+func renderReleaseNotes(appDir, version string, locales []metadata.Locale) error {
+	var notes *changelog.Notes
+	for i, l := range locales {
+		if l.NotesTemplate == "" || l.ReleaseNotes != "" {
+			continue
+		}
+		if notes == nil {
+			commits, err := changelog.Log(appDir)
+			if err != nil {
+				return err
+			}
+			if version == "" {
+				version = changelog.Version(appDir)
+			}
+			notes = &changelog.Notes{Version: version, Date: time.Now(), Commits: commits}
+		}
+		text, err := notes.Render(l.NotesTemplate, l.Code)
+		if err != nil {
+			return err
+		}
+		locales[i].ReleaseNotes = text
+	}
+	return nil
+}
+
+func storeFor(platform string) string {
+	if platform == "android" {
+		return metadata.Play
+	}
+	return metadata.AppStore
+}
+
+// metadataFields names the files a locale sets.
+func metadataFields(l metadata.Locale) []string {
+	var names []string
+	for _, f := range []struct{ name, value string }{
+		{"name", l.Name}, {"subtitle", l.Subtitle}, {"description", l.Description},
+		{"short_description", l.ShortDescription}, {"keywords", l.Keywords},
+		{"release_notes", l.ReleaseNotes}, {"promotional_text", l.PromotionalText},
+		{"support_url", l.SupportURL}, {"marketing_url", l.MarketingURL}, {"privacy_url", l.PrivacyURL},
+	} {
+		if f.value != "" {
+			names = append(names, f.name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{"(empty)"}
+	}
+	return names
+}
+
+// nonEmpty keeps the values that are set, as API attributes.
+func nonEmpty(values map[string]string) map[string]any {
+	attrs := map[string]any{}
+	for k, v := range values {
+		if v != "" {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+// appStoreKey resolves the App Store Connect API key secrets.
+func appStoreKey(store *secrets.Store) (key []byte, keyID, issuer string, err error) {
+	secret, err := store.Get("APPSTORE_API_KEY")
+	if err != nil {
+		return nil, "", "", err
+	}
+	if keyID, err = store.Text("APPSTORE_API_KEY_ID"); err != nil {
+		return nil, "", "", err
+	}
+	if issuer, err = store.Text("APPSTORE_API_ISSUER"); err != nil {
+		return nil, "", "", err
+	}
+	if secret == nil || keyID == "" || issuer == "" {
+		return nil, "", "", errors.New(i18n.T("App Store Connect credentials missing: set APPSTORE_API_KEY, APPSTORE_API_KEY_ID and APPSTORE_API_ISSUER (see 'goup-util secrets')"))
+	}
+	return secret.Value, keyID, issuer, nil
+}
+
+func publishAppStoreMetadata(store *secrets.Store, appDir, bundleID, platform string, locales []metadata.Locale) error {
+	key, keyID, issuer, err := appStoreKey(store)
+	if err != nil {
+		return err
+	}
+	client, err := appstore.New(key, keyID, issuer)
+	if err != nil {
+		return err
+	}
+
+	ascPlatform := appstore.IOS
+	if platform == "macos" {
+		ascPlatform = appstore.MacOS
+	}
+	appID, err := client.AppID(bundleID)
+	if err != nil {
+		return err
+	}
+	version, err := client.EditableVersion(appID, ascPlatform)
+	if err != nil {
+		return err
+	}
+	info, infoEditable, err := client.EditableAppInfo(appID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📝 App Store Connect: %s %s %s\n", bundleID, platform, version.Attr("versionString"))
+
+	for _, l := range locales {
+		versionAttrs := nonEmpty(map[string]string{
+			"description":     l.Description,
+			"keywords":        l.Keywords,
+			"whatsNew":        l.ReleaseNotes,
+			"promotionalText": l.PromotionalText,
+			"supportUrl":      l.SupportURL,
+			"marketingUrl":    l.MarketingURL,
+		})
+		infoAttrs := nonEmpty(map[string]string{
+			"name":             l.Name,
+			"subtitle":         l.Subtitle,
+			"privacyPolicyUrl": l.PrivacyURL,
+		})
+		if len(versionAttrs) > 0 {
+			created, err := client.Localize("appStoreVersionLocalizations", version, l.Code, versionAttrs)
+			if err != nil {
+				return fmt.Errorf(i18n.T("%s: %w"), l.Code, err)
+			}
+			if created {
+				fmt.Printf("  + %s: added locale\n", l.Code)
+			}
+		}
+		if len(infoAttrs) > 0 {
+			if !infoEditable {
+				fmt.Printf("  ⚠️  %s: name, subtitle and privacy URL can only change with a new app version; skipped\n", l.Code)
+			} else if _, err := client.Localize("appInfoLocalizations", info, l.Code, infoAttrs); err != nil {
+				return fmt.Errorf(i18n.T("%s: %w"), l.Code, err)
+			}
+		}
+		fmt.Printf("  ✓ %s: %s\n", l.Code, strings.Join(metadataFields(l), ", "))
+	}
+
+	build, ok, err := client.VersionBuild(version)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := setExportCompliance(client, build, appDir); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✅ App Store Connect metadata updated for %d locales\n", len(locales))
+	return nil
+}
+
+// setExportCompliance answers an App Store Connect build's encryption
+// export question from the exportCompliance in appDir's app.json, if any.
+func setExportCompliance(client *appstore.Client, build appstore.Resource, appDir string) error {
+	if appDir == "" {
+		return nil
+	}
+	ec := appconfig.LoadOrDefault(appDir).ExportCompliance
+	if ec == nil {
+		return nil
+	}
+	if err := client.SetExportCompliance(build, ec.UsesNonExemptEncryption); err != nil {
+		return fmt.Errorf(i18n.T("failed to set export compliance: %w"), err)
+	}
+	fmt.Printf("✓ Export compliance for build %s: usesNonExemptEncryption=%t\n", build.Attr("version"), ec.UsesNonExemptEncryption)
+	return nil
+}
+
+func publishPlayMetadata(store *secrets.Store, appDir, packageName, track string, images bool, locales []metadata.Locale) error {
+	sa, err := store.Get("GOOGLE_PLAY_SERVICE_ACCOUNT")
+	if err != nil {
+		return err
+	}
+	if sa == nil {
+		return errors.New(i18n.T("Google Play credentials missing: set GOOGLE_PLAY_SERVICE_ACCOUNT (see 'goup-util secrets')"))
+	}
+	client, err := playstore.New(sa.Value)
+	if err != nil {
+		return err
+	}
+
+	var graphics map[string]string
+	if images {
+		if graphics, err = listingGraphics(appDir); err != nil {
+			return err
+		}
+	}
+
+	edit, err := client.BeginEdit(packageName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📝 Google Play: %s (edit %s)\n", packageName, edit.ID)
+	err = func() error {
+		notes := map[string]string{}
+		for _, l := range locales {
+			lang := playstore.Language(l.Code)
+			entry := playstore.Listing{Title: l.Name, ShortDescription: l.ShortDescription, FullDescription: l.Description}
+			if entry != (playstore.Listing{}) {
+				if err := edit.UpdateListing(lang, entry); err != nil {
+					return fmt.Errorf(i18n.T("%s: %w"), l.Code, err)
+				}
+			}
+			if l.ReleaseNotes != "" {
+				notes[lang] = l.ReleaseNotes
+			}
+			for imageType, path := range graphics {
+				if err := edit.UploadImage(lang, imageType, path); err != nil {
+					return fmt.Errorf(i18n.T("%s: %w"), l.Code, err)
+				}
+			}
+			fmt.Printf("  ✓ %s: %s\n", lang, strings.Join(metadataFields(l), ", "))
+		}
+		if len(notes) > 0 {
+			ok, err := edit.SetReleaseNotes(track, notes)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Printf("  ⚠️  %s track has no release yet; release notes skipped\n", track)
+			}
+		}
+		return edit.Commit()
+	}()
+	if err != nil {
+		edit.Abort()
+		return err
+	}
+	fmt.Printf("✅ Google Play listing updated for %d locales\n", len(locales))
+	return nil
+}
+
+// listingGraphics finds the feature graphic and TV banner made by
+// 'goup-util listing generate'.
+func listingGraphics(appDir string) (map[string]string, error) {
+	l, err := listing.Load(appDir)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		l = listing.Default(appDir, filepath.Base(mustAbs(appDir)))
+	}
+	graphics := map[string]string{}
+	for file, imageType := range map[string]string{
+		listing.FeatureGraphic + ".png": playstore.FeatureGraphic,
+		listing.TVBanner + ".png":       playstore.TVBanner,
+	} {
+		path := filepath.Join(l.OutputDir(), file)
+		if _, err := os.Stat(path); err == nil {
+			graphics[imageType] = path
+		}
+	}
+	if len(graphics) == 0 {
+		return nil, fmt.Errorf(i18n.T("no listing graphics in %s (run 'goup-util listing generate %s')"), l.OutputDir(), appDir)
+	}
+	return graphics, nil
+}
+
+func init() {
+	publishMetadataCmd.Flags().String("bundle-id", "", i18n.T("Bundle ID (App Store) or package name (Play)"))
+	publishMetadataCmd.Flags().String("track", "production", i18n.T("Play track whose newest release gets the release notes"))
+	publishMetadataCmd.Flags().Bool("images", false, i18n.T("Also upload the feature graphic and TV banner from 'listing generate' to Play"))
+	publishMetadataCmd.Flags().Bool("dry-run", false, i18n.T("Check the metadata and show what would be sent"))
+	publishMetadataCmd.Flags().Bool("no-hooks", false, i18n.T("Skip the hooks in goup.json"))
+	publishMetadataCmd.Flags().String("version", "", i18n.T("Version for release note templates (default: the git tag on HEAD)"))
+	publishMetadataCmd.RunE = withHooks("publish", publishMetadataCmd.RunE)
+
+	publishNotesCmd.Flags().String("version", "", i18n.T("Version for release note templates (default: the git tag on HEAD)"))
+	publishNotesCmd.Flags().String("locale", "", i18n.T("Only this locale"))
+	publishPrecheckCmd.Flags().String("artifact", "", i18n.T("Check this .ipa, .app, .apk or .aab instead of the newest build"))
+	publishPrecheckCmd.Flags().Bool("json", false, i18n.T("Print the report as JSON"))
+
+	publishMetadataCmd.AddCommand(publishMetadataInitCmd)
+	publishCmd.AddCommand(publishMetadataCmd, publishNotesCmd, publishPrecheckCmd)
+
+	publishCmd.GroupID = "build"
+	rootCmd.AddCommand(publishCmd)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/release"
+	"github.com/spf13/cobra"
+)
+
+var releaseAllCmd = &cobra.Command{
+	Use:   "release-all",
+	Short: i18n.T("Build, bundle and publish several apps with one version"),
+	Long: i18n.T(`Release every app listed in apps.json in lock step. Each app has its own
+directory, app.json and bundle ID, and all of them share the version:
+
+  {
+    "version": "1.4.0",
+    "buildNumber": "auto",
+    "platforms": ["macos", "android"],
+    "apps": [
+      {"name": "acme", "dir": "shells/acme", "bundleId": "com.acme.portal", "publish": true},
+      {"name": "globex", "dir": "shells/globex", "bundleId": "com.globex.hub",
+       "platforms": ["macos", "windows"], "bundleFlags": ["--publisher", "CN=Globex"]}
+    ]
+  }
+
+For each app and platform this runs build, then bundle (macOS and
+Windows), then publish metadata when "publish" is set. A failed step
+skips the rest of that app's platform; the other apps carry on, and a
+summary of all outcomes is printed at the end.`),
+	Example: `  goup-util release-all --apps apps.json
+  goup-util release-all --apps apps.json --version 1.5.0 --only acme,globex
+  goup-util release-all --apps apps.json --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appsFile, _ := cmd.Flags().GetString("apps")
+		version, _ := cmd.Flags().GetString("version")
+		buildNumber, _ := cmd.Flags().GetString("build-number")
+		only, _ := cmd.Flags().GetStringSlice("only")
+		skipPublish, _ := cmd.Flags().GetBool("skip-publish")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		m, err := release.Load(appsFile)
+		if err != nil {
+			return err
+		}
+		if version != "" {
+			m.Version = version
+		}
+		if buildNumber != "" {
+			m.BuildNumber = buildNumber
+		}
+		if m.Version == "" {
+			return fmt.Errorf(i18n.T("no version: set \"version\" in %s or pass --version"), appsFile)
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to find goup-util executable: %w"), err)
+		}
+		stdout := os.Stdout
+		if asJSON {
+			os.Stdout = os.Stderr
+		}
+		outcomes, err := m.Run(release.Options{
+			Only:        only,
+			SkipPublish: skipPublish,
+			DryRun:      dryRun,
+			Out:         os.Stdout,
+			Exec: func(args []string) error {
+				c := exec.Command(exe, args...)
+				c.Stdout, c.Stderr = os.Stdout, os.Stderr
+				return c.Run()
+			},
+		})
+		os.Stdout = stdout
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(outcomes); err != nil {
+				return err
+			}
+		} else {
+			printReleaseSummary(m.Version, outcomes)
+		}
+		if release.AnyFailed(outcomes) {
+			return fmt.Errorf(i18n.T("release %s failed for some apps"), m.Version)
+		}
+		return nil
+	},
+}
+
+// printReleaseSummary prints one line per app and platform with the
+// outcome of each stage.
+func printReleaseSummary(version string, outcomes []release.Outcome) {
+	fmt.Printf("\n📋 Release %s\n", version)
+	var order []string
+	rows := map[string][]string{}
+	for _, o := range outcomes {
+		key := fmt.Sprintf("%-20s %-14s", o.App, o.Platform)
+		if _, ok := rows[key]; !ok {
+			order = append(order, key)
+		}
+		mark := "✓"
+		switch o.Status {
+		case release.Failed:
+			mark = "❌"
+		case release.Skipped:
+			mark = "·"
+		}
+		rows[key] = append(rows[key], mark+" "+o.Stage)
+	}
+	for _, key := range order {
+		fmt.Printf("  %s %s\n", key, strings.Join(rows[key], "  "))
+	}
+}
+
+func init() {
+	releaseAllCmd.Flags().String("apps", "apps.json", i18n.T("File listing the apps to release"))
+	releaseAllCmd.Flags().String("version", "", i18n.T("Version for every app (default: \"version\" in the apps file)"))
+	releaseAllCmd.Flags().String("build-number", "", i18n.T("Build number for every app: a number or 'auto' (default: \"buildNumber\" in the apps file)"))
+	releaseAllCmd.Flags().StringSlice("only", nil, i18n.T("Release only these apps (comma-separated names)"))
+	releaseAllCmd.Flags().Bool("skip-publish", false, i18n.T("Build and bundle without publishing"))
+	releaseAllCmd.Flags().Bool("dry-run", false, i18n.T("Print the commands without running them"))
+	releaseAllCmd.Flags().Bool("json", false, i18n.T("Print the outcomes as JSON; command output goes to stderr"))
+
+	releaseAllCmd.GroupID = "build"
+	rootCmd.AddCommand(releaseAllCmd)
+}
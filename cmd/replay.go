@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayList bool
+	replayWait time.Duration
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <session-file> [app-directory]",
+	Short: i18n.T("Replay a recorded session in a running app"),
+	Long: `Re-inject the deep links, page navigations and JavaScript bridge calls of
+a recorded session into a running app, with the pauses between them as
+recorded (at most ` + session.MaxPause.String() + `), to reproduce a bug that needs them.
+
+Record a session by starting the app with 'goup-util run --record'; each
+launch writes a new file to <app>/` + session.Dir + `. The app has to be
+running with --record to take a replay, and it records the replay as a
+new session too, so the two can be compared. The app must implement
+recording; the webviewer shell and the hybrid-dashboard example do.
+
+Examples:
+  goup-util run macos examples/hybrid-dashboard --record
+  goup-util replay examples/hybrid-dashboard/.bin/sessions/20261018-093000.jsonl examples/hybrid-dashboard
+  goup-util replay crash-report.jsonl --list`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		events, err := session.Load(args[0])
+		if err != nil {
+			return err
+		}
+		if replayList || len(args) == 1 {
+			printSession(events)
+			return nil
+		}
+
+		dir := filepath.Join(args[1], session.Dir)
+		if _, err := os.Stat(dir); err != nil {
+			return fmt.Errorf(i18n.T("%s has never recorded a session; start it with: goup-util run <platform> %s --record"), args[1], args[1])
+		}
+		if err := session.Send(dir, events); err != nil {
+			return err
+		}
+		var total time.Duration
+		for _, p := range session.Pauses(events) {
+			total += p
+		}
+		fmt.Printf("📼 Sent %d events (%s) to %s\n", len(events), total.Round(time.Second), args[1])
+
+		replay := filepath.Join(dir, session.ReplayFile)
+		for deadline := time.Now().Add(replayWait); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+			if _, err := os.Stat(replay); os.IsNotExist(err) {
+				fmt.Println("✅ The app is replaying the session")
+				return nil
+			}
+		}
+		fmt.Printf("⚠️  No app took it within %s; it will replay when the app is next started with --record\n", replayWait)
+		return nil
+	},
+}
+
+// printSession lists events with their time from the start.
+func printSession(events []session.Event) {
+	for _, e := range events {
+		target := e.URL
+		if e.Kind == session.Bridge {
+			target = firstLine(e.Data)
+		}
+		fmt.Printf("%9s  %-8s  %s\n", e.At.Sub(events[0].At).Round(time.Millisecond), e.Kind, target)
+	}
+	fmt.Printf("%d events\n", len(events))
+}
+
+func init() {
+	replayCmd.Flags().BoolVar(&replayList, "list", false, i18n.T("Print the session's events instead of replaying them"))
+	replayCmd.Flags().DurationVar(&replayWait, "wait", 5*time.Second, i18n.T("How long to wait for the app to take the session"))
+
+	replayCmd.GroupID = "build"
+	rootCmd.AddCommand(replayCmd)
+}
@@ -3,14 +3,15 @@ package cmd
 import (
 	"os"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/schema"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "goup-util",
-	Short: "Build cross-platform hybrid apps with Go",
-	Long: `goup-util - Build cross-platform hybrid applications using Go and Gio UI.
+	Short: i18n.T("Build cross-platform hybrid apps with Go"),
+	Long: i18n.T(`goup-util - Build cross-platform hybrid applications using Go and Gio UI.
 
 Write HTML/CSS once → Deploy everywhere: Web, iOS, Android, Desktop
 
@@ -25,10 +26,14 @@ SDK MANAGEMENT:
 
 DOCUMENTATION:
   goup-util docs                                    Generate CLI docs
-  https://github.com/joeblew999/goup-util`,
+  https://github.com/joeblew999/goup-util`),
 }
 
 func Execute() {
+	// Unknown commands may be plugins (goup-util-<name> on the PATH)
+	if code, ok := runPlugin(os.Args[1:]); ok {
+		os.Exit(code)
+	}
 	cobra.CheckErr(rootCmd.Execute())
 }
 
@@ -38,11 +43,11 @@ func init() {
 
 	// Add command groups for better help organization
 	rootCmd.AddGroup(
-		&cobra.Group{ID: "build", Title: "Build Commands:"},
-		&cobra.Group{ID: "sdk", Title: "SDK Management:"},
-		&cobra.Group{ID: "tools", Title: "Development Tools:"},
-		&cobra.Group{ID: "vm", Title: "Virtual Machines:"},
-		&cobra.Group{ID: "self", Title: "Self Management:"},
+		&cobra.Group{ID: "build", Title: i18n.T("Build Commands:")},
+		&cobra.Group{ID: "sdk", Title: i18n.T("SDK Management:")},
+		&cobra.Group{ID: "tools", Title: i18n.T("Development Tools:")},
+		&cobra.Group{ID: "vm", Title: i18n.T("Virtual Machines:")},
+		&cobra.Group{ID: "self", Title: i18n.T("Self Management:")},
 	)
 
 	// Enable shell completion descriptions
@@ -97,3 +102,15 @@ func getPlatformCompletion(cmd *cobra.Command, args []string, toComplete string)
 	}
 	return completions, cobra.ShellCompDirectiveNoFileComp
 }
+
+// Helper to get completion for icon platforms - uses shared schema
+func getIconPlatformCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return getExampleCompletion(cmd, args, toComplete)
+	}
+	var completions []string
+	for _, p := range schema.IconPlatforms {
+		completions = append(completions, p+"\t"+schema.IconPlatformDescriptions[p])
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 
 	"github.com/joeblew999/goup-util/pkg/adb"
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/devproxy"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/joeblew999/goup-util/pkg/session"
 	"github.com/joeblew999/goup-util/pkg/simctl"
 	"github.com/joeblew999/goup-util/pkg/utils"
 	"github.com/spf13/cobra"
@@ -14,7 +20,7 @@ import (
 
 var runCmd = &cobra.Command{
 	Use:   "run [platform] [app-directory]",
-	Short: "Build and run a Gio application",
+	Short: i18n.T("Build and run a Gio application"),
 	Long: `Build and run a Gio application for the specified platform.
 
 This command builds the app (if needed) and launches it. The app is automatically
@@ -24,116 +30,179 @@ Platforms: macos, android, ios-simulator
 
 For Windows, use: goup-util utm run "Windows 11" <app-dir>
 
+--dev-proxy (or "devProxy" in app.json) points a hybrid app's embedded
+server at a frontend dev server, so edits reload inside the app window.
+The app must serve its site through devproxy.Handler or the handler
+'goup-util embed-web' generates; nothing needs rebuilding when the
+frontend changes.
+
+--record makes the app record its deep links, page navigations and
+JavaScript bridge calls to <app>/` + session.Dir + `; see 'goup-util replay'.
+
 Examples:
   goup-util run macos ./myapp
+  goup-util run macos examples/hybrid-dashboard --dev-proxy http://localhost:5173
+  goup-util run macos examples/hybrid-dashboard --record
   goup-util run android examples/hybrid-dashboard
   goup-util run ios-simulator examples/hybrid-dashboard`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		platform := args[0]
-		appDir := args[1]
-
-		// Validate platform - support platforms we can run locally
-		validPlatforms := []string{"macos", "android"}
-		if runtime.GOOS == "darwin" {
-			validPlatforms = append(validPlatforms, "ios-simulator")
-		}
-
-		if !utils.Contains(validPlatforms, platform) {
-			return fmt.Errorf("cannot run %s apps on %s. Valid platforms: %v", platform, runtime.GOOS, validPlatforms)
-		}
-
-		// Create and validate project
-		proj, err := project.NewGioProject(appDir)
-		if err != nil {
-			return fmt.Errorf("failed to create project: %w", err)
-		}
-
-		if err := proj.Validate(); err != nil {
-			return fmt.Errorf("invalid project: %w", err)
-		}
-
 		// Get build flags
 		force, _ := cmd.Flags().GetBool("force")
 		skipIcons, _ := cmd.Flags().GetBool("skip-icons")
 		schemes, _ := cmd.Flags().GetString("schemes")
+		runDevProxy, _ = cmd.Flags().GetString("dev-proxy")
+		runRecord, _ = cmd.Flags().GetBool("record")
 
-		// Build the app
-		opts := BuildOptions{
+		return runApp(args[0], args[1], BuildOptions{
 			Force:     force,
 			SkipIcons: skipIcons,
 			Schemes:   schemes,
-		}
+		})
+	},
+}
 
-		switch platform {
-		case "macos":
-			if err := buildMacOS(proj, platform, opts); err != nil {
-				return fmt.Errorf("build failed: %w", err)
-			}
-		case "android":
-			if err := buildAndroid(proj, platform, opts); err != nil {
-				return fmt.Errorf("build failed: %w", err)
-			}
-		case "ios-simulator":
-			if err := buildIOS(proj, platform, opts, true); err != nil {
-				return fmt.Errorf("build failed: %w", err)
-			}
+// runDevProxy is the dev server 'run --dev-proxy' passes to the app.
+var runDevProxy string
+
+// runRecord makes the app record its session ('run --record').
+var runRecord bool
+
+// runApp builds the app in appDir for platform and launches it.
+func runApp(platform, appDir string, opts BuildOptions) error {
+	// Validate platform - support platforms we can run locally
+	if err := checkRunPlatform(platform); err != nil {
+		return err
+	}
+
+	var env []string
+	devProxy := runDevProxy
+	if devProxy == "" {
+		devProxy = appconfig.LoadOrDefault(appDir).DevProxy
+	}
+	if devProxy != "" {
+		if _, err := devproxy.New(devProxy); err != nil {
+			return err
+		}
+		if platform == "android" {
+			fmt.Println("⚠️  --dev-proxy is not supported on Android; serving the embedded files")
+		} else {
+			fmt.Printf("🔄 Dev proxy: %s\n", devProxy)
+			env = append(env, devproxy.Env+"="+devProxy)
+		}
+	}
+	if runRecord {
+		if platform == "android" {
+			return errors.New(i18n.T("--record is not supported on Android"))
 		}
+		dir, err := filepath.Abs(filepath.Join(appDir, session.Dir))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("⏺  Recording the session to %s\n", dir)
+		env = append(env, session.Env+"="+dir)
+	}
 
-		// Launch the app
-		appPath := proj.GetOutputPath(platform)
-		fmt.Printf("Launching %s...\n", appPath)
-
-		switch platform {
-		case "macos":
-			return launchMacOSApp(appPath)
-		case "android":
-			return launchAndroidApp(appPath, proj.Name)
-		case "ios-simulator":
-			return launchIOSSimulator(appPath, proj.Name)
+	// Create and validate project
+	proj, err := project.NewGioProject(appDir)
+	if err != nil {
+		return fmt.Errorf(i18n.T("failed to create project: %w"), err)
+	}
+
+	if err := proj.Validate(); err != nil {
+		return fmt.Errorf(i18n.T("invalid project: %w"), err)
+	}
+
+	// Build the app
+	switch platform {
+	case "macos":
+		if err := buildMacOS(proj, platform, opts); err != nil {
+			return fmt.Errorf(i18n.T("build failed: %w"), err)
+		}
+	case "android":
+		if err := buildAndroid(proj, platform, opts); err != nil {
+			return fmt.Errorf(i18n.T("build failed: %w"), err)
+		}
+	case "ios-simulator":
+		if err := buildIOS(proj, platform, opts, true); err != nil {
+			return fmt.Errorf(i18n.T("build failed: %w"), err)
 		}
+	}
 
-		return nil
-	},
+	// Launch the app
+	appPath := proj.GetOutputPath(platform)
+	fmt.Printf("Launching %s...\n", appPath)
+
+	switch platform {
+	case "macos":
+		return launchMacOSApp(appPath, env)
+	case "android":
+		return launchAndroidApp(appPath, proj.Name)
+	case "ios-simulator":
+		return launchIOSSimulator(appPath, proj.Name, env)
+	}
+
+	return nil
+}
+
+// runPlatforms are the platforms apps can be launched on from this host.
+func runPlatforms() []string {
+	platforms := []string{"macos", "android"}
+	if runtime.GOOS == "darwin" {
+		platforms = append(platforms, "ios-simulator")
+	}
+	return platforms
+}
+
+func checkRunPlatform(platform string) error {
+	if !utils.Contains(runPlatforms(), platform) {
+		return fmt.Errorf(i18n.T("cannot run %s apps on %s. Valid platforms: %v"), platform, runtime.GOOS, runPlatforms())
+	}
+	return nil
 }
 
 func launchAndroidApp(apkPath, appName string) error {
 	client := adb.New()
 	if !client.Available() {
-		return fmt.Errorf("adb not found at %s\nInstall with: goup-util install platform-tools", client.ADBPath())
+		return fmt.Errorf(i18n.T("adb not found at %s\nInstall with: goup-util install platform-tools"), client.ADBPath())
 	}
 
 	// Ensure a device is connected
 	if !client.HasDevice() {
-		return fmt.Errorf("no Android device connected. Start an emulator with: goup-util android emulator start <avd-name>")
+		return errors.New(i18n.T("no Android device connected. Start an emulator with: goup-util android emulator start <avd-name>"))
 	}
 
 	// Install the APK
 	fmt.Printf("Installing %s...\n", apkPath)
 	if err := client.Install(apkPath); err != nil {
-		return fmt.Errorf("install failed: %w", err)
+		return fmt.Errorf(i18n.T("install failed: %w"), err)
 	}
 
 	// Launch the app — gogio uses "localhost.<appname>" as package by default
 	pkg := "localhost." + appName
 	fmt.Printf("Launching %s...\n", pkg)
 	if err := client.Launch(pkg); err != nil {
-		return fmt.Errorf("launch failed: %w", err)
+		return fmt.Errorf(i18n.T("launch failed: %w"), err)
 	}
 
 	fmt.Printf("✓ App running on device\n")
 	return nil
 }
 
-func launchMacOSApp(appPath string) error {
-	cmd := exec.Command("open", appPath)
+// launchMacOSApp opens the app with env ("KEY=value") in its environment.
+func launchMacOSApp(appPath string, env []string) error {
+	var args []string
+	for _, kv := range env {
+		args = append(args, "--env", kv)
+	}
+	cmd := exec.Command("open", append(args, appPath)...)
 	return cmd.Run()
 }
 
-func launchIOSSimulator(appPath, appName string) error {
+func launchIOSSimulator(appPath, appName string, env []string) error {
 	client := simctl.New()
 	if !client.Available() {
-		return fmt.Errorf("xcrun simctl not available\nInstall Xcode command line tools: xcode-select --install")
+		return errors.New(i18n.T("xcrun simctl not available\nInstall Xcode command line tools: xcode-select --install"))
 	}
 
 	// Ensure a simulator is booted
@@ -141,7 +210,7 @@ func launchIOSSimulator(appPath, appName string) error {
 		// Try to open Simulator.app which boots the default device
 		fmt.Println("No simulator booted, opening Simulator.app...")
 		if err := client.OpenSimulatorApp(); err != nil {
-			return fmt.Errorf("could not open Simulator app: %w\nBoot a simulator with: goup-util ios boot \"iPhone 16\"", err)
+			return fmt.Errorf(i18n.T("could not open Simulator app: %w\nBoot a simulator with: goup-util ios boot \"iPhone 16\""), err)
 		}
 		fmt.Println("Waiting for simulator to boot...")
 	}
@@ -149,14 +218,14 @@ func launchIOSSimulator(appPath, appName string) error {
 	// Install the app
 	fmt.Printf("Installing %s...\n", appPath)
 	if err := client.Install(appPath); err != nil {
-		return fmt.Errorf("install failed: %w", err)
+		return fmt.Errorf(i18n.T("install failed: %w"), err)
 	}
 
 	// Launch the app — gogio uses "localhost.<appname>" as bundle ID by default
 	bundleID := "localhost." + appName
 	fmt.Printf("Launching %s...\n", bundleID)
-	if err := client.Launch(bundleID); err != nil {
-		return fmt.Errorf("launch failed: %w", err)
+	if err := client.LaunchEnv(bundleID, env); err != nil {
+		return fmt.Errorf(i18n.T("launch failed: %w"), err)
 	}
 
 	fmt.Printf("✓ App running on simulator\n")
@@ -164,9 +233,11 @@ func launchIOSSimulator(appPath, appName string) error {
 }
 
 func init() {
-	runCmd.Flags().Bool("force", false, "Force rebuild even if up-to-date")
-	runCmd.Flags().Bool("skip-icons", false, "Skip icon generation")
-	runCmd.Flags().String("schemes", "", "Deep linking URI schemes")
+	runCmd.Flags().Bool("force", false, i18n.T("Force rebuild even if up-to-date"))
+	runCmd.Flags().Bool("skip-icons", false, i18n.T("Skip icon generation"))
+	runCmd.Flags().String("schemes", "", i18n.T("Deep linking URI schemes"))
+	runCmd.Flags().String("dev-proxy", "", i18n.T("Serve a hybrid app's site from this dev server (e.g. http://localhost:5173)"))
+	runCmd.Flags().Bool("record", false, i18n.T("Record deep links, navigations and bridge calls for 'goup-util replay'"))
 
 	// Group for help organization
 	runCmd.GroupID = "build"
@@ -1,3 +1,4 @@
+//go:build screenshot
 // +build screenshot
 
 package cmd
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/screenshot"
 	"github.com/spf13/cobra"
 )
@@ -51,8 +53,8 @@ func generateTitleVariations(dirName string) []string {
 
 var runAndCaptureCmd = &cobra.Command{
 	Use:   "run-and-capture <app-dir> <output-file>",
-	Short: "Run Gio app and capture screenshot",
-	Long: `Run a Gio application, wait for its window to appear, and capture a screenshot.
+	Short: i18n.T("Run Gio app and capture screenshot"),
+	Long: i18n.T(`Run a Gio application, wait for its window to appear, and capture a screenshot.
 
 This automates the workflow of:
 1. Launch the app
@@ -69,7 +71,7 @@ Examples:
   goup-util run-and-capture --preset macos-retina examples/hybrid-dashboard screenshot.png
 
   # Custom size
-  goup-util run-and-capture --width 1280 --height 800 examples/hybrid-dashboard screenshot.png`,
+  goup-util run-and-capture --width 1280 --height 800 examples/hybrid-dashboard screenshot.png`),
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		appDir := args[0]
@@ -85,25 +87,25 @@ Examples:
 		// Resolve absolute paths
 		absAppDir, err := filepath.Abs(appDir)
 		if err != nil {
-			return fmt.Errorf("failed to resolve app directory: %w", err)
+			return fmt.Errorf(i18n.T("failed to resolve app directory: %w"), err)
 		}
 
 		absOutput, err := filepath.Abs(output)
 		if err != nil {
-			return fmt.Errorf("failed to resolve output path: %w", err)
+			return fmt.Errorf(i18n.T("failed to resolve output path: %w"), err)
 		}
 
 		// Ensure output directory exists
 		outputDir := filepath.Dir(absOutput)
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+			return fmt.Errorf(i18n.T("failed to create output directory: %w"), err)
 		}
 
 		// Handle preset
 		if presetName != "" {
 			preset, ok := screenshot.GetPreset(presetName)
 			if !ok {
-				return fmt.Errorf("unknown preset: %s (use --list-presets to see available)", presetName)
+				return fmt.Errorf(i18n.T("unknown preset: %s (use --list-presets to see available)"), presetName)
 			}
 			fmt.Printf("Using preset: %s (%dx%d)\n", preset.Name, preset.Width, preset.Height)
 			width = preset.Width
@@ -120,7 +122,7 @@ Examples:
 		cmdBuild.Stderr = os.Stderr
 
 		if err := cmdBuild.Run(); err != nil {
-			return fmt.Errorf("failed to build app: %w", err)
+			return fmt.Errorf(i18n.T("failed to build app: %w"), err)
 		}
 
 		// Launch the binary directly
@@ -132,7 +134,7 @@ Examples:
 
 		if err := cmdRun.Start(); err != nil {
 			os.Remove(binaryPath)
-			return fmt.Errorf("failed to launch app: %w", err)
+			return fmt.Errorf(i18n.T("failed to launch app: %w"), err)
 		}
 
 		pid := cmdRun.Process.Pid
@@ -199,7 +201,7 @@ Examples:
 			// Capture full screen instead
 			fmt.Printf("Capturing full screen...\n")
 			if err := screenshot.CaptureDesktop(absOutput, quality); err != nil {
-				return fmt.Errorf("failed to capture screenshot: %w", err)
+				return fmt.Errorf(i18n.T("failed to capture screenshot: %w"), err)
 			}
 		} else {
 			fmt.Printf("✓ Window appeared\n")
@@ -223,7 +225,7 @@ Examples:
 				captureErr = screenshot.CaptureWindowByPID(pid, absOutput, quality)
 			}
 			if captureErr != nil {
-				return fmt.Errorf("failed to capture screenshot: %w", captureErr)
+				return fmt.Errorf(i18n.T("failed to capture screenshot: %w"), captureErr)
 			}
 		}
 
@@ -233,11 +235,11 @@ Examples:
 }
 
 func init() {
-	runAndCaptureCmd.Flags().String("preset", "", "App Store preset size (e.g., macos-retina, iphone-6.9)")
-	runAndCaptureCmd.Flags().Int("width", 0, "Window width (note: robotgo doesn't support resizing)")
-	runAndCaptureCmd.Flags().Int("height", 0, "Window height (note: robotgo doesn't support resizing)")
-	runAndCaptureCmd.Flags().IntP("quality", "q", 90, "JPEG quality (1-100)")
-	runAndCaptureCmd.Flags().IntP("wait", "w", 5000, "Max wait time for window in milliseconds")
+	runAndCaptureCmd.Flags().String("preset", "", i18n.T("App Store preset size (e.g., macos-retina, iphone-6.9)"))
+	runAndCaptureCmd.Flags().Int("width", 0, i18n.T("Window width (note: robotgo doesn't support resizing)"))
+	runAndCaptureCmd.Flags().Int("height", 0, i18n.T("Window height (note: robotgo doesn't support resizing)"))
+	runAndCaptureCmd.Flags().IntP("quality", "q", 90, i18n.T("JPEG quality (1-100)"))
+	runAndCaptureCmd.Flags().IntP("wait", "w", 5000, i18n.T("Max wait time for window in milliseconds"))
 
 	rootCmd.AddCommand(runAndCaptureCmd)
 }
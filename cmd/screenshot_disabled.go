@@ -4,26 +4,32 @@
 package cmd
 
 import (
-	"fmt"
+	"errors"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/spf13/cobra"
 )
 
 var screenshotCmd = &cobra.Command{
 	Use:   "screenshot",
-	Short: "Screenshot support not available in this build",
-	Long: `Screenshot support requires CGO and is not available in this build.
+	Short: i18n.T("Screenshot support not available in this build"),
+	Long: i18n.T(`Screenshot support requires CGO and is not available in this build.
 
 To build with screenshot support:
   CGO_ENABLED=1 go build -tags screenshot .
 
 Or use the task:
-  task build:with-screenshot`,
+  task build:with-screenshot`),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fmt.Errorf("screenshot support not available in this build\nRebuild with: CGO_ENABLED=1 go build -tags screenshot")
+		return errors.New(i18n.T("screenshot support not available in this build\nRebuild with: CGO_ENABLED=1 go build -tags screenshot"))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(screenshotCmd)
 }
+
+// listDisplays needs robotgo, which requires CGO.
+func listDisplays() ([]display, error) {
+	return nil, errors.New(i18n.T("display listing not available in this build\nRebuild with: CGO_ENABLED=1 go build -tags screenshot"))
+}
@@ -1,20 +1,24 @@
+//go:build screenshot
 // +build screenshot
 
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/joeblew999/goup-util/pkg/history"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/screenshot"
 	"github.com/spf13/cobra"
 )
 
 var screenshotCmd = &cobra.Command{
 	Use:   "screenshot [output-file]",
-	Short: "Capture screenshots using robotgo",
+	Short: i18n.T("Capture screenshots using robotgo"),
 	Long: `Capture screenshots using robotgo for full multi-display support.
 
 Features:
@@ -78,7 +82,7 @@ Using: ` + screenshot.Platform(),
 		if preset != "" {
 			p, ok := screenshot.GetPreset(preset)
 			if !ok {
-				return fmt.Errorf("unknown preset: %s (use --list-presets to see available presets)", preset)
+				return fmt.Errorf(i18n.T("unknown preset: %s (use --list-presets to see available presets)"), preset)
 			}
 			fmt.Printf("Using preset: %s (%dx%d)\n", p.Name, p.Width, p.Height)
 			width = p.Width
@@ -126,7 +130,7 @@ Using: ` + screenshot.Platform(),
 
 		// Validate
 		if !allDisplays && output == "" {
-			return fmt.Errorf("output file required (or use --all with --prefix)")
+			return errors.New(i18n.T("output file required (or use --all with --prefix)"))
 		}
 
 		// Window mode - capture specific window
@@ -141,8 +145,11 @@ Using: ` + screenshot.Platform(),
 		}
 
 		// Capture
-		if err := screenshot.Capture(cfg); err != nil {
-			return fmt.Errorf("screenshot failed: %w", err)
+		span := history.Start(history.Screenshot, output, "desktop")
+		err := screenshot.Capture(cfg)
+		span.End(err, output)
+		if err != nil {
+			return fmt.Errorf(i18n.T("screenshot failed: %w"), err)
 		}
 
 		// Success message
@@ -218,20 +225,34 @@ func showPresets() error {
 }
 
 func init() {
-	screenshotCmd.Flags().Int("x", 0, "X coordinate of region to capture")
-	screenshotCmd.Flags().Int("y", 0, "Y coordinate of region to capture")
-	screenshotCmd.Flags().IntP("width", "w", 0, "Width of region to capture")
-	screenshotCmd.Flags().IntP("height", "H", 0, "Height of region to capture")
-	screenshotCmd.Flags().Bool("all", false, "Capture all displays (requires robotgo)")
-	screenshotCmd.Flags().String("prefix", "display", "Prefix for multi-display captures")
-	screenshotCmd.Flags().IntP("delay", "d", 0, "Delay before capture in milliseconds")
-	screenshotCmd.Flags().IntP("quality", "q", 90, "JPEG quality (1-100)")
-	screenshotCmd.Flags().Bool("info", false, "Show display information")
-	screenshotCmd.Flags().Bool("force", false, "Overwrite existing screenshot")
-	screenshotCmd.Flags().Bool("window", false, "Capture active window only")
-	screenshotCmd.Flags().Int("pid", 0, "Capture window by process ID")
-	screenshotCmd.Flags().String("preset", "", "Use App Store preset (e.g., macos-retina, iphone-6.9)")
-	screenshotCmd.Flags().Bool("list-presets", false, "List available App Store presets")
+	screenshotCmd.Flags().Int("x", 0, i18n.T("X coordinate of region to capture"))
+	screenshotCmd.Flags().Int("y", 0, i18n.T("Y coordinate of region to capture"))
+	screenshotCmd.Flags().IntP("width", "w", 0, i18n.T("Width of region to capture"))
+	screenshotCmd.Flags().IntP("height", "H", 0, i18n.T("Height of region to capture"))
+	screenshotCmd.Flags().Bool("all", false, i18n.T("Capture all displays (requires robotgo)"))
+	screenshotCmd.Flags().String("prefix", "display", i18n.T("Prefix for multi-display captures"))
+	screenshotCmd.Flags().IntP("delay", "d", 0, i18n.T("Delay before capture in milliseconds"))
+	screenshotCmd.Flags().IntP("quality", "q", 90, i18n.T("JPEG quality (1-100)"))
+	screenshotCmd.Flags().Bool("info", false, i18n.T("Show display information"))
+	screenshotCmd.Flags().Bool("force", false, i18n.T("Overwrite existing screenshot"))
+	screenshotCmd.Flags().Bool("window", false, i18n.T("Capture active window only"))
+	screenshotCmd.Flags().Int("pid", 0, i18n.T("Capture window by process ID"))
+	screenshotCmd.Flags().String("preset", "", i18n.T("Use App Store preset (e.g., macos-retina, iphone-6.9)"))
+	screenshotCmd.Flags().Bool("list-presets", false, i18n.T("List available App Store presets"))
 
 	rootCmd.AddCommand(screenshotCmd)
 }
+
+// listDisplays returns the displays in robotgo's order, which the shells'
+// display.index follows.
+func listDisplays() ([]display, error) {
+	info, err := screenshot.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+	displays := make([]display, len(info))
+	for i, d := range info {
+		displays[i] = display{Index: d.ID, X: d.X, Y: d.Y, Width: d.Width, Height: d.Height, Scale: d.Scale, Primary: d.Primary}
+	}
+	return displays, nil
+}
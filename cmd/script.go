@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/script"
+	"github.com/spf13/cobra"
+)
+
+var scriptCmd = &cobra.Command{
+	Use:   "script",
+	Short: i18n.T("Run YAML automation flows"),
+}
+
+var scriptRunCmd = &cobra.Command{
+	Use:   "run <flow.yaml>",
+	Short: i18n.T("Run the steps of a flow file"),
+	Long: i18n.T(`Run a YAML flow whose steps are goup-util operations, so a release flow
+can live in the repository without shell scripts or Taskfiles:
+
+  vars:
+    app: examples/hybrid-dashboard
+    version: 1.0.0
+  steps:
+    - icons: {platform: macos, app: "${app}"}
+    - name: Build for macOS
+      build: {platform: macos, app: "${app}", force: true}
+      if: ${os} == darwin
+    - utm-exec: {vm: Windows 11, command: "build windows ${app}"}
+      continueOnError: true
+    - publish: {platform: ios, app: "${app}", dry-run: true}
+    - goup: [bundle, macos, "${app}", --version, "${version}"]
+
+Steps: build, bundle, icons, run, utm-exec, ios-boot, screenshot,
+publish (metadata) and goup (any goup-util command). Keys other than an
+operation's arguments become flags: {force: true} is --force.
+
+${name} is a flow variable, an environment variable, or the built-in os
+and arch. "if" skips a step unless it is "a == b", "a != b" or a value
+other than empty, false, 0 or no. A failing step stops the flow unless
+it sets continueOnError.`),
+	Example: `  goup-util script run release.yaml
+  goup-util script run release.yaml --var version=1.2.0 --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		varFlags, _ := cmd.Flags().GetStringArray("var")
+
+		flow, err := script.Load(args[0])
+		if err != nil {
+			return err
+		}
+		vars := map[string]string{}
+		for _, kv := range varFlags {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf(i18n.T("invalid --var %q, expected name=value"), kv)
+			}
+			vars[k] = v
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to find goup-util executable: %w"), err)
+		}
+		return flow.Run(script.Options{
+			Vars:   vars,
+			DryRun: dryRun,
+			Exec: func(args []string) error {
+				c := exec.Command(exe, args...)
+				c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+				return c.Run()
+			},
+		})
+	},
+}
+
+func init() {
+	scriptRunCmd.Flags().StringArray("var", nil, i18n.T("Set a flow variable (name=value, repeatable)"))
+	scriptRunCmd.Flags().Bool("dry-run", false, i18n.T("Print the commands without running them"))
+
+	scriptCmd.AddCommand(scriptRunCmd)
+	scriptCmd.GroupID = "tools"
+	rootCmd.AddCommand(scriptCmd)
+}
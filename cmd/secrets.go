@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/secrets"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: i18n.T("Manage signing certificates, keystores and store API keys"),
+	Long: i18n.T(`Manage the signing material and API keys used by build, bundle and publish.
+
+Each secret is looked up, in order, in:
+
+  NAME        environment variable (base64 for keystores, certificates and keys)
+  NAME_FILE   path to a file, e.g. a CI secret mount
+  vault       age-encrypted file in the goup-util config directory
+
+Values are only decrypted in memory. Keystores and certificates are handed
+to gogio, codesign and signtool through a pipe (/dev/fd/N), never written
+to disk in plaintext. On Windows file secrets must be given as NAME_FILE.
+
+The vault is unlocked by its key file (created by 'secrets init'),
+$GOUP_VAULT_KEY (the key itself, for CI) or $GOUP_VAULT_PASSPHRASE.
+
+Examples:
+  goup-util secrets init
+  goup-util secrets set ANDROID_KEYSTORE --file release.jks
+  goup-util secrets set ANDROID_KEYSTORE_PASSWORD        # prompts
+  goup-util secrets list
+  goup-util secrets doctor
+  goup-util secrets exec -- ./scripts/upload.sh`),
+}
+
+var (
+	secretsVault      string
+	secretsFile       string
+	secretsPassphrase bool
+)
+
+var secretsInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: i18n.T("Create an encrypted vault"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vault := secrets.OpenVault(secretsVault)
+		passphrase := ""
+		if secretsPassphrase {
+			var err error
+			if passphrase, err = readSecret("Vault passphrase: "); err != nil {
+				return err
+			}
+			if passphrase == "" {
+				return errors.New(i18n.T("passphrase cannot be empty"))
+			}
+		}
+		recipient, err := vault.Init(passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Created vault %s\n", vault.Path)
+		if recipient != "" {
+			fmt.Printf("  Key: %s (public key %s)\n", vault.KeyPath, recipient)
+			fmt.Printf("  For CI, store the contents of the key file in $%s\n", secrets.VaultKeyEnv)
+		} else {
+			fmt.Printf("  Unlock with $%s\n", secrets.VaultPassphraseEnv)
+		}
+		return nil
+	},
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <NAME>",
+	Short: i18n.T("Store a secret in the vault (from --file, stdin or a prompt)"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		def, known := secrets.Lookup(name)
+		if !known {
+			fmt.Printf("⚠️  %s is not a secret goup-util uses; storing it for 'secrets exec'\n", name)
+		}
+
+		var value []byte
+		var err error
+		switch {
+		case secretsFile != "":
+			value, err = os.ReadFile(secretsFile)
+		case term.IsTerminal(int(os.Stdin.Fd())):
+			if known && def.Kind == secrets.File {
+				return fmt.Errorf(i18n.T("%s is a file; use --file"), name)
+			}
+			var s string
+			s, err = readSecret(name + ": ")
+			value = []byte(s)
+		default:
+			value, err = io.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to read %s: %w"), name, err)
+		}
+		if len(value) == 0 {
+			return fmt.Errorf(i18n.T("%s is empty"), name)
+		}
+
+		vault := secrets.OpenVault(secretsVault)
+		if !vault.Exists() {
+			return fmt.Errorf(i18n.T("no vault at %s; run 'goup-util secrets init' first"), vault.Path)
+		}
+		if err := vault.Set(name, value); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Stored %s (%s)\n", name, secrets.Mask(value))
+		if secretsFile != "" {
+			fmt.Printf("  You can now delete %s\n", secretsFile)
+		}
+		return nil
+	},
+}
+
+var secretsRemoveCmd = &cobra.Command{
+	Use:   "remove <NAME>",
+	Short: i18n.T("Remove a secret from the vault"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := secrets.OpenVault(secretsVault).Remove(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Removed %s\n", args[0])
+		return nil
+	},
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("Show which secrets are set and where they come from"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store := secrets.Open(secretsVault)
+		for _, def := range secrets.Known {
+			s, err := store.Get(def.Name)
+			switch {
+			case err != nil:
+				fmt.Printf("  ✗ %-30s %v\n", def.Name, err)
+			case s == nil:
+				fmt.Printf("  - %-30s not set (%s)\n", def.Name, def.UsedBy)
+			default:
+				fmt.Printf("  ✓ %-30s %-6s %s\n", def.Name, s.Source, secrets.Mask(s.Value))
+			}
+		}
+		return nil
+	},
+}
+
+var secretsDoctorCmd = &cobra.Command{
+	Use:   "doctor [app-directory]",
+	Short: i18n.T("Validate secrets before a release"),
+	Long: i18n.T(`Check that the vault decrypts, that each secret that is set has the right
+format (keystore, .p8 key, .pfx, service account JSON, key and issuer IDs),
+that paired secrets are set together, that the macOS signing identity is in
+the keychain, and that no keystores or keys are committed to git.
+
+Exits non-zero if any check fails, so it can gate a CI release job.`),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		checks := secrets.Doctor(secrets.Open(secretsVault), dir)
+
+		fmt.Println("Secrets:")
+		for _, c := range checks {
+			mark := map[secrets.Status]string{secrets.OK: "✓", secrets.Warning: "⚠", secrets.Error: "✗", secrets.Missing: "-"}[c.Status]
+			fmt.Printf("  %s %-30s %s\n", mark, c.Name, c.Detail)
+		}
+		if !secrets.Healthy(checks) {
+			return errors.New(i18n.T("secrets check failed"))
+		}
+		fmt.Println("\n✅ Secrets look good")
+		return nil
+	},
+}
+
+var secretsExecCmd = &cobra.Command{
+	Use:   "exec -- <command> [args...]",
+	Short: i18n.T("Run a command with vault secrets in its environment"),
+	Long: i18n.T(`Run a command with every vault secret exported as an environment variable,
+for scripts and tools that read secrets from the environment. File secrets
+are exported base64-encoded, the same form goup-util accepts. Variables
+already set in the environment take precedence.`),
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vault := secrets.OpenVault(secretsVault)
+		env := os.Environ()
+		if vault.Exists() {
+			values, err := vault.Load()
+			if err != nil {
+				return err
+			}
+			for name, value := range values {
+				if _, set := os.LookupEnv(name); set {
+					continue
+				}
+				v := string(value)
+				if def, ok := secrets.Lookup(name); ok && def.Kind == secrets.File {
+					v = base64.StdEncoding.EncodeToString(value)
+				}
+				env = append(env, name+"="+v)
+			}
+		}
+
+		c := exec.Command(args[0], args[1:]...)
+		c.Env = env
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				os.Exit(exitErr.ExitCode())
+			}
+			return err
+		}
+		return nil
+	},
+}
+
+// readSecret prompts on the terminal without echoing.
+func readSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func init() {
+	secretsCmd.PersistentFlags().StringVar(&secretsVault, "vault", "", i18n.T("Vault file (default <config>/goup-util/secrets.age)"))
+	secretsSetCmd.Flags().StringVar(&secretsFile, "file", "", i18n.T("Read the value from a file (keystores, certificates, keys)"))
+	secretsInitCmd.Flags().BoolVar(&secretsPassphrase, "passphrase", false, i18n.T("Encrypt with a passphrase instead of a key file"))
+
+	secretsCmd.AddCommand(secretsInitCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsRemoveCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsDoctorCmd)
+	secretsCmd.AddCommand(secretsExecCmd)
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.GroupID = "tools"
+}
@@ -2,27 +2,34 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 
+	"github.com/joeblew999/goup-util/pkg/history"
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/self"
 	"github.com/spf13/cobra"
 )
 
 var selfCmd = &cobra.Command{
 	Use:   "self",
-	Short: "Manage goup-util itself",
-	Long: `Commands for managing goup-util itself.
+	Short: i18n.T("Manage goup-util itself"),
+	Long: i18n.T(`Commands for managing goup-util itself.
 
 For Users:
   version        - Show version and check for updates
+  status         - Show where goup-util is installed and if an update is out
+  setup          - Install this binary to the system PATH
+  uninstall      - Remove every installed goup-util binary
   upgrade        - Download and install latest release
   doctor         - Validate dependencies
 
 For Developers:
   build          - Build goup-util binaries for all platforms
   release        - Create git tag and trigger GitHub Actions
-  release-check  - Check if GitHub release is ready (async monitoring)`,
+  release-check  - Check if GitHub release is ready (async monitoring)`),
 }
 
 var (
@@ -32,8 +39,8 @@ var (
 
 var selfBuildCmd = &cobra.Command{
 	Use:   "build",
-	Short: "Build goup-util binaries for all platforms",
-	Long: `Cross-compile goup-util for all supported architectures and generate bootstrap scripts.
+	Short: i18n.T("Build goup-util binaries for all platforms"),
+	Long: i18n.T(`Cross-compile goup-util for all supported architectures and generate bootstrap scripts.
 
 Output: All artifacts are placed in .dist/ directory
 - Binaries: .dist/goup-util-<platform>
@@ -43,7 +50,7 @@ Flags:
   --local      Generate scripts that use local binaries (for testing)
   --obfuscate  Use garble to obfuscate code (auto-installs if needed)
 
-This is a LOCAL build command - it does NOT create releases or push to GitHub.`,
+This is a LOCAL build command - it does NOT create releases or push to GitHub.`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		opts := self.BuildOptions{
 			UseLocal:  buildLocal,
@@ -55,17 +62,72 @@ This is a LOCAL build command - it does NOT create releases or push to GitHub.`,
 
 var selfVersionCmd = &cobra.Command{
 	Use:   "version",
-	Short: "Show goup-util version",
-	Long:  "Display the currently installed version of goup-util.",
+	Short: i18n.T("Show goup-util version"),
+	Long:  i18n.T("Display the currently installed version of goup-util."),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return self.ShowVersion()
 	},
 }
 
+var statusJSON bool // Flag for machine-readable status
+
+var selfStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: i18n.T("Show installed version, location and available updates"),
+	Long: i18n.T(`Show the goup-util found in PATH, its version, and whether a newer
+release is available.
+
+Also warns when the installed copy is shadowed by another
+goup-util earlier in PATH (for example one from 'go install'), which would
+make 'self upgrade' look like it had no effect.
+
+Use --json for the same JSON envelope as the other self commands.`),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return self.ShowStatus(statusJSON)
+	},
+}
+
+var (
+	setupNoPath bool // Flag for leaving the Windows user PATH alone
+	setupUser   bool // Flag for a per-user Unix install
+)
+
+var selfSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: i18n.T("Install this goup-util binary to the system PATH"),
+	Long: i18n.T(`Copy the running goup-util binary to its install location:
+
+  macOS, Linux  /usr/local/bin/goup-util (asks for sudo if needed)
+                ~/.local/bin/goup-util with --user (no sudo)
+  Windows       %USERPROFILE%\goup-util.exe
+
+--user suits CI containers and machines without admin rights. The directory
+is created if needed; if it isn't on PATH, the output's path_hint says how
+to add it for your shell. 'self upgrade' and 'self uninstall' then use the
+per-user copy.
+
+On Windows the install directory is added to your user PATH, and running
+programs are told the environment changed; open a new terminal to use
+goup-util. Use --no-path to manage PATH yourself.`),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return self.InstallSelf(self.SetupOptions{NoPath: setupNoPath, User: setupUser})
+	},
+}
+
+var selfUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: i18n.T("Remove installed goup-util binaries"),
+	Long: i18n.T(`Remove every goup-util binary found in PATH, plus a per-user install in
+~/.local/bin. Asks for sudo only for copies the current user can't remove.`),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return self.UninstallSelf()
+	},
+}
+
 var selfDoctorCmd = &cobra.Command{
 	Use:   "doctor",
-	Short: "Validate installation and dependencies",
-	Long:  "Check that goup-util and all dependencies (Homebrew, git, go, task) are properly installed.",
+	Short: i18n.T("Validate installation and dependencies"),
+	Long:  i18n.T("Check that goup-util and all dependencies (Homebrew, git, go, task) are properly installed."),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return self.Doctor()
 	},
@@ -73,8 +135,8 @@ var selfDoctorCmd = &cobra.Command{
 
 var selfReleaseCheckCmd = &cobra.Command{
 	Use:   "release-check [tag]",
-	Short: "Check if a GitHub release is ready",
-	Long: `Check if a GitHub release exists and has assets.
+	Short: i18n.T("Check if a GitHub release is ready"),
+	Long: i18n.T(`Check if a GitHub release exists and has assets.
 
 This is useful after running 'self release' to monitor the async GitHub Actions workflow.
 
@@ -87,7 +149,7 @@ Returns JSON with:
 - published: whether it's published
 - assets: list of available files
 - release_url: direct link to release
-- workflow_url: link to monitor GitHub Actions`,
+- workflow_url: link to monitor GitHub Actions`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tag := ""
 		if len(args) > 0 {
@@ -96,7 +158,7 @@ Returns JSON with:
 			// Get latest tag
 			out, err := exec.Command("git", "describe", "--tags", "--abbrev=0").Output()
 			if err != nil {
-				return fmt.Errorf("no tag specified and couldn't get latest tag: %w", err)
+				return fmt.Errorf(i18n.T("no tag specified and couldn't get latest tag: %w"), err)
 			}
 			tag = strings.TrimSpace(string(out))
 		}
@@ -104,24 +166,50 @@ Returns JSON with:
 	},
 }
 
+var (
+	upgradeTo    string // Flag for pinning a release
+	upgradeForce bool   // Flag for reinstalling or downgrading
+	upgradeFrom  string // Flag for a release source other than GitHub
+)
+
 var selfUpgradeCmd = &cobra.Command{
 	Use:   "upgrade",
-	Short: "Download and install latest release from GitHub",
+	Short: i18n.T("Download and install latest release from GitHub"),
 	Long: `Download and install the latest goup-util release from GitHub.
 
 This downloads the pre-built binary for your platform from the GitHub Releases page
 and installs it to your system PATH (~/.local/bin/ or ~/bin/).
 
-Use this command to update goup-util after a new release has been published.`,
+Use this command to update goup-util after a new release has been published.
+
+The installed version is compared with the release first: the same version
+is not reinstalled, and an older "latest" (e.g. published from a hotfix
+branch) is refused rather than silently installed.
+
+Companies that mirror goup-util privately point --from (or $` + self.UpdateRepoEnv + `)
+at the mirror: another GitHub repository, or a release index in a bucket
+or on a web server written by 'goup-util update-server index'.
+
+Flags:
+  --to v1.2.3  Install a specific release, older or newer
+  --force      Reinstall the same version, or accept a downgrade
+  --from SRC   owner/name, https://..., s3://..., r2://... or gs://...`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return self.DownloadAndInstallLatest(self.FullRepoName)
+		repo := upgradeFrom
+		if repo == "" {
+			repo = os.Getenv(self.UpdateRepoEnv)
+		}
+		if repo == "" {
+			repo = self.FullRepoName
+		}
+		return self.Upgrade(repo, self.UpgradeOptions{To: upgradeTo, Force: upgradeForce})
 	},
 }
 
 var selfReleaseCmd = &cobra.Command{
 	Use:   "release [patch|minor|major|v1.2.3]",
-	Short: "Create git tag and trigger GitHub Actions release",
-	Long: `Create a git tag and push it to trigger GitHub Actions release workflow.
+	Short: i18n.T("Create git tag and trigger GitHub Actions release"),
+	Long: i18n.T(`Create a git tag and push it to trigger GitHub Actions release workflow.
 
 This command does:
 1. Validates working directory is clean
@@ -140,13 +228,16 @@ Version options (defaults to 'minor'):
   major      - Increment major version (1.0.0 → 2.0.0)
   v1.2.3     - Use specific version
 
-This is a TRIGGER ONLY - no local builds or tests. GitHub Actions does all the work.`,
+This is a TRIGGER ONLY - no local builds or tests. GitHub Actions does all the work.`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		version := "minor" // Default to minor release
 		if len(args) == 1 {
 			version = args[0]
 		}
-		return self.Release(version)
+		span := history.Start(history.Release, "goup-util", version)
+		err := self.Release(version)
+		span.End(err, "")
+		return err
 	},
 }
 
@@ -158,6 +249,9 @@ func init() {
 
 	// User commands
 	selfCmd.AddCommand(selfVersionCmd)
+	selfCmd.AddCommand(selfStatusCmd)
+	selfCmd.AddCommand(selfSetupCmd)
+	selfCmd.AddCommand(selfUninstallCmd)
 	selfCmd.AddCommand(selfUpgradeCmd)
 	selfCmd.AddCommand(selfDoctorCmd)
 
@@ -166,7 +260,18 @@ func init() {
 	selfCmd.AddCommand(selfReleaseCmd)
 	selfCmd.AddCommand(selfReleaseCheckCmd)
 
+	// Windows keeps the replaced exe until the upgraded one next runs
+	if runtime.GOOS == "windows" {
+		cobra.OnInitialize(self.CleanupOldBinaries)
+	}
+
 	// Add flags
-	selfBuildCmd.Flags().BoolVar(&buildLocal, "local", false, "Generate bootstrap scripts for local testing (uses local binaries instead of GitHub releases)")
-	selfBuildCmd.Flags().BoolVar(&buildObfuscate, "obfuscate", false, "Use garble to obfuscate binaries (auto-installs garble if needed)")
+	selfStatusCmd.Flags().BoolVar(&statusJSON, "json", false, i18n.T("Output JSON"))
+	selfSetupCmd.Flags().BoolVar(&setupNoPath, "no-path", false, i18n.T("Don't add the install directory to the user PATH (Windows)"))
+	selfSetupCmd.Flags().BoolVar(&setupUser, "user", false, i18n.T("Install to ~/.local/bin without sudo (macOS, Linux)"))
+	selfUpgradeCmd.Flags().StringVar(&upgradeTo, "to", "", i18n.T("Install this release (e.g. v1.2.3) instead of the latest"))
+	selfUpgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, i18n.T("Reinstall the same version or allow a downgrade"))
+	selfUpgradeCmd.Flags().StringVar(&upgradeFrom, "from", "", "Release source: owner/name or an https, s3, r2 or gs index (default $"+self.UpdateRepoEnv+" or "+self.FullRepoName+")")
+	selfBuildCmd.Flags().BoolVar(&buildLocal, "local", false, i18n.T("Generate bootstrap scripts for local testing (uses local binaries instead of GitHub releases)"))
+	selfBuildCmd.Flags().BoolVar(&buildObfuscate, "obfuscate", false, i18n.T("Use garble to obfuscate binaries (auto-installs garble if needed)"))
 }
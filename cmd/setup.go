@@ -2,11 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"runtime"
 	"strings"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/installer"
 	"github.com/joeblew999/goup-util/pkg/utils"
 	"github.com/spf13/cobra"
@@ -16,8 +15,8 @@ var noEmulator bool
 
 var setupCmd = &cobra.Command{
 	Use:   "setup [setup-name]",
-	Short: "Install a predefined set of SDKs",
-	Long:  `Install a predefined set of SDKs defined in the sdk-android-list.json and sdk-ios-list.json files.`,
+	Short: i18n.T("Install a predefined set of SDKs"),
+	Long:  i18n.T(`Install a predefined set of SDKs defined in the sdk-android-list.json and sdk-ios-list.json files.`),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		setupName := args[0]
@@ -35,7 +34,7 @@ var setupCmd = &cobra.Command{
 }
 
 func init() {
-	setupCmd.Flags().BoolVar(&noEmulator, "no-emulator", false, "Skip installing the emulator and system-images (Android only)")
+	setupCmd.Flags().BoolVar(&noEmulator, "no-emulator", false, i18n.T("Skip installing the emulator and system-images (Android only)"))
 	rootCmd.AddCommand(setupCmd)
 }
 
@@ -58,30 +57,16 @@ func installSetup(setupName string, cache *installer.Cache, skipEmulator bool) e
 				continue
 			}
 
-			// First, check if the tools are already installed to avoid unnecessary pop-ups
-			checkCmd := exec.Command("xcode-select", "-p")
-			if err := checkCmd.Run(); err == nil {
-				fmt.Println("Xcode Command Line Tools are already installed.")
-				continue
-			}
-
-			// If the check failed, the tools are not installed, so attempt to install them
-			fmt.Println("Xcode Command Line Tools not found. Attempting to install...")
-			installCmd := exec.Command("xcode-select", "--install")
-			installCmd.Stdout = os.Stdout
-			installCmd.Stderr = os.Stderr
-			if err := installCmd.Run(); err != nil {
-				// Installation can fail if user cancels the dialog or if already installed
-				fmt.Println("Installation might have been cancelled or tools are already installed.")
-			} else {
-				fmt.Println("--- Finished installing Xcode Command Line Tools ---")
+			if err := installXcodeCLT(); err != nil {
+				// The user may have cancelled the dialog
+				fmt.Printf("⚠️  %v\n", err)
 			}
 			continue
 		}
 
 		fmt.Printf("--- Installing %s ---\n", sdkName)
 		if err := installSdk(sdkName, cache); err != nil {
-			return fmt.Errorf("failed to install %s: %w", sdkName, err)
+			return fmt.Errorf(i18n.T("failed to install %s: %w"), sdkName, err)
 		}
 		fmt.Printf("--- Finished installing %s ---\n\n", sdkName)
 	}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/fleet"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/updateserver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateServerDir     string
+	updateServerAddr    string
+	updateServerBaseURL string
+	updateServerFleet   bool
+)
+
+var updateServerCmd = &cobra.Command{
+	Use:   "update-server",
+	Short: i18n.T("Serve app updates from a directory instead of GitHub"),
+}
+
+var updateServerServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: i18n.T("Serve releases, manifest and appcast over HTTP"),
+	Long: `Serve app updates from a directory, for fleets of shell apps that should
+not depend on the GitHub API.
+
+Each subdirectory named after a version (v1.2.0 or 1.2.0) is a release;
+the highest version is the latest. Put the release archives in it, named
+like GitHub release assets (<asset>-macos.zip, <asset>-windows.zip), and
+optionally a NOTES.md.
+
+  releases/
+    v1.3.0/
+      NOTES.md
+      webviewer-shell-macos.zip
+      webviewer-shell-windows.zip
+
+Point apps at the server with "update": {"url": "https://updates.example.com",
+"asset": "webviewer-shell"} in app.json.
+
+Endpoints:
+  GET /manifest.json              latest release with sizes and SHA-256
+  GET /releases                   every release
+  GET /releases/latest            GitHub API format, read by the updater
+  GET /appcast.xml                Sparkle / WinSparkle appcast
+  GET /download/{version}/{file}  files, with range requests for resuming
+  POST /events                    app reports of crash-loop rollbacks, for
+                                  versions the server holds
+  GET /metrics                    download, rollout and event counts (Prometheus)
+
+With --fleet the server also keeps a registry of the installs that check
+in, in .fleet.json, for 'goup-util fleet'. Devices authenticate with
+$` + FleetTokenEnv + `, operators with $` + FleetAdminTokenEnv + `. Without the
+admin token the operator endpoints are closed, and the server refuses to
+start --fleet on an address other than loopback:
+  POST /fleet/checkin                 a device reports in
+  PUT /fleet/devices/{id}/screenshot  a device uploads a screenshot
+  GET /fleet/devices                  every device
+  GET /fleet/devices/{id}             one device
+  POST /fleet/devices/{id}/commands   queue a signed command
+  GET /fleet/devices/{id}/screenshot  the latest screenshot
+
+Counts are kept in .downloads.json and .events.json in the release directory.
+Stage a release with 'goup-util update-server rollout'.
+
+Examples:
+  goup-util update-server serve --dir releases/
+  goup-util update-server serve --dir /srv/releases --addr :443 --base-url https://updates.example.com
+  goup-util update-server serve --dir releases/ --fleet`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		adminToken := os.Getenv(FleetAdminTokenEnv)
+		if updateServerFleet && adminToken == "" && !isLoopbackAddr(updateServerAddr) {
+			return fmt.Errorf(i18n.T("refusing to serve the fleet on %s without an admin token; set $%s or use a loopback address"), updateServerAddr, FleetAdminTokenEnv)
+		}
+		srv, err := updateserver.NewServer(updateserver.Options{
+			Dir:     updateServerDir,
+			BaseURL: updateServerBaseURL,
+			Fleet:   updateServerFleet,
+			FleetTokens: fleet.Tokens{
+				Enroll: os.Getenv(FleetTokenEnv),
+				Admin:  adminToken,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		releases, err := srv.Releases()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		httpSrv := &http.Server{Addr: updateServerAddr, Handler: srv.Handler()}
+		errc := make(chan error, 1)
+		go func() { errc <- httpSrv.ListenAndServe() }()
+
+		fmt.Printf("📦 Update server on %s serving %s\n", updateServerAddr, updateServerDir)
+		if len(releases) == 0 {
+			fmt.Printf("⚠️  No version directories in %s yet\n", updateServerDir)
+		} else {
+			fmt.Printf("   Latest: %s (%d files), %d release(s)\n", releases[0].Version, len(releases[0].Assets), len(releases))
+		}
+		if updateServerFleet && adminToken == "" {
+			fmt.Printf("⚠️  $%s is not set; the fleet's operator endpoints are closed\n", FleetAdminTokenEnv)
+		}
+
+		select {
+		case err := <-errc:
+			return fmt.Errorf(i18n.T("update server failed: %w"), err)
+		case <-ctx.Done():
+		}
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpSrv.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+var updateServerRolloutCmd = &cobra.Command{
+	Use:   "rollout <version> <percent>",
+	Short: i18n.T("Offer a release to a percentage of installs, or halt it with 0"),
+	Long: i18n.T(`Stage a release: each app hashes its machine ID with the version and
+installs the release only if it falls within the percentage. Raising the
+percentage adds machines without dropping any; 0 halts the rollout, and
+100 releases to everyone.
+
+The setting is written to rollout.json in the version directory and takes
+effect on the next update check; the server does not need a restart.
+
+Examples:
+  goup-util update-server rollout v1.3.0 10 --dir releases/
+  goup-util update-server rollout v1.3.0 0     # halt
+  goup-util update-server rollout v1.3.0 100   # everyone`),
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		percentage, err := strconv.Atoi(strings.TrimSuffix(args[1], "%"))
+		if err != nil {
+			return fmt.Errorf(i18n.T("invalid percentage %q"), args[1])
+		}
+		srv, err := updateserver.NewServer(updateserver.Options{Dir: updateServerDir})
+		if err != nil {
+			return err
+		}
+		if err := srv.SetRollout(args[0], percentage); err != nil {
+			return err
+		}
+		switch percentage {
+		case 0:
+			fmt.Printf("✓ Rollout of %s halted\n", args[0])
+		case 100:
+			fmt.Printf("✓ %s released to all installs\n", args[0])
+		default:
+			fmt.Printf("✓ %s offered to %d%% of installs\n", args[0], percentage)
+		}
+		return nil
+	},
+}
+
+var updateServerIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: i18n.T("Write releases.json to host the releases without a server"),
+	Long: i18n.T(`Write releases.json into the release directory, listing every release with
+its files, sizes, SHA-256 and rollout. Copy the directory as is to an S3,
+R2 or GCS bucket or a web server and point update.repo in app.json (or
+'self upgrade --from') at it; apps read the index directly and check each
+download against it.
+
+  "update": {"repo": "r2://updates/myapp", "asset": "webviewer-shell"}
+  "update": {"repo": "https://dl.example.com/myapp", "asset": "webviewer-shell"}
+
+Run it again after adding a release or changing a rollout, and upload
+releases.json last so clients never see files that are not there yet.
+
+Examples:
+  goup-util update-server index --dir releases/
+  goup-util upload releases/v1.3.0/* --to r2://updates/myapp/v1.3.0
+  goup-util upload releases/releases.json --to r2://updates/myapp`),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		srv, err := updateserver.NewServer(updateserver.Options{Dir: updateServerDir})
+		if err != nil {
+			return err
+		}
+		releases, err := srv.WriteIndex()
+		if err != nil {
+			return err
+		}
+		if len(releases) == 0 {
+			fmt.Printf("⚠️  No version directories in %s yet\n", updateServerDir)
+		}
+		fmt.Printf("✓ Wrote %s with %d release(s)\n", filepath.Join(updateServerDir, updateserver.IndexFile), len(releases))
+		return nil
+	},
+}
+
+func init() {
+	updateServerCmd.PersistentFlags().StringVar(&updateServerDir, "dir", "releases", i18n.T("Directory with one subdirectory per version"))
+	updateServerServeCmd.Flags().StringVar(&updateServerAddr, "addr", ":8080", i18n.T("Listen address"))
+	updateServerServeCmd.Flags().StringVar(&updateServerBaseURL, "base-url", "", i18n.T("Public URL used in download links (default: the URL clients used)"))
+	updateServerServeCmd.Flags().BoolVar(&updateServerFleet, "fleet", false, i18n.T("Keep a registry of installs that check in (see 'goup-util fleet')"))
+	updateServerCmd.AddCommand(updateServerServeCmd)
+	updateServerCmd.AddCommand(updateServerRolloutCmd)
+	updateServerCmd.AddCommand(updateServerIndexCmd)
+
+	updateServerCmd.GroupID = "tools"
+	rootCmd.AddCommand(updateServerCmd)
+}
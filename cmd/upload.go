@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/secrets"
+	"github.com/joeblew999/goup-util/pkg/upload"
+	"github.com/spf13/cobra"
+)
+
+var uploadCmd = &cobra.Command{
+	Use:   "upload <artifact>... --to <target>",
+	Short: i18n.T("Upload build artifacts to a GitHub release or an R2 bucket"),
+	Long: i18n.T(`Upload packaged artifacts (.zip, .tar.gz, .apk, .aab, .ipa, .msix, .dmg)
+with a .sha256 file for each, in sha256sum format. The same command
+publishes from a laptop and from CI. Requests that fail with network or
+server errors are retried with backoff.
+
+Targets:
+  github-release:<tag>    the release for the tag, created if missing;
+                          assets with the same name are replaced.
+                          Needs GITHUB_TOKEN (contents: write). The repo
+                          is --repo, $GITHUB_REPOSITORY or the origin remote.
+  r2://<bucket>/<path>    objects under path. Needs R2_ACCESS_KEY_ID and
+                          R2_SECRET_ACCESS_KEY, and --account (or
+                          $R2_ACCOUNT_ID). --endpoint points at another
+                          S3-compatible store instead.
+
+Credentials come from the environment, *_FILE paths or the vault (see
+'goup-util secrets').`),
+	Example: `  goup-util upload .dist/myapp-macos.zip .dist/myapp.apk --to github-release:v1.2.3
+  goup-util upload .dist/*.zip --to r2://releases/myapp/1.2.3 --account 0123abcd --public-url https://dl.example.com
+  goup-util upload .dist/myapp.msix --to r2://builds/nightly --endpoint https://s3.eu-central-1.amazonaws.com --region eu-central-1`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, _ := cmd.Flags().GetString("to")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		spec, err := upload.ParseSpec(to)
+		if err != nil {
+			return err
+		}
+
+		store := secrets.Open("")
+		var target upload.Target
+		switch spec.Kind {
+		case upload.GitHubRelease:
+			target, err = githubTarget(cmd, store, spec)
+		case upload.R2:
+			target, err = r2Target(cmd, store, spec)
+		}
+		if err != nil {
+			return err
+		}
+
+		if !asJSON {
+			fmt.Printf("📤 Uploading %d artifacts to %s...\n", len(args), target)
+		}
+		results, err := upload.Upload(target, args)
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if encErr := enc.Encode(results); encErr != nil {
+				return encErr
+			}
+			return err
+		}
+		for _, r := range results {
+			fmt.Printf("✓ %s (%s)\n", r.Name, formatBytes(r.Size))
+			fmt.Printf("   %s\n", r.URL)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Uploaded %d artifacts with checksums\n", len(results))
+		return nil
+	},
+}
+
+func githubTarget(cmd *cobra.Command, store *secrets.Store, spec upload.Spec) (upload.Target, error) {
+	repo, _ := cmd.Flags().GetString("repo")
+	if repo == "" {
+		repo = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if repo == "" {
+		var err error
+		if repo, err = upload.RepoFromGit("."); err != nil {
+			return nil, fmt.Errorf(i18n.T("%w; set --repo owner/name"), err)
+		}
+	}
+	token, err := store.Text("GITHUB_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		token = ghapi.StoredToken(ghapi.Service)
+	}
+	if token == "" {
+		return nil, errors.New(i18n.T("GitHub credentials missing: set GITHUB_TOKEN (see 'goup-util secrets') or run 'goup-util github login'"))
+	}
+	return upload.NewGitHub(repo, spec.Tag, token), nil
+}
+
+func r2Target(cmd *cobra.Command, store *secrets.Store, spec upload.Spec) (upload.Target, error) {
+	keyID, err := store.Text("R2_ACCESS_KEY_ID")
+	if err != nil {
+		return nil, err
+	}
+	secret, err := store.Text("R2_SECRET_ACCESS_KEY")
+	if err != nil {
+		return nil, err
+	}
+	if keyID == "" || secret == "" {
+		return nil, errors.New(i18n.T("R2 credentials missing: set R2_ACCESS_KEY_ID and R2_SECRET_ACCESS_KEY (see 'goup-util secrets')"))
+	}
+	account, _ := cmd.Flags().GetString("account")
+	if account == "" {
+		account = os.Getenv("R2_ACCOUNT_ID")
+	}
+	endpoint, _ := cmd.Flags().GetString("endpoint")
+	if endpoint == "" {
+		endpoint = os.Getenv("R2_ENDPOINT")
+	}
+	if account == "" && endpoint == "" {
+		return nil, errors.New(i18n.T("--account is required (the Cloudflare account ID, or set $R2_ACCOUNT_ID)"))
+	}
+
+	b := upload.NewR2(account, spec.Bucket, spec.Prefix, keyID, secret)
+	if endpoint != "" {
+		b.Endpoint = endpoint
+	}
+	if region, _ := cmd.Flags().GetString("region"); region != "" {
+		b.Region = region
+	}
+	b.PublicURL, _ = cmd.Flags().GetString("public-url")
+	if b.PublicURL == "" {
+		b.PublicURL = os.Getenv("R2_PUBLIC_URL")
+	}
+	return b, nil
+}
+
+func init() {
+	uploadCmd.Flags().String("to", "", i18n.T("Where to upload: github-release:<tag> or r2://<bucket>/<path>"))
+	uploadCmd.Flags().String("repo", "", i18n.T("GitHub repository owner/name (default $GITHUB_REPOSITORY or the origin remote)"))
+	uploadCmd.Flags().String("account", "", i18n.T("Cloudflare account ID for R2 (default $R2_ACCOUNT_ID)"))
+	uploadCmd.Flags().String("endpoint", "", i18n.T("S3-compatible endpoint instead of R2's (default $R2_ENDPOINT)"))
+	uploadCmd.Flags().String("region", "", i18n.T("Signing region for --endpoint (R2: auto)"))
+	uploadCmd.Flags().String("public-url", "", i18n.T("URL the bucket is served from, for the printed links (default $R2_PUBLIC_URL)"))
+	uploadCmd.Flags().Bool("json", false, i18n.T("Print the uploaded artifacts as JSON"))
+	uploadCmd.MarkFlagRequired("to")
+
+	uploadCmd.GroupID = "build"
+	rootCmd.AddCommand(uploadCmd)
+}
@@ -1,21 +1,24 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/utm"
 	"github.com/spf13/cobra"
 )
 
 var utmCmd = &cobra.Command{
 	Use:   "utm",
-	Short: "Control UTM virtual machines",
-	Long: `Control UTM virtual machines using utmctl.
+	Short: i18n.T("Control UTM virtual machines"),
+	Long: i18n.T(`Control UTM virtual machines using utmctl.
 
 This command is a wrapper around utmctl for convenient VM automation.
 Requires UTM to be installed and QEMU guest agent running in the VM.
@@ -30,6 +33,10 @@ Examples:
   # Check VM status
   goup-util utm status "Windows 11"
 
+  # Run a build VM headless and look at its screen over SSH
+  goup-util utm start "Windows 11" --headless --vnc
+  goup-util utm console "Windows 11" --host builder@mac-mini.local
+
   # Execute command in VM
   goup-util utm exec "Windows 11" -- build windows examples/hybrid-dashboard
 
@@ -40,13 +47,13 @@ Examples:
   goup-util utm pull "Windows 11" "/path/in/vm/file.txt" ./local/
 
   # Push file to VM
-  goup-util utm push "Windows 11" ./local/file.txt "/path/in/vm/"`,
+  goup-util utm push "Windows 11" ./local/file.txt "/path/in/vm/"`),
 }
 
 var utmListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
-	Short:   "List all UTM virtual machines",
+	Short:   i18n.T("List all UTM virtual machines"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return utm.RunUTMCtlInteractive("list")
 	},
@@ -54,8 +61,8 @@ var utmListCmd = &cobra.Command{
 
 var utmGalleryCmd = &cobra.Command{
 	Use:   "gallery",
-	Short: "List available VMs from the gallery",
-	Long: `List VMs available in the gallery for installation.
+	Short: i18n.T("List available VMs from the gallery"),
+	Long: i18n.T(`List VMs available in the gallery for installation.
 
 The gallery contains pre-configured VM definitions for common operating systems
 including Windows 11 ARM, Ubuntu, Debian, and Fedora.
@@ -63,11 +70,11 @@ including Windows 11 ARM, Ubuntu, Debian, and Fedora.
 Examples:
   goup-util utm gallery
   goup-util utm gallery --os windows
-  goup-util utm gallery --arch arm64`,
+  goup-util utm gallery --arch arm64`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		gallery, err := utm.LoadGallery()
 		if err != nil {
-			return fmt.Errorf("failed to load gallery: %w", err)
+			return fmt.Errorf(i18n.T("failed to load gallery: %w"), err)
 		}
 
 		osFilter, _ := cmd.Flags().GetString("os")
@@ -118,7 +125,7 @@ Examples:
 
 var utmPathsCmd = &cobra.Command{
 	Use:   "paths",
-	Short: "Show UTM paths configuration",
+	Short: i18n.T("Show UTM paths configuration"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		paths := utm.GetPaths()
 		fmt.Println("UTM Paths:")
@@ -135,8 +142,8 @@ var utmPathsCmd = &cobra.Command{
 
 var utmInstallCmd = &cobra.Command{
 	Use:   "install [vm-key]",
-	Short: "Install UTM app or download VM ISO",
-	Long: `Install the UTM application or download a VM ISO from the gallery.
+	Short: i18n.T("Install UTM app or download VM ISO"),
+	Long: i18n.T(`Install the UTM application or download a VM ISO from the gallery.
 
 Without arguments, installs the UTM application.
 With a VM key, downloads the ISO for that VM.
@@ -149,7 +156,7 @@ Examples:
   goup-util utm install windows-11-arm
 
   # Force reinstall UTM
-  goup-util utm install --force`,
+  goup-util utm install --force`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		force, _ := cmd.Flags().GetBool("force")
 
@@ -165,7 +172,7 @@ Examples:
 
 var utmUninstallCmd = &cobra.Command{
 	Use:   "uninstall",
-	Short: "Uninstall UTM app",
+	Short: i18n.T("Uninstall UTM app"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return utm.UninstallUTM()
 	},
@@ -173,7 +180,7 @@ var utmUninstallCmd = &cobra.Command{
 
 var utmDoctorCmd = &cobra.Command{
 	Use:   "doctor",
-	Short: "Check UTM installation status",
+	Short: i18n.T("Check UTM installation status"),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		status, err := utm.GetInstallStatus()
 		if err != nil {
@@ -240,7 +247,7 @@ func checkDir(name, path string) {
 var utmStatusCmd = &cobra.Command{
 	Use:     "status <vm-name>",
 	Aliases: []string{"st"},
-	Short:   "Get status of a VM",
+	Short:   i18n.T("Get status of a VM"),
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		status, err := utm.GetVMStatus(args[0])
@@ -254,16 +261,278 @@ var utmStatusCmd = &cobra.Command{
 
 var utmStartCmd = &cobra.Command{
 	Use:   "start <vm-name>",
-	Short: "Start a VM",
-	Args:  cobra.ExactArgs(1),
+	Short: i18n.T("Start a VM"),
+	Long: i18n.T(`Start a VM.
+
+With --headless, UTM is launched in the background and the VM window is
+hidden, for build hosts nobody sits at (UTM still needs a logged-in GUI
+session, e.g. automatic login). Add --vnc to serve the VM display on
+127.0.0.1 so 'utm console' can inspect it later.
+
+Examples:
+  goup-util utm start "Windows 11"
+  goup-util utm start "Windows 11" --headless --vnc`),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		headless, _ := cmd.Flags().GetBool("headless")
+		vnc, _ := cmd.Flags().GetBool("vnc")
+		port, _ := cmd.Flags().GetInt("vnc-port")
+
+		if vnc {
+			if err := utm.EnableVNC(args[0], port); err != nil {
+				return err
+			}
+		}
+		if !headless {
+			return utm.StartVM(args[0])
+		}
+		if err := utm.StartVMHeadless(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Started '%s' headless\n", args[0])
+		if vnc {
+			fmt.Printf("  Console: goup-util utm console \"%s\" --port %d\n", args[0], port)
+		}
+		return nil
+	},
+}
+
+var utmConsoleCmd = &cobra.Command{
+	Use:   "console <vm-name>",
+	Short: i18n.T("Open the VM display over VNC, locally or through SSH"),
+	Long: i18n.T(`Open the display of a VM over VNC, e.g. to see why a build hangs.
+
+The VM's QEMU is configured to serve VNC on 127.0.0.1:<port> (this needs
+the VM stopped once; a stopped VM is started headless). The console is
+never exposed on the network: from another machine use --host, which runs
+the setup on the build Mac over SSH, forwards the port through an SSH
+tunnel and opens the local VNC viewer (Screen Sharing on macOS).
+
+--serial attaches to the VM's first serial port instead, for Linux guests
+with a serial console.
+
+Apple Virtualization VMs have no QEMU and do not support VNC.
+
+Examples:
+  goup-util utm console "Windows 11"
+  goup-util utm console "Windows 11" --host builder@mac-mini.local
+  goup-util utm console "Debian 13 Trixie" --serial`),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vmName := args[0]
+		port, _ := cmd.Flags().GetInt("port")
+		host, _ := cmd.Flags().GetString("host")
+		localPort, _ := cmd.Flags().GetInt("local-port")
+		serial, _ := cmd.Flags().GetBool("serial")
+		noOpen, _ := cmd.Flags().GetBool("no-open")
+
+		if host != "" {
+			return remoteConsole(host, vmName, port, localPort, serial)
+		}
+		if serial {
+			return utm.RunUTMCtlInteractive("attach", vmName)
+		}
+
+		if err := utm.EnableVNC(vmName, port); err != nil {
+			return err
+		}
+		status, err := utm.GetVMStatus(vmName)
+		if err != nil {
+			return err
+		}
+		if status != "started" {
+			fmt.Printf("Starting '%s' headless...\n", vmName)
+			if err := utm.StartVMHeadless(vmName); err != nil {
+				return err
+			}
+		}
+		if err := utm.WaitForPort(port, time.Minute); err != nil {
+			return fmt.Errorf(i18n.T("VNC console did not come up: %w"), err)
+		}
+
+		fmt.Printf("✓ Console for '%s' at vnc://127.0.0.1:%d\n", vmName, port)
+		if noOpen {
+			return nil
+		}
+		if opened, err := utm.OpenVNCViewer(port); err != nil || !opened {
+			fmt.Println("  Open it with a VNC viewer")
+			return err
+		}
+		return nil
+	},
+}
+
+// remoteConsole sets up the console on host over SSH and tunnels it to
+// localPort. It blocks until the tunnel is closed with Ctrl-C.
+func remoteConsole(host, vmName string, port, localPort int, serial bool) error {
+	if serial {
+		ssh := exec.Command("ssh", "-t", host, "goup-util", "utm", "console", shellQuote(vmName), "--serial")
+		ssh.Stdin, ssh.Stdout, ssh.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return ssh.Run()
+	}
+
+	fmt.Printf("Setting up console on %s...\n", host)
+	setup := exec.Command("ssh", host, "goup-util", "utm", "console", shellQuote(vmName), "--no-open", "--port", strconv.Itoa(port))
+	setup.Stdout, setup.Stderr = os.Stdout, os.Stderr
+	if err := setup.Run(); err != nil {
+		return fmt.Errorf(i18n.T("failed to set up console on %s (is goup-util on its PATH?): %w"), host, err)
+	}
+
+	if localPort == 0 {
+		localPort = port
+	}
+	forward := fmt.Sprintf("%d:127.0.0.1:%d", localPort, port)
+	tunnel := exec.Command("ssh", "-N", "-o", "ExitOnForwardFailure=yes", "-L", forward, host)
+	tunnel.Stderr = os.Stderr
+	if err := tunnel.Start(); err != nil {
+		return fmt.Errorf(i18n.T("failed to start SSH tunnel: %w"), err)
+	}
+	if err := utm.WaitForPort(localPort, 15*time.Second); err != nil {
+		tunnel.Process.Kill()
+		return fmt.Errorf(i18n.T("SSH tunnel did not come up: %w"), err)
+	}
+
+	fmt.Printf("✓ Tunnel vnc://127.0.0.1:%d -> %s:%d\n", localPort, host, port)
+	if opened, err := utm.OpenVNCViewer(localPort); err != nil || !opened {
+		fmt.Println("  Open it with a VNC viewer")
+	}
+	fmt.Println("  Press Ctrl-C to close the tunnel")
+	return tunnel.Wait()
+}
+
+// shellQuote quotes s for the remote shell ssh passes commands to.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var utmProvisionCmd = &cobra.Command{
+	Use:   "provision <vm-name>",
+	Short: i18n.T("Set up a build VM from a declarative recipe"),
+	Long: i18n.T(`Run a provisioning recipe inside a VM so build VMs are reproducible
+rather than hand-configured.
+
+A recipe is a YAML list of steps, each doing one of:
+
+  install: [go, git, task, goup-util]   install tools (winget on Windows, apt/curl on Linux)
+  run: <script>                         PowerShell on Windows, sh on Linux
+  env: {NAME: value}                    machine-wide environment variables
+  reboot: true                          reboot and wait for the guest agent
+
+Steps may add 'unless: <command>' to skip themselves when the command
+succeeds. Every finished step leaves a marker in the guest named after its
+contents, so running the recipe again only runs new or changed steps.
+
+Built-in recipes: windows-build, linux-build. The VM must be running with
+the QEMU guest agent.
+
+Examples:
+  goup-util utm provision "Windows 11" --recipe windows-build
+  goup-util utm provision "Windows 11" --recipe ./vm/windows-build.yaml
+  goup-util utm provision "Debian 13 Trixie" --recipe linux-build --dry-run`),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		recipePath, _ := cmd.Flags().GetString("recipe")
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		recipe, err := utm.LoadRecipe(recipePath)
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			if err := requireGuestAgent(args[0]); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Provisioning '%s' with %s (%d steps)\n\n", args[0], recipe.Name, len(recipe.Steps))
+		if err := utm.Provision(args[0], recipe, utm.ProvisionOptions{Force: force, DryRun: dryRun}); err != nil {
+			return err
+		}
+		if !dryRun {
+			fmt.Printf("\n✅ '%s' is provisioned\n", args[0])
+		}
+		return nil
+	},
+}
+
+var utmAgentCmd = &cobra.Command{
+	Use:   "agent <vm-name>",
+	Short: i18n.T("Check the VM's QEMU guest agent and optionally repair it"),
+	Long: i18n.T(`Check that the QEMU guest agent in a VM answers. exec, task, push, pull,
+screenshot, run, build and provision need it and run this check first.
+
+When the agent is down, the fixes for the guest OS are printed. With
+--repair and --ssh, goup-util logs into the VM over SSH (which does not
+need the agent) and reinstalls the agent (Linux) or restarts its service
+(Windows), then checks again.
+
+Examples:
+  goup-util utm agent "Windows 11"
+  goup-util utm agent "Debian 13 Trixie" --repair --ssh user@192.168.64.5
+  goup-util utm agent "Debian 13 Trixie" --repair --ssh user@localhost:2222`),
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return utm.StartVM(args[0])
+		vmName := args[0]
+		repair, _ := cmd.Flags().GetBool("repair")
+		target, _ := cmd.Flags().GetString("ssh")
+		guestOS, _ := cmd.Flags().GetString("os")
+		if guestOS == "" {
+			guestOS = utm.GuestOS(vmName)
+		}
+
+		err := utm.CheckGuestAgent(vmName)
+		if err == nil {
+			fmt.Printf("✓ Guest agent in '%s' is responding\n", vmName)
+			return nil
+		}
+		if !errors.Is(err, utm.ErrGuestAgent) || !repair {
+			return requireGuestAgentError(vmName, guestOS, err)
+		}
+		if target == "" {
+			return errors.New(i18n.T("--repair needs --ssh user@host to reach the VM without the agent"))
+		}
+		if host, port, ok := strings.Cut(target, ":"); ok && !strings.Contains(port, "@") {
+			target = "ssh://" + host + ":" + port
+		}
+
+		fmt.Printf("⚠️  %v\n", err)
+		fmt.Printf("Repairing the guest agent over SSH (%s guest)...\n", guestOS)
+		if err := utm.RepairGuestAgent(vmName, guestOS, target); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Guest agent in '%s' is responding\n", vmName)
+		return nil
 	},
 }
 
+// requireGuestAgent is the preflight for commands that talk to the guest.
+func requireGuestAgent(vmName string) error {
+	err := utm.CheckGuestAgent(vmName)
+	if err == nil {
+		return nil
+	}
+	return requireGuestAgentError(vmName, utm.GuestOS(vmName), err)
+}
+
+// requireGuestAgentError prints the fixes for a missing agent.
+func requireGuestAgentError(vmName, guestOS string, err error) error {
+	if !errors.Is(err, utm.ErrGuestAgent) {
+		return err
+	}
+	fmt.Printf("✗ %v\n\nTo fix (%s guest):\n", err, guestOS)
+	for _, fix := range utm.GuestAgentFixes(guestOS) {
+		fmt.Printf("  - %s\n", fix)
+	}
+	if guestOS != "macos" {
+		fmt.Printf("  - Or, with SSH access: goup-util utm agent \"%s\" --repair --ssh user@host\n", vmName)
+	}
+	fmt.Println()
+	return err
+}
+
 var utmStopCmd = &cobra.Command{
 	Use:   "stop <vm-name>",
-	Short: "Stop a VM",
+	Short: i18n.T("Stop a VM"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return utm.StopVM(args[0])
@@ -272,7 +541,7 @@ var utmStopCmd = &cobra.Command{
 
 var utmIPCmd = &cobra.Command{
 	Use:   "ip <vm-name>",
-	Short: "Get IP address of a VM",
+	Short: i18n.T("Get IP address of a VM"),
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ip, err := utm.GetVMIP(args[0])
@@ -286,8 +555,8 @@ var utmIPCmd = &cobra.Command{
 
 var utmExecCmd = &cobra.Command{
 	Use:   "exec <vm-name> -- <command> [args...]",
-	Short: "Execute a goup-util command in the VM",
-	Long: `Execute a goup-util command in the VM.
+	Short: i18n.T("Execute a goup-util command in the VM"),
+	Long: i18n.T(`Execute a goup-util command in the VM.
 
 The command after '--' will be prefixed with 'goup-util' automatically.
 
@@ -299,14 +568,17 @@ Examples:
   goup-util utm exec "Windows 11" -- icons examples/hybrid-dashboard
 
   # Check config
-  goup-util utm exec "Windows 11" -- config`,
+  goup-util utm exec "Windows 11" -- config`),
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 2 {
-			return fmt.Errorf("usage: goup-util utm exec <vm-name> -- <command> [args...]")
+			return errors.New(i18n.T("usage: goup-util utm exec <vm-name> -- <command> [args...]"))
 		}
 
 		vmName := args[0]
+		if err := requireGuestAgent(vmName); err != nil {
+			return err
+		}
 
 		// Find the -- separator
 		dashIndex := -1
@@ -318,7 +590,7 @@ Examples:
 		}
 
 		if dashIndex == -1 || dashIndex == len(args)-1 {
-			return fmt.Errorf("missing command after '--'")
+			return errors.New(i18n.T("missing command after '--'"))
 		}
 
 		// Build goup-util command
@@ -333,17 +605,20 @@ Examples:
 
 var utmTaskCmd = &cobra.Command{
 	Use:   "task <vm-name> <task-name>",
-	Short: "Execute a Taskfile task in the VM",
-	Long: `Execute a Taskfile task in the VM.
+	Short: i18n.T("Execute a Taskfile task in the VM"),
+	Long: i18n.T(`Execute a Taskfile task in the VM.
 
 This is a convenience wrapper around 'task <taskname>'.
 
 Examples:
   goup-util utm task "Windows 11" build:hybrid:windows
-  goup-util utm task "Windows 11" test:all`,
+  goup-util utm task "Windows 11" test:all`),
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vmName := args[0]
+		if err := requireGuestAgent(vmName); err != nil {
+			return err
+		}
 		taskName := args[1]
 
 		fmt.Printf("Executing task '%s' in VM '%s'\n\n", taskName, vmName)
@@ -355,18 +630,21 @@ Examples:
 
 var utmPullCmd = &cobra.Command{
 	Use:   "pull <vm-name> <remote-path> <local-path>",
-	Short: "Pull a file from the VM to local machine",
-	Long: `Pull a file from the VM to local machine.
+	Short: i18n.T("Pull a file from the VM to local machine"),
+	Long: i18n.T(`Pull a file from the VM to local machine.
 
 Examples:
   # Pull MSIX from VM
   goup-util utm pull "Windows 11" "C:\\Users\\User\\goup-util\\examples\\hybrid-dashboard\\.bin\\hybrid-dashboard.msix" ./artifacts/
 
   # Pull build log
-  goup-util utm pull "Windows 11" "/tmp/build.log" ./logs/`,
+  goup-util utm pull "Windows 11" "/tmp/build.log" ./logs/`),
 	Args: cobra.ExactArgs(3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vmName := args[0]
+		if err := requireGuestAgent(vmName); err != nil {
+			return err
+		}
 		remotePath := args[1]
 		localPath := args[2]
 
@@ -385,18 +663,21 @@ Examples:
 
 var utmPushCmd = &cobra.Command{
 	Use:   "push <vm-name> <local-path> <remote-path>",
-	Short: "Push a file from local machine to the VM",
-	Long: `Push a file from local machine to the VM.
+	Short: i18n.T("Push a file from local machine to the VM"),
+	Long: i18n.T(`Push a file from local machine to the VM.
 
 Examples:
   # Push config file
   goup-util utm push "Windows 11" ./config.json "C:\\Users\\User\\config.json"
 
   # Push test data
-  goup-util utm push "Windows 11" ./test-data.zip "/tmp/test-data.zip"`,
+  goup-util utm push "Windows 11" ./test-data.zip "/tmp/test-data.zip"`),
 	Args: cobra.ExactArgs(3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vmName := args[0]
+		if err := requireGuestAgent(vmName); err != nil {
+			return err
+		}
 		localPath := args[1]
 		remotePath := args[2]
 
@@ -415,8 +696,8 @@ Examples:
 
 var utmCreateCmd = &cobra.Command{
 	Use:   "create <vm-key>",
-	Short: "Create a VM from gallery template (automated)",
-	Long: `Create a new UTM virtual machine from the gallery template.
+	Short: i18n.T("Create a VM from gallery template (automated)"),
+	Long: i18n.T(`Create a new UTM virtual machine from the gallery template.
 
 This uses UTM's AppleScript API to fully automate VM creation including:
   - Creating the VM with correct backend (QEMU/Apple)
@@ -443,7 +724,7 @@ Examples:
   goup-util utm create debian-13-arm --force
 
   # Use manual mode (shows instructions instead of automating)
-  goup-util utm create debian-13-arm --manual`,
+  goup-util utm create debian-13-arm --manual`),
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		force, _ := cmd.Flags().GetBool("force")
@@ -461,8 +742,8 @@ Examples:
 
 var utmMigrateCmd = &cobra.Command{
 	Use:   "migrate",
-	Short: "Migrate UTM files from local to global location",
-	Long: `Migrate UTM.app and ISOs from local repo paths to global SDK location.
+	Short: i18n.T("Migrate UTM files from local to global location"),
+	Long: i18n.T(`Migrate UTM.app and ISOs from local repo paths to global SDK location.
 
 This moves:
   .bin/UTM.app     -> ~/goup-util-sdks/utm/UTM.app
@@ -470,7 +751,7 @@ This moves:
 
 VMs and share directories remain local (project-specific).
 
-The migration is idempotent - running it multiple times is safe.`,
+The migration is idempotent - running it multiple times is safe.`),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return utm.MigrateAll()
 	},
@@ -478,8 +759,8 @@ The migration is idempotent - running it multiple times is safe.`,
 
 var utmExportCmd = &cobra.Command{
 	Use:   "export <vm-name> <output-path>",
-	Short: "Export a VM to a .utm file (UTM 4.6+)",
-	Long: `Export a virtual machine to a .utm file for sharing or backup.
+	Short: i18n.T("Export a VM to a .utm file (UTM 4.6+)"),
+	Long: i18n.T(`Export a virtual machine to a .utm file for sharing or backup.
 
 The exported file can be imported on another machine or used as a template.
 Requires UTM 4.6 or later.
@@ -489,7 +770,7 @@ Examples:
   goup-util utm export "Debian 13 Trixie" ./debian-template.utm
 
   # Export to specific path
-  goup-util utm export "Windows 11" ~/vm-templates/windows-dev.utm`,
+  goup-util utm export "Windows 11" ~/vm-templates/windows-dev.utm`),
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vmName := args[0]
@@ -508,8 +789,8 @@ Examples:
 
 var utmImportCmd = &cobra.Command{
 	Use:   "import <utm-file>",
-	Short: "Import a VM from a .utm file (UTM 4.6+)",
-	Long: `Import a virtual machine from a .utm file.
+	Short: i18n.T("Import a VM from a .utm file (UTM 4.6+)"),
+	Long: i18n.T(`Import a virtual machine from a .utm file.
 
 This creates a new VM from the exported template.
 Requires UTM 4.6 or later.
@@ -519,7 +800,7 @@ Examples:
   goup-util utm import ./debian-template.utm
 
   # Import from absolute path
-  goup-util utm import ~/vm-templates/windows-dev.utm`,
+  goup-util utm import ~/vm-templates/windows-dev.utm`),
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		utmPath := args[0]
@@ -538,8 +819,8 @@ Examples:
 
 var utmScreenshotCmd = &cobra.Command{
 	Use:   "screenshot <vm-name> [output-file]",
-	Short: "Capture a screenshot from the VM",
-	Long: `Capture a screenshot from a running VM.
+	Short: i18n.T("Capture a screenshot from the VM"),
+	Long: i18n.T(`Capture a screenshot from a running VM.
 
 Uses PowerShell to capture the screen inside the VM, then pulls the
 image back to the host. Does NOT require goup-util to be installed in the VM.
@@ -551,10 +832,13 @@ Examples:
   goup-util utm screenshot "Windows 11" windows-screenshot.png
 
   # Capture with default filename
-  goup-util utm screenshot "Windows 11"`,
+  goup-util utm screenshot "Windows 11"`),
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vmName := args[0]
+		if err := requireGuestAgent(vmName); err != nil {
+			return err
+		}
 		output := "utm-screenshot.png"
 		if len(args) > 1 {
 			output = args[1]
@@ -573,14 +857,14 @@ Examples:
 			fmt.Println("PowerShell screenshot failed, trying goup-util in VM...")
 			goupCmd := fmt.Sprintf("goup-util screenshot --force %s", remotePath)
 			if err2 := utm.ExecInVM(vmName, goupCmd); err2 != nil {
-				return fmt.Errorf("screenshot failed in VM: %w (PowerShell: %v)", err2, err)
+				return fmt.Errorf(i18n.T("screenshot failed in VM: %w (PowerShell: %v)"), err2, err)
 			}
 		}
 
 		// Pull the screenshot back to host
 		fmt.Printf("Pulling screenshot to %s...\n", output)
 		if err := utm.PullFile(vmName, remotePath, output); err != nil {
-			return fmt.Errorf("failed to pull screenshot: %w", err)
+			return fmt.Errorf(i18n.T("failed to pull screenshot: %w"), err)
 		}
 
 		// Clean up remote file
@@ -594,8 +878,8 @@ Examples:
 
 var utmRunCmd = &cobra.Command{
 	Use:   "run <vm-name> <app-directory>",
-	Short: "Build app for Windows and run it in the VM",
-	Long: `Cross-compile a Gio application for Windows, push it to the VM, and run it.
+	Short: i18n.T("Build app for Windows and run it in the VM"),
+	Long: i18n.T(`Cross-compile a Gio application for Windows, push it to the VM, and run it.
 
 This automates the full workflow:
 1. Build the app for Windows (cross-compile on macOS)
@@ -609,10 +893,13 @@ Examples:
   goup-util utm run "Windows 11" examples/hybrid-dashboard
 
   # Build and run webviewer example
-  goup-util utm run "Windows 11" examples/gio-plugin-webviewer`,
+  goup-util utm run "Windows 11" examples/gio-plugin-webviewer`),
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vmName := args[0]
+		if err := requireGuestAgent(vmName); err != nil {
+			return err
+		}
 		appDir := args[1]
 
 		// Build for Windows
@@ -622,7 +909,7 @@ Examples:
 		buildExec.Stdout = os.Stdout
 		buildExec.Stderr = os.Stderr
 		if err := buildExec.Run(); err != nil {
-			return fmt.Errorf("Windows build failed: %w", err)
+			return fmt.Errorf(i18n.T("Windows build failed: %w"), err)
 		}
 
 		// Determine the output binary path
@@ -631,20 +918,20 @@ Examples:
 
 		// Check binary exists
 		if _, err := os.Stat(localBinary); os.IsNotExist(err) {
-			return fmt.Errorf("built binary not found at %s", localBinary)
+			return fmt.Errorf(i18n.T("built binary not found at %s"), localBinary)
 		}
 
 		// Push binary to VM
 		remoteBinary := fmt.Sprintf("C:\\Users\\User\\%s.exe", appName)
 		fmt.Printf("Pushing %s to VM '%s'...\n", localBinary, vmName)
 		if err := utm.PushFile(vmName, localBinary, remoteBinary); err != nil {
-			return fmt.Errorf("failed to push binary: %w", err)
+			return fmt.Errorf(i18n.T("failed to push binary: %w"), err)
 		}
 
 		// Run in VM
 		fmt.Printf("Launching %s in VM...\n", appName)
 		if err := utm.ExecInVM(vmName, remoteBinary); err != nil {
-			return fmt.Errorf("failed to run in VM: %w", err)
+			return fmt.Errorf(i18n.T("failed to run in VM: %w"), err)
 		}
 
 		fmt.Printf("✓ App running in VM '%s'\n", vmName)
@@ -654,8 +941,8 @@ Examples:
 
 var utmBuildCmd = &cobra.Command{
 	Use:   "build <vm-name> [platform] <app-directory>",
-	Short: "Build an app inside the VM natively",
-	Long: `Build a Gio application inside the VM using the VM's native toolchain.
+	Short: i18n.T("Build an app inside the VM natively"),
+	Long: i18n.T(`Build a Gio application inside the VM using the VM's native toolchain.
 
 This is different from 'utm run' which cross-compiles on macOS. This command
 executes the build inside the VM, producing a native binary. Useful when
@@ -669,10 +956,13 @@ Examples:
   goup-util utm build "Windows 11" windows examples/hybrid-dashboard
 
   # Build with platform auto-detected from VM OS
-  goup-util utm build "Windows 11" examples/hybrid-dashboard`,
+  goup-util utm build "Windows 11" examples/hybrid-dashboard`),
 	Args: cobra.RangeArgs(2, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vmName := args[0]
+		if err := requireGuestAgent(vmName); err != nil {
+			return err
+		}
 		var platform, appDir string
 
 		if len(args) == 3 {
@@ -689,7 +979,7 @@ Examples:
 
 		fmt.Printf("Building %s for %s in VM '%s'...\n", appDir, platform, vmName)
 		if err := utm.ExecInVM(vmName, buildCmd); err != nil {
-			return fmt.Errorf("build in VM failed: %w", err)
+			return fmt.Errorf(i18n.T("build in VM failed: %w"), err)
 		}
 
 		fmt.Printf("✓ Built %s for %s in VM '%s'\n", appDir, platform, vmName)
@@ -711,7 +1001,7 @@ Examples:
 
 			fmt.Printf("Pulling %s to %s...\n", remoteBinary, localBinary)
 			if err := utm.PullFile(vmName, remoteBinary, localBinary); err != nil {
-				return fmt.Errorf("failed to pull binary: %w", err)
+				return fmt.Errorf(i18n.T("failed to pull binary: %w"), err)
 			}
 			fmt.Printf("✓ Binary pulled to %s\n", localBinary)
 		}
@@ -722,8 +1012,8 @@ Examples:
 
 var utmPortForwardCmd = &cobra.Command{
 	Use:   "port-forward <vm-name> <guest-port> <host-port>",
-	Short: "Set up port forwarding for a VM",
-	Long: `Set up port forwarding from host to guest VM.
+	Short: i18n.T("Set up port forwarding for a VM"),
+	Long: i18n.T(`Set up port forwarding from host to guest VM.
 
 This allows you to access services running in the VM from your host machine.
 The VM must have an emulated VLAN network interface configured.
@@ -739,19 +1029,19 @@ Examples:
   goup-util utm port-forward "Debian 13 Trixie" 22 2222 --setup-network
 
   # Forward HTTP
-  goup-util utm port-forward "Windows 11" 80 8080`,
+  goup-util utm port-forward "Windows 11" 80 8080`),
 	Args: cobra.ExactArgs(3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		vmName := args[0]
 
 		guestPort, err := strconv.Atoi(args[1])
 		if err != nil {
-			return fmt.Errorf("invalid guest port: %s", args[1])
+			return fmt.Errorf(i18n.T("invalid guest port: %s"), args[1])
 		}
 
 		hostPort, err := strconv.Atoi(args[2])
 		if err != nil {
-			return fmt.Errorf("invalid host port: %s", args[2])
+			return fmt.Errorf(i18n.T("invalid host port: %s"), args[2])
 		}
 
 		setupNetwork, _ := cmd.Flags().GetBool("setup-network")
@@ -760,7 +1050,7 @@ Examples:
 		if setupNetwork {
 			fmt.Printf("Setting up emulated network for '%s'...\n", vmName)
 			if err := utm.SetupEmulatedNetwork(vmName); err != nil {
-				return fmt.Errorf("failed to setup network: %w", err)
+				return fmt.Errorf(i18n.T("failed to setup network: %w"), err)
 			}
 		}
 
@@ -803,6 +1093,9 @@ func init() {
 	utmCmd.AddCommand(utmDoctorCmd)
 	utmCmd.AddCommand(utmStatusCmd)
 	utmCmd.AddCommand(utmStartCmd)
+	utmCmd.AddCommand(utmConsoleCmd)
+	utmCmd.AddCommand(utmProvisionCmd)
+	utmCmd.AddCommand(utmAgentCmd)
 	utmCmd.AddCommand(utmStopCmd)
 	utmCmd.AddCommand(utmIPCmd)
 	utmCmd.AddCommand(utmExecCmd)
@@ -819,22 +1112,45 @@ func init() {
 	utmCmd.AddCommand(utmBuildCmd)
 
 	// Build flags
-	utmBuildCmd.Flags().Bool("pull", false, "Pull built binary back to host after building")
+	utmBuildCmd.Flags().Bool("pull", false, i18n.T("Pull built binary back to host after building"))
 
 	// Create flags
-	utmCreateCmd.Flags().Bool("force", false, "Force recreate VM if exists")
-	utmCreateCmd.Flags().Bool("manual", false, "Show manual instructions instead of automating")
-	utmCreateCmd.Flags().BoolP("verbose", "v", false, "Verbose output")
+	utmCreateCmd.Flags().Bool("force", false, i18n.T("Force recreate VM if exists"))
+	utmCreateCmd.Flags().Bool("manual", false, i18n.T("Show manual instructions instead of automating"))
+	utmCreateCmd.Flags().BoolP("verbose", "v", false, i18n.T("Verbose output"))
 
 	// Gallery filters
-	utmGalleryCmd.Flags().String("os", "", "Filter by OS (windows, linux)")
-	utmGalleryCmd.Flags().String("arch", "", "Filter by architecture (arm64, amd64)")
+	utmGalleryCmd.Flags().String("os", "", i18n.T("Filter by OS (windows, linux)"))
+	utmGalleryCmd.Flags().String("arch", "", i18n.T("Filter by architecture (arm64, amd64)"))
 
 	// Install flags
-	utmInstallCmd.Flags().Bool("force", false, "Force reinstall/redownload")
+	utmInstallCmd.Flags().Bool("force", false, i18n.T("Force reinstall/redownload"))
+
+	// Start flags
+	utmStartCmd.Flags().Bool("headless", false, i18n.T("Start in the background without showing UTM or the VM window"))
+	utmStartCmd.Flags().Bool("vnc", false, i18n.T("Serve the VM display over VNC on 127.0.0.1 (VM must be stopped the first time)"))
+	utmStartCmd.Flags().Int("vnc-port", utm.DefaultVNCPort, i18n.T("Host port for --vnc"))
+
+	// Provision flags
+	utmProvisionCmd.Flags().String("recipe", "", i18n.T("Recipe file, or a built-in recipe name (windows-build, linux-build)"))
+	utmProvisionCmd.MarkFlagRequired("recipe")
+	utmProvisionCmd.Flags().Bool("force", false, i18n.T("Run every step, ignoring markers"))
+	utmProvisionCmd.Flags().Bool("dry-run", false, i18n.T("Print the steps' scripts without running them"))
+
+	// Agent flags
+	utmAgentCmd.Flags().Bool("repair", false, i18n.T("Reinstall/restart the agent over SSH if it is down"))
+	utmAgentCmd.Flags().String("ssh", "", i18n.T("SSH destination in the VM for --repair (user@host or user@host:port)"))
+	utmAgentCmd.Flags().String("os", "", i18n.T("Guest OS (windows, linux); guessed from the VM name by default"))
+
+	// Console flags
+	utmConsoleCmd.Flags().Int("port", utm.DefaultVNCPort, i18n.T("VNC port on the VM host"))
+	utmConsoleCmd.Flags().String("host", "", i18n.T("Build Mac to reach over SSH (user@host); the console is tunnelled"))
+	utmConsoleCmd.Flags().Int("local-port", 0, i18n.T("Local end of the SSH tunnel (default: same as --port)"))
+	utmConsoleCmd.Flags().Bool("serial", false, i18n.T("Attach to the first serial port instead of VNC"))
+	utmConsoleCmd.Flags().Bool("no-open", false, i18n.T("Set up the console without opening a viewer"))
 
 	// Port forward flags
-	utmPortForwardCmd.Flags().String("protocol", "tcp", "Protocol (tcp or udp)")
-	utmPortForwardCmd.Flags().Int("network-index", 1, "Network interface index (1 = emulated VLAN)")
-	utmPortForwardCmd.Flags().Bool("setup-network", false, "Setup emulated network if not configured")
+	utmPortForwardCmd.Flags().String("protocol", "tcp", i18n.T("Protocol (tcp or udp)"))
+	utmPortForwardCmd.Flags().Int("network-index", 1, i18n.T("Network interface index (1 = emulated VLAN)"))
+	utmPortForwardCmd.Flags().Bool("setup-network", false, i18n.T("Setup emulated network if not configured"))
 }
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/joeblew999/goup-util/pkg/buildnumber"
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/project"
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: i18n.T("Manage build numbers (Android versionCode, iOS CFBundleVersion)"),
+	Long: i18n.T(`Manage the monotonic build numbers used for store uploads.
+
+Stores reject a build whose Android versionCode or iOS/macOS CFBundleVersion
+is not higher than the last upload. 'build --build-number auto' and 'bundle'
+take the next number from here automatically.
+
+Numbers are kept per app in the goup-util cache directory. CI runners that
+do not share a disk should use a git remote instead (--remote or
+$GOUP_BUILD_REMOTE): each number is reserved by pushing the tag
+build/<app>/<n>, and a rejected push means another runner took it first.
+
+The numbers print bare on stdout so scripts can capture them.
+
+Examples:
+  goup-util version next examples/hybrid-dashboard
+  goup-util version bump examples/hybrid-dashboard
+  goup-util version set 120 examples/hybrid-dashboard   # continue from Play Console
+  BUILD=$(goup-util version bump --remote origin .)`),
+}
+
+var versionRemote string
+
+var versionCurrentCmd = &cobra.Command{
+	Use:   "current [app-directory]",
+	Short: i18n.T("Print the last reserved build number"),
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, key, err := versionStore(args)
+		if err != nil {
+			return err
+		}
+		n, err := store.Current(key)
+		if err != nil {
+			return err
+		}
+		fmt.Println(n)
+		return nil
+	},
+}
+
+var versionNextCmd = &cobra.Command{
+	Use:   "next [app-directory]",
+	Short: i18n.T("Print the next build number without reserving it"),
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, key, err := versionStore(args)
+		if err != nil {
+			return err
+		}
+		n, err := store.Current(key)
+		if err != nil {
+			return err
+		}
+		fmt.Println(n + 1)
+		return nil
+	},
+}
+
+var versionBumpCmd = &cobra.Command{
+	Use:   "bump [app-directory]",
+	Short: i18n.T("Reserve and print the next build number"),
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, key, err := versionStore(args)
+		if err != nil {
+			return err
+		}
+		n, err := store.Next(key)
+		if err != nil {
+			return fmt.Errorf(i18n.T("failed to reserve build number: %w"), err)
+		}
+		fmt.Println(n)
+		return nil
+	},
+}
+
+var versionSetCmd = &cobra.Command{
+	Use:   "set <number> [app-directory]",
+	Short: i18n.T("Raise the build number (it can never go down)"),
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf(i18n.T("invalid build number %q"), args[0])
+		}
+		store, key, err := versionStore(args[1:])
+		if err != nil {
+			return err
+		}
+		if err := store.Set(key, n); err != nil {
+			return err
+		}
+		fmt.Println(n)
+		return nil
+	},
+}
+
+// versionStore opens the build number store for the app directory in args
+// (default "."), keyed by project name like build and bundle do.
+func versionStore(args []string) (buildnumber.Store, string, error) {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	proj, err := project.NewGioProject(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf(i18n.T("failed to create project: %w"), err)
+	}
+	return buildnumber.Open(versionRemote, proj.RootDir), proj.Name, nil
+}
+
+func init() {
+	versionCmd.PersistentFlags().StringVar(&versionRemote, "remote", "", "Git remote holding build number tags (default $"+buildnumber.RemoteEnv+", else local)")
+
+	versionCmd.AddCommand(versionCurrentCmd)
+	versionCmd.AddCommand(versionNextCmd)
+	versionCmd.AddCommand(versionBumpCmd)
+	versionCmd.AddCommand(versionSetCmd)
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.GroupID = "build"
+}
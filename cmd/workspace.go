@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/joeblew999/goup-util/pkg/i18n"
 	"github.com/joeblew999/goup-util/pkg/workspace"
 	"github.com/spf13/cobra"
 )
@@ -11,21 +12,21 @@ import (
 // workspaceCmd represents the workspace command
 var workspaceCmd = &cobra.Command{
 	Use:   "workspace",
-	Short: "Manage Go workspace files",
-	Long: `Manage Go workspace files by detecting, inspecting, and modifying go.work files.
+	Short: i18n.T("Manage Go workspace files"),
+	Long: i18n.T(`Manage Go workspace files by detecting, inspecting, and modifying go.work files.
 
 This command helps you work with Go workspaces in monorepos by automatically finding
-go.work files and managing module entries.`,
+go.work files and managing module entries.`),
 }
 
 // workspaceInfoCmd shows information about the current workspace
 var workspaceInfoCmd = &cobra.Command{
 	Use:   "info [path]",
-	Short: "Show information about the Go workspace",
-	Long: `Display information about the Go workspace, including the path to go.work
+	Short: i18n.T("Show information about the Go workspace"),
+	Long: i18n.T(`Display information about the Go workspace, including the path to go.work
 and all modules currently in the workspace.
 
-If no path is provided, searches from the current directory.`,
+If no path is provided, searches from the current directory.`),
 	Run: func(cmd *cobra.Command, args []string) {
 		var searchPath string
 		if len(args) > 0 {
@@ -59,10 +60,10 @@ If no path is provided, searches from the current directory.`,
 // workspaceListCmd lists all modules in the workspace
 var workspaceListCmd = &cobra.Command{
 	Use:   "list [path]",
-	Short: "List all modules in the workspace",
-	Long: `List all modules currently included in the Go workspace.
+	Short: i18n.T("List all modules in the workspace"),
+	Long: i18n.T(`List all modules currently included in the Go workspace.
 
-If no path is provided, searches from the current directory.`,
+If no path is provided, searches from the current directory.`),
 	Run: func(cmd *cobra.Command, args []string) {
 		var searchPath string
 		if len(args) > 0 {
@@ -95,10 +96,10 @@ If no path is provided, searches from the current directory.`,
 // workspaceAddCmd adds a module to the workspace
 var workspaceAddCmd = &cobra.Command{
 	Use:   "add <module-path>",
-	Short: "Add a module to the workspace",
-	Long: `Add a module to the Go workspace using 'go work use'.
+	Short: i18n.T("Add a module to the workspace"),
+	Long: i18n.T(`Add a module to the Go workspace using 'go work use'.
 
-The module path should be relative to the workspace root.`,
+The module path should be relative to the workspace root.`),
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		modulePath := args[0]
@@ -133,10 +134,10 @@ The module path should be relative to the workspace root.`,
 // workspaceRemoveCmd removes a module from the workspace
 var workspaceRemoveCmd = &cobra.Command{
 	Use:   "remove <module-path>",
-	Short: "Remove a module from the workspace",
-	Long: `Remove a module from the Go workspace using 'go work drop'.
+	Short: i18n.T("Remove a module from the workspace"),
+	Long: i18n.T(`Remove a module from the Go workspace using 'go work drop'.
 
-The module path should match exactly what's in the go.work file.`,
+The module path should match exactly what's in the go.work file.`),
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		modulePath := args[0]
@@ -171,10 +172,10 @@ The module path should match exactly what's in the go.work file.`,
 // workspaceCheckCmd checks if a module is in the workspace
 var workspaceCheckCmd = &cobra.Command{
 	Use:   "check <module-path>",
-	Short: "Check if a module is in the workspace",
-	Long: `Check if a specific module is included in the Go workspace.
+	Short: i18n.T("Check if a module is in the workspace"),
+	Long: i18n.T(`Check if a specific module is included in the Go workspace.
 
-Returns exit code 0 if the module is in the workspace, 1 if not.`,
+Returns exit code 0 if the module is in the workspace, 1 if not.`),
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		modulePath := args[0]
@@ -211,6 +212,6 @@ func init() {
 	workspaceCmd.AddCommand(workspaceCheckCmd)
 
 	// Add flags
-	workspaceAddCmd.Flags().BoolP("force", "f", false, "Force addition without confirmation")
-	workspaceRemoveCmd.Flags().BoolP("force", "f", false, "Force removal without confirmation")
+	workspaceAddCmd.Flags().BoolP("force", "f", false, i18n.T("Force addition without confirmation"))
+	workspaceRemoveCmd.Flags().BoolP("force", "f", false, i18n.T("Force removal without confirmation"))
 }
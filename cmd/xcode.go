@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/i18n"
+	"github.com/joeblew999/goup-util/pkg/xcode"
+	"github.com/spf13/cobra"
+)
+
+var xcodeCmd = &cobra.Command{
+	Use:   "xcode",
+	Short: i18n.T("Manage Xcode versions and check Xcode components"),
+	Long: i18n.T(`List the installed Xcode versions, switch the active developer directory
+between them, and check that what iOS and macOS builds need is installed.
+
+Install the Command Line Tools with: goup-util install xcode-clt`),
+}
+
+var xcodeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("List installed Xcode versions"),
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := xcode.Installed()
+		if err != nil {
+			return err
+		}
+		active := xcode.Active(list)
+		for _, x := range list {
+			marker := " "
+			if active != nil && active.Path == x.Path {
+				marker = "*"
+			}
+			fmt.Printf("%s %-8s %-8s %s\n", marker, x.Version, x.Build, x.Path)
+		}
+		if len(list) == 0 {
+			fmt.Println("No Xcode installed in /Applications")
+		}
+
+		dir, err := xcode.ActiveDeveloperDir()
+		switch {
+		case err != nil:
+			fmt.Println("⚠️  No developer directory selected; run: goup-util install xcode-clt")
+		case active == nil:
+			fmt.Printf("Active developer directory: %s\n", dir)
+		}
+		if xcode.CLTInstalled() {
+			fmt.Printf("✓ Command Line Tools installed in %s\n", xcode.CLTDir)
+		}
+		return nil
+	},
+}
+
+var xcodeSelectCmd = &cobra.Command{
+	Use:   "select <version|path>",
+	Short: i18n.T("Make an installed Xcode the active developer directory"),
+	Long: i18n.T(`Switch the machine's active developer directory (xcode-select --switch,
+through sudo) to an installed Xcode. "15" picks the newest 15.x.
+
+To use another Xcode for one command only, set DEVELOPER_DIR instead:
+
+  DEVELOPER_DIR=/Applications/Xcode-15.2.app/Contents/Developer goup-util build ios .`),
+	Example: `  goup-util xcode select 15.4
+  goup-util xcode select /Applications/Xcode-beta.app`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := xcode.Installed()
+		if err != nil {
+			return err
+		}
+		x, err := xcode.Find(list, args[0])
+		if err != nil {
+			return err
+		}
+		if active := xcode.Active(list); active != nil && active.Path == x.Path {
+			fmt.Printf("✓ Xcode %s is already active\n", x.Version)
+			return nil
+		}
+		if err := xcode.Select(x); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Xcode %s (%s) is now active\n", x.Version, x.Path)
+		return nil
+	},
+}
+
+// xcodeCheckTargets maps build platforms to the SDKs they need.
+var xcodeCheckTargets = map[string]string{
+	"macos":         xcode.MacOSX,
+	"ios":           xcode.IPhoneOS,
+	"ios-simulator": xcode.IPhoneSimulator,
+}
+
+var xcodeCheckCmd = &cobra.Command{
+	Use:   "check [macos|ios|ios-simulator]...",
+	Short: i18n.T("Check the Xcode components builds need"),
+	Args:  cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"macos", "ios", "ios-simulator"}
+		}
+		failed := false
+		for _, platform := range args {
+			sdk, ok := xcodeCheckTargets[platform]
+			if !ok {
+				return fmt.Errorf(i18n.T("unknown platform %q (use macos, ios or ios-simulator)"), platform)
+			}
+			problems := xcode.Preflight(sdk)
+			if len(problems) == 0 {
+				fmt.Printf("✓ %s: ready\n", platform)
+				continue
+			}
+			failed = true
+			for _, p := range problems {
+				fmt.Printf("❌ %s: %s\n", platform, p)
+			}
+		}
+		if failed {
+			return errors.New(i18n.T("Xcode components are missing"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	xcodeCmd.AddCommand(xcodeListCmd, xcodeSelectCmd, xcodeCheckCmd)
+	xcodeCmd.GroupID = "sdk"
+	rootCmd.AddCommand(xcodeCmd)
+}
+
+// isXcodeCLT reports whether sdkName refers to the Command Line Tools.
+func isXcodeCLT(sdkName string) bool {
+	return sdkName == "xcode-clt" || sdkName == "xcode-command-line-tools"
+}
+
+// installXcodeCLT installs the Command Line Tools with softwareupdate,
+// falling back to the xcode-select --install dialog.
+func installXcodeCLT() error {
+	if runtime.GOOS != "darwin" {
+		return errors.New(i18n.T("the Xcode Command Line Tools can only be installed on macOS"))
+	}
+	if xcode.CLTInstalled() {
+		fmt.Println("✓ Xcode Command Line Tools are already installed.")
+		return nil
+	}
+	if dir, err := xcode.ActiveDeveloperDir(); err == nil {
+		fmt.Printf("✓ Developer tools are already available from %s\n", dir)
+		return nil
+	}
+
+	if err := xcode.InstallCLT(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		fmt.Println("Opening the installer dialog instead (xcode-select --install); rerun this command once it finishes.")
+		dialog := exec.Command("xcode-select", "--install")
+		dialog.Stdout = os.Stdout
+		dialog.Stderr = os.Stderr
+		if err := dialog.Run(); err != nil {
+			return fmt.Errorf(i18n.T("xcode-select --install failed: %w"), err)
+		}
+		return nil
+	}
+	fmt.Println("✅ Xcode Command Line Tools installed.")
+	return nil
+}
+
+// xcodePreflight stops a local Apple build before gogio runs when an
+// Xcode component is missing, naming the component and its fix.
+func xcodePreflight(sdk string) error {
+	if runtime.GOOS != "darwin" {
+		return nil
+	}
+	problems := xcode.Preflight(sdk)
+	if len(problems) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for _, p := range problems {
+		fmt.Fprintf(&b, "\n❌ %s", p)
+	}
+	return fmt.Errorf(i18n.T("missing Xcode components for %s:%s"), sdk, b.String())
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gioui.org/io/event"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+
+// accessibilityConfig adapts the shell and its pages for public kiosks.
+type accessibilityConfig struct {
+	// MinFontSize is the smallest text in CSS pixels the pages show. The
+	// shell's toolbar and tabs grow to match when it is above their 16.
+	MinFontSize  int  `json:"minFontSize,omitempty"`
+	HighContrast bool `json:"highContrast,omitempty"` // High-contrast toolbar and tabs, instead of the theme
+	Audit        bool `json:"audit,omitempty"`        // Check pages for screen reader problems, logged to accessibility.log
+	Keyboard     bool `json:"keyboard,omitempty"`     // Toolbar button that shows and hides the on-screen keyboard
+}
+
+// chromeFontSize is the text size of the toolbar and tabs, in dp.
+const chromeFontSize = 16
+
+// metric scales the shell's chrome up so its text is at least MinFontSize.
+func (a accessibilityConfig) metric(m unit.Metric) unit.Metric {
+	if a.MinFontSize > chromeFontSize {
+		scale := float32(a.MinFontSize) / chromeFontSize
+		m.PxPerDp *= scale
+		m.PxPerSp *= scale
+	}
+	return m
+}
+
+// highContrastPalette is black and white with yellow for the selected tab
+// and selection, above the WCAG AAA contrast ratio.
+var highContrastPalette = palette{
+	Mode:         ThemeDark,
+	HighContrast: true,
+	Toolbar:      color.NRGBA{A: 255},
+	Address:      color.NRGBA{A: 255},
+	Text:         color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+	Selection:    color.NRGBA{R: 255, G: 214, B: 0, A: 160},
+	TabActive:    color.NRGBA{R: 255, G: 214, B: 0, A: 255},
+	Tab:          color.NRGBA{A: 255},
+	Button:       color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+	ButtonText:   color.NRGBA{A: 255},
+}
+
+// palette is the chrome's palette: the theme, or the high-contrast one.
+func (c *appConfig) palette() (palette, error) {
+	p, err := newPalette(c.Theme)
+	if err != nil || !c.Accessibility.HighContrast {
+		return p, err
+	}
+	return highContrastPalette, nil
+}
+
+// minFontScript returns JavaScript that raises text smaller than min CSS
+// pixels to min, in the page and in content added to it later.
+func minFontScript(min int) string {
+	return fmt.Sprintf(`(function (min) {
+  if (window.__goupMinFont) { return; }
+  window.__goupMinFont = true;
+  function fix(el) {
+    if (el.nodeType !== 1) { return; }
+    var all = [el].concat(Array.prototype.slice.call(el.querySelectorAll("*")));
+    all.forEach(function (e) {
+      if (parseFloat(getComputedStyle(e).fontSize) < min) {
+        e.style.setProperty("font-size", min + "px", "important");
+      }
+    });
+  }
+  function start() {
+    fix(document.documentElement);
+    new MutationObserver(function (records) {
+      records.forEach(function (r) { r.addedNodes.forEach(fix); });
+    }).observe(document.documentElement, { childList: true, subtree: true });
+  }
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", start);
+  } else {
+    start();
+  }
+})(%d);`, min)
+}
+
+const auditCallback = "goupa11y" // window.callback.goupa11y(report)
+
+// auditReport is what a page's accessibility check found.
+type auditReport struct {
+	Page   string `json:"page"`
+	Issues []struct {
+		Rule    string `json:"rule"`
+		Count   int    `json:"count"`
+		Example string `json:"example,omitempty"` // Outer HTML of the first, shortened
+	} `json:"issues"`
+}
+
+// auditRules describe the checks for the log.
+var auditRules = map[string]string{
+	"lang":     "no lang attribute on <html>",
+	"title":    "no page title",
+	"img-alt":  "images without alt text",
+	"label":    "form fields without a label",
+	"name":     "buttons or links without an accessible name",
+	"headings": "heading levels skipped",
+}
+
+// pageAudit checks loaded pages for problems screen reader users hit.
+type pageAudit struct {
+	logPath string
+	seen    map[string]bool // Pages already logged
+}
+
+// newPageAudit returns nil unless accessibility.audit is set.
+func newPageAudit(cfg *appConfig) *pageAudit {
+	if !cfg.Accessibility.Audit {
+		return nil
+	}
+	a := &pageAudit{seen: map[string]bool{}}
+	if dir, err := os.UserConfigDir(); err == nil {
+		a.logPath = filepath.Join(dir, cfg.Name, "accessibility.log")
+	}
+	return a
+}
+
+// prepare installs the check into a tab; nil does nothing.
+func (a *pageAudit) prepare(gtx layout.Context, view event.Tag) {
+	if a == nil {
+		return
+	}
+	gioplugins.Execute(gtx, giowebview.MessageReceiverCmd{View: view, Tag: a, Name: auditCallback})
+	gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: view, Script: auditScript})
+}
+
+// update logs the reports of the pages. Call it once per frame.
+func (a *pageAudit) update(gtx layout.Context) {
+	if a == nil {
+		return
+	}
+	for {
+		evt, ok := gioplugins.Event(gtx, giowebview.Filter{Target: a})
+		if !ok {
+			return
+		}
+		msg, ok := evt.(giowebview.MessageEvent)
+		if !ok {
+			continue
+		}
+		var r auditReport
+		if json.Unmarshal([]byte(msg.Message), &r) != nil || len(r.Issues) == 0 || a.seen[r.Page] {
+			continue
+		}
+		a.seen[r.Page] = true
+		a.log(r)
+	}
+}
+
+// log prints a report and appends it to accessibility.log.
+func (a *pageAudit) log(r auditReport) {
+	var found []string
+	for _, issue := range r.Issues {
+		found = append(found, fmt.Sprintf("%d %s", issue.Count, auditRules[issue.Rule]))
+	}
+	line := fmt.Sprintf("%s: %s", r.Page, strings.Join(found, ", "))
+	fmt.Println(tr("Accessibility: %s", line))
+	if a.logPath == "" || os.MkdirAll(filepath.Dir(a.logPath), 0755) != nil {
+		return
+	}
+	f, err := os.OpenFile(a.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s\n", time.Now().Format(time.RFC3339), line)
+	for _, issue := range r.Issues {
+		if issue.Example != "" {
+			fmt.Fprintf(f, "\t%s: %s\n", issue.Rule, issue.Example)
+		}
+	}
+}
+
+// auditScript checks the page two seconds after it loads, once scripts
+// have rendered it, and reports what it found.
+const auditScript = `(function () {
+  if (window.__goupAudit) { return; }
+  window.__goupAudit = true;
+  function hidden(el) {
+    return el.closest("[aria-hidden=true]") || el.getAttribute("role") === "presentation" || el.getAttribute("role") === "none";
+  }
+  function named(el) {
+    if (el.getAttribute("aria-label") || el.getAttribute("aria-labelledby") || el.getAttribute("title")) { return true; }
+    if ((el.textContent || "").trim()) { return true; }
+    return !!el.querySelector("img[alt]:not([alt='']), svg title");
+  }
+  function labelled(el) {
+    if (el.getAttribute("aria-label") || el.getAttribute("aria-labelledby") || el.getAttribute("title")) { return true; }
+    if (el.closest("label")) { return true; }
+    return el.id && document.querySelector("label[for='" + CSS.escape(el.id) + "']");
+  }
+  function check() {
+    var issues = [];
+    function add(rule, els) {
+      if (els.length) {
+        issues.push({ rule: rule, count: els.length, example: (els[0].outerHTML || "").slice(0, 160) });
+      }
+    }
+    function all(sel, keep) {
+      return Array.prototype.filter.call(document.querySelectorAll(sel), keep);
+    }
+    if (!document.documentElement.getAttribute("lang")) { issues.push({ rule: "lang", count: 1 }); }
+    if (!document.title.trim()) { issues.push({ rule: "title", count: 1 }); }
+    add("img-alt", all("img", function (el) { return !el.hasAttribute("alt") && !hidden(el); }));
+    add("label", all("input:not([type=hidden]):not([type=submit]):not([type=button]):not([type=reset]):not([type=image]), select, textarea",
+      function (el) { return !labelled(el) && !hidden(el); }));
+    add("name", all("button, a[href], [role=button]", function (el) { return !named(el) && !hidden(el); }));
+    var last = 0, skipped = [];
+    document.querySelectorAll("h1, h2, h3, h4, h5, h6").forEach(function (h) {
+      var level = +h.tagName[1];
+      if (last && level > last + 1) { skipped.push(h); }
+      last = level;
+    });
+    add("headings", skipped);
+    try {
+      window.callback.goupa11y(JSON.stringify({ page: location.href, issues: issues }));
+    } catch (err) {}
+  }
+  window.addEventListener("load", function () { setTimeout(check, 2000); });
+})();`
+
+// showKeyboardButton adds the on-screen keyboard button to the toolbar.
+func (b *Browsers) showKeyboardButton() {
+	b.HeaderFlex = append(b.HeaderFlex,
+		layout.Rigid(layout.Spacer{Width: 4}.Layout),
+		layout.Rigid(Button{Clickable: &b.Keyboard, Icon: IconKeyboard}.Layout),
+	)
+}
@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bufio"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// adminConfig adds a settings overlay behind a PIN, so technicians can
+// service a kiosk from its touch screen, without a keyboard or SSH.
+type adminConfig struct {
+	Enabled bool `json:"enabled,omitempty"` // Settings button in the toolbar
+}
+
+// adminPINKey is the secure store key of the PIN's hash.
+const adminPINKey = "admin-pin"
+
+// pinIterations is the PBKDF2 work factor for new PIN hashes.
+const pinIterations = 600000
+
+// Wrong PINs in a row before the overlay locks, and for how long.
+const (
+	pinAttempts = 5
+	pinLockout  = 5 * time.Minute
+)
+
+// validPIN checks a new PIN: 4 to 12 digits.
+func validPIN(pin string) error {
+	if len(pin) < 4 || len(pin) > 12 || strings.Trim(pin, "0123456789") != "" {
+		return errors.New(tr("The PIN must be 4 to 12 digits"))
+	}
+	return nil
+}
+
+// hashPIN returns a salted PBKDF2-SHA256 hash of pin.
+func hashPIN(pin string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, pin, salt, pinIterations, 32)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%x$%x", pinIterations, salt, key), nil
+}
+
+// checkPIN reports whether pin matches a hash from hashPIN.
+func checkPIN(hash, pin string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	salt, err1 := hex.DecodeString(parts[2])
+	want, err2 := hex.DecodeString(parts[3])
+	if err1 != nil || err2 != nil || len(want) == 0 {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, pin, salt, iter, len(want))
+	return err == nil && subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// pinLock counts wrong PINs in a row and locks the overlay for pinLockout
+// after pinAttempts of them.
+type pinLock struct {
+	failures int
+	until    time.Time
+}
+
+// wait returns how long the overlay stays locked at now; not locked if
+// it is zero or less.
+func (l *pinLock) wait(now time.Time) time.Duration {
+	return l.until.Sub(now)
+}
+
+// fail records a wrong PIN at now and reports whether it locked the
+// overlay.
+func (l *pinLock) fail(now time.Time) bool {
+	l.failures++
+	if l.failures < pinAttempts {
+		return false
+	}
+	l.failures, l.until = 0, now.Add(pinLockout)
+	return true
+}
+
+// pinPrompt returns the screen and message to open the overlay at, given
+// what the secure store returned for the PIN's hash.
+func pinPrompt(err error) (adminState, string) {
+	switch {
+	case errors.Is(err, errSecretNotFound):
+		// Whoever reaches the screen first must not choose the PIN
+		return adminNoPIN, tr("No admin PIN is set. Run the app with --set-admin-pin to set one.")
+	case err != nil:
+		return adminLocked, err.Error()
+	}
+	return adminLocked, ""
+}
+
+// setAdminPIN reads a new PIN from stdin and stores its hash, for
+// provisioning scripts: echo 4711 | app --set-admin-pin
+func setAdminPIN(cfg *appConfig) error {
+	fmt.Fprint(os.Stderr, tr("New admin PIN: "))
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return err
+	}
+	pin := strings.TrimSpace(line)
+	if err := validPIN(pin); err != nil {
+		return err
+	}
+	hash, err := hashPIN(pin)
+	if err != nil {
+		return err
+	}
+	return secureStore{service: cfg.Name}.set(adminPINKey, []byte(hash))
+}
+
+// saveAppURL sets url in app.json, next to the executable unless app.json
+// was found elsewhere, so the change survives a restart.
+func saveAppURL(u string) error {
+	path := appConfigPath()
+	data := embeddedConfig
+	if path == "" {
+		exePath, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(filepath.Dir(exePath), "app.json")
+	} else {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return err
+		}
+	}
+	cfg := map[string]any{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	cfg["url"] = u
+	out, err := json.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
+
+// adminState is the overlay's current screen.
+type adminState int
+
+const (
+	adminLoading    adminState = iota // Reading the PIN's hash
+	adminLocked                       // Asking for the PIN
+	adminNewPIN                       // Choosing a PIN
+	adminConfirmPIN                   // Repeating it
+	adminMenu                         // Unlocked
+	adminNoPIN                        // No PIN stored; only --set-admin-pin sets the first
+)
+
+// adminPanel is the settings overlay. It replaces the page while open and
+// locks again when closed. Slow work (PBKDF2, the secure store, updates)
+// runs in goroutines that hand their outcome back through done.
+type adminPanel struct {
+	cfg     *appConfig
+	secrets secureStore
+	window  *app.Window
+	actions *pageActions
+	done    chan func()
+
+	open    bool
+	busy    bool
+	state   adminState
+	hash    string // The stored PIN's hash
+	pin     string // Digits entered so far
+	first   string // A new PIN waiting to be repeated
+	message string
+	lock    pinLock
+
+	keys     [10]widget.Clickable
+	erase    widget.Clickable
+	enter    widget.Clickable
+	exit     widget.Clickable
+	update   widget.Clickable
+	newPIN   widget.Clickable
+	close    widget.Clickable
+	saveURL  widget.Clickable
+	keyboard widget.Clickable
+	url      widget.Editor
+	logs     []string // Log files in the config folder
+	logTabs  []widget.Clickable
+	logText  string
+}
+
+// newAdminPanel returns nil unless admin.enabled is set.
+func newAdminPanel(cfg *appConfig, w *app.Window, actions *pageActions) *adminPanel {
+	if !cfg.Admin.Enabled {
+		return nil
+	}
+	return &adminPanel{
+		cfg:     cfg,
+		secrets: secureStore{service: cfg.Name},
+		window:  w,
+		actions: actions,
+		done:    make(chan func(), 4),
+		url:     widget.Editor{SingleLine: true, Submit: true},
+	}
+}
+
+// showSettingsButton adds the button that opens the overlay to the toolbar.
+func (b *Browsers) showSettingsButton() {
+	b.HeaderFlex = append(b.HeaderFlex,
+		layout.Rigid(layout.Spacer{Width: 4}.Layout),
+		layout.Rigid(Button{Clickable: &b.Settings, Icon: IconSettings}.Layout),
+	)
+}
+
+// showing reports whether the overlay is open; nil never is.
+func (p *adminPanel) showing() bool {
+	return p != nil && p.open
+}
+
+// toggle opens the overlay at the PIN prompt, or closes it.
+func (p *adminPanel) toggle() {
+	if p.open {
+		p.hide()
+		return
+	}
+	p.open, p.state, p.message = true, adminLoading, ""
+	p.work(func() func() {
+		hash, err := p.secrets.get(adminPINKey)
+		return func() {
+			p.state, p.message = pinPrompt(err)
+			if err == nil {
+				p.hash = string(hash)
+			}
+		}
+	})
+}
+
+// hide closes and locks the overlay; nil does nothing.
+func (p *adminPanel) hide() {
+	if p == nil {
+		return
+	}
+	p.open, p.pin, p.first, p.logText = false, "", "", ""
+}
+
+// work runs fn off the UI goroutine and applies the function it returns
+// on the next frame.
+func (p *adminPanel) work(fn func() func()) {
+	p.busy = true
+	go func() {
+		p.done <- fn()
+		p.window.Invalidate()
+	}()
+}
+
+// submit acts on the PIN entered on the keypad.
+func (p *adminPanel) submit() {
+	pin := p.pin
+	p.pin = ""
+	switch p.state {
+	case adminLocked:
+		if wait := p.lock.wait(time.Now()); wait > 0 {
+			p.message = tr("Too many wrong PINs; try again in %d min", int(wait.Minutes())+1)
+			return
+		}
+		hash := p.hash
+		p.work(func() func() {
+			ok := checkPIN(hash, pin)
+			return func() {
+				if ok {
+					p.unlock("")
+					return
+				}
+				p.message = tr("Wrong PIN")
+				if p.lock.fail(time.Now()) {
+					p.message = tr("Too many wrong PINs; try again in %d min", int(pinLockout.Minutes()))
+				}
+			}
+		})
+	case adminNewPIN:
+		if err := validPIN(pin); err != nil {
+			p.message = err.Error()
+			return
+		}
+		p.first, p.state, p.message = pin, adminConfirmPIN, tr("Enter the PIN again")
+	case adminConfirmPIN:
+		if pin != p.first {
+			p.first, p.state, p.message = "", adminNewPIN, tr("The PINs did not match; choose an admin PIN")
+			return
+		}
+		p.work(func() func() {
+			hash, err := hashPIN(pin)
+			if err == nil {
+				err = p.secrets.set(adminPINKey, []byte(hash))
+			}
+			return func() {
+				if err != nil {
+					p.state, p.message = adminNewPIN, err.Error()
+					return
+				}
+				p.hash = hash
+				p.unlock(tr("PIN saved"))
+			}
+		})
+	}
+}
+
+// unlock shows the menu.
+func (p *adminPanel) unlock(message string) {
+	p.state, p.message, p.lock.failures = adminMenu, message, 0
+	p.url.SetText(DefaultURL)
+	p.findLogs()
+}
+
+// changeURL makes u the start page, in app.json and in every tab.
+func (p *adminPanel) changeURL(u string) {
+	if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		p.message = tr("URL must start with http:// or https://")
+		return
+	}
+	if err := saveAppURL(u); err != nil {
+		p.message = err.Error()
+		return
+	}
+	DefaultURL = u
+	p.actions.push(pageAction{URL: u})
+	p.message = tr("Start page changed to %s", u)
+}
+
+// installUpdate installs the latest release and restarts into it.
+func (p *adminPanel) installUpdate() {
+	p.message = tr("Checking for updates...")
+	p.work(func() func() {
+		installed, err := selfUpdate(p.cfg)
+		if err == nil && installed {
+			err = restartApp()
+		}
+		return func() {
+			switch {
+			case err != nil:
+				p.message = err.Error()
+			case installed:
+				p.message = tr("Restarting...")
+			default:
+				p.message = tr("Already up to date, or not in this release's rollout")
+			}
+		}
+	})
+}
+
+// findLogs lists the shell's log files.
+func (p *adminPanel) findLogs() {
+	dir, _ := os.UserConfigDir()
+	p.logs, _ = filepath.Glob(filepath.Join(dir, p.cfg.Name, "*.log"))
+	p.logTabs = make([]widget.Clickable, len(p.logs))
+	p.logText = ""
+}
+
+// showLog shows the end of the log at path.
+func (p *adminPanel) showLog(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		p.logText = err.Error()
+		return
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	p.logText = strings.Join(lines[max(len(lines)-30, 0):], "\n")
+}
+
+// layout handles the overlay's buttons and draws it in the page's place.
+func (p *adminPanel) layout(gtx layout.Context) layout.Dimensions {
+	for done := false; !done; {
+		select {
+		case apply := <-p.done:
+			apply()
+			p.busy = false
+		default:
+			done = true
+		}
+	}
+	p.handle(gtx)
+
+	defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
+	paint.Fill(gtx.Ops, Theme.Toolbar)
+	gtx.Constraints.Min = image.Point{}
+	var body []layout.FlexChild
+	switch p.state {
+	case adminLoading:
+	case adminNoPIN:
+		body = []layout.FlexChild{menuButton(&p.close, tr("Close"))}
+	case adminMenu:
+		body = p.menu()
+	default:
+		body = p.keypad()
+	}
+	children := append([]layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return adminText(gtx, 20, tr("Settings"), Theme.Text, text.Middle, 1)
+		}),
+		layout.Rigid(layout.Spacer{Height: 8}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return adminText(gtx, 14, p.message, Theme.Text, text.Middle, 3)
+		}),
+		layout.Rigid(layout.Spacer{Height: 16}.Layout),
+	}, body...)
+	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+	})
+	return layout.Dimensions{Size: gtx.Constraints.Max}
+}
+
+// handle acts on the clicks since the last frame.
+func (p *adminPanel) handle(gtx layout.Context) {
+	for i := range p.keys {
+		if p.keys[i].Clicked(gtx) && len(p.pin) < 12 {
+			p.pin += strconv.Itoa(i)
+		}
+	}
+	if p.erase.Clicked(gtx) && p.pin != "" {
+		p.pin = p.pin[:len(p.pin)-1]
+	}
+	if p.enter.Clicked(gtx) && !p.busy {
+		p.submit()
+	}
+	if p.close.Clicked(gtx) {
+		p.hide()
+	}
+	if p.state != adminMenu {
+		return
+	}
+	if p.exit.Clicked(gtx) {
+		go life.exit(0)
+	}
+	if p.update.Clicked(gtx) && !p.busy {
+		p.installUpdate()
+	}
+	if p.newPIN.Clicked(gtx) {
+		p.state, p.message = adminNewPIN, tr("Choose an admin PIN")
+	}
+	submitted := p.saveURL.Clicked(gtx)
+	for {
+		evt, ok := p.url.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := evt.(widget.SubmitEvent); ok {
+			submitted = true
+		}
+	}
+	if submitted {
+		p.changeURL(strings.TrimSpace(p.url.Text()))
+	}
+	if p.keyboard.Clicked(gtx) {
+		go func() {
+			if err := toggleKeyboard(); err != nil {
+				fmt.Fprintln(os.Stderr, tr("On-screen keyboard: %v", err))
+			}
+		}()
+	}
+	for i := range p.logTabs {
+		if p.logTabs[i].Clicked(gtx) {
+			p.showLog(p.logs[i])
+		}
+	}
+}
+
+// keypad is the PIN display and the digit buttons.
+func (p *adminPanel) keypad() []layout.FlexChild {
+	row := func(keys ...layout.FlexChild) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx, keys...)
+		})
+	}
+	digit := func(i int) layout.FlexChild { return padKey(&p.keys[i], strconv.Itoa(i)) }
+	return []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return adminText(gtx, 28, strings.Repeat("•", len(p.pin))+" ", Theme.Text, text.Middle, 1)
+		}),
+		layout.Rigid(layout.Spacer{Height: 8}.Layout),
+		row(digit(1), digit(2), digit(3)),
+		row(digit(4), digit(5), digit(6)),
+		row(digit(7), digit(8), digit(9)),
+		row(padKey(&p.erase, "⌫"), digit(0), padKey(&p.enter, tr("OK"))),
+		layout.Rigid(layout.Spacer{Height: 8}.Layout),
+		menuButton(&p.close, tr("Cancel")),
+	}
+}
+
+// menu is the unlocked overlay.
+func (p *adminPanel) menu() []layout.FlexChild {
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints = layout.Exact(image.Pt(gtx.Dp(360), gtx.Dp(40)))
+					paint.FillShape(gtx.Ops, Theme.Address, clip.Rect{Max: gtx.Constraints.Max}.Op())
+					return layout.Inset{Left: 8, Right: 8, Top: 10}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						textMaterial := op.Record(gtx.Ops)
+						paint.ColorOp{Color: Theme.Text}.Add(gtx.Ops)
+						tmat := textMaterial.Stop()
+						selectMaterial := op.Record(gtx.Ops)
+						paint.ColorOp{Color: Theme.Selection}.Add(gtx.Ops)
+						smat := selectMaterial.Stop()
+						return p.url.Layout(gtx, GlobalShaper, font.Font{}, gtx.Metric.DpToSp(16), tmat, smat)
+					})
+				}),
+				layout.Rigid(layout.Spacer{Width: 4}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Max.Y = gtx.Dp(40)
+					return Button{Clickable: &p.saveURL, Text: tr("Change URL")}.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: 4}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Max.Y = gtx.Dp(40)
+					return Button{Clickable: &p.keyboard, Icon: IconKeyboard}.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: 16}.Layout),
+		menuButton(&p.update, tr("Install update")),
+		menuButton(&p.newPIN, tr("Change PIN")),
+		menuButton(&p.exit, tr("Exit kiosk")),
+		menuButton(&p.close, tr("Close")),
+	}
+	if len(p.logs) > 0 {
+		tabs := make([]layout.FlexChild, 0, 2*len(p.logs))
+		for i, path := range p.logs {
+			tabs = append(tabs,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Max.Y = gtx.Dp(40)
+					return Button{Clickable: &p.logTabs[i], Text: filepath.Base(path)}.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: 4}.Layout),
+			)
+		}
+		children = append(children,
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{}.Layout(gtx, tabs...)
+			}),
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Max.X = min(gtx.Constraints.Max.X, gtx.Dp(720))
+				return adminText(gtx, 12, p.logText, Theme.Text, text.Start, 30)
+			}),
+		)
+	}
+	return children
+}
+
+// padKey is a keypad button.
+func padKey(c *widget.Clickable, label string) layout.FlexChild {
+	return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints = layout.Exact(image.Pt(gtx.Dp(80), gtx.Dp(64)))
+		return layout.UniformInset(4).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return c.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				paint.FillShape(gtx.Ops, Theme.Button, clip.Rect{Max: gtx.Constraints.Max}.Op())
+				return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return adminText(gtx, 22, label, Theme.ButtonText, text.Middle, 1)
+				})
+			})
+		})
+	})
+}
+
+// menuButton is a full-height button in the overlay's column.
+func menuButton(c *widget.Clickable, label string) layout.FlexChild {
+	return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Max.Y = gtx.Dp(48)
+		return layout.Inset{Bottom: 8}.Layout(gtx, Button{Clickable: c, Text: label}.Layout)
+	})
+}
+
+// adminText draws a label in the overlay.
+func adminText(gtx layout.Context, size unit.Dp, txt string, c color.NRGBA, align text.Alignment, maxLines int) layout.Dimensions {
+	colorMaterial := op.Record(gtx.Ops)
+	paint.ColorOp{Color: c}.Add(gtx.Ops)
+	pcolor := colorMaterial.Stop()
+	return widget.Label{Alignment: align, MaxLines: maxLines}.Layout(gtx, GlobalShaper, font.Font{}, gtx.Metric.DpToSp(size), txt, pcolor)
+}
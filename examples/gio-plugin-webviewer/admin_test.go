@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPINHash(t *testing.T) {
+	hash, err := hashPIN("4711")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := hashPIN("4711"); again == hash {
+		t.Error("same PIN hashed twice to the same string; salt not random")
+	}
+	parts := strings.Split(hash, "$")
+	tests := []struct {
+		name, hash, pin string
+		want            bool
+	}{
+		{"right PIN", hash, "4711", true},
+		{"wrong PIN", hash, "4712", false},
+		{"empty PIN", hash, "", false},
+		{"fewer iterations", strings.Join([]string{parts[0], "1", parts[2], parts[3]}, "$"), "4711", false},
+		{"other salt", strings.Join([]string{parts[0], parts[1], strings.Repeat("00", 16), parts[3]}, "$"), "4711", false},
+		{"zero iterations", strings.Join([]string{parts[0], "0", parts[2], parts[3]}, "$"), "4711", false},
+		{"other scheme", "sha256$600000$" + parts[2] + "$" + parts[3], "4711", false},
+		{"no key", strings.Join([]string{parts[0], parts[1], parts[2], ""}, "$"), "4711", false},
+		{"plain text", "4711", "4711", false},
+		{"empty hash", "", "", false},
+	}
+	for _, tt := range tests {
+		if got := checkPIN(tt.hash, tt.pin); got != tt.want {
+			t.Errorf("%s: checkPIN = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPINLock(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	var l pinLock
+	for i := 1; i < pinAttempts; i++ {
+		if l.fail(now) || l.wait(now) > 0 {
+			t.Fatalf("locked after %d wrong PINs", i)
+		}
+	}
+	if !l.fail(now) {
+		t.Fatalf("not locked after %d wrong PINs", pinAttempts)
+	}
+
+	tests := []struct {
+		after  time.Duration
+		locked bool
+	}{
+		{0, true},
+		{pinLockout - time.Second, true},
+		{pinLockout, false},
+		{pinLockout + time.Minute, false},
+	}
+	for _, tt := range tests {
+		if got := l.wait(now.Add(tt.after)) > 0; got != tt.locked {
+			t.Errorf("%v after locking: locked = %v, want %v", tt.after, got, tt.locked)
+		}
+	}
+
+	// The count starts over after a lockout
+	later := now.Add(pinLockout)
+	for i := 1; i < pinAttempts; i++ {
+		if l.fail(later) {
+			t.Fatalf("locked again after %d wrong PINs", i)
+		}
+	}
+}
+
+func TestPINPrompt(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		state adminState
+	}{
+		{"stored", nil, adminLocked},
+		{"not provisioned", errSecretNotFound, adminNoPIN},
+		{"not provisioned, wrapped", errors.Join(errors.New("keychain"), errSecretNotFound), adminNoPIN},
+		{"store failed", errors.New("secret-tool not found"), adminLocked},
+	}
+	for _, tt := range tests {
+		if state, _ := pinPrompt(tt.err); state != tt.state {
+			t.Errorf("%s: state = %v, want %v", tt.name, state, tt.state)
+		}
+	}
+
+	// Without a PIN the keypad does nothing, so it cannot choose one
+	p := &adminPanel{state: adminNoPIN, pin: "4711"}
+	p.submit()
+	if p.state != adminNoPIN || p.hash != "" || p.first != "" || p.busy {
+		t.Errorf("submit without a PIN: state %v, hash %q, first %q, busy %v", p.state, p.hash, p.first, p.busy)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework LocalAuthentication -framework Foundation
+
+#import <LocalAuthentication/LocalAuthentication.h>
+#include <stdlib.h>
+#include <string.h>
+
+// canAuthenticate returns NULL if the policy can be evaluated, or why not.
+static char *canAuthenticate(int passcode) {
+	@autoreleasepool {
+		LAContext *ctx = [[LAContext alloc] init];
+		LAPolicy policy = passcode ? LAPolicyDeviceOwnerAuthentication : LAPolicyDeviceOwnerAuthenticationWithBiometrics;
+		NSError *err = nil;
+		BOOL ok = [ctx canEvaluatePolicy:policy error:&err];
+		[ctx release];
+		if (ok) {
+			return NULL;
+		}
+		return strdup([[err localizedDescription] UTF8String]);
+	}
+}
+
+// authenticate shows the Touch ID or Face ID prompt and waits for it.
+// It returns 1 if the user was confirmed, 0 if they cancelled or failed,
+// and -1 with *msg set on other errors.
+static int authenticate(const char *reason, int passcode, char **msg) {
+	@autoreleasepool {
+		LAContext *ctx = [[LAContext alloc] init];
+		LAPolicy policy = passcode ? LAPolicyDeviceOwnerAuthentication : LAPolicyDeviceOwnerAuthenticationWithBiometrics;
+		dispatch_semaphore_t done = dispatch_semaphore_create(0);
+		__block int result = 0;
+		__block char *errMsg = NULL;
+		[ctx evaluatePolicy:policy localizedReason:[NSString stringWithUTF8String:reason] reply:^(BOOL ok, NSError *err) {
+			if (ok) {
+				result = 1;
+			} else if ([err code] != LAErrorUserCancel && [err code] != LAErrorSystemCancel &&
+				[err code] != LAErrorAppCancel && [err code] != LAErrorAuthenticationFailed &&
+				[err code] != LAErrorUserFallback) {
+				result = -1;
+				errMsg = strdup([[err localizedDescription] UTF8String]);
+			}
+			dispatch_semaphore_signal(done);
+		}];
+		dispatch_semaphore_wait(done, DISPATCH_TIME_FOREVER);
+		dispatch_release(done);
+		[ctx release];
+		*msg = errMsg;
+		return result;
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// canAuthenticate reports whether Touch ID, Face ID or, with passcode,
+// the login password can confirm the user.
+func canAuthenticate(passcode bool) error {
+	if msg := C.canAuthenticate(cbool(passcode)); msg != nil {
+		defer C.free(unsafe.Pointer(msg))
+		return errors.New(C.GoString(msg))
+	}
+	return nil
+}
+
+// authenticate asks for Touch ID or Face ID with LocalAuthentication.
+// With passcode the system offers the password when biometrics fail or
+// are not enrolled.
+func authenticate(reason string, passcode bool) (bool, error) {
+	cReason := C.CString(reason)
+	defer C.free(unsafe.Pointer(cReason))
+	var msg *C.char
+	switch C.authenticate(cReason, cbool(passcode), &msg) {
+	case 1:
+		return true, nil
+	case 0:
+		return false, nil
+	}
+	defer C.free(unsafe.Pointer(msg))
+	return false, errors.New(C.GoString(msg))
+}
+
+func cbool(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
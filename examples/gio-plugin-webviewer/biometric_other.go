@@ -0,0 +1,17 @@
+//go:build !darwin && !windows
+
+package main
+
+import "errors"
+
+// Linux has no common biometric API, and Android's BiometricPrompt needs
+// Java the shell does not ship.
+var errNoBiometrics = errors.New("biometric authentication is not supported on this platform")
+
+func canAuthenticate(passcode bool) error {
+	return errNoBiometrics
+}
+
+func authenticate(reason string, passcode bool) (bool, error) {
+	return false, errNoBiometrics
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// helloScript awaits UserConsentVerifier from Windows PowerShell, which
+// can call WinRT without cgo. It prints "Available" for the check, or the
+// UserConsentVerificationResult ("Verified", "Canceled", ...) after the
+// prompt. The reason comes from the environment so it needs no quoting.
+const helloScript = `
+Add-Type -AssemblyName System.Runtime.WindowsRuntime
+$asTask = [System.WindowsRuntimeSystemExtensions].GetMethods() | Where-Object {
+  $_.Name -eq 'AsTask' -and $_.GetParameters().Count -eq 1 -and $_.GetParameters()[0].ParameterType.Name -eq 'IAsyncOperation` + "`" + `1'
+} | Select-Object -First 1
+$verifier = [Windows.Security.Credentials.UI.UserConsentVerifier, Windows.Security.Credentials.UI, ContentType = WindowsRuntime]
+function Await($op, $type) {
+  $task = $asTask.MakeGenericMethod($type).Invoke($null, @($op))
+  [void]$task.Wait(-1)
+  $task.Result
+}
+$available = Await ($verifier::CheckAvailabilityAsync()) ([Windows.Security.Credentials.UI.UserConsentVerifierAvailability])
+if ([string]::IsNullOrEmpty($env:GOUP_HELLO_REASON) -or $available -ne 'Available') { Write-Output $available; exit }
+Await ($verifier::RequestVerificationAsync($env:GOUP_HELLO_REASON)) ([Windows.Security.Credentials.UI.UserConsentVerificationResult])
+`
+
+// hello runs helloScript; an empty reason only checks availability.
+func hello(reason string) (string, error) {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", helloScript)
+	cmd.Env = append(os.Environ(), "GOUP_HELLO_REASON="+reason)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("windows hello failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// canAuthenticate reports whether Windows Hello is set up. Hello always
+// offers the PIN, so passcode makes no difference.
+func canAuthenticate(passcode bool) error {
+	status, err := hello("")
+	if err != nil {
+		return err
+	}
+	if status != "Available" {
+		return fmt.Errorf("windows hello is not available (%s)", status)
+	}
+	return nil
+}
+
+// authenticate asks for a face, fingerprint or PIN with Windows Hello.
+func authenticate(reason string, passcode bool) (bool, error) {
+	status, err := hello(reason)
+	if err != nil {
+		return false, err
+	}
+	switch status {
+	case "Verified":
+		return true, nil
+	case "Canceled", "RetriesExhausted":
+		return false, nil
+	}
+	return false, fmt.Errorf("windows hello: %s", status)
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gioui.org/app"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/transfer"
+	"gioui.org/layout"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+
+// bridgeConfig gives the app's own pages what webviews usually withhold:
+// the native clipboard (navigator.clipboard needs a secure context and a
+// user gesture, and WebKitGTK lacks it), files dropped onto the window,
+// the system's open and save dialogs, QR and barcode scanning with the
+// camera, biometric confirmation of the user, the system's credential
+// store, notifications and switching profiles. All are off by default because any page on a
+// trusted origin can use them.
+type bridgeConfig struct {
+	Clipboard     bool     `json:"clipboard,omitempty"`     // window.goup.clipboard.readText/writeText
+	Files         bool     `json:"files,omitempty"`         // "goup-drop" events for files dropped on the page
+	Dialogs       bool     `json:"dialogs,omitempty"`       // window.goup.openFile/saveFile
+	Scan          bool     `json:"scan,omitempty"`          // window.goup.scan: QR and barcodes from the camera
+	Biometrics    bool     `json:"biometrics,omitempty"`    // window.goup.authenticate: Touch ID, Face ID, Windows Hello
+	SecureStore   bool     `json:"secureStore,omitempty"`   // window.goup.secureStore: tokens in the Keychain, DPAPI or Secret Service
+	Notifications bool     `json:"notifications,omitempty"` // window.goup.notify: system notifications that open a page when clicked
+	Profiles      bool     `json:"profiles,omitempty"`      // window.goup.profiles: list and switch the profiles app.json names
+	Origins       []string `json:"origins,omitempty"`       // Trusted origins besides the app URL's
+}
+
+// Permission reasons for enabled features app.json gives none for, as
+// 'goup-util bundle' uses.
+const (
+	scanCameraReason    = "To scan QR codes and barcodes"
+	biometricsReason    = "To confirm it's you"
+	notificationsReason = "To let you know when something needs your attention"
+)
+
+// declareBridgePermissions adds the permissions of enabled bridge
+// features, so the first-run page mentions them.
+func declareBridgePermissions(cfg *appConfig) {
+	declare := func(enabled bool, name, reason string) {
+		if !enabled || cfg.Permissions[name] != "" {
+			return
+		}
+		if cfg.Permissions == nil {
+			cfg.Permissions = map[string]string{}
+		}
+		cfg.Permissions[name] = reason
+	}
+	declare(cfg.Bridge.Scan, "camera", scanCameraReason)
+	declare(cfg.Bridge.Biometrics, "biometrics", biometricsReason)
+	declare(cfg.Bridge.Notifications, "notifications", notificationsReason)
+}
+
+// bridgeCallback is the name pages call as window.callback.goup(message).
+const bridgeCallback = "goup"
+
+// bridgeRequest is a message from the page script.
+type bridgeRequest struct {
+	ID   int    `json:"id"`
+	Op   string `json:"op"`             // "clipboard.read", "clipboard.write", "openFile", "saveFile", "scan", "authenticate", "authenticate.available", "secureStore.get", ".set", ".delete", "notify", "profiles.list" or "profiles.switch"
+	Text string `json:"text,omitempty"` // Clipboard text, the reason shown by authenticate, or a secret
+
+	Accept []string `json:"accept,omitempty"` // Open dialog filters: ".png", "image/png" or "image/*"
+	Name   string   `json:"name,omitempty"`   // Suggested name in the save dialog, the secret's key or a profile
+	Type   string   `json:"type,omitempty"`   // MIME type of Data
+	Data   []byte   `json:"data,omitempty"`   // Contents to save, or a camera frame to scan (base64 in JSON)
+
+	Passcode bool `json:"passcode,omitempty"` // Let authenticate fall back to the device password or PIN
+
+	Title string `json:"title,omitempty"` // Notification title
+	Body  string `json:"body,omitempty"`  // Notification text
+	URL   string `json:"url,omitempty"`   // Page the notification opens when clicked
+}
+
+// bridgeFile describes a file chosen in the open dialog to the page.
+type bridgeFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Type string `json:"type"`
+	URL  string `json:"url"`            // Served by the shell, supports Range requests
+	Path string `json:"path,omitempty"` // Empty when the OS only handed over the contents
+}
+
+// bridgeCall is a request waiting for its reply.
+type bridgeCall struct {
+	view event.Tag
+	id   int
+}
+
+// bridgeResult is a reply worked out off the UI goroutine.
+type bridgeResult struct {
+	call  bridgeCall
+	value any
+	err   error
+}
+
+// bridge answers the page script's requests on the UI goroutine.
+// Slow work, such as copying a file, runs in goroutines that hand their
+// replies back through results.
+type bridge struct {
+	cfg     bridgeConfig
+	appName string
+	origins []string
+	window  *app.Window
+	reads   []bridgeCall // Clipboard reads waiting for Gio's DataEvent
+	dialog  *fileDialog  // The open or save dialog, if one is showing
+	results chan bridgeResult
+	clicks  chan notificationClick
+	secrets secureStore
+	profile *profileSet // nil without profiles
+
+	mu    sync.Mutex
+	files *fileServer // Started on the first file
+}
+
+// newBridge returns nil unless app.json enables part of the bridge.
+// Secrets are kept under appName, apart for each of the profiles.
+func newBridge(cfg bridgeConfig, appName, appURL string, w *app.Window, profiles *profileSet) *bridge {
+	if !cfg.Clipboard && !cfg.Files && !cfg.Dialogs && !cfg.Scan && !cfg.Biometrics && !cfg.SecureStore && !cfg.Notifications && !cfg.Profiles {
+		return nil
+	}
+	br := &bridge{
+		cfg:     cfg,
+		appName: appName,
+		window:  w,
+		results: make(chan bridgeResult, 8),
+		clicks:  make(chan notificationClick, 8),
+		secrets: secureStore{service: appName},
+		profile: profiles,
+	}
+	for _, o := range append([]string{appURL}, cfg.Origins...) {
+		if origin := originOf(o); origin != "" {
+			br.origins = append(br.origins, origin)
+		}
+	}
+	return br
+}
+
+// originOf returns scheme://host[:port] of rawURL, or "" if it has none.
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// trusted reports whether a page at pageURL may use the bridge.
+func (br *bridge) trusted(pageURL string) bool {
+	origin := originOf(pageURL)
+	for _, o := range br.origins {
+		if origin != "" && origin == o {
+			return true
+		}
+	}
+	return false
+}
+
+// prepare installs the page script into a tab and starts listening for
+// its messages.
+func (br *bridge) prepare(gtx layout.Context, view event.Tag) {
+	if br == nil {
+		return
+	}
+	gioplugins.Execute(gtx, giowebview.MessageReceiverCmd{View: view, Tag: view, Name: bridgeCallback})
+	gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: view, Script: br.script()})
+}
+
+// update delivers clipboard contents, dialog results, finished work and
+// notification clicks to the pages. Call it once per frame.
+func (br *bridge) update(gtx layout.Context) {
+	if br == nil {
+		return
+	}
+	br.updateDialog(gtx)
+	for done := false; !done; {
+		select {
+		case r := <-br.results:
+			if br.dialog != nil && br.dialog.call == r.call {
+				br.dialog = nil
+			}
+			br.reply(gtx, r.call, r.value, r.err)
+		case c := <-br.clicks:
+			br.clicked(gtx, c)
+		default:
+			done = true
+		}
+	}
+	event.Op(gtx.Ops, br)
+	for {
+		e, ok := gtx.Event(transfer.TargetFilter{Target: br, Type: "application/text"})
+		if !ok {
+			break
+		}
+		data, ok := e.(transfer.DataEvent)
+		if !ok {
+			continue
+		}
+		text, err := readAll(data.Open())
+		for _, c := range br.reads {
+			br.reply(gtx, c, text, err)
+		}
+		br.reads = nil
+	}
+}
+
+func readAll(r io.ReadCloser) (string, error) {
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	return string(b), err
+}
+
+// message handles a request from the page at pageURL in view.
+func (br *bridge) message(gtx layout.Context, view event.Tag, pageURL, message string) {
+	var req bridgeRequest
+	if err := json.Unmarshal([]byte(message), &req); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Warning: bridge: %v", err))
+		return
+	}
+	call := bridgeCall{view: view, id: req.ID}
+	if !br.trusted(pageURL) {
+		br.reply(gtx, call, nil, errors.New("not available on "+originOf(pageURL)))
+		return
+	}
+	switch {
+	case req.Op == "clipboard.write" && br.cfg.Clipboard:
+		gtx.Execute(clipboard.WriteCmd{Type: "application/text", Data: io.NopCloser(strings.NewReader(req.Text))})
+		br.reply(gtx, call, true, nil)
+	case req.Op == "clipboard.read" && br.cfg.Clipboard:
+		br.reads = append(br.reads, call)
+		gtx.Execute(clipboard.ReadCmd{Tag: br})
+	case (req.Op == "openFile" || req.Op == "saveFile") && br.cfg.Dialogs:
+		br.openDialog(gtx, call, req)
+	case req.Op == "scan" && br.cfg.Scan:
+		go br.complete(call, func() (any, error) { return decodeFrame(req.Data) })
+	case req.Op == "authenticate" && br.cfg.Biometrics:
+		reason := req.Text
+		if reason == "" {
+			reason = tr("Confirm it's you")
+		}
+		go br.complete(call, func() (any, error) { return authenticate(reason, req.Passcode) })
+	case req.Op == "authenticate.available" && br.cfg.Biometrics:
+		go br.complete(call, func() (any, error) { return canAuthenticate(req.Passcode) == nil, nil })
+	case req.Op == "notify" && br.cfg.Notifications:
+		go br.complete(call, func() (any, error) { return true, br.notify(view, req) })
+	case strings.HasPrefix(req.Op, "secureStore.") && br.cfg.SecureStore:
+		go br.complete(call, func() (any, error) { return br.secret(req) })
+	case req.Op == "profiles.list" && br.cfg.Profiles && br.profile != nil:
+		br.reply(gtx, call, map[string]any{"names": br.profile.names, "current": br.profile.name()}, nil)
+	case req.Op == "profiles.switch" && br.cfg.Profiles && br.profile != nil:
+		go br.complete(call, func() (any, error) { return true, br.profile.switchTo(req.Name) })
+	default:
+		br.reply(gtx, call, nil, errors.New(req.Op+" is not enabled"))
+	}
+}
+
+// complete runs work off the UI goroutine and queues its reply.
+func (br *bridge) complete(call bridgeCall, work func() (any, error)) {
+	value, err := work()
+	br.results <- bridgeResult{call: call, value: value, err: err}
+	br.window.Invalidate()
+}
+
+// secret answers a secureStore request. Page keys get their own prefix
+// so pages cannot reach secrets the shell keeps for itself, nor those of
+// another profile.
+func (br *bridge) secret(req bridgeRequest) (any, error) {
+	if req.Name == "" {
+		return nil, errors.New("secureStore: empty key")
+	}
+	key := "web:" + req.Name
+	if profile := br.profile.name(); profile != "" {
+		key = "web:" + profile + ":" + req.Name
+	}
+	switch req.Op {
+	case "secureStore.get":
+		secret, err := br.secrets.get(key)
+		if errors.Is(err, errSecretNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return string(secret), nil
+	case "secureStore.set":
+		return true, br.secrets.set(key, []byte(req.Text))
+	case "secureStore.delete":
+		return true, br.secrets.delete(key)
+	}
+	return nil, errors.New(req.Op + " is not enabled")
+}
+
+// close stops the file server, letting downloads in progress finish
+// until ctx expires.
+func (br *bridge) close(ctx context.Context) error {
+	br.mu.Lock()
+	files := br.files
+	br.mu.Unlock()
+	if files == nil {
+		return nil
+	}
+	return files.srv.Shutdown(ctx)
+}
+
+// serveFile adds path to the file server, starting it if needed. Safe to
+// call from any goroutine.
+func (br *bridge) serveFile(path, name string) (bridgeFile, error) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if br.files == nil {
+		s, err := startFileServer()
+		if err != nil {
+			return bridgeFile{}, err
+		}
+		br.files = s
+	}
+	return br.files.add(path, name)
+}
+
+// reply resolves or rejects the page's promise for call.
+func (br *bridge) reply(gtx layout.Context, call bridgeCall, result any, err error) {
+	value, _ := json.Marshal(result)
+	var msg []byte
+	if err != nil {
+		msg, _ = json.Marshal(err.Error())
+	} else {
+		msg = []byte("null")
+	}
+	gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{
+		View:   call.view,
+		Script: fmt.Sprintf("window.goup && window.goup._reply && window.goup._reply(%d, %s, %s);", call.id, value, msg),
+	})
+}
+
+// script returns the JavaScript defining window.goup in trusted pages.
+// Drops the page handles itself (by calling preventDefault) are left
+// alone; others would navigate the webview to the file, so they are
+// turned into a "goup-drop" event instead, carrying blob URLs of the
+// page's own File objects. The shell never serves a path the page names:
+// any page on a trusted origin could ask for ~/.ssh that way.
+func (br *bridge) script() string {
+	origins, _ := json.Marshal(br.origins)
+	scan, notify := "", ""
+	if br.cfg.Scan {
+		scan = scanScript
+	}
+	if br.cfg.Notifications {
+		notify = notifyScript
+	}
+	return fmt.Sprintf(`(function () {
+  if (window.goup && window.goup._reply) { return; }
+  if (%s.indexOf(location.origin.toLowerCase()) < 0) { return; }
+  var goup = window.goup = window.goup || {};
+  var pending = {}, next = 1;
+  function call(op, args) {
+    return new Promise(function (resolve, reject) {
+      var id = next++;
+      pending[id] = { resolve: resolve, reject: reject };
+      args = args || {};
+      args.id = id;
+      args.op = op;
+      window.callback.%s(JSON.stringify(args));
+    });
+  }
+  goup._reply = function (id, result, error) {
+    var p = pending[id];
+    if (!p) { return; }
+    delete pending[id];
+    if (error) { p.reject(new Error(error)); } else { p.resolve(result); }
+  };
+  function readers(f) {
+    if (f) {
+      f.text = function () { return fetch(f.url).then(function (r) { return r.text(); }); };
+      f.blob = function () { return fetch(f.url).then(function (r) { return r.blob(); }); };
+    }
+    return f;
+  }
+  function base64(data) {
+    function encode(bytes) {
+      var s = "";
+      for (var i = 0; i < bytes.length; i += 0x8000) {
+        s += String.fromCharCode.apply(null, bytes.subarray(i, i + 0x8000));
+      }
+      return btoa(s);
+    }
+    if (typeof data === "string") { return Promise.resolve(encode(new TextEncoder().encode(data))); }
+    if (data instanceof Blob) { return data.arrayBuffer().then(function (b) { return encode(new Uint8Array(b)); }); }
+    if (data instanceof ArrayBuffer) { return Promise.resolve(encode(new Uint8Array(data))); }
+    if (ArrayBuffer.isView(data)) { return Promise.resolve(encode(new Uint8Array(data.buffer, data.byteOffset, data.byteLength))); }
+    return Promise.reject(new TypeError("goup.saveFile: data must be a string, Blob or buffer"));
+  }
+  if (%t) {
+    goup.clipboard = {
+      readText: function () { return call("clipboard.read"); },
+      writeText: function (text) { return call("clipboard.write", { text: String(text) }); }
+    };
+  }
+  if (%t) {
+    goup.openFile = function (options) {
+      return call("openFile", { accept: (options && options.accept) || [] }).then(readers);
+    };
+    goup.saveFile = function (name, data, options) {
+      var type = (options && options.type) || (data && data.type) || "";
+      return base64(data).then(function (b) {
+        return call("saveFile", { name: String(name || ""), type: type, data: b });
+      });
+    };
+  }
+%s
+  if (%t) {
+    goup.authenticate = function (reason, options) {
+      return call("authenticate", { text: String(reason || ""), passcode: !(options && options.passcode === false) });
+    };
+    goup.authenticate.available = function (options) {
+      return call("authenticate.available", { passcode: !(options && options.passcode === false) });
+    };
+  }
+  if (%t) {
+    goup.secureStore = {
+      get: function (key) { return call("secureStore.get", { name: String(key) }); },
+      set: function (key, value) { return call("secureStore.set", { name: String(key), text: String(value) }); },
+      delete: function (key) { return call("secureStore.delete", { name: String(key) }); }
+    };
+  }
+  if (%t) {
+    goup.profiles = {
+      list: function () { return call("profiles.list"); },
+      switch: function (name) { return call("profiles.switch", { name: String(name) }); }
+    };
+  }
+%s
+  if (!%t) { return; }
+  function hasFiles(e) {
+    return e.dataTransfer && Array.prototype.indexOf.call(e.dataTransfer.types, "Files") >= 0;
+  }
+  function fire(files) {
+    window.dispatchEvent(new CustomEvent("goup-drop", { detail: { files: files.map(readers) } }));
+  }
+  window.addEventListener("dragover", function (e) {
+    if (hasFiles(e)) { e.preventDefault(); }
+  });
+  window.addEventListener("drop", function (e) {
+    if (e.defaultPrevented || !hasFiles(e)) { return; }
+    e.preventDefault();
+    var files = Array.prototype.slice.call(e.dataTransfer.files);
+    fire(files.map(function (f) {
+      return { name: f.name, size: f.size, type: f.type, url: URL.createObjectURL(f), file: f };
+    }));
+  });
+})();`, origins, bridgeCallback, br.cfg.Clipboard, br.cfg.Dialogs, scan, br.cfg.Biometrics, br.cfg.SecureStore, br.cfg.Profiles, notify, br.cfg.Files)
+}
+
+// fileServer serves the files chosen in the open dialog on the loopback
+// interface. Each file gets an unguessable URL; nothing else is reachable.
+type fileServer struct {
+	srv   *http.Server
+	base  string
+	mu    sync.Mutex
+	paths map[string]string // token -> path
+}
+
+func startFileServer() (*fileServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start file server: %w", err)
+	}
+	s := &fileServer{base: "http://" + ln.Addr().String(), paths: map[string]string{}}
+	s.srv = &http.Server{Handler: s}
+	go s.srv.Serve(ln)
+	return s, nil
+}
+
+// add makes path downloadable and describes it, as name if set.
+func (s *fileServer) add(path, name string) (bridgeFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return bridgeFile{}, err
+	}
+	if info.IsDir() {
+		return bridgeFile{}, fmt.Errorf("%s is a folder", filepath.Base(path))
+	}
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return bridgeFile{}, err
+	}
+	key := hex.EncodeToString(token)
+	s.mu.Lock()
+	s.paths[key] = path
+	s.mu.Unlock()
+
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	typ := mime.TypeByExtension(filepath.Ext(name))
+	if typ == "" {
+		typ = "application/octet-stream"
+	}
+	return bridgeFile{
+		Name: name,
+		Size: info.Size(),
+		Type: typ,
+		URL:  s.base + "/files/" + key + "/" + url.PathEscape(name),
+		Path: path,
+	}, nil
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Range")
+	w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Range")
+	if r.Method == http.MethodOptions {
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	key, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/files/"), "/")
+	s.mu.Lock()
+	path, ok := s.paths[key]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, filepath.Base(path), info.ModTime(), f)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileServer(t *testing.T) {
+	chosen := filepath.Join(t.TempDir(), "notes.md")
+	os.WriteFile(chosen, []byte("# Notes"), 0644)
+	secret := filepath.Join(t.TempDir(), "id_ed25519")
+	os.WriteFile(secret, []byte("private key"), 0600)
+
+	s, err := startFileServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.srv.Close()
+	f, err := s.add(chosen, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(u string) (int, string) {
+		resp, err := http.Get(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if code, body := get(f.URL); code != http.StatusOK || body != "# Notes" {
+		t.Errorf("chosen file: %d %q", code, body)
+	}
+
+	// Only the URLs add hands out reach a file; a path the page names never does
+	key := strings.Split(strings.TrimPrefix(f.URL, s.base+"/files/"), "/")[0]
+	for _, path := range []string{
+		"/files/" + strings.Repeat("0", len(key)) + "/id_ed25519",
+		"/files/" + secret,
+		"/files/file://" + secret,
+		secret,
+		"/etc/passwd",
+	} {
+		if code, body := get(s.base + path); code != http.StatusNotFound || strings.Contains(body, "private key") {
+			t.Errorf("GET %s = %d %q, want 404", path, code, body)
+		}
+	}
+	// The name after the key is only a label
+	if _, body := get(s.base + "/files/" + key + "/../../" + secret); body != "# Notes" {
+		t.Errorf("path after the key reached %q", body)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Crash-loop rollback, inlined from goup-util's pkg/updater. selfUpdate
+// keeps the running binary as <exe>.backup; the next runs are watched, and
+// if the update crashes Crashes times within Minutes of being installed the
+// backup is put back and the app restarts on the previous version.
+
+// rollbackConfig sets what counts as a crash loop after an update.
+type rollbackConfig struct {
+	Crashes int `json:"crashes,omitempty"` // Crashes that trigger a rollback (default 3)
+	Minutes int `json:"minutes,omitempty"` // Minutes after the update that crashes count (default 10)
+}
+
+// guardState is saved next to the executable. Running is set while the app
+// runs and cleared on a clean exit, so finding it set means a crash.
+type guardState struct {
+	Version   string      `json:"version"`
+	Installed time.Time   `json:"installed"`
+	Running   bool        `json:"running"`
+	Crashes   []time.Time `json:"crashes,omitempty"`
+}
+
+// backupForUpdate copies the executable aside before an update replaces it.
+func backupForUpdate(exePath, version string) error {
+	data, err := os.ReadFile(exePath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(exePath+".backup", data, 0755); err != nil {
+		return err
+	}
+	return saveGuardState(exePath, &guardState{Version: version, Installed: time.Now()})
+}
+
+func saveGuardState(exePath string, state *guardState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(exePath+".update.json", data, 0644)
+}
+
+// startCrashGuard counts a crash of the previous run and rolls back once
+// the update is in a crash loop, restarting on the previous binary. It
+// returns the function to call on a clean exit.
+func startCrashGuard(cfg *appConfig) (stop func()) {
+	stop = func() {}
+	exePath, err := os.Executable()
+	if err != nil {
+		return stop
+	}
+	data, err := os.ReadFile(exePath + ".update.json")
+	if err != nil {
+		return stop
+	}
+	var state guardState
+	if json.Unmarshal(data, &state) != nil {
+		os.Remove(exePath + ".update.json")
+		return stop
+	}
+	limit, window := cfg.Update.Rollback.Crashes, time.Duration(cfg.Update.Rollback.Minutes)*time.Minute
+	if limit <= 0 {
+		limit = 3
+	}
+	if window <= 0 {
+		window = 10 * time.Minute
+	}
+	if time.Since(state.Installed) > window {
+		os.Remove(exePath + ".update.json")
+		return stop
+	}
+	if state.Running {
+		state.Crashes = append(state.Crashes, time.Now())
+	}
+	if len(state.Crashes) >= limit {
+		if err := rollbackUpdate(exePath); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Rollback failed: %v", err))
+			return stop
+		}
+		os.Remove(exePath + ".update.json")
+		fmt.Fprintln(os.Stderr, tr("%s crashed %d times after updating; rolled back to the previous version", state.Version, len(state.Crashes)))
+		if cfg.Update.URL != "" {
+			reportRollback(cfg.Update.URL, state)
+		}
+		cmd := exec.Command(exePath, os.Args[1:]...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		cmd.Start()
+		os.Exit(0)
+	}
+	state.Running = true
+	saveGuardState(exePath, &state)
+	return func() {
+		state.Running = false
+		saveGuardState(exePath, &state)
+	}
+}
+
+// rollbackUpdate renames the running update to <exe>.failed (allowed on
+// Windows, unlike overwriting it) and puts the backup in its place.
+func rollbackUpdate(exePath string) error {
+	if _, err := os.Stat(exePath + ".backup"); err != nil {
+		return fmt.Errorf("no backup: %w", err)
+	}
+	os.Remove(exePath + ".failed")
+	if err := os.Rename(exePath, exePath+".failed"); err != nil {
+		return err
+	}
+	if err := os.Rename(exePath+".backup", exePath); err != nil {
+		os.Rename(exePath+".failed", exePath)
+		return err
+	}
+	return nil
+}
+
+// reportRollback tells the update server, which counts it in /metrics.
+func reportRollback(serverURL string, state guardState) {
+	body, _ := json.Marshal(map[string]any{
+		"type":       "rollback",
+		"version":    state.Version,
+		"machine_id": machineID(),
+		"crashes":    len(state.Crashes),
+	})
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(strings.TrimSuffix(serverURL, "/")+"/events", "application/json", bytes.NewReader(body))
+	if err == nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
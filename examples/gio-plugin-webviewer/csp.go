@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gioui.org/io/event"
+	"gioui.org/layout"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+
+// cspConfig adds a Content Security Policy to the site's pages, for sites
+// that send none ('goup-util audit security' shows which), as defense in
+// depth on kiosks. The policy goes in as a <meta> tag when the page
+// starts, so it covers what the page loads from then on; a <meta> policy
+// cannot set frame-ancestors, report-uri or report-only.
+type cspConfig struct {
+	Policy  string   `json:"policy,omitempty"`  // e.g. "default-src 'self'; img-src 'self' data:"
+	Origins []string `json:"origins,omitempty"` // Origins besides the app URL's that get the policy
+	Log     bool     `json:"log,omitempty"`     // Record violations of any policy to csp.log
+}
+
+const cspCallback = "goupcsp" // window.callback.goupcsp(violation)
+
+// cspViolation is a securitypolicyviolation event from a page.
+type cspViolation struct {
+	Page      string `json:"page"`
+	Directive string `json:"directive"`
+	Blocked   string `json:"blocked"`
+	Source    string `json:"source,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Report    bool   `json:"report,omitempty"` // Reported by a report-only policy, not blocked
+}
+
+// contentPolicy installs the policy and collects its violations.
+type contentPolicy struct {
+	cfg     cspConfig
+	origins []string
+	logPath string
+}
+
+// newContentPolicy returns nil unless app.json sets a csp policy or log.
+func newContentPolicy(cfg *appConfig) *contentPolicy {
+	if cfg.CSP.Policy == "" && !cfg.CSP.Log {
+		return nil
+	}
+	c := &contentPolicy{cfg: cfg.CSP}
+	for _, o := range append([]string{cfg.URL}, cfg.CSP.Origins...) {
+		if origin := originOf(o); origin != "" {
+			c.origins = append(c.origins, origin)
+		}
+	}
+	if dir, err := os.UserConfigDir(); err == nil && cfg.CSP.Log {
+		c.logPath = filepath.Join(dir, cfg.Name, "csp.log")
+	}
+	return c
+}
+
+// prepare installs the policy into a tab; nil does nothing.
+func (c *contentPolicy) prepare(gtx layout.Context, view event.Tag) {
+	if c == nil {
+		return
+	}
+	if c.cfg.Log {
+		gioplugins.Execute(gtx, giowebview.MessageReceiverCmd{View: view, Tag: c, Name: cspCallback})
+	}
+	gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: view, Script: c.script()})
+}
+
+// update logs the violations the pages reported. Call it once per frame.
+func (c *contentPolicy) update(gtx layout.Context) {
+	if c == nil {
+		return
+	}
+	for {
+		evt, ok := gioplugins.Event(gtx, giowebview.Filter{Target: c})
+		if !ok {
+			return
+		}
+		msg, ok := evt.(giowebview.MessageEvent)
+		if !ok {
+			continue
+		}
+		var v cspViolation
+		if json.Unmarshal([]byte(msg.Message), &v) != nil {
+			continue
+		}
+		c.log(v)
+	}
+}
+
+// log prints a violation and appends it to csp.log.
+func (c *contentPolicy) log(v cspViolation) {
+	line := fmt.Sprintf("%s: %s blocked %s", v.Page, v.Directive, v.Blocked)
+	if v.Report {
+		line = fmt.Sprintf("%s: %s would block %s", v.Page, v.Directive, v.Blocked)
+	}
+	if v.Source != "" {
+		line += fmt.Sprintf(" (%s:%d)", v.Source, v.Line)
+	}
+	fmt.Println(tr("CSP: %s", line))
+	if c.logPath == "" || os.MkdirAll(filepath.Dir(c.logPath), 0755) != nil {
+		return
+	}
+	if f, err := os.OpenFile(c.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+		fmt.Fprintf(f, "%s %s\n", time.Now().Format(time.RFC3339), line)
+		f.Close()
+	}
+}
+
+// script reports violations when logging, then adds the policy to pages
+// on its origins. The <meta> tag has to be in <head>, so it is added as
+// soon as the parser creates it, before any script in it runs.
+func (c *contentPolicy) script() string {
+	origins, _ := json.Marshal(c.origins)
+	policy, _ := json.Marshal(c.cfg.Policy)
+	return fmt.Sprintf(`(function () {
+  if (window.__goupCSP) { return; }
+  window.__goupCSP = true;
+  if (%t) {
+    document.addEventListener("securitypolicyviolation", function (e) {
+      try {
+        window.callback.%s(JSON.stringify({
+          page: location.href, directive: e.effectiveDirective, blocked: e.blockedURI || "inline",
+          source: e.sourceFile, line: e.lineNumber, report: e.disposition === "report"
+        }));
+      } catch (err) {}
+    });
+  }
+  var policy = %s;
+  if (!policy || %s.indexOf(location.origin.toLowerCase()) < 0) { return; }
+  function add() {
+    if (!document.head) { return false; }
+    var meta = document.createElement("meta");
+    meta.httpEquiv = "Content-Security-Policy";
+    meta.content = policy;
+    document.head.insertBefore(meta, document.head.firstChild);
+    return true;
+  }
+  if (!add()) {
+    new MutationObserver(function (records, observer) {
+      if (add()) { observer.disconnect(); }
+    }).observe(document, { childList: true, subtree: true });
+  }
+})();`, c.cfg.Log, cspCallback, policy, origins)
+}
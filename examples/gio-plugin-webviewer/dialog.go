@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gioui.org/layout"
+	"github.com/gioui-plugins/gio-plugins/explorer/gioexplorer"
+	"github.com/gioui-plugins/gio-plugins/explorer/mimetype"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+)
+
+// errDialogCancelled is returned by pickFile when the user closes the
+// dialog without choosing a file.
+var errDialogCancelled = errors.New("cancelled")
+
+// fileDialog is the open or save dialog a page is waiting on. Only one
+// is shown at a time.
+type fileDialog struct {
+	call bridgeCall
+	save bool
+	name string
+	typ  string
+	data []byte
+}
+
+// openDialog shows the dialog for an openFile or saveFile request. The
+// gio-plugins explorer provides the system dialogs except on desktop
+// Unix, where pickFile runs zenity or kdialog.
+func (br *bridge) openDialog(gtx layout.Context, call bridgeCall, req bridgeRequest) {
+	if br.dialog != nil {
+		br.reply(gtx, call, nil, errors.New("a file dialog is already open"))
+		return
+	}
+	d := &fileDialog{call: call, save: req.Op == "saveFile", name: req.Name, typ: req.Type, data: req.Data}
+	br.dialog = d
+	if !explorerDialogs {
+		go br.complete(d.call, func() (any, error) {
+			path, err := pickFile(d.save, d.name, req.Accept)
+			if err != nil {
+				return d.cancelled(err)
+			}
+			if d.save {
+				f, err := os.Create(path)
+				if err != nil {
+					return nil, err
+				}
+				return true, writeAll(f, d.data)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			return br.opened(f)
+		})
+		return
+	}
+	if d.save {
+		typ := d.typ
+		if typ == "" {
+			typ = filepath.Ext(d.name)
+		}
+		gioplugins.Execute(gtx, gioexplorer.SaveFileCmd{Tag: d, Filename: d.name, Mimetype: explorerType(typ)})
+		return
+	}
+	var types []mimetype.MimeType
+	for _, a := range req.Accept {
+		if t := explorerType(a); t != mimetype.Any {
+			types = append(types, t)
+		}
+	}
+	gioplugins.Execute(gtx, gioexplorer.OpenFileCmd{Tag: d, Mimetype: types})
+}
+
+// updateDialog handles the explorer's answer for the showing dialog.
+func (br *bridge) updateDialog(gtx layout.Context) {
+	d := br.dialog
+	if d == nil {
+		return
+	}
+	for {
+		e, ok := gioplugins.Event(gtx, gioexplorer.Filter{Target: d})
+		if !ok {
+			break
+		}
+		switch e := e.(type) {
+		case gioexplorer.OpenFileEvent:
+			go br.complete(d.call, func() (any, error) { return br.opened(e.File) })
+		case gioexplorer.SaveFileEvent:
+			go br.complete(d.call, func() (any, error) { return true, writeAll(e.File, d.data) })
+		case gioexplorer.CancelEvent:
+			br.dialog = nil
+			value, _ := d.cancelled(errDialogCancelled)
+			br.reply(gtx, d.call, value, nil)
+		case gioexplorer.ErrorEvent:
+			br.dialog = nil
+			br.reply(gtx, d.call, nil, e)
+		}
+	}
+}
+
+// cancelled turns a cancelled dialog into the page's answer: null for
+// openFile, false for saveFile. Other errors are passed on.
+func (d *fileDialog) cancelled(err error) (any, error) {
+	if !errors.Is(err, errDialogCancelled) {
+		return nil, err
+	}
+	if d.save {
+		return false, nil
+	}
+	return nil, nil
+}
+
+// opened serves a file chosen in the open dialog. Windows and the Unix
+// dialogs hand over the file itself; macOS and mobile only give a
+// stream, which is copied to a temporary file so the server can answer
+// Range requests.
+func (br *bridge) opened(r io.ReadCloser) (any, error) {
+	defer r.Close()
+	if f, ok := r.(*os.File); ok {
+		return br.serveFile(f.Name(), "")
+	}
+	tmp, err := os.CreateTemp("", "goup-open-*")
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Close()
+	if _, err := io.Copy(tmp, r); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	f, err := br.serveFile(tmp.Name(), "file")
+	f.Path = ""
+	return f, err
+}
+
+func writeAll(w io.WriteCloser, data []byte) error {
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// explorerType converts a filter as pages write it (".png", "image/png"
+// or "image/*") to the explorer's form. Unknown filters match anything.
+func explorerType(s string) mimetype.MimeType {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(s, ".") {
+		m := explorerType(mime.TypeByExtension(s))
+		if m == mimetype.Any {
+			m = mimetype.MimeType{Type: "application", Subtype: "octet-stream"}
+		}
+		m.Extension = s[1:]
+		return m
+	}
+	s, _, _ = strings.Cut(s, ";")
+	typ, sub, ok := strings.Cut(s, "/")
+	if !ok || typ == "" || sub == "" {
+		return mimetype.Any
+	}
+	m := mimetype.MimeType{Extension: "*", Type: typ, Subtype: sub}
+	if exts, _ := mime.ExtensionsByType(s); len(exts) > 0 {
+		m.Extension = exts[0][1:]
+	}
+	return m
+}
@@ -0,0 +1,12 @@
+//go:build !((linux && !android) || freebsd || openbsd)
+
+package main
+
+import "errors"
+
+// The gio-plugins explorer shows the system dialogs here.
+const explorerDialogs = true
+
+func pickFile(save bool, name string, accept []string) (string, error) {
+	return "", errors.New("not used on this platform")
+}
@@ -0,0 +1,73 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// The gio-plugins explorer has no dialogs for desktop Unix.
+const explorerDialogs = false
+
+// pickFile asks for a file with zenity (GNOME and most desktops) or
+// kdialog (KDE).
+func pickFile(save bool, name string, accept []string) (string, error) {
+	patterns := filePatterns(accept)
+	if _, err := exec.LookPath("zenity"); err == nil {
+		args := []string{"--file-selection"}
+		if save {
+			args = append(args, "--save", "--confirm-overwrite", "--filename="+name)
+		}
+		if len(patterns) > 0 {
+			args = append(args, "--file-filter="+strings.Join(patterns, " "))
+		}
+		return runDialog("zenity", args...)
+	}
+	if _, err := exec.LookPath("kdialog"); err == nil {
+		dir, _ := os.UserHomeDir()
+		filter := strings.Join(patterns, " ")
+		if save {
+			return runDialog("kdialog", "--getsavefilename", filepath.Join(dir, name), filter)
+		}
+		return runDialog("kdialog", "--getopenfilename", dir, filter)
+	}
+	return "", errors.New("no file dialog found (install zenity or kdialog)")
+}
+
+// runDialog returns the path the dialog printed. Both tools exit with
+// status 1 when cancelled.
+func runDialog(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return "", errDialogCancelled
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// filePatterns turns ".png", "image/png" and "image/*" filters into glob
+// patterns. Wildcard MIME types have no pattern and are skipped.
+func filePatterns(accept []string) []string {
+	var patterns []string
+	for _, a := range accept {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if strings.HasPrefix(a, ".") {
+			patterns = append(patterns, "*"+a)
+			continue
+		}
+		exts, _ := mime.ExtensionsByType(a)
+		for _, ext := range exts {
+			patterns = append(patterns, "*"+ext)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gioui.org/app"
+	"gioui.org/unit"
+)
+
+// displayConfig places the window for kiosks and multi-monitor setups.
+// Display numbers match 'goup-util displays'.
+type displayConfig struct {
+	Index      int     `json:"index,omitempty"`      // Display to open on (0 is the first, usually the primary)
+	Fullscreen bool    `json:"fullscreen,omitempty"` // Fill that display
+	Scale      float32 `json:"scale,omitempty"`      // Pixels per dp, overriding the system DPI (e.g. 2 for a 4K panel that reports 1x)
+}
+
+// validate checks the settings before the window opens.
+func (d displayConfig) validate() error {
+	if d.Index < 0 {
+		return fmt.Errorf("display.index %d: want 0 or more", d.Index)
+	}
+	if d.Scale < 0 || d.Scale > 8 {
+		return fmt.Errorf("display.scale %g: want a factor between 0 and 8", d.Scale)
+	}
+	return nil
+}
+
+// fixed reports whether app.json pins the window, which then always opens
+// where configured instead of where it was left.
+func (d displayConfig) fixed() bool {
+	return d.Index > 0 || d.Fullscreen
+}
+
+// metric applies the scale override to the metric Gio derived from the
+// system DPI.
+func (d displayConfig) metric(m unit.Metric) unit.Metric {
+	if d.Scale > 0 {
+		m.PxPerDp, m.PxPerSp = d.Scale, d.Scale
+	}
+	return m
+}
+
+// rect is a window or monitor area in the platform's desktop coordinates.
+type rect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"width"`
+	H int `json:"height"`
+}
+
+// overlap returns the area r and o have in common.
+func (r rect) overlap(o rect) int {
+	w := min(r.X+r.W, o.X+o.W) - max(r.X, o.X)
+	h := min(r.Y+r.H, o.Y+o.H) - max(r.Y, o.Y)
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+// fit returns r moved and shrunk onto the monitor it overlaps most, and
+// false when less than half of it (or of that monitor, for a window larger
+// than it) is on a monitor, e.g. because the monitor was unplugged.
+func fit(r rect, monitors []rect) (rect, bool) {
+	best, area := rect{}, 0
+	for _, m := range monitors {
+		if a := r.overlap(m); a > area {
+			best, area = m, a
+		}
+	}
+	if area == 0 || area*2 < min(r.W*r.H, best.W*best.H) {
+		return r, false
+	}
+	r.W, r.H = min(r.W, best.W), min(r.H, best.H)
+	r.X = min(max(r.X, best.X), best.X+best.W-r.W)
+	r.Y = min(max(r.Y, best.Y), best.Y+best.H-r.H)
+	return r, true
+}
+
+// moveToDisplay centres the window on the index'th monitor. It must run
+// on the window's thread.
+func moveToDisplay(view app.ViewEvent, index int) error {
+	monitors, err := monitors()
+	if err != nil {
+		return err
+	}
+	if index >= len(monitors) {
+		return fmt.Errorf("%d display(s) connected", len(monitors))
+	}
+	r, err := windowRect(view)
+	if err != nil {
+		return err
+	}
+	m := monitors[index]
+	r.X, r.Y = m.X+(m.W-r.W)/2, m.Y+(m.H-r.H)/2
+	return setWindowRect(view, r)
+}
+
+// placeWindow puts the window where app.json pins it, or where it was
+// left last time, then keeps track of where it is left. It runs once, on
+// the first valid ViewEvent, in its own goroutine: moving the native
+// window has to happen on its thread.
+func placeWindow(w *app.Window, view app.ViewEvent, d displayConfig, saver *windowSaver) {
+	if d.Index > 0 {
+		var err error
+		w.Run(func() { err = moveToDisplay(view, d.Index) })
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Warning: cannot open on display %d: %v", d.Index, err))
+		}
+	}
+	if d.Fullscreen {
+		w.Option(app.Fullscreen.Option())
+	}
+	if saver != nil {
+		saver.restore(view)
+		saver.run(view)
+	}
+}
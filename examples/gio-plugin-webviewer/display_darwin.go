@@ -0,0 +1,81 @@
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework AppKit
+
+#import <AppKit/AppKit.h>
+
+// screenFrames stores up to max visible screen frames as x, y, w, h in
+// frames and returns the number of screens.
+static int screenFrames(double *frames, int max) {
+	@autoreleasepool {
+		NSArray<NSScreen *> *screens = [NSScreen screens];
+		int n = (int)[screens count];
+		for (int i = 0; i < n && i < max; i++) {
+			NSRect f = [screens[i] visibleFrame];
+			frames[i*4] = f.origin.x;
+			frames[i*4+1] = f.origin.y;
+			frames[i*4+2] = f.size.width;
+			frames[i*4+3] = f.size.height;
+		}
+		return n;
+	}
+}
+
+static void windowFrame(CFTypeRef viewRef, double *frame) {
+	NSRect f = [[(NSView *)viewRef window] frame];
+	frame[0] = f.origin.x;
+	frame[1] = f.origin.y;
+	frame[2] = f.size.width;
+	frame[3] = f.size.height;
+}
+
+static void setWindowFrame(CFTypeRef viewRef, double x, double y, double w, double h) {
+	[[(NSView *)viewRef window] setFrame:NSMakeRect(x, y, w, h) display:YES];
+}
+*/
+import "C"
+
+import (
+	"fmt"
+
+	"gioui.org/app"
+)
+
+// maxScreens is more screens than any Mac drives.
+const maxScreens = 16
+
+// monitors returns the visible frames of the screens in NSScreen order
+// (the first is the one with the menu bar), in points with the origin at
+// the bottom left. It must run on the main thread.
+func monitors() ([]rect, error) {
+	var frames [maxScreens * 4]C.double
+	n := int(C.screenFrames(&frames[0], maxScreens))
+	monitors := make([]rect, 0, n)
+	for i := 0; i < n && i < maxScreens; i++ {
+		monitors = append(monitors, rect{X: int(frames[i*4]), Y: int(frames[i*4+1]), W: int(frames[i*4+2]), H: int(frames[i*4+3])})
+	}
+	return monitors, nil
+}
+
+// windowRect returns the window's frame. It must run on the main thread.
+func windowRect(view app.ViewEvent) (rect, error) {
+	v, ok := view.(app.AppKitViewEvent)
+	if !ok {
+		return rect{}, fmt.Errorf("unexpected view %T", view)
+	}
+	var f [4]C.double
+	C.windowFrame(C.CFTypeRef(v.View), &f[0])
+	return rect{X: int(f[0]), Y: int(f[1]), W: int(f[2]), H: int(f[3])}, nil
+}
+
+// setWindowRect sets the window's frame. It must run on the main thread.
+func setWindowRect(view app.ViewEvent, r rect) error {
+	v, ok := view.(app.AppKitViewEvent)
+	if !ok {
+		return fmt.Errorf("unexpected view %T", view)
+	}
+	C.setWindowFrame(C.CFTypeRef(v.View), C.double(r.X), C.double(r.Y), C.double(r.W), C.double(r.H))
+	return nil
+}
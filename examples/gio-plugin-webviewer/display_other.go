@@ -0,0 +1,25 @@
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+
+package main
+
+import (
+	"errors"
+
+	"gioui.org/app"
+)
+
+// Windows have no place to go on mobile, where apps own the one screen.
+
+var errNoWindows = errors.New("not supported on this platform")
+
+func monitors() ([]rect, error) {
+	return nil, errNoWindows
+}
+
+func windowRect(view app.ViewEvent) (rect, error) {
+	return rect{}, errNoWindows
+}
+
+func setWindowRect(view app.ViewEvent, r rect) error {
+	return errNoWindows
+}
@@ -0,0 +1,91 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gioui.org/app"
+)
+
+// xrandrMonitor matches a line of 'xrandr --listmonitors', e.g.
+// " 1: +HDMI-1 1920/531x1080/299+2560+0  HDMI-1".
+var xrandrMonitor = regexp.MustCompile(`^\s*\d+:\s+\S+\s+(\d+)/\d+x(\d+)/\d+\+(-?\d+)\+(-?\d+)`)
+
+// monitors lists the X11 monitors with xrandr.
+func monitors() ([]rect, error) {
+	out, err := exec.Command("xrandr", "--listmonitors").Output()
+	if err != nil {
+		return nil, fmt.Errorf("xrandr failed: %w", err)
+	}
+	var monitors []rect
+	for _, line := range strings.Split(string(out), "\n") {
+		m := xrandrMonitor.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var v [4]int
+		for i := range v {
+			v[i], _ = strconv.Atoi(m[i+1])
+		}
+		monitors = append(monitors, rect{X: v[2], Y: v[3], W: v[0], H: v[1]})
+	}
+	return monitors, nil
+}
+
+// x11Window returns the X11 window ID for xdotool. Wayland compositors
+// place windows themselves, so there is nothing to move there.
+func x11Window(view app.ViewEvent) (string, error) {
+	v, ok := view.(app.X11ViewEvent)
+	if !ok {
+		return "", errors.New("placing windows needs X11; Wayland places windows itself")
+	}
+	if _, err := exec.LookPath("xdotool"); err != nil {
+		return "", errors.New("xdotool is not installed")
+	}
+	return strconv.FormatUint(uint64(v.Window), 10), nil
+}
+
+// windowRect asks xdotool for the window's geometry.
+func windowRect(view app.ViewEvent) (rect, error) {
+	wid, err := x11Window(view)
+	if err != nil {
+		return rect{}, err
+	}
+	out, err := exec.Command("xdotool", "getwindowgeometry", "--shell", wid).Output()
+	if err != nil {
+		return rect{}, fmt.Errorf("xdotool failed: %w", err)
+	}
+	var r rect
+	for _, line := range strings.Split(string(out), "\n") {
+		k, v, _ := strings.Cut(strings.TrimSpace(line), "=")
+		n, _ := strconv.Atoi(v)
+		switch k {
+		case "X":
+			r.X = n
+		case "Y":
+			r.Y = n
+		case "WIDTH":
+			r.W = n
+		case "HEIGHT":
+			r.H = n
+		}
+	}
+	return r, nil
+}
+
+// setWindowRect resizes and moves the window with xdotool.
+func setWindowRect(view app.ViewEvent, r rect) error {
+	wid, err := x11Window(view)
+	if err != nil {
+		return err
+	}
+	return exec.Command("xdotool",
+		"windowsize", wid, strconv.Itoa(r.W), strconv.Itoa(r.H),
+		"windowmove", wid, strconv.Itoa(r.X), strconv.Itoa(r.Y)).Run()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"unsafe"
+
+	"gioui.org/app"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32              = windows.NewLazySystemDLL("user32.dll")
+	enumDisplayMonitors = user32.NewProc("EnumDisplayMonitors")
+	getMonitorInfo      = user32.NewProc("GetMonitorInfoW")
+	getWindowRect       = user32.NewProc("GetWindowRect")
+	setWindowPos        = user32.NewProc("SetWindowPos")
+)
+
+// monitorInfo is MONITORINFO.
+type monitorInfo struct {
+	Size    uint32
+	Monitor windows.Rect
+	Work    windows.Rect
+	Flags   uint32
+}
+
+// monitors returns the work areas of the monitors in EnumDisplayMonitors
+// order, like 'goup-util displays'.
+func monitors() ([]rect, error) {
+	var monitors []rect
+	enumDisplayMonitors.Call(0, 0, windows.NewCallback(func(monitor, dc, r, data uintptr) uintptr {
+		mi := monitorInfo{Size: uint32(unsafe.Sizeof(monitorInfo{}))}
+		if ok, _, _ := getMonitorInfo.Call(monitor, uintptr(unsafe.Pointer(&mi))); ok != 0 {
+			monitors = append(monitors, fromWinRect(mi.Work))
+		}
+		return 1
+	}), 0)
+	if len(monitors) == 0 {
+		return nil, fmt.Errorf("no monitors found")
+	}
+	return monitors, nil
+}
+
+func fromWinRect(r windows.Rect) rect {
+	return rect{X: int(r.Left), Y: int(r.Top), W: int(r.Right - r.Left), H: int(r.Bottom - r.Top)}
+}
+
+// windowRect returns the window's frame in physical pixels.
+func windowRect(view app.ViewEvent) (rect, error) {
+	v, ok := view.(app.Win32ViewEvent)
+	if !ok {
+		return rect{}, fmt.Errorf("unexpected view %T", view)
+	}
+	var r windows.Rect
+	if ok, _, err := getWindowRect.Call(v.HWND, uintptr(unsafe.Pointer(&r))); ok == 0 {
+		return rect{}, err
+	}
+	return fromWinRect(r), nil
+}
+
+// setWindowRect moves and resizes the window.
+func setWindowRect(view app.ViewEvent, r rect) error {
+	v, ok := view.(app.Win32ViewEvent)
+	if !ok {
+		return fmt.Errorf("unexpected view %T", view)
+	}
+	const swpNoZOrder, swpNoActivate = 0x0004, 0x0010
+	if ok, _, err := setWindowPos.Call(v.HWND, 0, uintptr(r.X), uintptr(r.Y), uintptr(r.W), uintptr(r.H), swpNoZOrder|swpNoActivate); ok == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// filterConfig restricts which hosts the shell may navigate to.
+// Rules are domain patterns: "example.com" also matches its subdomains,
+// "*.example.com" matches only subdomains, and EasyList-style "||example.com^"
+// is accepted as an alias for "example.com".
+type filterConfig struct {
+	Block []string `json:"block,omitempty"` // Hosts that may never be loaded
+	Allow []string `json:"allow,omitempty"` // If set, only these hosts may be loaded
+	Lists []string `json:"lists,omitempty"` // Rule files (one rule per line, "!" or "#" comments)
+}
+
+// urlFilter decides whether a URL may be loaded.
+type urlFilter struct {
+	block []string
+	allow []string
+}
+
+// newURLFilter builds a filter from app.json, reading any rule files.
+// Relative list paths are resolved against the executable's directory first,
+// then the current working directory. Returns nil if no rules are configured.
+func newURLFilter(cfg filterConfig) (*urlFilter, error) {
+	f := &urlFilter{}
+	for _, r := range cfg.Block {
+		if p := parseRule(r); p != "" {
+			f.block = append(f.block, p)
+		}
+	}
+	for _, r := range cfg.Allow {
+		if p := parseRule(r); p != "" {
+			f.allow = append(f.allow, p)
+		}
+	}
+	for _, path := range cfg.Lists {
+		rules, err := readRuleList(path)
+		if err != nil {
+			return nil, err
+		}
+		f.block = append(f.block, rules...)
+	}
+	if len(f.block) == 0 && len(f.allow) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// readRuleList reads a blocklist file.
+func readRuleList(path string) ([]string, error) {
+	if !filepath.IsAbs(path) {
+		if exePath, err := os.Executable(); err == nil {
+			candidate := filepath.Join(filepath.Dir(exePath), path)
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+			}
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open filter list: %w", err)
+	}
+	defer file.Close()
+
+	var rules []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if p := parseRule(scanner.Text()); p != "" {
+			rules = append(rules, p)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read filter list %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// parseRule normalises a rule to a lowercase host pattern.
+// Comments, element-hiding rules and exceptions are skipped (returns "").
+func parseRule(rule string) string {
+	rule = strings.TrimSpace(rule)
+	if rule == "" || strings.HasPrefix(rule, "!") || strings.HasPrefix(rule, "#") ||
+		strings.HasPrefix(rule, "[") || strings.HasPrefix(rule, "@@") || strings.Contains(rule, "##") {
+		return ""
+	}
+	// Drop EasyList options ("$third-party") and anchors ("||host^")
+	if i := strings.Index(rule, "$"); i >= 0 {
+		rule = rule[:i]
+	}
+	rule = strings.TrimPrefix(rule, "||")
+	rule = strings.TrimSuffix(rule, "^")
+	// Accept full URLs by keeping only the host
+	if strings.Contains(rule, "://") {
+		if u, err := url.Parse(rule); err == nil {
+			rule = u.Hostname()
+		}
+	}
+	if i := strings.IndexAny(rule, "/^"); i >= 0 {
+		rule = rule[:i]
+	}
+	// Rules match a host on any port
+	if host, _, err := net.SplitHostPort(rule); err == nil {
+		rule = host
+	}
+	return strings.TrimSuffix(strings.ToLower(rule), ".")
+}
+
+// matchHost reports whether host matches a pattern from parseRule.
+func matchHost(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// Allowed reports whether rawURL may be loaded. Only http(s) URLs are
+// filtered; internal pages such as about:blank and data: always pass.
+func (f *urlFilter) Allowed(rawURL string) bool {
+	if f == nil {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return true
+	}
+	// "example.com." is example.com too
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, p := range f.block {
+		if matchHost(p, host) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, p := range f.allow {
+		if matchHost(p, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// script returns JavaScript that stops link clicks and form posts to blocked
+// hosts before they leave the page. Navigations it cannot see (redirects,
+// location changes) are caught by the shell on NavigationEvent. The rules
+// are kept in window.goupFilter, so a script installed after a config
+// reload replaces them.
+func (f *urlFilter) script() string {
+	if f == nil {
+		return ""
+	}
+	block, _ := json.Marshal(append([]string{}, f.block...))
+	allow, _ := json.Marshal(append([]string{}, f.allow...))
+	return fmt.Sprintf(`(function () {
+  var installed = !!window.goupFilter;
+  window.goupFilter = { block: %s, allow: %s };
+  if (installed) { return; }
+  function match(p, h) {
+    if (p.indexOf("*.") === 0) { return h.endsWith(p.slice(1)); }
+    return h === p || h.endsWith("." + p);
+  }
+  function allowed(href) {
+    var u;
+    try { u = new URL(href, location.href); } catch (e) { return true; }
+    if (u.protocol !== "http:" && u.protocol !== "https:") { return true; }
+    var h = u.hostname.toLowerCase().replace(/\.$/, ""), block = window.goupFilter.block, allow = window.goupFilter.allow;
+    if (block.some(function (p) { return match(p, h); })) { return false; }
+    return allow.length === 0 || allow.some(function (p) { return match(p, h); });
+  }
+  document.addEventListener("click", function (e) {
+    var a = e.target.closest && e.target.closest("a[href]");
+    if (a && !allowed(a.href)) { e.preventDefault(); e.stopPropagation(); }
+  }, true);
+  document.addEventListener("submit", function (e) {
+    if (!allowed(e.target.action)) { e.preventDefault(); }
+  }, true);
+})();`, block, allow)
+}
+
+// blockedPage returns a page explaining that rawURL was blocked.
+func blockedPage(rawURL string) string {
+	return shellPage(tr("Blocked"), fmt.Sprintf(`<div style="text-align:center"><h2>%s</h2><p>%s</p></div>`,
+		html.EscapeString(tr("This page is blocked")), html.EscapeString(rawURL)))
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		rule, want string
+	}{
+		{"Example.com", "example.com"},
+		{"*.example.com", "*.example.com"},
+		{"||ads.example.com^", "ads.example.com"},
+		{"||ads.example.com^$third-party", "ads.example.com"},
+		{"https://example.com/path?q=1", "example.com"},
+		{"example.com/path", "example.com"},
+		{"example.com:8080", "example.com"},
+		{"||example.com:8443^", "example.com"},
+		{"example.com.", "example.com"},
+		{"  example.com  ", "example.com"},
+		{"", ""},
+		{"! comment", ""},
+		{"# comment", ""},
+		{"[Adblock Plus 2.0]", ""},
+		{"@@||example.com^", ""},
+		{"example.com##.banner", ""},
+	}
+	for _, tt := range tests {
+		if got := parseRule(tt.rule); got != tt.want {
+			t.Errorf("parseRule(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern, host string
+		want          bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com", "www.example.com", true},
+		{"example.com", "a.b.example.com", true},
+		{"example.com", "notexample.com", false},
+		{"example.com", "example.com.evil.net", false},
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "notexample.com", false},
+	}
+	for _, tt := range tests {
+		if got := matchHost(tt.pattern, tt.host); got != tt.want {
+			t.Errorf("matchHost(%q, %q) = %v, want %v", tt.pattern, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestURLFilterAllowed(t *testing.T) {
+	f, err := newURLFilter(filterConfig{
+		Allow: []string{"example.com", "*.cdn.net"},
+		Block: []string{"ads.example.com", "||tracker.cdn.net:443^"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/", true},
+		{"http://www.example.com/page", true},
+		{"https://EXAMPLE.com/", true},
+		{"https://example.com:8443/", true},
+		{"https://img.cdn.net/a.png", true},
+		{"https://cdn.net/", false},           // Wildcards match subdomains only
+		{"https://other.org/", false},         // Not allowed
+		{"https://ads.example.com/", false},   // Block wins over allow
+		{"https://x.ads.example.com/", false}, // Blocked subdomain
+		{"https://ads.example.com./", false},  // Trailing dot
+		{"http://ads.example.com:8080/", false},
+		{"https://tracker.cdn.net/", false}, // Ports in rules are ignored
+		{"https://example.com@other.org/", false},
+		{"ftp://other.org/file", true}, // Only http(s) is filtered
+		{"about:blank", true},
+		{"data:text/html,hi", true},
+	}
+	for _, tt := range tests {
+		if got := f.Allowed(tt.url); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+
+	var none *urlFilter
+	if !none.Allowed("https://anything.example/") {
+		t.Error("nil filter blocked a URL")
+	}
+	if f, err := newURLFilter(filterConfig{Block: []string{"! only a comment"}}); f != nil || err != nil {
+		t.Errorf("filter without rules = %v, %v", f, err)
+	}
+}
+
+func TestURLFilterLists(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "block.txt")
+	if err := os.WriteFile(list, []byte("! EasyList\n||ads.example.com^\n\nexample.com##.ad\ntracker.net\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := newURLFilter(filterConfig{Lists: []string{list}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ads.example.com", "tracker.net"}; !reflect.DeepEqual(f.block, want) {
+		t.Errorf("block = %q, want %q", f.block, want)
+	}
+	if _, err := newURLFilter(filterConfig{Lists: []string{filepath.Join(t.TempDir(), "missing.txt")}}); err == nil {
+		t.Error("missing list: no error")
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// flagsConfig points the shell at a remote feature flag document, the
+// same format as goup-util's pkg/remoteconfig. Pages read the flags from
+// window.goupFlags and are told about changes with a "goupflags" event.
+type flagsConfig struct {
+	URL            string         `json:"url,omitempty"`
+	RefreshMinutes int            `json:"refreshMinutes,omitempty"` // Default 15
+	Defaults       map[string]any `json:"defaults,omitempty"`
+}
+
+// flagStore holds the current flags. version counts changes so each tab
+// can tell whether it has the latest values.
+type flagStore struct {
+	mu      sync.Mutex
+	values  map[string]any
+	version int
+}
+
+func (s *flagStore) get() (map[string]any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values, s.version
+}
+
+// set stores values and reports whether they changed.
+func (s *flagStore) set(values map[string]any) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.version > 0 && reflect.DeepEqual(s.values, values) {
+		return false
+	}
+	s.values = values
+	s.version++
+	return true
+}
+
+// flagsCache is the last response, kept so an unchanged document costs a
+// 304 and the flags survive starting offline.
+type flagsCache struct {
+	URL    string         `json:"url"`
+	ETag   string         `json:"etag,omitempty"`
+	Values map[string]any `json:"values"`
+}
+
+// startFlags loads the cached flags and polls cfg.URL in the background,
+// calling changed after each update. It returns nil when app.json sets no
+// flags URL.
+func startFlags(ctx context.Context, appName string, cfg flagsConfig, changed func()) *flagStore {
+	if cfg.URL == "" {
+		return nil
+	}
+	dir, _ := os.UserConfigDir()
+	cachePath := filepath.Join(dir, appName, "remote-config.json")
+	store := &flagStore{}
+
+	var cached flagsCache
+	if data, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(data, &cached) == nil && cached.URL == cfg.URL {
+		store.set(mergeFlags(cfg.Defaults, cached.Values))
+	} else {
+		cached = flagsCache{URL: cfg.URL}
+		store.set(mergeFlags(cfg.Defaults, nil))
+	}
+
+	interval := time.Duration(cfg.RefreshMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if values, err := fetchFlags(ctx, &cached); err != nil {
+				fmt.Fprintln(os.Stderr, tr("Feature flags: %v", err))
+			} else {
+				if data, err := json.Marshal(cached); err == nil && os.MkdirAll(filepath.Dir(cachePath), 0755) == nil {
+					os.WriteFile(cachePath, data, 0644)
+				}
+				if store.set(mergeFlags(cfg.Defaults, values)) {
+					changed()
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return store
+}
+
+// fetchFlags gets the flag document, sending the cached ETag, and updates
+// the cache on success.
+func fetchFlags(ctx context.Context, cached *flagsCache) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cached.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	resp, err := (&http.Client{Timeout: 15 * time.Second}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && cached.Values != nil {
+		return cached.Values, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	if err := json.Unmarshal(body, &values); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %w", err)
+	}
+	cached.ETag, cached.Values = resp.Header.Get("ETag"), values
+	return values, nil
+}
+
+func mergeFlags(defaults, values map[string]any) map[string]any {
+	merged := map[string]any{}
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+	return merged
+}
+
+// flagsScript returns JavaScript that publishes the flags as
+// window.goupFlags and fires a "goupflags" event with them.
+func flagsScript(values map[string]any) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		data = []byte("{}")
+	}
+	return fmt.Sprintf(`(function () {
+  window.goupFlags = %s;
+  window.dispatchEvent(new CustomEvent("goupflags", { detail: window.goupFlags }));
+})();`, data)
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// fleetConfig makes the shell check in with a fleet registry, run with
+// 'goup-util update-server serve --fleet'. Operators see the install in
+// 'goup-util fleet list' and can send it commands signed with the key
+// whose public half is CommandKey.
+type fleetConfig struct {
+	URL             string `json:"url,omitempty"`
+	Token           string `json:"token,omitempty"`           // Enrollment token
+	IntervalMinutes int    `json:"intervalMinutes,omitempty"` // Default 5
+	CommandKey      string `json:"commandKey,omitempty"`      // From 'goup-util fleet keygen'; no commands without it
+}
+
+// fleetDevice is the check-in body and reply, the same fields as
+// goup-util's pkg/fleet Device.
+type fleetDevice struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name,omitempty"`
+	App         string          `json:"app,omitempty"`
+	Version     string          `json:"version,omitempty"`
+	Platform    string          `json:"platform,omitempty"`
+	Environment string          `json:"environment,omitempty"`
+	Commands    []signedCommand `json:"commands,omitempty"`
+	Results     []fleetResult   `json:"results,omitempty"`
+}
+
+type signedCommand struct {
+	Command   json.RawMessage `json:"command"`
+	Signature string          `json:"signature"`
+}
+
+type fleetCommand struct {
+	ID      string    `json:"id"`
+	Device  string    `json:"device"`
+	Type    string    `json:"type"`
+	URL     string    `json:"url,omitempty"`
+	Expires time.Time `json:"expires"`
+}
+
+type fleetResult struct {
+	Command string    `json:"command"`
+	Type    string    `json:"type,omitempty"`
+	OK      bool      `json:"ok"`
+	Error   string    `json:"error,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// fleetAgent checks in and runs the commands it is sent.
+type fleetAgent struct {
+	cfg     *appConfig
+	device  fleetDevice
+	actions *pageActions
+	wake    func() // Redraws the window so Layout runs queued actions
+
+	// ran maps the IDs of commands already run to when they expire. It
+	// is kept in ranFile so a command replayed after a restart (such as
+	// the one after an update) is not run again while it is valid.
+	ran     map[string]time.Time
+	ranFile string
+}
+
+// fleetStateFile is fleet-commands.json next to the saved machine ID,
+// the same file pkg/fleet's Agent uses.
+func fleetStateFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "goup-util", "fleet-commands.json")
+}
+
+// saveRan drops expired commands, which run refuses anyway, and writes
+// the rest to ranFile.
+func (a *fleetAgent) saveRan() error {
+	for id, expires := range a.ran {
+		if time.Now().After(expires) {
+			delete(a.ran, id)
+		}
+	}
+	if a.ranFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(a.ran)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.ranFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(a.ranFile, data, 0600)
+}
+
+// startFleet checks in now and every interval in the background, when
+// app.json has a fleet URL. Reload and clear-cache commands go to actions.
+func startFleet(ctx context.Context, cfg *appConfig, actions *pageActions, wake func()) {
+	if cfg.Fleet.URL == "" {
+		return
+	}
+	name, _ := os.Hostname()
+	platform := runtime.GOOS
+	if platform == "darwin" {
+		platform = "macos"
+	}
+	a := &fleetAgent{
+		cfg: cfg,
+		device: fleetDevice{
+			ID:          machineID(),
+			Name:        name,
+			App:         cfg.Name,
+			Version:     buildVersion(),
+			Platform:    platform + "/" + runtime.GOARCH,
+			Environment: cfg.Environment,
+		},
+		actions: actions,
+		wake:    wake,
+		ran:     map[string]time.Time{},
+		ranFile: fleetStateFile(),
+	}
+	if data, err := os.ReadFile(a.ranFile); err == nil {
+		json.Unmarshal(data, &a.ran)
+	}
+	interval := time.Duration(cfg.Fleet.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := a.checkin(ctx); err != nil && ctx.Err() == nil {
+				fmt.Fprintln(os.Stderr, tr("Fleet check-in failed: %v", err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// checkin reports in, runs the commands in the reply and reports their
+// results straight away. An update that installed a release restarts the
+// app after that.
+func (a *fleetAgent) checkin(ctx context.Context) error {
+	var reply fleetDevice
+	if err := a.post(ctx, a.device, &reply); err != nil {
+		return err
+	}
+	d := a.device
+	restart := false
+	for _, sc := range reply.Commands {
+		var c fleetCommand
+		if json.Unmarshal(sc.Command, &c) != nil {
+			continue
+		}
+		if _, seen := a.ran[c.ID]; seen {
+			continue
+		}
+		a.ran[c.ID] = time.Now().Add(24 * time.Hour) // Unverified: report the failure once
+		installed, err := a.run(ctx, sc, &c)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Fleet command %s failed: %v", c.Type, err))
+		} else {
+			fmt.Println(tr("Fleet command: %s", c.Type))
+		}
+		res := fleetResult{Command: c.ID, Type: c.Type, OK: err == nil, At: time.Now().UTC()}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		d.Results = append(d.Results, res)
+		restart = restart || installed
+	}
+	if len(d.Results) == 0 {
+		return nil
+	}
+	if err := a.post(ctx, d, nil); err != nil {
+		return err
+	}
+	if restart {
+		return restartApp()
+	}
+	return nil
+}
+
+// run verifies a command against the configured key and runs it.
+// installed reports an update that installed a release.
+func (a *fleetAgent) run(ctx context.Context, sc signedCommand, c *fleetCommand) (installed bool, err error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(a.cfg.Fleet.CommandKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return false, errors.New("no valid fleet.commandKey in app.json")
+	}
+	sig, err := base64.StdEncoding.DecodeString(sc.Signature)
+	if err != nil || !ed25519.Verify(key, sc.Command, sig) {
+		return false, errors.New("command signature is invalid")
+	}
+	if c.Device != a.device.ID {
+		return false, errors.New("command is for another device")
+	}
+	if time.Now().After(c.Expires) {
+		return false, errors.New("command has expired")
+	}
+	// Recorded before running, since an update restarts the app
+	a.ran[c.ID] = c.Expires
+	if err := a.saveRan(); err != nil {
+		return false, fmt.Errorf("cannot record the command as run: %w", err)
+	}
+
+	switch c.Type {
+	case "reload", "clear-cache":
+		a.actions.push(pageAction{ClearCache: c.Type == "clear-cache", URL: c.URL})
+		a.wake()
+		return false, nil
+	case "update":
+		installed, err := selfUpdate(a.cfg)
+		var d *deferredError
+		if errors.As(err, &d) {
+			go func() {
+				time.Sleep(time.Until(d.retry))
+				installed, err := updateWhenAllowed(a.cfg)
+				if err == nil && installed {
+					err = restartApp()
+				}
+				if err != nil {
+					fmt.Fprintln(os.Stderr, tr("Update failed: %v", err))
+				}
+			}()
+		}
+		return installed, err
+	case "screenshot":
+		dir, err := os.MkdirTemp("", "webviewer-screenshot-*")
+		if err != nil {
+			return false, err
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "screen.png")
+		if err := captureScreen(path); err != nil {
+			return false, fmt.Errorf("screenshot failed: %w", err)
+		}
+		png, err := os.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		return false, a.send(ctx, http.MethodPut, "/fleet/devices/"+url.PathEscape(a.device.ID)+"/screenshot", "image/png", png, nil)
+	}
+	return false, fmt.Errorf("unsupported command %q", c.Type)
+}
+
+func (a *fleetAgent) post(ctx context.Context, d fleetDevice, reply *fleetDevice) error {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return a.send(ctx, http.MethodPost, "/fleet/checkin", "application/json", body, reply)
+}
+
+func (a *fleetAgent) send(ctx context.Context, method, path, contentType string, body []byte, reply any) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(a.cfg.Fleet.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if a.cfg.Fleet.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.cfg.Fleet.Token)
+	}
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	if reply == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(reply)
+}
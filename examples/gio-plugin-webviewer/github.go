@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Private repositories: releases are read through GitHub's API with a
+// token from GITHUB_TOKEN, or one saved in the secure store by
+// --github-login (GitHub's device flow, the same as 'goup-util github
+// login'). The token is only sent to api.github.com.
+
+const (
+	githubAPI      = "https://api.github.com"
+	githubTokenKey = "github-token"
+)
+
+// githubToken returns the token for update checks, or "".
+func githubToken(cfg *appConfig) string {
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+	token, err := secureStore{service: cfg.Name}.get(githubTokenKey)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(token))
+}
+
+// githubGet fetches u, sending the token when u is on GitHub's API. accept
+// is "application/octet-stream" for asset downloads.
+func githubGet(cfg *appConfig, u, accept string) (*http.Response, error) {
+	req, err := githubRequest(cfg, u, accept)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// githubRequest is the GET request githubGet sends, for callers that add
+// headers.
+func githubRequest(cfg *appConfig, u, accept string) (*http.Request, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if token := githubToken(cfg); token != "" && strings.HasPrefix(u, githubAPI+"/") {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	// GitHub redirects asset downloads to storage on another host; Go
+	// drops the Authorization header on the way.
+	return req, nil
+}
+
+// releaseAsset is a file attached to a release.
+type releaseAsset struct {
+	Name               string `json:"name"`
+	URL                string `json:"url"` // API URL, which serves private assets
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+	SHA256             string `json:"-"` // From a release index
+}
+
+// downloadURL picks the API URL when there is a token, so assets of
+// private repositories download too.
+func (a releaseAsset) downloadURL(cfg *appConfig) (string, string) {
+	if a.URL != "" && strings.HasPrefix(a.URL, githubAPI+"/") && githubToken(cfg) != "" {
+		return a.URL, "application/octet-stream"
+	}
+	return a.BrowserDownloadURL, "*/*"
+}
+
+// githubLogin signs in with the device flow for update.clientId and saves
+// the token in the secure store.
+func githubLogin(cfg *appConfig) error {
+	if cfg.Update.ClientID == "" {
+		return errors.New("update.clientId is not set in app.json (an OAuth or GitHub App with the device flow enabled)")
+	}
+	var code struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+		ExpiresIn       int    `json:"expires_in"`
+		Interval        int    `json:"interval"`
+	}
+	if err := githubPostForm("/login/device/code", url.Values{"client_id": {cfg.Update.ClientID}, "scope": {"repo"}}, &code); err != nil {
+		return err
+	}
+	if code.DeviceCode == "" {
+		return errors.New("GitHub returned no device code; is the device flow enabled for update.clientId?")
+	}
+	fmt.Println(tr("Open %s and enter the code %s", code.VerificationURI, code.UserCode))
+
+	interval := time.Duration(max(code.Interval, 5)) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+		var r struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+			Interval    int    `json:"interval"`
+		}
+		err := githubPostForm("/login/oauth/access_token", url.Values{
+			"client_id":   {cfg.Update.ClientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &r)
+		if err != nil {
+			return err
+		}
+		switch r.Error {
+		case "":
+			if err := (secureStore{service: cfg.Name}).set(githubTokenKey, []byte(r.AccessToken)); err != nil {
+				return fmt.Errorf("failed to save the token: %w", err)
+			}
+			fmt.Println(tr("Signed in to GitHub"))
+			return nil
+		case "authorization_pending":
+		case "slow_down":
+			interval = time.Duration(max(r.Interval, code.Interval+5)) * time.Second
+		case "access_denied":
+			return errors.New("the login was cancelled")
+		default:
+			return fmt.Errorf("GitHub login failed: %s", r.Error)
+		}
+	}
+	return errors.New("the code expired before it was entered")
+}
+
+// githubPostForm posts to the device flow endpoints on github.com.
+func githubPostForm(path string, form url.Values, out any) error {
+	req, err := http.NewRequest("POST", "https://github.com"+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub login failed: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -9,7 +9,9 @@ replace github.com/gioui-plugins/gio-plugins => ../../.src/gio-plugins
 require (
 	gioui.org v0.9.1-0.20251215212054-7bcb315ee174
 	github.com/gioui-plugins/gio-plugins v0.9.1
+	github.com/makiuchi-d/gozxing v0.1.1
 	golang.org/x/exp/shiny v0.0.0-20250620022241-b7579e27df2b
+	golang.org/x/sys v0.39.0
 )
 
 require (
@@ -20,6 +22,6 @@ require (
 	golang.org/x/crypto v0.46.0 // indirect
 	golang.org/x/image v0.26.0 // indirect
 	golang.org/x/net v0.48.0 // indirect
-	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
+	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2 // indirect
 )
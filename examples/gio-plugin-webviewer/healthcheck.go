@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// healthcheckConfig makes the shell ping a monitoring URL on a schedule
+// (healthchecks.io, Uptime Kuma push monitors and the like), so a dead
+// kiosk raises an alert when the pings stop. --healthcheck-url overrides
+// URL.
+type healthcheckConfig struct {
+	URL             string `json:"url,omitempty"`
+	IntervalMinutes int    `json:"intervalMinutes,omitempty"` // Default 5
+}
+
+// healthStatus is the ping body, the same fields as goup-util's
+// pkg/healthcheck Status.
+type healthStatus struct {
+	Status   string            `json:"status"`
+	App      string            `json:"app,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Host     string            `json:"host,omitempty"`
+	Platform string            `json:"platform,omitempty"`
+	Started  time.Time         `json:"started"`
+	Uptime   int64             `json:"uptimeSeconds"`
+	Checks   map[string]string `json:"checks,omitempty"`
+}
+
+// uiHealth records when the window last drew a frame, so a hung UI is
+// reported even though the ping goroutine still runs.
+type uiHealth struct {
+	mu    sync.Mutex
+	frame time.Time
+}
+
+func (h *uiHealth) drew() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.frame = time.Now()
+}
+
+func (h *uiHealth) lastFrame() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.frame
+}
+
+// startHealthcheck pings url now and every interval in the background. It
+// asks the window to redraw before each ping and reports a failure to
+// url/fail when no frame follows within 10 seconds. It returns nil when no
+// URL is configured.
+func startHealthcheck(ctx context.Context, cfg *appConfig, url string, redraw func()) *uiHealth {
+	if url == "" {
+		return nil
+	}
+	interval := time.Duration(cfg.Healthcheck.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	host, _ := os.Hostname()
+	platform := runtime.GOOS
+	if platform == "darwin" {
+		platform = "macos"
+	}
+	started := time.Now()
+	h := &uiHealth{}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			asked := time.Now()
+			redraw()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(10 * time.Second):
+			}
+			s := healthStatus{
+				Status:   "ok",
+				App:      cfg.Name,
+				Version:  buildVersion(),
+				Host:     host,
+				Platform: platform + "/" + runtime.GOARCH,
+				Started:  started.UTC(),
+				Uptime:   int64(time.Since(started).Seconds()),
+			}
+			if h.lastFrame().Before(asked) {
+				s.Status = "fail"
+				s.Checks = map[string]string{"window": "not responding"}
+			}
+			if err := pingHealthcheck(ctx, url, s); err != nil && ctx.Err() == nil {
+				fmt.Fprintln(os.Stderr, tr("Health check ping failed: %v", err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return h
+}
+
+func pingHealthcheck(ctx context.Context, url string, s healthStatus) error {
+	if s.Status != "ok" {
+		url += "/fail"
+	}
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := (&http.Client{Timeout: 15 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
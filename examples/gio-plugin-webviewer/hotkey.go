@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gioui.org/app"
+	"gioui.org/io/system"
+)
+
+// hotkeyConfig binds a system-wide key combination to a shell action, for
+// launcher-style apps that pop up over whatever the user is doing.
+type hotkeyConfig struct {
+	Keys   string `json:"keys"`            // e.g. "Ctrl+Alt+Space"; Super, Cmd and Win are the logo key
+	Action string `json:"action"`          // "toggle", "show", "hide", "reload" or "event"
+	Event  string `json:"event,omitempty"` // Passed to the page with "event"
+}
+
+// Hotkey actions.
+var hotkeyActions = []string{"toggle", "show", "hide", "reload", "event"}
+
+// modifiers are the modifier keys of a hotkey.
+type modifiers uint8
+
+const (
+	modCtrl modifiers = 1 << iota
+	modAlt
+	modShift
+	modSuper
+)
+
+// hotkey is a parsed key combination. key is the canonical name of the
+// key: "A" to "Z", "0" to "9", "F1" to "F24", or one of namedKeys.
+type hotkey struct {
+	mods modifiers
+	key  string
+}
+
+// String returns the combination in canonical form, e.g. "Ctrl+Alt+K".
+func (h hotkey) String() string {
+	var names []string
+	for _, m := range []struct {
+		mod  modifiers
+		name string
+	}{{modCtrl, "Ctrl"}, {modAlt, "Alt"}, {modShift, "Shift"}, {modSuper, "Super"}} {
+		if h.mods&m.mod != 0 {
+			names = append(names, m.name)
+		}
+	}
+	return strings.Join(append(names, h.key), "+")
+}
+
+// namedKeys are the other keys a hotkey can use, with their aliases.
+var namedKeys = map[string]string{
+	"space": "Space", "enter": "Enter", "return": "Enter", "escape": "Escape", "esc": "Escape",
+	"tab": "Tab", "backspace": "Backspace", "delete": "Delete", "del": "Delete", "insert": "Insert",
+	"home": "Home", "end": "End", "pageup": "PageUp", "pgup": "PageUp", "pagedown": "PageDown", "pgdn": "PageDown",
+	"up": "Up", "down": "Down", "left": "Left", "right": "Right",
+}
+
+// parseHotkey parses "Ctrl+Shift+K". At least one modifier is required,
+// so a hotkey never swallows ordinary typing.
+func parseHotkey(s string) (hotkey, error) {
+	var h hotkey
+	parts := strings.Split(s, "+")
+	for _, p := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(p)) {
+		case "ctrl", "control":
+			h.mods |= modCtrl
+		case "alt", "option", "opt":
+			h.mods |= modAlt
+		case "shift":
+			h.mods |= modShift
+		case "super", "cmd", "command", "win", "meta":
+			h.mods |= modSuper
+		default:
+			return h, fmt.Errorf("hotkey %q: unknown modifier %q", s, p)
+		}
+	}
+	key := strings.TrimSpace(parts[len(parts)-1])
+	upper := strings.ToUpper(key)
+	switch {
+	case len(upper) == 1 && (upper[0] >= 'A' && upper[0] <= 'Z' || upper[0] >= '0' && upper[0] <= '9'):
+		h.key = upper
+	case len(upper) > 1 && upper[0] == 'F':
+		if n, err := strconv.Atoi(upper[1:]); err == nil && n >= 1 && n <= 24 {
+			h.key = upper
+		}
+	default:
+		h.key = namedKeys[strings.ToLower(key)]
+	}
+	if h.key == "" {
+		return h, fmt.Errorf("hotkey %q: unknown key %q", s, key)
+	}
+	if h.mods == 0 {
+		return h, fmt.Errorf("hotkey %q: needs at least one of Ctrl, Alt, Shift or Super", s)
+	}
+	return h, nil
+}
+
+// hotkeys runs the configured hotkeys' actions.
+type hotkeys struct {
+	w       *app.Window
+	config  []hotkeyConfig
+	keys    []hotkey
+	actions *pageActions
+
+	mu      sync.Mutex
+	mode    app.WindowMode
+	shown   app.WindowMode // Mode to restore from minimized
+	focused bool
+}
+
+// newHotkeys validates the hotkeys in app.json. It returns nil when there
+// are none.
+func newHotkeys(w *app.Window, config []hotkeyConfig, actions *pageActions) (*hotkeys, error) {
+	if len(config) == 0 {
+		return nil, nil
+	}
+	h := &hotkeys{w: w, config: config, actions: actions}
+	for _, c := range config {
+		k, err := parseHotkey(c.Keys)
+		if err != nil {
+			return nil, err
+		}
+		known := false
+		for _, a := range hotkeyActions {
+			known = known || a == c.Action
+		}
+		if !known {
+			return nil, fmt.Errorf("hotkey %q: unknown action %q (want one of %s)", c.Keys, c.Action, strings.Join(hotkeyActions, ", "))
+		}
+		if c.Action == "event" && c.Event == "" {
+			return nil, fmt.Errorf("hotkey %q: action \"event\" needs an \"event\" name", c.Keys)
+		}
+		h.keys = append(h.keys, k)
+	}
+	return h, nil
+}
+
+// register grabs the hotkeys system-wide. A combination another app
+// already holds is reported and skipped.
+func (h *hotkeys) register() {
+	if err := registerHotkeys(h.w, h.keys, h.pressed); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Warning: hotkeys: %v", err))
+	}
+}
+
+// configure records the window state that toggle decides on.
+func (h *hotkeys) configure(c app.Config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mode, h.focused = c.Mode, c.Focused
+	if c.Mode != app.Minimized {
+		h.shown = c.Mode
+	}
+}
+
+// pressed runs the action of hotkey i. It is called from the platform's
+// hotkey thread.
+func (h *hotkeys) pressed(i int) {
+	c := h.config[i]
+	h.mu.Lock()
+	visible := h.mode != app.Minimized && h.focused
+	h.mu.Unlock()
+	switch {
+	case c.Action == "show", c.Action == "toggle" && !visible:
+		h.show()
+	case c.Action == "hide", c.Action == "toggle":
+		h.w.Option(app.Minimized.Option())
+	case c.Action == "reload":
+		h.actions.push(pageAction{})
+	case c.Action == "event":
+		h.actions.push(pageAction{Script: hotkeyScript(c)})
+	}
+	h.w.Invalidate()
+}
+
+// show brings the window back in the mode it was minimized from, in front.
+func (h *hotkeys) show() {
+	h.mu.Lock()
+	mode := h.shown
+	h.mu.Unlock()
+	h.w.Option(mode.Option())
+	h.w.Perform(system.ActionRaise)
+}
+
+// hotkeyScript returns JavaScript that fires a "goup-hotkey" event with the
+// hotkey's event name and keys.
+func hotkeyScript(c hotkeyConfig) string {
+	detail, _ := json.Marshal(map[string]string{"event": c.Event, "keys": c.Keys})
+	return fmt.Sprintf(`window.dispatchEvent(new CustomEvent("goup-hotkey", { detail: %s }));`, detail)
+}
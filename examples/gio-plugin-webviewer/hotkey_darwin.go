@@ -0,0 +1,93 @@
+package main
+
+/*
+#cgo LDFLAGS: -framework Carbon
+
+#include <Carbon/Carbon.h>
+
+extern void goupHotkeyPressed(int id);
+
+static OSStatus hotkeyHandler(EventHandlerCallRef next, EventRef event, void *data) {
+	EventHotKeyID id;
+	GetEventParameter(event, kEventParamDirectObject, typeEventHotKeyID, NULL, sizeof(id), NULL, &id);
+	goupHotkeyPressed((int)id.id);
+	return noErr;
+}
+
+static OSStatus installHotkeyHandler(void) {
+	EventTypeSpec spec = {kEventClassKeyboard, kEventHotKeyPressed};
+	return InstallApplicationEventHandler(&hotkeyHandler, 1, &spec, NULL, NULL);
+}
+
+static OSStatus registerHotkey(int id, UInt32 keyCode, UInt32 mods) {
+	EventHotKeyID hk = {'goup', (UInt32)id};
+	EventHotKeyRef ref;
+	return RegisterEventHotKey(keyCode, mods, hk, GetApplicationEventTarget(), 0, &ref);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+
+	"gioui.org/app"
+)
+
+// macKeyCodes maps keys to Carbon virtual key codes (kVK_*), which follow
+// the ANSI keyboard layout rather than the alphabet.
+var macKeyCodes = map[string]C.UInt32{
+	"A": 0x00, "S": 0x01, "D": 0x02, "F": 0x03, "H": 0x04, "G": 0x05, "Z": 0x06, "X": 0x07,
+	"C": 0x08, "V": 0x09, "B": 0x0B, "Q": 0x0C, "W": 0x0D, "E": 0x0E, "R": 0x0F, "Y": 0x10,
+	"T": 0x11, "1": 0x12, "2": 0x13, "3": 0x14, "4": 0x15, "6": 0x16, "5": 0x17, "9": 0x19,
+	"7": 0x1A, "8": 0x1C, "0": 0x1D, "O": 0x1F, "U": 0x20, "I": 0x22, "P": 0x23, "L": 0x25,
+	"J": 0x26, "K": 0x28, "N": 0x2D, "M": 0x2E,
+	"Enter": 0x24, "Tab": 0x30, "Space": 0x31, "Backspace": 0x33, "Escape": 0x35,
+	"Home": 0x73, "PageUp": 0x74, "Delete": 0x75, "End": 0x77, "PageDown": 0x79,
+	"Left": 0x7B, "Right": 0x7C, "Down": 0x7D, "Up": 0x7E,
+	"F1": 0x7A, "F2": 0x78, "F3": 0x63, "F4": 0x76, "F5": 0x60, "F6": 0x61, "F7": 0x62, "F8": 0x64,
+	"F9": 0x65, "F10": 0x6D, "F11": 0x67, "F12": 0x6F, "F13": 0x69, "F14": 0x6B, "F15": 0x71,
+	"F16": 0x6A, "F17": 0x40, "F18": 0x4F, "F19": 0x50, "F20": 0x5A,
+}
+
+// hotkeyPressed is the callback of the registered hotkeys; Carbon only
+// passes an ID to the C handler.
+var hotkeyPressed func(i int)
+
+//export goupHotkeyPressed
+func goupHotkeyPressed(id C.int) {
+	if hotkeyPressed != nil {
+		go hotkeyPressed(int(id) - 1) // Off the main thread, which the actions need
+	}
+}
+
+// registerHotkeys registers the hotkeys with Carbon's RegisterEventHotKey,
+// which needs no Accessibility permission.
+func registerHotkeys(w *app.Window, keys []hotkey, pressed func(i int)) error {
+	var errs []error
+	w.Run(func() {
+		hotkeyPressed = pressed
+		if status := C.installHotkeyHandler(); status != 0 {
+			errs = append(errs, fmt.Errorf("cannot install hotkey handler (%d)", int(status)))
+			return
+		}
+		const cmdKey, shiftKey, optionKey, controlKey = 0x100, 0x200, 0x800, 0x1000
+		for i, k := range keys {
+			code, ok := macKeyCodes[k.key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: key not available on macOS", k))
+				continue
+			}
+			var mods C.UInt32
+			for m, mac := range map[modifiers]C.UInt32{modCtrl: controlKey, modAlt: optionKey, modShift: shiftKey, modSuper: cmdKey} {
+				if k.mods&m != 0 {
+					mods |= mac
+				}
+			}
+			if status := C.registerHotkey(C.int(i+1), code, mods); status != 0 {
+				errs = append(errs, fmt.Errorf("%s: already in use (%d)", k, int(status)))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,14 @@
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+
+package main
+
+import (
+	"errors"
+
+	"gioui.org/app"
+)
+
+// registerHotkeys has nothing to do on mobile, which has no global hotkeys.
+func registerHotkeys(w *app.Window, keys []hotkey, pressed func(i int)) error {
+	return errors.New("not supported on this platform")
+}
@@ -0,0 +1,118 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package main
+
+/*
+#cgo LDFLAGS: -lX11
+
+#include <stdlib.h>
+#include <X11/Xlib.h>
+
+static int grabFailed;
+
+static int grabError(Display *d, XErrorEvent *e) {
+	grabFailed = 1;
+	return 0;
+}
+
+// grabKey grabs keysym with mods on the root window, also with Caps Lock
+// and Num Lock on, and returns 0 if another client holds the combination.
+static int grabKey(Display *d, KeySym sym, unsigned int mods) {
+	KeyCode code = XKeysymToKeycode(d, sym);
+	if (code == 0) {
+		return 0;
+	}
+	grabFailed = 0;
+	int (*old)(Display *, XErrorEvent *) = XSetErrorHandler(grabError);
+	unsigned int locks[] = {0, LockMask, Mod2Mask, LockMask | Mod2Mask};
+	for (int i = 0; i < 4; i++) {
+		XGrabKey(d, code, mods | locks[i], DefaultRootWindow(d), False, GrabModeAsync, GrabModeAsync);
+	}
+	XSync(d, False);
+	XSetErrorHandler(old);
+	return !grabFailed;
+}
+
+// nextKeyPress waits for a key press and returns its keycode and
+// modifiers, without the lock keys.
+static void nextKeyPress(Display *d, unsigned int *code, unsigned int *mods) {
+	XEvent e;
+	for (;;) {
+		XNextEvent(d, &e);
+		if (e.type == KeyPress) {
+			*code = e.xkey.keycode;
+			*mods = e.xkey.state & ~(LockMask | Mod2Mask);
+			return;
+		}
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"unsafe"
+
+	"gioui.org/app"
+)
+
+// keysymNames maps the named keys to X11 keysym names.
+var keysymNames = map[string]string{
+	"Space": "space", "Enter": "Return", "Escape": "Escape", "Tab": "Tab", "Backspace": "BackSpace",
+	"Delete": "Delete", "Insert": "Insert", "Home": "Home", "End": "End", "PageUp": "Prior", "PageDown": "Next",
+	"Up": "Up", "Down": "Down", "Left": "Left", "Right": "Right",
+}
+
+// registerHotkeys grabs the hotkeys on the X11 root window through a
+// display connection of its own, and reads their presses from it. Wayland
+// has no global hotkeys for apps; bind a key in the desktop's settings to
+// a command there instead.
+func registerHotkeys(w *app.Window, keys []hotkey, pressed func(i int)) error {
+	done := make(chan error)
+	go func() {
+		runtime.LockOSThread()
+		d := C.XOpenDisplay(nil)
+		if d == nil {
+			done <- errors.New("global hotkeys need X11")
+			return
+		}
+		type grab struct{ code, mods C.uint }
+		grabs := map[grab]int{}
+		var errs []error
+		for i, k := range keys {
+			name := keysymNames[k.key]
+			if name == "" {
+				name = strings.ToLower(k.key)
+				if len(k.key) > 1 {
+					name = k.key // F1 to F24
+				}
+			}
+			cname := C.CString(name)
+			sym := C.XStringToKeysym(cname)
+			C.free(unsafe.Pointer(cname))
+			var mods C.uint
+			for m, x := range map[modifiers]C.uint{modCtrl: C.ControlMask, modAlt: C.Mod1Mask, modShift: C.ShiftMask, modSuper: C.Mod4Mask} {
+				if k.mods&m != 0 {
+					mods |= x
+				}
+			}
+			if C.grabKey(d, sym, mods) == 0 {
+				errs = append(errs, fmt.Errorf("%s: already in use", k))
+				continue
+			}
+			grabs[grab{C.uint(C.XKeysymToKeycode(d, sym)), mods}] = i
+		}
+		done <- errors.Join(errs...)
+		for {
+			var g grab
+			C.nextKeyPress(d, &g.code, &g.mods)
+			if i, ok := grabs[g]; ok {
+				pressed(i)
+			}
+		}
+	}()
+	return <-done
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"gioui.org/app"
+)
+
+var (
+	registerHotKey = user32.NewProc("RegisterHotKey")
+	getMessage     = user32.NewProc("GetMessageW")
+)
+
+// winMsg is MSG.
+type winMsg struct {
+	HWND    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      struct{ X, Y int32 }
+	Private uint32
+}
+
+// virtualKeys maps the named keys to Windows virtual-key codes.
+var virtualKeys = map[string]uintptr{
+	"Space": 0x20, "Enter": 0x0D, "Escape": 0x1B, "Tab": 0x09, "Backspace": 0x08,
+	"Delete": 0x2E, "Insert": 0x2D, "Home": 0x24, "End": 0x23, "PageUp": 0x21, "PageDown": 0x22,
+	"Up": 0x26, "Down": 0x28, "Left": 0x25, "Right": 0x27,
+}
+
+func virtualKey(key string) uintptr {
+	if vk, ok := virtualKeys[key]; ok {
+		return vk
+	}
+	if len(key) == 1 {
+		return uintptr(key[0]) // VK codes of letters and digits are their ASCII codes
+	}
+	var n int
+	fmt.Sscanf(key, "F%d", &n)
+	return 0x70 + uintptr(n-1)
+}
+
+// registerHotkeys registers the hotkeys with RegisterHotKey on a thread of
+// their own, which then waits for WM_HOTKEY messages.
+func registerHotkeys(w *app.Window, keys []hotkey, pressed func(i int)) error {
+	done := make(chan error)
+	go func() {
+		runtime.LockOSThread() // Hotkey messages go to the registering thread
+		const modAltWin, modControlWin, modShiftWin, modWinWin, modNoRepeat = 0x1, 0x2, 0x4, 0x8, 0x4000
+		var errs []error
+		for i, k := range keys {
+			mods := uintptr(modNoRepeat)
+			for m, win := range map[modifiers]uintptr{modCtrl: modControlWin, modAlt: modAltWin, modShift: modShiftWin, modSuper: modWinWin} {
+				if k.mods&m != 0 {
+					mods |= win
+				}
+			}
+			if ok, _, err := registerHotKey.Call(0, uintptr(i+1), mods, virtualKey(k.key)); ok == 0 {
+				errs = append(errs, fmt.Errorf("%s: %w", k, err))
+			}
+		}
+		done <- errors.Join(errs...)
+		const wmHotkey = 0x0312
+		var m winMsg
+		for {
+			if r, _, _ := getMessage.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0); int32(r) <= 0 {
+				return
+			}
+			if m.Message == wmHotkey && m.WParam >= 1 && int(m.WParam) <= len(keys) {
+				pressed(int(m.WParam) - 1)
+			}
+		}
+	}()
+	return <-done
+}
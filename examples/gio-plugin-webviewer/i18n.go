@@ -0,0 +1,71 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// Message catalogs, keyed by the English text. Regenerate after changing
+// tr() strings with: goup-util i18n extract examples/gio-plugin-webviewer
+//
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// translations holds the active catalog; nil means English.
+var translations map[string]string
+
+// setupLocale picks the UI language: app.json "locale", then GOUP_LANG and
+// the POSIX locale variables, then the OS preference.
+func setupLocale(configured string) {
+	locale := normalizeLocale(configured)
+	for _, env := range []string{"GOUP_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale != "" {
+			break
+		}
+		locale = normalizeLocale(os.Getenv(env))
+	}
+	if locale == "" {
+		locale = normalizeLocale(systemLocale())
+	}
+
+	data, err := localeFS.ReadFile(path.Join("locales", locale+".json"))
+	if err != nil {
+		return
+	}
+	var catalog map[string]string
+	if err := json.Unmarshal(data, &catalog); err == nil {
+		translations = catalog
+	}
+}
+
+// normalizeLocale reduces "de_DE.UTF-8" or "pt-BR" to "de" / "pt".
+func normalizeLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ToLower(locale)
+	if locale == "c" || locale == "posix" {
+		return ""
+	}
+	return locale
+}
+
+// tr translates msg and formats it with args like fmt.Sprintf.
+// Untranslated messages fall back to English.
+func tr(msg string, args ...any) string {
+	if t := translations[msg]; t != "" {
+		msg = t
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"net/url"
+	"time"
+
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+
+// idleConfig is attract mode for public kiosks: once nobody has touched
+// the screen for a while, the shell shows an attract page and forgets the
+// last visitor.
+type idleConfig struct {
+	Minutes      int    `json:"minutes,omitempty"`      // Time without input before attract mode (default: never)
+	URL          string `json:"url,omitempty"`          // Attract page (default: url)
+	ClearSession bool   `json:"clearSession,omitempty"` // Clear cookies, storage and cache first (default true)
+	LoopMinutes  int    `json:"loopMinutes,omitempty"`  // Reload the attract page this often while idle
+}
+
+func (c idleConfig) validate() error {
+	if c.Minutes < 0 || c.LoopMinutes < 0 {
+		return fmt.Errorf("idle.minutes and idle.loopMinutes: want 0 or more")
+	}
+	if c.URL != "" {
+		if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("idle.url %q: want an http or https URL", c.URL)
+		}
+	}
+	return nil
+}
+
+const idleCallback = "goupidle" // window.callback.goupidle()
+
+// idleTimer tracks input in the pages and on the toolbar, and expires
+// after timeout without any.
+type idleTimer struct {
+	timeout time.Duration
+	url     string        // Attract page; "" for url
+	clear   bool          // Clear the session data on expiry
+	loop    time.Duration // Reload the attract page this often; 0 for never
+
+	last    time.Time
+	expired bool      // Showing the attract page; waits for the next input
+	shown   time.Time // When the attract page last loaded
+}
+
+// newIdleTimer returns the timer for app.json's idle section, or the
+// plain return to url of touch.idleSeconds; nil without either.
+func newIdleTimer(cfg *appConfig) *idleTimer {
+	switch {
+	case cfg.Idle.Minutes > 0:
+		return &idleTimer{
+			timeout: time.Duration(cfg.Idle.Minutes) * time.Minute,
+			url:     cfg.Idle.URL,
+			clear:   cfg.Idle.ClearSession,
+			loop:    time.Duration(cfg.Idle.LoopMinutes) * time.Minute,
+		}
+	case cfg.Touch.IdleSeconds > 0:
+		return &idleTimer{timeout: time.Duration(cfg.Touch.IdleSeconds) * time.Second}
+	}
+	return nil
+}
+
+// prepare installs the input report into a tab; nil does nothing.
+func (t *idleTimer) prepare(gtx layout.Context, view event.Tag) {
+	if t == nil {
+		return
+	}
+	gioplugins.Execute(gtx, giowebview.MessageReceiverCmd{View: view, Tag: t, Name: idleCallback})
+	gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: view, Script: idleScript})
+}
+
+// update records the input since the last frame and reports whether the
+// attract page is due: when the timeout expires, and every loop after.
+// Call it once per frame, before watch.
+func (t *idleTimer) update(gtx layout.Context) bool {
+	if t == nil {
+		return false
+	}
+	if t.last.IsZero() {
+		t.last = gtx.Now
+	}
+	for {
+		evt, ok := gioplugins.Event(gtx, giowebview.Filter{Target: t})
+		if !ok {
+			break
+		}
+		if _, ok := evt.(giowebview.MessageEvent); ok {
+			t.touched(gtx.Now)
+		}
+	}
+	for {
+		_, ok := gtx.Event(pointer.Filter{Target: t, Kinds: pointer.Press | pointer.Scroll})
+		if !ok {
+			break
+		}
+		t.touched(gtx.Now)
+	}
+
+	due := t.last.Add(t.timeout)
+	if t.expired {
+		if t.loop <= 0 {
+			return false
+		}
+		due = t.shown.Add(t.loop)
+	}
+	if gtx.Now.Before(due) {
+		gtx.Execute(op.InvalidateCmd{At: due})
+		return false
+	}
+	t.expired, t.shown = true, gtx.Now
+	return true
+}
+
+func (t *idleTimer) touched(now time.Time) {
+	t.last, t.expired = now, false
+}
+
+// watch registers the shell's window for input, passing it on to the
+// buttons and tabs below. Call it after laying them out.
+func (t *idleTimer) watch(gtx layout.Context) {
+	if t == nil {
+		return
+	}
+	defer pointer.PassOp{}.Push(gtx.Ops).Pop()
+	defer clip.Rect{Max: image.Point{X: gtx.Constraints.Max.X, Y: gtx.Constraints.Max.Y}}.Push(gtx.Ops).Pop()
+	event.Op(gtx.Ops, t)
+}
+
+// attract closes every tab but the first and the settings overlay, and
+// shows the attract page, after clearing the last visitor's session data
+// if configured.
+func (b *Browsers) attract(gtx layout.Context) {
+	for i := len(b.Tabs) - 1; i > 0; i-- {
+		b.remove(i)
+	}
+	b.Selected = 0
+	b.Admin.hide()
+	if b.Idle.clear {
+		gioplugins.Execute(gtx, giowebview.ClearCacheCmd{View: b.Tags[0]})
+	}
+	target := DefaultURL
+	if b.Idle.url != "" {
+		target = b.Idle.url
+	}
+	b.Address[0].SetText(target)
+	b.prepare(gtx, 0)
+	gioplugins.Execute(gtx, giowebview.NavigateCmd{View: b.Tags[0], URL: b.guard(target)})
+}
+
+// idleScript reports touches, clicks and key presses in the page, at most
+// every five seconds.
+const idleScript = `(function () {
+  if (window.__goupIdle) { return; }
+  window.__goupIdle = true;
+  var last = 0;
+  function active() {
+    var now = Date.now();
+    if (now - last < 5000) { return; }
+    last = now;
+    try { window.callback.goupidle("active"); } catch (err) {}
+  }
+  ["pointerdown", "touchstart", "keydown", "wheel"].forEach(function (name) {
+    window.addEventListener(name, active, { capture: true, passive: true });
+  });
+})();`
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// loginID names the app's login item: the LaunchAgent label on macOS,
+// the Run value on Windows and the autostart file on Linux.
+func loginID(appName string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, appName)
+	return "goup." + strings.Trim(slug, "-")
+}
+
+// applyStartAtLogin registers the app to start when the user logs in, or
+// removes the registration once app.json no longer asks for it. MSIX
+// builds get a startup task from 'goup-util bundle windows' instead.
+func applyStartAtLogin(cfg *appConfig) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	id := loginID(cfg.Name)
+	if !cfg.StartAtLogin {
+		return disableAtLogin(id)
+	}
+	return enableAtLogin(id, cfg.Name, exe)
+}
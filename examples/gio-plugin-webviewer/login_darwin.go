@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+func launchAgentPath(id string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", id+".plist"), nil
+}
+
+// enableAtLogin writes a LaunchAgent, which launchd reads at the next
+// login.
+func enableAtLogin(id, name, exe string) error {
+	if runtime.GOOS == "ios" {
+		return nil
+	}
+	path, err := launchAgentPath(id)
+	if err != nil {
+		return err
+	}
+	var label, program bytes.Buffer
+	xml.EscapeText(&label, []byte(id))
+	xml.EscapeText(&program, []byte(exe))
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + label.String() + `</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>` + program.String() + `</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>ProcessType</key>
+	<string>Interactive</string>
+</dict>
+</plist>
+`
+	if old, err := os.ReadFile(path); err == nil && string(old) == plist {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(plist), 0644)
+}
+
+func disableAtLogin(id string) error {
+	path, err := launchAgentPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+
+package main
+
+// Mobile systems have no login items; the app is started by the user.
+
+func enableAtLogin(id, name, exe string) error {
+	return nil
+}
+
+func disableAtLogin(id string) error {
+	return nil
+}
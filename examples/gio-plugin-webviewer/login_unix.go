@@ -0,0 +1,48 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func autostartPath(id string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "autostart", id+".desktop"), nil
+}
+
+// enableAtLogin writes an XDG autostart entry, which GNOME, KDE and most
+// other desktops run at login.
+func enableAtLogin(id, name, exe string) error {
+	path, err := autostartPath(id)
+	if err != nil {
+		return err
+	}
+	// Quoted as the Desktop Entry spec asks for Exec arguments
+	quoted := `"` + strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", `$`, `\\$`, `%`, `%%`).Replace(exe) + `"`
+	entry := "[Desktop Entry]\nType=Application\nName=" + name + "\nExec=" + quoted + "\nX-GNOME-Autostart-enabled=true\n"
+	if old, err := os.ReadFile(path); err == nil && string(old) == entry {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(entry), 0644)
+}
+
+func disableAtLogin(id string) error {
+	path, err := autostartPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const runKey = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+// packaged reports whether the shell runs from an MSIX package, where the
+// manifest's startup task starts it and Run key writes are virtualized.
+func packaged() bool {
+	proc := windows.NewLazySystemDLL("kernel32.dll").NewProc("GetCurrentPackageFullName")
+	if proc.Find() != nil {
+		return false
+	}
+	var n uint32
+	r, _, _ := proc.Call(uintptr(unsafe.Pointer(&n)), 0)
+	return r != 15700 // APPMODEL_ERROR_NO_PACKAGE
+}
+
+func enableAtLogin(id, name, exe string) error {
+	if packaged() {
+		return nil
+	}
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, runKey, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	command := syscall.EscapeArg(exe)
+	if old, _, err := k.GetStringValue(id); err == nil && old == command {
+		return nil
+	}
+	return k.SetStringValue(id, command)
+}
+
+func disableAtLogin(id string) error {
+	if packaged() {
+		return nil
+	}
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return nil
+	}
+	defer k.Close()
+	if err := k.DeleteValue(id); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"image"
-	"image/color"
 	"io"
 	"math"
 	"net/http"
@@ -14,7 +18,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
 	"time"
 
 	"gioui.org/font"
@@ -48,6 +56,12 @@ var (
 	IconLocalStorage, _   = widget.NewIcon(icons.DeviceStorage)
 	IconSessionStorage, _ = widget.NewIcon(icons.ImageTimer)
 	IconJavascript, _     = widget.NewIcon(icons.AVPlayArrow)
+	IconVolumeUp, _       = widget.NewIcon(icons.AVVolumeUp)
+	IconVolumeOff, _      = widget.NewIcon(icons.AVVolumeOff)
+	IconKeyboard, _       = widget.NewIcon(icons.HardwareKeyboard)
+	IconBack, _           = widget.NewIcon(icons.NavigationArrowBack)
+	IconHome, _           = widget.NewIcon(icons.ActionHome)
+	IconSettings, _       = widget.NewIcon(icons.ActionSettings)
 )
 
 //go:embed app.json
@@ -57,26 +71,192 @@ var embeddedConfig []byte
 type appConfig struct {
 	URL    string       `json:"url"`
 	Name   string       `json:"name,omitempty"`
+	Locale string       `json:"locale,omitempty"` // UI language (e.g. "de"); detected from the OS if empty
 	Width  int          `json:"width,omitempty"`
 	Height int          `json:"height,omitempty"`
 	Update updateConfig `json:"update,omitempty"`
+	Media  mediaConfig  `json:"media,omitempty"`
+	Filter filterConfig `json:"filter,omitempty"`
+	Theme  themeConfig  `json:"theme,omitempty"`
+
+	Display        displayConfig `json:"display,omitempty"`        // Monitor, fullscreen and DPI override
+	RememberWindow bool          `json:"rememberWindow,omitempty"` // Reopen where the window was left (default true)
+	StartAtLogin   bool          `json:"startAtLogin,omitempty"`   // Start when the user logs in
+
+	Hotkeys []hotkeyConfig `json:"hotkeys,omitempty"` // System-wide shortcuts
+	Bridge  bridgeConfig   `json:"bridge,omitempty"`  // Native clipboard and file drops for the app's pages
+
+	// Permissions maps "camera", "microphone", "screen-recording" or
+	// "local-network" to the reason shown on the first-run page.
+	Permissions map[string]string `json:"permissions,omitempty"`
+
+	Proxy   proxyConfig            `json:"proxy,omitempty"`
+	Proxies map[string]proxyConfig `json:"proxies,omitempty"` // Named alternatives for --proxy-profile
+	TLS     tlsConfig              `json:"tls,omitempty"`     // Extra CAs and certificate pins
+	CSP     cspConfig              `json:"csp,omitempty"`     // Content Security Policy for the site's pages
+
+	Flags flagsConfig `json:"flags,omitempty"` // Remote feature flags for pages
+	Fleet fleetConfig `json:"fleet,omitempty"` // Check in with a fleet registry
+
+	Healthcheck healthcheckConfig `json:"healthcheck,omitempty"` // Uptime monitoring pings
+	Watchdog    watchdogConfig    `json:"watchdog,omitempty"`    // Scheduled restart and memory limit
+	NetworkLog  networkLogConfig  `json:"networkLog,omitempty"`  // The pages' requests, saved as a HAR file
+
+	Reload reloadConfig `json:"reload,omitempty"` // Apply app.json changes while running
+
+	Accessibility accessibilityConfig `json:"accessibility,omitempty"` // Text size, contrast, page checks, on-screen keyboard
+	Touch         touchConfig         `json:"touch,omitempty"`         // Back/home buttons, swipes, return home when idle
+	Idle          idleConfig          `json:"idle,omitempty"`          // Attract page and session reset between visitors
+	Profiles      profilesConfig      `json:"profiles,omitempty"`      // People sharing the device, each with their own sessions
+	Admin         adminConfig         `json:"admin,omitempty"`         // PIN-locked settings for technicians
+
+	Environment string `json:"environment,omitempty"` // Set by 'goup-util build --env'
+}
+
+// versionInfo describes the build for --version: the app, the environment
+// it was built for and the platform.
+func (c *appConfig) versionInfo() string {
+	info := c.Name
+	if c.Environment != "" {
+		info += " (" + c.Environment + ")"
+	}
+	if v := buildVersion(); v != "" {
+		info += " " + v
+	}
+	return fmt.Sprintf("%s %s/%s", info, runtime.GOOS, runtime.GOARCH)
+}
+
+// buildVersion is the module version stamped into the binary, if any.
+func buildVersion() string {
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+	return ""
+}
+
+// title is the window title. Builds for an environment other than
+// production carry its name so testers can tell them apart.
+func (c *appConfig) title() string {
+	switch c.Environment {
+	case "", "prod", "production":
+		return c.Name
+	}
+	return fmt.Sprintf("%s [%s]", c.Name, c.Environment)
 }
 
-// updateConfig tells the shell where to find updates on GitHub.
+// updateConfig tells the shell where to find updates: GitHub releases, or
+// a server run with 'goup-util update-server serve'.
 type updateConfig struct {
-	Repo  string `json:"repo"`  // GitHub owner/repo (e.g. "joeblew999/goup-util")
-	Asset string `json:"asset"` // Asset name prefix (e.g. "webviewer-shell")
+	Repo  string `json:"repo"`          // GitHub owner/repo (e.g. "joeblew999/goup-util"), or an https:// release index
+	Asset string `json:"asset"`         // Asset name prefix (e.g. "webviewer-shell")
+	URL   string `json:"url,omitempty"` // Self-hosted update server, used instead of GitHub
+
+	// ClientID is the OAuth or GitHub App for --github-login, for releases
+	// in a private repo.
+	ClientID string `json:"clientId,omitempty"`
+
+	Rollback rollbackConfig `json:"rollback,omitempty"` // Revert an update that keeps crashing
+	Download downloadPolicy `json:"download,omitempty"` // When and how fast updates download
 }
 
-// loadAppConfig tries to load app.json from the executable's directory first,
-// then the current working directory. Returns defaults if not found.
-func loadAppConfig() *appConfig {
-	cfg := &appConfig{
+// configured reports whether app.json says where updates come from.
+func (u updateConfig) configured() bool {
+	return (u.Repo != "" || u.URL != "") && u.Asset != ""
+}
+
+// latestURL is the latest-release endpoint. The update server answers in
+// GitHub's format.
+func (u updateConfig) latestURL() string {
+	if u.URL != "" {
+		return strings.TrimSuffix(u.URL, "/") + "/releases/latest"
+	}
+	return fmt.Sprintf("%s/repos/%s/releases/latest", githubAPI, u.Repo)
+}
+
+// releaseInfo is the latest release, in GitHub's format.
+type releaseInfo struct {
+	TagName           string         `json:"tag_name"`
+	Assets            []releaseAsset `json:"assets"`
+	RolloutPercentage *int           `json:"rollout_percentage"`
+}
+
+// fetchLatestRelease asks GitHub or the update server, or reads the
+// release index update.repo points at.
+func fetchLatestRelease(cfg *appConfig) (*releaseInfo, error) {
+	if cfg.Update.URL == "" && cfg.Update.index() {
+		return fetchIndexRelease(cfg.Update.Repo)
+	}
+	resp, err := githubGet(cfg, cfg.Update.latestURL(), "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && cfg.Update.URL == "" && githubToken(cfg) == "" {
+		return nil, fmt.Errorf("no releases found in %s; for a private repo, run with --github-login or set GITHUB_TOKEN", cfg.Update.Repo)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch release info: %s", resp.Status)
+	}
+
+	var r releaseInfo
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to parse release info: %w", err)
+	}
+	return &r, nil
+}
+
+// downloadAsset saves an asset from byte offset on to w, to resume an
+// interrupted download; downloadUpdate checks the whole file.
+func downloadAsset(cfg *appConfig, a releaseAsset, offset int64, w io.Writer) error {
+	u, accept := a.downloadURL(cfg)
+	req, err := githubRequest(cfg, u, accept)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The range was ignored; skip what we have
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			return fmt.Errorf("failed to download: %w", err)
+		}
+	default:
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to save download: %w", err)
+	}
+	return nil
+}
+
+// newAppConfig returns the settings app.json overrides.
+func newAppConfig() *appConfig {
+	return &appConfig{
 		URL:    "https://google.com",
 		Name:   "Gio WebViewer",
 		Width:  1200,
 		Height: 800,
+
+		RememberWindow: true,
+		Idle:           idleConfig{ClearSession: true},
 	}
+}
+
+// loadAppConfig tries to load app.json from the executable's directory first,
+// then the current working directory. Returns defaults if not found.
+func loadAppConfig() *appConfig {
+	cfg := newAppConfig()
 
 	// Try executable directory first (for pre-built shell binaries)
 	if exePath, err := os.Executable(); err == nil {
@@ -102,38 +282,30 @@ func loadAppConfig() *appConfig {
 
 // selfUpdate downloads the latest release asset and replaces the current binary.
 // Only works on desktop (macOS, Windows, Linux). Mobile uses app stores.
-func selfUpdate(cfg *appConfig) error {
+// installed is false when the running build is already the latest release
+// or the release's rollout does not include this machine.
+func selfUpdate(cfg *appConfig) (installed bool, err error) {
 	switch runtime.GOOS {
 	case "darwin", "linux", "windows":
 		// OK — desktop can self-update
 	default:
-		return fmt.Errorf("self-update not supported on %s (use app store)", runtime.GOOS)
+		return false, fmt.Errorf("self-update not supported on %s (use app store)", runtime.GOOS)
 	}
-	if cfg.Update.Repo == "" || cfg.Update.Asset == "" {
-		return fmt.Errorf("update not configured in app.json (need update.repo and update.asset)")
+	if !cfg.Update.configured() {
+		return false, fmt.Errorf("update not configured in app.json (need update.repo or update.url, and update.asset)")
 	}
 
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", cfg.Update.Repo)
-	resp, err := http.Get(apiURL)
+	release, err := fetchLatestRelease(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to check for updates: %w", err)
+		return false, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to fetch release info: %s", resp.Status)
-	}
-
-	var release struct {
-		TagName string `json:"tag_name"`
-		Assets  []struct {
-			Name               string `json:"name"`
-			BrowserDownloadURL string `json:"browser_download_url"`
-		} `json:"assets"`
+	if v := buildVersion(); v != "" && strings.TrimPrefix(v, "v") == strings.TrimPrefix(release.TagName, "v") {
+		fmt.Println(tr("Already up to date (%s)", release.TagName))
+		return false, nil
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return fmt.Errorf("failed to parse release info: %w", err)
+	if !inRollout(release.TagName, release.RolloutPercentage) {
+		fmt.Println(tr("%s is rolling out to %d%% of installs and does not include this machine yet", release.TagName, *release.RolloutPercentage))
+		return false, nil
 	}
 
 	// Find matching asset: e.g. "webviewer-shell-macos.zip" for asset prefix "webviewer-shell"
@@ -144,84 +316,132 @@ func selfUpdate(cfg *appConfig) error {
 	}
 	wantPrefix := fmt.Sprintf("%s-%s", cfg.Update.Asset, osName)
 
-	var downloadURL, assetName string
-	for _, a := range release.Assets {
+	var asset *releaseAsset
+	for i, a := range release.Assets {
 		if len(a.Name) >= len(wantPrefix) && a.Name[:len(wantPrefix)] == wantPrefix {
-			downloadURL = a.BrowserDownloadURL
-			assetName = a.Name
+			asset = &release.Assets[i]
 			break
 		}
 	}
 
-	if downloadURL == "" {
-		return fmt.Errorf("no matching asset for %s in release %s", wantPrefix, release.TagName)
+	if asset == nil {
+		return false, fmt.Errorf("no matching asset for %s in release %s", wantPrefix, release.TagName)
 	}
 
-	fmt.Printf("Downloading %s (%s)...\n", assetName, release.TagName)
-
-	// Download to temp file
-	tmpFile, err := os.CreateTemp("", "webviewer-update-*")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+	if err := cfg.Update.Download.check(asset.Size); err != nil {
+		return false, err
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
+	fmt.Println(tr("Downloading %s (%s)...", asset.Name, release.TagName))
 
-	dlResp, err := http.Get(downloadURL)
+	// Download next to earlier partial downloads, so an interrupted one resumes
+	archive, err := downloadUpdate(cfg, *asset, release.TagName)
 	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
+		return false, err
 	}
-	defer dlResp.Body.Close()
-
-	if dlResp.StatusCode != 200 {
-		return fmt.Errorf("download failed: %s", dlResp.Status)
-	}
-
-	if _, err := io.Copy(tmpFile, dlResp.Body); err != nil {
-		return fmt.Errorf("failed to save download: %w", err)
-	}
-	tmpFile.Close()
+	defer os.Remove(archive)
 
 	// Get current executable path
 	exePath, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return false, fmt.Errorf("failed to get executable path: %w", err)
 	}
 	exePath, _ = filepath.EvalSymlinks(exePath)
 	exeDir := filepath.Dir(exePath)
 
+	// Keep the current binary so a crash-looping update can be rolled back
+	if err := backupForUpdate(exePath, release.TagName); err != nil {
+		return false, fmt.Errorf("failed to back up current version: %w", err)
+	}
+
 	// Unzip the downloaded archive into the executable's directory
-	if err := unzipUpdate(tmpFile.Name(), exeDir); err != nil {
-		return fmt.Errorf("failed to extract update: %w", err)
+	if err := unzipUpdate(archive, exeDir); err != nil {
+		return false, fmt.Errorf("failed to extract update: %w", err)
 	}
 
-	fmt.Printf("Updated to %s\n", release.TagName)
+	fmt.Println(tr("Updated to %s", release.TagName))
+	return true, nil
+}
+
+// restartApp starts the executable again with the same arguments and
+// exits cleanly; used after an update and by the watchdog.
+func restartApp() error {
+	return restart(os.Args[1:])
+}
+
+// restart starts the app again with args and exits.
+func restart(args []string) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exePath, args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	life.exit(0)
 	return nil
 }
 
-// checkForUpdate quietly checks GitHub for a newer release and prints a notice.
+// checkForUpdate quietly checks for a newer release and prints a notice.
 // Runs in a goroutine so it never blocks app startup.
 func checkForUpdate(cfg *appConfig) {
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", cfg.Update.Repo)
-	resp, err := http.Get(apiURL)
+	release, err := fetchLatestRelease(cfg)
 	if err != nil {
 		return // silently ignore network errors
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return
+
+	if release.TagName != "" && inRollout(release.TagName, release.RolloutPercentage) {
+		fmt.Println(tr("[update] Latest release: %s — run with --update to install", release.TagName))
 	}
+}
 
-	var release struct {
-		TagName string `json:"tag_name"`
+// inRollout reports whether this machine is offered a staged release
+// (same hashing as goup-util's pkg/updater). GitHub releases have no
+// rollout percentage and go to everyone.
+func inRollout(version string, percentage *int) bool {
+	if percentage == nil || *percentage >= 100 {
+		return true
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return
+	if *percentage <= 0 {
+		return false
 	}
+	sum := sha256.Sum256([]byte(machineID() + "/" + version))
+	return binary.BigEndian.Uint64(sum[:8])%100 < uint64(*percentage)
+}
 
-	if release.TagName != "" {
-		fmt.Printf("[update] Latest release: %s — run with --update to install\n", release.TagName)
+// machineID returns the OS machine ID, or a random one saved next to the
+// user's config on first use.
+func machineID() string {
+	switch runtime.GOOS {
+	case "linux":
+		if data, err := os.ReadFile("/etc/machine-id"); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+			return strings.TrimSpace(string(data))
+		}
+	case "darwin":
+		if out, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output(); err == nil {
+			if m := regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`).FindSubmatch(out); m != nil {
+				return string(m[1])
+			}
+		}
+	case "windows":
+		out, err := exec.Command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid").Output()
+		if fields := strings.Fields(string(out)); err == nil && len(fields) > 0 && strings.Contains(string(out), "MachineGuid") {
+			return fields[len(fields)-1]
+		}
+	}
+	dir, _ := os.UserConfigDir()
+	path := filepath.Join(dir, "goup-util", "machine-id")
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		return strings.TrimSpace(string(data))
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+	if os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		os.WriteFile(path, []byte(id+"\n"), 0644)
 	}
+	return id
 }
 
 // unzipUpdate extracts a zip file to the destination directory.
@@ -245,73 +465,247 @@ func unzipUpdate(zipPath, destDir string) error {
 }
 
 func main() {
-	proxy := flag.String("proxy", "", "proxy")
+	proxy := flag.String("proxy", "", "proxy URL (overrides app.json)")
+	proxyProfile := flag.String("proxy-profile", "", "named proxy from app.json \"proxies\"")
 	update := flag.Bool("update", false, "self-update from GitHub releases")
-	if proxy != nil && *proxy != "" {
-		u, err := url.Parse(*proxy)
-		if err != nil {
-			panic(err)
-		}
-		if err := webview.SetProxy(u); err != nil {
-			panic(err)
-		}
-	}
+	githubLoginFlag := flag.Bool("github-login", false, "sign in to GitHub for updates from a private repo")
+	version := flag.Bool("version", false, "print the app name, build environment and platform")
+	healthcheckURL := flag.String("healthcheck-url", "", "ping this monitoring URL on a schedule (overrides app.json)")
+	softwareRender := flag.Bool("software-render", false, "render without the GPU, to work around graphics driver bugs")
+	logNetwork := flag.Bool("network-log", false, "log the pages' requests to network.har in the config directory")
+	profile := flag.String("profile", "", "named profile from app.json \"profiles\" (skips the picker)")
+	setAdminPINFlag := flag.Bool("set-admin-pin", false, "read a new PIN for the settings overlay from stdin")
 	flag.Parse()
 
-	// Load config from app.json (if present)
-	cfg := loadAppConfig()
+	// Load config from app.json (if present), with the cached remote overrides
+	reloader, cfg := newConfigReloader(loadAppConfig())
+	declareBridgePermissions(cfg)
+	setupLocale(cfg.Locale)
+
+	if *version {
+		fmt.Println(cfg.versionInfo())
+		os.Exit(0)
+	}
 
 	// Validate URL for non-dev users
 	if cfg.URL == "" {
-		fmt.Fprintln(os.Stderr, "ERROR: No URL configured. Edit app.json and set \"url\" to your website address.")
+		fmt.Fprintln(os.Stderr, tr("ERROR: No URL configured. Edit app.json and set \"url\" to your website address."))
 		fmt.Fprintln(os.Stderr, "Example: {\"url\": \"https://your-website.com\", \"name\": \"My App\"}")
 		os.Exit(1)
 	}
 	if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
-		fmt.Fprintf(os.Stderr, "ERROR: Invalid URL in app.json: %q\n", cfg.URL)
-		fmt.Fprintln(os.Stderr, "URL must start with http:// or https://")
+		fmt.Fprintln(os.Stderr, tr("ERROR: Invalid URL in app.json: %q", cfg.URL))
+		fmt.Fprintln(os.Stderr, tr("URL must start with http:// or https://"))
 		fmt.Fprintln(os.Stderr, "Example: {\"url\": \"https://your-website.com\"}")
 		os.Exit(1)
 	}
 
+	if *githubLoginFlag {
+		if err := githubLogin(cfg); err != nil {
+			fmt.Fprintln(os.Stderr, tr("GitHub login failed: %v", err))
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *setAdminPINFlag {
+		if err := setAdminPIN(cfg); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Setting the admin PIN failed: %v", err))
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Handle --update flag
 	if *update {
-		if err := selfUpdate(cfg); err != nil {
-			fmt.Fprintf(os.Stderr, "Update failed: %v\n", err)
+		if _, err := updateWhenAllowed(cfg); err != nil {
+			fmt.Fprintln(os.Stderr, tr("Update failed: %v", err))
 			os.Exit(1)
 		}
 		os.Exit(0)
 	}
 
+	if err := applyStartAtLogin(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, tr("Warning: start at login: %v", err))
+	}
+
+	// Windows machines without WebView2 would otherwise show an empty window
+	if err := ensureWebView2(cfg.Name); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Roll back an update that keeps crashing
+	stopGuard := startCrashGuard(cfg)
+	life.onShutdown("crash guard", func(context.Context) error {
+		stopGuard() // Closing or restarting is not a crash
+		return nil
+	})
+	life.handleSignals()
+
+	// Proxy must be set before the first webview is created
+	proxyCfg, err := selectProxy(cfg, *proxy, *proxyProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	if err := applyProxy(proxyCfg); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	// Like the proxy, each profile's storage directory is set up front
+	profiles, err := newProfileSet(cfg, *profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	cas, err := loadTrustedCAs(cfg.TLS.CA)
+	if err == nil {
+		err = applyTrustedCAs(cas)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	pins, err := newCertPins(cfg, cas, proxyCfg.URL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Like the proxy, this must happen before the window and first webview
+	if *softwareRender {
+		if err := disableGPU(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	filter, err := newURLFilter(cfg.Filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	if Theme, err = cfg.palette(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Display.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Idle.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Reload.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
 	DefaultURL = cfg.URL
-	fmt.Printf("Loading %s (%s)\n", cfg.Name, cfg.URL)
+	fmt.Println(tr("Loading %s (%s)", cfg.Name, cfg.URL))
 
 	// Check for updates in the background (non-blocking)
-	if cfg.Update.Repo != "" && cfg.Update.Asset != "" {
+	if cfg.Update.configured() {
 		go checkForUpdate(cfg)
 	}
 
 	webview.SetDebug(true)
 	window := &app.Window{}
-	window.Option(app.Title(cfg.Name))
+	window.Option(app.Title(cfg.title()))
 	window.Option(app.Size(unit.Dp(cfg.Width), unit.Dp(cfg.Height)))
 
 	browsers := NewBrowser()
+	browsers.Media = cfg.Media
+	browsers.Filter = filter
+	browsers.CSP = newContentPolicy(cfg)
+	browsers.Audit = newPageAudit(cfg)
+	browsers.MinFontSize = cfg.Accessibility.MinFontSize
+	if cfg.Accessibility.Keyboard {
+		browsers.showKeyboardButton()
+	}
+	browsers.showNavButtons(cfg.Touch)
+	browsers.Swipe = cfg.Touch.Swipe
+	browsers.Idle = newIdleTimer(cfg)
+	browsers.Admin = newAdminPanel(cfg, window, browsers.Actions)
+	if browsers.Admin != nil {
+		browsers.showSettingsButton()
+	}
+	if pins != nil {
+		pins.check(life.ctx) // Before the first page loads
+		go pins.watch(life.ctx, browsers.Actions, window.Invalidate)
+		browsers.Pins = pins
+	}
+	browsers.Profiles = profiles
+	browsers.Bridge = newBridge(cfg.Bridge, cfg.Name, cfg.URL, window, profiles)
+	if browsers.Bridge != nil {
+		life.onShutdown("bridge", browsers.Bridge.close)
+	}
+	if *logNetwork {
+		cfg.NetworkLog.Enabled = true
+	}
+	browsers.Network = startNetworkLog(life.ctx, cfg)
+	if browsers.Network != nil {
+		life.onShutdown("network log", browsers.Network.save)
+	}
+	browsers.Session = startSession(life.ctx, func(e sessionEvent) {
+		browsers.Actions.push(pageAction{Replay: &e})
+		window.Invalidate()
+	})
+	browsers.Flags = startFlags(life.ctx, cfg.Name, cfg.Flags, window.Invalidate)
+	startFleet(life.ctx, cfg, browsers.Actions, window.Invalidate)
+	reloader.start(life.ctx, window, browsers.Actions)
+	if *healthcheckURL == "" {
+		*healthcheckURL = cfg.Healthcheck.URL
+	}
+	browsers.Health = startHealthcheck(life.ctx, cfg, *healthcheckURL, window.Invalidate)
+	if err := startWatchdog(life.ctx, cfg, browsers.Actions, window.Invalidate, restartApp); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	keys, err := newHotkeys(window, cfg.Hotkeys, browsers.Actions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
 	browsers.add()
 	browsers.InitialURL = DefaultURL
+	if page := onboardingURL(cfg, DefaultURL); page != "" {
+		browsers.InitialURL = page
+	}
 	browsers.Address[0].SetText(DefaultURL)
 
 	go func() {
 		ops := new(op.Ops)
+		placed := false
+		saver := newWindowSaver(window, cfg)
 		for {
 			evt := gioplugins.Hijack(window)
 
 			switch evt := evt.(type) {
 			case app.DestroyEvent:
-				os.Exit(0)
+				life.exit(0)
 				return
+			case app.ViewEvent:
+				if evt.Valid() && !placed {
+					placed = true
+					go placeWindow(window, evt, cfg.Display, saver)
+					if keys != nil {
+						go keys.register()
+					}
+				}
+			case app.ConfigEvent:
+				if saver != nil {
+					saver.config(evt.Config)
+				}
+				if keys != nil {
+					keys.configure(evt.Config)
+				}
 			case app.FrameEvent:
 				gtx := app.NewContext(ops, evt)
+				gtx.Metric = cfg.Accessibility.metric(cfg.Display.metric(gtx.Metric))
 				browsers.Layout(gtx)
 				evt.Frame(ops)
 			}
@@ -330,9 +724,11 @@ const (
 type Browsers struct {
 	Selected int
 
-	Go    widget.Clickable
-	Add   widget.Clickable
-	Close widget.Clickable
+	Go      widget.Clickable
+	Add     widget.Clickable
+	Close   widget.Clickable
+	Mute    widget.Clickable
+	MuteAll widget.Clickable
 
 	JavascriptCode widget.Editor
 	JavascriptRun  widget.Clickable
@@ -342,6 +738,58 @@ type Browsers struct {
 
 	Tags   []*int
 	Titles []string
+	Muted  []bool
+
+	// Media is the sound/autoplay policy from app.json.
+	Media mediaConfig
+	// Filter blocks navigation to disallowed hosts (nil allows everything).
+	Filter *urlFilter
+	// Pins blocks pinned hosts whose certificate failed the check (nil
+	// without tls.pins).
+	Pins *certPins
+	// CSP adds app.json's csp.policy to the site's pages and logs
+	// violations (nil without one).
+	CSP *contentPolicy
+	// Audit logs screen reader problems of the pages (nil unless
+	// accessibility.audit is set).
+	Audit *pageAudit
+	// MinFontSize is accessibility.minFontSize, enforced in the pages.
+	MinFontSize int
+	// Keyboard toggles the on-screen keyboard, when shown.
+	Keyboard widget.Clickable
+	// Back and Home navigate the selected tab, when app.json's touch
+	// section shows them; Swipe adds edge swipes to the pages.
+	Back  widget.Clickable
+	Home  widget.Clickable
+	Swipe bool
+	// Profiles is who uses the shell; its picker shows before the pages
+	// until someone is picked (nil without profiles).
+	Profiles *profileSet
+	// Idle shows the attract page, or url, once nobody has used the
+	// shell for idle.minutes or touch.idleSeconds (nil without either).
+	Idle *idleTimer
+	// Admin is the PIN-locked settings overlay, opened with Settings (nil
+	// unless admin.enabled is set).
+	Admin    *adminPanel
+	Settings widget.Clickable
+	// Bridge serves window.goup to trusted pages (nil when disabled).
+	Bridge *bridge
+	// prepared records which tabs already have their page scripts installed.
+	prepared []bool
+	// Flags are the remote feature flags (nil when app.json sets none);
+	// flagsVersion is the version last sent to the pages.
+	Flags        *flagStore
+	flagsVersion int
+	// Actions are reloads and scripts requested from outside the UI
+	// goroutine, by fleet commands, the watchdog and hotkeys.
+	Actions *pageActions
+	// Health records frames for the health check pings (nil without one).
+	Health *uiHealth
+	// Session records page loads and bridge calls for 'goup-util replay'
+	// (nil unless started with 'goup-util run --record').
+	Session *sessionRecorder
+	// Network logs the pages' requests (nil unless enabled).
+	Network *networkLog
 
 	LocalStorage   [][]webview.StorageData
 	SessionStorage [][]webview.StorageData
@@ -362,12 +810,74 @@ type Browsers struct {
 	frameCount int
 }
 
+// pageAction reloads every tab, with URL if set, after clearing the
+// webview cache if ClearCache is set. With Script set, it runs the script
+// in the selected tab instead, with Replay set, it re-injects a recorded
+// session event there, and with Config set, it applies a config reload.
+type pageAction struct {
+	URL        string
+	ClearCache bool
+	Script     string
+	Replay     *sessionEvent
+	Config     *configChange
+}
+
+// pageActions queues page actions for Browsers.Layout.
+type pageActions struct {
+	mu      sync.Mutex
+	pending []pageAction
+}
+
+func (q *pageActions) push(a pageAction) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, a)
+}
+
+func (q *pageActions) take() []pageAction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	actions := q.pending
+	q.pending = nil
+	return actions
+}
+
+// guard returns target, or the page explaining why it may not load:
+// the filter blocks it, or its certificate failed the pin check.
+func (b *Browsers) guard(target string) string {
+	if !b.Filter.Allowed(target) {
+		return blockedPage(target)
+	}
+	if !b.Pins.Allowed(target) {
+		return untrustedPage(target)
+	}
+	return target
+}
+
+// replay re-injects a recorded session event into the selected tab:
+// deep links and page loads navigate it, bridge calls reach the bridge
+// as if the page had sent them.
+func (b *Browsers) replay(gtx layout.Context, e sessionEvent) {
+	i := b.Selected
+	switch e.Kind {
+	case "url", "navigate":
+		target := e.URL
+		target = b.guard(target)
+		b.prepare(gtx, i)
+		gioplugins.Execute(gtx, giowebview.NavigateCmd{View: b.Tags[i], URL: target})
+	case "bridge":
+		if b.Bridge != nil {
+			b.Bridge.message(gtx, b.Tags[i], b.Address[i].Text(), e.Data)
+		}
+	}
+}
+
 func NewBrowser() *Browsers {
-	b := &Browsers{}
+	b := &Browsers{Actions: &pageActions{}}
 	b.HeaderFlex = []layout.FlexChild{
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 			defer clip.Outline{Path: clip.Rect{Max: gtx.Constraints.Max}.Path()}.Op().Push(gtx.Ops).Pop()
-			paint.ColorOp{Color: color.NRGBA{R: 24, G: 26, B: 33, A: 255}}.Add(gtx.Ops)
+			paint.ColorOp{Color: Theme.Address}.Add(gtx.Ops)
 			paint.PaintOp{}.Add(gtx.Ops)
 
 			gtx.Constraints.Min.Y = 0
@@ -375,11 +885,11 @@ func NewBrowser() *Browsers {
 			macro := op.Record(gtx.Ops)
 
 			textMaterial := op.Record(gtx.Ops)
-			paint.ColorOp{Color: color.NRGBA{R: 255, G: 255, B: 255, A: 255}}.Add(gtx.Ops)
+			paint.ColorOp{Color: Theme.Text}.Add(gtx.Ops)
 			tmat := textMaterial.Stop()
 
 			selectMaterial := op.Record(gtx.Ops)
-			paint.ColorOp{Color: color.NRGBA{R: 123, G: 123, B: 123, A: 255}}.Add(gtx.Ops)
+			paint.ColorOp{Color: Theme.Selection}.Add(gtx.Ops)
 			smat := selectMaterial.Stop()
 
 			dims := b.Address[b.Selected].Layout(gtx, GlobalShaper, font.Font{}, gtx.Metric.DpToSp(16), tmat, smat)
@@ -393,11 +903,27 @@ func NewBrowser() *Browsers {
 			return layout.Dimensions{Size: gtx.Constraints.Max}
 		}),
 		layout.Rigid(layout.Spacer{Width: 4}.Layout),
-		layout.Rigid(Button{Clickable: &b.Go, Icon: IconGo, Text: "Go"}.Layout),
+		layout.Rigid(Button{Clickable: &b.Go, Icon: IconGo, Text: tr("Go")}.Layout),
 		layout.Rigid(layout.Spacer{Width: 4}.Layout),
-		layout.Rigid(Button{Clickable: &b.Close, Icon: IconClose, Text: "Close"}.Layout),
+		layout.Rigid(Button{Clickable: &b.Close, Icon: IconClose, Text: tr("Close")}.Layout),
+		layout.Rigid(layout.Spacer{Width: 4}.Layout),
+		layout.Rigid(Button{Clickable: &b.Add, Icon: IconAdd, Text: tr("Add")}.Layout),
+		layout.Rigid(layout.Spacer{Width: 4}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			icon := IconVolumeUp
+			if b.Muted[b.Selected] {
+				icon = IconVolumeOff
+			}
+			return Button{Clickable: &b.Mute, Icon: icon}.Layout(gtx)
+		}),
 		layout.Rigid(layout.Spacer{Width: 4}.Layout),
-		layout.Rigid(Button{Clickable: &b.Add, Icon: IconAdd, Text: "Add"}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			text := tr("Mute all")
+			if b.allMuted() {
+				text = tr("Unmute all")
+			}
+			return Button{Clickable: &b.MuteAll, Text: text}.Layout(gtx)
+		}),
 		layout.Rigid(layout.Spacer{Width: 4}.Layout),
 		layout.Rigid(Button{Clickable: &b.CookieButton, Icon: IconCookie}.Layout),
 		layout.Rigid(layout.Spacer{Width: 4}.Layout),
@@ -412,6 +938,8 @@ func (b *Browsers) add() {
 	b.Tabs = append(b.Tabs, widget.Clickable{})
 	b.Tags = append(b.Tags, new(int))
 	b.Titles = append(b.Titles, "")
+	b.Muted = append(b.Muted, b.Media.Muted)
+	b.prepared = append(b.prepared, false)
 	b.Address = append(b.Address, widget.Editor{SingleLine: true, Submit: true})
 	b.LocalStorage = append(b.LocalStorage, nil)
 	b.SessionStorage = append(b.SessionStorage, nil)
@@ -434,6 +962,8 @@ func (b *Browsers) remove(i int) {
 	b.Tabs = append(b.Tabs[:i], b.Tabs[i+1:]...)
 	b.Tags = append(b.Tags[:i], b.Tags[i+1:]...)
 	b.Titles = append(b.Titles[:i], b.Titles[i+1:]...)
+	b.Muted = append(b.Muted[:i], b.Muted[i+1:]...)
+	b.prepared = append(b.prepared[:i], b.prepared[i+1:]...)
 	b.TabsFlex = append(b.TabsFlex[:i], b.TabsFlex[i+1:]...)
 	b.Address = append(b.Address[:i], b.Address[i+1:]...)
 	b.SessionStorage = append(b.SessionStorage[:i], b.SessionStorage[i+1:]...)
@@ -441,8 +971,62 @@ func (b *Browsers) remove(i int) {
 	b.CookieStorage = append(b.CookieStorage[:i], b.CookieStorage[i+1:]...)
 }
 
+// prepare installs the page scripts for tab i before its first navigation.
+func (b *Browsers) prepare(gtx layout.Context, i int) {
+	if b.prepared[i] {
+		return
+	}
+	b.Profiles.prepare(gtx, b.Tags[i])
+	gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: b.Tags[i], Script: mediaScript(b.Media, b.Muted[i])})
+	gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: b.Tags[i], Script: themeScript(Theme)})
+	if script := b.Filter.script(); script != "" {
+		gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: b.Tags[i], Script: script})
+	}
+	if b.Flags != nil {
+		values, _ := b.Flags.get()
+		gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: b.Tags[i], Script: flagsScript(values)})
+	}
+	b.CSP.prepare(gtx, b.Tags[i])
+	b.Audit.prepare(gtx, b.Tags[i])
+	b.Idle.prepare(gtx, b.Tags[i])
+	if b.Swipe {
+		gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: b.Tags[i], Script: swipeScript})
+	}
+	if b.MinFontSize > 0 {
+		gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: b.Tags[i], Script: minFontScript(b.MinFontSize)})
+	}
+	b.Bridge.prepare(gtx, b.Tags[i])
+	b.Network.prepare(gtx, b.Tags[i])
+	b.prepared[i] = true
+}
+
+// setMuted mutes or unmutes tab i, applying it to the loaded page if any.
+func (b *Browsers) setMuted(gtx layout.Context, i int, muted bool) {
+	b.Muted[i] = muted
+	if b.prepared[i] {
+		gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{View: b.Tags[i], Script: setMutedScript(muted)})
+	}
+}
+
+// allMuted reports whether every tab is muted.
+func (b *Browsers) allMuted() bool {
+	for _, m := range b.Muted {
+		if !m {
+			return false
+		}
+	}
+	return true
+}
+
 func (b *Browsers) Layout(gtx layout.Context) layout.Dimensions {
+	if b.Health != nil {
+		b.Health.drew()
+	}
+	if b.Profiles.picking() {
+		return b.Profiles.layout(gtx)
+	}
 	b.frameCount++
+	b.Bridge.update(gtx)
 
 	if b.Add.Clicked(gtx) {
 		b.add()
@@ -450,6 +1034,34 @@ func (b *Browsers) Layout(gtx layout.Context) layout.Dimensions {
 	if b.Close.Clicked(gtx) {
 		b.remove(b.Selected)
 	}
+	if b.Mute.Clicked(gtx) {
+		b.setMuted(gtx, b.Selected, !b.Muted[b.Selected])
+	}
+	if b.Back.Clicked(gtx) {
+		b.goBack(gtx)
+	}
+	if b.Home.Clicked(gtx) {
+		b.goHome(gtx, b.Selected)
+	}
+	if b.Idle.update(gtx) {
+		b.attract(gtx)
+	}
+	if b.Settings.Clicked(gtx) {
+		b.Admin.toggle()
+	}
+	if b.Keyboard.Clicked(gtx) {
+		go func() {
+			if err := toggleKeyboard(); err != nil {
+				fmt.Fprintln(os.Stderr, tr("On-screen keyboard: %v", err))
+			}
+		}()
+	}
+	if b.MuteAll.Clicked(gtx) {
+		muted := !b.allMuted()
+		for i := range b.Tags {
+			b.setMuted(gtx, i, muted)
+		}
+	}
 
 	currentStoragePanel := b.StorageVisible
 	if b.LocalButton.Clicked(gtx) {
@@ -468,6 +1080,49 @@ func (b *Browsers) Layout(gtx layout.Context) layout.Dimensions {
 		submittedIndex = b.Selected
 	}
 
+	// Push changed feature flags to the loaded pages
+	if b.Flags != nil {
+		if values, version := b.Flags.get(); version != b.flagsVersion {
+			for i := range b.Tags {
+				if b.prepared[i] {
+					gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{View: b.Tags[i], Script: flagsScript(values)})
+				}
+			}
+			b.flagsVersion = version
+		}
+	}
+
+	// Reloads requested by fleet commands, the watchdog and hotkeys,
+	// session replays and config reloads
+	for _, a := range b.Actions.take() {
+		if a.Config != nil {
+			b.reconfigure(gtx, *a.Config)
+			continue
+		}
+		if a.Replay != nil {
+			b.replay(gtx, *a.Replay)
+			continue
+		}
+		if a.Script != "" {
+			if b.prepared[b.Selected] {
+				gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{View: b.Tags[b.Selected], Script: a.Script})
+			}
+			continue
+		}
+		for i := range b.Tags {
+			target := a.URL
+			if target == "" {
+				target = b.Address[i].Text()
+			}
+			if a.ClearCache {
+				gioplugins.Execute(gtx, giowebview.ClearCacheCmd{View: b.Tags[i]})
+			}
+			target = b.guard(target)
+			b.prepare(gtx, i)
+			gioplugins.Execute(gtx, giowebview.NavigateCmd{View: b.Tags[i], URL: target})
+		}
+	}
+
 	// Auto-navigate initial URL after webview has initialized
 	autoNavigate := b.InitialURL != "" && !b.navigated && b.frameCount > 10
 
@@ -492,7 +1147,13 @@ func (b *Browsers) Layout(gtx layout.Context) layout.Dimensions {
 		}
 
 		if submited {
-			gioplugins.Execute(gtx, giowebview.NavigateCmd{View: b.Tags[i], URL: t.Text()})
+			b.prepare(gtx, i)
+			target := t.Text()
+			if autoNavigate && i == 0 {
+				target = b.InitialURL
+			}
+			target = b.guard(target)
+			gioplugins.Execute(gtx, giowebview.NavigateCmd{View: b.Tags[i], URL: target})
 		}
 	}
 
@@ -513,25 +1174,50 @@ func (b *Browsers) Layout(gtx layout.Context) layout.Dimensions {
 			case giowebview.TitleEvent:
 				b.Titles[i] = evt.Title
 			case giowebview.NavigationEvent:
+				if isShellPage(evt.URL) {
+					continue
+				}
+				if page := b.guard(evt.URL); page != evt.URL {
+					b.Address[i].SetText(evt.URL)
+					gioplugins.Execute(gtx, giowebview.NavigateCmd{View: b.Tags[i], URL: page})
+					continue
+				}
 				b.Address[i].SetText(evt.URL)
+				b.Session.record("navigate", evt.URL, "")
+				// The installed script carries the mute state from when the tab opened
+				gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{View: b.Tags[i], Script: setMutedScript(b.Muted[i])})
+				// The installed script carries the flags from when the tab opened
+				if b.Flags != nil {
+					values, _ := b.Flags.get()
+					gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{View: b.Tags[i], Script: flagsScript(values)})
+				}
 			case giowebview.CookiesEvent:
 				fmt.Println(evt.Cookies)
 			case giowebview.StorageEvent:
 				fmt.Println(evt.Storage)
 			case giowebview.MessageEvent:
-				fmt.Println(evt.Message)
+				b.Session.recordBridge(b.Address[i].Text(), evt.Message)
+				if b.Bridge != nil {
+					b.Bridge.message(gtx, b.Tags[i], b.Address[i].Text(), evt.Message)
+				} else {
+					fmt.Println(evt.Message)
+				}
 			}
 		}
 	}
+	b.Network.update(gtx)
+	b.CSP.update(gtx)
+	b.Audit.update(gtx)
 
 	gtxi := gtx
+	defer b.Idle.watch(gtx)
 	return Rows{}.Layout(gtx, 4, func(i int, gtx layout.Context) layout.Dimensions {
 		switch i {
 		case 0:
 			gtx.Constraints.Max.Y = gtx.Dp(48)
 			gtx.Constraints.Min.Y = gtx.Constraints.Max.Y
 			defer clip.Outline{Path: clip.Rect{Max: gtx.Constraints.Max}.Path()}.Op().Push(gtx.Ops).Pop()
-			paint.ColorOp{Color: color.NRGBA{R: 48, G: 52, B: 67, A: 255}}.Add(gtx.Ops)
+			paint.ColorOp{Color: Theme.Toolbar}.Add(gtx.Ops)
 			paint.PaintOp{}.Add(gtx.Ops)
 
 			gtx.Constraints.Max.Y = gtx.Dp(40)
@@ -550,16 +1236,16 @@ func (b *Browsers) Layout(gtx layout.Context) layout.Dimensions {
 
 					defer clip.Outline{Path: clip.Rect{Max: gtx.Constraints.Max}.Path()}.Op().Push(gtx.Ops).Pop()
 					if b.Selected == i {
-						paint.ColorOp{Color: color.NRGBA{R: 48, G: 52, B: 67, A: 255}}.Add(gtx.Ops)
+						paint.ColorOp{Color: Theme.TabActive}.Add(gtx.Ops)
 					} else {
-						paint.ColorOp{Color: color.NRGBA{R: 61, G: 61, B: 69, A: 255}}.Add(gtx.Ops)
+						paint.ColorOp{Color: Theme.Tab}.Add(gtx.Ops)
 					}
 					paint.PaintOp{}.Add(gtx.Ops)
 
 					return b.Tabs[i].Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 						return layout.UniformInset(4).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 							colorMaterial := op.Record(gtx.Ops)
-							paint.ColorOp{Color: color.NRGBA{R: 255, G: 255, B: 255, A: 255}}.Add(gtx.Ops)
+							paint.ColorOp{Color: Theme.Text}.Add(gtx.Ops)
 							pcolor := colorMaterial.Stop()
 
 							macro := op.Record(gtx.Ops)
@@ -576,6 +1262,9 @@ func (b *Browsers) Layout(gtx layout.Context) layout.Dimensions {
 			}
 			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, b.TabsFlex...)
 		case 2:
+			if b.Admin.showing() {
+				return b.Admin.layout(gtx)
+			}
 			defer giowebview.WebViewOp{Tag: b.Tags[b.Selected]}.Push(gtx.Ops).Pop(gtx.Ops)
 			giowebview.OffsetOp{Point: f32.Point{Y: float32(gtxi.Constraints.Max.Y - gtx.Constraints.Max.Y)}}.Add(gtx.Ops)
 			giowebview.RectOp{Size: f32.Point{X: float32(gtx.Constraints.Max.X), Y: float32(gtx.Constraints.Max.Y)}}.Add(gtx.Ops)
@@ -633,7 +1322,7 @@ func (b Button) Layout(gtx layout.Context) layout.Dimensions {
 		macro := op.Record(gtx.Ops)
 
 		colorMaterial := op.Record(gtx.Ops)
-		c := color.NRGBA{R: 32, G: 32, B: 32, A: 255}
+		c := Theme.ButtonText
 		paint.ColorOp{Color: c}.Add(gtx.Ops)
 		pcolor := colorMaterial.Stop()
 
@@ -652,7 +1341,7 @@ func (b Button) Layout(gtx layout.Context) layout.Dimensions {
 		gtx.Constraints.Max.X = dims.Size.X + gtx.Dp(16)
 
 		defer clip.Outline{Path: clip.Rect{Max: gtx.Constraints.Max}.Path()}.Op().Push(gtx.Ops).Pop()
-		paint.ColorOp{Color: color.NRGBA{R: 237, G: 237, B: 237, A: 255}}.Add(gtx.Ops)
+		paint.ColorOp{Color: Theme.Button}.Add(gtx.Ops)
 		paint.PaintOp{}.Add(gtx.Ops)
 		pointer.CursorPointer.Add(gtx.Ops)
 
@@ -701,7 +1390,7 @@ func (l *Loading) Layout(gtx layout.Context) layout.Dimensions {
 	)
 
 	defer clip.Stroke{Path: path.End(), Width: width}.Op().Push(gtx.Ops).Pop()
-	paint.ColorOp{Color: color.NRGBA{R: 255, G: 255, B: 255, A: 255}}.Add(gtx.Ops)
+	paint.ColorOp{Color: Theme.Text}.Add(gtx.Ops)
 	paint.PaintOp{}.Add(gtx.Ops)
 	gtx.Execute(op.InvalidateCmd{})
 
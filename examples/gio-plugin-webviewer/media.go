@@ -0,0 +1,56 @@
+package main
+
+import "fmt"
+
+// Autoplay policies accepted in app.json "media.autoplay".
+const (
+	AutoplayAllow = "allow" // Pages may start playback freely (default)
+	AutoplayMuted = "muted" // Playback before a user gesture is forced muted
+	AutoplayBlock = "block" // Playback before a user gesture is paused
+)
+
+// mediaConfig controls sound in the shell's tabs.
+// Kiosk deployments typically start muted or block autoplay entirely.
+type mediaConfig struct {
+	Muted    bool   `json:"muted,omitempty"`    // Start every tab muted
+	Autoplay string `json:"autoplay,omitempty"` // "allow" (default), "muted" or "block"
+}
+
+// mediaScript returns the JavaScript installed into every page of a tab.
+// It enforces the autoplay policy on media started without a user gesture
+// and exposes window.goupMedia.setMuted so the shell can toggle sound. A
+// script installed after a config reload updates the first one's settings.
+func mediaScript(cfg mediaConfig, muted bool) string {
+	autoplay := cfg.Autoplay
+	if autoplay == "" {
+		autoplay = AutoplayAllow
+	}
+	return fmt.Sprintf(`(function (muted, autoplay) {
+  if (window.goupMedia) {
+    window.goupMedia.autoplay = autoplay;
+    window.goupMedia.setMuted(muted);
+    return;
+  }
+  var media = { muted: muted, autoplay: autoplay, gesture: false };
+  ["pointerdown", "keydown", "touchstart"].forEach(function (t) {
+    window.addEventListener(t, function () { media.gesture = true; }, true);
+  });
+  document.addEventListener("play", function (e) {
+    var el = e.target;
+    if (media.muted) { el.muted = true; }
+    if (media.gesture) { return; }
+    if (media.autoplay === "block") { el.pause(); }
+    else if (media.autoplay === "muted") { el.muted = true; }
+  }, true);
+  media.setMuted = function (m) {
+    media.muted = m;
+    document.querySelectorAll("audio,video").forEach(function (el) { el.muted = m; });
+  };
+  window.goupMedia = media;
+})(%t, %q);`, muted, autoplay)
+}
+
+// setMutedScript returns JavaScript that mutes or unmutes the current page.
+func setMutedScript(muted bool) string {
+	return fmt.Sprintf("window.goupMedia && window.goupMedia.setMuted(%t);", muted)
+}
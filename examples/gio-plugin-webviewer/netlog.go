@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"gioui.org/io/event"
+	"gioui.org/layout"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+
+// networkLogConfig records the requests the pages make, so problems with
+// a wrapped site can be looked into on machines where proxy tools cannot
+// be installed. The log is kept as network.har in the app's config
+// directory, which browser developer tools and HAR viewers open.
+type networkLogConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	Entries int  `json:"entries,omitempty"` // Most recent requests kept (default 1000)
+}
+
+const (
+	networkLogCallback = "goupnet"     // window.callback.goupnet(request)
+	networkLogFile     = "network.har" // In the app's config directory
+	networkLogEntries  = 1000
+	networkLogInterval = 10 * time.Second // How often a changed log is saved
+)
+
+// netRequest is a request reported by networkLogScript. Times are in
+// milliseconds.
+type netRequest struct {
+	Page       string      `json:"page"`
+	Type       string      `json:"type"` // "navigation", "fetch", "xmlhttprequest", "script", "img", ...
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Status     int         `json:"status"` // 0 if it failed or the site does not share it
+	StatusText string      `json:"statusText,omitempty"`
+	MimeType   string      `json:"mime,omitempty"`
+	Size       int64       `json:"size"` // Bytes transferred, -1 if unknown
+	Started    time.Time   `json:"started"`
+	Time       float64     `json:"time"`
+	Timings    *harTimings `json:"timings,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// HAR 1.2, as far as a page can see its own requests: there are no
+// headers or bodies.
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	Started   time.Time   `json:"startedDateTime"`
+	Time      float64     `json:"time"`
+	Request   harRequest  `json:"request"`
+	Response  harResponse `json:"response"`
+	Cache     struct{}    `json:"cache"`
+	Timings   harTimings  `json:"timings"`
+	Page      string      `json:"_page,omitempty"`
+	Initiator string      `json:"_initiator,omitempty"`
+	Error     string      `json:"_error,omitempty"`
+}
+
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`
+	HTTPVersion string    `json:"httpVersion"`
+	Headers     []harPair `json:"headers"`
+	QueryString []harPair `json:"queryString"`
+	Cookies     []harPair `json:"cookies"`
+	HeadersSize int       `json:"headersSize"`
+	BodySize    int       `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int        `json:"status"`
+	StatusText  string     `json:"statusText"`
+	HTTPVersion string     `json:"httpVersion"`
+	Headers     []harPair  `json:"headers"`
+	Cookies     []harPair  `json:"cookies"`
+	Content     harContent `json:"content"`
+	RedirectURL string     `json:"redirectURL"`
+	HeadersSize int        `json:"headersSize"`
+	BodySize    int64      `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// harTimings are the phases of a request; -1 is unknown.
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// entry converts r to a HAR entry.
+func (r netRequest) entry() harEntry {
+	timings := harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: r.Time}
+	if r.Timings != nil {
+		timings = *r.Timings
+	}
+	return harEntry{
+		Started: r.Started,
+		Time:    r.Time,
+		Request: harRequest{
+			Method:      r.Method,
+			URL:         r.URL,
+			Headers:     []harPair{},
+			QueryString: []harPair{},
+			Cookies:     []harPair{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Response: harResponse{
+			Status:      r.Status,
+			StatusText:  r.StatusText,
+			Headers:     []harPair{},
+			Cookies:     []harPair{},
+			Content:     harContent{Size: r.Size, MimeType: r.MimeType},
+			HeadersSize: -1,
+			BodySize:    r.Size,
+		},
+		Timings:   timings,
+		Page:      r.Page,
+		Initiator: r.Type,
+		Error:     r.Error,
+	}
+}
+
+// networkLog keeps the most recent requests and saves them as a HAR file.
+type networkLog struct {
+	path    string
+	max     int
+	creator harCreator
+
+	mu      sync.Mutex
+	entries []harEntry
+	dirty   bool
+
+	saving sync.Mutex // Held while the file is written
+}
+
+// startNetworkLog returns nil unless app.json or --network-log enables
+// it. Until ctx is done, a changed log is saved every few seconds, so it
+// is there for support even after a crash.
+func startNetworkLog(ctx context.Context, cfg *appConfig) *networkLog {
+	if !cfg.NetworkLog.Enabled {
+		return nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("Warning: network log: %v", err))
+		return nil
+	}
+	n := &networkLog{
+		path:    filepath.Join(dir, cfg.Name, networkLogFile),
+		max:     cfg.NetworkLog.Entries,
+		creator: harCreator{Name: cfg.Name, Version: buildVersion()},
+	}
+	if n.max <= 0 {
+		n.max = networkLogEntries
+	}
+	fmt.Println(tr("Logging network requests to %s", n.path))
+	go func() {
+		tick := time.NewTicker(networkLogInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				if err := n.save(ctx); err != nil {
+					fmt.Fprintln(os.Stderr, tr("Warning: network log: %v", err))
+				}
+			}
+		}
+	}()
+	return n
+}
+
+// prepare installs the request hooks into a tab; nil does nothing.
+func (n *networkLog) prepare(gtx layout.Context, view event.Tag) {
+	if n == nil {
+		return
+	}
+	gioplugins.Execute(gtx, giowebview.MessageReceiverCmd{View: view, Tag: n, Name: networkLogCallback})
+	gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: view, Script: networkLogScript})
+}
+
+// update logs the requests the pages reported. Call it once per frame.
+func (n *networkLog) update(gtx layout.Context) {
+	if n == nil {
+		return
+	}
+	for {
+		evt, ok := gioplugins.Event(gtx, giowebview.Filter{Target: n})
+		if !ok {
+			return
+		}
+		if msg, ok := evt.(giowebview.MessageEvent); ok {
+			n.add(msg.Message)
+		}
+	}
+}
+
+// add logs a request, dropping the oldest beyond the limit.
+func (n *networkLog) add(message string) {
+	r := netRequest{Size: -1}
+	if err := json.Unmarshal([]byte(message), &r); err != nil || r.URL == "" {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.entries = append(n.entries, r.entry())
+	if over := len(n.entries) - n.max; over > 0 {
+		n.entries = slices.Delete(n.entries, 0, over)
+	}
+	n.dirty = true
+}
+
+// save writes the log if it changed. The file is replaced whole, so a
+// reader never sees half of it. It is private to the user: URLs can
+// carry tokens.
+func (n *networkLog) save(context.Context) error {
+	n.saving.Lock()
+	defer n.saving.Unlock()
+	n.mu.Lock()
+	if !n.dirty {
+		n.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(harFile{Log: harLog{Version: "1.2", Creator: n.creator, Entries: n.entries}}, "", "  ")
+	n.dirty = false
+	n.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(n.path), 0755); err != nil {
+		return err
+	}
+	tmp := n.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, n.path)
+}
+
+// networkLogScript reports every request a page makes. fetch and
+// XMLHttpRequest are wrapped for their method and status; everything else
+// (the page itself, scripts, styles, images) comes from resource timing,
+// which only has the status and size of cross-origin requests when the
+// site allows it. Loads that fail are reported by the error event.
+const networkLogScript = `(function () {
+  if (window.__goupNetworkLog) { return; }
+  window.__goupNetworkLog = true;
+  function send(r) {
+    r.page = location.href;
+    try { window.callback.` + networkLogCallback + `(JSON.stringify(r)); } catch (e) {}
+  }
+  function abs(u) {
+    try { return new URL(String(u), location.href).href; } catch (e) { return String(u); }
+  }
+  function now() { return new Date().toISOString(); }
+
+  if (window.fetch) {
+    var fetch = window.fetch;
+    window.fetch = function (input, init) {
+      var r = {
+        type: "fetch",
+        method: String((init && init.method) || (input && input.method) || "GET").toUpperCase(),
+        url: abs((input && input.url) || input),
+        started: now()
+      };
+      var t0 = performance.now();
+      return fetch.apply(this, arguments).then(function (res) {
+        r.status = res.status;
+        r.statusText = res.statusText;
+        r.mime = res.headers.get("content-type") || "";
+        r.time = performance.now() - t0;
+        send(r);
+        return res;
+      }, function (err) {
+        r.time = performance.now() - t0;
+        r.error = String((err && err.message) || err);
+        send(r);
+        throw err;
+      });
+    };
+  }
+
+  var open = XMLHttpRequest.prototype.open, xsend = XMLHttpRequest.prototype.send;
+  XMLHttpRequest.prototype.open = function (method, u) {
+    this.__goup = { type: "xmlhttprequest", method: String(method).toUpperCase(), url: abs(u) };
+    return open.apply(this, arguments);
+  };
+  XMLHttpRequest.prototype.send = function () {
+    var xhr = this, r = this.__goup;
+    if (r) {
+      r.started = now();
+      var t0 = performance.now();
+      xhr.addEventListener("loadend", function () {
+        r.status = xhr.status;
+        r.statusText = xhr.statusText;
+        r.mime = xhr.getResponseHeader("content-type") || "";
+        r.time = performance.now() - t0;
+        if (!xhr.status) { r.error = "request failed"; }
+        send(r);
+      });
+    }
+    return xsend.apply(this, arguments);
+  };
+
+  function timing(e) {
+    if (e.initiatorType === "fetch" || e.initiatorType === "xmlhttprequest") { return; }
+    var r = {
+      type: e.initiatorType || e.entryType,
+      method: "GET",
+      url: e.name,
+      status: e.responseStatus || 0,
+      size: e.transferSize || -1,
+      started: new Date(performance.timeOrigin + e.startTime).toISOString(),
+      time: e.duration
+    };
+    if (e.requestStart) {
+      r.timings = {
+        blocked: -1,
+        dns: e.domainLookupEnd - e.domainLookupStart,
+        connect: e.connectEnd - e.connectStart,
+        ssl: e.secureConnectionStart ? e.connectEnd - e.secureConnectionStart : -1,
+        send: 0,
+        wait: e.responseStart - e.requestStart,
+        receive: e.responseEnd - e.responseStart
+      };
+    }
+    send(r);
+  }
+  if (window.PerformanceObserver) {
+    var observer = new PerformanceObserver(function (list) { list.getEntries().forEach(timing); });
+    try {
+      observer.observe({ type: "navigation", buffered: true });
+      observer.observe({ type: "resource", buffered: true });
+    } catch (e) {}
+  }
+
+  window.addEventListener("error", function (e) {
+    var t = e.target;
+    if (!t || t === window || !(t.src || t.href)) { return; }
+    send({ type: t.localName, method: "GET", url: abs(t.src || t.href), status: 0, started: now(), time: 0, error: "failed to load" });
+  }, true);
+})();`
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gioui.org/io/event"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+
+// errNotificationsOff is returned by showNotification when the user has
+// turned notifications off for the app.
+var errNotificationsOff = errors.New("notifications are turned off for this app")
+
+// notificationClick is a click on a notification shown for the page in
+// view.
+type notificationClick struct {
+	view event.Tag
+	url  string
+}
+
+// notify shows the notification a page asked for. Clicks are queued for
+// update, which brings the window to the front and opens the URL in the
+// tab that showed it.
+func (br *bridge) notify(view event.Tag, req bridgeRequest) error {
+	title := req.Title
+	if title == "" {
+		title = br.appName
+	}
+	return showNotification(br.appName, title, req.Body, func() {
+		br.clicks <- notificationClick{view: view, url: req.URL}
+		br.window.Invalidate()
+	})
+}
+
+// clicked raises the window and tells the page about the click. The page
+// script navigates to the URL unless a "goup-notification-click"
+// listener calls preventDefault, as single-page apps do to route
+// themselves.
+func (br *bridge) clicked(gtx layout.Context, c notificationClick) {
+	br.window.Perform(system.ActionRaise)
+	url, _ := json.Marshal(c.url)
+	gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{
+		View:   c.view,
+		Script: fmt.Sprintf("window.goup && window.goup._notificationClick && window.goup._notificationClick(%s);", url),
+	})
+}
+
+// notifyScript adds window.goup.notify(title, {body, url}) to the bridge
+// script. Relative URLs are resolved against the page.
+const notifyScript = `
+  goup.notify = function (title, options) {
+    options = options || {};
+    var url = options.url ? new URL(options.url, location.href).href : "";
+    return call("notify", { title: String(title || ""), body: String(options.body || ""), url: url });
+  };
+  goup._notificationClick = function (url) {
+    var e = new CustomEvent("goup-notification-click", { detail: { url: url }, cancelable: true });
+    if (window.dispatchEvent(e) && url) { location.href = url; }
+  };
+`
@@ -0,0 +1,152 @@
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework UserNotifications -framework Foundation
+
+#import <Foundation/Foundation.h>
+#import <UserNotifications/UserNotifications.h>
+#include <stdlib.h>
+#include <string.h>
+
+extern void notificationClicked(char *id);
+
+@interface GoupNotificationDelegate : NSObject <UNUserNotificationCenterDelegate>
+@end
+
+@implementation GoupNotificationDelegate
+// Show notifications even while the app is in front.
+- (void)userNotificationCenter:(UNUserNotificationCenter *)center
+       willPresentNotification:(UNNotification *)notification
+         withCompletionHandler:(void (^)(UNNotificationPresentationOptions))completionHandler {
+	if (@available(macOS 11.0, iOS 14.0, *)) {
+		completionHandler(UNNotificationPresentationOptionBanner | UNNotificationPresentationOptionList | UNNotificationPresentationOptionSound);
+	} else {
+		completionHandler(UNNotificationPresentationOptionAlert | UNNotificationPresentationOptionSound);
+	}
+}
+
+- (void)userNotificationCenter:(UNUserNotificationCenter *)center
+didReceiveNotificationResponse:(UNNotificationResponse *)response
+         withCompletionHandler:(void (^)(void))completionHandler {
+	if ([response.actionIdentifier isEqualToString:UNNotificationDefaultActionIdentifier]) {
+		notificationClicked((char *)[response.notification.request.identifier UTF8String]);
+	}
+	completionHandler();
+}
+@end
+
+// bundled reports whether the app runs from a .app bundle, without which
+// UserNotifications aborts the process.
+static int bundled(void) {
+	return [[NSBundle mainBundle] bundleIdentifier] != nil;
+}
+
+// postNotification asks for permission the first time, then posts the
+// notification. It returns 0 once posted, 1 if the user turned
+// notifications off, and -1 with *msg set on other errors.
+static int postNotification(const char *ident, const char *title, const char *body, char **msg) {
+	@autoreleasepool {
+		static GoupNotificationDelegate *delegate = nil;
+		UNUserNotificationCenter *center = [UNUserNotificationCenter currentNotificationCenter];
+		if (delegate == nil) {
+			delegate = [[GoupNotificationDelegate alloc] init];
+			center.delegate = delegate;
+		}
+
+		dispatch_semaphore_t done = dispatch_semaphore_create(0);
+		__block BOOL granted = NO;
+		__block char *errMsg = NULL;
+		[center requestAuthorizationWithOptions:(UNAuthorizationOptionAlert | UNAuthorizationOptionSound)
+			completionHandler:^(BOOL ok, NSError *err) {
+				granted = ok;
+				if (err != nil) {
+					errMsg = strdup([[err localizedDescription] UTF8String]);
+				}
+				dispatch_semaphore_signal(done);
+			}];
+		dispatch_semaphore_wait(done, DISPATCH_TIME_FOREVER);
+		if (!granted) {
+			dispatch_release(done);
+			*msg = errMsg;
+			return errMsg ? -1 : 1;
+		}
+
+		UNMutableNotificationContent *content = [[UNMutableNotificationContent alloc] init];
+		content.title = [NSString stringWithUTF8String:title];
+		content.body = [NSString stringWithUTF8String:body];
+		content.sound = [UNNotificationSound defaultSound];
+		UNNotificationRequest *request = [UNNotificationRequest requestWithIdentifier:[NSString stringWithUTF8String:ident]
+			content:content trigger:nil];
+		[content release];
+		[center addNotificationRequest:request withCompletionHandler:^(NSError *err) {
+			if (err != nil) {
+				errMsg = strdup([[err localizedDescription] UTF8String]);
+			}
+			dispatch_semaphore_signal(done);
+		}];
+		dispatch_semaphore_wait(done, DISPATCH_TIME_FOREVER);
+		dispatch_release(done);
+		*msg = errMsg;
+		return errMsg ? -1 : 0;
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// notificationClicks holds the click handlers of shown notifications by
+// request identifier.
+var (
+	notificationClicks sync.Map
+	notificationCount  atomic.Int64
+)
+
+// showNotification posts a notification with UserNotifications and calls
+// clicked when the user clicks it. The first one asks the user for
+// permission. Outside an app bundle, as with 'go run', it falls back to
+// AppleScript, which cannot report clicks.
+func showNotification(appName, title, body string, clicked func()) error {
+	if C.bundled() == 0 {
+		if runtime.GOOS != "darwin" {
+			return errors.New("notifications need an app bundle")
+		}
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		if out, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+			return fmt.Errorf("osascript: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	id := fmt.Sprintf("goup-%d", notificationCount.Add(1))
+	notificationClicks.Store(id, clicked)
+
+	cID, cTitle, cBody := C.CString(id), C.CString(title), C.CString(body)
+	defer C.free(unsafe.Pointer(cID))
+	defer C.free(unsafe.Pointer(cTitle))
+	defer C.free(unsafe.Pointer(cBody))
+	var msg *C.char
+	switch C.postNotification(cID, cTitle, cBody, &msg) {
+	case 0:
+		return nil
+	case 1:
+		notificationClicks.Delete(id)
+		return errNotificationsOff
+	}
+	notificationClicks.Delete(id)
+	defer C.free(unsafe.Pointer(msg))
+	return errors.New(C.GoString(msg))
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
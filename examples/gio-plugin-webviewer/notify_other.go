@@ -0,0 +1,9 @@
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+
+package main
+
+import "errors"
+
+func showNotification(appName, title, body string, clicked func()) error {
+	return errors.New("notifications are not supported on this platform yet")
+}
@@ -0,0 +1,62 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// showNotification shows a notification through notify-send and calls
+// clicked if the user clicks it while it is on screen. Versions of
+// notify-send without --action (before libnotify 0.7.10) show it without
+// reporting clicks.
+func showNotification(appName, title, body string, clicked func()) error {
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return errors.New("notify-send not found (install libnotify-bin)")
+	}
+	err := notifySend(appName, title, body, clicked)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return notifySend(appName, title, body, nil)
+	}
+	return err
+}
+
+// notifySend runs notify-send until it fails or has been running for a
+// second, by when the notification is showing. With --wait it keeps
+// running until the notification closes.
+func notifySend(appName, title, body string, clicked func()) error {
+	args := []string{"--app-name=" + appName}
+	if clicked != nil {
+		args = append(args, "--action=default="+tr("Open"), "--wait")
+	}
+	cmd := exec.Command("notify-send", append(args, "--", title, body)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		if err == nil && clicked != nil && strings.TrimSpace(stdout.String()) == "default" {
+			clicked()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil && stderr.Len() > 0 {
+			return fmt.Errorf("notify-send: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return err
+	case <-time.After(time.Second):
+		return nil
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// toastScript shows a toast with Windows PowerShell, which can call
+// WinRT without cgo. It prints "shown" once the toast is up, or the
+// setting that blocks it ("DisabledForApplication", ...), then
+// "activated" if the user clicks it. The text comes from the environment
+// so it needs no quoting.
+const toastScript = `
+$ErrorActionPreference = 'Stop'
+[void][Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime]
+[void][Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime]
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml('<toast><visual><binding template="ToastGeneric"><text/><text/></binding></visual></toast>')
+$texts = $xml.GetElementsByTagName('text')
+[void]$texts.Item(0).AppendChild($xml.CreateTextNode($env:GOUP_TOAST_TITLE))
+[void]$texts.Item(1).AppendChild($xml.CreateTextNode($env:GOUP_TOAST_BODY))
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[void](Register-ObjectEvent -InputObject $toast -EventName Activated -SourceIdentifier activated)
+[void](Register-ObjectEvent -InputObject $toast -EventName Dismissed -SourceIdentifier dismissed)
+[void](Register-ObjectEvent -InputObject $toast -EventName Failed -SourceIdentifier failed)
+$notifier = [Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:GOUP_TOAST_APPID)
+if ($notifier.Setting -ne 'Enabled') { Write-Output $notifier.Setting; exit }
+$notifier.Show($toast)
+Write-Output shown
+$e = Wait-Event -Timeout 120
+if ($e) { Write-Output $e.SourceIdentifier }
+`
+
+// toastAppID is the AppUserModelID toasts are posted as: the package's
+// under MSIX, so they carry the app's name and icon, or Windows
+// PowerShell's for the zip download.
+func toastAppID() string {
+	const powerShell = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+	proc := windows.NewLazySystemDLL("kernel32.dll").NewProc("GetCurrentApplicationUserModelId")
+	if proc.Find() != nil {
+		return powerShell
+	}
+	buf := make([]uint16, 130) // APPLICATION_USER_MODEL_ID_MAX_LENGTH
+	n := uint32(len(buf))
+	if r, _, _ := proc.Call(uintptr(unsafe.Pointer(&n)), uintptr(unsafe.Pointer(&buf[0]))); r != 0 {
+		return powerShell // Not packaged
+	}
+	return windows.UTF16ToString(buf)
+}
+
+// showNotification shows a toast and calls clicked if the user clicks it
+// while it is on screen.
+func showNotification(appName, title, body string, clicked func()) error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Env = append(os.Environ(),
+		"GOUP_TOAST_TITLE="+title,
+		"GOUP_TOAST_BODY="+body,
+		"GOUP_TOAST_APPID="+toastAppID())
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_NO_WINDOW}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start powershell: %w", err)
+	}
+	lines := bufio.NewScanner(stdout)
+	if !lines.Scan() || lines.Text() != "shown" {
+		setting := lines.Text()
+		cmd.Wait()
+		if strings.HasPrefix(setting, "Disabled") {
+			return errNotificationsOff
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.New("toast failed: " + msg)
+		}
+		return errors.New("toast failed")
+	}
+	go func() {
+		for lines.Scan() {
+			if lines.Text() == "activated" {
+				clicked()
+			}
+		}
+		cmd.Wait()
+	}()
+	return nil
+}
@@ -0,0 +1,13 @@
+package main
+
+import "C"
+
+// notificationClicked is called by the notification center delegate,
+// on its own thread, when the user clicks a notification.
+//
+//export notificationClicked
+func notificationClicked(id *C.char) {
+	if clicked, ok := notificationClicks.LoadAndDelete(C.GoString(id)); ok {
+		go clicked.(func())()
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// permissionName returns the display name for an app.json "permissions" key.
+func permissionName(key string) string {
+	switch key {
+	case "camera":
+		return tr("Camera")
+	case "microphone":
+		return tr("Microphone")
+	case "screen-recording":
+		return tr("Screen Recording")
+	case "local-network":
+		return tr("Local Network")
+	case "biometrics":
+		return tr("Touch ID, Face ID or Windows Hello")
+	case "notifications":
+		return tr("Notifications")
+	}
+	return key
+}
+
+// firstRunMarker is the file that records onboarding was shown for an app.
+func firstRunMarker(appName string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, appName, "first-run")
+}
+
+// onboardingURL returns a welcome page explaining the permissions the app
+// will ask for, with a link on to startURL. It returns "" when there is
+// nothing to explain or the page was already shown, and records the first
+// run so the page appears only once.
+func onboardingURL(cfg *appConfig, startURL string) string {
+	if len(cfg.Permissions) == 0 {
+		return ""
+	}
+	marker := firstRunMarker(cfg.Name)
+	if marker == "" {
+		return ""
+	}
+	if _, err := os.Stat(marker); err == nil {
+		return ""
+	}
+	if err := os.MkdirAll(filepath.Dir(marker), 0755); err == nil {
+		os.WriteFile(marker, nil, 0644)
+	}
+
+	keys := make([]string, 0, len(cfg.Permissions))
+	for k := range cfg.Permissions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var items strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&items, "<li><b>%s</b> — %s</li>", html.EscapeString(permissionName(k)), html.EscapeString(cfg.Permissions[k]))
+	}
+
+	return shellPage(cfg.Name, fmt.Sprintf(`<h2>%s</h2><p>%s</p><ul>%s</ul><p><a href="%s">%s</a></p>`,
+		html.EscapeString(tr("Welcome to %s", cfg.Name)),
+		html.EscapeString(tr("Your system will ask for these permissions when they are first needed:")),
+		items.String(),
+		html.EscapeString(startURL),
+		html.EscapeString(tr("Continue"))))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// systemLocale returns the macOS preferred locale (e.g. "en_AU").
+// GUI apps launched from Finder have no LANG, so ask the defaults system.
+func systemLocale() string {
+	out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// systemDarkMode reports whether macOS is set to the dark appearance.
+// The key only exists in dark mode, so any error means light.
+func systemDarkMode() bool {
+	out, err := exec.Command("defaults", "read", "-g", "AppleInterfaceStyle").Output()
+	return err == nil && strings.EqualFold(strings.TrimSpace(string(out)), "dark")
+}
+
+// ensureWebView2 only matters on Windows; other platforms use the system
+// webview.
+func ensureWebView2(appName string) error {
+	return nil
+}
+
+// captureScreen saves the main display as PNG with screencapture. It
+// needs the Screen Recording permission.
+func captureScreen(path string) error {
+	return exec.Command("screencapture", "-x", "-t", "png", path).Run()
+}
+
+// processTable lists "pid ppid rss-in-KB" for every process.
+func processTable() ([]byte, error) {
+	return exec.Command("ps", "-A", "-o", "pid=,ppid=,rss=").Output()
+}
+
+// disableGPU has nothing to switch on macOS: WKWebView and Metal have no
+// software mode.
+func disableGPU() error {
+	fmt.Fprintln(os.Stderr, "Warning: --software-render has no effect on macOS")
+	return nil
+}
+
+// toggleKeyboard has no counterpart on macOS, where the Accessibility
+// Keyboard is only switched in System Settings; iOS shows its own keyboard
+// when a field is focused.
+func toggleKeyboard() error {
+	return errors.New("turn on the Accessibility Keyboard in System Settings > Accessibility > Keyboard")
+}
@@ -0,0 +1,88 @@
+//go:build !darwin && !windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// systemLocale has no OS query here; the POSIX locale variables are used.
+func systemLocale() string {
+	return ""
+}
+
+// systemDarkMode checks GTK_THEME and the GNOME color-scheme setting.
+func systemDarkMode() bool {
+	if strings.Contains(strings.ToLower(os.Getenv("GTK_THEME")), "dark") {
+		return true
+	}
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "color-scheme").Output()
+	return err == nil && strings.Contains(string(out), "dark")
+}
+
+// ensureWebView2 only matters on Windows; other platforms use the system
+// webview.
+func ensureWebView2(appName string) error {
+	return nil
+}
+
+// captureScreen tries the common X11 and Wayland screenshot tools.
+func captureScreen(path string) error {
+	for _, args := range [][]string{
+		{"grim", path},
+		{"gnome-screenshot", "-f", path},
+		{"import", "-window", "root", path},
+	} {
+		if _, err := exec.LookPath(args[0]); err == nil {
+			return exec.Command(args[0], args[1:]...).Run()
+		}
+	}
+	return errors.New("no screenshot tool found (grim, gnome-screenshot or ImageMagick)")
+}
+
+// processTable lists "pid ppid rss-in-KB" for every process.
+func processTable() ([]byte, error) {
+	return exec.Command("ps", "-A", "-o", "pid=,ppid=,rss=").Output()
+}
+
+// disableGPU makes Mesa render with llvmpipe, which Gio's EGL context also
+// uses, and turns off WebKitGTK's accelerated compositing and DMA-BUF
+// renderer, the usual source of blank webviews on broken drivers.
+func disableGPU() error {
+	for k, v := range map[string]string{
+		"LIBGL_ALWAYS_SOFTWARE":           "1",
+		"WEBKIT_DISABLE_COMPOSITING_MODE": "1",
+		"WEBKIT_DISABLE_DMABUF_RENDERER":  "1",
+	} {
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toggleKeyboard shows or hides Onboard, or else GNOME's screen keyboard.
+// Android shows its own keyboard when a field is focused.
+func toggleKeyboard() error {
+	if runtime.GOOS == "android" {
+		return errors.New("the Android keyboard shows itself when a text field is focused")
+	}
+	if _, err := exec.LookPath("onboard"); err == nil {
+		if exec.Command("pkill", "-x", "onboard").Run() == nil {
+			return nil // It was running
+		}
+		return exec.Command("onboard").Start()
+	}
+	const schema, key = "org.gnome.desktop.a11y.applications", "screen-keyboard-enabled"
+	out, err := exec.Command("gsettings", "get", schema, key).Output()
+	if err != nil {
+		return errors.New("no on-screen keyboard found (install onboard, or use GNOME's)")
+	}
+	enabled := strings.TrimSpace(string(out)) == "true"
+	return exec.Command("gsettings", "set", schema, key, fmt.Sprint(!enabled)).Run()
+}
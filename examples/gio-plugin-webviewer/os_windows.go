@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// systemLocale returns the first Windows UI language (e.g. "de-DE").
+func systemLocale() string {
+	langs, err := windows.GetUserPreferredUILanguages(windows.MUI_LANGUAGE_NAME)
+	if err != nil || len(langs) == 0 {
+		return ""
+	}
+	return langs[0]
+}
+
+// systemDarkMode reports whether Windows apps are set to the dark theme.
+func systemDarkMode() bool {
+	k, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer k.Close()
+	light, _, err := k.GetIntegerValue("AppsUseLightTheme")
+	return err == nil && light == 0
+}
+
+// webView2Client is the EdgeUpdate client id of the WebView2 runtime.
+const webView2Client = `{F3017226-FE2A-4295-8BDF-00C3A9A7E4C5}`
+
+// webView2Installed reports whether the Evergreen WebView2 runtime is
+// installed per machine or per user (same keys as goup-util's pkg/wintools).
+func webView2Installed() bool {
+	keys := []struct {
+		root registry.Key
+		path string
+	}{
+		{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Microsoft\EdgeUpdate\Clients\` + webView2Client},
+		{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\EdgeUpdate\Clients\` + webView2Client},
+		{registry.CURRENT_USER, `Software\Microsoft\EdgeUpdate\Clients\` + webView2Client},
+	}
+	for _, k := range keys {
+		key, err := registry.OpenKey(k.root, k.path, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		v, _, err := key.GetStringValue("pv")
+		key.Close()
+		if err == nil && v != "" && v != "0.0.0.0" {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureWebView2 makes the WebView2 runtime available before the first
+// webview is created, using what 'goup-util bundle windows --webview2'
+// shipped next to the executable: a fixed-version runtime folder, which
+// the WebView2 loader picks up from WEBVIEW2_BROWSER_EXECUTABLE_FOLDER, or
+// the Evergreen bootstrapper, run once when no runtime is installed.
+// Without either, it tells the user in a dialog instead of failing silently.
+func ensureWebView2(appName string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(exe)
+	fixed := filepath.Join(dir, "WebView2Runtime")
+	if _, err := os.Stat(filepath.Join(fixed, "msedgewebview2.exe")); err == nil {
+		return os.Setenv("WEBVIEW2_BROWSER_EXECUTABLE_FOLDER", fixed)
+	}
+	if webView2Installed() {
+		return nil
+	}
+
+	setup := filepath.Join(dir, "MicrosoftEdgeWebview2Setup.exe")
+	if _, err := os.Stat(setup); err == nil {
+		fmt.Println(tr("Installing the WebView2 runtime..."))
+		if err := exec.Command(setup, "/silent", "/install").Run(); err == nil && webView2Installed() {
+			return nil
+		}
+	}
+	msg := tr("%s needs the Microsoft Edge WebView2 runtime. Install it from %s and start %s again.",
+		appName, "https://go.microsoft.com/fwlink/p/?LinkId=2124703", appName)
+	text, _ := windows.UTF16PtrFromString(msg)
+	caption, _ := windows.UTF16PtrFromString(appName)
+	windows.MessageBox(0, text, caption, windows.MB_OK|windows.MB_ICONERROR)
+	return errors.New(msg)
+}
+
+// captureScreen saves the primary screen as PNG through System.Drawing.
+func captureScreen(path string) error {
+	script := `Add-Type -AssemblyName System.Windows.Forms,System.Drawing
+$b = [System.Windows.Forms.Screen]::PrimaryScreen.Bounds
+$img = New-Object System.Drawing.Bitmap $b.Width, $b.Height
+$g = [System.Drawing.Graphics]::FromImage($img)
+$g.CopyFromScreen($b.Location, [System.Drawing.Point]::Empty, $b.Size)
+$img.Save($env:GOUP_SCREENSHOT, [System.Drawing.Imaging.ImageFormat]::Png)`
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.Env = append(os.Environ(), "GOUP_SCREENSHOT="+path)
+	return cmd.Run()
+}
+
+// processTable lists "pid ppid working-set-in-KB" for every process.
+func processTable() ([]byte, error) {
+	return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command",
+		`Get-CimInstance Win32_Process | ForEach-Object { "$($_.ProcessId) $($_.ParentProcessId) $([int64]($_.WorkingSetSize / 1024))" }`).Output()
+}
+
+// disableGPU starts WebView2 without GPU acceleration. Gio keeps Direct3D
+// 11, which falls back to WARP by itself when no hardware device works.
+func disableGPU() error {
+	existing := os.Getenv(webView2ArgsEnv)
+	return os.Setenv(webView2ArgsEnv, strings.TrimSpace(existing+" --disable-gpu"))
+}
+
+// toggleKeyboard closes the On-Screen Keyboard when it is open, and opens
+// it otherwise.
+func toggleKeyboard() error {
+	out, _ := exec.Command("tasklist", "/FI", "IMAGENAME eq osk.exe", "/NH").Output()
+	if strings.Contains(strings.ToLower(string(out)), "osk.exe") {
+		return exec.Command("taskkill", "/IM", "osk.exe").Run()
+	}
+	return exec.Command(filepath.Join(os.Getenv("SystemRoot"), "System32", "osk.exe")).Start()
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// shellPageMarker tags pages generated by the shell (blocked, onboarding) so
+// the address bar keeps showing the real URL. Engines may report data: URLs
+// escaped or not, so only the marker text is matched.
+const shellPageMarker = "goup-shell-page"
+
+// shellPage returns a data: URL for a simple page with the given HTML body.
+func shellPage(title, body string) string {
+	page := fmt.Sprintf(`<!DOCTYPE html><html id="%s"><head><meta charset="utf-8"><title>%s</title></head>`+
+		`<body style="font-family:sans-serif;max-width:36em;margin:15vh auto;line-height:1.5;color:#333">%s</body></html>`,
+		shellPageMarker, html.EscapeString(title), body)
+	return "data:text/html;charset=utf-8," + url.PathEscape(page)
+}
+
+// isShellPage reports whether rawURL is a page built by shellPage.
+func isShellPage(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "data:") && strings.Contains(rawURL, shellPageMarker)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/io/event"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+	"github.com/gioui-plugins/gio-plugins/webviewer/webview"
+)
+
+// profilesConfig lets several people share a device, such as a clinic
+// tablet, without sharing their web sessions.
+type profilesConfig struct {
+	Names []string `json:"names,omitempty"` // e.g. ["Reception", "Dr. Patel"]
+	Pick  bool     `json:"pick,omitempty"`  // Ask at launch who is using the device
+}
+
+// profileSet is the profile the shell runs as. Each profile keeps its
+// cookies, storage and cache in a directory of its own where the webview
+// allows it (WebView2); elsewhere the webview has a single store, which is
+// cleared when the profile changes so no one sees the last user's session.
+type profileSet struct {
+	names   []string
+	dir     string // Profile directories, and the last profile used
+	current string // "" while the picker shows
+	clear   bool   // Clear the shared store before the first page
+	buttons []widget.Clickable
+}
+
+// separateStorage reports whether the webview can keep each profile's data
+// in its own directory.
+func separateStorage() bool {
+	return runtime.GOOS == "windows"
+}
+
+// newProfileSet returns nil unless app.json names profiles. The profile is
+// the one given with --profile, the one picked at launch if pick is set,
+// or else the last one used.
+func newProfileSet(cfg *appConfig, flagProfile string) (*profileSet, error) {
+	names := cfg.Profiles.Names
+	if len(names) == 0 {
+		if flagProfile != "" {
+			return nil, fmt.Errorf("--profile %q: app.json names no profiles", flagProfile)
+		}
+		return nil, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	p := &profileSet{
+		names:   names,
+		dir:     filepath.Join(dir, cfg.Name, "profiles"),
+		buttons: make([]widget.Clickable, len(names)),
+	}
+	switch {
+	case flagProfile != "":
+		if !slices.Contains(names, flagProfile) {
+			return nil, fmt.Errorf("--profile %q: not one of %s", flagProfile, strings.Join(names, ", "))
+		}
+		return p, p.use(flagProfile)
+	case cfg.Profiles.Pick:
+		return p, nil
+	}
+	last := p.last()
+	if !slices.Contains(names, last) {
+		last = names[0]
+	}
+	return p, p.use(last)
+}
+
+// last is the profile the shell ran as before, if any.
+func (p *profileSet) last() string {
+	data, _ := os.ReadFile(filepath.Join(p.dir, "last"))
+	return strings.TrimSpace(string(data))
+}
+
+// use makes name the profile. It must run before the first webview is
+// created.
+func (p *profileSet) use(name string) error {
+	if separateStorage() {
+		dir := filepath.Join(p.dir, profileDirName(name))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		if err := webview.SetDirectory(dir); err != nil {
+			return err
+		}
+	} else {
+		p.clear = p.last() != name
+	}
+	p.current = name
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return err
+	}
+	fmt.Println(tr("Profile: %s", name))
+	return os.WriteFile(filepath.Join(p.dir, "last"), []byte(name+"\n"), 0600)
+}
+
+// profileDirName turns a profile name into a directory name.
+func profileDirName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// picking reports whether the picker shows instead of the pages; nil is
+// never picking.
+func (p *profileSet) picking() bool {
+	return p != nil && p.current == ""
+}
+
+// name is the current profile; "" for nil.
+func (p *profileSet) name() string {
+	if p == nil {
+		return ""
+	}
+	return p.current
+}
+
+// prepare clears the last profile's session data from the shared store
+// before the first page loads in view.
+func (p *profileSet) prepare(gtx layout.Context, view event.Tag) {
+	if p == nil || !p.clear {
+		return
+	}
+	gioplugins.Execute(gtx, giowebview.ClearCacheCmd{View: view})
+	p.clear = false
+}
+
+// layout draws the picker: a button per profile.
+func (p *profileSet) layout(gtx layout.Context) layout.Dimensions {
+	for i := range p.buttons {
+		if p.buttons[i].Clicked(gtx) {
+			if err := p.use(p.names[i]); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+				life.exit(1)
+			}
+			gtx.Execute(op.InvalidateCmd{})
+		}
+	}
+
+	paint.Fill(gtx.Ops, Theme.Toolbar)
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			colorMaterial := op.Record(gtx.Ops)
+			paint.ColorOp{Color: Theme.Text}.Add(gtx.Ops)
+			pcolor := colorMaterial.Stop()
+			return widget.Label{Alignment: text.Middle}.Layout(gtx, GlobalShaper, font.Font{}, gtx.Metric.DpToSp(20), tr("Who is using this device?"), pcolor)
+		}),
+		layout.Rigid(layout.Spacer{Height: 16}.Layout),
+	}
+	for i, name := range p.names {
+		children = append(children,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min = image.Point{}
+				gtx.Constraints.Max.Y = gtx.Dp(48)
+				return Button{Clickable: &p.buttons[i], Text: name}.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+		)
+	}
+	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+	})
+}
+
+// switchTo restarts the shell as profile name.
+func (p *profileSet) switchTo(name string) error {
+	if !slices.Contains(p.names, name) {
+		return fmt.Errorf("no profile %q", name)
+	}
+	var args []string
+	for i := 1; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "-profile" || arg == "--profile":
+			i++ // Skip its value
+		case strings.HasPrefix(arg, "-profile=") || strings.HasPrefix(arg, "--profile="):
+		default:
+			args = append(args, arg)
+		}
+	}
+	return restart(append(args, "--profile", name))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/gioui-plugins/gio-plugins/webviewer/webview"
+)
+
+// webView2ArgsEnv is read by WebView2 when the first webview is created.
+const webView2ArgsEnv = "WEBVIEW2_ADDITIONAL_BROWSER_ARGUMENTS"
+
+// proxyConfig routes webview traffic through a corporate proxy.
+// The webview fixes its proxy when the first tab is created, so a change
+// (or a different --proxy-profile) takes effect on the next launch.
+type proxyConfig struct {
+	URL    string   `json:"url,omitempty"`    // Fixed proxy, e.g. "http://proxy.corp:8080"
+	PAC    string   `json:"pac,omitempty"`    // Proxy auto-config script URL (Windows only)
+	Bypass []string `json:"bypass,omitempty"` // Hosts that skip the proxy, e.g. "*.corp.local" (Windows only)
+}
+
+// selectProxy picks the proxy to use: the --proxy flag wins, then the named
+// profile from app.json "proxies", then the default "proxy" section.
+func selectProxy(cfg *appConfig, flagURL, profile string) (proxyConfig, error) {
+	p := cfg.Proxy
+	if profile != "" {
+		named, ok := cfg.Proxies[profile]
+		if !ok {
+			return p, fmt.Errorf("proxy profile %q not found in app.json", profile)
+		}
+		p = named
+	}
+	if flagURL != "" {
+		p = proxyConfig{URL: flagURL, Bypass: p.Bypass}
+	}
+	return p, nil
+}
+
+// applyProxy configures the webview proxy. It must run before any tab exists.
+func applyProxy(p proxyConfig) error {
+	if p.URL == "" && p.PAC == "" {
+		return nil
+	}
+
+	if p.URL != "" {
+		u, err := url.Parse(p.URL)
+		if err != nil || u.Hostname() == "" {
+			return fmt.Errorf("invalid proxy url %q", p.URL)
+		}
+		if err := webview.SetProxy(u); err != nil {
+			return fmt.Errorf("failed to set proxy: %w", err)
+		}
+	}
+
+	var args []string
+	if p.PAC != "" {
+		u, err := url.Parse(p.PAC)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file") {
+			return fmt.Errorf("invalid proxy pac url %q", p.PAC)
+		}
+		args = append(args, fmt.Sprintf(`--proxy-pac-url="%s"`, p.PAC))
+	}
+	if len(p.Bypass) > 0 {
+		args = append(args, fmt.Sprintf(`--proxy-bypass-list="%s"`, strings.Join(p.Bypass, ";")))
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	if runtime.GOOS != "windows" {
+		fmt.Fprintf(os.Stderr, "Warning: proxy pac/bypass are only supported on Windows, ignoring on %s\n", runtime.GOOS)
+		return nil
+	}
+	existing := os.Getenv(webView2ArgsEnv)
+	return os.Setenv(webView2ArgsEnv, strings.TrimSpace(existing+" "+strings.Join(args, " ")))
+}
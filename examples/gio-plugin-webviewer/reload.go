@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/layout"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+
+// reloadConfig lets operators reconfigure a running shell. With watch set,
+// edits to app.json apply within seconds; with url set, the shell also
+// fetches a JSON document over https that overrides app.json's live
+// sections, so one build serves several tenants, each pointed at its own
+// document. The document's other keys are ignored.
+type reloadConfig struct {
+	Watch          bool   `json:"watch,omitempty"`          // Apply edits to app.json while running
+	URL            string `json:"url,omitempty"`            // Remote overrides of the live sections (https)
+	RefreshMinutes int    `json:"refreshMinutes,omitempty"` // How often to fetch URL (default 5)
+}
+
+func (c reloadConfig) validate() error {
+	if c.URL != "" {
+		if u, err := url.Parse(c.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("reload.url %q: want an https URL", c.URL)
+		}
+	}
+	return nil
+}
+
+// liveSections are the app.json settings a reload applies in place; the
+// others take effect at the next start. display is live when only
+// fullscreen changed.
+var liveSections = []string{"url", "name", "environment", "filter", "theme", "media", "display"}
+
+// configChange is a reload for Browsers.Layout to apply.
+type configChange struct {
+	Live    []string // Sections that changed and are applied now
+	Restart []string // Sections that changed and need a restart
+	URL     string
+	Filter  *urlFilter
+	Theme   palette
+	Media   mediaConfig
+}
+
+// configReloader keeps the running configuration in step with app.json
+// and the remote overrides.
+type configReloader struct {
+	path     string       // app.json; "" for the embedded one
+	settings reloadConfig // As started; changing them needs a restart
+	cache    string       // Remote overrides, for starting offline
+	remote   remoteConfigCache
+	current  *appConfig
+	modTime  time.Time
+}
+
+// remoteConfigCache is the last remote document, kept so an unchanged one
+// costs a 304 and the overrides survive starting offline.
+type remoteConfigCache struct {
+	URL  string          `json:"url"`
+	ETag string          `json:"etag,omitempty"`
+	Body json.RawMessage `json:"body"`
+}
+
+// appConfigPath is the app.json loadAppConfig reads: next to the
+// executable, else in the working directory; "" for the embedded one.
+func appConfigPath() string {
+	if exePath, err := os.Executable(); err == nil {
+		if path := filepath.Join(filepath.Dir(exePath), "app.json"); fileExists(path) {
+			return path
+		}
+	}
+	if fileExists("app.json") {
+		return "app.json"
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// newConfigReloader starts from cfg, as loaded from app.json, and returns
+// it with the cached remote overrides applied.
+func newConfigReloader(cfg *appConfig) (*configReloader, *appConfig) {
+	r := &configReloader{path: appConfigPath(), settings: cfg.Reload, current: cfg}
+	if info, err := os.Stat(r.path); err == nil {
+		r.modTime = info.ModTime()
+	}
+	if r.settings.URL == "" {
+		return r, cfg
+	}
+	dir, _ := os.UserConfigDir()
+	r.cache = filepath.Join(dir, cfg.Name, "remote-app.json")
+	if data, err := os.ReadFile(r.cache); err == nil && json.Unmarshal(data, &r.remote) == nil && r.remote.URL == r.settings.URL {
+		if next, err := r.load(); err == nil {
+			r.current = next
+		} else {
+			fmt.Fprintln(os.Stderr, tr("Config reload: %v", err))
+		}
+	} else {
+		r.remote = remoteConfigCache{URL: r.settings.URL}
+	}
+	return r, r.current
+}
+
+// load reads app.json and applies the remote overrides.
+func (r *configReloader) load() (*appConfig, error) {
+	cfg := newAppConfig()
+	data := embeddedConfig
+	if r.path != "" {
+		var err error
+		if data, err = os.ReadFile(r.path); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.path, err)
+		}
+	}
+	if len(r.remote.Body) > 0 {
+		// Only the live sections: a remote document must not turn off
+		// updates, repoint the fleet or unlock the admin panel.
+		overrides := struct {
+			URL         *string        `json:"url"`
+			Name        *string        `json:"name"`
+			Environment *string        `json:"environment"`
+			Filter      *filterConfig  `json:"filter"`
+			Theme       *themeConfig   `json:"theme"`
+			Media       *mediaConfig   `json:"media"`
+			Display     *displayConfig `json:"display"`
+		}{&cfg.URL, &cfg.Name, &cfg.Environment, &cfg.Filter, &cfg.Theme, &cfg.Media, &cfg.Display}
+		if err := json.Unmarshal(r.remote.Body, &overrides); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.remote.URL, err)
+		}
+	}
+	return cfg, nil
+}
+
+// start watches app.json and polls the remote overrides until ctx ends,
+// applying changes to window and the pages.
+func (r *configReloader) start(ctx context.Context, window *app.Window, actions *pageActions) {
+	if !r.settings.Watch && r.settings.URL == "" {
+		return
+	}
+	interval := time.Duration(r.settings.RefreshMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	go func() {
+		watch := time.NewTicker(2 * time.Second)
+		defer watch.Stop()
+		var fetched time.Time
+		for {
+			changed := false
+			if r.settings.Watch && r.path != "" {
+				if info, err := os.Stat(r.path); err == nil && !info.ModTime().Equal(r.modTime) {
+					r.modTime, changed = info.ModTime(), true
+				}
+			}
+			if r.settings.URL != "" && time.Since(fetched) >= interval {
+				fetched = time.Now()
+				if updated, err := r.fetch(ctx); err != nil {
+					fmt.Fprintln(os.Stderr, tr("Config reload: %v", err))
+				} else {
+					changed = changed || updated
+				}
+			}
+			if changed {
+				if err := r.reload(window, actions); err != nil {
+					fmt.Fprintln(os.Stderr, tr("Config reload: %v", err))
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-watch.C:
+			}
+		}
+	}()
+}
+
+// fetch gets the remote overrides, sending the cached ETag, and reports
+// whether they changed.
+func (r *configReloader) fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.settings.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.remote.ETag != "" && len(r.remote.Body) > 0 {
+		req.Header.Set("If-None-Match", r.remote.ETag)
+	}
+	resp, err := (&http.Client{Timeout: 15 * time.Second}).Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%s: %s", r.settings.URL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return false, err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return false, fmt.Errorf("%s: not a JSON object: %w", r.settings.URL, err)
+	}
+	changed := string(body) != string(r.remote.Body)
+	r.remote.ETag, r.remote.Body = resp.Header.Get("ETag"), body
+	if data, err := json.Marshal(r.remote); err == nil && os.MkdirAll(filepath.Dir(r.cache), 0755) == nil {
+		os.WriteFile(r.cache, data, 0644)
+	}
+	return changed, nil
+}
+
+// reload applies the live sections that changed: the window title and
+// fullscreen here, the rest through a page action. Invalid settings keep
+// the running configuration.
+func (r *configReloader) reload(window *app.Window, actions *pageActions) error {
+	next, err := r.load()
+	if err != nil {
+		return err
+	}
+	live, restart := configChanges(r.current, next)
+	if len(live) == 0 && len(restart) == 0 {
+		return nil
+	}
+
+	c := &configChange{Live: live, Restart: restart, URL: next.URL, Media: next.Media}
+	if u, err := url.Parse(next.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid url %q", next.URL)
+	}
+	if c.Filter, err = newURLFilter(next.Filter); err != nil {
+		return err
+	}
+	if c.Theme, err = next.palette(); err != nil {
+		return err
+	}
+	if err := next.Display.validate(); err != nil {
+		return err
+	}
+
+	if slices.Contains(live, "name") || slices.Contains(live, "environment") {
+		window.Option(app.Title(next.title()))
+	}
+	if slices.Contains(live, "display") {
+		if next.Display.Fullscreen {
+			window.Option(app.Fullscreen.Option())
+		} else {
+			window.Option(app.Windowed.Option())
+		}
+	}
+	actions.push(pageAction{Config: c})
+	window.Invalidate()
+	r.current = next
+
+	if len(live) > 0 {
+		fmt.Println(tr("Config reloaded: %s", strings.Join(live, ", ")))
+	}
+	if len(restart) > 0 {
+		fmt.Println(tr("Config changes that apply at the next start: %s", strings.Join(restart, ", ")))
+	}
+	return nil
+}
+
+// configChanges lists the app.json sections that differ, by their JSON
+// names, split into those applied live and those that need a restart.
+func configChanges(old, next *appConfig) (live, restart []string) {
+	ov, nv := reflect.ValueOf(*old), reflect.ValueOf(*next)
+	for i := range ov.NumField() {
+		if reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			continue
+		}
+		name, _, _ := strings.Cut(ov.Type().Field(i).Tag.Get("json"), ",")
+		switch {
+		case name == "display" && (old.Display.Index != next.Display.Index || old.Display.Scale != next.Display.Scale):
+			restart = append(restart, name)
+		case slices.Contains(liveSections, name):
+			live = append(live, name)
+		default:
+			restart = append(restart, name)
+		}
+	}
+	return live, restart
+}
+
+// reconfigure applies a reload to the tabs: new filter rules, theme and
+// media settings for the loaded pages and the ones to come, the new start
+// page, and a "goup-config" event so pages can react.
+func (b *Browsers) reconfigure(gtx layout.Context, c configChange) {
+	// Run script in the loaded page and in the pages the tab loads next
+	apply := func(i int, script string) {
+		gioplugins.Execute(gtx, giowebview.InstallJavascriptCmd{View: b.Tags[i], Script: script})
+		gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{View: b.Tags[i], Script: script})
+	}
+	if slices.Contains(c.Live, "filter") {
+		b.Filter = c.Filter
+		script := c.Filter.script()
+		if script == "" {
+			script = (&urlFilter{}).script() // Lift the rules of an earlier script
+		}
+		for i := range b.Tags {
+			if b.prepared[i] {
+				apply(i, script)
+			}
+		}
+	}
+	if slices.Contains(c.Live, "theme") {
+		Theme = c.Theme
+		for i := range b.Tags {
+			if b.prepared[i] {
+				apply(i, themeScript(Theme))
+			}
+		}
+	}
+	if slices.Contains(c.Live, "media") {
+		b.Media = c.Media
+		for i := range b.Tags {
+			b.Muted[i] = c.Media.Muted
+			if b.prepared[i] {
+				apply(i, mediaScript(b.Media, b.Muted[i]))
+			}
+		}
+	}
+	if slices.Contains(c.Live, "url") {
+		DefaultURL = c.URL
+		for i := range b.Tags {
+			b.Address[i].SetText(c.URL)
+		}
+	}
+	for i := range b.Tags {
+		if !b.prepared[i] {
+			continue
+		}
+		gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{View: b.Tags[i], Script: configEventScript(c)})
+		// Leave pages the new rules block, and go to the new start page
+		if target := b.Address[i].Text(); slices.Contains(c.Live, "url") || b.guard(target) != target {
+			gioplugins.Execute(gtx, giowebview.NavigateCmd{View: b.Tags[i], URL: b.guard(target)})
+		}
+	}
+}
+
+// configEventScript fires a "goup-config" event with the sections that
+// changed: live ones are in effect, restart ones wait for the next start.
+func configEventScript(c configChange) string {
+	data, _ := json.Marshal(map[string][]string{
+		"live":    append([]string{}, c.Live...),
+		"restart": append([]string{}, c.Restart...),
+	})
+	return fmt.Sprintf(`window.dispatchEvent(new CustomEvent("goup-config", { detail: %s }));`, data)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigChanges(t *testing.T) {
+	tests := []struct {
+		name          string
+		change        func(c *appConfig)
+		live, restart []string
+	}{
+		{"none", func(c *appConfig) {}, nil, nil},
+		{"url", func(c *appConfig) { c.URL = "https://example.com" }, []string{"url"}, nil},
+		{"name and filter", func(c *appConfig) {
+			c.Name = "Kiosk"
+			c.Filter.Block = []string{"ads.example.com"}
+		}, []string{"name", "filter"}, nil},
+		{"fullscreen", func(c *appConfig) { c.Display.Fullscreen = true }, []string{"display"}, nil},
+		{"display scale", func(c *appConfig) { c.Display.Scale = 2 }, nil, []string{"display"}},
+		{"update", func(c *appConfig) { c.Update.Repo = "acme/app" }, nil, []string{"update"}},
+		{"environment and size", func(c *appConfig) {
+			c.Environment = "staging"
+			c.Width = 800
+		}, []string{"environment"}, []string{"width"}},
+	}
+	for _, tt := range tests {
+		next := newAppConfig()
+		tt.change(next)
+		live, restart := configChanges(newAppConfig(), next)
+		if !reflect.DeepEqual(live, tt.live) || !reflect.DeepEqual(restart, tt.restart) {
+			t.Errorf("%s: configChanges = %q, %q; want %q, %q", tt.name, live, restart, tt.live, tt.restart)
+		}
+	}
+}
+
+func TestRemoteOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	os.WriteFile(path, []byte(`{"url": "https://local.example", "name": "Shell", "update": {"repo": "acme/app"}}`), 0644)
+	r := &configReloader{path: path, remote: remoteConfigCache{
+		URL: "https://config.example/tenant.json",
+		Body: []byte(`{
+			"url": "https://tenant.example",
+			"filter": {"block": ["ads.example.com"]},
+			"update": {"repo": "evil/app"},
+			"admin": {"enabled": true},
+			"reload": {"url": "https://evil.example"},
+			"fleet": {"url": "https://evil.example"}
+		}`),
+	}}
+	cfg, err := r.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.URL != "https://tenant.example" || cfg.Name != "Shell" || !reflect.DeepEqual(cfg.Filter.Block, []string{"ads.example.com"}) {
+		t.Errorf("live sections not overridden: url %q, name %q, filter %v", cfg.URL, cfg.Name, cfg.Filter)
+	}
+	if cfg.Update.Repo != "acme/app" || cfg.Admin.Enabled || cfg.Reload.URL != "" || !reflect.DeepEqual(cfg.Fleet, fleetConfig{}) {
+		t.Errorf("remote document changed other sections: update %+v, admin %+v, reload %+v, fleet %+v", cfg.Update, cfg.Admin, cfg.Reload, cfg.Fleet)
+	}
+}
+
+func TestReloadConfigValidate(t *testing.T) {
+	for url, ok := range map[string]bool{
+		"":                                   true,
+		"https://config.example/tenant.json": true,
+		"http://config.example/tenant.json":  false,
+		"file:///etc/app.json":               false,
+		"config.example/tenant.json":         false,
+		"https:///tenant.json":               false,
+	} {
+		if err := (reloadConfig{URL: url}).validate(); (err == nil) != ok {
+			t.Errorf("validate(%q) = %v", url, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/datamatrix"
+	"github.com/makiuchi-d/gozxing/oned"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// scanResult is a decoded code. Format uses the BarcodeDetector names
+// ("qr_code", "ean_13", ...) so pages see the same values whichever side
+// decoded it.
+type scanResult struct {
+	Text   string `json:"text"`
+	Format string `json:"format"`
+}
+
+// scanReaders are tried in order on every frame. QR codes come first as
+// the most common in kiosk and inventory apps.
+var scanReaders = []func() gozxing.Reader{
+	qrcode.NewQRCodeReader,
+	func() gozxing.Reader { return datamatrix.NewDataMatrixReader() },
+	func() gozxing.Reader { return oned.NewMultiFormatUPCEANReader(nil) },
+	oned.NewCode128Reader,
+	oned.NewCode39Reader,
+	oned.NewCode93Reader,
+	oned.NewITFReader,
+	oned.NewCodaBarReader,
+}
+
+// decodeFrame looks for a code in a camera frame (JPEG or PNG) sent by
+// the page. It returns nil if the frame holds none.
+func decodeFrame(data []byte) (*scanResult, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil, err
+	}
+	for _, reader := range scanReaders {
+		res, err := reader().Decode(bmp, nil)
+		if err != nil {
+			continue
+		}
+		return &scanResult{Text: res.GetText(), Format: strings.ToLower(res.GetBarcodeFormat().String())}, nil
+	}
+	return nil, nil
+}
+
+// scanScript adds window.goup.scan to the bridge script. It shows the
+// camera full screen and resolves to {text, format}, or null if the user
+// cancels. The camera comes from the webview's getUserMedia, so the
+// app.json "camera" permission applies; frames are decoded with the
+// engine's BarcodeDetector where it has one (Chromium on macOS and
+// Android) and by the shell everywhere else.
+const scanScript = `
+  goup.scan = function (options) {
+    options = options || {};
+    return new Promise(function (resolve, reject) {
+      if (!navigator.mediaDevices || !navigator.mediaDevices.getUserMedia) {
+        reject(new Error("camera access needs https or localhost"));
+        return;
+      }
+      var overlay = document.createElement("div");
+      overlay.style.cssText = "position:fixed;inset:0;z-index:2147483647;background:#000";
+      var video = document.createElement("video");
+      video.playsInline = true;
+      video.muted = true;
+      video.style.cssText = "width:100%;height:100%;object-fit:cover";
+      var cancel = document.createElement("button");
+      cancel.textContent = options.cancelLabel || "Cancel";
+      cancel.style.cssText = "position:absolute;bottom:32px;left:50%;transform:translateX(-50%);padding:12px 32px;font-size:18px;border:0;border-radius:24px";
+      overlay.appendChild(video);
+      overlay.appendChild(cancel);
+      document.body.appendChild(overlay);
+
+      var stream = null, done = false, detector = null;
+      var canvas = document.createElement("canvas"), ctx = canvas.getContext("2d");
+      if ("BarcodeDetector" in window) {
+        try { detector = new BarcodeDetector(); } catch (e) { detector = null; }
+      }
+      function finish(result, err) {
+        if (done) { return; }
+        done = true;
+        if (stream) { stream.getTracks().forEach(function (t) { t.stop(); }); }
+        overlay.remove();
+        if (err) { reject(err); } else { resolve(result); }
+      }
+      cancel.onclick = function () { finish(null); };
+      function frame() {
+        if (done) { return; }
+        if (video.readyState < 2) { setTimeout(frame, 100); return; }
+        if (detector) {
+          detector.detect(video).then(function (codes) {
+            if (codes.length) { finish({ text: codes[0].rawValue, format: codes[0].format }); }
+            else { setTimeout(frame, 150); }
+          }, function () { detector = null; frame(); });
+          return;
+        }
+        var scale = Math.min(1, 800 / Math.max(video.videoWidth, video.videoHeight));
+        canvas.width = Math.round(video.videoWidth * scale);
+        canvas.height = Math.round(video.videoHeight * scale);
+        ctx.drawImage(video, 0, 0, canvas.width, canvas.height);
+        call("scan", { data: canvas.toDataURL("image/jpeg", 0.8).split(",")[1] }).then(function (code) {
+          if (code) { finish(code); } else { setTimeout(frame, 100); }
+        }, function (err) { finish(null, err); });
+      }
+      navigator.mediaDevices.getUserMedia({ video: { facingMode: options.camera || "environment" } }).then(function (s) {
+        if (done) { s.getTracks().forEach(function (t) { t.stop(); }); return; }
+        stream = s;
+        video.srcObject = s;
+        video.play();
+        frame();
+      }, function (err) { finish(null, err); });
+    });
+  };
+`
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// errSecretNotFound is returned by secureStore.get for a key that has no
+// secret.
+var errSecretNotFound = errors.New("secret not found")
+
+// secureStore keeps the app's tokens in the system credential store
+// (Keychain, DPAPI, the Secret Service) instead of the webview's
+// localStorage, which any script on the page and anyone with the profile
+// folder can read. pkg/securestore is the same store for Go apps built
+// with goup-util.
+type secureStore struct {
+	service string
+}
+
+// get returns the secret stored under key, or errSecretNotFound.
+func (s secureStore) get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, errors.New("empty key")
+	}
+	return s.load(key)
+}
+
+// set stores secret under key, replacing any previous one.
+func (s secureStore) set(key string, secret []byte) error {
+	if key == "" {
+		return errors.New("empty key")
+	}
+	return s.save(key, secret)
+}
+
+// delete removes the secret stored under key, if there is one.
+func (s secureStore) delete(key string) error {
+	if key == "" {
+		return errors.New("empty key")
+	}
+	return s.remove(key)
+}
+
+// path is the file holding key where secrets are kept in files, under
+// the app's config folder like the shell's other state.
+func (s secureStore) path(key string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no config folder: %w", err)
+	}
+	return filepath.Join(dir, s.service, "secure", base64.RawURLEncoding.EncodeToString([]byte(key))), nil
+}
+
+func (s secureStore) readFile(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errSecretNotFound
+	}
+	return data, err
+}
+
+func (s secureStore) writeFile(key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s secureStore) removeFile(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+/*
+#cgo LDFLAGS: -framework Security -framework CoreFoundation
+
+#include <Security/Security.h>
+#include <stdlib.h>
+#include <string.h>
+
+static CFMutableDictionaryRef keychainQuery(const char *service, const char *account) {
+	CFMutableDictionaryRef q = CFDictionaryCreateMutable(NULL, 0, &kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks);
+	CFStringRef s = CFStringCreateWithCString(NULL, service, kCFStringEncodingUTF8);
+	CFStringRef a = CFStringCreateWithCString(NULL, account, kCFStringEncodingUTF8);
+	CFDictionarySetValue(q, kSecClass, kSecClassGenericPassword);
+	CFDictionarySetValue(q, kSecAttrService, s);
+	CFDictionarySetValue(q, kSecAttrAccount, a);
+	CFRelease(s);
+	CFRelease(a);
+	return q;
+}
+
+static OSStatus keychainGet(const char *service, const char *account, void **data, int *size) {
+	CFMutableDictionaryRef q = keychainQuery(service, account);
+	CFDictionarySetValue(q, kSecReturnData, kCFBooleanTrue);
+	CFDictionarySetValue(q, kSecMatchLimit, kSecMatchLimitOne);
+	CFTypeRef result = NULL;
+	OSStatus status = SecItemCopyMatching(q, &result);
+	CFRelease(q);
+	if (status != errSecSuccess) {
+		return status;
+	}
+	*size = (int)CFDataGetLength(result);
+	*data = malloc(*size > 0 ? *size : 1);
+	memcpy(*data, CFDataGetBytePtr(result), *size);
+	CFRelease(result);
+	return status;
+}
+
+static OSStatus keychainSet(const char *service, const char *account, const void *data, int size) {
+	CFMutableDictionaryRef q = keychainQuery(service, account);
+	CFDataRef value = CFDataCreate(NULL, data, size);
+	CFMutableDictionaryRef update = CFDictionaryCreateMutable(NULL, 0, &kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks);
+	CFDictionarySetValue(update, kSecValueData, value);
+	OSStatus status = SecItemUpdate(q, update);
+	if (status == errSecItemNotFound) {
+		CFDictionarySetValue(q, kSecValueData, value);
+		CFDictionarySetValue(q, kSecAttrAccessible, kSecAttrAccessibleAfterFirstUnlockThisDeviceOnly);
+		status = SecItemAdd(q, NULL);
+	}
+	CFRelease(update);
+	CFRelease(value);
+	CFRelease(q);
+	return status;
+}
+
+static OSStatus keychainDelete(const char *service, const char *account) {
+	CFMutableDictionaryRef q = keychainQuery(service, account);
+	OSStatus status = SecItemDelete(q);
+	CFRelease(q);
+	return status;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+func keychainError(op string, status C.OSStatus) error {
+	return fmt.Errorf("keychain %s failed (OSStatus %d)", op, int(status))
+}
+
+func (s secureStore) load(key string) ([]byte, error) {
+	service, account := C.CString(s.service), C.CString(key)
+	defer C.free(unsafe.Pointer(service))
+	defer C.free(unsafe.Pointer(account))
+	var data unsafe.Pointer
+	var size C.int
+	switch status := C.keychainGet(service, account, &data, &size); status {
+	case C.errSecSuccess:
+		defer C.free(data)
+		return C.GoBytes(data, size), nil
+	case C.errSecItemNotFound:
+		return nil, errSecretNotFound
+	default:
+		return nil, keychainError("read", status)
+	}
+}
+
+func (s secureStore) save(key string, secret []byte) error {
+	service, account := C.CString(s.service), C.CString(key)
+	defer C.free(unsafe.Pointer(service))
+	defer C.free(unsafe.Pointer(account))
+	data := C.CBytes(secret)
+	defer C.free(data)
+	if status := C.keychainSet(service, account, data, C.int(len(secret))); status != C.errSecSuccess {
+		return keychainError("write", status)
+	}
+	return nil
+}
+
+func (s secureStore) remove(key string) error {
+	service, account := C.CString(s.service), C.CString(key)
+	defer C.free(unsafe.Pointer(service))
+	defer C.free(unsafe.Pointer(account))
+	if status := C.keychainDelete(service, account); status != C.errSecSuccess && status != C.errSecItemNotFound {
+		return keychainError("delete", status)
+	}
+	return nil
+}
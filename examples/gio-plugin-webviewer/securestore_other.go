@@ -0,0 +1,19 @@
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+
+package main
+
+// Elsewhere (Android) the secrets are files in the app's private folder,
+// which other apps cannot read. The hardware-backed Keystore is only
+// reachable from Java.
+
+func (s secureStore) load(key string) ([]byte, error) {
+	return s.readFile(key)
+}
+
+func (s secureStore) save(key string, secret []byte) error {
+	return s.writeFile(key, secret)
+}
+
+func (s secureStore) remove(key string) error {
+	return s.removeFile(key)
+}
@@ -0,0 +1,65 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// secretTool runs secret-tool with stdin.
+func secretTool(stdin []byte, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil, errors.New("secret-tool not found (install libsecret-tools and a keyring such as GNOME Keyring)")
+	}
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, err
+}
+
+// attributes identify the secret in the Secret Service.
+func (s secureStore) attributes(key string) []string {
+	return []string{"service", s.service, "key", key}
+}
+
+// load uses 'secret-tool lookup', which exits 1 with no output for a
+// missing secret.
+func (s secureStore) load(key string) ([]byte, error) {
+	out, err := secretTool(nil, append([]string{"lookup"}, s.attributes(key)...)...)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && len(out) == 0 {
+		return nil, errSecretNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("secret-tool lookup failed: %w", err)
+	}
+	return out, nil
+}
+
+// save passes the secret on stdin so it never shows in the process list.
+func (s secureStore) save(key string, secret []byte) error {
+	args := append([]string{"store", "--label=" + s.service + " " + key}, s.attributes(key)...)
+	if _, err := secretTool(secret, args...); err != nil {
+		return fmt.Errorf("secret-tool store failed: %w", err)
+	}
+	return nil
+}
+
+func (s secureStore) remove(key string) error {
+	if _, err := secretTool(nil, append([]string{"clear"}, s.attributes(key)...)...); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return nil // Nothing to clear
+		}
+		return fmt.Errorf("secret-tool clear failed: %w", err)
+	}
+	return nil
+}
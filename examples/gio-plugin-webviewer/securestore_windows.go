@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// dataBlob wraps b for the DPAPI calls.
+func dataBlob(b []byte) *windows.DataBlob {
+	if len(b) == 0 {
+		return &windows.DataBlob{}
+	}
+	return &windows.DataBlob{Size: uint32(len(b)), Data: &b[0]}
+}
+
+// takeBlob copies the output of a DPAPI call and frees it.
+func takeBlob(out windows.DataBlob) []byte {
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...)
+}
+
+// The service name is the DPAPI entropy, so another app running as the
+// same user cannot decrypt the files without knowing it.
+func (s secureStore) load(key string) ([]byte, error) {
+	data, err := s.readFile(key)
+	if err != nil {
+		return nil, err
+	}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(dataBlob(data), nil, dataBlob([]byte(s.service)), 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("decrypting %s failed: %w", key, err)
+	}
+	return takeBlob(out), nil
+}
+
+func (s secureStore) save(key string, secret []byte) error {
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(dataBlob(secret), nil, dataBlob([]byte(s.service)), 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return fmt.Errorf("encrypting %s failed: %w", key, err)
+	}
+	return s.writeFile(key, takeBlob(out))
+}
+
+func (s secureStore) remove(key string) error {
+	return s.removeFile(key)
+}
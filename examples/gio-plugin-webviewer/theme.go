@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Theme modes accepted in app.json "theme.mode".
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+	ThemeAuto  = "auto" // Follow the OS appearance (default)
+)
+
+// themeConfig selects the shell chrome colors.
+type themeConfig struct {
+	Mode   string            `json:"mode,omitempty"`   // "auto" (default), "dark" or "light"
+	Colors map[string]string `json:"colors,omitempty"` // Overrides as "#rrggbb": toolbar, address, text, selection, tabActive, tab, button, buttonText
+}
+
+// palette holds the colors used to draw the toolbar, tabs and buttons.
+type palette struct {
+	Mode         string      // ThemeDark or ThemeLight, after resolving "auto"
+	HighContrast bool        // accessibility.highContrast
+	Toolbar      color.NRGBA // Header bar background
+	Address      color.NRGBA // Address field background
+	Text         color.NRGBA // Address and tab text
+	Selection    color.NRGBA // Address text selection
+	TabActive    color.NRGBA // Selected tab background
+	Tab          color.NRGBA // Other tabs' background
+	Button       color.NRGBA // Button background
+	ButtonText   color.NRGBA // Button label and icon
+}
+
+var darkPalette = palette{
+	Mode:       ThemeDark,
+	Toolbar:    color.NRGBA{R: 48, G: 52, B: 67, A: 255},
+	Address:    color.NRGBA{R: 24, G: 26, B: 33, A: 255},
+	Text:       color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+	Selection:  color.NRGBA{R: 123, G: 123, B: 123, A: 255},
+	TabActive:  color.NRGBA{R: 48, G: 52, B: 67, A: 255},
+	Tab:        color.NRGBA{R: 61, G: 61, B: 69, A: 255},
+	Button:     color.NRGBA{R: 237, G: 237, B: 237, A: 255},
+	ButtonText: color.NRGBA{R: 32, G: 32, B: 32, A: 255},
+}
+
+var lightPalette = palette{
+	Mode:       ThemeLight,
+	Toolbar:    color.NRGBA{R: 232, G: 234, B: 240, A: 255},
+	Address:    color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+	Text:       color.NRGBA{R: 32, G: 33, B: 36, A: 255},
+	Selection:  color.NRGBA{R: 168, G: 199, B: 250, A: 255},
+	TabActive:  color.NRGBA{R: 232, G: 234, B: 240, A: 255},
+	Tab:        color.NRGBA{R: 211, G: 214, B: 222, A: 255},
+	Button:     color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+	ButtonText: color.NRGBA{R: 32, G: 32, B: 32, A: 255},
+}
+
+// Theme is the active palette, set once at startup by newPalette.
+var Theme = darkPalette
+
+// newPalette resolves the mode (asking the OS for "auto") and applies
+// any custom colors from app.json.
+func newPalette(cfg themeConfig) (palette, error) {
+	mode := strings.ToLower(cfg.Mode)
+	if mode == "" || mode == ThemeAuto {
+		mode = ThemeLight
+		if systemDarkMode() {
+			mode = ThemeDark
+		}
+	}
+
+	var p palette
+	switch mode {
+	case ThemeDark:
+		p = darkPalette
+	case ThemeLight:
+		p = lightPalette
+	default:
+		return darkPalette, fmt.Errorf("unknown theme mode %q (use auto, dark or light)", cfg.Mode)
+	}
+
+	slots := p.colors()
+	for name, hex := range cfg.Colors {
+		slot, ok := slots[name]
+		if !ok {
+			return p, fmt.Errorf("unknown theme color %q", name)
+		}
+		c, err := parseHexColor(hex)
+		if err != nil {
+			return p, fmt.Errorf("theme color %q: %w", name, err)
+		}
+		*slot = c
+	}
+	return p, nil
+}
+
+// colors maps the app.json color names to the palette fields.
+func (p *palette) colors() map[string]*color.NRGBA {
+	return map[string]*color.NRGBA{
+		"toolbar":    &p.Toolbar,
+		"address":    &p.Address,
+		"text":       &p.Text,
+		"selection":  &p.Selection,
+		"tabActive":  &p.TabActive,
+		"tab":        &p.Tab,
+		"button":     &p.Button,
+		"buttonText": &p.ButtonText,
+	}
+}
+
+// parseHexColor parses "#rgb", "#rrggbb" or "#rrggbbaa".
+func parseHexColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// cssColor formats c as a CSS "#rrggbb" value.
+func cssColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// themeScript returns JavaScript installed into every page that exposes the
+// shell theme as window.goupTheme, sets data-goup-theme and color-scheme on
+// <html>, and fires a "goup-theme" event so sites can match the chrome.
+func themeScript(p palette) string {
+	colors := make(map[string]string)
+	for name, c := range p.colors() {
+		colors[name] = cssColor(*c)
+	}
+	data, _ := json.Marshal(map[string]any{"mode": p.Mode, "colors": colors, "highContrast": p.HighContrast})
+	return fmt.Sprintf(`(function () {
+  var theme = %s;
+  window.goupTheme = theme;
+  function apply() {
+    var root = document.documentElement;
+    if (!root) { return; }
+    root.dataset.goupTheme = theme.mode;
+    root.style.colorScheme = theme.mode;
+    if (theme.highContrast) { root.dataset.goupContrast = "more"; }
+    window.dispatchEvent(new CustomEvent("goup-theme", { detail: theme }));
+  }
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", apply);
+  } else {
+    apply();
+  }
+})();`, data)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"gioui.org/layout"
+	"github.com/gioui-plugins/gio-plugins/plugin/gioplugins"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+
+// touchConfig sets up navigation for tablet kiosks, which have no browser
+// buttons, keyboard shortcuts or mouse.
+type touchConfig struct {
+	Back        bool `json:"back,omitempty"`        // Back button in the toolbar
+	Home        bool `json:"home,omitempty"`        // Home button in the toolbar, to url
+	Swipe       bool `json:"swipe,omitempty"`       // Swipe in from the left edge to go back, from the right to go forward
+	IdleSeconds int  `json:"idleSeconds,omitempty"` // Return to url after this long without a touch; see idleConfig
+}
+
+// showNavButtons adds the back and home buttons app.json asks for to the
+// start of the toolbar.
+func (b *Browsers) showNavButtons(t touchConfig) {
+	var buttons []layout.FlexChild
+	if t.Back {
+		buttons = append(buttons,
+			layout.Rigid(Button{Clickable: &b.Back, Icon: IconBack}.Layout),
+			layout.Rigid(layout.Spacer{Width: 4}.Layout),
+		)
+	}
+	if t.Home {
+		buttons = append(buttons,
+			layout.Rigid(Button{Clickable: &b.Home, Icon: IconHome}.Layout),
+			layout.Rigid(layout.Spacer{Width: 4}.Layout),
+		)
+	}
+	b.HeaderFlex = append(buttons, b.HeaderFlex...)
+}
+
+// goBack goes back in the selected tab's history.
+func (b *Browsers) goBack(gtx layout.Context) {
+	if b.prepared[b.Selected] {
+		gioplugins.Execute(gtx, giowebview.ExecuteJavascriptCmd{View: b.Tags[b.Selected], Script: "history.back();"})
+	}
+}
+
+// goHome loads url in tab i.
+func (b *Browsers) goHome(gtx layout.Context, i int) {
+	b.Address[i].SetText(DefaultURL)
+	b.prepare(gtx, i)
+	gioplugins.Execute(gtx, giowebview.NavigateCmd{View: b.Tags[i], URL: b.guard(DefaultURL)})
+}
+
+// swipeScript navigates the history on horizontal swipes that start at the
+// edge of the page, leaving swipes inside it to carousels and maps.
+const swipeScript = `(function () {
+  if (window.__goupSwipe) { return; }
+  window.__goupSwipe = true;
+  var edge = 32, distance = 80, start = null;
+  window.addEventListener("touchstart", function (e) {
+    var t = e.touches[0];
+    start = null;
+    if (e.touches.length === 1 && (t.clientX < edge || t.clientX > window.innerWidth - edge)) {
+      start = { x: t.clientX, y: t.clientY, left: t.clientX < edge };
+    }
+  }, { passive: true });
+  window.addEventListener("touchend", function (e) {
+    if (!start) { return; }
+    var t = e.changedTouches[0], dx = t.clientX - start.x, dy = t.clientY - start.y;
+    if (Math.abs(dx) >= distance && Math.abs(dx) > 2 * Math.abs(dy)) {
+      if (start.left && dx > 0) { history.back(); }
+      if (!start.left && dx < 0) { history.forward(); }
+    }
+    start = null;
+  }, { passive: true });
+})();`
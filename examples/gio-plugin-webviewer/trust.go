@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gioui-plugins/gio-plugins/webviewer/webview"
+)
+
+// tlsConfig is for deployments that must not talk to an impostor: extra
+// CAs to trust, such as a corporate TLS inspection CA, and public key pins
+// for the site's own hosts.
+type tlsConfig struct {
+	CA    []string `json:"ca,omitempty"`    // PEM files of CAs to trust besides the system's (Windows, Android)
+	Pins  []string `json:"pins,omitempty"`  // "sha256/<base64>" of a public key in the site's certificate chain
+	Hosts []string `json:"hosts,omitempty"` // Hosts the pins apply to, exactly (default: the app URL's)
+}
+
+// pinCheckInterval is how often pinned hosts are checked again, and
+// pinRetryInterval how often while one is blocked.
+const (
+	pinCheckInterval = 10 * time.Minute
+	pinRetryInterval = 30 * time.Second
+)
+
+// errPinMismatch is a certificate chain with none of the pinned keys.
+var errPinMismatch = errors.New("certificate matches none of tls.pins")
+
+// loadTrustedCAs reads the tls.ca files. Relative paths are resolved
+// against the executable's directory first, then the working directory.
+func loadTrustedCAs(paths []string) ([]*x509.Certificate, error) {
+	var cas []*x509.Certificate
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			if exePath, err := os.Executable(); err == nil {
+				candidate := filepath.Join(filepath.Dir(exePath), path)
+				if _, err := os.Stat(candidate); err == nil {
+					path = candidate
+				}
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls.ca: %w", err)
+		}
+		found := false
+		for rest := data; ; {
+			var block *pem.Block
+			if block, rest = pem.Decode(rest); block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("tls.ca %s: %w", path, err)
+			}
+			cas = append(cas, cert)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("tls.ca %s: no PEM certificates", path)
+		}
+	}
+	return cas, nil
+}
+
+// applyTrustedCAs makes the webview trust cas. Like the proxy, it must
+// run before any tab exists. WKWebView and WebKitGTK only trust the
+// system's store, so there the CA has to be installed in it.
+func applyTrustedCAs(cas []*x509.Certificate) error {
+	if len(cas) == 0 {
+		return nil
+	}
+	if runtime.GOOS != "windows" && runtime.GOOS != "android" {
+		fmt.Fprintln(os.Stderr, tr("Warning: tls.ca is only applied on Windows and Android; install the CA in the system's trust store on %s", runtime.GOOS))
+		return nil
+	}
+	if err := webview.SetCustomCertificates(cas); err != nil {
+		return fmt.Errorf("failed to trust tls.ca: %w", err)
+	}
+	return nil
+}
+
+// certPins checks that the pinned hosts present a certificate chain with
+// a pinned key. The webview cannot be told to pin, so the shell makes its
+// own connection to each host, through the same proxy, when it starts
+// and every pinCheckInterval, and shows a host only while its last check
+// passed. This detects interception; it cannot pin the webview's own
+// connections.
+type certPins struct {
+	hosts  []string
+	pins   map[string]bool
+	client *http.Client
+
+	mu     sync.Mutex
+	passed map[string]bool // Hosts whose last check passed
+}
+
+// newCertPins returns nil unless app.json sets tls.pins. proxyURL is the
+// webview's proxy, if any.
+func newCertPins(cfg *appConfig, cas []*x509.Certificate, proxyURL string) (*certPins, error) {
+	if len(cfg.TLS.Pins) == 0 {
+		return nil, nil
+	}
+	p := &certPins{pins: map[string]bool{}, passed: map[string]bool{}}
+	for _, pin := range cfg.TLS.Pins {
+		hash, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(pin), "sha256/"))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("tls.pins %q: want \"sha256/\" and a base64 SHA-256 hash", pin)
+		}
+		p.pins[string(hash)] = true
+	}
+	hosts := cfg.TLS.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{cfg.URL}
+	}
+	for _, h := range hosts {
+		if host := parseRule(h); host != "" {
+			p.hosts = append(p.hosts, host)
+		}
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	for _, ca := range cas {
+		roots.AddCert(ca)
+	}
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			RootCAs:          roots,
+			VerifyConnection: p.verify,
+		},
+		DisableKeepAlives: true, // Every check sees a fresh handshake
+	}
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url %q", proxyURL)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	p.client = &http.Client{
+		Transport: transport,
+		Timeout:   15 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return p, nil
+}
+
+// verify accepts a verified chain that holds a pinned key.
+func (p *certPins) verify(cs tls.ConnectionState) error {
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			if p.pins[string(hash[:])] {
+				return nil
+			}
+		}
+	}
+	return errPinMismatch
+}
+
+// check connects to every pinned host and records the ones that passed.
+// A host that cannot be reached fails too: an interceptor could be
+// blocking the check. It returns whether the hosts that passed changed.
+func (p *certPins) check(ctx context.Context) bool {
+	passed := map[string]bool{}
+	for _, host := range p.hosts {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+host+"/", nil)
+		if err != nil {
+			continue
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("Warning: %s failed the certificate check: %v", host, err))
+			continue
+		}
+		resp.Body.Close()
+		passed[host] = true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := !maps.Equal(passed, p.passed)
+	p.passed = passed
+	return changed
+}
+
+// blocked reports whether a pinned host failed its last check.
+func (p *certPins) blocked() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.passed) < len(p.hosts)
+}
+
+// watch checks the pinned hosts every pinCheckInterval, or every
+// pinRetryInterval while one is blocked, until ctx is done. When the
+// outcome changes, every tab is reloaded so the navigation layer replaces
+// pages from a blocked host, or brings them back.
+func (p *certPins) watch(ctx context.Context, actions *pageActions, wake func()) {
+	for {
+		interval := pinCheckInterval
+		if p.blocked() {
+			interval = pinRetryInterval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+			if p.check(ctx) {
+				actions.push(pageAction{})
+				wake()
+			}
+		}
+	}
+}
+
+// Allowed reports whether rawURL may be loaded: false for pinned hosts
+// until they pass a check, and after they fail one.
+func (p *certPins) Allowed(rawURL string) bool {
+	if p == nil {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	if !slices.Contains(p.hosts, host) {
+		return true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.passed[host]
+}
+
+// untrustedPage returns a page explaining that rawURL's certificate
+// could not be confirmed.
+func untrustedPage(rawURL string) string {
+	return shellPage(tr("Connection not trusted"), fmt.Sprintf(`<div style="text-align:center"><h2>%s</h2><p>%s</p><p>%s</p></div>`,
+		html.EscapeString(tr("This site's certificate could not be confirmed")),
+		html.EscapeString(tr("Someone may be intercepting the connection, or the site cannot be reached. The app retries every 30 seconds. If this persists, contact your IT department.")),
+		html.EscapeString(rawURL)))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCertPins(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	hash := sha256.Sum256(srv.Certificate().RawSubjectPublicKeyInfo)
+	pin := "sha256/" + base64.StdEncoding.EncodeToString(hash[:])
+	other := "sha256/" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	// pinsFor checks example.com, the test certificate's name, against the
+	// test server, or against addr if set.
+	pinsFor := func(addr string, pins ...string) *certPins {
+		t.Helper()
+		cfg := newAppConfig()
+		cfg.URL = "https://example.com/app"
+		cfg.TLS.Pins = pins
+		p, err := newCertPins(cfg, []*x509.Certificate{srv.Certificate()}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr == "" {
+			addr = srv.Listener.Addr().String()
+		}
+		p.client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		}
+		return p
+	}
+
+	p := pinsFor("", other, pin)
+	if p.Allowed("https://example.com/app") {
+		t.Error("pinned host allowed before its first check")
+	}
+	if !p.check(context.Background()) || !p.Allowed("https://example.com/app") || p.blocked() {
+		t.Error("host with a pinned key blocked")
+	}
+	if !p.Allowed("https://unpinned.example.org/") {
+		t.Error("unpinned host blocked")
+	}
+	if p.check(context.Background()) {
+		t.Error("unchanged outcome reported as a change")
+	}
+
+	p = pinsFor("", other)
+	p.check(context.Background())
+	if p.Allowed("https://example.com/app") {
+		t.Error("host without a pinned key allowed")
+	}
+
+	// An interceptor that drops the check must not get the host through
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+	p = pinsFor(addr, pin)
+	p.check(context.Background())
+	if p.Allowed("https://example.com/app") || !p.blocked() {
+		t.Error("unreachable pinned host allowed")
+	}
+}
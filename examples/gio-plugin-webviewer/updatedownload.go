@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Download policy, inlined from goup-util's pkg/updater: large updates can
+// wait for off-hours or a connection that is not metered, downloads can
+// be capped in bandwidth, and an interrupted download resumes where it
+// stopped, for shells on cellular routers and weak kiosk connections.
+
+// downloadPolicy is the "download" section of update in app.json.
+type downloadPolicy struct {
+	Window    string `json:"window,omitempty"`    // Local off-hours for large downloads, e.g. "01:00-05:00"
+	Unmetered bool   `json:"unmetered,omitempty"` // Large downloads wait while the connection is metered
+	LargeMB   int    `json:"largeMB,omitempty"`   // Size above which window and unmetered apply (default: every download)
+	MaxKBps   int    `json:"maxKBps,omitempty"`   // Bandwidth cap in KB/s (default: unlimited)
+}
+
+// deferredError is returned by selfUpdate when the policy holds the
+// download back until retry.
+type deferredError struct {
+	reason string
+	retry  time.Time
+}
+
+func (e *deferredError) Error() string {
+	return fmt.Sprintf("update deferred: %s; retrying at %s", e.reason, e.retry.Format("15:04"))
+}
+
+// check returns a deferredError when a download of size bytes has to wait.
+func (p downloadPolicy) check(size int64) error {
+	if p.LargeMB > 0 && size <= int64(p.LargeMB)<<20 {
+		return nil
+	}
+	now := time.Now()
+	if p.Window != "" {
+		from, to, ok := strings.Cut(p.Window, "-")
+		start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+		end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+		if !ok || err1 != nil || err2 != nil {
+			return fmt.Errorf("update.download.window %q is not HH:MM-HH:MM", p.Window)
+		}
+		s, e := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+		m := now.Hour()*60 + now.Minute()
+		in := m >= s && m < e
+		if s > e { // Spans midnight
+			in = m >= s || m < e
+		}
+		if !in && s != e {
+			midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			next := midnight.Add(time.Duration(s) * time.Minute)
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			return &deferredError{reason: "outside the download window " + p.Window, retry: next}
+		}
+	}
+	if p.Unmetered && meteredConnection() {
+		return &deferredError{reason: "the connection is metered", retry: now.Add(15 * time.Minute)}
+	}
+	return nil
+}
+
+// updateWhenAllowed runs selfUpdate, waiting out the download policy.
+func updateWhenAllowed(cfg *appConfig) (installed bool, err error) {
+	for {
+		installed, err := selfUpdate(cfg)
+		var d *deferredError
+		if !errors.As(err, &d) {
+			return installed, err
+		}
+		fmt.Println(tr("Update deferred: %s; retrying at %s", d.reason, d.retry.Format("15:04")))
+		time.Sleep(time.Until(d.retry))
+	}
+}
+
+// meteredConnection reports a cellular, tethered or user-marked metered
+// connection: NetworkManager on Linux, the connection cost on Windows.
+// macOS cannot tell and reports false.
+func meteredConnection() bool {
+	switch runtime.GOOS {
+	case "linux":
+		out, _ := exec.Command("nmcli", "-t", "-f", "GENERAL.METERED", "device", "show").Output()
+		for _, line := range strings.Split(string(out), "\n") {
+			if _, v, _ := strings.Cut(line, ":"); strings.HasPrefix(v, "yes") {
+				return true
+			}
+		}
+	case "windows":
+		out, _ := exec.Command("powershell", "-NoProfile", "-Command",
+			"[void][Windows.Networking.Connectivity.NetworkInformation,Windows.Networking.Connectivity,ContentType=WindowsRuntime];"+
+				"$p = [Windows.Networking.Connectivity.NetworkInformation]::GetInternetConnectionProfile();"+
+				"if ($p) { $p.GetConnectionCost().NetworkCostType }").Output()
+		cost := strings.TrimSpace(string(out))
+		return cost == "Fixed" || cost == "Variable"
+	}
+	return false
+}
+
+// downloadUpdate fetches the release's asset to a file the caller removes.
+// A partial download from an earlier attempt at the same release resumes;
+// those of other releases are removed.
+func downloadUpdate(cfg *appConfig, a releaseAsset, tag string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	dir := filepath.Join(base, cfg.Name, "updates")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, tag+"-"+a.Name+".partial")
+	old, _ := filepath.Glob(filepath.Join(dir, "*.partial"))
+	for _, f := range old {
+		if f != path {
+			os.Remove(f)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer f.Close()
+	offset, _ := f.Seek(0, io.SeekEnd)
+	if a.Size == 0 || offset >= a.Size {
+		offset = 0
+	}
+	f.Truncate(offset)
+	f.Seek(offset, io.SeekStart)
+	if offset > 0 {
+		fmt.Println(tr("Resuming at %d of %d bytes", offset, a.Size))
+	}
+
+	var w io.Writer = f
+	if kbps := cfg.Update.Download.MaxKBps; kbps > 0 {
+		w = &throttled{w: f, rate: int64(kbps) << 10, start: time.Now()}
+	}
+	if err := downloadAsset(cfg, a, offset, w); err != nil {
+		return "", err // The partial file is kept for the next attempt
+	}
+
+	if a.SHA256 != "" {
+		f.Seek(0, io.SeekStart)
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), a.SHA256) {
+			os.Remove(path)
+			return "", fmt.Errorf("download of %s does not match the SHA-256 in the release index", a.Name)
+		}
+	}
+	return path, nil
+}
+
+// throttled limits writes to rate bytes per second on average.
+type throttled struct {
+	w     io.Writer
+	rate  int64
+	start time.Time
+	n     int64
+}
+
+func (t *throttled) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(int64(len(p)), max(t.rate/10, 1))]
+		n, err := t.w.Write(chunk)
+		written += n
+		t.n += int64(n)
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+		due := t.start.Add(time.Duration(float64(t.n) / float64(t.rate) * float64(time.Second)))
+		if wait := time.Until(due); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	return written, nil
+}
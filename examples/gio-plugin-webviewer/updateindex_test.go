@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchIndexRelease(t *testing.T) {
+	index := `[{"version": "v1.3.0", "assets": [{"name": "app.zip", "url": "v1.3.0/app.zip", "size": 3}]}]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, index)
+	}))
+	defer srv.Close()
+
+	r, err := fetchIndexRelease(srv.URL + "/releases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.RolloutPercentage != nil {
+		t.Errorf("release without a rollout has RolloutPercentage %d, want nil (everyone)", *r.RolloutPercentage)
+	}
+	if !inRollout(r.TagName, r.RolloutPercentage) {
+		t.Error("release without a rollout is not offered")
+	}
+	if want := srv.URL + "/releases/v1.3.0/app.zip"; r.Assets[0].BrowserDownloadURL != want {
+		t.Errorf("asset URL = %q, want %q", r.Assets[0].BrowserDownloadURL, want)
+	}
+
+	index = `[{"version": "v1.3.0", "rollout": 0, "assets": []}]`
+	if r, err := fetchIndexRelease(srv.URL + "/releases"); err != nil || r.RolloutPercentage == nil || *r.RolloutPercentage != 0 {
+		t.Errorf("rollout 0 = %+v, %v", r, err)
+	}
+}
+
+func TestInRollout(t *testing.T) {
+	pct := func(n int) *int { return &n }
+	tests := []struct {
+		percentage *int
+		want       bool
+	}{
+		{nil, true},
+		{pct(100), true},
+		{pct(150), true},
+		{pct(0), false},
+		{pct(-5), false},
+	}
+	for _, tt := range tests {
+		if got := inRollout("v1.3.0", tt.percentage); got != tt.want {
+			t.Errorf("inRollout(%v) = %v, want %v", tt.percentage, got, tt.want)
+		}
+	}
+
+	// A staged rollout picks the same installs every time and about the
+	// share asked for across releases.
+	in := 0
+	for i := 0; i < 1000; i++ {
+		version := fmt.Sprintf("v1.%d.0", i)
+		got := inRollout(version, pct(30))
+		if got != inRollout(version, pct(30)) {
+			t.Fatalf("inRollout(%s) changed between calls", version)
+		}
+		if got {
+			in++
+		}
+	}
+	if in < 220 || in > 380 {
+		t.Errorf("30%% rollout included %d of 1000 releases", in)
+	}
+}
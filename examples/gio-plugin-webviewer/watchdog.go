@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// watchdogConfig keeps a long-running shell healthy: a daily restart, and
+// a memory limit for the shell and its webview processes. Each action is
+// logged to watchdog.log in the app's config directory.
+type watchdogConfig struct {
+	RestartAt    string `json:"restartAt,omitempty"`    // Local time of a daily restart ("03:30")
+	MaxMemoryMB  int    `json:"maxMemoryMB,omitempty"`  // Memory limit (resident set of the process tree)
+	MemoryAction string `json:"memoryAction,omitempty"` // "reload" (default) or "restart"
+	CheckMinutes int    `json:"checkMinutes,omitempty"` // Memory check interval (default 5)
+}
+
+// watchdogCooldown is the least time between memory actions, so a page
+// that is still large after a reload is not reloaded in a loop.
+const watchdogCooldown = 10 * time.Minute
+
+// startWatchdog validates the watchdog settings and runs them in the
+// background. Reloads go to actions; restart exits the process.
+func startWatchdog(ctx context.Context, cfg *appConfig, actions *pageActions, wake func(), restart func() error) error {
+	w := cfg.Watchdog
+	var at time.Time
+	if w.RestartAt != "" {
+		var err error
+		if at, err = time.Parse("15:04", w.RestartAt); err != nil {
+			return fmt.Errorf("watchdog.restartAt %q: want a time like \"03:30\"", w.RestartAt)
+		}
+	}
+	switch w.MemoryAction {
+	case "", "reload", "restart":
+	default:
+		return fmt.Errorf("watchdog.memoryAction %q: want \"reload\" or \"restart\"", w.MemoryAction)
+	}
+	logPath := ""
+	if dir, err := os.UserConfigDir(); err == nil {
+		logPath = filepath.Join(dir, cfg.Name, "watchdog.log")
+	}
+	logEvent := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		fmt.Println(tr("Watchdog: %s", msg))
+		if logPath == "" || os.MkdirAll(filepath.Dir(logPath), 0755) != nil {
+			return
+		}
+		if f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+			fmt.Fprintf(f, "%s %s\n", time.Now().Format(time.RFC3339), msg)
+			f.Close()
+		}
+	}
+	doRestart := func(reason string) {
+		logEvent("restart: %s", reason)
+		if err := restart(); err != nil {
+			logEvent("restart failed: %v", err)
+		}
+	}
+
+	if w.RestartAt != "" {
+		go func() {
+			next := nextDaily(time.Now(), at.Hour(), at.Minute())
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Until(next)):
+				doRestart("scheduled (" + w.RestartAt + ")")
+			}
+		}()
+	}
+
+	if w.MaxMemoryMB > 0 {
+		interval := time.Duration(w.CheckMinutes) * time.Minute
+		if interval <= 0 {
+			interval = 5 * time.Minute
+		}
+		limit := int64(w.MaxMemoryMB) << 20
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			var acted time.Time
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+				used, err := treeMemory(os.Getpid())
+				if err != nil {
+					logEvent("memory check disabled: %v", err)
+					return
+				}
+				if used <= limit || time.Since(acted) < watchdogCooldown {
+					continue
+				}
+				acted = time.Now()
+				reason := fmt.Sprintf("memory %d MB over the %d MB limit", used>>20, w.MaxMemoryMB)
+				if w.MemoryAction == "restart" {
+					doRestart(reason)
+					continue
+				}
+				logEvent("reload: %s", reason)
+				actions.push(pageAction{})
+				wake()
+			}
+		}()
+	}
+	return nil
+}
+
+// nextDaily returns the next time after now at hour:min local time.
+func nextDaily(now time.Time, hour, min int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, min, 0, 0, now.Location())
+	if !next.After(now) {
+		next = time.Date(now.Year(), now.Month(), now.Day()+1, hour, min, 0, 0, now.Location())
+	}
+	return next
+}
+
+// treeMemory returns the resident memory in bytes of process pid and its
+// descendants, which include the webview's helper processes on Windows
+// and Linux. macOS runs WebKit's content processes under launchd, so
+// there it covers the shell process only.
+func treeMemory(pid int) (int64, error) {
+	table, err := processTable()
+	if err != nil {
+		return 0, err
+	}
+	// Lines are "pid ppid rss-in-KB"
+	children := map[int][]int{}
+	rss := map[int]int64{}
+	sc := bufio.NewScanner(bytes.NewReader(table))
+	for sc.Scan() {
+		var p, pp int
+		var kb int64
+		if n, _ := fmt.Sscan(sc.Text(), &p, &pp, &kb); n == 3 {
+			children[pp] = append(children[pp], p)
+			rss[p] = kb
+		}
+	}
+	if _, ok := rss[pid]; !ok {
+		return 0, fmt.Errorf("process %d not in the process list", pid)
+	}
+	var total int64
+	seen := map[int]bool{}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		total += rss[p]
+		queue = append(queue, children[p]...)
+	}
+	return total << 10, nil
+}
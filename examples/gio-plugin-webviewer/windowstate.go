@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"gioui.org/app"
+)
+
+// windowState is where the window was left: its last windowed frame and
+// whether it was maximized.
+type windowState struct {
+	Bounds    rect `json:"bounds"`
+	Maximized bool `json:"maximized,omitempty"`
+}
+
+// windowSavePeriod is how often the window frame is checked for changes;
+// Gio has no event for a moved window.
+const windowSavePeriod = 2 * time.Second
+
+// windowSaver restores the window where it was left and saves where it is
+// left, in window-state.json in the app's config directory.
+type windowSaver struct {
+	w     *app.Window
+	path  string
+	saved windowState
+	mode  atomic.Uint32 // app.WindowMode, from ConfigEvents
+}
+
+// newWindowSaver returns nil when app.json turns remembering off or pins
+// the window to a display.
+func newWindowSaver(w *app.Window, cfg *appConfig) *windowSaver {
+	if !cfg.RememberWindow || cfg.Display.fixed() {
+		return nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	s := &windowSaver{w: w, path: filepath.Join(dir, cfg.Name, "window-state.json")}
+	if data, err := os.ReadFile(s.path); err == nil {
+		json.Unmarshal(data, &s.saved)
+	}
+	return s
+}
+
+// config records the window mode.
+func (s *windowSaver) config(c app.Config) {
+	s.mode.Store(uint32(c.Mode))
+}
+
+// restore puts the window back where it was left, if that is still on a
+// connected monitor; otherwise the window keeps its default place.
+func (s *windowSaver) restore(view app.ViewEvent) {
+	if s.saved.Bounds.W > 0 {
+		s.w.Run(func() {
+			monitors, err := monitors()
+			if err != nil {
+				return
+			}
+			if r, ok := fit(s.saved.Bounds, monitors); ok {
+				setWindowRect(view, r)
+			}
+		})
+	}
+	if s.saved.Maximized {
+		s.w.Option(app.Maximized.Option())
+	}
+}
+
+// run saves the window state whenever it changes. It never returns; the
+// app exits with the window.
+func (s *windowSaver) run(view app.ViewEvent) {
+	for range time.Tick(windowSavePeriod) {
+		state := s.saved
+		switch app.WindowMode(s.mode.Load()) {
+		case app.Windowed:
+			var err error
+			s.w.Run(func() { state.Bounds, err = windowRect(view) })
+			if err != nil {
+				return // Not supported here
+			}
+			state.Maximized = false
+		case app.Maximized:
+			state.Maximized = true // Keep the windowed frame to go back to
+		default:
+			continue // Minimized or fullscreen: keep the last state
+		}
+		if state == s.saved || state.Bounds.W <= 0 {
+			continue
+		}
+		data, err := json.MarshalIndent(state, "", "  ")
+		if err != nil || os.MkdirAll(filepath.Dir(s.path), 0755) != nil {
+			continue
+		}
+		if os.WriteFile(s.path, data, 0644) == nil {
+			s.saved = state
+		}
+	}
+}
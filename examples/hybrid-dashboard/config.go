@@ -0,0 +1,43 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+//go:embed app.json
+var embeddedConfig []byte
+
+// appConfig is the part of goup-util's app.json this example reads.
+type appConfig struct {
+	Name   string       `json:"name,omitempty"`
+	Server serverConfig `json:"server,omitempty"`
+}
+
+// serverConfig configures the embedded HTTP server.
+type serverConfig struct {
+	TLS  bool `json:"tls,omitempty"`  // HTTPS with a self-signed localhost certificate (see tls.go)
+	Port int  `json:"port,omitempty"` // Fixed port; 0 reuses the last launch's (see server.go)
+}
+
+// loadAppConfig reads app.json from the executable's directory, then the
+// current working directory, then the copy embedded at build time (the
+// only one on mobile).
+func loadAppConfig() *appConfig {
+	cfg := &appConfig{}
+	var dirs []string
+	if exePath, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(exePath))
+	}
+	dirs = append(dirs, ".")
+	for _, dir := range dirs {
+		if data, err := os.ReadFile(filepath.Join(dir, "app.json")); err == nil {
+			json.Unmarshal(data, cfg)
+			return cfg
+		}
+	}
+	json.Unmarshal(embeddedConfig, cfg)
+	return cfg
+}
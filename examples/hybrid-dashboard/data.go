@@ -0,0 +1,253 @@
+package main
+
+// Local data for the web UI: an embedded SQLite database with numbered
+// migrations and a JSON API under /api/data/. This is a standalone copy of
+// goup-util's pkg/localdb, so the example builds without depending on the
+// goup-util module; 'goup-util data inspect examples/hybrid-dashboard'
+// shows what the app stored.
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	_ "modernc.org/sqlite" // Pure Go driver, so the app cross-compiles without cgo
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// dataTables are the tables the web UI may read and write.
+var dataTables = []string{"notes"}
+
+// openData opens data.db in the app's config directory and applies the
+// migrations in migrations/, each once, tracked by SQLite's user_version.
+func openData(appName string) (*sql.DB, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	dir = filepath.Join(dir, appName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "data.db")
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+func migrate(db *sql.DB) error {
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	var have int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&have); err != nil {
+		return err
+	}
+	if have > len(names) {
+		return fmt.Errorf("database is at version %d but the app only knows %d migrations", have, len(names))
+	}
+	for i := have; i < len(names); i++ {
+		stmt, err := migrationFiles.ReadFile(names[i])
+		if err != nil {
+			return err
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(string(stmt)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s failed: %w", names[i], err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dataHandler serves the tables in dataTables:
+//
+//	GET    /api/data/{table}       list rows (?limit=&offset=)
+//	POST   /api/data/{table}       insert a JSON object
+//	PUT    /api/data/{table}/{id}  update the given columns
+//	DELETE /api/data/{table}/{id}  delete
+func dataHandler(db *sql.DB) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/data/{table}", func(w http.ResponseWriter, r *http.Request) {
+		table, _, ok := dataTable(db, w, r)
+		if !ok {
+			return
+		}
+		limit, offset := r.URL.Query().Get("limit"), r.URL.Query().Get("offset")
+		if limit == "" {
+			limit = "100"
+		}
+		if offset == "" {
+			offset = "0"
+		}
+		rows, err := queryMaps(db, `SELECT * FROM `+table+` ORDER BY id LIMIT ? OFFSET ?`, limit, offset)
+		writeRows(w, rows, err, http.StatusOK, false)
+	})
+	mux.HandleFunc("POST /api/data/{table}", func(w http.ResponseWriter, r *http.Request) {
+		table, cols, ok := dataTable(db, w, r)
+		if !ok {
+			return
+		}
+		names, args, ok := dataBody(w, r, cols)
+		if !ok {
+			return
+		}
+		stmt := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) RETURNING *`, table,
+			strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+		rows, err := queryMaps(db, stmt, args...)
+		writeRows(w, rows, err, http.StatusCreated, true)
+	})
+	mux.HandleFunc("PUT /api/data/{table}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		table, cols, ok := dataTable(db, w, r)
+		if !ok {
+			return
+		}
+		names, args, ok := dataBody(w, r, cols)
+		if !ok {
+			return
+		}
+		for i := range names {
+			names[i] += " = ?"
+		}
+		rows, err := queryMaps(db, `UPDATE `+table+` SET `+strings.Join(names, ", ")+` WHERE id = ? RETURNING *`,
+			append(args, r.PathValue("id"))...)
+		writeRows(w, rows, err, http.StatusOK, true)
+	})
+	mux.HandleFunc("DELETE /api/data/{table}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		table, _, ok := dataTable(db, w, r)
+		if !ok {
+			return
+		}
+		res, err := db.Exec(`DELETE FROM `+table+` WHERE id = ?`, r.PathValue("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+// dataTable returns the requested table, quoted, and its columns.
+func dataTable(db *sql.DB, w http.ResponseWriter, r *http.Request) (string, []string, bool) {
+	name := r.PathValue("table")
+	if !slices.Contains(dataTables, name) {
+		http.Error(w, "unknown table "+name, http.StatusNotFound)
+		return "", nil, false
+	}
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", nil, false
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var c string
+		rows.Scan(&c)
+		cols = append(cols, c)
+	}
+	return `"` + name + `"`, cols, true
+}
+
+// dataBody decodes a JSON object whose keys must be columns; the id is
+// left to SQLite.
+func dataBody(w http.ResponseWriter, r *http.Request, cols []string) ([]string, []any, bool) {
+	var obj map[string]any
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&obj); err != nil || len(obj) == 0 {
+		http.Error(w, "body must be a JSON object", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	var names []string
+	var args []any
+	for k, v := range obj {
+		if k == "id" {
+			continue
+		}
+		if !slices.Contains(cols, k) {
+			http.Error(w, "unknown column "+k, http.StatusBadRequest)
+			return nil, nil, false
+		}
+		names = append(names, `"`+k+`"`)
+		args = append(args, v)
+	}
+	return names, args, true
+}
+
+func queryMaps(db *sql.DB, q string, args ...any) ([]map[string]any, error) {
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, _ := rows.Columns()
+	out := []map[string]any{}
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		m := map[string]any{}
+		for i, c := range cols {
+			m[c] = vals[i]
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// writeRows writes a list, or with single the one row a statement returned.
+func writeRows(w http.ResponseWriter, rows []map[string]any, err error, code int, single bool) {
+	switch {
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case single && len(rows) == 0:
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if single {
+		json.NewEncoder(w).Encode(rows[0])
+		return
+	}
+	json.NewEncoder(w).Encode(rows)
+}
@@ -0,0 +1,45 @@
+package main
+
+// Dev proxy: with $GOUP_DEV_PROXY set ('goup-util run --dev-proxy
+// http://localhost:5173', or "devProxy" in app.json) the page comes from a
+// frontend dev server instead of web/, so edits hot reload in the window.
+// This is a standalone copy of goup-util's pkg/devproxy, so the example
+// builds without depending on the goup-util module.
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+)
+
+// devProxyHandler returns a proxy to the dev server in $GOUP_DEV_PROXY,
+// or fallback when it is not set.
+func devProxyHandler(fallback http.Handler) http.Handler {
+	target := os.Getenv("GOUP_DEV_PROXY")
+	if target == "" {
+		return fallback
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		log.Printf("Dev proxy %q is not an http:// URL; serving the embedded files", target)
+		return fallback
+	}
+	log.Printf("Dev proxy: forwarding to %s", target)
+	origin := u.Scheme + "://" + u.Host
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(u)
+			// Dev servers refuse HMR sockets from other origins.
+			if r.In.Header.Get("Origin") != "" {
+				r.Out.Header.Set("Origin", origin)
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("Dev server %s is not reachable (%v).\nStart it (e.g. npm run dev).", target, err),
+				http.StatusBadGateway)
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout bounds how long the shutdown hooks may take together,
+// so a hung hook cannot keep a closed window's process alive.
+const shutdownTimeout = 5 * time.Second
+
+// life runs the app's shutdown: background work started with life.ctx
+// stops, then the hooks run, newest first, before the process exits. A
+// standalone copy of goup-util's pkg/lifecycle, so the example builds
+// without depending on the goup-util module.
+var life = newLifecycle()
+
+type lifecycle struct {
+	ctx    context.Context // Cancelled when shutdown starts
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	hooks []shutdownHook
+	once  sync.Once
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// onShutdown registers fn to run on exit.
+func (l *lifecycle) onShutdown(name string, fn func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, shutdownHook{name, fn})
+}
+
+// exit runs the shutdown once and exits with code. A second caller blocks
+// until the first one exits.
+func (l *lifecycle) exit(code int) {
+	l.once.Do(func() {
+		l.cancel()
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		l.mu.Lock()
+		hooks := l.hooks
+		l.mu.Unlock()
+		for i := len(hooks) - 1; i >= 0; i-- {
+			h := hooks[i]
+			errc := make(chan error, 1)
+			go func() { errc <- h.fn(ctx) }()
+			select {
+			case err := <-errc:
+				if err != nil {
+					log.Printf("Shutdown: %s: %v", h.name, err)
+				}
+			case <-ctx.Done():
+				log.Printf("Shutdown: %s: %v", h.name, ctx.Err())
+			}
+		}
+		os.Exit(code)
+	})
+	select {}
+}
+
+// handleSignals exits cleanly on Ctrl-C, a service stop or a logout.
+func (l *lifecycle) handleSignals() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		l.exit(0)
+	}()
+}
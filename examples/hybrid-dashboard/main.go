@@ -1,16 +1,19 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log"
 	"math/rand"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"runtime"
 	"strings"
 	"time"
 
@@ -52,6 +55,7 @@ var (
 	startTime     = time.Now()
 	th            = material.NewTheme()
 	pendingURLs   = make(chan string, 10) // Channel for deep link URLs
+	replayedPages = make(chan string, 10) // Page loads replayed from a recorded session
 	lastDeepLink  *DeepLinkInfo           // Most recent deep link for API
 )
 
@@ -62,22 +66,21 @@ func main() {
 	webview.SetDebug(true)
 
 	// Start embedded HTTP server
-	serverURL := startWebServer()
+	serverURL := startWebServer(loadAppConfig())
 	fmt.Printf("Web server started at %s\n", serverURL)
 
 	// Launch Gio UI app
+	life.handleSignals()
 	go runApp(serverURL)
 	app.Main()
 }
 
-func startWebServer() string {
-	// Find available port
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+func startWebServer(cfg *appConfig) string {
+	// Same port as last time, so the page keeps its localStorage (see server.go)
+	listener, err := listenLocal(cfg.Server.Port, "hybrid-dashboard")
 	if err != nil {
 		log.Fatal(err)
 	}
-	port := listener.Addr().(*net.TCPAddr).Port
-	listener.Close()
 
 	// Serve embedded web content
 	webFS, err := fs.Sub(webContent, "web")
@@ -86,7 +89,7 @@ func startWebServer() string {
 	}
 
 	mux := http.NewServeMux()
-	mux.Handle("/", http.FileServer(http.FS(webFS)))
+	mux.Handle("/", devProxyHandler(http.FileServer(http.FS(webFS))))
 	
 	// API endpoint: Get system stats (called from JavaScript)
 	mux.HandleFunc("/api/stats", handleStats)
@@ -97,30 +100,52 @@ func startWebServer() string {
 	// API endpoint: Get last deep link (for JavaScript to poll)
 	mux.HandleFunc("/api/deeplink", handleDeepLink)
 
-	serverAddr := fmt.Sprintf("127.0.0.1:%d", port)
-	go func() {
-		log.Printf("HTTP server listening on http://%s\n", serverAddr)
-		if err := http.ListenAndServe(serverAddr, mux); err != nil {
-			log.Fatal(err)
-		}
-	}()
+	// Health endpoint for local monitoring (same format as goup-util's pkg/healthcheck)
+	mux.HandleFunc("/healthz", handleHealthz)
 
-	// Give server time to start
-	time.Sleep(200 * time.Millisecond)
+	// API endpoints: local SQLite data (see data.go). The page still works
+	// without it, so a database problem is logged rather than fatal.
+	if db, err := openData("hybrid-dashboard"); err != nil {
+		log.Printf("Local data disabled: %v", err)
+	} else {
+		mux.Handle("/api/data/", dataHandler(db))
+		life.onShutdown("database", func(context.Context) error { return db.Close() })
+	}
 
-	// Verify server is ready
-	serverURL := fmt.Sprintf("http://%s", serverAddr)
-	for i := 0; i < 10; i++ {
-		resp, err := http.Get(serverURL)
-		if err == nil {
-			resp.Body.Close()
-			log.Printf("Server verified ready at %s", serverURL)
-			break
+	server := &http.Server{Handler: mux}
+	scheme := "http"
+
+	// HTTPS for secure-context APIs, if app.json asks for it (see tls.go)
+	if cfg.Server.TLS && localTLSSupported() {
+		cert, err := localCertificate("hybrid-dashboard")
+		if err != nil {
+			log.Printf("TLS disabled: %v", err)
+		} else if err := webview.SetCustomCertificates([]*x509.Certificate{cert.Leaf}); err != nil {
+			log.Printf("TLS disabled, the webview cannot trust the certificate: %v", err)
+		} else {
+			server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+			scheme = "https"
 		}
-		log.Printf("Waiting for server... attempt %d", i+1)
-		time.Sleep(100 * time.Millisecond)
 	}
 
+	// The listener is open, so the page can load as soon as this returns
+	serverURL := fmt.Sprintf("%s://%s", scheme, listener.Addr())
+	go func() {
+		log.Printf("HTTP server listening on %s\n", serverURL)
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != http.ErrServerClosed {
+			log.Printf("HTTP server stopped: %v", err)
+		}
+	}()
+
+	// Runs before the database closes, once open requests are done
+	life.onShutdown("server", server.Shutdown)
+
 	return serverURL
 }
 
@@ -137,6 +162,20 @@ func handleStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	host, _ := os.Hostname()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":        "ok",
+		"app":           "hybrid-dashboard",
+		"host":          host,
+		"platform":      runtime.GOOS + "/" + runtime.GOARCH,
+		"started":       startTime.UTC(),
+		"uptimeSeconds": int64(time.Since(startTime).Seconds()),
+	})
+}
+
 func handleHello(w http.ResponseWriter, r *http.Request) {
 	response := map[string]string{
 		"message": "Hello from Go! 🚀",
@@ -180,6 +219,21 @@ func parseDeepLink(rawURL string) *DeepLinkInfo {
 	}
 }
 
+// onServer moves a page recorded on an earlier launch's server, which may
+// have had another port, to this launch's server.
+func onServer(page, serverURL string) string {
+	u, err := url.Parse(page)
+	if err != nil || u.Hostname() != "127.0.0.1" {
+		return page
+	}
+	base, err := url.Parse(serverURL)
+	if err != nil {
+		return page
+	}
+	u.Scheme, u.Host = base.Scheme, base.Host
+	return u.String()
+}
+
 // mapDeepLinkToWebPath converts a deep link path to a web app path
 // Examples:
 //   hybrid://dashboard/stats  -> /#/stats
@@ -216,6 +270,17 @@ func runApp(serverURL string) {
 	frameCount := 0
 	pendingNavigation := "" // URL to navigate to (from deep link)
 
+	// Record deep links and page loads for 'goup-util replay' (see session.go)
+	recorder := startSession(life.ctx, func(e sessionEvent) {
+		switch e.Kind {
+		case "url":
+			pendingURLs <- e.URL
+		case "navigate":
+			replayedPages <- e.URL
+		}
+		window.Invalidate()
+	})
+
 	// Trigger initial frame
 	window.Invalidate()
 
@@ -224,7 +289,7 @@ func runApp(serverURL string) {
 
 		switch evt := evt.(type) {
 		case app.DestroyEvent:
-			os.Exit(0)
+			life.exit(0)
 			return
 
 		// Handle deep link URLs (app.URLEvent)
@@ -233,6 +298,7 @@ func runApp(serverURL string) {
 		case app.URLEvent:
 			rawURL := evt.URL.String()
 			log.Printf("Deep link received: %s", rawURL)
+			recorder.record("url", rawURL, "")
 
 			// Create DeepLinkInfo directly from the parsed URL
 			info := &DeepLinkInfo{
@@ -267,6 +333,7 @@ func runApp(serverURL string) {
 				switch e := ev.(type) {
 				case giowebview.NavigationEvent:
 					log.Printf("Navigation event: %s", e.URL)
+					recorder.record("navigate", e.URL, "")
 				case giowebview.TitleEvent:
 					log.Printf("Title event: %s", e.Title)
 				}
@@ -295,6 +362,21 @@ func runApp(serverURL string) {
 				navigated = true
 			}
 
+			// Deep links and page loads replayed from a recorded session
+			for replayed := true; replayed; {
+				select {
+				case rawURL := <-pendingURLs:
+					if info := parseDeepLink(rawURL); info != nil {
+						lastDeepLink = info
+						pendingNavigation = mapDeepLinkToWebPath(info, serverURL)
+					}
+				case page := <-replayedPages:
+					pendingNavigation = onServer(page, serverURL)
+				default:
+					replayed = false
+				}
+			}
+
 			// Handle pending deep link navigation
 			if pendingNavigation != "" {
 				log.Printf("Navigating webview to: %s", pendingNavigation)
@@ -0,0 +1,7 @@
+-- Notes written in the dashboard's Notes card.
+CREATE TABLE notes (
+	id      INTEGER PRIMARY KEY,
+	text    TEXT NOT NULL,
+	done    INTEGER NOT NULL DEFAULT 0,
+	created TEXT NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,56 @@
+package main
+
+// Listener for the embedded server. The page's origin
+// includes the port, and localStorage belongs to the origin, so the port
+// stays the same across launches: "server": {"port": 8123} in app.json,
+// or else the port the last launch used. A taken port is retried while a
+// previous instance closes, then replaced rather than stopping the app.
+// This is a standalone copy of goup-util's pkg/localserver, so the example
+// builds without depending on the goup-util module.
+
+import (
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listenLocal listens on 127.0.0.1 at port, or at the port remembered
+// for appName when port is 0, or at a free port.
+func listenLocal(port int, appName string) (net.Listener, error) {
+	var portFile string
+	if dir, err := os.UserConfigDir(); err == nil {
+		portFile = filepath.Join(dir, appName, "server.port")
+	}
+	if data, err := os.ReadFile(portFile); err == nil && port == 0 {
+		port, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+
+	var ln net.Listener
+	if port > 0 && port <= 65535 {
+		var err error
+		for i := 0; i < 10; i++ {
+			if ln, err = net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port))); err == nil {
+				break
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+		if err != nil {
+			log.Printf("%v; using another port, so the page loses what it stored", err)
+		}
+	}
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			return nil, err
+		}
+	}
+	if portFile != "" {
+		os.MkdirAll(filepath.Dir(portFile), 0700)
+		os.WriteFile(portFile, []byte(strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)+"\n"), 0644)
+	}
+	return ln, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session recording for debugging, a standalone copy of goup-util's
+// pkg/session, so the example builds without depending on the goup-util
+// module. 'goup-util run --record' sets $GOUP_SESSION to a directory; the
+// app then appends every deep link and page load to a new JSON Lines file
+// there, and replays the sessions 'goup-util replay' drops into it as
+// replay.jsonl.
+type sessionEvent struct {
+	At   time.Time `json:"at"`
+	Kind string    `json:"kind"` // "url" or "navigate" here; the shell adds "bridge"
+	URL  string    `json:"url,omitempty"`
+	Data string    `json:"data,omitempty"` // Bridge message
+}
+
+type sessionRecorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// sessionMaxPause caps the pause before a replayed event.
+const sessionMaxPause = 5 * time.Second
+
+// startSession returns nil unless $GOUP_SESSION is set. Replayed events
+// go to inject, from another goroutine, until ctx is done.
+func startSession(ctx context.Context, inject func(sessionEvent)) *sessionRecorder {
+	dir := os.Getenv("GOUP_SESSION")
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Session recording disabled: %v", err)
+		return nil
+	}
+	path := filepath.Join(dir, time.Now().Format("20060102-150405")+".jsonl")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		log.Printf("Session recording disabled: %v", err)
+		return nil
+	}
+	log.Printf("Recording session to %s", path)
+	go replaySessions(ctx, filepath.Join(dir, "replay.jsonl"), inject)
+	return &sessionRecorder{enc: json.NewEncoder(f)}
+}
+
+// record appends an event; nil records nothing.
+func (s *sessionRecorder) record(kind, url, data string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enc.Encode(sessionEvent{At: time.Now(), Kind: kind, URL: url, Data: data})
+}
+
+// replaySessions takes each session dropped at path and injects its
+// events with the recorded pauses between them.
+func replaySessions(ctx context.Context, path string, inject func(sessionEvent)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(500 * time.Millisecond):
+		}
+		events, err := loadSession(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		os.Remove(path)
+		if err != nil {
+			log.Printf("Session replay failed: %v", err)
+			continue
+		}
+		log.Printf("Replaying %d session events", len(events))
+		for i, e := range events {
+			if i > 0 {
+				pause := min(max(e.At.Sub(events[i-1].At), 0), sessionMaxPause)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(pause):
+				}
+			}
+			inject(e)
+		}
+	}
+}
+
+func loadSession(path string) ([]sessionEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var events []sessionEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			var e sessionEvent
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				return nil, err
+			}
+			events = append(events, e)
+		}
+	}
+	return events, scanner.Err()
+}
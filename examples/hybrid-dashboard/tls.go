@@ -0,0 +1,90 @@
+package main
+
+// HTTPS for the embedded server, for web APIs and cookies that insist on
+// a secure context. With "server": {"tls": true} in app.json the server
+// gets a self-signed certificate for localhost and 127.0.0.1, kept in the
+// app's config directory, and the webview is told to trust it. This is a
+// standalone copy of goup-util's pkg/localtls, so the example builds
+// without depending on the goup-util module.
+//
+// WKWebView (macOS, iOS) cannot be told to trust a certificate, but it
+// already treats http://127.0.0.1 as a secure context, so the server stays
+// on HTTP there.
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// localTLSSupported reports whether this platform's webview can trust
+// localCertificate.
+func localTLSSupported() bool {
+	return runtime.GOOS != "darwin" && runtime.GOOS != "ios"
+}
+
+// localCertificate returns the app's localhost certificate, creating it
+// on first use and replacing it 30 days before it expires.
+func localCertificate(appName string) (tls.Certificate, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	dir = filepath.Join(dir, appName)
+	certPath, keyPath := filepath.Join(dir, "localhost.pem"), filepath.Join(dir, "localhost-key.pem")
+	if cert, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil &&
+		time.Until(cert.Leaf.NotAfter) > 30*24*time.Hour {
+		return cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(397 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
@@ -105,6 +105,59 @@ async function checkDeepLink() {
     }
 }
 
+// Local data: notes stored in SQLite by the Go backend
+async function loadNotes() {
+    const list = document.getElementById('notes');
+    try {
+        const response = await fetch('/api/data/notes');
+        if (!response.ok) throw new Error(await response.text());
+        const notes = await response.json();
+        list.innerHTML = '';
+        notes.forEach(note => {
+            const item = document.createElement('li');
+            const done = document.createElement('input');
+            done.type = 'checkbox';
+            done.checked = note.done === 1;
+            done.addEventListener('change', () => saveNote(note.id, { done: done.checked ? 1 : 0 }));
+            const text = document.createElement('span');
+            text.textContent = note.text;
+            const remove = document.createElement('button');
+            remove.textContent = '✕';
+            remove.addEventListener('click', () => deleteNote(note.id));
+            item.append(done, text, remove);
+            list.appendChild(item);
+        });
+    } catch (error) {
+        list.innerHTML = '<li>Local data unavailable: ' + error.message + '</li>';
+    }
+}
+
+async function addNote(event) {
+    event.preventDefault();
+    const input = document.getElementById('noteText');
+    await fetch('/api/data/notes', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ text: input.value })
+    });
+    input.value = '';
+    loadNotes();
+}
+
+async function saveNote(id, fields) {
+    await fetch('/api/data/notes/' + id, {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(fields)
+    });
+    loadNotes();
+}
+
+async function deleteNote(id) {
+    await fetch('/api/data/notes/' + id, { method: 'DELETE' });
+    loadNotes();
+}
+
 // Initialize
 document.addEventListener('DOMContentLoaded', function() {
     console.log('Hybrid Dashboard initialized');
@@ -125,6 +178,8 @@ document.addEventListener('DOMContentLoaded', function() {
     // Set up button handlers
     document.getElementById('callGoBtn').addEventListener('click', callGoFunction);
     document.getElementById('checkDeepLinkBtn').addEventListener('click', checkDeepLink);
+    document.getElementById('noteForm').addEventListener('submit', addNote);
+    loadNotes();
 
     // Handle hash routing (for deep link navigation)
     window.addEventListener('hashchange', handleHashRoute);
@@ -151,6 +151,39 @@ func (c *Client) ForceStop(pkg string) error {
 	return err
 }
 
+// OpenURL sends a VIEW intent for url, as tapping a deep link would.
+func (c *Client) OpenURL(url string) error {
+	_, err := c.run("shell", "am", "start", "-W", "-a", "android.intent.action.VIEW", "-d", url)
+	return err
+}
+
+// Tap sends a tap at screen coordinates x, y in pixels.
+func (c *Client) Tap(x, y int) error {
+	_, err := c.run("shell", "input", "tap", fmt.Sprint(x), fmt.Sprint(y))
+	return err
+}
+
+// InputText types text into the focused field.
+func (c *Client) InputText(text string) error {
+	// input text treats spaces as argument separators; %s is its escape
+	_, err := c.run("shell", "input", "text", strings.ReplaceAll(text, " ", "%s"))
+	return err
+}
+
+// DumpUI returns the accessibility hierarchy of the current screen as
+// uiautomator XML.
+func (c *Client) DumpUI() (string, error) {
+	out, err := c.run("exec-out", "uiautomator", "dump", "/dev/tty")
+	if err != nil {
+		return "", err
+	}
+	// The XML is followed by "UI hierchary dumped to: /dev/tty"
+	if i := strings.LastIndex(out, ">"); i >= 0 {
+		out = out[:i+1]
+	}
+	return out, nil
+}
+
 // Screenshot captures the device screen and saves it to a local file.
 func (c *Client) Screenshot(outputPath string) error {
 	cmd := exec.Command(c.ADBPath(), "exec-out", "screencap", "-p")
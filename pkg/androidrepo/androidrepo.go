@@ -0,0 +1,329 @@
+// Package androidrepo reads Google's Android SDK repository manifests
+// (repository2-3.xml and the sys-img2-3.xml system image sites), so SDK
+// packages such as build-tools;34.0.0 or platforms;android-34 can be
+// installed without the Java-based sdkmanager.
+//
+// Packages are installed where sdkmanager puts them, <sdk_root>/<path with
+// ; replaced by />, and accepted licenses are recorded in
+// <sdk_root>/licenses the way sdkmanager records them, so Gradle and
+// sdkmanager accept the result.
+package androidrepo
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BaseURL is where Google publishes the SDK repository.
+const BaseURL = "https://dl.google.com/android/repository/"
+
+// ErrNotFound is returned for packages that are not in the repository, or
+// have no archive for this host.
+var ErrNotFound = errors.New("package not found in the Android repository")
+
+// Revision is a package revision, e.g. 34.0.0.
+type Revision struct {
+	Major   int `xml:"major"`
+	Minor   int `xml:"minor"`
+	Micro   int `xml:"micro"`
+	Preview int `xml:"preview"`
+}
+
+func (r Revision) String() string {
+	s := fmt.Sprintf("%d.%d.%d", r.Major, r.Minor, r.Micro)
+	if r.Preview > 0 {
+		s += fmt.Sprintf(" rc%d", r.Preview)
+	}
+	return s
+}
+
+// Archive is one downloadable zip of a package.
+type Archive struct {
+	HostOS   string `xml:"host-os"`
+	HostArch string `xml:"host-arch"`
+	Size     int64  `xml:"complete>size"`
+	Checksum string `xml:"complete>checksum"` // SHA-1
+	URL      string `xml:"complete>url"`
+}
+
+// Dependency is another package a package needs.
+type Dependency struct {
+	Path        string    `xml:"path,attr"`
+	MinRevision *Revision `xml:"min-revision"`
+}
+
+// Package is a remote package.
+type Package struct {
+	Path         string       `xml:"path,attr"`
+	Obsolete     bool         `xml:"obsolete,attr"`
+	DisplayName  string       `xml:"display-name"`
+	Revision     Revision     `xml:"revision"`
+	License      LicenseRef   `xml:"uses-license"`
+	Channel      LicenseRef   `xml:"channelRef"`
+	Dependencies []Dependency `xml:"dependencies>dependency"`
+	Archives     []Archive    `xml:"archives>archive"`
+
+	// base is the manifest URL that archive URLs are relative to.
+	base string
+}
+
+// LicenseRef refers to a license or channel by id.
+type LicenseRef struct {
+	Ref string `xml:"ref,attr"`
+}
+
+// License is a license text packages refer to.
+type License struct {
+	ID   string `xml:"id,attr"`
+	Text string `xml:",chardata"`
+}
+
+// Repository is a parsed manifest.
+type Repository struct {
+	Licenses []License `xml:"license"`
+	Packages []Package `xml:"remotePackage"`
+}
+
+// Parse reads a manifest downloaded from base.
+func Parse(r io.Reader, base string) (*Repository, error) {
+	var repo Repository
+	if err := xml.NewDecoder(r).Decode(&repo); err != nil {
+		return nil, fmt.Errorf("failed to parse repository manifest: %w", err)
+	}
+	for i := range repo.Packages {
+		repo.Packages[i].base = base
+	}
+	return &repo, nil
+}
+
+// Find returns the package at path, preferring the stable channel when
+// several channels carry it.
+func (r *Repository) Find(path string) (*Package, bool) {
+	var found *Package
+	for i := range r.Packages {
+		p := &r.Packages[i]
+		if p.Path != path {
+			continue
+		}
+		if found == nil || p.Channel.Ref == "channel-0" && found.Channel.Ref != "channel-0" {
+			found = p
+		}
+	}
+	return found, found != nil
+}
+
+// LicenseText returns the text of the license with the given id.
+func (r *Repository) LicenseText(id string) string {
+	for _, l := range r.Licenses {
+		if l.ID == id {
+			return l.Text
+		}
+	}
+	return ""
+}
+
+// ManifestURL returns the manifest that lists path. System images are
+// published per tag, e.g. system-images;android-34;google_apis;arm64-v8a
+// is in sys-img/google_apis/sys-img2-3.xml; "default" images are in
+// sys-img/android.
+func ManifestURL(path string) string {
+	parts := strings.Split(path, ";")
+	if parts[0] == "system-images" && len(parts) == 4 {
+		tag := parts[2]
+		if tag == "default" {
+			tag = "android"
+		}
+		return BaseURL + "sys-img/" + tag + "/sys-img2-3.xml"
+	}
+	return BaseURL + "repository2-3.xml"
+}
+
+var hostOS = map[string]string{"darwin": "macosx", "linux": "linux", "windows": "windows"}
+var hostArch = map[string]string{"amd64": "x64", "arm64": "aarch64", "386": "x86"}
+
+// ArchiveFor returns the archive for goos/goarch. Archives without a
+// host-os are for every host. Apple silicon falls back to x64 archives,
+// which run under Rosetta.
+func (p *Package) ArchiveFor(goos, goarch string) (*Archive, error) {
+	archOK := func(a Archive, arch string) bool {
+		return a.HostArch == "" || a.HostArch == arch
+	}
+	for _, arch := range []string{hostArch[goarch], "x64"} {
+		for i, a := range p.Archives {
+			if (a.HostOS == "" || a.HostOS == hostOS[goos]) && archOK(a, arch) {
+				return &p.Archives[i], nil
+			}
+		}
+		if goos != "darwin" || goarch != "arm64" {
+			break
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has no archive for %s/%s", ErrNotFound, p.Path, goos, goarch)
+}
+
+// ArchiveURL resolves the archive's URL against the manifest it came from.
+func (p *Package) ArchiveURL(a *Archive) (string, error) {
+	base, err := url.Parse(p.base)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(a.URL)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// Dir is where sdkmanager installs the package under root.
+func (p *Package) Dir(root string) string {
+	return filepath.Join(root, filepath.FromSlash(strings.ReplaceAll(p.Path, ";", "/")))
+}
+
+// Client fetches manifests, keeping them in a cache directory for a day.
+type Client struct {
+	CacheDir string
+	HTTP     *http.Client
+
+	repos map[string]*Repository
+}
+
+// NewClient returns a client that caches manifests in cacheDir.
+func NewClient(cacheDir string) *Client {
+	return &Client{CacheDir: cacheDir, HTTP: &http.Client{Timeout: 2 * time.Minute}}
+}
+
+// Repository returns the manifest at manifestURL.
+func (c *Client) Repository(manifestURL string) (*Repository, error) {
+	if repo, ok := c.repos[manifestURL]; ok {
+		return repo, nil
+	}
+	data, err := c.fetch(manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := Parse(bytes.NewReader(data), manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	if c.repos == nil {
+		c.repos = map[string]*Repository{}
+	}
+	c.repos[manifestURL] = repo
+	return repo, nil
+}
+
+func (c *Client) fetch(manifestURL string) ([]byte, error) {
+	u, err := url.Parse(manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	cached := filepath.Join(c.CacheDir, filepath.FromSlash(strings.TrimPrefix(u.Path, "/")))
+	if info, err := os.Stat(cached); err == nil && time.Since(info.ModTime()) < 24*time.Hour {
+		return os.ReadFile(cached)
+	}
+
+	resp, err := c.HTTP.Get(manifestURL)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: no manifest at %s", ErrNotFound, manifestURL)
+		}
+		err = fmt.Errorf("%s: %s", manifestURL, resp.Status)
+	}
+	if err != nil {
+		// Work offline with a stale copy
+		if data, readErr := os.ReadFile(cached); readErr == nil {
+			return data, nil
+		}
+		return nil, fmt.Errorf("failed to fetch Android repository manifest: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Android repository manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err == nil {
+		os.WriteFile(cached, data, 0644)
+	}
+	return data, nil
+}
+
+// Find returns the package at path from the manifest that lists it.
+func (c *Client) Find(path string) (*Package, *Repository, error) {
+	repo, err := c.Repository(ManifestURL(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	p, ok := repo.Find(path)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	return p, repo, nil
+}
+
+// Resolve returns path and the packages it depends on, dependencies first,
+// leaving out those installed reports as already present.
+func Resolve(path string, find func(path string) (*Package, error), installed func(p *Package) bool) ([]*Package, error) {
+	var order []*Package
+	visiting := map[string]bool{}
+	var visit func(path string) error
+	visit = func(path string) error {
+		if visiting[path] {
+			return nil
+		}
+		visiting[path] = true
+		p, err := find(path)
+		if err != nil {
+			return err
+		}
+		if installed(p) {
+			return nil
+		}
+		for _, dep := range p.Dependencies {
+			if err := visit(dep.Path); err != nil {
+				return fmt.Errorf("%s needs %s: %w", path, dep.Path, err)
+			}
+		}
+		order = append(order, p)
+		return nil
+	}
+	if err := visit(path); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// LicenseHash is how sdkmanager identifies an accepted license text.
+func LicenseHash(text string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// AcceptLicense records license id as accepted under root, as
+// 'sdkmanager --licenses' does.
+func AcceptLicense(root, id, text string) error {
+	path := filepath.Join(root, "licenses", id)
+	hash := LicenseHash(text)
+	data, _ := os.ReadFile(path)
+	lines := strings.Fields(string(data))
+	if slices.Contains(lines, hash) {
+		return nil
+	}
+	lines = append(lines, hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte("\n"+strings.Join(lines, "\n")), 0644)
+}
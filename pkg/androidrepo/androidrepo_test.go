@@ -0,0 +1,174 @@
+package androidrepo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const manifest = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sdk:sdk-repository xmlns:sdk="http://schemas.android.com/sdk/android/repo/repository2/03" xmlns:common="http://schemas.android.com/repository/android/common/02">
+  <license id="android-sdk-license" type="text">Terms and Conditions
+  </license>
+  <channel id="channel-0">stable</channel>
+  <channel id="channel-1">beta</channel>
+  <remotePackage path="build-tools;34.0.0">
+    <revision><major>34</major><minor>0</minor><micro>0</micro></revision>
+    <display-name>Android SDK Build-Tools 34</display-name>
+    <uses-license ref="android-sdk-license"/>
+    <dependencies>
+      <dependency path="tools"><min-revision><major>26</major></min-revision></dependency>
+    </dependencies>
+    <channelRef ref="channel-0"/>
+    <archives>
+      <archive>
+        <complete><size>100</size><checksum type="sha1">aaaa</checksum><url>build-tools_r34-linux.zip</url></complete>
+        <host-os>linux</host-os>
+      </archive>
+      <archive>
+        <complete><size>100</size><checksum type="sha1">bbbb</checksum><url>build-tools_r34-macosx.zip</url></complete>
+        <host-os>macosx</host-os>
+        <host-arch>x64</host-arch>
+      </archive>
+    </archives>
+  </remotePackage>
+  <remotePackage path="tools" obsolete="true">
+    <revision><major>26</major><minor>1</minor><micro>1</micro></revision>
+    <uses-license ref="android-sdk-license"/>
+    <dependencies><dependency path="patcher;v4"/></dependencies>
+    <channelRef ref="channel-0"/>
+    <archives><archive><complete><size>1</size><checksum>cccc</checksum><url>https://mirror.example.com/tools.zip</url></complete></archive></archives>
+  </remotePackage>
+  <remotePackage path="patcher;v4">
+    <revision><major>1</major></revision>
+    <channelRef ref="channel-0"/>
+    <archives><archive><complete><size>1</size><checksum>dddd</checksum><url>patcher.zip</url></complete></archive></archives>
+  </remotePackage>
+  <remotePackage path="platform-tools">
+    <revision><major>35</major><preview>1</preview></revision>
+    <channelRef ref="channel-1"/>
+  </remotePackage>
+  <remotePackage path="platform-tools">
+    <revision><major>34</major></revision>
+    <channelRef ref="channel-0"/>
+  </remotePackage>
+</sdk:sdk-repository>`
+
+func parse(t *testing.T) *Repository {
+	t.Helper()
+	repo, err := Parse(strings.NewReader(manifest), BaseURL+"repository2-3.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestParse(t *testing.T) {
+	repo := parse(t)
+	p, ok := repo.Find("build-tools;34.0.0")
+	if !ok {
+		t.Fatal("build-tools not found")
+	}
+	if p.Revision.String() != "34.0.0" || p.License.Ref != "android-sdk-license" || len(p.Dependencies) != 1 || p.Dependencies[0].MinRevision.Major != 26 {
+		t.Errorf("package = %+v", p)
+	}
+	if tools, _ := repo.Find("tools"); !tools.Obsolete || tools.Archives[0].Checksum != "cccc" {
+		t.Errorf("tools = %+v", tools)
+	}
+	if got := repo.LicenseText("android-sdk-license"); !strings.HasPrefix(got, "Terms and Conditions") {
+		t.Errorf("license = %q", got)
+	}
+	// The stable channel wins over the beta listed first
+	if pt, _ := repo.Find("platform-tools"); pt.Revision.String() != "34.0.0" {
+		t.Errorf("platform-tools = %s", pt.Revision)
+	}
+}
+
+func TestArchiveFor(t *testing.T) {
+	p, _ := parse(t).Find("build-tools;34.0.0")
+	for _, tc := range []struct{ goos, goarch, want string }{
+		{"linux", "amd64", "aaaa"},
+		{"darwin", "amd64", "bbbb"},
+		{"darwin", "arm64", "bbbb"}, // x64 under Rosetta
+		{"windows", "amd64", ""},
+	} {
+		a, err := p.ArchiveFor(tc.goos, tc.goarch)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("%s/%s: got an archive", tc.goos, tc.goarch)
+			}
+			continue
+		}
+		if err != nil || a.Checksum != tc.want {
+			t.Errorf("%s/%s = %+v, %v", tc.goos, tc.goarch, a, err)
+		}
+	}
+
+	a, _ := p.ArchiveFor("linux", "amd64")
+	if url, _ := p.ArchiveURL(a); url != BaseURL+"build-tools_r34-linux.zip" {
+		t.Errorf("relative URL = %s", url)
+	}
+	tools, _ := parse(t).Find("tools")
+	if url, _ := tools.ArchiveURL(&tools.Archives[0]); url != "https://mirror.example.com/tools.zip" {
+		t.Errorf("absolute URL = %s", url)
+	}
+}
+
+func TestManifestURL(t *testing.T) {
+	for path, want := range map[string]string{
+		"platforms;android-34":                           BaseURL + "repository2-3.xml",
+		"system-images;android-31;default;x86_64":        BaseURL + "sys-img/android/sys-img2-3.xml",
+		"system-images;android-34;google_apis;arm64-v8a": BaseURL + "sys-img/google_apis/sys-img2-3.xml",
+	} {
+		if got := ManifestURL(path); got != want {
+			t.Errorf("ManifestURL(%s) = %s", path, got)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	repo := parse(t)
+	find := func(path string) (*Package, error) {
+		if p, ok := repo.Find(path); ok {
+			return p, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+
+	order, err := Resolve("build-tools;34.0.0", find, func(*Package) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, p := range order {
+		paths = append(paths, p.Path)
+	}
+	if got := strings.Join(paths, " "); got != "patcher;v4 tools build-tools;34.0.0" {
+		t.Errorf("order = %s", got)
+	}
+
+	order, _ = Resolve("build-tools;34.0.0", find, func(p *Package) bool { return p.Path == "tools" })
+	if len(order) != 1 {
+		t.Errorf("installed dependency was reinstalled: %d packages", len(order))
+	}
+
+	repo.Packages = repo.Packages[:1]
+	if _, err := Resolve("build-tools;34.0.0", find, func(*Package) bool { return false }); err == nil || !strings.Contains(err.Error(), "needs tools") {
+		t.Errorf("missing dependency: %v", err)
+	}
+}
+
+func TestAcceptLicense(t *testing.T) {
+	root := t.TempDir()
+	for range 2 {
+		if err := AcceptLicense(root, "android-sdk-license", "Terms\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "licenses", "android-sdk-license"))
+	if got := strings.Fields(string(data)); len(got) != 1 || got[0] != LicenseHash("Terms") {
+		t.Errorf("licenses file = %q", data)
+	}
+}
@@ -16,15 +16,309 @@ type AppConfig struct {
 	Name   string       `json:"name,omitempty"`   // Window title
 	Width  int          `json:"width,omitempty"`  // Window width in dp
 	Height int          `json:"height,omitempty"` // Window height in dp
+	Locale string       `json:"locale,omitempty"` // UI language (e.g. "de"), detected from the OS if empty
 	Update UpdateConfig `json:"update,omitempty"` // Self-update from GitHub releases
+	Media  MediaConfig  `json:"media,omitempty"`  // Sound and autoplay policy
+	Filter FilterConfig `json:"filter,omitempty"` // Blocked/allowed hosts
+	Theme  ThemeConfig  `json:"theme,omitempty"`  // Toolbar and tab colors
+
+	Display        DisplayConfig `json:"display,omitempty"`        // Monitor, fullscreen and DPI override
+	RememberWindow *bool         `json:"rememberWindow,omitempty"` // Reopen where the window was left (default true)
+	StartAtLogin   bool          `json:"startAtLogin,omitempty"`   // Start when the user logs in (a startup task under MSIX)
+
+	Hotkeys []HotkeyConfig `json:"hotkeys,omitempty"` // System-wide shortcuts
+	Bridge  BridgeConfig   `json:"bridge,omitempty"`  // Native clipboard and file drops for the app's pages
+
+	// Permissions maps a permission ("camera", "microphone", "screen-recording",
+	// "local-network") to the reason shown to users on first run and in the
+	// macOS Info.plist usage descriptions.
+	Permissions map[string]string `json:"permissions,omitempty"`
+
+	// ExportCompliance answers Apple's encryption export questions up front
+	// so App Store Connect does not hold TestFlight builds for them.
+	ExportCompliance *ExportCompliance `json:"exportCompliance,omitempty"`
+
+	Flags FlagsConfig `json:"flags,omitempty"` // Remote feature flags
+	Fleet FleetConfig `json:"fleet,omitempty"` // Check in with a fleet registry
+
+	Healthcheck HealthcheckConfig `json:"healthcheck,omitempty"` // Uptime monitoring pings
+	Watchdog    WatchdogConfig    `json:"watchdog,omitempty"`    // Scheduled restart and memory limit
+	NetworkLog  NetworkLogConfig  `json:"networkLog,omitempty"`  // The pages' requests, saved as network.har
+
+	Reload        ReloadConfig        `json:"reload,omitempty"`        // Apply app.json changes while running
+	Accessibility AccessibilityConfig `json:"accessibility,omitempty"` // Text size, contrast, page checks, on-screen keyboard
+	Touch         TouchConfig         `json:"touch,omitempty"`         // Back/home buttons, swipes, return home when idle
+	Idle          IdleConfig          `json:"idle,omitempty"`          // Attract page and session reset between visitors
+	Profiles      ProfilesConfig      `json:"profiles,omitempty"`      // People sharing the device, each with their own sessions
+	Admin         AdminConfig         `json:"admin,omitempty"`         // PIN-locked settings for technicians
+
+	Proxy   ProxyConfig            `json:"proxy,omitempty"`   // Corporate proxy for the webview
+	Proxies map[string]ProxyConfig `json:"proxies,omitempty"` // Named alternatives selected with --proxy-profile
+	TLS     TLSConfig              `json:"tls,omitempty"`     // Extra CAs and certificate pins
+	CSP     CSPConfig              `json:"csp,omitempty"`     // Content Security Policy added to the site's pages
+
+	Server ServerConfig `json:"server,omitempty"` // Embedded local server of hybrid apps
+
+	// DevProxy is the frontend dev server (e.g. "http://localhost:5173")
+	// 'goup-util run' points a hybrid app's embedded server at, so edits
+	// hot reload in the app window. Ignored by built releases.
+	DevProxy string `json:"devProxy,omitempty"`
+
+	// Environment is the overlay the app was built with (e.g. "staging");
+	// set by 'goup-util build --env', shown in the app's version info.
+	Environment string `json:"environment,omitempty"`
+}
+
+// ServerConfig configures the embedded HTTP server of hybrid apps.
+type ServerConfig struct {
+	// TLS serves HTTPS with a self-signed localhost certificate the app's
+	// webview trusts (Windows, Android; see pkg/localtls). macOS and iOS
+	// stay on HTTP, which their webview already treats as secure.
+	TLS bool `json:"tls,omitempty"`
+
+	// Port is a fixed loopback port, for pages that need the same origin
+	// (and so the same localStorage) on every launch. 0 reuses the port
+	// of the last launch where it is free; see pkg/localserver.
+	Port int `json:"port,omitempty"`
+}
+
+// ExportCompliance maps to the ITSAppUsesNonExemptEncryption and
+// ITSEncryptionExportComplianceCode Info.plist keys.
+// HTTPS through the OS (webviews, net/http) is exempt, so most apps set
+// UsesNonExemptEncryption to false.
+type ExportCompliance struct {
+	UsesNonExemptEncryption bool   `json:"usesNonExemptEncryption"`
+	ComplianceCode          string `json:"complianceCode,omitempty"` // Code issued by Apple after review of export documentation
+}
+
+// Theme modes accepted in ThemeConfig.Mode.
+const (
+	ThemeAuto  = "auto" // Follow the OS appearance (default)
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+)
+
+// ThemeConfig selects the shell chrome colors.
+// Colors overrides individual palette entries with "#rrggbb" values; keys are
+// toolbar, address, text, selection, tabActive, tab, button and buttonText.
+type ThemeConfig struct {
+	Mode   string            `json:"mode,omitempty"`
+	Colors map[string]string `json:"colors,omitempty"`
+}
+
+// FilterConfig restricts which hosts the shell may navigate to.
+// Rules are domain patterns ("example.com", "*.example.com") or
+// EasyList-style host rules ("||example.com^").
+type FilterConfig struct {
+	Block []string `json:"block,omitempty"` // Hosts that may never be loaded
+	Allow []string `json:"allow,omitempty"` // If set, only these hosts may be loaded
+	Lists []string `json:"lists,omitempty"` // Rule files next to the app, one rule per line
+}
+
+// ProxyConfig routes webview traffic through a proxy.
+// PAC and Bypass are only honoured by WebView2 (Windows).
+type ProxyConfig struct {
+	URL    string   `json:"url,omitempty"`    // Fixed proxy (e.g. "http://proxy.corp:8080")
+	PAC    string   `json:"pac,omitempty"`    // Proxy auto-config script URL
+	Bypass []string `json:"bypass,omitempty"` // Hosts that skip the proxy (e.g. "*.corp.local")
+}
+
+// TLSConfig hardens the shell's connections to its site. CA lists PEM
+// files of CAs the webview trusts besides the system's, such as a
+// corporate TLS inspection CA (Windows and Android; elsewhere the CA goes
+// in the system's store). Pins are "sha256/<base64>" hashes of public
+// keys, one of which the certificate chains of Hosts (default: the URL's
+// host) must hold; the shell checks them over its own connection at
+// startup and every 10 minutes, and shows a host only while its last
+// check passed. This is best-effort detection: the webview's own
+// connections are not pinned.
+type TLSConfig struct {
+	CA    []string `json:"ca,omitempty"`
+	Pins  []string `json:"pins,omitempty"`
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// CSPConfig makes the shell add a Content Security Policy to pages on the
+// app URL's origin and Origins, as a <meta> tag, for sites that send none
+// (see 'goup-util audit security'). Log records the violations of any
+// policy to csp.log in the app's config directory.
+type CSPConfig struct {
+	Policy  string   `json:"policy,omitempty"` // e.g. "default-src 'self'; img-src 'self' data:"
+	Origins []string `json:"origins,omitempty"`
+	Log     bool     `json:"log,omitempty"`
+}
+
+// FlagsConfig points the shell at a remote feature flag document (see
+// pkg/remoteconfig). Pages read the flags from window.goupFlags.
+type FlagsConfig struct {
+	URL            string         `json:"url,omitempty"`            // JSON object with the flags
+	RefreshMinutes int            `json:"refreshMinutes,omitempty"` // Poll interval (default 15)
+	Defaults       map[string]any `json:"defaults,omitempty"`       // Values until the first fetch succeeds
+}
+
+// FleetConfig makes the shell check in with a fleet registry (see
+// pkg/fleet), so operators can list installs with 'goup-util fleet list'
+// and send them signed commands with 'goup-util fleet send'.
+type FleetConfig struct {
+	URL             string `json:"url,omitempty"`             // Server run with 'update-server serve --fleet'
+	Token           string `json:"token,omitempty"`           // Enrollment token
+	IntervalMinutes int    `json:"intervalMinutes,omitempty"` // Check-in interval (default 5)
+	CommandKey      string `json:"commandKey,omitempty"`      // Public key from 'goup-util fleet keygen'; commands are refused without it
+}
+
+// HealthcheckConfig makes the shell ping a monitoring URL on a schedule
+// (see pkg/healthcheck); the shell's --healthcheck-url flag overrides URL.
+type HealthcheckConfig struct {
+	URL             string `json:"url,omitempty"`             // e.g. "https://hc-ping.com/<uuid>"
+	IntervalMinutes int    `json:"intervalMinutes,omitempty"` // Ping interval (default 5)
+}
+
+// WatchdogConfig keeps a long-running shell healthy with a daily restart
+// and a memory limit. Actions are logged to watchdog.log in the app's
+// config directory.
+type WatchdogConfig struct {
+	RestartAt    string `json:"restartAt,omitempty"`    // Local time of a daily restart ("03:30")
+	MaxMemoryMB  int    `json:"maxMemoryMB,omitempty"`  // Limit for the shell and its webview processes
+	MemoryAction string `json:"memoryAction,omitempty"` // "reload" (default) or "restart"
+	CheckMinutes int    `json:"checkMinutes,omitempty"` // Memory check interval (default 5)
+}
+
+// NetworkLogConfig makes the shell record the requests its pages make
+// to network.har (HAR 1.2) in the app's config directory, for support on
+// machines where proxy tools cannot be installed. The shell's
+// --network-log flag sets Enabled.
+type NetworkLogConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	Entries int  `json:"entries,omitempty"` // Most recent requests kept (default 1000)
+}
+
+// DisplayConfig places the shell window on kiosks and multi-monitor
+// setups. Display numbers match 'goup-util displays'.
+type DisplayConfig struct {
+	Index      int     `json:"index,omitempty"`      // Display to open on (0 is the first, usually the primary)
+	Fullscreen bool    `json:"fullscreen,omitempty"` // Fill that display
+	Scale      float32 `json:"scale,omitempty"`      // Pixels per dp, overriding the system DPI
+}
+
+// HotkeyConfig binds a system-wide key combination such as
+// "Ctrl+Alt+Space" to a shell action: "toggle", "show" or "hide" the
+// window, "reload" the page, or "event", which fires a "goup-hotkey" DOM
+// event carrying Event in the page.
+type HotkeyConfig struct {
+	Keys   string `json:"keys"`
+	Action string `json:"action"`
+	Event  string `json:"event,omitempty"`
+}
+
+// BridgeConfig exposes native features to pages on the app's origin
+// through window.goup: the clipboard, files dropped onto the window,
+// delivered as "goup-drop" events with blob URLs, the system's open and
+// save dialogs, whose files the shell streams, QR and barcode scanning
+// with the camera, confirming the user with biometrics, keeping tokens in
+// the system credential store (see pkg/securestore) and showing
+// notifications (see pkg/localnotify).
+type BridgeConfig struct {
+	Clipboard     bool     `json:"clipboard,omitempty"`
+	Files         bool     `json:"files,omitempty"`
+	Dialogs       bool     `json:"dialogs,omitempty"`
+	Scan          bool     `json:"scan,omitempty"`          // Needs the camera; see ScanCameraReason
+	Biometrics    bool     `json:"biometrics,omitempty"`    // Touch ID, Face ID or Windows Hello; see BiometricsReason
+	SecureStore   bool     `json:"secureStore,omitempty"`   // Keychain, DPAPI or the Secret Service
+	Notifications bool     `json:"notifications,omitempty"` // See NotificationsReason
+	Profiles      bool     `json:"profiles,omitempty"`      // List and switch the profiles of ProfilesConfig
+	Origins       []string `json:"origins,omitempty"`       // Trusted origins besides the app URL's
 }
 
 // UpdateConfig tells the app where to find updates on GitHub.
 type UpdateConfig struct {
-	Repo  string `json:"repo"`  // GitHub owner/repo (e.g. "joeblew999/goup-util")
-	Asset string `json:"asset"` // Asset name prefix (e.g. "webviewer-shell")
+	Repo  string `json:"repo"`          // GitHub owner/repo (e.g. "joeblew999/goup-util")
+	Asset string `json:"asset"`         // Asset name prefix (e.g. "webviewer-shell")
+	URL   string `json:"url,omitempty"` // Self-hosted update server, used instead of GitHub
+
+	// Rollback reverts to the previous binary when an update keeps crashing.
+	Rollback RollbackConfig `json:"rollback,omitempty"`
+}
+
+// RollbackConfig sets what counts as a crash loop after an update.
+type RollbackConfig struct {
+	Crashes int `json:"crashes,omitempty"` // Crashes that trigger a rollback (default 3)
+	Minutes int `json:"minutes,omitempty"` // Minutes after the update that crashes count (default 10)
+}
+
+// ReloadConfig lets operators reconfigure a running shell. Watch applies
+// edits to app.json within seconds; URL is an https document in app.json's
+// format whose live sections (url, name, environment, filter, theme, media,
+// display) override the file's, fetched every RefreshMinutes (default 5).
+type ReloadConfig struct {
+	Watch          bool   `json:"watch,omitempty"`
+	URL            string `json:"url,omitempty"`
+	RefreshMinutes int    `json:"refreshMinutes,omitempty"`
+}
+
+// AccessibilityConfig adapts the shell and its pages for public kiosks.
+type AccessibilityConfig struct {
+	MinFontSize  int  `json:"minFontSize,omitempty"`  // Smallest text in the pages, in CSS pixels; the chrome grows to match
+	HighContrast bool `json:"highContrast,omitempty"` // High-contrast toolbar and tabs, instead of the theme
+	Audit        bool `json:"audit,omitempty"`        // Check pages for screen reader problems, logged to accessibility.log
+	Keyboard     bool `json:"keyboard,omitempty"`     // Toolbar button that shows and hides the on-screen keyboard
+}
+
+// TouchConfig sets up navigation for tablet kiosks, which have no browser
+// buttons, keyboard shortcuts or mouse.
+type TouchConfig struct {
+	Back        bool `json:"back,omitempty"`        // Back button in the toolbar
+	Home        bool `json:"home,omitempty"`        // Home button in the toolbar, to URL
+	Swipe       bool `json:"swipe,omitempty"`       // Edge swipes go back and forward
+	IdleSeconds int  `json:"idleSeconds,omitempty"` // Return to URL after this long without a touch; see IdleConfig
 }
 
+// IdleConfig is attract mode for public kiosks: once nobody has touched
+// the shell for Minutes, it closes the extra tabs, clears the session data
+// and shows the attract page. It takes the place of Touch.IdleSeconds.
+type IdleConfig struct {
+	Minutes      int    `json:"minutes,omitempty"`      // Time without input before attract mode (default: never)
+	URL          string `json:"url,omitempty"`          // Attract page (default: the app URL)
+	ClearSession *bool  `json:"clearSession,omitempty"` // Clear cookies, storage and cache first (default true)
+	LoopMinutes  int    `json:"loopMinutes,omitempty"`  // Reload the attract page this often while idle
+}
+
+// ProfilesConfig lets several people share a device without sharing their
+// web sessions. Windows keeps each profile's webview data in a folder of
+// its own; elsewhere the shared store is cleared when the profile changes.
+type ProfilesConfig struct {
+	Names []string `json:"names,omitempty"` // e.g. ["Reception", "Dr. Patel"]
+	Pick  bool     `json:"pick,omitempty"`  // Ask at launch who is using the device
+}
+
+// AdminConfig adds a toolbar button that opens PIN-locked settings, so
+// technicians can service a kiosk from its touch screen. The PIN is set
+// with the shell's --set-admin-pin flag and kept in the secure store.
+type AdminConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// Autoplay policies accepted in MediaConfig.Autoplay.
+const (
+	AutoplayAllow = "allow" // Pages may start playback freely (default)
+	AutoplayMuted = "muted" // Playback before a user gesture is forced muted
+	AutoplayBlock = "block" // Playback before a user gesture is paused
+)
+
+// MediaConfig controls sound in the shell's tabs.
+type MediaConfig struct {
+	Muted    bool   `json:"muted,omitempty"`    // Start every tab muted
+	Autoplay string `json:"autoplay,omitempty"` // "allow" (default), "muted" or "block"
+}
+
+// Permission reasons used when a bridge feature is enabled and app.json
+// does not give one, so bundles still get the usage descriptions,
+// entitlements and capabilities the feature needs.
+const (
+	ScanCameraReason    = "To scan QR codes and barcodes"
+	BiometricsReason    = "To confirm it's you"
+	NotificationsReason = "To let you know when something needs your attention"
+)
+
 // Defaults returns an AppConfig with sensible default values.
 func Defaults() *AppConfig {
 	return &AppConfig{
@@ -47,10 +341,28 @@ func Load(dir string) (*AppConfig, error) {
 	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse %s: %w", ConfigFileName, err)
 	}
+	cfg.declareBridgePermissions()
 
 	return cfg, nil
 }
 
+// declareBridgePermissions adds the permissions of enabled bridge
+// features, keeping any reason app.json gives.
+func (c *AppConfig) declareBridgePermissions() {
+	declare := func(enabled bool, name, reason string) {
+		if !enabled || c.Permissions[name] != "" {
+			return
+		}
+		if c.Permissions == nil {
+			c.Permissions = map[string]string{}
+		}
+		c.Permissions[name] = reason
+	}
+	declare(c.Bridge.Scan, "camera", ScanCameraReason)
+	declare(c.Bridge.Biometrics, "biometrics", BiometricsReason)
+	declare(c.Bridge.Notifications, "notifications", NotificationsReason)
+}
+
 // LoadOrDefault loads app.json from the given directory,
 // returning defaults if the file doesn't exist.
 func LoadOrDefault(dir string) *AppConfig {
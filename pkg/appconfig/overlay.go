@@ -0,0 +1,80 @@
+package appconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OverlayFileName is the per-environment overlay for env, e.g.
+// app.staging.json, layered over app.json at build time.
+func OverlayFileName(env string) string {
+	return "app." + env + ".json"
+}
+
+// Environments lists the overlays next to app.json in dir, sorted.
+func Environments(dir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "app.*.json"))
+	var envs []string
+	for _, m := range matches {
+		env := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), "app."), ".json")
+		if env != "" && !strings.Contains(env, ".") {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+	return envs
+}
+
+// Overlay returns app.json in dir with the overlay for env merged in and
+// "environment" set to env. Objects merge key by key; other values,
+// including arrays, replace the base value.
+func Overlay(dir, env string) (map[string]any, error) {
+	base := map[string]any{}
+	if data, err := os.ReadFile(filepath.Join(dir, ConfigFileName)); err == nil {
+		if err := json.Unmarshal(data, &base); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", ConfigFileName, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	name := OverlayFileName(env)
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		envs := Environments(dir)
+		if len(envs) == 0 {
+			return nil, fmt.Errorf("no %s next to %s", name, ConfigFileName)
+		}
+		return nil, fmt.Errorf("no %s. Environments: %s", name, strings.Join(envs, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	overlay := map[string]any{}
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	merged := Merge(base, overlay)
+	merged["environment"] = env
+	return merged, nil
+}
+
+// Merge layers overlay over base, recursing into objects present in both.
+// base is modified and returned.
+func Merge(base, overlay map[string]any) map[string]any {
+	for k, v := range overlay {
+		if o, ok := v.(map[string]any); ok {
+			if b, ok := base[k].(map[string]any); ok {
+				base[k] = Merge(b, o)
+				continue
+			}
+		}
+		base[k] = v
+	}
+	return base
+}
@@ -0,0 +1,255 @@
+// Package appstore is a small App Store Connect API client for the parts
+// goup-util publishes: app lookup, the editable version, and localized
+// listing text.
+//
+// Requests are authenticated with an API key (.p8, key ID and issuer ID)
+// as described at https://developer.apple.com/documentation/appstoreconnectapi.
+package appstore
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BaseURL is the App Store Connect API.
+const BaseURL = "https://api.appstoreconnect.apple.com"
+
+// Platforms as App Store Connect names them.
+const (
+	IOS   = "IOS"
+	MacOS = "MAC_OS"
+	TVOS  = "TV_OS"
+)
+
+// editableStates are the version states whose metadata can change.
+var editableStates = []string{"PREPARE_FOR_SUBMISSION", "DEVELOPER_REJECTED", "REJECTED", "METADATA_REJECTED", "INVALID_BINARY"}
+
+// liveStates are the app info states that are on sale and read-only.
+var liveStates = []string{"READY_FOR_SALE", "READY_FOR_DISTRIBUTION", "REPLACED_WITH_NEW_INFO"}
+
+// Client calls App Store Connect.
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+
+	keyID  string
+	issuer string
+	key    *ecdsa.PrivateKey
+
+	token   string
+	expires time.Time
+}
+
+// New returns a client for the API key in keyPEM.
+func New(keyPEM []byte, keyID, issuer string) (*Client, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("API key is not a PEM (.p8) file")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("API key is not an EC key")
+	}
+	if keyID == "" || issuer == "" {
+		return nil, fmt.Errorf("API key ID and issuer ID are required")
+	}
+	return &Client{BaseURL: BaseURL, HTTP: &http.Client{Timeout: 60 * time.Second}, keyID: keyID, issuer: issuer, key: key}, nil
+}
+
+// bearer returns a JWT, reusing it until shortly before it expires.
+// Apple accepts tokens valid for at most 20 minutes.
+func (c *Client) bearer() (string, error) {
+	now := time.Now()
+	if c.token != "" && now.Before(c.expires.Add(-time.Minute)) {
+		return c.token, nil
+	}
+	exp := now.Add(19 * time.Minute)
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": c.keyID, "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{"iss": c.issuer, "iat": now.Unix(), "exp": exp.Unix(), "aud": "appstoreconnect-v1"})
+	enc := base64.RawURLEncoding
+	signing := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signing))
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	c.token, c.expires = signing+"."+enc.EncodeToString(sig), exp
+	return c.token, nil
+}
+
+// Resource is a JSON:API resource object.
+type Resource struct {
+	Type          string         `json:"type"`
+	ID            string         `json:"id,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+	Relationships map[string]any `json:"relationships,omitempty"`
+}
+
+// Attr returns a string attribute.
+func (r Resource) Attr(name string) string {
+	s, _ := r.Attributes[name].(string)
+	return s
+}
+
+type apiError struct {
+	Errors []struct {
+		Status string `json:"status"`
+		Code   string `json:"code"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+func (c *Client) do(method, path string, in, out any) error {
+	token, err := c.bearer()
+	if err != nil {
+		return err
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("App Store Connect request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e apiError
+		if json.Unmarshal(data, &e) == nil && len(e.Errors) > 0 {
+			msgs := make([]string, len(e.Errors))
+			for i, x := range e.Errors {
+				msgs[i] = x.Title
+				if x.Detail != "" {
+					msgs[i] = x.Detail
+				}
+			}
+			return fmt.Errorf("App Store Connect %s %s: %s (%s)", method, path, strings.Join(msgs, "; "), resp.Status)
+		}
+		return fmt.Errorf("App Store Connect %s %s: %s", method, path, resp.Status)
+	}
+	if out != nil && len(data) > 0 {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+func (c *Client) list(path string) ([]Resource, error) {
+	var resp struct {
+		Data []Resource `json:"data"`
+	}
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// AppID looks up the App Store Connect app for a bundle ID.
+func (c *Client) AppID(bundleID string) (string, error) {
+	apps, err := c.list("/v1/apps?filter[bundleId]=" + url.QueryEscape(bundleID))
+	if err != nil {
+		return "", err
+	}
+	if len(apps) == 0 {
+		return "", fmt.Errorf("no app with bundle ID %s in App Store Connect (create it there first)", bundleID)
+	}
+	return apps[0].ID, nil
+}
+
+// EditableVersion returns the app's version on platform whose metadata
+// can still change, e.g. one being prepared for submission.
+func (c *Client) EditableVersion(appID, platform string) (Resource, error) {
+	versions, err := c.list(fmt.Sprintf("/v1/apps/%s/appStoreVersions?filter[platform]=%s&limit=10", appID, platform))
+	if err != nil {
+		return Resource{}, err
+	}
+	for _, v := range versions {
+		if slices.Contains(editableStates, v.Attr("appStoreState")) {
+			return v, nil
+		}
+	}
+	return Resource{}, fmt.Errorf("no editable %s version; create a new version in App Store Connect first", platform)
+}
+
+// EditableAppInfo returns the app info (name, subtitle, privacy URL) that
+// can change, or ok=false while only the live one exists.
+func (c *Client) EditableAppInfo(appID string) (info Resource, ok bool, err error) {
+	infos, err := c.list(fmt.Sprintf("/v1/apps/%s/appInfos", appID))
+	if err != nil {
+		return Resource{}, false, err
+	}
+	for _, i := range infos {
+		state := i.Attr("appStoreState")
+		if state == "" {
+			state = i.Attr("state")
+		}
+		if !slices.Contains(liveStates, state) {
+			return i, true, nil
+		}
+	}
+	return Resource{}, false, nil
+}
+
+// Localize creates or updates the localization of parent for locale.
+// kind is "appStoreVersionLocalizations" (parent an appStoreVersion) or
+// "appInfoLocalizations" (parent an appInfo). It reports whether the
+// locale was created.
+func (c *Client) Localize(kind string, parent Resource, locale string, attrs map[string]any) (created bool, err error) {
+	existing, err := c.list(fmt.Sprintf("/v1/%s/%s/%s?limit=200", parent.Type, parent.ID, kind))
+	if err != nil {
+		return false, err
+	}
+	for _, l := range existing {
+		if l.Attr("locale") == locale {
+			body := map[string]Resource{"data": {Type: kind, ID: l.ID, Attributes: attrs}}
+			return false, c.do(http.MethodPatch, fmt.Sprintf("/v1/%s/%s", kind, l.ID), body, nil)
+		}
+	}
+	create := map[string]any{"locale": locale}
+	for k, v := range attrs {
+		create[k] = v
+	}
+	parentType := parent.Type[:len(parent.Type)-1] // appStoreVersions → appStoreVersion
+	body := map[string]Resource{"data": {
+		Type:          kind,
+		Attributes:    create,
+		Relationships: map[string]any{parentType: map[string]any{"data": map[string]string{"type": parent.Type, "id": parent.ID}}},
+	}}
+	return true, c.do(http.MethodPost, "/v1/"+kind, body, nil)
+}
@@ -0,0 +1,134 @@
+package appstore
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Upload sends an .ipa (ios) or .pkg (macos) to App Store Connect with
+// Xcode's altool. altool only reads API keys from a directory, so the key
+// is written to a private temporary one for the duration of the upload.
+func Upload(path, platform string, keyPEM []byte, keyID, issuer string) error {
+	if _, err := exec.LookPath("xcrun"); err != nil {
+		return fmt.Errorf("uploading to App Store Connect needs Xcode's altool (macOS with Xcode installed)")
+	}
+	dir, err := os.MkdirTemp("", "goup-asc-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "AuthKey_"+keyID+".p8"), keyPEM, 0600); err != nil {
+		return err
+	}
+	kind := "ios"
+	if platform == "macos" {
+		kind = "macos"
+	}
+	cmd := exec.Command("xcrun", "altool", "--upload-app", "-f", path, "-t", kind, "--apiKey", keyID, "--apiIssuer", issuer)
+	cmd.Env = append(os.Environ(), "API_PRIVATE_KEYS_DIR="+dir)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("altool upload failed: %w", err)
+	}
+	return nil
+}
+
+// WaitForBuild polls until a build uploaded after since has finished
+// processing, which usually takes a few minutes.
+func (c *Client) WaitForBuild(appID string, since time.Time, timeout, interval time.Duration) (Resource, error) {
+	deadline := time.Now().Add(timeout)
+	path := fmt.Sprintf("/v1/builds?filter[app]=%s&sort=-uploadedDate&limit=1", url.QueryEscape(appID))
+	for {
+		builds, err := c.list(path)
+		if err != nil {
+			return Resource{}, err
+		}
+		if len(builds) > 0 {
+			b := builds[0]
+			uploaded, _ := time.Parse(time.RFC3339, b.Attr("uploadedDate"))
+			if !uploaded.Before(since.Add(-time.Minute)) {
+				switch state := b.Attr("processingState"); state {
+				case "VALID":
+					return b, nil
+				case "FAILED", "INVALID":
+					return Resource{}, fmt.Errorf("build %s failed processing (%s); check the email from App Store Connect", b.Attr("version"), state)
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			return Resource{}, fmt.Errorf("build did not finish processing within %s", timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// BetaGroup finds a TestFlight group by name.
+func (c *Client) BetaGroup(appID, name string) (Resource, error) {
+	groups, err := c.list(fmt.Sprintf("/v1/apps/%s/betaGroups?filter[name]=%s", appID, url.QueryEscape(name)))
+	if err != nil {
+		return Resource{}, err
+	}
+	if len(groups) == 0 {
+		return Resource{}, fmt.Errorf("no TestFlight group named %q; create it in App Store Connect", name)
+	}
+	return groups[0], nil
+}
+
+// IsExternal reports whether a beta group is for external testers.
+func IsExternal(group Resource) bool {
+	internal, _ := group.Attributes["isInternalGroup"].(bool)
+	return !internal
+}
+
+// AddToGroup gives a TestFlight group access to a build. Builds for
+// external groups also need beta app review.
+func (c *Client) AddToGroup(group, build Resource) error {
+	body := map[string][]Resource{"data": {{Type: "builds", ID: build.ID}}}
+	return c.do(http.MethodPost, fmt.Sprintf("/v1/betaGroups/%s/relationships/builds", group.ID), body, nil)
+}
+
+// SubmitForBetaReview submits a build for the review external testers
+// need.
+func (c *Client) SubmitForBetaReview(build Resource) error {
+	body := map[string]Resource{"data": {
+		Type:          "betaAppReviewSubmissions",
+		Relationships: map[string]any{"build": map[string]any{"data": map[string]string{"type": "builds", "id": build.ID}}},
+	}}
+	return c.do(http.MethodPost, "/v1/betaAppReviewSubmissions", body, nil)
+}
+
+// SetExportCompliance answers a build's encryption export question, which
+// otherwise holds it as "Missing Compliance" until someone answers in App
+// Store Connect. It does nothing if the build already has that answer,
+// e.g. from ITSAppUsesNonExemptEncryption in its Info.plist.
+func (c *Client) SetExportCompliance(build Resource, usesNonExemptEncryption bool) error {
+	if answer, ok := build.Attributes["usesNonExemptEncryption"].(bool); ok && answer == usesNonExemptEncryption {
+		return nil
+	}
+	body := map[string]Resource{"data": {
+		Type:       "builds",
+		ID:         build.ID,
+		Attributes: map[string]any{"usesNonExemptEncryption": usesNonExemptEncryption},
+	}}
+	return c.do(http.MethodPatch, "/v1/builds/"+build.ID, body, nil)
+}
+
+// VersionBuild returns the build attached to an App Store version, or
+// ok=false if none has been chosen yet.
+func (c *Client) VersionBuild(version Resource) (build Resource, ok bool, err error) {
+	var resp struct {
+		Data *Resource `json:"data"`
+	}
+	if err := c.do(http.MethodGet, fmt.Sprintf("/v1/appStoreVersions/%s/build", version.ID), nil, &resp); err != nil {
+		return Resource{}, false, err
+	}
+	if resp.Data == nil {
+		return Resource{}, false, nil
+	}
+	return *resp.Data, true, nil
+}
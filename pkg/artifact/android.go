@@ -0,0 +1,251 @@
+package artifact
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// androidAttrIDs are the resource IDs of android: attributes, for
+// manifests whose string pool leaves attribute names out.
+var androidAttrIDs = map[string]uint32{
+	"debuggable":       0x0101000f,
+	"minSdkVersion":    0x0101020c,
+	"targetSdkVersion": 0x01010270,
+}
+
+// AndroidAttr returns an attribute of the first element named element in
+// an Android manifest: binary XML in APKs, protobuf in AABs, or text.
+// Numbers come back in decimal and booleans as "true" or "false".
+func (a *Artifact) AndroidAttr(manifest, element, attr string) (string, bool) {
+	data, err := a.Read(manifest)
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte{0x03, 0x00, 0x08, 0x00}):
+		return axmlAttr(data, element, attr)
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")):
+		return xmlAttr(data, element, attr)
+	}
+	return protoAttr(data, element, attr)
+}
+
+func xmlAttr(data []byte, element, attr string) (string, bool) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", false
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != element {
+			continue
+		}
+		for _, at := range start.Attr {
+			if at.Name.Local == attr {
+				return at.Value, true
+			}
+		}
+		return "", false
+	}
+}
+
+// axmlAttr walks the chunks of Android binary XML: the string pool, the
+// resource map naming attributes by ID, then the elements.
+func axmlAttr(data []byte, element, attr string) (string, bool) {
+	const (
+		resourceMapType = 0x0180
+		startType       = 0x0102
+
+		typeString = 0x03
+		typeDec    = 0x10
+		typeHex    = 0x11
+		typeBool   = 0x12
+	)
+	pool := axmlPool(data)
+	id, known := androidAttrIDs[attr]
+	var ids []uint32
+	str := func(i uint32) string {
+		if int(i) < len(pool) {
+			return pool[i]
+		}
+		return ""
+	}
+
+	for off := 8; off+8 <= len(data); {
+		typ := binary.LittleEndian.Uint16(data[off:])
+		size := int(binary.LittleEndian.Uint32(data[off+4:]))
+		if size < 8 || off+size > len(data) {
+			return "", false
+		}
+		chunk := data[off : off+size]
+		off += size
+
+		switch typ {
+		case resourceMapType:
+			header := int(binary.LittleEndian.Uint16(chunk[2:]))
+			for i := header; i+4 <= len(chunk); i += 4 {
+				ids = append(ids, binary.LittleEndian.Uint32(chunk[i:]))
+			}
+		case startType:
+			if len(chunk) < 36 || str(binary.LittleEndian.Uint32(chunk[20:])) != element {
+				continue
+			}
+			start := 16 + int(binary.LittleEndian.Uint16(chunk[24:]))
+			attrSize := int(binary.LittleEndian.Uint16(chunk[26:]))
+			count := int(binary.LittleEndian.Uint16(chunk[28:]))
+			for i := 0; i < count; i++ {
+				at := chunk[min(start+i*attrSize, len(chunk)):]
+				if len(at) < 20 {
+					break
+				}
+				name := binary.LittleEndian.Uint32(at[4:])
+				if str(name) != attr && (!known || int(name) >= len(ids) || ids[name] != id) {
+					continue
+				}
+				raw, dataType, value := binary.LittleEndian.Uint32(at[8:]), at[15], binary.LittleEndian.Uint32(at[16:])
+				switch dataType {
+				case typeString:
+					return str(value), true
+				case typeDec, typeHex:
+					return strconv.Itoa(int(int32(value))), true
+				case typeBool:
+					return strconv.FormatBool(value != 0), true
+				}
+				if raw != 0xffffffff {
+					return str(raw), true
+				}
+				return fmt.Sprintf("0x%08x", value), true
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// protoAttr walks the aapt2 protobuf XML of an AAB manifest:
+// XmlNode{element 1}, XmlElement{name 3, attribute 4, child 5},
+// XmlAttribute{name 2, value 3, compiled_item 6}, Item{prim 7} and
+// Primitive{int_decimal_value 6, int_hexadecimal_value 7, boolean_value 8}.
+func protoAttr(node []byte, element, attr string) (string, bool) {
+	el, ok := protoField(node, 1)
+	if !ok {
+		return "", false
+	}
+	if name, _ := protoField(el, 3); string(name) == element {
+		for _, at := range protoFields(el, 4) {
+			if name, _ := protoField(at, 2); string(name) != attr {
+				continue
+			}
+			if value, _ := protoField(at, 3); len(value) > 0 {
+				return string(value), true
+			}
+			item, _ := protoField(at, 6)
+			prim, _ := protoField(item, 7)
+			for _, f := range protoScan(prim) {
+				switch f.num {
+				case 6, 7:
+					return strconv.Itoa(int(int32(f.varint))), true
+				case 8:
+					return strconv.FormatBool(f.varint != 0), true
+				}
+			}
+			return "", true
+		}
+		return "", false
+	}
+	for _, child := range protoFields(el, 5) {
+		if v, ok := protoAttr(child, element, attr); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// protoValue is one field of a protobuf message.
+type protoValue struct {
+	num    int
+	varint uint64
+	bytes  []byte
+}
+
+// protoScan splits a protobuf message into its fields, stopping at the
+// first it cannot read.
+func protoScan(msg []byte) []protoValue {
+	var fields []protoValue
+	r := bytes.NewReader(msg)
+	for r.Len() > 0 {
+		key, err := binary.ReadUvarint(r)
+		if err != nil {
+			break
+		}
+		f := protoValue{num: int(key >> 3)}
+		switch key & 7 {
+		case 0:
+			if f.varint, err = binary.ReadUvarint(r); err != nil {
+				return fields
+			}
+		case 1:
+			_, err = r.Seek(8, io.SeekCurrent)
+		case 2:
+			n, err := binary.ReadUvarint(r)
+			if err != nil || n > uint64(r.Len()) {
+				return fields
+			}
+			f.bytes = make([]byte, n)
+			_, _ = r.Read(f.bytes)
+		case 5:
+			_, err = r.Seek(4, io.SeekCurrent)
+		default:
+			return fields
+		}
+		if err != nil {
+			break
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// protoField returns the first length-delimited field num of msg.
+func protoField(msg []byte, num int) ([]byte, bool) {
+	for _, f := range protoScan(msg) {
+		if f.num == num && f.bytes != nil {
+			return f.bytes, true
+		}
+	}
+	return nil, false
+}
+
+// protoFields returns every length-delimited field num of msg.
+func protoFields(msg []byte, num int) [][]byte {
+	var out [][]byte
+	for _, f := range protoScan(msg) {
+		if f.num == num && f.bytes != nil {
+			out = append(out, f.bytes)
+		}
+	}
+	return out
+}
+
+// ABIs returns the Android ABIs the artifact has native libraries for,
+// from lib/<abi>/ in APKs and <module>/lib/<abi>/ in AABs.
+func (a *Artifact) ABIs() []string {
+	seen := map[string]bool{}
+	var abis []string
+	for _, f := range a.Files {
+		parts := strings.Split(f.Path, "/")
+		for i := 0; i+2 < len(parts) && i < 2; i++ {
+			if parts[i] == "lib" && !seen[parts[i+1]] {
+				seen[parts[i+1]] = true
+				abis = append(abis, parts[i+1])
+			}
+		}
+	}
+	return abis
+}
@@ -0,0 +1,126 @@
+package artifact
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// axml encodes a manifest with one uses-sdk element in Android binary XML.
+// Attribute names are left empty in the pool, as shrinkers do, so they
+// resolve through the resource map.
+func axml(target uint32) []byte {
+	le := binary.LittleEndian
+	strs := []string{"", "", "uses-sdk", "debuggable"}
+
+	var offsets, data []byte
+	for _, s := range strs {
+		offsets = le.AppendUint32(offsets, uint32(len(data)))
+		data = append(data, byte(len(s)), byte(len(s)))
+		data = append(data, s...)
+		data = append(data, 0)
+	}
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+	pool := le.AppendUint16(nil, 0x0001)
+	pool = le.AppendUint16(pool, 28)
+	pool = le.AppendUint32(pool, uint32(28+len(offsets)+len(data)))
+	pool = le.AppendUint32(pool, uint32(len(strs)))
+	pool = le.AppendUint32(pool, 0)
+	pool = le.AppendUint32(pool, 1<<8) // UTF-8
+	pool = le.AppendUint32(pool, uint32(28+len(offsets)))
+	pool = le.AppendUint32(pool, 0)
+	pool = append(append(pool, offsets...), data...)
+
+	ids := le.AppendUint16(nil, 0x0180)
+	ids = le.AppendUint16(ids, 8)
+	ids = le.AppendUint32(ids, 16)
+	ids = le.AppendUint32(ids, androidAttrIDs["minSdkVersion"])
+	ids = le.AppendUint32(ids, androidAttrIDs["targetSdkVersion"])
+
+	attr := func(name, dataType, value uint32) []byte {
+		b := le.AppendUint32(nil, 0xffffffff)
+		b = le.AppendUint32(b, name)
+		b = le.AppendUint32(b, 0xffffffff)
+		b = le.AppendUint16(b, 8)
+		b = append(b, 0, byte(dataType))
+		return le.AppendUint32(b, value)
+	}
+	attrs := append(append(attr(0, 0x10, 21), attr(1, 0x10, target)...), attr(3, 0x12, 0xffffffff)...)
+	start := le.AppendUint16(nil, 0x0102)
+	start = le.AppendUint16(start, 16)
+	start = le.AppendUint32(start, uint32(36+len(attrs)))
+	start = le.AppendUint32(start, 1)
+	start = le.AppendUint32(start, 0xffffffff)
+	start = le.AppendUint32(start, 0xffffffff)
+	start = le.AppendUint32(start, 2) // uses-sdk
+	start = le.AppendUint16(start, 20)
+	start = le.AppendUint16(start, 20)
+	start = le.AppendUint16(start, 3)
+	start = append(start, make([]byte, 6)...)
+	start = append(start, attrs...)
+
+	body := append(append(pool, ids...), start...)
+	doc := le.AppendUint16(nil, 0x0003)
+	doc = le.AppendUint16(doc, 8)
+	doc = le.AppendUint32(doc, uint32(8+len(body)))
+	return append(doc, body...)
+}
+
+// proto encodes length-delimited field num holding b.
+func proto(num int, b []byte) []byte {
+	out := binary.AppendUvarint(nil, uint64(num<<3|2))
+	out = binary.AppendUvarint(out, uint64(len(b)))
+	return append(out, b...)
+}
+
+func TestAndroidAttr(t *testing.T) {
+	// <manifest><uses-sdk android:targetSdkVersion=35 (compiled only)/></manifest>
+	prim := append(binary.AppendUvarint(nil, 6<<3), binary.AppendUvarint(nil, 35)...)
+	target := append(proto(2, []byte("targetSdkVersion")), proto(6, proto(7, prim))...)
+	usesSdk := append(proto(3, []byte("uses-sdk")), proto(4, target)...)
+	manifest := proto(1, append(proto(3, []byte("manifest")), proto(5, proto(1, usesSdk))...))
+
+	dir := writeFiles(t, map[string]string{
+		"lib/arm64-v8a/libgio.so":   "x",
+		"lib/armeabi-v7a/libgio.so": "x",
+		"text/AndroidManifest.xml":  `<manifest xmlns:android="http://schemas.android.com/apk/res/android"><uses-sdk android:targetSdkVersion="34"/></manifest>`,
+	})
+	for name, data := range map[string][]byte{"binary/AndroidManifest.xml": axml(33), "proto/AndroidManifest.xml": manifest} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		manifest, attr, want string
+		ok                   bool
+	}{
+		{"binary/AndroidManifest.xml", "targetSdkVersion", "33", true},
+		{"binary/AndroidManifest.xml", "minSdkVersion", "21", true},
+		{"binary/AndroidManifest.xml", "debuggable", "true", true},
+		{"binary/AndroidManifest.xml", "maxSdkVersion", "", false},
+		{"proto/AndroidManifest.xml", "targetSdkVersion", "35", true},
+		{"proto/AndroidManifest.xml", "minSdkVersion", "", false},
+		{"text/AndroidManifest.xml", "targetSdkVersion", "34", true},
+	} {
+		got, ok := a.AndroidAttr(tt.manifest, "uses-sdk", tt.attr)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("AndroidAttr(%s, %s) = %q, %v; want %q, %v", tt.manifest, tt.attr, got, ok, tt.want, tt.ok)
+		}
+	}
+	if got := a.ABIs(); !reflect.DeepEqual(got, []string{"arm64-v8a", "armeabi-v7a"}) {
+		t.Errorf("ABIs = %v", got)
+	}
+}
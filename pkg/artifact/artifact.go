@@ -0,0 +1,200 @@
+// Package artifact reads build outputs (APK, AAB, IPA, MSIX, .app bundles,
+// .tar.gz/.zip packages) as flat file lists so two releases can be compared.
+package artifact
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// File is one entry of an artifact.
+type File struct {
+	Path string // Slash-separated path inside the artifact
+	Size int64  // Uncompressed size in bytes
+}
+
+// Artifact is the file list of a build output. Manifest and binary
+// contents are read on demand through Read.
+type Artifact struct {
+	Path  string
+	Kind  string // "apk", "aab", "ipa", "msix", "app", "zip", "tar.gz" or "dir"
+	Files []File
+
+	read func(name string) ([]byte, error)
+}
+
+// Open reads the file list of an artifact.
+func Open(path string) (*Artifact, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("artifact not found: %s", path)
+	}
+
+	a := &Artifact{Path: path, Kind: kindOf(path, info.IsDir())}
+	switch {
+	case info.IsDir():
+		err = a.openDir()
+	case a.Kind == "tar.gz":
+		err = a.openTarGz()
+	default:
+		err = a.openZip()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sort.Slice(a.Files, func(i, j int) bool { return a.Files[i].Path < a.Files[j].Path })
+	return a, nil
+}
+
+func kindOf(path string, dir bool) string {
+	lower := strings.ToLower(path)
+	switch {
+	case dir && strings.HasSuffix(strings.TrimSuffix(lower, "/"), ".app"):
+		return "app"
+	case dir:
+		return "dir"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	}
+	switch ext := strings.TrimPrefix(filepath.Ext(lower), "."); ext {
+	case "apk", "aab", "ipa", "msix", "appx":
+		return ext
+	}
+	return "zip"
+}
+
+// TotalSize returns the sum of all file sizes.
+func (a *Artifact) TotalSize() int64 {
+	var total int64
+	for _, f := range a.Files {
+		total += f.Size
+	}
+	return total
+}
+
+// Read returns the contents of a file inside the artifact.
+func (a *Artifact) Read(name string) ([]byte, error) {
+	return a.read(name)
+}
+
+// Has reports whether the artifact contains name.
+func (a *Artifact) Has(name string) bool {
+	for _, f := range a.Files {
+		if f.Path == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Artifact) openDir() error {
+	root := a.Path
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		a.Files = append(a.Files, File{Path: filepath.ToSlash(rel), Size: info.Size()})
+		return nil
+	})
+	a.read = func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+	}
+	return err
+}
+
+func (a *Artifact) openZip() error {
+	r, err := zip.OpenReader(a.Path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		a.Files = append(a.Files, File{Path: f.Name, Size: int64(f.UncompressedSize64)})
+	}
+	a.read = func(name string) ([]byte, error) {
+		r, err := zip.OpenReader(a.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		f, err := r.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+	return nil
+}
+
+func (a *Artifact) openTarGz() error {
+	err := walkTarGz(a.Path, func(h *tar.Header, _ io.Reader) (bool, error) {
+		if h.Typeflag == tar.TypeReg {
+			a.Files = append(a.Files, File{Path: strings.TrimPrefix(h.Name, "./"), Size: h.Size})
+		}
+		return true, nil
+	})
+	a.read = func(name string) ([]byte, error) {
+		var data []byte
+		found := false
+		err := walkTarGz(a.Path, func(h *tar.Header, r io.Reader) (bool, error) {
+			if strings.TrimPrefix(h.Name, "./") != name {
+				return true, nil
+			}
+			var buf bytes.Buffer
+			if _, err := io.Copy(&buf, r); err != nil {
+				return false, err
+			}
+			data, found = buf.Bytes(), true
+			return false, nil
+		})
+		if err == nil && !found {
+			err = fmt.Errorf("%s: %w", name, os.ErrNotExist)
+		}
+		return data, err
+	}
+	return err
+}
+
+// walkTarGz calls fn for each entry until it returns false or an error.
+func walkTarGz(path string, fn func(*tar.Header, io.Reader) (bool, error)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		more, err := fn(h, tr)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
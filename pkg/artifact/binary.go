@@ -0,0 +1,211 @@
+package artifact
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/gosym"
+	"debug/macho"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrStripped is returned when a binary has neither a symbol table nor a
+// Go pclntab to attribute size to.
+var ErrStripped = errors.New("binary is stripped")
+
+// symbol is an address range attributed to a Go package.
+type symbol struct {
+	name     string
+	addr     uint64
+	size     uint64
+	section  int  // Section number, while sizeBySuccessor sizes Mach-O and PE symbols
+	fromPcln bool // Sized from the Go pclntab rather than the symbol table
+}
+
+// IsBinary reports whether name is likely an executable or shared library
+// worth attributing (the Go app inside an APK, .app, IPA or MSIX).
+func IsBinary(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".so", ".exe", ".dll", ".dylib":
+		return true
+	case "":
+		dir := path.Dir(name)
+		return strings.HasSuffix(dir, "/MacOS") || dir == "MacOS" || strings.HasSuffix(dir, ".app")
+	}
+	return false
+}
+
+// PackageSizes attributes the bytes of an ELF, Mach-O or PE binary to Go
+// packages. Binaries without symbols fall back to the Go pclntab, which
+// covers function code only; codeOnly reports that fallback.
+func PackageSizes(data []byte) (sizes map[string]int64, codeOnly bool, err error) {
+	syms, err := readSymbols(data)
+	if err != nil {
+		return nil, false, err
+	}
+	sizes = make(map[string]int64)
+	for _, s := range syms {
+		sizes[packageOf(s.name)] += int64(s.size)
+		codeOnly = s.fromPcln
+	}
+	return sizes, codeOnly, nil
+}
+
+func readSymbols(data []byte) ([]symbol, error) {
+	r := bytes.NewReader(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("\x7fELF")):
+		f, err := elf.NewFile(r)
+		if err != nil {
+			return nil, err
+		}
+		return elfSymbols(f)
+	case bytes.HasPrefix(data, []byte("MZ")):
+		f, err := pe.NewFile(r)
+		if err != nil {
+			return nil, err
+		}
+		return peSymbols(f)
+	}
+	if fat, err := macho.NewFatFile(r); err == nil {
+		// Prefer arm64, the slice users on Apple silicon actually run
+		arch := fat.Arches[0]
+		for _, a := range fat.Arches {
+			if a.Cpu == macho.CpuArm64 {
+				arch = a
+			}
+		}
+		return machoSymbols(arch.File)
+	}
+	f, err := macho.NewFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("not an ELF, Mach-O or PE binary")
+	}
+	return machoSymbols(f)
+}
+
+func elfSymbols(f *elf.File) ([]symbol, error) {
+	syms, err := f.Symbols()
+	if err == nil && len(syms) > 0 {
+		var out []symbol
+		for _, s := range syms {
+			// Skip undefined symbols and .bss/.tbss, which take no file space
+			if s.Size == 0 || s.Section == elf.SHN_UNDEF || int(s.Section) >= len(f.Sections) ||
+				f.Sections[s.Section].Type == elf.SHT_NOBITS {
+				continue
+			}
+			out = append(out, symbol{name: s.Name, addr: s.Value, size: s.Size})
+		}
+		return out, nil
+	}
+	text := f.Section(".text")
+	pcln := f.Section(".gopclntab")
+	if text == nil || pcln == nil {
+		return nil, ErrStripped
+	}
+	data, err := pcln.Data()
+	if err != nil {
+		return nil, err
+	}
+	return pclnSymbols(data, text.Addr)
+}
+
+func machoSymbols(f *macho.File) ([]symbol, error) {
+	if f.Symtab != nil && len(f.Symtab.Syms) > 0 {
+		var raw []symbol
+		ends := make(map[int]uint64)
+		for _, s := range f.Symtab.Syms {
+			if s.Sect == 0 || int(s.Sect) > len(f.Sections) || s.Type&0xe0 != 0 || s.Type&0x0e != 0x0e { // defined in a section, not a debug stab
+				continue
+			}
+			sect := f.Sections[s.Sect-1]
+			ends[int(s.Sect)] = sect.Addr + sect.Size
+			raw = append(raw, symbol{name: strings.TrimPrefix(s.Name, "_"), addr: s.Value, section: int(s.Sect)})
+		}
+		return sizeBySuccessor(raw, func(s symbol) uint64 { return ends[s.section] }), nil
+	}
+	text := f.Section("__text")
+	pcln := f.Section("__gopclntab")
+	if text == nil || pcln == nil {
+		return nil, ErrStripped
+	}
+	data, err := pcln.Data()
+	if err != nil {
+		return nil, err
+	}
+	return pclnSymbols(data, text.Addr)
+}
+
+func peSymbols(f *pe.File) ([]symbol, error) {
+	if len(f.Symbols) == 0 {
+		return nil, ErrStripped
+	}
+	var raw []symbol
+	for _, s := range f.Symbols {
+		if s.SectionNumber <= 0 || int(s.SectionNumber) > len(f.Sections) {
+			continue
+		}
+		raw = append(raw, symbol{name: s.Name, addr: uint64(s.Value), section: int(s.SectionNumber)})
+	}
+	return sizeBySuccessor(raw, func(s symbol) uint64 {
+		return uint64(f.Sections[s.section-1].VirtualSize)
+	}), nil
+}
+
+// sizeBySuccessor sizes symbols that only carry an address (Mach-O, PE) as
+// the gap to the next symbol in the same section. sectionEnd returns where
+// the last symbol of a section stops.
+func sizeBySuccessor(raw []symbol, sectionEnd func(symbol) uint64) []symbol {
+	sort.Slice(raw, func(i, j int) bool {
+		if raw[i].section != raw[j].section {
+			return raw[i].section < raw[j].section
+		}
+		return raw[i].addr < raw[j].addr
+	})
+	out := make([]symbol, 0, len(raw))
+	for i, s := range raw {
+		end := sectionEnd(s)
+		if i+1 < len(raw) && raw[i+1].section == s.section {
+			end = raw[i+1].addr
+		}
+		if end > s.addr {
+			out = append(out, symbol{name: s.name, addr: s.addr, size: end - s.addr})
+		}
+	}
+	return out
+}
+
+func pclnSymbols(pclntab []byte, textAddr uint64) ([]symbol, error) {
+	table, err := gosym.NewTable(nil, gosym.NewLineTable(pclntab, textAddr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Go pclntab: %w", err)
+	}
+	out := make([]symbol, 0, len(table.Funcs))
+	for _, fn := range table.Funcs {
+		if fn.End > fn.Entry {
+			out = append(out, symbol{name: fn.Name, addr: fn.Entry, size: fn.End - fn.Entry, fromPcln: true})
+		}
+	}
+	return out, nil
+}
+
+// packageOf maps a symbol to its Go package. Runtime type data and
+// compiler-generated symbols get their own buckets, C symbols go to "<C>".
+func packageOf(name string) string {
+	for _, b := range []struct{ prefix, bucket string }{
+		{"type:", "<type data>"}, {"type..", "<type data>"},
+		{"go:", "<runtime data>"}, {"go.", "<runtime data>"},
+	} {
+		if strings.HasPrefix(name, b.prefix) {
+			return b.bucket
+		}
+	}
+	if pkg := (&gosym.Sym{Name: name}).PackageName(); pkg != "" && strings.Contains(name, ".") {
+		return pkg
+	}
+	return "<C>"
+}
@@ -0,0 +1,26 @@
+package artifact
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSizeBySuccessor(t *testing.T) {
+	raw := []symbol{
+		{name: "main.b", addr: 0x1040, section: 1},
+		{name: "runtime.x", addr: 0x2000, section: 2},
+		{name: "main.a", addr: 0x1000, section: 1},
+		{name: "main.c", addr: 0x1100, section: 1},
+	}
+	ends := map[int]uint64{1: 0x1200, 2: 0x2010}
+	got := sizeBySuccessor(raw, func(s symbol) uint64 { return ends[s.section] })
+	want := []symbol{
+		{name: "main.a", addr: 0x1000, size: 0x40},
+		{name: "main.b", addr: 0x1040, size: 0xc0},
+		{name: "main.c", addr: 0x1100, size: 0x100},
+		{name: "runtime.x", addr: 0x2000, size: 0x10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sizeBySuccessor() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,213 @@
+package artifact
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Change is the size of one item in both artifacts.
+type Change struct {
+	Name   string `json:"name"`
+	Before int64  `json:"before"`
+	After  int64  `json:"after"`
+}
+
+// Delta returns After - Before.
+func (c Change) Delta() int64 {
+	return c.After - c.Before
+}
+
+// ManifestDiff lists manifest lines only present on one side.
+type ManifestDiff struct {
+	Path    string   `json:"path"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Note    string   `json:"note,omitempty"` // Why lines could not be compared
+}
+
+// BinaryDiff attributes the size change of one binary to Go packages.
+type BinaryDiff struct {
+	Path     string   `json:"path"`
+	Packages []Change `json:"packages,omitempty"` // Largest change first
+	Error    string   `json:"error,omitempty"`    // e.g. "binary is stripped"
+	Note     string   `json:"note,omitempty"`     // Caveats about the attribution
+}
+
+// Report is the result of Diff.
+type Report struct {
+	A        string        `json:"a"`
+	B        string        `json:"b"`
+	Total    Change        `json:"total"`
+	Sections []Change      `json:"sections"` // Top-level directories, largest change first
+	Added    []File        `json:"added,omitempty"`
+	Removed  []File        `json:"removed,omitempty"`
+	Changed  []Change      `json:"changed,omitempty"` // Files whose size changed, largest change first
+	Manifest *ManifestDiff `json:"manifest,omitempty"`
+	Binaries []BinaryDiff  `json:"binaries,omitempty"`
+}
+
+// Diff compares two artifacts. Files are matched by path, so compare
+// artifacts of the same kind (two APKs, two .app bundles, ...).
+func Diff(a, b *Artifact) *Report {
+	r := &Report{A: a.Path, B: b.Path, Total: Change{Name: "total", Before: a.TotalSize(), After: b.TotalSize()}}
+
+	before := fileSizes(a)
+	after := fileSizes(b)
+	sections := make(map[string]*Change)
+	section := func(name string) *Change {
+		key := sectionOf(name)
+		if sections[key] == nil {
+			sections[key] = &Change{Name: key}
+		}
+		return sections[key]
+	}
+
+	for _, f := range a.Files {
+		section(f.Path).Before += f.Size
+		if _, ok := after[f.Path]; !ok {
+			r.Removed = append(r.Removed, f)
+		}
+	}
+	for _, f := range b.Files {
+		section(f.Path).After += f.Size
+		old, ok := before[f.Path]
+		switch {
+		case !ok:
+			r.Added = append(r.Added, f)
+		case old != f.Size:
+			r.Changed = append(r.Changed, Change{Name: f.Path, Before: old, After: f.Size})
+		}
+	}
+	for _, c := range sections {
+		r.Sections = append(r.Sections, *c)
+	}
+	sortByDelta(r.Sections)
+	sortByDelta(r.Changed)
+	sort.Slice(r.Added, func(i, j int) bool { return r.Added[i].Size > r.Added[j].Size })
+	sort.Slice(r.Removed, func(i, j int) bool { return r.Removed[i].Size > r.Removed[j].Size })
+
+	r.Manifest = diffManifests(a, b)
+	for _, c := range r.Changed {
+		if IsBinary(c.Name) {
+			r.Binaries = append(r.Binaries, diffBinary(a, b, c.Name))
+		}
+	}
+	return r
+}
+
+func fileSizes(a *Artifact) map[string]int64 {
+	sizes := make(map[string]int64, len(a.Files))
+	for _, f := range a.Files {
+		sizes[f.Path] = f.Size
+	}
+	return sizes
+}
+
+// sectionOf groups a path by its first directory ("lib/arm64-v8a",
+// "Contents/MacOS", "res"), or "(root)" for top-level files.
+func sectionOf(name string) string {
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 1:
+		return "(root)"
+	case len(parts) > 2 && (parts[0] == "lib" || parts[0] == "Contents" || parts[0] == "Payload"):
+		return parts[0] + "/" + parts[1]
+	}
+	return parts[0]
+}
+
+func sortByDelta(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		di, dj := abs(changes[i].Delta()), abs(changes[j].Delta())
+		if di != dj {
+			return di > dj
+		}
+		return changes[i].Name < changes[j].Name
+	})
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func diffManifests(a, b *Artifact) *ManifestDiff {
+	nameA, nameB := a.Manifest(), b.Manifest()
+	if nameA == "" || nameB == "" || path.Base(nameA) != path.Base(nameB) {
+		return nil
+	}
+	d := &ManifestDiff{Path: nameB}
+	linesA, errA := a.ManifestLines(nameA)
+	linesB, errB := b.ManifestLines(nameB)
+	switch {
+	case errA != nil || errB != nil:
+		d.Note = "could not read manifest"
+		return d
+	case linesA == nil && linesB == nil:
+		d.Note = "binary plist; compare with 'plutil -p'"
+		return d
+	}
+	d.Added, d.Removed = lineDiff(linesA, linesB)
+	return d
+}
+
+// lineDiff returns lines only in b (added) and only in a (removed).
+// Manifests are unordered enough that a set comparison reads better
+// than an edit script.
+func lineDiff(a, b []string) (added, removed []string) {
+	count := make(map[string]int)
+	for _, l := range a {
+		count[l]++
+	}
+	for _, l := range b {
+		if count[l] > 0 {
+			count[l]--
+		} else {
+			added = append(added, l)
+		}
+	}
+	for _, l := range a {
+		if count[l] > 0 {
+			count[l]--
+			removed = append(removed, l)
+		}
+	}
+	return added, removed
+}
+
+func diffBinary(a, b *Artifact, name string) BinaryDiff {
+	d := BinaryDiff{Path: name}
+	sizes := make([]map[string]int64, 2)
+	codeOnly := make([]bool, 2)
+	for i, art := range []*Artifact{a, b} {
+		data, err := art.Read(name)
+		if err == nil {
+			sizes[i], codeOnly[i], err = PackageSizes(data)
+		}
+		if err != nil {
+			d.Error = err.Error()
+			return d
+		}
+	}
+	switch {
+	case codeOnly[0] != codeOnly[1]:
+		d.Note = "only one binary has a symbol table; package sizes are not comparable"
+	case codeOnly[0]:
+		d.Note = "no symbol table; sizes cover function code only"
+	}
+	for pkg, before := range sizes[0] {
+		if after := sizes[1][pkg]; after != before {
+			d.Packages = append(d.Packages, Change{Name: pkg, Before: before, After: after})
+		}
+	}
+	for pkg, after := range sizes[1] {
+		if _, ok := sizes[0][pkg]; !ok {
+			d.Packages = append(d.Packages, Change{Name: pkg, After: after})
+		}
+	}
+	sortByDelta(d.Packages)
+	return d
+}
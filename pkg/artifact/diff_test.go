@@ -0,0 +1,81 @@
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiff(t *testing.T) {
+	a, err := Open(writeFiles(t, map[string]string{
+		"AppxManifest.xml":  "<Package>\n  <Capability Name=\"bluetooth\"/>\n</Package>\n",
+		"assets/logo.png":   "1234",
+		"assets/old.png":    "12",
+		"lib/arm64/libx.so": "abc",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(writeFiles(t, map[string]string{
+		"AppxManifest.xml":  "<Package>\n  <Capability Name=\"proximity\"/>\n</Package>\n",
+		"assets/logo.png":   "12345678",
+		"assets/new.png":    "1",
+		"lib/arm64/libx.so": "abc",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Diff(a, b)
+	if len(r.Added) != 1 || r.Added[0].Path != "assets/new.png" {
+		t.Errorf("Added = %v", r.Added)
+	}
+	if len(r.Removed) != 1 || r.Removed[0].Path != "assets/old.png" {
+		t.Errorf("Removed = %v", r.Removed)
+	}
+	if len(r.Changed) != 1 || r.Changed[0].Name != "assets/logo.png" || r.Changed[0].Delta() != 4 {
+		t.Errorf("Changed = %v", r.Changed)
+	}
+	if r.Sections[0].Name != "assets" || r.Sections[0].Delta() != 3 {
+		t.Errorf("Sections[0] = %v", r.Sections[0])
+	}
+	if r.Manifest == nil {
+		t.Fatal("manifest not compared")
+	}
+	if !reflect.DeepEqual(r.Manifest.Added, []string{`<Capability Name="proximity"/>`}) ||
+		!reflect.DeepEqual(r.Manifest.Removed, []string{`<Capability Name="bluetooth"/>`}) {
+		t.Errorf("Manifest = %+v", r.Manifest)
+	}
+}
+
+func TestPackageOf(t *testing.T) {
+	tests := map[string]string{
+		"runtime.mallocgc":                           "runtime",
+		"github.com/joeblew999/goup-util/cmd.init.0": "github.com/joeblew999/goup-util/cmd",
+		"gioui.org/app.(*Window).Invalidate":         "gioui.org/app",
+		"type:*gioui.org/app.Window":                 "<type data>",
+		"go:buildinfo":                               "<runtime data>",
+		"x_cgo_init":                                 "<C>",
+	}
+	for sym, want := range tests {
+		if got := packageOf(sym); got != want {
+			t.Errorf("packageOf(%q) = %q, want %q", sym, got, want)
+		}
+	}
+}
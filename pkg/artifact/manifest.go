@@ -0,0 +1,148 @@
+package artifact
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// manifestNames are the platform manifests compared by Diff.
+var manifestNames = []string{"AndroidManifest.xml", "Info.plist", "AppxManifest.xml"}
+
+// Manifest returns the path of the app manifest (the shallowest
+// AndroidManifest.xml, Info.plist or AppxManifest.xml), or "".
+func (a *Artifact) Manifest() string {
+	best := ""
+	for _, f := range a.Files {
+		for _, name := range manifestNames {
+			if path.Base(f.Path) != name {
+				continue
+			}
+			if best == "" || strings.Count(f.Path, "/") < strings.Count(best, "/") {
+				best = f.Path
+			}
+		}
+	}
+	return best
+}
+
+// ManifestLines returns the manifest as comparable lines: trimmed XML
+// lines for text manifests, the string pool for Android binary XML.
+// Binary plists yield nil.
+func (a *Artifact) ManifestLines(name string) ([]string, error) {
+	data, err := a.Read(name)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0x03, 0x00, 0x08, 0x00}) {
+		return axmlStrings(data), nil
+	}
+	if bytes.HasPrefix(data, []byte("bplist")) {
+		return nil, nil
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// axmlStrings extracts the string pool of Android binary XML. It holds the
+// package name, permissions, activity names and other string values, which
+// is enough to spot manifest changes without aapt2.
+func axmlStrings(data []byte) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, s := range axmlPool(data) {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// axmlPool returns the string pool of Android binary XML by index, with
+// "" for strings it cannot read.
+func axmlPool(data []byte) []string {
+	const poolType = 0x0001
+	if len(data) < 8+28 || binary.LittleEndian.Uint16(data[8:]) != poolType {
+		return nil
+	}
+	pool := data[8:]
+	headerSize := int(binary.LittleEndian.Uint16(pool[2:]))
+	count := int(binary.LittleEndian.Uint32(pool[8:]))
+	flags := binary.LittleEndian.Uint32(pool[16:])
+	stringsStart := int(binary.LittleEndian.Uint32(pool[20:]))
+	utf8 := flags&(1<<8) != 0
+
+	var out []string
+	for i := 0; i < count; i++ {
+		at := headerSize + 4*i
+		if at+4 > len(pool) {
+			break
+		}
+		off := stringsStart + int(binary.LittleEndian.Uint32(pool[at:]))
+		s, _ := poolString(pool, off, utf8)
+		out = append(out, s)
+	}
+	return out
+}
+
+func poolString(pool []byte, off int, utf8 bool) (string, bool) {
+	if utf8 {
+		// UTF-16 length, then UTF-8 byte length
+		_, off, ok := poolLen8(pool, off)
+		if !ok {
+			return "", false
+		}
+		n, off, ok := poolLen8(pool, off)
+		if !ok || off+n > len(pool) {
+			return "", false
+		}
+		return string(pool[off : off+n]), true
+	}
+	if off+2 > len(pool) {
+		return "", false
+	}
+	n := int(binary.LittleEndian.Uint16(pool[off:]))
+	off += 2
+	if n&0x8000 != 0 {
+		if off+2 > len(pool) {
+			return "", false
+		}
+		n = (n&0x7fff)<<16 | int(binary.LittleEndian.Uint16(pool[off:]))
+		off += 2
+	}
+	if off+2*n > len(pool) {
+		return "", false
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(pool[off+2*i:])
+	}
+	return string(utf16.Decode(units)), true
+}
+
+// poolLen8 reads a 1 or 2 byte length prefix of a UTF-8 pool string.
+func poolLen8(pool []byte, off int) (n, next int, ok bool) {
+	if off >= len(pool) {
+		return 0, off, false
+	}
+	n = int(pool[off])
+	off++
+	if n&0x80 != 0 {
+		if off >= len(pool) {
+			return 0, off, false
+		}
+		n = (n&0x7f)<<8 | int(pool[off])
+		off++
+	}
+	return n, off, true
+}
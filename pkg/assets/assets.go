@@ -0,0 +1,205 @@
+// Package assets embeds static files declared in goup.json into an app
+// through a generated go:embed file, so shells ship offline pages, fonts
+// and images inside the binary instead of next to it:
+//
+//	{
+//	  "assets": {
+//	    "include": ["offline.html", "fonts", "images/*.png"]
+//	  }
+//	}
+//
+// Patterns are relative to the app directory and follow go:embed rules:
+// directories are embedded recursively, skipping files starting with "."
+// or "_". The generated file declares an embed.FS (goupAssets by default)
+// in the app's package.
+package assets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/hooks"
+)
+
+// FileName is the generated Go file in the app directory.
+const FileName = "goup_assets.go"
+
+// DefaultVar is the embed.FS variable the generated file declares.
+const DefaultVar = "goupAssets"
+
+// Config is the assets section of goup.json.
+type Config struct {
+	Include []string `json:"include"`
+	Var     string   `json:"var,omitempty"` // Default "goupAssets"
+}
+
+// Load finds goup.json for dir and returns its assets section, or nil
+// when there is none.
+func Load(dir string) (*Config, error) {
+	path, data, err := hooks.Find(dir)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	var section struct {
+		Assets *Config `json:"assets"`
+	}
+	if err := json.Unmarshal(data, &section); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	c := section.Assets
+	if c == nil || len(c.Include) == 0 {
+		return nil, nil
+	}
+	if c.Var == "" {
+		c.Var = DefaultVar
+	}
+	if !token.IsIdentifier(c.Var) {
+		return nil, fmt.Errorf("%s: assets var %q is not a Go identifier", path, c.Var)
+	}
+	return c, nil
+}
+
+// File is one embedded file.
+type File struct {
+	Path string `json:"path"` // Slash-separated, relative to the app directory
+	Size int64  `json:"size"`
+}
+
+// Files expands the patterns against appDir the way go:embed does and
+// returns the files that will be embedded, sorted by path.
+func (c *Config) Files(appDir string) ([]File, error) {
+	seen := map[string]bool{}
+	var files []File
+	for _, pattern := range c.Include {
+		if filepath.IsAbs(pattern) || strings.HasPrefix(filepath.Clean(pattern), "..") {
+			return nil, fmt.Errorf("asset %q must be inside the app directory", pattern)
+		}
+		matches, err := filepath.Glob(filepath.Join(appDir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("asset %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("asset %q matches no files in %s", pattern, appDir)
+		}
+		for _, m := range matches {
+			err := filepath.WalkDir(m, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				// go:embed skips hidden files below a named directory
+				if path != m && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() || !d.Type().IsRegular() {
+					return nil
+				}
+				rel, err := filepath.Rel(appDir, path)
+				if err != nil {
+					return err
+				}
+				rel = filepath.ToSlash(rel)
+				if seen[rel] {
+					return nil
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				seen[rel] = true
+				files = append(files, File{Path: rel, Size: info.Size()})
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// Source returns the generated Go file for an app in package pkg.
+func (c *Config) Source(pkg string) []byte {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by goup-util from goup.json \"assets\". DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\nimport \"embed\"\n\n", pkg)
+	fmt.Fprintf(&b, "// %s holds the static assets declared in goup.json.\n//\n", c.Var)
+	for _, pattern := range c.Include {
+		fmt.Fprintf(&b, "//go:embed %s\n", strconv.Quote(filepath.ToSlash(pattern)))
+	}
+	fmt.Fprintf(&b, "var %s embed.FS\n", c.Var)
+	return b.Bytes()
+}
+
+// Generate writes FileName into appDir after checking that every pattern
+// matches. The file is only rewritten when it changes, so builds stay
+// cached. It reports whether the file was written.
+func (c *Config) Generate(appDir string) (bool, error) {
+	if _, err := c.Files(appDir); err != nil {
+		return false, err
+	}
+	pkg, err := packageName(appDir)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(appDir, FileName)
+	src := c.Source(pkg)
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, src) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// Extract copies the embedded files to outDir, keeping their paths.
+func (c *Config) Extract(appDir, outDir string) ([]File, error) {
+	files, err := c.Files(appDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(appDir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, err
+		}
+		dst := filepath.Join(outDir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// packageName reads the package clause of the app's Go files, skipping
+// tests and the generated files.
+func packageName(appDir string) (string, error) {
+	matches, _ := filepath.Glob(filepath.Join(appDir, "*.go"))
+	for _, m := range matches {
+		if strings.HasSuffix(m, "_test.go") || filepath.Base(m) == FileName || filepath.Base(m) == SiteFileName {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), m, nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		return f.Name.Name, nil
+	}
+	return "", fmt.Errorf("no Go files in %s", appDir)
+}
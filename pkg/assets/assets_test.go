@@ -0,0 +1,93 @@
+package assets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setup(t *testing.T, goup string) string {
+	t.Helper()
+	app := t.TempDir()
+	os.Mkdir(filepath.Join(app, ".git"), 0755)
+	os.MkdirAll(filepath.Join(app, "fonts", "_draft"), 0755)
+	os.WriteFile(filepath.Join(app, "goup.json"), []byte(goup), 0644)
+	os.WriteFile(filepath.Join(app, "go.mod"), []byte("module shell\n\ngo 1.21\n"), 0644)
+	os.WriteFile(filepath.Join(app, "main.go"), []byte("package main\n\nfunc main() {\n\tdata, _ := goupAssets.ReadFile(\"offline.html\")\n\tprint(string(data))\n}\n"), 0644)
+	os.WriteFile(filepath.Join(app, "offline.html"), []byte("<p>offline</p>"), 0644)
+	os.WriteFile(filepath.Join(app, "fonts", "a.ttf"), []byte("aa"), 0644)
+	os.WriteFile(filepath.Join(app, "fonts", ".DS_Store"), nil, 0644)
+	os.WriteFile(filepath.Join(app, "fonts", "_draft", "b.ttf"), nil, 0644)
+	return app
+}
+
+func TestGenerate(t *testing.T) {
+	app := setup(t, `{"assets": {"include": ["offline.html", "fonts"]}}`)
+	c, err := Load(app)
+	if err != nil || c == nil {
+		t.Fatalf("Load: %v %v", c, err)
+	}
+
+	files, err := c.Files(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	if got := strings.Join(paths, " "); got != "fonts/a.ttf offline.html" {
+		t.Errorf("files = %s", got)
+	}
+
+	if written, err := c.Generate(app); err != nil || !written {
+		t.Fatalf("Generate = %v, %v", written, err)
+	}
+	if written, _ := c.Generate(app); written {
+		t.Error("unchanged file rewritten")
+	}
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = app
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	out, err := cmd.CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "<p>offline</p>") {
+		t.Errorf("go run: %v\n%s", err, out)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	app := setup(t, `{"assets": {"include": ["fonts/*.ttf"], "var": "static"}}`)
+	c, _ := Load(app)
+	out := t.TempDir()
+	if _, err := c.Extract(app, out); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "fonts", "a.ttf")); string(data) != "aa" {
+		t.Errorf("extracted %q", data)
+	}
+	if !strings.Contains(string(c.Source("main")), "var static embed.FS") {
+		t.Errorf("source:\n%s", c.Source("main"))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, goup := range []string{
+		`{"assets": {"include": ["missing.png"]}}`,
+		`{"assets": {"include": ["../outside"]}}`,
+	} {
+		app := setup(t, goup)
+		c, _ := Load(app)
+		if _, err := c.Generate(app); err == nil {
+			t.Errorf("no error for %s", goup)
+		}
+	}
+	if _, err := Load(setup(t, `{"assets": {"include": ["fonts"], "var": "no-ident"}}`)); err == nil {
+		t.Error("bad var accepted")
+	}
+	if c, _ := Load(setup(t, `{}`)); c != nil {
+		t.Error("config without assets")
+	}
+}
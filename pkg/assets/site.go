@@ -0,0 +1,194 @@
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/devproxy"
+)
+
+// SiteFileName is the Go file 'goup-util embed-web' generates in the app.
+const SiteFileName = "goup_site.go"
+
+// SiteVar is the embed.FS variable SiteFileName declares.
+const SiteVar = "goupSite"
+
+// Site copies a built web app (the dist/ or build/ output of Vite,
+// webpack, Next.js export, ...) into a hybrid app and embeds it.
+type Site struct {
+	SrcDir string // Built site, with index.html at the top
+	AppDir string // Gio app directory
+	Dir    string // Directory in AppDir to copy to, default "web"
+	Base   string // Base path the site was built for, e.g. "/app/"; "" detects it from <base href>
+}
+
+// Dest returns the directory the site is copied to.
+func (s *Site) Dest() string {
+	return filepath.Join(s.AppDir, s.dir())
+}
+
+func (s *Site) dir() string {
+	if s.Dir == "" {
+		return "web"
+	}
+	return filepath.ToSlash(filepath.Clean(s.Dir))
+}
+
+// Managed reports whether the destination holds a site embed-web copied
+// before, which it may replace without asking.
+func (s *Site) Managed() bool {
+	src, err := os.ReadFile(filepath.Join(s.AppDir, SiteFileName))
+	return err == nil && bytes.Contains(src, []byte("//go:embed all:"+s.dir()+"\n"))
+}
+
+// Copy replaces the destination with the site and rewrites its base path
+// to "/", where the app's embedded server serves it. It returns the files
+// copied and how many of them were rewritten.
+func (s *Site) Copy() (files []File, rewritten int, err error) {
+	if _, err := os.Stat(filepath.Join(s.SrcDir, "index.html")); err != nil {
+		return nil, 0, fmt.Errorf("%s has no index.html: point at the build output (e.g. dist/), not the sources", s.SrcDir)
+	}
+	dir := s.dir()
+	if filepath.IsAbs(dir) || strings.HasPrefix(dir, "..") {
+		return nil, 0, fmt.Errorf("%s must be inside the app directory", s.Dir)
+	}
+	base := s.Base
+	if base == "" {
+		index, _ := os.ReadFile(filepath.Join(s.SrcDir, "index.html"))
+		base = DetectBase(index)
+	}
+	rewrite := strings.HasPrefix(base, "/") && base != "/"
+	if rewrite && !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+
+	dest := s.Dest()
+	if err := os.RemoveAll(dest); err != nil {
+		return nil, 0, err
+	}
+	err = filepath.WalkDir(s.SrcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.SrcDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if rewrite && rewritable[strings.ToLower(filepath.Ext(path))] {
+			if out := RewriteBase(data, base); !bytes.Equal(out, data) {
+				data = out
+				rewritten++
+			}
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+		files = append(files, File{Path: filepath.ToSlash(filepath.Join(dir, rel)), Size: int64(len(data))})
+		return nil
+	})
+	return files, rewritten, err
+}
+
+// rewritable are the file types that reference the base path.
+var rewritable = map[string]bool{
+	".html": true, ".htm": true, ".css": true, ".js": true, ".mjs": true,
+	".json": true, ".webmanifest": true, ".svg": true, ".xml": true,
+}
+
+var baseTag = regexp.MustCompile(`(?i)<base\s[^>]*href\s*=\s*["']([^"']*)["']`)
+
+// DetectBase returns the href of index.html's <base> tag, or "".
+func DetectBase(index []byte) string {
+	if m := baseTag.FindSubmatch(index); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// RewriteBase points URLs that start with base at "/" instead. Only
+// quoted strings and CSS url() are touched, so text that merely mentions
+// the path stays as it is.
+func RewriteBase(data []byte, base string) []byte {
+	for _, open := range []string{`"`, `'`, "`", `(`} {
+		data = bytes.ReplaceAll(data, []byte(open+base), []byte(open+"/"))
+	}
+	return data
+}
+
+// Source returns the generated Go file for an app in package pkg. It
+// embeds the site with "all:", since bundlers emit files starting with
+// "_" (Next.js's _next/), and serves it with client-side routes falling
+// back to index.html, or from the dev server in $GOUP_DEV_PROXY.
+func (s *Site) Source(pkg string) []byte {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by goup-util embed-web. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\t\"embed\"\n\t\"io/fs\"\n\t\"log\"\n\t\"net/http\"\n\t\"net/http/httputil\"\n\t\"net/url\"\n\t\"os\"\n\t\"path\"\n\t\"strings\"\n)\n\n")
+	fmt.Fprintf(&b, "// %s holds the web app copied into %s/ by 'goup-util embed-web'.\n//\n", SiteVar, s.dir())
+	fmt.Fprintf(&b, "//go:embed all:%s\nvar %s embed.FS\n\n", s.dir(), SiteVar)
+	fmt.Fprintf(&b, `// %[1]sHandler serves %[1]s. Paths without a file extension that
+// match no file get index.html, so the app's client-side routes load.
+// With $%[3]s set ('goup-util run --dev-proxy') it forwards to the
+// frontend dev server instead, WebSockets included, for hot reload.
+func %[1]sHandler() http.Handler {
+	if target, err := url.Parse(os.Getenv(%[3]q)); err == nil && target.Host != "" {
+		log.Printf("%[1]s: forwarding to dev server %%s", target)
+		return &httputil.ReverseProxy{Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			if r.In.Header.Get("Origin") != "" {
+				r.Out.Header.Set("Origin", target.Scheme+"://"+target.Host)
+			}
+		}}
+	}
+	root, err := fs.Sub(%[1]s, %[2]q)
+	if err != nil {
+		panic(err)
+	}
+	files := http.FileServer(http.FS(root))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name != "" && path.Ext(name) == "" {
+			if _, err := fs.Stat(root, name); err != nil {
+				r = r.Clone(r.Context())
+				r.URL.Path = "/"
+			}
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+`, SiteVar, s.dir(), devproxy.Env)
+	return b.Bytes()
+}
+
+// Generate writes SiteFileName into the app, if it changed.
+func (s *Site) Generate() (bool, error) {
+	pkg, err := packageName(s.AppDir)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(s.AppDir, SiteFileName)
+	src := s.Source(pkg)
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, src) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,118 @@
+package assets
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectAndRewriteBase(t *testing.T) {
+	if got := DetectBase([]byte(`<head><BASE target="_top" href='/app/'></head>`)); got != "/app/" {
+		t.Errorf("DetectBase = %q", got)
+	}
+	if got := DetectBase([]byte(`<head></head>`)); got != "" {
+		t.Errorf("DetectBase without tag = %q", got)
+	}
+	in := `<base href="/app/"><script src='/app/assets/a.js'></script> see /app/ docs; .x{background:url(/app/bg.png)} import("/app/chunk.js")`
+	want := `<base href="/"><script src='/assets/a.js'></script> see /app/ docs; .x{background:url(/bg.png)} import("/chunk.js")`
+	if got := string(RewriteBase([]byte(in), "/app/")); got != want {
+		t.Errorf("RewriteBase =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSite(t *testing.T) {
+	dist := t.TempDir()
+	os.MkdirAll(filepath.Join(dist, "assets"), 0755)
+	os.MkdirAll(filepath.Join(dist, "_next"), 0755)
+	os.WriteFile(filepath.Join(dist, "index.html"), []byte(`<base href="/app/"><script src="/app/assets/main.js"></script>`), 0644)
+	os.WriteFile(filepath.Join(dist, "assets", "main.js"), []byte(`fetch("/app/data.json")`), 0644)
+	os.WriteFile(filepath.Join(dist, "_next", "chunk.js"), []byte(`1`), 0644)
+	os.WriteFile(filepath.Join(dist, "logo.png"), []byte("/app/"), 0644)
+
+	app := t.TempDir()
+	os.WriteFile(filepath.Join(app, "go.mod"), []byte("module shell\n\ngo 1.22\n"), 0644)
+	os.WriteFile(filepath.Join(app, "main.go"), []byte(`package main
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+)
+
+func main() {
+	for _, path := range []string{"/", "/settings/profile", "/_next/chunk.js", "/missing.js"} {
+		w := httptest.NewRecorder()
+		goupSiteHandler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		body, _ := io.ReadAll(w.Result().Body)
+		fmt.Printf("%s %d %s\n", path, w.Code, body)
+	}
+}
+`), 0644)
+	os.MkdirAll(filepath.Join(app, "web"), 0755)
+	os.WriteFile(filepath.Join(app, "web", "old.html"), nil, 0644)
+
+	s := &Site{SrcDir: dist, AppDir: app}
+	if s.Managed() {
+		t.Fatal("hand-written web/ reported as managed")
+	}
+	files, rewritten, err := s.Copy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 || rewritten != 2 {
+		t.Errorf("copied %d files, rewrote %d; want 4 and 2", len(files), rewritten)
+	}
+	if _, err := os.Stat(filepath.Join(app, "web", "old.html")); err == nil {
+		t.Error("stale file kept")
+	}
+	if data, _ := os.ReadFile(filepath.Join(app, "web", "logo.png")); string(data) != "/app/" {
+		t.Errorf("binary file rewritten: %q", data)
+	}
+	if written, err := s.Generate(); err != nil || !written {
+		t.Fatalf("Generate = %v, %v", written, err)
+	}
+	if !s.Managed() {
+		t.Error("generated site not reported as managed")
+	}
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = app
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %v\n%s", err, out)
+	}
+	if src, _ := os.ReadFile(filepath.Join(app, SiteFileName)); !bytes.Equal(gofmt(t, src), src) {
+		t.Errorf("generated file is not gofmt'ed:\n%s", src)
+	}
+	for _, want := range []string{
+		`/ 200 <base href="/"><script src="/assets/main.js">`,
+		`/settings/profile 200 <base href="/">`,
+		"/_next/chunk.js 200 1",
+		"/missing.js 404",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func gofmt(t *testing.T, src []byte) []byte {
+	t.Helper()
+	out, err := format.Source(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestSiteNeedsIndex(t *testing.T) {
+	s := &Site{SrcDir: t.TempDir(), AppDir: t.TempDir()}
+	if _, _, err := s.Copy(); err == nil || !strings.Contains(err.Error(), "index.html") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package bench summarizes repeated build timings and compares them with
+// a stored baseline, so slowdowns in the build pipeline (a gogio upgrade,
+// a new signing step) show up as numbers rather than impressions.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Cache modes a build is measured in.
+const (
+	Cold = "cold" // Empty Go build cache
+	Warm = "warm" // Go build cache from the previous build
+)
+
+// Sample is one measured build.
+type Sample struct {
+	Mode   string
+	Total  time.Duration
+	Phases map[string]time.Duration
+}
+
+// Stats summarizes the durations of one phase, in milliseconds.
+type Stats struct {
+	Median int64 `json:"medianMs"`
+	P90    int64 `json:"p90Ms"`
+	Min    int64 `json:"minMs"`
+	Max    int64 `json:"maxMs"`
+}
+
+// Mode is the summary of the builds in one cache mode.
+type Mode struct {
+	Iterations int              `json:"iterations"`
+	Total      Stats            `json:"total"`
+	Phases     map[string]Stats `json:"phases"`
+}
+
+// Result is a benchmark run.
+type Result struct {
+	App      string          `json:"app"`
+	Platform string          `json:"platform"`
+	Date     time.Time       `json:"date"`
+	Version  string          `json:"goupUtilVersion,omitempty"`
+	Modes    map[string]Mode `json:"modes"`
+}
+
+// Summarize groups samples by mode and computes their statistics.
+func Summarize(samples []Sample) map[string]Mode {
+	totals := map[string][]time.Duration{}
+	phases := map[string]map[string][]time.Duration{}
+	for _, s := range samples {
+		totals[s.Mode] = append(totals[s.Mode], s.Total)
+		if phases[s.Mode] == nil {
+			phases[s.Mode] = map[string][]time.Duration{}
+		}
+		for phase, d := range s.Phases {
+			phases[s.Mode][phase] = append(phases[s.Mode][phase], d)
+		}
+	}
+	modes := map[string]Mode{}
+	for mode, ds := range totals {
+		m := Mode{Iterations: len(ds), Total: stats(ds), Phases: map[string]Stats{}}
+		for phase, pds := range phases[mode] {
+			m.Phases[phase] = stats(pds)
+		}
+		modes[mode] = m
+	}
+	return modes
+}
+
+func stats(ds []time.Duration) Stats {
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return Stats{
+		Median: Percentile(sorted, 50).Milliseconds(),
+		P90:    Percentile(sorted, 90).Milliseconds(),
+		Min:    sorted[0].Milliseconds(),
+		Max:    sorted[len(sorted)-1].Milliseconds(),
+	}
+}
+
+// Percentile returns the p-th percentile of sorted durations, by linear
+// interpolation between the closest ranks.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(rank)
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := rank - float64(lo)
+	return sorted[lo] + time.Duration(frac*float64(sorted[lo+1]-sorted[lo]))
+}
+
+// Regression is a median that grew beyond the threshold.
+type Regression struct {
+	Mode     string  `json:"mode"`
+	Phase    string  `json:"phase"` // "total" for the whole build
+	Baseline int64   `json:"baselineMs"`
+	Current  int64   `json:"currentMs"`
+	Change   float64 `json:"change"` // Fraction, 0.25 = 25% slower
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s %s: %s → %s (+%.0f%%)", r.Mode, r.Phase,
+		time.Duration(r.Baseline)*time.Millisecond, time.Duration(r.Current)*time.Millisecond, r.Change*100)
+}
+
+// minDelta keeps sub-second noise in short phases from counting as a
+// regression however large it is relatively.
+const minDelta = 500 // ms
+
+// Compare returns the medians in current that are more than threshold (a
+// fraction) slower than in baseline. Modes and phases missing from either
+// side are ignored.
+func Compare(baseline, current *Result, threshold float64) []Regression {
+	var regressions []Regression
+	check := func(mode, phase string, base, cur Stats) {
+		if base.Median <= 0 || cur.Median-base.Median < minDelta {
+			return
+		}
+		change := float64(cur.Median-base.Median) / float64(base.Median)
+		if change > threshold {
+			regressions = append(regressions, Regression{Mode: mode, Phase: phase, Baseline: base.Median, Current: cur.Median, Change: change})
+		}
+	}
+	for _, mode := range []string{Cold, Warm} {
+		base, ok1 := baseline.Modes[mode]
+		cur, ok2 := current.Modes[mode]
+		if !ok1 || !ok2 {
+			continue
+		}
+		check(mode, "total", base.Total, cur.Total)
+		phases := make([]string, 0, len(cur.Phases))
+		for phase := range cur.Phases {
+			phases = append(phases, phase)
+		}
+		sort.Strings(phases)
+		for _, phase := range phases {
+			if b, ok := base.Phases[phase]; ok {
+				check(mode, phase, b, cur.Phases[phase])
+			}
+		}
+	}
+	return regressions
+}
+
+// BaselinePath is where the baseline for an app and platform is kept
+// unless a file is given.
+func BaselinePath(cacheDir, app, platform string) string {
+	return filepath.Join(cacheDir, "bench", app+"-"+platform+".json")
+}
+
+// Load reads a result file.
+func Load(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid benchmark file %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Save writes a result file.
+func (r *Result) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
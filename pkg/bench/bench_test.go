@@ -0,0 +1,63 @@
+package bench
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func samples(mode string, compile ...time.Duration) []Sample {
+	var out []Sample
+	for _, d := range compile {
+		out = append(out, Sample{Mode: mode, Total: d + time.Second, Phases: map[string]time.Duration{"compile": d, "sign": time.Second}})
+	}
+	return out
+}
+
+func TestSummarize(t *testing.T) {
+	s := append(samples(Cold, 10*time.Second, 30*time.Second, 20*time.Second), samples(Warm, 2*time.Second)...)
+	modes := Summarize(s)
+	cold := modes[Cold]
+	if cold.Iterations != 3 || cold.Phases["compile"] != (Stats{Median: 20000, P90: 28000, Min: 10000, Max: 30000}) {
+		t.Errorf("cold = %+v", cold)
+	}
+	if cold.Total.Median != 21000 || modes[Warm].Total.Max != 3000 {
+		t.Errorf("totals = %+v, %+v", cold.Total, modes[Warm].Total)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := &Result{Modes: Summarize(append(samples(Cold, 20*time.Second), samples(Warm, 4*time.Second)...))}
+	cur := &Result{Modes: Summarize(append(samples(Cold, 21*time.Second), samples(Warm, 6*time.Second)...))}
+	cur.Modes[Warm].Phases["link"] = Stats{Median: 9000} // Not in the baseline
+
+	regressions := Compare(base, cur, 0.10)
+	// Cold +5% is within the threshold; warm compile +50% and total +40% are not
+	if len(regressions) != 2 || regressions[0].Phase != "total" || regressions[1].Phase != "compile" || regressions[1].Mode != Warm {
+		t.Fatalf("regressions = %+v", regressions)
+	}
+	if got := regressions[1].String(); got != "warm compile: 4s → 6s (+50%)" {
+		t.Errorf("String = %q", got)
+	}
+
+	// Small absolute changes are noise
+	small := &Result{Modes: Summarize(samples(Warm, 4200*time.Millisecond))}
+	if r := Compare(base, small, 0.01); len(r) != 0 {
+		t.Errorf("noise counted: %+v", r)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := BaselinePath(t.TempDir(), "demo", "macos")
+	r := &Result{App: "demo", Platform: "macos", Modes: Summarize(samples(Warm, time.Second))}
+	if err := r.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Modes[Warm].Total.Median != 2000 || filepath.Base(path) != "demo-macos.json" {
+		t.Errorf("loaded %+v from %s", got, path)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
@@ -88,6 +89,23 @@ func (c *Cache) SetState(state *BuildState) {
 	c.states[key] = state
 }
 
+// States returns the recorded builds of a project, one per platform
+func (c *Cache) States(project string) []*BuildState {
+	var states []*BuildState
+	for _, state := range c.states {
+		if state.Project == project {
+			states = append(states, state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Platform < states[j].Platform })
+	return states
+}
+
+// Remove forgets the build of a project/platform combination
+func (c *Cache) Remove(project, platform string) {
+	delete(c.states, c.key(project, platform))
+}
+
 // key generates a cache key
 func (c *Cache) key(project, platform string) string {
 	return fmt.Sprintf("%s:%s", project, platform)
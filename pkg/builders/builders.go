@@ -0,0 +1,230 @@
+// Package builders keeps a registry of remote machines that can build
+// targets the local host cannot (macOS/iOS from Linux, Windows MSIX from
+// macOS, ...) and dispatches builds to them.
+//
+// The registry is builders.json in the goup-util config directory
+// (override with $GOUP_BUILDERS):
+//
+//	{
+//	  "builders": [
+//	    {"name": "win-vm", "type": "utm", "vm": "Windows 11", "platforms": ["windows"], "arch": ["arm64"]},
+//	    {"name": "mac-mini", "type": "ssh", "host": "ci@mac-mini.local", "os": "darwin", "platforms": ["macos", "ios"]},
+//	    {"name": "farm", "type": "daemon", "url": "https://builds.example.com", "tokenEnv": "GOUP_FARM_TOKEN", "platforms": ["android", "windows"]}
+//	  ]
+//	}
+//
+// Builders are tried in file order; the first capable one wins.
+package builders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RegistryEnv overrides the registry location.
+const RegistryEnv = "GOUP_BUILDERS"
+
+// Kind is how goup-util reaches a builder.
+type Kind string
+
+const (
+	UTM    Kind = "utm"    // Local UTM VM, through the QEMU guest agent
+	SSH    Kind = "ssh"    // Any host with goup-util, over SSH
+	Daemon Kind = "daemon" // A goup-util daemon, over HTTP
+)
+
+// Builder is one registry entry.
+type Builder struct {
+	Name      string   `json:"name"`
+	Kind      Kind     `json:"type"`
+	VM        string   `json:"vm,omitempty"`       // UTM: VM name
+	Host      string   `json:"host,omitempty"`     // SSH: user@host[:port]
+	URL       string   `json:"url,omitempty"`      // Daemon: base URL
+	TokenEnv  string   `json:"tokenEnv,omitempty"` // Daemon: env var holding a bearer token
+	OS        string   `json:"os,omitempty"`       // Builder OS: windows, linux or darwin
+	Platforms []string `json:"platforms"`          // goup-util platforms it can build
+	Arch      []string `json:"arch,omitempty"`     // Empty means any
+	Workdir   string   `json:"workdir,omitempty"`  // Where sources are unpacked
+}
+
+// Registry is the parsed builders.json.
+type Registry struct {
+	Builders []Builder `json:"builders"`
+
+	path string
+}
+
+// DefaultPath returns the registry location.
+func DefaultPath() string {
+	if p := os.Getenv(RegistryEnv); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "goup-util", "builders.json")
+}
+
+// Load reads the registry at path ("" for the default). A missing file is
+// an empty registry.
+func Load(path string) (*Registry, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+	r := &Registry{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read builders: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, b := range r.Builders {
+		if err := b.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return r, nil
+}
+
+// Path is where the registry is stored.
+func (r *Registry) Path() string {
+	return r.path
+}
+
+// Save writes the registry.
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// Add adds or replaces the builder with the same name.
+func (r *Registry) Add(b Builder) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	for i := range r.Builders {
+		if r.Builders[i].Name == b.Name {
+			r.Builders[i] = b
+			return nil
+		}
+	}
+	r.Builders = append(r.Builders, b)
+	return nil
+}
+
+// Remove deletes a builder by name.
+func (r *Registry) Remove(name string) error {
+	for i, b := range r.Builders {
+		if b.Name == name {
+			r.Builders = append(r.Builders[:i], r.Builders[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no builder named %q", name)
+}
+
+// Get returns a builder by name.
+func (r *Registry) Get(name string) (*Builder, error) {
+	for i := range r.Builders {
+		if r.Builders[i].Name == name {
+			return &r.Builders[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no builder named %q in %s", name, r.path)
+}
+
+// Find returns the first builder that can build platform for arch (""
+// for any).
+func (r *Registry) Find(platform, arch string) (*Builder, error) {
+	for i := range r.Builders {
+		if r.Builders[i].Supports(platform, arch) {
+			return &r.Builders[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no builder for %s in %s; add one with 'goup-util builders add'", platform, r.path)
+}
+
+// Supports reports whether b can build platform for arch.
+func (b *Builder) Supports(platform, arch string) bool {
+	if !contains(b.Platforms, platform) {
+		return false
+	}
+	return arch == "" || len(b.Arch) == 0 || contains(b.Arch, arch)
+}
+
+// Validate checks that the fields for b's kind are set.
+func (b *Builder) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("builder has no name")
+	}
+	if len(b.Platforms) == 0 {
+		return fmt.Errorf("builder %s: no platforms", b.Name)
+	}
+	switch b.Kind {
+	case UTM:
+		if b.VM == "" {
+			return fmt.Errorf("builder %s: utm builders need a vm", b.Name)
+		}
+	case SSH:
+		if b.Host == "" {
+			return fmt.Errorf("builder %s: ssh builders need a host", b.Name)
+		}
+	case Daemon:
+		if !strings.HasPrefix(b.URL, "http://") && !strings.HasPrefix(b.URL, "https://") {
+			return fmt.Errorf("builder %s: daemon builders need an http(s) url", b.Name)
+		}
+	default:
+		return fmt.Errorf("builder %s: unknown type %q (use utm, ssh or daemon)", b.Name, b.Kind)
+	}
+	switch b.OS {
+	case "", "windows", "linux", "darwin":
+	default:
+		return fmt.Errorf("builder %s: unknown os %q (use windows, linux or darwin)", b.Name, b.OS)
+	}
+	return nil
+}
+
+// Target is where the builder is reached, for display.
+func (b *Builder) Target() string {
+	switch b.Kind {
+	case UTM:
+		return b.VM
+	case SSH:
+		return b.Host
+	}
+	return b.URL
+}
+
+// LocalSupports reports whether this host can build platform itself.
+// Apple targets need Xcode, so they only build on macOS.
+func LocalSupports(platform, hostOS string) bool {
+	switch platform {
+	case "macos", "ios", "ios-simulator":
+		return hostOS == "darwin"
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package builders
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "builders.json")
+	r, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []Builder{
+		{Name: "win", Kind: UTM, VM: "Windows 11", Platforms: []string{"windows"}, Arch: []string{"arm64"}},
+		{Name: "mac", Kind: SSH, Host: "ci@mac.local:2222", OS: "darwin", Platforms: []string{"macos", "ios"}},
+	} {
+		if err := r.Add(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := r.Find("ios", ""); err != nil || b.Name != "mac" {
+		t.Errorf("Find(ios) = %v, %v", b, err)
+	}
+	if _, err := r.Find("windows", "amd64"); err == nil {
+		t.Error("Find(windows, amd64) matched an arm64-only builder")
+	}
+	if err := r.Add(Builder{Name: "bad", Kind: Daemon, URL: "builds.example.com", Platforms: []string{"android"}}); err == nil {
+		t.Error("daemon builder without http(s) URL accepted")
+	}
+	if got := sshTarget("ci@mac.local:2222"); got != "ssh://ci@mac.local:2222" {
+		t.Errorf("sshTarget = %q", got)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "app", ".bin"), 0755)
+	os.MkdirAll(filepath.Join(src, ".git"), 0755)
+	os.WriteFile(filepath.Join(src, "app", "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(src, "app", ".bin", "app.exe"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref"), 0644)
+
+	data, err := Archive(src, skipDirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := t.TempDir()
+	if err := Extract(data, dest); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dest, "app", "main.go")); string(got) != "package main" {
+		t.Errorf("main.go = %q", got)
+	}
+	for _, skipped := range []string{".git", filepath.Join("app", ".bin")} {
+		if _, err := os.Stat(filepath.Join(dest, skipped)); err == nil {
+			t.Errorf("%s was archived", skipped)
+		}
+	}
+}
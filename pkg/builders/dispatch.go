@@ -0,0 +1,204 @@
+package builders
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Job is one goup-util invocation run on a builder.
+type Job struct {
+	// Args are the goup-util arguments, with paths relative to SourceDir
+	// (e.g. build windows examples/hybrid-dashboard --force).
+	Args []string
+
+	// SourceDir is sent to the builder, usually the repository root so
+	// replace directives pointing at sibling modules resolve.
+	SourceDir string
+
+	// ArtifactDir (relative to SourceDir, slash-separated) is copied back
+	// when the job succeeds.
+	ArtifactDir string
+
+	// Dest is where the artifacts land locally (default: ArtifactDir
+	// under SourceDir).
+	Dest string
+}
+
+// skipDirs are never sent to a builder.
+var skipDirs = map[string]bool{".git": true, ".bin": true, ".dist": true, ".src": true, "node_modules": true}
+
+// Run sends the job's sources to b, runs goup-util there and copies the
+// artifacts back.
+func (b *Builder) Run(job Job) error {
+	src, err := Archive(job.SourceDir, skipDirs)
+	if err != nil {
+		return fmt.Errorf("failed to pack sources: %w", err)
+	}
+	fmt.Printf("📤 Sending %s of sources to builder %s (%s)\n", formatSize(len(src)), b.Name, b.Target())
+
+	var artifacts []byte
+	switch b.Kind {
+	case SSH:
+		artifacts, err = runSSH(b, job, src)
+	case UTM:
+		artifacts, err = runUTM(b, job, src)
+	case Daemon:
+		artifacts, err = runDaemon(b, job, src)
+	default:
+		err = fmt.Errorf("unknown builder type %q", b.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("builder %s: %w", b.Name, err)
+	}
+
+	dest := job.Dest
+	if dest == "" {
+		dest = filepath.Join(job.SourceDir, filepath.FromSlash(job.ArtifactDir))
+	}
+	if err := Extract(artifacts, dest); err != nil {
+		return fmt.Errorf("failed to unpack artifacts: %w", err)
+	}
+	fmt.Printf("📥 Artifacts from %s in %s\n", b.Name, dest)
+	return nil
+}
+
+// RepoRoot returns the git checkout containing dir, or dir itself.
+func RepoRoot(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return dir
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// workdir is where the builder unpacks the sources.
+func (b *Builder) workdir(job Job, guestOS string) string {
+	if b.Workdir != "" {
+		return b.Workdir
+	}
+	name := filepath.Base(job.SourceDir)
+	switch {
+	case b.Kind == UTM && guestOS == "windows":
+		return `C:\goup-builds\` + name
+	case b.Kind == UTM:
+		return "/tmp/goup-builds/" + name
+	}
+	return "goup-builds/" + name // Relative to the SSH login directory
+}
+
+// Archive packs dir as a tar.gz, skipping directories named in skip.
+func Archive(dir string, skip map[string]bool) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.IsDir() && skip[d.Name()] {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil // Symlinks, sockets, ...
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Extract unpacks a tar.gz into dest, refusing entries outside it.
+func Extract(data []byte, dest string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "." {
+			continue
+		}
+		if strings.HasPrefix(name, "../") || name == ".." || path.IsAbs(name) {
+			return fmt.Errorf("unsafe path in archive: %s", hdr.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0777|0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func formatSize(n int) string {
+	if n < 1<<20 {
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
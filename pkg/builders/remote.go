@@ -0,0 +1,286 @@
+package builders
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/utm"
+)
+
+// Check verifies that a builder is reachable and has goup-util.
+func Check(b *Builder) error {
+	switch b.Kind {
+	case SSH:
+		out, err := exec.Command("ssh", "-o", "BatchMode=yes", sshTarget(b.Host), "goup-util", "--version").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("goup-util over SSH failed: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	case UTM:
+		return utm.CheckGuestAgent(b.VM)
+	case Daemon:
+		resp, err := daemonRequest(b, http.MethodGet, "/api/health", nil, "")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	return fmt.Errorf("unknown builder type %q", b.Kind)
+}
+
+// sshTarget turns user@host:port into the ssh:// form ssh accepts.
+func sshTarget(host string) string {
+	if h, port, ok := strings.Cut(host, ":"); ok && !strings.Contains(port, "@") {
+		return "ssh://" + h + ":" + port
+	}
+	return host
+}
+
+// remoteScripts builds the commands run on a builder's shell.
+type remoteScripts struct {
+	windows bool
+	dir     string
+}
+
+func (s remoteScripts) quote(arg string) string {
+	if s.windows {
+		return `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func (s remoteScripts) join(elem string) string {
+	if s.windows {
+		return s.dir + `\` + strings.ReplaceAll(elem, "/", `\`)
+	}
+	return s.dir + "/" + elem
+}
+
+// unpack replaces the work directory with the archive at src ("-" for stdin).
+func (s remoteScripts) unpack(src string) string {
+	d := s.quote(s.dir)
+	if s.windows {
+		return fmt.Sprintf(`(if exist %s rmdir /s /q %s) & mkdir %s && tar -xzf %s -C %s`, d, d, d, s.quote(src), d)
+	}
+	return fmt.Sprintf(`rm -rf %s && mkdir -p %s && tar -xzf %s -C %s`, d, d, s.quote(src), d)
+}
+
+func (s remoteScripts) build(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = s.quote(a)
+	}
+	if s.windows {
+		return fmt.Sprintf(`cd /d %s && goup-util %s`, s.quote(s.dir), strings.Join(quoted, " "))
+	}
+	return fmt.Sprintf(`cd %s && goup-util %s`, s.quote(s.dir), strings.Join(quoted, " "))
+}
+
+// pack archives the artifact directory to dst ("-" for stdout).
+func (s remoteScripts) pack(artifactDir, dst string) string {
+	return fmt.Sprintf(`tar -czf %s -C %s .`, s.quote(dst), s.quote(s.join(artifactDir)))
+}
+
+func runSSH(b *Builder, job Job, src []byte) ([]byte, error) {
+	s := remoteScripts{windows: b.OS == "windows", dir: b.workdir(job, b.OS)}
+	target := sshTarget(b.Host)
+
+	upload := exec.Command("ssh", target, s.unpack("-"))
+	upload.Stdin = bytes.NewReader(src)
+	upload.Stderr = os.Stderr
+	if err := upload.Run(); err != nil {
+		return nil, fmt.Errorf("failed to upload sources: %w", err)
+	}
+
+	fmt.Printf("🔨 goup-util %s (on %s)\n", strings.Join(job.Args, " "), b.Name)
+	build := exec.Command("ssh", target, s.build(job.Args))
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		return nil, fmt.Errorf("remote build failed: %w", err)
+	}
+
+	var out bytes.Buffer
+	fetch := exec.Command("ssh", target, s.pack(job.ArtifactDir, "-"))
+	fetch.Stdout = &out
+	fetch.Stderr = os.Stderr
+	if err := fetch.Run(); err != nil {
+		return nil, fmt.Errorf("failed to fetch artifacts: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func runUTM(b *Builder, job Job, src []byte) ([]byte, error) {
+	guestOS := b.OS
+	if guestOS == "" {
+		guestOS = utm.GuestOS(b.VM)
+	}
+	if err := utm.CheckGuestAgent(b.VM); err != nil {
+		return nil, err
+	}
+	s := remoteScripts{windows: guestOS == "windows", dir: b.workdir(job, guestOS)}
+	shell := func(script string) error {
+		if s.windows {
+			return utm.ExecArgsInVM(b.VM, "cmd.exe", "/c", script)
+		}
+		return utm.ExecArgsInVM(b.VM, "/bin/sh", "-c", script)
+	}
+
+	srcFile, err := os.CreateTemp("", "goup-src-*.tgz")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(srcFile.Name())
+	if _, err := srcFile.Write(src); err != nil {
+		srcFile.Close()
+		return nil, err
+	}
+	srcFile.Close()
+
+	remoteSrc, remoteOut := s.dir+"-src.tgz", s.dir+"-out.tgz"
+	if err := utm.PushFile(b.VM, srcFile.Name(), remoteSrc); err != nil {
+		return nil, fmt.Errorf("failed to upload sources: %w", err)
+	}
+	if err := shell(s.unpack(remoteSrc)); err != nil {
+		return nil, fmt.Errorf("failed to unpack sources: %w", err)
+	}
+
+	fmt.Printf("🔨 goup-util %s (in VM %s)\n", strings.Join(job.Args, " "), b.VM)
+	if err := shell(s.build(job.Args)); err != nil {
+		return nil, fmt.Errorf("build in VM failed: %w", err)
+	}
+	if err := shell(s.pack(job.ArtifactDir, remoteOut)); err != nil {
+		return nil, fmt.Errorf("failed to pack artifacts: %w", err)
+	}
+
+	outFile, err := os.CreateTemp("", "goup-out-*.tgz")
+	if err != nil {
+		return nil, err
+	}
+	outFile.Close()
+	defer os.Remove(outFile.Name())
+	if err := utm.PullFile(b.VM, remoteOut, outFile.Name()); err != nil {
+		return nil, fmt.Errorf("failed to fetch artifacts: %w", err)
+	}
+	return os.ReadFile(outFile.Name())
+}
+
+// Daemon protocol:
+//
+//	POST   /api/jobs                 multipart: args (JSON array), artifactDir, source (tar.gz) → {"id": ...}
+//	GET    /api/jobs/{id}            → JobStatus
+//	GET    /api/jobs/{id}/artifacts  → tar.gz of artifactDir
+//	DELETE /api/jobs/{id}            → cancel
+//	GET    /api/health               → 200 when ready
+
+// JobStatus is the daemon's view of a job.
+type JobStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // queued, running, succeeded, failed, canceled
+	Error  string `json:"error,omitempty"`
+}
+
+// Final reports whether the job has finished.
+func (s JobStatus) Final() bool {
+	return s.Status == "succeeded" || s.Status == "failed" || s.Status == "canceled"
+}
+
+func runDaemon(b *Builder, job Job, src []byte) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	argsJSON, _ := json.Marshal(job.Args)
+	mw.WriteField("args", string(argsJSON))
+	mw.WriteField("artifactDir", job.ArtifactDir)
+	fw, err := mw.CreateFormFile("source", "source.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	fw.Write(src)
+	mw.Close()
+
+	resp, err := daemonRequest(b, http.MethodPost, "/api/jobs", &body, mw.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+	var status JobStatus
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if err != nil || status.ID == "" {
+		return nil, fmt.Errorf("daemon returned no job id")
+	}
+	fmt.Printf("🔨 Job %s queued on %s\n", status.ID, b.Name)
+
+	// Ctrl-C cancels the job on the daemon too.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	last := ""
+	for !status.Final() {
+		select {
+		case <-interrupt:
+			if resp, err := daemonRequest(b, http.MethodDelete, "/api/jobs/"+status.ID, nil, ""); err == nil {
+				resp.Body.Close()
+			}
+			return nil, fmt.Errorf("interrupted; canceled job %s", status.ID)
+		case <-time.After(3 * time.Second):
+		}
+		resp, err := daemonRequest(b, http.MethodGet, "/api/jobs/"+status.ID, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		err = json.NewDecoder(resp.Body).Decode(&status)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job status: %w", err)
+		}
+		if status.Status != last {
+			fmt.Printf("   %s\n", status.Status)
+			last = status.Status
+		}
+	}
+	if status.Status != "succeeded" {
+		return nil, fmt.Errorf("job %s %s: %s", status.ID, status.Status, status.Error)
+	}
+
+	resp, err = daemonRequest(b, http.MethodGet, "/api/jobs/"+status.ID+"/artifacts", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func daemonRequest(b *Builder, method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequest(method, strings.TrimRight(b.URL, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if b.TokenEnv != "" {
+		if token := os.Getenv(b.TokenEnv); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("daemon %s: %w", b.URL, err)
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("daemon %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,81 @@
+// Package buildnumber hands out monotonic build numbers for store uploads:
+// the Android versionCode and the iOS/macOS CFBundleVersion.
+//
+// Numbers live in a local JSON file by default. CI runners that do not share
+// a disk use a git remote instead, where each number is reserved by pushing
+// a tag; a rejected push means another runner won the race and the next
+// number is tried.
+package buildnumber
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+)
+
+// RemoteEnv selects a git remote for build numbers without passing
+// --build-remote to every command (e.g. set once in CI).
+const RemoteEnv = "GOUP_BUILD_REMOTE"
+
+// Auto is the --build-number value that reserves the next number.
+const Auto = "auto"
+
+// Store reserves build numbers per key (usually the app name).
+type Store interface {
+	// Current returns the last reserved number, or 0 if none.
+	Current(key string) (int, error)
+	// Next reserves and returns Current+1. Concurrent callers never
+	// receive the same number.
+	Next(key string) (int, error)
+	// Set raises the counter to n, e.g. to continue from a number
+	// already uploaded to a store. Lowering it is an error.
+	Set(key string, n int) error
+}
+
+// DefaultPath is the local store in the goup-util cache directory.
+func DefaultPath() string {
+	return filepath.Join(config.GetCacheDir(), "build-numbers.json")
+}
+
+// Open returns the git store for remote, or the local store if remote is
+// empty. An empty remote falls back to $GOUP_BUILD_REMOTE. dir is any
+// directory inside the git checkout whose HEAD the tags should point at.
+func Open(remote, dir string) Store {
+	if remote == "" {
+		remote = os.Getenv(RemoteEnv)
+	}
+	if remote == "" {
+		return &LocalStore{Path: DefaultPath()}
+	}
+	return &GitStore{Remote: remote, Dir: dir}
+}
+
+// Resolve turns a --build-number flag value into a number: "" keeps the
+// tool default (0), "auto" reserves the next number, anything else must be
+// a positive integer and also raises the stored counter so later "auto"
+// numbers stay above it.
+func Resolve(s Store, key, value string) (int, error) {
+	switch value {
+	case "":
+		return 0, nil
+	case Auto:
+		return s.Next(key)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid build number %q (use %q or a positive integer)", value, Auto)
+	}
+	cur, err := s.Current(key)
+	if err != nil {
+		return 0, err
+	}
+	if n > cur {
+		if err := s.Set(key, n); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
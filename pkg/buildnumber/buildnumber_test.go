@@ -0,0 +1,107 @@
+package buildnumber
+
+import (
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestLocalStoreNextIsUnique(t *testing.T) {
+	s := &LocalStore{Path: filepath.Join(t.TempDir(), "build-numbers.json")}
+
+	const n = 20
+	var wg sync.WaitGroup
+	got := make(chan int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := s.Next("app")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			got <- v
+		}()
+	}
+	wg.Wait()
+	close(got)
+
+	seen := make(map[int]bool)
+	for v := range got {
+		if seen[v] {
+			t.Errorf("build number %d handed out twice", v)
+		}
+		seen[v] = true
+	}
+	if cur, _ := s.Current("app"); cur != n {
+		t.Errorf("Current = %d, want %d", cur, n)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s := &LocalStore{Path: filepath.Join(t.TempDir(), "build-numbers.json")}
+
+	if n, err := Resolve(s, "app", ""); err != nil || n != 0 {
+		t.Errorf(`Resolve("") = %d, %v; want 0`, n, err)
+	}
+	if n, err := Resolve(s, "app", "41"); err != nil || n != 41 {
+		t.Errorf(`Resolve("41") = %d, %v; want 41`, n, err)
+	}
+	if n, err := Resolve(s, "app", Auto); err != nil || n != 42 {
+		t.Errorf("Resolve(auto) = %d, %v; want 42", n, err)
+	}
+	if _, err := Resolve(s, "app", "x"); err == nil {
+		t.Error(`Resolve("x") should fail`)
+	}
+	if err := s.Set("app", 10); err == nil {
+		t.Error("Set should refuse to lower the counter")
+	}
+}
+
+func TestGitStore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	work := filepath.Join(dir, "work")
+	run := func(dir string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(cmd.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run(dir, "init", "-q", "--bare", remote)
+	run(dir, "init", "-q", work)
+	run(work, "commit", "-q", "--allow-empty", "-m", "init")
+
+	a := &GitStore{Remote: remote, Dir: work}
+	b := &GitStore{Remote: remote, Dir: work}
+	for want := 1; want <= 3; want++ {
+		s := a
+		if want%2 == 0 {
+			s = b
+		}
+		n, err := s.Next("app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Errorf("Next = %d, want %d", n, want)
+		}
+	}
+	if err := a.Set("app", 2); err == nil {
+		t.Error("Set should refuse to lower the counter")
+	}
+}
+
+func TestHighestTag(t *testing.T) {
+	out := "aaa\trefs/tags/build/app/9\nbbb\trefs/tags/build/app/10\nccc\trefs/tags/build/other/99\n"
+	if got := highestTag(out, "app"); got != 10 {
+		t.Errorf("highestTag = %d, want 10", got)
+	}
+}
@@ -0,0 +1,121 @@
+package buildnumber
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tagPrefix namespaces build number tags: refs/tags/build/<key>/<n>.
+const tagPrefix = "refs/tags/build/"
+
+// maxAttempts bounds how often Next retries after losing a push race.
+const maxAttempts = 10
+
+// GitStore reserves numbers by pushing tags to a git remote. Pushing a tag
+// that already exists is rejected by the server, which makes the push an
+// atomic compare-and-set that works across CI runners without a lock
+// service. The tags point at the commit being built, so each number also
+// records its source.
+type GitStore struct {
+	Remote string // Remote name or URL (e.g. "origin")
+	Dir    string // Directory inside the git checkout
+}
+
+// Current implements Store.
+func (s *GitStore) Current(key string) (int, error) {
+	out, err := s.git("ls-remote", "--tags", s.Remote, tagPrefix+key+"/*")
+	if err != nil {
+		return 0, err
+	}
+	return highestTag(out, key), nil
+}
+
+// Next implements Store.
+func (s *GitStore) Next(key string) (int, error) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		cur, err := s.Current(key)
+		if err != nil {
+			return 0, err
+		}
+		n := cur + 1
+		ok, err := s.push(key, n)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("could not reserve a build number for %s after %d attempts", key, maxAttempts)
+}
+
+// Set implements Store.
+func (s *GitStore) Set(key string, n int) error {
+	cur, err := s.Current(key)
+	if err != nil {
+		return err
+	}
+	if n < cur {
+		return fmt.Errorf("build number for %s is already %d; numbers can only go up", key, cur)
+	}
+	if n == cur {
+		return nil
+	}
+	ok, err := s.push(key, n)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("build number %d for %s was taken concurrently", n, key)
+	}
+	return nil
+}
+
+// push creates the tag for n on the remote. It returns false, without an
+// error, if the tag already exists.
+func (s *GitStore) push(key string, n int) (bool, error) {
+	ref := fmt.Sprintf("%s%s/%d", tagPrefix, key, n)
+	out, err := s.git("push", "--porcelain", s.Remote, "HEAD:"+ref)
+	if err == nil {
+		return true, nil
+	}
+	if strings.Contains(out, "already exists") || strings.Contains(out, "[rejected]") {
+		return false, nil
+	}
+	return false, err
+}
+
+func (s *GitStore) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = s.Dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("git %s failed: %w\nOutput: %s", args[0], err, strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
+
+// highestTag returns the largest n among "<sha>\trefs/tags/build/<key>/<n>"
+// lines of git ls-remote output.
+func highestTag(lsRemote, key string) int {
+	prefix := tagPrefix + key + "/"
+	max := 0
+	sc := bufio.NewScanner(strings.NewReader(lsRemote))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], prefix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(fields[1], prefix), "^{}"))
+		if err == nil && n > max {
+			max = n
+		}
+	}
+	return max
+}
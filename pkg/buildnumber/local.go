@@ -0,0 +1,123 @@
+package buildnumber
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Lock timing for LocalStore. A lock older than staleLock is assumed to be
+// left behind by a killed process and is removed.
+const (
+	lockTimeout = 30 * time.Second
+	staleLock   = 5 * time.Minute
+)
+
+// LocalStore keeps counters in a JSON file ({"myapp": 42}), guarded by a
+// lock file so parallel builds on one machine get distinct numbers.
+type LocalStore struct {
+	Path string
+}
+
+// Current implements Store.
+func (s *LocalStore) Current(key string) (int, error) {
+	counters, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	return counters[key], nil
+}
+
+// Next implements Store.
+func (s *LocalStore) Next(key string) (int, error) {
+	var n int
+	err := s.update(func(counters map[string]int) error {
+		counters[key]++
+		n = counters[key]
+		return nil
+	})
+	return n, err
+}
+
+// Set implements Store.
+func (s *LocalStore) Set(key string, n int) error {
+	return s.update(func(counters map[string]int) error {
+		if n < counters[key] {
+			return fmt.Errorf("build number for %s is already %d; numbers can only go up", key, counters[key])
+		}
+		counters[key] = n
+		return nil
+	})
+}
+
+// update runs fn on the counters while holding the lock, then saves them.
+func (s *LocalStore) update(fn func(map[string]int) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	counters, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(counters); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(counters, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write build numbers: %w", err)
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+func (s *LocalStore) load() (map[string]int, error) {
+	counters := make(map[string]int)
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return counters, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build numbers: %w", err)
+	}
+	if err := json.Unmarshal(data, &counters); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.Path, err)
+	}
+	return counters, nil
+}
+
+// lock creates Path+".lock" exclusively, waiting up to lockTimeout.
+func (s *LocalStore) lock() (func(), error) {
+	path := s.Path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock build numbers: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s (remove it if no build is running)", path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,159 @@
+package capabilities
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/artifact"
+	"github.com/joeblew999/goup-util/pkg/constants"
+)
+
+// Status is the outcome of checking one requirement.
+type Status string
+
+const (
+	Present  Status = "present"
+	Missing  Status = "missing"
+	NotBuilt Status = "not-built" // No bundle for the platform to check
+)
+
+// Finding is a requirement and whether the bundle meets it.
+type Finding struct {
+	Requirement
+	Status   Status `json:"status"`
+	Artifact string `json:"artifact,omitempty"`
+}
+
+// Report is the result of auditing an app.
+type Report struct {
+	App          string       `json:"app"`
+	Capabilities []Capability `json:"capabilities"`
+	Findings     []Finding    `json:"findings"`
+}
+
+// OK reports whether no required entry is missing.
+func (r *Report) OK() bool {
+	for _, f := range r.Findings {
+		if f.Status == Missing && !f.Optional {
+			return false
+		}
+	}
+	return true
+}
+
+// Audit detects the capabilities of the app in appDir and checks its
+// app.json and the newest bundles in .dist and .bin for what they need.
+// name is the app name the bundles are called after.
+func Audit(appDir, name string) (*Report, error) {
+	cfg := appconfig.LoadOrDefault(appDir)
+	caps, err := Detect(appDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{App: name, Capabilities: caps, Findings: []Finding{}}
+
+	bundles := map[string]*artifact.Artifact{}
+	for _, req := range Requirements(caps) {
+		f := Finding{Requirement: req, Status: NotBuilt}
+		if req.Kind == AppJSON {
+			f.Status = Missing
+			if cfg.Permissions[strings.TrimPrefix(req.Key, "permissions.")] != "" {
+				f.Status = Present
+			}
+			report.Findings = append(report.Findings, f)
+			continue
+		}
+
+		a, ok := bundles[req.Platform]
+		if !ok {
+			a = OpenBundle(appDir, name, req.Platform)
+			bundles[req.Platform] = a
+		}
+		if a != nil {
+			f.Artifact = a.Path
+			f.Status = check(a, req)
+		}
+		report.Findings = append(report.Findings, f)
+	}
+	return report, nil
+}
+
+// bundlePaths lists where a platform's bundle is looked for, best first:
+// goup-util bundle output, then the gogio build.
+func bundlePaths(appDir, name, platform string) []string {
+	dist := filepath.Join(appDir, constants.DistDir)
+	bin := filepath.Join(appDir, constants.BinDir, platform)
+	switch platform {
+	case "macos":
+		return []string{filepath.Join(dist, name+".app"), filepath.Join(bin, name+".app")}
+	case "ios":
+		return []string{filepath.Join(dist, name+".ipa"), filepath.Join(bin, name+".ipa"), filepath.Join(bin, name+".app")}
+	case "android":
+		return []string{filepath.Join(bin, name+".apk"), filepath.Join(bin, name+".aab")}
+	case "windows":
+		msix, _ := filepath.Glob(filepath.Join(dist, "*.msix"))
+		return append(msix, filepath.Join(dist, ".staging"))
+	}
+	return nil
+}
+
+// OpenBundle opens the best bundle of the app in appDir for platform, or
+// returns nil when it has not been built.
+func OpenBundle(appDir, name, platform string) *artifact.Artifact {
+	for _, path := range bundlePaths(appDir, name, platform) {
+		if a, err := artifact.Open(path); err == nil {
+			return a
+		}
+	}
+	return nil
+}
+
+// check looks for a requirement in a bundle.
+func check(a *artifact.Artifact, req Requirement) Status {
+	switch req.Kind {
+	case BundledRuntime:
+		for _, f := range a.Files {
+			if f.Path == "MicrosoftEdgeWebview2Setup.exe" || strings.HasPrefix(f.Path, "WebView2Runtime/") {
+				return Present
+			}
+		}
+		return Missing
+	case Entitlement:
+		// Only goup-util bundles carry entitlements; a plain gogio
+		// build is not signed for distribution yet
+		if !a.Has("Contents/Entitlements.plist") {
+			return NotBuilt
+		}
+		data, _ := a.Read("Contents/Entitlements.plist")
+		return found(string(data), "<key>"+req.Key+"</key>")
+	}
+
+	manifest := a.Manifest()
+	if manifest == "" {
+		return Missing
+	}
+	lines, _ := a.ManifestLines(manifest)
+	text := strings.Join(lines, "\n")
+	if lines == nil {
+		// Binary plists keep ASCII keys as plain bytes
+		data, _ := a.Read(manifest)
+		text = string(data)
+	}
+	switch req.Kind {
+	case MSIXCapability:
+		return found(text, `Name="`+req.Key+`"`)
+	case InfoPlist:
+		if strings.Contains(text, "<key>") {
+			return found(text, "<key>"+req.Key+"</key>")
+		}
+	}
+	return found(text, req.Key)
+}
+
+func found(text, needle string) Status {
+	if strings.Contains(text, needle) {
+		return Present
+	}
+	return Missing
+}
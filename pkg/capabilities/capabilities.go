@@ -0,0 +1,236 @@
+// Package capabilities finds what an app does that the OS gates behind a
+// permission (a webview, the camera, the microphone, the local network,
+// file pickers, biometrics, notifications) from its imports and app.json,
+// and audits the built bundles for the usage descriptions, entitlements
+// and manifest entries each one needs. A missing entry rarely fails the
+// build; the feature just does not work, or the app is killed, at
+// runtime.
+package capabilities
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/permissions"
+)
+
+// Capability names.
+const (
+	WebView       = "webview"
+	Camera        = "camera"
+	Microphone    = "microphone"
+	LocalNetwork  = "local-network"
+	FilePicker    = "file-picker"
+	Biometrics    = "biometrics"
+	Notifications = "notifications"
+)
+
+// Capability is something the app uses, and what revealed it.
+type Capability struct {
+	Name   string `json:"name"`
+	Source string `json:"source"` // Import path or app.json key
+}
+
+// Kinds of bundle entries.
+const (
+	AppJSON           = "app.json"
+	InfoPlist         = "Info.plist"
+	Entitlement       = "entitlement"
+	AndroidPermission = "Android permission"
+	MSIXCapability    = "MSIX capability"
+	BundledRuntime    = "bundled runtime"
+)
+
+// Requirement is an entry a capability needs in one platform's bundle.
+type Requirement struct {
+	Capability string `json:"capability"`
+	Platform   string `json:"platform"` // "all" for app.json
+	Kind       string `json:"kind"`
+	Key        string `json:"key"`
+	Optional   bool   `json:"optional,omitempty"` // Recommended, not required
+	Fix        string `json:"fix,omitempty"`
+}
+
+// rule detects a capability and lists what it needs.
+type rule struct {
+	name       string
+	imports    []string // Import path prefixes
+	permission permissions.Permission
+	needs      []Requirement
+}
+
+const bundleMacOS = "goup-util bundle macos"
+
+var rules = []rule{
+	{
+		name:    WebView,
+		imports: []string{"github.com/gioui-plugins/gio-plugins/webviewer"},
+		needs: []Requirement{
+			{Platform: "android", Kind: AndroidPermission, Key: "android.permission.INTERNET",
+				Fix: "rebuild with goup-util build android; gogio requests INTERNET for apps that use the network"},
+			{Platform: "macos", Kind: Entitlement, Key: "com.apple.security.network.client", Fix: bundleMacOS},
+			{Platform: "windows", Kind: BundledRuntime, Key: "WebView2", Optional: true,
+				Fix: "goup-util bundle windows --webview2 bootstrapper (Windows 10 machines may lack the runtime)"},
+		},
+	},
+	{
+		name:       Camera,
+		imports:    []string{"gioui.org/app/permission/camera"},
+		permission: permissions.Camera,
+		needs: []Requirement{
+			{Platform: "macos", Kind: InfoPlist, Key: "NSCameraUsageDescription", Fix: bundleMacOS},
+			{Platform: "macos", Kind: Entitlement, Key: "com.apple.security.device.camera", Fix: bundleMacOS},
+			{Platform: "ios", Kind: InfoPlist, Key: "NSCameraUsageDescription",
+				Fix: "gogio does not write usage descriptions; iOS terminates the app on first camera use"},
+			{Platform: "android", Kind: AndroidPermission, Key: "android.permission.CAMERA",
+				Fix: `import _ "gioui.org/app/permission/camera" so gogio requests it`},
+			{Platform: "windows", Kind: MSIXCapability, Key: "webcam", Fix: "goup-util bundle windows"},
+		},
+	},
+	{
+		name:       Microphone,
+		permission: permissions.Microphone,
+		needs: []Requirement{
+			{Platform: "macos", Kind: InfoPlist, Key: "NSMicrophoneUsageDescription", Fix: bundleMacOS},
+			{Platform: "macos", Kind: Entitlement, Key: "com.apple.security.device.audio-input", Fix: bundleMacOS},
+			{Platform: "ios", Kind: InfoPlist, Key: "NSMicrophoneUsageDescription",
+				Fix: "gogio does not write usage descriptions; iOS terminates the app on first microphone use"},
+			{Platform: "android", Kind: AndroidPermission, Key: "android.permission.RECORD_AUDIO",
+				Fix: "gogio has no permission package for it; audio capture will fail on Android"},
+			{Platform: "windows", Kind: MSIXCapability, Key: "microphone", Fix: "goup-util bundle windows"},
+		},
+	},
+	{
+		name:       LocalNetwork,
+		permission: permissions.LocalNetwork,
+		needs: []Requirement{
+			{Platform: "macos", Kind: InfoPlist, Key: "NSLocalNetworkUsageDescription", Fix: bundleMacOS},
+			{Platform: "ios", Kind: InfoPlist, Key: "NSLocalNetworkUsageDescription",
+				Fix: "gogio does not write usage descriptions; LAN connections fail silently on iOS 14+"},
+		},
+	},
+	{
+		name:       Biometrics,
+		permission: permissions.Biometrics,
+		needs: []Requirement{
+			{Platform: "ios", Kind: InfoPlist, Key: "NSFaceIDUsageDescription",
+				Fix: "gogio does not write usage descriptions; iOS refuses Face ID without it"},
+			{Platform: "android", Kind: AndroidPermission, Key: "android.permission.USE_BIOMETRIC",
+				Fix: "gogio has no permission package for it; BiometricPrompt will fail on Android"},
+		},
+	},
+	{
+		name:       Notifications,
+		imports:    []string{"github.com/joeblew999/goup-util/pkg/localnotify"},
+		permission: permissions.Notifications,
+		needs: []Requirement{
+			{Platform: "android", Kind: AndroidPermission, Key: "android.permission.POST_NOTIFICATIONS",
+				Fix: "gogio has no permission package for it; Android 13+ drops the notifications"},
+		},
+	},
+	{
+		name:    FilePicker,
+		imports: []string{"github.com/gioui-plugins/gio-plugins/explorer"},
+		needs: []Requirement{
+			{Platform: "macos", Kind: Entitlement, Key: "com.apple.security.files.user-selected.read-write", Fix: bundleMacOS},
+		},
+	},
+}
+
+// Detect returns the capabilities an app uses, from the imports of its Go
+// files and the permissions declared in its app.json.
+func Detect(appDir string, cfg *appconfig.AppConfig) ([]Capability, error) {
+	imports, err := Imports(appDir)
+	if err != nil {
+		return nil, err
+	}
+	var caps []Capability
+	for _, r := range rules {
+		if source := matchImport(imports, r.imports); source != "" {
+			caps = append(caps, Capability{Name: r.name, Source: source})
+			continue
+		}
+		if _, ok := cfg.Permissions[string(r.permission)]; ok && r.permission != "" {
+			caps = append(caps, Capability{Name: r.name, Source: appconfig.ConfigFileName + " permissions." + string(r.permission)})
+		}
+	}
+	return caps, nil
+}
+
+func matchImport(imports, prefixes []string) string {
+	for _, imp := range imports {
+		for _, prefix := range prefixes {
+			if imp == prefix || strings.HasPrefix(imp, prefix+"/") {
+				return imp
+			}
+		}
+	}
+	return ""
+}
+
+// Imports returns the sorted import paths of the Go files under appDir,
+// skipping hidden, vendor and testdata directories and tests.
+func Imports(appDir string) ([]string, error) {
+	seen := map[string]bool{}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(appDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != appDir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil // The build reports syntax errors
+		}
+		for _, imp := range f.Imports {
+			seen[strings.Trim(imp.Path.Value, `"`)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	imports := make([]string, 0, len(seen))
+	for imp := range seen {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	return imports, nil
+}
+
+// Requirements returns what the capabilities need: the app.json permission
+// that drives goup-util's generated entries, then the bundle entries.
+func Requirements(caps []Capability) []Requirement {
+	var reqs []Requirement
+	for _, c := range caps {
+		i := slices.IndexFunc(rules, func(r rule) bool { return r.name == c.Name })
+		if i < 0 {
+			continue
+		}
+		r := rules[i]
+		if r.permission != "" {
+			reqs = append(reqs, Requirement{Capability: r.name, Platform: "all", Kind: AppJSON, Key: "permissions." + string(r.permission),
+				Fix: `add "` + string(r.permission) + `": "<why the app needs it>" to the permissions in ` + appconfig.ConfigFileName})
+		}
+		for _, need := range r.needs {
+			need.Capability = r.name
+			reqs = append(reqs, need)
+		}
+	}
+	return reqs
+}
@@ -0,0 +1,100 @@
+package capabilities
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeApp(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const mainGo = `package main
+
+import (
+	_ "gioui.org/app/permission/camera"
+	"github.com/gioui-plugins/gio-plugins/webviewer/giowebview"
+)
+`
+
+func TestAudit(t *testing.T) {
+	dir := writeApp(t, map[string]string{
+		"main.go":         mainGo,
+		".src/other/x.go": `package x; import _ "github.com/gioui-plugins/gio-plugins/explorer"`,
+		"app.json":        `{"url": "https://example.com", "permissions": {"microphone": "Calls"}}`,
+		".dist/demo.app/Contents/Info.plist": `<plist><dict>
+	<key>NSMicrophoneUsageDescription</key><string>Calls</string>
+</dict></plist>`,
+		".dist/demo.app/Contents/Entitlements.plist": `<plist><dict>
+	<key>com.apple.security.network.client</key><true/>
+	<key>com.apple.security.device.audio-input</key><true/>
+</dict></plist>`,
+		".dist/.staging/AppxManifest.xml": `<Capabilities>
+    <rescap:Capability Name="runFullTrust" />
+    <DeviceCapability Name="microphone" />
+</Capabilities>`,
+		".dist/.staging/MicrosoftEdgeWebview2Setup.exe": "",
+	})
+
+	report, err := Audit(dir, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range report.Capabilities {
+		names = append(names, c.Name)
+	}
+	// Hidden directories are skipped, so no file-picker
+	if len(names) != 3 || names[0] != WebView || names[1] != Camera || names[2] != Microphone {
+		t.Fatalf("capabilities = %v", names)
+	}
+
+	status := map[string]Status{}
+	for _, f := range report.Findings {
+		status[f.Capability+" "+f.Platform+" "+f.Key] = f.Status
+	}
+	for key, want := range map[string]Status{
+		"webview macos com.apple.security.network.client":        Present,
+		"webview windows WebView2":                               Present,
+		"webview android android.permission.INTERNET":            NotBuilt,
+		"camera all permissions.camera":                          Missing,
+		"camera macos NSCameraUsageDescription":                  Missing,
+		"camera macos com.apple.security.device.camera":          Missing,
+		"camera windows webcam":                                  Missing,
+		"microphone all permissions.microphone":                  Present,
+		"microphone macos NSMicrophoneUsageDescription":          Present,
+		"microphone macos com.apple.security.device.audio-input": Present,
+		"microphone windows microphone":                          Present,
+		"microphone ios NSMicrophoneUsageDescription":            NotBuilt,
+	} {
+		if status[key] != want {
+			t.Errorf("%s = %q, want %q", key, status[key], want)
+		}
+	}
+	if report.OK() {
+		t.Error("report with missing camera entries is OK")
+	}
+}
+
+func TestAuditNothingToCheck(t *testing.T) {
+	dir := writeApp(t, map[string]string{"main.go": "package main\n\nimport \"fmt\"\n"})
+	report, err := Audit(dir, "plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Capabilities) != 0 || !report.OK() {
+		t.Errorf("report = %+v", report)
+	}
+}
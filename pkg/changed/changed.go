@@ -0,0 +1,302 @@
+// Package changed maps the files changed in a git repository to the Go
+// modules and apps they affect, so CI can rebuild only those. A file
+// belongs to the innermost module containing it; a module is also
+// affected when it requires, or replaces with a local directory, an
+// affected module. GOOS file name suffixes (_android.go, _windows.go, ...)
+// narrow the platforms a change affects.
+package changed
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Module is a Go module in the repository.
+type Module struct {
+	Path string   `json:"path"`
+	Dir  string   `json:"dir"`               // Relative to the repository, "." for the root
+	App  bool     `json:"app"`               // package main at the module root
+	Deps []string `json:"deps,omitempty"`    // Module paths in the repository it depends on
+	Hits []string `json:"changed,omitempty"` // Its changed files
+	Via  string   `json:"via,omitempty"`     // The affected dependency, for modules without changed files
+
+	platforms map[string]bool
+}
+
+// App is an app to rebuild for some platforms.
+type App struct {
+	Name      string   `json:"name"`
+	Dir       string   `json:"dir"`
+	Platforms []string `json:"platforms"`
+	Reason    string   `json:"reason"`
+}
+
+// Result is what changed since a ref.
+type Result struct {
+	Since   string    `json:"since"`
+	Base    string    `json:"base"` // The merge base of since and HEAD
+	Files   []string  `json:"files"`
+	Modules []*Module `json:"modules"` // Affected modules
+	Apps    []App     `json:"apps"`
+}
+
+// goosPlatforms are the platforms a file with a GOOS suffix is built for.
+// Android builds use linux files too, and iOS builds use darwin files.
+var goosPlatforms = map[string][]string{
+	"android": {"android"},
+	"darwin":  {"macos", "ios"},
+	"ios":     {"ios"},
+	"js":      {"web"},
+	"linux":   {"linux", "android"},
+	"windows": {"windows"},
+}
+
+// Since returns the apps in the repository containing dir that changed
+// between the merge base of since and HEAD and the working tree,
+// including untracked files. Only the given platforms are reported.
+func Since(dir, since string, platforms []string) (*Result, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository", dir)
+	}
+	base, err := git(root, "merge-base", since, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a ref with history in common with HEAD", since)
+	}
+	diff, err := git(root, "diff", "--name-only", base)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	files := append(lines(diff), lines(untracked)...)
+	sort.Strings(files)
+
+	modules, err := Modules(root)
+	if err != nil {
+		return nil, err
+	}
+	r := Affected(modules, slices.Compact(files), platforms)
+	r.Since, r.Base = since, base
+	return r, nil
+}
+
+// Modules finds the modules in the repository at root, tracked or not,
+// and reads their dependencies on each other.
+func Modules(root string) ([]*Module, error) {
+	out, err := git(root, "ls-files", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	var modules []*Module
+	byDir := map[string]*Module{}
+	files := map[*Module]*modfile.File{}
+	for _, file := range lines(out) {
+		if path.Base(file) != "go.mod" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			continue // Deleted in the working tree
+		}
+		f, err := modfile.ParseLax(file, data, nil)
+		if err != nil || f.Module == nil {
+			return nil, fmt.Errorf("%s: not a usable go.mod", file)
+		}
+		m := &Module{Path: f.Module.Mod.Path, Dir: path.Dir(file)}
+		m.App = isMain(filepath.Join(root, filepath.FromSlash(m.Dir)))
+		modules = append(modules, m)
+		byDir[m.Dir] = m
+		files[m] = f
+	}
+
+	byPath := map[string]*Module{}
+	for _, m := range modules {
+		byPath[m.Path] = m
+	}
+	for _, m := range modules {
+		f := files[m]
+		deps := map[string]bool{}
+		for _, req := range f.Require {
+			if _, ok := byPath[req.Mod.Path]; ok {
+				deps[req.Mod.Path] = true
+			}
+		}
+		for _, rep := range f.Replace {
+			if !modfile.IsDirectoryPath(rep.New.Path) {
+				continue
+			}
+			if dep := byDir[path.Clean(path.Join(m.Dir, filepath.ToSlash(rep.New.Path)))]; dep != nil {
+				deps[dep.Path] = true
+			}
+		}
+		for p := range deps {
+			if p != m.Path {
+				m.Deps = append(m.Deps, p)
+			}
+		}
+		sort.Strings(m.Deps)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+	return modules, nil
+}
+
+// Affected maps changed files (relative to the repository, with forward
+// slashes) to the modules and apps they affect on platforms. Markdown
+// files and tests affect nothing.
+func Affected(modules []*Module, files, platforms []string) *Result {
+	r := &Result{Files: files}
+	for _, m := range modules {
+		m.Hits, m.Via, m.platforms = nil, "", map[string]bool{}
+	}
+	for _, file := range files {
+		if strings.EqualFold(path.Ext(file), ".md") {
+			continue
+		}
+		m := owner(modules, file)
+		if m == nil {
+			continue
+		}
+		m.Hits = append(m.Hits, file)
+		for _, p := range filePlatforms(file, platforms) {
+			m.platforms[p] = true
+		}
+	}
+
+	byPath := map[string]*Module{}
+	for _, m := range modules {
+		byPath[m.Path] = m
+	}
+	for grew := true; grew; {
+		grew = false
+		for _, m := range modules {
+			for _, dep := range m.Deps {
+				for p := range byPath[dep].platforms {
+					if !m.platforms[p] {
+						m.platforms[p] = true
+						grew = true
+						if len(m.Hits) == 0 && m.Via == "" {
+							m.Via = dep
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, m := range modules {
+		if len(m.platforms) == 0 {
+			continue
+		}
+		r.Modules = append(r.Modules, m)
+		if !m.App {
+			continue
+		}
+		app := App{Name: path.Base(m.Dir), Dir: m.Dir}
+		if m.Dir == "." {
+			app.Name = path.Base(m.Path)
+		}
+		for _, p := range platforms {
+			if m.platforms[p] {
+				app.Platforms = append(app.Platforms, p)
+			}
+		}
+		if len(m.Hits) > 0 {
+			app.Reason = fmt.Sprintf("%d changed files", len(m.Hits))
+			if len(m.Hits) == 1 {
+				app.Reason = "1 changed file"
+			}
+		} else {
+			app.Reason = "depends on " + m.Via
+		}
+		r.Apps = append(r.Apps, app)
+	}
+	return r
+}
+
+// owner is the innermost module containing file.
+func owner(modules []*Module, file string) *Module {
+	var best *Module
+	for _, m := range modules {
+		if m.Dir != "." && !strings.HasPrefix(file, m.Dir+"/") {
+			continue
+		}
+		if best == nil || len(m.Dir) > len(best.Dir) || best.Dir == "." {
+			best = m
+		}
+	}
+	return best
+}
+
+// filePlatforms are the platforms among platforms that a file is built
+// for, going by a GOOS suffix on Go files. Tests are built for none;
+// other files affect them all.
+func filePlatforms(file string, platforms []string) []string {
+	name := path.Base(file)
+	if strings.HasSuffix(name, "_test.go") {
+		return nil
+	}
+	if !strings.HasSuffix(name, ".go") {
+		return platforms
+	}
+	parts := strings.Split(strings.TrimSuffix(name, ".go"), "_")
+	for i := len(parts) - 1; i >= max(1, len(parts)-2); i-- {
+		if only, ok := goosPlatforms[parts[i]]; ok {
+			var out []string
+			for _, p := range platforms {
+				if slices.Contains(only, p) {
+					out = append(out, p)
+				}
+			}
+			return out
+		}
+	}
+	return platforms
+}
+
+// isMain reports whether the Go files in dir are package main.
+func isMain(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range matches {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err == nil {
+			return f.Name.Name == "main"
+		}
+	}
+	return false
+}
+
+func lines(s string) []string {
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,138 @@
+package changed
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var all = []string{"macos", "ios", "android", "windows", "linux", "web"}
+
+func write(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+		"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// repo has a tool at the root, a shared library, an app that uses it
+// through a replace directive and an app that does not.
+func repo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	write(t, root, map[string]string{
+		"go.mod":                   "module example.com/tool\n\ngo 1.22\n",
+		"main.go":                  "package main\n\nfunc main() {}\n",
+		"lib/go.mod":               "module example.com/lib\n\ngo 1.22\n",
+		"lib/lib.go":               "package lib\n",
+		"examples/dash/go.mod":     "module example.com/dash\n\ngo 1.22\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../../lib\n",
+		"examples/dash/main.go":    "package main\n\nfunc main() {}\n",
+		"examples/basic/go.mod":    "module example.com/basic\n\ngo 1.22\n",
+		"examples/basic/main.go":   "package main\n\nfunc main() {}\n",
+		"examples/basic/README.md": "# basic\n",
+	})
+	run(t, root, "init", "-q", "-b", "main")
+	run(t, root, "add", "-A")
+	run(t, root, "commit", "-q", "-m", "initial")
+	run(t, root, "tag", "base")
+	return root
+}
+
+func apps(r *Result) map[string][]string {
+	m := map[string][]string{}
+	for _, a := range r.Apps {
+		m[a.Name] = a.Platforms
+	}
+	return m
+}
+
+func TestModules(t *testing.T) {
+	modules, err := Modules(repo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, m := range modules {
+		dirs = append(dirs, m.Dir)
+		if m.Dir == "examples/dash" && !reflect.DeepEqual(m.Deps, []string{"example.com/lib"}) {
+			t.Errorf("dash deps = %v", m.Deps)
+		}
+		if m.Dir == "lib" && m.App {
+			t.Error("lib is not an app")
+		}
+	}
+	if want := []string{".", "examples/basic", "examples/dash", "lib"}; !reflect.DeepEqual(dirs, want) {
+		t.Errorf("modules = %v, want %v", dirs, want)
+	}
+}
+
+func TestSince(t *testing.T) {
+	root := repo(t)
+	write(t, root, map[string]string{
+		"lib/lib_android.go":       "package lib\n",
+		"examples/basic/README.md": "# basic app\n",
+	})
+	run(t, root, "add", "-A")
+	run(t, root, "commit", "-q", "-m", "android only")
+	write(t, root, map[string]string{"examples/basic/untracked_windows.go": "package main\n"})
+
+	r, err := Since(root, "base", all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"dash": {"android"}, "basic": {"windows"}}
+	if got := apps(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("apps = %v, want %v", got, want)
+	}
+	for _, a := range r.Apps {
+		if a.Name == "dash" && a.Reason != "depends on example.com/lib" {
+			t.Errorf("dash reason = %q", a.Reason)
+		}
+	}
+}
+
+func TestAffected(t *testing.T) {
+	modules := []*Module{
+		{Path: "example.com/tool", Dir: ".", App: true},
+		{Path: "example.com/lib", Dir: "lib"},
+		{Path: "example.com/dash", Dir: "examples/dash", App: true, Deps: []string{"example.com/lib"}},
+	}
+	tests := []struct {
+		files []string
+		want  map[string][]string
+	}{
+		{[]string{"docs/index.md", "examples/dash/main_test.go"}, map[string][]string{}},
+		{[]string{"cmd/build.go"}, map[string][]string{"tool": all}},
+		{[]string{"lib/lib_darwin.go"}, map[string][]string{"dash": {"macos", "ios"}}},
+		{[]string{"lib/web/assets/app.js", "examples/dash/x_linux_arm64.go"}, map[string][]string{"dash": all}},
+		{[]string{"examples/dash/x_linux_arm64.go"}, map[string][]string{"dash": {"android", "linux"}}},
+	}
+	for _, tt := range tests {
+		got := apps(Affected(modules, tt.files, all))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Affected(%v) = %v, want %v", tt.files, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package changelog turns git history into release notes: the commit
+// subjects since the previous tag, one bullet each, or per-locale
+// templates filled with the version, the date and the conventional
+// commits grouped into breaking changes, features and fixes.
+package changelog
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MaxEntries caps the bullets taken from an untagged history.
+const MaxEntries = 20
+
+// Since returns the notes for the commits in dir after the latest tag
+// before HEAD (or the last MaxEntries commits when nothing is tagged).
+// Merge commits are left out.
+func Since(dir string) (string, error) {
+	args, err := logArgs(dir, "--pretty=format:%s")
+	if err != nil {
+		return "", err
+	}
+	out, err := git(dir, args...)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, subject := range strings.Split(out, "\n") {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			lines = append(lines, "- "+subject)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// logArgs are the git log arguments for the commits since the previous
+// tag, in format.
+func logArgs(dir, format string) ([]string, error) {
+	from, err := previousTag(dir)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"log", "--no-merges", format}
+	if from != "" {
+		return append(args, from+"..HEAD"), nil
+	}
+	return append(args, fmt.Sprintf("-%d", MaxEntries)), nil
+}
+
+// previousTag is the newest tag reachable from HEAD, skipping a tag on
+// HEAD itself so a tagged release build lists what the release adds.
+func previousTag(dir string) (string, error) {
+	if _, err := git(dir, "rev-parse", "--verify", "HEAD"); err != nil {
+		return "", fmt.Errorf("%s is not a git repository with commits", dir)
+	}
+	tag, err := git(dir, "describe", "--tags", "--abbrev=0", "HEAD")
+	if err != nil {
+		return "", nil
+	}
+	head, _ := git(dir, "rev-parse", "HEAD")
+	tagged, _ := git(dir, "rev-parse", tag+"^{commit}")
+	if head != tagged {
+		return tag, nil
+	}
+	prev, err := git(dir, "describe", "--tags", "--abbrev=0", "HEAD^")
+	if err != nil {
+		return "", nil
+	}
+	return prev, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
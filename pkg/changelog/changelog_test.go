@@ -0,0 +1,99 @@
+package changelog
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestSince(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(cmd.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	run("commit", "-q", "--allow-empty", "-m", "Initial release")
+	run("tag", "v1.0.0")
+	run("commit", "-q", "--allow-empty", "-m", "Add offline mode")
+	run("commit", "-q", "--allow-empty", "-m", "Fix crash on rotate")
+
+	notes, err := Since(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "- Fix crash on rotate\n- Add offline mode"; notes != want {
+		t.Errorf("notes = %q, want %q", notes, want)
+	}
+
+	commits, err := Log(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "Fix crash on rotate" {
+		t.Errorf("Log = %+v", commits)
+	}
+
+	// Tagging HEAD still lists what the release adds.
+	run("tag", "v1.1.0")
+	if again, _ := Since(dir); again != notes {
+		t.Errorf("notes after tagging = %q, want %q", again, notes)
+	}
+	if v := Version(dir); v != "1.1.0" {
+		t.Errorf("Version = %q", v)
+	}
+}
+
+func TestParseCommit(t *testing.T) {
+	c := ParseCommit("feat(sync)!: add offline mode\n\nRelease-Note: Works without a connection\nRelease-Note-de: Funktioniert ohne Verbindung\n")
+	if c.Type != "feat" || c.Scope != "sync" || !c.Breaking || c.Subject != "add offline mode" {
+		t.Errorf("commit = %+v", c)
+	}
+	for locale, want := range map[string]string{
+		"de-DE": "Funktioniert ohne Verbindung",
+		"en-US": "Works without a connection",
+	} {
+		if got := c.Note(locale); got != want {
+			t.Errorf("Note(%s) = %q, want %q", locale, got, want)
+		}
+	}
+	if got := ParseCommit("Fix crash on rotate").Note("en-US"); got != "Fix crash on rotate" {
+		t.Errorf("plain commit note = %q", got)
+	}
+	if got := ParseCommit("chore: bump deps\n\nRelease-Note: -").Note("en-US"); got != "" {
+		t.Errorf("hidden commit note = %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	notes := Notes{
+		Version: "1.2.0",
+		Date:    time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Commits: []Commit{
+			ParseCommit("fix: crash on rotate\n\nRelease-Note-de: Absturz beim Drehen behoben"),
+			ParseCommit("feat: dark mode"),
+			ParseCommit("chore: bump deps"),
+		},
+	}
+	tmpl := `Version {{.Version}} ({{.Date.Format "2.1.2006"}})
+{{if .Breaking}}
+{{bullets .Breaking}}
+{{end}}
+{{bullets (first 5 .Highlights)}}`
+
+	got, err := notes.Render(tmpl, "de-DE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Version 1.2.0 (18.10.2026)\n\n- Dark mode\n- Absturz beim Drehen behoben"; got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+	if _, err := notes.Render("{{.Missing}}", "en-US"); err == nil {
+		t.Error("unknown variable accepted")
+	}
+}
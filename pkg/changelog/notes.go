@@ -0,0 +1,178 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Commit is a commit message read as a conventional commit
+// ("feat(sync): add offline mode"). Other messages have no Type.
+type Commit struct {
+	Type     string `json:"type,omitempty"` // "feat", "fix", "chore", ...
+	Scope    string `json:"scope,omitempty"`
+	Breaking bool   `json:"breaking,omitempty"` // "feat!:" or a BREAKING CHANGE footer
+	Subject  string `json:"subject"`            // Without the type and scope
+
+	// Notes are Release-Note trailers by locale: "" for Release-Note,
+	// "de" for Release-Note-de. "-" leaves the commit out of the notes.
+	Notes map[string]string `json:"notes,omitempty"`
+}
+
+var (
+	conventional = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	noteTrailer  = regexp.MustCompile(`(?m)^Release-Note(?:-([A-Za-z]{2,3}(?:[-_][A-Za-z0-9]+)?))?:\s*(.+)$`)
+)
+
+// ParseCommit reads a full commit message: the subject line, then the
+// body with its footers.
+func ParseCommit(message string) Commit {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	c := Commit{Subject: strings.TrimSpace(subject)}
+	if m := conventional.FindStringSubmatch(c.Subject); m != nil {
+		c.Type, c.Scope, c.Breaking, c.Subject = strings.ToLower(m[1]), m[2], m[3] == "!", m[4]
+	}
+	if strings.Contains(body, "BREAKING CHANGE:") || strings.Contains(body, "BREAKING-CHANGE:") {
+		c.Breaking = true
+	}
+	for _, m := range noteTrailer.FindAllStringSubmatch(body, -1) {
+		if c.Notes == nil {
+			c.Notes = map[string]string{}
+		}
+		c.Notes[strings.ReplaceAll(m[1], "_", "-")] = strings.TrimSpace(m[2])
+	}
+	return c
+}
+
+// Note is the commit's line in the release notes for locale: its
+// Release-Note trailer for the locale or its language, then the plain
+// Release-Note trailer, then the subject. It is "" for commits left out.
+func (c Commit) Note(locale string) string {
+	note := c.Subject
+	lang, _, _ := strings.Cut(locale, "-")
+	for _, key := range []string{locale, lang, ""} {
+		if n, ok := c.Notes[key]; ok {
+			note = n
+			break
+		}
+	}
+	if note == "-" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(note)
+	return string(unicode.ToUpper(r)) + note[size:]
+}
+
+// Log returns the commits in dir after the latest tag before HEAD, newest
+// first, with the same range and limit as Since.
+func Log(dir string) ([]Commit, error) {
+	args, err := logArgs(dir, "--pretty=format:%B%x1e")
+	if err != nil {
+		return nil, err
+	}
+	out, err := git(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, message := range strings.Split(out, "\x1e") {
+		if message = strings.TrimSpace(message); message != "" {
+			commits = append(commits, ParseCommit(message))
+		}
+	}
+	return commits, nil
+}
+
+// Version is the tag on HEAD without a leading "v", or "" when HEAD is
+// not tagged.
+func Version(dir string) string {
+	tag, err := git(dir, "describe", "--tags", "--exact-match", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(tag, "v")
+}
+
+// Notes is a release to write notes for.
+type Notes struct {
+	Version string
+	Date    time.Time
+	Commits []Commit
+}
+
+// Release is what a release note template sees. Lists hold one line per
+// commit, already in the template's locale where the commits have
+// Release-Note trailers for it.
+type Release struct {
+	Locale     string
+	Version    string
+	Date       time.Time // {{.Date.Format "2.1.2006"}}
+	Highlights []string  // Breaking changes, features, then fixes
+	Breaking   []string
+	Features   []string
+	Fixes      []string
+	Changes    []string // Every commit, including those without a type
+}
+
+// templateFuncs are available in release note templates.
+var templateFuncs = template.FuncMap{
+	// bullets lists lines as "- line", one per line.
+	"bullets": func(lines []string) string {
+		var b strings.Builder
+		for i, line := range lines {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString("- " + line)
+		}
+		return b.String()
+	},
+	// first keeps the first n lines, for stores with short limits.
+	"first": func(n int, lines []string) []string {
+		return lines[:min(n, len(lines))]
+	},
+}
+
+// Release returns the template variables for locale.
+func (n Notes) Release(locale string) Release {
+	r := Release{Locale: locale, Version: n.Version, Date: n.Date}
+	for _, c := range n.Commits {
+		note := c.Note(locale)
+		if note == "" {
+			continue
+		}
+		r.Changes = append(r.Changes, note)
+		switch {
+		case c.Breaking:
+			r.Breaking = append(r.Breaking, note)
+		case c.Type == "feat":
+			r.Features = append(r.Features, note)
+		case c.Type == "fix":
+			r.Fixes = append(r.Fixes, note)
+		}
+	}
+	r.Highlights = append(append(append([]string{}, r.Breaking...), r.Features...), r.Fixes...)
+	return r
+}
+
+// Render executes a release note template (text/template) for locale. Runs
+// of blank lines left by empty sections are collapsed.
+func (n Notes) Render(text, locale string) (string, error) {
+	t, err := template.New(locale).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("release note template for %s: %w", locale, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, n.Release(locale)); err != nil {
+		return "", fmt.Errorf("release note template for %s: %w", locale, err)
+	}
+	out := strings.TrimSpace(b.String())
+	for strings.Contains(out, "\n\n\n") {
+		out = strings.ReplaceAll(out, "\n\n\n", "\n\n")
+	}
+	return out, nil
+}
@@ -19,6 +19,9 @@ var IosSdkList []byte
 //go:embed sdk-build-tools.json
 var BuildToolsSdkList []byte
 
+//go:embed sdk-windows-list.json
+var WindowsSdkList []byte
+
 // Platform defines the structure for platform-specific SDK details.
 type Platform struct {
 	DownloadURL string `json:"downloadUrl"`
@@ -153,8 +156,15 @@ func GetCacheDir() string {
 	return ".goup-util"
 }
 
+// SDKDirEnv overrides the SDK directory, e.g. for a project that keeps its
+// own SDKs (see 'goup-util env hook').
+const SDKDirEnv = "GOUP_SDK_DIR"
+
 // GetSDKDir returns the OS-appropriate SDK storage directory for goup-util
 func GetSDKDir() string {
+	if dir := os.Getenv(SDKDirEnv); dir != "" {
+		return dir
+	}
 	switch runtime.GOOS {
 	case "darwin": // macOS
 		if home, err := os.UserHomeDir(); err == nil {
@@ -177,8 +187,13 @@ func GetSDKDir() string {
 	return filepath.Join(GetCacheDir(), "sdks")
 }
 
-// GetCachePath returns the full path to the cache.json file
+// GetCachePath returns the full path to the cache.json file. With
+// GOUP_SDK_DIR set it lives in that directory, since it records what is
+// installed there.
 func GetCachePath() string {
+	if dir := os.Getenv(SDKDirEnv); dir != "" {
+		return filepath.Join(dir, "cache.json")
+	}
 	return filepath.Join(GetCacheDir(), "cache.json")
 }
 
@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/joeblew999/goup-util/pkg/builders"
+)
+
+func TestStoreLifecycle(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	for _, id := range []string{"a", "b"} {
+		if err := store.Create(&Job{ID: id, Status: Queued, Args: []string{"build", "windows", "app"}, ArtifactDir: "app/.bin/windows"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	job, err := store.Claim()
+	if err != nil || job == nil || job.Status != Running {
+		t.Fatalf("Claim = %+v, %v", job, err)
+	}
+	if n, _ := store.Requeue(); n != 1 {
+		t.Errorf("Requeue = %d, want 1", n)
+	}
+	if ok, _ := store.Finish("b", Canceled, ""); !ok {
+		t.Error("Finish(b) did not update a queued job")
+	}
+	if ok, _ := store.Finish("b", Succeeded, ""); ok {
+		t.Error("Finish overwrote a canceled job")
+	}
+	if _, err := store.Get("missing"); err != ErrNotFound {
+		t.Errorf("Get(missing) = %v", err)
+	}
+}
+
+func TestDaemonRoundTrip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as goup-util")
+	}
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "goup-util")
+	os.WriteFile(fake, []byte("#!/bin/sh\nmkdir -p \"$3/.bin/$2\" && echo built > \"$3/.bin/$2/app.exe\"\n"), 0755)
+
+	srv, err := NewServer(Options{Dir: filepath.Join(dir, "data"), Executable: fake, Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { srv.Run(ctx); close(done) }()
+	defer func() { cancel(); <-done }()
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	src := filepath.Join(dir, "repo")
+	os.MkdirAll(filepath.Join(src, "app"), 0755)
+	os.WriteFile(filepath.Join(src, "app", "main.go"), []byte("package main"), 0644)
+
+	t.Setenv("TEST_DAEMON_TOKEN", "secret")
+	b := &builders.Builder{Name: "farm", Kind: builders.Daemon, URL: ts.URL, TokenEnv: "TEST_DAEMON_TOKEN", Platforms: []string{"windows"}}
+	err = b.Run(builders.Job{
+		Args:        []string{"build", "windows", "app"},
+		SourceDir:   src,
+		ArtifactDir: "app/.bin/windows",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(src, "app", ".bin", "windows", "app.exe")); string(got) != "built\n" {
+		t.Errorf("artifact = %q", got)
+	}
+
+	t.Setenv("TEST_DAEMON_TOKEN", "wrong")
+	if err := builders.Check(b); err != nil {
+		t.Errorf("health should not need a token: %v", err)
+	}
+	if err := b.Run(builders.Job{Args: []string{"build", "windows", "app"}, SourceDir: src, ArtifactDir: "app/.bin/windows"}); err == nil {
+		t.Error("job accepted with a wrong token")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, args := range [][]string{
+		{"utm", "exec", "vm"},
+		{"build", "windows", "/etc"},
+		{"build", "windows", "../other"},
+		{"build", "windows", `C:\app`},
+		{"build", "windows", "app", "--output=/etc"},
+		{"build", "windows", "app", "--output=../x"},
+		{"build", "windows", "app", "--output", "out"},
+		{"build", "android", "app", "--signkey=/home/ci/.android/release.keystore"},
+		{"build", "android", "app", "--signkey", "../keys/release.keystore"},
+		{"build", "windows", "app", "--build-remote=origin"},
+		{"build", "windows", "app", "-o", "out"},
+		{"build", "windows", "app", "--version"},
+		{"bundle", "macos", "app", "--sign=Developer ID Application: Host"},
+		{"bundle", "macos", "app", "--provisioning-profile=/etc/profile"},
+	} {
+		if err := validate(&Job{Args: args, ArtifactDir: "app/.bin"}); err == nil {
+			t.Errorf("validate(%q) accepted", args)
+		}
+	}
+	for _, args := range [][]string{
+		{"build", "windows", "app", "--no-hooks", "--version=1.2.0", "--force=true"},
+		{"build", "android", "app", "--build-number", "42", "--signkey=keys/release.keystore"},
+		{"bundle", "macos", "app", "--bundle-id", "com.example.app", "--entitlements=false"},
+	} {
+		if err := validate(&Job{Args: args, ArtifactDir: "app/.bin/windows"}); err != nil {
+			t.Errorf("validate(%q): %v", args, err)
+		}
+	}
+}
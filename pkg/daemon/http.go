@@ -0,0 +1,282 @@
+package daemon
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/builders"
+)
+
+// MaxSourceSize bounds an uploaded source archive.
+const MaxSourceSize = 1 << 30
+
+// commands clients may run; anything else could touch the daemon host.
+var commands = map[string]bool{"build": true, "bundle": true, "package": true}
+
+// flagKind is what a job flag takes: nothing, a value, or a path that
+// must stay inside the job's sources.
+type flagKind int
+
+const (
+	boolFlag flagKind = iota
+	valueFlag
+	pathFlag
+)
+
+// jobFlags are the flags of build, bundle and package that jobs may pass.
+// Left out are those that write outside the job (--output), reach the
+// host's git remotes (--build-remote), pick its signing identities (--sign,
+// --installer-sign) or start more builds (--builder, --via-docker).
+var jobFlags = map[string]flagKind{
+	"no-hooks":   boolFlag,
+	"force":      boolFlag,
+	"check":      boolFlag,
+	"skip-icons": boolFlag,
+	"yes":        boolFlag,
+	"json":       boolFlag,
+
+	"flavor":       valueFlag,
+	"env":          valueFlag,
+	"schemes":      valueFlag,
+	"queries":      valueFlag,
+	"version":      valueFlag,
+	"build-number": valueFlag,
+	"sbom":         valueFlag,
+	"signkey":      pathFlag,
+
+	"bundle-id":            valueFlag,
+	"entitlements":         boolFlag,
+	"publisher":            valueFlag,
+	"create-msix":          boolFlag,
+	"app-store":            boolFlag,
+	"category":             valueFlag,
+	"webview2":             valueFlag,
+	"webview2-source":      pathFlag,
+	"provisioning-profile": pathFlag,
+}
+
+// Handler serves the job API (see the builders package for the client):
+//
+//	GET    /api/health
+//	POST   /api/jobs                  submit (multipart: args, artifactDir, source)
+//	GET    /api/jobs[?status=&limit=]  list, newest first
+//	GET    /api/jobs/{id}             status
+//	GET    /api/jobs/{id}/log         combined output so far
+//	GET    /api/jobs/{id}/artifacts   tar.gz of artifactDir once succeeded
+//	DELETE /api/jobs/{id}             cancel
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/health", s.health)
+	mux.HandleFunc("POST /api/jobs", s.auth(s.submit))
+	mux.HandleFunc("GET /api/jobs", s.auth(s.list))
+	mux.HandleFunc("GET /api/jobs/{id}", s.auth(s.status))
+	mux.HandleFunc("GET /api/jobs/{id}/log", s.auth(s.log))
+	mux.HandleFunc("GET /api/jobs/{id}/artifacts", s.auth(s.artifacts))
+	mux.HandleFunc("DELETE /api/jobs/{id}", s.auth(s.cancel))
+	return mux
+}
+
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.Token != "" {
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(s.opts.Token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	queued, _ := s.store.Count(Queued)
+	running, _ := s.store.Count(Running)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":      "ok",
+		"queued":      queued,
+		"running":     running,
+		"concurrency": s.opts.Concurrency,
+	})
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxSourceSize)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, "bad upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	job := &Job{ID: newID(), Client: r.RemoteAddr}
+	if err := json.Unmarshal([]byte(r.FormValue("args")), &job.Args); err != nil {
+		http.Error(w, "args must be a JSON array", http.StatusBadRequest)
+		return
+	}
+	job.ArtifactDir = r.FormValue("artifactDir")
+	if err := validate(job); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("source")
+	if err != nil {
+		http.Error(w, "missing source archive", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	src, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := builders.Extract(src, s.SourceDir(job.ID)); err != nil {
+		os.RemoveAll(s.jobDir(job.ID))
+		http.Error(w, "bad source archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Submit(job); err != nil {
+		os.RemoveAll(s.jobDir(job.ID))
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrQueueFull) {
+			code = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	writeJSON(w, http.StatusCreated, job)
+}
+
+// validate rejects commands other than builds, flags jobs may not pass
+// and paths outside the job.
+func validate(job *Job) error {
+	if len(job.Args) == 0 || !commands[job.Args[0]] {
+		return fmt.Errorf("only build, bundle and package jobs are accepted")
+	}
+	if job.ArtifactDir == "" {
+		return fmt.Errorf("artifactDir is required")
+	}
+	paths := []string{job.ArtifactDir}
+	args := job.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			paths = append(paths, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		kind, ok := jobFlags[name]
+		if !ok || !strings.HasPrefix(arg, "--") {
+			return fmt.Errorf("flag %s is not accepted in jobs", arg)
+		}
+		if kind == boolFlag {
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return fmt.Errorf("flag --%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if kind == pathFlag {
+			paths = append(paths, value)
+		}
+	}
+	for _, p := range paths {
+		if path.IsAbs(p) || strings.HasPrefix(p, `\`) || strings.Contains(p, ":") || strings.Contains(p, "..") {
+			return fmt.Errorf("path %q must be relative to the sources", p)
+		}
+	}
+	return nil
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	jobs, err := s.store.List(r.URL.Query().Get("status"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []*Job{}
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Server) job(w http.ResponseWriter, r *http.Request) *Job {
+	job, err := s.store.Get(r.PathValue("id"))
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	return job
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	if job := s.job(w, r); job != nil {
+		writeJSON(w, http.StatusOK, job)
+	}
+}
+
+func (s *Server) log(w http.ResponseWriter, r *http.Request) {
+	job := s.job(w, r)
+	if job == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	http.ServeFile(w, r, s.LogPath(job.ID))
+}
+
+func (s *Server) artifacts(w http.ResponseWriter, r *http.Request) {
+	job := s.job(w, r)
+	if job == nil {
+		return
+	}
+	if job.Status != Succeeded {
+		http.Error(w, "job is "+job.Status, http.StatusConflict)
+		return
+	}
+	data, err := builders.Archive(s.ArtifactPath(job), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.tar.gz"`, job.ID))
+	w.Write(data)
+}
+
+func (s *Server) cancel(w http.ResponseWriter, r *http.Request) {
+	if s.job(w, r) == nil {
+		return
+	}
+	job, err := s.Cancel(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
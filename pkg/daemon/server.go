@@ -0,0 +1,262 @@
+package daemon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Options configures a Server.
+type Options struct {
+	Dir         string        // Data directory (database, sources, logs)
+	Concurrency int           // Jobs run at once (default 1)
+	MaxQueued   int           // Jobs waiting before submissions are refused (0 = no limit)
+	Retention   time.Duration // Finished jobs are deleted after this long (0 = keep)
+	Token       string        // Bearer token clients must send ("" = no auth)
+	Executable  string        // goup-util binary to run (default: this one)
+}
+
+// Server owns the job queue and its workers.
+type Server struct {
+	opts  Options
+	store *Store
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+	wake    chan struct{}
+}
+
+// NewServer opens the queue in opts.Dir.
+func NewServer(opts Options) (*Server, error) {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Executable == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find goup-util executable: %w", err)
+		}
+		opts.Executable = exe
+	}
+	if err := os.MkdirAll(filepath.Join(opts.Dir, "jobs"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	store, err := OpenStore(filepath.Join(opts.Dir, "jobs.db"))
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		opts:    opts,
+		store:   store,
+		running: map[string]context.CancelFunc{},
+		wake:    make(chan struct{}, 1),
+	}, nil
+}
+
+// Store returns the job database.
+func (s *Server) Store() *Store {
+	return s.store
+}
+
+// Close closes the database. Call it after the workers have stopped.
+func (s *Server) Close() error {
+	return s.store.Close()
+}
+
+// Run requeues jobs interrupted by a previous shutdown and runs the workers
+// until ctx is canceled. Jobs still running then stay "running" in the
+// database and are requeued on the next start.
+func (s *Server) Run(ctx context.Context) {
+	if n, err := s.store.Requeue(); err != nil {
+		log.Printf("requeue: %v", err)
+	} else if n > 0 {
+		log.Printf("requeued %d interrupted job(s)", n)
+	}
+	s.prune()
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.worker(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Server) worker(ctx context.Context) {
+	// Poll as a fallback; submissions normally wake a worker at once.
+	tick := time.NewTicker(5 * time.Second)
+	defer tick.Stop()
+	for {
+		job, err := s.store.Claim()
+		if err != nil {
+			log.Printf("claim: %v", err)
+		}
+		if job != nil {
+			s.run(ctx, job)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-tick.C:
+		}
+	}
+}
+
+func (s *Server) run(parent context.Context, job *Job) {
+	ctx, cancel := context.WithCancel(parent)
+	s.mu.Lock()
+	s.running[job.ID] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, job.ID)
+		s.mu.Unlock()
+		cancel()
+	}()
+
+	// A cancel that raced with Claim has already recorded the outcome.
+	if current, err := s.store.Get(job.ID); err == nil && current.Final() {
+		return
+	}
+
+	log.Printf("job %s: goup-util %v", job.ID, job.Args)
+	err := s.execute(ctx, job)
+	if parent.Err() != nil {
+		return // Shutting down; requeued on the next start
+	}
+
+	status, msg := Succeeded, ""
+	if err != nil {
+		status, msg = Failed, err.Error()
+	}
+	if ok, ferr := s.store.Finish(job.ID, status, msg); ferr != nil {
+		log.Printf("job %s: %v", job.ID, ferr)
+	} else if ok {
+		log.Printf("job %s: %s", job.ID, status)
+	}
+	s.prune()
+}
+
+func (s *Server) execute(ctx context.Context, job *Job) error {
+	logFile, err := os.Create(s.LogPath(job.ID))
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd := exec.CommandContext(ctx, s.opts.Executable, job.Args...)
+	cmd.Dir = s.SourceDir(job.ID)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.WaitDelay = 10 * time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("canceled")
+		}
+		return fmt.Errorf("goup-util %s failed: %w (see log)", job.Args[0], err)
+	}
+	if _, err := os.Stat(s.ArtifactPath(job)); err != nil {
+		return fmt.Errorf("build produced no %s", job.ArtifactDir)
+	}
+	return nil
+}
+
+// Submit queues a job whose sources are already in SourceDir(job.ID).
+func (s *Server) Submit(job *Job) error {
+	if s.opts.MaxQueued > 0 {
+		n, err := s.store.Count(Queued)
+		if err != nil {
+			return err
+		}
+		if n >= s.opts.MaxQueued {
+			return ErrQueueFull
+		}
+	}
+	job.Status = Queued
+	job.Created = time.Now()
+	if err := s.store.Create(job); err != nil {
+		return err
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// ErrQueueFull is returned by Submit when MaxQueued jobs are waiting.
+var ErrQueueFull = errors.New("queue is full")
+
+// Cancel stops a queued or running job.
+func (s *Server) Cancel(id string) (*Job, error) {
+	job, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Final() {
+		return job, nil
+	}
+	if _, err := s.store.Finish(id, Canceled, "canceled by client"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if cancel, ok := s.running[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+	return s.store.Get(id)
+}
+
+// prune deletes expired jobs and their files.
+func (s *Server) prune() {
+	if s.opts.Retention <= 0 {
+		return
+	}
+	ids, err := s.store.Prune(time.Now().Add(-s.opts.Retention))
+	if err != nil {
+		log.Printf("prune: %v", err)
+		return
+	}
+	for _, id := range ids {
+		os.RemoveAll(s.jobDir(id))
+	}
+}
+
+func (s *Server) jobDir(id string) string {
+	return filepath.Join(s.opts.Dir, "jobs", id)
+}
+
+// SourceDir is where a job's sources are unpacked.
+func (s *Server) SourceDir(id string) string {
+	return filepath.Join(s.jobDir(id), "src")
+}
+
+// LogPath is a job's combined output.
+func (s *Server) LogPath(id string) string {
+	return filepath.Join(s.jobDir(id), "build.log")
+}
+
+// ArtifactPath is the directory returned to the client.
+func (s *Server) ArtifactPath(job *Job) string {
+	return filepath.Join(s.SourceDir(job.ID), filepath.FromSlash(job.ArtifactDir))
+}
+
+// newID returns a short random job ID.
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102") + "-" + hex.EncodeToString(b)
+}
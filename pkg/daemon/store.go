@@ -0,0 +1,216 @@
+// Package daemon runs goup-util as a build service: clients (remote
+// builders of type "daemon") submit jobs over HTTP, a fixed number of
+// workers run them, and every job is kept in a SQLite database so status,
+// logs and artifacts survive restarts.
+//
+// Layout of the data directory:
+//
+//	jobs.db            job queue and history
+//	jobs/<id>/src/     unpacked sources; goup-util runs here
+//	jobs/<id>/build.log
+package daemon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go driver, so the daemon cross-compiles without cgo
+)
+
+// Job states.
+const (
+	Queued    = "queued"
+	Running   = "running"
+	Succeeded = "succeeded"
+	Failed    = "failed"
+	Canceled  = "canceled"
+)
+
+// ErrNotFound is returned for unknown job IDs.
+var ErrNotFound = errors.New("job not found")
+
+// Job is one queued or finished goup-util invocation.
+type Job struct {
+	ID          string    `json:"id"`
+	Status      string    `json:"status"`
+	Args        []string  `json:"args"`
+	ArtifactDir string    `json:"artifactDir"`
+	Client      string    `json:"client,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Created     time.Time `json:"created"`
+	Started     time.Time `json:"started,omitzero"`
+	Finished    time.Time `json:"finished,omitzero"`
+}
+
+// Final reports whether the job has finished.
+func (j *Job) Final() bool {
+	return j.Status == Succeeded || j.Status == Failed || j.Status == Canceled
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	id           TEXT PRIMARY KEY,
+	status       TEXT NOT NULL,
+	args         TEXT NOT NULL,
+	artifact_dir TEXT NOT NULL,
+	client       TEXT NOT NULL DEFAULT '',
+	error        TEXT NOT NULL DEFAULT '',
+	created_at   INTEGER NOT NULL,
+	started_at   INTEGER NOT NULL DEFAULT 0,
+	finished_at  INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS jobs_status ON jobs (status, created_at);
+`
+
+// Store persists jobs in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// OpenStore opens (creating if needed) the job database at path.
+func OpenStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	// One connection serializes writers; reads are quick.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Create inserts a new job.
+func (s *Store) Create(j *Job) error {
+	args, err := json.Marshal(j.Args)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO jobs (id, status, args, artifact_dir, client, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		j.ID, j.Status, string(args), j.ArtifactDir, j.Client, j.Created.UnixMilli())
+	return err
+}
+
+const columns = `id, status, args, artifact_dir, client, error, created_at, started_at, finished_at`
+
+func scanJob(row interface{ Scan(...any) error }) (*Job, error) {
+	var j Job
+	var args string
+	var created, started, finished int64
+	if err := row.Scan(&j.ID, &j.Status, &args, &j.ArtifactDir, &j.Client, &j.Error, &created, &started, &finished); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(args), &j.Args); err != nil {
+		return nil, fmt.Errorf("job %s: bad args: %w", j.ID, err)
+	}
+	j.Created = time.UnixMilli(created)
+	if started != 0 {
+		j.Started = time.UnixMilli(started)
+	}
+	if finished != 0 {
+		j.Finished = time.UnixMilli(finished)
+	}
+	return &j, nil
+}
+
+// Get returns a job by ID.
+func (s *Store) Get(id string) (*Job, error) {
+	j, err := scanJob(s.db.QueryRow(`SELECT `+columns+` FROM jobs WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return j, err
+}
+
+// List returns the newest jobs first, optionally only those in status.
+func (s *Store) List(status string, limit int) ([]*Job, error) {
+	rows, err := s.db.Query(`SELECT `+columns+` FROM jobs WHERE (? = '' OR status = ?) ORDER BY created_at DESC LIMIT ?`,
+		status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var jobs []*Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// Count returns the number of jobs in status.
+func (s *Store) Count(status string) (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM jobs WHERE status = ?`, status).Scan(&n)
+	return n, err
+}
+
+// Claim marks the oldest queued job as running and returns it, or nil when
+// the queue is empty.
+func (s *Store) Claim() (*Job, error) {
+	var id string
+	err := s.db.QueryRow(`UPDATE jobs SET status = ?, started_at = ?
+		WHERE id = (SELECT id FROM jobs WHERE status = ? ORDER BY created_at LIMIT 1)
+		RETURNING id`, Running, time.Now().UnixMilli(), Queued).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// Finish records the outcome of a job that has not finished yet. It
+// reports false when the job had already finished (e.g. was canceled).
+func (s *Store) Finish(id, status, errMsg string) (bool, error) {
+	res, err := s.db.Exec(`UPDATE jobs SET status = ?, error = ?, finished_at = ? WHERE id = ? AND status IN (?, ?)`,
+		status, errMsg, time.Now().UnixMilli(), id, Queued, Running)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// Requeue puts jobs left running by a stopped daemon back in the queue.
+func (s *Store) Requeue() (int, error) {
+	res, err := s.db.Exec(`UPDATE jobs SET status = ?, started_at = 0 WHERE status = ?`, Queued, Running)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// Prune deletes finished jobs older than before and returns their IDs.
+func (s *Store) Prune(before time.Time) ([]string, error) {
+	rows, err := s.db.Query(`DELETE FROM jobs WHERE status IN (?, ?, ?) AND finished_at < ? RETURNING id`,
+		Succeeded, Failed, Canceled, before.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,64 @@
+// Package devproxy lets a hybrid app's embedded server forward to a
+// frontend dev server (Vite, webpack, Next.js) during development, so
+// edits show up inside the Gio window with hot reload instead of after a
+// rebuild:
+//
+//	mux.Handle("/api/", api)
+//	mux.Handle("/", devproxy.Handler(embeddedSite))
+//
+// 'goup-util run --dev-proxy http://localhost:5173' (or "devProxy" in
+// app.json) sets $GOUP_DEV_PROXY for the launched app; without it Handler
+// returns the embedded site unchanged, so release builds never proxy.
+// WebSocket upgrades pass through, which is how HMR clients connect.
+package devproxy
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+)
+
+// Env holds the dev server URL.
+const Env = "GOUP_DEV_PROXY"
+
+// Handler returns a proxy to the dev server in $GOUP_DEV_PROXY, or
+// fallback when it is not set.
+func Handler(fallback http.Handler) http.Handler {
+	target := os.Getenv(Env)
+	if target == "" {
+		return fallback
+	}
+	h, err := New(target)
+	if err != nil {
+		log.Printf("devproxy: %v; serving the embedded files", err)
+		return fallback
+	}
+	log.Printf("devproxy: forwarding to %s", target)
+	return h
+}
+
+// New returns a reverse proxy to the dev server at target.
+func New(target string) (http.Handler, error) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("dev server %q must be an http:// or https:// URL", target)
+	}
+	origin := u.Scheme + "://" + u.Host
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(u)
+			// Dev servers refuse HMR sockets from origins other than
+			// their own (Vite since 6.0.9), and the page's origin is ours.
+			if r.In.Header.Get("Origin") != "" {
+				r.Out.Header.Set("Origin", origin)
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("Dev server %s is not reachable (%v).\nStart it (e.g. npm run dev), or run without --dev-proxy.", target, err),
+				http.StatusBadGateway)
+		},
+	}, nil
+}
@@ -0,0 +1,96 @@
+package devproxy
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	dev := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") == "websocket" {
+			if r.Header.Get("Origin") != "http://"+r.Host {
+				http.Error(w, "bad origin "+r.Header.Get("Origin"), http.StatusForbidden)
+				return
+			}
+			conn, rw, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+			rw.Flush()
+			line, _ := rw.ReadString('\n')
+			rw.WriteString("echo " + line)
+			rw.Flush()
+			return
+		}
+		io.WriteString(w, "dev "+r.URL.Path)
+	}))
+	defer dev.Close()
+
+	h, err := New(dev.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := httptest.NewServer(h)
+	defer app.Close()
+
+	resp, err := http.Get(app.URL + "/src/main.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "dev /src/main.ts" {
+		t.Errorf("body = %q", body)
+	}
+
+	// HMR: the upgrade and the frames after it pass through.
+	conn, err := net.Dial("tcp", strings.TrimPrefix(app.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET /hmr HTTP/1.1\r\nHost: app\r\nOrigin: "+app.URL+"\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("upgrade = %s: %s", resp.Status, body)
+	}
+	io.WriteString(conn, "update\n")
+	if line, _ := br.ReadString('\n'); line != "echo update\n" {
+		t.Errorf("frame = %q", line)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	fallback := http.NotFoundHandler()
+	t.Setenv(Env, "")
+	if Handler(fallback) == nil {
+		t.Fatal("nil handler")
+	}
+
+	t.Setenv(Env, "localhost:5173") // no scheme
+	w := httptest.NewRecorder()
+	Handler(fallback).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("bad URL did not fall back: %d", w.Code)
+	}
+
+	t.Setenv(Env, "http://127.0.0.1:1") // nothing listens
+	w = httptest.NewRecorder()
+	Handler(fallback).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusBadGateway || !strings.Contains(w.Body.String(), "npm run dev") {
+		t.Errorf("unreachable dev server = %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,320 @@
+// Package dlcache keeps SDK downloads in a content-addressed store under
+// the cache directory, so reinstalling an SDK, or installing it into
+// another GOUP_SDK_DIR, does not download it again:
+//
+//	<cache>/downloads/sha256/ab/ab12...ef    the archive, named by its SHA-256
+//	<cache>/downloads/index.json             download URL -> SHA-256
+//
+// Most catalog entries carry no checksum, so the index maps URLs to the
+// blobs they produced. Blobs are re-hashed before use; least recently used
+// ones are removed first by GC.
+package dlcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+)
+
+// Store is a download cache directory.
+type Store struct {
+	Dir string
+}
+
+// Open returns the store in goup-util's cache directory.
+func Open() *Store {
+	return &Store{Dir: filepath.Join(config.GetCacheDir(), "downloads")}
+}
+
+func (s *Store) blobPath(sum string) string {
+	return filepath.Join(s.Dir, "sha256", sum[:2], sum)
+}
+
+func (s *Store) indexPath() string {
+	return filepath.Join(s.Dir, "index.json")
+}
+
+// normalize strips the sha256: prefix catalog checksums use.
+func normalize(checksum string) string {
+	sum := strings.ToLower(strings.TrimPrefix(checksum, "sha256:"))
+	if len(sum) != sha256.Size*2 {
+		return ""
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return ""
+	}
+	return sum
+}
+
+func (s *Store) readIndex() map[string]string {
+	index := map[string]string{}
+	if data, err := os.ReadFile(s.indexPath()); err == nil {
+		json.Unmarshal(data, &index)
+	}
+	return index
+}
+
+func (s *Store) writeIndex(index map[string]string) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.indexPath())
+}
+
+// Lookup returns the cached archive for a download, found by checksum when
+// the catalog has one and by URL otherwise. A blob whose content no longer
+// matches its name is removed and reported as a miss.
+func (s *Store) Lookup(url, checksum string) (string, bool) {
+	sum := normalize(checksum)
+	if sum == "" {
+		sum = s.readIndex()[url]
+	}
+	if sum == "" {
+		return "", false
+	}
+	path := s.blobPath(sum)
+	got, err := HashFile(path)
+	if err != nil {
+		return "", false
+	}
+	if got != sum {
+		os.Remove(path)
+		return "", false
+	}
+	// Mark as recently used for GC
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return path, true
+}
+
+// Put moves the downloaded file src, whose SHA-256 is sum, into the store
+// and records url for it. It returns the blob path.
+func (s *Store) Put(url, src, sum string) (string, error) {
+	sum = normalize(sum)
+	if sum == "" {
+		return "", fmt.Errorf("invalid checksum")
+	}
+	path := s.blobPath(sum)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(src, path); err != nil {
+		// Temp files may be on another filesystem
+		if err := copyFile(src, path, true); err != nil {
+			return "", fmt.Errorf("failed to store download: %w", err)
+		}
+	}
+	if err := s.record(sum, url); err != nil {
+		return path, fmt.Errorf("failed to update download index: %w", err)
+	}
+	return path, nil
+}
+
+// record maps urls to sum in the index.
+func (s *Store) record(sum string, urls ...string) error {
+	index := s.readIndex()
+	changed := false
+	for _, url := range urls {
+		if url != "" && index[url] != sum {
+			index[url] = sum
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.writeIndex(index)
+}
+
+// Import copies the archive at src into the store, checking it against
+// sum, and records the URLs it was downloaded from. Bundles use it to seed
+// the cache on machines without network.
+func (s *Store) Import(src, sum string, urls []string) error {
+	got, err := HashFile(src)
+	if err != nil {
+		return err
+	}
+	if got != normalize(sum) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filepath.Base(src), sum, got)
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, ".import-*")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	if err := copyFile(src, tmp.Name(), false); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if _, err := s.Put("", tmp.Name(), got); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return s.record(got, urls...)
+}
+
+// copyFile copies src to dst, removing src afterwards when move is set.
+func copyFile(src, dst string, move bool) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".partial"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	if move {
+		os.Remove(src)
+	}
+	return nil
+}
+
+// HashFile returns the hex SHA-256 of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Entry is one cached archive.
+type Entry struct {
+	Sum  string
+	Path string
+	Size int64
+	Used time.Time
+	URLs []string
+}
+
+// Entries lists the cached archives, least recently used first.
+func (s *Store) Entries() ([]Entry, error) {
+	urls := map[string][]string{}
+	for url, sum := range s.readIndex() {
+		urls[sum] = append(urls[sum], url)
+	}
+	var entries []Entry
+	err := filepath.WalkDir(filepath.Join(s.Dir, "sha256"), func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() || normalize(d.Name()) == "" {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sum := d.Name()
+		slices.Sort(urls[sum])
+		entries = append(entries, Entry{Sum: sum, Path: path, Size: info.Size(), Used: info.ModTime(), URLs: urls[sum]})
+		return nil
+	})
+	slices.SortFunc(entries, func(a, b Entry) int { return a.Used.Compare(b.Used) })
+	return entries, err
+}
+
+// GC removes least recently used archives until the store is at most
+// maxSize bytes, and drops index entries for archives that are gone.
+func (s *Store) GC(maxSize int64) ([]Entry, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	var removed []Entry
+	for _, e := range entries {
+		if total <= maxSize {
+			break
+		}
+		if err := os.Remove(e.Path); err != nil {
+			return removed, err
+		}
+		total -= e.Size
+		removed = append(removed, e)
+	}
+
+	index := s.readIndex()
+	pruned := false
+	for url, sum := range index {
+		if _, err := os.Stat(s.blobPath(sum)); err != nil {
+			delete(index, url)
+			pruned = true
+		}
+	}
+	if pruned {
+		if err := s.writeIndex(index); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// ParseSize parses sizes like 10G, 500M or 1.5GB (powers of 1024).
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "IB"), "B")
+	multiplier := int64(1)
+	if n := len(value); n > 0 {
+		switch value[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			value = value[:n-1]
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 10G, 500M)", s)
+	}
+	return int64(number * float64(multiplier)), nil
+}
@@ -0,0 +1,110 @@
+package dlcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func download(t *testing.T, store *Store, url, content string) string {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "download")
+	os.WriteFile(src, []byte(content), 0644)
+	sum, err := HashFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put(url, src, sum); err != nil {
+		t.Fatal(err)
+	}
+	return sum
+}
+
+func TestPutLookup(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	sum := download(t, store, "https://example.com/jdk.tar.gz", "jdk")
+
+	if _, ok := store.Lookup("https://example.com/other.zip", ""); ok {
+		t.Error("unknown URL was found")
+	}
+	path, ok := store.Lookup("https://example.com/jdk.tar.gz", "")
+	if !ok {
+		t.Fatal("download not found by URL")
+	}
+	if _, ok := store.Lookup("https://mirror.example.com/jdk.tar.gz", "sha256:"+sum); !ok {
+		t.Error("download not found by checksum")
+	}
+
+	// A damaged blob is a miss, and is removed
+	os.WriteFile(path, []byte("truncated"), 0644)
+	if _, ok := store.Lookup("https://example.com/jdk.tar.gz", ""); ok {
+		t.Error("damaged download was reused")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("damaged download was kept")
+	}
+}
+
+func TestGC(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	old := download(t, store, "https://example.com/old", "0123456789")
+	download(t, store, "https://example.com/new", "9876543210")
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(store.blobPath(old), past, past)
+
+	removed, err := store.GC(15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Sum != old {
+		t.Fatalf("GC removed %+v, want the least recently used", removed)
+	}
+	if _, ok := store.readIndex()["https://example.com/old"]; ok {
+		t.Error("index still lists the removed download")
+	}
+	entries, _ := store.Entries()
+	if len(entries) != 1 || entries[0].URLs[0] != "https://example.com/new" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestImport(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	src := filepath.Join(t.TempDir(), "blob")
+	os.WriteFile(src, []byte("ndk"), 0644)
+	sum, _ := HashFile(src)
+
+	if err := store.Import(src, "sha256:"+sum[:63]+"0", nil); err == nil {
+		t.Error("imported a download with the wrong checksum")
+	}
+	if err := store.Import(src, sum, []string{"https://example.com/ndk.zip"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Error("Import removed its source")
+	}
+	if _, ok := store.Lookup("https://example.com/ndk.zip", ""); !ok {
+		t.Error("imported download not found")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{
+		"10G":   10 << 30,
+		"500M":  500 << 20,
+		"1.5GB": 3 << 29,
+		"2gib":  2 << 30,
+		"1024":  1024,
+		"0":     0,
+	} {
+		if got, err := ParseSize(in); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "G", "ten", "-1G"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) succeeded", in)
+		}
+	}
+}
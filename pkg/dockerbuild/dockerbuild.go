@@ -0,0 +1,218 @@
+// Package dockerbuild builds Windows executables inside a container, for
+// Linux and macOS hosts (and CI runners) that have Docker or Podman but no
+// Windows VM. The image carries gogio, a mingw-w64 toolchain for cgo and
+// osslsigncode for Authenticode signing, so the result is the same signed
+// .exe a Windows machine would produce.
+package dockerbuild
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+//go:embed Dockerfile.windows
+var windowsDockerfile []byte
+
+// Environment overrides.
+const (
+	EngineEnv = "GOUP_CONTAINER_ENGINE" // docker or podman
+	ImageEnv  = "GOUP_WINDOWS_IMAGE"    // Prebuilt image to use instead of building one
+)
+
+// TimestampURL is the RFC 3161 server used when signing.
+const TimestampURL = "http://timestamp.digicert.com"
+
+// Named volumes keeping the module and build caches between runs.
+const (
+	modCacheVolume   = "goup-util-gomod"
+	buildCacheVolume = "goup-util-gocache"
+)
+
+// Signing is the Authenticode certificate for the executable. Either Path
+// (a .pfx on the host, mounted read-only) or Data (streamed over stdin into
+// a tmpfs, never written to the host disk) is set.
+type Signing struct {
+	Path     string
+	Data     []byte
+	Password string
+	Name     string // Program name shown in the UAC prompt
+}
+
+// WindowsOptions describes one build.
+type WindowsOptions struct {
+	AppDir    string   // App module directory (absolute)
+	Output    string   // .exe to produce (absolute)
+	Icon      string   // Icon for gogio -icon (absolute, may be "")
+	GogioArgs []string // Extra gogio flags, e.g. -version
+	Signing   *Signing // nil for an unsigned build
+
+	Stdout, Stderr io.Writer // Build output (default os.Stdout and os.Stderr)
+}
+
+// Engine returns the container CLI to use.
+func Engine() (string, error) {
+	if e := os.Getenv(EngineEnv); e != "" {
+		return e, nil
+	}
+	for _, e := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(e); err == nil {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("--via-docker needs Docker or Podman (neither found in PATH)")
+}
+
+// Image returns the image tag for the embedded Dockerfile, building it on
+// first use. The tag changes with the Dockerfile, so edits rebuild it.
+func Image(engine string) (string, error) {
+	if img := os.Getenv(ImageEnv); img != "" {
+		return img, nil
+	}
+	sum := sha256.Sum256(windowsDockerfile)
+	image := "goup-util/windows-cross:" + hex.EncodeToString(sum[:6])
+	if exec.Command(engine, "image", "inspect", image).Run() == nil {
+		return image, nil
+	}
+
+	fmt.Printf("📦 Building %s (first run only)...\n", image)
+	// "-" reads the Dockerfile from stdin with no build context
+	cmd := exec.Command(engine, "build", "-t", image, "-")
+	cmd.Stdin = bytes.NewReader(windowsDockerfile)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to build %s: %w", image, err)
+	}
+	return image, nil
+}
+
+// Windows builds (and optionally signs) the app's .exe in a container.
+func Windows(opts WindowsOptions) error {
+	engine, err := Engine()
+	if err != nil {
+		return err
+	}
+	image, err := Image(engine)
+	if err != nil {
+		return err
+	}
+
+	// Mount the repository, so replace directives pointing at sibling
+	// modules resolve, and the output directory if it lives elsewhere.
+	root := repoRoot(opts.AppDir)
+	mounts := map[string]string{root: "/src"}
+	outDir := filepath.Dir(opts.Output)
+	if _, ok := containerPath(mounts, outDir); !ok {
+		mounts[outDir] = "/out"
+	}
+	workdir, _ := containerPath(mounts, opts.AppDir)
+	output, _ := containerPath(mounts, opts.Output)
+
+	gogio := []string{"gogio", "-target", "windows", "-o", output}
+	if opts.Icon != "" {
+		if icon, ok := containerPath(mounts, opts.Icon); ok {
+			gogio = append(gogio, "-icon", icon)
+		}
+	}
+	gogio = append(gogio, opts.GogioArgs...)
+	gogio = append(gogio, ".")
+
+	args := []string{"run", "--rm",
+		"-v", modCacheVolume + ":/go/pkg/mod",
+		"-v", buildCacheVolume + ":/root/.cache/go-build",
+		"-w", workdir,
+	}
+	for host, guest := range mounts {
+		args = append(args, "-v", host+":"+guest)
+	}
+
+	var script []string
+	cmd := exec.Command(engine)
+	cmd.Env = os.Environ()
+	if s := opts.Signing; s != nil {
+		cert := "/run/secrets/cert.pfx"
+		if s.Path != "" {
+			args = append(args, "-v", s.Path+":"+cert+":ro")
+		} else {
+			// Stream the certificate into memory inside the container.
+			args = append(args, "-i", "--tmpfs", "/run/secrets")
+			script = append(script, "cat > "+cert)
+			cmd.Stdin = bytes.NewReader(s.Data)
+		}
+		// Pass the password by name so it is not on the command line.
+		args = append(args, "-e", "WINDOWS_CERTIFICATE_PASSWORD")
+		cmd.Env = append(cmd.Env, "WINDOWS_CERTIFICATE_PASSWORD="+s.Password)
+		script = append(script, quoteArgs(gogio))
+		script = append(script, fmt.Sprintf(
+			`osslsigncode sign -pkcs12 %s -pass "$WINDOWS_CERTIFICATE_PASSWORD" -n %s -h sha256 -ts %s -in %s -out %s.signed && mv %s.signed %s`,
+			cert, shQuote(s.Name), TimestampURL, shQuote(output), shQuote(output), shQuote(output), shQuote(output)))
+	} else {
+		script = append(script, quoteArgs(gogio))
+	}
+
+	// Files written as root in the container should belong to the caller.
+	if runtime.GOOS == "linux" {
+		script = append(script, fmt.Sprintf("chown %d:%d %s", os.Getuid(), os.Getgid(), shQuote(output)))
+	}
+
+	args = append(args, image, "sh", "-ec", strings.Join(script, "\n"))
+	cmd.Args = append(cmd.Args, args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if opts.Stdout != nil {
+		cmd.Stdout = opts.Stdout
+	}
+	if opts.Stderr != nil {
+		cmd.Stderr = opts.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("container build failed: %w", err)
+	}
+	return nil
+}
+
+// repoRoot returns the git checkout containing dir, or dir itself.
+func repoRoot(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return dir
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// containerPath maps a host path to its path inside the container.
+func containerPath(mounts map[string]string, path string) (string, bool) {
+	for host, guest := range mounts {
+		rel, err := filepath.Rel(host, path)
+		if err == nil && rel == "." {
+			return guest, true
+		}
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return guest + "/" + filepath.ToSlash(rel), true
+	}
+	return "", false
+}
+
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
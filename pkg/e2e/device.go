@@ -0,0 +1,134 @@
+package e2e
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/adb"
+	"github.com/joeblew999/goup-util/pkg/simctl"
+)
+
+// Device is what scenarios drive.
+type Device interface {
+	Install(path string) error
+	Launch(appID string) error
+	Stop(appID string) error
+	OpenURL(url string) error
+	Tap(x, y int) error
+	Type(text string) error
+	Screenshot(path string) error
+	// Elements returns the accessibility elements on screen.
+	Elements() ([]Element, error)
+}
+
+// Element is an accessibility node with its screen bounds in pixels.
+type Element struct {
+	Text   string
+	Bounds [4]int // Left, top, right, bottom
+}
+
+// Center is where a tap on the element lands.
+func (e Element) Center() (int, int) {
+	return (e.Bounds[0] + e.Bounds[2]) / 2, (e.Bounds[1] + e.Bounds[3]) / 2
+}
+
+// Find returns the first element whose text contains text.
+func Find(elements []Element, text string) (Element, bool) {
+	for _, e := range elements {
+		if strings.Contains(e.Text, text) {
+			return e, true
+		}
+	}
+	return Element{}, false
+}
+
+// NewDevice returns the device for platform: android or ios-simulator.
+func NewDevice(platform string) (Device, error) {
+	switch platform {
+	case "android":
+		client := adb.New()
+		if !client.Available() {
+			return nil, fmt.Errorf("adb not found at %s\nInstall with: goup-util install platform-tools", client.ADBPath())
+		}
+		if !client.HasDevice() {
+			return nil, fmt.Errorf("no Android device connected. Start an emulator with: goup-util android emulator start <avd-name>")
+		}
+		return &android{client}, nil
+	case "ios-simulator":
+		client := simctl.New()
+		if !client.Available() {
+			return nil, fmt.Errorf("xcrun simctl not available\nInstall Xcode command line tools: xcode-select --install")
+		}
+		if !client.HasBooted() {
+			return nil, fmt.Errorf("no simulator booted. Boot one with: goup-util ios boot \"iPhone 16\"")
+		}
+		return &simulator{client}, nil
+	}
+	return nil, fmt.Errorf("e2e runs on android or ios-simulator, not %s", platform)
+}
+
+type android struct{ *adb.Client }
+
+func (d *android) Stop(appID string) error { return d.ForceStop(appID) }
+func (d *android) Type(text string) error  { return d.InputText(text) }
+func (d *android) Elements() ([]Element, error) {
+	dump, err := d.DumpUI()
+	if err != nil {
+		return nil, err
+	}
+	return parseUIAutomator(dump)
+}
+
+// parseUIAutomator reads the text and content-desc of every node in a
+// uiautomator dump. Gio exposes its semantic labels as content-desc.
+func parseUIAutomator(dump string) ([]Element, error) {
+	var elements []Element
+	dec := xml.NewDecoder(strings.NewReader(dump))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return elements, nil
+			}
+			return nil, fmt.Errorf("invalid uiautomator dump: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "node" {
+			continue
+		}
+		var e Element
+		var texts []string
+		for _, a := range start.Attr {
+			switch a.Name.Local {
+			case "text", "content-desc":
+				if a.Value != "" {
+					texts = append(texts, a.Value)
+				}
+			case "bounds":
+				fmt.Sscanf(a.Value, "[%d,%d][%d,%d]", &e.Bounds[0], &e.Bounds[1], &e.Bounds[2], &e.Bounds[3])
+			}
+		}
+		if len(texts) > 0 {
+			e.Text = strings.Join(texts, " ")
+			elements = append(elements, e)
+		}
+	}
+}
+
+// simulator drives the iOS simulator. simctl has no input events, so taps,
+// typing and accessibility queries are not available; deep links and
+// screenshots are.
+type simulator struct{ *simctl.Client }
+
+func (d *simulator) Stop(appID string) error { return d.Terminate(appID) }
+func (d *simulator) Tap(x, y int) error {
+	return fmt.Errorf("the iOS simulator cannot be tapped from the command line; drive the app with deep links")
+}
+func (d *simulator) Type(text string) error {
+	return fmt.Errorf("the iOS simulator cannot be typed into from the command line; drive the app with deep links")
+}
+func (d *simulator) Elements() ([]Element, error) {
+	return nil, fmt.Errorf("accessibility queries are not available on the iOS simulator; assert with screenshots")
+}
@@ -0,0 +1,228 @@
+// Package e2e runs end-to-end scenarios against a built app on an Android
+// emulator or device or the iOS simulator. Scenarios are YAML files in
+// Given/When/Then form:
+//
+//	feature: Settings
+//	app: ../examples/hybrid-dashboard
+//	scenarios:
+//	  - name: deep link opens settings
+//	    given: [launch]
+//	    when:
+//	      - open: myapp://settings
+//	      - tap: {text: Dark mode}
+//	    then:
+//	      - see: Settings
+//	      - screenshot: {name: settings, tolerance: 0.02}
+//
+// Each feature's app is built and installed once; each scenario starts
+// from a fresh launch. Results are written as JUnit XML for CI.
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Step kinds.
+const (
+	Launch     = "launch"     // Stop and start the app
+	Open       = "open"       // Open a deep link
+	Wait       = "wait"       // Sleep for a duration
+	Tap        = "tap"        // Tap coordinates or an element by text
+	Type       = "type"       // Type into the focused field
+	See        = "see"        // An accessibility element shows the text
+	NotSee     = "not-see"    // No accessibility element shows the text
+	Screenshot = "screenshot" // Capture, and compare with a baseline
+)
+
+var kinds = []string{Launch, Open, Wait, Tap, Type, See, NotSee, Screenshot}
+
+// Step is one action or assertion. In YAML it is a bare kind ("launch")
+// or a single-key map whose value is a string or, for tap and
+// screenshot, a map.
+type Step struct {
+	Kind  string `yaml:"-"`
+	Value string `yaml:"-"` // URL, duration, text or screenshot name
+
+	// tap
+	X    int    `yaml:"x"`
+	Y    int    `yaml:"y"`
+	Text string `yaml:"text"`
+
+	// screenshot
+	Name      string  `yaml:"name"`
+	Baseline  string  `yaml:"baseline"`  // Defaults to baselines/<name>.png next to the feature
+	Tolerance float64 `yaml:"tolerance"` // Fraction of pixels allowed to differ
+}
+
+// UnmarshalYAML decodes the short and long step forms.
+func (s *Step) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		s.Kind = node.Value
+		return s.validate(node.Line)
+	}
+	if node.Kind != yaml.MappingNode || len(node.Content) != 2 {
+		return fmt.Errorf("line %d: a step is a kind or a map with one key", node.Line)
+	}
+	s.Kind = node.Content[0].Value
+	value := node.Content[1]
+	if value.Kind == yaml.MappingNode {
+		type plain Step
+		if err := value.Decode((*plain)(s)); err != nil {
+			return fmt.Errorf("line %d: %w", value.Line, err)
+		}
+		s.Value = s.Text
+		if s.Kind == Screenshot {
+			s.Value = s.Name
+		}
+	} else if err := value.Decode(&s.Value); err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	return s.validate(node.Line)
+}
+
+func (s *Step) validate(line int) error {
+	found := false
+	for _, k := range kinds {
+		found = found || k == s.Kind
+	}
+	switch {
+	case !found:
+		return fmt.Errorf("line %d: unknown step %q (use %s)", line, s.Kind, strings.Join(kinds, ", "))
+	case s.Kind != Launch && s.Kind != Tap && s.Value == "":
+		return fmt.Errorf("line %d: %s needs a value", line, s.Kind)
+	case s.Kind == Tap && s.Value == "" && s.X == 0 && s.Y == 0:
+		return fmt.Errorf("line %d: tap needs x and y or text", line)
+	case s.Kind == Wait:
+		if _, err := time.ParseDuration(s.Value); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return nil
+}
+
+func (s Step) String() string {
+	switch {
+	case s.Kind == Tap && s.Value == "":
+		return fmt.Sprintf("tap %d,%d", s.X, s.Y)
+	case s.Value != "":
+		return s.Kind + " " + s.Value
+	}
+	return s.Kind
+}
+
+// Scenario is a named Given/When/Then sequence.
+type Scenario struct {
+	Name  string `yaml:"name"`
+	Given []Step `yaml:"given"`
+	When  []Step `yaml:"when"`
+	Then  []Step `yaml:"then"`
+}
+
+// Steps returns the given, when and then steps in order.
+func (s *Scenario) Steps() []Step {
+	steps := append([]Step{}, s.Given...)
+	steps = append(steps, s.When...)
+	return append(steps, s.Then...)
+}
+
+// Feature is a scenario file.
+type Feature struct {
+	Name      string     `yaml:"feature"`
+	App       string     `yaml:"app"`      // App directory, relative to the file
+	AppID     string     `yaml:"appId"`    // Package or bundle ID; defaults to gogio's
+	Platform  string     `yaml:"platform"` // android or ios-simulator
+	Scenarios []Scenario `yaml:"scenarios"`
+
+	Path string `yaml:"-"`
+}
+
+// Dir is the directory holding the feature file.
+func (f *Feature) Dir() string {
+	return filepath.Dir(f.Path)
+}
+
+// AppDir is the app directory resolved against the feature file.
+func (f *Feature) AppDir() string {
+	if filepath.IsAbs(f.App) {
+		return f.App
+	}
+	return filepath.Join(f.Dir(), f.App)
+}
+
+// Parse decodes a feature file; path is used for relative paths and errors.
+func Parse(data []byte, path string) (*Feature, error) {
+	var f Feature
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	f.Path = path
+	if f.Name == "" {
+		f.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if f.App == "" {
+		return nil, fmt.Errorf("%s: app is required", path)
+	}
+	if len(f.Scenarios) == 0 {
+		return nil, fmt.Errorf("%s has no scenarios", path)
+	}
+	for i, sc := range f.Scenarios {
+		if sc.Name == "" {
+			f.Scenarios[i].Name = fmt.Sprintf("scenario %d", i+1)
+		}
+	}
+	return &f, nil
+}
+
+// Load reads the feature at path, or every .yaml and .yml feature in the
+// directory tree at path, sorted by path.
+func Load(path string) ([]*Feature, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	if info.IsDir() {
+		err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && d.Name() == "baselines" {
+				return filepath.SkipDir
+			}
+			if ext := filepath.Ext(p); !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	} else {
+		files = []string{path}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no scenario files (.yaml, .yml) in %s", path)
+	}
+
+	var features []*Feature
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, err := Parse(data, file)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, f)
+	}
+	return features, nil
+}
@@ -0,0 +1,169 @@
+package e2e
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const feature = `
+feature: Settings
+app: ../app
+scenarios:
+  - name: deep link
+    given: [launch]
+    when:
+      - open: demo://settings
+      - tap: {text: Dark mode}
+      - tap: {x: 10, y: 20}
+    then:
+      - see: Settings
+      - screenshot: home
+  - name: missing text
+    given: [launch]
+    then:
+      - see: Nowhere
+`
+
+// fakeDevice records calls and shows a fixed screen.
+type fakeDevice struct {
+	calls    []string
+	elements []Element
+	color    color.Color
+}
+
+func (d *fakeDevice) Install(path string) error {
+	d.calls = append(d.calls, "install "+path)
+	return nil
+}
+func (d *fakeDevice) Launch(id string) error   { d.calls = append(d.calls, "launch "+id); return nil }
+func (d *fakeDevice) Stop(id string) error     { return nil }
+func (d *fakeDevice) OpenURL(url string) error { d.calls = append(d.calls, "open "+url); return nil }
+func (d *fakeDevice) Type(text string) error   { return nil }
+func (d *fakeDevice) Tap(x, y int) error {
+	d.calls = append(d.calls, fmt.Sprintf("tap %d,%d", x, y))
+	return nil
+}
+func (d *fakeDevice) Elements() ([]Element, error) { return d.elements, nil }
+func (d *fakeDevice) Screenshot(path string) error {
+	return writePNG(path, d.color)
+}
+
+func writePNG(path string, c color.Color) error {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	img.Set(0, 0, color.Black)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "tests"), 0755)
+	os.WriteFile(filepath.Join(dir, "tests", "settings.yaml"), []byte(feature), 0644)
+	features, err := Load(filepath.Join(dir, "tests"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dev := &fakeDevice{
+		elements: []Element{{Text: "Dark mode", Bounds: [4]int{100, 200, 300, 400}}, {Text: "Settings page"}},
+		color:    color.White,
+	}
+	opts := Options{
+		OutDir:  filepath.Join(dir, "out"),
+		Timeout: time.Millisecond,
+		Out:     &bytes.Buffer{},
+		Prepare: func(platform, appDir string) (string, string, error) {
+			if platform != "android" || appDir != filepath.Join(dir, "app") {
+				t.Errorf("prepare %s %s", platform, appDir)
+			}
+			return "app.apk", "localhost.demo", nil
+		},
+		Device: func(string) (Device, error) { return dev, nil },
+	}
+	suites := Run(features, opts)
+
+	want := "install app.apk|launch localhost.demo|open demo://settings|tap 200,300|tap 10,20|launch localhost.demo"
+	if got := strings.Join(dev.calls, "|"); got != want {
+		t.Errorf("calls = %s", got)
+	}
+	if len(suites) != 1 || len(suites[0].Cases) != 2 || !Failed(suites) {
+		t.Fatalf("suites = %+v", suites)
+	}
+	if c := suites[0].Cases[0]; c.Failure != "" {
+		t.Errorf("deep link failed: %s", c.Failure)
+	}
+	if c := suites[0].Cases[1]; !strings.Contains(c.Failure, `"Nowhere" not on screen`) || c.Capture == "" {
+		t.Errorf("missing text = %+v", c)
+	}
+	baseline := filepath.Join(dir, "tests", "baselines", "home.png")
+	if _, err := os.Stat(baseline); err != nil {
+		t.Fatal("baseline not created")
+	}
+
+	// A changed screen fails against the baseline
+	dev.color = color.NRGBA{R: 255, A: 255}
+	suites = Run(features, opts)
+	if c := suites[0].Cases[0]; !strings.Contains(c.Failure, "93.8% of pixels differ") {
+		t.Errorf("changed screen = %q", c.Failure)
+	}
+
+	var junit bytes.Buffer
+	if err := WriteJUnit(&junit, suites); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`<testsuites tests="2" failures="2" errors="0">`, `<testcase name="deep link" classname="Settings"`, `<failure message=`} {
+		if !strings.Contains(junit.String(), s) {
+			t.Errorf("JUnit missing %s:\n%s", s, junit.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"app: x\nscenarios:\n  - given: [jump]\n",
+		"app: x\nscenarios:\n  - given: [open]\n",
+		"app: x\nscenarios:\n  - given: [{wait: soon}]\n",
+		"app: x\nscenarios:\n  - given: [tap]\n",
+		"scenarios:\n  - given: [launch]\n",
+	} {
+		if _, err := Parse([]byte(src), "f.yaml"); err == nil {
+			t.Errorf("no error for %q", src)
+		}
+	}
+}
+
+func TestParseUIAutomator(t *testing.T) {
+	dump := `<?xml version='1.0' encoding='UTF-8' standalone='yes' ?><hierarchy rotation="0">
+<node index="0" text="" content-desc="" bounds="[0,0][1080,2400]">
+<node index="1" text="" content-desc="Save" bounds="[40,100][240,180]" />
+<node index="2" text="Name" content-desc="" bounds="[0,200][1080,300]" />
+</node></hierarchy>`
+	elements, err := parseUIAutomator(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok := Find(elements, "Save")
+	if len(elements) != 2 || !ok {
+		t.Fatalf("elements = %+v", elements)
+	}
+	if x, y := e.Center(); x != 140 || y != 140 {
+		t.Errorf("center = %d,%d", x, y)
+	}
+}
@@ -0,0 +1,61 @@
+package e2e
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+)
+
+// pixelThreshold is how far apart a channel may be (0-255) before a
+// pixel counts as different; it absorbs antialiasing and compression.
+const pixelThreshold = 16
+
+// Diff returns the fraction of pixels that differ between two PNGs.
+func Diff(a, b string) (float64, error) {
+	imgA, err := readPNG(a)
+	if err != nil {
+		return 0, err
+	}
+	imgB, err := readPNG(b)
+	if err != nil {
+		return 0, err
+	}
+	ba, bb := imgA.Bounds(), imgB.Bounds()
+	if ba.Dx() != bb.Dx() || ba.Dy() != bb.Dy() {
+		return 1, fmt.Errorf("size %dx%d differs from baseline %dx%d", ba.Dx(), ba.Dy(), bb.Dx(), bb.Dy())
+	}
+
+	differ := 0
+	for y := 0; y < ba.Dy(); y++ {
+		for x := 0; x < ba.Dx(); x++ {
+			r1, g1, b1, a1 := imgA.At(ba.Min.X+x, ba.Min.Y+y).RGBA()
+			r2, g2, b2, a2 := imgB.At(bb.Min.X+x, bb.Min.Y+y).RGBA()
+			if far(r1, r2) || far(g1, g2) || far(b1, b2) || far(a1, a2) {
+				differ++
+			}
+		}
+	}
+	return float64(differ) / float64(ba.Dx()*ba.Dy()), nil
+}
+
+func far(a, b uint32) bool {
+	a, b = a>>8, b>>8
+	if a > b {
+		return a-b > pixelThreshold
+	}
+	return b-a > pixelThreshold
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,317 @@
+package e2e
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Options control a run.
+type Options struct {
+	Platform        string        // Overrides the features' platform
+	OutDir          string        // Screenshots and failure captures
+	UpdateBaselines bool          // Replace baselines with new screenshots
+	Timeout         time.Duration // How long see and tap wait for an element
+	Out             io.Writer
+
+	// Prepare builds the app in appDir for platform and returns the
+	// artifact to install and the app's package or bundle ID.
+	Prepare func(platform, appDir string) (artifact, appID string, err error)
+	// Device connects to the device for platform.
+	Device func(platform string) (Device, error)
+}
+
+// Case is the result of one scenario.
+type Case struct {
+	Name     string
+	Duration time.Duration
+	Failure  string // Empty when the scenario passed
+	Capture  string // Screenshot taken on failure
+}
+
+// Suite is the result of one feature.
+type Suite struct {
+	Name  string
+	Cases []Case
+	Error string // Set when the feature could not run at all
+}
+
+// Failed reports whether any suite failed.
+func Failed(suites []Suite) bool {
+	for _, s := range suites {
+		if s.Error != "" {
+			return true
+		}
+		for _, c := range s.Cases {
+			if c.Failure != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Run runs every feature and returns its results. Failures are recorded
+// in the results rather than returned.
+func Run(features []*Feature, opts Options) []Suite {
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	var suites []Suite
+	for _, f := range features {
+		suites = append(suites, runFeature(f, opts))
+	}
+	return suites
+}
+
+func runFeature(f *Feature, opts Options) Suite {
+	suite := Suite{Name: f.Name}
+	platform := opts.Platform
+	if platform == "" {
+		platform = f.Platform
+	}
+	if platform == "" {
+		platform = "android"
+	}
+	fmt.Fprintf(opts.Out, "📦 Feature: %s (%s)\n", f.Name, platform)
+
+	fail := func(err error) Suite {
+		suite.Error = err.Error()
+		fmt.Fprintf(opts.Out, "❌ %v\n", err)
+		return suite
+	}
+	artifact, appID, err := opts.Prepare(platform, f.AppDir())
+	if err != nil {
+		return fail(fmt.Errorf("build failed: %w", err))
+	}
+	if f.AppID != "" {
+		appID = f.AppID
+	}
+	dev, err := opts.Device(platform)
+	if err != nil {
+		return fail(err)
+	}
+	if err := dev.Install(artifact); err != nil {
+		return fail(fmt.Errorf("install failed: %w", err))
+	}
+
+	for _, sc := range f.Scenarios {
+		started := time.Now()
+		r := &runner{dev: dev, appID: appID, feature: f, opts: opts}
+		err := r.scenario(sc)
+		c := Case{Name: sc.Name, Duration: time.Since(started)}
+		if err != nil {
+			c.Failure = err.Error()
+			c.Capture = filepath.Join(opts.OutDir, "failures", slug(f.Name)+"-"+slug(sc.Name)+".png")
+			if os.MkdirAll(filepath.Dir(c.Capture), 0755) != nil || dev.Screenshot(c.Capture) != nil {
+				c.Capture = ""
+			}
+			fmt.Fprintf(opts.Out, "  ❌ %s: %v\n", sc.Name, err)
+		} else {
+			fmt.Fprintf(opts.Out, "  ✓ %s (%.1fs)\n", sc.Name, c.Duration.Seconds())
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	return suite
+}
+
+type runner struct {
+	dev     Device
+	appID   string
+	feature *Feature
+	opts    Options
+}
+
+func (r *runner) scenario(sc Scenario) error {
+	for _, step := range sc.Steps() {
+		if err := r.step(step); err != nil {
+			return fmt.Errorf("%s: %w", step, err)
+		}
+	}
+	return nil
+}
+
+func (r *runner) step(s Step) error {
+	switch s.Kind {
+	case Launch:
+		r.dev.Stop(r.appID) // Not running is fine
+		return r.dev.Launch(r.appID)
+	case Open:
+		return r.dev.OpenURL(s.Value)
+	case Wait:
+		d, _ := time.ParseDuration(s.Value)
+		time.Sleep(d)
+		return nil
+	case Type:
+		return r.dev.Type(s.Value)
+	case Tap:
+		if s.Value == "" {
+			return r.dev.Tap(s.X, s.Y)
+		}
+		e, err := r.waitFor(s.Value, true)
+		if err != nil {
+			return err
+		}
+		return r.dev.Tap(e.Center())
+	case See:
+		_, err := r.waitFor(s.Value, true)
+		return err
+	case NotSee:
+		_, err := r.waitFor(s.Value, false)
+		return err
+	case Screenshot:
+		return r.screenshot(s)
+	}
+	return fmt.Errorf("unknown step")
+}
+
+// waitFor polls the accessibility tree until text is shown (or, with
+// present false, gone) or the timeout passes.
+func (r *runner) waitFor(text string, present bool) (Element, error) {
+	deadline := time.Now().Add(r.opts.Timeout)
+	for {
+		elements, err := r.dev.Elements()
+		if err != nil {
+			return Element{}, err
+		}
+		e, ok := Find(elements, text)
+		if ok == present {
+			return e, nil
+		}
+		if time.Now().After(deadline) {
+			if present {
+				return Element{}, fmt.Errorf("%q not on screen after %s", text, r.opts.Timeout)
+			}
+			return Element{}, fmt.Errorf("%q still on screen after %s", text, r.opts.Timeout)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// screenshot captures the screen and compares it with the baseline,
+// creating the baseline when there is none.
+func (r *runner) screenshot(s Step) error {
+	shot := filepath.Join(r.opts.OutDir, "screenshots", slug(r.feature.Name)+"-"+slug(s.Value)+".png")
+	if err := os.MkdirAll(filepath.Dir(shot), 0755); err != nil {
+		return err
+	}
+	if err := r.dev.Screenshot(shot); err != nil {
+		return err
+	}
+	baseline := s.Baseline
+	if baseline == "" {
+		baseline = filepath.Join("baselines", slug(s.Value)+".png")
+	}
+	if !filepath.IsAbs(baseline) {
+		baseline = filepath.Join(r.feature.Dir(), baseline)
+	}
+
+	if _, err := os.Stat(baseline); err != nil || r.opts.UpdateBaselines {
+		if err := os.MkdirAll(filepath.Dir(baseline), 0755); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(shot)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.opts.Out, "    📸 baseline saved: %s\n", baseline)
+		return os.WriteFile(baseline, data, 0644)
+	}
+	diff, err := Diff(shot, baseline)
+	if err != nil {
+		return err
+	}
+	if diff > s.Tolerance {
+		return fmt.Errorf("%.1f%% of pixels differ from %s (tolerance %.1f%%); new screenshot: %s", diff*100, baseline, s.Tolerance*100, shot)
+	}
+	return nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(s string) string {
+	return strings.Trim(unsafeChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// JUnit XML, as read by CI systems.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Tests   int          `xml:"tests,attr"`
+	Fails   int          `xml:"failures,attr"`
+	Errors  int          `xml:"errors,attr"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name   string      `xml:"name,attr"`
+	Tests  int         `xml:"tests,attr"`
+	Fails  int         `xml:"failures,attr"`
+	Errors int         `xml:"errors,attr"`
+	Time   string      `xml:"time,attr"`
+	Cases  []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as JUnit XML. A feature that could not
+// run is reported as one errored test case.
+func WriteJUnit(w io.Writer, suites []Suite) error {
+	var out junitSuites
+	for _, s := range suites {
+		js := junitSuite{Name: s.Name}
+		var total time.Duration
+		if s.Error != "" {
+			js.Errors = 1
+			js.Cases = append(js.Cases, junitCase{Name: "setup", Classname: s.Name, Time: "0",
+				Error: &junitMessage{Message: s.Error, Text: s.Error}})
+		}
+		for _, c := range s.Cases {
+			total += c.Duration
+			jc := junitCase{Name: c.Name, Classname: s.Name, Time: fmt.Sprintf("%.3f", c.Duration.Seconds())}
+			if c.Failure != "" {
+				js.Fails++
+				jc.Failure = &junitMessage{Message: c.Failure, Text: c.Failure}
+				if c.Capture != "" {
+					jc.SystemOut = "[[ATTACHMENT|" + c.Capture + "]]"
+				}
+			}
+			js.Cases = append(js.Cases, jc)
+		}
+		js.Tests = len(js.Cases)
+		js.Time = fmt.Sprintf("%.3f", total.Seconds())
+		out.Tests += js.Tests
+		out.Fails += js.Fails
+		out.Errors += js.Errors
+		out.Suites = append(out.Suites, js)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,228 @@
+// Package examples finds the example apps that ship in the goup-util
+// repository and prepares them for building, so they can be tried without
+// knowing the repository layout.
+//
+// The examples are separate Go modules and cannot be embedded in the
+// binary. They are read from a goup-util checkout when run inside one, and
+// otherwise from a shallow clone kept in the cache directory.
+package examples
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/giocompat"
+	"golang.org/x/mod/modfile"
+)
+
+// Module is the goup-util module path, used to recognise a checkout.
+const Module = "github.com/joeblew999/goup-util"
+
+// RepoURL is cloned when no checkout is found.
+const RepoURL = "https://" + Module + ".git"
+
+// descriptions says what each bundled example shows; examples missing
+// here fall back to their README heading.
+var descriptions = map[string]string{
+	"gio-basic":            "Plain Gio app: a large scrolling grid of coloured cells",
+	"gio-plugin-hyperlink": "Opens links in the system browser with the hyperlink plugin",
+	"gio-plugin-webviewer": "Webviewer shell: native WebView tabs configured by app.json",
+	"hybrid-dashboard":     "Go UI plus an embedded web server shown in a native WebView",
+}
+
+// Example is a bundled example app.
+type Example struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Dir         string `json:"dir"`
+}
+
+// Hooks replaced in tests.
+var (
+	gitClone = func(url, dir string) error {
+		cmd := exec.Command("git", "clone", "--depth", "1", url, dir)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		return cmd.Run()
+	}
+	modTidy = func(dir string) error {
+		cmd := exec.Command("go", "mod", "tidy")
+		cmd.Dir = dir
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		return cmd.Run()
+	}
+)
+
+// FindRoot returns the examples directory of the goup-util checkout
+// containing dir, or "" when dir is not inside one.
+func FindRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		root := filepath.Join(dir, "examples")
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil && modfile.ModulePath(data) == Module {
+			if info, err := os.Stat(root); err == nil && info.IsDir() {
+				return root
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Root returns the examples directory to use: the checkout containing the
+// working directory, else a clone in cacheDir, made on first use.
+func Root(cacheDir string) (string, error) {
+	if root := FindRoot("."); root != "" {
+		return root, nil
+	}
+	repo := filepath.Join(cacheDir, "examples", "goup-util")
+	if _, err := os.Stat(filepath.Join(repo, "examples")); err == nil {
+		return filepath.Join(repo, "examples"), nil
+	}
+	fmt.Printf("📦 Fetching examples from %s...\n", RepoURL)
+	if err := os.MkdirAll(filepath.Dir(repo), 0755); err != nil {
+		return "", err
+	}
+	if err := gitClone(RepoURL, repo); err != nil {
+		os.RemoveAll(repo)
+		return "", fmt.Errorf("failed to clone %s: %w", RepoURL, err)
+	}
+	return filepath.Join(repo, "examples"), nil
+}
+
+// List returns the examples in root, sorted by name.
+func List(root string) ([]Example, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read examples: %w", err)
+	}
+	var list []Example
+	for _, e := range entries {
+		dir := filepath.Join(root, e.Name())
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+			continue
+		}
+		desc := descriptions[e.Name()]
+		if desc == "" {
+			desc = readmeTitle(dir)
+		}
+		list = append(list, Example{Name: e.Name(), Description: desc, Dir: dir})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Get returns the named example.
+func Get(root, name string) (*Example, error) {
+	list, err := List(root)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ex := range list {
+		if ex.Name == name {
+			return &ex, nil
+		}
+		names = append(names, ex.Name)
+	}
+	return nil, fmt.Errorf("unknown example %q. Available: %s", name, strings.Join(names, ", "))
+}
+
+func readmeTitle(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		}
+	}
+	return ""
+}
+
+// Prepare returns a directory the example builds in. The examples replace
+// Gio with local checkouts under .src for development; when those are
+// absent the example is copied to cacheDir and built against the released
+// modules its go.mod requires instead.
+func Prepare(ex *Example, cacheDir string) (string, error) {
+	versions, err := giocompat.ReadVersions(filepath.Join(ex.Dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	var drop []string
+	for module, dir := range map[string]string{
+		giocompat.GioModule:     versions.GioDir,
+		giocompat.PluginsModule: versions.PluginsDir,
+	} {
+		if dir == "" {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			drop = append(drop, module)
+		}
+	}
+	if len(drop) == 0 {
+		return ex.Dir, nil
+	}
+
+	sort.Strings(drop)
+	dst := filepath.Join(cacheDir, "examples", "build", ex.Name)
+	fmt.Printf("📂 %s is not checked out locally; building %s against released modules\n", strings.Join(drop, " and "), ex.Name)
+	if err := os.RemoveAll(dst); err != nil {
+		return "", err
+	}
+	if err := copyTree(ex.Dir, dst); err != nil {
+		return "", fmt.Errorf("failed to copy example: %w", err)
+	}
+	args := []string{"mod", "edit"}
+	for _, module := range drop {
+		args = append(args, "-dropreplace="+module)
+	}
+	edit := exec.Command("go", args...)
+	edit.Dir = dst
+	if out, err := edit.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to edit go.mod: %w\n%s", err, out)
+	}
+	if err := modTidy(dst); err != nil {
+		return "", fmt.Errorf("failed to resolve modules: %w", err)
+	}
+	return dst, nil
+}
+
+// copyTree copies the example sources, leaving out build output.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if rel != "." && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+}
@@ -0,0 +1,107 @@
+package examples
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	repo := t.TempDir()
+	writeFiles(t, repo, map[string]string{
+		"go.mod":                              "module " + Module + "\n",
+		"examples/hybrid-dashboard/go.mod":    "module main\n",
+		"examples/my-demo/go.mod":             "module main\n",
+		"examples/my-demo/README.md":          "intro\n# My Demo \n",
+		"examples/notes/README.md":            "# Not a module\n",
+		"examples/hybrid-dashboard/web/x.txt": "",
+	})
+
+	root := FindRoot(filepath.Join(repo, "examples", "my-demo"))
+	if root != filepath.Join(repo, "examples") {
+		t.Fatalf("FindRoot = %q", root)
+	}
+	if FindRoot(t.TempDir()) != "" {
+		t.Error("FindRoot outside a checkout found one")
+	}
+
+	list, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "hybrid-dashboard" || list[1].Description != "My Demo" {
+		t.Fatalf("list = %+v", list)
+	}
+	if list[0].Description != descriptions["hybrid-dashboard"] {
+		t.Errorf("description = %q", list[0].Description)
+	}
+	if _, err := Get(root, "nope"); err == nil || !strings.Contains(err.Error(), "hybrid-dashboard, my-demo") {
+		t.Errorf("Get unknown = %v", err)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	repo := t.TempDir()
+	writeFiles(t, repo, map[string]string{
+		"examples/demo/go.mod": `module main
+
+go 1.24
+
+require gioui.org v0.9.0
+
+replace gioui.org => ../../.src/gio
+`,
+		"examples/demo/main.go":        "package main\n",
+		"examples/demo/.bin/demo.apk":  "",
+		"examples/local/go.mod":        "module main\n\nrequire gioui.org v0.9.0\n\nreplace gioui.org => ../../.src/gio\n",
+		"examples/plain/go.mod":        "module main\n",
+		"examples/plain/sub/plain.txt": "",
+	})
+	var tidied string
+	orig := modTidy
+	modTidy = func(dir string) error { tidied = dir; return nil }
+	t.Cleanup(func() { modTidy = orig })
+	cache := t.TempDir()
+
+	demo := &Example{Name: "demo", Dir: filepath.Join(repo, "examples", "demo")}
+	dir, err := Prepare(demo, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != filepath.Join(cache, "examples", "build", "demo") || tidied != dir {
+		t.Fatalf("dir = %q, tidied %q", dir, tidied)
+	}
+	goMod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if strings.Contains(string(goMod), "replace") {
+		t.Errorf("replace kept:\n%s", goMod)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Error("sources not copied")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".bin")); err == nil {
+		t.Error("build output copied")
+	}
+
+	// With the checkout present, or nothing replaced, the example is used in place
+	writeFiles(t, repo, map[string]string{".src/gio/go.mod": "module gioui.org\n"})
+	for _, name := range []string{"local", "plain"} {
+		ex := &Example{Name: name, Dir: filepath.Join(repo, "examples", name)}
+		if dir, err := Prepare(ex, cache); err != nil || dir != ex.Dir {
+			t.Errorf("%s: dir = %q, err = %v", name, dir, err)
+		}
+	}
+}
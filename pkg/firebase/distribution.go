@@ -0,0 +1,174 @@
+// Package firebase uploads builds to Firebase App Distribution and shares
+// them with tester groups.
+package firebase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/googleauth"
+)
+
+// Endpoints of the App Distribution API.
+const (
+	BaseURL   = "https://firebaseappdistribution.googleapis.com/v1"
+	UploadURL = "https://firebaseappdistribution.googleapis.com/upload/v1"
+	scope     = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// Client calls App Distribution.
+type Client struct {
+	BaseURL   string
+	UploadURL string
+	HTTP      *http.Client
+	// PollInterval is how often upload processing is checked.
+	PollInterval time.Duration
+
+	auth *googleauth.Source
+}
+
+// New returns a client for a service account JSON key with the Firebase
+// App Distribution Admin role.
+func New(serviceAccount []byte) (*Client, error) {
+	auth, err := googleauth.New(serviceAccount, scope)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		BaseURL:      BaseURL,
+		UploadURL:    UploadURL,
+		HTTP:         &http.Client{Timeout: 10 * time.Minute},
+		PollInterval: 5 * time.Second,
+		auth:         auth,
+	}, nil
+}
+
+// AppName turns a Firebase app ID ("1:1234567890:android:0a1b2c") into
+// the API resource name projects/<number>/apps/<id>.
+func AppName(appID string) (string, error) {
+	parts := strings.Split(appID, ":")
+	if len(parts) != 4 || parts[1] == "" {
+		return "", fmt.Errorf("%q is not a Firebase app ID (1:<project-number>:<platform>:<hash>, see Project settings)", appID)
+	}
+	return fmt.Sprintf("projects/%s/apps/%s", parts[1], appID), nil
+}
+
+// Release is an uploaded build.
+type Release struct {
+	Name           string `json:"name"`
+	DisplayVersion string `json:"displayVersion"`
+	BuildVersion   string `json:"buildVersion"`
+	TesterURI      string `json:"testingUri"`
+	ConsoleURI     string `json:"firebaseConsoleUri"`
+}
+
+func (c *Client) do(method, rawURL string, header http.Header, body io.Reader, out any) error {
+	token, err := c.auth.Token(c.HTTP)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("App Distribution request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Error.Message != "" {
+			return fmt.Errorf("App Distribution: %s (%s)", e.Error.Message, resp.Status)
+		}
+		return fmt.Errorf("App Distribution %s: %s", method, resp.Status)
+	}
+	if out != nil && len(data) > 0 {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+func (c *Client) doJSON(method, rawURL string, in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(method, rawURL, http.Header{"Content-Type": {"application/json"}}, bytes.NewReader(data), out)
+}
+
+// Upload sends an APK, AAB or IPA and waits until Firebase has processed
+// it. Uploading a binary that already exists returns the existing release.
+func (c *Client) Upload(appName, path string) (*Release, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer f.Close()
+
+	header := http.Header{
+		"X-Goog-Upload-Protocol":  {"raw"},
+		"X-Goog-Upload-File-Name": {filepath.Base(path)},
+		"Content-Type":            {"application/octet-stream"},
+	}
+	var op operation
+	if err := c.do(http.MethodPost, fmt.Sprintf("%s/%s/releases:upload", c.UploadURL, appName), header, f, &op); err != nil {
+		return nil, err
+	}
+	for !op.Done {
+		time.Sleep(c.PollInterval)
+		if err := c.do(http.MethodGet, c.BaseURL+"/"+op.Name, nil, nil, &op); err != nil {
+			return nil, err
+		}
+	}
+	if op.Error != nil {
+		return nil, fmt.Errorf("App Distribution rejected the upload: %s", op.Error.Message)
+	}
+	return &op.Response.Release, nil
+}
+
+// operation is the long-running operation returned by an upload.
+type operation struct {
+	Name  string `json:"name"`
+	Done  bool   `json:"done"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Response struct {
+		Result  string  `json:"result"`
+		Release Release `json:"release"`
+	} `json:"response"`
+}
+
+// SetNotes replaces the release notes testers see.
+func (c *Client) SetNotes(release *Release, notes string) error {
+	u := fmt.Sprintf("%s/%s?updateMask=%s", c.BaseURL, release.Name, url.QueryEscape("release_notes.text"))
+	return c.doJSON(http.MethodPatch, u, map[string]any{"releaseNotes": map[string]string{"text": notes}}, nil)
+}
+
+// Distribute shares a release with tester groups (aliases) and testers
+// (emails).
+func (c *Client) Distribute(release *Release, groups, testers []string) error {
+	body := map[string][]string{"groupAliases": groups, "testerEmails": testers}
+	return c.doJSON(http.MethodPost, fmt.Sprintf("%s/%s:distribute", c.BaseURL, release.Name), body, nil)
+}
@@ -0,0 +1,281 @@
+// Package flavors builds white-label variants of one app from the flavors
+// section of goup.json:
+//
+//	{
+//	  "flavors": {
+//	    "customerA": {
+//	      "name": "Acme Portal",
+//	      "url": "https://portal.acme.com",
+//	      "bundleId": "com.acme.portal",
+//	      "icon": "flavors/acme.png",
+//	      "colors": {"toolbar": "#0B3D91"},
+//	      "app": {"filter": {"allow": ["*.acme.com"]}}
+//	    }
+//	  }
+//	}
+//
+// app.json is compiled into the shell and icons are generated next to the
+// sources, so a flavor is built from a staged copy of the app directory
+// with its own app.json and icon-source.png. The copy is named after the
+// flavor, which gives its artifacts distinct names. Builds for an
+// environment (app.staging.json layered over app.json) are staged the
+// same way.
+package flavors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/constants"
+	"github.com/joeblew999/goup-util/pkg/hooks"
+	"golang.org/x/mod/modfile"
+)
+
+// Dir holds the staged copies inside the app directory. Go skips
+// directories starting with a dot, so the copies stay out of ./...
+const Dir = ".flavors"
+
+// Flavor is one white-label variant.
+type Flavor struct {
+	Name     string            `json:"name,omitempty"`     // app.json name; also names the artifacts
+	URL      string            `json:"url,omitempty"`      // app.json url
+	BundleID string            `json:"bundleId,omitempty"` // Passed to gogio as -appid
+	Icon     string            `json:"icon,omitempty"`     // Replaces icon-source.png; relative to goup.json
+	Colors   map[string]string `json:"colors,omitempty"`   // Merged into app.json theme.colors
+	App      map[string]any    `json:"app,omitempty"`      // Other app.json fields to override
+}
+
+// Config is the flavors section of goup.json.
+type Config struct {
+	Flavors map[string]Flavor `json:"flavors"`
+
+	// Dir holds goup.json; icon paths are resolved against it.
+	Dir string `json:"-"`
+}
+
+// Load finds goup.json for dir and returns its flavors, or nil when it
+// has none.
+func Load(dir string) (*Config, error) {
+	path, data, err := hooks.Find(dir)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(c.Flavors) == 0 {
+		return nil, nil
+	}
+	c.Dir = filepath.Dir(path)
+	return &c, nil
+}
+
+// Names lists the flavors, sorted.
+func (c *Config) Names() []string {
+	var names []string
+	for name := range c.Flavors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named flavor.
+func (c *Config) Get(name string) (*Flavor, error) {
+	if c == nil {
+		return nil, fmt.Errorf("no flavors defined: add a \"flavors\" section to %s", hooks.ConfigFileName)
+	}
+	f, ok := c.Flavors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown flavor %q. Defined: %s", name, strings.Join(c.Names(), ", "))
+	}
+	return &f, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// AppName is the directory, and so the artifact, name of a flavor: its
+// display name or flavor name, lowercased with dashes.
+func (f *Flavor) AppName(flavor string) string {
+	name := f.Name
+	if name == "" {
+		name = flavor
+	}
+	return strings.Trim(unsafeChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// Stage copies appDir to appDir/.flavors/<variant>/<app-name>, applies
+// the flavor and the environment overlay (app.<env>.json) to its app.json
+// and icon, and returns the copy's path. Either may be empty; c may be nil
+// when flavor is. Earlier copies of the variant are replaced.
+func (c *Config) Stage(appDir, flavor, env string) (string, error) {
+	appDir, err := filepath.Abs(appDir)
+	if err != nil {
+		return "", err
+	}
+	f, name := &Flavor{}, filepath.Base(appDir)
+	if flavor != "" {
+		if f, err = c.Get(flavor); err != nil {
+			return "", err
+		}
+		name = f.AppName(flavor)
+	}
+
+	variant := flavor
+	if env != "" {
+		variant = strings.TrimPrefix(flavor+"-"+env, "-")
+	}
+	dst := filepath.Join(appDir, Dir, variant, name)
+	if err := os.RemoveAll(filepath.Join(appDir, Dir, variant)); err != nil {
+		return "", err
+	}
+	if err := copySources(appDir, dst); err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", appDir, err)
+	}
+	if err := rebaseReplaces(filepath.Join(dst, "go.mod"), appDir); err != nil {
+		return "", err
+	}
+
+	var base map[string]any
+	if env != "" {
+		if base, err = appconfig.Overlay(appDir, env); err != nil {
+			return "", err
+		}
+	}
+	if err := f.writeAppConfig(dst, base); err != nil {
+		return "", err
+	}
+	if f.Icon != "" {
+		icon := f.Icon
+		if !filepath.IsAbs(icon) {
+			icon = filepath.Join(c.Dir, icon)
+		}
+		data, err := os.ReadFile(icon)
+		if err != nil {
+			return "", fmt.Errorf("flavor %s icon: %w", flavor, err)
+		}
+		if err := os.WriteFile(filepath.Join(dst, "icon-source.png"), data, 0644); err != nil {
+			return "", err
+		}
+	}
+	return dst, nil
+}
+
+// writeAppConfig applies the flavor to the copy's app.json, or to cfg when
+// it is given, keeping any fields it does not set.
+func (f *Flavor) writeAppConfig(dir string, cfg map[string]any) error {
+	path := filepath.Join(dir, appconfig.ConfigFileName)
+	if cfg == nil {
+		cfg = map[string]any{}
+		if data, err := os.ReadFile(path); err == nil {
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		} else if f.URL == "" && f.Name == "" && len(f.Colors) == 0 && len(f.App) == 0 {
+			return nil // Not a shell app, and nothing to write
+		}
+	}
+
+	for k, v := range f.App {
+		cfg[k] = v
+	}
+	if f.URL != "" {
+		cfg["url"] = f.URL
+	}
+	if f.Name != "" {
+		cfg["name"] = f.Name
+	}
+	if len(f.Colors) > 0 {
+		theme, _ := cfg["theme"].(map[string]any)
+		if theme == nil {
+			theme = map[string]any{}
+		}
+		colors, _ := theme["colors"].(map[string]any)
+		if colors == nil {
+			colors = map[string]any{}
+		}
+		for k, v := range f.Colors {
+			colors[k] = v
+		}
+		theme["colors"] = colors
+		cfg["theme"] = theme
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// copySources copies the app without build output, generated icons or
+// other hidden directories.
+func copySources(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if rel != "." && (strings.HasPrefix(d.Name(), ".") || d.Name() == constants.BuildDir) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// rebaseReplaces makes the local replace directives of a copied go.mod
+// absolute, so they still point at the original's neighbours.
+func rebaseReplaces(goMod, origDir string) error {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return nil // Validated later, with a better message
+	}
+	f, err := modfile.Parse(goMod, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", goMod, err)
+	}
+	changed := false
+	for _, r := range f.Replace {
+		if r.New.Version != "" || filepath.IsAbs(r.New.Path) {
+			continue
+		}
+		abs := filepath.Join(origDir, r.New.Path)
+		if err := f.AddReplace(r.Old.Path, r.Old.Version, abs, ""); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	out, err := f.Format()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(goMod, out, 0644)
+}
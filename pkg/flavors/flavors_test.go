@@ -0,0 +1,128 @@
+package flavors
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setup(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	app := filepath.Join(root, "shell")
+	os.MkdirAll(filepath.Join(app, ".build"), 0755)
+	os.MkdirAll(filepath.Join(root, "flavors"), 0755)
+	os.WriteFile(filepath.Join(root, "goup.json"), []byte(`{
+  "flavors": {
+    "customerA": {
+      "name": "Acme Portal",
+      "url": "https://portal.acme.com",
+      "bundleId": "com.acme.portal",
+      "icon": "flavors/acme.png",
+      "colors": {"toolbar": "#0B3D91"},
+      "app": {"filter": {"allow": ["*.acme.com"]}}
+    },
+    "plain": {}
+  }
+}`), 0644)
+	os.WriteFile(filepath.Join(root, "flavors", "acme.png"), []byte("acme"), 0644)
+	os.WriteFile(filepath.Join(app, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(app, "icon-source.png"), []byte("default"), 0644)
+	os.WriteFile(filepath.Join(app, ".build", "stale"), nil, 0644)
+	os.WriteFile(filepath.Join(app, "go.mod"), []byte("module shell\n\ngo 1.25\n\nreplace example.com/lib => ../lib\n"), 0644)
+	os.WriteFile(filepath.Join(app, "app.json"), []byte(`{"url": "https://example.com", "theme": {"colors": {"background": "#fff"}}}`), 0644)
+	return app
+}
+
+func TestStage(t *testing.T) {
+	app := setup(t)
+	c, err := Load(app)
+	if err != nil || c == nil {
+		t.Fatalf("Load: %v %v", c, err)
+	}
+	dir, err := c.Stage(app, "customerA", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(app, Dir, "customerA", "acme-portal"); dir != want {
+		t.Errorf("dir = %s, want %s", dir, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".build")); err == nil {
+		t.Error(".build was copied")
+	}
+	if icon, _ := os.ReadFile(filepath.Join(dir, "icon-source.png")); string(icon) != "acme" {
+		t.Errorf("icon = %q", icon)
+	}
+	mod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if !strings.Contains(string(mod), "=> "+filepath.Join(app, "..", "lib")) {
+		t.Errorf("go.mod replace not rebased:\n%s", mod)
+	}
+
+	var cfg struct {
+		URL    string `json:"url"`
+		Name   string `json:"name"`
+		Filter struct{ Allow []string }
+		Theme  struct{ Colors map[string]string }
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "app.json"))
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.URL != "https://portal.acme.com" || cfg.Name != "Acme Portal" || len(cfg.Filter.Allow) != 1 ||
+		cfg.Theme.Colors["toolbar"] != "#0B3D91" || cfg.Theme.Colors["background"] != "#fff" {
+		t.Errorf("app.json = %s", data)
+	}
+}
+
+func TestGet(t *testing.T) {
+	app := setup(t)
+	c, _ := Load(app)
+	if _, err := c.Get("customerB"); err == nil || !strings.Contains(err.Error(), "customerA, plain") {
+		t.Errorf("unknown flavor error = %v", err)
+	}
+	f, _ := c.Get("plain")
+	if f.AppName("plain") != "plain" {
+		t.Errorf("AppName = %s", f.AppName("plain"))
+	}
+	var none *Config
+	if _, err := none.Get("x"); err == nil {
+		t.Error("nil config accepted")
+	}
+}
+
+func TestStageEnv(t *testing.T) {
+	app := setup(t)
+	os.WriteFile(filepath.Join(app, "app.staging.json"), []byte(`{"url": "https://staging.example.com", "theme": {"mode": "dark"}}`), 0644)
+	c, _ := Load(app)
+
+	dir, err := c.Stage(app, "", "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(app, Dir, "staging", "shell"); dir != want {
+		t.Errorf("dir = %s, want %s", dir, want)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "app.json"))
+	for _, want := range []string{`"url": "https://staging.example.com"`, `"environment": "staging"`, `"background": "#fff"`, `"mode": "dark"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("app.json lacks %s:\n%s", want, data)
+		}
+	}
+
+	// The flavor applies over the environment
+	dir, err = c.Stage(app, "customerA", "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "app.json"))
+	if !strings.Contains(string(data), `"url": "https://portal.acme.com"`) || !strings.Contains(string(data), `"environment": "staging"`) {
+		t.Errorf("app.json = %s", data)
+	}
+
+	if _, err := c.Stage(app, "", "prod"); err == nil || !strings.Contains(err.Error(), "Environments: staging") {
+		t.Errorf("missing overlay error = %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Handler runs one command on the device.
+type Handler func(ctx context.Context, c *Command) error
+
+// Agent checks a device in periodically and runs the signed commands the
+// registry hands back. It is the Go side of a managed shell; the
+// webviewer example implements the same protocol inline.
+type Agent struct {
+	Client *Client
+	Device Device
+
+	// PublicKey verifies commands (base64 ed25519, from 'goup-util fleet
+	// keygen'). Without it every command is refused.
+	PublicKey string
+
+	// Handlers run commands by type. A screenshot command without a
+	// handler captures the desktop with pkg/screenshot, in binaries
+	// built with -tags screenshot.
+	Handlers map[string]Handler
+
+	// After is called once the results of a batch of commands are
+	// reported, with the types that succeeded; shells restart here after
+	// an update.
+	After func(done []string)
+
+	Interval time.Duration // Default DefaultInterval
+	OnError  func(error)
+
+	// StateFile keeps the IDs of the commands already run until they
+	// expire, so a command replayed after a restart is not run again.
+	// Default DefaultStateFile().
+	StateFile string
+
+	ran ranCommands
+}
+
+// DefaultStateFile is fleet-commands.json next to the machine ID that
+// pkg/updater keeps in the user config directory.
+func DefaultStateFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "goup-util", "fleet-commands.json")
+}
+
+// ranCommands maps the IDs of commands a device has run to when they
+// expire; after that Command.Check refuses them anyway.
+type ranCommands map[string]time.Time
+
+func loadRanCommands(path string) ranCommands {
+	ran := ranCommands{}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &ran)
+	}
+	return ran
+}
+
+// save drops expired commands and writes the rest to path.
+func (r ranCommands) save(path string, now time.Time) error {
+	for id, expires := range r {
+		if now.After(expires) {
+			delete(r, id)
+		}
+	}
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Run checks in now and every Interval until ctx is done. Failures go to
+// OnError (if set) and never stop the loop.
+func (a *Agent) Run(ctx context.Context) {
+	interval := a.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		a.checkin(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkin reports in, runs any commands and reports their results
+// straight away.
+func (a *Agent) checkin(ctx context.Context) {
+	d := a.Device
+	stored, err := a.Client.Checkin(ctx, d)
+	if err != nil {
+		a.fail(ctx, err)
+		return
+	}
+	if a.Handlers == nil && a.PublicKey == "" {
+		return
+	}
+	var done []string
+	for _, sc := range stored.Commands {
+		res, ok := a.run(ctx, sc)
+		if !ok {
+			continue
+		}
+		d.Results = append(d.Results, res)
+		if res.OK {
+			done = append(done, res.Type)
+		}
+	}
+	if len(d.Results) == 0 {
+		return
+	}
+	if _, err := a.Client.Checkin(ctx, d); err != nil {
+		a.fail(ctx, err)
+		return
+	}
+	if a.After != nil {
+		a.After(done)
+	}
+}
+
+// run verifies and runs one command. ok is false for commands that were
+// already run, in this process or before a restart.
+func (a *Agent) run(ctx context.Context, sc SignedCommand) (res Result, ok bool) {
+	c, err := sc.command()
+	if err != nil {
+		a.fail(ctx, err)
+		return Result{}, false
+	}
+	if a.ran == nil {
+		if a.StateFile == "" {
+			a.StateFile = DefaultStateFile()
+		}
+		a.ran = loadRanCommands(a.StateFile)
+	}
+	if _, seen := a.ran[c.ID]; seen {
+		return Result{}, false
+	}
+
+	now := time.Now()
+	res = Result{Command: c.ID, Type: c.Type}
+	a.ran[c.ID] = now.Add(DefaultCommandTTL) // Unverified: report the failure once
+	if a.PublicKey == "" {
+		err = fmt.Errorf("device has no command key")
+	} else if c, err = sc.Verify(a.PublicKey); err == nil {
+		if err = c.Check(now, a.Device.ID); err == nil {
+			a.ran[c.ID] = c.Expires
+		}
+	}
+	// Saved before running, since an update restarts the app
+	if serr := a.ran.save(a.StateFile, now); serr != nil && err == nil {
+		err = fmt.Errorf("cannot record the command as run: %w", serr)
+	}
+	if err == nil {
+		if h, found := a.Handlers[c.Type]; found {
+			err = h(ctx, c)
+		} else if c.Type == CommandScreenshot {
+			err = a.screenshot(ctx)
+		} else {
+			err = fmt.Errorf("unsupported command %q", c.Type)
+		}
+	}
+	res.OK, res.At = err == nil, time.Now().UTC()
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res, true
+}
+
+func (a *Agent) screenshot(ctx context.Context) error {
+	png, err := captureScreen()
+	if err != nil {
+		return err
+	}
+	return a.Client.UploadScreenshot(ctx, a.Device.ID, png)
+}
+
+func (a *Agent) fail(ctx context.Context, err error) {
+	if a.OnError != nil && ctx.Err() == nil {
+		a.OnError(err)
+	}
+}
@@ -0,0 +1,156 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/updater"
+)
+
+// DefaultInterval is how often Heartbeat checks in.
+const DefaultInterval = 5 * time.Minute
+
+// Client talks to a fleet registry.
+type Client struct {
+	URL   string // Server base URL
+	Token string // Enrollment token for check-ins, admin token for listing
+	HTTP  *http.Client
+}
+
+// NewClient returns a client for the server at baseURL.
+func NewClient(baseURL, token string) *Client {
+	return &Client{URL: strings.TrimSuffix(baseURL, "/"), Token: token, HTTP: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// This describes the running install: machine ID, host name and platform.
+// Callers fill in App, Version and Environment.
+func This() Device {
+	name, _ := os.Hostname()
+	platform := runtime.GOOS
+	if platform == "darwin" {
+		platform = "macos"
+	}
+	return Device{ID: updater.MachineID(), Name: name, Platform: platform + "/" + runtime.GOARCH}
+}
+
+// Checkin reports d to the registry and returns the stored device, with
+// the commands queued for it.
+func (c *Client) Checkin(ctx context.Context, d Device) (*Device, error) {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+"/fleet/checkin", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var stored Device
+	if err := c.do(req, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Heartbeat checks in now and every interval until ctx is done, ignoring
+// commands. Failures go to onError (if set) and never stop the loop.
+func (c *Client) Heartbeat(ctx context.Context, d Device, interval time.Duration, onError func(error)) {
+	(&Agent{Client: c, Device: d, Interval: interval, OnError: onError}).Run(ctx)
+}
+
+// Queue sends a signed command to the registry for the device it names.
+func (c *Client) Queue(ctx context.Context, sc SignedCommand) error {
+	cmd, err := sc.command()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(sc)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+"/fleet/devices/"+url.PathEscape(cmd.Device)+"/commands", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, nil)
+}
+
+// UploadScreenshot stores a PNG as the latest screenshot of device id.
+func (c *Client) UploadScreenshot(ctx context.Context, id string, png []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.URL+"/fleet/devices/"+url.PathEscape(id)+"/screenshot", bytes.NewReader(png))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "image/png")
+	return c.do(req, nil)
+}
+
+// Screenshot downloads the latest screenshot of device id.
+func (c *Client) Screenshot(ctx context.Context, id string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL+"/fleet/devices/"+url.PathEscape(id)+"/screenshot", nil)
+	if err != nil {
+		return nil, err
+	}
+	var png []byte
+	return png, c.do(req, &png)
+}
+
+// Devices lists the fleet.
+func (c *Client) Devices(ctx context.Context) ([]Device, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL+"/fleet/devices", nil)
+	if err != nil {
+		return nil, err
+	}
+	var devices []Device
+	return devices, c.do(req, &devices)
+}
+
+// Device returns one device by ID.
+func (c *Client) Device(ctx context.Context, id string) (*Device, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL+"/fleet/devices/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var d Device
+	if err := c.do(req, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (c *Client) do(req *http.Request, into any) error {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	switch into := into.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*into, err = io.ReadAll(resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(into)
+}
@@ -0,0 +1,137 @@
+package fleet
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/licensing"
+)
+
+// Commands an operator can send to a device.
+const (
+	CommandReload     = "reload"      // Load URL (or the start page) in every tab
+	CommandClearCache = "clear-cache" // Clear the webview cache and reload
+	CommandUpdate     = "update"      // Install the latest release now and restart
+	CommandScreenshot = "screenshot"  // Capture the screen and upload it
+)
+
+// CommandTypes lists the commands shells understand.
+var CommandTypes = []string{CommandReload, CommandClearCache, CommandUpdate, CommandScreenshot}
+
+// DefaultCommandTTL is how long a device accepts a command after it was
+// issued.
+const DefaultCommandTTL = 24 * time.Hour
+
+// maxResults is how many command results the registry keeps per device.
+const maxResults = 20
+
+var (
+	ErrSignature = errors.New("command signature is invalid")
+	ErrExpired   = errors.New("command has expired")
+	ErrDevice    = errors.New("command is for another device")
+)
+
+// Command is an instruction for one device. Commands are signed with the
+// operator's ed25519 key; devices only run commands that verify against
+// the public key they were built with, so the server (or anyone between
+// it and the device) cannot forge them.
+type Command struct {
+	ID      string    `json:"id"`
+	Device  string    `json:"device"`
+	Type    string    `json:"type"`
+	URL     string    `json:"url,omitempty"` // For reload
+	Issued  time.Time `json:"issued"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewCommand returns a command of type typ for device, valid for ttl
+// (DefaultCommandTTL if zero).
+func NewCommand(device, typ string, ttl time.Duration) (Command, error) {
+	known := false
+	for _, t := range CommandTypes {
+		known = known || t == typ
+	}
+	if !known {
+		return Command{}, fmt.Errorf("unknown command %q (want one of %v)", typ, CommandTypes)
+	}
+	if ttl <= 0 {
+		ttl = DefaultCommandTTL
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return Command{}, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	return Command{ID: hex.EncodeToString(b), Device: device, Type: typ, Issued: now, Expires: now.Add(ttl)}, nil
+}
+
+// Check reports whether the command may run on deviceID at now.
+func (c *Command) Check(now time.Time, deviceID string) error {
+	if c.Device != deviceID {
+		return ErrDevice
+	}
+	if now.After(c.Expires) {
+		return fmt.Errorf("%w (%s)", ErrExpired, c.Expires.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// SignedCommand is a command as queued on the server and delivered to the
+// device.
+type SignedCommand struct {
+	Command   json.RawMessage `json:"command"`
+	Signature string          `json:"signature"` // base64 ed25519 signature of Command
+}
+
+// Sign signs c with a base64 ed25519 private key, as created by
+// 'goup-util fleet keygen'.
+func Sign(privateKey string, c Command) (SignedCommand, error) {
+	key, err := licensing.ParsePrivateKey(privateKey)
+	if err != nil {
+		return SignedCommand{}, err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return SignedCommand{}, err
+	}
+	return SignedCommand{Command: payload, Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))}, nil
+}
+
+// Verify checks the signature against a base64 ed25519 public key and
+// returns the command.
+func (s SignedCommand) Verify(publicKey string) (*Command, error) {
+	key, err := licensing.ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil || !ed25519.Verify(key, s.Command, sig) {
+		return nil, ErrSignature
+	}
+	return s.command()
+}
+
+// command decodes the payload without checking the signature; the server
+// uses it to route and expire commands.
+func (s SignedCommand) command() (*Command, error) {
+	var c Command
+	if err := json.Unmarshal(s.Command, &c); err != nil {
+		return nil, fmt.Errorf("invalid command: %w", err)
+	}
+	return &c, nil
+}
+
+// Result is what a device reports after running a command.
+type Result struct {
+	Command string    `json:"command"` // Command ID
+	Type    string    `json:"type,omitempty"`
+	OK      bool      `json:"ok"`
+	Error   string    `json:"error,omitempty"`
+	At      time.Time `json:"at"`
+}
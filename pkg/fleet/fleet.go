@@ -0,0 +1,344 @@
+// Package fleet keeps a registry of installed shells for kiosk and
+// signage deployments. Each install checks in periodically with its
+// machine ID, app, version and platform; operators list the fleet to see
+// which devices are online and what they run.
+//
+// The registry is served by 'goup-util update-server serve --fleet', next
+// to the updates the devices already fetch:
+//
+//	POST /fleet/checkin                    a device reports in (enrollment token)
+//	PUT  /fleet/devices/{id}/screenshot    a device uploads a screenshot (enrollment token)
+//	GET  /fleet/devices                    every device, most recently seen first (admin token)
+//	GET  /fleet/devices/{id}               one device (admin token)
+//	POST /fleet/devices/{id}/commands      queue a signed command (admin token)
+//	GET  /fleet/devices/{id}/screenshot    the latest screenshot (admin token)
+//
+// Queued commands go to the device in the reply to its next check-in, and
+// it reports the results with the one after. See Command.
+package fleet
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStaleAfter is how long after its last check-in a device counts
+// as offline. Devices check in every five minutes by default.
+const DefaultStaleAfter = 15 * time.Minute
+
+// Device is one registered install.
+type Device struct {
+	ID          string    `json:"id"` // Machine ID
+	Name        string    `json:"name,omitempty"`
+	App         string    `json:"app,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	Platform    string    `json:"platform,omitempty"`
+	Environment string    `json:"environment,omitempty"`
+	Addr        string    `json:"addr,omitempty"` // Remote address of the last check-in
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+
+	// Commands are queued for the device. Devices send Results for the
+	// commands they ran; the registry keeps the latest.
+	Commands []SignedCommand `json:"commands,omitempty"`
+	Results  []Result        `json:"results,omitempty"`
+}
+
+// Online reports whether the device checked in within staleAfter of now.
+func (d Device) Online(now time.Time, staleAfter time.Duration) bool {
+	return now.Sub(d.LastSeen) <= staleAfter
+}
+
+// Registry holds the devices, persisted to a JSON file.
+type Registry struct {
+	path string
+
+	mu      sync.Mutex
+	devices map[string]Device
+}
+
+// Open loads the registry at path, creating it on the first check-in.
+func Open(path string) (*Registry, error) {
+	r := &Registry{path: path, devices: map[string]Device{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var devices []Device
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, d := range devices {
+		r.devices[d.ID] = d
+	}
+	return r, nil
+}
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Checkin records a report from a device at now and returns the stored
+// device, with the commands still queued for it. Commands the report has
+// results for, and expired ones, are dropped from the queue.
+func (r *Registry) Checkin(d Device, now time.Time) (Device, error) {
+	if !validID.MatchString(d.ID) {
+		return Device{}, fmt.Errorf("invalid device id %q", d.ID)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.devices[d.ID]
+	d.FirstSeen = old.FirstSeen
+	if d.FirstSeen.IsZero() {
+		d.FirstSeen = now
+	}
+	d.LastSeen = now
+
+	done := map[string]bool{}
+	for _, res := range d.Results {
+		done[res.Command] = true
+	}
+	d.Commands = nil
+	for _, sc := range old.Commands {
+		if c, err := sc.command(); err == nil && !done[c.ID] && !now.After(c.Expires) {
+			d.Commands = append(d.Commands, sc)
+		}
+	}
+	d.Results = append(old.Results, d.Results...)
+	if len(d.Results) > maxResults {
+		d.Results = d.Results[len(d.Results)-maxResults:]
+	}
+
+	r.devices[d.ID] = d
+	return d, r.save()
+}
+
+// Queue adds a signed command for device id. The signature is checked by
+// the device, not here.
+func (r *Registry) Queue(id string, sc SignedCommand, now time.Time) (Device, error) {
+	c, err := sc.command()
+	if err != nil {
+		return Device{}, err
+	}
+	if c.Device != id {
+		return Device{}, fmt.Errorf("command is for %q, not %q", c.Device, id)
+	}
+	if now.After(c.Expires) {
+		return Device{}, ErrExpired
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.devices[id]
+	if !ok {
+		return Device{}, fmt.Errorf("unknown device %q", id)
+	}
+	for _, queued := range d.Commands {
+		if q, err := queued.command(); err == nil && q.ID == c.ID {
+			return Device{}, fmt.Errorf("command %s is already queued", c.ID)
+		}
+	}
+	d.Commands = append(d.Commands, sc)
+	r.devices[id] = d
+	return d, r.save()
+}
+
+// ScreenshotPath is where the latest screenshot from device id is kept,
+// next to the registry file.
+func (r *Registry) ScreenshotPath(id string) string {
+	return filepath.Join(strings.TrimSuffix(r.path, filepath.Ext(r.path))+"-screenshots", id+".png")
+}
+
+// SaveScreenshot stores a PNG uploaded by device id.
+func (r *Registry) SaveScreenshot(id string, src io.Reader) error {
+	if _, ok := r.Device(id); !ok {
+		return fmt.Errorf("unknown device %q", id)
+	}
+	path := r.ScreenshotPath(id)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Devices returns every device, most recently seen first.
+func (r *Registry) Devices() []Device {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	devices := make([]Device, 0, len(r.devices))
+	for _, d := range r.devices {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if !devices[i].LastSeen.Equal(devices[j].LastSeen) {
+			return devices[i].LastSeen.After(devices[j].LastSeen)
+		}
+		return devices[i].ID < devices[j].ID
+	})
+	return devices
+}
+
+// Device returns one device.
+func (r *Registry) Device(id string) (Device, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.devices[id]
+	return d, ok
+}
+
+// save writes the registry; the caller holds mu.
+func (r *Registry) save() error {
+	devices := make([]Device, 0, len(r.devices))
+	for _, d := range r.devices {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	data, err := json.MarshalIndent(devices, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// Tokens protect the endpoints. An empty Enroll token lets any device
+// check in; an empty Admin token closes the admin endpoints.
+type Tokens struct {
+	Enroll string // Sent by devices with each check-in; shipped inside the app
+	Admin  string // Needed to list devices; kept by operators
+}
+
+// Register adds the fleet endpoints to mux.
+func (r *Registry) Register(mux *http.ServeMux, tokens Tokens) {
+	mux.HandleFunc("POST /fleet/checkin", func(w http.ResponseWriter, req *http.Request) {
+		if tokens.Enroll != "" && !authorized(req, tokens.Enroll) {
+			http.Error(w, "invalid enrollment token", http.StatusUnauthorized)
+			return
+		}
+		var d Device
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 8192)).Decode(&d); err != nil {
+			http.Error(w, "invalid check-in", http.StatusBadRequest)
+			return
+		}
+		d.Addr = remoteHost(req)
+		d, err := r.Checkin(d, time.Now().UTC())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, d)
+	})
+	mux.HandleFunc("GET /fleet/devices", func(w http.ResponseWriter, req *http.Request) {
+		if !authorized(req, tokens.Admin) {
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, http.StatusOK, r.Devices())
+	})
+	mux.HandleFunc("GET /fleet/devices/{id}", func(w http.ResponseWriter, req *http.Request) {
+		if !authorized(req, tokens.Admin) {
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		d, ok := r.Device(req.PathValue("id"))
+		if !ok {
+			http.Error(w, "unknown device", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, d)
+	})
+	mux.HandleFunc("POST /fleet/devices/{id}/commands", func(w http.ResponseWriter, req *http.Request) {
+		if !authorized(req, tokens.Admin) {
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		var sc SignedCommand
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 8192)).Decode(&sc); err != nil {
+			http.Error(w, "invalid command", http.StatusBadRequest)
+			return
+		}
+		d, err := r.Queue(req.PathValue("id"), sc, time.Now().UTC())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, d)
+	})
+	mux.HandleFunc("PUT /fleet/devices/{id}/screenshot", func(w http.ResponseWriter, req *http.Request) {
+		if tokens.Enroll != "" && !authorized(req, tokens.Enroll) {
+			http.Error(w, "invalid enrollment token", http.StatusUnauthorized)
+			return
+		}
+		if err := r.SaveScreenshot(req.PathValue("id"), http.MaxBytesReader(w, req.Body, 32<<20)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /fleet/devices/{id}/screenshot", func(w http.ResponseWriter, req *http.Request) {
+		if !authorized(req, tokens.Admin) {
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		id := req.PathValue("id")
+		if !validID.MatchString(id) {
+			http.Error(w, "unknown device", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		http.ServeFile(w, req, r.ScreenshotPath(id))
+	})
+}
+
+// authorized reports whether req carries token. Nothing matches an empty
+// token.
+func authorized(req *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	got := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func remoteHost(req *http.Request) string {
+	if fwd := req.Header.Get("X-Forwarded-For"); fwd != "" {
+		return strings.TrimSpace(strings.Split(fwd, ",")[0])
+	}
+	host := req.RemoteAddr
+	if i := strings.LastIndex(host, ":"); i > 0 {
+		host = host[:i]
+	}
+	return strings.Trim(host, "[]")
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
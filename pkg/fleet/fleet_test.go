@@ -0,0 +1,186 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/licensing"
+)
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fleet.json")
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	r.Checkin(Device{ID: "kiosk-1", Version: "v1.0.0"}, t0)
+	r.Checkin(Device{ID: "kiosk-2"}, t0.Add(time.Minute))
+	r.Checkin(Device{ID: "kiosk-1", Version: "v1.1.0"}, t0.Add(2*time.Minute))
+	if _, err := r.Checkin(Device{ID: "../etc"}, t0); err == nil {
+		t.Error("invalid id accepted")
+	}
+
+	// Reload from disk
+	r, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	devices := r.Devices()
+	if len(devices) != 2 || devices[0].ID != "kiosk-1" || devices[0].Version != "v1.1.0" || !devices[0].FirstSeen.Equal(t0) {
+		t.Errorf("devices = %+v", devices)
+	}
+	if !devices[1].Online(t0.Add(10*time.Minute), DefaultStaleAfter) || devices[1].Online(t0.Add(time.Hour), DefaultStaleAfter) {
+		t.Error("Online")
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	r, _ := Open(filepath.Join(t.TempDir(), "fleet.json"))
+	mux := http.NewServeMux()
+	r.Register(mux, Tokens{Enroll: "enroll", Admin: "admin"})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ctx := context.Background()
+
+	d := Device{ID: "kiosk-1", App: "acme", Version: "v1.2.0"}
+	if _, err := NewClient(srv.URL, "wrong").Checkin(ctx, d); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("bad enrollment token: %v", err)
+	}
+	if _, err := NewClient(srv.URL, "enroll").Checkin(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewClient(srv.URL, "enroll").Devices(ctx); err == nil {
+		t.Error("enrollment token lists devices")
+	}
+	admin := NewClient(srv.URL+"/", "admin")
+	devices, err := admin.Devices(ctx)
+	if err != nil || len(devices) != 1 || devices[0].App != "acme" || devices[0].Addr != "127.0.0.1" {
+		t.Errorf("devices = %+v, %v", devices, err)
+	}
+	if got, err := admin.Device(ctx, "kiosk-1"); err != nil || got.Version != "v1.2.0" {
+		t.Errorf("device = %+v, %v", got, err)
+	}
+	if _, err := admin.Device(ctx, "kiosk-9"); err == nil {
+		t.Error("unknown device found")
+	}
+}
+
+func TestCommands(t *testing.T) {
+	r, _ := Open(filepath.Join(t.TempDir(), "fleet.json"))
+	mux := http.NewServeMux()
+	r.Register(mux, Tokens{Admin: "admin"})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ctx := context.Background()
+	client := NewClient(srv.URL, "")
+	admin := NewClient(srv.URL, "admin")
+
+	pub, priv, _ := licensing.GenerateKey()
+	_, otherPriv, _ := licensing.GenerateKey()
+	var reloaded []string
+	var after []string
+	state := filepath.Join(t.TempDir(), "fleet-commands.json")
+	newAgent := func() *Agent {
+		return &Agent{
+			Client:    client,
+			Device:    Device{ID: "kiosk-1"},
+			PublicKey: pub,
+			Handlers: map[string]Handler{
+				CommandReload: func(ctx context.Context, c *Command) error {
+					reloaded = append(reloaded, c.URL)
+					return nil
+				},
+			},
+			After:     func(done []string) { after = append(after, done...) },
+			StateFile: state,
+		}
+	}
+	agent := newAgent()
+	agent.checkin(ctx)
+
+	signed := map[string]SignedCommand{}
+	queue := func(key, typ, url string) Command {
+		t.Helper()
+		c, err := NewCommand("kiosk-1", typ, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.URL = url
+		sc, err := Sign(key, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := admin.Queue(ctx, sc); err != nil {
+			t.Fatal(err)
+		}
+		signed[c.ID] = sc
+		return c
+	}
+	reload := queue(priv, CommandReload, "https://example.com/menu")
+	forged := queue(otherPriv, CommandReload, "https://evil.example")
+	update := queue(priv, CommandUpdate, "")
+	if _, err := NewCommand("kiosk-1", "format-disk", 0); err == nil {
+		t.Error("unknown command type accepted")
+	}
+
+	agent.checkin(ctx)
+	if len(reloaded) != 1 || reloaded[0] != "https://example.com/menu" {
+		t.Errorf("reloaded = %v", reloaded)
+	}
+	if len(after) != 1 || after[0] != CommandReload {
+		t.Errorf("after = %v", after)
+	}
+
+	d, _ := r.Device("kiosk-1")
+	if len(d.Commands) != 0 {
+		t.Errorf("%d command(s) still queued", len(d.Commands))
+	}
+	results := map[string]Result{}
+	for _, res := range d.Results {
+		results[res.Command] = res
+	}
+	if !results[reload.ID].OK || results[forged.ID].Error != ErrSignature.Error() || !strings.Contains(results[update.ID].Error, "unsupported") {
+		t.Errorf("results = %+v", d.Results)
+	}
+
+	// Replaying a command after a restart does not run it again
+	if err := admin.Queue(ctx, signed[reload.ID]); err != nil {
+		t.Fatal(err)
+	}
+	newAgent().checkin(ctx)
+	if len(reloaded) != 1 {
+		t.Errorf("replayed command ran again: reloaded = %v", reloaded)
+	}
+
+	// A command for another device is rejected by the server
+	other, _ := NewCommand("kiosk-2", CommandReload, 0)
+	sc, _ := Sign(priv, other)
+	if err := admin.Queue(ctx, sc); err == nil {
+		t.Error("command for unknown device queued")
+	}
+	// Without the admin token, the admin endpoints stay closed
+	if err := client.Queue(ctx, signed[reload.ID]); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("command queued without the admin token: %v", err)
+	}
+	if _, err := client.Devices(ctx); err == nil {
+		t.Error("devices listed without the admin token")
+	}
+
+	if err := client.UploadScreenshot(ctx, "kiosk-1", []byte("\x89PNG")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Screenshot(ctx, "kiosk-1"); err == nil {
+		t.Error("screenshot fetched without the admin token")
+	}
+	if png, err := admin.Screenshot(ctx, "kiosk-1"); err != nil || !bytes.Equal(png, []byte("\x89PNG")) {
+		t.Errorf("screenshot = %q, %v", png, err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !screenshot
+
+package fleet
+
+import "errors"
+
+// captureScreen needs pkg/screenshot, which requires CGO.
+func captureScreen() ([]byte, error) {
+	return nil, errors.New("screenshots need a build with -tags screenshot (CGO)")
+}
@@ -0,0 +1,24 @@
+//go:build screenshot
+
+package fleet
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/joeblew999/goup-util/pkg/screenshot"
+)
+
+// captureScreen captures the primary display as PNG.
+func captureScreen() ([]byte, error) {
+	dir, err := os.MkdirTemp("", "goup-fleet-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "screen.png")
+	if err := screenshot.CaptureDesktop(path, 0); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,149 @@
+package ghapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/securestore"
+)
+
+// TokenEnvs are read for a token, in order.
+var TokenEnvs = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+
+// ClientIDEnv names the OAuth or GitHub App client ID used for
+// 'goup-util github login'; the app needs the device flow enabled.
+const ClientIDEnv = "GOUP_GITHUB_CLIENT_ID"
+
+// Service and TokenKey are where a device flow login is kept in the
+// secure store. Shell apps keep theirs under their own service name.
+const (
+	Service  = "goup-util"
+	TokenKey = "github-token"
+)
+
+// Token returns goup-util's GitHub token: the first of TokenEnvs that is
+// set, else the login saved by 'goup-util github login', else "".
+func Token() string {
+	for _, env := range TokenEnvs {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+	return StoredToken(Service)
+}
+
+// StoredToken returns the login saved for service, or "".
+func StoredToken(service string) string {
+	token, err := securestore.New(service).Get(TokenKey)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(token))
+}
+
+// SaveToken keeps a login for service in the secure store.
+func SaveToken(service, token string) error {
+	return securestore.New(service).Set(TokenKey, []byte(token))
+}
+
+// DeleteToken forgets the login saved for service.
+func DeleteToken(service string) error {
+	return securestore.New(service).Delete(TokenKey)
+}
+
+// DeviceCode is the first step of the device flow: the user enters
+// UserCode at VerificationURI while the app polls for the token.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// StartDeviceFlow asks for a device code for clientID. scope is "repo" to
+// read private releases and open issues; public repositories need none.
+func (c *Client) StartDeviceFlow(clientID, scope string) (*DeviceCode, error) {
+	var dc DeviceCode
+	if err := c.postForm("/login/device/code", url.Values{"client_id": {clientID}, "scope": {scope}}, &dc); err != nil {
+		return nil, err
+	}
+	if dc.DeviceCode == "" {
+		return nil, fmt.Errorf("GitHub returned no device code; is the device flow enabled for client %s?", clientID)
+	}
+	return &dc, nil
+}
+
+// WaitForToken polls until the user has approved dc, and returns the
+// access token.
+func (c *Client) WaitForToken(clientID string, dc *DeviceCode) (string, error) {
+	interval := time.Duration(max(dc.Interval, 5)) * time.Second
+	deadline := time.Now().Add(time.Duration(dc.ExpiresIn) * time.Second)
+	for {
+		c.sleep(interval)
+		var r struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+			Interval    int    `json:"interval"`
+		}
+		err := c.postForm("/login/oauth/access_token", url.Values{
+			"client_id":   {clientID},
+			"device_code": {dc.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &r)
+		if err != nil {
+			return "", err
+		}
+		switch r.Error {
+		case "":
+			if r.AccessToken == "" {
+				return "", errors.New("GitHub returned no access token")
+			}
+			return r.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval = time.Duration(max(r.Interval, dc.Interval+5)) * time.Second
+		case "expired_token":
+			return "", errors.New("the code expired before it was entered; run the login again")
+		case "access_denied":
+			return "", errors.New("the login was cancelled")
+		default:
+			return "", fmt.Errorf("GitHub login failed: %s", strings.TrimSpace(r.Error+": "+r.Description))
+		}
+		if dc.ExpiresIn > 0 && time.Now().After(deadline) {
+			return "", errors.New("the code expired before it was entered; run the login again")
+		}
+	}
+}
+
+// postForm posts to the device flow endpoints, which take forms and
+// answer errors with 200 and an "error" field.
+func (c *Client) postForm(path string, form url.Values, out any) error {
+	req, err := http.NewRequest("POST", strings.TrimSuffix(c.WebURL, "/")+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp, body)
+	}
+	return json.Unmarshal(body, out)
+}
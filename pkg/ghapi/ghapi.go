@@ -0,0 +1,421 @@
+// Package ghapi is the client for GitHub's REST API that release lookups
+// share. It sends a token when one is set (see Token), keeps responses on disk and
+// revalidates them with If-None-Match (a 304 does not count against the
+// rate limit), and retries rate limiting and server errors with
+// exponential backoff. When GitHub cannot be reached or the rate limit is
+// spent, the last cached response is used.
+//
+// The client also reads servers that answer in GitHub's format, such as
+// 'goup-util update-server'; the token is only sent to BaseURL's host.
+package ghapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+)
+
+// GitHub endpoints: the API, and the site that runs the device flow.
+const (
+	BaseURL = "https://api.github.com"
+	WebURL  = "https://github.com"
+)
+
+// MaxRateLimitWait is the longest the client sleeps for the rate limit to
+// reset before giving up.
+const MaxRateLimitWait = time.Minute
+
+// ErrNotFound is returned for 404 responses.
+var ErrNotFound = errors.New("not found")
+
+// Client calls the GitHub API.
+type Client struct {
+	BaseURL  string
+	WebURL   string
+	Token    string
+	HTTP     *http.Client
+	CacheDir string // "" disables the cache
+	Attempts int
+
+	sleep func(time.Duration)
+}
+
+// New returns a client with goup-util's token (see Token) and the cache in
+// goup-util's cache directory.
+func New() *Client {
+	return &Client{
+		BaseURL:  BaseURL,
+		WebURL:   WebURL,
+		Token:    Token(),
+		HTTP:     &http.Client{Timeout: 30 * time.Second},
+		CacheDir: filepath.Join(config.GetCacheDir(), "github-api"),
+		Attempts: 4,
+		sleep:    time.Sleep,
+	}
+}
+
+// StatusError is a response other than 200, 304 or 404.
+type StatusError struct {
+	Code    int
+	Message string
+	Reset   time.Time // When a spent rate limit resets
+}
+
+func (e *StatusError) Error() string {
+	if !e.Reset.IsZero() {
+		msg := fmt.Sprintf("GitHub API rate limit exceeded until %s", e.Reset.Local().Format("15:04"))
+		if e.Message != "" {
+			msg += " (" + e.Message + ")"
+		}
+		return msg + "; set GITHUB_TOKEN for a higher limit"
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("GitHub API: %s (%d)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("GitHub API: %d %s", e.Code, http.StatusText(e.Code))
+}
+
+// entry is a cached response.
+type entry struct {
+	ETag string          `json:"etag"`
+	Body json.RawMessage `json:"body"`
+}
+
+// Get fetches path (relative to BaseURL, or an absolute URL) and decodes
+// the JSON response into out.
+func (c *Client) Get(path string, out any) error {
+	u := path
+	if !strings.Contains(path, "://") {
+		u = strings.TrimSuffix(c.BaseURL, "/") + path
+	}
+	cached := c.load(u)
+
+	wait := time.Second
+	var err error
+	for attempt := 1; ; attempt++ {
+		var body []byte
+		if body, err = c.fetch(u, cached); err == nil {
+			return json.Unmarshal(body, out)
+		}
+		delay, ok := retryAfter(err, wait)
+		if !ok || attempt >= max(c.Attempts, 1) {
+			break
+		}
+		c.sleep(delay)
+		wait *= 2
+	}
+	if cached != nil && stale(err) {
+		return json.Unmarshal(cached.Body, out)
+	}
+	return err
+}
+
+// stale reports whether a cached response may stand in after err: not
+// when the resource is gone or the token was refused.
+func stale(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) && se.Code == http.StatusUnauthorized {
+		return false
+	}
+	return !errors.Is(err, ErrNotFound)
+}
+
+// retryAfter says how long to wait before retrying after err: until the
+// rate limit resets, or wait for network and server errors.
+func retryAfter(err error, wait time.Duration) (time.Duration, bool) {
+	if errors.Is(err, ErrNotFound) {
+		return 0, false
+	}
+	var se *StatusError
+	if !errors.As(err, &se) {
+		return wait, true
+	}
+	if !se.Reset.IsZero() {
+		until := time.Until(se.Reset)
+		return max(until, time.Second), until <= MaxRateLimitWait
+	}
+	return wait, se.Code >= 500 || se.Code == http.StatusTooManyRequests
+}
+
+// Post sends in as JSON to path (relative to BaseURL) and decodes the
+// response into out, which may be nil. Posts are not retried.
+func (c *Client) Post(path string, in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest("POST", strings.TrimSuffix(c.BaseURL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return statusError(resp, body)
+	}
+	if out != nil {
+		return json.Unmarshal(body, out)
+	}
+	return nil
+}
+
+// newRequest sets the API headers, and the token for BaseURL's host.
+func (c *Client) newRequest(method, u string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" && sameHost(u, c.BaseURL) {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return req, nil
+}
+
+// fetch makes one request, revalidating cached.
+func (c *Client) fetch(u string, cached *entry) ([]byte, error) {
+	req, err := c.newRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached.Body, nil
+	case resp.StatusCode == http.StatusOK:
+		if etag := resp.Header.Get("ETag"); etag != "" && json.Valid(body) {
+			c.store(u, &entry{ETag: etag, Body: body})
+		}
+		return body, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", u, ErrNotFound)
+	}
+	return nil, statusError(resp, body)
+}
+
+// statusError describes a failed response, with the reset time when the
+// rate limit is spent.
+func statusError(resp *http.Response, body []byte) error {
+	var e struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &e)
+	se := &StatusError{Code: resp.StatusCode, Message: e.Message}
+	if (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) &&
+		resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			se.Reset = time.Unix(reset, 0)
+		}
+	}
+	if se.Reset.IsZero() && resp.StatusCode == http.StatusForbidden && resp.Header.Get("Retry-After") != "" {
+		se.Code = http.StatusTooManyRequests // Secondary rate limit: retry
+	}
+	return se
+}
+
+func (c *Client) cachePath(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	return filepath.Join(c.CacheDir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func (c *Client) load(u string) *entry {
+	if c.CacheDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.cachePath(u))
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if json.Unmarshal(data, &e) != nil || len(e.Body) == 0 {
+		return nil
+	}
+	return &e
+}
+
+// store writes an entry; the cache is best effort.
+func (c *Client) store(u string, e *entry) {
+	if c.CacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil || os.MkdirAll(c.CacheDir, 0755) != nil {
+		return
+	}
+	tmp := c.cachePath(u) + ".tmp"
+	if os.WriteFile(tmp, data, 0644) == nil {
+		os.Rename(tmp, c.cachePath(u))
+	}
+}
+
+func sameHost(a, b string) bool {
+	ua, err1 := url.Parse(a)
+	ub, err2 := url.Parse(b)
+	return err1 == nil && err2 == nil && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	URL                string `json:"url"` // API URL, which serves private assets
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Digest             string `json:"digest,omitempty"` // "sha256:<hex>"
+}
+
+// Release is a GitHub release, or an update server's answer in the same
+// format.
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Prerelease  bool      `json:"prerelease"`
+	Assets      []Asset   `json:"assets"`
+
+	RolloutPercentage *int `json:"rollout_percentage,omitempty"` // Update server only
+}
+
+// LatestRelease returns the latest release of repo (owner/name).
+func (c *Client) LatestRelease(repo string) (*Release, error) {
+	var r Release
+	if err := c.Get("/repos/"+repo+"/releases/latest", &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ReleaseByTag returns the release for tag; the error wraps ErrNotFound
+// when there is none.
+func (c *Client) ReleaseByTag(repo, tag string) (*Release, error) {
+	var r Release
+	if err := c.Get("/repos/"+repo+"/releases/tags/"+url.PathEscape(tag), &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Download writes the asset to w. With a token, assets are fetched through
+// the API so those of private repositories work too; GitHub redirects to
+// storage elsewhere, and the token is not sent there.
+func (c *Client) Download(a Asset, w io.Writer) error {
+	return c.DownloadFrom(a, 0, w)
+}
+
+// DownloadFrom writes the asset from byte offset on to w, to resume an
+// interrupted download. Servers that ignore the range are read from the
+// start, skipping what the caller has.
+func (c *Client) DownloadFrom(a Asset, offset int64, w io.Writer) error {
+	u := a.BrowserDownloadURL
+	api := c.Token != "" && a.URL != "" && sameHost(a.URL, c.BaseURL)
+	if api {
+		u = a.URL
+	}
+	req, err := c.newRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	if api {
+		req.Header.Set("Accept", "application/octet-stream")
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	dl := *c.HTTP
+	dl.Timeout = 0 // Large assets take longer than API calls
+	resp, err := dl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", a.Name, ErrNotFound)
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return statusError(resp, body)
+	}
+	if err := SkipTo(resp, offset); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// SkipTo reads past the first offset bytes of a full (200) response to a
+// range request; a 206 response already starts at offset.
+func SkipTo(resp *http.Response, offset int64) error {
+	if offset == 0 || resp.StatusCode == http.StatusPartialContent {
+		return nil
+	}
+	_, err := io.CopyN(io.Discard, resp.Body, offset)
+	return err
+}
+
+// Issue is a GitHub issue.
+type Issue struct {
+	Number  int      `json:"number,omitempty"`
+	Title   string   `json:"title"`
+	Body    string   `json:"body,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+	HTMLURL string   `json:"html_url,omitempty"`
+}
+
+// CreateIssue opens an issue in repo. The token needs issues: write.
+func (c *Client) CreateIssue(repo string, issue Issue) (*Issue, error) {
+	var created struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := c.Post("/repos/"+repo+"/issues", issue, &created); err != nil {
+		return nil, err
+	}
+	issue.Number, issue.HTMLURL = created.Number, created.HTMLURL
+	return &issue, nil
+}
+
+// User returns the login the token belongs to.
+func (c *Client) User() (string, error) {
+	var u struct {
+		Login string `json:"login"`
+	}
+	if err := c.Get("/user", &u); err != nil {
+		return "", err
+	}
+	return u.Login, nil
+}
@@ -0,0 +1,238 @@
+package ghapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testClient(t *testing.T, srv *httptest.Server) *Client {
+	t.Helper()
+	return &Client{
+		BaseURL:  srv.URL,
+		WebURL:   srv.URL,
+		Token:    "token",
+		HTTP:     srv.Client(),
+		CacheDir: t.TempDir(),
+		Attempts: 3,
+		sleep:    func(time.Duration) {},
+	}
+}
+
+func TestETagCache(t *testing.T) {
+	var revalidated int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"tag_name":"v1.2.3","assets":[{"name":"app.zip"}]}`)
+	}))
+	defer srv.Close()
+
+	c := testClient(t, srv)
+	for i := 0; i < 2; i++ {
+		r, err := c.LatestRelease("acme/app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.TagName != "v1.2.3" || len(r.Assets) != 1 {
+			t.Errorf("release = %+v", r)
+		}
+	}
+	if revalidated != 1 {
+		t.Errorf("revalidated %d times, want 1", revalidated)
+	}
+}
+
+func TestTokenOnlyForBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("token sent to another host: %q", auth)
+		}
+		fmt.Fprint(w, `{"tag_name":"v1.0.0"}`)
+	}))
+	defer srv.Close()
+
+	c := testClient(t, srv)
+	c.BaseURL = BaseURL
+	var r Release
+	if err := c.Get(srv.URL+"/api/latest", &r); err != nil || r.TagName != "v1.0.0" {
+		t.Errorf("Get = %+v, %v", r, err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls++; calls < 3 {
+			http.Error(w, `{"message":"Server Error"}`, http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"tag_name":"v1.0.0"}`)
+	}))
+	defer srv.Close()
+
+	c := testClient(t, srv)
+	var waits []time.Duration
+	c.sleep = func(d time.Duration) { waits = append(waits, d) }
+	if _, err := c.LatestRelease("acme/app"); err != nil {
+		t.Fatal(err)
+	}
+	if len(waits) != 2 || waits[1] != 2*waits[0] {
+		t.Errorf("waits = %v, want two doubling waits", waits)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limited := false
+	reset := time.Now().Add(time.Hour).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limited {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset))
+			http.Error(w, `{"message":"API rate limit exceeded"}`, http.StatusForbidden)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"tag_name":"v1.0.0"}`)
+	}))
+	defer srv.Close()
+
+	c := testClient(t, srv)
+	c.sleep = func(time.Duration) { t.Error("waited for a reset an hour away") }
+	limited = true
+	_, err := c.LatestRelease("acme/app")
+	var se *StatusError
+	if !errors.As(err, &se) || se.Reset.Unix() != reset || !strings.Contains(err.Error(), "GITHUB_TOKEN") {
+		t.Errorf("err = %v, want a rate limit error", err)
+	}
+
+	limited = false
+	if _, err := c.LatestRelease("acme/app"); err != nil {
+		t.Fatal(err)
+	}
+	limited = true
+	r, err := c.LatestRelease("acme/app")
+	if err != nil || r.TagName != "v1.0.0" {
+		t.Errorf("cached fallback = %+v, %v", r, err)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/repos/acme/app/releases/tags/v1.0.0" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := testClient(t, srv).ReleaseByTag("acme/app", "v1.0.0")
+	if !errors.Is(err, ErrNotFound) || calls != 1 {
+		t.Errorf("err = %v after %d calls, want one ErrNotFound", err, calls)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/app/releases/assets/7", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/octet-stream" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		http.Redirect(w, r, "/storage/app.zip?signature=x", http.StatusFound)
+	})
+	mux.HandleFunc("GET /storage/app.zip", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "private zip")
+	})
+	mux.HandleFunc("GET /download/app.zip", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "public zip")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	a := Asset{Name: "app.zip", URL: srv.URL + "/repos/acme/app/releases/assets/7", BrowserDownloadURL: srv.URL + "/download/app.zip"}
+	c := testClient(t, srv)
+	var buf bytes.Buffer
+	if err := c.Download(a, &buf); err != nil || buf.String() != "private zip" {
+		t.Errorf("with a token: %q, %v", buf.String(), err)
+	}
+	c.Token = ""
+	buf.Reset()
+	if err := c.Download(a, &buf); err != nil || buf.String() != "public zip" {
+		t.Errorf("without a token: %q, %v", buf.String(), err)
+	}
+}
+
+func TestCreateIssue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/acme/app/issues" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("%s %s (Authorization %q)", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var in Issue
+		json.NewDecoder(r.Body).Decode(&in)
+		if in.Title != "Crash" || len(in.Labels) != 1 {
+			t.Errorf("issue = %+v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number":12,"html_url":"https://github.com/acme/app/issues/12","labels":[{"name":"telemetry"}]}`)
+	}))
+	defer srv.Close()
+
+	issue, err := testClient(t, srv).CreateIssue("acme/app", Issue{Title: "Crash", Labels: []string{"telemetry"}})
+	if err != nil || issue.Number != 12 {
+		t.Errorf("CreateIssue = %+v, %v", issue, err)
+	}
+}
+
+func TestDeviceFlow(t *testing.T) {
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != "client" || r.FormValue("scope") != "repo" {
+			t.Errorf("form = %v", r.Form)
+		}
+		fmt.Fprint(w, `{"device_code":"dev","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device","expires_in":900,"interval":5}`)
+	})
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		switch polls++; polls {
+		case 1:
+			fmt.Fprint(w, `{"error":"authorization_pending"}`)
+		case 2:
+			fmt.Fprint(w, `{"error":"slow_down","interval":10}`)
+		default:
+			fmt.Fprint(w, `{"access_token":"gho_token","token_type":"bearer"}`)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := testClient(t, srv)
+	var waits []time.Duration
+	c.sleep = func(d time.Duration) { waits = append(waits, d) }
+	dc, err := c.StartDeviceFlow("client", "repo")
+	if err != nil || dc.UserCode != "ABCD-1234" {
+		t.Fatalf("StartDeviceFlow = %+v, %v", dc, err)
+	}
+	token, err := c.WaitForToken("client", dc)
+	if err != nil || token != "gho_token" {
+		t.Fatalf("WaitForToken = %q, %v", token, err)
+	}
+	if fmt.Sprint(waits) != "[5s 5s 10s]" {
+		t.Errorf("waits = %v", waits)
+	}
+}
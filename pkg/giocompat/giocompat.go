@@ -0,0 +1,313 @@
+// Package giocompat checks the gioui.org and gio-plugins versions in an
+// app's go.mod against a small compatibility matrix. Mismatched versions
+// show up as cryptic compile errors in the plugins or as runtime panics in
+// webviews, far from their cause.
+//
+// The matrix is fetched from the goup-util repository, so new
+// incompatibilities reach users without a release, and is cached for a
+// day. Offline, the cached or embedded copy is used.
+package giocompat
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Module paths the matrix covers.
+const (
+	GioModule     = "gioui.org"
+	PluginsModule = "github.com/gioui-plugins/gio-plugins"
+)
+
+// MatrixURL is where the current matrix is published; GOUP_GIO_MATRIX_URL
+// overrides it.
+const MatrixURL = "https://raw.githubusercontent.com/joeblew999/goup-util/main/pkg/giocompat/matrix.json"
+
+//go:embed matrix.json
+var embeddedMatrix []byte
+
+// Pair is a Gio and gio-plugins version combination.
+type Pair struct {
+	Gio     string `json:"gio"`
+	Plugins string `json:"plugins"`
+}
+
+// Rule flags the combinations matching both constraints. A constraint is
+// a space-separated list of comparisons such as ">=v0.9.1 <v0.10.0"; an
+// empty one matches any version, including none.
+type Rule struct {
+	Gio      string `json:"gio,omitempty"`
+	Plugins  string `json:"plugins,omitempty"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Message  string `json:"message"`
+}
+
+// Matrix is the compatibility data.
+type Matrix struct {
+	Recommended Pair   `json:"recommended"`
+	Rules       []Rule `json:"rules"`
+}
+
+// Problem is an incompatibility found in a go.mod.
+type Problem struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Fix      string `json:"fix,omitempty"`
+}
+
+// Versions are the Gio module versions an app's go.mod selects. A
+// module replaced by a local directory keeps its required version and
+// records the directory.
+type Versions struct {
+	GoMod      string `json:"gomod"`
+	Gio        string `json:"gio,omitempty"`
+	Plugins    string `json:"plugins,omitempty"`
+	GioDir     string `json:"gioDir,omitempty"`
+	PluginsDir string `json:"pluginsDir,omitempty"`
+}
+
+// Parse decodes a matrix.
+func Parse(data []byte) (*Matrix, error) {
+	var m Matrix
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid compatibility matrix: %w", err)
+	}
+	return &m, nil
+}
+
+// Embedded returns the matrix built into goup-util.
+func Embedded() *Matrix {
+	m, err := Parse(embeddedMatrix)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Load returns the published matrix, cached in cacheDir for a day, falling
+// back to a stale cached copy and then the embedded one when offline.
+func Load(cacheDir string) *Matrix {
+	cached := filepath.Join(cacheDir, "gio-compat.json")
+	if info, err := os.Stat(cached); err == nil && time.Since(info.ModTime()) < 24*time.Hour {
+		if m, err := readMatrix(cached); err == nil {
+			return m
+		}
+	}
+
+	if data, err := fetch(); err == nil {
+		if m, err := Parse(data); err == nil {
+			if err := os.MkdirAll(cacheDir, 0755); err == nil {
+				os.WriteFile(cached, data, 0644)
+			}
+			return m
+		}
+	}
+	if m, err := readMatrix(cached); err == nil {
+		return m
+	}
+	return Embedded()
+}
+
+func readMatrix(path string) (*Matrix, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func fetch() ([]byte, error) {
+	url := os.Getenv("GOUP_GIO_MATRIX_URL")
+	if url == "" {
+		url = MatrixURL
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// FindGoMod returns the go.mod governing dir.
+func FindGoMod(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod found for %s", dir)
+		}
+		dir = parent
+	}
+}
+
+// ReadVersions reads the Gio module versions from a go.mod.
+func ReadVersions(goModPath string) (*Versions, error) {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.Parse(goModPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", goModPath, err)
+	}
+	v := &Versions{GoMod: goModPath}
+	for _, r := range f.Require {
+		switch r.Mod.Path {
+		case GioModule:
+			v.Gio = r.Mod.Version
+		case PluginsModule:
+			v.Plugins = r.Mod.Version
+		}
+	}
+	for _, r := range f.Replace {
+		if r.Old.Version != "" {
+			continue // Version-specific replacements are rare for these
+		}
+		dir := ""
+		if r.New.Version == "" {
+			dir = r.New.Path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(filepath.Dir(goModPath), dir)
+			}
+		}
+		switch r.Old.Path {
+		case GioModule:
+			v.GioDir = dir
+			if r.New.Version != "" {
+				v.Gio = r.New.Version
+			}
+		case PluginsModule:
+			v.PluginsDir = dir
+			if r.New.Version != "" {
+				v.Plugins = r.New.Version
+			}
+		}
+	}
+	return v, nil
+}
+
+// Check returns the problems the matrix and local replacements reveal.
+func (m *Matrix) Check(v *Versions) []Problem {
+	var problems []Problem
+	for _, r := range m.Rules {
+		if matchAll(r.Gio, v.Gio) && matchAll(r.Plugins, v.Plugins) {
+			problems = append(problems, Problem{Severity: r.Severity, Message: r.Message, Fix: m.fix()})
+		}
+	}
+	for _, local := range []struct{ module, version, dir string }{
+		{GioModule, v.Gio, v.GioDir},
+		{PluginsModule, v.Plugins, v.PluginsDir},
+	} {
+		if p := checkCheckout(local.module, local.version, local.dir); p != nil {
+			problems = append(problems, *p)
+		}
+	}
+	return problems
+}
+
+// fix is the command that moves an app to the recommended pair.
+func (m *Matrix) fix() string {
+	gio := m.Recommended.Gio
+	if rev, err := module.PseudoVersionRev(gio); err == nil {
+		gio = rev
+	}
+	return fmt.Sprintf("go get %s@%s %s@%s && go mod tidy", GioModule, gio, PluginsModule, m.Recommended.Plugins)
+}
+
+// checkCheckout compares a local replacement's git commit with the
+// pseudo-version go.mod requires; the checkout is what actually builds.
+func checkCheckout(modulePath, version, dir string) *Problem {
+	if dir == "" {
+		return nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return &Problem{Severity: "error", Message: fmt.Sprintf("%s is replaced by %s, which does not exist", modulePath, dir),
+			Fix: "check out the source there, or remove the replace directive"}
+	}
+	rev, err := module.PseudoVersionRev(version)
+	if err != nil {
+		return nil // A tagged version: nothing to compare a checkout with
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return nil
+	}
+	head := strings.TrimSpace(string(out))
+	if strings.HasPrefix(head, rev) {
+		return nil
+	}
+	return &Problem{Severity: "warning",
+		Message: fmt.Sprintf("%s is replaced by %s at %.12s, but go.mod requires %s", modulePath, dir, head, rev),
+		Fix:     fmt.Sprintf("git -C %s checkout %s", dir, rev)}
+}
+
+// matchAll reports whether version satisfies every comparison in
+// constraint. An empty constraint matches anything; otherwise a missing
+// version never matches.
+func matchAll(constraint, version string) bool {
+	if constraint == "" {
+		return true
+	}
+	if version == "" {
+		return false
+	}
+	for _, c := range strings.Fields(constraint) {
+		if !match(c, version) {
+			return false
+		}
+	}
+	return true
+}
+
+func match(c, version string) bool {
+	op := strings.TrimRight(c[:min(2, len(c))], "v0123456789")
+	want := strings.TrimPrefix(c, op)
+	cmp := semver.Compare(version, want)
+	switch op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	case "=", "":
+		return cmp == 0
+	}
+	return false
+}
+
+// Errors reports whether any problem is an error rather than a warning.
+func Errors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package giocompat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const tested = "v0.9.1-0.20251215212054-7bcb315ee174"
+
+func TestCheck(t *testing.T) {
+	m := Embedded()
+	for _, tc := range []struct {
+		name     string
+		versions Versions
+		want     []string // Severities
+	}{
+		{"recommended", Versions{Gio: tested, Plugins: "v0.9.1"}, nil},
+		{"older gio", Versions{Gio: "v0.9.0", Plugins: "v0.9.1"}, []string{"error"}},
+		{"older pseudo-version", Versions{Gio: "v0.9.1-0.20251001000000-aaaaaaaaaaaa", Plugins: "v0.9.1"}, []string{"error"}},
+		{"newer gio", Versions{Gio: "v0.9.1-0.20260301000000-bbbbbbbbbbbb", Plugins: "v0.9.1"}, []string{"warning"}},
+		{"no plugins", Versions{Gio: "v0.8.0"}, nil},
+	} {
+		problems := m.Check(&tc.versions)
+		if len(problems) != len(tc.want) {
+			t.Errorf("%s: problems = %+v", tc.name, problems)
+			continue
+		}
+		for i, p := range problems {
+			if p.Severity != tc.want[i] {
+				t.Errorf("%s: severity = %s, want %s", tc.name, p.Severity, tc.want[i])
+			}
+		}
+	}
+
+	if fix := m.fix(); fix != "go get gioui.org@7bcb315ee174 github.com/gioui-plugins/gio-plugins@v0.9.1 && go mod tidy" {
+		t.Errorf("fix = %q", fix)
+	}
+}
+
+func TestReadVersions(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte(`module main
+
+go 1.24
+
+replace gioui.org => ../missing-gio
+
+require (
+	gioui.org `+tested+`
+	github.com/gioui-plugins/gio-plugins v0.9.1
+)
+`), 0644)
+	sub := filepath.Join(dir, "cmd", "app")
+	os.MkdirAll(sub, 0755)
+
+	path, err := FindGoMod(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := ReadVersions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Gio != tested || v.Plugins != "v0.9.1" || v.GioDir != filepath.Join(filepath.Dir(dir), "missing-gio") {
+		t.Errorf("versions = %+v", v)
+	}
+	problems := Embedded().Check(v)
+	if len(problems) != 1 || !Errors(problems) {
+		t.Errorf("missing replacement: %+v", problems)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	remote := `{"recommended": {"gio": "v0.10.0", "plugins": "v0.10.0"}, "rules": []}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(remote))
+	}))
+	t.Setenv("GOUP_GIO_MATRIX_URL", srv.URL)
+	cache := t.TempDir()
+
+	if m := Load(cache); m.Recommended.Gio != "v0.10.0" {
+		t.Errorf("remote matrix not used: %+v", m.Recommended)
+	}
+
+	// Offline, the cached copy is used, then the embedded one
+	srv.Close()
+	if m := Load(cache); m.Recommended.Gio != "v0.10.0" {
+		t.Errorf("cached matrix not used: %+v", m.Recommended)
+	}
+	if m := Load(t.TempDir()); m.Recommended.Gio != tested {
+		t.Errorf("embedded matrix not used: %+v", m.Recommended)
+	}
+}
@@ -0,0 +1,104 @@
+// Package googleauth gets OAuth access tokens for a Google service account
+// key (the JSON file downloaded from the Cloud console), as used by the
+// Play Developer and Firebase App Distribution APIs.
+package googleauth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Source mints and caches access tokens for one service account and scope.
+type Source struct {
+	Email string
+	scope string
+
+	tokenURI string
+	key      *rsa.PrivateKey
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// New parses a service account JSON key.
+func New(serviceAccount []byte, scope string) (*Source, error) {
+	var sa struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(serviceAccount, &sa); err != nil {
+		return nil, fmt.Errorf("service account is not a JSON key file: %w", err)
+	}
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil || sa.ClientEmail == "" {
+		return nil, fmt.Errorf("service account key has no client_email or private_key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("service account key is not an RSA key")
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &Source{Email: sa.ClientEmail, scope: scope, tokenURI: sa.TokenURI, key: key}, nil
+}
+
+// Token exchanges a signed JWT for an access token, reusing it until
+// shortly before it expires.
+func (s *Source) Token(client *http.Client) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.token != "" && now.Before(s.expires.Add(-time.Minute)) {
+		return s.token, nil
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss": s.Email, "scope": s.scope, "aud": s.tokenURI,
+		"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	signing := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {signing + "." + enc.EncodeToString(sig)},
+	}
+	resp, err := client.PostForm(s.tokenURI, form)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Google access token: %w", err)
+	}
+	defer resp.Body.Close()
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil || tok.AccessToken == "" {
+		return "", fmt.Errorf("failed to get Google access token: %s %s", resp.Status, tok.Error)
+	}
+	s.token, s.expires = tok.AccessToken, now.Add(time.Duration(tok.ExpiresIn)*time.Second)
+	return s.token, nil
+}
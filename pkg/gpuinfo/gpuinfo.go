@@ -0,0 +1,317 @@
+// Package gpuinfo reports what a Gio app will render with on this
+// machine: the GPUs and their drivers, which graphics APIs are available,
+// the backend Gio picks from them, and whether the system webview is GPU
+// accelerated. It backs 'goup-util gpu-info', for triaging "blank window"
+// and rendering glitch reports from end users.
+package gpuinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// GPU is one graphics adapter.
+type GPU struct {
+	Name   string `json:"name"`
+	Vendor string `json:"vendor,omitempty"`
+	Driver string `json:"driver,omitempty"` // Driver version
+	Memory string `json:"memory,omitempty"`
+	Metal  string `json:"metal,omitempty"` // Metal family (macOS)
+}
+
+// API is a graphics API Gio can render with.
+type API struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Detail    string `json:"detail,omitempty"` // Version or renderer, or why it is unavailable
+}
+
+// WebView describes the system webview's rendering.
+type WebView struct {
+	Engine      string `json:"engine"`
+	Accelerated bool   `json:"accelerated"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+// Report is everything gpu-info shows.
+type Report struct {
+	Platform string `json:"platform"`
+	GPUs     []GPU  `json:"gpus"`
+	APIs     []API  `json:"apis"`
+
+	// Backend is the API Gio will use: the first available one in
+	// Gio's order of preference for the platform.
+	Backend string  `json:"backend"`
+	WebView WebView `json:"webview"`
+
+	// SoftwareRender says what a shell's --software-render flag changes
+	// here.
+	SoftwareRender string `json:"softwareRender"`
+}
+
+// Tests replace these.
+var (
+	goos     = runtime.GOOS
+	lookPath = exec.LookPath
+	output   = func(name string, args ...string) (string, error) {
+		out, err := exec.Command(name, args...).Output()
+		return string(out), err
+	}
+	exists = func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	getenv = os.Getenv
+)
+
+// Detect inspects the running machine.
+func Detect() (*Report, error) {
+	r := &Report{Platform: goos + "/" + runtime.GOARCH}
+	var err error
+	switch goos {
+	case "darwin":
+		err = detectMacOS(r)
+	case "windows":
+		err = detectWindows(r)
+	case "linux":
+		err = detectLinux(r)
+	default:
+		return nil, fmt.Errorf("gpu-info is not supported on %s", goos)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range r.APIs {
+		if a.Available {
+			r.Backend = a.Name
+			break
+		}
+	}
+	if r.Backend == "" {
+		r.Backend = "none (Gio windows will fail to open)"
+	}
+	return r, nil
+}
+
+// detectMacOS reads system_profiler. Gio renders with Metal, or OpenGL
+// when built with -tags nometal.
+func detectMacOS(r *Report) error {
+	out, err := output("system_profiler", "SPDisplaysDataType", "-json")
+	if err != nil {
+		return fmt.Errorf("system_profiler failed: %w", err)
+	}
+	gpus, err := parseSystemProfiler(out)
+	if err != nil {
+		return err
+	}
+	r.GPUs = gpus
+	metal := API{Name: "Metal"}
+	for _, g := range gpus {
+		if g.Metal != "" {
+			metal.Available, metal.Detail = true, g.Metal
+		}
+	}
+	if !metal.Available {
+		metal.Detail = "no Metal-capable GPU reported"
+	}
+	r.APIs = []API{metal, {Name: "OpenGL", Available: true, Detail: "deprecated; used with -tags nometal"}}
+	r.WebView = WebView{Engine: "WKWebView", Accelerated: metal.Available, Detail: "always composited by WebKit; cannot be switched off"}
+	r.SoftwareRender = "no effect: WKWebView and Gio's Metal renderer have no software mode"
+	return nil
+}
+
+func parseSystemProfiler(out string) ([]GPU, error) {
+	var doc struct {
+		Displays []map[string]any `json:"SPDisplaysDataType"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse system_profiler output: %w", err)
+	}
+	str := func(m map[string]any, keys ...string) string {
+		for _, k := range keys {
+			if s, ok := m[k].(string); ok && s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	var gpus []GPU
+	for _, d := range doc.Displays {
+		g := GPU{
+			Name:   str(d, "sppci_model", "_name"),
+			Vendor: strings.TrimPrefix(str(d, "spdisplays_vendor"), "sppci_vendor_"),
+			Memory: str(d, "spdisplays_vram", "spdisplays_vram_shared"),
+			Metal:  str(d, "spdisplays_mtlgpufamilysupport", "spdisplays_metal", "spdisplays_metalfamily"),
+		}
+		g.Metal = strings.ReplaceAll(strings.TrimPrefix(g.Metal, "spdisplays_"), "metal", "Metal ")
+		g.Metal = strings.TrimSpace(g.Metal)
+		if cores := str(d, "sppci_cores"); cores != "" {
+			g.Memory = strings.TrimSpace(g.Memory + " (" + cores + " cores)")
+		}
+		gpus = append(gpus, g)
+	}
+	return gpus, nil
+}
+
+// detectWindows asks WMI for the adapters. Gio tries Direct3D 11, then
+// OpenGL ES through ANGLE's libEGL.dll if it ships next to the app.
+func detectWindows(r *Report) error {
+	out, err := output("powershell", "-NoProfile", "-NonInteractive", "-Command",
+		"ConvertTo-Json -InputObject @(Get-CimInstance Win32_VideoController | Select-Object Name,AdapterCompatibility,DriverVersion,AdapterRAM)")
+	if err != nil {
+		return fmt.Errorf("failed to query video controllers: %w", err)
+	}
+	gpus, err := parseVideoControllers(out)
+	if err != nil {
+		return err
+	}
+	r.GPUs = gpus
+
+	system := filepath.Join(getenv("SystemRoot"), "System32")
+	d3d := API{Name: "Direct3D 11", Available: exists(filepath.Join(system, "d3d11.dll"))}
+	if !d3d.Available {
+		d3d.Detail = "d3d11.dll not found"
+	}
+	r.APIs = []API{
+		d3d,
+		{Name: "OpenGL ES (ANGLE)", Available: false, Detail: "only if libEGL.dll and libGLESv2.dll ship next to the app"},
+		{Name: "Vulkan", Available: exists(filepath.Join(system, "vulkan-1.dll")), Detail: "not used by Gio on Windows"},
+	}
+
+	args := getenv("WEBVIEW2_ADDITIONAL_BROWSER_ARGUMENTS")
+	r.WebView = WebView{Engine: "WebView2 (Chromium)", Accelerated: !strings.Contains(args, "--disable-gpu")}
+	if args != "" {
+		r.WebView.Detail = "WEBVIEW2_ADDITIONAL_BROWSER_ARGUMENTS=" + args
+	}
+	r.SoftwareRender = "WebView2 starts with --disable-gpu; Gio keeps Direct3D 11"
+	return nil
+}
+
+func parseVideoControllers(out string) ([]GPU, error) {
+	var controllers []struct {
+		Name                 string
+		AdapterCompatibility string
+		DriverVersion        string
+		AdapterRAM           int64
+	}
+	if err := json.Unmarshal([]byte(out), &controllers); err != nil {
+		return nil, fmt.Errorf("failed to parse video controllers: %w", err)
+	}
+	var gpus []GPU
+	for _, c := range controllers {
+		g := GPU{Name: c.Name, Vendor: c.AdapterCompatibility, Driver: c.DriverVersion}
+		if c.AdapterRAM > 0 {
+			g.Memory = fmt.Sprintf("%d MB", c.AdapterRAM>>20)
+		}
+		gpus = append(gpus, g)
+	}
+	return gpus, nil
+}
+
+// detectLinux uses lspci, glxinfo/eglinfo and vulkaninfo where installed.
+// Gio uses OpenGL ES through EGL; on Wayland it falls back to Vulkan.
+func detectLinux(r *Report) error {
+	if _, err := lookPath("lspci"); err == nil {
+		if out, err := output("lspci", "-mm"); err == nil {
+			r.GPUs = parseLspci(out)
+		}
+	}
+
+	gl := API{Name: "OpenGL ES (EGL)"}
+	if _, err := lookPath("glxinfo"); err == nil {
+		if out, err := output("glxinfo", "-B"); err == nil {
+			renderer, version := parseGlxinfo(out)
+			gl.Available = renderer != ""
+			gl.Detail = strings.TrimSpace(renderer + ", " + version)
+			if len(r.GPUs) > 0 && version != "" && r.GPUs[0].Driver == "" {
+				r.GPUs[0].Driver = version
+			}
+		}
+	} else {
+		gl.Available = exists("/usr/lib/x86_64-linux-gnu/libEGL.so.1") || exists("/usr/lib64/libEGL.so.1") || exists("/usr/lib/libEGL.so.1") || exists("/usr/lib/aarch64-linux-gnu/libEGL.so.1")
+		gl.Detail = "install mesa-utils (glxinfo) for the renderer"
+	}
+
+	vk := API{Name: "Vulkan"}
+	if _, err := lookPath("vulkaninfo"); err == nil {
+		if out, err := output("vulkaninfo", "--summary"); err == nil {
+			vk.Available, vk.Detail = true, parseVulkanSummary(out)
+		} else {
+			vk.Detail = "vulkaninfo found no device"
+		}
+	} else {
+		vk.Detail = "install vulkan-tools (vulkaninfo) to check"
+	}
+	if getenv("WAYLAND_DISPLAY") == "" {
+		vk.Detail = strings.TrimSpace(vk.Detail + "; not used by Gio on X11")
+		vk.Available = false
+	}
+	r.APIs = []API{gl, vk}
+
+	r.WebView = WebView{Engine: "WebKitGTK", Accelerated: getenv("WEBKIT_DISABLE_COMPOSITING_MODE") == "" && getenv("LIBGL_ALWAYS_SOFTWARE") == ""}
+	if !r.WebView.Accelerated {
+		r.WebView.Detail = "compositing disabled by environment"
+	}
+	r.SoftwareRender = "Mesa renders with llvmpipe (LIBGL_ALWAYS_SOFTWARE=1) and WebKitGTK compositing is off"
+	return nil
+}
+
+var lspciLine = regexp.MustCompile(`"([^"]*)"`)
+
+// parseLspci returns the display controllers from 'lspci -mm'.
+func parseLspci(out string) []GPU {
+	var gpus []GPU
+	for _, line := range strings.Split(out, "\n") {
+		f := lspciLine.FindAllStringSubmatch(line, -1)
+		if len(f) < 3 {
+			continue
+		}
+		class := f[0][1]
+		if !strings.Contains(class, "VGA") && !strings.Contains(class, "3D") && !strings.Contains(class, "Display") {
+			continue
+		}
+		gpus = append(gpus, GPU{Name: f[2][1], Vendor: f[1][1]})
+	}
+	return gpus
+}
+
+// parseGlxinfo returns the renderer and version from 'glxinfo -B'.
+func parseGlxinfo(out string) (renderer, version string) {
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if v, ok := strings.CutPrefix(line, "OpenGL renderer string:"); ok {
+			renderer = strings.TrimSpace(v)
+		}
+		if v, ok := strings.CutPrefix(line, "OpenGL version string:"); ok {
+			version = strings.TrimSpace(v)
+		}
+	}
+	return renderer, version
+}
+
+// parseVulkanSummary returns the API version and device names from
+// 'vulkaninfo --summary'.
+func parseVulkanSummary(out string) string {
+	var version string
+	var devices []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if v, ok := strings.CutPrefix(line, "Vulkan Instance Version:"); ok && version == "" {
+			version = strings.TrimSpace(v)
+		}
+		if v, ok := strings.CutPrefix(line, "deviceName"); ok {
+			devices = append(devices, strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(v), "=")))
+		}
+	}
+	detail := version
+	if len(devices) > 0 {
+		detail = strings.TrimSpace(detail + " " + strings.Join(devices, ", "))
+	}
+	return detail
+}
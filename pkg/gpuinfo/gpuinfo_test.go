@@ -0,0 +1,110 @@
+package gpuinfo
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseSystemProfiler(t *testing.T) {
+	gpus, err := parseSystemProfiler(`{"SPDisplaysDataType": [{
+		"_name": "kHW_AppleM2ProItem",
+		"sppci_model": "Apple M2 Pro",
+		"sppci_cores": "19",
+		"spdisplays_vendor": "sppci_vendor_Apple",
+		"spdisplays_mtlgpufamilysupport": "spdisplays_metal3"
+	}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gpus) != 1 {
+		t.Fatalf("got %d GPUs", len(gpus))
+	}
+	g := gpus[0]
+	if g.Name != "Apple M2 Pro" || g.Vendor != "Apple" || g.Metal != "Metal 3" || g.Memory != "(19 cores)" {
+		t.Errorf("got %+v", g)
+	}
+}
+
+func TestParseVideoControllers(t *testing.T) {
+	gpus, err := parseVideoControllers(`[{"Name":"NVIDIA GeForce RTX 3060","AdapterCompatibility":"NVIDIA","DriverVersion":"31.0.15.5222","AdapterRAM":4293918720}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gpus) != 1 || gpus[0].Driver != "31.0.15.5222" || gpus[0].Memory != "4095 MB" {
+		t.Errorf("got %+v", gpus)
+	}
+}
+
+func TestParseLinuxTools(t *testing.T) {
+	gpus := parseLspci(`00:02.0 "VGA compatible controller" "Intel Corporation" "Alder Lake-P GT2 [Iris Xe Graphics]" -r0c "Lenovo" "Device 22fb"
+00:1f.3 "Audio device" "Intel Corporation" "Alder Lake PCH-P High Definition Audio Controller" -r01 "Lenovo" "Device 22fb"
+01:00.0 "3D controller" "NVIDIA Corporation" "GA107M [GeForce RTX 3050 Mobile]" -ra1 "Lenovo" "Device 22fb"
+`)
+	if len(gpus) != 2 || gpus[0].Vendor != "Intel Corporation" || !strings.Contains(gpus[1].Name, "RTX 3050") {
+		t.Errorf("parseLspci = %+v", gpus)
+	}
+
+	renderer, version := parseGlxinfo(`name of display: :0
+Extended renderer info (GLX_MESA_query_renderer):
+    Device: Mesa Intel(R) Graphics (ADL GT2) (0x46a6)
+OpenGL vendor string: Intel
+OpenGL renderer string: Mesa Intel(R) Graphics (ADL GT2)
+OpenGL version string: 4.6 (Compatibility Profile) Mesa 24.0.9
+`)
+	if renderer != "Mesa Intel(R) Graphics (ADL GT2)" || version != "4.6 (Compatibility Profile) Mesa 24.0.9" {
+		t.Errorf("parseGlxinfo = %q, %q", renderer, version)
+	}
+
+	vk := parseVulkanSummary(`Vulkan Instance Version: 1.3.275
+
+Devices:
+========
+GPU0:
+	apiVersion         = 1.3.274
+	deviceName         = Intel(R) Graphics (ADL GT2)
+GPU1:
+	deviceName         = llvmpipe (LLVM 17.0.6, 256 bits)
+`)
+	if vk != "1.3.275 Intel(R) Graphics (ADL GT2), llvmpipe (LLVM 17.0.6, 256 bits)" {
+		t.Errorf("parseVulkanSummary = %q", vk)
+	}
+}
+
+func TestDetectLinux(t *testing.T) {
+	oldGOOS, oldLook, oldOutput, oldExists, oldGetenv := goos, lookPath, output, exists, getenv
+	t.Cleanup(func() { goos, lookPath, output, exists, getenv = oldGOOS, oldLook, oldOutput, oldExists, oldGetenv })
+	goos = "linux"
+	lookPath = func(name string) (string, error) {
+		if name == "glxinfo" {
+			return "/usr/bin/glxinfo", nil
+		}
+		return "", exec.ErrNotFound
+	}
+	output = func(name string, args ...string) (string, error) {
+		if name == "glxinfo" {
+			return "OpenGL renderer string: llvmpipe (LLVM 17.0.6, 256 bits)\nOpenGL version string: 4.5 Mesa 24.0.9\n", nil
+		}
+		return "", errors.New("exit status 1")
+	}
+	env := map[string]string{"WEBKIT_DISABLE_COMPOSITING_MODE": "1"}
+	getenv = func(k string) string { return env[k] }
+
+	r, err := Detect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Backend != "OpenGL ES (EGL)" {
+		t.Errorf("Backend = %q", r.Backend)
+	}
+	if r.WebView.Accelerated {
+		t.Error("WebView reported accelerated with compositing disabled")
+	}
+	// Gio only uses Vulkan on Wayland
+	for _, a := range r.APIs {
+		if a.Name == "Vulkan" && a.Available {
+			t.Errorf("Vulkan available on X11: %+v", a)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package healthcheck lets shells report that they are alive, so
+// monitoring notices a dead kiosk: Monitor.Run pings a URL on a schedule
+// (healthchecks.io, Uptime Kuma push monitors, Better Stack heartbeats)
+// with a status payload, and Monitor.Handler serves the same status as
+// /healthz from an app's embedded server.
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often Run pings.
+const DefaultInterval = 5 * time.Minute
+
+// Status values.
+const (
+	OK   = "ok"
+	Fail = "fail"
+)
+
+// Status describes the running app.
+type Status struct {
+	Status   string            `json:"status"` // OK or Fail
+	App      string            `json:"app,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Host     string            `json:"host,omitempty"`
+	Platform string            `json:"platform,omitempty"`
+	Started  time.Time         `json:"started"`
+	Uptime   int64             `json:"uptimeSeconds"`
+	Checks   map[string]string `json:"checks,omitempty"` // Failing checks and why
+}
+
+// Monitor tracks the app's status. Checks are named problems set by the
+// app (e.g. "page": "load failed: timeout") and cleared when resolved;
+// any set check makes the status Fail.
+type Monitor struct {
+	App     string
+	Version string
+	Started time.Time
+
+	mu     sync.Mutex
+	checks map[string]string
+}
+
+// New returns a monitor for app, started now.
+func New(app, version string) *Monitor {
+	return &Monitor{App: app, Version: version, Started: time.Now()}
+}
+
+// Set records a failing check, or clears it when problem is "".
+func (m *Monitor) Set(check, problem string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if problem == "" {
+		delete(m.checks, check)
+		return
+	}
+	if m.checks == nil {
+		m.checks = map[string]string{}
+	}
+	m.checks[check] = problem
+}
+
+// Status returns the current status.
+func (m *Monitor) Status() Status {
+	host, _ := os.Hostname()
+	platform := runtime.GOOS
+	if platform == "darwin" {
+		platform = "macos"
+	}
+	s := Status{
+		Status:   OK,
+		App:      m.App,
+		Version:  m.Version,
+		Host:     host,
+		Platform: platform + "/" + runtime.GOARCH,
+		Started:  m.Started.UTC(),
+		Uptime:   int64(time.Since(m.Started).Seconds()),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.checks) > 0 {
+		s.Status = Fail
+		s.Checks = map[string]string{}
+		for k, v := range m.checks {
+			s.Checks[k] = v
+		}
+	}
+	return s
+}
+
+// Handler serves the status as JSON: 200 when OK, 503 otherwise.
+func (m *Monitor) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := m.Status()
+		code := http.StatusOK
+		if s.Status != OK {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(s)
+	})
+}
+
+// Ping posts the status to url. A failing status goes to url + "/fail",
+// which healthchecks.io and compatible services record as down.
+func Ping(ctx context.Context, url string, s Status) error {
+	if s.Status != OK {
+		url += "/fail"
+	}
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := (&http.Client{Timeout: 15 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("ping %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// Run pings url now and every interval (DefaultInterval if zero) until
+// ctx is done. Failures go to onError (if set) and never stop the loop;
+// the monitoring service alerts when pings stop arriving.
+func (m *Monitor) Run(ctx context.Context, url string, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := Ping(ctx, url, m.Status()); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMonitor(t *testing.T) {
+	m := New("acme-kiosk", "v1.2.0")
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	get := func() (int, Status) {
+		t.Helper()
+		resp, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var s Status
+		json.NewDecoder(resp.Body).Decode(&s)
+		return resp.StatusCode, s
+	}
+
+	if code, s := get(); code != http.StatusOK || s.Status != OK || s.App != "acme-kiosk" || s.Platform == "" {
+		t.Errorf("healthy: %d %+v", code, s)
+	}
+	m.Set("page", "load failed")
+	if code, s := get(); code != http.StatusServiceUnavailable || s.Status != Fail || s.Checks["page"] != "load failed" {
+		t.Errorf("failing: %d %+v", code, s)
+	}
+	m.Set("page", "")
+	if code, _ := get(); code != http.StatusOK {
+		t.Errorf("recovered: %d", code)
+	}
+}
+
+func TestRun(t *testing.T) {
+	pings := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s Status
+		if json.NewDecoder(r.Body).Decode(&s) != nil || s.App != "acme-kiosk" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		pings <- r.URL.Path
+	}))
+	defer srv.Close()
+
+	m := New("acme-kiosk", "")
+	m.Set("webview", "crashed")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx, srv.URL+"/ping/abc", 10*time.Millisecond, func(err error) { t.Error(err) })
+
+	select {
+	case path := <-pings:
+		if path != "/ping/abc/fail" {
+			t.Errorf("failing ping went to %s", path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no ping")
+	}
+	m.Set("webview", "")
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case path := <-pings:
+			if path == "/ping/abc" {
+				return
+			}
+		case <-deadline:
+			t.Fatal("no healthy ping")
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Package history records every build, SDK install, screenshot and release
+// in a local SQLite database, with timings and outcomes, for 'goup-util
+// history' and analytics exports. It complements the JSON caches (build
+// cache, SDK cache), which only keep the latest state.
+//
+// Recording is best effort: a locked or unwritable database never fails the
+// command being recorded. Set GOUP_HISTORY=off to disable it, or to a path
+// to use another database.
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+	_ "modernc.org/sqlite"
+)
+
+// Env disables history ("off") or moves the database.
+const Env = "GOUP_HISTORY"
+
+// Event kinds.
+const (
+	Build      = "build"
+	Install    = "install"
+	Screenshot = "screenshot"
+	Release    = "release"
+)
+
+// Outcomes.
+const (
+	OK     = "ok"
+	Failed = "failed"
+)
+
+// Event is one recorded operation.
+type Event struct {
+	ID       int64         `json:"id"`
+	Kind     string        `json:"kind"`
+	Name     string        `json:"name"`             // App, SDK or version
+	Target   string        `json:"target,omitempty"` // Platform, device, ...
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Artifact string        `json:"artifact,omitempty"`
+	Detail   string        `json:"detail,omitempty"` // e.g. the remote builder used
+	Host     string        `json:"host"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS events (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind        TEXT NOT NULL,
+	name        TEXT NOT NULL,
+	target      TEXT NOT NULL DEFAULT '',
+	status      TEXT NOT NULL,
+	error       TEXT NOT NULL DEFAULT '',
+	artifact    TEXT NOT NULL DEFAULT '',
+	detail      TEXT NOT NULL DEFAULT '',
+	host        TEXT NOT NULL DEFAULT '',
+	started_at  INTEGER NOT NULL,
+	duration_ms INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS events_started ON events (started_at);
+`
+
+// Path returns the database location, or "" when history is off.
+func Path() string {
+	switch v := os.Getenv(Env); strings.ToLower(v) {
+	case "off", "0", "false":
+		return ""
+	case "":
+		return filepath.Join(config.GetCacheDir(), "history.db")
+	default:
+		return v
+	}
+}
+
+// DB is an open history database.
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the database at path.
+func Open(path string) (*DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("history is disabled ($%s=off)", Env)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Add inserts e and sets its ID.
+func (d *DB) Add(e *Event) error {
+	res, err := d.db.Exec(`INSERT INTO events (kind, name, target, status, error, artifact, detail, host, started_at, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Kind, e.Name, e.Target, e.Status, e.Error, e.Artifact, e.Detail, e.Host, e.Started.UnixMilli(), e.Duration.Milliseconds())
+	if err != nil {
+		return err
+	}
+	e.ID, err = res.LastInsertId()
+	return err
+}
+
+// Filter selects events; zero fields match everything.
+type Filter struct {
+	Kind   string
+	Name   string
+	Target string
+	Status string
+	Since  time.Time
+	Limit  int
+}
+
+// Query returns matching events, newest first.
+func (d *DB) Query(f Filter) ([]Event, error) {
+	var where []string
+	var args []any
+	for _, c := range []struct{ col, val string }{
+		{"kind", f.Kind}, {"name", f.Name}, {"target", f.Target}, {"status", f.Status},
+	} {
+		if c.val != "" {
+			where = append(where, c.col+" = ?")
+			args = append(args, c.val)
+		}
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "started_at >= ?")
+		args = append(args, f.Since.UnixMilli())
+	}
+	q := `SELECT id, kind, name, target, status, error, artifact, detail, host, started_at, duration_ms FROM events`
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+	q += " ORDER BY started_at DESC, id DESC"
+	if f.Limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+
+	rows, err := d.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var started, ms int64
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Name, &e.Target, &e.Status, &e.Error, &e.Artifact, &e.Detail, &e.Host, &started, &ms); err != nil {
+			return nil, err
+		}
+		e.Started = time.UnixMilli(started)
+		e.Duration = time.Duration(ms) * time.Millisecond
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// Prune deletes events started before t and returns how many were removed.
+func (d *DB) Prune(t time.Time) (int64, error) {
+	res, err := d.db.Exec(`DELETE FROM events WHERE started_at < ?`, t.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Span times one operation; End records it.
+type Span struct {
+	Event
+}
+
+// Start begins timing an operation.
+func Start(kind, name, target string) *Span {
+	return &Span{Event{Kind: kind, Name: name, Target: target, Started: time.Now()}}
+}
+
+// End records the operation with err's outcome. artifact may be "".
+func (s *Span) End(err error, artifact string) {
+	s.Duration = time.Since(s.Started)
+	s.Artifact = artifact
+	s.Status = OK
+	if err != nil {
+		s.Status, s.Error = Failed, err.Error()
+	}
+	Record(&s.Event)
+}
+
+// Record stores e in the default database, ignoring any failure.
+func Record(e *Event) {
+	path := Path()
+	if path == "" {
+		return
+	}
+	if e.Host == "" {
+		e.Host, _ = os.Hostname()
+	}
+	d, err := Open(path)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	d.Add(e)
+}
+
+// Stat summarizes events of one kind and target.
+type Stat struct {
+	Kind    string        `json:"kind"`
+	Target  string        `json:"target,omitempty"`
+	Count   int           `json:"count"`
+	Failed  int           `json:"failed"`
+	Average time.Duration `json:"average"`
+	Longest time.Duration `json:"longest"`
+	LastRun time.Time     `json:"lastRun"`
+}
+
+// Stats groups the events matching f (Limit is ignored) by kind and target.
+func (d *DB) Stats(f Filter) ([]Stat, error) {
+	f.Limit = 0
+	events, err := d.Query(f)
+	if err != nil {
+		return nil, err
+	}
+	var stats []Stat
+	index := map[[2]string]int{}
+	for _, e := range events {
+		key := [2]string{e.Kind, e.Target}
+		i, ok := index[key]
+		if !ok {
+			i = len(stats)
+			index[key] = i
+			stats = append(stats, Stat{Kind: e.Kind, Target: e.Target, LastRun: e.Started})
+		}
+		s := &stats[i]
+		s.Count++
+		if e.Status == Failed {
+			s.Failed++
+		}
+		s.Average += e.Duration // Summed here, divided below
+		s.Longest = max(s.Longest, e.Duration)
+	}
+	for i := range stats {
+		stats[i].Average /= time.Duration(stats[i].Count)
+	}
+	return stats, nil
+}
+
+// Typical estimates how long the next kind/name/target operation takes:
+// the median of the last ten successful runs, ignoring near-instant ones
+// (up-to-date builds). It returns 0 without enough history.
+func Typical(kind, name, target string) time.Duration {
+	path := Path()
+	if path == "" {
+		return 0
+	}
+	if _, err := os.Stat(path); err != nil {
+		return 0
+	}
+	d, err := Open(path)
+	if err != nil {
+		return 0
+	}
+	defer d.Close()
+	events, err := d.Query(Filter{Kind: kind, Name: name, Target: target, Status: OK, Limit: 30})
+	if err != nil {
+		return 0
+	}
+	var runs []time.Duration
+	for _, e := range events {
+		if e.Duration >= 2*time.Second && len(runs) < 10 {
+			runs = append(runs, e.Duration)
+		}
+	}
+	if len(runs) == 0 {
+		return 0
+	}
+	slices.Sort(runs)
+	return runs[len(runs)/2]
+}
@@ -0,0 +1,51 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	t.Setenv(Env, path)
+
+	for _, target := range []string{"android", "android", "windows"} {
+		s := Start(Build, "hybrid-dashboard", target)
+		s.Started = s.Started.Add(-2 * time.Second)
+		var err error
+		if target == "windows" {
+			err = errors.New("gogio build failed")
+		}
+		s.End(err, "")
+	}
+
+	db, err := Open(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	failed, err := db.Query(Filter{Status: Failed})
+	if err != nil || len(failed) != 1 || failed[0].Target != "windows" {
+		t.Fatalf("Query(failed) = %+v, %v", failed, err)
+	}
+	if all, _ := db.Query(Filter{Kind: Build, Limit: 2}); len(all) != 2 {
+		t.Errorf("Limit ignored: got %d events", len(all))
+	}
+	stats, err := db.Stats(Filter{})
+	if err != nil || len(stats) != 2 {
+		t.Fatalf("Stats = %+v, %v", stats, err)
+	}
+	for _, s := range stats {
+		if s.Target == "android" && (s.Count != 2 || s.Failed != 0 || s.Average < 2*time.Second) {
+			t.Errorf("android stats = %+v", s)
+		}
+	}
+
+	t.Setenv(Env, "off")
+	if Path() != "" {
+		t.Error("history not disabled by off")
+	}
+}
@@ -0,0 +1,243 @@
+// Package hooks runs the lifecycle hooks a project declares in goup.json,
+// so teams can add notarization, artifact uploads or chat notifications
+// around build, bundle, package and publish without forking goup-util:
+//
+//	{
+//	  "hooks": {
+//	    "pre_build":   ["go test ./..."],
+//	    "post_bundle": [
+//	      {"run": "./scripts/notarize.sh", "platforms": ["macos"]},
+//	      {"plugin": "slack-notify", "args": ["#releases"], "continueOnError": true}
+//	    ]
+//	  }
+//	}
+//
+// Each hook gets the operation's Context as JSON on stdin and the main
+// fields as GOUP_* environment variables. A failing pre hook stops the
+// operation; post hooks run after success and failure alike.
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/plugins"
+)
+
+// ConfigFileName is the project file holding the hooks.
+const ConfigFileName = "goup.json"
+
+// Operations that have hooks; events are pre_<op> and post_<op>.
+var Operations = []string{"build", "bundle", "package", "publish"}
+
+// Hook is one command. In goup.json a plain string is a Run hook.
+type Hook struct {
+	Run             string   `json:"run,omitempty"`             // Shell command
+	Plugin          string   `json:"plugin,omitempty"`          // goup-util-<name> plugin
+	Args            []string `json:"args,omitempty"`            // Plugin arguments
+	Platforms       []string `json:"platforms,omitempty"`       // Only for these platforms
+	ContinueOnError bool     `json:"continueOnError,omitempty"` // Report failures without failing the operation
+}
+
+// UnmarshalJSON accepts a string as shorthand for {"run": ...}.
+func (h *Hook) UnmarshalJSON(data []byte) error {
+	var run string
+	if err := json.Unmarshal(data, &run); err == nil {
+		*h = Hook{Run: run}
+		return nil
+	}
+	type plain Hook
+	return json.Unmarshal(data, (*plain)(h))
+}
+
+func (h Hook) String() string {
+	if h.Plugin != "" {
+		return strings.TrimSpace("plugin " + h.Plugin + " " + strings.Join(h.Args, " "))
+	}
+	return h.Run
+}
+
+// Config is the hooks section of goup.json.
+type Config struct {
+	Hooks map[string][]Hook `json:"hooks"`
+
+	// Dir holds goup.json; hooks run there.
+	Dir string `json:"-"`
+}
+
+// Load finds goup.json in dir or its parents, up to the repository root,
+// and returns its hooks. It returns nil when there is no goup.json.
+func Load(dir string) (*Config, error) {
+	path, data, err := Find(dir)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return parse(data, filepath.Dir(path), path)
+}
+
+// Find looks for goup.json in dir or its parents, up to the repository
+// root, and returns its path and contents. The path is "" when there is
+// none.
+func Find(dir string) (string, []byte, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return path, data, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", nil, err
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil, nil
+		}
+		dir = parent
+	}
+}
+
+func parse(data []byte, dir, path string) (*Config, error) {
+	c := &Config{Dir: dir}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for event, list := range c.Hooks {
+		if !validEvent(event) {
+			return nil, fmt.Errorf("%s: unknown hook %q (use pre_ or post_ with %s)", path, event, strings.Join(Operations, ", "))
+		}
+		for _, h := range list {
+			if (h.Run == "") == (h.Plugin == "") {
+				return nil, fmt.Errorf("%s: each %s hook needs exactly one of run or plugin", path, event)
+			}
+		}
+	}
+	return c, nil
+}
+
+func validEvent(event string) bool {
+	phase, op, ok := strings.Cut(event, "_")
+	if !ok || (phase != "pre" && phase != "post") {
+		return false
+	}
+	for _, o := range Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Context describes the operation to a hook.
+type Context struct {
+	Event     string `json:"event"` // e.g. post_build
+	Operation string `json:"operation"`
+	Platform  string `json:"platform"`
+	Project   string `json:"project"`
+	AppDir    string `json:"appDir"`
+	Artifact  string `json:"artifact,omitempty"` // Post hooks: what was produced
+	Version   string `json:"version,omitempty"`
+	Status    string `json:"status,omitempty"` // Post hooks: ok or failed
+	Error     string `json:"error,omitempty"`
+	Duration  int64  `json:"durationMs,omitempty"`
+	GoupUtil  string `json:"goupUtilVersion,omitempty"`
+}
+
+// Pre runs the pre_<op> hooks.
+func (c *Config) Pre(ctx Context) error {
+	ctx.Event = "pre_" + ctx.Operation
+	return c.run(ctx)
+}
+
+// Post runs the post_<op> hooks for an operation that took d and ended
+// with opErr.
+func (c *Config) Post(ctx Context, d time.Duration, opErr error) error {
+	ctx.Event = "post_" + ctx.Operation
+	ctx.Duration = d.Milliseconds()
+	ctx.Status = "ok"
+	if opErr != nil {
+		ctx.Status, ctx.Error = "failed", opErr.Error()
+	}
+	return c.run(ctx)
+}
+
+func (c *Config) run(ctx Context) error {
+	if c == nil {
+		return nil
+	}
+	input, err := json.Marshal(ctx)
+	if err != nil {
+		return err
+	}
+	for _, h := range c.Hooks[ctx.Event] {
+		if len(h.Platforms) > 0 && !contains(h.Platforms, ctx.Platform) {
+			continue
+		}
+		fmt.Printf("🪝 %s: %s\n", ctx.Event, h)
+		if err := c.runHook(h, ctx, input); err != nil {
+			err = fmt.Errorf("%s hook %q failed: %w", ctx.Event, h, err)
+			if !h.ContinueOnError {
+				return err
+			}
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) runHook(h Hook, ctx Context, input []byte) error {
+	var cmd *exec.Cmd
+	if h.Plugin != "" {
+		p, ok := plugins.Find(h.Plugin)
+		if !ok {
+			return fmt.Errorf("plugin %s not found (install %s%s on the PATH)", h.Plugin, plugins.Prefix, h.Plugin)
+		}
+		cmd = p.Command(ctx.GoupUtil, h.Args...)
+	} else if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", h.Run)
+	} else {
+		cmd = exec.Command("sh", "-c", h.Run)
+	}
+	cmd.Dir = c.Dir
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env,
+		"GOUP_HOOK_EVENT="+ctx.Event,
+		"GOUP_PLATFORM="+ctx.Platform,
+		"GOUP_PROJECT="+ctx.Project,
+		"GOUP_APP_DIR="+ctx.AppDir,
+		"GOUP_ARTIFACT="+ctx.Artifact,
+		"GOUP_VERSION="+ctx.Version,
+		"GOUP_STATUS="+ctx.Status,
+		"GOUP_DURATION_MS="+strconv.FormatInt(ctx.Duration, 10),
+	)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	app := filepath.Join(root, "examples", "app")
+	os.MkdirAll(app, 0755)
+
+	if c, err := Load(app); c != nil || err != nil {
+		t.Fatalf("Load without goup.json = %v, %v", c, err)
+	}
+
+	os.WriteFile(filepath.Join(root, ConfigFileName), []byte(`{
+		"hooks": {
+			"pre_build": ["echo hi"],
+			"post_bundle": [{"plugin": "notify", "platforms": ["macos"], "continueOnError": true}]
+		}
+	}`), 0644)
+	c, err := Load(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Dir != root || c.Hooks["pre_build"][0].Run != "echo hi" || c.Hooks["post_bundle"][0].Plugin != "notify" {
+		t.Errorf("Load = %+v", c)
+	}
+
+	for _, bad := range []string{
+		`{"hooks": {"after_build": ["x"]}}`,
+		`{"hooks": {"pre_build": [{"run": "x", "plugin": "y"}]}}`,
+	} {
+		if _, err := parse([]byte(bad), root, "goup.json"); err == nil {
+			t.Errorf("parse(%s) accepted", bad)
+		}
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "ctx.json")
+	c := &Config{Dir: dir, Hooks: map[string][]Hook{
+		"post_build": {
+			{Run: "cat > " + out},
+			{Run: "exit 1", Platforms: []string{"ios"}}, // Skipped for android
+		},
+		"pre_build": {{Run: "exit 3"}},
+	}}
+
+	ctx := Context{Operation: "build", Platform: "android", Project: "app"}
+	if err := c.Pre(ctx); err == nil || !strings.Contains(err.Error(), "pre_build") {
+		t.Errorf("failing pre hook: %v", err)
+	}
+	if err := c.Post(ctx, 2*time.Second, nil); err != nil {
+		t.Fatal(err)
+	}
+	var got Context
+	data, _ := os.ReadFile(out)
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Event != "post_build" || got.Status != "ok" || got.Duration != 2000 {
+		t.Errorf("hook context = %+v", got)
+	}
+}
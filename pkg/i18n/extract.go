@@ -0,0 +1,150 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultFuncs are the call names treated as translatable:
+// i18n.T in goup-util and tr in the webviewer shell.
+var DefaultFuncs = []string{"T", "tr"}
+
+// Extract walks dir and returns the sorted, unique string literals passed
+// as the first argument to any of funcs. Hidden directories, vendor and
+// nested Go modules are skipped so each module keeps its own catalog.
+func Extract(dir string, funcs []string) ([]string, error) {
+	seen := make(map[string]bool)
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || !isTranslateCall(call.Fun, funcs) {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			if msg, err := strconv.Unquote(lit.Value); err == nil && msg != "" {
+				seen[msg] = true
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]string, 0, len(seen))
+	for m := range seen {
+		msgs = append(msgs, m)
+	}
+	sort.Strings(msgs)
+	return msgs, nil
+}
+
+// isTranslateCall matches tr(...) and i18n.T(...) style calls.
+func isTranslateCall(fun ast.Expr, funcs []string) bool {
+	var name string
+	switch f := fun.(type) {
+	case *ast.Ident:
+		name = f.Name
+	case *ast.SelectorExpr:
+		name = f.Sel.Name
+	default:
+		return false
+	}
+	for _, fn := range funcs {
+		if name == fn {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateCatalogs rewrites <outDir>/<lang>.json for every existing catalog
+// plus DefaultLocale and any extra locales. Existing translations are kept,
+// new messages are added untranslated, and messages no longer in msgs are
+// dropped. The DefaultLocale catalog maps each message to itself.
+// Returns the locales written.
+func UpdateCatalogs(outDir string, msgs []string, extra []string) ([]string, error) {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", outDir, err)
+	}
+
+	existing, err := LoadCatalogs(os.DirFS(outDir), ".")
+	if err != nil {
+		return nil, err
+	}
+	locales := map[string]bool{DefaultLocale: true}
+	for l := range existing {
+		locales[l] = true
+	}
+	for _, l := range extra {
+		if l = Normalize(l); l != "" {
+			locales[l] = true
+		}
+	}
+
+	var written []string
+	for l := range locales {
+		c := make(Catalog, len(msgs))
+		for _, m := range msgs {
+			if l == DefaultLocale {
+				c[m] = m
+			} else {
+				c[m] = existing[l][m]
+			}
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(c); err != nil {
+			return nil, err
+		}
+		path := filepath.Join(outDir, l+".json")
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, l)
+	}
+	sort.Strings(written)
+	return written, nil
+}
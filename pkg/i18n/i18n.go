@@ -0,0 +1,143 @@
+// Package i18n translates goup-util's user-facing messages.
+//
+// Catalogs are JSON files (locales/<lang>.json) keyed by the English message,
+// gettext style, so untranslated strings fall back to readable English.
+// Regenerate them with: goup-util i18n extract . --out pkg/i18n/locales
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used when no catalog matches the detected locale.
+const DefaultLocale = "en"
+
+// LocaleEnv overrides locale detection (e.g. GOUP_LANG=de).
+const LocaleEnv = "GOUP_LANG"
+
+//go:embed locales/*.json
+var embedded embed.FS
+
+// Catalog maps an English message to its translation.
+// An empty translation means "not translated yet".
+type Catalog map[string]string
+
+var (
+	mu       sync.RWMutex
+	catalogs map[string]Catalog
+	current  = DefaultLocale
+)
+
+func init() {
+	c, err := LoadCatalogs(embedded, "locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: embedded catalogs: %v", err))
+	}
+	catalogs = c
+	SetLocale(Detect())
+}
+
+// LoadCatalogs reads every <lang>.json file in dir.
+func LoadCatalogs(fsys fs.FS, dir string) (map[string]Catalog, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]Catalog)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var c Catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", e.Name(), err)
+		}
+		result[strings.TrimSuffix(e.Name(), ".json")] = c
+	}
+	return result, nil
+}
+
+// Detect returns the user's language from GOUP_LANG, LC_ALL, LC_MESSAGES
+// or LANG, falling back to DefaultLocale.
+func Detect() string {
+	for _, env := range []string{LocaleEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if l := Normalize(os.Getenv(env)); l != "" {
+			return l
+		}
+	}
+	return DefaultLocale
+}
+
+// Normalize reduces a POSIX or BCP 47 locale ("de_DE.UTF-8", "pt-BR")
+// to its lowercase language code ("de", "pt"). "C" and "POSIX" yield "".
+func Normalize(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ToLower(locale)
+	if locale == "c" || locale == "posix" {
+		return ""
+	}
+	return locale
+}
+
+// SetLocale selects the catalog used by T. Unknown locales use DefaultLocale.
+func SetLocale(locale string) {
+	locale = Normalize(locale)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := catalogs[locale]; !ok {
+		locale = DefaultLocale
+	}
+	current = locale
+}
+
+// Locale returns the active locale.
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Locales returns the available locales, sorted.
+func Locales() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var names []string
+	for l := range catalogs {
+		names = append(names, l)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// T translates msg and formats it with args like fmt.Sprintf.
+func T(msg string, args ...any) string {
+	mu.RLock()
+	translated := catalogs[current][msg]
+	mu.RUnlock()
+	if translated == "" {
+		translated = msg
+	}
+	if len(args) == 0 {
+		return translated
+	}
+	return fmt.Sprintf(translated, args...)
+}
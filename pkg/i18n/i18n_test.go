@@ -0,0 +1,101 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"de_DE.UTF-8", "de"},
+		{"pt-BR", "pt"},
+		{"fr", "fr"},
+		{"en_US@euro", "en"},
+		{"C", ""},
+		{"POSIX", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.expected {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTFallback(t *testing.T) {
+	defer SetLocale(Locale())
+
+	SetLocale("de")
+	if got := T("Up to date: %s", "x"); got != "Aktuell: x" {
+		t.Errorf("T in de = %q", got)
+	}
+	if got := T("not in any catalog"); got != "not in any catalog" {
+		t.Errorf("untranslated message = %q, want original", got)
+	}
+
+	SetLocale("xx")
+	if Locale() != DefaultLocale {
+		t.Errorf("unknown locale selected %q, want %q", Locale(), DefaultLocale)
+	}
+}
+
+func TestExtractAndUpdate(t *testing.T) {
+	dir := t.TempDir()
+	src := `package main
+
+func main() {
+	println(i18n.T("Hello %s", "x"))
+	println(tr("Close"))
+	println(tr(dynamic))
+	println(other("ignored"))
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Nested modules keep their own catalogs
+	nested := filepath.Join(dir, "nested")
+	os.MkdirAll(nested, 0755)
+	os.WriteFile(filepath.Join(nested, "go.mod"), []byte("module nested\n"), 0644)
+	os.WriteFile(filepath.Join(nested, "x.go"), []byte("package x\nvar _ = tr(\"Nested\")\n"), 0644)
+
+	msgs, err := Extract(dir, DefaultFuncs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Close", "Hello %s"}; !reflect.DeepEqual(msgs, want) {
+		t.Fatalf("Extract = %v, want %v", msgs, want)
+	}
+
+	out := filepath.Join(dir, "locales")
+	os.MkdirAll(out, 0755)
+	os.WriteFile(filepath.Join(out, "de.json"), []byte(`{"Close": "Schließen", "Old": "Alt"}`), 0644)
+
+	written, err := UpdateCatalogs(out, msgs, []string{"fr_FR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"de", "en", "fr"}; !reflect.DeepEqual(written, want) {
+		t.Errorf("written = %v, want %v", written, want)
+	}
+
+	catalogs, err := LoadCatalogs(os.DirFS(out), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDe := Catalog{"Close": "Schließen", "Hello %s": ""}
+	if !reflect.DeepEqual(catalogs["de"], wantDe) {
+		t.Errorf("de = %v, want %v", catalogs["de"], wantDe)
+	}
+	if catalogs["en"]["Hello %s"] != "Hello %s" {
+		t.Errorf("en should map messages to themselves, got %v", catalogs["en"])
+	}
+}
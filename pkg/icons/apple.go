@@ -0,0 +1,318 @@
+package icons
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nfnt/resize"
+)
+
+// Layered icons (tvOS, visionOS) take optional extra layers from files next
+// to the source icon: icon-source-back.png, icon-source-middle.png and
+// icon-source-front.png. Without them the source icon is the artwork and a
+// background is derived from its edges.
+
+// assetInfo is the "info" block every asset catalog Contents.json carries.
+var assetInfo = map[string]any{"version": 1, "author": "goup-util"}
+
+// generateWatchOSIcons creates a single-size watchOS app icon set (Xcode 14
+// and later scale it for every watch). Watch faces mask the icon to a
+// circle and reject transparency, so the icon is flattened.
+func generateWatchOSIcons(inputPath, outputDir string) error {
+	img, err := loadImage(inputPath)
+	if err != nil {
+		return err
+	}
+	if err := writeContents(outputDir, map[string]any{}); err != nil {
+		return err
+	}
+	dir := filepath.Join(outputDir, "AppIcon.appiconset")
+	icon := flatten(resize.Resize(1024, 1024, img, resize.Lanczos3), color.Black)
+	if err := savePNG(icon, filepath.Join(dir, "icon-1024.png")); err != nil {
+		return err
+	}
+	if err := writeContents(dir, map[string]any{
+		"images": []map[string]string{
+			{"idiom": "universal", "platform": "watchos", "size": "1024x1024", "filename": "icon-1024.png"},
+		},
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully generated watchOS icons in %s\n", dir)
+	return nil
+}
+
+// generateTVOSIcons creates the tvOS brand assets: the layered (parallax)
+// App Icon for the home screen and the App Store, and the Top Shelf images.
+func generateTVOSIcons(inputPath, outputDir string) error {
+	front, back, err := loadLayers(inputPath)
+	if err != nil {
+		return err
+	}
+	if err := writeContents(outputDir, map[string]any{}); err != nil {
+		return err
+	}
+	dir := filepath.Join(outputDir, "App Icon & Top Shelf Image.brandassets")
+
+	stacks := []struct {
+		name   string
+		w, h   int
+		scales []int
+	}{
+		{"App Icon.imagestack", 400, 240, []int{1, 2}},
+		{"App Icon - App Store.imagestack", 1280, 768, []int{1}},
+	}
+	for _, s := range stacks {
+		stackDir := filepath.Join(dir, s.name)
+		layers := []struct {
+			name string
+			draw func(w, h int) image.Image
+		}{
+			{"Front", func(w, h int) image.Image { return contain(front, w, h, 0.8, color.Transparent) }},
+			{"Back", func(w, h int) image.Image { return cover(back, w, h) }},
+		}
+		var names []map[string]string
+		for _, l := range layers {
+			layerDir := filepath.Join(stackDir, l.name+".imagestacklayer")
+			names = append(names, map[string]string{"filename": l.name + ".imagestacklayer"})
+			var images []map[string]string
+			for _, scale := range s.scales {
+				file := fmt.Sprintf("%s@%dx.png", strings.ToLower(l.name), scale)
+				images = append(images, map[string]string{"idiom": "tv", "scale": fmt.Sprintf("%dx", scale), "filename": file})
+				if err := savePNG(l.draw(s.w*scale, s.h*scale), filepath.Join(layerDir, "Content.imageset", file)); err != nil {
+					return err
+				}
+			}
+			if err := writeContents(filepath.Join(layerDir, "Content.imageset"), map[string]any{"images": images}); err != nil {
+				return err
+			}
+			if err := writeContents(layerDir, map[string]any{}); err != nil {
+				return err
+			}
+		}
+		if err := writeContents(stackDir, map[string]any{"layers": names}); err != nil {
+			return err
+		}
+	}
+
+	shelves := []struct {
+		name string
+		w, h int
+	}{
+		{"Top Shelf Image.imageset", 1920, 720},
+		{"Top Shelf Image Wide.imageset", 2320, 720},
+	}
+	for _, s := range shelves {
+		shelfDir := filepath.Join(dir, s.name)
+		var images []map[string]string
+		for _, scale := range []int{1, 2} {
+			file := fmt.Sprintf("shelf@%dx.png", scale)
+			images = append(images, map[string]string{"idiom": "tv", "scale": fmt.Sprintf("%dx", scale), "filename": file})
+			w, h := s.w*scale, s.h*scale
+			shelf := cover(back, w, h)
+			draw.Draw(shelf, shelf.Bounds(), contain(front, w, h, 0.6, color.Transparent), image.Point{}, draw.Over)
+			if err := savePNG(shelf, filepath.Join(shelfDir, file)); err != nil {
+				return err
+			}
+		}
+		if err := writeContents(shelfDir, map[string]any{"images": images}); err != nil {
+			return err
+		}
+	}
+
+	if err := writeContents(dir, map[string]any{
+		"assets": []map[string]string{
+			{"size": "1280x768", "idiom": "tv", "filename": "App Icon - App Store.imagestack", "role": "primary-app-icon"},
+			{"size": "400x240", "idiom": "tv", "filename": "App Icon.imagestack", "role": "primary-app-icon"},
+			{"size": "2320x720", "idiom": "tv", "filename": "Top Shelf Image Wide.imageset", "role": "top-shelf-image-wide"},
+			{"size": "1920x720", "idiom": "tv", "filename": "Top Shelf Image.imageset", "role": "top-shelf-image"},
+		},
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully generated tvOS brand assets in %s\n", dir)
+	return nil
+}
+
+// generateVisionOSIcons creates the three-layer visionOS app icon. The back
+// layer must be opaque; the middle and front layers are only filled when
+// icon-source-middle.png and icon-source-front.png exist.
+func generateVisionOSIcons(inputPath, outputDir string) error {
+	src, err := loadImage(inputPath)
+	if err != nil {
+		return err
+	}
+	if err := writeContents(outputDir, map[string]any{}); err != nil {
+		return err
+	}
+	dir := filepath.Join(outputDir, "AppIcon.solidimagestack")
+	base := strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+
+	var names []map[string]string
+	for _, layer := range []string{"Front", "Middle", "Back"} {
+		layerDir := filepath.Join(dir, layer+".solidimagestacklayer")
+		names = append(names, map[string]string{"filename": layer + ".solidimagestacklayer"})
+
+		var img image.Image
+		if extra, err := loadImage(base + "-" + strings.ToLower(layer) + ".png"); err == nil {
+			img = extra
+		} else if layer == "Back" {
+			img = src
+		}
+		entry := map[string]string{"idiom": "vision", "scale": "2x"}
+		if img != nil {
+			resized := resize.Resize(1024, 1024, img, resize.Lanczos3)
+			if layer == "Back" {
+				resized = flatten(resized, edgeColor(img))
+			}
+			file := strings.ToLower(layer) + ".png"
+			if err := savePNG(resized, filepath.Join(layerDir, "Content.imageset", file)); err != nil {
+				return err
+			}
+			entry["filename"] = file
+		}
+		if err := writeContents(filepath.Join(layerDir, "Content.imageset"), map[string]any{"images": []map[string]string{entry}}); err != nil {
+			return err
+		}
+		if err := writeContents(layerDir, map[string]any{}); err != nil {
+			return err
+		}
+	}
+	if err := writeContents(dir, map[string]any{"layers": names}); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully generated visionOS icons in %s\n", dir)
+	return nil
+}
+
+// loadLayers returns the front and back artwork for a two-layer icon.
+func loadLayers(inputPath string) (front, back image.Image, err error) {
+	src, err := loadImage(inputPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	base := strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+	front, back = src, nil
+	if img, err := loadImage(base + "-front.png"); err == nil {
+		front = img
+	}
+	if img, err := loadImage(base + "-back.png"); err == nil {
+		back = img
+	} else {
+		bg := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		bg.Set(0, 0, edgeColor(src))
+		back = bg
+	}
+	return front, back, nil
+}
+
+func loadImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+func savePNG(img image.Image, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", path, err)
+	}
+	defer out.Close()
+	if err := png.Encode(out, img); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	fmt.Printf("Generated %s\n", path)
+	return nil
+}
+
+// writeContents writes an asset catalog Contents.json into dir.
+func writeContents(dir string, v map[string]any) error {
+	v["info"] = assetInfo
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Contents.json"), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write Contents.json: %w", err)
+	}
+	return nil
+}
+
+// flatten draws img over an opaque background.
+func flatten(img image.Image, bg color.Color) image.Image {
+	out := image.NewNRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Over)
+	return out
+}
+
+// contain scales img to fit frac of a w x h canvas, centered on bg.
+func contain(img image.Image, w, h int, frac float64, bg color.Color) *image.NRGBA {
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(out, out.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	b := img.Bounds()
+	scale := min(float64(w)*frac/float64(b.Dx()), float64(h)*frac/float64(b.Dy()))
+	sw, sh := int(float64(b.Dx())*scale), int(float64(b.Dy())*scale)
+	scaled := resize.Resize(uint(sw), uint(sh), img, resize.Lanczos3)
+	at := image.Pt((w-sw)/2, (h-sh)/2)
+	draw.Draw(out, image.Rectangle{at, at.Add(image.Pt(sw, sh))}, scaled, scaled.Bounds().Min, draw.Over)
+	return out
+}
+
+// cover scales img to fill a w x h canvas, cropping the overflow.
+func cover(img image.Image, w, h int) *image.NRGBA {
+	b := img.Bounds()
+	scale := max(float64(w)/float64(b.Dx()), float64(h)/float64(b.Dy()))
+	sw, sh := max(w, int(float64(b.Dx())*scale+0.5)), max(h, int(float64(b.Dy())*scale+0.5))
+	scaled := resize.Resize(uint(sw), uint(sh), img, resize.Bilinear)
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(out, out.Bounds(), scaled, scaled.Bounds().Min.Add(image.Pt((sw-w)/2, (sh-h)/2)), draw.Src)
+	return out
+}
+
+// edgeColor is the average opaque color along the image border, used as a
+// background that blends with the artwork. It is white when the border is
+// transparent.
+func edgeColor(img image.Image) color.Color {
+	b := img.Bounds()
+	var r, g, bl, n uint64
+	add := func(x, y int) {
+		cr, cg, cb, ca := img.At(x, y).RGBA()
+		if ca < 0x8000 {
+			return
+		}
+		r, g, bl, n = r+uint64(cr*0xffff/ca), g+uint64(cg*0xffff/ca), bl+uint64(cb*0xffff/ca), n+1
+	}
+	for x := b.Min.X; x < b.Max.X; x++ {
+		add(x, b.Min.Y)
+		add(x, b.Max.Y-1)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		add(b.Min.X, y)
+		add(b.Max.X-1, y)
+	}
+	if n == 0 {
+		return color.White
+	}
+	return color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), 0xffff}
+}
@@ -0,0 +1,51 @@
+package icons
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateAppleCompanionIcons(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "icon-source.png")
+	if err := GenerateTestIcon(src); err != nil {
+		t.Fatal(err)
+	}
+
+	for platform, want := range map[string][]string{
+		"watchos": {"AppIcon.appiconset/icon-1024.png"},
+		"tvos": {
+			"App Icon & Top Shelf Image.brandassets/App Icon.imagestack/Front.imagestacklayer/Content.imageset/front@2x.png",
+			"App Icon & Top Shelf Image.brandassets/App Icon - App Store.imagestack/Back.imagestacklayer/Content.imageset/back@1x.png",
+			"App Icon & Top Shelf Image.brandassets/Top Shelf Image Wide.imageset/shelf@2x.png",
+		},
+		"visionos": {"AppIcon.solidimagestack/Back.solidimagestacklayer/Content.imageset/back.png"},
+	} {
+		out := filepath.Join(dir, platform, "Assets.xcassets")
+		if err := Generate(Config{InputPath: src, OutputPath: out, Platform: platform}); err != nil {
+			t.Fatalf("%s: %v", platform, err)
+		}
+		for _, f := range want {
+			if _, err := os.Stat(filepath.Join(out, f)); err != nil {
+				t.Errorf("%s: %v", platform, err)
+			}
+		}
+	}
+
+	// The visionOS stack lists all three layers, front first
+	data, err := os.ReadFile(filepath.Join(dir, "visionos", "Assets.xcassets", "AppIcon.solidimagestack", "Contents.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stack struct {
+		Layers []struct{ Filename string }
+	}
+	if err := json.Unmarshal(data, &stack); err != nil {
+		t.Fatal(err)
+	}
+	if len(stack.Layers) != 3 || stack.Layers[0].Filename != "Front.solidimagestacklayer" {
+		t.Errorf("layers = %+v", stack.Layers)
+	}
+}
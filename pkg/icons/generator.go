@@ -15,7 +15,7 @@ import (
 type Config struct {
 	InputPath  string // Path to source icon (e.g., "icon-source.png")
 	OutputPath string // Directory to output icons
-	Platform   string // Target platform: android, ios, macos, windows-msix, windows-ico
+	Platform   string // Target platform: one of schema.IconPlatforms
 }
 
 // ProjectConfig holds configuration for project-aware icon generation
@@ -77,6 +77,9 @@ func GenerateForProject(cfg ProjectConfig) error {
 		if err := os.MkdirAll(outputPath, 0755); err != nil {
 			return fmt.Errorf("failed to create build directory: %w", err)
 		}
+	case "watchos", "tvos", "visionos":
+		// Companion targets get their own asset catalog next to the iOS one
+		outputPath = filepath.Join(cfg.ProjectPath, constants.BuildDir, cfg.Platform, "Assets.xcassets")
 	default:
 		outputPath = cfg.ProjectPath
 	}
@@ -102,6 +105,12 @@ func Generate(cfg Config) error {
 		return generateWindowsIcons(cfg.InputPath, cfg.OutputPath)
 	case "windows-ico":
 		return generateICO(cfg.InputPath, cfg.OutputPath)
+	case "watchos":
+		return generateWatchOSIcons(cfg.InputPath, cfg.OutputPath)
+	case "tvos":
+		return generateTVOSIcons(cfg.InputPath, cfg.OutputPath)
+	case "visionos":
+		return generateVisionOSIcons(cfg.InputPath, cfg.OutputPath)
 	default:
 		return fmt.Errorf("unsupported platform: %s", cfg.Platform)
 	}
@@ -0,0 +1,233 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Severity of a validation issue. Errors fail builds; warnings do not.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// MinSize is the smallest source icon each platform's store accepts
+// without upscaling. The App Store marketing icon is 1024x1024.
+var MinSize = map[string]int{
+	"ios":      1024,
+	"macos":    1024,
+	"android":  512,
+	"windows":  256,
+	"watchos":  1024,
+	"tvos":     1024,
+	"visionos": 1024,
+}
+
+// Issue is one problem with a source icon.
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Fix      string   `json:"fix,omitempty"`
+}
+
+// Report is the result of validating a source icon.
+type Report struct {
+	Path      string   `json:"path"`
+	Width     int      `json:"width"`
+	Height    int      `json:"height"`
+	Format    string   `json:"format"`
+	Platforms []string `json:"platforms"`
+	Issues    []Issue  `json:"issues"`
+}
+
+// OK reports whether the icon has no errors.
+func (r *Report) OK() bool {
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// Err summarizes the errors, or returns nil when the icon is usable.
+func (r *Report) Err() error {
+	var msgs []string
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			msgs = append(msgs, fmt.Sprintf("%s\n    fix: %s", i.Message, i.Fix))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("icon %s is not usable:\n  %s\n(run 'goup-util icons validate' for details, or build with --skip-icons)", r.Path, strings.Join(msgs, "\n  "))
+}
+
+func (r *Report) add(sev Severity, code, fix, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{Severity: sev, Code: code, Message: fmt.Sprintf(format, args...), Fix: fix})
+}
+
+func (r *Report) targets(platform string) bool {
+	return slices.Contains(r.Platforms, platform)
+}
+
+// Validate checks a source icon for the given platforms (ios, macos,
+// android, windows; none means all of them): format, size, squareness,
+// transparency where a store forbids it, and color encoding.
+func Validate(path string, platforms ...string) (*Report, error) {
+	if len(platforms) == 0 {
+		platforms = []string{"ios", "macos", "android", "windows"}
+	}
+	platforms = slices.Clone(platforms)
+	for i, p := range platforms {
+		switch p {
+		case "ios-simulator":
+			platforms[i] = "ios"
+		case "windows-msix", "windows-ico":
+			platforms[i] = "windows"
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read icon: %w", err)
+	}
+	r := &Report{Path: path, Platforms: platforms, Issues: []Issue{}}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		r.add(SeverityError, "unreadable", "Export the icon as a PNG", "cannot decode %s: %v", path, err)
+		return r, nil
+	}
+	r.Format = format
+	b := img.Bounds()
+	r.Width, r.Height = b.Dx(), b.Dy()
+
+	if format != "png" {
+		r.add(SeverityError, "format", "Export the icon as a PNG", "icon is a %s; a PNG is required", strings.ToUpper(format))
+	}
+
+	if r.Width != r.Height {
+		r.add(SeverityError, "aspect", "Pad the artwork to a square canvas instead of stretching it",
+			"icon is %dx%d; it must be square (stores and launchers would stretch it)", r.Width, r.Height)
+	}
+
+	need, needFor := 0, ""
+	for _, p := range platforms {
+		if MinSize[p] > need {
+			need, needFor = MinSize[p], p
+		}
+	}
+	side := min(r.Width, r.Height)
+	if side < need {
+		r.add(SeverityError, "size", fmt.Sprintf("Export the icon at %dx%d or larger from the original artwork", need, need),
+			"icon is %dx%d; %s needs at least %dx%d", r.Width, r.Height, needFor, need, need)
+	} else if side < 1024 {
+		r.add(SeverityWarning, "size", "Export the icon at 1024x1024 so every platform gets a sharp result",
+			"icon is %dx%d; 1024x1024 is recommended", r.Width, r.Height)
+	}
+
+	transparent, total := alphaStats(img)
+	if transparent > 0 {
+		if r.targets("ios") {
+			r.add(SeverityError, "alpha", "Flatten the icon onto an opaque background (iOS adds the rounded corners itself)",
+				"icon has transparent pixels; the iOS App Store marketing icon must be opaque")
+		}
+		if transparent*10 > total*9 {
+			r.add(SeverityWarning, "mostly-transparent", "Check that the artwork was exported with its layers visible",
+				"%d%% of the icon is transparent", transparent*100/total)
+		}
+	}
+
+	if format == "png" {
+		checkPNG(r, data)
+	}
+	if isPlaceholder(img) {
+		r.add(SeverityWarning, "placeholder", "Replace icon-source.png with the app's real icon",
+			"icon is the placeholder generated by goup-util")
+	}
+	return r, nil
+}
+
+// alphaStats counts pixels that are not fully opaque. Large images are
+// sampled.
+func alphaStats(img image.Image) (transparent, total int) {
+	b := img.Bounds()
+	step := max(1, b.Dx()/512)
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			_, _, _, a := img.At(x, y).RGBA()
+			if a < 0xffff {
+				transparent++
+			}
+			total++
+		}
+	}
+	return transparent, total
+}
+
+// isPlaceholder reports whether img is the solid blue icon GenerateTestIcon
+// writes.
+func isPlaceholder(img image.Image) bool {
+	b := img.Bounds()
+	blue := color.RGBA{0, 0, 255, 255}
+	for _, p := range []image.Point{b.Min, {b.Max.X - 1, b.Max.Y - 1}, {(b.Min.X + b.Max.X) / 2, (b.Min.Y + b.Max.Y) / 2}} {
+		if color.RGBAModel.Convert(img.At(p.X, p.Y)) != blue {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPNG inspects the PNG header chunks for encodings that stores reject
+// or that render with shifted colors.
+func checkPNG(r *Report, data []byte) {
+	const sigLen = 8
+	for pos := sigLen; pos+12 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[pos:]))
+		typ := string(data[pos+4 : pos+8])
+		if pos+12+n > len(data) {
+			return
+		}
+		chunk := data[pos+8 : pos+8+n]
+		switch typ {
+		case "IHDR":
+			if len(chunk) < 10 {
+				return
+			}
+			depth, colorType := chunk[8], chunk[9]
+			switch colorType {
+			case 0, 4:
+				r.add(SeverityWarning, "grayscale", "Export the icon as RGB (8-bit RGBA PNG)",
+					"icon is a grayscale PNG; some stores and Windows tooling reject it")
+			case 3:
+				r.add(SeverityWarning, "palette", "Export the icon as a full-color 8-bit RGBA PNG",
+					"icon is a palette (indexed) PNG; resizing it loses quality")
+			}
+			if depth == 16 {
+				r.add(SeverityWarning, "16-bit", "Export the icon with 8 bits per channel",
+					"icon has 16 bits per channel; App Store Connect expects 8-bit")
+			}
+		case "iCCP":
+			name, _, _ := bytes.Cut(chunk, []byte{0})
+			profile := string(name)
+			if !strings.Contains(strings.ToLower(profile), "srgb") {
+				r.add(SeverityWarning, "color-profile", "Convert the icon to sRGB when exporting",
+					"icon has the %q color profile; colors will shift on Android and Windows, which assume sRGB", profile)
+			}
+		case "IDAT", "IEND":
+			return
+		}
+		pos += 12 + n
+	}
+}
@@ -0,0 +1,85 @@
+package icons
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePNG(t *testing.T, img image.Image) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "icon-source.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func codes(r *Report) map[string]Severity {
+	m := map[string]Severity{}
+	for _, i := range r.Issues {
+		m[i.Code] = i.Severity
+	}
+	return m
+}
+
+func TestValidate(t *testing.T) {
+	// Small, not square, with a transparent corner
+	img := image.NewNRGBA(image.Rect(0, 0, 600, 520))
+	for y := 0; y < 520; y++ {
+		for x := 0; x < 600; x++ {
+			img.Set(x, y, color.NRGBA{200, 40, 40, 255})
+		}
+	}
+	img.Set(0, 0, color.NRGBA{})
+	path := writePNG(t, img)
+
+	r, err := Validate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := codes(r)
+	for _, c := range []string{"aspect", "size", "alpha"} {
+		if got[c] != SeverityError {
+			t.Errorf("%s: got %q, want error (issues %+v)", c, got[c], r.Issues)
+		}
+	}
+	if r.OK() || r.Err() == nil {
+		t.Error("report should fail")
+	}
+
+	// Android alone accepts 512px and transparency, with a size warning
+	r, _ = Validate(path, "android")
+	got = codes(r)
+	if _, ok := got["alpha"]; ok {
+		t.Error("alpha reported for android")
+	}
+	if got["size"] != SeverityWarning {
+		t.Errorf("size for android: %q", got["size"])
+	}
+}
+
+func TestValidatePlaceholder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "icon-source.png")
+	if err := GenerateTestIcon(path); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Validate(path, "ios")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.OK() {
+		t.Errorf("placeholder should pass: %+v", r.Issues)
+	}
+	if codes(r)["placeholder"] != SeverityWarning {
+		t.Errorf("placeholder not flagged: %+v", r.Issues)
+	}
+}
@@ -0,0 +1,169 @@
+package installer
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/androidrepo"
+	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/dlcache"
+)
+
+// InstallAndroidPackage installs the Android SDK package at path (e.g.
+// "build-tools;34.0.0") and the packages it depends on under sdkRoot,
+// straight from Google's repository. Unlike InstallAndroidSDK it needs
+// neither sdkmanager nor a JDK. It returns the package's directory.
+func InstallAndroidPackage(path, sdkRoot string) (string, error) {
+	client := androidrepo.NewClient(filepath.Join(config.GetCacheDir(), "android-repository"))
+	find := func(path string) (*androidrepo.Package, error) {
+		p, _, err := client.Find(path)
+		return p, err
+	}
+	installed := func(p *androidrepo.Package) bool {
+		return isAndroidPackageInstalled(p.Dir(sdkRoot))
+	}
+
+	fmt.Printf("🔎 Resolving %s in the Android repository...\n", path)
+	pkgs, err := androidrepo.Resolve(path, find, installed)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range pkgs {
+		if err := installAndroidPackage(client, p, sdkRoot); err != nil {
+			return "", fmt.Errorf("failed to install %s: %w", p.Path, err)
+		}
+	}
+	return (&androidrepo.Package{Path: path}).Dir(sdkRoot), nil
+}
+
+// isAndroidPackageInstalled reports whether dir holds an installed package;
+// sdkmanager and the package zips both leave one of these behind.
+func isAndroidPackageInstalled(dir string) bool {
+	for _, name := range []string{"source.properties", "package.xml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func installAndroidPackage(client *androidrepo.Client, p *androidrepo.Package, sdkRoot string) error {
+	_, repo, err := client.Find(p.Path)
+	if err != nil {
+		return err
+	}
+	if id := p.License.Ref; id != "" {
+		// sdkmanager is usually fed "y" for these too
+		fmt.Printf("📜 Accepting license %s for %s\n", id, p.Path)
+		if err := androidrepo.AcceptLicense(sdkRoot, id, repo.LicenseText(id)); err != nil {
+			return fmt.Errorf("failed to record license: %w", err)
+		}
+	}
+
+	archive, err := p.ArchiveFor(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	url, err := p.ArchiveURL(archive)
+	if err != nil {
+		return err
+	}
+
+	sdk := &SDK{Name: p.Path, Version: p.Revision.String(), URL: url}
+	file, stored, err := fetchAndroidArchive(sdk, dlcache.Open(), archive.Checksum)
+	if err != nil {
+		return err
+	}
+	if !stored {
+		defer os.Remove(file)
+	}
+
+	dest := p.Dir(sdkRoot)
+	fmt.Printf("📂 Extracting to %s...\n", dest)
+	if err := extractPackage(file, dest); err != nil {
+		return fmt.Errorf("failed to extract: %w", err)
+	}
+	fmt.Printf("✅ Installed %s %s\n", p.Path, sdk.Version)
+	return nil
+}
+
+// fetchAndroidArchive returns the package archive for sdk from the
+// download cache, or downloads it, and checks it against the repository's
+// SHA-1. The cache is keyed by URL here, so a cached archive that fails the
+// check (an interrupted download, or a URL that now serves a new build) is
+// removed and downloaded once more before giving up.
+func fetchAndroidArchive(sdk *SDK, store *dlcache.Store, sha1sum string) (file string, stored bool, err error) {
+	if file, ok := store.Lookup(sdk.URL, ""); ok {
+		fmt.Printf("♻️  Using cached download of %s %s\n", sdk.Name, sdk.Version)
+		err := verifySHA1(file, sha1sum)
+		if err == nil {
+			return file, true, nil
+		}
+		fmt.Printf("⚠️  Cached download of %s %s is bad (%v); downloading it again\n", sdk.Name, sdk.Version, err)
+		os.Remove(file)
+	}
+	file, stored, err = download(sdk, store)
+	if err != nil {
+		return "", false, err
+	}
+	if err := verifySHA1(file, sha1sum); err != nil {
+		os.Remove(file)
+		return "", false, err
+	}
+	return file, stored, nil
+}
+
+// verifySHA1 checks a download against the repository's SHA-1 checksum.
+func verifySHA1(path, want string) error {
+	if want == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch: expected sha1 %s, got %s", want, got)
+	}
+	return nil
+}
+
+// extractPackage unpacks a package zip into dest. The zips hold one
+// top-level directory (e.g. android-14/ for build-tools;34.0.0) whose
+// contents sdkmanager installs as dest.
+func extractPackage(archive, dest string) error {
+	staging := dest + ".installing"
+	os.RemoveAll(staging)
+	defer os.RemoveAll(staging)
+	if err := Extract(archive, staging); err != nil {
+		return err
+	}
+
+	src := staging
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		src = filepath.Join(staging, entries[0].Name())
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return os.Rename(src, dest)
+}
@@ -53,8 +53,9 @@ func getArchiveType(source string) (string, error) {
 		return "tar.gz", nil
 	}
 
-	// Fallback for xip files
-	if strings.HasSuffix(source, ".xip") {
+	// Fallback for xip files, which are xar archives; cached downloads
+	// have no extension
+	if strings.HasSuffix(source, ".xip") || bytes.Equal(buffer, []byte("xar!")) {
 		return "zip", nil
 	}
 
@@ -119,6 +120,9 @@ func unzip(source, destination string) error {
 
 	for _, f := range reader.File {
 		fpath := filepath.Join(destination, f.Name)
+		if !strings.HasPrefix(fpath, filepath.Clean(destination)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path in archive: %s", f.Name)
+		}
 
 		if f.FileInfo().IsDir() {
 			os.MkdirAll(fpath, os.ModePerm)
@@ -129,6 +133,14 @@ func unzip(source, destination string) error {
 			return err
 		}
 
+		// The NDK and emulator zips contain symlinks
+		if f.Mode()&os.ModeSymlink != 0 {
+			if err := unzipSymlink(f, fpath); err != nil {
+				return err
+			}
+			continue
+		}
+
 		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
 		if err != nil {
 			return err
@@ -152,3 +164,17 @@ func unzip(source, destination string) error {
 
 	return nil
 }
+
+func unzipSymlink(f *zip.File, fpath string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	target, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+	os.Remove(fpath)
+	return os.Symlink(string(target), fpath)
+}
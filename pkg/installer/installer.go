@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/dlcache"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -60,21 +61,82 @@ func Install(sdk *SDK, cache *Cache) error {
 		return fmt.Errorf("cannot automatically install SDK %s. Please install it manually (e.g., by installing or updating Xcode) and ensure it is available at %s", sdk.Name, dest)
 	}
 
+	// Reuse an earlier download of the same archive
+	store := dlcache.Open()
+	archive, ok := store.Lookup(sdk.URL, sdk.Checksum)
+	if ok {
+		fmt.Printf("♻️  Using cached download of %s %s\n", sdk.Name, sdk.Version)
+	} else {
+		var stored bool
+		archive, stored, err = download(sdk, store)
+		if err != nil {
+			return err
+		}
+		if !stored {
+			defer os.Remove(archive)
+		}
+	}
+
+	// The destination is already resolved, so we don't need to call ResolveInstallPath again.
+
+	// Extract the archive
+	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	fmt.Printf("📂 Extracting to %s...\n", dest)
+	if err := Extract(archive, dest); err != nil {
+		return fmt.Errorf("failed to extract SDK: %w", err)
+	}
+	fmt.Println("✅ Extraction complete.")
+
+	// Add to cache and save
+	cache.Add(sdk)
+	if err := cache.Save(); err != nil {
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+
+	fmt.Printf("Successfully installed %s %s to %s\n", sdk.Name, sdk.Version, dest)
+
+	// If OpenJDK was installed, print instructions for setting JAVA_HOME
+	if strings.Contains(sdk.Name, "openjdk") {
+		fmt.Println("\n---------------------------------------------------------------------")
+		fmt.Println("IMPORTANT: To use this JDK for Android development with Gio,")
+		fmt.Println("you need to set the JAVA_HOME environment variable.")
+		fmt.Println("\nFor your current shell session, run:")
+		fmt.Printf("export JAVA_HOME=\"%s\"\n", dest)
+		fmt.Println("\nTo make this change permanent, run:")
+		fmt.Println("goup-util env --apply")
+		fmt.Println("---------------------------------------------------------------------")
+	}
+
+	return nil
+}
+
+// download fetches an SDK archive and verifies its checksum. The archive is
+// moved into the download cache when possible; stored reports whether it
+// was, otherwise the caller removes the temporary file.
+func download(sdk *SDK, store *dlcache.Store) (archive string, stored bool, err error) {
 	fmt.Printf("📥 Downloading %s %s...\n", sdk.Name, sdk.Version)
 
 	// Create a temporary file with the correct extension from the URL
 	fileExt := filepath.Ext(sdk.URL)
 	tmpFile, err := os.CreateTemp("", "sdk-download-*"+fileExt)
 	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
+		return "", false, fmt.Errorf("failed to create temporary file: %w", err)
 	}
-	defer os.Remove(tmpFile.Name()) // Clean up the temp file
+	// Clean up the temp file on failure
+	defer func() {
+		if err != nil {
+			os.Remove(tmpFile.Name())
+		}
+	}()
 
 	// Get the data with retry and progress
 	client := &http.Client{Timeout: 60 * time.Minute} // Extended timeout for large downloads like NDK
 	req, err := http.NewRequest("GET", sdk.URL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Retry logic with exponential backoff
@@ -99,13 +161,13 @@ func Install(sdk *SDK, cache *Cache) error {
 			if resp != nil {
 				resp.Body.Close()
 			}
-			return fmt.Errorf("failed to download SDK after %d attempts: %w", maxRetries, err)
+			return "", false, fmt.Errorf("failed to download SDK after %d attempts: %w", maxRetries, err)
 		}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download SDK: received status code %d", resp.StatusCode)
+		return "", false, fmt.Errorf("failed to download SDK: received status code %d", resp.StatusCode)
 	}
 
 	// Get content length for progress bar
@@ -142,7 +204,7 @@ func Install(sdk *SDK, cache *Cache) error {
 	_, err = io.Copy(tmpFile, teeReader)
 	if err != nil {
 		tmpFile.Close()
-		return fmt.Errorf("failed to write to temporary file: %w", err)
+		return "", false, fmt.Errorf("failed to write to temporary file: %w", err)
 	}
 	tmpFile.Close()
 
@@ -151,46 +213,22 @@ func Install(sdk *SDK, cache *Cache) error {
 	expectedChecksum := strings.TrimPrefix(sdk.Checksum, "sha256:")
 
 	if expectedChecksum != "" && calculatedChecksum != expectedChecksum {
-		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, calculatedChecksum)
+		return "", false, fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, calculatedChecksum)
 	}
 	fmt.Println("✅ Checksum verified.")
 
 	fmt.Printf("📦 Downloaded %s %s (%.1f MB)\n", sdk.Name, sdk.Version, float64(contentLength)/1024/1024)
 
-	// The destination is already resolved, so we don't need to call ResolveInstallPath again.
-
-	// Extract the archive
-	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
-	}
-
-	fmt.Printf("📂 Extracting to %s...\n", dest)
-	if err := Extract(tmpFile.Name(), dest); err != nil {
-		return fmt.Errorf("failed to extract SDK: %w", err)
-	}
-	fmt.Println("✅ Extraction complete.")
-
-	// Add to cache and save
-	cache.Add(sdk)
-	if err := cache.Save(); err != nil {
-		return fmt.Errorf("failed to save cache: %w", err)
-	}
-
-	fmt.Printf("Successfully installed %s %s to %s\n", sdk.Name, sdk.Version, dest)
-
-	// If OpenJDK was installed, print instructions for setting JAVA_HOME
-	if strings.Contains(sdk.Name, "openjdk") {
-		fmt.Println("\n---------------------------------------------------------------------")
-		fmt.Println("IMPORTANT: To use this JDK for Android development with Gio,")
-		fmt.Println("you need to set the JAVA_HOME environment variable.")
-		fmt.Println("\nFor your current shell session, run:")
-		fmt.Printf("export JAVA_HOME=\"%s\"\n", dest)
-		fmt.Println("\nTo make this change permanent, add the line above to your")
-		fmt.Println("shell profile file (e.g., ~/.zshrc, ~/.bash_profile).")
-		fmt.Println("---------------------------------------------------------------------")
+	if path, putErr := store.Put(sdk.URL, tmpFile.Name(), calculatedChecksum); putErr == nil {
+		return path, true, nil
+	} else if path != "" {
+		// Stored, but the URL index could not be updated
+		fmt.Printf("⚠️  %v\n", putErr)
+		return path, true, nil
+	} else {
+		fmt.Printf("⚠️  Could not cache download: %v\n", putErr)
 	}
-
-	return nil
+	return tmpFile.Name(), false, nil
 }
 
 // isSDKComplete checks if an SDK installation appears complete
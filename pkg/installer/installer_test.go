@@ -1,12 +1,18 @@
 package installer
 
 import (
+	"archive/zip"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/joeblew999/goup-util/pkg/config"
+	"github.com/joeblew999/goup-util/pkg/dlcache"
 )
 
 func TestResolveInstallPath(t *testing.T) {
@@ -159,3 +165,72 @@ func TestSDKInstallationUsesOSLevelPaths(t *testing.T) {
 		t.Errorf("Resolved path %q should be absolute", resolvedPath)
 	}
 }
+
+func TestExtractPackage(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "build-tools.zip")
+	f, _ := os.Create(archive)
+	zw := zip.NewWriter(f)
+	hdr := &zip.FileHeader{Name: "android-14/aapt2"}
+	hdr.SetMode(0755)
+	w, _ := zw.CreateHeader(hdr)
+	w.Write([]byte("aapt2"))
+	hdr = &zip.FileHeader{Name: "android-14/aapt"}
+	hdr.SetMode(os.ModeSymlink | 0777)
+	w, _ = zw.CreateHeader(hdr)
+	w.Write([]byte("aapt2"))
+	zw.Close()
+	f.Close()
+
+	dest := filepath.Join(t.TempDir(), "build-tools", "34.0.0")
+	if err := extractPackage(archive, dest); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "aapt2")); err != nil || info.Mode()&0100 == 0 {
+		t.Errorf("aapt2 missing or not executable: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(dest, "aapt")); err != nil || target != "aapt2" {
+		t.Errorf("aapt symlink = %q, %v", target, err)
+	}
+	if _, err := os.Stat(dest + ".installing"); !os.IsNotExist(err) {
+		t.Error("staging directory left behind")
+	}
+}
+
+func TestFetchAndroidArchiveRedownloadsBadCache(t *testing.T) {
+	good := []byte("build-tools 34.0.0")
+	sum := sha1.Sum(good)
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write(good)
+	}))
+	defer srv.Close()
+
+	// A truncated download cached under the package URL
+	store := &dlcache.Store{Dir: t.TempDir()}
+	sdk := &SDK{Name: "build-tools;34.0.0", Version: "34.0.0", URL: srv.URL + "/build-tools.zip"}
+	bad := filepath.Join(t.TempDir(), "bad.zip")
+	os.WriteFile(bad, good[:5], 0644)
+	badSum, _ := dlcache.HashFile(bad)
+	badPath, err := store.Put(sdk.URL, bad, badSum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, _, err := fetchAndroidArchive(sdk, store, hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != string(good) || downloads != 1 {
+		t.Errorf("got %q after %d downloads", data, downloads)
+	}
+	if _, err := os.Stat(badPath); !os.IsNotExist(err) {
+		t.Error("bad cached archive kept")
+	}
+
+	// A download that fails the check too is an error, not a loop
+	downloads = 0
+	if _, _, err := fetchAndroidArchive(sdk, store, strings.Repeat("0", 40)); err == nil || downloads != 1 {
+		t.Errorf("mismatch after download = %v after %d downloads", err, downloads)
+	}
+}
@@ -0,0 +1,232 @@
+// Package licensing issues and checks offline license files for shells
+// distributed to customers. Licenses are signed with an ed25519 key kept
+// by the vendor; apps embed only the public key and validate the file
+// without calling home:
+//
+//	lic, err := licensing.ValidateFile(publicKey, "portal", licensing.DefaultPath("acme-portal"))
+//	if err != nil {
+//		// show the activation page
+//	}
+//	if lic.HasFeature("offline") { ... }
+//
+// A license file is JSON holding the license and its signature, so it can
+// be read but not edited.
+package licensing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/updater"
+)
+
+// FileName is the license file name in DefaultPath.
+const FileName = "license.lic"
+
+// Errors returned by Verify and the checks, for apps to tell the user why.
+var (
+	ErrSignature = errors.New("license signature is invalid")
+	ErrExpired   = errors.New("license has expired")
+	ErrMachine   = errors.New("license is for another machine")
+	ErrProduct   = errors.New("license is for another product")
+)
+
+// License is the signed content of a license file.
+type License struct {
+	ID       string    `json:"id"`
+	Product  string    `json:"product,omitempty"` // See CheckProduct
+	Licensee string    `json:"licensee"`
+	Email    string    `json:"email,omitempty"`
+	Features []string  `json:"features,omitempty"`
+	Seats    int       `json:"seats,omitempty"`
+	Machine  string    `json:"machine,omitempty"` // Locks the license to one machine ID
+	Issued   time.Time `json:"issued"`
+	Expires  time.Time `json:"expires,omitempty"` // Zero: perpetual
+}
+
+// file is the on-disk format.
+type file struct {
+	License   json.RawMessage `json:"license"`
+	Signature string          `json:"signature"`
+}
+
+// GenerateKey returns a new key pair, base64 encoded.
+func GenerateKey() (publicKey, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(priv), nil
+}
+
+// ParsePublicKey decodes a base64 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("not an ed25519 public key")
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// ParsePrivateKey decodes a base64 private key.
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("not an ed25519 private key")
+	}
+	return ed25519.PrivateKey(b), nil
+}
+
+// Sign fills in the ID and issue time when unset and returns the license
+// file.
+func Sign(privateKey string, l License) ([]byte, error) {
+	key, err := ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if l.Licensee == "" {
+		return nil, fmt.Errorf("license has no licensee")
+	}
+	if l.ID == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		l.ID = hex.EncodeToString(b)
+	}
+	if l.Issued.IsZero() {
+		l.Issued = time.Now().UTC().Truncate(time.Second)
+	}
+	payload, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(file{
+		License:   payload,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Verify checks the signature of a license file and returns its license.
+// It does not check expiry or the machine; see Check.
+func Verify(publicKey string, data []byte) (*License, error) {
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil || len(f.License) == 0 {
+		return nil, fmt.Errorf("not a license file")
+	}
+	sig, err := base64.StdEncoding.DecodeString(f.Signature)
+	if err != nil || !ed25519.Verify(key, signed(f.License), sig) {
+		return nil, ErrSignature
+	}
+	var l License
+	if err := json.Unmarshal(f.License, &l); err != nil {
+		return nil, fmt.Errorf("not a license file: %w", err)
+	}
+	return &l, nil
+}
+
+// signed returns the bytes that were signed: the license as json.Marshal
+// wrote it, before the file was indented.
+func signed(raw json.RawMessage) []byte {
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return raw
+	}
+	return b.Bytes()
+}
+
+// Check reports whether the license is valid at now on the given machine.
+func (l *License) Check(now time.Time, machineID string) error {
+	if !l.Expires.IsZero() && now.After(l.Expires) {
+		return fmt.Errorf("%w on %s", ErrExpired, l.Expires.Format("2006-01-02"))
+	}
+	if l.Machine != "" && l.Machine != machineID {
+		return ErrMachine
+	}
+	return nil
+}
+
+// CheckProduct reports whether the license covers product. Licenses
+// without a product cover every product signed with the same key; an
+// empty product is covered only by those.
+func (l *License) CheckProduct(product string) error {
+	if l.Product != "" && l.Product != product {
+		return ErrProduct
+	}
+	return nil
+}
+
+// HasFeature reports whether the license grants feature.
+func (l *License) HasFeature(feature string) bool {
+	for _, f := range l.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate verifies a license file and checks it against the app's
+// product, the current time and this machine. This is the check apps run
+// at startup.
+func Validate(publicKey, product string, data []byte) (*License, error) {
+	l, err := Verify(publicKey, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.CheckProduct(product); err != nil {
+		return l, err
+	}
+	if err := l.Check(time.Now(), updater.MachineID()); err != nil {
+		return l, err
+	}
+	return l, nil
+}
+
+// ValidateFile reads and validates the license file at path.
+func ValidateFile(publicKey, product, path string) (*License, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no license: %w", err)
+	}
+	return Validate(publicKey, product, data)
+}
+
+// DefaultPath is where an app keeps its license: <config>/<app>/license.lic.
+func DefaultPath(app string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, app, FileName)
+}
+
+// Install copies a license file to DefaultPath(app) after validating it
+// for product, for apps that let users activate by picking a file.
+func Install(publicKey, app, product string, data []byte) (*License, error) {
+	l, err := Validate(publicKey, product, data)
+	if err != nil {
+		return nil, err
+	}
+	path := DefaultPath(app)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return l, os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,104 @@
+package licensing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	data, err := Sign(priv, License{Licensee: "Acme <Corp>", Product: "portal", Features: []string{"offline"}, Expires: expires})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Verify(pub, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.ID == "" || l.Issued.IsZero() || !l.HasFeature("offline") || l.HasFeature("kiosk") {
+		t.Errorf("license = %+v", l)
+	}
+	if err := l.CheckProduct("portal"); err != nil {
+		t.Error(err)
+	}
+	if err := l.CheckProduct("hub"); !errors.Is(err, ErrProduct) {
+		t.Errorf("CheckProduct(hub) = %v", err)
+	}
+
+	// Reformatting the file keeps it valid
+	var buf bytes.Buffer
+	json.Compact(&buf, data)
+	if _, err := Verify(pub, buf.Bytes()); err != nil {
+		t.Errorf("compacted file: %v", err)
+	}
+
+	// Editing the license does not
+	edited := bytes.Replace(data, []byte("offline"), []byte("kiosk"), 1)
+	if _, err := Verify(pub, edited); !errors.Is(err, ErrSignature) {
+		t.Errorf("edited license: %v", err)
+	}
+	other, _, _ := GenerateKey()
+	if _, err := Verify(other, data); !errors.Is(err, ErrSignature) {
+		t.Errorf("other key: %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	l := &License{Machine: "m1", Expires: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := l.Check(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), "m1"); err != nil {
+		t.Error(err)
+	}
+	if err := l.Check(time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC), "m1"); !errors.Is(err, ErrExpired) {
+		t.Errorf("expired: %v", err)
+	}
+	if err := l.Check(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), "m2"); !errors.Is(err, ErrMachine) {
+		t.Errorf("other machine: %v", err)
+	}
+	if err := (&License{}).Check(time.Now(), ""); err != nil {
+		t.Errorf("perpetual: %v", err)
+	}
+}
+
+func TestValidateProduct(t *testing.T) {
+	pub, priv, _ := GenerateKey()
+	portal, _ := Sign(priv, License{Licensee: "Acme", Product: "portal"})
+	all, _ := Sign(priv, License{Licensee: "Acme"})
+
+	tests := []struct {
+		data    []byte
+		product string
+		want    error
+	}{
+		{portal, "portal", nil},
+		{portal, "hub", ErrProduct},
+		{portal, "", ErrProduct},
+		{all, "hub", nil},
+		{all, "", nil},
+	}
+	for _, tt := range tests {
+		if _, err := Validate(pub, tt.product, tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(%q) = %v, want %v", tt.product, err, tt.want)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	pub, priv, _ := GenerateKey()
+	if _, err := ParsePublicKey(priv); err == nil {
+		t.Error("private key accepted as public")
+	}
+	if _, err := Sign(pub, License{Licensee: "x"}); err == nil {
+		t.Error("signed with public key")
+	}
+	if _, err := Sign(priv, License{}); err == nil {
+		t.Error("license without licensee")
+	}
+}
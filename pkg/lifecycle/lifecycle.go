@@ -0,0 +1,152 @@
+// Package lifecycle shuts an app down in order: background work started
+// with Context is cancelled, then shutdown hooks run, newest first, within
+// a bounded time, before the process exits.
+//
+// Gio apps learn that they are closing from app.DestroyEvent, and calling
+// os.Exit there skips every deferred call and goroutine, so telemetry is
+// not flushed, state is not saved and embedded servers drop requests.
+// Exit runs the hooks first:
+//
+//	life := lifecycle.New()
+//	go healthcheck.Run(life.Context(), ...)
+//	life.OnShutdown("server", srv.Shutdown)
+//	life.OnShutdown("database", func(context.Context) error { return db.Close() })
+//	...
+//	case app.DestroyEvent:
+//		life.Exit(0)
+//
+// A hook still running when the timeout expires is abandoned, so a hung
+// hook cannot keep a closed app alive.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultTimeout bounds how long shutdown hooks may take together.
+const DefaultTimeout = 5 * time.Second
+
+// Hook is a shutdown hook. ctx expires when the shutdown timeout does.
+type Hook func(ctx context.Context) error
+
+// Manager runs an app's shutdown hooks.
+type Manager struct {
+	Timeout time.Duration // Default DefaultTimeout
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	hooks []namedHook
+	done  chan struct{}
+	err   error
+	once  sync.Once
+}
+
+type namedHook struct {
+	name string
+	fn   Hook
+}
+
+// New returns a Manager whose Context is live until Shutdown.
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{ctx: ctx, cancel: cancel, done: make(chan struct{})}
+}
+
+// Context is cancelled when shutdown starts; pass it to background work.
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// OnShutdown registers fn to run on shutdown. Hooks run one at a time,
+// the last registered first, like deferred calls. Hooks registered once
+// shutdown has started do not run.
+func (m *Manager) OnShutdown(name string, fn Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, namedHook{name, fn})
+}
+
+// Shutdown cancels Context and runs the hooks, giving them Timeout in
+// total. It returns the hooks' errors, including those that timed out.
+// Calls after the first wait for it and return the same result.
+func (m *Manager) Shutdown() error {
+	m.once.Do(func() {
+		defer close(m.done)
+		m.cancel()
+		timeout := m.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		m.mu.Lock()
+		hooks := m.hooks
+		m.hooks = nil
+		m.mu.Unlock()
+
+		var errs []error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := run(ctx, hooks[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		m.err = errors.Join(errs...)
+	})
+	<-m.done
+	return m.err
+}
+
+// run runs h, giving up on it when ctx expires.
+func run(ctx context.Context, h namedHook) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s: skipped, shutdown timed out", h.name)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errc <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errc <- h.fn(ctx)
+	}()
+	select {
+	case err := <-errc:
+		if err != nil {
+			return fmt.Errorf("%s: %w", h.name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: shutdown timed out", h.name)
+	}
+}
+
+// Exit shuts down, logging hook errors, and exits with code.
+func (m *Manager) Exit(code int) {
+	if err := m.Shutdown(); err != nil {
+		log.Printf("lifecycle: %v", err)
+	}
+	os.Exit(code)
+}
+
+// HandleSignals exits cleanly on SIGINT and SIGTERM (Ctrl-C in the
+// terminal an app was started from, a service stop, a logout).
+func (m *Manager) HandleSignals() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		m.Exit(0)
+	}()
+}
@@ -0,0 +1,83 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	m := New()
+	var ran []string
+	for _, name := range []string{"server", "database", "telemetry"} {
+		m.OnShutdown(name, func(context.Context) error {
+			ran = append(ran, name)
+			return nil
+		})
+	}
+	if err := m.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"telemetry", "database", "server"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+	if m.Context().Err() == nil {
+		t.Error("Context not cancelled")
+	}
+
+	// Once only.
+	if err := m.Shutdown(); err != nil || len(ran) != 3 {
+		t.Errorf("second Shutdown = %v, ran %v", err, ran)
+	}
+}
+
+func TestShutdownErrors(t *testing.T) {
+	m := New()
+	m.Timeout = 50 * time.Millisecond
+	m.OnShutdown("hung", func(ctx context.Context) error {
+		select {} // Ignores ctx
+	})
+	m.OnShutdown("failing", func(context.Context) error {
+		return errors.New("disk full")
+	})
+	m.OnShutdown("panicking", func(context.Context) error {
+		panic("boom")
+	})
+
+	start := time.Now()
+	err := m.Shutdown()
+	if time.Since(start) > time.Second {
+		t.Errorf("Shutdown took %s", time.Since(start))
+	}
+	for _, want := range []string{"failing: disk full", "panicking: panic: boom", "hung: shutdown timed out"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %q", err, want)
+		}
+	}
+}
+
+func TestShutdownConcurrent(t *testing.T) {
+	m := New()
+	var calls int
+	m.OnShutdown("once", func(context.Context) error {
+		calls++
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Shutdown()
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("hook ran %d times", calls)
+	}
+}
@@ -0,0 +1,308 @@
+// Package listing generates the non-icon store assets an app listing
+// needs: the Google Play feature graphic, Android TV banner, and App Store
+// style preview frames composited from screenshots. They are described by a
+// listing.json next to the app:
+//
+//	{
+//	  "title": "Hybrid Dashboard",
+//	  "tagline": "All your metrics, on every device",
+//	  "background": "#0F172A",
+//	  "assets": [
+//	    {"type": "feature-graphic"},
+//	    {"type": "tv-banner"},
+//	    {"type": "preview", "store": "macos",
+//	     "screenshots": ["screenshots/macos/*.png"],
+//	     "captions": ["Live charts", "Works offline"]}
+//	  ]
+//	}
+//
+// Generated files go to .dist/listing (or "output") where the publish
+// commands pick them up.
+package listing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/constants"
+)
+
+// FileName is the listing config in the app directory.
+const FileName = "listing.json"
+
+// Asset types.
+const (
+	FeatureGraphic = "feature-graphic" // Google Play, 1024x500
+	TVBanner       = "tv-banner"       // Android TV / Google TV, 1280x720
+	Preview        = "preview"         // Screenshot in a captioned frame
+)
+
+// PreviewSizes are the default preview frame sizes per store.
+var PreviewSizes = map[string]string{
+	"macos":   "2880x1800",
+	"ios":     "1290x2796",
+	"android": "1080x1920",
+	"windows": "1920x1080",
+}
+
+var defaultSizes = map[string]string{
+	FeatureGraphic: "1024x500",
+	TVBanner:       "1280x720",
+}
+
+// Listing is a parsed listing.json.
+type Listing struct {
+	Title      string  `json:"title" jsonschema:"App name shown on the graphics"`
+	Tagline    string  `json:"tagline,omitempty" jsonschema:"Short line under the title"`
+	Background string  `json:"background,omitempty" jsonschema:"Background color (#RRGGBB)"`
+	Foreground string  `json:"foreground,omitempty" jsonschema:"Text color (#RRGGBB)"`
+	Template   string  `json:"template,omitempty" jsonschema:"Background image used instead of the color, relative to the app"`
+	Icon       string  `json:"icon,omitempty" jsonschema:"Icon to place on the graphics (default icon-source.png)"`
+	Output     string  `json:"output,omitempty" jsonschema:"Output directory relative to the app (default .dist/listing)"`
+	Assets     []Asset `json:"assets,omitempty" jsonschema:"Assets to generate (default feature graphic and TV banner)"`
+
+	dir string
+}
+
+// Asset is one graphic, or one preview frame per screenshot.
+type Asset struct {
+	Type        string   `json:"type" jsonschema:"feature-graphic, tv-banner or preview"`
+	Store       string   `json:"store,omitempty" jsonschema:"Preview store: macos, ios, android or windows"`
+	Size        string   `json:"size,omitempty" jsonschema:"WIDTHxHEIGHT (default per type and store)"`
+	Screenshots []string `json:"screenshots,omitempty" jsonschema:"Preview screenshots: files or globs relative to the app"`
+	Captions    []string `json:"captions,omitempty" jsonschema:"Preview captions, one per screenshot"`
+	Template    string   `json:"template,omitempty" jsonschema:"Background image for this asset"`
+}
+
+// Load reads listing.json from appDir. It returns nil when there is none.
+func Load(appDir string) (*Listing, error) {
+	path := filepath.Join(appDir, FileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	l := &Listing{dir: appDir}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := l.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Default returns the listing used when an app has no listing.json.
+func Default(appDir, title string) *Listing {
+	return &Listing{Title: title, dir: appDir}
+}
+
+// Validate checks asset types, stores and sizes.
+func (l *Listing) Validate() error {
+	if l.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	for _, c := range []string{l.Background, l.Foreground} {
+		if _, err := parseColor(c, color.Black); err != nil {
+			return err
+		}
+	}
+	for i, a := range l.Assets {
+		switch a.Type {
+		case FeatureGraphic, TVBanner:
+		case Preview:
+			if _, ok := PreviewSizes[a.Store]; !ok {
+				return fmt.Errorf("asset %d: preview needs a store (macos, ios, android or windows)", i+1)
+			}
+			if len(a.Screenshots) == 0 {
+				return fmt.Errorf("asset %d: preview needs screenshots", i+1)
+			}
+		default:
+			return fmt.Errorf("asset %d: unknown type %q (use %s, %s or %s)", i+1, a.Type, FeatureGraphic, TVBanner, Preview)
+		}
+		if _, _, err := l.size(a); err != nil {
+			return fmt.Errorf("asset %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// OutputDir is where the assets are written.
+func (l *Listing) OutputDir() string {
+	if l.Output != "" {
+		return filepath.Join(l.dir, l.Output)
+	}
+	return filepath.Join(l.dir, constants.DistDir, "listing")
+}
+
+func (l *Listing) assets() []Asset {
+	if len(l.Assets) > 0 {
+		return l.Assets
+	}
+	return []Asset{{Type: FeatureGraphic}, {Type: TVBanner}}
+}
+
+func (l *Listing) size(a Asset) (int, int, error) {
+	s := a.Size
+	if s == "" {
+		s = defaultSizes[a.Type]
+		if a.Type == Preview {
+			s = PreviewSizes[a.Store]
+		}
+	}
+	ws, hs, ok := strings.Cut(s, "x")
+	w, err1 := strconv.Atoi(ws)
+	h, err2 := strconv.Atoi(hs)
+	if !ok || err1 != nil || err2 != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("bad size %q (use WIDTHxHEIGHT)", s)
+	}
+	return w, h, nil
+}
+
+// screenshots expands an asset's screenshot globs in order.
+func (l *Listing) screenshots(a Asset) ([]string, error) {
+	var files []string
+	for _, pattern := range a.Screenshots {
+		matches, err := filepath.Glob(filepath.Join(l.dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("bad screenshot pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no screenshots match %s", pattern)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// Generate writes every asset and returns the files created.
+func (l *Listing) Generate() ([]string, error) {
+	out := l.OutputDir()
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	style, err := l.style()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	count := map[string]int{}
+	for _, a := range l.assets() {
+		w, h, err := l.size(a)
+		if err != nil {
+			return nil, err
+		}
+		bg, err := l.background(a, w, h, style)
+		if err != nil {
+			return nil, err
+		}
+		switch a.Type {
+		case FeatureGraphic, TVBanner:
+			img := banner(bg, style, l.Title, l.Tagline, a.Type == TVBanner)
+			name := a.Type + ".png"
+			if count[a.Type]++; count[a.Type] > 1 {
+				name = fmt.Sprintf("%s-%dx%d.png", a.Type, w, h)
+			}
+			path := filepath.Join(out, name)
+			if err := savePNG(img, path); err != nil {
+				return nil, err
+			}
+			files = append(files, path)
+		case Preview:
+			shots, err := l.screenshots(a)
+			if err != nil {
+				return nil, err
+			}
+			for i, shot := range shots {
+				src, err := loadImage(shot)
+				if err != nil {
+					return nil, err
+				}
+				caption := ""
+				if i < len(a.Captions) {
+					caption = a.Captions[i]
+				}
+				count[a.Store]++
+				frame, err := l.background(a, w, h, style)
+				if err != nil {
+					return nil, err
+				}
+				path := filepath.Join(out, fmt.Sprintf("preview-%s-%d.png", a.Store, count[a.Store]))
+				if err := savePNG(preview(frame, style, src, caption), path); err != nil {
+					return nil, err
+				}
+				files = append(files, path)
+			}
+		}
+	}
+	return files, nil
+}
+
+// style is the resolved colors and icon.
+type style struct {
+	bg, fg color.Color
+	icon   image.Image
+}
+
+func (l *Listing) style() (style, error) {
+	s := style{}
+	var err error
+	if s.bg, err = parseColor(l.Background, color.RGBA{0x1F, 0x29, 0x37, 0xFF}); err != nil {
+		return s, err
+	}
+	if s.fg, err = parseColor(l.Foreground, color.White); err != nil {
+		return s, err
+	}
+	icon := l.Icon
+	if icon == "" {
+		icon = "icon-source.png"
+	}
+	if img, err := loadImage(filepath.Join(l.dir, icon)); err == nil {
+		s.icon = img
+	} else if l.Icon != "" {
+		return s, err
+	}
+	return s, nil
+}
+
+func (l *Listing) background(a Asset, w, h int, s style) (*image.NRGBA, error) {
+	tmpl := a.Template
+	if tmpl == "" {
+		tmpl = l.Template
+	}
+	if tmpl == "" {
+		return fill(w, h, s.bg), nil
+	}
+	img, err := loadImage(filepath.Join(l.dir, tmpl))
+	if err != nil {
+		return nil, err
+	}
+	return cover(img, w, h), nil
+}
+
+func parseColor(s string, def color.Color) (color.Color, error) {
+	if s == "" {
+		return def, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return nil, fmt.Errorf("bad color %q (use #RRGGBB)", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xFF}, nil
+}
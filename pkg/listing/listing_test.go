@@ -0,0 +1,68 @@
+package listing
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	if err := savePNG(fill(600, 400, color.White), filepath.Join(dir, "shots", "one.png")); err != nil {
+		t.Fatal(err)
+	}
+	if err := savePNG(fill(512, 512, color.RGBA{0, 0, 255, 255}), filepath.Join(dir, "icon-source.png")); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"title": "Hybrid Dashboard", "tagline": "All your metrics, on every device",
+	  "assets": [{"type": "feature-graphic"}, {"type": "tv-banner"},
+	    {"type": "preview", "store": "windows", "size": "960x540", "screenshots": ["shots/*.png"], "captions": ["Live charts"]}]}`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := l.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]image.Point{
+		"feature-graphic.png":   {1024, 500},
+		"tv-banner.png":         {1280, 720},
+		"preview-windows-1.png": {960, 540},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("generated %v, want %d files", files, len(want))
+	}
+	for _, f := range files {
+		img, err := loadImage(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := img.Bounds().Size(); got != want[filepath.Base(f)] {
+			t.Errorf("%s is %v, want %v", filepath.Base(f), got, want[filepath.Base(f)])
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for name, l := range map[string]Listing{
+		"no title":      {},
+		"bad color":     {Title: "x", Background: "navy"},
+		"unknown type":  {Title: "x", Assets: []Asset{{Type: "poster"}}},
+		"preview store": {Title: "x", Assets: []Asset{{Type: Preview, Screenshots: []string{"a.png"}}}},
+		"bad size":      {Title: "x", Assets: []Asset{{Type: FeatureGraphic, Size: "wide"}}},
+	} {
+		if err := l.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := Default(".", "x").Validate(); err != nil {
+		t.Errorf("default listing: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package listing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nfnt/resize"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// banner lays out the icon, title and tagline: side by side for the
+// feature graphic, stacked and centered for the TV banner.
+func banner(dst *image.NRGBA, s style, title, tagline string, stacked bool) *image.NRGBA {
+	w, h := dst.Bounds().Dx(), dst.Bounds().Dy()
+	margin := h / 8
+
+	if stacked {
+		y := h / 6
+		if s.icon != nil {
+			size := h * 38 / 100
+			drawIcon(dst, s.icon, (w-size)/2, y, size)
+			y += size + h/20
+		}
+		y = drawText(dst, true, title, float64(h)/10, w-2*margin, 1, margin, y, true, s.fg)
+		drawText(dst, false, tagline, float64(h)/22, w-2*margin, 1, margin, y+h/60, true, s.fg)
+		return dst
+	}
+
+	x := margin
+	if s.icon != nil {
+		size := h * 55 / 100
+		drawIcon(dst, s.icon, margin, (h-size)/2, size)
+		x += size + margin
+	}
+	width := w - x - margin
+	titleFace, titleLines := fitText(true, title, float64(h)*0.16, width, 2)
+	tagFace, tagLines := fitText(false, tagline, float64(h)*0.075, width, 2)
+	block := lineHeight(titleFace) * len(titleLines)
+	if len(tagLines) > 0 {
+		block += h/30 + lineHeight(tagFace)*len(tagLines)
+	}
+	y := (h - block) / 2
+	y = drawLines(dst, titleFace, titleLines, x, y, width, false, s.fg)
+	drawLines(dst, tagFace, tagLines, x, y+h/30, width, false, s.fg)
+	return dst
+}
+
+// preview puts a caption above the screenshot, which is scaled to fit the
+// rest of the frame.
+func preview(dst *image.NRGBA, s style, shot image.Image, caption string) *image.NRGBA {
+	w, h := dst.Bounds().Dx(), dst.Bounds().Dy()
+	margin := min(w, h) * 6 / 100
+	top := margin
+	if caption != "" {
+		top = drawText(dst, true, caption, float64(min(w, h))*0.07, w-2*margin, 2, margin, margin, true, s.fg) + margin/2
+	}
+
+	areaW, areaH := w-2*margin, h-top-margin
+	b := shot.Bounds()
+	scale := min(float64(areaW)/float64(b.Dx()), float64(areaH)/float64(b.Dy()))
+	sw, sh := int(float64(b.Dx())*scale), int(float64(b.Dy())*scale)
+	scaled := resize.Resize(uint(sw), uint(sh), shot, resize.Lanczos3)
+	at := image.Pt((w-sw)/2, top+(areaH-sh)/2)
+
+	offset := max(4, sw/100)
+	shadow := image.Rectangle{at.Add(image.Pt(offset, offset)), at.Add(image.Pt(sw+offset, sh+offset))}
+	draw.Draw(dst, shadow, image.NewUniform(color.NRGBA{0, 0, 0, 80}), image.Point{}, draw.Over)
+	draw.Draw(dst, image.Rectangle{at, at.Add(image.Pt(sw, sh))}, scaled, scaled.Bounds().Min, draw.Over)
+	return dst
+}
+
+func drawIcon(dst *image.NRGBA, icon image.Image, x, y, size int) {
+	scaled := resize.Resize(uint(size), uint(size), icon, resize.Lanczos3)
+	draw.Draw(dst, image.Rect(x, y, x+size, y+size), scaled, scaled.Bounds().Min, draw.Over)
+}
+
+// drawText fits text into width and maxLines, draws it from y and returns
+// the y below it.
+func drawText(dst *image.NRGBA, bold bool, text string, size float64, width, maxLines, x, y int, center bool, c color.Color) int {
+	face, lines := fitText(bold, text, size, width, maxLines)
+	return drawLines(dst, face, lines, x, y, width, center, c)
+}
+
+func drawLines(dst *image.NRGBA, face font.Face, lines []string, x, y, width int, center bool, c color.Color) int {
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: face}
+	ascent := face.Metrics().Ascent.Ceil()
+	for _, line := range lines {
+		lx := x
+		if center {
+			lx += (width - d.MeasureString(line).Ceil()) / 2
+		}
+		d.Dot = fixed.P(lx, y+ascent)
+		d.DrawString(line)
+		y += lineHeight(face)
+	}
+	return y
+}
+
+func lineHeight(face font.Face) int {
+	return face.Metrics().Height.Ceil() * 115 / 100
+}
+
+// fitText shrinks the font until text wraps into at most maxLines lines of
+// width.
+func fitText(bold bool, text string, size float64, width, maxLines int) (font.Face, []string) {
+	for {
+		face := newFace(bold, size)
+		lines, ok := wrap(face, text, width)
+		if (ok && len(lines) <= maxLines) || size < 8 {
+			return face, lines
+		}
+		size *= 0.9
+	}
+}
+
+// wrap breaks text into lines no wider than width; ok is false when a
+// single word does not fit.
+func wrap(face font.Face, text string, width int) (lines []string, ok bool) {
+	ok = true
+	line := ""
+	for _, word := range strings.Fields(text) {
+		next := strings.TrimSpace(line + " " + word)
+		if font.MeasureString(face, next).Ceil() <= width {
+			line = next
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		if font.MeasureString(face, word).Ceil() > width {
+			ok = false
+		}
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines, ok
+}
+
+var fonts = sync.OnceValues(func() (*opentype.Font, *opentype.Font) {
+	regular, _ := opentype.Parse(goregular.TTF)
+	bold, _ := opentype.Parse(gobold.TTF)
+	return regular, bold
+})
+
+func newFace(bold bool, size float64) font.Face {
+	regular, b := fonts()
+	f := regular
+	if bold {
+		f = b
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic(err) // The embedded Go fonts always parse
+	}
+	return face
+}
+
+func fill(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+// cover scales img to fill w x h, cropping the overflow.
+func cover(img image.Image, w, h int) *image.NRGBA {
+	b := img.Bounds()
+	scale := max(float64(w)/float64(b.Dx()), float64(h)/float64(b.Dy()))
+	sw, sh := max(w, int(float64(b.Dx())*scale+0.5)), max(h, int(float64(b.Dy())*scale+0.5))
+	scaled := resize.Resize(uint(sw), uint(sh), img, resize.Bilinear)
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(out, out.Bounds(), scaled, scaled.Bounds().Min.Add(image.Pt((sw-w)/2, (sh-h)/2)), draw.Src)
+	return out
+}
+
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+func savePNG(img image.Image, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	return nil
+}
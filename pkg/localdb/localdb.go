@@ -0,0 +1,272 @@
+// Package localdb gives hybrid apps an embedded SQLite database with
+// numbered migrations and a small REST API, so the web UI can keep data on
+// the device without writing a server by hand:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	db, err := localdb.OpenApp("my-app", localdb.MustMigrations(migrations, "migrations"))
+//	mux.Handle("/api/data/", http.StripPrefix("/api/data", db.REST("notes")))
+//
+// The driver is modernc.org/sqlite, which is pure Go, so apps keep cross
+// compiling to every platform without cgo. 'goup-util data inspect' opens
+// the same file to look at an app's data while debugging.
+package localdb
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// FileName is the database file OpenApp uses in the app's config directory.
+const FileName = "data.db"
+
+// DB is an open app database. The embedded *sql.DB is there for queries
+// the REST scaffold does not cover.
+type DB struct {
+	*sql.DB
+	Path string
+}
+
+// AppPath returns where OpenApp keeps the database for appName.
+func AppPath(appName string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName, FileName), nil
+}
+
+// OpenApp opens the database for appName in the user's config directory
+// and applies migrations.
+func OpenApp(appName string, migrations []string) (*DB, error) {
+	p, err := AppPath(appName)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return nil, err
+	}
+	return Open(p, migrations)
+}
+
+// Open opens (creating if needed) the database at path and applies any
+// migrations it has not seen yet. Migration i (counting from 1) runs once,
+// in its own transaction, and SQLite's user_version records how many ran.
+// Migrations are append-only: edit the schema by adding one, never by
+// changing one that shipped.
+func Open(path string, migrations []string) (*DB, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	// One connection serializes writers; an app's own UI is the only client.
+	db.SetMaxOpenConns(1)
+	d := &DB{DB: db, Path: path}
+	if err := d.migrate(migrations); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// OpenReadOnly opens an existing database without migrating or writing it.
+func OpenReadOnly(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path), RawQuery: "mode=ro&_pragma=busy_timeout(5000)"}
+	db, err := sql.Open("sqlite", u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return &DB{DB: db, Path: path}, nil
+}
+
+// Version returns how many migrations have been applied.
+func (d *DB) Version() (int, error) {
+	var v int
+	err := d.QueryRow(`PRAGMA user_version`).Scan(&v)
+	return v, err
+}
+
+func (d *DB) migrate(migrations []string) error {
+	have, err := d.Version()
+	if err != nil {
+		return err
+	}
+	if have > len(migrations) {
+		return fmt.Errorf("database is at version %d but the app only knows %d migrations (was it opened by a newer version?)", have, len(migrations))
+	}
+	for i := have; i < len(migrations); i++ {
+		tx, err := d.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		// user_version lives in the file header, so it commits with the tx.
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Migrations reads the *.sql files in dir of fsys, sorted by name, so
+// 001_notes.sql runs before 002_tags.sql.
+func Migrations(fsys fs.FS, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	migrations := make([]string, len(names))
+	for i, name := range names {
+		b, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		migrations[i] = string(b)
+	}
+	return migrations, nil
+}
+
+// MustMigrations is Migrations for embedded files, which cannot fail at
+// run time once they compiled.
+func MustMigrations(fsys fs.FS, dir string) []string {
+	m, err := Migrations(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Table describes one user table for inspection.
+type Table struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+}
+
+// Tables lists the database's own tables (not SQLite's internal ones).
+func (d *DB) Tables() ([]Table, error) {
+	rows, err := d.Query(`SELECT name FROM sqlite_schema WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tables := make([]Table, 0, len(names))
+	for _, n := range names {
+		t := Table{Name: n}
+		if t.Columns, err = d.Columns(n); err != nil {
+			return nil, err
+		}
+		if err := d.QueryRow(`SELECT count(*) FROM ` + quote(n)).Scan(&t.Rows); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// Columns returns the column names of table, or nil if it does not exist.
+func (d *DB) Columns(table string) ([]string, error) {
+	rows, err := d.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+// QueryMaps runs q and returns each row as a column→value map, the shape the
+// REST API and 'goup-util data inspect --json' print.
+func (d *DB) QueryMaps(q string, args ...any) ([]map[string]any, error) {
+	cols, rows, err := d.QueryRows(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		m := make(map[string]any, len(cols))
+		for j, c := range cols {
+			m[c] = row[j]
+		}
+		out[i] = m
+	}
+	return out, nil
+}
+
+// QueryRows runs q and returns the column names and the rows in order.
+func (d *DB) QueryRows(q string, args ...any) ([]string, [][]any, error) {
+	rows, err := d.Query(q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	var out [][]any
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+		out = append(out, vals)
+	}
+	return cols, out, rows.Err()
+}
+
+// quote makes name safe to use as an SQL identifier.
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
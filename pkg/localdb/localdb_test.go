@@ -0,0 +1,214 @@
+package localdb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var testMigrations = []string{
+	`CREATE TABLE notes (id INTEGER PRIMARY KEY, title TEXT NOT NULL, done INTEGER NOT NULL DEFAULT 0)`,
+	`ALTER TABLE notes ADD COLUMN tags TEXT`,
+}
+
+func openTest(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "data.db"), testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	db, err := Open(path, testMigrations[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := db.Version(); v != 1 {
+		t.Fatalf("version = %d, want 1", v)
+	}
+	db.Close()
+
+	// Reopening applies only the new migration.
+	db, err = Open(path, testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := db.Version(); v != 2 {
+		t.Fatalf("version = %d, want 2", v)
+	}
+	tables, err := db.Tables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || strings.Join(tables[0].Columns, ",") != "id,title,done,tags" {
+		t.Fatalf("tables = %+v", tables)
+	}
+	db.Close()
+
+	// An older app must not touch a newer schema.
+	if _, err := Open(path, testMigrations[:1]); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Fatalf("err = %v, want newer-version error", err)
+	}
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	_, err := Open(path, []string{testMigrations[0], `CREATE TABLE x (a); SELECT nope FROM nowhere`})
+	if err == nil || !strings.Contains(err.Error(), "migration 2") {
+		t.Fatalf("err = %v", err)
+	}
+	db, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if v, _ := db.Version(); v != 1 {
+		t.Errorf("version = %d, want 1", v)
+	}
+	tables, _ := db.Tables()
+	if len(tables) != 1 {
+		t.Errorf("tables = %+v, want only notes", tables)
+	}
+}
+
+func TestMigrationsFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/002_tags.sql":  {Data: []byte("two")},
+		"migrations/001_notes.sql": {Data: []byte("one")},
+		"migrations/README.md":     {Data: []byte("skip")},
+	}
+	m, err := Migrations(fsys, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(m, ",") != "one,two" {
+		t.Errorf("migrations = %q", m)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	db := openTest(t)
+	if _, err := db.Exec(`INSERT INTO notes (title) VALUES ('a')`); err != nil {
+		t.Fatal(err)
+	}
+	ro, err := OpenReadOnly(db.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ro.Close()
+	rows, err := ro.QueryMaps(`SELECT title FROM notes`)
+	if err != nil || len(rows) != 1 || rows[0]["title"] != "a" {
+		t.Fatalf("rows = %v, err = %v", rows, err)
+	}
+	if _, err := ro.Exec(`DELETE FROM notes`); err == nil {
+		t.Error("read-only database accepted a write")
+	}
+	if _, err := OpenReadOnly(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("opened a missing database")
+	}
+}
+
+func TestREST(t *testing.T) {
+	db := openTest(t)
+	srv := httptest.NewServer(http.StripPrefix("/api/data", db.REST("notes")))
+	defer srv.Close()
+
+	do := func(method, path, body string, want int) map[string]any {
+		t.Helper()
+		req, _ := http.NewRequest(method, srv.URL+"/api/data"+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("%s %s = %d, want %d", method, path, resp.StatusCode, want)
+		}
+		var v map[string]any
+		json.NewDecoder(resp.Body).Decode(&v)
+		return v
+	}
+
+	row := do("POST", "/notes", `{"title":"milk","tags":["shop"]}`, http.StatusCreated)
+	if row["title"] != "milk" || row["tags"] != `["shop"]` || row["id"] != 1.0 {
+		t.Fatalf("created %v", row)
+	}
+	do("POST", "/notes", `{"title":"bread","done":true}`, http.StatusCreated)
+	do("POST", "/notes", `{"nope":1}`, http.StatusBadRequest)
+	do("POST", "/notes", `{}`, http.StatusBadRequest) // title is NOT NULL
+	do("POST", "/secrets", `{"title":"x"}`, http.StatusNotFound)
+
+	if row := do("PUT", "/notes/1", `{"done":1}`, http.StatusOK); row["done"] != 1.0 || row["title"] != "milk" {
+		t.Fatalf("updated %v", row)
+	}
+	do("PUT", "/notes/9", `{"done":1}`, http.StatusNotFound)
+	if row := do("GET", "/notes/2", "", http.StatusOK); row["title"] != "bread" {
+		t.Fatalf("got %v", row)
+	}
+
+	list := func(query string) []map[string]any {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/api/data/notes" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var rows []map[string]any
+		json.NewDecoder(resp.Body).Decode(&rows)
+		return rows
+	}
+	if rows := list(""); len(rows) != 2 {
+		t.Fatalf("list = %v", rows)
+	}
+	if rows := list("?title=bread"); len(rows) != 1 || rows[0]["id"] != 2.0 {
+		t.Fatalf("filtered = %v", rows)
+	}
+	if rows := list("?limit=1&offset=1"); len(rows) != 1 || rows[0]["id"] != 2.0 {
+		t.Fatalf("paged = %v", rows)
+	}
+
+	// Only the app's own page on a loopback host gets through
+	for _, tt := range []struct{ host, origin string }{
+		{"", "https://evil.example"},
+		{"", "null"},
+		{"evil.example", ""},
+		{"rebound.example:" + srv.URL[strings.LastIndex(srv.URL, ":")+1:], ""},
+	} {
+		req, _ := http.NewRequest("DELETE", srv.URL+"/api/data/notes/1", nil)
+		if tt.host != "" {
+			req.Host = tt.host
+		}
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("host %q origin %q = %d, want 403", tt.host, tt.origin, resp.StatusCode)
+		}
+	}
+	req, _ := http.NewRequest("GET", srv.URL+"/api/data/notes/1", nil)
+	req.Header.Set("Origin", srv.URL)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("same-origin request = %v, %v", resp, err)
+	} else {
+		resp.Body.Close()
+	}
+
+	do("DELETE", "/notes/1", "", http.StatusNoContent)
+	do("DELETE", "/notes/1", "", http.StatusNotFound)
+	if rows := list(""); len(rows) != 1 {
+		t.Fatalf("after delete = %v", rows)
+	}
+}
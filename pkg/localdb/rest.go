@@ -0,0 +1,268 @@
+package localdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxLimit bounds how many rows one REST list request returns.
+const MaxLimit = 1000
+
+// REST returns a JSON API over tables, for mounting under a prefix with
+// http.StripPrefix:
+//
+//	GET    /{table}[?limit=&offset=&<column>=<value>]  list rows, oldest first
+//	POST   /{table}                                   insert a JSON object
+//	GET    /{table}/{id}                              one row
+//	PUT    /{table}/{id}                              update the given columns
+//	DELETE /{table}/{id}                              delete
+//
+// Each table needs an "id" primary key column; INTEGER PRIMARY KEY gives
+// SQLite's automatic ids, and tables synced with localsync use TEXT ids
+// with a random default. Only the listed tables are served, and JSON keys
+// must be column names, so a page cannot reach anything else.
+//
+// There is no authentication, so the server should only listen on
+// localhost, and the API refuses requests that did not come from the app's
+// own page there: the Host must be a loopback name, which stops DNS
+// rebinding, and an Origin, if sent, must be that same host, which stops
+// other sites open in a browser from writing through it.
+func (d *DB) REST(tables ...string) http.Handler {
+	rest := &rest{db: d, tables: tables}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{table}", rest.list)
+	mux.HandleFunc("POST /{table}", rest.create)
+	mux.HandleFunc("GET /{table}/{id}", rest.get)
+	mux.HandleFunc("PUT /{table}/{id}", rest.update)
+	mux.HandleFunc("PATCH /{table}/{id}", rest.update)
+	mux.HandleFunc("DELETE /{table}/{id}", rest.delete)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !sameLocalOrigin(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// sameLocalOrigin reports whether r was sent to a loopback host by a page
+// served from that host, or by something that is not a page at all.
+func sameLocalOrigin(r *http.Request) bool {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	return origin == "" || origin == "http://"+r.Host || origin == "https://"+r.Host
+}
+
+type rest struct {
+	db     *DB
+	tables []string
+}
+
+// table returns the requested table's columns, or writes an error.
+func (s *rest) table(w http.ResponseWriter, r *http.Request) (string, []string, bool) {
+	name := r.PathValue("table")
+	if !slices.Contains(s.tables, name) {
+		http.Error(w, "unknown table "+name, http.StatusNotFound)
+		return "", nil, false
+	}
+	cols, err := s.db.Columns(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", nil, false
+	}
+	if !slices.Contains(cols, "id") {
+		http.Error(w, "table "+name+" has no id column", http.StatusInternalServerError)
+		return "", nil, false
+	}
+	return name, cols, true
+}
+
+func (s *rest) list(w http.ResponseWriter, r *http.Request) {
+	table, cols, ok := s.table(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	limit, offset := 100, 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "bad limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, MaxLimit)
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "bad offset", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+
+	var where []string
+	var args []any
+	for _, c := range cols {
+		if v, ok := q[c]; ok {
+			where = append(where, quote(c)+" = ?")
+			args = append(args, v[0])
+		}
+	}
+	stmt := `SELECT * FROM ` + quote(table)
+	if len(where) > 0 {
+		stmt += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	stmt += ` ORDER BY id LIMIT ? OFFSET ?`
+	rows, err := s.db.QueryMaps(stmt, append(args, limit, offset)...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rows)
+}
+
+func (s *rest) get(w http.ResponseWriter, r *http.Request) {
+	table, _, ok := s.table(w, r)
+	if !ok {
+		return
+	}
+	rows, err := s.db.QueryMaps(`SELECT * FROM `+quote(table)+` WHERE id = ?`, r.PathValue("id"))
+	s.one(w, rows, err, http.StatusOK)
+}
+
+func (s *rest) create(w http.ResponseWriter, r *http.Request) {
+	table, cols, ok := s.table(w, r)
+	if !ok {
+		return
+	}
+	names, args, ok := body(w, r, cols)
+	if !ok {
+		return
+	}
+	stmt := `INSERT INTO ` + quote(table) + ` DEFAULT VALUES RETURNING *`
+	if len(names) > 0 {
+		stmt = fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) RETURNING *`, quote(table),
+			strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+	}
+	rows, err := s.db.QueryMaps(stmt, args...)
+	s.one(w, rows, err, http.StatusCreated)
+}
+
+func (s *rest) update(w http.ResponseWriter, r *http.Request) {
+	table, cols, ok := s.table(w, r)
+	if !ok {
+		return
+	}
+	names, args, ok := body(w, r, cols)
+	if !ok {
+		return
+	}
+	if len(names) == 0 {
+		http.Error(w, "nothing to update", http.StatusBadRequest)
+		return
+	}
+	for i := range names {
+		names[i] += " = ?"
+	}
+	rows, err := s.db.QueryMaps(`UPDATE `+quote(table)+` SET `+strings.Join(names, ", ")+` WHERE id = ? RETURNING *`,
+		append(args, r.PathValue("id"))...)
+	s.one(w, rows, err, http.StatusOK)
+}
+
+func (s *rest) delete(w http.ResponseWriter, r *http.Request) {
+	table, _, ok := s.table(w, r)
+	if !ok {
+		return
+	}
+	res, err := s.db.Exec(`DELETE FROM `+quote(table)+` WHERE id = ?`, r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// one writes the single row a statement returned.
+func (s *rest) one(w http.ResponseWriter, rows []map[string]any, err error, code int) {
+	switch {
+	case err != nil:
+		// Constraint failures are the client's fault; they read as such.
+		if strings.Contains(err.Error(), "constraint failed") {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case len(rows) == 0:
+		http.Error(w, "not found", http.StatusNotFound)
+	default:
+		writeJSON(w, code, rows[0])
+	}
+}
+
+// body decodes a JSON object whose keys are columns of the table. The id
+// is left to SQLite.
+func body(w http.ResponseWriter, r *http.Request, cols []string) ([]string, []any, bool) {
+	var obj map[string]any
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		http.Error(w, "body must be a JSON object", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		if k == "id" {
+			continue
+		}
+		if !slices.Contains(cols, k) {
+			http.Error(w, "unknown column "+k, http.StatusBadRequest)
+			return nil, nil, false
+		}
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	names := make([]string, len(keys))
+	args := make([]any, len(keys))
+	for i, k := range keys {
+		names[i] = quote(k)
+		switch v := obj[k].(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				args[i] = n
+			} else {
+				args[i], _ = v.Float64()
+			}
+		case bool, string, nil:
+			args[i] = v
+		default:
+			// Nested objects and arrays are stored as JSON text, which
+			// SQLite's json functions can query.
+			b, _ := json.Marshal(v)
+			args[i] = string(b)
+		}
+	}
+	return names, args, true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
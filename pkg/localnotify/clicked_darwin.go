@@ -0,0 +1,16 @@
+//go:build cgo
+
+package localnotify
+
+import "C"
+
+//export goupNotificationClicked
+func goupNotificationClicked(title, body, url *C.char) {
+	n := Notification{Title: C.GoString(title), Body: C.GoString(body), URL: C.GoString(url)}
+	active.Lock()
+	nt := active.nt
+	active.Unlock()
+	if nt != nil {
+		go nt.clicked(n)
+	}
+}
@@ -0,0 +1,75 @@
+// Package localnotify shows notifications from an app on the device it
+// runs on, and tells the app when the user clicks one so it can open the
+// page the notification is about:
+//
+//	macOS, iOS   UserNotifications (needs cgo and an app bundle; plain
+//	             binaries on macOS fall back to osascript, without clicks)
+//	Windows      toast notifications through PowerShell, as the packaged
+//	             app under MSIX or as Windows PowerShell otherwise
+//	Linux, BSD   the desktop's notification server through notify-send
+//
+// Android has no support yet; Show returns ErrUnsupported there.
+//
+// macOS and iOS ask the user for permission the first time an app shows
+// a notification. Android 13+ needs the POST_NOTIFICATIONS permission,
+// which 'goup-util audit capabilities' reports for apps importing this
+// package or declaring "notifications" in app.json.
+package localnotify
+
+import "errors"
+
+// ErrUnsupported is returned by Show where there is no notification
+// service.
+var ErrUnsupported = errors.New("local notifications are not supported on this platform")
+
+// ErrDenied is returned by Show when the user has turned notifications
+// off for the app.
+var ErrDenied = errors.New("notifications are turned off for this app")
+
+// Notification is one local notification.
+type Notification struct {
+	Title string
+	Body  string
+	// URL is handed back to OnClick when the user clicks the
+	// notification, usually a page or deep link within the app.
+	URL string
+}
+
+// Notifier shows notifications for one app.
+type Notifier struct {
+	// AppName names the sender on Linux.
+	AppName string
+	// Icon is an icon file or theme icon name shown on Linux. Elsewhere
+	// the app's own icon is used.
+	Icon string
+	// AppID is the Windows AppUserModelID to post toasts as. Packaged
+	// apps find their own; set it only for an unpackaged app that
+	// registered a Start menu shortcut with its own ID.
+	AppID string
+	// OnClick is called, on another goroutine, when the user clicks a
+	// notification. Clicks are seen while the app runs; on Windows and
+	// Linux, only until the notification leaves the screen.
+	OnClick func(Notification)
+}
+
+// New returns a Notifier for the app called appName.
+func New(appName string) *Notifier {
+	return &Notifier{AppName: appName}
+}
+
+// Show posts n and returns once it is on screen, or with the reason it
+// could not be shown. On Apple platforms the first call asks the user for
+// permission and waits for the answer, so call it off the UI thread.
+func (nt *Notifier) Show(n Notification) error {
+	if n.Title == "" && n.Body == "" {
+		return errors.New("localnotify: notification has no title or body")
+	}
+	return nt.show(n)
+}
+
+// clicked tells the app n was clicked.
+func (nt *Notifier) clicked(n Notification) {
+	if nt.OnClick != nil {
+		nt.OnClick(n)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !cgo
+
+package localnotify
+
+func (nt *Notifier) show(n Notification) error {
+	return osascript(n)
+}
@@ -0,0 +1,92 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package localnotify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// notifySend starts notify-send; tests replace it.
+var notifySend = func(args ...string) (*exec.Cmd, error) {
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return nil, errors.New("notify-send not found (install libnotify-bin)")
+	}
+	return exec.Command("notify-send", args...), nil
+}
+
+// posted is how long notify-send gets to fail before the notification
+// counts as shown. With --wait it only exits once the notification
+// closes.
+const posted = time.Second
+
+// notifySendArgs builds the notify-send command line. With wait it
+// offers the default action and waits for the notification to close,
+// printing "default" if it was clicked (libnotify 0.7.10 and later).
+func (nt *Notifier) notifySendArgs(n Notification, wait bool) []string {
+	var args []string
+	if nt.AppName != "" {
+		args = append(args, "--app-name="+nt.AppName)
+	}
+	if nt.Icon != "" {
+		args = append(args, "--icon="+nt.Icon)
+	}
+	if wait {
+		args = append(args, "--action=default=Open", "--wait")
+	}
+	title := n.Title
+	if title == "" {
+		title = nt.AppName
+	}
+	return append(args, "--", title, n.Body)
+}
+
+func (nt *Notifier) show(n Notification) error {
+	if nt.OnClick == nil {
+		return nt.run(n, false)
+	}
+	err := nt.run(n, true)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		// Older notify-send has no --action or --wait
+		return nt.run(n, false)
+	}
+	return err
+}
+
+// run starts notify-send and waits until it fails, finishes or has been
+// running long enough to have shown the notification. A click is
+// reported after run returns.
+func (nt *Notifier) run(n Notification, wait bool) error {
+	cmd, err := notifySend(nt.notifySendArgs(n, wait)...)
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		if err == nil && wait && strings.TrimSpace(stdout.String()) == "default" {
+			nt.clicked(n)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil && stderr.Len() > 0 {
+			return fmt.Errorf("notify-send: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return err
+	case <-time.After(posted):
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package localnotify
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNotifySendArgs(t *testing.T) {
+	nt := &Notifier{AppName: "Orders", Icon: "orders"}
+	got := nt.notifySendArgs(Notification{Body: "-3 new"}, true)
+	want := []string{"--app-name=Orders", "--icon=orders", "--action=default=Open", "--wait", "--", "Orders", "-3 new"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
+
+func TestShowClick(t *testing.T) {
+	saved := notifySend
+	defer func() { notifySend = saved }()
+
+	var calls [][]string
+	notifySend = func(args ...string) (*exec.Cmd, error) {
+		calls = append(calls, args)
+		if len(calls) == 1 {
+			// An old notify-send rejecting --action
+			return exec.Command("sh", "-c", "echo 'Unknown option --action' >&2; exit 1"), nil
+		}
+		return exec.Command("true"), nil
+	}
+	nt := New("Orders")
+	nt.OnClick = func(Notification) {}
+	if err := nt.Show(Notification{Title: "Order shipped"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || len(calls[1]) != 4 {
+		t.Fatalf("calls = %q, want a retry without --action", calls)
+	}
+
+	clicked := make(chan Notification, 1)
+	nt.OnClick = func(n Notification) { clicked <- n }
+	notifySend = func(args ...string) (*exec.Cmd, error) {
+		return exec.Command("echo", "default"), nil
+	}
+	if err := nt.Show(Notification{Title: "Order shipped", URL: "/orders/42"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case n := <-clicked:
+		if n.URL != "/orders/42" {
+			t.Errorf("clicked %+v", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("click not reported")
+	}
+}
@@ -0,0 +1,109 @@
+package localnotify
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// powerShellAppID is the AppUserModelID of Windows PowerShell, which
+// every Windows install registers. Unpackaged apps post toasts as it.
+const powerShellAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// toastScript shows a toast with Windows PowerShell, which can call
+// WinRT without cgo. It prints "shown" once the toast is posted, or the
+// setting that blocks it ("DisabledForApplication", ...), then
+// "activated" if the user clicks it or "dismissed" when it leaves the
+// screen. The text comes from the environment so it needs no quoting.
+const toastScript = `
+$ErrorActionPreference = 'Stop'
+[void][Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime]
+[void][Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime]
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml('<toast><visual><binding template="ToastGeneric"><text/><text/></binding></visual></toast>')
+$texts = $xml.GetElementsByTagName('text')
+[void]$texts.Item(0).AppendChild($xml.CreateTextNode($env:GOUP_TOAST_TITLE))
+[void]$texts.Item(1).AppendChild($xml.CreateTextNode($env:GOUP_TOAST_BODY))
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[void](Register-ObjectEvent -InputObject $toast -EventName Activated -SourceIdentifier activated)
+[void](Register-ObjectEvent -InputObject $toast -EventName Dismissed -SourceIdentifier dismissed)
+[void](Register-ObjectEvent -InputObject $toast -EventName Failed -SourceIdentifier failed)
+$notifier = [Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:GOUP_TOAST_APPID)
+if ($notifier.Setting -ne 'Enabled') { Write-Output $notifier.Setting; exit }
+$notifier.Show($toast)
+Write-Output shown
+if ($env:GOUP_TOAST_WAIT -eq '1') {
+  $e = Wait-Event -Timeout 120
+  if ($e) { Write-Output $e.SourceIdentifier }
+}
+`
+
+// appID returns the AppUserModelID to post as: AppID if set, the
+// package's when running from an MSIX, or Windows PowerShell's.
+func (nt *Notifier) appID() string {
+	if nt.AppID != "" {
+		return nt.AppID
+	}
+	proc := windows.NewLazySystemDLL("kernel32.dll").NewProc("GetCurrentApplicationUserModelId")
+	if proc.Find() != nil {
+		return powerShellAppID
+	}
+	buf := make([]uint16, 130) // APPLICATION_USER_MODEL_ID_MAX_LENGTH
+	n := uint32(len(buf))
+	if r, _, _ := proc.Call(uintptr(unsafe.Pointer(&n)), uintptr(unsafe.Pointer(&buf[0]))); r != 0 {
+		return powerShellAppID // APPMODEL_ERROR_NO_APPLICATION: not packaged
+	}
+	return windows.UTF16ToString(buf)
+}
+
+func (nt *Notifier) show(n Notification) error {
+	wait := "0"
+	if nt.OnClick != nil {
+		wait = "1"
+	}
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Env = append(os.Environ(),
+		"GOUP_TOAST_TITLE="+n.Title,
+		"GOUP_TOAST_BODY="+n.Body,
+		"GOUP_TOAST_APPID="+nt.appID(),
+		"GOUP_TOAST_WAIT="+wait)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_NO_WINDOW} // No console window for GUI apps
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start powershell: %w", err)
+	}
+	lines := bufio.NewScanner(stdout)
+	if !lines.Scan() || lines.Text() != "shown" {
+		setting := lines.Text()
+		cmd.Wait()
+		if strings.HasPrefix(setting, "Disabled") {
+			return fmt.Errorf("%w (%s)", ErrDenied, setting)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.New("toast failed: " + msg)
+		}
+		return errors.New("toast failed")
+	}
+	go func() {
+		for lines.Scan() {
+			if lines.Text() == "activated" {
+				nt.clicked(n)
+			}
+		}
+		cmd.Wait()
+	}()
+	return nil
+}
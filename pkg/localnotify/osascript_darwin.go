@@ -0,0 +1,26 @@
+package localnotify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// osascript shows n with AppleScript, for binaries outside an app
+// bundle, which UserNotifications refuses. macOS shows these as coming
+// from Script Editor, and clicks cannot be seen.
+func osascript(n Notification) error {
+	if runtime.GOOS != "darwin" {
+		return ErrUnsupported
+	}
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.Body), appleScriptString(n.Title))
+	if out, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("osascript: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
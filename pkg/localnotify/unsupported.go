@@ -0,0 +1,7 @@
+//go:build android || !(darwin || windows || linux || freebsd || openbsd)
+
+package localnotify
+
+func (nt *Notifier) show(n Notification) error {
+	return ErrUnsupported
+}
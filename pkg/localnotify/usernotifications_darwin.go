@@ -0,0 +1,137 @@
+//go:build cgo
+
+package localnotify
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework UserNotifications -framework Foundation
+
+#import <Foundation/Foundation.h>
+#import <UserNotifications/UserNotifications.h>
+#include <stdlib.h>
+#include <string.h>
+
+extern void goupNotificationClicked(char *title, char *body, char *url);
+
+@interface GoupNotificationDelegate : NSObject <UNUserNotificationCenterDelegate>
+@end
+
+@implementation GoupNotificationDelegate
+// Show notifications even while the app is in front.
+- (void)userNotificationCenter:(UNUserNotificationCenter *)center
+       willPresentNotification:(UNNotification *)notification
+         withCompletionHandler:(void (^)(UNNotificationPresentationOptions))completionHandler {
+	if (@available(macOS 11.0, iOS 14.0, *)) {
+		completionHandler(UNNotificationPresentationOptionBanner | UNNotificationPresentationOptionList | UNNotificationPresentationOptionSound);
+	} else {
+		completionHandler(UNNotificationPresentationOptionAlert | UNNotificationPresentationOptionSound);
+	}
+}
+
+- (void)userNotificationCenter:(UNUserNotificationCenter *)center
+didReceiveNotificationResponse:(UNNotificationResponse *)response
+         withCompletionHandler:(void (^)(void))completionHandler {
+	if ([response.actionIdentifier isEqualToString:UNNotificationDefaultActionIdentifier]) {
+		UNNotificationContent *content = response.notification.request.content;
+		NSString *url = content.userInfo[@"url"];
+		goupNotificationClicked((char *)[content.title UTF8String], (char *)[content.body UTF8String],
+			(char *)(url ? [url UTF8String] : ""));
+	}
+	completionHandler();
+}
+@end
+
+// bundled reports whether the process runs from an app bundle, without
+// which UserNotifications aborts the process.
+static int bundled(void) {
+	return [[NSBundle mainBundle] bundleIdentifier] != nil;
+}
+
+// postNotification asks for permission the first time, then posts the
+// notification. It returns 0 once posted, 1 if the user turned
+// notifications off, and -1 with *msg set on other errors.
+static int postNotification(const char *title, const char *body, const char *url, char **msg) {
+	@autoreleasepool {
+		static GoupNotificationDelegate *delegate = nil;
+		UNUserNotificationCenter *center = [UNUserNotificationCenter currentNotificationCenter];
+		if (delegate == nil) {
+			delegate = [[GoupNotificationDelegate alloc] init];
+			center.delegate = delegate;
+		}
+
+		dispatch_semaphore_t done = dispatch_semaphore_create(0);
+		__block BOOL granted = NO;
+		__block char *errMsg = NULL;
+		[center requestAuthorizationWithOptions:(UNAuthorizationOptionAlert | UNAuthorizationOptionSound)
+			completionHandler:^(BOOL ok, NSError *err) {
+				granted = ok;
+				if (err != nil) {
+					errMsg = strdup([[err localizedDescription] UTF8String]);
+				}
+				dispatch_semaphore_signal(done);
+			}];
+		dispatch_semaphore_wait(done, DISPATCH_TIME_FOREVER);
+		if (!granted) {
+			dispatch_release(done);
+			*msg = errMsg;
+			return errMsg ? -1 : 1;
+		}
+
+		UNMutableNotificationContent *content = [[UNMutableNotificationContent alloc] init];
+		content.title = [NSString stringWithUTF8String:title];
+		content.body = [NSString stringWithUTF8String:body];
+		content.sound = [UNNotificationSound defaultSound];
+		content.userInfo = @{@"url": [NSString stringWithUTF8String:url]};
+		UNNotificationRequest *request = [UNNotificationRequest requestWithIdentifier:[[NSUUID UUID] UUIDString]
+			content:content trigger:nil];
+		[content release];
+		[center addNotificationRequest:request withCompletionHandler:^(NSError *err) {
+			if (err != nil) {
+				errMsg = strdup([[err localizedDescription] UTF8String]);
+			}
+			dispatch_semaphore_signal(done);
+		}];
+		dispatch_semaphore_wait(done, DISPATCH_TIME_FOREVER);
+		dispatch_release(done);
+		*msg = errMsg;
+		return errMsg ? -1 : 0;
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+// active receives clicks. UserNotifications has one delegate per
+// process, so the Notifier that showed the latest notification gets them.
+var active struct {
+	sync.Mutex
+	nt *Notifier
+}
+
+func (nt *Notifier) show(n Notification) error {
+	if C.bundled() == 0 {
+		return osascript(n)
+	}
+	active.Lock()
+	active.nt = nt
+	active.Unlock()
+
+	title, body, url := C.CString(n.Title), C.CString(n.Body), C.CString(n.URL)
+	defer C.free(unsafe.Pointer(title))
+	defer C.free(unsafe.Pointer(body))
+	defer C.free(unsafe.Pointer(url))
+	var msg *C.char
+	switch C.postNotification(title, body, url, &msg) {
+	case 0:
+		return nil
+	case 1:
+		return ErrDenied
+	}
+	defer C.free(unsafe.Pointer(msg))
+	return errors.New(C.GoString(msg))
+}
@@ -0,0 +1,114 @@
+// Package localserver opens the loopback listener of a hybrid app's
+// embedded server and shuts the server down when the app exits.
+//
+// The page's origin includes the port, and localStorage, IndexedDB and
+// cookies belong to the origin, so a new random port on every launch
+// empties them. Listen keeps the port stable: a fixed one from app.json
+// ("server": {"port": 8123}), or else the one the last launch used,
+// remembered in the app's config directory. A port that is taken is
+// retried for a moment, since the app's previous instance may still be
+// closing, and then replaced rather than failing the app:
+//
+//	ln, err := localserver.Listen(cfg.Server.Port, dir)
+//	srv := &http.Server{Handler: mux}
+//	go srv.Serve(ln)
+//	...
+//	localserver.Shutdown(srv) // on app.DestroyEvent
+package localserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PortFile, in the directory given to Listen, holds the last port used.
+const PortFile = "server.port"
+
+// Retry bounds how long Listen waits for a taken port.
+var (
+	Retries    = 10
+	RetryDelay = 200 * time.Millisecond
+)
+
+// ShutdownTimeout bounds how long Shutdown waits for open requests.
+const ShutdownTimeout = 5 * time.Second
+
+// Listen listens on 127.0.0.1, on port if it is not 0, else on the port
+// remembered in dir (if dir is not ""), else on a free one. If the port
+// stays taken, Listen logs the conflict and picks a free port instead.
+// The port used is remembered in dir.
+func Listen(port int, dir string) (net.Listener, error) {
+	if port == 0 && dir != "" {
+		port = remembered(dir)
+	}
+	var ln net.Listener
+	if port != 0 {
+		var err error
+		for i := 0; ; i++ {
+			if ln, err = listen(port); err == nil || i == Retries {
+				break
+			}
+			time.Sleep(RetryDelay)
+		}
+		if err != nil {
+			log.Printf("localserver: %v; using another port, so pages lose what they stored for this origin", err)
+		}
+	}
+	if ln == nil {
+		var err error
+		if ln, err = listen(0); err != nil {
+			return nil, err
+		}
+	}
+	if got := ln.Addr().(*net.TCPAddr).Port; dir != "" && remembered(dir) != got {
+		remember(dir, got)
+	}
+	return ln, nil
+}
+
+func listen(port int) (net.Listener, error) {
+	return net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+}
+
+// remembered returns the port in dir's PortFile, or 0.
+func remembered(dir string) int {
+	data, err := os.ReadFile(filepath.Join(dir, PortFile))
+	if err != nil {
+		return 0
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || port <= 0 || port > 65535 {
+		return 0
+	}
+	return port
+}
+
+func remember(dir string, port int) {
+	err := os.MkdirAll(dir, 0700)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, PortFile), []byte(strconv.Itoa(port)+"\n"), 0644)
+	}
+	if err != nil {
+		log.Printf("localserver: failed to remember port %d: %v", port, err)
+	}
+}
+
+// Shutdown stops srv, letting open requests finish for up to
+// ShutdownTimeout.
+func Shutdown(srv *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+		return fmt.Errorf("server shutdown: %w", err)
+	}
+	return nil
+}
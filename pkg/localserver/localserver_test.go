@@ -0,0 +1,97 @@
+package localserver
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func port(ln net.Listener) int {
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func retry(t *testing.T, n int, delay time.Duration) {
+	retries, retryDelay := Retries, RetryDelay
+	Retries, RetryDelay = n, delay
+	t.Cleanup(func() { Retries, RetryDelay = retries, retryDelay })
+}
+
+func TestListenRemembersPort(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	ln, err := Listen(0, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := port(ln)
+	if remembered(dir) != first {
+		t.Errorf("remembered %d, listening on %d", remembered(dir), first)
+	}
+	ln.Close()
+
+	// The next launch gets the same origin.
+	ln, err = Listen(0, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if port(ln) != first {
+		t.Errorf("second launch on %d, want %d", port(ln), first)
+	}
+}
+
+func TestListenConflict(t *testing.T) {
+	retry(t, 2, time.Millisecond)
+	taken, err := listen(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	dir := t.TempDir()
+	ln, err := Listen(port(taken), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if port(ln) == port(taken) || remembered(dir) != port(ln) {
+		t.Errorf("listening on %d (taken %d, remembered %d)", port(ln), port(taken), remembered(dir))
+	}
+}
+
+func TestListenWaitsForPreviousInstance(t *testing.T) {
+	retry(t, 20, 10*time.Millisecond)
+	taken, err := listen(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		taken.Close()
+	}()
+	ln, err := Listen(port(taken), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if port(ln) != port(taken) {
+		t.Errorf("listening on %d, want %d once it was free", port(ln), port(taken))
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	ln, err := Listen(0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+	done := make(chan error)
+	go func() { done <- srv.Serve(ln) }()
+	if err := Shutdown(srv); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != http.ErrServerClosed {
+		t.Errorf("Serve = %v", err)
+	}
+}
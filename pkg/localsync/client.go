@@ -0,0 +1,450 @@
+package localsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/localdb"
+)
+
+// batch bounds how many changed rows one request pushes.
+const batch = 500
+
+// Status describes the last sync attempt, for the app's UI.
+type Status struct {
+	LastSync time.Time `json:"lastSync,omitzero"` // Last successful sync
+	Pending  int       `json:"pending"`           // Local changes not yet pushed
+	Error    string    `json:"error,omitempty"`   // Why the last attempt failed
+	Syncing  bool      `json:"syncing,omitempty"`
+}
+
+// Client syncs tables of a local database with a Server.
+type Client struct {
+	DB     *localdb.DB
+	URL    string // The server's sync endpoint, e.g. https://sync.example.com/sync
+	Token  string // Sent as a bearer token, if set
+	Tables []string
+
+	// HTTPClient defaults to one with a 30 second timeout.
+	HTTPClient *http.Client
+
+	// OnChange is called with the tables that changed after a sync pulled
+	// rows from other devices, e.g. to tell the web UI to reload.
+	OnChange func(tables []string)
+
+	device string
+	wake   chan struct{}
+
+	mu     sync.Mutex // Serializes syncs
+	smu    sync.Mutex
+	status Status
+}
+
+// New prepares tables of db for syncing with the server at url: it adds
+// the bookkeeping tables and triggers, gives the database a device ID on
+// first use, and marks rows written before syncing was enabled as changed.
+func New(db *localdb.DB, url string, tables ...string) (*Client, error) {
+	c := &Client{DB: db, URL: url, Tables: tables, wake: make(chan struct{}, 1)}
+	if err := c.setup(); err != nil {
+		return nil, fmt.Errorf("localsync: %w", err)
+	}
+	return c, nil
+}
+
+func (c *Client) setup() error {
+	_, err := c.DB.Exec(`
+CREATE TABLE IF NOT EXISTS _sync_state (key TEXT PRIMARY KEY, value TEXT NOT NULL);
+CREATE TABLE IF NOT EXISTS _sync_rows (
+	tbl     TEXT NOT NULL,
+	id      TEXT NOT NULL,
+	updated INTEGER NOT NULL,
+	origin  TEXT NOT NULL,
+	deleted INTEGER NOT NULL DEFAULT 0,
+	dirty   INTEGER NOT NULL DEFAULT 1,
+	PRIMARY KEY (tbl, id)
+);
+CREATE INDEX IF NOT EXISTS _sync_rows_dirty ON _sync_rows (dirty);
+INSERT OR IGNORE INTO _sync_state VALUES ('applying', '0'), ('cursor', '0');`)
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, 8)
+	rand.Read(b)
+	if _, err := c.DB.Exec(`INSERT OR IGNORE INTO _sync_state VALUES ('device', ?)`, hex.EncodeToString(b)); err != nil {
+		return err
+	}
+	if err := c.DB.QueryRow(`SELECT value FROM _sync_state WHERE key = 'device'`).Scan(&c.device); err != nil {
+		return err
+	}
+
+	for _, t := range c.Tables {
+		_, types, err := c.DB.QueryRows(`SELECT type FROM pragma_table_info(?) WHERE name = 'id'`, t)
+		if err != nil {
+			return err
+		}
+		if len(types) == 0 {
+			return fmt.Errorf("table %s has no id column", t)
+		}
+		if typ, _ := types[0][0].(string); !strings.EqualFold(typ, "TEXT") {
+			return fmt.Errorf("table %s: synced tables need a TEXT id, unique across devices (see the package docs)", t)
+		}
+		if _, err := c.DB.Exec(triggers(t)); err != nil {
+			return fmt.Errorf("table %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// triggers records every local write to table in _sync_rows, except the
+// ones the client itself makes while applying pulled changes.
+func triggers(table string) string {
+	var b strings.Builder
+	for _, op := range []struct{ name, row, deleted string }{
+		{"insert", "NEW", "0"},
+		{"update", "NEW", "0"},
+		{"delete", "OLD", "1"},
+	} {
+		fmt.Fprintf(&b, `
+CREATE TRIGGER IF NOT EXISTS %s AFTER %s ON %s
+WHEN (SELECT value FROM _sync_state WHERE key = 'applying') = '0'
+BEGIN
+	INSERT INTO _sync_rows (tbl, id, updated, origin, deleted, dirty)
+	VALUES (%s, %s.id, %s, (SELECT value FROM _sync_state WHERE key = 'device'), %s, 1)
+	ON CONFLICT (tbl, id) DO UPDATE SET
+		updated = max(excluded.updated, updated + 1), origin = excluded.origin, deleted = excluded.deleted, dirty = 1;
+END;`, quote("_sync_"+table+"_"+op.name), strings.ToUpper(op.name), quote(table),
+			literal(table), op.row, clock, op.deleted)
+	}
+	// Rows from before syncing was enabled.
+	fmt.Fprintf(&b, `
+INSERT OR IGNORE INTO _sync_rows (tbl, id, updated, origin)
+SELECT %s, id, %s, (SELECT value FROM _sync_state WHERE key = 'device') FROM %s;`,
+		literal(table), clock, quote(table))
+	return b.String()
+}
+
+// Device returns this database's device ID.
+func (c *Client) Device() string {
+	return c.device
+}
+
+// Status returns the state of the last sync.
+func (c *Client) Status() Status {
+	c.smu.Lock()
+	s := c.status
+	c.smu.Unlock()
+	c.DB.QueryRow(`SELECT count(*) FROM _sync_rows WHERE dirty = 1`).Scan(&s.Pending)
+	return s
+}
+
+func (c *Client) setStatus(f func(*Status)) {
+	c.smu.Lock()
+	f(&c.status)
+	c.smu.Unlock()
+}
+
+// Sync pushes local changes and pulls everyone else's until both sides
+// are up to date.
+func (c *Client) Sync(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setStatus(func(s *Status) { s.Syncing = true })
+
+	changed := map[string]bool{}
+	err := c.sync(ctx, changed)
+	c.setStatus(func(s *Status) {
+		s.Syncing = false
+		if err != nil {
+			s.Error = err.Error()
+		} else {
+			s.Error = ""
+			s.LastSync = time.Now()
+		}
+	})
+
+	if len(changed) > 0 && c.OnChange != nil {
+		tables := make([]string, 0, len(changed))
+		for t := range changed {
+			tables = append(tables, t)
+		}
+		slices.Sort(tables)
+		c.OnChange(tables)
+	}
+	return err
+}
+
+func (c *Client) sync(ctx context.Context, changed map[string]bool) error {
+	for {
+		var cursor string
+		if err := c.DB.QueryRow(`SELECT value FROM _sync_state WHERE key = 'cursor'`).Scan(&cursor); err != nil {
+			return err
+		}
+		since, _ := strconv.ParseInt(cursor, 10, 64)
+		push, err := c.pending()
+		if err != nil {
+			return err
+		}
+		resp, err := c.post(ctx, &request{Device: c.device, Since: since, Changes: push})
+		if err != nil {
+			return err
+		}
+		if err := c.apply(push, resp, changed); err != nil {
+			return err
+		}
+		if !resp.More && len(push) < batch {
+			return nil
+		}
+	}
+}
+
+// pending returns the local changes the server has not seen.
+func (c *Client) pending() ([]Change, error) {
+	if len(c.Tables) == 0 {
+		return nil, nil
+	}
+	args := make([]any, 0, len(c.Tables)+1)
+	for _, t := range c.Tables {
+		args = append(args, t)
+	}
+	rows, err := c.DB.Query(`SELECT tbl, id, updated, origin, deleted FROM _sync_rows WHERE dirty = 1 AND tbl IN (`+
+		strings.TrimSuffix(strings.Repeat("?, ", len(c.Tables)), ", ")+`) ORDER BY updated LIMIT ?`, append(args, batch)...)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for rows.Next() {
+		var ch Change
+		if err := rows.Scan(&ch.Table, &ch.ID, &ch.Updated, &ch.Origin, &ch.Deleted); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		changes = append(changes, ch)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range changes {
+		ch := &changes[i]
+		if ch.Deleted {
+			continue
+		}
+		row, err := c.DB.QueryMaps(`SELECT * FROM `+quote(ch.Table)+` WHERE id = ?`, ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(row) == 0 {
+			// Gone without the delete trigger seeing it; push a delete.
+			ch.Deleted = true
+			continue
+		}
+		if ch.Data, err = json.Marshal(row[0]); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+func (c *Client) post(ctx context.Context, r *request) (*response, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var msg bytes.Buffer
+		msg.ReadFrom(resp.Body)
+		return nil, fmt.Errorf("sync server: %s: %s", resp.Status, strings.TrimSpace(msg.String()))
+	}
+	var out response
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("sync server: bad response: %w", err)
+	}
+	return &out, nil
+}
+
+// apply stores pulled changes that win over the local rows, marks pushed
+// rows as seen, and moves the cursor, all in one transaction.
+func (c *Client) apply(pushed []Change, resp *response, changed map[string]bool) error {
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE _sync_state SET value = '1' WHERE key = 'applying'`); err != nil {
+		return err
+	}
+
+	// A row edited again while the request was out keeps its dirty mark.
+	for _, ch := range pushed {
+		if _, err := tx.Exec(`UPDATE _sync_rows SET dirty = 0 WHERE tbl = ? AND id = ? AND updated = ?`, ch.Table, ch.ID, ch.Updated); err != nil {
+			return err
+		}
+	}
+
+	for _, ch := range resp.Changes {
+		if !slices.Contains(c.Tables, ch.Table) {
+			continue
+		}
+		var updated int64
+		var origin string
+		err := tx.QueryRow(`SELECT updated, origin FROM _sync_rows WHERE tbl = ? AND id = ?`, ch.Table, ch.ID).Scan(&updated, &origin)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil && !ch.newer(updated, origin) {
+			continue
+		}
+		if err := c.store(tx, &ch); err != nil {
+			return fmt.Errorf("%s %s: %w", ch.Table, ch.ID, err)
+		}
+		_, err = tx.Exec(`INSERT INTO _sync_rows (tbl, id, updated, origin, deleted, dirty) VALUES (?, ?, ?, ?, ?, 0)
+ON CONFLICT (tbl, id) DO UPDATE SET updated = excluded.updated, origin = excluded.origin, deleted = excluded.deleted, dirty = 0`,
+			ch.Table, ch.ID, ch.Updated, ch.Origin, ch.Deleted)
+		if err != nil {
+			return err
+		}
+		changed[ch.Table] = true
+	}
+
+	if _, err := tx.Exec(`UPDATE _sync_state SET value = ? WHERE key = 'cursor'`, strconv.FormatInt(resp.Cursor, 10)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE _sync_state SET value = '0' WHERE key = 'applying'`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// store writes a pulled row. Columns this version of the app does not
+// have are dropped, so devices on older schemas keep syncing.
+func (c *Client) store(tx *sql.Tx, ch *Change) error {
+	if ch.Deleted {
+		_, err := tx.Exec(`DELETE FROM `+quote(ch.Table)+` WHERE id = ?`, ch.ID)
+		return err
+	}
+	var row map[string]any
+	dec := json.NewDecoder(bytes.NewReader(ch.Data))
+	dec.UseNumber()
+	if err := dec.Decode(&row); err != nil {
+		return err
+	}
+	row["id"] = ch.ID
+	// Through tx: the database has a single connection, which tx holds.
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, ch.Table)
+	if err != nil {
+		return err
+	}
+	var cols []string
+	for rows.Next() {
+		var col string
+		rows.Scan(&col)
+		cols = append(cols, col)
+	}
+	rows.Close()
+
+	var names, sets []string
+	var args []any
+	for _, col := range cols {
+		v, ok := row[col]
+		if !ok {
+			continue
+		}
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				v = i
+			} else {
+				v, _ = n.Float64()
+			}
+		}
+		names = append(names, quote(col))
+		args = append(args, v)
+		if col != "id" {
+			sets = append(sets, quote(col)+" = excluded."+quote(col))
+		}
+	}
+	stmt := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (id) DO `, quote(ch.Table),
+		strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+	if len(sets) == 0 {
+		stmt += `NOTHING`
+	} else {
+		stmt += `UPDATE SET ` + strings.Join(sets, ", ")
+	}
+	_, err = tx.Exec(stmt, args...)
+	return err
+}
+
+// Trigger asks a running Run loop to sync now, e.g. after a local write
+// or when the app learns it is back online.
+func (c *Client) Trigger() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run syncs now, then every interval and on Trigger, until ctx is done.
+// Failed attempts (no network, server down) are kept in Status and
+// retried on the next round; local writes are never blocked by them.
+func (c *Client) Run(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		c.Sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-c.wake:
+		}
+	}
+}
+
+// Handler serves the sync status for the web UI:
+//
+//	GET  status as JSON
+//	POST sync now, then the status
+func (c *Client) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+		case "POST":
+			c.Sync(r.Context())
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c.Status())
+	})
+}
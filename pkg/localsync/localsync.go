@@ -0,0 +1,71 @@
+// Package localsync keeps tables of a localdb database in step across
+// devices through a server, so hybrid apps work offline and catch up when
+// the network returns. Every write is local first; Client pushes the rows
+// that changed and pulls what other devices wrote in one HTTP round trip.
+//
+// Conflicts resolve per row, last writer wins: the version with the later
+// timestamp is kept, ties going to the higher device ID, so every device
+// and the server settle on the same row. A delete is a version too and
+// wins over older edits. A local edit always stamps a row later than the
+// version it replaces, so a device whose clock runs behind cannot lose
+// its own edits to ones it already pulled.
+//
+// Synced tables need a TEXT id that is unique across devices. A random
+// default keeps inserts (and localdb's REST API) unchanged:
+//
+//	CREATE TABLE notes (
+//		id   TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+//		text TEXT NOT NULL
+//	);
+//
+// Writes are tracked with SQLite triggers, so rows changed through the
+// REST API, raw SQL or migrations all sync. Server is the reference
+// server ('goup-util data sync-server').
+package localsync
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Change is the latest version of one row.
+type Change struct {
+	Table   string          `json:"table"`
+	ID      string          `json:"id"`
+	Data    json.RawMessage `json:"data,omitempty"` // The row as a JSON object; empty when deleted
+	Deleted bool            `json:"deleted,omitempty"`
+	Updated int64           `json:"updated"` // Writer's clock, milliseconds since 1970
+	Origin  string          `json:"origin"`  // Device that wrote it
+}
+
+// newer reports whether c wins over a version written at updated by origin.
+func (c *Change) newer(updated int64, origin string) bool {
+	return c.Updated > updated || c.Updated == updated && c.Origin > origin
+}
+
+// request is what a client posts to the server.
+type request struct {
+	Device  string   `json:"device"`
+	Since   int64    `json:"since"` // Server cursor from the last response
+	Changes []Change `json:"changes"`
+}
+
+// response carries the changes after the client's cursor.
+type response struct {
+	Changes []Change `json:"changes"`
+	Cursor  int64    `json:"cursor"`
+	More    bool     `json:"more,omitempty"` // Ask again for the rest
+}
+
+// clock is SQLite's current time in milliseconds since 1970.
+const clock = `CAST((julianday('now') - 2440587.5) * 86400000 AS INTEGER)`
+
+// quote makes name safe to use as an SQL identifier.
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// literal makes s safe to use as an SQL string.
+func literal(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
@@ -0,0 +1,191 @@
+package localsync
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/localdb"
+)
+
+var migrations = []string{`CREATE TABLE notes (
+	id   TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	text TEXT NOT NULL,
+	done INTEGER NOT NULL DEFAULT 0
+)`}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	s, err := OpenServer(filepath.Join(t.TempDir(), "sync.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Token = "secret"
+	srv := httptest.NewServer(s)
+	t.Cleanup(func() { srv.Close(); s.Close() })
+	return srv
+}
+
+func newClient(t *testing.T, url string) *Client {
+	t.Helper()
+	db, err := localdb.Open(filepath.Join(t.TempDir(), "data.db"), migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	c, err := New(db, url, "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Token = "secret"
+	return c
+}
+
+func mustSync(t *testing.T, clients ...*Client) {
+	t.Helper()
+	for _, c := range clients {
+		if err := c.Sync(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func text(t *testing.T, c *Client, id string) string {
+	t.Helper()
+	rows, err := c.DB.QueryMaps(`SELECT text FROM notes WHERE id = ?`, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) == 0 {
+		return "<none>"
+	}
+	return rows[0]["text"].(string)
+}
+
+func TestSync(t *testing.T) {
+	srv := newServer(t)
+	a, b := newClient(t, srv.URL), newClient(t, srv.URL)
+	if a.Device() == b.Device() {
+		t.Fatal("devices share an ID")
+	}
+
+	if _, err := a.DB.Exec(`INSERT INTO notes (id, text) VALUES ('n1', 'milk')`); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Status().Pending; got != 1 {
+		t.Fatalf("pending = %d, want 1", got)
+	}
+	var changed []string
+	b.OnChange = func(tables []string) { changed = tables }
+	mustSync(t, a, b)
+	if got := text(t, b, "n1"); got != "milk" {
+		t.Fatalf("b has %q", got)
+	}
+	if strings.Join(changed, ",") != "notes" {
+		t.Errorf("OnChange got %v", changed)
+	}
+	if got := a.Status().Pending; got != 0 {
+		t.Errorf("pending after sync = %d", got)
+	}
+
+	// Pulled rows are not pushed back as local edits.
+	if got := b.Status().Pending; got != 0 {
+		t.Errorf("b pending = %d, want 0", got)
+	}
+
+	// Concurrent offline edits: the later one wins everywhere.
+	a.DB.Exec(`UPDATE notes SET text = 'oat milk' WHERE id = 'n1'`)
+	time.Sleep(5 * time.Millisecond)
+	b.DB.Exec(`UPDATE notes SET text = 'soy milk' WHERE id = 'n1'`)
+	mustSync(t, b, a, b)
+	if ta, tb := text(t, a, "n1"), text(t, b, "n1"); ta != "soy milk" || tb != "soy milk" {
+		t.Fatalf("a = %q, b = %q, want soy milk on both", ta, tb)
+	}
+
+	// Deletes sync too.
+	b.DB.Exec(`DELETE FROM notes WHERE id = 'n1'`)
+	mustSync(t, b, a)
+	if got := text(t, a, "n1"); got != "<none>" {
+		t.Fatalf("a still has %q", got)
+	}
+}
+
+func TestExistingRowsAndDefaults(t *testing.T) {
+	srv := newServer(t)
+	db, err := localdb.Open(filepath.Join(t.TempDir(), "data.db"), migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.Exec(`INSERT INTO notes (text) VALUES ('before sync')`)
+	a, err := New(db, srv.URL, "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Token = "secret"
+	b := newClient(t, srv.URL)
+	mustSync(t, a, b)
+	rows, _ := b.DB.QueryMaps(`SELECT id, text FROM notes`)
+	if len(rows) != 1 || rows[0]["text"] != "before sync" || len(rows[0]["id"].(string)) != 32 {
+		t.Fatalf("b rows = %v", rows)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	srv := newServer(t)
+	a := newClient(t, srv.URL)
+	srv.Close()
+
+	if _, err := a.DB.Exec(`INSERT INTO notes (id, text) VALUES ('n1', 'offline')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Sync(context.Background()); err == nil {
+		t.Fatal("sync succeeded without a server")
+	}
+	st := a.Status()
+	if st.Error == "" || st.Pending != 1 || !st.LastSync.IsZero() {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := newServer(t)
+	a := newClient(t, srv.URL)
+	a.Token = "wrong"
+	if err := a.Sync(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("err = %v, want 401", err)
+	}
+}
+
+func TestIntegerIDRejected(t *testing.T) {
+	db, err := localdb.Open(filepath.Join(t.TempDir(), "data.db"), []string{`CREATE TABLE notes (id INTEGER PRIMARY KEY, text TEXT)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := New(db, "http://localhost", "notes"); err == nil || !strings.Contains(err.Error(), "TEXT id") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestPaging(t *testing.T) {
+	srv := newServer(t)
+	a, b := newClient(t, srv.URL), newClient(t, srv.URL)
+	tx, _ := a.DB.Begin()
+	for i := 0; i < pageSize+batch; i++ {
+		tx.Exec(`INSERT INTO notes (text) VALUES ('x')`)
+	}
+	tx.Commit()
+	mustSync(t, a, b)
+	var n int
+	b.DB.QueryRow(`SELECT count(*) FROM notes`).Scan(&n)
+	if n != pageSize+batch {
+		t.Fatalf("b has %d rows, want %d", n, pageSize+batch)
+	}
+	if got := a.Status().Pending; got != 0 {
+		t.Errorf("a pending = %d", got)
+	}
+}
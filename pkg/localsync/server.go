@@ -0,0 +1,171 @@
+package localsync
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/localdb"
+)
+
+// pageSize bounds how many changes one response carries.
+const pageSize = 1000
+
+// MaxRequestSize bounds a client's request body.
+const MaxRequestSize = 32 << 20
+
+var serverMigrations = []string{`
+CREATE TABLE changes (
+	tbl     TEXT NOT NULL,
+	id      TEXT NOT NULL,
+	data    TEXT,
+	deleted INTEGER NOT NULL DEFAULT 0,
+	updated INTEGER NOT NULL,
+	origin  TEXT NOT NULL,
+	seq     INTEGER NOT NULL,
+	PRIMARY KEY (tbl, id)
+);
+CREATE INDEX changes_seq ON changes (seq);
+`}
+
+// Server is the reference sync server. It keeps the winning version of
+// every row it has seen, of any table, in its own SQLite database; it does
+// not need the apps' schemas. Each stored version gets the next sequence
+// number, which is the cursor clients pull from.
+type Server struct {
+	Token string // Bearer token clients must send, if set
+
+	db *localdb.DB
+}
+
+// OpenServer opens (creating if needed) the server's database at path.
+func OpenServer(path string) (*Server, error) {
+	db, err := localdb.Open(path, serverMigrations)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Server) Close() error {
+	return s.db.Close()
+}
+
+// Rows returns how many rows the server holds.
+func (s *Server) Rows() (int64, error) {
+	var n int64
+	err := s.db.QueryRow(`SELECT count(*) FROM changes`).Scan(&n)
+	return n, err
+}
+
+// ServeHTTP handles POST requests from Client: it stores the pushed
+// changes that win and answers with every change after the client's
+// cursor, its own included.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Token != "" {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(s.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, ch := range req.Changes {
+		if ch.Table == "" || ch.ID == "" || ch.Origin == "" {
+			http.Error(w, "changes need a table, id and origin", http.StatusBadRequest)
+			return
+		}
+		if !ch.Deleted && len(ch.Data) == 0 {
+			http.Error(w, "change to "+ch.Table+" "+ch.ID+" has no data", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := s.store(req.Changes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp, err := s.since(req.Since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// store keeps the changes that are newer than what the server has.
+func (s *Server) store(changes []Change) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var seq int64
+	if err := tx.QueryRow(`SELECT coalesce(max(seq), 0) FROM changes`).Scan(&seq); err != nil {
+		return err
+	}
+	for _, ch := range changes {
+		var updated int64
+		var origin string
+		err := tx.QueryRow(`SELECT updated, origin FROM changes WHERE tbl = ? AND id = ?`, ch.Table, ch.ID).Scan(&updated, &origin)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil && !ch.newer(updated, origin) {
+			continue
+		}
+		var data any
+		if !ch.Deleted {
+			data = string(ch.Data)
+		}
+		seq++
+		_, err = tx.Exec(`INSERT INTO changes (tbl, id, data, deleted, updated, origin, seq) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (tbl, id) DO UPDATE SET data = excluded.data, deleted = excluded.deleted,
+	updated = excluded.updated, origin = excluded.origin, seq = excluded.seq`,
+			ch.Table, ch.ID, data, ch.Deleted, ch.Updated, ch.Origin, seq)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", ch.Table, ch.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// since returns a page of the changes after cursor.
+func (s *Server) since(cursor int64) (*response, error) {
+	rows, err := s.db.Query(`SELECT tbl, id, data, deleted, updated, origin, seq FROM changes WHERE seq > ? ORDER BY seq LIMIT ?`,
+		cursor, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	resp := &response{Changes: []Change{}, Cursor: cursor}
+	for rows.Next() {
+		if len(resp.Changes) == pageSize {
+			resp.More = true
+			break
+		}
+		var ch Change
+		var data sql.NullString
+		if err := rows.Scan(&ch.Table, &ch.ID, &data, &ch.Deleted, &ch.Updated, &ch.Origin, &resp.Cursor); err != nil {
+			return nil, err
+		}
+		if data.Valid {
+			ch.Data = json.RawMessage(data.String)
+		}
+		resp.Changes = append(resp.Changes, ch)
+	}
+	return resp, rows.Err()
+}
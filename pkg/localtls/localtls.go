@@ -0,0 +1,133 @@
+// Package localtls serves a hybrid app's embedded server over HTTPS on
+// the loopback interface, for web APIs and cookies that insist on a secure
+// context (camera, clipboard, service workers, Secure cookies).
+//
+// Certificate returns a self-signed certificate for localhost, 127.0.0.1
+// and ::1, created on first use and kept in the app's config directory,
+// so the origin and what the page stored under it survive restarts. The
+// app's webview trusts it through webview.SetCustomCertificates, which is
+// supported on Windows and Android:
+//
+//	cert, _ := localtls.Certificate(dir)
+//	webview.SetCustomCertificates([]*x509.Certificate{cert.Leaf})
+//	srv := &http.Server{Handler: mux, TLSConfig: localtls.ServerConfig(cert)}
+//
+// WKWebView (macOS, iOS) has no such hook, but it already treats
+// http://127.0.0.1 and http://localhost as secure contexts; Loopback
+// reports where plain HTTP is the right choice. Nothing is added to the
+// system trust store, so the certificate means nothing to other programs.
+package localtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// File names in the directory given to Certificate.
+const (
+	CertFile = "localhost.pem"
+	KeyFile  = "localhost-key.pem"
+)
+
+// Validity is how long a new certificate is valid: the 398 days clients
+// accept at most, less a day of slack.
+const Validity = 397 * 24 * time.Hour
+
+// renewBefore is how long before it expires a certificate is replaced.
+const renewBefore = 30 * 24 * time.Hour
+
+// Certificate returns the certificate kept in dir, creating or renewing
+// it as needed. Leaf is set.
+func Certificate(dir string) (tls.Certificate, error) {
+	certPath, keyPath := filepath.Join(dir, CertFile), filepath.Join(dir, KeyFile)
+	if cert, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil &&
+		time.Until(cert.Leaf.NotAfter) > renewBefore {
+		return cert, nil
+	}
+
+	certPEM, keyPEM, err := Generate(time.Now())
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// Generate creates a self-signed certificate for the loopback names,
+// valid from now, returning it and its key PEM encoded.
+func Generate(now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "localhost", Organization: []string{"goup-util local server"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(Validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// ServerConfig returns a TLS configuration serving cert.
+func ServerConfig(cert tls.Certificate) *tls.Config {
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+}
+
+// ClientConfig returns a TLS configuration that trusts cert only, for
+// the app's own requests to its server (e.g. a readiness check).
+func ClientConfig(cert tls.Certificate) *tls.Config {
+	pool := x509.NewCertPool()
+	pool.AddCert(cert.Leaf)
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+}
+
+// Loopback reports whether the webview on goos treats plain HTTP on the
+// loopback interface as a secure context and cannot be told to trust a
+// certificate, so the server should stay on HTTP.
+func Loopback(goos string) bool {
+	return goos == "darwin" || goos == "ios"
+}
+
+// Supported reports whether this platform's webview can trust Certificate.
+func Supported() bool {
+	return !Loopback(runtime.GOOS)
+}
@@ -0,0 +1,91 @@
+package localtls
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCertificate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	cert, err := Certificate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.Leaf.VerifyHostname("localhost"); err != nil {
+		t.Error(err)
+	}
+	if err := cert.Leaf.VerifyHostname("127.0.0.1"); err != nil {
+		t.Error(err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, KeyFile)); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v", info.Mode())
+	}
+
+	// Kept across restarts, so the origin stays trusted.
+	again, err := Certificate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Leaf.Raw, cert.Leaf.Raw) {
+		t.Error("certificate was not reused")
+	}
+
+	// Replaced when close to expiry.
+	certPEM, keyPEM, err := Generate(time.Now().Add(-Validity + 24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, CertFile), certPEM, 0644)
+	os.WriteFile(filepath.Join(dir, KeyFile), keyPEM, 0600)
+	renewed, err := Certificate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(renewed.Leaf.NotAfter) < Validity-time.Hour {
+		t.Errorf("not renewed: expires %s", renewed.Leaf.NotAfter)
+	}
+}
+
+func TestServe(t *testing.T) {
+	cert, err := Certificate(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "secure")
+	}))
+	srv.TLS = ServerConfig(cert)
+	srv.StartTLS()
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: ClientConfig(cert)}}
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	for _, host := range []string{"localhost", "127.0.0.1"} {
+		resp, err := client.Get("https://" + net.JoinHostPort(host, port))
+		if err != nil {
+			t.Fatalf("%s: %v", host, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "secure" {
+			t.Errorf("%s: body = %q", host, body)
+		}
+	}
+
+	// Other certificates are not trusted.
+	other, _ := Certificate(t.TempDir())
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: ClientConfig(other)}}
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Error("request with another certificate's config succeeded")
+	}
+}
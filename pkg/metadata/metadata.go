@@ -0,0 +1,180 @@
+// Package metadata reads store listing text kept in git next to the app,
+// in the layout fastlane deliver and supply use: one directory per locale
+// with one plain-text file per field.
+//
+//	metadata/
+//	  en-US/
+//	    name.txt
+//	    subtitle.txt
+//	    description.txt
+//	    keywords.txt
+//	    release_notes.txt
+//	    release_notes.tmpl
+//	  de-DE/
+//	    ...
+//
+// Missing files are left unchanged in the stores, so a locale only needs
+// the fields it translates. release_notes.tmpl is a template the changelog
+// package fills from git history, for locales without release_notes.txt.
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Dir is the metadata directory in the app directory.
+const Dir = "metadata"
+
+// NotesTemplate is the release note template file in a locale directory.
+const NotesTemplate = "release_notes.tmpl"
+
+// Stores.
+const (
+	AppStore = "appstore"
+	Play     = "play"
+)
+
+// Locale is the listing text for one language. Empty fields are not sent.
+type Locale struct {
+	Code             string `json:"locale"`
+	Name             string `json:"name,omitempty"`
+	Subtitle         string `json:"subtitle,omitempty"`
+	Description      string `json:"description,omitempty"`
+	ShortDescription string `json:"shortDescription,omitempty"`
+	Keywords         string `json:"keywords,omitempty"`
+	ReleaseNotes     string `json:"releaseNotes,omitempty"`
+	PromotionalText  string `json:"promotionalText,omitempty"`
+	SupportURL       string `json:"supportUrl,omitempty"`
+	MarketingURL     string `json:"marketingUrl,omitempty"`
+	PrivacyURL       string `json:"privacyUrl,omitempty"`
+
+	// NotesTemplate is release_notes.tmpl, rendered into ReleaseNotes
+	// when release_notes.txt is empty.
+	NotesTemplate string `json:"-"`
+}
+
+// Field is one file in a locale directory and the stores' length limits
+// for it: 0 means the store has no such field, -1 that it has no limit.
+type Field struct {
+	File     string
+	AppStore int
+	Play     int
+	URL      bool
+	get      func(*Locale) *string
+}
+
+// Fields lists the supported files.
+var Fields = []Field{
+	{File: "name.txt", AppStore: 30, Play: 30, get: func(l *Locale) *string { return &l.Name }},
+	{File: "subtitle.txt", AppStore: 30, get: func(l *Locale) *string { return &l.Subtitle }},
+	{File: "description.txt", AppStore: 4000, Play: 4000, get: func(l *Locale) *string { return &l.Description }},
+	{File: "short_description.txt", Play: 80, get: func(l *Locale) *string { return &l.ShortDescription }},
+	{File: "keywords.txt", AppStore: 100, get: func(l *Locale) *string { return &l.Keywords }},
+	{File: "release_notes.txt", AppStore: 4000, Play: 500, get: func(l *Locale) *string { return &l.ReleaseNotes }},
+	{File: "promotional_text.txt", AppStore: 170, get: func(l *Locale) *string { return &l.PromotionalText }},
+	{File: "support_url.txt", AppStore: -1, URL: true, get: func(l *Locale) *string { return &l.SupportURL }},
+	{File: "marketing_url.txt", AppStore: -1, URL: true, get: func(l *Locale) *string { return &l.MarketingURL }},
+	{File: "privacy_url.txt", AppStore: -1, URL: true, get: func(l *Locale) *string { return &l.PrivacyURL }},
+}
+
+// Load reads every locale under appDir/metadata, sorted by code. It
+// returns nil when there is no metadata directory.
+func Load(appDir string) ([]Locale, error) {
+	root := filepath.Join(appDir, Dir)
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var locales []Locale
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		l := Locale{Code: e.Name()}
+		for _, f := range Fields {
+			data, err := os.ReadFile(filepath.Join(root, e.Name(), f.File))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			*f.get(&l) = strings.TrimSpace(string(data))
+		}
+		if data, err := os.ReadFile(filepath.Join(root, e.Name(), NotesTemplate)); err == nil {
+			l.NotesTemplate = string(data)
+		}
+		l.Keywords = normalizeKeywords(l.Keywords)
+		locales = append(locales, l)
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i].Code < locales[j].Code })
+	return locales, nil
+}
+
+// normalizeKeywords accepts one keyword per line as well as the comma
+// separated list App Store Connect expects.
+func normalizeKeywords(s string) string {
+	var words []string
+	for _, w := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, ",")
+}
+
+// Check returns every field of l that store would reject.
+func (l Locale) Check(store string) []error {
+	var problems []error
+	for _, f := range Fields {
+		value := *f.get(&l)
+		limit := f.AppStore
+		if store == Play {
+			limit = f.Play
+		}
+		if value == "" || limit == 0 {
+			continue
+		}
+		if f.URL {
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				problems = append(problems, fmt.Errorf("%s/%s: %q is not an http(s) URL", l.Code, f.File, value))
+			}
+			continue
+		}
+		if n := utf8.RuneCountInString(value); limit > 0 && n > limit {
+			problems = append(problems, fmt.Errorf("%s/%s: %d characters, %s allows %d", l.Code, f.File, n, store, limit))
+		}
+	}
+	return problems
+}
+
+// Init writes an en-US locale with the app name and empty files to fill in.
+func Init(appDir, name string) (string, error) {
+	dir := filepath.Join(appDir, Dir, "en-US")
+	if _, err := os.Stat(dir); err == nil {
+		return "", fmt.Errorf("%s already exists", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	starter := map[string]string{
+		"name.txt":        name,
+		"description.txt": name + " is a Gio app.",
+	}
+	for _, f := range []string{"name.txt", "subtitle.txt", "description.txt", "short_description.txt", "keywords.txt", "release_notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte(starter[f]+"\n"), 0644); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,50 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAndCheck(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Init(dir, "Hybrid Dashboard"); err != nil {
+		t.Fatal(err)
+	}
+	de := filepath.Join(dir, Dir, "de-DE")
+	os.MkdirAll(de, 0755)
+	os.WriteFile(filepath.Join(de, "keywords.txt"), []byte("Diagramme\nMetriken, Dashboard\n"), 0644)
+	os.WriteFile(filepath.Join(de, "short_description.txt"), []byte(strings.Repeat("x", 81)), 0644)
+	os.WriteFile(filepath.Join(de, "support_url.txt"), []byte("example.com/help"), 0644)
+	os.WriteFile(filepath.Join(de, NotesTemplate), []byte("Version {{.Version}}\n"), 0644)
+
+	locales, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locales) != 2 || locales[0].Code != "de-DE" || locales[1].Code != "en-US" {
+		t.Fatalf("locales = %+v", locales)
+	}
+	if en := locales[1]; en.Name != "Hybrid Dashboard" || en.Subtitle != "" {
+		t.Errorf("en-US = %+v", en)
+	}
+	if got := locales[0].NotesTemplate; got != "Version {{.Version}}\n" {
+		t.Errorf("release note template = %q", got)
+	}
+	if got := locales[0].Keywords; got != "Diagramme,Metriken,Dashboard" {
+		t.Errorf("keywords = %q", got)
+	}
+
+	if errs := locales[1].Check(Play); len(errs) != 0 {
+		t.Errorf("en-US: %v", errs)
+	}
+	// The short description only exists on Play, the support URL only on
+	// the App Store.
+	if errs := locales[0].Check(Play); len(errs) != 1 || !strings.Contains(errs[0].Error(), "short_description") {
+		t.Errorf("de-DE on Play: %v", errs)
+	}
+	if errs := locales[0].Check(AppStore); len(errs) != 1 || !strings.Contains(errs[0].Error(), "support_url") {
+		t.Errorf("de-DE on the App Store: %v", errs)
+	}
+}
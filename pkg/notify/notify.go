@@ -0,0 +1,319 @@
+// Package notify reports the end of long operations (SDK installs, build
+// all, bundles) to desktop notifications, Slack or Discord webhooks and
+// ntfy topics.
+//
+// Channels are configured once per user in notify.json in the goup-util
+// config directory (override with $GOUP_NOTIFY_CONFIG):
+//
+//	{
+//	  "channels": [
+//	    {"name": "desktop", "type": "desktop"},
+//	    {"name": "team", "type": "slack", "url": "${SLACK_WEBHOOK_URL}"},
+//	    {"name": "phone", "type": "ntfy", "topic": "my-builds"}
+//	  ]
+//	}
+//
+// URLs and topics may reference environment variables, which keeps
+// webhook secrets out of the file.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ConfigEnv overrides the config location.
+const ConfigEnv = "GOUP_NOTIFY_CONFIG"
+
+// Channel types.
+const (
+	Desktop = "desktop"
+	Slack   = "slack"
+	Discord = "discord"
+	Ntfy    = "ntfy"
+)
+
+// DefaultNtfyServer is used when a ntfy channel has no url.
+const DefaultNtfyServer = "https://ntfy.sh"
+
+// Channel is one notification target.
+type Channel struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	URL   string `json:"url,omitempty"`   // Webhook URL, or ntfy server
+	Topic string `json:"topic,omitempty"` // ntfy topic
+}
+
+// Validate checks the fields for the channel's type.
+func (c Channel) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("channel has no name")
+	}
+	switch c.Type {
+	case Desktop:
+	case Slack, Discord:
+		if c.URL == "" {
+			return fmt.Errorf("channel %s: %s needs a webhook url", c.Name, c.Type)
+		}
+	case Ntfy:
+		if c.Topic == "" {
+			return fmt.Errorf("channel %s: ntfy needs a topic", c.Name)
+		}
+	default:
+		return fmt.Errorf("channel %s: unknown type %q (use desktop, slack, discord or ntfy)", c.Name, c.Type)
+	}
+	return nil
+}
+
+// Config is the parsed notify.json.
+type Config struct {
+	Channels []Channel `json:"channels"`
+
+	path string
+}
+
+// DefaultPath returns the config location.
+func DefaultPath() string {
+	if p := os.Getenv(ConfigEnv); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "goup-util", "notify.json")
+}
+
+// Load reads the config at path ("" for the default). A missing file has
+// no channels.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+	c := &Config{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notify config: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, ch := range c.Channels {
+		if err := ch.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return c, nil
+}
+
+// Path is where the config is stored.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Save writes the config.
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Webhook URLs are credentials.
+	return os.WriteFile(c.path, append(data, '\n'), 0600)
+}
+
+// Add adds or replaces the channel with the same name.
+func (c *Config) Add(ch Channel) error {
+	if err := ch.Validate(); err != nil {
+		return err
+	}
+	for i := range c.Channels {
+		if c.Channels[i].Name == ch.Name {
+			c.Channels[i] = ch
+			return nil
+		}
+	}
+	c.Channels = append(c.Channels, ch)
+	return nil
+}
+
+// Remove deletes a channel by name.
+func (c *Config) Remove(name string) error {
+	for i, ch := range c.Channels {
+		if ch.Name == name {
+			c.Channels = append(c.Channels[:i], c.Channels[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no notification channel named %q", name)
+}
+
+// Select returns the channels named in names (comma-separated), or all of
+// them for "" or "all". With no channels configured it falls back to a
+// desktop notification.
+func (c *Config) Select(names string) ([]Channel, error) {
+	if names == "" || names == "all" {
+		if len(c.Channels) == 0 {
+			return []Channel{{Name: Desktop, Type: Desktop}}, nil
+		}
+		return c.Channels, nil
+	}
+	var selected []Channel
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, ch := range c.Channels {
+			if ch.Name == name || (ch.Type == name && name == Desktop) {
+				selected, found = append(selected, ch), true
+			}
+		}
+		if !found && name == Desktop {
+			selected, found = append(selected, Channel{Name: Desktop, Type: Desktop}), true
+		}
+		if !found {
+			return nil, fmt.Errorf("no notification channel named %q in %s; add one with 'goup-util notify add'", name, c.path)
+		}
+	}
+	return selected, nil
+}
+
+// Message is one notification.
+type Message struct {
+	Title    string
+	Body     string
+	OK       bool
+	Duration time.Duration
+}
+
+func (m Message) text() string {
+	mark := "✅"
+	if !m.OK {
+		mark = "❌"
+	}
+	s := mark + " " + m.Title
+	if m.Duration > 0 {
+		s += fmt.Sprintf(" (%s)", m.Duration.Round(time.Second))
+	}
+	if m.Body != "" {
+		s += "\n" + m.Body
+	}
+	return s
+}
+
+// Send delivers m to every channel and joins the errors.
+func Send(channels []Channel, m Message) error {
+	var errs []error
+	for _, ch := range channels {
+		if err := send(ch, m); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func send(ch Channel, m Message) error {
+	switch ch.Type {
+	case Desktop:
+		return desktop(m)
+	case Slack:
+		return postJSON(os.ExpandEnv(ch.URL), map[string]string{"text": m.text()})
+	case Discord:
+		return postJSON(os.ExpandEnv(ch.URL), map[string]string{"content": m.text()})
+	case Ntfy:
+		return ntfy(ch, m)
+	}
+	return fmt.Errorf("unknown type %q", ch.Type)
+}
+
+var client = &http.Client{Timeout: 15 * time.Second}
+
+func postJSON(url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func ntfy(ch Channel, m Message) error {
+	server := os.ExpandEnv(ch.URL)
+	if server == "" {
+		server = DefaultNtfyServer
+	}
+	body := m.Body
+	if m.Duration > 0 {
+		body = strings.TrimSpace(body + fmt.Sprintf("\nTook %s", m.Duration.Round(time.Second)))
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(server, "/")+"/"+os.ExpandEnv(ch.Topic), strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", m.Title)
+	if m.OK {
+		req.Header.Set("Tags", "white_check_mark")
+	} else {
+		req.Header.Set("Tags", "x")
+		req.Header.Set("Priority", "high")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("ntfy returned %s", resp.Status)
+	}
+	return nil
+}
+
+func desktop(m Message) error {
+	title := "goup-util"
+	body := m.text()
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e",
+			fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title)))
+	case "windows":
+		script := `[void][Reflection.Assembly]::LoadWithPartialName('System.Windows.Forms');` +
+			`$n = New-Object System.Windows.Forms.NotifyIcon; $n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true;` +
+			`$n.ShowBalloonTip(10000, $env:GOUP_NOTIFY_TITLE, $env:GOUP_NOTIFY_BODY, 'Info'); Start-Sleep -Seconds 5; $n.Dispose()`
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+		cmd.Env = append(os.Environ(), "GOUP_NOTIFY_TITLE="+title, "GOUP_NOTIFY_BODY="+body)
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return fmt.Errorf("notify-send not found (install libnotify-bin)")
+		}
+		cmd = exec.Command("notify-send", title, body)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigSelect(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "notify.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.Select("all"); len(got) != 1 || got[0].Type != Desktop {
+		t.Fatalf("empty config should fall back to desktop, got %+v", got)
+	}
+
+	if err := c.Add(Channel{Name: "team", Type: Slack}); err == nil {
+		t.Fatal("slack channel without url accepted")
+	}
+	c.Add(Channel{Name: "team", Type: Slack, URL: "https://hooks.example/x"})
+	c.Add(Channel{Name: "phone", Type: Ntfy, Topic: "builds"})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = Load(c.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.Select(""); len(got) != 2 {
+		t.Fatalf("all channels: got %d", len(got))
+	}
+	if got, err := c.Select("phone, desktop"); err != nil || len(got) != 2 || got[1].Type != Desktop {
+		t.Fatalf("select: %+v %v", got, err)
+	}
+	if _, err := c.Select("nope"); err == nil {
+		t.Fatal("unknown channel accepted")
+	}
+}
+
+func TestSendWebhooks(t *testing.T) {
+	var bodies []string
+	var title string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, r.URL.Path+" "+string(data))
+		if r.URL.Path == "/builds" {
+			title = r.Header.Get("Title")
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("TEST_NOTIFY_URL", srv.URL)
+	channels := []Channel{
+		{Name: "team", Type: Slack, URL: "${TEST_NOTIFY_URL}/slack"},
+		{Name: "chat", Type: Discord, URL: srv.URL + "/discord"},
+		{Name: "phone", Type: Ntfy, URL: srv.URL, Topic: "builds"},
+	}
+	msg := Message{Title: "goup-util build all finished", OK: true, Duration: 90 * time.Second}
+	if err := Send(channels, msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("got %d requests", len(bodies))
+	}
+	if !strings.HasPrefix(bodies[0], `/slack {"text":"✅ goup-util build all finished (1m30s)"`) {
+		t.Errorf("slack body: %s", bodies[0])
+	}
+	if !strings.HasPrefix(bodies[1], `/discord {"content":`) {
+		t.Errorf("discord body: %s", bodies[1])
+	}
+	if title != msg.Title || !strings.Contains(bodies[2], "Took 1m30s") {
+		t.Errorf("ntfy: title %q body %s", title, bodies[2])
+	}
+}
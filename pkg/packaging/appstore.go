@@ -0,0 +1,174 @@
+package packaging
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/macos-appstore-entitlements.plist.tmpl
+var macosAppStoreEntitlementsTemplate string
+
+// DefaultAppStoreCategory is used when no LSApplicationCategoryType is given.
+const DefaultAppStoreCategory = "public.app-category.utilities"
+
+// Signing identity prefixes accepted by the Mac App Store.
+var (
+	appStoreAppIdentities       = []string{"Apple Distribution", "3rd Party Mac Developer Application"}
+	appStoreInstallerIdentities = []string{"3rd Party Mac Developer Installer", "Mac Installer Distribution"}
+)
+
+// ProvisioningProfile holds the fields of a .provisionprofile that matter
+// for App Store signing.
+type ProvisioningProfile struct {
+	TeamID        string // e.g. "ABCDE12345"
+	AppIdentifier string // e.g. "ABCDE12345.com.example.myapp" (may end in "*")
+}
+
+// ValidateAppStore checks a bundle config against Mac App Store rules and
+// returns every problem found, so they can be fixed in one pass.
+func ValidateAppStore(config MacOSBundleConfig) []error {
+	var problems []error
+
+	if config.BundleID == "" || strings.HasPrefix(config.BundleID, "com.example.") {
+		problems = append(problems, fmt.Errorf("bundle ID %q is a placeholder; set --bundle-id to the ID registered in App Store Connect", config.BundleID))
+	}
+	if config.SigningIdentity == "" || config.SigningIdentity == "-" {
+		problems = append(problems, fmt.Errorf("ad-hoc signing is not accepted; set --sign to an %q identity", appStoreAppIdentities[0]))
+	} else if !hasAnyPrefix(config.SigningIdentity, appStoreAppIdentities) {
+		problems = append(problems, fmt.Errorf("signing identity %q is not an App Store identity (want one of %v)", config.SigningIdentity, appStoreAppIdentities))
+	}
+	if config.InstallerIdentity == "" {
+		problems = append(problems, fmt.Errorf("an installer identity is required to sign the .pkg; set --installer-sign"))
+	} else if !hasAnyPrefix(config.InstallerIdentity, appStoreInstallerIdentities) {
+		problems = append(problems, fmt.Errorf("installer identity %q is not an App Store identity (want one of %v)", config.InstallerIdentity, appStoreInstallerIdentities))
+	}
+
+	if config.ProvisioningProfile == "" {
+		problems = append(problems, fmt.Errorf("a Mac App Store provisioning profile is required; set --provisioning-profile"))
+	} else if profile, err := ReadProvisioningProfile(config.ProvisioningProfile); err != nil {
+		problems = append(problems, err)
+	} else if !profile.Matches(config.BundleID) {
+		problems = append(problems, fmt.Errorf("provisioning profile is for %q, not %s.%s", profile.AppIdentifier, profile.TeamID, config.BundleID))
+	}
+
+	return problems
+}
+
+// exportComplianceWarnings explains export compliance gaps that will make
+// App Store Connect stop the build for manual answers.
+func exportComplianceWarnings(config MacOSBundleConfig) []string {
+	if !config.EncryptionDeclared() {
+		return []string{`export compliance not declared; add "exportCompliance" to app.json or TestFlight will wait for the encryption questionnaire`}
+	}
+	if config.UsesEncryption() && config.EncryptionComplianceCode == "" {
+		return []string{"app uses non-exempt encryption but has no compliance code; App Store Connect will ask for export documentation"}
+	}
+	return nil
+}
+
+// ReadProvisioningProfile decodes a .provisionprofile with `security cms`.
+func ReadProvisioningProfile(path string) (*ProvisioningProfile, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("provisioning profile not found: %s", path)
+	}
+	out, err := exec.Command("security", "cms", "-D", "-i", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode provisioning profile: %w", err)
+	}
+	return parseProvisioningProfile(string(out))
+}
+
+var (
+	teamIDPattern = regexp.MustCompile(`<key>TeamIdentifier</key>\s*<array>\s*<string>([^<]+)</string>`)
+	appIDPattern  = regexp.MustCompile(`<key>com\.apple\.application-identifier</key>\s*<string>([^<]+)</string>`)
+)
+
+// parseProvisioningProfile extracts the team and app identifier from the
+// decoded profile plist.
+func parseProvisioningProfile(plist string) (*ProvisioningProfile, error) {
+	team := teamIDPattern.FindStringSubmatch(plist)
+	app := appIDPattern.FindStringSubmatch(plist)
+	if team == nil || app == nil {
+		return nil, fmt.Errorf("provisioning profile has no TeamIdentifier or application-identifier")
+	}
+	return &ProvisioningProfile{TeamID: team[1], AppIdentifier: app[1]}, nil
+}
+
+// Matches reports whether the profile may sign bundleID.
+func (p *ProvisioningProfile) Matches(bundleID string) bool {
+	want := p.TeamID + "." + bundleID
+	if prefix, ok := strings.CutSuffix(p.AppIdentifier, "*"); ok {
+		return strings.HasPrefix(want, prefix)
+	}
+	return p.AppIdentifier == want
+}
+
+// prepareAppStoreBundle embeds the provisioning profile and writes sandbox
+// entitlements. It returns the entitlements path for signing.
+func prepareAppStoreBundle(contentsDir string, config MacOSBundleConfig) (string, error) {
+	profile, err := ReadProvisioningProfile(config.ProvisioningProfile)
+	if err != nil {
+		return "", err
+	}
+	if err := copyFile(config.ProvisioningProfile, filepath.Join(contentsDir, "embedded.provisionprofile")); err != nil {
+		return "", fmt.Errorf("failed to embed provisioning profile: %w", err)
+	}
+	fmt.Printf("  ✓ Provisioning profile embedded (team %s)\n", profile.TeamID)
+
+	tmpl, err := template.New("appstore-entitlements.plist").Parse(macosAppStoreEntitlementsTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	path := filepath.Join(contentsDir, "Entitlements.plist")
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	data := map[string]interface{}{
+		"TeamID":     profile.TeamID,
+		"BundleID":   config.BundleID,
+		"Camera":     config.UsageDescriptions["NSCameraUsageDescription"] != "",
+		"Microphone": config.UsageDescriptions["NSMicrophoneUsageDescription"] != "",
+	}
+	if err := tmpl.Execute(file, data); err != nil {
+		return "", err
+	}
+	fmt.Printf("  ✓ Sandbox entitlements created\n")
+	return path, nil
+}
+
+// buildAppStorePackage wraps the signed .app in an installer .pkg for upload
+// to App Store Connect (Transporter or `xcrun altool`).
+func buildAppStorePackage(appBundlePath, outputDir, installerIdentity string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(appBundlePath), ".app")
+	pkgPath := filepath.Join(outputDir, name+".pkg")
+
+	fmt.Printf("📦 Building App Store package...\n")
+	cmd := exec.Command("productbuild",
+		"--component", appBundlePath, "/Applications",
+		"--sign", installerIdentity,
+		pkgPath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("productbuild failed: %w\nOutput: %s", err, output)
+	}
+	fmt.Printf("  ✓ Package created: %s\n", pkgPath)
+	return pkgPath, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package packaging
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SetIOSExportCompliance writes ITSAppUsesNonExemptEncryption, and
+// ITSEncryptionExportComplianceCode when code is set, into the Info.plist
+// of an iOS .app built by gogio, which has no option for extra keys.
+// A signed app is re-signed with the identity that signed it, keeping its
+// identifier and entitlements.
+func SetIOSExportCompliance(appPath string, usesNonExemptEncryption bool, code string) error {
+	plist := filepath.Join(appPath, "Info.plist")
+	edits := [][]string{{"ITSAppUsesNonExemptEncryption", "-bool", strconv.FormatBool(usesNonExemptEncryption)}}
+	if code != "" {
+		edits = append(edits, []string{"ITSEncryptionExportComplianceCode", "-string", code})
+	}
+	for _, e := range edits {
+		args := append([]string{"-replace"}, e...)
+		if output, err := exec.Command("plutil", append(args, plist)...).CombinedOutput(); err != nil {
+			return fmt.Errorf("plutil failed to set %s: %w\nOutput: %s", e[0], err, output)
+		}
+	}
+
+	identity := signingAuthority(appPath)
+	if identity == "" {
+		return nil // Unsigned simulator build
+	}
+	output, err := exec.Command("codesign", "--force", "--preserve-metadata=identifier,entitlements,flags", "--sign", identity, appPath).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("codesign failed: %w\nOutput: %s", err, output)
+	}
+	return nil
+}
+
+// signingAuthority returns the identity that signed a bundle, "-" for an
+// ad-hoc signature, or "" if it is not signed.
+func signingAuthority(bundlePath string) string {
+	output, err := exec.Command("codesign", "-dvv", bundlePath).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if name, ok := strings.CutPrefix(line, "Authority="); ok {
+			return strings.TrimSpace(name) // The leaf certificate comes first
+		}
+	}
+	if strings.Contains(string(output), "Signature=adhoc") {
+		return "-"
+	}
+	return ""
+}
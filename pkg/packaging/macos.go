@@ -32,9 +32,22 @@ type MacOSBundleConfig struct {
 	OutputDir  string // Where to create the .app bundle
 	IconPath   string // Path to .icns icon file (optional)
 
+	// Privacy usage descriptions (e.g. "NSCameraUsageDescription" → reason)
+	UsageDescriptions map[string]string
+
+	// Export compliance (nil leaves the App Store Connect questionnaire open)
+	UsesNonExemptEncryption  *bool  // ITSAppUsesNonExemptEncryption
+	EncryptionComplianceCode string // ITSEncryptionExportComplianceCode
+
 	// Code signing
 	SigningIdentity string // Code signing identity (empty for ad-hoc)
 	Entitlements    bool   // Whether to use entitlements
+
+	// Mac App Store
+	AppStore            bool   // Sandbox entitlements, validation and a signed .pkg
+	ProvisioningProfile string // Path to the Mac App Store .provisionprofile
+	InstallerIdentity   string // Installer signing identity for productbuild
+	Category            string // LSApplicationCategoryType (e.g. "public.app-category.utilities")
 }
 
 // Executable returns the executable name from the app name
@@ -42,6 +55,16 @@ func (c *MacOSBundleConfig) Executable() string {
 	return c.Name
 }
 
+// EncryptionDeclared reports whether export compliance was declared.
+func (c MacOSBundleConfig) EncryptionDeclared() bool {
+	return c.UsesNonExemptEncryption != nil
+}
+
+// UsesEncryption reports the declared ITSAppUsesNonExemptEncryption value.
+func (c MacOSBundleConfig) UsesEncryption() bool {
+	return c.UsesNonExemptEncryption != nil && *c.UsesNonExemptEncryption
+}
+
 // CreateMacOSBundle creates a properly structured macOS app bundle with code signing
 func CreateMacOSBundle(config MacOSBundleConfig) error {
 	// Validate config
@@ -71,6 +94,23 @@ func CreateMacOSBundle(config MacOSBundleConfig) error {
 	if config.Year == "" {
 		config.Year = fmt.Sprintf("%d", time.Now().Year())
 	}
+	if config.AppStore && config.Category == "" {
+		config.Category = DefaultAppStoreCategory
+	}
+
+	// App Store builds must pass validation before anything is written
+	if config.AppStore {
+		if problems := ValidateAppStore(config); len(problems) > 0 {
+			msg := "bundle is not App Store compliant:"
+			for _, p := range problems {
+				msg += "\n  - " + p.Error()
+			}
+			return fmt.Errorf("%s", msg)
+		}
+		for _, w := range exportComplianceWarnings(config) {
+			fmt.Printf("⚠️  %s\n", w)
+		}
+	}
 
 	// Check binary exists
 	if _, err := os.Stat(config.BinaryPath); os.IsNotExist(err) {
@@ -126,9 +166,15 @@ func CreateMacOSBundle(config MacOSBundleConfig) error {
 
 	// Generate entitlements if needed
 	var entitlementsPath string
-	if config.Entitlements {
+	if config.AppStore {
+		path, err := prepareAppStoreBundle(contentsDir, config)
+		if err != nil {
+			return fmt.Errorf("failed to prepare App Store bundle: %w", err)
+		}
+		entitlementsPath = path
+	} else if config.Entitlements {
 		entitlementsPath = filepath.Join(contentsDir, "Entitlements.plist")
-		if err := generateEntitlements(entitlementsPath); err != nil {
+		if err := generateEntitlements(entitlementsPath, config); err != nil {
 			return fmt.Errorf("failed to generate entitlements: %w", err)
 		}
 		fmt.Printf("  ✓ Entitlements.plist created\n")
@@ -139,6 +185,12 @@ func CreateMacOSBundle(config MacOSBundleConfig) error {
 		return fmt.Errorf("failed to sign bundle: %w", err)
 	}
 
+	if config.AppStore {
+		if _, err := buildAppStorePackage(appBundlePath, config.OutputDir, config.InstallerIdentity); err != nil {
+			return err
+		}
+	}
+
 	fmt.Printf("✅ macOS app bundle created successfully\n")
 	fmt.Printf("📍 Location: %s\n", appBundlePath)
 
@@ -161,8 +213,9 @@ func generateInfoPlist(path string, config MacOSBundleConfig) error {
 	return tmpl.Execute(file, config)
 }
 
-// generateEntitlements creates the Entitlements.plist from template
-func generateEntitlements(path string) error {
+// generateEntitlements creates the Entitlements.plist from template, with
+// device access for the permissions that have usage descriptions
+func generateEntitlements(path string, config MacOSBundleConfig) error {
 	tmpl, err := template.New("entitlements.plist").Parse(macosEntitlementsTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
@@ -174,7 +227,11 @@ func generateEntitlements(path string) error {
 	}
 	defer file.Close()
 
-	return tmpl.Execute(file, nil)
+	data := map[string]interface{}{
+		"Camera":     config.UsageDescriptions["NSCameraUsageDescription"] != "",
+		"Microphone": config.UsageDescriptions["NSMicrophoneUsageDescription"] != "",
+	}
+	return tmpl.Execute(file, data)
 }
 
 // signBundle signs the app bundle with the specified identity
@@ -0,0 +1,49 @@
+package packaging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeblew999/goup-util/pkg/permissions"
+)
+
+func TestMacOSPermissionKeys(t *testing.T) {
+	usage, err := permissions.UsageDescriptions(map[string]string{
+		"camera":           "Scan <badges> & codes",
+		"screen-recording": "Screenshots",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := MacOSBundleConfig{Name: "demo", BundleID: "com.example.demo", UsageDescriptions: usage}
+	dir := t.TempDir()
+
+	infoPath := filepath.Join(dir, "Info.plist")
+	if err := generateInfoPlist(infoPath, config); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(info), "<key>NSCameraUsageDescription</key>\n\t<string>Scan &lt;badges&gt; &amp; codes</string>") {
+		t.Errorf("Info.plist lacks the escaped camera usage description:\n%s", info)
+	}
+
+	entPath := filepath.Join(dir, "Entitlements.plist")
+	if err := generateEntitlements(entPath, config); err != nil {
+		t.Fatal(err)
+	}
+	ent, err := os.ReadFile(entPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(ent), "<key>com.apple.security.device.camera</key>") {
+		t.Errorf("Entitlements.plist lacks the camera entitlement:\n%s", ent)
+	}
+	if strings.Contains(string(ent), "<key>com.apple.security.device.audio-input</key>") {
+		t.Errorf("Entitlements.plist grants the microphone without a usage description:\n%s", ent)
+	}
+}
@@ -0,0 +1,77 @@
+package packaging
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/wintools"
+)
+
+// WebView2 runtime bundling modes for Windows webview apps.
+const (
+	// WebView2Bootstrapper ships Microsoft's Evergreen bootstrapper; the
+	// app runs it on first launch when no runtime is installed.
+	WebView2Bootstrapper = "bootstrapper"
+	// WebView2Fixed ships a fixed-version runtime next to the app; nothing
+	// is installed and the app always uses that version.
+	WebView2Fixed = "fixed"
+)
+
+// WebView2FixedDir is the bundle folder holding a fixed-version runtime.
+// Shells point WEBVIEW2_BROWSER_EXECUTABLE_FOLDER at it.
+const WebView2FixedDir = "WebView2Runtime"
+
+// WebView2Bundle configures bundling the WebView2 runtime with a Windows app.
+type WebView2Bundle struct {
+	Mode   string // "" (none), WebView2Bootstrapper or WebView2Fixed
+	Source string // Bootstrapper .exe (default: downloaded), or the extracted fixed-version runtime folder
+}
+
+// stageWebView2 copies the bootstrapper or fixed-version runtime next to
+// the executable in the staging directory.
+func stageWebView2(w WebView2Bundle, stagingDir string) error {
+	switch w.Mode {
+	case "":
+		return nil
+	case WebView2Bootstrapper:
+		dst := filepath.Join(stagingDir, wintools.WebView2BootstrapperName)
+		if w.Source != "" {
+			return CopyFile(w.Source, dst)
+		}
+		return wintools.DownloadWebView2Bootstrapper(dst)
+	case WebView2Fixed:
+		if w.Source == "" {
+			return fmt.Errorf("a fixed-version WebView2 runtime needs its folder; download it from https://developer.microsoft.com/microsoft-edge/webview2/")
+		}
+		if strings.EqualFold(filepath.Ext(w.Source), ".cab") {
+			return fmt.Errorf("extract %s first (on Windows: expand %s -F:* <folder>) and pass the folder", w.Source, filepath.Base(w.Source))
+		}
+		if _, err := os.Stat(filepath.Join(w.Source, "msedgewebview2.exe")); err != nil {
+			return fmt.Errorf("%s is not a WebView2 runtime folder (no msedgewebview2.exe)", w.Source)
+		}
+		return copyDir(w.Source, filepath.Join(stagingDir, WebView2FixedDir))
+	default:
+		return fmt.Errorf("unknown WebView2 mode %q (use %s or %s)", w.Mode, WebView2Bootstrapper, WebView2Fixed)
+	}
+}
+
+// copyDir copies a directory tree.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return CopyFile(path, target)
+	})
+}
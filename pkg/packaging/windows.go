@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"text/template"
+
+	"github.com/joeblew999/goup-util/pkg/wintools"
 )
 
 //go:embed templates/windows-appxmanifest.xml.tmpl
@@ -28,9 +30,21 @@ type WindowsBundleConfig struct {
 	OutputDir  string // Where to create the MSIX bundle
 	AssetsDir  string // Path to logo assets (optional)
 
+	// Device capabilities the app uses (e.g. "webcam", "microphone");
+	// packaged apps are denied devices they do not declare
+	DeviceCapabilities []string
+
+	// StartupTask starts the app when the user logs in. Packaged apps
+	// cannot add themselves to the Run key; users can still turn the
+	// task off in Task Manager's startup apps.
+	StartupTask bool
+
 	// Packaging options
 	CreateMSIX bool // Whether to create the actual MSIX (Windows-only)
 
+	// WebView2 runtime to ship with webview apps (optional)
+	WebView2 WebView2Bundle
+
 	// Code signing (future)
 	SigningCertificate  string // Path to .pfx file
 	CertificatePassword string // Password for certificate
@@ -97,6 +111,13 @@ func CreateWindowsBundle(config WindowsBundleConfig) error {
 	}
 	fmt.Printf("  ✓ Binary copied: %s\n", executableName)
 
+	if config.WebView2.Mode != "" {
+		if err := stageWebView2(config.WebView2, stagingDir); err != nil {
+			return fmt.Errorf("failed to bundle WebView2 runtime: %w", err)
+		}
+		fmt.Printf("  ✓ WebView2 runtime bundled (%s)\n", config.WebView2.Mode)
+	}
+
 	// Create assets directory
 	assetsDir := filepath.Join(stagingDir, "assets")
 	if err := os.MkdirAll(assetsDir, 0755); err != nil {
@@ -186,24 +207,30 @@ func generateWindowsManifest(path string, config WindowsBundleConfig) error {
 		"publisherDisplayName": config.PublisherDisplayName,
 		"executable":           config.Name, // Just the name, .exe added by template
 		"description":          config.Description,
+		"deviceCapabilities":   config.DeviceCapabilities,
+		"startupTask":          config.StartupTask,
 	}
 
 	return tmpl.Execute(file, data)
 }
 
-// createMSIXPackage creates the MSIX file using the msix toolkit
+// createMSIXPackage creates the MSIX file using the msix toolkit, or
+// MakeAppx from the Windows SDK
 func createMSIXPackage(sourceDir, outputPath string) error {
-	// Check if msix command is available
-	msixPath, err := exec.LookPath("msix")
+	packer, makeAppx, err := wintools.FindMSIXPacker()
 	if err != nil {
-		return fmt.Errorf("msix command not found. Install via: winget install Microsoft.MsixPackagingTool")
+		return err
 	}
 
 	// Run msix pack command
-	cmd := exec.Command(msixPath, "pack", "-d", sourceDir, "-p", outputPath)
+	args := []string{"pack", "-d", sourceDir, "-p", outputPath}
+	if makeAppx {
+		args = []string{"pack", "/d", sourceDir, "/p", outputPath, "/o"}
+	}
+	cmd := exec.Command(packer, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("msix pack failed: %w\nOutput: %s", err, output)
+		return fmt.Errorf("%s pack failed: %w\nOutput: %s", filepath.Base(packer), err, output)
 	}
 
 	return nil
@@ -212,9 +239,9 @@ func createMSIXPackage(sourceDir, outputPath string) error {
 // signMSIX signs an MSIX package using signtool
 func signMSIX(msixPath, certPath, password string) error {
 	// Check if signtool is available
-	signtool, err := exec.LookPath("signtool")
+	signtool, err := wintools.FindSDKTool("signtool")
 	if err != nil {
-		return fmt.Errorf("signtool not found. Install Windows SDK or use Visual Studio Developer Command Prompt")
+		return err
 	}
 
 	// Build signtool command
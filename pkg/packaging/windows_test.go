@@ -0,0 +1,37 @@
+package packaging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeblew999/goup-util/pkg/permissions"
+)
+
+func TestWindowsDeviceCapabilities(t *testing.T) {
+	var capabilities []string
+	for _, p := range []permissions.Permission{permissions.Camera, permissions.Microphone, permissions.LocalNetwork} {
+		if c := permissions.MSIXCapability(p); c != "" {
+			capabilities = append(capabilities, c)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "AppxManifest.xml")
+	config := WindowsBundleConfig{Name: "demo", Publisher: "CN=Example", Version: "1.0.0.0", DeviceCapabilities: capabilities}
+	if err := generateWindowsManifest(path, config); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := string(data)
+	for _, want := range []string{`<DeviceCapability Name="webcam" />`, `<DeviceCapability Name="microphone" />`} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("AppxManifest.xml lacks %s:\n%s", want, manifest)
+		}
+	}
+	if n := strings.Count(manifest, "<DeviceCapability"); n != 2 {
+		t.Errorf("AppxManifest.xml declares %d device capabilities, want 2", n)
+	}
+}
@@ -0,0 +1,143 @@
+// Package permissions detects OS privacy permissions that goup-util and
+// packaged apps depend on, and opens the matching settings pane.
+//
+// Detection is best effort without cgo: macOS answers come from the TCC
+// database (readable only with Full Disk Access), Windows from the
+// CapabilityAccessManager consent store, Linux from the session type.
+package permissions
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Permission identifies an OS privacy permission.
+type Permission string
+
+const (
+	ScreenRecording Permission = "screen-recording" // Screenshots (goup-util screenshot)
+	Camera          Permission = "camera"
+	Microphone      Permission = "microphone"
+	LocalNetwork    Permission = "local-network" // Discovering devices on the LAN (macOS 15+, iOS 14+)
+	Biometrics      Permission = "biometrics"    // Touch ID, Face ID, Windows Hello
+	Notifications   Permission = "notifications" // Local notifications (macOS, iOS, Android 13+)
+)
+
+// All lists every known permission in display order.
+var All = []Permission{ScreenRecording, Camera, Microphone, LocalNetwork, Biometrics, Notifications}
+
+// Status is the detected state of a permission.
+type Status string
+
+const (
+	Granted       Status = "granted"
+	Denied        Status = "denied"
+	NotDetermined Status = "not-determined" // The OS will prompt on first use
+	NotRequired   Status = "not-required"   // This platform has no such permission
+	Unknown       Status = "unknown"        // Could not be detected
+)
+
+// Result is the outcome of checking one permission.
+type Result struct {
+	Permission Permission `json:"permission"`
+	Status     Status     `json:"status"`
+	Detail     string     `json:"detail,omitempty"`
+	Fix        string     `json:"fix,omitempty"` // How to grant it, if not granted
+}
+
+// Options narrows a check to a specific app.
+type Options struct {
+	// BundleID is the macOS app to check (e.g. "com.example.myapp").
+	// Empty means the app that launched goup-util (usually the terminal).
+	BundleID string
+}
+
+// usageDescriptionKeys maps permissions to their Info.plist usage keys.
+var usageDescriptionKeys = map[Permission]string{
+	Camera:       "NSCameraUsageDescription",
+	Microphone:   "NSMicrophoneUsageDescription",
+	LocalNetwork: "NSLocalNetworkUsageDescription",
+	Biometrics:   "NSFaceIDUsageDescription",
+}
+
+// msixCapabilities maps permissions to MSIX device capabilities.
+var msixCapabilities = map[Permission]string{
+	Camera:     "webcam",
+	Microphone: "microphone",
+}
+
+// Parse validates a permission name.
+func Parse(name string) (Permission, error) {
+	for _, p := range All {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown permission %q (valid: %v)", name, All)
+}
+
+// UsageDescriptionKey returns the Info.plist key that must explain why the
+// app needs p, or "" if p has none (screen recording is prompted by the OS).
+func UsageDescriptionKey(p Permission) string {
+	return usageDescriptionKeys[p]
+}
+
+// MSIXCapability returns the AppxManifest DeviceCapability a packaged
+// Windows app must declare to use p, or "" if it needs none.
+func MSIXCapability(p Permission) string {
+	return msixCapabilities[p]
+}
+
+// UsageDescriptions converts app.json permission reasons into Info.plist
+// keys, sorted for stable output. Unknown names are reported as errors.
+func UsageDescriptions(reasons map[string]string) (map[string]string, error) {
+	result := make(map[string]string)
+	names := make([]string, 0, len(reasons))
+	for name := range reasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p, err := Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		if key := UsageDescriptionKey(p); key != "" {
+			result[key] = reasons[name]
+		}
+	}
+	return result, nil
+}
+
+// Check reports the status of each permission on this machine.
+func Check(opts Options, perms ...Permission) []Result {
+	if len(perms) == 0 {
+		perms = All
+	}
+	results := make([]Result, 0, len(perms))
+	for _, p := range perms {
+		r := check(opts, p)
+		r.Permission = p
+		if r.Status != Granted && r.Status != NotRequired && r.Fix == "" {
+			r.Fix = fixHint(p)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// OK reports whether none of the results block the app.
+func OK(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Denied {
+			return false
+		}
+	}
+	return true
+}
+
+// Open shows the OS settings pane where p can be granted.
+func Open(p Permission) error {
+	return openSettings(p)
+}
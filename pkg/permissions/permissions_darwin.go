@@ -0,0 +1,94 @@
+package permissions
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// tccServices maps permissions to their TCC service names.
+var tccServices = map[Permission]string{
+	ScreenRecording: "kTCCServiceScreenCapture",
+	Camera:          "kTCCServiceCamera",
+	Microphone:      "kTCCServiceMicrophone",
+}
+
+// settingsPanes are the System Settings deep links for each permission.
+var settingsPanes = map[Permission]string{
+	ScreenRecording: "Privacy_ScreenCapture",
+	Camera:          "Privacy_Camera",
+	Microphone:      "Privacy_Microphone",
+	LocalNetwork:    "Privacy_LocalNetwork",
+}
+
+var settingsNames = map[Permission]string{
+	ScreenRecording: "Screen Recording",
+	Camera:          "Camera",
+	Microphone:      "Microphone",
+	LocalNetwork:    "Local Network",
+}
+
+func check(opts Options, p Permission) Result {
+	switch p {
+	case Biometrics:
+		return Result{Status: NotRequired, Detail: "Touch ID asks every time; there is no consent to store"}
+	case Notifications:
+		// Kept per app by the notification center, not in TCC.db
+		return Result{Status: Unknown, Detail: "macOS asks the first time the app shows a notification",
+			Fix: "System Settings → Notifications (goup-util permissions open notifications)"}
+	}
+	service, ok := tccServices[p]
+	if !ok {
+		// Local Network consent is not stored in TCC.db
+		return Result{Status: Unknown, Detail: "macOS asks the first time the app contacts the local network"}
+	}
+
+	client := opts.BundleID
+	if client == "" {
+		// Set by macOS for processes launched from an app (Terminal, iTerm, VS Code)
+		client = os.Getenv("__CFBundleIdentifier")
+	}
+	if client == "" {
+		return Result{Status: Unknown, Detail: "cannot tell which app to check (use --bundle-id)"}
+	}
+
+	// Screen recording lives in the system database, the rest per user
+	db := "/Library/Application Support/com.apple.TCC/TCC.db"
+	if p != ScreenRecording {
+		home, _ := os.UserHomeDir()
+		db = filepath.Join(home, "Library", "Application Support", "com.apple.TCC", "TCC.db")
+	}
+
+	query := fmt.Sprintf("SELECT auth_value FROM access WHERE service='%s' AND client='%s';",
+		service, strings.ReplaceAll(client, "'", ""))
+	out, err := exec.Command("sqlite3", "-readonly", db, query).Output()
+	if err != nil {
+		return Result{Status: Unknown, Detail: fmt.Sprintf("TCC database not readable; grant Full Disk Access to %s to detect", client)}
+	}
+
+	switch strings.TrimSpace(string(out)) {
+	case "":
+		return Result{Status: NotDetermined, Detail: client + " has not asked yet"}
+	case "0":
+		return Result{Status: Denied, Detail: client + " was denied"}
+	default: // 2 = allowed, 3 = limited
+		return Result{Status: Granted, Detail: client}
+	}
+}
+
+func fixHint(p Permission) string {
+	return fmt.Sprintf("System Settings → Privacy & Security → %s (goup-util permissions open %s)", settingsNames[p], p)
+}
+
+func openSettings(p Permission) error {
+	if p == Notifications {
+		return exec.Command("open", "x-apple.systempreferences:com.apple.preference.notifications").Run()
+	}
+	pane, ok := settingsPanes[p]
+	if !ok {
+		return fmt.Errorf("no settings pane for %s", p)
+	}
+	return exec.Command("open", "x-apple.systempreferences:com.apple.preference.security?"+pane).Run()
+}
@@ -0,0 +1,40 @@
+//go:build !darwin && !windows
+
+package permissions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func check(opts Options, p Permission) Result {
+	switch p {
+	case ScreenRecording:
+		if os.Getenv("XDG_SESSION_TYPE") == "wayland" {
+			return Result{Status: NotDetermined, Detail: "Wayland asks through the desktop portal on each capture"}
+		}
+		return Result{Status: NotRequired}
+	case Camera:
+		devices, _ := filepath.Glob("/dev/video*")
+		if len(devices) == 0 {
+			return Result{Status: Unknown, Detail: "no camera device found"}
+		}
+		f, err := os.Open(devices[0])
+		if err != nil {
+			return Result{Status: Denied, Detail: fmt.Sprintf("%s: %v", devices[0], err), Fix: "Add your user to the video group: sudo usermod -aG video $USER"}
+		}
+		f.Close()
+		return Result{Status: Granted, Detail: devices[0]}
+	default:
+		return Result{Status: NotRequired}
+	}
+}
+
+func fixHint(p Permission) string {
+	return fmt.Sprintf("Grant %s access in your desktop's privacy settings", p)
+}
+
+func openSettings(p Permission) error {
+	return fmt.Errorf("opening privacy settings is not supported on this platform")
+}
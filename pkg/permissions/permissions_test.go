@@ -0,0 +1,97 @@
+package permissions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, p := range All {
+		got, err := Parse(string(p))
+		if err != nil || got != p {
+			t.Errorf("Parse(%q) = %q, %v, want %q", p, got, err, p)
+		}
+	}
+	for _, name := range []string{"", "Camera", "location", "camera "} {
+		if _, err := Parse(name); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", name)
+		}
+	}
+}
+
+func TestUsageDescriptionKey(t *testing.T) {
+	tests := []struct {
+		perm Permission
+		want string
+	}{
+		{Camera, "NSCameraUsageDescription"},
+		{Microphone, "NSMicrophoneUsageDescription"},
+		{LocalNetwork, "NSLocalNetworkUsageDescription"},
+		{Biometrics, "NSFaceIDUsageDescription"},
+		{ScreenRecording, ""}, // prompted by the OS, no key
+		{Notifications, ""},   // requested at runtime, no key
+	}
+	for _, tt := range tests {
+		if got := UsageDescriptionKey(tt.perm); got != tt.want {
+			t.Errorf("UsageDescriptionKey(%q) = %q, want %q", tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestMSIXCapability(t *testing.T) {
+	tests := []struct {
+		perm Permission
+		want string
+	}{
+		{Camera, "webcam"},
+		{Microphone, "microphone"},
+		{ScreenRecording, ""},
+		{LocalNetwork, ""},
+		{Biometrics, ""},
+		{Notifications, ""},
+	}
+	for _, tt := range tests {
+		if got := MSIXCapability(tt.perm); got != tt.want {
+			t.Errorf("MSIXCapability(%q) = %q, want %q", tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestUsageDescriptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		reasons map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"none", nil, map[string]string{}, false},
+		{
+			"plist keys",
+			map[string]string{"camera": "Scan badges", "microphone": "Calls", "biometrics": "Unlock"},
+			map[string]string{
+				"NSCameraUsageDescription":     "Scan badges",
+				"NSMicrophoneUsageDescription": "Calls",
+				"NSFaceIDUsageDescription":     "Unlock",
+			},
+			false,
+		},
+		{
+			"permissions without a key are dropped",
+			map[string]string{"screen-recording": "Screenshots", "notifications": "Alerts", "local-network": "Find printers"},
+			map[string]string{"NSLocalNetworkUsageDescription": "Find printers"},
+			false,
+		},
+		{"unknown permission", map[string]string{"camera": "x", "gps": "y"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UsageDescriptions(tt.reasons)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UsageDescriptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UsageDescriptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package permissions
+
+import (
+	"fmt"
+	"os/exec"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// consentStores are the CapabilityAccessManager keys for each permission.
+var consentStores = map[Permission]string{
+	Camera:     "webcam",
+	Microphone: "microphone",
+}
+
+var settingsURIs = map[Permission]string{
+	Camera:        "ms-settings:privacy-webcam",
+	Microphone:    "ms-settings:privacy-microphone",
+	Notifications: "ms-settings:notifications",
+}
+
+func check(opts Options, p Permission) Result {
+	switch p {
+	case ScreenRecording:
+		return Result{Status: NotRequired}
+	case LocalNetwork:
+		return Result{Status: NotRequired, Detail: "Windows Firewall asks when an app first listens on the network"}
+	case Biometrics:
+		return Result{Status: NotRequired, Detail: "Windows Hello asks every time; set it up in Sign-in options"}
+	case Notifications:
+		return checkToasts()
+	}
+
+	path := `Software\Microsoft\Windows\CurrentVersion\CapabilityAccessManager\ConsentStore\` + consentStores[p]
+	k, err := registry.OpenKey(registry.CURRENT_USER, path, registry.QUERY_VALUE)
+	if err != nil {
+		return Result{Status: Unknown, Detail: "consent store not found"}
+	}
+	defer k.Close()
+
+	value, _, err := k.GetStringValue("Value")
+	if err != nil {
+		return Result{Status: NotDetermined}
+	}
+	if value == "Deny" {
+		return Result{Status: Denied, Detail: "disabled for apps in Privacy settings"}
+	}
+	return Result{Status: Granted}
+}
+
+// checkToasts reads the switch that turns off notifications for every
+// app. Windows does not ask apps first; each can also be turned off on
+// its own in the same settings page.
+func checkToasts() Result {
+	k, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\PushNotifications`, registry.QUERY_VALUE)
+	if err != nil {
+		return Result{Status: Granted, Detail: "on by default"}
+	}
+	defer k.Close()
+	if v, _, err := k.GetIntegerValue("ToastEnabled"); err == nil && v == 0 {
+		return Result{Status: Denied, Detail: "notifications are turned off for all apps",
+			Fix: "Settings → System → Notifications (goup-util permissions open notifications)"}
+	}
+	return Result{Status: Granted}
+}
+
+func fixHint(p Permission) string {
+	return fmt.Sprintf("Settings → Privacy & security → %s (goup-util permissions open %s)", p, p)
+}
+
+func openSettings(p Permission) error {
+	uri, ok := settingsURIs[p]
+	if !ok {
+		return fmt.Errorf("no settings page for %s", p)
+	}
+	return exec.Command("rundll32", "url.dll,FileProtocolHandler", uri).Run()
+}
@@ -0,0 +1,249 @@
+// Package playstore is a small Google Play Developer API client for the
+// parts goup-util publishes: edits, store listings, listing images and
+// release notes.
+//
+// Requests are authenticated with a service account key that has been
+// granted access to the app in Play Console.
+package playstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/googleauth"
+)
+
+// Endpoints of the Android Publisher API.
+const (
+	BaseURL   = "https://androidpublisher.googleapis.com/androidpublisher/v3"
+	UploadURL = "https://androidpublisher.googleapis.com/upload/androidpublisher/v3"
+	scope     = "https://www.googleapis.com/auth/androidpublisher"
+)
+
+// Image types accepted by Edit.UploadImage.
+const (
+	FeatureGraphic = "featureGraphic"
+	TVBanner       = "tvBanner"
+	Icon           = "icon"
+)
+
+// Client calls the Play Developer API.
+type Client struct {
+	BaseURL   string
+	UploadURL string
+	HTTP      *http.Client
+
+	auth *googleauth.Source
+}
+
+// New returns a client for a service account JSON key.
+func New(serviceAccount []byte) (*Client, error) {
+	auth, err := googleauth.New(serviceAccount, scope)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{BaseURL: BaseURL, UploadURL: UploadURL, HTTP: &http.Client{Timeout: 2 * time.Minute}, auth: auth}, nil
+}
+
+func (c *Client) do(method, rawURL, contentType string, body io.Reader, out any) error {
+	token, err := c.auth.Token(c.HTTP)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("Google Play request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		path := strings.TrimPrefix(strings.TrimPrefix(rawURL, c.UploadURL), c.BaseURL)
+		if json.Unmarshal(data, &e) == nil && e.Error.Message != "" {
+			return fmt.Errorf("Google Play %s %s: %s (%s)", method, path, e.Error.Message, resp.Status)
+		}
+		return fmt.Errorf("Google Play %s %s: %s", method, path, resp.Status)
+	}
+	if out != nil && len(data) > 0 {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+func (c *Client) doJSON(method, rawURL string, in, out any) error {
+	if in == nil {
+		return c.do(method, rawURL, "", nil, out)
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(method, rawURL, "application/json", bytes.NewReader(data), out)
+}
+
+// Edit is an open set of changes to an app. Nothing is visible in Play
+// Console until Commit.
+type Edit struct {
+	c       *Client
+	Package string
+	ID      string
+}
+
+// BeginEdit opens an edit for the app with the given package name.
+func (c *Client) BeginEdit(packageName string) (*Edit, error) {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := c.doJSON(http.MethodPost, fmt.Sprintf("%s/applications/%s/edits", c.BaseURL, packageName), map[string]any{}, &resp); err != nil {
+		return nil, err
+	}
+	return &Edit{c: c, Package: packageName, ID: resp.ID}, nil
+}
+
+func (e *Edit) url(base, path string) string {
+	return fmt.Sprintf("%s/applications/%s/edits/%s%s", base, e.Package, e.ID, path)
+}
+
+// Listing is the localized store listing. Empty fields are left unchanged.
+type Listing struct {
+	Title            string `json:"title,omitempty"`
+	ShortDescription string `json:"shortDescription,omitempty"`
+	FullDescription  string `json:"fullDescription,omitempty"`
+	Video            string `json:"video,omitempty"`
+}
+
+// UpdateListing creates or updates the listing for language.
+func (e *Edit) UpdateListing(language string, l Listing) error {
+	return e.c.doJSON(http.MethodPatch, e.url(e.c.BaseURL, "/listings/"+language), l, nil)
+}
+
+// UploadImage replaces the images of imageType for language with the PNG
+// at path.
+func (e *Edit) UploadImage(language, imageType, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read image: %w", err)
+	}
+	images := e.url(e.c.BaseURL, fmt.Sprintf("/listings/%s/%s", language, imageType))
+	if err := e.c.do(http.MethodDelete, images, "", nil, nil); err != nil {
+		return err
+	}
+	upload := e.url(e.c.UploadURL, fmt.Sprintf("/listings/%s/%s?uploadType=media", language, imageType))
+	return e.c.do(http.MethodPost, upload, "image/png", bytes.NewReader(data), nil)
+}
+
+// Track is a release track (internal, alpha, beta, production, ...).
+type Track struct {
+	Track    string    `json:"track"`
+	Releases []Release `json:"releases"`
+}
+
+// Release is a release on a track.
+type Release struct {
+	Name         string          `json:"name,omitempty"`
+	VersionCodes []string        `json:"versionCodes,omitempty"`
+	Status       string          `json:"status,omitempty"`
+	UserFraction float64         `json:"userFraction,omitempty"`
+	ReleaseNotes []LocalizedText `json:"releaseNotes,omitempty"`
+}
+
+// LocalizedText is text for one language.
+type LocalizedText struct {
+	Language string `json:"language"`
+	Text     string `json:"text"`
+}
+
+// Track returns the releases on a track.
+func (e *Edit) Track(name string) (*Track, error) {
+	var t Track
+	if err := e.c.doJSON(http.MethodGet, e.url(e.c.BaseURL, "/tracks/"+name), nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UpdateTrack replaces the releases on a track.
+func (e *Edit) UpdateTrack(t *Track) error {
+	return e.c.doJSON(http.MethodPut, e.url(e.c.BaseURL, "/tracks/"+t.Track), t, nil)
+}
+
+// SetReleaseNotes sets the notes, by language, of the newest release on a
+// track. It returns false when the track has no release yet.
+func (e *Edit) SetReleaseNotes(track string, notes map[string]string) (bool, error) {
+	t, err := e.Track(track)
+	if err != nil {
+		return false, err
+	}
+	if len(t.Releases) == 0 {
+		return false, nil
+	}
+	r := &t.Releases[0]
+	merged := map[string]string{}
+	for _, n := range r.ReleaseNotes {
+		merged[n.Language] = n.Text
+	}
+	for lang, text := range notes {
+		merged[lang] = text
+	}
+	r.ReleaseNotes = r.ReleaseNotes[:0]
+	for _, lang := range slices.Sorted(maps.Keys(merged)) {
+		r.ReleaseNotes = append(r.ReleaseNotes, LocalizedText{Language: lang, Text: merged[lang]})
+	}
+	return true, e.UpdateTrack(t)
+}
+
+// Commit publishes the edit.
+func (e *Edit) Commit() error {
+	return e.c.doJSON(http.MethodPost, e.url(e.c.BaseURL, ":commit"), nil, nil)
+}
+
+// Abort discards the edit.
+func (e *Edit) Abort() error {
+	return e.c.do(http.MethodDelete, e.url(e.c.BaseURL, ""), "", nil, nil)
+}
+
+// Language maps a metadata locale to the code Play uses where they
+// differ from App Store Connect's.
+func Language(locale string) string {
+	if l, ok := languages[locale]; ok {
+		return l
+	}
+	return locale
+}
+
+var languages = map[string]string{
+	"ja":      "ja-JP",
+	"ko":      "ko-KR",
+	"zh-Hans": "zh-CN",
+	"zh-Hant": "zh-TW",
+	"de":      "de-DE",
+	"fr":      "fr-FR",
+	"es":      "es-ES",
+	"it":      "it-IT",
+	"nl":      "nl-NL",
+	"pt":      "pt-PT",
+	"ru":      "ru-RU",
+}
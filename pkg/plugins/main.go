@@ -0,0 +1,87 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/joeblew999/goup-util/pkg/schema"
+)
+
+// Main runs a plugin written in Go. In and Out are structs tagged like the
+// types in pkg/schema; their JSON Schemas become the manifest's input and
+// output, so the plugin shows up in 'goup-util plugins schema' without a
+// sidecar file:
+//
+//	type UploadInput struct {
+//		Artifact string `json:"artifact" jsonschema:"Path to the .apk or .ipa"`
+//		Track    string `json:"track,omitempty" jsonschema:"Release track"`
+//	}
+//
+//	func main() {
+//		plugins.Main(plugins.Manifest{Name: "store-upload", Short: "Upload to the internal store"},
+//			func(in UploadInput) (UploadOutput, error) { ... })
+//	}
+//
+// Input is read from --input (JSON, @file, or - for stdin); other
+// arguments are ignored. The result is printed as JSON.
+func Main[In, Out any](m Manifest, run func(In) (Out, error)) {
+	if err := serve(m, os.Args[1:], os.Stdin, os.Stdout, run); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", m.Name, err)
+		os.Exit(1)
+	}
+}
+
+func serve[In, Out any](m Manifest, args []string, stdin io.Reader, stdout io.Writer, run func(In) (Out, error)) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+
+	input := ""
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == ManifestFlag:
+			var err error
+			if m.Input, err = jsonschema.For[In](schema.SchemaOptions()); err != nil {
+				return err
+			}
+			if m.Output, err = jsonschema.For[Out](schema.SchemaOptions()); err != nil {
+				return err
+			}
+			return enc.Encode(m)
+		case args[i] == "--input" && i+1 < len(args):
+			i++
+			input = args[i]
+		case strings.HasPrefix(args[i], "--input="):
+			input = strings.TrimPrefix(args[i], "--input=")
+		}
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case input == "-":
+		data, err = io.ReadAll(stdin)
+	case strings.HasPrefix(input, "@"):
+		data, err = os.ReadFile(input[1:])
+	default:
+		data = []byte(input)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	var in In
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, &in); err != nil {
+			return fmt.Errorf("bad input: %w", err)
+		}
+	}
+	out, err := run(in)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(out)
+}
@@ -0,0 +1,198 @@
+// Package plugins lets teams add their own goup-util commands, kubectl
+// style: an executable named goup-util-<name> on the PATH (or in
+// $GOUP_PLUGIN_PATH) runs as 'goup-util <name>'. Built-in commands always
+// win over plugins of the same name.
+//
+// A plugin may describe itself with a manifest, either a sidecar file
+// (goup-util-<name>.json next to the executable) or by printing it when
+// run with --goup-manifest. The manifest's JSON Schemas are what 'goup-util
+// plugins schema' exports as tool definitions and what 'goup-util plugins
+// run' validates structured input against. Plugins written in Go get all
+// of this from Main.
+//
+// Plugins run with these environment variables:
+//
+//	GOUP_UTIL          path of the goup-util executable that started them
+//	GOUP_UTIL_VERSION  its version
+//	GOUP_PLUGIN_NAME   the command name
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/joeblew999/goup-util/pkg/self"
+)
+
+// Prefix starts every plugin executable name.
+const Prefix = "goup-util-"
+
+// PathEnv lists extra plugin directories, searched before $PATH.
+const PathEnv = "GOUP_PLUGIN_PATH"
+
+// ManifestFlag makes a plugin print its manifest and exit.
+const ManifestFlag = "--goup-manifest"
+
+// Manifest describes a plugin.
+type Manifest struct {
+	Name        string             `json:"name"`
+	Short       string             `json:"short,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Version     string             `json:"version,omitempty"`
+	Input       *jsonschema.Schema `json:"input,omitempty"`  // Structured input (plugins run --input)
+	Output      *jsonschema.Schema `json:"output,omitempty"` // JSON printed on stdout for structured runs
+}
+
+// Plugin is a discovered plugin executable.
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// Discover returns the plugins found, first match per name winning.
+func Discover() []Plugin {
+	// Release binaries (goup-util-darwin-arm64, ...) are not plugins.
+	skip := map[string]bool{}
+	for _, a := range self.SupportedArchitectures() {
+		skip[strings.TrimSuffix(a.BinaryName(), ".exe")] = true
+	}
+
+	seen := map[string]bool{}
+	var found []Plugin
+	for _, dir := range searchPath() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := executableName(e.Name())
+			if !ok || skip[name] || !strings.HasPrefix(name, Prefix) || len(name) == len(Prefix) {
+				continue
+			}
+			cmdName := strings.TrimPrefix(name, Prefix)
+			if seen[cmdName] {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if info, err := os.Stat(path); err != nil || info.IsDir() || !isExecutable(info) {
+				continue
+			}
+			seen[cmdName] = true
+			found = append(found, Plugin{Name: cmdName, Path: path})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+// Find returns the plugin for a command name.
+func Find(name string) (*Plugin, bool) {
+	for _, p := range Discover() {
+		if p.Name == name {
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
+func searchPath() []string {
+	dirs := filepath.SplitList(os.Getenv(PathEnv))
+	return append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+}
+
+// executableName strips the Windows executable extension.
+func executableName(file string) (string, bool) {
+	if runtime.GOOS != "windows" {
+		return file, !strings.HasSuffix(file, ".json")
+	}
+	for _, ext := range []string{".exe", ".cmd", ".bat"} {
+		if strings.EqualFold(filepath.Ext(file), ext) {
+			return strings.TrimSuffix(file, filepath.Ext(file)), true
+		}
+	}
+	return "", false
+}
+
+func isExecutable(info os.FileInfo) bool {
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// Manifest loads the sidecar manifest, else asks the plugin for one. A
+// plugin without either gets a minimal manifest.
+func (p *Plugin) Manifest() (*Manifest, error) {
+	base := p.Path
+	if runtime.GOOS == "windows" {
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, p.Path, ManifestFlag)
+		cmd.Env = append(os.Environ(), "GOUP_PLUGIN_NAME="+p.Name)
+		if data, err = cmd.Output(); err != nil || !json.Valid(data) {
+			return &Manifest{Name: p.Name}, nil
+		}
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("plugin %s: bad manifest: %w", p.Name, err)
+	}
+	if m.Name == "" {
+		m.Name = p.Name
+	}
+	return &m, nil
+}
+
+// Command returns the command running the plugin with args.
+func (p *Plugin) Command(version string, args ...string) *exec.Cmd {
+	cmd := exec.Command(p.Path, args...)
+	exe, _ := os.Executable()
+	cmd.Env = append(os.Environ(),
+		"GOUP_UTIL="+exe,
+		"GOUP_UTIL_VERSION="+version,
+		"GOUP_PLUGIN_NAME="+p.Name,
+	)
+	return cmd
+}
+
+// RunJSON validates input against the manifest's input schema, passes it
+// to the plugin (--input -, on stdin) and returns the JSON it prints.
+func (p *Plugin) RunJSON(version string, m *Manifest, input []byte) (json.RawMessage, error) {
+	var value any
+	if err := json.Unmarshal(input, &value); err != nil {
+		return nil, fmt.Errorf("input is not JSON: %w", err)
+	}
+	if m.Input != nil {
+		resolved, err := m.Input.Resolve(nil)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: bad input schema: %w", p.Name, err)
+		}
+		if err := resolved.Validate(value); err != nil {
+			return nil, fmt.Errorf("invalid input for %s: %w", p.Name, err)
+		}
+	}
+
+	cmd := p.Command(version, "--input", "-")
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", p.Name, err)
+	}
+	out = bytes.TrimSpace(out)
+	if !json.Valid(out) {
+		return nil, fmt.Errorf("plugin %s did not print JSON", p.Name)
+	}
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type greetInput struct {
+	Who string `json:"who" jsonschema:"Who to greet"`
+}
+
+type greetOutput struct {
+	Greeting string `json:"greeting"`
+}
+
+func greet(in greetInput) (greetOutput, error) {
+	return greetOutput{Greeting: "hello " + in.Who}, nil
+}
+
+func TestServe(t *testing.T) {
+	var out bytes.Buffer
+	if err := serve(Manifest{Name: "greet"}, []string{ManifestFlag}, nil, &out, greet); err != nil {
+		t.Fatal(err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Input == nil || m.Input.Properties["who"] == nil {
+		t.Errorf("manifest input schema = %+v", m.Input)
+	}
+
+	out.Reset()
+	if err := serve(Manifest{Name: "greet"}, []string{"--input", "-"}, strings.NewReader(`{"who": "gio"}`), &out, greet); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"hello gio"`) {
+		t.Errorf("output = %s", out.String())
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses executable bits")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"goup-util-upload", "goup-util-linux-amd64", "goup-util-upload.json", "other"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755)
+	}
+	t.Setenv(PathEnv, dir)
+	t.Setenv("PATH", "")
+
+	found := Discover()
+	if len(found) != 1 || found[0].Name != "upload" {
+		t.Errorf("Discover() = %+v, want only upload", found)
+	}
+}
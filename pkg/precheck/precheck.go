@@ -0,0 +1,417 @@
+// Package precheck checks a store build for what App Store and Google
+// Play review reject most often, so a missing usage description or a
+// too-old target SDK costs a minute instead of a review cycle:
+//
+//   - iOS and macOS: Info.plist usage descriptions for the APIs the app
+//     uses, export compliance, opaque iOS icons, 64-bit (arm64) code and
+//     a privacy manifest
+//   - Android: targetSdkVersion, a 64-bit library for every 32-bit ABI,
+//     16 KB aligned native libraries and no debuggable flag
+//
+// Checks stores always enforce are required; the rest are Optional.
+package precheck
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+	"html"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/artifact"
+)
+
+// Status is the outcome of one check.
+type Status string
+
+const (
+	Pass    Status = "pass"
+	Fail    Status = "fail"
+	Skipped Status = "skipped" // The artifact does not show it
+)
+
+// PlayMinTargetSdk is the lowest targetSdkVersion Google Play accepts for
+// new apps and updates (Android 15, required since August 2025).
+const PlayMinTargetSdk = 35
+
+// PlayPageSize is the page size 64-bit native libraries must be aligned
+// to for apps that target Android 15 or later.
+const PlayPageSize = 16 << 10
+
+// Finding is one check of the artifact.
+type Finding struct {
+	Check    string `json:"check"`
+	Status   Status `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Fix      string `json:"fix,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Report is the result of checking an artifact.
+type Report struct {
+	Artifact string    `json:"artifact"`
+	Platform string    `json:"platform"`
+	Findings []Finding `json:"findings"`
+}
+
+// OK reports whether every required check passed.
+func (r *Report) OK() bool {
+	for _, f := range r.Findings {
+		if f.Status == Fail && !f.Optional {
+			return false
+		}
+	}
+	return true
+}
+
+// Options tune the checks.
+type Options struct {
+	// UsageKeys are Info.plist usage descriptions the app needs whatever
+	// its binary shows, such as those 'audit capabilities' derives from
+	// the app's imports and app.json permissions.
+	UsageKeys []string
+	// MinTargetSdk overrides PlayMinTargetSdk.
+	MinTargetSdk int
+}
+
+// Check checks an artifact for platform: "ios", "macos" or "android".
+func Check(a *artifact.Artifact, platform string, opts Options) (*Report, error) {
+	r := &Report{Artifact: a.Path, Platform: platform, Findings: []Finding{}}
+	switch platform {
+	case "ios", "macos":
+		return r, checkApple(r, a, platform, opts)
+	case "android":
+		return r, checkAndroid(r, a, opts)
+	}
+	return nil, fmt.Errorf("invalid platform: %s. Valid platforms: [ios macos android]", platform)
+}
+
+func (r *Report) add(f Finding) {
+	r.Findings = append(r.Findings, f)
+}
+
+// usageAPIs maps Objective-C classes to the Info.plist usage description
+// App Store review requires when a binary references them. The class
+// references are plain strings in the Mach-O symbol table.
+var usageAPIs = []struct {
+	class, key string
+	iosOnly    bool
+}{
+	{"AVCaptureDevice", "NSCameraUsageDescription", false},
+	{"AVAudioRecorder", "NSMicrophoneUsageDescription", false},
+	{"CLLocationManager", "NSLocationWhenInUseUsageDescription", false},
+	{"PHPhotoLibrary", "NSPhotoLibraryUsageDescription", false},
+	{"CNContactStore", "NSContactsUsageDescription", false},
+	{"EKEventStore", "NSCalendarsUsageDescription", false},
+	{"CBCentralManager", "NSBluetoothAlwaysUsageDescription", false},
+	{"SFSpeechRecognizer", "NSSpeechRecognitionUsageDescription", false},
+	{"CMMotionManager", "NSMotionUsageDescription", true},
+	{"LAContext", "NSFaceIDUsageDescription", true},
+	{"ATTrackingManager", "NSUserTrackingUsageDescription", false},
+}
+
+func checkApple(r *Report, a *artifact.Artifact, platform string, opts Options) error {
+	manifest := a.Manifest()
+	if path.Base(manifest) != "Info.plist" {
+		return fmt.Errorf("%s has no Info.plist; is it an iOS or macOS build?", a.Path)
+	}
+	data, err := a.Read(manifest)
+	if err != nil {
+		return err
+	}
+	info := plist(data)
+	bundle := path.Dir(manifest) // Payload/X.app or Contents
+	exeDir := bundle
+	if platform == "macos" {
+		exeDir = path.Join(bundle, "MacOS")
+	}
+	var exe []byte
+	if name, _ := info.value("CFBundleExecutable"); name != "" {
+		exe, _ = a.Read(path.Join(exeDir, name))
+	}
+
+	// Usage descriptions
+	needed := map[string]string{}
+	for _, key := range opts.UsageKeys {
+		needed[key] = "the app's capabilities"
+	}
+	for _, api := range usageAPIs {
+		if api.iosOnly && platform != "ios" {
+			continue
+		}
+		if bytes.Contains(exe, []byte("_OBJC_CLASS_$_"+api.class)) {
+			needed[api.key] = "the binary uses " + api.class
+		}
+	}
+	keys := make([]string, 0, len(needed))
+	for key := range needed {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		f := Finding{Check: key, Status: Pass, Detail: "needed: " + needed[key]}
+		switch value, ok := info.value(key); {
+		case !ok:
+			f.Status, f.Fix = Fail, `add a reason for it to the permissions in app.json and rebuild with goup-util bundle `+platform
+		case strings.TrimSpace(value) == "":
+			f.Status, f.Fix = Fail, "write what the app uses it for; review rejects empty and vague descriptions"
+		}
+		r.add(f)
+	}
+	if len(keys) == 0 {
+		r.add(Finding{Check: "usage descriptions", Status: Pass, Detail: "none needed"})
+	}
+
+	// Export compliance
+	ec := Finding{Check: "ITSAppUsesNonExemptEncryption", Status: Pass, Optional: true}
+	if v, ok := info.value("ITSAppUsesNonExemptEncryption"); !ok {
+		ec.Status, ec.Detail = Fail, "export compliance not declared; App Store Connect holds the build for the encryption questions"
+		ec.Fix = `add "exportCompliance" to app.json and rebuild with goup-util bundle ` + platform
+	} else {
+		ec.Detail = v
+	}
+	r.add(ec)
+
+	// 64-bit code
+	r.add(checkArchs(exe, platform))
+
+	// Icons
+	if platform == "ios" {
+		r.add(checkIcons(a, bundle))
+	}
+
+	// Privacy manifest
+	privacy := Finding{Check: "PrivacyInfo.xcprivacy", Status: Pass, Optional: true}
+	if !a.Has(path.Join(bundle, "PrivacyInfo.xcprivacy")) && !a.Has(path.Join(bundle, "Resources", "PrivacyInfo.xcprivacy")) {
+		privacy.Status = Fail
+		privacy.Detail = "Go binaries call stat and fstat, which Apple lists as required-reason APIs; uploads get an ITMS-91053 warning"
+		privacy.Fix = "add a privacy manifest declaring NSPrivacyAccessedAPICategoryFileTimestamp with reason C617.1"
+	}
+	r.add(privacy)
+	return nil
+}
+
+// checkArchs requires arm64 code: iOS has run nothing else since iOS 11,
+// and the Mac App Store warns about Intel-only apps.
+func checkArchs(exe []byte, platform string) Finding {
+	f := Finding{Check: "64-bit (arm64)", Status: Pass}
+	var archs []string
+	if fat, err := macho.NewFatFile(bytes.NewReader(exe)); err == nil {
+		for _, arch := range fat.Arches {
+			archs = append(archs, arch.Cpu.String())
+		}
+	} else if thin, err := macho.NewFile(bytes.NewReader(exe)); err == nil {
+		archs = append(archs, thin.Cpu.String())
+	} else {
+		f.Status, f.Detail = Skipped, "main executable not found"
+		return f
+	}
+	f.Detail = strings.Join(archs, ", ")
+	if slices.Contains(archs, macho.CpuArm64.String()) {
+		if platform == "macos" && slices.ContainsFunc(archs, func(a string) bool { return a != macho.CpuArm64.String() && a != macho.CpuAmd64.String() }) {
+			f.Status, f.Fix = Fail, "build only arm64 and amd64"
+		}
+		return f
+	}
+	f.Status = Fail
+	f.Fix = "build for arm64 (goup-util build " + platform + ")"
+	if platform == "macos" {
+		f.Optional = true
+		f.Fix = "build a universal binary with an arm64 slice"
+	}
+	return f
+}
+
+// checkIcons finds app icons with an alpha channel. App Store Connect
+// rejects the upload when the large icon has one (ITMS-90717).
+func checkIcons(a *artifact.Artifact, bundle string) Finding {
+	f := Finding{Check: "app icon alpha", Status: Pass}
+	var icons, alpha []string
+	for _, file := range a.Files {
+		name := strings.TrimPrefix(file.Path, bundle+"/")
+		if strings.Contains(name, "/") || !strings.HasSuffix(name, ".png") ||
+			!(strings.HasPrefix(name, "AppIcon") || strings.HasPrefix(name, "Icon")) {
+			continue
+		}
+		icons = append(icons, name)
+		if data, err := a.Read(file.Path); err == nil && pngHasAlpha(data) {
+			alpha = append(alpha, name)
+		}
+	}
+	switch {
+	case len(icons) == 0:
+		f.Status, f.Detail = Skipped, "no loose icon PNGs (an asset catalog is not checked)"
+	case len(alpha) > 0:
+		f.Status, f.Detail = Fail, "alpha channel in "+strings.Join(alpha, ", ")
+		f.Fix = "flatten the source icon onto an opaque background and rebuild (goup-util icons validate shows it)"
+	default:
+		f.Detail = fmt.Sprintf("%d icons opaque", len(icons))
+	}
+	return f
+}
+
+// pngHasAlpha reports whether a PNG has an alpha channel or a
+// transparency chunk, whether or not any pixel is transparent.
+func pngHasAlpha(data []byte) bool {
+	const sig = "\x89PNG\r\n\x1a\n"
+	if len(data) < 33 || string(data[:8]) != sig {
+		return false
+	}
+	if colorType := data[25]; colorType == 4 || colorType == 6 {
+		return true
+	}
+	for off := 8; off+8 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[off:]))
+		switch string(data[off+4 : off+8]) {
+		case "tRNS":
+			return true
+		case "IDAT", "IEND":
+			return false
+		}
+		off += 12 + n
+	}
+	return false
+}
+
+// plistInfo reads values from an Info.plist. Binary plists keep ASCII keys
+// as plain bytes, so they show which keys exist but not their values.
+type plistInfo struct {
+	text   string
+	binary bool
+}
+
+func plist(data []byte) plistInfo {
+	return plistInfo{text: string(data), binary: bytes.HasPrefix(data, []byte("bplist"))}
+}
+
+// value returns a string or boolean value; binary plists return "?" for
+// keys they have.
+func (p plistInfo) value(key string) (string, bool) {
+	if p.binary {
+		if strings.Contains(p.text, key) {
+			return "?", true
+		}
+		return "", false
+	}
+	tag := "<key>" + key + "</key>"
+	i := strings.Index(p.text, tag)
+	if i < 0 {
+		return "", false
+	}
+	rest := strings.TrimSpace(p.text[i+len(tag):])
+	switch {
+	case strings.HasPrefix(rest, "<true/>"):
+		return "true", true
+	case strings.HasPrefix(rest, "<false/>"):
+		return "false", true
+	case strings.HasPrefix(rest, "<string/>"):
+		return "", true
+	}
+	if v, ok := strings.CutPrefix(rest, "<string>"); ok {
+		if end := strings.Index(v, "</string>"); end >= 0 {
+			return html.UnescapeString(v[:end]), true
+		}
+	}
+	return "", true
+}
+
+// abiPairs maps each 32-bit Android ABI to the 64-bit one Play requires
+// alongside it.
+var abiPairs = map[string]string{"armeabi-v7a": "arm64-v8a", "armeabi": "arm64-v8a", "x86": "x86_64"}
+
+func checkAndroid(r *Report, a *artifact.Artifact, opts Options) error {
+	manifest := a.Manifest()
+	if path.Base(manifest) != "AndroidManifest.xml" {
+		return fmt.Errorf("%s has no AndroidManifest.xml; is it an Android build?", a.Path)
+	}
+	minTarget := opts.MinTargetSdk
+	if minTarget == 0 {
+		minTarget = PlayMinTargetSdk
+	}
+
+	target := Finding{Check: "targetSdkVersion", Status: Pass}
+	value, ok := a.AndroidAttr(manifest, "uses-sdk", "targetSdkVersion")
+	if !ok {
+		// Without one, it defaults to minSdkVersion
+		value, ok = a.AndroidAttr(manifest, "uses-sdk", "minSdkVersion")
+	}
+	if sdk, err := strconv.Atoi(value); ok && err == nil {
+		target.Detail = value
+		if sdk < minTarget {
+			target.Status = Fail
+			target.Detail = fmt.Sprintf("%d; Google Play requires %d or later", sdk, minTarget)
+			target.Fix = fmt.Sprintf("rebuild against Android API %d or later (install the android-%d platform)", minTarget, minTarget)
+		}
+	} else {
+		target.Status, target.Detail = Skipped, "not found in the manifest"
+	}
+	r.add(target)
+
+	debuggable := Finding{Check: "android:debuggable", Status: Pass, Detail: "false"}
+	if v, _ := a.AndroidAttr(manifest, "application", "debuggable"); v == "true" {
+		debuggable.Status, debuggable.Detail = Fail, "true; Google Play rejects debuggable builds"
+		debuggable.Fix = "build a release APK or AAB"
+	}
+	r.add(debuggable)
+
+	abis := a.ABIs()
+	bits := Finding{Check: "64-bit ABIs", Status: Pass, Detail: strings.Join(abis, ", ")}
+	if len(abis) == 0 {
+		bits.Detail = "no native code"
+	}
+	for _, abi := range abis {
+		if want, ok := abiPairs[abi]; ok && !slices.Contains(abis, want) {
+			bits.Status = Fail
+			bits.Detail = fmt.Sprintf("%s has no %s counterpart", abi, want)
+			bits.Fix = "build for arm64 too (gogio -arch arm64,arm)"
+		}
+	}
+	r.add(bits)
+
+	r.add(checkPageSize(a))
+	return nil
+}
+
+// checkPageSize requires the 64-bit native libraries to load on devices
+// with 16 KB pages.
+func checkPageSize(a *artifact.Artifact) Finding {
+	f := Finding{Check: "16 KB page size", Status: Pass}
+	var checked int
+	var unaligned []string
+	for _, file := range a.Files {
+		dir := path.Base(path.Dir(file.Path))
+		if !strings.HasSuffix(file.Path, ".so") || (dir != "arm64-v8a" && dir != "x86_64") {
+			continue
+		}
+		data, err := a.Read(file.Path)
+		if err != nil {
+			continue
+		}
+		lib, err := elf.NewFile(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		checked++
+		for _, p := range lib.Progs {
+			if p.Type == elf.PT_LOAD && p.Align < PlayPageSize {
+				unaligned = append(unaligned, fmt.Sprintf("%s (%d)", path.Join(dir, path.Base(file.Path)), p.Align))
+				break
+			}
+		}
+	}
+	switch {
+	case checked == 0:
+		f.Status, f.Detail = Skipped, "no 64-bit native libraries"
+	case len(unaligned) > 0:
+		f.Status, f.Detail = Fail, "aligned below 16 KB: "+strings.Join(unaligned, ", ")
+		f.Fix = "link with -Wl,-z,max-page-size=16384 (the default from NDK r28)"
+	default:
+		f.Detail = fmt.Sprintf("%d libraries aligned", checked)
+	}
+	return f
+}
@@ -0,0 +1,153 @@
+package precheck
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeblew999/goup-util/pkg/artifact"
+)
+
+func open(t *testing.T, files map[string][]byte) *artifact.Artifact {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, err := artifact.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func icon(t *testing.T, alpha uint8) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < 16; i++ {
+		img.Set(i%4, i/4, color.NRGBA{R: 200, A: alpha})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// machO is a thin arm64 Mach-O header followed by data.
+func machO(data string) []byte {
+	le := binary.LittleEndian
+	b := le.AppendUint32(nil, 0xfeedfacf)
+	b = le.AppendUint32(b, 0x0100000c) // arm64
+	b = le.AppendUint32(b, 0)
+	b = le.AppendUint32(b, 2) // MH_EXECUTE
+	b = append(b, make([]byte, 16)...)
+	return append(b, data...)
+}
+
+func findings(r *Report) map[string]Finding {
+	m := map[string]Finding{}
+	for _, f := range r.Findings {
+		m[f.Check] = f
+	}
+	return m
+}
+
+func TestCheckIOS(t *testing.T) {
+	a := open(t, map[string][]byte{
+		"Payload/demo.app/Info.plist": []byte(`<plist><dict>
+<key>CFBundleExecutable</key><string>demo</string>
+<key>NSCameraUsageDescription</key><string>Scan QR codes</string>
+<key>NSMicrophoneUsageDescription</key><string></string>
+</dict></plist>`),
+		"Payload/demo.app/demo":                machO("_OBJC_CLASS_$_AVCaptureDevice _OBJC_CLASS_$_CLLocationManager"),
+		"Payload/demo.app/AppIcon60x60@2x.png": icon(t, 128),
+		"Payload/demo.app/AppIcon76x76.png":    icon(t, 255),
+	})
+	r, err := Check(a, "ios", Options{UsageKeys: []string{"NSMicrophoneUsageDescription"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := findings(r)
+	for check, want := range map[string]Status{
+		"NSCameraUsageDescription":            Pass,
+		"NSLocationWhenInUseUsageDescription": Fail,
+		"NSMicrophoneUsageDescription":        Fail, // Empty
+		"ITSAppUsesNonExemptEncryption":       Fail,
+		"64-bit (arm64)":                      Pass,
+		"app icon alpha":                      Fail,
+		"PrivacyInfo.xcprivacy":               Fail,
+	} {
+		if got[check].Status != want {
+			t.Errorf("%s = %s (%s), want %s", check, got[check].Status, got[check].Detail, want)
+		}
+	}
+	if got["app icon alpha"].Detail != "alpha channel in AppIcon60x60@2x.png" {
+		t.Errorf("app icon alpha detail = %q", got["app icon alpha"].Detail)
+	}
+	if r.OK() {
+		t.Error("OK with missing usage descriptions")
+	}
+}
+
+func TestCheckAndroid(t *testing.T) {
+	manifest := `<manifest xmlns:android="http://schemas.android.com/apk/res/android">
+  <uses-sdk android:minSdkVersion="21" android:targetSdkVersion="%s"/>
+  <application android:debuggable="%s"/>
+</manifest>`
+	tests := []struct {
+		name, target, debuggable string
+		libs                     []string
+		ok                       bool
+	}{
+		{"release", "35", "false", []string{"lib/arm64-v8a/libgio.so", "lib/armeabi-v7a/libgio.so"}, true},
+		{"old target", "34", "false", []string{"lib/arm64-v8a/libgio.so"}, false},
+		{"debuggable", "35", "true", nil, false},
+		{"32-bit only", "35", "false", []string{"lib/armeabi-v7a/libgio.so"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string][]byte{
+				"AndroidManifest.xml": []byte(fmt.Sprintf(manifest, tt.target, tt.debuggable)),
+			}
+			for _, lib := range tt.libs {
+				files[lib] = []byte("not an ELF")
+			}
+			r, err := Check(open(t, files), "android", Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.OK() != tt.ok {
+				t.Errorf("OK = %v, want %v: %+v", r.OK(), tt.ok, r.Findings)
+			}
+		})
+	}
+}
+
+func TestCheckWrongPlatform(t *testing.T) {
+	a := open(t, map[string][]byte{"AndroidManifest.xml": []byte("<manifest/>")})
+	if _, err := Check(a, "ios", Options{}); err == nil {
+		t.Error("checked an Android build as iOS")
+	}
+}
+
+func TestPNGHasAlpha(t *testing.T) {
+	if !pngHasAlpha(icon(t, 0)) {
+		t.Error("transparent icon has no alpha")
+	}
+	if pngHasAlpha(icon(t, 255)) {
+		t.Error("opaque icon has alpha")
+	}
+}
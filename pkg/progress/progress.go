@@ -0,0 +1,320 @@
+// Package progress tracks the phases of a build (icons → compile → link →
+// package → sign) and shows them while gogio runs: a spinner with the
+// percentage, elapsed time and ETA on a terminal, or one line per phase in
+// CI logs. The per-phase timings end up in the build's JSON output.
+//
+// gogio does not report its own phases, so they are inferred from the
+// commands it echoes with -x (go build, lipo, d8, apksigner, codesign, ...).
+// The echoed commands are hidden; all other output passes through.
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Build phases, in order.
+const (
+	Icons   = "icons"
+	Compile = "compile"
+	Link    = "link"
+	Package = "package"
+	Sign    = "sign"
+)
+
+// Phases lists the build phases in order.
+var Phases = []string{Icons, Compile, Link, Package, Sign}
+
+// weights is each phase's rough share of a build, in percent.
+var weights = map[string]int{Icons: 5, Compile: 65, Link: 10, Package: 12, Sign: 8}
+
+// Timing is how long one phase took.
+type Timing struct {
+	Phase    string        `json:"phase"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Tracker follows one build. A nil *Tracker ignores every call, so build
+// code can use it unconditionally.
+type Tracker struct {
+	label    string
+	estimate time.Duration
+	out      io.Writer
+	tty      bool
+
+	mu         sync.Mutex
+	started    time.Time
+	phase      string
+	phaseStart time.Time
+	timings    []Timing
+	writers    []*lineWriter
+	drawn      bool
+	stop       chan struct{}
+	stopped    chan struct{}
+}
+
+// New returns a tracker for label (e.g. "myapp android") that reports on
+// stderr. estimate is the expected total duration, 0 if unknown.
+func New(label string, estimate time.Duration) *Tracker {
+	tty := term.IsTerminal(int(os.Stderr.Fd())) && os.Getenv("CI") == ""
+	return newTracker(label, estimate, os.Stderr, tty)
+}
+
+func newTracker(label string, estimate time.Duration, out io.Writer, tty bool) *Tracker {
+	return &Tracker{label: label, estimate: estimate, out: out, tty: tty, started: time.Now()}
+}
+
+// Phase ends the current phase and starts name. Phases only move forward:
+// starting the current or an earlier phase does nothing.
+func (t *Tracker) Phase(name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if index(name) <= index(t.phase) {
+		return
+	}
+	now := time.Now()
+	t.endPhase(now)
+	t.phase, t.phaseStart = name, now
+	if !t.tty {
+		fmt.Fprintf(t.out, "▶ %s: %s (%d%%)\n", t.label, name, t.percent(now))
+		return
+	}
+	if t.stop == nil {
+		t.stop, t.stopped = make(chan struct{}), make(chan struct{})
+		go t.spin()
+	}
+}
+
+// Finish ends the last phase, prints a summary when any phase ran, and
+// returns the timings.
+func (t *Tracker) Finish(err error) []Timing {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	stop, stopped := t.stop, t.stopped
+	t.stop = nil
+	t.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endPhase(time.Now())
+	t.phase = ""
+	for _, lw := range t.writers {
+		if len(lw.buf) > 0 {
+			t.clear()
+			lw.w.Write(lw.buf)
+			lw.buf = nil
+		}
+	}
+	if len(t.timings) > 0 {
+		t.clear()
+		mark := "⏱ "
+		if err != nil {
+			mark = "❌"
+		}
+		parts := make([]string, len(t.timings))
+		for i, tm := range t.timings {
+			parts[i] = fmt.Sprintf("%s %s", tm.Phase, round(tm.Duration))
+		}
+		fmt.Fprintf(t.out, "%s %s: %s — total %s\n", mark, t.label, strings.Join(parts, " · "), round(time.Since(t.started)))
+	}
+	return append([]Timing(nil), t.timings...)
+}
+
+// Total is the time since the tracker was created.
+func (t *Tracker) Total() time.Duration {
+	if t == nil {
+		return 0
+	}
+	return time.Since(t.started)
+}
+
+// Writer returns a writer for the build tool's output that advances the
+// phase on echoed commands, hides them, and keeps the spinner intact.
+func (t *Tracker) Writer(w io.Writer) io.Writer {
+	if t == nil {
+		return w
+	}
+	lw := &lineWriter{t: t, w: w}
+	t.mu.Lock()
+	t.writers = append(t.writers, lw)
+	t.mu.Unlock()
+	return lw
+}
+
+func (t *Tracker) endPhase(now time.Time) {
+	if t.phase != "" {
+		t.timings = append(t.timings, Timing{Phase: t.phase, Duration: now.Sub(t.phaseStart)})
+	}
+}
+
+// percent combines finished phases with the progress of the current one,
+// which is only known when there is an estimate. It stays below 100 until
+// Finish.
+func (t *Tracker) percent(now time.Time) int {
+	done := 0
+	for _, p := range Phases {
+		if p == t.phase {
+			break
+		}
+		done += weights[p]
+	}
+	if t.estimate > 0 && t.phase != "" {
+		expected := t.estimate * time.Duration(weights[t.phase]) / 100
+		if expected > 0 {
+			frac := float64(now.Sub(t.phaseStart)) / float64(expected)
+			done += int(min(frac, 0.95) * float64(weights[t.phase]))
+		}
+	}
+	return min(done, 99)
+}
+
+var frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+func (t *Tracker) spin() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		t.mu.Lock()
+		t.draw(frames[i%len(frames)])
+		stop := t.stop
+		t.mu.Unlock()
+		select {
+		case <-stop:
+			t.mu.Lock()
+			t.clear()
+			t.mu.Unlock()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// draw and clear are called with mu held.
+func (t *Tracker) draw(frame string) {
+	if t.phase == "" {
+		return
+	}
+	now := time.Now()
+	line := fmt.Sprintf("%s %3d%% %s: %s %s · %s", frame, t.percent(now), t.label, t.phase,
+		clock(now.Sub(t.phaseStart)), clock(now.Sub(t.started)))
+	if t.estimate > 0 {
+		if left := t.estimate - now.Sub(t.started); left > 0 {
+			line += " · ETA " + clock(left)
+		} else {
+			line += " · ETA soon"
+		}
+	}
+	fmt.Fprint(t.out, "\r\033[K"+line)
+	t.drawn = true
+}
+
+func (t *Tracker) clear() {
+	if t.drawn {
+		fmt.Fprint(t.out, "\r\033[K")
+		t.drawn = false
+	}
+}
+
+// lineWriter passes output through line by line.
+type lineWriter struct {
+	t   *Tracker
+	w   io.Writer
+	buf []byte
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.buf = append(lw.buf, p...)
+	for {
+		i := bytes.IndexByte(lw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(lw.buf[:i+1])
+		lw.buf = lw.buf[i+1:]
+		if phase := Detect(line); phase != "" {
+			lw.t.Phase(phase)
+			continue
+		}
+		lw.t.mu.Lock()
+		lw.t.clear()
+		_, err := io.WriteString(lw.w, line)
+		lw.t.mu.Unlock()
+		if err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Detect returns the phase an echoed build command belongs to, or "" for
+// ordinary output.
+func Detect(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	tool := strings.TrimSuffix(filepath.Base(fields[0]), ".exe")
+	arg := ""
+	if len(fields) > 1 {
+		arg = fields[1]
+	}
+	switch tool {
+	case "go":
+		if arg == "build" {
+			return Compile
+		}
+	case "javac", "clang", "cc", "gcc":
+		return Compile
+	case "lipo", "ld":
+		return Link
+	case "aapt2":
+		if arg == "link" {
+			return Link
+		}
+		return Package
+	case "d8", "zipalign", "bundletool", "actool", "ibtool", "plutil", "makeappx":
+		return Package
+	case "apksigner", "jarsigner", "codesign", "signtool", "osslsigncode":
+		return Sign
+	}
+	return ""
+}
+
+func index(phase string) int {
+	for i, p := range Phases {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+func round(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(100 * time.Millisecond)
+}
+
+func clock(d time.Duration) string {
+	s := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
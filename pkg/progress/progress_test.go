@@ -0,0 +1,51 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTrackerFollowsGogio(t *testing.T) {
+	var log, out bytes.Buffer
+	tr := newTracker("app android", 0, &log, false)
+	tr.Phase(Icons)
+	w := tr.Writer(&out)
+
+	// What gogio -x prints for an Android build, interleaved with output.
+	fmt.Fprint(w, "/usr/local/go/bin/go build -buildmode=c-shared -o /tmp/x/libgio.so .\n")
+	fmt.Fprint(w, "# warning from cgo\n")
+	fmt.Fprint(w, "javac -source 8 Gio.java\n/sdk/build-tools/34.0.0/aapt2 link -o res.apk\n")
+	fmt.Fprint(w, "go build -o second .\n") // Later compiles do not move back
+	fmt.Fprint(w, "d8 --min-api 21 classes\nzipalign 4 in.apk out.apk\n")
+	fmt.Fprint(w, "apksigner sign --ks key.jks out.apk\nunterminated")
+
+	timings := tr.Finish(errors.New("boom"))
+	var phases []string
+	for _, tm := range timings {
+		phases = append(phases, tm.Phase)
+	}
+	if got := strings.Join(phases, ","); got != "icons,compile,link,package,sign" {
+		t.Errorf("phases = %s", got)
+	}
+	if got := out.String(); got != "# warning from cgo\nunterminated" {
+		t.Errorf("passed-through output = %q", got)
+	}
+	if !strings.Contains(log.String(), "▶ app android: sign") || !strings.Contains(log.String(), "❌ app android: icons") {
+		t.Errorf("log:\n%s", log.String())
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	var tr *Tracker
+	tr.Phase(Compile)
+	var out bytes.Buffer
+	if w := tr.Writer(&out); w != &out {
+		t.Error("nil tracker should not wrap the writer")
+	}
+	if tr.Finish(nil) != nil || tr.Total() != 0 {
+		t.Error("nil tracker reported timings")
+	}
+}
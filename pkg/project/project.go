@@ -130,6 +130,8 @@ func (pp *ProjectPaths) GetIconOutputPath(platform string) string {
 		return pp.IOSIcons
 	case "windows", "windows-msix", "windows-ico":
 		return pp.WindowsIcons
+	case "watchos", "tvos", "visionos":
+		return filepath.Join(pp.Root, constants.BuildDir, platform, "Assets.xcassets")
 	default:
 		return pp.Output
 	}
@@ -0,0 +1,337 @@
+// Package projectcheck checks one app directory for problems that builds
+// trip over later or only warn about: an untidy go.mod, a missing source
+// icon, a webview app without a url, a module its go.work does not list,
+// build cache entries for outputs that are gone, and unsigned bundles in
+// .dist. The mechanical ones can be repaired.
+package projectcheck
+
+import (
+	"bytes"
+	"debug/pe"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/appconfig"
+	"github.com/joeblew999/goup-util/pkg/artifact"
+	"github.com/joeblew999/goup-util/pkg/buildcache"
+	"github.com/joeblew999/goup-util/pkg/capabilities"
+	"github.com/joeblew999/goup-util/pkg/constants"
+	"github.com/joeblew999/goup-util/pkg/icons"
+	"github.com/joeblew999/goup-util/pkg/workspace"
+)
+
+// Check names.
+const (
+	GoMod      = "go.mod"
+	SourceIcon = "icon-source.png"
+	AppURL     = "app.json url"
+	Workspace  = "go.work"
+	BuildCache = "build cache"
+	Signatures = "signed bundles"
+)
+
+// Check is one line of the project report.
+type Check struct {
+	Name     string `json:"name"`
+	OK       bool   `json:"ok"`
+	Required bool   `json:"required"` // Builds fail without it
+	Detail   string `json:"detail,omitempty"`
+	Fix      string `json:"fix,omitempty"`
+	AutoFix  bool   `json:"autoFix,omitempty"` // Repair can fix it
+}
+
+// Project is the app directory to check.
+type Project struct {
+	Dir   string // Absolute
+	Name  string // The name builds are recorded under
+	Cache *buildcache.Cache
+}
+
+// Run checks the project.
+func Run(p Project) []Check {
+	checks := []Check{checkGoMod(p), checkSourceIcon(p)}
+	if c, ok := checkAppURL(p); ok {
+		checks = append(checks, c)
+	}
+	return append(checks, checkWorkspace(p), checkBuildCache(p), checkSignatures(p))
+}
+
+// Healthy reports whether every required check passed.
+func Healthy(checks []Check) bool {
+	for _, c := range checks {
+		if c.Required && !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Repair applies the fix of a failed check that has AutoFix set.
+func Repair(p Project, c Check) error {
+	switch c.Name {
+	case GoMod:
+		cmd := exec.Command("go", "mod", "tidy")
+		cmd.Dir = p.Dir
+		cmd.Env = append(os.Environ(), "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("go mod tidy: %w\n%s", err, out)
+		}
+		return nil
+	case SourceIcon:
+		return icons.GenerateTestIcon(filepath.Join(p.Dir, SourceIcon))
+	case Workspace:
+		ws, err := findWorkspace(p.Dir)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(filepath.Dir(ws.FilePath), p.Dir)
+		if err != nil {
+			return err
+		}
+		return ws.AddModule("./"+filepath.ToSlash(rel), true)
+	case BuildCache:
+		for _, state := range staleBuilds(p) {
+			p.Cache.Remove(state.Project, state.Platform)
+		}
+		return p.Cache.Save()
+	}
+	return fmt.Errorf("%s cannot be fixed automatically", c.Name)
+}
+
+// checkGoMod runs go mod tidy -diff, which changes nothing.
+func checkGoMod(p Project) Check {
+	c := Check{Name: GoMod, Required: true}
+	if _, err := os.Stat(filepath.Join(p.Dir, "go.mod")); err != nil {
+		c.Detail, c.Fix = "not found", "go mod init <module path>"
+		return c
+	}
+	cmd := exec.Command("go", "mod", "tidy", "-diff")
+	cmd.Dir = p.Dir
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	switch {
+	case err == nil:
+		c.OK, c.Detail = true, "tidy"
+	case strings.Contains(stderr.String(), "-diff"):
+		c.OK, c.Detail = true, "not checked; go mod tidy -diff needs Go 1.23"
+	case stdout.Len() > 0:
+		changed := 0
+		for _, line := range strings.Split(stdout.String(), "\n") {
+			if (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")) &&
+				!strings.HasPrefix(line, "+++") && !strings.HasPrefix(line, "---") {
+				changed++
+			}
+		}
+		c.Detail = fmt.Sprintf("not tidy (%d lines of go.mod and go.sum would change)", changed)
+		c.Fix, c.AutoFix = "go mod tidy", true
+	default:
+		c.OK = true // A network or proxy problem, not the project's
+		c.Detail = "not checked: " + firstError(stderr.String())
+	}
+	return c
+}
+
+func checkSourceIcon(p Project) Check {
+	c := Check{Name: SourceIcon}
+	iconPath := filepath.Join(p.Dir, SourceIcon)
+	if _, err := os.Stat(iconPath); err != nil {
+		c.Detail = "missing; builds use a blue placeholder"
+		c.Fix, c.AutoFix = "add a 1024x1024 PNG, or create the placeholder with --fix", true
+		return c
+	}
+	report, err := icons.Validate(iconPath)
+	if err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	if !report.OK() {
+		c.Detail, c.Fix = "not usable for every store", "goup-util icons validate "+p.Dir
+		return c
+	}
+	c.OK, c.Detail = true, fmt.Sprintf("%dx%d %s", report.Width, report.Height, report.Format)
+	return c
+}
+
+// checkAppURL applies to apps that use a webview: the webviewer shell
+// and apps like it load app.json's url.
+func checkAppURL(p Project) (Check, bool) {
+	caps, err := capabilities.Detect(p.Dir, appconfig.LoadOrDefault(p.Dir))
+	if err != nil || !usesWebView(caps) {
+		return Check{}, false
+	}
+	cfg, err := appconfig.Load(p.Dir)
+	if err != nil {
+		return Check{Name: AppURL, Detail: err.Error(), Fix: "add " + appconfig.ConfigFileName + " with a url"}, true
+	}
+	c := Check{Name: AppURL, OK: cfg.URL != "", Detail: cfg.URL}
+	if !c.OK {
+		c.Detail = "not set; the webview has no page to load"
+		c.Fix = `add "url": "https://..." to ` + appconfig.ConfigFileName
+	}
+	return c, true
+}
+
+func usesWebView(caps []capabilities.Capability) bool {
+	for _, c := range caps {
+		if c.Name == capabilities.WebView {
+			return true
+		}
+	}
+	return false
+}
+
+// findWorkspace finds the go.work above dir, not the one for the
+// current directory that 'go env GOWORK' reports.
+func findWorkspace(dir string) (*workspace.Workspace, error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.work")); err == nil {
+			return workspace.Load(filepath.Join(d, "go.work"))
+		}
+		if filepath.Dir(d) == d {
+			return nil, fmt.Errorf("no go.work above %s", dir)
+		}
+	}
+}
+
+func checkWorkspace(p Project) Check {
+	c := Check{Name: Workspace, Required: true}
+	ws, err := findWorkspace(p.Dir)
+	if err != nil {
+		c.OK, c.Detail = true, "no go.work; the module builds on its own"
+		return c
+	}
+	root := filepath.Dir(ws.FilePath)
+	for _, m := range ws.Modules {
+		if filepath.Clean(filepath.Join(root, filepath.FromSlash(m))) == p.Dir {
+			c.OK, c.Detail = true, "listed in "+ws.FilePath
+			return c
+		}
+	}
+	c.Detail = "not listed in " + ws.FilePath + "; go commands in the workspace refuse it"
+	c.Fix, c.AutoFix = "go work use", true
+	return c
+}
+
+// staleBuilds are recorded builds whose output is gone or belongs to
+// another directory with the same app name.
+func staleBuilds(p Project) []*buildcache.BuildState {
+	var stale []*buildcache.BuildState
+	for _, state := range p.Cache.States(p.Name) {
+		_, err := os.Stat(state.OutputPath)
+		if err != nil || !strings.HasPrefix(state.OutputPath, p.Dir+string(filepath.Separator)) {
+			stale = append(stale, state)
+		}
+	}
+	return stale
+}
+
+func checkBuildCache(p Project) Check {
+	c := Check{Name: BuildCache, OK: true}
+	states := p.Cache.States(p.Name)
+	stale := staleBuilds(p)
+	if len(stale) == 0 {
+		c.Detail = fmt.Sprintf("%d builds recorded", len(states))
+		return c
+	}
+	var platforms []string
+	for _, state := range stale {
+		platforms = append(platforms, state.Platform)
+	}
+	c.OK = false
+	c.Detail = "entries for missing or moved outputs: " + strings.Join(platforms, ", ")
+	c.Fix, c.AutoFix = "forget them, so the next build does not trust them", true
+	return c
+}
+
+func checkSignatures(p Project) Check {
+	c := Check{Name: Signatures, OK: true}
+	entries, err := os.ReadDir(filepath.Join(p.Dir, constants.DistDir))
+	if err != nil {
+		c.Detail = "no " + constants.DistDir
+		return c
+	}
+	var checked int
+	var unsigned []string
+	for _, e := range entries {
+		signed, ok := isSigned(filepath.Join(p.Dir, constants.DistDir, e.Name()))
+		if !ok {
+			continue
+		}
+		checked++
+		if !signed {
+			unsigned = append(unsigned, e.Name())
+		}
+	}
+	switch {
+	case checked == 0:
+		c.Detail = "no bundles in " + constants.DistDir
+	case len(unsigned) > 0:
+		c.OK = false
+		c.Detail = "unsigned: " + strings.Join(unsigned, ", ")
+		c.Fix = "set the signing secrets (goup-util secrets) and run goup-util bundle again"
+	default:
+		c.Detail = fmt.Sprintf("%d bundles signed", checked)
+	}
+	return c
+}
+
+// isSigned reports whether a bundle carries a signature; ok is false for
+// files it does not know how to check.
+func isSigned(file string) (signed, ok bool) {
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".app", ".ipa", ".apk", ".aab", ".msix", ".appx":
+		a, err := artifact.Open(file)
+		if err != nil {
+			return false, false
+		}
+		for _, f := range a.Files {
+			name := f.Path
+			switch {
+			case path.Base(name) == "CodeResources" && path.Base(path.Dir(name)) == "_CodeSignature":
+				return true, true
+			case name == "AppxSignature.p7x":
+				return true, true
+			case strings.HasPrefix(name, "META-INF/") && strings.Count(name, "/") == 1 &&
+				(strings.HasSuffix(name, ".RSA") || strings.HasSuffix(name, ".EC") || strings.HasSuffix(name, ".DSA")):
+				return true, true
+			}
+		}
+		if ext == ".apk" {
+			// Signature scheme v2 and later leave META-INF alone
+			data, err := os.ReadFile(file)
+			return err == nil && bytes.Contains(data, []byte("APK Sig Block 42")), true
+		}
+		return false, true
+	case ".exe":
+		f, err := pe.Open(file)
+		if err != nil {
+			return false, false
+		}
+		defer f.Close()
+		var dirs []pe.DataDirectory
+		switch h := f.OptionalHeader.(type) {
+		case *pe.OptionalHeader64:
+			dirs = h.DataDirectory[:min(int(h.NumberOfRvaAndSizes), len(h.DataDirectory))]
+		case *pe.OptionalHeader32:
+			dirs = h.DataDirectory[:min(int(h.NumberOfRvaAndSizes), len(h.DataDirectory))]
+		}
+		return len(dirs) > pe.IMAGE_DIRECTORY_ENTRY_SECURITY && dirs[pe.IMAGE_DIRECTORY_ENTRY_SECURITY].Size > 0, true
+	}
+	return false, false
+}
+
+// firstError is the first error go printed, with its indented
+// continuation line ("go: x imports\n\ty: reason").
+func firstError(s string) string {
+	line, rest, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if next, _, _ := strings.Cut(rest, "\n"); strings.HasPrefix(next, "\t") {
+		line += " " + strings.TrimSpace(next)
+	}
+	return line
+}
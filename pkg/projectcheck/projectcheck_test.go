@@ -0,0 +1,134 @@
+package projectcheck
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeblew999/goup-util/pkg/buildcache"
+)
+
+func write(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// project is a dependency-free app in a workspace that does not list it.
+func project(t *testing.T) Project {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "examples", "demo")
+	write(t, filepath.Join(root, "go.work"), "go 1.22\n\nuse ./other\n")
+	write(t, filepath.Join(root, "other", "go.mod"), "module example.com/other\n\ngo 1.22\n")
+	write(t, filepath.Join(dir, "go.mod"), "module example.com/demo\n\ngo 1.22\n")
+	write(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() {}\n")
+	cache, err := buildcache.NewCache(filepath.Join(root, "cache.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Project{Dir: dir, Name: "demo", Cache: cache}
+}
+
+func byName(checks []Check) map[string]Check {
+	m := map[string]Check{}
+	for _, c := range checks {
+		m[c.Name] = c
+	}
+	return m
+}
+
+func TestWorkspace(t *testing.T) {
+	p := project(t)
+	c := checkWorkspace(p)
+	if c.OK || !c.AutoFix {
+		t.Fatalf("unlisted module: %+v", c)
+	}
+	if err := Repair(p, c); err != nil {
+		t.Fatal(err)
+	}
+	if c := checkWorkspace(p); !c.OK {
+		t.Errorf("after repair: %+v", c)
+	}
+}
+
+func TestBuildCache(t *testing.T) {
+	p := project(t)
+	output := filepath.Join(p.Dir, ".bin", "demo.app")
+	write(t, output, "")
+	p.Cache.SetState(&buildcache.BuildState{Project: "demo", Platform: "macos", OutputPath: output})
+	p.Cache.SetState(&buildcache.BuildState{Project: "demo", Platform: "ios", OutputPath: filepath.Join(p.Dir, ".bin", "gone.app")})
+	p.Cache.SetState(&buildcache.BuildState{Project: "other", Platform: "ios", OutputPath: "/elsewhere"})
+
+	c := checkBuildCache(p)
+	if c.OK || c.Detail != "entries for missing or moved outputs: ios" {
+		t.Fatalf("stale entry: %+v", c)
+	}
+	if err := Repair(p, c); err != nil {
+		t.Fatal(err)
+	}
+	if c := checkBuildCache(p); !c.OK {
+		t.Errorf("after repair: %+v", c)
+	}
+	if p.Cache.GetState("other", "ios") == nil {
+		t.Error("repair removed another project's build")
+	}
+}
+
+func TestSourceIcon(t *testing.T) {
+	p := project(t)
+	c := checkSourceIcon(p)
+	if c.OK || !c.AutoFix {
+		t.Fatalf("missing icon: %+v", c)
+	}
+	if err := Repair(p, c); err != nil {
+		t.Fatal(err)
+	}
+	if c := checkSourceIcon(p); !c.OK {
+		t.Errorf("after repair: %+v", c)
+	}
+}
+
+func TestSignatures(t *testing.T) {
+	p := project(t)
+	dist := filepath.Join(p.Dir, ".dist")
+	write(t, filepath.Join(dist, "demo.app", "Contents", "_CodeSignature", "CodeResources"), "")
+	write(t, filepath.Join(dist, "notes.txt"), "")
+
+	f, err := os.Create(filepath.Join(dist, "demo.apk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	if _, err := zw.Create("AndroidManifest.xml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	c := checkSignatures(p)
+	if c.OK || c.Detail != "unsigned: demo.apk" {
+		t.Errorf("checkSignatures = %+v", c)
+	}
+}
+
+func TestRun(t *testing.T) {
+	p := project(t)
+	checks := byName(Run(p))
+	if c := checks[GoMod]; !c.OK {
+		t.Errorf("go.mod: %+v", c)
+	}
+	if _, ok := checks[AppURL]; ok {
+		t.Error("app.json url checked for an app without a webview")
+	}
+	if Healthy(Run(p)) {
+		t.Error("healthy with the module missing from go.work")
+	}
+}
@@ -0,0 +1,246 @@
+// Package release builds, bundles and publishes several apps in lock
+// step, for repositories holding many webviewer shells that ship
+// together. The apps are listed in an apps.json:
+//
+//	{
+//	  "version": "1.4.0",
+//	  "platforms": ["macos", "android"],
+//	  "apps": [
+//	    {"name": "acme", "dir": "shells/acme", "bundleId": "com.acme.portal", "publish": true},
+//	    {"name": "globex", "dir": "shells/globex", "bundleId": "com.globex.hub", "platforms": ["macos"]}
+//	  ]
+//	}
+//
+// Each app keeps its own directory, app.json and bundle ID; the version
+// (and build number) is shared. A failing app does not stop the others.
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Stages run for each app and platform, in order.
+const (
+	Build   = "build"
+	Bundle  = "bundle"
+	Publish = "publish"
+)
+
+// bundlePlatforms are those "goup-util bundle" packages; the others ship
+// the build output.
+var bundlePlatforms = map[string]bool{"macos": true, "windows": true}
+
+// App is one app in apps.json.
+type App struct {
+	Name        string   `json:"name"`
+	Dir         string   `json:"dir"` // Relative to apps.json
+	BundleID    string   `json:"bundleId,omitempty"`
+	Platforms   []string `json:"platforms,omitempty"` // Default: the manifest's
+	Publish     bool     `json:"publish,omitempty"`   // Push store metadata
+	BuildFlags  []string `json:"buildFlags,omitempty"`
+	BundleFlags []string `json:"bundleFlags,omitempty"`
+}
+
+// Manifest is apps.json.
+type Manifest struct {
+	Version     string   `json:"version"`
+	BuildNumber string   `json:"buildNumber,omitempty"` // A number or "auto"
+	Platforms   []string `json:"platforms"`
+	Apps        []App    `json:"apps"`
+
+	Path string `json:"-"`
+}
+
+// Load reads and checks an apps.json.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	m.Path = path
+	if len(m.Apps) == 0 {
+		return nil, fmt.Errorf("%s lists no apps", path)
+	}
+	seen := map[string]bool{}
+	for i, a := range m.Apps {
+		if a.Dir == "" {
+			return nil, fmt.Errorf("%s: app %d has no dir", path, i+1)
+		}
+		if a.Name == "" {
+			m.Apps[i].Name = filepath.Base(a.Dir)
+		}
+		if seen[m.Apps[i].Name] {
+			return nil, fmt.Errorf("%s: app %q is listed twice", path, m.Apps[i].Name)
+		}
+		seen[m.Apps[i].Name] = true
+		if len(m.Apps[i].platforms(&m)) == 0 {
+			return nil, fmt.Errorf("%s: app %q has no platforms", path, m.Apps[i].Name)
+		}
+	}
+	return &m, nil
+}
+
+func (a *App) platforms(m *Manifest) []string {
+	if len(a.Platforms) > 0 {
+		return a.Platforms
+	}
+	return m.Platforms
+}
+
+// AppDir is the app's directory resolved against apps.json.
+func (m *Manifest) AppDir(a App) string {
+	if filepath.IsAbs(a.Dir) {
+		return a.Dir
+	}
+	return filepath.Join(filepath.Dir(m.Path), a.Dir)
+}
+
+// Step is one goup-util command of the release.
+type Step struct {
+	App      string   `json:"app"`
+	Platform string   `json:"platform"`
+	Stage    string   `json:"stage"`
+	Args     []string `json:"args"`
+}
+
+// Plan returns the commands releasing app: build, then bundle where the
+// platform has one, then publish when the app asks for it.
+func (m *Manifest) Plan(a App, skipPublish bool) []Step {
+	dir := m.AppDir(a)
+	var steps []Step
+	for _, p := range a.platforms(m) {
+		build := []string{"build", p, dir, "--force"}
+		if m.Version != "" {
+			build = append(build, "--version", m.Version)
+		}
+		if m.BuildNumber != "" {
+			build = append(build, "--build-number", m.BuildNumber)
+		}
+		steps = append(steps, Step{a.Name, p, Build, append(build, a.BuildFlags...)})
+
+		if bundlePlatforms[p] {
+			bundle := []string{"bundle", p, dir}
+			if m.Version != "" {
+				bundle = append(bundle, "--version", m.Version)
+			}
+			if a.BundleID != "" {
+				bundle = append(bundle, "--bundle-id", a.BundleID)
+			}
+			steps = append(steps, Step{a.Name, p, Bundle, append(bundle, a.BundleFlags...)})
+		}
+
+		if a.Publish && !skipPublish {
+			publish := []string{"publish", "metadata", p, dir}
+			if a.BundleID != "" {
+				publish = append(publish, "--bundle-id", a.BundleID)
+			}
+			steps = append(steps, Step{a.Name, p, Publish, publish})
+		}
+	}
+	return steps
+}
+
+// Outcome statuses.
+const (
+	OK      = "ok"
+	Failed  = "failed"
+	Skipped = "skipped" // An earlier step of the same app and platform failed
+)
+
+// Outcome is the result of one step.
+type Outcome struct {
+	Step
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// Options control a release.
+type Options struct {
+	Only        []string // App names to release; all when empty
+	SkipPublish bool
+	DryRun      bool
+	Out         io.Writer
+	Exec        func(args []string) error // Runs goup-util with args
+}
+
+// Run releases the apps one after another and returns every step's
+// outcome. A failed step skips the rest of that app's platform; other
+// platforms and apps carry on.
+func (m *Manifest) Run(opts Options) ([]Outcome, error) {
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	apps, err := m.selected(opts.Only)
+	if err != nil {
+		return nil, err
+	}
+
+	var outcomes []Outcome
+	for _, a := range apps {
+		fmt.Fprintf(opts.Out, "\n📦 %s\n", a.Name)
+		failed := map[string]bool{} // Platforms with a failed step
+		for _, s := range m.Plan(a, opts.SkipPublish) {
+			o := Outcome{Step: s}
+			switch {
+			case failed[s.Platform]:
+				o.Status = Skipped
+			case opts.DryRun:
+				fmt.Fprintf(opts.Out, "  → goup-util %s\n", strings.Join(s.Args, " "))
+				o.Status = OK
+			default:
+				fmt.Fprintf(opts.Out, "  → goup-util %s\n", strings.Join(s.Args, " "))
+				started := time.Now()
+				err := opts.Exec(s.Args)
+				o.Duration = time.Since(started).Round(time.Second).String()
+				o.Status = OK
+				if err != nil {
+					o.Status, o.Error = Failed, err.Error()
+					failed[s.Platform] = true
+					fmt.Fprintf(opts.Out, "  ❌ %s %s failed: %v\n", s.Stage, s.Platform, err)
+				}
+			}
+			outcomes = append(outcomes, o)
+		}
+	}
+	return outcomes, nil
+}
+
+func (m *Manifest) selected(only []string) ([]App, error) {
+	if len(only) == 0 {
+		return m.Apps, nil
+	}
+	var apps []App
+	for _, name := range only {
+		found := false
+		for _, a := range m.Apps {
+			if a.Name == name {
+				apps, found = append(apps, a), true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no app %q", m.Path, name)
+		}
+	}
+	return apps, nil
+}
+
+// AnyFailed reports whether a step failed.
+func AnyFailed(outcomes []Outcome) bool {
+	for _, o := range outcomes {
+		if o.Status == Failed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package release
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const apps = `{
+  "version": "1.4.0",
+  "platforms": ["macos", "android"],
+  "apps": [
+    {"name": "acme", "dir": "shells/acme", "bundleId": "com.acme.portal", "publish": true},
+    {"dir": "shells/globex", "platforms": ["windows"], "bundleFlags": ["--publisher", "CN=Globex"]}
+  ]
+}`
+
+func load(t *testing.T, content string) *Manifest {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "apps.json")
+	os.WriteFile(path, []byte(content), 0644)
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestPlan(t *testing.T) {
+	m := load(t, apps)
+	root := filepath.Dir(m.Path)
+	var got []string
+	for _, s := range m.Plan(m.Apps[0], false) {
+		got = append(got, strings.Join(s.Args, " "))
+	}
+	acme := filepath.Join(root, "shells", "acme")
+	want := []string{
+		"build macos " + acme + " --force --version 1.4.0",
+		"bundle macos " + acme + " --version 1.4.0 --bundle-id com.acme.portal",
+		"publish metadata macos " + acme + " --bundle-id com.acme.portal",
+		"build android " + acme + " --force --version 1.4.0",
+		"publish metadata android " + acme + " --bundle-id com.acme.portal",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("plan:\n%s", strings.Join(got, "\n"))
+	}
+
+	if m.Apps[1].Name != "globex" {
+		t.Errorf("name = %q", m.Apps[1].Name)
+	}
+	globex := m.Plan(m.Apps[1], true)
+	if len(globex) != 2 || !strings.HasSuffix(strings.Join(globex[1].Args, " "), "--version 1.4.0 --publisher CN=Globex") {
+		t.Errorf("globex plan = %+v", globex)
+	}
+}
+
+func TestRun(t *testing.T) {
+	m := load(t, apps)
+	var ran []string
+	outcomes, err := m.Run(Options{
+		Out: &bytes.Buffer{},
+		Exec: func(args []string) error {
+			ran = append(ran, args[0]+" "+args[1])
+			if args[0] == "build" && args[1] == "macos" {
+				return errors.New("exit status 1")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// macOS fails at build; android and the other app still run
+	if got := strings.Join(ran, ", "); got != "build macos, build android, publish metadata, build windows, bundle windows" {
+		t.Errorf("ran %s", got)
+	}
+	status := map[string]string{}
+	for _, o := range outcomes {
+		status[o.App+" "+o.Platform+" "+o.Stage] = o.Status
+	}
+	if status["acme macos bundle"] != Skipped || status["acme macos build"] != Failed || status["globex windows bundle"] != OK || !AnyFailed(outcomes) {
+		t.Errorf("outcomes = %v", status)
+	}
+
+	if _, err := m.Run(Options{Only: []string{"initech"}}); err == nil {
+		t.Error("unknown app accepted")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, content := range []string{
+		`{"apps": []}`,
+		`{"platforms": ["macos"], "apps": [{"name": "a"}]}`,
+		`{"platforms": ["macos"], "apps": [{"dir": "a"}, {"dir": "x/a"}]}`,
+		`{"apps": [{"dir": "a"}]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "apps.json")
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := Load(path); err == nil {
+			t.Errorf("no error for %s", content)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package remoteconfig fetches feature flags for deployed shells from a
+// JSON document on any web server, so a fleet can change behaviour
+// without shipping an update:
+//
+//	c := remoteconfig.New("https://config.example.com/kiosk.json", remoteconfig.DefaultCachePath("acme-portal"))
+//	c.Defaults = remoteconfig.Values{"showBanner": false}
+//	values, _ := c.Fetch(ctx)
+//	if values.Bool("showBanner", false) { ... }
+//
+// Responses are cached with their ETag, so unchanged documents cost a 304,
+// and the cached copy is used when the server cannot be reached.
+package remoteconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// CacheFileName is the cache file in DefaultCachePath.
+const CacheFileName = "remote-config.json"
+
+// Values is a flag document: a JSON object.
+type Values map[string]any
+
+// Bool returns a boolean flag, or def when it is missing or not a bool.
+func (v Values) Bool(key string, def bool) bool {
+	if b, ok := v[key].(bool); ok {
+		return b
+	}
+	return def
+}
+
+// String returns a string flag, or def.
+func (v Values) String(key, def string) string {
+	if s, ok := v[key].(string); ok {
+		return s
+	}
+	return def
+}
+
+// Number returns a numeric flag, or def.
+func (v Values) Number(key string, def float64) float64 {
+	if n, ok := v[key].(float64); ok {
+		return n
+	}
+	return def
+}
+
+// Source says where the values came from.
+type Source string
+
+const (
+	FromServer   Source = "server"   // Fetched, or confirmed unchanged with a 304
+	FromCache    Source = "cache"    // The server failed; last fetched copy
+	FromDefaults Source = "defaults" // Nothing fetched yet
+)
+
+// cache is the on-disk copy of the last response.
+type cache struct {
+	URL     string    `json:"url"`
+	ETag    string    `json:"etag,omitempty"`
+	Fetched time.Time `json:"fetched"`
+	Values  Values    `json:"values"`
+}
+
+// Client fetches one flag document.
+type Client struct {
+	URL       string
+	CachePath string // "" disables the cache
+	Defaults  Values // Used for keys the document lacks
+	HTTP      *http.Client
+}
+
+// New returns a client for url caching at cachePath.
+func New(url, cachePath string) *Client {
+	return &Client{URL: url, CachePath: cachePath, HTTP: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// DefaultCachePath is <config>/<app>/remote-config.json.
+func DefaultCachePath(app string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, app, CacheFileName)
+}
+
+// Cached returns the defaults overlaid with the cached document, without
+// going to the network. Apps use it to start with the last known flags.
+func (c *Client) Cached() (Values, Source) {
+	if cached := c.readCache(); cached != nil {
+		return c.withDefaults(cached.Values), FromCache
+	}
+	return c.withDefaults(nil), FromDefaults
+}
+
+// Fetch gets the document, sending the cached ETag. When the request fails
+// it returns the cached or default values together with the error, so
+// callers can log it and carry on.
+func (c *Client) Fetch(ctx context.Context) (Values, Source, error) {
+	cached := c.readCache()
+	values, err := c.fetch(ctx, cached)
+	if err != nil {
+		v, src := c.Cached()
+		return v, src, err
+	}
+	return c.withDefaults(values), FromServer, nil
+}
+
+func (c *Client) fetch(ctx context.Context, cached *cache) (Values, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		cached.Fetched = time.Now()
+		c.writeCache(cached)
+		return cached.Values, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch remote config: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote config: %w", err)
+	}
+	var values Values
+	if err := json.Unmarshal(body, &values); err != nil {
+		return nil, fmt.Errorf("remote config is not a JSON object: %w", err)
+	}
+	c.writeCache(&cache{URL: c.URL, ETag: resp.Header.Get("ETag"), Fetched: time.Now(), Values: values})
+	return values, nil
+}
+
+func (c *Client) withDefaults(values Values) Values {
+	merged := Values{}
+	for k, v := range c.Defaults {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+	return merged
+}
+
+// readCache returns the cached response for this URL, or nil.
+func (c *Client) readCache() *cache {
+	if c.CachePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.CachePath)
+	if err != nil {
+		return nil
+	}
+	var cached cache
+	if json.Unmarshal(data, &cached) != nil || cached.URL != c.URL {
+		return nil
+	}
+	return &cached
+}
+
+// writeCache saves the response; a failure only costs the next fetch.
+func (c *Client) writeCache(cached *cache) {
+	if c.CachePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(c.CachePath), 0755) != nil {
+		return
+	}
+	tmp := c.CachePath + ".tmp"
+	if os.WriteFile(tmp, data, 0644) == nil {
+		os.Rename(tmp, c.CachePath)
+	}
+}
+
+// Watch fetches every interval until ctx is done and calls onChange with
+// the values whenever they differ from the previous ones, starting with
+// the cached values.
+func (c *Client) Watch(ctx context.Context, interval time.Duration, onChange func(Values, Source)) {
+	current, src := c.Cached()
+	onChange(current, src)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if values, src, err := c.Fetch(ctx); err == nil && !reflect.DeepEqual(values, current) {
+			current = values
+			onChange(values, src)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
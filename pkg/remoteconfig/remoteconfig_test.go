@@ -0,0 +1,89 @@
+package remoteconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFetch(t *testing.T) {
+	var requests, notModified int
+	body := `{"showBanner": true, "theme": "dark"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	}))
+
+	c := New(srv.URL, filepath.Join(t.TempDir(), "flags", CacheFileName))
+	c.Defaults = Values{"showBanner": false, "refresh": 30.0}
+
+	if v, src := c.Cached(); src != FromDefaults || v.Bool("showBanner", true) {
+		t.Errorf("before fetch: %v %s", v, src)
+	}
+
+	ctx := context.Background()
+	v, src, err := c.Fetch(ctx)
+	if err != nil || src != FromServer {
+		t.Fatalf("Fetch: %s %v", src, err)
+	}
+	if !v.Bool("showBanner", false) || v.String("theme", "") != "dark" || v.Number("refresh", 0) != 30 {
+		t.Errorf("values = %v", v)
+	}
+
+	// Unchanged document: the cached ETag gets a 304
+	if v, _, err := c.Fetch(ctx); err != nil || v.String("theme", "") != "dark" || notModified != 1 {
+		t.Errorf("second fetch: %v %v (304s: %d)", v, err, notModified)
+	}
+
+	// Server gone: the cached copy is used
+	srv.Close()
+	v, src, err = c.Fetch(ctx)
+	if err == nil || src != FromCache || !v.Bool("showBanner", false) {
+		t.Errorf("offline: %v %s %v", v, src, err)
+	}
+
+	// A cache for another URL is ignored
+	other := New("http://example.invalid/flags.json", c.CachePath)
+	if _, src := other.Cached(); src != FromDefaults {
+		t.Errorf("other URL source = %s", src)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	version := "a"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"v": "` + version + `"}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan Values, 10)
+	go c.Watch(ctx, 10*time.Millisecond, func(v Values, _ Source) { changes <- v })
+	defer cancel()
+
+	for _, want := range []string{"", "a"} {
+		select {
+		case v := <-changes:
+			if v.String("v", "") != want {
+				t.Errorf("change = %v, want %q", v, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no change to %q", want)
+		}
+	}
+	select {
+	case v := <-changes:
+		t.Errorf("unexpected change %v", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
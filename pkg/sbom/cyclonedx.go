@@ -0,0 +1,65 @@
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type cdxComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// cycloneDXDocument renders a CycloneDX 1.5 JSON BOM. The first module is
+// the app itself; the Go standard library is listed as "stdlib" so
+// scanners can match Go security advisories.
+func cycloneDXDocument(opts Options, mods []Module, goVersion string) ([]byte, error) {
+	app := cdxComponent{Type: "application", Name: opts.Name, Version: opts.Version}
+	var libs []cdxComponent
+	for _, m := range mods {
+		if m.Main {
+			app.BOMRef = purl(m.Path, opts.Version)
+			app.PURL = app.BOMRef
+			continue
+		}
+		ref := purl(m.Path, m.Version)
+		libs = append(libs, cdxComponent{Type: "library", BOMRef: ref, Name: m.Path, Version: m.Version, PURL: ref})
+	}
+	if app.BOMRef == "" {
+		app.BOMRef = opts.Name
+	}
+	if goVersion != "" {
+		ref := purl("stdlib", goVersion)
+		libs = append(libs, cdxComponent{Type: "library", BOMRef: ref, Name: "stdlib", Version: goVersion, PURL: ref})
+	}
+
+	dependsOn := make([]string, 0, len(libs))
+	for _, l := range libs {
+		dependsOn = append(dependsOn, l.BOMRef)
+	}
+
+	doc := map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + newUUID(),
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"tools": map[string]any{
+				"components": []cdxComponent{{Type: "application", BOMRef: "goup-util", Name: "goup-util", Version: opts.Builder}},
+			},
+			"component": app,
+		},
+		"components":   libs,
+		"dependencies": []cdxDependency{{Ref: app.BOMRef, DependsOn: dependsOn}},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,159 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BuildType identifies goup-util builds in SLSA provenance.
+const BuildType = "https://github.com/joeblew999/goup-util/build/v1"
+
+// statement is an in-toto v1 statement carrying SLSA v1 provenance.
+type statement struct {
+	Type          string         `json:"_type"`
+	Subject       []subject      `json:"subject"`
+	PredicateType string         `json:"predicateType"`
+	Predicate     map[string]any `json:"predicate"`
+}
+
+type subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// provenance records who built the artifact, from which commit and with
+// which parameters. It is unsigned; sign it with cosign or attach it to
+// GitHub artifact attestations to reach SLSA build level 2+.
+func provenance(opts Options, goVersion string) (*statement, error) {
+	subj, err := subjectOf(opts.Artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	external := map[string]any{"platform": opts.Platform}
+	if len(opts.Parameters) > 0 {
+		external["parameters"] = opts.Parameters
+	}
+	internal := map[string]any{"goVersion": goVersion}
+	for _, kv := range opts.Env {
+		if k, v, ok := strings.Cut(kv, "="); ok && (k == "GOOS" || k == "GOARCH") {
+			internal[strings.ToLower(k)] = v
+		}
+	}
+
+	buildDef := map[string]any{
+		"buildType":          BuildType,
+		"externalParameters": external,
+		"internalParameters": internal,
+	}
+	if src := gitSource(opts.AppDir); src != nil {
+		external["source"] = src["uri"]
+		buildDef["resolvedDependencies"] = []map[string]any{src}
+	}
+
+	metadata := map[string]any{"finishedOn": time.Now().UTC().Format(time.RFC3339)}
+	if !opts.StartedOn.IsZero() {
+		metadata["startedOn"] = opts.StartedOn.UTC().Format(time.RFC3339)
+	}
+	if id := invocationID(); id != "" {
+		metadata["invocationId"] = id
+	}
+
+	return &statement{
+		Type:          "https://in-toto.io/Statement/v1",
+		Subject:       []subject{subj},
+		PredicateType: "https://slsa.dev/provenance/v1",
+		Predicate: map[string]any{
+			"buildDefinition": buildDef,
+			"runDetails": map[string]any{
+				"builder": map[string]any{
+					"id":      builderID(),
+					"version": map[string]string{"goup-util": opts.Builder},
+				},
+				"metadata": metadata,
+			},
+		},
+	}, nil
+}
+
+// subjectOf hashes the artifact. For a .app directory the subject is its
+// main executable, since in-toto subjects are files.
+func subjectOf(path string) (subject, error) {
+	file := path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		name := strings.TrimSuffix(filepath.Base(path), ".app")
+		file = filepath.Join(path, "Contents", "MacOS", name) // macOS
+		if _, err := os.Stat(file); err != nil {
+			file = filepath.Join(path, name) // iOS
+		}
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return subject{}, fmt.Errorf("failed to hash artifact: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return subject{}, fmt.Errorf("failed to hash artifact: %w", err)
+	}
+	name, _ := filepath.Rel(filepath.Dir(path), file)
+	return subject{Name: filepath.ToSlash(name), Digest: map[string]string{"sha256": hex.EncodeToString(h.Sum(nil))}}, nil
+}
+
+// gitSource describes the checkout the app was built from, or nil.
+func gitSource(dir string) map[string]any {
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := git("rev-parse", "HEAD")
+	if commit == "" {
+		return nil
+	}
+	uri := git("config", "--get", "remote.origin.url")
+	if uri == "" {
+		uri = "file://" + filepath.ToSlash(git("rev-parse", "--show-toplevel"))
+	}
+	src := map[string]any{
+		"uri":    "git+" + uri,
+		"digest": map[string]string{"gitCommit": commit},
+	}
+	if git("status", "--porcelain") != "" {
+		src["annotations"] = map[string]any{"dirty": true}
+	}
+	return src
+}
+
+// builderID names the machine or CI service that ran the build.
+func builderID() string {
+	if server, repo := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"); server != "" && repo != "" {
+		return server + "/" + repo + "/actions"
+	}
+	host, _ := os.Hostname()
+	return "goup-util://local/" + host
+}
+
+// invocationID links to the CI run, if any.
+func invocationID() string {
+	server, repo, run := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server == "" || repo == "" || run == "" {
+		return ""
+	}
+	id := fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, run)
+	if attempt := os.Getenv("GITHUB_RUN_ATTEMPT"); attempt != "" {
+		id += "/attempts/" + attempt
+	}
+	return id
+}
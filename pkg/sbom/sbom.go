@@ -0,0 +1,245 @@
+// Package sbom writes a software bill of materials (CycloneDX or SPDX) of
+// an app's Go module dependencies and SLSA provenance for the artifact
+// built from it. Both are written next to the artifact as sidecar files
+// (myapp.apk.cdx.json, myapp.apk.intoto.json) so they travel with it into
+// packages and release uploads.
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Supported SBOM formats.
+const (
+	CycloneDX = "cyclonedx"
+	SPDX      = "spdx"
+)
+
+// FormatEnv turns on SBOM generation for every build and bundle (e.g. in
+// CI) without passing --sbom.
+const FormatEnv = "GOUP_SBOM"
+
+// ProvenanceSuffix is appended to the artifact name for the provenance file.
+const ProvenanceSuffix = ".intoto.json"
+
+// Module is a Go module linked into the app.
+type Module struct {
+	Path    string
+	Version string // Empty for the main module and local replacements
+	Main    bool
+}
+
+// Options describes one artifact to document.
+type Options struct {
+	Format   string // CycloneDX or SPDX
+	Artifact string // Built file, or a .app directory
+	AppDir   string // App module directory
+	Name     string // App name
+	Version  string // App version, if known
+
+	// Env resolves the dependency set for the target (GOOS, GOARCH, ...).
+	Env []string
+
+	Builder    string            // goup-util version
+	Platform   string            // goup-util platform (macos, android, ...)
+	Parameters map[string]string // Flags that shaped the build
+	StartedOn  time.Time
+}
+
+// Resolve returns the format to use: flag, else $GOUP_SBOM, else "" (off).
+func Resolve(flag string) (string, error) {
+	format := flag
+	if format == "" {
+		format = os.Getenv(FormatEnv)
+	}
+	switch format {
+	case "", CycloneDX, SPDX:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown SBOM format %q (use %s or %s)", format, CycloneDX, SPDX)
+}
+
+// FileName returns the SBOM sidecar path for an artifact.
+func FileName(artifact, format string) string {
+	if format == SPDX {
+		return artifact + ".spdx.json"
+	}
+	return artifact + ".cdx.json"
+}
+
+// Write generates the SBOM and provenance sidecars for opts.Artifact and
+// returns their paths.
+func Write(opts Options) ([]string, error) {
+	mods, err := Modules(opts.AppDir, opts.Env)
+	if err != nil {
+		return nil, err
+	}
+	goVersion := goEnv("GOVERSION", opts.Env)
+
+	var doc []byte
+	switch opts.Format {
+	case SPDX:
+		doc, err = spdxDocument(opts, mods, goVersion)
+	default:
+		doc, err = cycloneDXDocument(opts, mods, goVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sbomPath := FileName(opts.Artifact, opts.Format)
+	if err := os.WriteFile(sbomPath, doc, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write SBOM: %w", err)
+	}
+
+	stmt, err := provenance(opts, goVersion)
+	if err != nil {
+		return nil, err
+	}
+	provPath := opts.Artifact + ProvenanceSuffix
+	if err := writeJSON(provPath, stmt); err != nil {
+		return nil, err
+	}
+	return []string{sbomPath, provPath}, nil
+}
+
+// Attach copies the sidecars of artifact to pkg (an archive made from it)
+// and re-issues the provenance with pkg as the subject. It returns the
+// written paths, or nothing if artifact has no sidecars.
+func Attach(artifact, pkg string) ([]string, error) {
+	var written []string
+	for _, format := range []string{CycloneDX, SPDX} {
+		src := FileName(artifact, format)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := copyFile(src, FileName(pkg, format)); err != nil {
+			return nil, err
+		}
+		written = append(written, FileName(pkg, format))
+	}
+
+	data, err := os.ReadFile(artifact + ProvenanceSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return written, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stmt statement
+	if err := json.Unmarshal(data, &stmt); err != nil {
+		return nil, fmt.Errorf("failed to parse provenance: %w", err)
+	}
+	subj, err := subjectOf(pkg)
+	if err != nil {
+		return nil, err
+	}
+	stmt.Subject = []subject{subj}
+	if err := writeJSON(pkg+ProvenanceSuffix, stmt); err != nil {
+		return nil, err
+	}
+	return append(written, pkg+ProvenanceSuffix), nil
+}
+
+// Modules lists the modules whose packages are linked into the app for
+// the target in env, main module first.
+func Modules(dir string, env []string) ([]Module, error) {
+	cmd := exec.Command("go", "list", "-deps", "-json=Module", ".")
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), "GOWORK=off"), env...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w", err)
+	}
+
+	type listModule struct {
+		Path    string
+		Version string
+		Main    bool
+		Replace *listModule
+	}
+	dec := json.NewDecoder(strings.NewReader(string(out)))
+	seen := make(map[string]bool)
+	var main []Module
+	var deps []Module
+	for {
+		var pkg struct{ Module *listModule }
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		m := pkg.Module
+		if m == nil || seen[m.Path] { // standard library
+			continue
+		}
+		seen[m.Path] = true
+		mod := Module{Path: m.Path, Version: m.Version, Main: m.Main}
+		if r := m.Replace; r != nil && r.Version != "" {
+			mod.Path, mod.Version = r.Path, r.Version
+		} else if r != nil {
+			mod.Version = "" // Local directory replacement
+		}
+		if m.Main {
+			main = append(main, mod)
+		} else {
+			deps = append(deps, mod)
+		}
+	}
+	return append(main, deps...), nil
+}
+
+// purl returns the package URL of a Go module.
+func purl(path, version string) string {
+	p := "pkg:golang/" + path
+	if version != "" {
+		p += "@" + version
+	}
+	return p + "?type=module"
+}
+
+func goEnv(name string, env []string) string {
+	cmd := exec.Command("go", "env", name)
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
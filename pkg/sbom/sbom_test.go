@@ -0,0 +1,68 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testModules = []Module{
+	{Path: "example.com/app", Main: true},
+	{Path: "gioui.org", Version: "v0.8.0"},
+}
+
+func TestCycloneDXDocument(t *testing.T) {
+	data, err := cycloneDXDocument(Options{Name: "app", Version: "1.2.0", Builder: "v1.0.0"}, testModules, "go1.23.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		BOMFormat  string
+		Metadata   struct{ Component cdxComponent }
+		Components []cdxComponent
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.Metadata.Component.PURL != "pkg:golang/example.com/app@1.2.0?type=module" {
+		t.Errorf("unexpected metadata: %+v", doc)
+	}
+	if len(doc.Components) != 2 || doc.Components[0].PURL != "pkg:golang/gioui.org@v0.8.0?type=module" || doc.Components[1].Name != "stdlib" {
+		t.Errorf("unexpected components: %+v", doc.Components)
+	}
+}
+
+func TestAttach(t *testing.T) {
+	dir := t.TempDir()
+	artifact := filepath.Join(dir, "app.exe")
+	pkg := filepath.Join(dir, "app-windows.zip")
+	os.WriteFile(artifact, []byte("binary"), 0644)
+	os.WriteFile(pkg, []byte("archive"), 0644)
+	os.WriteFile(FileName(artifact, CycloneDX), []byte("{}"), 0644)
+
+	stmt, err := provenance(Options{Artifact: artifact, AppDir: dir, Platform: "windows"}, "go1.23.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(artifact+ProvenanceSuffix, stmt); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Attach(artifact, pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Attach wrote %v, want SBOM and provenance", files)
+	}
+	data, _ := os.ReadFile(pkg + ProvenanceSuffix)
+	var got statement
+	json.Unmarshal(data, &got)
+	if len(got.Subject) != 1 || got.Subject[0].Name != "app-windows.zip" {
+		t.Errorf("subject = %+v, want the package", got.Subject)
+	}
+	if got.Predicate["buildDefinition"] == nil {
+		t.Error("build definition was not carried over")
+	}
+}
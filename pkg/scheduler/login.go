@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrUnsupported is returned where apps cannot be started at login.
+var ErrUnsupported = errors.New("starting at login is not supported on this platform")
+
+// Login describes how to start an app when the user logs in.
+type Login struct {
+	// ID names the registration: the LaunchAgent label on macOS, the Run
+	// value on Windows and the autostart file on Linux. Use the app ID
+	// (e.g. "com.example.myapp").
+	ID string
+	// Name is shown in the desktop's startup settings where it has them.
+	Name string
+	// Path is the executable to start. It defaults to the running one.
+	Path string
+	// Args are passed to it, e.g. "--background" to start hidden.
+	Args []string
+}
+
+func (l Login) command() (Login, error) {
+	if l.ID == "" {
+		return l, errors.New("scheduler: login item needs an ID")
+	}
+	if l.Name == "" {
+		l.Name = l.ID
+	}
+	if l.Path == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return l, err
+		}
+		l.Path = exe
+	}
+	return l, nil
+}
+
+// EnableAtLogin registers the app to start when the current user logs
+// in. MSIX packages cannot register themselves on Windows; set
+// "startAtLogin" in app.json so 'goup-util bundle windows' declares a
+// startup task instead.
+func EnableAtLogin(l Login) error {
+	l, err := l.command()
+	if err != nil {
+		return err
+	}
+	return enableAtLogin(l)
+}
+
+// DisableAtLogin removes the registration. Removing one that does not
+// exist is not an error.
+func DisableAtLogin(l Login) error {
+	l, err := l.command()
+	if err != nil {
+		return err
+	}
+	return disableAtLogin(l)
+}
+
+// AtLogin reports whether the app is registered to start at login.
+func AtLogin(l Login) (bool, error) {
+	l, err := l.command()
+	if err != nil {
+		return false, err
+	}
+	return atLogin(l)
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// launchAgent is the LaunchAgent that starts l. launchd reads it at the
+// next login; loading it now would start a second copy of the app.
+func launchAgent(l Login) []byte {
+	var b bytes.Buffer
+	esc := func(s string) string {
+		var e bytes.Buffer
+		xml.EscapeText(&e, []byte(s))
+		return e.String()
+	}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + esc(l.ID) + `</string>
+	<key>ProgramArguments</key>
+	<array>
+`)
+	for _, arg := range append([]string{l.Path}, l.Args...) {
+		b.WriteString("\t\t<string>" + esc(arg) + "</string>\n")
+	}
+	b.WriteString(`	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>ProcessType</key>
+	<string>Interactive</string>
+</dict>
+</plist>
+`)
+	return b.Bytes()
+}
+
+func launchAgentPath(l Login) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", l.ID+".plist"), nil
+}
+
+func enableAtLogin(l Login) error {
+	if runtime.GOOS == "ios" {
+		return ErrUnsupported
+	}
+	path, err := launchAgentPath(l)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, launchAgent(l), 0644)
+}
+
+func disableAtLogin(l Login) error {
+	path, err := launchAgentPath(l)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func atLogin(l Login) (bool, error) {
+	path, err := launchAgentPath(l)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	return err == nil, nil
+}
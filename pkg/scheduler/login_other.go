@@ -0,0 +1,15 @@
+//go:build !darwin && !windows && !((linux && !android) || freebsd || openbsd)
+
+package scheduler
+
+func enableAtLogin(l Login) error {
+	return ErrUnsupported
+}
+
+func disableAtLogin(l Login) error {
+	return ErrUnsupported
+}
+
+func atLogin(l Login) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,73 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package scheduler
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// desktopEntry is the XDG autostart entry that starts l, which GNOME,
+// KDE and most other desktops run at login.
+func desktopEntry(l Login) []byte {
+	var exec []string
+	for _, arg := range append([]string{l.Path}, l.Args...) {
+		exec = append(exec, desktopQuote(arg))
+	}
+	return []byte("[Desktop Entry]\n" +
+		"Type=Application\n" +
+		"Name=" + l.Name + "\n" +
+		"Exec=" + strings.Join(exec, " ") + "\n" +
+		"X-GNOME-Autostart-enabled=true\n")
+}
+
+// desktopQuote quotes an Exec argument as the Desktop Entry spec asks:
+// in double quotes when needed, with ", `, $ and \ escaped and % doubled.
+func desktopQuote(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", `$`, `\\$`).Replace(arg) + `"`
+}
+
+func autostartPath(l Login) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "autostart", l.ID+".desktop"), nil
+}
+
+func enableAtLogin(l Login) error {
+	path, err := autostartPath(l)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, desktopEntry(l), 0644)
+}
+
+func disableAtLogin(l Login) error {
+	path, err := autostartPath(l)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func atLogin(l Login) (bool, error) {
+	path, err := autostartPath(l)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	return err == nil, nil
+}
@@ -0,0 +1,30 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package scheduler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDesktopEntry(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	l := Login{ID: "com.example.kiosk", Name: "Kiosk", Path: "/opt/My Kiosk/kiosk", Args: []string{"--background", "100%"}}
+	entry := string(desktopEntry(l))
+	if !strings.Contains(entry, `Exec="/opt/My Kiosk/kiosk" --background 100%%`+"\n") {
+		t.Errorf("entry = %s", entry)
+	}
+
+	if err := EnableAtLogin(l); err != nil {
+		t.Fatal(err)
+	}
+	if on, _ := AtLogin(l); !on {
+		t.Error("not registered after EnableAtLogin")
+	}
+	if err := DisableAtLogin(l); err != nil {
+		t.Fatal(err)
+	}
+	if on, _ := AtLogin(l); on {
+		t.Error("still registered after DisableAtLogin")
+	}
+}
@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const runKey = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+// errPackaged is returned inside an MSIX package, where writes to the
+// Run key are virtualized and never seen at login.
+var errPackaged = errors.New(`packaged apps start at login through a startup task; set "startAtLogin" in app.json and rebuild with 'goup-util bundle windows'`)
+
+// packaged reports whether the process runs from an MSIX package.
+func packaged() bool {
+	proc := windows.NewLazySystemDLL("kernel32.dll").NewProc("GetCurrentPackageFullName")
+	if proc.Find() != nil {
+		return false
+	}
+	var n uint32
+	r, _, _ := proc.Call(uintptr(unsafe.Pointer(&n)), 0)
+	return r != 15700 // APPMODEL_ERROR_NO_PACKAGE
+}
+
+// runCommand quotes the command line as Windows parses it.
+func runCommand(l Login) string {
+	var args []string
+	for _, a := range append([]string{l.Path}, l.Args...) {
+		args = append(args, syscall.EscapeArg(a))
+	}
+	return strings.Join(args, " ")
+}
+
+func enableAtLogin(l Login) error {
+	if packaged() {
+		return errPackaged
+	}
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	return k.SetStringValue(l.ID, runCommand(l))
+}
+
+func disableAtLogin(l Login) error {
+	if packaged() {
+		return errPackaged
+	}
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return nil
+	}
+	defer k.Close()
+	if err := k.DeleteValue(l.ID); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func atLogin(l Login) (bool, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false, nil
+	}
+	defer k.Close()
+	_, _, err = k.GetStringValue(l.ID)
+	return err == nil, nil
+}
@@ -0,0 +1,296 @@
+// Package scheduler runs an app's periodic background work, such as
+// syncing, refreshing caches or flushing telemetry, and registers the app
+// to start when the user logs in (see EnableAtLogin).
+//
+// Tasks run on timers in the app's own process, so they run whenever the
+// app does: on desktop that includes a hidden or minimized window. Mobile
+// systems suspend apps soon after they leave the screen. Android stops
+// them once the process is frozen, and iOS within seconds; its
+// BGTaskScheduler needs the task identifiers in Info.plist and a handler
+// registered from Objective-C before launch finishes, which Gio apps
+// cannot do. On mobile, call Pause and Resume from the app's stage
+// events: tasks that fell due while the app was away run on Resume.
+//
+// The last run of each task is kept in a state file, so a daily task
+// runs once a day however often the app restarts.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxSleep bounds how long the scheduler waits between checks. Timers
+// stop while a laptop sleeps, so waking regularly and comparing wall
+// clock times catches tasks that fell due meanwhile.
+const maxSleep = time.Minute
+
+// Task is a piece of work run every interval.
+type Task struct {
+	Name  string
+	Every time.Duration
+	Run   func(ctx context.Context) error
+	// Timeout cancels a run's context after this long. It defaults to
+	// Every.
+	Timeout time.Duration
+}
+
+// Status describes a task for settings pages and diagnostics.
+type Status struct {
+	Name    string    `json:"name"`
+	LastRun time.Time `json:"lastRun,omitzero"`
+	NextRun time.Time `json:"nextRun"`
+	Error   string    `json:"error,omitempty"` // From the last run
+	Running bool      `json:"running,omitempty"`
+}
+
+type entry struct {
+	task    Task
+	last    time.Time
+	err     string
+	running bool
+	forced  bool
+}
+
+// Scheduler runs tasks while Run is active.
+type Scheduler struct {
+	// StateFile keeps the last run of each task. It defaults to
+	// scheduler.json in the app's folder of the user config directory;
+	// change it before adding tasks.
+	StateFile string
+	// OnError is called with the errors tasks return, on the task's
+	// goroutine.
+	OnError func(task string, err error)
+
+	mu      sync.Mutex
+	tasks   map[string]*entry
+	last    map[string]time.Time // Last runs as saved in StateFile
+	paused  bool
+	wake    chan struct{}
+	running sync.WaitGroup
+}
+
+// New returns a Scheduler keeping its state for the app called appName.
+func New(appName string) *Scheduler {
+	s := &Scheduler{tasks: map[string]*entry{}, wake: make(chan struct{}, 1)}
+	if dir, err := os.UserConfigDir(); err == nil && appName != "" {
+		s.StateFile = filepath.Join(dir, appName, "scheduler.json")
+	}
+	return s
+}
+
+// Add registers t. A task that never ran, or whose interval passed while
+// the app was closed, runs as soon as the scheduler starts.
+func (s *Scheduler) Add(t Task) error {
+	if t.Name == "" || t.Run == nil {
+		return errors.New("scheduler: task needs a name and a Run func")
+	}
+	if t.Every <= 0 {
+		return fmt.Errorf("scheduler: task %s needs a positive interval", t.Name)
+	}
+	if t.Timeout <= 0 {
+		t.Timeout = t.Every
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tasks[t.Name]; ok {
+		return fmt.Errorf("scheduler: task %s already added", t.Name)
+	}
+	if s.last == nil {
+		s.load()
+	}
+	s.tasks[t.Name] = &entry{task: t, last: s.last[t.Name]}
+	s.signal()
+	return nil
+}
+
+// Trigger runs the named task now, as for a "sync now" button, unless it
+// is already running.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.tasks[name]
+	if !ok {
+		return fmt.Errorf("scheduler: no task named %s", name)
+	}
+	e.forced = true
+	s.signal()
+	return nil
+}
+
+// Pause stops starting tasks, for when a mobile app goes to the
+// background. Runs in progress finish.
+func (s *Scheduler) Pause() {
+	s.mu.Lock()
+	s.paused = true
+	s.mu.Unlock()
+}
+
+// Resume starts tasks again, running any that fell due while paused.
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	s.paused = false
+	s.signal()
+	s.mu.Unlock()
+}
+
+// Status reports every task, sorted by name.
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []Status
+	for name, e := range s.tasks {
+		list = append(list, Status{
+			Name:    name,
+			LastRun: e.last,
+			NextRun: e.due(),
+			Error:   e.err,
+			Running: e.running,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Run starts due tasks until ctx is done, then waits for running ones
+// to return. Call it in its own goroutine.
+func (s *Scheduler) Run(ctx context.Context) {
+	defer s.running.Wait()
+
+	for {
+		next := s.start(ctx)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (e *entry) due() time.Time {
+	if e.forced || e.last.IsZero() {
+		return time.Time{}
+	}
+	return e.last.Add(e.task.Every)
+}
+
+// start launches the due tasks and returns when to check next.
+func (s *Scheduler) start(ctx context.Context) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	next := now.Add(maxSleep)
+	if s.paused {
+		return next
+	}
+	for _, e := range s.tasks {
+		if e.running {
+			continue
+		}
+		if due := e.due(); due.After(now) {
+			if due.Before(next) {
+				next = due
+			}
+			continue
+		}
+		e.running, e.forced = true, false
+		s.running.Add(1)
+		go s.run(ctx, e, now)
+	}
+	return next
+}
+
+// run runs one task and records the outcome. Intervals count from the
+// start of a run, so slow tasks do not drift.
+func (s *Scheduler) run(ctx context.Context, e *entry, started time.Time) {
+	defer s.running.Done()
+	ctx, cancel := context.WithTimeout(ctx, e.task.Timeout)
+	defer cancel()
+	err := safeRun(ctx, e.task.Run)
+	if err != nil && s.OnError != nil {
+		s.OnError(e.task.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.running = false
+	e.last = started
+	s.last[e.task.Name] = started
+	e.err = ""
+	if err != nil {
+		e.err = err.Error()
+	}
+	s.save()
+	s.signal()
+}
+
+// safeRun turns a panicking task into an error instead of taking the app
+// down.
+func safeRun(ctx context.Context, run func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// signal wakes Run to look at the tasks again.
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// load reads the last runs of the tasks. A missing or damaged file just
+// means every task is due.
+func (s *Scheduler) load() {
+	s.last = map[string]time.Time{}
+	if s.StateFile == "" {
+		return
+	}
+	data, err := os.ReadFile(s.StateFile)
+	if err != nil {
+		return
+	}
+	var last map[string]time.Time
+	if json.Unmarshal(data, &last) != nil {
+		return
+	}
+	now := time.Now()
+	for name, t := range last {
+		// A clock set back would otherwise hold the task off until it
+		// catches up
+		if !t.After(now) {
+			s.last[name] = t
+		}
+	}
+}
+
+// save writes the last runs. Errors are ignored: the worst case is a
+// task running again after a restart.
+func (s *Scheduler) save() {
+	if s.StateFile == "" {
+		return
+	}
+	data, _ := json.MarshalIndent(s.last, "", "  ")
+	if os.MkdirAll(filepath.Dir(s.StateFile), 0755) != nil {
+		return
+	}
+	tmp := s.StateFile + ".tmp"
+	if os.WriteFile(tmp, data, 0644) == nil {
+		os.Rename(tmp, s.StateFile)
+	}
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRunAndPersist(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "scheduler.json")
+	var runs atomic.Int32
+	var failures atomic.Int32
+
+	s := New("")
+	s.StateFile = state
+	s.OnError = func(task string, err error) { failures.Add(1) }
+	s.Add(Task{Name: "sync", Every: time.Hour, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	s.Add(Task{Name: "flush", Every: time.Hour, Run: func(ctx context.Context) error {
+		panic("boom")
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { s.Run(ctx); close(done) }()
+
+	waitFor(t, "first runs", func() bool { return runs.Load() == 1 && failures.Load() == 1 })
+	st := s.Status()
+	if len(st) != 2 || st[0].Name != "flush" || st[0].Error == "" || st[1].LastRun.IsZero() {
+		t.Fatalf("status = %+v", st)
+	}
+	if got := st[1].NextRun.Sub(st[1].LastRun); got != time.Hour {
+		t.Errorf("next run after %v, want 1h", got)
+	}
+
+	s.Trigger("sync")
+	waitFor(t, "triggered run", func() bool { return runs.Load() == 2 })
+	cancel()
+	<-done
+
+	// A restart within the hour does not run the task again
+	s2 := New("")
+	s2.StateFile = state
+	s2.Add(Task{Name: "sync", Every: time.Hour, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s2.Run(ctx)
+	if runs.Load() != 2 {
+		t.Errorf("task ran again after restart")
+	}
+}
+
+func TestPause(t *testing.T) {
+	var runs atomic.Int32
+	s := New("")
+	s.Pause()
+	s.Add(Task{Name: "refresh", Every: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+	if runs.Load() != 0 {
+		t.Fatal("task ran while paused")
+	}
+	s.Resume()
+	waitFor(t, "runs after resume", func() bool { return runs.Load() >= 2 })
+}
+
+func TestAddErrors(t *testing.T) {
+	s := New("")
+	run := func(context.Context) error { return nil }
+	if err := s.Add(Task{Name: "x", Run: run}); err == nil {
+		t.Error("task without interval accepted")
+	}
+	s.Add(Task{Name: "x", Every: time.Second, Run: run})
+	if err := s.Add(Task{Name: "x", Every: time.Second, Run: run}); err == nil {
+		t.Error("duplicate task accepted")
+	}
+	if err := s.Trigger("y"); err == nil {
+		t.Error("unknown task triggered")
+	}
+	if _, err := AtLogin(Login{}); err == nil || errors.Is(err, ErrUnsupported) {
+		t.Errorf("login item without ID: %v", err)
+	}
+}
@@ -57,6 +57,49 @@ func ValidPlatform(s string) bool {
 	return false
 }
 
+// IconPlatform is a target that icons can be generated for. Besides the
+// build platforms it covers the Apple companion targets (watchOS, tvOS,
+// visionOS) that apps ship alongside their Gio binary.
+type IconPlatform string
+
+const (
+	IconAndroid     IconPlatform = "android"
+	IconIOS         IconPlatform = "ios"
+	IconMacOS       IconPlatform = "macos"
+	IconWindows     IconPlatform = "windows"
+	IconWindowsMSIX IconPlatform = "windows-msix"
+	IconWindowsICO  IconPlatform = "windows-ico"
+	IconWatchOS     IconPlatform = "watchos"
+	IconTVOS        IconPlatform = "tvos"
+	IconVisionOS    IconPlatform = "visionos"
+)
+
+// IconPlatforms is the list of all icon targets (for CLI validation and completion)
+var IconPlatforms = []string{
+	string(IconAndroid),
+	string(IconIOS),
+	string(IconMacOS),
+	string(IconWindows),
+	string(IconWindowsMSIX),
+	string(IconWindowsICO),
+	string(IconWatchOS),
+	string(IconTVOS),
+	string(IconVisionOS),
+}
+
+// IconPlatformDescriptions for CLI tab completion
+var IconPlatformDescriptions = map[string]string{
+	string(IconAndroid):     "Android drawables",
+	string(IconIOS):         "iOS AppIcon.appiconset",
+	string(IconMacOS):       "macOS .icns",
+	string(IconWindows):     "Windows MSIX assets",
+	string(IconWindowsMSIX): "Windows MSIX assets",
+	string(IconWindowsICO):  "Windows .ico",
+	string(IconWatchOS):     "watchOS AppIcon.appiconset",
+	string(IconTVOS):        "tvOS layered brand assets (App Icon, Top Shelf)",
+	string(IconVisionOS):    "visionOS layered AppIcon.solidimagestack",
+}
+
 // =============================================================================
 // VM STATUS - UTM virtual machine states
 // =============================================================================
@@ -98,10 +141,19 @@ type BuildInput struct {
 
 // BuildOutput is the result of a build operation.
 type BuildOutput struct {
-	Success    bool   `json:"success"`
-	OutputPath string `json:"output_path" jsonschema:"Path to built artifact"`
-	Cached     bool   `json:"cached" jsonschema:"Whether result was from cache"`
-	Duration   string `json:"duration,omitempty" jsonschema:"Build duration"`
+	Success    bool         `json:"success"`
+	Platform   string       `json:"platform,omitempty" jsonschema:"Platform that was built"`
+	OutputPath string       `json:"output_path" jsonschema:"Path to built artifact"`
+	Cached     bool         `json:"cached" jsonschema:"Whether result was from cache"`
+	Duration   string       `json:"duration,omitempty" jsonschema:"Build duration"`
+	Phases     []BuildPhase `json:"phases,omitempty" jsonschema:"Time spent in each build phase"`
+	Error      string       `json:"error,omitempty" jsonschema:"Why the build failed"`
+}
+
+// BuildPhase is the time one build phase took.
+type BuildPhase struct {
+	Phase    string `json:"phase" jsonschema:"Phase: icons, compile, link, package or sign"`
+	Duration string `json:"duration" jsonschema:"Time spent in the phase"`
 }
 
 // =============================================================================
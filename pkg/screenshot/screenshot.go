@@ -17,11 +17,13 @@ import (
 
 // DisplayInfo contains information about a display
 type DisplayInfo struct {
-	ID     int
-	X      int
-	Y      int
-	Width  int
-	Height int
+	ID      int
+	X       int
+	Y       int
+	Width   int
+	Height  int
+	Scale   float64 // System scale factor (2 on Retina)
+	Primary bool    // The display at the origin of the desktop
 }
 
 // Config contains screenshot configuration
@@ -143,11 +145,13 @@ func GetInfo() ([]DisplayInfo, error) {
 	for i := 0; i < num; i++ {
 		x, y, w, h := robotgo.GetDisplayBounds(i)
 		displays[i] = DisplayInfo{
-			ID:     i,
-			X:      x,
-			Y:      y,
-			Width:  w,
-			Height: h,
+			ID:      i,
+			X:       x,
+			Y:       y,
+			Width:   w,
+			Height:  h,
+			Scale:   robotgo.SysScale(i),
+			Primary: x == 0 && y == 0,
 		}
 	}
 
@@ -0,0 +1,313 @@
+// Package script runs YAML flows whose steps are goup-util operations,
+// so a release flow can be kept in the repository without shell scripts
+// or Taskfiles:
+//
+//	vars:
+//	  app: examples/hybrid-dashboard
+//	steps:
+//	  - icons: {platform: macos, app: "${app}"}
+//	  - name: Build
+//	    build: {platform: macos, app: "${app}", force: true}
+//	  - ios-boot: {device: iPhone 16}
+//	    if: ${os} == darwin
+//	  - utm-exec: {vm: Windows 11, command: "build windows ${app}"}
+//	    continueOnError: true
+//
+// Each operation names its positional arguments; other keys become flags,
+// so {force: true} is --force. "goup" runs any other goup-util command
+// from a list of arguments.
+package script
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Operation maps a step kind to a goup-util command.
+type Operation struct {
+	Command    []string // Command words, e.g. "utm", "exec"
+	Positional []string // Keys passed as arguments, in order
+	Rest       string   // Key whose words follow a "--" separator
+}
+
+// Operations are the step kinds besides "goup".
+var Operations = map[string]Operation{
+	"build":      {Command: []string{"build"}, Positional: []string{"platform", "app"}},
+	"bundle":     {Command: []string{"bundle"}, Positional: []string{"platform", "app"}},
+	"icons":      {Command: []string{"icons"}, Positional: []string{"platform", "app"}},
+	"run":        {Command: []string{"run"}, Positional: []string{"platform", "app"}},
+	"utm-exec":   {Command: []string{"utm", "exec"}, Positional: []string{"vm"}, Rest: "command"},
+	"ios-boot":   {Command: []string{"ios", "boot"}, Positional: []string{"device"}},
+	"screenshot": {Command: []string{"screenshot"}, Positional: []string{"output"}},
+	"publish":    {Command: []string{"publish", "metadata"}, Positional: []string{"platform", "app"}},
+}
+
+// Step is one operation, optionally guarded by a condition.
+type Step struct {
+	Name            string
+	If              string
+	ContinueOnError bool
+	Op              string         // Key of Operations, or "goup"
+	Params          map[string]any // Operation parameters
+	Args            []string       // "goup" arguments
+}
+
+// Flow is a parsed flow file.
+type Flow struct {
+	Vars  map[string]string `yaml:"vars"`
+	Steps []Step            `yaml:"steps"`
+}
+
+// UnmarshalYAML reads the name, if and continueOnError keys and exactly
+// one operation key.
+func (s *Step) UnmarshalYAML(node *yaml.Node) error {
+	var fields map[string]yaml.Node
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+	for key, value := range fields {
+		var err error
+		switch key {
+		case "name":
+			err = value.Decode(&s.Name)
+		case "if":
+			err = value.Decode(&s.If)
+		case "continueOnError":
+			err = value.Decode(&s.ContinueOnError)
+		case "goup":
+			if s.Op != "" {
+				return fmt.Errorf("line %d: step has both %s and %s", node.Line, s.Op, key)
+			}
+			s.Op = key
+			if value.Kind == yaml.ScalarNode {
+				s.Args = strings.Fields(value.Value)
+			} else {
+				err = value.Decode(&s.Args)
+			}
+		default:
+			if _, ok := Operations[key]; !ok {
+				return fmt.Errorf("line %d: unknown step %q (use %s)", node.Line, key, strings.Join(opNames(), ", "))
+			}
+			if s.Op != "" {
+				return fmt.Errorf("line %d: step has both %s and %s", node.Line, s.Op, key)
+			}
+			s.Op = key
+			err = value.Decode(&s.Params)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %s: %w", value.Line, key, err)
+		}
+	}
+	if s.Op == "" {
+		return fmt.Errorf("line %d: step has no operation (use %s)", node.Line, strings.Join(opNames(), ", "))
+	}
+	return nil
+}
+
+func opNames() []string {
+	names := []string{"goup"}
+	for name := range Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load reads a flow file.
+func Load(path string) (*Flow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, path)
+}
+
+// Parse decodes a flow; path is used in errors.
+func Parse(data []byte, path string) (*Flow, error) {
+	var f Flow
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(f.Steps) == 0 {
+		return nil, fmt.Errorf("%s has no steps", path)
+	}
+	return &f, nil
+}
+
+// Options control a run.
+type Options struct {
+	Vars   map[string]string         // Override the flow's vars
+	DryRun bool                      // Print the commands without running them
+	Out    io.Writer                 // Progress output; os.Stdout when nil
+	Exec   func(args []string) error // Runs goup-util with args
+}
+
+var varRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// Run executes the steps in order, stopping at the first failure of a
+// step without continueOnError.
+func (f *Flow) Run(opts Options) error {
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	vars := map[string]string{"os": runtime.GOOS, "arch": runtime.GOARCH}
+	for k, v := range f.Vars {
+		vars[k] = v
+	}
+	for k, v := range opts.Vars {
+		vars[k] = v
+	}
+
+	var failed []string
+	for i, step := range f.Steps {
+		label := step.Name
+		if label == "" {
+			label = step.Op
+		}
+		label = fmt.Sprintf("[%d/%d] %s", i+1, len(f.Steps), label)
+
+		cond, err := expand(step.If, vars)
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		if !truthy(cond) {
+			fmt.Fprintf(out, "⏭️  %s (skipped: %s)\n", label, step.If)
+			continue
+		}
+		args, err := step.command(vars)
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		fmt.Fprintf(out, "▶️  %s: goup-util %s\n", label, strings.Join(args, " "))
+		if opts.DryRun {
+			continue
+		}
+		if err := opts.Exec(args); err != nil {
+			if !step.ContinueOnError {
+				return fmt.Errorf("%s failed: %w", label, err)
+			}
+			fmt.Fprintf(out, "⚠️  %s failed: %v (continuing)\n", label, err)
+			failed = append(failed, label)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(out, "⚠️  %d step(s) failed: %s\n", len(failed), strings.Join(failed, ", "))
+	} else {
+		fmt.Fprintln(out, "✅ Flow complete")
+	}
+	return nil
+}
+
+// command is the goup-util arguments for a step.
+func (s *Step) command(vars map[string]string) ([]string, error) {
+	if s.Op == "goup" {
+		return expandAll(s.Args, vars)
+	}
+	op := Operations[s.Op]
+	args := append([]string{}, op.Command...)
+	used := map[string]bool{}
+	for _, key := range op.Positional {
+		if v, ok := s.Params[key]; ok {
+			args = append(args, fmt.Sprint(v))
+			used[key] = true
+		}
+	}
+
+	var flags []string
+	for key := range s.Params {
+		if !used[key] && key != op.Rest {
+			flags = append(flags, key)
+		}
+	}
+	sort.Strings(flags)
+	for _, key := range flags {
+		switch v := s.Params[key].(type) {
+		case bool:
+			if v {
+				args = append(args, "--"+key)
+			} else {
+				args = append(args, "--"+key+"=false")
+			}
+		case []any:
+			for _, item := range v {
+				args = append(args, fmt.Sprintf("--%s=%v", key, item))
+			}
+		default:
+			args = append(args, fmt.Sprintf("--%s=%v", key, v))
+		}
+	}
+	if op.Rest != "" {
+		rest, ok := s.Params[op.Rest].(string)
+		if !ok || rest == "" {
+			return nil, fmt.Errorf("%s needs %s", s.Op, op.Rest)
+		}
+		args = append(args, "--")
+		args = append(args, strings.Fields(rest)...)
+	}
+	return expandAll(args, vars)
+}
+
+func expandAll(args []string, vars map[string]string) ([]string, error) {
+	out := make([]string, len(args))
+	for i, a := range args {
+		v, err := expand(a, vars)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// expand substitutes ${name} with a flow variable or, failing that, an
+// environment variable. Undefined names are an error.
+func expand(s string, vars map[string]string) (string, error) {
+	var missing []string
+	result := varRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := varRef.FindStringSubmatch(ref)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		missing = append(missing, name)
+		return ref
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable %s (set it in vars or with --var)", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+// truthy evaluates an expanded condition: "a == b", "a != b", or a single
+// value that is true unless empty, false, 0 or no. No condition is true.
+func truthy(cond string) bool {
+	cond = strings.TrimSpace(cond)
+	if cond == "" {
+		return true
+	}
+	for _, op := range []string{"==", "!="} {
+		if a, b, ok := strings.Cut(cond, op); ok {
+			equal := unquote(a) == unquote(b)
+			return equal == (op == "==")
+		}
+	}
+	switch strings.ToLower(unquote(cond)) {
+	case "", "false", "0", "no":
+		return false
+	}
+	return true
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `"'`)
+}
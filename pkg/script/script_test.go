@@ -0,0 +1,103 @@
+package script
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const flow = `
+vars:
+  app: examples/hybrid-dashboard
+  target: macos
+steps:
+  - icons: {platform: "${target}", app: "${app}"}
+  - name: Build
+    build: {platform: "${target}", app: "${app}", force: true, schemes: [a, b]}
+  - ios-boot: {device: iPhone 16}
+    if: ${target} == ios
+  - utm-exec: {vm: Windows 11, command: "build windows ${app}"}
+    continueOnError: true
+  - goup: bundle ${target} ${app}
+`
+
+func TestRun(t *testing.T) {
+	f, err := Parse([]byte(flow), "flow.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ran [][]string
+	var out bytes.Buffer
+	err = f.Run(Options{
+		Vars: map[string]string{"app": "my app"},
+		Out:  &out,
+		Exec: func(args []string) error {
+			ran = append(ran, args)
+			if args[0] == "utm" {
+				return errors.New("no VM")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"icons", "macos", "my app"},
+		{"build", "macos", "my app", "--force", "--schemes=a", "--schemes=b"},
+		{"utm", "exec", "Windows 11", "--", "build", "windows", "my app"},
+		{"bundle", "macos", "my app"}, // Split before expansion
+	}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran = %q", ran)
+	}
+	if !strings.Contains(out.String(), "skipped: ${target} == ios") || !strings.Contains(out.String(), "1 step(s) failed") {
+		t.Errorf("output:\n%s", out.String())
+	}
+
+	// A failure without continueOnError stops the flow
+	ran = nil
+	err = f.Run(Options{Out: &out, Exec: func(args []string) error {
+		ran = append(ran, args)
+		return errors.New("boom")
+	}})
+	if err == nil || len(ran) != 1 || !strings.Contains(err.Error(), "[1/5] icons failed") {
+		t.Errorf("err = %v after %d steps", err, len(ran))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"steps:\n  - deploy: {}\n",
+		"steps:\n  - build: {}\n    icons: {}\n",
+		"steps:\n  - name: nothing\n",
+		"vars: {}\n",
+	} {
+		if _, err := Parse([]byte(src), "flow.yaml"); err == nil {
+			t.Errorf("no error for %q", src)
+		}
+	}
+
+	f, _ := Parse([]byte("steps:\n  - build: {app: \"${nope}\"}\n"), "flow.yaml")
+	if err := f.Run(Options{Out: &bytes.Buffer{}, Exec: func([]string) error { return nil }}); err == nil || !strings.Contains(err.Error(), "undefined variable nope") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	for cond, want := range map[string]bool{
+		"":                 true,
+		"darwin == darwin": true,
+		"linux == darwin":  false,
+		"'a' != \"b\"":     true,
+		"false":            false,
+		"0":                false,
+		"yes":              true,
+	} {
+		if got := truthy(cond); got != want {
+			t.Errorf("truthy(%q) = %v", cond, got)
+		}
+	}
+}
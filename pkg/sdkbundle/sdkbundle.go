@@ -0,0 +1,550 @@
+// Package sdkbundle writes and reads offline installation bundles: one tar
+// holding goup-util, gogio and the installed SDKs, with checksums, so
+// machines without network access can be set up from a file:
+//
+//	goup-util                  the goup-util binary
+//	bin/gogio                  gogio, if it was installed
+//	sdks/<name>.tar.gz         one archive per SDK directory
+//	downloads/<sha256>         cached SDK downloads, if asked for
+//	manifest.json              what is in the bundle (see Manifest)
+//	SHA256SUMS                 for checking by hand: sha256sum -c SHA256SUMS
+//
+// SDKs are archived from their installed directories, so sdkmanager
+// packages are included too. A bundle only works on the OS and
+// architecture it was made on.
+package sdkbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Member names
+const (
+	ManifestName = "manifest.json"
+	SumsName     = "SHA256SUMS"
+	GogioName    = "bin/gogio"
+)
+
+// FormatVersion is the bundle format version.
+const FormatVersion = 1
+
+// Manifest describes a bundle.
+type Manifest struct {
+	Version   int        `json:"bundleVersion"`
+	Created   time.Time  `json:"created"`
+	OS        string     `json:"os"`
+	Arch      string     `json:"arch"`
+	Platforms []string   `json:"platforms,omitempty"`
+	GoupUtil  *File      `json:"goupUtil,omitempty"`
+	Gogio     *File      `json:"gogio,omitempty"`
+	SDKs      []SDK      `json:"sdks"`
+	Downloads []Download `json:"downloads,omitempty"`
+}
+
+// File is one member and its checksum.
+type File struct {
+	Name    string `json:"file"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	Version string `json:"version,omitempty"`
+}
+
+// SDK is an SDK directory, archived as a tar.gz member.
+type SDK struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Checksum    string `json:"checksum,omitempty"`    // Catalog checksum, kept for the install cache
+	InstallPath string `json:"installPath,omitempty"` // As in the install cache, e.g. sdks/ndk-bundle
+	File
+}
+
+// Download is an archive from the download cache, as it was downloaded.
+type Download struct {
+	URLs []string `json:"urls,omitempty"`
+	File
+}
+
+// Writer builds a bundle.
+type Writer struct {
+	f        *os.File
+	tw       *tar.Writer
+	manifest Manifest
+}
+
+// Create starts a bundle at path.
+func Create(path string, platforms []string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		f:  f,
+		tw: tar.NewWriter(f),
+		manifest: Manifest{
+			Version:   FormatVersion,
+			Created:   time.Now().UTC(),
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			Platforms: platforms,
+		},
+	}, nil
+}
+
+// AddGoupUtil adds the goup-util binary at src.
+func (w *Writer) AddGoupUtil(src, version string) error {
+	name := "goup-util"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	file, err := w.addFile(name, src, 0755)
+	if err != nil {
+		return err
+	}
+	file.Version = version
+	w.manifest.GoupUtil = file
+	return nil
+}
+
+// AddGogio adds the gogio binary at src.
+func (w *Writer) AddGogio(src, version string) error {
+	name := GogioName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	file, err := w.addFile(name, src, 0755)
+	if err != nil {
+		return err
+	}
+	file.Version = version
+	w.manifest.Gogio = file
+	return nil
+}
+
+// AddSDK archives the installed SDK directory dir.
+func (w *Writer) AddSDK(sdk SDK, dir string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(w.f.Name()), ".sdk-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := archiveDir(tmp, dir); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", sdk.Name, err)
+	}
+	file, err := w.addFile("sdks/"+sdk.Name+".tar.gz", tmp.Name(), 0644)
+	if err != nil {
+		return err
+	}
+	sdk.File = *file
+	w.manifest.SDKs = append(w.manifest.SDKs, sdk)
+	return nil
+}
+
+// AddDownload adds the downloaded archive at src, whose SHA-256 is sum.
+func (w *Writer) AddDownload(src, sum string, urls []string) error {
+	file, err := w.addFile("downloads/"+sum, src, 0644)
+	if err != nil {
+		return err
+	}
+	if file.SHA256 != sum {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", src, sum, file.SHA256)
+	}
+	w.manifest.Downloads = append(w.manifest.Downloads, Download{URLs: urls, File: *file})
+	return nil
+}
+
+// addFile copies src into the bundle as name, hashing it on the way.
+func (w *Writer) addFile(name, src string, mode int64) (*File, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return nil, err
+	}
+	hdr := &tar.Header{Name: name, Mode: mode, Size: info.Size(), ModTime: info.ModTime()}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w.tw, h), in); err != nil {
+		return nil, fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return &File{Name: name, SHA256: hex.EncodeToString(h.Sum(nil)), Size: info.Size()}, nil
+}
+
+func (w *Writer) addBytes(name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: w.manifest.Created}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+// Close writes the manifest and checksums and finishes the bundle.
+func (w *Writer) Close() (*Manifest, error) {
+	defer w.f.Close()
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := w.addBytes(ManifestName, data); err != nil {
+		return nil, err
+	}
+	var sums strings.Builder
+	for _, f := range w.manifest.files() {
+		fmt.Fprintf(&sums, "%s  %s\n", f.SHA256, f.Name)
+	}
+	if err := w.addBytes(SumsName, []byte(sums.String())); err != nil {
+		return nil, err
+	}
+	if err := w.tw.Close(); err != nil {
+		return nil, err
+	}
+	return &w.manifest, w.f.Close()
+}
+
+// Abort closes and removes an unfinished bundle.
+func (w *Writer) Abort() {
+	w.f.Close()
+	os.Remove(w.f.Name())
+}
+
+// files lists every member with a checksum.
+func (m *Manifest) files() []File {
+	var files []File
+	if m.GoupUtil != nil {
+		files = append(files, *m.GoupUtil)
+	}
+	if m.Gogio != nil {
+		files = append(files, *m.Gogio)
+	}
+	for _, sdk := range m.SDKs {
+		files = append(files, sdk.File)
+	}
+	for _, d := range m.Downloads {
+		files = append(files, d.File)
+	}
+	return files
+}
+
+// archiveDir writes dir as a tar.gz, keeping symlinks (JDKs and the NDK
+// use them).
+func archiveDir(w io.Writer, dir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil // Sockets, devices, ...
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Bundle is an opened bundle file.
+type Bundle struct {
+	Path     string
+	Manifest *Manifest
+}
+
+// Open reads the manifest of the bundle at path.
+func Open(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// The manifest is written last; os.File lets tar seek past the SDKs
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s is not a goup-util bundle: no %s", path, ManifestName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if hdr.Name != ManifestName {
+			continue
+		}
+		var m Manifest
+		if err := json.NewDecoder(tr).Decode(&m); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", ManifestName, err)
+		}
+		if m.Version > FormatVersion {
+			return nil, fmt.Errorf("bundle format %d is newer than this goup-util supports (%d)", m.Version, FormatVersion)
+		}
+		return &Bundle{Path: path, Manifest: &m}, nil
+	}
+}
+
+// CheckHost reports an error if the bundle was made for another OS or
+// architecture.
+func (b *Bundle) CheckHost() error {
+	if b.Manifest.OS != runtime.GOOS || b.Manifest.Arch != runtime.GOARCH {
+		return fmt.Errorf("bundle is for %s/%s, this machine is %s/%s", b.Manifest.OS, b.Manifest.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+	return nil
+}
+
+// Targets says where Install puts things.
+type Targets struct {
+	// SDK returns the directory for sdk, or "" to skip it.
+	SDK func(sdk SDK) (string, error)
+	// Gogio is the path to install gogio at, or "" to skip it.
+	Gogio string
+	// Download, if set, is given each cached download, verified and
+	// extracted to a temporary file at path.
+	Download func(d Download, path string) error
+}
+
+// Install extracts the bundle's SDKs and gogio, verifying each checksum
+// before anything replaces what is installed. It returns the SDKs it
+// installed.
+func (b *Bundle) Install(targets Targets) ([]SDK, error) {
+	wanted := map[string]SDK{}
+	for _, sdk := range b.Manifest.SDKs {
+		wanted[sdk.Name] = sdk
+	}
+	downloads := map[string]Download{}
+	for _, d := range b.Manifest.Downloads {
+		downloads[d.Name] = d
+	}
+
+	f, err := os.Open(b.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var installed []SDK
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return installed, nil
+		}
+		if err != nil {
+			return installed, err
+		}
+
+		if g := b.Manifest.Gogio; g != nil && hdr.Name == g.Name && targets.Gogio != "" {
+			if err := installFile(tr, *g, targets.Gogio); err != nil {
+				return installed, fmt.Errorf("gogio: %w", err)
+			}
+			continue
+		}
+
+		if d, ok := downloads[hdr.Name]; ok && targets.Download != nil {
+			if err := installDownload(tr, d, targets.Download); err != nil {
+				return installed, fmt.Errorf("%s: %w", d.Name, err)
+			}
+			continue
+		}
+
+		name, ok := strings.CutSuffix(strings.TrimPrefix(hdr.Name, "sdks/"), ".tar.gz")
+		sdk, found := wanted[name]
+		if !ok || !found || hdr.Name != sdk.File.Name {
+			continue
+		}
+		dest, err := targets.SDK(sdk)
+		if err != nil {
+			return installed, err
+		}
+		if dest == "" {
+			continue
+		}
+		if err := installDir(tr, sdk.File, dest); err != nil {
+			return installed, fmt.Errorf("%s: %w", sdk.Name, err)
+		}
+		installed = append(installed, sdk)
+	}
+}
+
+// installFile writes r to dest once its checksum matches.
+func installFile(r io.Reader, want File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp := dest + ".importing"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, h), r)
+	out.Close()
+	if err == nil {
+		err = verify(h.Sum(nil), want)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// installDownload verifies a cached download into a temporary file and
+// hands it to fn.
+func installDownload(r io.Reader, d Download, fn func(Download, string) error) error {
+	tmp, err := os.CreateTemp("", "goup-download-*")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := installFile(r, d.File, tmp.Name()); err != nil {
+		return err
+	}
+	return fn(d, tmp.Name())
+}
+
+// installDir extracts the tar.gz in r next to dest, then swaps it in once
+// the checksum matches.
+func installDir(r io.Reader, want File, dest string) error {
+	staging := dest + ".importing"
+	os.RemoveAll(staging)
+	h := sha256.New()
+	err := extractTarGz(io.TeeReader(r, h), staging)
+	if err == nil {
+		// Drain gzip padding so the hash covers the whole member
+		_, err = io.Copy(h, r)
+	}
+	if err == nil {
+		err = verify(h.Sum(nil), want)
+	}
+	if err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to replace %s: %w", dest, err)
+	}
+	return os.Rename(staging, dest)
+}
+
+func verify(sum []byte, want File) error {
+	if got := hex.EncodeToString(sum); got != want.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s (the bundle is damaged)", want.Name, want.SHA256, got)
+	}
+	return nil
+}
+
+// extractTarGz unpacks r into dest, refusing entries and symlinks that
+// point outside it.
+func extractTarGz(r io.Reader, dest string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "." {
+			continue
+		}
+		if !fs.ValidPath(name) {
+			return fmt.Errorf("unsafe path in archive: %s", hdr.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(hdr.Mode)&0777|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0777|0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			resolved := path.Join(path.Dir(name), hdr.Linkname)
+			if path.IsAbs(hdr.Linkname) || !fs.ValidPath(resolved) {
+				return fmt.Errorf("unsafe symlink in archive: %s -> %s", hdr.Name, hdr.Linkname)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil && !errors.Is(err, fs.ErrExist) {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package sdkbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBundle(t *testing.T, sdkDir string) string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "bundle.tar")
+	w, err := Create(out, []string{"android"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(t.TempDir(), "goup-util")
+	os.WriteFile(exe, []byte("binary"), 0755)
+	if err := w.AddGoupUtil(exe, "v1.2.3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddGogio(exe, "v0.0.0-test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddSDK(SDK{Name: "ndk-bundle", InstallPath: "sdks/ndk-bundle"}, sdkDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "toolchains", "bin"), 0755)
+	os.WriteFile(filepath.Join(src, "toolchains", "bin", "clang"), []byte("clang"), 0755)
+	os.WriteFile(filepath.Join(src, "source.properties"), []byte("Pkg.Revision = 26.1\n"), 0644)
+	if err := os.Symlink("toolchains/bin/clang", filepath.Join(src, "clang")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	b, err := Open(writeBundle(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Manifest.GoupUtil.Version != "v1.2.3" || len(b.Manifest.SDKs) != 1 || b.CheckHost() != nil {
+		t.Fatalf("manifest = %+v", b.Manifest)
+	}
+
+	dest := filepath.Join(t.TempDir(), "sdks", "ndk-bundle")
+	os.MkdirAll(dest, 0755)
+	os.WriteFile(filepath.Join(dest, "stale"), nil, 0644)
+	gogio := filepath.Join(t.TempDir(), "bin", "gogio")
+	installed, err := b.Install(Targets{
+		SDK:   func(SDK) (string, error) { return dest, nil },
+		Gogio: gogio,
+	})
+	if err != nil || len(installed) != 1 {
+		t.Fatalf("Install = %v, %v", installed, err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dest, "clang")); err != nil || string(data) != "clang" {
+		t.Errorf("symlinked clang = %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "toolchains", "bin", "clang")); err != nil || info.Mode()&0100 == 0 {
+		t.Errorf("clang lost its exec bit: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "stale")); !os.IsNotExist(err) {
+		t.Error("previous install was not replaced")
+	}
+	if data, _ := os.ReadFile(gogio); string(data) != "binary" {
+		t.Errorf("gogio = %q", data)
+	}
+}
+
+func TestInstallRejectsDamagedBundle(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "adb"), []byte(strings.Repeat("adb", 1000)), 0755)
+	path := writeBundle(t, src)
+
+	b, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Claim a different checksum, as if the SDK archive was corrupted
+	b.Manifest.SDKs[0].SHA256 = strings.Repeat("0", 64)
+
+	dest := filepath.Join(t.TempDir(), "platform-tools")
+	os.MkdirAll(dest, 0755)
+	os.WriteFile(filepath.Join(dest, "adb"), []byte("old"), 0755)
+	_, err = b.Install(Targets{SDK: func(SDK) (string, error) { return dest, nil }})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Install = %v, want checksum mismatch", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "adb")); string(data) != "old" {
+		t.Error("damaged bundle replaced the installed SDK")
+	}
+	if _, err := os.Stat(dest + ".importing"); !os.IsNotExist(err) {
+		t.Error("staging directory left behind")
+	}
+}
+
+func TestExtractRefusesEscapes(t *testing.T) {
+	for name, hdr := range map[string]*tar.Header{
+		"path":    {Name: "../evil", Typeflag: tar.TypeReg, Mode: 0644},
+		"symlink": {Name: "lib/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"},
+		"abslink": {Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+	} {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		tw.WriteHeader(hdr)
+		tw.Close()
+		gz.Close()
+		if err := extractTarGz(&buf, t.TempDir()); err == nil {
+			t.Errorf("%s: extracted an entry outside the destination", name)
+		}
+	}
+}
+
+func TestOpenRejectsOtherTars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.tar")
+	f, _ := os.Create(path)
+	tw := tar.NewWriter(f)
+	tw.WriteHeader(&tar.Header{Name: "README", Mode: 0644})
+	tw.Close()
+	f.Close()
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "not a goup-util bundle") {
+		t.Errorf("Open = %v", err)
+	}
+}
+
+func TestDownloads(t *testing.T) {
+	blob := filepath.Join(t.TempDir(), "blob")
+	os.WriteFile(blob, []byte("archive"), 0644)
+	sum := "0e0b0b7ad6a3c4ef4f15a42cd6e2e4cc3bb1ac4b6e3e7bc19a8c8c2b7b8dd1c8"
+
+	out := filepath.Join(t.TempDir(), "bundle.tar")
+	w, err := Create(out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddDownload(blob, sum, nil); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("AddDownload with a wrong checksum = %v", err)
+	}
+	w.Abort()
+
+	w, _ = Create(out, nil)
+	h := sha256.Sum256([]byte("archive"))
+	sum = hex.EncodeToString(h[:])
+	if err := w.AddDownload(blob, sum, []string{"https://example.com/a.zip"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	_, err = b.Install(Targets{Download: func(d Download, path string) error {
+		data, _ := os.ReadFile(path)
+		got = append(got, d.URLs[0], string(data))
+		return nil
+	}})
+	if err != nil || strings.Join(got, " ") != "https://example.com/a.zip archive" {
+		t.Errorf("Install = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,209 @@
+// Package sdklock reads and writes goup.lock, which pins the SDKs and
+// tools a project is built with so every machine and CI run gets the
+// same ones:
+//
+//	{
+//	  "lockVersion": 1,
+//	  "sdks": {
+//	    "cmdline-tools-11.0": {"version": "11.0", "checksum": "sha256:..."},
+//	    "ndk-bundle": {"package": "ndk-bundle", "revision": "26.1.10909125"},
+//	    "openjdk-17": {"version": "17", "checksums": {"darwin": "...", "linux": "..."}}
+//	  },
+//	  "gogio": {"path": "gioui.org/cmd/gogio", "version": "v0.0.0-20240101000000-abcdef123456"}
+//	}
+//
+// Catalog fields (version, checksums, package) are checked against the SDK
+// catalog built into goup-util. The revision is what sdkmanager actually
+// installed, since sdkmanager packages are not versioned in the catalog.
+package sdklock
+
+import (
+	"bufio"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+)
+
+// FileName is the lockfile, kept next to goup.json.
+const FileName = "goup.lock"
+
+// CurrentVersion is the lockfile format version.
+const CurrentVersion = 1
+
+// Lock is the content of goup.lock.
+type Lock struct {
+	Version int            `json:"lockVersion"`
+	SDKs    map[string]SDK `json:"sdks"`
+	Gogio   *Tool          `json:"gogio,omitempty"`
+}
+
+// SDK pins one catalog entry.
+type SDK struct {
+	Version   string            `json:"version,omitempty"`
+	Checksum  string            `json:"checksum,omitempty"`
+	Checksums map[string]string `json:"checksums,omitempty"` // Per platform, as in the catalog
+	Package   string            `json:"package,omitempty"`   // sdkmanager package
+	Revision  string            `json:"revision,omitempty"`  // Installed Pkg.Revision
+}
+
+// Tool pins a Go-installed tool by package path and module version.
+type Tool struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+}
+
+// FromCatalog returns the catalog fields of item as a lock entry.
+func FromCatalog(item *config.SdkItem) SDK {
+	sdk := SDK{
+		Version:  item.Version,
+		Checksum: item.Checksum,
+		Package:  item.SdkManagerName,
+	}
+	for platform, p := range item.Platforms {
+		if sdk.Checksums == nil {
+			sdk.Checksums = map[string]string{}
+		}
+		sdk.Checksums[platform] = p.Checksum
+	}
+	return sdk
+}
+
+// sameCatalog reports whether two entries agree on the catalog fields.
+func (s SDK) sameCatalog(other SDK) bool {
+	return s.Version == other.Version && s.Checksum == other.Checksum &&
+		s.Package == other.Package && maps.Equal(s.Checksums, other.Checksums)
+}
+
+// Path returns the lockfile path in dir.
+func Path(dir string) string {
+	return filepath.Join(dir, FileName)
+}
+
+// Load reads goup.lock from dir.
+func Load(dir string) (*Lock, error) {
+	data, err := os.ReadFile(Path(dir))
+	if err != nil {
+		return nil, err
+	}
+	var l Lock
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	if l.Version > CurrentVersion {
+		return nil, fmt.Errorf("%s has lockVersion %d; this goup-util understands up to %d (run: goup-util self upgrade)", FileName, l.Version, CurrentVersion)
+	}
+	if l.SDKs == nil {
+		l.SDKs = map[string]SDK{}
+	}
+	return &l, nil
+}
+
+// Save writes l to dir as indented JSON with sorted keys, so it diffs
+// cleanly in review.
+func (l *Lock) Save(dir string) error {
+	l.Version = CurrentVersion
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(Path(dir), append(data, '\n'), 0644)
+}
+
+// Names returns the locked SDK names in install order: direct downloads
+// (JDK, command-line tools) before the sdkmanager packages that need them.
+func (l *Lock) Names() []string {
+	names := slices.Sorted(maps.Keys(l.SDKs))
+	slices.SortStableFunc(names, func(a, b string) int {
+		pa, pb := l.SDKs[a].Package != "", l.SDKs[b].Package != ""
+		switch {
+		case pa == pb:
+			return 0
+		case pa:
+			return 1
+		default:
+			return -1
+		}
+	})
+	return names
+}
+
+// Check compares the lock with the SDK catalog and returns one message per
+// entry that is missing from it or differs.
+func (l *Lock) Check(find func(name string) (*config.SdkItem, error)) []string {
+	var problems []string
+	for _, name := range slices.Sorted(maps.Keys(l.SDKs)) {
+		item, err := find(name)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: not in the SDK catalog", name))
+			continue
+		}
+		locked, catalog := l.SDKs[name], FromCatalog(item)
+		if !locked.sameCatalog(catalog) {
+			problems = append(problems, fmt.Sprintf("%s: locked %s, catalog has %s", name, locked.describe(), catalog.describe()))
+		}
+	}
+	return problems
+}
+
+func (s SDK) describe() string {
+	var parts []string
+	if s.Version != "" {
+		parts = append(parts, "version "+s.Version)
+	}
+	if s.Package != "" {
+		parts = append(parts, "package "+s.Package)
+	}
+	if s.Checksum != "" {
+		parts = append(parts, "checksum "+s.Checksum)
+	}
+	for _, platform := range slices.Sorted(maps.Keys(s.Checksums)) {
+		if sum := s.Checksums[platform]; sum != "" {
+			parts = append(parts, platform+" checksum "+sum)
+		}
+	}
+	if len(parts) == 0 {
+		return "no version"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Revision reads Pkg.Revision from an sdkmanager package's
+// source.properties. It returns "" when there is none.
+func Revision(installDir string) string {
+	f, err := os.Open(filepath.Join(installDir, "source.properties"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(key) == "Pkg.Revision" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// ToolAt reads the package path and module version a Go binary was built
+// from.
+func ToolAt(binary string) (*Tool, error) {
+	info, err := buildinfo.ReadFile(binary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build info from %s: %w", binary, err)
+	}
+	return &Tool{Path: info.Path, Version: info.Main.Version, Sum: info.Main.Sum}, nil
+}
+
+// Pinnable reports whether t can be reinstalled with go install path@version.
+func (t *Tool) Pinnable() bool {
+	return t.Path != "" && t.Version != "" && t.Version != "(devel)"
+}
@@ -0,0 +1,114 @@
+package sdklock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeblew999/goup-util/pkg/config"
+)
+
+var catalog = map[string]*config.SdkItem{
+	"cmdline-tools-11.0": {Version: "11.0", Checksum: "sha256:aaa"},
+	"ndk-bundle":         {SdkManagerName: "ndk-bundle"},
+	"openjdk-17": {Version: "17", Platforms: map[string]config.Platform{
+		"darwin": {Checksum: "d"}, "linux": {Checksum: "l"},
+	}},
+}
+
+func find(name string) (*config.SdkItem, error) {
+	if item, ok := catalog[name]; ok {
+		return item, nil
+	}
+	return nil, fmt.Errorf("SDK '%s' not found", name)
+}
+
+func TestCheck(t *testing.T) {
+	lock := &Lock{SDKs: map[string]SDK{}}
+	for name, item := range catalog {
+		lock.SDKs[name] = FromCatalog(item)
+	}
+	// The installed revision is not part of the catalog
+	ndk := lock.SDKs["ndk-bundle"]
+	ndk.Revision = "26.1.10909125"
+	lock.SDKs["ndk-bundle"] = ndk
+
+	if problems := lock.Check(find); len(problems) != 0 {
+		t.Fatalf("fresh lock has problems: %v", problems)
+	}
+
+	jdk := lock.SDKs["openjdk-17"]
+	jdk.Checksums = map[string]string{"darwin": "d", "linux": "old"}
+	lock.SDKs["openjdk-17"] = jdk
+	lock.SDKs["build-tools-99"] = SDK{Version: "99"}
+
+	problems := lock.Check(find)
+	if len(problems) != 2 ||
+		!strings.HasPrefix(problems[0], "build-tools-99: not in the SDK catalog") ||
+		!strings.Contains(problems[1], "linux checksum old") {
+		t.Errorf("problems = %q", problems)
+	}
+}
+
+func TestNamesInstallDownloadsFirst(t *testing.T) {
+	lock := &Lock{SDKs: map[string]SDK{
+		"build-tools-34.0.0": {Package: "build-tools;34.0.0"},
+		"openjdk-17":         {Version: "17"},
+		"ndk-bundle":         {Package: "ndk-bundle"},
+		"cmdline-tools-11.0": {Version: "11.0"},
+	}}
+	got := strings.Join(lock.Names(), " ")
+	if want := "cmdline-tools-11.0 openjdk-17 build-tools-34.0.0 ndk-bundle"; got != want {
+		t.Errorf("Names = %s, want %s", got, want)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	lock := &Lock{
+		SDKs:  map[string]SDK{"openjdk-17": FromCatalog(catalog["openjdk-17"])},
+		Gogio: &Tool{Path: "gioui.org/cmd/gogio", Version: "v0.0.0-20240101000000-abcdef123456"},
+	}
+	if err := lock.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Version != CurrentVersion || loaded.SDKs["openjdk-17"].Checksums["linux"] != "l" || *loaded.Gogio != *lock.Gogio {
+		t.Errorf("round trip = %+v", loaded)
+	}
+
+	os.WriteFile(Path(dir), []byte(`{"lockVersion": 99}`), 0644)
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "self upgrade") {
+		t.Errorf("newer lockfile: %v", err)
+	}
+}
+
+func TestRevision(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "source.properties"), []byte("Pkg.Desc = Android NDK\nPkg.Revision = 26.1.10909125\n"), 0644)
+	if got := Revision(dir); got != "26.1.10909125" {
+		t.Errorf("Revision = %q", got)
+	}
+	if got := Revision(t.TempDir()); got != "" {
+		t.Errorf("Revision without source.properties = %q", got)
+	}
+}
+
+func TestToolAt(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	tool, err := ToolAt(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(tool.Path, "sdklock.test") {
+		t.Errorf("Path = %q", tool.Path)
+	}
+}
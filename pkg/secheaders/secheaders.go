@@ -0,0 +1,276 @@
+// Package secheaders checks the security headers a site sends, for sites
+// wrapped in a shell where the site, not the shell, decides what a page
+// may load and who may frame it.
+//
+//	report, err := secheaders.Audit(ctx, http.DefaultClient, "https://example.com")
+//	for _, f := range report.Findings {
+//		fmt.Println(f.Check, f.Status, f.Message)
+//	}
+//
+// Checks that every site should pass are required; the rest are Optional.
+// The shell's csp.policy (injected as a <meta> tag) can add a policy a
+// site lacks, but a meta policy cannot set frame-ancestors or report-only.
+package secheaders
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Status is the outcome of one check.
+type Status string
+
+const (
+	OK      Status = "ok"
+	Missing Status = "missing"
+	Weak    Status = "weak"
+)
+
+// HSTSMinAge is the shortest Strict-Transport-Security max-age (180 days)
+// that counts as protecting returning visitors.
+const HSTSMinAge = 180 * 24 * 60 * 60
+
+// Finding is one check of the response.
+type Finding struct {
+	Check    string `json:"check"` // Header name, "HTTPS" or "Cookie <name>"
+	Status   Status `json:"status"`
+	Value    string `json:"value,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Fix      string `json:"fix,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Report is the result of auditing a URL.
+type Report struct {
+	URL      string    `json:"url"`
+	FinalURL string    `json:"finalURL"` // After redirects
+	Status   int       `json:"status"`
+	Findings []Finding `json:"findings"`
+}
+
+// OK reports whether every required check passed.
+func (r *Report) OK() bool {
+	for _, f := range r.Findings {
+		if f.Status != OK && !f.Optional {
+			return false
+		}
+	}
+	return true
+}
+
+// Audit fetches rawURL, following redirects, and checks the final
+// response's headers.
+func Audit(ctx context.Context, client *http.Client, rawURL string) (*Report, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return &Report{
+		URL:      rawURL,
+		FinalURL: resp.Request.URL.String(),
+		Status:   resp.StatusCode,
+		Findings: Check(resp.Request.URL, resp.Header),
+	}, nil
+}
+
+// Check checks the headers of a response from u.
+func Check(u *url.URL, h http.Header) []Finding {
+	https := u.Scheme == "https"
+	var findings []Finding
+	add := func(f Finding) { findings = append(findings, f) }
+
+	if !https {
+		add(Finding{Check: "HTTPS", Status: Missing, Message: "served over plain HTTP; anyone on the network can read and change it",
+			Fix: "serve the site over HTTPS"})
+	} else {
+		add(hsts(h.Get("Strict-Transport-Security")))
+	}
+
+	csp := ParseCSP(h.Get("Content-Security-Policy"))
+	add(contentSecurityPolicy(h, csp))
+
+	frame := Finding{Check: "X-Frame-Options", Status: OK, Value: h.Get("X-Frame-Options")}
+	switch _, ancestors := csp["frame-ancestors"]; {
+	case ancestors:
+		frame.Check, frame.Value = "frame-ancestors", strings.Join(csp["frame-ancestors"], " ")
+	case strings.EqualFold(frame.Value, "DENY"), strings.EqualFold(frame.Value, "SAMEORIGIN"):
+	case frame.Value == "":
+		frame.Status, frame.Message = Missing, "other sites can frame the page (clickjacking)"
+		frame.Fix = "X-Frame-Options: DENY, or frame-ancestors 'none' in the CSP"
+	default:
+		frame.Status, frame.Message, frame.Fix = Weak, "not DENY or SAMEORIGIN", "X-Frame-Options: DENY"
+	}
+	add(frame)
+
+	nosniff := Finding{Check: "X-Content-Type-Options", Status: OK, Value: h.Get("X-Content-Type-Options")}
+	if !strings.EqualFold(nosniff.Value, "nosniff") {
+		nosniff.Status, nosniff.Message = Missing, "browsers may guess content types and run uploads as scripts"
+		nosniff.Fix = "X-Content-Type-Options: nosniff"
+	}
+	add(nosniff)
+
+	referrer := Finding{Check: "Referrer-Policy", Status: OK, Value: h.Get("Referrer-Policy"), Optional: true}
+	switch strings.ToLower(referrer.Value) {
+	case "":
+		referrer.Status, referrer.Message = Missing, "browsers default to strict-origin-when-cross-origin"
+		referrer.Fix = "Referrer-Policy: strict-origin-when-cross-origin"
+	case "unsafe-url", "no-referrer-when-downgrade":
+		referrer.Status, referrer.Message = Weak, "full URLs, with any tokens in them, go to other sites"
+		referrer.Fix = "Referrer-Policy: strict-origin-when-cross-origin"
+	}
+	add(referrer)
+
+	perms := Finding{Check: "Permissions-Policy", Status: OK, Value: h.Get("Permissions-Policy"), Optional: true}
+	if perms.Value == "" {
+		perms.Status, perms.Message = Missing, "embedded content may ask for the camera, microphone and location"
+		perms.Fix = "Permissions-Policy: camera=(), microphone=(), geolocation=() for what the site does not use"
+	}
+	add(perms)
+
+	for _, c := range (&http.Response{Header: h}).Cookies() {
+		add(cookie(c, https))
+	}
+	for _, name := range []string{"Server", "X-Powered-By"} {
+		if v := h.Get(name); versioned.MatchString(v) {
+			add(Finding{Check: name, Status: Weak, Value: v, Optional: true,
+				Message: "reveals software versions to attackers", Fix: "leave the version out"})
+		}
+	}
+	return findings
+}
+
+var versioned = regexp.MustCompile(`\d+\.\d+`)
+
+func hsts(value string) Finding {
+	f := Finding{Check: "Strict-Transport-Security", Status: OK, Value: value}
+	if value == "" {
+		f.Status, f.Message = Missing, "a first visit over HTTP can be intercepted"
+		f.Fix = "Strict-Transport-Security: max-age=31536000; includeSubDomains"
+		return f
+	}
+	for _, directive := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if strings.EqualFold(k, "max-age") {
+			if age, err := strconv.Atoi(strings.Trim(v, `"`)); err == nil && age >= HSTSMinAge {
+				return f
+			}
+		}
+	}
+	f.Status, f.Message = Weak, fmt.Sprintf("max-age is under %d days", HSTSMinAge/(24*60*60))
+	f.Fix = "Strict-Transport-Security: max-age=31536000; includeSubDomains"
+	return f
+}
+
+// CheckCSP checks a policy the shell adds, as a site's would be.
+func CheckCSP(policy string) Finding {
+	h := http.Header{}
+	h.Set("Content-Security-Policy", policy)
+	return contentSecurityPolicy(h, ParseCSP(policy))
+}
+
+func contentSecurityPolicy(h http.Header, csp map[string][]string) Finding {
+	f := Finding{Check: "Content-Security-Policy", Status: OK, Value: h.Get("Content-Security-Policy")}
+	if f.Value == "" {
+		if ro := h.Get("Content-Security-Policy-Report-Only"); ro != "" {
+			f.Check, f.Value = "Content-Security-Policy-Report-Only", ro
+			f.Status, f.Message = Weak, "the policy only reports, it blocks nothing"
+			f.Fix = "send it as Content-Security-Policy once the reports are clean"
+			return f
+		}
+		f.Status, f.Message = Missing, "injected scripts can load and run anything"
+		f.Fix = "start with Content-Security-Policy-Report-Only: default-src 'self', or set csp.policy in the shell's app.json"
+		return f
+	}
+	scripts, ok := csp["script-src"]
+	if !ok {
+		scripts, ok = csp["default-src"]
+	}
+	var problems []string
+	switch {
+	case !ok:
+		problems = append(problems, "no script-src or default-src")
+	case hasSource(scripts, "*"):
+		problems = append(problems, "scripts from any host")
+	}
+	if hasSource(scripts, "'unsafe-inline'") && !hasNonceOrHash(scripts) {
+		problems = append(problems, "'unsafe-inline' scripts")
+	}
+	if hasSource(scripts, "'unsafe-eval'") {
+		problems = append(problems, "'unsafe-eval'")
+	}
+	if len(problems) > 0 {
+		f.Status, f.Message = Weak, "allows "+strings.Join(problems, ", ")
+		f.Fix = "restrict script-src to the site's own hosts, with nonces for inline scripts"
+	}
+	return f
+}
+
+func cookie(c *http.Cookie, https bool) Finding {
+	f := Finding{Check: "Cookie " + c.Name, Status: OK, Optional: true}
+	var missing []string
+	if https && !c.Secure {
+		missing = append(missing, "Secure")
+		f.Optional = false
+	}
+	if !c.HttpOnly {
+		missing = append(missing, "HttpOnly")
+	}
+	if c.SameSite == http.SameSiteDefaultMode {
+		missing = append(missing, "SameSite")
+	}
+	if len(missing) > 0 {
+		f.Status, f.Message = Weak, "no "+strings.Join(missing, ", ")
+		f.Fix = "set " + strings.Join(missing, "; ") + " unless the page's scripts need to read it"
+	}
+	return f
+}
+
+// ParseCSP splits a policy into its directives, lower-cased, with their
+// sources. Only the first occurrence of a directive counts, as in
+// browsers.
+func ParseCSP(policy string) map[string][]string {
+	directives := map[string][]string{}
+	for _, d := range strings.Split(policy, ";") {
+		fields := strings.Fields(d)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, seen := directives[name]; !seen {
+			directives[name] = fields[1:]
+		}
+	}
+	return directives
+}
+
+func hasSource(sources []string, want string) bool {
+	for _, s := range sources {
+		if strings.EqualFold(s, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNonceOrHash reports whether sources use nonces or hashes, which make
+// browsers ignore 'unsafe-inline'.
+func hasNonceOrHash(sources []string) bool {
+	for _, s := range sources {
+		s = strings.ToLower(s)
+		if strings.HasPrefix(s, "'nonce-") || strings.HasPrefix(s, "'sha256-") ||
+			strings.HasPrefix(s, "'sha384-") || strings.HasPrefix(s, "'sha512-") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package secheaders
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func find(t *testing.T, findings []Finding, check string) Finding {
+	t.Helper()
+	for _, f := range findings {
+		if f.Check == check {
+			return f
+		}
+	}
+	t.Fatalf("no %s finding in %+v", check, findings)
+	return Finding{}
+}
+
+func TestCheck(t *testing.T) {
+	u, _ := url.Parse("https://example.com/")
+	h := http.Header{}
+	h.Set("Strict-Transport-Security", "max-age=3600")
+	h.Set("Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline'; frame-ancestors 'none'")
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Server", "nginx/1.25.3")
+	h.Add("Set-Cookie", "session=abc; HttpOnly; SameSite=Lax")
+	findings := Check(u, h)
+
+	for check, want := range map[string]Status{
+		"Strict-Transport-Security": Weak,
+		"Content-Security-Policy":   Weak,
+		"frame-ancestors":           OK,
+		"X-Content-Type-Options":    OK,
+		"Referrer-Policy":           Missing,
+		"Cookie session":            Weak,
+		"Server":                    Weak,
+	} {
+		if f := find(t, findings, check); f.Status != want {
+			t.Errorf("%s = %s (%s), want %s", check, f.Status, f.Message, want)
+		}
+	}
+	if f := find(t, findings, "Cookie session"); f.Optional || f.Message != "no Secure" {
+		t.Errorf("cookie finding = %+v", f)
+	}
+	if f := find(t, findings, "Referrer-Policy"); !f.Optional {
+		t.Error("Referrer-Policy should be optional")
+	}
+}
+
+func TestCheckNonce(t *testing.T) {
+	u, _ := url.Parse("https://example.com/")
+	h := http.Header{}
+	h.Set("Content-Security-Policy", "script-src 'self' 'nonce-r4nd0m' 'unsafe-inline'")
+	if f := find(t, Check(u, h), "Content-Security-Policy"); f.Status != OK {
+		t.Errorf("nonce policy = %+v", f)
+	}
+	h = http.Header{}
+	h.Set("Content-Security-Policy-Report-Only", "default-src 'self'")
+	if f := find(t, Check(u, h), "Content-Security-Policy-Report-Only"); f.Status != Weak {
+		t.Errorf("report-only policy = %+v", f)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+	}))
+	defer good.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(good.URL+"/home", http.StatusFound))
+	defer redirect.Close()
+
+	report, err := Audit(context.Background(), http.DefaultClient, redirect.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FinalURL != good.URL+"/home" || report.Status != http.StatusOK {
+		t.Errorf("report = %+v", report)
+	}
+	// Plain HTTP fails, the headers pass
+	if report.OK() || find(t, report.Findings, "HTTPS").Status != Missing {
+		t.Errorf("plain HTTP passed: %+v", report.Findings)
+	}
+	if f := find(t, report.Findings, "X-Frame-Options"); f.Status != OK {
+		t.Errorf("X-Frame-Options = %+v", f)
+	}
+}
+
+func TestCheckCSP(t *testing.T) {
+	if f := CheckCSP("default-src *; script-src 'self' 'unsafe-eval'"); f.Status != Weak || f.Message != "allows 'unsafe-eval'" {
+		t.Errorf("CheckCSP = %+v", f)
+	}
+}
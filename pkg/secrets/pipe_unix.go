@@ -0,0 +1,35 @@
+//go:build !windows
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// FilePath returns a path cmd can open to read a file secret. Secrets that
+// came from a file keep their path. Values from the environment or vault
+// are streamed through a pipe inherited by cmd as /dev/fd/N, so the
+// plaintext never touches the disk. The pipe can be read once.
+//
+// Call release once cmd.Start has returned, whether or not it succeeded:
+// it closes the parent's copy of the pipe, so the writer stops when cmd
+// exits or never started instead of blocking forever.
+func FilePath(cmd *exec.Cmd, s *Secret) (path string, release func(), err error) {
+	if s.Path != "" {
+		return s.Path, func() {}, nil
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create pipe for %s: %w", s.Name, err)
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, r)
+	fd := 2 + len(cmd.ExtraFiles)
+	go func() {
+		// Fails with EPIPE once every read end is closed
+		w.Write(s.Value)
+		w.Close()
+	}()
+	return fmt.Sprintf("/dev/fd/%d", fd), func() { r.Close() }, nil
+}
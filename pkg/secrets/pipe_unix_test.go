@@ -0,0 +1,51 @@
+//go:build !windows
+
+package secrets
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFilePathPipe(t *testing.T) {
+	value := bytes.Repeat([]byte("k"), 1<<20) // more than a pipe buffer holds
+	cmd := exec.Command("sh", "-c", `cat "$0"`)
+	path, release, err := FilePath(cmd, &Secret{Name: "ANDROID_KEYSTORE", Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Args = append(cmd.Args, path)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err = cmd.Start()
+	release()
+	if err == nil {
+		err = cmd.Wait()
+	}
+	if err != nil || !bytes.Equal(out.Bytes(), value) {
+		t.Fatalf("read %d bytes, %v", out.Len(), err)
+	}
+}
+
+func TestFilePathUnreadPipe(t *testing.T) {
+	before := runtime.NumGoroutine()
+	cmd := exec.Command("true")
+	_, release, err := FilePath(cmd, &Secret{Name: "ANDROID_KEYSTORE", Value: bytes.Repeat([]byte("k"), 1<<20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Start()
+	release()
+	cmd.Wait()
+
+	// The writer gives up once the command exits without reading
+	for deadline := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > before; {
+		if time.Now().After(deadline) {
+			t.Fatal("pipe writer still blocked after the command exited")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
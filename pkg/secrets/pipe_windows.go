@@ -0,0 +1,15 @@
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// FilePath returns a path cmd can open to read a file secret. Windows has
+// no /dev/fd, so only secrets that came from a file (NAME_FILE) work.
+func FilePath(cmd *exec.Cmd, s *Secret) (path string, release func(), err error) {
+	if s.Path != "" {
+		return s.Path, func() {}, nil
+	}
+	return "", nil, fmt.Errorf("%s must be given as a path on Windows: set %s_FILE", s.Name, s.Name)
+}
@@ -0,0 +1,151 @@
+// Package secrets resolves signing material and API keys for bundle and
+// publish steps from environment variables, files, or an age-encrypted
+// local vault, keeping values in memory.
+//
+// For a secret NAME the sources are tried in order:
+//
+//	NAME          the value (base64 for file secrets such as keystores)
+//	NAME_FILE     path to a file holding the value (e.g. CI secret mounts)
+//	vault         the age-encrypted vault (see Vault)
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Kind tells how a secret is consumed.
+type Kind int
+
+const (
+	Text Kind = iota // Passwords, IDs, identities: passed as values
+	File             // Keystores, certificates, keys: passed as a path
+)
+
+// Definition describes a secret goup-util knows how to use.
+type Definition struct {
+	Name        string
+	Kind        Kind
+	Description string
+	UsedBy      string
+	validate    func([]byte) error
+}
+
+// Known secrets, in display order.
+var Known = []Definition{
+	{Name: "ANDROID_KEYSTORE", Kind: File, Description: "Android signing keystore (.jks/.keystore)", UsedBy: "build android", validate: validateKeystore},
+	{Name: "ANDROID_KEYSTORE_PASSWORD", Kind: Text, Description: "Password for ANDROID_KEYSTORE", UsedBy: "build android"},
+	{Name: "MACOS_SIGNING_IDENTITY", Kind: Text, Description: `codesign identity (e.g. "Developer ID Application: ...")`, UsedBy: "bundle macos"},
+	{Name: "MACOS_INSTALLER_IDENTITY", Kind: Text, Description: "productbuild identity for App Store .pkg files", UsedBy: "bundle macos --app-store"},
+	{Name: "APPSTORE_API_KEY", Kind: File, Description: "App Store Connect API key (.p8)", UsedBy: "publish, distribute", validate: validatePEMKey},
+	{Name: "APPSTORE_API_KEY_ID", Kind: Text, Description: "App Store Connect API key ID", UsedBy: "publish, distribute", validate: validateKeyID},
+	{Name: "APPSTORE_API_ISSUER", Kind: Text, Description: "App Store Connect issuer ID", UsedBy: "publish, distribute", validate: validateUUID},
+	{Name: "GOOGLE_PLAY_SERVICE_ACCOUNT", Kind: File, Description: "Google Play service account JSON", UsedBy: "publish", validate: validateServiceAccount},
+	{Name: "FIREBASE_SERVICE_ACCOUNT", Kind: File, Description: "Firebase service account JSON (App Distribution Admin)", UsedBy: "distribute", validate: validateServiceAccount},
+	{Name: "WINDOWS_CERTIFICATE", Kind: File, Description: "Code signing certificate (.pfx)", UsedBy: "bundle windows", validate: validatePKCS12},
+	{Name: "WINDOWS_CERTIFICATE_PASSWORD", Kind: Text, Description: "Password for WINDOWS_CERTIFICATE", UsedBy: "bundle windows"},
+	{Name: "GITHUB_TOKEN", Kind: Text, Description: "GitHub token with contents: write, for release assets", UsedBy: "upload"},
+	{Name: "R2_ACCESS_KEY_ID", Kind: Text, Description: "R2 API token access key ID", UsedBy: "upload"},
+	{Name: "R2_SECRET_ACCESS_KEY", Kind: Text, Description: "Secret access key for R2_ACCESS_KEY_ID", UsedBy: "upload"},
+	{Name: "LICENSE_SIGNING_KEY", Kind: Text, Description: "ed25519 private key for license files (from 'license keygen')", UsedBy: "license generate", validate: validateEd25519Key},
+	{Name: "FLEET_COMMAND_KEY", Kind: Text, Description: "ed25519 private key for fleet commands (from 'fleet keygen')", UsedBy: "fleet send", validate: validateEd25519Key},
+}
+
+// Lookup returns the definition of a known secret.
+func Lookup(name string) (Definition, bool) {
+	for _, d := range Known {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Source says where a secret was found.
+type Source string
+
+const (
+	FromEnv   Source = "env"
+	FromFile  Source = "file"
+	FromVault Source = "vault"
+)
+
+// Secret is a resolved value.
+type Secret struct {
+	Name   string
+	Value  []byte
+	Source Source
+	Path   string // Set when Source is FromFile
+}
+
+// String returns the value of a text secret.
+func (s *Secret) String() string {
+	return strings.TrimRight(string(s.Value), "\r\n")
+}
+
+// Store resolves secrets. The vault is only decrypted when a secret is
+// not found in the environment.
+type Store struct {
+	Vault *Vault
+}
+
+// Open returns a store backed by the vault at path ("" for the default).
+func Open(vaultPath string) *Store {
+	return &Store{Vault: OpenVault(vaultPath)}
+}
+
+// Get resolves name, returning nil if no source has it.
+func (s *Store) Get(name string) (*Secret, error) {
+	def, known := Lookup(name)
+
+	if v, ok := os.LookupEnv(name); ok && v != "" {
+		value := []byte(v)
+		if known && def.Kind == File {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("%s must be base64 (or use %s_FILE with a path)", name, name)
+			}
+			value = decoded
+		}
+		return &Secret{Name: name, Value: value, Source: FromEnv}, nil
+	}
+
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return &Secret{Name: name, Value: value, Source: FromFile, Path: path}, nil
+	}
+
+	if s.Vault == nil || !s.Vault.Exists() {
+		return nil, nil
+	}
+	values, err := s.Vault.Load()
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := values[name]; ok {
+		return &Secret{Name: name, Value: value, Source: FromVault}, nil
+	}
+	return nil, nil
+}
+
+// Text resolves a text secret, returning "" if it is not set.
+func (s *Store) Text(name string) (string, error) {
+	secret, err := s.Get(name)
+	if err != nil || secret == nil {
+		return "", err
+	}
+	return secret.String(), nil
+}
+
+// Mask shows enough of a value to tell secrets apart in logs.
+func Mask(value []byte) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return fmt.Sprintf("%s… (%d bytes)", strings.Repeat("*", 4), len(value))
+}
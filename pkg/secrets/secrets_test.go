@@ -0,0 +1,52 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultRoundTrip(t *testing.T) {
+	t.Setenv(VaultKeyEnv, "")
+	t.Setenv(VaultPassphraseEnv, "")
+	dir := t.TempDir()
+	v := &Vault{Path: filepath.Join(dir, "secrets.age"), KeyPath: filepath.Join(dir, "vault.key")}
+	if _, err := v.Init(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("ANDROID_KEYSTORE_PASSWORD", []byte("hunter2\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &Store{Vault: &Vault{Path: v.Path, KeyPath: v.KeyPath}}
+	s, err := store.Get("ANDROID_KEYSTORE_PASSWORD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s == nil || s.Source != FromVault || s.String() != "hunter2" {
+		t.Fatalf("Get = %+v, want hunter2 from the vault", s)
+	}
+
+	// The environment wins over the vault.
+	t.Setenv("ANDROID_KEYSTORE_PASSWORD", "fromenv")
+	if got, _ := store.Text("ANDROID_KEYSTORE_PASSWORD"); got != "fromenv" {
+		t.Errorf("Text = %q, want the env value", got)
+	}
+}
+
+func TestFileSecretFromEnv(t *testing.T) {
+	jks := []byte{0xfe, 0xed, 0xfe, 0xed, 0, 0, 0, 2}
+	t.Setenv("ANDROID_KEYSTORE", base64.StdEncoding.EncodeToString(jks))
+	s, err := (&Store{}).Get("ANDROID_KEYSTORE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateKeystore(s.Value); err != nil {
+		t.Errorf("decoded keystore rejected: %v", err)
+	}
+
+	t.Setenv("ANDROID_KEYSTORE", "not base64!")
+	if _, err := (&Store{}).Get("ANDROID_KEYSTORE"); err == nil {
+		t.Error("expected an error for a non-base64 keystore")
+	}
+}
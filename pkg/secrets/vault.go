@@ -0,0 +1,223 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Environment variables that unlock the vault. GOUP_VAULT_KEY holds an age
+// identity ("AGE-SECRET-KEY-1...") and is the usual choice in CI;
+// GOUP_VAULT_PASSPHRASE is used for passphrase-encrypted vaults.
+const (
+	VaultKeyEnv        = "GOUP_VAULT_KEY"
+	VaultPassphraseEnv = "GOUP_VAULT_PASSPHRASE"
+)
+
+// Vault is an age-encrypted JSON map of secret names to values. It is
+// decrypted in memory only.
+type Vault struct {
+	Path    string // Encrypted vault (default <config>/goup-util/secrets.age)
+	KeyPath string // age identity file (default <config>/goup-util/vault.key)
+
+	values map[string][]byte
+}
+
+// DefaultDir is where the vault and its key live.
+func DefaultDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "goup-util")
+}
+
+// OpenVault returns the vault at path, or the default vault if path is "".
+// The key file is looked up next to the default vault.
+func OpenVault(path string) *Vault {
+	if path == "" {
+		path = filepath.Join(DefaultDir(), "secrets.age")
+	}
+	return &Vault{Path: path, KeyPath: filepath.Join(DefaultDir(), "vault.key")}
+}
+
+// Exists reports whether the vault file is present.
+func (v *Vault) Exists() bool {
+	_, err := os.Stat(v.Path)
+	return err == nil
+}
+
+// Init creates a new identity and an empty vault encrypted to it. With a
+// passphrase the vault is passphrase-encrypted instead and no key file is
+// written. It returns the age recipient ("age1...") for key-based vaults.
+func (v *Vault) Init(passphrase string) (string, error) {
+	if v.Exists() {
+		return "", fmt.Errorf("vault already exists: %s", v.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(v.Path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	v.values = make(map[string][]byte)
+	if passphrase != "" {
+		r, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return "", err
+		}
+		return "", v.save(r)
+	}
+
+	if _, err := os.Stat(v.KeyPath); err == nil {
+		return "", fmt.Errorf("key file already exists: %s", v.KeyPath)
+	}
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("# goup-util vault key, public key: %s\n%s\n", id.Recipient(), id)
+	if err := os.MkdirAll(filepath.Dir(v.KeyPath), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(v.KeyPath, []byte(key), 0600); err != nil {
+		return "", fmt.Errorf("failed to write key: %w", err)
+	}
+	return id.Recipient().String(), v.save(id.Recipient())
+}
+
+// Load decrypts the vault.
+func (v *Vault) Load() (map[string][]byte, error) {
+	if v.values != nil {
+		return v.values, nil
+	}
+	data, err := os.ReadFile(v.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+	ids, err := v.identities()
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt vault %s: %w", v.Path, err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt vault: %w", err)
+	}
+	values := make(map[string][]byte)
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, fmt.Errorf("vault is corrupt: %w", err)
+	}
+	v.values = values
+	return values, nil
+}
+
+// Set stores a value, re-encrypting the vault to the same key or passphrase.
+func (v *Vault) Set(name string, value []byte) error {
+	values, err := v.Load()
+	if err != nil {
+		return err
+	}
+	values[name] = value
+	return v.rewrite()
+}
+
+// Remove deletes a value.
+func (v *Vault) Remove(name string) error {
+	values, err := v.Load()
+	if err != nil {
+		return err
+	}
+	if _, ok := values[name]; !ok {
+		return fmt.Errorf("%s is not in the vault", name)
+	}
+	delete(values, name)
+	return v.rewrite()
+}
+
+// rewrite re-encrypts to whatever unlocked the vault.
+func (v *Vault) rewrite() error {
+	if pass := os.Getenv(VaultPassphraseEnv); pass != "" {
+		r, err := age.NewScryptRecipient(pass)
+		if err != nil {
+			return err
+		}
+		return v.save(r)
+	}
+	ids, err := v.identities()
+	if err != nil {
+		return err
+	}
+	var recipients []age.Recipient
+	for _, id := range ids {
+		if x, ok := id.(*age.X25519Identity); ok {
+			recipients = append(recipients, x.Recipient())
+		}
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("no age key available to re-encrypt the vault")
+	}
+	return v.save(recipients...)
+}
+
+func (v *Vault) save(recipients ...age.Recipient) error {
+	plain, err := json.Marshal(v.values)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(plain); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	tmp := v.Path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	return os.Rename(tmp, v.Path)
+}
+
+// identities collects the keys that may unlock the vault.
+func (v *Vault) identities() ([]age.Identity, error) {
+	var ids []age.Identity
+	if key := os.Getenv(VaultKeyEnv); key != "" {
+		parsed, err := age.ParseIdentities(strings.NewReader(key))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", VaultKeyEnv, err)
+		}
+		ids = append(ids, parsed...)
+	}
+	if data, err := os.ReadFile(v.KeyPath); err == nil {
+		parsed, err := age.ParseIdentities(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.KeyPath, err)
+		}
+		ids = append(ids, parsed...)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if pass := os.Getenv(VaultPassphraseEnv); pass != "" {
+		id, err := age.NewScryptIdentity(pass)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no vault key: set %s or %s, or create %s with 'goup-util secrets init'", VaultKeyEnv, VaultPassphraseEnv, v.KeyPath)
+	}
+	return ids, nil
+}
@@ -0,0 +1,50 @@
+package securestore
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const backend = "dpapi"
+
+// blob wraps b for the DPAPI calls.
+func blob(b []byte) *windows.DataBlob {
+	if len(b) == 0 {
+		return &windows.DataBlob{}
+	}
+	return &windows.DataBlob{Size: uint32(len(b)), Data: &b[0]}
+}
+
+// take copies the output of a DPAPI call and frees it.
+func take(out windows.DataBlob) []byte {
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...)
+}
+
+// The service name is the DPAPI entropy, so another app running as the
+// same user cannot decrypt the files without knowing it.
+func (s *Store) get(key string) ([]byte, error) {
+	data, err := s.readFile(key)
+	if err != nil {
+		return nil, err
+	}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(blob(data), nil, blob([]byte(s.Service)), 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("securestore: decrypting %s failed: %w", key, err)
+	}
+	return take(out), nil
+}
+
+func (s *Store) set(key string, secret []byte) error {
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(blob(secret), nil, blob([]byte(s.Service)), 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return fmt.Errorf("securestore: encrypting %s failed: %w", key, err)
+	}
+	return s.writeFile(key, take(out))
+}
+
+func (s *Store) remove(key string) error {
+	return s.removeFile(key)
+}
@@ -0,0 +1,17 @@
+package securestore
+
+// Android apps cannot read each other's private directory, so files
+// there are the safest store reachable without Java.
+const backend = "app-private-files"
+
+func (s *Store) get(key string) ([]byte, error) {
+	return s.readFile(key)
+}
+
+func (s *Store) set(key string, secret []byte) error {
+	return s.writeFile(key, secret)
+}
+
+func (s *Store) remove(key string) error {
+	return s.removeFile(key)
+}
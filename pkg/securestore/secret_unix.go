@@ -0,0 +1,67 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package securestore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+const backend = "secret-service"
+
+// secretTool runs secret-tool with stdin; tests replace it.
+var secretTool = func(stdin []byte, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil, fmt.Errorf("%w (install libsecret-tools and a keyring such as GNOME Keyring)", ErrUnsupported)
+	}
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, err
+}
+
+// attributes identify the secret in the Secret Service.
+func (s *Store) attributes(key string) []string {
+	return []string{"service", s.Service, "key", key}
+}
+
+// get uses 'secret-tool lookup', which exits 1 with no output for a
+// missing secret.
+func (s *Store) get(key string) ([]byte, error) {
+	out, err := secretTool(nil, append([]string{"lookup"}, s.attributes(key)...)...)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("securestore: secret-tool lookup failed: %w", err)
+	}
+	return out, nil
+}
+
+// set passes the secret on stdin so it never shows in the process list.
+func (s *Store) set(key string, secret []byte) error {
+	args := append([]string{"store", "--label=" + s.Service + " " + key}, s.attributes(key)...)
+	if _, err := secretTool(secret, args...); err != nil {
+		return fmt.Errorf("securestore: secret-tool store failed: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) remove(key string) error {
+	if _, err := secretTool(nil, append([]string{"clear"}, s.attributes(key)...)...); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return nil // Nothing to clear
+		}
+		return fmt.Errorf("securestore: secret-tool clear failed: %w", err)
+	}
+	return nil
+}
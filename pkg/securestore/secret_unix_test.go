@@ -0,0 +1,55 @@
+//go:build (linux && !android) || freebsd || openbsd
+
+package securestore
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSecretTool(t *testing.T) {
+	saved := secretTool
+	defer func() { secretTool = saved }()
+
+	secrets := map[string]string{}
+	secretTool = func(stdin []byte, args ...string) ([]byte, error) {
+		id := strings.Join(args[len(args)-4:], " ")
+		switch args[0] {
+		case "store":
+			if args[1] != "--label=app token" {
+				t.Errorf("label = %q", args[1])
+			}
+			secrets[id] = string(stdin)
+		case "lookup":
+			if s, ok := secrets[id]; ok {
+				return []byte(s), nil
+			}
+			return nil, exec.Command("false").Run()
+		case "clear":
+			delete(secrets, id)
+		}
+		return nil, nil
+	}
+
+	s := New("app")
+	if _, err := s.Get("token"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing key = %v, want ErrNotFound", err)
+	}
+	if err := s.Set("token", []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Get("token"); err != nil || string(got) != "secret" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if secrets["service app key token"] != "secret" {
+		t.Errorf("stored %v", secrets)
+	}
+	if err := s.Delete("token"); err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 0 {
+		t.Errorf("Delete left %v", secrets)
+	}
+}
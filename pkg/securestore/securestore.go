@@ -0,0 +1,138 @@
+// Package securestore keeps small secrets, such as API and refresh
+// tokens, in the operating system's credential store rather than in plain
+// files or a webview's localStorage:
+//
+//	macOS, iOS   Keychain (needs cgo, which Gio apps on Apple platforms use anyway)
+//	Windows      files encrypted with DPAPI for the signed-in user
+//	Linux, BSD   the Secret Service (GNOME Keyring, KWallet) through secret-tool
+//	Android      files in the app's private directory
+//
+// Android's Keystore is reachable only from Java, so there the secrets
+// rely on the app sandbox alone; Backend reports which store is in use.
+package securestore
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrNotFound is returned by Get for a key that has no secret.
+	ErrNotFound = errors.New("secret not found")
+	// ErrUnsupported is returned where no store is available.
+	ErrUnsupported = errors.New("no secure store on this platform")
+)
+
+// Store holds the secrets of one app.
+type Store struct {
+	// Service keeps apps' secrets apart; use the app ID or name.
+	Service string
+
+	// Dir holds the secret files on Windows and Android. It defaults to
+	// <user config dir>/<Service>/secure; Gio apps on Android should set
+	// it to app.DataDir().
+	Dir string
+}
+
+// New returns the store for service.
+func New(service string) *Store {
+	return &Store{Service: service}
+}
+
+// Backend names the store in use on this platform.
+func Backend() string {
+	return backend
+}
+
+// Get returns the secret stored under key, or ErrNotFound.
+func (s *Store) Get(key string) ([]byte, error) {
+	if err := s.check(key); err != nil {
+		return nil, err
+	}
+	return s.get(key)
+}
+
+// Set stores secret under key, replacing any previous one.
+func (s *Store) Set(key string, secret []byte) error {
+	if err := s.check(key); err != nil {
+		return err
+	}
+	return s.set(key, secret)
+}
+
+// Delete removes the secret stored under key. Deleting a missing key is
+// not an error.
+func (s *Store) Delete(key string) error {
+	if err := s.check(key); err != nil {
+		return err
+	}
+	return s.remove(key)
+}
+
+func (s *Store) check(key string) error {
+	if s.Service == "" {
+		return errors.New("securestore: no service name")
+	}
+	if key == "" {
+		return errors.New("securestore: empty key")
+	}
+	return nil
+}
+
+// path is the file holding key for the file-based stores. Keys are
+// encoded so any string makes a safe file name.
+func (s *Store) path(key string) (string, error) {
+	dir := s.Dir
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("securestore: no config directory: %w", err)
+		}
+		dir = filepath.Join(base, s.Service, "secure")
+	}
+	return filepath.Join(dir, base64.RawURLEncoding.EncodeToString([]byte(key))), nil
+}
+
+// readFile returns the contents stored for key, or ErrNotFound.
+func (s *Store) readFile(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// writeFile replaces the contents stored for key, readable only by the
+// user.
+func (s *Store) writeFile(key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Store) removeFile(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
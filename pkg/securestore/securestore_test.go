@@ -0,0 +1,44 @@
+package securestore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFiles(t *testing.T) {
+	s := &Store{Service: "com.example.app", Dir: t.TempDir()}
+	if _, err := s.readFile("token"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("readFile of a missing key = %v, want ErrNotFound", err)
+	}
+	if err := s.writeFile("../token/with slashes", []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.readFile("../token/with slashes")
+	if err != nil || string(got) != "secret" {
+		t.Fatalf("readFile = %q, %v", got, err)
+	}
+	entries, _ := os.ReadDir(s.Dir)
+	if len(entries) != 1 {
+		t.Fatalf("got %d files, want 1", len(entries))
+	}
+	if info, _ := entries[0].Info(); info.Mode().Perm() != 0600 && filepath.Separator == '/' {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if err := s.removeFile("../token/with slashes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.removeFile("../token/with slashes"); err != nil {
+		t.Errorf("removing a missing key = %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if _, err := New("").Get("token"); err == nil {
+		t.Error("Get without a service should fail")
+	}
+	if err := New("app").Set("", nil); err == nil {
+		t.Error("Set with an empty key should fail")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build (darwin && !cgo) || !(darwin || windows || linux || freebsd || openbsd)
+
+package securestore
+
+const backend = "none"
+
+func (s *Store) get(key string) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func (s *Store) set(key string, secret []byte) error {
+	return ErrUnsupported
+}
+
+func (s *Store) remove(key string) error {
+	return ErrUnsupported
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/joeblew999/goup-util/pkg/installer"
 	"github.com/joeblew999/goup-util/pkg/self/output"
@@ -56,6 +57,12 @@ func Build(opts BuildOptions) error {
 		fmt.Println("🔒 Building with garble obfuscation...")
 	}
 
+	// Stamp the release tag so 'self upgrade' can refuse downgrades
+	ldflags := ""
+	if tag, err := exec.Command("git", "describe", "--tags", "--exact-match").Output(); err == nil {
+		ldflags = "-X github.com/joeblew999/goup-util/pkg/self.Version=" + strings.TrimSpace(string(tag))
+	}
+
 	// Build for all supported architectures
 	for _, arch := range SupportedArchitectures() {
 		outputPath := filepath.Join(currentDir, fmt.Sprintf("goup-util-%s", arch.Suffix))
@@ -68,10 +75,10 @@ func Build(opts BuildOptions) error {
 				return fmt.Errorf("failed to get garble path: %w", err)
 			}
 			// Use garble build for obfuscation
-			buildCmd = exec.Command(garblePath, "build", "-o", outputPath, ".")
+			buildCmd = exec.Command(garblePath, "build", "-ldflags", ldflags, "-o", outputPath, ".")
 		} else {
 			// Normal go build
-			buildCmd = exec.Command("go", "build", "-o", outputPath, ".")
+			buildCmd = exec.Command("go", "build", "-ldflags", ldflags, "-o", outputPath, ".")
 		}
 
 		buildCmd.Env = append(os.Environ(),
@@ -13,6 +13,10 @@ const (
 	FullRepoName = GitHubOwner + "/" + GitHubRepo
 )
 
+// UpdateRepoEnv replaces FullRepoName for 'self upgrade', e.g. with a
+// private mirror in a bucket (see pkg/self/source).
+const UpdateRepoEnv = "GOUP_UPDATE_REPO"
+
 // GitHub URLs
 const (
 	GitHubAPIBase = "https://api.github.com"
@@ -28,6 +32,7 @@ const (
 const (
 	UnixInstallDir  = "/usr/local/bin"
 	UnixInstallPath = UnixInstallDir + "/" + BinaryName
+	UserInstallDir  = ".local/bin" // Per-user install (self setup --user), under $HOME
 )
 
 // Directory and file names
@@ -41,19 +46,41 @@ const (
 // Temp file pattern
 const TempFilePattern = "goup-util-*"
 
-// GetInstallPath returns the installation path for the current platform
+// GetInstallPath returns the installation path for the current platform.
+// On Unix a per-user install, when present, wins over the system one so
+// upgrades go where 'self setup --user' put goup-util.
 func GetInstallPath() string {
 	if runtime.GOOS == "windows" {
 		return filepath.Join(os.Getenv("USERPROFILE"), BinaryName+".exe")
 	}
+	if user := GetUserInstallPath(); user != "" {
+		if _, err := os.Stat(user); err == nil {
+			return user
+		}
+	}
 	return UnixInstallPath
 }
 
+// GetUserInstallPath returns the per-user Unix installation path
+// (~/.local/bin/goup-util), or "" without a home directory.
+func GetUserInstallPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, UserInstallDir, BinaryName)
+}
+
 // GetLatestReleaseURL returns the GitHub API URL for latest release
 func GetLatestReleaseURL() string {
 	return GitHubAPIBase + "/repos/" + FullRepoName + "/releases/latest"
 }
 
+// GetReleaseURL returns the GitHub API URL for a release tag
+func GetReleaseURL(tag string) string {
+	return GitHubAPIBase + "/repos/" + FullRepoName + "/releases/tags/" + tag
+}
+
 // GetRepoGitURL returns the git clone URL
 func GetRepoGitURL() string {
 	return GitHubBase + "/" + FullRepoName + ".git"
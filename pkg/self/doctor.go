@@ -45,6 +45,26 @@ func Doctor() error {
 		}
 	}
 
+	// Check the install directory is on PATH (Windows installs add it to
+	// the user PATH, which only new terminals see)
+	result.InstallPath = GetInstallPath()
+	installDir := filepath.Dir(result.InstallPath)
+	result.InPath = inProcessPath(installDir)
+	if _, err := os.Stat(result.InstallPath); err == nil && !result.InPath {
+		inUserPath, _ := userPathContains(installDir)
+		switch {
+		case inUserPath:
+			result.Issues = append(result.Issues, installDir+" is in your user PATH but not in this terminal's")
+			result.Suggestions = append(result.Suggestions, "Open a new terminal")
+		case runtime.GOOS == "windows":
+			result.Issues = append(result.Issues, installDir+" is not in PATH")
+			result.Suggestions = append(result.Suggestions, "Run: goup-util self setup")
+		default:
+			result.Issues = append(result.Issues, installDir+" is not in PATH")
+			result.Suggestions = append(result.Suggestions, shellPathHint(installDir))
+		}
+	}
+
 	// Check platform-specific package manager
 	switch runtime.GOOS {
 	case "darwin":
@@ -110,14 +130,23 @@ func findAllInstallations() []string {
 	pathEnv := os.Getenv("PATH")
 	paths := filepath.SplitList(pathEnv)
 
+	name := BinaryName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
 	// Check each directory in PATH
+	seen := map[string]bool{}
 	for _, dir := range paths {
-		binaryPath := filepath.Join(dir, BinaryName)
+		binaryPath := filepath.Join(dir, name)
+		if dir == "" || seen[binaryPath] {
+			continue
+		}
+		seen[binaryPath] = true
 
-		// Check if file exists and is executable
+		// Check if file exists and is executable (Windows has no executable bit)
 		if info, err := os.Stat(binaryPath); err == nil && !info.IsDir() {
-			// Check if executable
-			if info.Mode()&0111 != 0 {
+			if runtime.GOOS == "windows" || info.Mode()&0111 != 0 {
 				installations = append(installations, binaryPath)
 			}
 		}
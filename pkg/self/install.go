@@ -1,28 +1,38 @@
 package self
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+	"github.com/joeblew999/goup-util/pkg/self/source"
 	"github.com/joeblew999/goup-util/pkg/self/output"
+	"golang.org/x/mod/semver"
 )
 
+// SetupOptions control 'self setup'.
+type SetupOptions struct {
+	NoPath bool // Windows: leave the user PATH alone
+	User   bool // Unix: install to ~/.local/bin without sudo
+}
+
 // InstallSelf installs the current binary to system path.
-// For Unix (macOS, Linux): /usr/local/bin/goup-util
+// For Unix (macOS, Linux): /usr/local/bin/goup-util, or ~/.local/bin/goup-util with User
 // For Windows: %USERPROFILE%\goup-util.exe (and adds to PATH if needed)
-func InstallSelf() error {
+func InstallSelf(opts SetupOptions) error {
 	var installPath string
 	var err error
 
 	switch runtime.GOOS {
 	case "darwin", "linux":
-		installPath, err = installSelfUnix()
+		installPath, err = installSelfUnix(opts.User)
 	case "windows":
 		installPath, err = installSelfWindows()
 	default:
@@ -36,7 +46,21 @@ func InstallSelf() error {
 	// Check if in PATH
 	inPath := false
 	if foundPath, pathErr := exec.LookPath(BinaryName); pathErr == nil {
-		inPath = (foundPath == installPath)
+		inPath = samePath(foundPath, installPath)
+	}
+
+	// Windows: add the install directory to the user PATH. This process and
+	// terminals already open keep their PATH; new ones find goup-util.
+	pathUpdated := false
+	if runtime.GOOS == "windows" && !inPath {
+		if opts.NoPath {
+			inPath, _ = userPathContains(filepath.Dir(installPath))
+		} else {
+			if pathUpdated, err = addToUserPath(filepath.Dir(installPath)); err != nil {
+				return err
+			}
+			inPath = true
+		}
 	}
 
 	// Check dependencies
@@ -55,16 +79,30 @@ func InstallSelf() error {
 		Installed:      true,
 		Location:       installPath,
 		InPath:         inPath,
+		PathUpdated:    pathUpdated,
 		DependenciesOK: depsOK,
 	}
+	if !inPath && runtime.GOOS != "windows" {
+		result.PathHint = shellPathHint(filepath.Dir(installPath))
+	}
 
 	output.OK("self setup", result)
 	return nil
 }
 
-// installSelfUnix installs the binary on Unix systems (macOS, Linux)
-func installSelfUnix() (string, error) {
+// installSelfUnix installs the binary on Unix systems (macOS, Linux),
+// system-wide or, with user, to ~/.local/bin without sudo
+func installSelfUnix(user bool) (string, error) {
 	installPath := UnixInstallPath
+	if user {
+		installPath = GetUserInstallPath()
+		if installPath == "" {
+			return "", fmt.Errorf("no home directory for a per-user install")
+		}
+		if err := os.MkdirAll(filepath.Dir(installPath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(installPath), err)
+		}
+	}
 
 	// Get current executable path
 	exePath, err := os.Executable()
@@ -78,9 +116,11 @@ func installSelfUnix() (string, error) {
 		return "", fmt.Errorf("failed to resolve executable path: %w", err)
 	}
 
-	// Check if we can write directly (unlikely)
-	if isWritable(UnixInstallDir) {
-		if err := copyFile(exePath, installPath); err != nil {
+	// Check if we can write directly (unlikely, unless per-user)
+	if samePath(exePath, installPath) {
+		// Already running the installed binary
+	} else if isWritable(filepath.Dir(installPath)) {
+		if err := replaceExecutable(exePath, installPath); err != nil {
 			return "", fmt.Errorf("failed to copy binary: %w", err)
 		}
 	} else {
@@ -103,6 +143,9 @@ func installSelfUnix() (string, error) {
 	// macOS: Remove quarantine attribute
 	if runtime.GOOS == "darwin" {
 		cmd := exec.Command("sudo", "xattr", "-d", "com.apple.quarantine", installPath)
+		if user {
+			cmd = exec.Command("xattr", "-d", "com.apple.quarantine", installPath)
+		}
 		// Ignore error - attribute may not exist
 		_ = cmd.Run()
 	}
@@ -115,6 +158,23 @@ func installSelfUnix() (string, error) {
 	return installPath, nil
 }
 
+// shellPathHint tells the user how to put dir on PATH in their shell.
+func shellPathHint(dir string) string {
+	profile := "your shell profile"
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case "fish":
+		return "fish_add_path " + dir
+	case "zsh":
+		profile = "~/.zshrc"
+	case "bash":
+		profile = "~/.bashrc"
+		if runtime.GOOS == "darwin" {
+			profile = "~/.bash_profile"
+		}
+	}
+	return fmt.Sprintf(`Add to %s: export PATH="%s:$PATH"`, profile, dir)
+}
+
 // installSelfWindows installs the binary on Windows
 func installSelfWindows() (string, error) {
 	// Install to user profile directory
@@ -131,9 +191,11 @@ func installSelfWindows() (string, error) {
 		return "", fmt.Errorf("failed to get executable path: %w", err)
 	}
 
-	// Copy file
-	if err := copyFile(exePath, installPath); err != nil {
-		return "", fmt.Errorf("failed to copy binary: %w", err)
+	// Copy file; another goup-util.exe may be running from installPath
+	if same, _ := filepath.Abs(exePath); !strings.EqualFold(same, installPath) {
+		if err := replaceExecutable(exePath, installPath); err != nil {
+			return "", fmt.Errorf("failed to copy binary: %w", err)
+		}
 	}
 
 	// Unblock file (Windows SmartScreen)
@@ -191,41 +253,69 @@ func isWritable(path string) bool {
 	return true
 }
 
+// UpgradeOptions control 'self upgrade'.
+type UpgradeOptions struct {
+	To    string // Install this release tag instead of the latest
+	Force bool   // Reinstall or downgrade without asking
+}
+
 // DownloadAndInstallLatest downloads the latest release and installs it.
-// This is used by the 'self upgrade' command.
 func DownloadAndInstallLatest(repo string) error {
+	return Upgrade(repo, UpgradeOptions{})
+}
+
+// Upgrade downloads a release from repo, a GitHub owner/name or a source
+// URL (see pkg/self/source), and installs it. This is used by the
+// 'self upgrade' command. It refuses to replace the binary with an older
+// release unless the version was pinned with To or Force is set, so a
+// "latest" release cut from a hotfix branch can't silently downgrade.
+func Upgrade(repo string, opts UpgradeOptions) error {
+	current := CurrentVersion()
 	result := output.UpgradeResult{
-		PreviousVersion: Version,
+		PreviousVersion: current,
 		Downloaded:      false,
 		Installed:       false,
 	}
 
-	// Get latest release info
-	releaseURL := GetLatestReleaseURL()
-	resp, err := http.Get(releaseURL)
+	// Get release info
+	src, err := source.Open(repo)
 	if err != nil {
-		return fmt.Errorf("failed to check for updates: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to fetch release info: %s", resp.Status)
+	var release *ghapi.Release
+	if opts.To != "" {
+		if !strings.HasPrefix(opts.To, "v") {
+			opts.To = "v" + opts.To
+		}
+		if !semver.IsValid(opts.To) {
+			return fmt.Errorf("invalid version %q (use a release tag like v1.2.3)", opts.To)
+		}
+		release, err = src.Release(opts.To)
+	} else {
+		release, err = src.Latest()
 	}
-
-	var release struct {
-		TagName string `json:"tag_name"`
-		Assets  []struct {
-			Name               string `json:"name"`
-			BrowserDownloadURL string `json:"browser_download_url"`
-		} `json:"assets"`
+	if errors.Is(err, ghapi.ErrNotFound) && opts.To != "" {
+		return fmt.Errorf("release %s not found in %s", opts.To, src)
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return fmt.Errorf("failed to parse release info: %w", err)
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
 	}
 
 	result.NewVersion = release.TagName
 
+	switch compareVersions(release.TagName, current) {
+	case 0:
+		if !opts.Force {
+			result.UpToDate = true
+			output.OK("self upgrade", result)
+			return nil
+		}
+	case -1:
+		if opts.To == "" && !opts.Force {
+			return fmt.Errorf("latest release %s is older than the installed %s; use --to %s or --force to downgrade", release.TagName, current, release.TagName)
+		}
+	}
+
 	// Get binary name for current platform
 	binaryName := getBinaryName()
 	if binaryName == "" {
@@ -233,15 +323,15 @@ func DownloadAndInstallLatest(repo string) error {
 	}
 
 	// Find matching asset
-	var downloadURL string
-	for _, asset := range release.Assets {
-		if asset.Name == binaryName {
-			downloadURL = asset.BrowserDownloadURL
+	var asset *ghapi.Asset
+	for i := range release.Assets {
+		if release.Assets[i].Name == binaryName {
+			asset = &release.Assets[i]
 			break
 		}
 	}
 
-	if downloadURL == "" {
+	if asset == nil {
 		return fmt.Errorf("binary not found for %s/%s in release %s", runtime.GOOS, runtime.GOARCH, release.TagName)
 	}
 
@@ -253,19 +343,9 @@ func DownloadAndInstallLatest(repo string) error {
 	defer os.Remove(tmpFile.Name())
 	defer tmpFile.Close()
 
-	resp, err = http.Get(downloadURL)
-	if err != nil {
+	if err := src.Download(*asset, tmpFile); err != nil {
 		return fmt.Errorf("failed to download binary: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed: %s", resp.Status)
-	}
-
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to save binary: %w", err)
-	}
 
 	result.Downloaded = true
 
@@ -283,7 +363,10 @@ func DownloadAndInstallLatest(repo string) error {
 	// On Unix, we might need sudo
 	if runtime.GOOS != "windows" {
 		if !isWritable(filepath.Dir(installPath)) {
-			cmd := exec.Command("sudo", "cp", tmpFile.Name(), installPath)
+			// Stage next to the target, then mv: a rename, so the binary
+			// is never half-written
+			staged := installPath + NewSuffix
+			cmd := exec.Command("sudo", "cp", tmpFile.Name(), staged)
 			cmd.Stdin = os.Stdin
 			cmd.Stdout = os.Stdout
 			cmd.Stderr = os.Stderr
@@ -291,12 +374,17 @@ func DownloadAndInstallLatest(repo string) error {
 				return fmt.Errorf("sudo cp failed: %w", err)
 			}
 
-			cmd = exec.Command("sudo", "chmod", "+x", installPath)
+			cmd = exec.Command("sudo", "chmod", "+x", staged)
 			if err := cmd.Run(); err != nil {
 				return fmt.Errorf("chmod failed: %w", err)
 			}
+
+			cmd = exec.Command("sudo", "mv", "-f", staged, installPath)
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("sudo mv failed: %w", err)
+			}
 		} else {
-			if err := copyFile(tmpFile.Name(), installPath); err != nil {
+			if err := replaceExecutable(tmpFile.Name(), installPath); err != nil {
 				return fmt.Errorf("failed to install: %w", err)
 			}
 		}
@@ -306,8 +394,8 @@ func DownloadAndInstallLatest(repo string) error {
 			exec.Command("sudo", "xattr", "-d", "com.apple.quarantine", installPath).Run()
 		}
 	} else {
-		// Windows
-		if err := copyFile(tmpFile.Name(), installPath); err != nil {
+		// Windows: the installed exe is often the one running this upgrade
+		if err := replaceExecutable(tmpFile.Name(), installPath); err != nil {
 			return fmt.Errorf("failed to install: %w", err)
 		}
 
@@ -332,14 +420,11 @@ func getBinaryName() string {
 
 // getInstallPath returns the installation path for the current platform
 func getInstallPath() string {
-	if runtime.GOOS == "windows" {
-		return filepath.Join(os.Getenv("USERPROFILE"), "goup-util.exe")
-	}
-	return UnixInstallPath
+	return GetInstallPath()
 }
 
 // UninstallSelf removes goup-util from the system path.
-// For Unix (macOS, Linux): removes /usr/local/bin/goup-util
+// For Unix (macOS, Linux): removes /usr/local/bin/goup-util and ~/.local/bin/goup-util
 // For Windows: removes %USERPROFILE%\goup-util.exe
 func UninstallSelf() error {
 	result := output.UninstallResult{
@@ -347,8 +432,13 @@ func UninstallSelf() error {
 		Failed:  []string{},
 	}
 
-	// Find ALL installations
-	installations := findAllGoupUtilInstallations()
+	// Find ALL installations, including a per-user one not on PATH
+	installations := findAllInstallations()
+	if user := GetUserInstallPath(); user != "" && runtime.GOOS != "windows" {
+		if _, err := os.Stat(user); err == nil && !slices.ContainsFunc(installations, func(p string) bool { return samePath(p, user) }) {
+			installations = append(installations, user)
+		}
+	}
 
 	if len(installations) == 0 {
 		output.OK("self uninstall", result)
@@ -368,30 +458,6 @@ func UninstallSelf() error {
 	return nil
 }
 
-// findAllGoupUtilInstallations finds all goup-util binaries in PATH
-func findAllGoupUtilInstallations() []string {
-	var installations []string
-
-	// Get PATH
-	pathEnv := os.Getenv("PATH")
-	paths := filepath.SplitList(pathEnv)
-
-	// Check each directory in PATH
-	for _, dir := range paths {
-		binaryPath := filepath.Join(dir, BinaryName)
-
-		// Check if file exists and is executable
-		if info, err := os.Stat(binaryPath); err == nil && !info.IsDir() {
-			// Check if executable
-			if info.Mode()&0111 != 0 {
-				installations = append(installations, binaryPath)
-			}
-		}
-	}
-
-	return installations
-}
-
 // removeBinary removes a binary file, using sudo if needed
 func removeBinary(path string) error {
 	// Try to remove directly
@@ -0,0 +1,25 @@
+package self
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestInstallPathPrefersUserInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("per-user install is the only mode on Windows")
+	}
+	t.Setenv("HOME", t.TempDir())
+	if got := GetInstallPath(); got != UnixInstallPath {
+		t.Errorf("GetInstallPath() = %q without a user install, want %q", got, UnixInstallPath)
+	}
+
+	user := GetUserInstallPath()
+	os.MkdirAll(filepath.Dir(user), 0755)
+	os.WriteFile(user, []byte("bin"), 0755)
+	if got := GetInstallPath(); got != user {
+		t.Errorf("GetInstallPath() = %q, want the user install %q", got, user)
+	}
+}
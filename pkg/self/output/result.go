@@ -51,16 +51,21 @@ func (v VersionResult) ToBaseResult(command string) *BaseResult {
 
 // StatusResult represents status command output
 type StatusResult struct {
-	Installed       bool   `json:"installed"`
-	CurrentVersion  string `json:"current_version,omitempty"`
-	LatestVersion   string `json:"latest_version,omitempty"`
-	UpdateAvailable bool   `json:"update_available"`
-	Location        string `json:"location,omitempty"`
+	Installed       bool               `json:"installed"`
+	CurrentVersion  string             `json:"current_version,omitempty"`
+	LatestVersion   string             `json:"latest_version,omitempty"`
+	UpdateAvailable bool               `json:"update_available"`
+	Location        string             `json:"location,omitempty"`      // goup-util found first in PATH
+	InstallPath     string             `json:"install_path"`            // Where goup-util is installed to
+	Running         string             `json:"running,omitempty"`       // This binary, when it isn't Location
+	Shadowed        bool               `json:"shadowed"`                // Another binary in PATH comes before InstallPath
+	Installations   []InstallationInfo `json:"installations,omitempty"` // Every goup-util in PATH, in order
+	CheckError      string             `json:"check_error,omitempty"`   // Why the latest release is unknown
 }
 
 func (s StatusResult) ToBaseResult(command string) *BaseResult {
 	status := StatusOK
-	if !s.Installed {
+	if !s.Installed || s.Shadowed {
 		status = StatusWarning
 	}
 	data, _ := json.Marshal(s)
@@ -77,6 +82,8 @@ func (s StatusResult) ToBaseResult(command string) *BaseResult {
 // DoctorResult represents doctor command output
 type DoctorResult struct {
 	Installations []InstallationInfo `json:"installations"`
+	InstallPath   string             `json:"install_path"`
+	InPath        bool               `json:"in_path"` // The install directory is on this process's PATH
 	Dependencies  []DependencyInfo   `json:"dependencies"`
 	Issues        []string           `json:"issues,omitempty"`
 	Suggestions   []string           `json:"suggestions,omitempty"`
@@ -143,6 +150,8 @@ type SetupResult struct {
 	Installed      bool   `json:"installed"`
 	Location       string `json:"location"`
 	InPath         bool   `json:"in_path"`
+	PathUpdated    bool   `json:"path_updated,omitempty"` // Added to the user PATH; open a new terminal
+	PathHint       string `json:"path_hint,omitempty"`    // How to put Location on PATH
 	DependenciesOK bool   `json:"dependencies_ok"`
 }
 
@@ -293,6 +302,7 @@ type UpgradeResult struct {
 	NewVersion      string `json:"new_version"`
 	Downloaded      bool   `json:"downloaded"`
 	Installed       bool   `json:"installed"`
+	UpToDate        bool   `json:"up_to_date,omitempty"`
 	Location        string `json:"location"`
 }
 
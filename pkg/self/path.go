@@ -0,0 +1,58 @@
+package self
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// windowsEnvVar matches %NAME% references in REG_EXPAND_SZ values.
+var windowsEnvVar = regexp.MustCompile(`%[^%;]+%`)
+
+// pathEntryEqual compares two PATH entries the way Windows resolves them:
+// case-insensitively, with environment variables expanded and trailing
+// separators ignored.
+func pathEntryEqual(a, b string) bool {
+	clean := func(p string) string {
+		p = windowsEnvVar.ReplaceAllStringFunc(p, func(v string) string {
+			if value, ok := os.LookupEnv(strings.Trim(v, "%")); ok {
+				return value
+			}
+			return v
+		})
+		p = strings.TrimRight(strings.TrimSpace(p), `\/`)
+		return strings.ToLower(filepath.Clean(p))
+	}
+	return a != "" && b != "" && clean(a) == clean(b)
+}
+
+// pathListContains reports whether a ';'-separated Windows PATH value
+// contains dir.
+func pathListContains(list, dir string) bool {
+	for _, entry := range strings.Split(list, ";") {
+		if pathEntryEqual(entry, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathListAppend adds dir to the end of a Windows PATH value.
+func pathListAppend(list, dir string) string {
+	list = strings.TrimRight(list, ";")
+	if list == "" {
+		return dir
+	}
+	return list + ";" + dir
+}
+
+// inProcessPath reports whether dir is on this process's PATH.
+func inProcessPath(dir string) bool {
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if samePath(entry, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package self
+
+import "errors"
+
+// errNoUserPath: outside Windows, PATH lives in shell startup files.
+var errNoUserPath = errors.New("the user PATH registry value only exists on Windows")
+
+func userPathContains(dir string) (bool, error) {
+	return false, errNoUserPath
+}
+
+func addToUserPath(dir string) (bool, error) {
+	return false, errNoUserPath
+}
@@ -0,0 +1,26 @@
+package self
+
+import "testing"
+
+func TestPathList(t *testing.T) {
+	t.Setenv("USERPROFILE", `C:\Users\ada`)
+	list := `C:\Windows\system32;%USERPROFILE%\;C:\Go\bin`
+
+	for dir, want := range map[string]bool{
+		`C:\Users\ada`:    true, // expanded and without the trailing separator
+		`c:\users\ADA\`:   true,
+		`C:\Users\ada\go`: false,
+		`C:\Go`:           false,
+	} {
+		if got := pathListContains(list, dir); got != want {
+			t.Errorf("pathListContains(%q) = %v, want %v", dir, got, want)
+		}
+	}
+
+	if got := pathListAppend("", `C:\Users\ada`); got != `C:\Users\ada` {
+		t.Errorf("append to empty PATH = %q", got)
+	}
+	if got := pathListAppend(`C:\Go\bin;`, `C:\Users\ada`); got != `C:\Go\bin;C:\Users\ada` {
+		t.Errorf("append = %q", got)
+	}
+}
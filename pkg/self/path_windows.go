@@ -0,0 +1,92 @@
+package self
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// userEnvironmentKey holds the per-user environment, including Path.
+const userEnvironmentKey = `Environment`
+
+// readUserPath returns the user Path value and whether it is REG_EXPAND_SZ.
+func readUserPath(k registry.Key) (string, bool, error) {
+	value, valueType, err := k.GetStringValue("Path")
+	if errors.Is(err, registry.ErrNotExist) {
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, valueType == registry.EXPAND_SZ, nil
+}
+
+func writeUserPath(k registry.Key, value string, expand bool) error {
+	// Keep %VARS% working: REG_SZ would store them literally
+	if expand || strings.Contains(value, "%") {
+		return k.SetExpandStringValue("Path", value)
+	}
+	return k.SetStringValue("Path", value)
+}
+
+// userPathContains reports whether dir is on the user PATH in the registry,
+// which new terminals pick up.
+func userPathContains(dir string) (bool, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, userEnvironmentKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false, err
+	}
+	defer k.Close()
+	value, _, err := readUserPath(k)
+	if err != nil {
+		return false, err
+	}
+	return pathListContains(value, dir), nil
+}
+
+// addToUserPath appends dir to the user PATH and tells running programs
+// (Explorer, and so new terminals) that the environment changed.
+func addToUserPath(dir string) (bool, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, userEnvironmentKey, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return false, fmt.Errorf("failed to open user environment: %w", err)
+	}
+	defer k.Close()
+	value, expand, err := readUserPath(k)
+	if err != nil {
+		return false, fmt.Errorf("failed to read user PATH: %w", err)
+	}
+	if pathListContains(value, dir) {
+		return false, nil
+	}
+	if err := writeUserPath(k, pathListAppend(value, dir), expand); err != nil {
+		return false, fmt.Errorf("failed to update user PATH: %w", err)
+	}
+	broadcastEnvironmentChange()
+	return true, nil
+}
+
+var procSendMessageTimeout = windows.NewLazySystemDLL("user32.dll").NewProc("SendMessageTimeoutW")
+
+// broadcastEnvironmentChange sends WM_SETTINGCHANGE("Environment") so
+// Explorer reloads the environment for programs it starts. Terminals that
+// are already open keep their old PATH.
+func broadcastEnvironmentChange() {
+	const (
+		hwndBroadcast    = 0xffff
+		wmSettingChange  = 0x001A
+		smtoAbortIfHung  = 0x0002
+		broadcastTimeout = 5000 // ms
+	)
+	env, err := windows.UTF16PtrFromString("Environment")
+	if err != nil {
+		return
+	}
+	var result uintptr
+	procSendMessageTimeout.Call(hwndBroadcast, wmSettingChange, 0, uintptr(unsafe.Pointer(env)),
+		smtoAbortIfHung, broadcastTimeout, uintptr(unsafe.Pointer(&result)))
+}
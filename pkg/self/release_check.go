@@ -1,11 +1,11 @@
 package self
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"time"
 
+	"github.com/joeblew999/goup-util/pkg/ghapi"
 	selfOutput "github.com/joeblew999/goup-util/pkg/self/output"
 )
 
@@ -32,38 +32,13 @@ func CheckRelease(tag string) error {
 		}
 
 		// Try to get the release
-		releaseURL := fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", FullRepoName, tag)
-		
-		client := &http.Client{Timeout: 10 * time.Second}
-		resp, err := client.Get(releaseURL)
-		if err != nil {
-			result.Message = fmt.Sprintf("Failed to check release: %v", err)
-			return result, nil
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 404 {
+		release, err := ghapi.New().ReleaseByTag(FullRepoName, tag)
+		if errors.Is(err, ghapi.ErrNotFound) {
 			result.Message = fmt.Sprintf("Release %s not found - workflow may still be running", tag)
 			return result, nil
 		}
-
-		if resp.StatusCode != 200 {
-			result.Message = fmt.Sprintf("GitHub API returned status %d", resp.StatusCode)
-			return result, nil
-		}
-
-		// Parse release info
-		var release struct {
-			TagName     string    `json:"tag_name"`
-			PublishedAt time.Time `json:"published_at"`
-			HTMLURL     string    `json:"html_url"`
-			Assets      []struct {
-				Name string `json:"name"`
-			} `json:"assets"`
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-			result.Message = fmt.Sprintf("Failed to parse release info: %v", err)
+		if err != nil {
+			result.Message = fmt.Sprintf("Failed to check release: %v", err)
 			return result, nil
 		}
 
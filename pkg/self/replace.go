@@ -0,0 +1,89 @@
+package self
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Suffixes used while replacing an executable.
+const (
+	NewSuffix = ".new" // The downloaded binary, next to the target until it is swapped in
+	OldSuffix = ".old" // The replaced binary, until it can be deleted
+)
+
+// replaceExecutable installs src at dst, even when dst is the running
+// executable. Windows locks a running .exe against writes and deletes but
+// not renames, so dst is moved aside to dst.old, the new binary is moved
+// into place, and dst.old is deleted once nothing runs it any more: right
+// away where possible, otherwise by a delete scheduled for after this
+// process exits, with CleanupOldBinaries as the fallback on the next run.
+func replaceExecutable(src, dst string) error {
+	// Stage next to dst so the final step is a rename on one volume
+	staged := dst + NewSuffix
+	if err := copyFile(src, staged); err != nil {
+		return err
+	}
+	if err := os.Chmod(staged, 0755); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to make binary executable: %w", err)
+	}
+
+	old := ""
+	if _, err := os.Stat(dst); err == nil {
+		old = dst + OldSuffix
+		if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+			// An earlier .old is still running; keep it and use a new name
+			old = dst + OldSuffix + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		}
+		if err := os.Rename(dst, old); err != nil {
+			os.Remove(staged)
+			return fmt.Errorf("failed to move %s aside: %w", dst, err)
+		}
+	}
+	if err := os.Rename(staged, dst); err != nil {
+		if old != "" {
+			os.Rename(old, dst)
+		}
+		os.Remove(staged)
+		return fmt.Errorf("failed to install %s: %w", dst, err)
+	}
+	if old != "" {
+		if err := os.Remove(old); err != nil {
+			scheduleDelete(old)
+		}
+	}
+	return nil
+}
+
+// scheduleDelete removes a file shortly after this process exits. Only
+// Windows needs it: elsewhere a running binary can be deleted.
+func scheduleDelete(path string) {
+	if runtime.GOOS != "windows" {
+		return
+	}
+	// ping is the usual way to sleep in cmd.exe without a console
+	script := fmt.Sprintf(`ping -n 3 127.0.0.1 >NUL & del /F /Q "%s"`, path)
+	exec.Command("cmd", "/C", script).Start()
+}
+
+// CleanupOldBinaries deletes binaries left behind by earlier upgrades of
+// the running executable and the installed one. Errors are ignored: a
+// leftover that is still running is removed next time.
+func CleanupOldBinaries() {
+	targets := []string{getInstallPath()}
+	if exe, err := os.Executable(); err == nil {
+		targets = append(targets, exe)
+	}
+	for _, target := range targets {
+		matches, _ := filepath.Glob(target + OldSuffix + "*")
+		for _, m := range matches {
+			os.Remove(m)
+		}
+		os.Remove(target + NewSuffix)
+	}
+}
@@ -0,0 +1,83 @@
+package self
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceExecutable(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "download")
+	dst := filepath.Join(dir, "goup-util.exe")
+	os.WriteFile(src, []byte("v2"), 0644)
+
+	// Fresh install: nothing to move aside
+	if err := replaceExecutable(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, dst, "v2")
+
+	// Upgrade: the old binary is moved aside and removed
+	os.WriteFile(src, []byte("v3"), 0644)
+	if err := replaceExecutable(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, dst, "v3")
+	for _, leftover := range []string{dst + OldSuffix, dst + NewSuffix} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", filepath.Base(leftover))
+		}
+	}
+	if info, _ := os.Stat(dst); info.Mode()&0111 == 0 {
+		t.Errorf("installed binary is not executable: %v", info.Mode())
+	}
+}
+
+func TestReplaceExecutableKeepsLockedOld(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "download")
+	dst := filepath.Join(dir, "goup-util.exe")
+	os.WriteFile(src, []byte("v3"), 0644)
+	os.WriteFile(dst, []byte("v2"), 0755)
+
+	// Stand in for a .old that an earlier upgrade left running: a
+	// non-empty directory cannot be removed either
+	locked := dst + OldSuffix
+	os.MkdirAll(filepath.Join(locked, "busy"), 0755)
+
+	if err := replaceExecutable(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, dst, "v3")
+	if _, err := os.Stat(locked); err != nil {
+		t.Error("the running .old was touched")
+	}
+}
+
+func TestCleanupOldBinaries(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	leftovers := []string{exe + OldSuffix, exe + OldSuffix + "-abc", exe + NewSuffix}
+	for _, f := range leftovers {
+		if err := os.WriteFile(f, []byte("x"), 0644); err != nil {
+			t.Skip("test binary directory is not writable")
+		}
+	}
+	CleanupOldBinaries()
+	for _, f := range leftovers {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("%s not removed", filepath.Base(f))
+			os.Remove(f)
+		}
+	}
+}
+
+func assertContent(t *testing.T, path, want string) {
+	t.Helper()
+	if data, err := os.ReadFile(path); err != nil || string(data) != want {
+		t.Errorf("%s = %q, %v; want %q", filepath.Base(path), data, err, want)
+	}
+}
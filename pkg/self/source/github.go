@@ -0,0 +1,45 @@
+package source
+
+import (
+	"io"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+)
+
+// GitHub is the releases of a GitHub repository.
+type GitHub struct {
+	Repo   string // owner/name
+	Client *ghapi.Client
+}
+
+// NewGitHub returns the releases of repo, read with goup-util's token.
+func NewGitHub(repo string) *GitHub {
+	return &GitHub{Repo: repo, Client: ghapi.New()}
+}
+
+func (g *GitHub) String() string { return "github.com/" + g.Repo }
+
+// Latest returns the latest release.
+func (g *GitHub) Latest() (*ghapi.Release, error) {
+	return g.Client.LatestRelease(g.Repo)
+}
+
+// Release returns the release for tag.
+func (g *GitHub) Release(tag string) (*ghapi.Release, error) {
+	return g.Client.ReleaseByTag(g.Repo, tag)
+}
+
+// Download writes the asset to w, checking GitHub's digest when the
+// release has one.
+func (g *GitHub) Download(a ghapi.Asset, w io.Writer) error {
+	w, check := verified(a, w)
+	if err := g.Client.Download(a, w); err != nil {
+		return err
+	}
+	return check()
+}
+
+// DownloadFrom resumes a download of the asset at offset.
+func (g *GitHub) DownloadFrom(a ghapi.Asset, offset int64, w io.Writer) error {
+	return g.Client.DownloadFrom(a, offset, w)
+}
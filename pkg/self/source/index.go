@@ -0,0 +1,124 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+)
+
+// IndexFile lists the releases of a static index, newest first, in the
+// format of the update server's /releases with URLs relative to the index.
+const IndexFile = "releases.json"
+
+// Store reads the files of a static index by their path relative to its
+// root. Missing files are errors wrapping ghapi.ErrNotFound.
+type Store interface {
+	Get(path string, w io.Writer) error
+	// GetFrom writes path from byte offset on, to resume a download.
+	GetFrom(path string, offset int64, w io.Writer) error
+	// URL is where path is found, for display and for the release's
+	// browser_download_url.
+	URL(path string) string
+}
+
+// Index is a static index of releases kept in a Store.
+type Index struct {
+	Store Store
+}
+
+func (x *Index) String() string { return x.Store.URL("") }
+
+type indexRelease struct {
+	Version   string    `json:"version"`
+	Published time.Time `json:"published"`
+	Rollout   *int      `json:"rollout"` // nil: not staged, offered to everyone
+	Assets    []struct {
+		Name   string `json:"name"`
+		URL    string `json:"url"`
+		Size   int64  `json:"size"`
+		SHA256 string `json:"sha256"`
+	} `json:"assets"`
+}
+
+// releases reads the index. Each asset's URL is its path in the store.
+func (x *Index) releases() ([]ghapi.Release, error) {
+	var buf bytes.Buffer
+	if err := x.Store.Get(IndexFile, &buf); err != nil {
+		return nil, fmt.Errorf("%s: %w", x.Store.URL(IndexFile), err)
+	}
+	var list []indexRelease
+	if err := json.Unmarshal(buf.Bytes(), &list); err != nil {
+		return nil, fmt.Errorf("%s is not a release index: %w", x.Store.URL(IndexFile), err)
+	}
+	releases := make([]ghapi.Release, len(list))
+	for i, r := range list {
+		releases[i] = ghapi.Release{
+			TagName:           r.Version,
+			Name:              r.Version,
+			PublishedAt:       r.Published,
+			RolloutPercentage: r.Rollout,
+		}
+		for _, a := range r.Assets {
+			path, err := url.PathUnescape(a.URL)
+			if err != nil || strings.HasPrefix(path, "/") || strings.Contains(path, "://") {
+				return nil, fmt.Errorf("%s: asset URL %q is not relative to the index", x.Store.URL(IndexFile), a.URL)
+			}
+			asset := ghapi.Asset{Name: a.Name, Size: a.Size, URL: path, BrowserDownloadURL: x.Store.URL(path)}
+			if a.SHA256 != "" {
+				asset.Digest = "sha256:" + a.SHA256
+			}
+			releases[i].Assets = append(releases[i].Assets, asset)
+		}
+	}
+	return releases, nil
+}
+
+// Latest returns the first release of the index.
+func (x *Index) Latest() (*ghapi.Release, error) {
+	releases, err := x.releases()
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("%s lists no releases: %w", x.Store.URL(IndexFile), ghapi.ErrNotFound)
+	}
+	return &releases[0], nil
+}
+
+// Release returns the release for tag, with or without its leading v.
+func (x *Index) Release(tag string) (*ghapi.Release, error) {
+	releases, err := x.releases()
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range releases {
+		if strings.TrimPrefix(r.TagName, "v") == strings.TrimPrefix(tag, "v") {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s in %s: %w", tag, x.Store.URL(IndexFile), ghapi.ErrNotFound)
+}
+
+// Download writes the asset to w and checks it against the index's
+// SHA-256.
+func (x *Index) Download(a ghapi.Asset, w io.Writer) error {
+	w, check := verified(a, w)
+	if err := x.Store.Get(a.URL, w); err != nil {
+		return fmt.Errorf("%s: %w", a.Name, err)
+	}
+	return check()
+}
+
+// DownloadFrom resumes a download of the asset at offset.
+func (x *Index) DownloadFrom(a ghapi.Asset, offset int64, w io.Writer) error {
+	if err := x.Store.GetFrom(a.URL, offset, w); err != nil {
+		return fmt.Errorf("%s: %w", a.Name, err)
+	}
+	return nil
+}
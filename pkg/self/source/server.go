@@ -0,0 +1,56 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+)
+
+// UpdateServer is a server run with 'goup-util update-server serve',
+// which answers in GitHub's format.
+type UpdateServer struct {
+	URL    string
+	Client *ghapi.Client
+}
+
+// NewUpdateServer returns the releases of the update server at url.
+func NewUpdateServer(url string) *UpdateServer {
+	return &UpdateServer{URL: strings.TrimSuffix(url, "/"), Client: ghapi.New()}
+}
+
+func (s *UpdateServer) String() string { return s.URL }
+
+// Latest returns the server's latest release.
+func (s *UpdateServer) Latest() (*ghapi.Release, error) {
+	var r ghapi.Release
+	if err := s.Client.Get(s.URL+"/releases/latest", &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Release returns the latest release if it is tag; the server offers no
+// others in GitHub's format.
+func (s *UpdateServer) Release(tag string) (*ghapi.Release, error) {
+	r, err := s.Latest()
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimPrefix(r.TagName, "v") != strings.TrimPrefix(tag, "v") {
+		return nil, fmt.Errorf("%s on %s: %w", tag, s.URL, ghapi.ErrNotFound)
+	}
+	return r, nil
+}
+
+// Download writes the asset to w.
+func (s *UpdateServer) Download(a ghapi.Asset, w io.Writer) error {
+	return s.Client.Download(a, w)
+}
+
+// DownloadFrom resumes a download of the asset at offset; the server
+// answers range requests.
+func (s *UpdateServer) DownloadFrom(a ghapi.Asset, offset int64, w io.Writer) error {
+	return s.Client.DownloadFrom(a, offset, w)
+}
@@ -0,0 +1,119 @@
+// Package source finds and downloads releases wherever they are
+// published: GitHub, or a static index in a bucket or on any HTTPS server,
+// for companies that cannot put binaries on public GitHub. The update
+// repo of goup-util and of apps (update.repo in app.json) names one:
+//
+//	owner/name                     GitHub releases (token from ghapi.Token)
+//	https://dl.example.com/myapp   <url>/releases.json; user:password@ for basic auth
+//	s3://bucket/prefix             ?region=, ?endpoint=; AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+//	r2://bucket/prefix             ?account= or R2_ACCOUNT_ID; R2_ACCESS_KEY_ID, R2_SECRET_ACCESS_KEY
+//	gs://bucket/prefix             GOOGLE_APPLICATION_CREDENTIALS (a service account key file)
+//
+// Buckets without credentials are read anonymously. A static index is the
+// directory layout of 'goup-util update-server' copied as is, with the
+// releases.json that 'goup-util update-server index' writes. Register adds
+// other schemes.
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+)
+
+// Source is where releases are published.
+type Source interface {
+	// Latest returns the newest release.
+	Latest() (*ghapi.Release, error)
+	// Release returns the release for tag; the error wraps
+	// ghapi.ErrNotFound when there is none.
+	Release(tag string) (*ghapi.Release, error)
+	// Download writes an asset of one of the source's releases to w.
+	Download(a ghapi.Asset, w io.Writer) error
+	String() string
+}
+
+// Resumer is a Source that can continue an interrupted download. All the
+// sources in this package are.
+type Resumer interface {
+	// DownloadFrom writes the asset from byte offset on to w. It does not
+	// check the digest; Verify checks the whole file once it is complete.
+	DownloadFrom(a ghapi.Asset, offset int64, w io.Writer) error
+}
+
+// Opener opens a source for a URL of its scheme.
+type Opener func(u *url.URL) (Source, error)
+
+var openers = map[string]Opener{
+	"http":  openHTTP,
+	"https": openHTTP,
+	"s3":    openS3,
+	"r2":    openR2,
+	"gs":    openGCS,
+}
+
+// Register adds a backend for URLs with scheme, or replaces one.
+func Register(scheme string, open Opener) {
+	openers[scheme] = open
+}
+
+// Schemes lists the registered URL schemes.
+func Schemes() []string {
+	schemes := make([]string, 0, len(openers))
+	for s := range openers {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Open returns the source repo names: a GitHub owner/name or a URL.
+func Open(repo string) (Source, error) {
+	if !strings.Contains(repo, "://") {
+		if strings.Count(repo, "/") != 1 || strings.HasPrefix(repo, "/") || strings.HasSuffix(repo, "/") {
+			return nil, fmt.Errorf("update repo %q is neither owner/name nor a URL (%s://...)", repo, strings.Join(Schemes(), "://, "))
+		}
+		return NewGitHub(repo), nil
+	}
+	u, err := url.Parse(repo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update repo %q: %w", repo, err)
+	}
+	open, ok := openers[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no update backend for %s:// (known: %s)", u.Scheme, strings.Join(Schemes(), ", "))
+	}
+	return open(u)
+}
+
+// verified wraps w to check a download against the asset's digest, when
+// it has one; check reports a mismatch once the download is complete.
+func verified(a ghapi.Asset, w io.Writer) (io.Writer, func() error) {
+	want, ok := strings.CutPrefix(a.Digest, "sha256:")
+	if !ok {
+		return w, func() error { return nil }
+	}
+	h := sha256.New()
+	return io.MultiWriter(w, h), func() error {
+		if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+			return fmt.Errorf("%s: SHA-256 %s does not match the release's %s", a.Name, got, want)
+		}
+		return nil
+	}
+}
+
+// Verify checks a complete download against the asset's digest, when it
+// has one.
+func Verify(a ghapi.Asset, r io.Reader) error {
+	w, check := verified(a, io.Discard)
+	if _, err := io.Copy(w, r); err != nil {
+		return err
+	}
+	return check()
+}
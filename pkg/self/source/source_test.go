@@ -0,0 +1,170 @@
+package source
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+)
+
+func TestOpen(t *testing.T) {
+	t.Setenv("R2_ACCOUNT_ID", "")
+	src, err := Open("acme/app")
+	if gh, ok := src.(*GitHub); err != nil || !ok || gh.Repo != "acme/app" {
+		t.Errorf("Open(acme/app) = %v, %v", src, err)
+	}
+	for _, repo := range []string{"acme", "acme/app/extra", "ftp://host/releases", "r2://updates/app"} {
+		if _, err := Open(repo); err == nil {
+			t.Errorf("Open(%q) succeeded", repo)
+		}
+	}
+
+	Register("test", func(u *url.URL) (Source, error) { return NewGitHub(u.Host + u.Path), nil })
+	defer delete(openers, "test")
+	if src, err := Open("test://acme/app"); err != nil || src.String() != "github.com/acme/app" {
+		t.Errorf("registered scheme: %v, %v", src, err)
+	}
+}
+
+// index is releases.json for a staged and an unstaged release whose files
+// are in files, keyed by their path in the index.
+func index(files map[string]string) string {
+	sum := func(name string) string {
+		h := sha256.Sum256([]byte(files[name]))
+		return hex.EncodeToString(h[:])
+	}
+	return fmt.Sprintf(`[
+  {"version": "v1.3.0", "rollout": 25, "assets": [
+    {"name": "app-macos.zip", "url": "v1.3.0/app-macos.zip", "size": 3, "sha256": %q}]},
+  {"version": "v1.2.0", "assets": [
+    {"name": "app macos.zip", "url": "v1.2.0/app%%20macos.zip", "size": 3, "sha256": %q}]}
+]`, sum("v1.3.0/app-macos.zip"), sum("v1.2.0/app macos.zip"))
+}
+
+func TestHTTPIndex(t *testing.T) {
+	files := map[string]string{"v1.3.0/app-macos.zip": "new", "v1.2.0/app macos.zip": "old"}
+	files[IndexFile] = index(files)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "ci" || pass != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		data, ok := files[strings.TrimPrefix(r.URL.Path, "/releases/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, data)
+	}))
+	defer srv.Close()
+
+	src, err := Open(strings.Replace(srv.URL, "://", "://ci:secret@", 1) + "/releases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := src.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.TagName != "v1.3.0" || *latest.RolloutPercentage != 25 || latest.Assets[0].BrowserDownloadURL != srv.URL+"/releases/v1.3.0/app-macos.zip" {
+		t.Errorf("latest = %+v", latest)
+	}
+
+	old, err := src.Release("1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.RolloutPercentage != nil {
+		t.Errorf("release without a rollout has RolloutPercentage %d, want nil (everyone)", *old.RolloutPercentage)
+	}
+	var buf bytes.Buffer
+	if err := src.Download(old.Assets[0], &buf); err != nil || buf.String() != "old" {
+		t.Errorf("Download = %q, %v", buf.String(), err)
+	}
+	buf.Reset()
+	if err := src.(Resumer).DownloadFrom(old.Assets[0], 1, &buf); err != nil || buf.String() != "ld" {
+		t.Errorf("DownloadFrom = %q, %v", buf.String(), err)
+	}
+	if _, err := src.Release("v9.9.9"); !errors.Is(err, ghapi.ErrNotFound) {
+		t.Errorf("missing release: %v", err)
+	}
+
+	files["v1.3.0/app-macos.zip"] = "tampered"
+	if err := src.Download(latest.Assets[0], io.Discard); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("tampered download: %v", err)
+	}
+}
+
+func TestBucketIndex(t *testing.T) {
+	files := map[string]string{"v1.3.0/app-macos.zip": "new", "v1.2.0/app macos.zip": "old"}
+	files[IndexFile] = index(files)
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		data, ok := files[strings.TrimPrefix(r.URL.Path, "/updates/acme/app/")]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, data)
+	}))
+	defer srv.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	src, err := Open("s3://updates/acme/app?region=eu-west-1&endpoint=" + url.QueryEscape(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := src.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Download(latest.Assets[0], io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(auth[0], "AWS4-HMAC-SHA256 Credential=key/") || !strings.Contains(auth[0], "/eu-west-1/s3/") {
+		t.Errorf("Authorization = %q", auth[0])
+	}
+	if err := src.Download(ghapi.Asset{Name: "gone.zip", URL: "v1.3.0/gone.zip"}, io.Discard); !errors.Is(err, ghapi.ErrNotFound) {
+		t.Errorf("missing object: %v", err)
+	}
+}
+
+func TestGCSIndex(t *testing.T) {
+	files := map[string]string{"v1.3.0/app-macos.zip": "new", "v1.2.0/app macos.zip": "old"}
+	files[IndexFile] = index(files)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[strings.TrimPrefix(r.URL.Path, "/updates/app/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, data)
+	}))
+	defer srv.Close()
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	src, err := Open("gs://updates/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.(*Index).Store.(*GCSStore).Endpoint = srv.URL
+	old, err := src.Release("v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := src.Download(old.Assets[0], &buf); err != nil || buf.String() != "old" {
+		t.Errorf("Download = %q, %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,218 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+	"github.com/joeblew999/goup-util/pkg/googleauth"
+	"github.com/joeblew999/goup-util/pkg/upload"
+)
+
+// HTTPStore is an index on a web server, such as a bucket's public or
+// access-controlled domain.
+type HTTPStore struct {
+	Base     *url.URL // Without credentials; ends in /
+	User     string   // Basic auth, when set
+	Password string
+	HTTP     *http.Client
+}
+
+func openHTTP(u *url.URL) (Source, error) {
+	base := *u
+	base.User = nil
+	base.RawQuery, base.Fragment = "", ""
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	s := &HTTPStore{Base: &base, HTTP: &http.Client{Timeout: 30 * time.Minute}}
+	if u.User != nil {
+		s.User = u.User.Username()
+		s.Password, _ = u.User.Password()
+	}
+	return &Index{Store: s}, nil
+}
+
+// URL resolves path against the base.
+func (s *HTTPStore) URL(path string) string {
+	return s.Base.ResolveReference(&url.URL{Path: path}).String()
+}
+
+// Get downloads path to w.
+func (s *HTTPStore) Get(path string, w io.Writer) error {
+	return s.GetFrom(path, 0, w)
+}
+
+// GetFrom downloads path from byte offset on.
+func (s *HTTPStore) GetFrom(path string, offset int64, w io.Writer) error {
+	req, err := http.NewRequest("GET", s.URL(path), nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if s.User != "" {
+		req.SetBasicAuth(s.User, s.Password)
+	}
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ghapi.ErrNotFound
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent:
+		return fmt.Errorf("GET %s: %s", req.URL.Redacted(), resp.Status)
+	}
+	if err := ghapi.SkipTo(resp, offset); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// BucketStore is an index in an S3 or R2 bucket.
+type BucketStore struct {
+	Bucket *upload.Bucket
+	Scheme string // s3 or r2, for URL
+}
+
+// openS3 reads an AWS bucket, or any S3-compatible one with ?endpoint=.
+func openS3(u *url.URL) (Source, error) {
+	region := u.Query().Get("region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	b := &upload.Bucket{
+		Endpoint:  u.Query().Get("endpoint"),
+		Region:    region,
+		Name:      u.Host,
+		Prefix:    strings.Trim(u.Path, "/"),
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		HTTP:      &http.Client{Timeout: 30 * time.Minute},
+	}
+	if b.Endpoint == "" {
+		b.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	return &Index{Store: &BucketStore{Bucket: b, Scheme: "s3"}}, nil
+}
+
+func openR2(u *url.URL) (Source, error) {
+	account := u.Query().Get("account")
+	if account == "" {
+		account = os.Getenv("R2_ACCOUNT_ID")
+	}
+	if account == "" {
+		return nil, fmt.Errorf("%s: the Cloudflare account is missing (add ?account=<id> or set R2_ACCOUNT_ID)", u.Redacted())
+	}
+	b := upload.NewR2(account, u.Host, strings.Trim(u.Path, "/"), os.Getenv("R2_ACCESS_KEY_ID"), os.Getenv("R2_SECRET_ACCESS_KEY"))
+	return &Index{Store: &BucketStore{Bucket: b, Scheme: "r2"}}, nil
+}
+
+// URL is the object's s3:// or r2:// address.
+func (s *BucketStore) URL(path string) string {
+	return s.Scheme + "://" + strings.TrimSuffix(s.Bucket.Name+"/"+strings.TrimPrefix(s.Bucket.Prefix+"/"+path, "/"), "/")
+}
+
+// Get downloads the object at path to w.
+func (s *BucketStore) Get(path string, w io.Writer) error {
+	return s.GetFrom(path, 0, w)
+}
+
+// GetFrom downloads the object at path from byte offset on.
+func (s *BucketStore) GetFrom(path string, offset int64, w io.Writer) error {
+	err := s.Bucket.GetFrom(path, offset, w)
+	var se *upload.StatusError
+	if errors.As(err, &se) && se.Code == http.StatusNotFound {
+		return ghapi.ErrNotFound
+	}
+	return err
+}
+
+// GCSScope is the OAuth scope for reading objects.
+const GCSScope = "https://www.googleapis.com/auth/devstorage.read_only"
+
+// GCSStore is an index in a Google Cloud Storage bucket.
+type GCSStore struct {
+	Endpoint string // https://storage.googleapis.com
+	Bucket   string
+	Prefix   string
+	Auth     *googleauth.Source // nil reads anonymously
+	HTTP     *http.Client
+}
+
+func openGCS(u *url.URL) (Source, error) {
+	s := &GCSStore{
+		Endpoint: "https://storage.googleapis.com",
+		Bucket:   u.Host,
+		Prefix:   strings.Trim(u.Path, "/"),
+		HTTP:     &http.Client{Timeout: 30 * time.Minute},
+	}
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+		}
+		if s.Auth, err = googleauth.New(key, GCSScope); err != nil {
+			return nil, err
+		}
+	}
+	return &Index{Store: s}, nil
+}
+
+// URL is the object's address on the storage endpoint.
+func (s *GCSStore) URL(path string) string {
+	key := strings.TrimPrefix(s.Prefix+"/"+path, "/")
+	return strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+// Get downloads the object at path to w.
+func (s *GCSStore) Get(path string, w io.Writer) error {
+	return s.GetFrom(path, 0, w)
+}
+
+// GetFrom downloads path from byte offset on.
+func (s *GCSStore) GetFrom(path string, offset int64, w io.Writer) error {
+	req, err := http.NewRequest("GET", s.URL(path), nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if s.Auth != nil {
+		token, err := s.Auth.Token(s.HTTP)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ghapi.ErrNotFound
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent:
+		return fmt.Errorf("GET %s: %s", req.URL, resp.Status)
+	}
+	if err := ghapi.SkipTo(resp, offset); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,150 @@
+package self
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+	"github.com/joeblew999/goup-util/pkg/self/output"
+)
+
+// Status reports the goup-util found in PATH, where it is installed to, and whether a newer release is out. A failed release check is
+// recorded in CheckError rather than returned.
+func Status() *output.StatusResult {
+	result := &output.StatusResult{InstallPath: GetInstallPath()}
+
+	for i, path := range findAllInstallations() {
+		result.Installations = append(result.Installations, output.InstallationInfo{
+			Path:     path,
+			Active:   i == 0,
+			Shadowed: i > 0,
+		})
+	}
+	if len(result.Installations) > 0 {
+		result.Installed = true
+		result.Location = result.Installations[0].Path
+		// Installed, but something else earlier in PATH runs instead
+		for _, inst := range result.Installations[1:] {
+			if samePath(inst.Path, result.InstallPath) {
+				result.Shadowed = true
+			}
+		}
+	}
+
+	running, _ := os.Executable()
+	if result.Location == "" || samePath(running, result.Location) {
+		result.CurrentVersion = CurrentVersion()
+	} else {
+		result.Running = running
+		result.CurrentVersion = binaryVersion(result.Location)
+	}
+
+	latest, err := latestReleaseTag()
+	if err != nil {
+		result.CheckError = err.Error()
+	} else {
+		result.LatestVersion = latest
+		result.UpdateAvailable = compareVersions(latest, result.CurrentVersion) > 0
+	}
+	return result
+}
+
+// ShowStatus prints Status, as JSON or for people.
+func ShowStatus(asJSON bool) error {
+	result := Status()
+	if asJSON {
+		output.Print(result, output.CommandStatus)
+		return nil
+	}
+
+	version := orDev(result.CurrentVersion)
+	if result.CurrentVersion == "" && result.Running != "" {
+		version = "unknown version"
+	}
+	if !result.Installed {
+		fmt.Printf("⚠️  goup-util is not in PATH (this is %s)\n", version)
+		fmt.Println("   Run: goup-util self setup")
+	} else {
+		fmt.Printf("goup-util %s\n", version)
+		fmt.Printf("   Location: %s\n", result.Location)
+	}
+	if result.Running != "" {
+		fmt.Printf("   Running:  %s (%s)\n", result.Running, orDev(CurrentVersion()))
+	}
+	if result.Shadowed {
+		fmt.Printf("⚠️  %s is shadowed: %s comes first in PATH\n", result.InstallPath, result.Location)
+		fmt.Println("   Remove it or move it later in PATH ('goup-util self doctor' lists every copy)")
+	}
+	switch {
+	case result.CheckError != "":
+		fmt.Printf("⚠️  %s\n", result.CheckError)
+	case result.UpdateAvailable:
+		fmt.Printf("⬆️  %s is available — run: goup-util self upgrade\n", result.LatestVersion)
+	default:
+		fmt.Printf("✓ Up to date (latest release %s)\n", result.LatestVersion)
+	}
+	return nil
+}
+
+func orDev(version string) string {
+	if version == "" {
+		return "development build"
+	}
+	return version
+}
+
+// samePath compares paths after resolving symlinks, ignoring case on
+// Windows.
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if r, err := filepath.EvalSymlinks(a); err == nil {
+		a = r
+	}
+	if r, err := filepath.EvalSymlinks(b); err == nil {
+		b = r
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Clean(a), filepath.Clean(b))
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// binaryVersion asks another goup-util binary for its version.
+func binaryVersion(path string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "self", "version").Output()
+	if err != nil {
+		return ""
+	}
+	var base output.BaseResult
+	if err := json.Unmarshal(out, &base); err != nil {
+		return ""
+	}
+	v, err := base.ParseVersionData()
+	if err != nil || v.Version == "dev" {
+		return ""
+	}
+	if !strings.HasPrefix(v.Version, "v") {
+		return "v" + v.Version
+	}
+	return v.Version
+}
+
+// latestReleaseTag asks GitHub for the release 'self upgrade' would install.
+func latestReleaseTag() (string, error) {
+	release, err := ghapi.New().LatestRelease(FullRepoName)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for updates: %w", err)
+	}
+	return release.TagName, nil
+}
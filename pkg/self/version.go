@@ -1,18 +1,44 @@
 package self
 
 import (
-	"fmt"
 	"os/exec"
-	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"strings"
 
 	"github.com/joeblew999/goup-util/pkg/self/output"
+	"golang.org/x/mod/semver"
 )
 
 // Version is set by the build process
 var Version = "dev"
 
+// CurrentVersion returns the release this binary was built from: Version
+// when the build stamped it, else the module version Go records for
+// 'go install ...@v1.2.3' and tagged builds. It is "" for development
+// builds.
+func CurrentVersion() string {
+	if v := normalizeVersion(Version); semver.IsValid(v) {
+		return v
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && semver.IsValid(info.Main.Version) && semver.Prerelease(info.Main.Version) == "" {
+		return info.Main.Version
+	}
+	return ""
+}
+
+// compareVersions is semver.Compare, treating an unknown current version
+// (a development build) as older than any release.
+func compareVersions(release, current string) int {
+	if current == "" {
+		return 1
+	}
+	if !strings.HasPrefix(release, "v") {
+		release = "v" + release
+	}
+	return semver.Compare(release, current)
+}
+
 // ShowVersion displays the current version of goup-util
 func ShowVersion() error {
 	output.Run("self version", func() (*output.VersionResult, error) {
@@ -30,65 +56,3 @@ func ShowVersion() error {
 	})
 	return nil
 }
-
-// ShowStatus checks installation status and available updates
-func ShowStatus() error {
-	output.Run("self status", func() (*output.StatusResult, error) {
-		result := &output.StatusResult{}
-
-		// Check if installed
-		installPath, err := exec.LookPath("goup-util")
-		if err != nil {
-			result.Installed = false
-			result.UpdateAvailable = false
-			return result, nil
-		}
-
-		result.Installed = true
-		result.CurrentVersion = normalizeVersion(Version)
-		result.Location = installPath
-
-		// Check for updates (from GitHub)
-		latest, err := getLatestVersion(FullRepoName)
-		if err == nil && latest != "" {
-			result.LatestVersion = latest
-			result.UpdateAvailable = (result.CurrentVersion != latest)
-		} else {
-			result.LatestVersion = ""
-			result.UpdateAvailable = false
-		}
-
-		return result, nil
-	})
-	return nil
-}
-
-// getLatestVersion fetches the latest release tag from GitHub
-func getLatestVersion(repo string) (string, error) {
-	cmd := exec.Command("git", "ls-remote", "--tags", "--refs",
-		fmt.Sprintf("https://github.com/%s.git", repo))
-
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-
-	// Parse output to find latest semver tag
-	lines := strings.Split(string(output), "\n")
-	var latest string
-
-	for _, line := range lines {
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
-		}
-
-		tag := filepath.Base(parts[1]) // Extract tag name from refs/tags/v1.2.3
-		if strings.HasPrefix(tag, "v") && strings.Contains(tag, ".") {
-			// Simple comparison - just use last tag (sorted by git)
-			latest = tag
-		}
-	}
-
-	return latest, nil
-}
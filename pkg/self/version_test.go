@@ -0,0 +1,33 @@
+package self
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		release, current string
+		want             int
+	}{
+		{"v1.3.0", "v1.2.0", 1},
+		{"v1.2.0", "v1.2.0", 0},
+		{"1.2.0", "v1.2.0", 0},
+		{"v1.9.1", "v1.10.0", -1}, // hotfix of an older line published as latest
+		{"v1.0.0", "", 1},         // development builds always upgrade
+	} {
+		if got := compareVersions(tc.release, tc.current); got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.release, tc.current, got, tc.want)
+		}
+	}
+}
+
+func TestCurrentVersion(t *testing.T) {
+	defer func(v string) { Version = v }(Version)
+
+	Version = "1.4.0"
+	if got := CurrentVersion(); got != "v1.4.0" {
+		t.Errorf("CurrentVersion() = %q, want v1.4.0", got)
+	}
+	Version = "dev"
+	if got := CurrentVersion(); got != "" {
+		t.Errorf("CurrentVersion() = %q for a test build, want \"\"", got)
+	}
+}
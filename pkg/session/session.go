@@ -0,0 +1,169 @@
+// Package session records what happens to a running app (deep links,
+// page navigations, JavaScript bridge calls) and plays it back, so a bug
+// that needs a sequence of events can be reproduced on demand.
+//
+// 'goup-util run --record' sets $GOUP_SESSION to a directory in the app
+// (.bin/sessions). An app that sees it appends every event to a new JSON
+// Lines file there, one object per line:
+//
+//	{"at":"2026-10-18T09:30:01.2Z","kind":"url","url":"myapp://orders/42"}
+//	{"at":"2026-10-18T09:30:01.9Z","kind":"navigate","url":"http://127.0.0.1:8123/#/orders/42"}
+//	{"at":"2026-10-18T09:30:04.0Z","kind":"bridge","url":"http://127.0.0.1:8123/","data":"{\"op\":\"clipboard.write\",...}"}
+//
+// and watches the directory for ReplayFile. 'goup-util replay' writes a
+// recorded session there; the running app takes it, re-injects its events
+// through the same code paths with the recorded pauses between them, and
+// records the replay as a new session, which can be compared with the
+// first.
+//
+// Sessions can hold what users typed into the page. Apps leave secure
+// storage payloads out, and only record while $GOUP_SESSION is set.
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Env is the directory an app records sessions to and takes replays from.
+const Env = "GOUP_SESSION"
+
+// Dir is where 'goup-util run --record' puts sessions, relative to the app.
+const Dir = ".bin/sessions"
+
+// ReplayFile, in the session directory, is a session for the app to replay.
+const ReplayFile = "replay.jsonl"
+
+// Event kinds.
+const (
+	URL      = "url"      // Deep link the app was opened with
+	Navigate = "navigate" // Page the webview loaded
+	Bridge   = "bridge"   // Message a page sent to the app
+)
+
+// MaxPause caps the pause before an event on replay, so a session left
+// idle does not stall it.
+const MaxPause = 5 * time.Second
+
+// Event is one recorded event.
+type Event struct {
+	At   time.Time `json:"at"`
+	Kind string    `json:"kind"`
+	URL  string    `json:"url,omitempty"`  // Deep link, page loaded, or page that sent the message
+	Data string    `json:"data,omitempty"` // Bridge message
+}
+
+// Recorder appends events to a session file. It is safe for concurrent use.
+type Recorder struct {
+	Path string
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// Create starts a session file in dir, named after the current time.
+func Create(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, time.Now().Format("20060102-150405")+".jsonl")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{Path: path, f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Record appends e, stamping it with the current time if At is zero.
+func (r *Recorder) Record(e Event) error {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(e)
+}
+
+// Close closes the session file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// Load reads a session file.
+func Load(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20) // Bridge messages can carry file contents
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		switch e.Kind {
+		case URL, Navigate, Bridge:
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown event kind %q", path, line, e.Kind)
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// Send hands events to the app recording in dir. The file is written
+// whole and then renamed, so the app never reads half of it.
+func Send(dir string, events []Event) error {
+	if len(events) == 0 {
+		return errors.New("session has no events")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".replay-*")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(tmp)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, ReplayFile))
+}
+
+// Pauses returns how long to wait before each event on replay: the
+// recorded gap to the previous event, at most MaxPause.
+func Pauses(events []Event) []time.Duration {
+	pauses := make([]time.Duration, len(events))
+	for i := 1; i < len(events); i++ {
+		gap := events[i].At.Sub(events[i-1].At)
+		pauses[i] = min(max(gap, 0), MaxPause)
+	}
+	return pauses
+}
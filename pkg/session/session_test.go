@@ -0,0 +1,65 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), Dir)
+	r, err := Create(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	want := []Event{
+		{At: start, Kind: URL, URL: "myapp://orders/42"},
+		{At: start.Add(700 * time.Millisecond), Kind: Navigate, URL: "http://127.0.0.1:8123/#/orders/42"},
+		{At: start.Add(time.Minute), Kind: Bridge, URL: "http://127.0.0.1:8123/", Data: `{"op":"clipboard.write","text":"42"}`},
+	}
+	for _, e := range want {
+		if err := r.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Close()
+
+	got, err := Load(r.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(got, want, func(a, b Event) bool {
+		return a.At.Equal(b.At) && a.Kind == b.Kind && a.URL == b.URL && a.Data == b.Data
+	}) {
+		t.Errorf("Load = %+v", got)
+	}
+	if pauses := Pauses(got); !slices.Equal(pauses, []time.Duration{0, 700 * time.Millisecond, MaxPause}) {
+		t.Errorf("Pauses = %v", pauses)
+	}
+
+	if err := Send(dir, got); err != nil {
+		t.Fatal(err)
+	}
+	sent, err := Load(filepath.Join(dir, ReplayFile))
+	if err != nil || len(sent) != len(want) {
+		t.Errorf("replay file = %d events, %v", len(sent), err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, ".replay-*")); len(leftovers) != 0 {
+		t.Errorf("temporary files left: %v", leftovers)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.jsonl")
+	os.WriteFile(path, []byte(`{"kind":"url","url":"a://b"}`+"\n\n"+`{"kind":"tap"}`+"\n"), 0644)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), ":3: unknown event kind") {
+		t.Errorf("Load = %v", err)
+	}
+	if err := Send(t.TempDir(), nil); err == nil {
+		t.Error("Send accepted an empty session")
+	}
+}
@@ -0,0 +1,195 @@
+package shellenv
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// StateVar is where the hook remembers which project it applied and the
+// values it replaced, so leaving the project puts them back.
+const StateVar = "GOUP_ENV_STATE"
+
+type state struct {
+	Dir   string             `json:"dir"`
+	Stamp string             `json:"stamp"`
+	Saved map[string]*string `json:"saved"` // nil: was unset
+}
+
+func readState(lookup func(string) (string, bool)) *state {
+	raw, ok := lookup(StateVar)
+	if !ok || raw == "" {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil
+	}
+	var s state
+	if json.Unmarshal(data, &s) != nil {
+		return nil
+	}
+	return &s
+}
+
+func (s *state) encode() string {
+	data, _ := json.Marshal(s)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Restore puts back, in this process, the values the hook replaced, so the
+// next project's environment is worked out from a clean slate.
+func Restore() {
+	s := readState(os.LookupEnv)
+	if s == nil {
+		return
+	}
+	for name, value := range s.Saved {
+		if value == nil {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, *value)
+		}
+	}
+}
+
+// Snapshot returns a lookup over the current process environment that
+// later Setenv calls do not affect.
+func Snapshot() func(string) (string, bool) {
+	vars := map[string]string{}
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok && name != "" {
+			vars[name] = value
+		}
+	}
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+// Export returns the statements that take the shell from the project it
+// was in (if any) to env for the project in dir. env is nil outside a
+// project. Nothing is returned when the shell is already up to date, so
+// the hook is cheap to run on every prompt.
+func Export(shell, dir string, env *Env, lookup func(string) (string, bool)) string {
+	old := readState(lookup)
+	var stamp string
+	if env != nil {
+		sum := sha256.Sum256([]byte(Render(Bash, *env)))
+		stamp = hex.EncodeToString(sum[:8])
+	}
+	if old == nil && env == nil {
+		return ""
+	}
+	if old != nil && env != nil && old.Dir == dir && old.Stamp == stamp {
+		return ""
+	}
+
+	// The value before any project was entered
+	original := func(name string) *string {
+		if old != nil {
+			if value, ok := old.Saved[name]; ok {
+				return value
+			}
+		}
+		if value, ok := lookup(name); ok {
+			return &value
+		}
+		return nil
+	}
+
+	changes := map[string]*string{}
+	if old != nil {
+		maps.Copy(changes, old.Saved)
+	}
+	if env == nil {
+		changes[StateVar] = nil
+	} else {
+		next := &state{Dir: dir, Stamp: stamp, Saved: map[string]*string{}}
+		for _, v := range env.Vars {
+			next.Saved[v.Name] = original(v.Name)
+			changes[v.Name] = &v.Value
+		}
+		if len(env.Path) > 0 {
+			base := original("PATH")
+			next.Saved["PATH"] = base
+			path := strings.Join(env.Path, string(os.PathListSeparator))
+			if base != nil && *base != "" {
+				path += string(os.PathListSeparator) + *base
+			}
+			changes["PATH"] = &path
+		}
+		encoded := next.encode()
+		changes[StateVar] = &encoded
+	}
+
+	var sets Env
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(changes)) {
+		if value := changes[name]; value != nil {
+			sets.Vars = append(sets.Vars, Var{Name: name, Value: *value})
+		} else {
+			b.WriteString(unset(shell, name))
+		}
+	}
+	return b.String() + Render(shell, sets)
+}
+
+func unset(shell, name string) string {
+	switch shell {
+	case Fish:
+		return fmt.Sprintf("set -e %s\n", name)
+	case PowerShell:
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue\n", name)
+	default:
+		return fmt.Sprintf("unset %s\n", name)
+	}
+}
+
+// Hook returns the code that makes shell run 'exe env export' before each
+// prompt. It goes in the shell's startup file, e.g.
+//
+//	eval "$(goup-util env hook zsh)"
+func Hook(shell, exe string) (string, error) {
+	switch shell {
+	case Zsh:
+		return fmt.Sprintf(`_goup_util_hook() {
+  eval "$(%[1]s env export zsh)"
+}
+typeset -ag precmd_functions
+if (( ! ${precmd_functions[(I)_goup_util_hook]} )); then
+  precmd_functions=(_goup_util_hook $precmd_functions)
+fi
+`, quote(Bash, exe)), nil
+	case Bash:
+		return fmt.Sprintf(`_goup_util_hook() {
+  local previous_exit_status=$?
+  eval "$(%[1]s env export bash)"
+  return $previous_exit_status
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_goup_util_hook;"* ]]; then
+  PROMPT_COMMAND="_goup_util_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`, quote(Bash, exe)), nil
+	case Fish:
+		return fmt.Sprintf(`function __goup_util_hook --on-event fish_prompt
+  %[1]s env export fish | source
+end
+`, quote(Fish, exe)), nil
+	case PowerShell:
+		return fmt.Sprintf(`$__goupUtilPrompt = $function:prompt
+function global:prompt {
+  (& %[1]s env export powershell) -join [Environment]::NewLine | Invoke-Expression
+  & $__goupUtilPrompt
+}
+`, quote(PowerShell, exe)), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q", shell)
+}
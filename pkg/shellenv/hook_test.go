@@ -0,0 +1,126 @@
+package shellenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// shellEnv stands in for the shell: it applies Export's bash output.
+type shellEnv map[string]string
+
+func (s shellEnv) lookup(name string) (string, bool) {
+	v, ok := s[name]
+	return v, ok
+}
+
+func (s shellEnv) apply(t *testing.T, script string) {
+	t.Helper()
+	for _, line := range strings.Split(strings.TrimSpace(script), "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "unset "):
+			delete(s, strings.TrimPrefix(line, "unset "))
+		case strings.HasPrefix(line, "export "):
+			name, value, _ := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+			value = strings.NewReplacer(`\$`, `$`, `\"`, `"`, `\\`, `\`).Replace(strings.Trim(value, `"`))
+			s[name] = value
+		default:
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	sh := shellEnv{"PATH": "/usr/bin", "GOFLAGS": "-mod=mod"}
+
+	var a Env
+	a.Set("GOFLAGS", "-trimpath")
+	a.Set("GOTOOLCHAIN", "go1.23.4")
+	a.PrependPath("/a/bin")
+
+	// Entering a project
+	sh.apply(t, Export(Bash, "/a", &a, sh.lookup))
+	if sh["GOFLAGS"] != "-trimpath" || sh["GOTOOLCHAIN"] != "go1.23.4" || sh["PATH"] != "/a/bin"+sep+"/usr/bin" {
+		t.Fatalf("project not applied: %v", sh)
+	}
+
+	// Next prompt in the same project: nothing to do
+	if out := Export(Bash, "/a", &a, sh.lookup); out != "" {
+		t.Errorf("unchanged project exported again:\n%s", out)
+	}
+
+	// Switching projects starts from the original values, not project a's
+	var b Env
+	b.Set("ANDROID_HOME", "/b/sdks")
+	b.PrependPath("/b/bin")
+	sh.apply(t, Export(Bash, "/b", &b, sh.lookup))
+	if sh["GOFLAGS"] != "-mod=mod" || sh["PATH"] != "/b/bin"+sep+"/usr/bin" || sh["ANDROID_HOME"] != "/b/sdks" {
+		t.Errorf("switch kept project a's values: %v", sh)
+	}
+	if _, ok := sh["GOTOOLCHAIN"]; ok {
+		t.Error("GOTOOLCHAIN from project a survived the switch")
+	}
+
+	// Leaving restores everything
+	sh.apply(t, Export(Bash, "", nil, sh.lookup))
+	want := shellEnv{"PATH": "/usr/bin", "GOFLAGS": "-mod=mod"}
+	if len(sh) != len(want) || sh["PATH"] != want["PATH"] || sh["GOFLAGS"] != want["GOFLAGS"] {
+		t.Errorf("after leaving got %v, want %v", sh, want)
+	}
+	if out := Export(Bash, "", nil, sh.lookup); out != "" {
+		t.Errorf("outside a project exported:\n%s", out)
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	sub := filepath.Join(root, "app", "cmd")
+	os.MkdirAll(sub, 0755)
+
+	// No goup.json, and a goup.json without env, leave the shell alone
+	if p, err := LoadProject(sub); p != nil || err != nil {
+		t.Fatalf("LoadProject without goup.json = %v, %v", p, err)
+	}
+	write := func(content string) {
+		os.WriteFile(filepath.Join(root, "goup.json"), []byte(content), 0644)
+	}
+	write(`{"hooks": {"pre_build": ["go vet ./..."]}}`)
+	if p, err := LoadProject(sub); p != nil || err != nil {
+		t.Fatalf("LoadProject with hooks only = %v, %v", p, err)
+	}
+
+	write(`{"env": {"go": "1.23.4", "sdkDir": ".sdks", "signing": {"ANDROID_KEYSTORE_FILE": "keys/release.jks"}}}`)
+	p, err := LoadProject(sub)
+	if err != nil || p == nil {
+		t.Fatalf("LoadProject = %v, %v", p, err)
+	}
+	var env Env
+	p.Apply(&env)
+	got := map[string]string{}
+	for _, v := range env.Vars {
+		got[v.Name] = v.Value
+	}
+	if got["GOTOOLCHAIN"] != "go1.23.4" || got["ANDROID_KEYSTORE_FILE"] != filepath.Join(root, "keys", "release.jks") {
+		t.Errorf("Apply = %v", got)
+	}
+	if p.SDKPath() != filepath.Join(root, ".sdks") {
+		t.Errorf("SDKPath = %q", p.SDKPath())
+	}
+
+	for _, bad := range []string{
+		`{"env": {"go": "latest"}}`,
+		`{"env": {"signing": {"ANDROID_KEYSTORE_PASSWORD": "hunter2"}}}`,
+		`{"env": {"signing": {"ANDROID_KEYSTORE": "MIIK..."}}}`,
+		`{"env": {"signing": {"NOT_A_SECRET_FILE": "x"}}}`,
+		`{"env": {"vars": {"PATH": "/bin"}}}`,
+	} {
+		write(bad)
+		if _, err := LoadProject(sub); err == nil {
+			t.Errorf("LoadProject accepted %s", bad)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package shellenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/joeblew999/goup-util/pkg/hooks"
+	"github.com/joeblew999/goup-util/pkg/secrets"
+)
+
+// Project is the env section of goup.json, which the shell hook applies
+// while you are inside the project:
+//
+//	{
+//	  "env": {
+//	    "go": "1.23.4",
+//	    "sdkDir": ".sdks",
+//	    "signing": {
+//	      "ANDROID_KEYSTORE_FILE": "keys/release.jks",
+//	      "MACOS_SIGNING_IDENTITY": "Developer ID Application: Example (ABCDE12345)"
+//	    },
+//	    "vars": {"GOFLAGS": "-trimpath"}
+//	  }
+//	}
+type Project struct {
+	Go      string            `json:"go,omitempty"`      // Go toolchain, exported as GOTOOLCHAIN
+	SDKDir  string            `json:"sdkDir,omitempty"`  // Project-private SDKs, exported as GOUP_SDK_DIR
+	Signing map[string]string `json:"signing,omitempty"` // Secret references, see secrets.Known
+	Vars    map[string]string `json:"vars,omitempty"`    // Anything else
+
+	// Dir holds goup.json; relative paths are resolved against it.
+	Dir string `json:"-"`
+}
+
+var goVersion = regexp.MustCompile(`^(go)?1\.\d+(\.\d+|rc\d+)?$`)
+
+// LoadProject finds goup.json in dir or its parents and returns its env
+// section. It returns nil when there is no goup.json or it has no env
+// section, so the hook leaves the shell alone.
+func LoadProject(dir string) (*Project, error) {
+	path, data, err := hooks.Find(dir)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	var file struct {
+		Env *Project `json:"env"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if file.Env == nil {
+		return nil, nil
+	}
+	p := file.Env
+	p.Dir = filepath.Dir(path)
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+func (p *Project) validate() error {
+	if p.Go != "" && p.Go != "local" && !goVersion.MatchString(p.Go) {
+		return fmt.Errorf("env.go must be a Go version like 1.23.4, got %q", p.Go)
+	}
+	for name := range p.Signing {
+		if err := validSigningVar(name); err != nil {
+			return err
+		}
+	}
+	for name := range p.Vars {
+		if name == "PATH" || strings.HasPrefix(name, "GOUP_ENV") {
+			return fmt.Errorf("env.vars cannot set %s", name)
+		}
+	}
+	return nil
+}
+
+// validSigningVar allows references to signing material (NAME_FILE paths
+// and identity names) but not the secrets themselves, which must not be
+// committed in goup.json.
+func validSigningVar(name string) error {
+	base, isFile := strings.CutSuffix(name, "_FILE")
+	def, ok := secrets.Lookup(base)
+	if !ok {
+		return fmt.Errorf("env.signing: unknown secret %s", base)
+	}
+	if isFile {
+		return nil
+	}
+	if def.Kind == secrets.File || strings.Contains(def.Name, "PASSWORD") {
+		return fmt.Errorf("env.signing: %s is a secret; reference it with %s_FILE or keep it in 'goup-util secrets'", name, name)
+	}
+	return nil
+}
+
+// Apply adds the project's settings to env. The SDK directory is applied
+// separately, before the SDK paths are worked out.
+func (p *Project) Apply(env *Env) {
+	if p.Go != "" {
+		toolchain := p.Go
+		if toolchain != "local" && !strings.HasPrefix(toolchain, "go") {
+			toolchain = "go" + toolchain
+		}
+		env.Set("GOTOOLCHAIN", toolchain)
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.Signing)) {
+		value := p.Signing[name]
+		if strings.HasSuffix(name, "_FILE") {
+			value = p.Path(value)
+		}
+		env.Set(name, value)
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.Vars)) {
+		env.Set(name, p.Vars[name])
+	}
+}
+
+// SDKPath returns the absolute SDK directory, or "" for the shared one.
+func (p *Project) SDKPath() string {
+	if p.SDKDir == "" {
+		return ""
+	}
+	return p.Path(p.SDKDir)
+}
+
+// Path resolves path against the directory holding goup.json.
+func (p *Project) Path(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.Dir, path)
+}
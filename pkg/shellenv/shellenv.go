@@ -0,0 +1,301 @@
+// Package shellenv renders environment variables for shells and keeps them
+// in a managed block of the user's shell startup file.
+package shellenv
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Supported shells
+const (
+	Bash       = "bash"
+	Zsh        = "zsh"
+	Fish       = "fish"
+	PowerShell = "powershell"
+)
+
+// Shells lists the accepted --shell values.
+var Shells = []string{Bash, Zsh, Fish, PowerShell}
+
+// Markers delimit the block goup-util owns in a startup file. Everything
+// between them is rewritten on every apply.
+const (
+	BeginMarker = "# >>> goup-util env >>>"
+	EndMarker   = "# <<< goup-util env <<<"
+)
+
+// Var is one exported variable.
+type Var struct {
+	Name  string
+	Value string
+}
+
+// Env is what goup-util wants in the shell: variables, in order, and
+// directories to put in front of PATH.
+type Env struct {
+	Vars []Var
+	Path []string
+}
+
+// Set adds or replaces a variable.
+func (e *Env) Set(name, value string) {
+	for i := range e.Vars {
+		if e.Vars[i].Name == name {
+			e.Vars[i].Value = value
+			return
+		}
+	}
+	e.Vars = append(e.Vars, Var{Name: name, Value: value})
+}
+
+// PrependPath adds dir to the PATH additions once.
+func (e *Env) PrependPath(dir string) {
+	for _, p := range e.Path {
+		if p == dir {
+			return
+		}
+	}
+	e.Path = append(e.Path, dir)
+}
+
+// Detect guesses the user's shell: PowerShell on Windows, otherwise the
+// basename of $SHELL, defaulting to bash.
+func Detect() string {
+	if runtime.GOOS == "windows" {
+		return PowerShell
+	}
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case Zsh:
+		return Zsh
+	case Fish:
+		return Fish
+	case "pwsh":
+		return PowerShell
+	}
+	return Bash
+}
+
+// Normalize validates a --shell value, detecting the shell when it is
+// empty and accepting sh and pwsh as aliases.
+func Normalize(shell string) (string, error) {
+	switch s := strings.ToLower(shell); s {
+	case "":
+		return Detect(), nil
+	case "pwsh":
+		return PowerShell, nil
+	case "sh":
+		return Bash, nil
+	case Bash, Zsh, Fish, PowerShell:
+		return s, nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(Shells, ", "))
+}
+
+// Render returns the statements that set env in shell, suitable for eval.
+func Render(shell string, env Env) string {
+	var b strings.Builder
+	for _, v := range env.Vars {
+		switch shell {
+		case Fish:
+			// fish keeps PATH-like variables as lists
+			value := quote(shell, v.Value)
+			if strings.HasSuffix(v.Name, "PATH") {
+				parts := strings.Split(v.Value, ":")
+				for i, p := range parts {
+					parts[i] = quote(shell, p)
+				}
+				value = strings.Join(parts, " ")
+			}
+			fmt.Fprintf(&b, "set -gx %s %s\n", v.Name, value)
+		case PowerShell:
+			fmt.Fprintf(&b, "$env:%s = %s\n", v.Name, quote(shell, v.Value))
+		default:
+			fmt.Fprintf(&b, "export %s=%s\n", v.Name, quote(shell, v.Value))
+		}
+	}
+	if len(env.Path) > 0 {
+		quoted := make([]string, len(env.Path))
+		for i, p := range env.Path {
+			quoted[i] = quote(shell, p)
+		}
+		switch shell {
+		case Fish:
+			fmt.Fprintf(&b, "fish_add_path -g %s\n", strings.Join(quoted, " "))
+		case PowerShell:
+			fmt.Fprintf(&b, "$env:PATH = (@(%s) + $env:PATH) -join [IO.Path]::PathSeparator\n", strings.Join(quoted, ", "))
+		default:
+			dirs := make([]string, len(env.Path))
+			for i, p := range env.Path {
+				dirs[i] = shEscape(p)
+			}
+			fmt.Fprintf(&b, "export PATH=\"%s:$PATH\"\n", strings.Join(dirs, ":"))
+		}
+	}
+	return b.String()
+}
+
+// quote makes value a literal string for shell.
+func quote(shell, value string) string {
+	switch shell {
+	case Fish:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+	case PowerShell:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	default:
+		return `"` + shEscape(value) + `"`
+	}
+}
+
+// shEscape escapes value for use inside double quotes in sh.
+func shEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`").Replace(value)
+}
+
+// Block wraps Render in the begin and end markers.
+func Block(shell string, env Env) string {
+	return BeginMarker + "\n" +
+		"# Managed by 'goup-util env --apply'; edits here are overwritten.\n" +
+		Render(shell, env) +
+		EndMarker + "\n"
+}
+
+// Upsert replaces the managed block in content with block, or appends it
+// when there is none.
+func Upsert(content, block string) string {
+	if start, end, ok := find(content); ok {
+		return content[:start] + block + content[end:]
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block
+}
+
+// Strip removes the managed block from content.
+func Strip(content string) (string, bool) {
+	start, end, ok := find(content)
+	if !ok {
+		return content, false
+	}
+	return content[:start] + content[end:], true
+}
+
+// find locates the managed block, including the end marker's newline.
+func find(content string) (int, int, bool) {
+	start := strings.Index(content, BeginMarker)
+	if start < 0 {
+		return 0, 0, false
+	}
+	rel := strings.Index(content[start:], EndMarker)
+	if rel < 0 {
+		return 0, 0, false
+	}
+	end := start + rel + len(EndMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
+
+// StartupFile returns the file shell reads for interactive sessions.
+func StartupFile(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	switch shell {
+	case Zsh:
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case Bash:
+		// Terminal.app starts login shells, which skip .bashrc
+		if runtime.GOOS == "darwin" {
+			return filepath.Join(home, ".bash_profile"), nil
+		}
+		return filepath.Join(home, ".bashrc"), nil
+	case Fish:
+		config := os.Getenv("XDG_CONFIG_HOME")
+		if config == "" {
+			config = filepath.Join(home, ".config")
+		}
+		return filepath.Join(config, "fish", "config.fish"), nil
+	case PowerShell:
+		return powerShellProfile(home), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q", shell)
+}
+
+// powerShellProfile asks PowerShell for $PROFILE, since Documents may be
+// redirected (OneDrive), falling back to the Windows PowerShell default.
+func powerShellProfile(home string) string {
+	for _, exe := range []string{"pwsh", "powershell"} {
+		out, err := exec.Command(exe, "-NoProfile", "-NonInteractive", "-Command", "$PROFILE.CurrentUserCurrentHost").Output()
+		if err == nil {
+			if path := strings.TrimSpace(string(out)); path != "" {
+				return path
+			}
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1")
+	}
+	return filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1")
+}
+
+// Apply writes block into path, creating the file if needed. It reports
+// whether the file changed.
+func Apply(path, block string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	updated := Upsert(string(data), block)
+	if updated == string(data) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := writeKeepingMode(path, updated); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// Remove deletes the managed block from path. It reports whether there was
+// one.
+func Remove(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	updated, found := Strip(string(data))
+	if !found {
+		return false, nil
+	}
+	if err := writeKeepingMode(path, updated); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+func writeKeepingMode(path, content string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return os.WriteFile(path, []byte(content), mode)
+}
@@ -0,0 +1,124 @@
+package shellenv
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testEnv() Env {
+	var env Env
+	env.Set("JAVA_HOME", `/sdks/jdk "17"`)
+	env.Set("ANDROID_HOME", "/sdks/$android")
+	env.PrependPath("/sdks/jdk/bin")
+	env.PrependPath("/sdks/platform-tools")
+	env.PrependPath("/sdks/platform-tools")
+	return env
+}
+
+func TestRender(t *testing.T) {
+	tests := map[string][]string{
+		Bash: {
+			`export JAVA_HOME="/sdks/jdk \"17\""`,
+			`export ANDROID_HOME="/sdks/\$android"`,
+			`export PATH="/sdks/jdk/bin:/sdks/platform-tools:$PATH"`,
+		},
+		Fish: {
+			`set -gx JAVA_HOME '/sdks/jdk "17"'`,
+			`fish_add_path -g '/sdks/jdk/bin' '/sdks/platform-tools'`,
+		},
+		PowerShell: {
+			`$env:ANDROID_HOME = '/sdks/$android'`,
+			`$env:PATH = (@('/sdks/jdk/bin', '/sdks/platform-tools') + $env:PATH)`,
+		},
+	}
+	for shell, want := range tests {
+		got := Render(shell, testEnv())
+		for _, line := range want {
+			if !strings.Contains(got, line) {
+				t.Errorf("%s: missing %q in\n%s", shell, line, got)
+			}
+		}
+	}
+}
+
+func TestRenderEvaluatesInSh(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	script := Render(Bash, testEnv()) + `printf '%s|%s|%s' "$JAVA_HOME" "$ANDROID_HOME" "${PATH%%:*}"`
+	out, err := exec.Command(sh, "-c", script).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `/sdks/jdk "17"|/sdks/$android|/sdks/jdk/bin`; string(out) != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	block := Block(Bash, testEnv())
+	rc := "alias ll='ls -l'"
+
+	once := Upsert(rc, block)
+	if !strings.HasPrefix(once, rc+"\n\n"+BeginMarker) {
+		t.Errorf("block not appended after existing content:\n%s", once)
+	}
+	if twice := Upsert(once, block); twice != once {
+		t.Errorf("second apply changed the file:\n%s", twice)
+	}
+
+	// A changed environment replaces the block in place
+	var env Env
+	env.Set("JAVA_HOME", "/new")
+	replaced := Upsert(once+"export EDITOR=vi\n", Block(Bash, env))
+	if strings.Count(replaced, BeginMarker) != 1 || strings.Contains(replaced, "/sdks") {
+		t.Errorf("block not replaced:\n%s", replaced)
+	}
+	if !strings.HasSuffix(replaced, "export EDITOR=vi\n") {
+		t.Errorf("content after the block was lost:\n%s", replaced)
+	}
+
+	stripped, found := Strip(replaced)
+	if !found || strings.Contains(stripped, BeginMarker) {
+		t.Errorf("Strip left the block:\n%s", stripped)
+	}
+}
+
+func TestApplyAndRemove(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), "fish", "config.fish")
+	block := Block(Fish, testEnv())
+
+	changed, err := Apply(rc, block)
+	if err != nil || !changed {
+		t.Fatalf("Apply = %v, %v; want true, nil", changed, err)
+	}
+	if changed, _ := Apply(rc, block); changed {
+		t.Error("second Apply reported a change")
+	}
+
+	removed, err := Remove(rc)
+	if err != nil || !removed {
+		t.Fatalf("Remove = %v, %v; want true, nil", removed, err)
+	}
+	if data, _ := os.ReadFile(rc); len(data) != 0 {
+		t.Errorf("file not empty after Remove: %q", data)
+	}
+	if removed, _ := Remove(rc); removed {
+		t.Error("second Remove reported a block")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{"zsh": Zsh, "pwsh": PowerShell, "sh": Bash, "Fish": Fish} {
+		if got, err := Normalize(in); err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := Normalize("tcsh"); err == nil {
+		t.Error("Normalize(tcsh) should fail")
+	}
+}
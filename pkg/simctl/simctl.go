@@ -177,12 +177,32 @@ func (c *Client) Launch(bundleID string) error {
 	return c.runPassthrough("launch", "booted", bundleID)
 }
 
+// LaunchEnv starts an app like Launch, adding env ("KEY=value") to the
+// app's environment.
+func (c *Client) LaunchEnv(bundleID string, env []string) error {
+	cmd := exec.Command("xcrun", "simctl", "launch", "booted", bundleID)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	for _, kv := range env {
+		// simctl passes SIMCTL_CHILD_* variables on without the prefix.
+		cmd.Env = append(cmd.Env, "SIMCTL_CHILD_"+kv)
+	}
+	return cmd.Run()
+}
+
 // Terminate stops an app by bundle ID.
 func (c *Client) Terminate(bundleID string) error {
 	_, err := c.run("terminate", "booted", bundleID)
 	return err
 }
 
+// OpenURL opens url on the booted simulator, as tapping a deep link would.
+func (c *Client) OpenURL(url string) error {
+	_, err := c.run("openurl", "booted", url)
+	return err
+}
+
 // Screenshot captures the booted simulator screen to a local file.
 func (c *Client) Screenshot(outputPath string) error {
 	return c.runPassthrough("io", "booted", "screenshot", outputPath)
@@ -0,0 +1,162 @@
+// Package sysdeps finds the Linux distribution's package manager and
+// checks and installs the system packages Gio needs to build: a C
+// compiler, pkg-config and the Wayland, X11, EGL/GLES and Vulkan headers.
+package sysdeps
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Manager is a Linux package manager.
+type Manager string
+
+// Supported package managers.
+const (
+	Apt    Manager = "apt"
+	DNF    Manager = "dnf"
+	Pacman Manager = "pacman"
+	APK    Manager = "apk"
+)
+
+// packages are Gio's build dependencies for each package manager, from
+// https://gioui.org/doc/install/linux.
+var packages = map[Manager][]string{
+	Apt: {"gcc", "pkg-config", "libwayland-dev", "libx11-dev", "libx11-xcb-dev", "libxkbcommon-x11-dev",
+		"libgles2-mesa-dev", "libegl1-mesa-dev", "libffi-dev", "libxcursor-dev", "libvulkan-dev"},
+	DNF: {"gcc", "pkgconf-pkg-config", "wayland-devel", "libX11-devel", "libxkbcommon-x11-devel",
+		"mesa-libGLES-devel", "mesa-libEGL-devel", "libXcursor-devel", "vulkan-headers"},
+	Pacman: {"gcc", "pkgconf", "wayland", "libx11", "libxkbcommon-x11", "mesa", "libxcursor", "vulkan-headers"},
+	APK:    {"gcc", "musl-dev", "pkgconf", "wayland-dev", "libx11-dev", "libxkbcommon-dev", "mesa-dev", "libxcursor-dev", "vulkan-headers"},
+}
+
+// distros maps os-release IDs to their package manager.
+var distros = map[string]Manager{
+	"debian": Apt, "ubuntu": Apt, "linuxmint": Apt, "pop": Apt, "raspbian": Apt,
+	"fedora": DNF, "rhel": DNF, "centos": DNF, "rocky": DNF, "almalinux": DNF,
+	"arch": Pacman, "manjaro": Pacman, "endeavouros": Pacman,
+	"alpine": APK,
+}
+
+// Tests replace these.
+var (
+	osRelease = "/etc/os-release"
+	lookPath  = exec.LookPath
+	output    = func(name string, args ...string) (string, error) {
+		out, err := exec.Command(name, args...).Output()
+		return string(out), err
+	}
+	geteuid = os.Geteuid
+)
+
+// Detect returns the package manager of the running distribution, from
+// the ID and ID_LIKE fields of /etc/os-release, or else the first package
+// manager found in PATH.
+func Detect() (Manager, error) {
+	if f, err := os.Open(osRelease); err == nil {
+		fields := map[string]string{}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if k, v, ok := strings.Cut(scanner.Text(), "="); ok {
+				fields[k] = strings.Trim(v, `"'`)
+			}
+		}
+		f.Close()
+		ids := append([]string{fields["ID"]}, strings.Fields(fields["ID_LIKE"])...)
+		for _, id := range ids {
+			if m, ok := distros[id]; ok {
+				return m, nil
+			}
+		}
+	}
+	for _, m := range []Manager{Apt, DNF, Pacman, APK} {
+		if _, err := lookPath(m.binary()); err == nil {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported Linux distribution: no apt, dnf, pacman or apk found; install Gio's dependencies by hand (https://gioui.org/doc/install/linux)")
+}
+
+func (m Manager) binary() string {
+	if m == Apt {
+		return "apt-get"
+	}
+	return string(m)
+}
+
+// Packages returns the packages Gio needs with this package manager.
+func (m Manager) Packages() []string {
+	return slices.Clone(packages[m])
+}
+
+// installed reports whether a package is installed.
+func (m Manager) installed(pkg string) bool {
+	switch m {
+	case Apt:
+		// Removed packages stay listed as "deinstall ok config-files"
+		out, err := output("dpkg-query", "-W", "-f=${Status}", pkg)
+		return err == nil && strings.HasSuffix(strings.TrimSpace(out), " installed")
+	case DNF:
+		_, err := output("rpm", "-q", pkg)
+		return err == nil
+	case Pacman:
+		_, err := output("pacman", "-Q", pkg)
+		return err == nil
+	case APK:
+		_, err := output("apk", "info", "-e", pkg)
+		return err == nil
+	}
+	return false
+}
+
+// Missing returns the required packages that are not installed.
+func (m Manager) Missing() []string {
+	var missing []string
+	for _, pkg := range packages[m] {
+		if !m.installed(pkg) {
+			missing = append(missing, pkg)
+		}
+	}
+	return missing
+}
+
+// InstallCommand returns the command that installs pkgs, through sudo
+// unless running as root.
+func (m Manager) InstallCommand(pkgs []string) []string {
+	var args []string
+	switch m {
+	case Apt:
+		args = []string{"apt-get", "install", "-y"}
+	case DNF:
+		args = []string{"dnf", "install", "-y"}
+	case Pacman:
+		args = []string{"pacman", "-S", "--needed", "--noconfirm"}
+	case APK:
+		args = []string{"apk", "add"}
+	}
+	args = append(args, pkgs...)
+	if geteuid() != 0 {
+		args = append([]string{"sudo"}, args...)
+	}
+	return args
+}
+
+// Install installs pkgs, showing the package manager's output.
+func (m Manager) Install(pkgs []string) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+	args := m.InstallCommand(pkgs)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install %s: %w", strings.Join(pkgs, " "), err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package sysdeps
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	oldRelease, oldLook := osRelease, lookPath
+	t.Cleanup(func() { osRelease, lookPath = oldRelease, oldLook })
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+
+	dir := t.TempDir()
+	osRelease = filepath.Join(dir, "os-release")
+	for release, want := range map[string]Manager{
+		"ID=fedora\nVERSION_ID=40\n":                 DNF,
+		"ID=neon\nID_LIKE=\"ubuntu debian\"\n":       Apt,
+		"NAME=\"Alpine Linux\"\nID=alpine\n":         APK,
+		"ID='endeavouros'\nID_LIKE=arch\n":           Pacman,
+		"ID=opensuse-tumbleweed\nID_LIKE=\"suse\"\n": "",
+	} {
+		os.WriteFile(osRelease, []byte(release), 0644)
+		got, err := Detect()
+		if got != want || (want == "") != (err != nil) {
+			t.Errorf("Detect(%q) = %q, %v; want %q", release, got, err, want)
+		}
+	}
+
+	// Without os-release, fall back to the package manager in PATH
+	osRelease = filepath.Join(dir, "missing")
+	lookPath = func(name string) (string, error) {
+		if name == "pacman" {
+			return "/usr/bin/pacman", nil
+		}
+		return "", exec.ErrNotFound
+	}
+	if got, _ := Detect(); got != Pacman {
+		t.Errorf("Detect from PATH = %q", got)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	oldOutput := output
+	t.Cleanup(func() { output = oldOutput })
+	output = func(name string, args ...string) (string, error) {
+		switch args[len(args)-1] {
+		case "gcc", "pkg-config":
+			return "install ok installed", nil
+		case "libx11-dev":
+			return "deinstall ok config-files", nil
+		}
+		return "", errors.New("exit status 1")
+	}
+	missing := Apt.Missing()
+	if slices.Contains(missing, "gcc") || slices.Contains(missing, "pkg-config") {
+		t.Errorf("installed packages reported missing: %v", missing)
+	}
+	if !slices.Contains(missing, "libx11-dev") || !slices.Contains(missing, "libvulkan-dev") {
+		t.Errorf("Missing = %v", missing)
+	}
+}
+
+func TestInstallCommand(t *testing.T) {
+	oldEuid := geteuid
+	t.Cleanup(func() { geteuid = oldEuid })
+
+	geteuid = func() int { return 1000 }
+	got := Pacman.InstallCommand([]string{"wayland", "mesa"})
+	want := []string{"sudo", "pacman", "-S", "--needed", "--noconfirm", "wayland", "mesa"}
+	if !slices.Equal(got, want) {
+		t.Errorf("InstallCommand = %v, want %v", got, want)
+	}
+
+	geteuid = func() int { return 0 }
+	got = Apt.InstallCommand([]string{"gcc"})
+	want = []string{"apt-get", "install", "-y", "gcc"}
+	if !slices.Equal(got, want) {
+		t.Errorf("InstallCommand as root = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package updater
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+	"github.com/joeblew999/goup-util/pkg/self/source"
+)
+
+// PartialSuffix marks a download that was interrupted. It is kept in
+// DownloadDir and resumed by the next Update.
+const PartialSuffix = ".partial"
+
+// Policy decides when and how fast updates download, for apps on metered
+// or weak connections such as kiosks on a cellular router. The zero Policy
+// downloads right away at full speed.
+type Policy struct {
+	// Window holds back large downloads to local off-hours, such as
+	// "01:00-05:00"; it may span midnight. "" allows any time.
+	Window string
+	// Unmetered holds back large downloads while the connection is
+	// metered (see Metered).
+	Unmetered bool
+	// LargeSize is the size in bytes above which Window and Unmetered
+	// apply; 0 applies them to every download.
+	LargeSize int64
+	// RateLimit caps the download in bytes per second; 0 is unlimited.
+	RateLimit int64
+}
+
+// Defer returns why a download of size bytes has to wait, or "", and when
+// to try again.
+func (p Policy) Defer(size int64, now time.Time) (string, time.Time, error) {
+	if size <= p.LargeSize && p.LargeSize > 0 {
+		return "", time.Time{}, nil
+	}
+	if p.Window != "" {
+		start, end, err := parseWindow(p.Window)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if next, ok := windowOpens(start, end, now); !ok {
+			return fmt.Sprintf("outside the download window %s", p.Window), next, nil
+		}
+	}
+	if p.Unmetered {
+		if metered, _ := Metered(); metered {
+			return "the connection is metered", now.Add(15 * time.Minute), nil
+		}
+	}
+	return "", time.Time{}, nil
+}
+
+// parseWindow parses "HH:MM-HH:MM" into minutes after midnight.
+func parseWindow(window string) (int, int, error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("download window %q is not HH:MM-HH:MM", window)
+	}
+	var minutes [2]int
+	for i, s := range []string{from, to} {
+		t, err := time.Parse("15:04", strings.TrimSpace(s))
+		if err != nil {
+			return 0, 0, fmt.Errorf("download window %q is not HH:MM-HH:MM", window)
+		}
+		minutes[i] = t.Hour()*60 + t.Minute()
+	}
+	return minutes[0], minutes[1], nil
+}
+
+// windowOpens reports whether now is in the window, and if not, when it
+// next opens.
+func windowOpens(start, end int, now time.Time) (time.Time, bool) {
+	m := now.Hour()*60 + now.Minute()
+	in := m >= start && m < end
+	if start > end { // Spans midnight
+		in = m >= start || m < end
+	}
+	if in || start == end {
+		return now, true
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(time.Duration(start) * time.Minute)
+	if !next.After(now) {
+		next = midnight.AddDate(0, 0, 1).Add(time.Duration(start) * time.Minute)
+	}
+	return next, false
+}
+
+// Metered reports whether the network connection is metered: a cellular
+// or tethered connection, or one the user marked as metered. It uses
+// NetworkManager on Linux and the connection cost on Windows, and reports
+// false where it cannot tell (macOS).
+func Metered() (bool, error) {
+	switch runtime.GOOS {
+	case "linux":
+		out, err := exec.Command("nmcli", "-t", "-f", "GENERAL.METERED", "device", "show").Output()
+		if err != nil {
+			return false, err
+		}
+		// One line per device: "GENERAL.METERED:yes (guessed)"
+		for _, line := range strings.Split(string(out), "\n") {
+			if _, v, _ := strings.Cut(line, ":"); strings.HasPrefix(v, "yes") {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "windows":
+		out, err := exec.Command("powershell", "-NoProfile", "-Command",
+			"[void][Windows.Networking.Connectivity.NetworkInformation,Windows.Networking.Connectivity,ContentType=WindowsRuntime];"+
+				"$p = [Windows.Networking.Connectivity.NetworkInformation]::GetInternetConnectionProfile();"+
+				"if ($p) { $p.GetConnectionCost().NetworkCostType }").Output()
+		if err != nil {
+			return false, err
+		}
+		cost := strings.TrimSpace(string(out))
+		return cost == "Fixed" || cost == "Variable", nil
+	default:
+		return false, nil
+	}
+}
+
+// DownloadDir keeps partial downloads between runs.
+func DownloadDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "goup-updates")
+}
+
+// download fetches the asset of release tag into dir, resuming a partial
+// download of it and removing those of other releases. The caller removes
+// the returned file.
+func download(src source.Source, a ghapi.Asset, tag, dir string, p Policy) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	suffix := "-" + a.Name + PartialSuffix
+	path := filepath.Join(dir, tag+suffix)
+	if old, _ := filepath.Glob(filepath.Join(dir, "*"+suffix)); len(old) > 0 {
+		for _, f := range old {
+			if f != path {
+				os.Remove(f)
+			}
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", err
+	}
+	r, ok := src.(source.Resumer)
+	if !ok || a.Size == 0 || offset >= a.Size {
+		offset = 0
+	}
+	if err := f.Truncate(offset); err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	var w io.Writer = f
+	if p.RateLimit > 0 {
+		w = &throttled{w: f, rate: p.RateLimit, start: time.Now()}
+	}
+	if offset > 0 {
+		fmt.Printf("Resuming at %d of %d bytes\n", offset, a.Size)
+		err = r.DownloadFrom(a, offset, w)
+	} else {
+		err = src.Download(a, w)
+	}
+	if err != nil {
+		// Keep what arrived for the next attempt
+		return "", err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	if err := source.Verify(a, f); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// throttled limits writes to rate bytes per second on average.
+type throttled struct {
+	w     io.Writer
+	rate  int64
+	start time.Time
+	n     int64
+}
+
+func (t *throttled) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// Small chunks keep the rate even at low limits
+		chunk := p[:min(int64(len(p)), max(t.rate/10, 1))]
+		n, err := t.w.Write(chunk)
+		written += n
+		t.n += int64(n)
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+		due := t.start.Add(time.Duration(float64(t.n) / float64(t.rate) * float64(time.Second)))
+		if wait := time.Until(due); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,98 @@
+package updater
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+	"github.com/joeblew999/goup-util/pkg/self/source"
+)
+
+func TestPolicyDefer(t *testing.T) {
+	at := func(clock string) time.Time {
+		tm, _ := time.ParseInLocation("2006-01-02 15:04", "2026-03-10 "+clock, time.Local)
+		return tm
+	}
+	p := Policy{Window: "22:00-05:00", LargeSize: 10 << 20}
+
+	if reason, _, _ := p.Defer(1<<20, at("12:00")); reason != "" {
+		t.Errorf("small download deferred: %s", reason)
+	}
+	for _, clock := range []string{"23:30", "02:00"} {
+		if reason, _, _ := p.Defer(50<<20, at(clock)); reason != "" {
+			t.Errorf("deferred at %s, inside the window: %s", clock, reason)
+		}
+	}
+	reason, retry, err := p.Defer(50<<20, at("12:00"))
+	if err != nil || reason == "" || !retry.Equal(at("22:00")) {
+		t.Errorf("at 12:00: %q, retry %v, %v", reason, retry, err)
+	}
+	_, retry, _ = Policy{Window: "01:00-05:00"}.Defer(1, at("06:00"))
+	if !retry.Equal(at("01:00").AddDate(0, 0, 1)) {
+		t.Errorf("retry = %v, want 01:00 tomorrow", retry)
+	}
+	if _, _, err := (Policy{Window: "night"}).Defer(1, at("12:00")); err == nil {
+		t.Error("invalid window accepted")
+	}
+}
+
+func TestDownloadResumes(t *testing.T) {
+	content := bytes.Repeat([]byte("goup-util update "), 1000)
+	sum := sha256.Sum256(content)
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "app.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	src := source.NewUpdateServer(srv.URL)
+	a := ghapi.Asset{Name: "app-linux.zip", Size: int64(len(content)), BrowserDownloadURL: srv.URL + "/v1.1.0/app-linux.zip", Digest: "sha256:" + hex.EncodeToString(sum[:])}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "v1.0.0-app-linux.zip"+PartialSuffix), []byte("stale"), 0644)
+	os.WriteFile(filepath.Join(dir, "v1.1.0-app-linux.zip"+PartialSuffix), content[:5000], 0644)
+
+	path, err := download(src, a, "v1.1.0", dir, Policy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+		t.Error("resumed download differs")
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=5000-" {
+		t.Errorf("Range = %q", ranges)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "v1.0.0-app-linux.zip"+PartialSuffix)); !os.IsNotExist(err) {
+		t.Error("partial download of an older release was kept")
+	}
+
+	// A partial file that does not match is downloaded again.
+	os.WriteFile(path, append([]byte("corrupt"), content[7:9000]...), 0644)
+	if _, err := download(src, a, "v1.1.0", dir, Policy{}); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("corrupt resume: %v", err)
+	}
+	if path, err = download(src, a, "v1.1.0", dir, Policy{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+		t.Error("download after a corrupt resume differs")
+	}
+}
+
+func TestThrottled(t *testing.T) {
+	var buf bytes.Buffer
+	w := &throttled{w: &buf, rate: 4000, start: time.Now()}
+	start := time.Now()
+	w.Write(make([]byte, 1000))
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || buf.Len() != 1000 {
+		t.Errorf("wrote %d bytes in %v at 4000 B/s", buf.Len(), elapsed)
+	}
+}
@@ -0,0 +1,72 @@
+package updater
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCrashGuardRollsBack(t *testing.T) {
+	var reported Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/events" {
+			json.NewDecoder(r.Body).Decode(&reported)
+		}
+	}))
+	defer srv.Close()
+
+	exe := filepath.Join(t.TempDir(), "app")
+	os.WriteFile(exe, []byte("old"), 0755)
+	if err := Backup(exe, "v2.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(exe, []byte("new"), 0755)
+	opts := GuardOptions{MaxCrashes: 2, ReportURL: srv.URL}
+
+	// A clean run does not count.
+	g, err := StartCrashGuard(exe, opts)
+	if err != nil || g == nil {
+		t.Fatalf("StartCrashGuard = %v, %v", g, err)
+	}
+	g.Stop()
+
+	// Two runs that never stop are crashes; the next start rolls back.
+	for i := range 3 {
+		g, err := StartCrashGuard(exe, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.RolledBack() != (i == 2) {
+			t.Fatalf("start %d: RolledBack = %v", i, g.RolledBack())
+		}
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Errorf("executable = %q after rollback, want the backup", data)
+	}
+	if data, _ := os.ReadFile(exe + FailedSuffix); string(data) != "new" {
+		t.Errorf("failed update = %q", data)
+	}
+	if reported.Type != EventRollback || reported.Version != "v2.0.0" || reported.Crashes != 2 {
+		t.Errorf("reported %+v", reported)
+	}
+	if g, _ := StartCrashGuard(exe, opts); g != nil {
+		t.Error("still watching after the rollback")
+	}
+}
+
+func TestCrashGuardProbationEnds(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "app")
+	os.WriteFile(exe, []byte("new"), 0755)
+	saveState(exe, &guardState{Version: "v2.0.0", Running: true})
+
+	g, err := StartCrashGuard(exe, GuardOptions{MaxCrashes: 1})
+	if err != nil || g != nil {
+		t.Fatalf("StartCrashGuard = %v, %v; want nothing to watch", g, err)
+	}
+	if _, err := os.Stat(exe + StateSuffix); !os.IsNotExist(err) {
+		t.Error("state kept after the probation window")
+	}
+}
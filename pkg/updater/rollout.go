@@ -0,0 +1,79 @@
+package updater
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// InRollout reports whether a machine is among the percentage of the fleet
+// offered a release. The machine ID is hashed together with the version,
+// so each release reaches a different slice of the fleet, while a given
+// machine stays in or out as the percentage only grows.
+func InRollout(machineID, version string, percentage int) bool {
+	if percentage >= 100 {
+		return true
+	}
+	if percentage <= 0 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(machineID + "/" + version))
+	return binary.BigEndian.Uint64(sum[:8])%100 < uint64(percentage)
+}
+
+var ioregUUID = regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`)
+
+// MachineID returns a stable identifier for this machine: the OS machine
+// ID where there is one, otherwise a random ID saved in the user config
+// directory on first use.
+func MachineID() string {
+	switch runtime.GOOS {
+	case "linux":
+		for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+			if data, err := os.ReadFile(path); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+				return strings.TrimSpace(string(data))
+			}
+		}
+	case "darwin":
+		if out, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output(); err == nil {
+			if m := ioregUUID.FindSubmatch(out); m != nil {
+				return string(m[1])
+			}
+		}
+	case "windows":
+		out, err := exec.Command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid").Output()
+		if err == nil {
+			if fields := strings.Fields(string(out)); len(fields) > 0 && strings.Contains(string(out), "MachineGuid") {
+				return fields[len(fields)-1]
+			}
+		}
+	}
+	return savedMachineID()
+}
+
+// savedMachineID returns the ID kept in the user config directory,
+// creating it if needed. If it cannot be saved the ID changes every run,
+// which at worst moves the machine between rollout buckets.
+func savedMachineID() string {
+	dir, err := os.UserConfigDir()
+	if err == nil {
+		dir = filepath.Join(dir, "goup-util")
+		if data, err := os.ReadFile(filepath.Join(dir, "machine-id")); err == nil && len(data) > 0 {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+	if dir != "" && os.MkdirAll(dir, 0755) == nil {
+		os.WriteFile(filepath.Join(dir, "machine-id"), []byte(id+"\n"), 0644)
+	}
+	return id
+}
@@ -0,0 +1,29 @@
+package updater
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestInRollout(t *testing.T) {
+	for i := range 100 {
+		id := fmt.Sprint("machine-", i)
+		if !InRollout(id, "v1.0.0", 100) || InRollout(id, "v1.0.0", 0) {
+			t.Fatalf("%s: 0%% and 100%% must be exact", id)
+		}
+	}
+
+	in := 0
+	for i := range 10000 {
+		id := fmt.Sprint("machine-", i)
+		if InRollout(id, "v1.2.0", 25) {
+			in++
+			if !InRollout(id, "v1.2.0", 50) {
+				t.Fatalf("%s left the rollout when it grew", id)
+			}
+		}
+	}
+	if in < 2300 || in > 2700 {
+		t.Errorf("25%% rollout reached %d of 10000 machines", in)
+	}
+}
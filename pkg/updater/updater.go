@@ -1,4 +1,5 @@
-// Package updater provides self-update from GitHub releases.
+// Package updater provides self-update from GitHub releases, an update
+// server, or a static index in a bucket (see pkg/self/source).
 // This is the reusable core extracted from pkg/self/install.go.
 // Any binary (goup-util, webviewer shell, hybrid-dashboard) can use this
 // to update itself from GitHub release assets.
@@ -8,20 +9,27 @@
 package updater
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/ghapi"
+	"github.com/joeblew999/goup-util/pkg/self/source"
 )
 
 // Config tells the updater where to find releases.
 type Config struct {
-	Repo  string // GitHub owner/repo (e.g. "joeblew999/goup-util")
+	Repo  string // GitHub owner/repo (e.g. "joeblew999/goup-util"), or a source URL such as r2://bucket/app
 	Asset string // Asset name prefix (e.g. "webviewer-shell")
+	URL   string // Self-hosted update server (goup-util update-server); replaces Repo
+	// Token reads releases of a private Repo; "" uses ghapi.Token. Apps
+	// with their own login pass ghapi.StoredToken(<app>).
+	Token string
+	// Policy holds back large downloads and limits their bandwidth.
+	Policy Policy
 }
 
 // Result holds the outcome of an update check or update.
@@ -32,21 +40,32 @@ type Result struct {
 	Downloaded     bool
 	Installed      bool
 	AssetName      string
+	Rollout        int  // Percentage of machines the release is offered to
+	HeldBack       bool // Staged release that does not include this machine yet
+	Deferred       string    // Why Policy holds back the download, "" when it does not
+	RetryAt        time.Time // When a deferred download may go ahead
 }
 
 // Check queries GitHub for the latest release and returns whether an update is available.
 func Check(cfg Config) (*Result, error) {
-	release, err := fetchLatestRelease(cfg.Repo)
+	src, err := openSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	release, err := fetchLatestRelease(src)
 	if err != nil {
 		return nil, err
 	}
 
 	assetName := findAsset(release, cfg.Asset)
-	return &Result{
-		LatestVersion:   release.TagName,
-		UpdateAvailable: assetName != "",
-		AssetName:       assetName,
-	}, nil
+	result := &Result{
+		LatestVersion: release.TagName,
+		AssetName:     assetName,
+		Rollout:       rollout(release),
+	}
+	result.HeldBack = !InRollout(MachineID(), release.TagName, result.Rollout)
+	result.UpdateAvailable = assetName != "" && !result.HeldBack
+	return result, nil
 }
 
 // CanSelfUpdate returns true if the current platform supports self-update.
@@ -66,17 +85,27 @@ func Update(cfg Config) (*Result, error) {
 	if !CanSelfUpdate() {
 		return nil, fmt.Errorf("self-update not supported on %s (use app store)", runtime.GOOS)
 	}
-	if cfg.Repo == "" || cfg.Asset == "" {
-		return nil, fmt.Errorf("update not configured (need repo and asset)")
+	if (cfg.Repo == "" && cfg.URL == "") || cfg.Asset == "" {
+		return nil, fmt.Errorf("update not configured (need repo or url, and asset)")
 	}
 
-	release, err := fetchLatestRelease(cfg.Repo)
+	src, err := openSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	release, err := fetchLatestRelease(src)
 	if err != nil {
 		return nil, err
 	}
 
 	result := &Result{
 		LatestVersion: release.TagName,
+		Rollout:       rollout(release),
+	}
+	if !InRollout(MachineID(), release.TagName, result.Rollout) {
+		result.HeldBack = true
+		fmt.Printf("%s is rolling out to %d%% of installs and does not include this machine yet\n", release.TagName, result.Rollout)
+		return result, nil
 	}
 
 	// Find matching asset for current platform
@@ -86,39 +115,32 @@ func Update(cfg Config) (*Result, error) {
 			cfg.Asset, platformName(), release.TagName)
 	}
 
-	var downloadURL string
+	var asset ghapi.Asset
 	for _, a := range release.Assets {
 		if a.Name == assetName {
-			downloadURL = a.BrowserDownloadURL
+			asset = a
 			break
 		}
 	}
 
 	result.AssetName = assetName
-	fmt.Printf("Downloading %s (%s)...\n", assetName, release.TagName)
-
-	// Download to temp file
-	tmpFile, err := os.CreateTemp("", "app-update-*")
+	reason, retry, err := cfg.Policy.Defer(asset.Size, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return nil, err
+	}
+	if reason != "" {
+		result.Deferred, result.RetryAt = reason, retry
+		fmt.Printf("Download of %s deferred: %s; retry at %s\n", release.TagName, reason, retry.Format("15:04"))
+		return result, nil
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
+	fmt.Printf("Downloading %s (%s)...\n", assetName, release.TagName)
 
-	dlResp, err := http.Get(downloadURL)
+	// Download next to earlier partial downloads, so an interrupted one resumes
+	archive, err := download(src, asset, release.TagName, DownloadDir(), cfg.Policy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download: %w", err)
 	}
-	defer dlResp.Body.Close()
-
-	if dlResp.StatusCode != 200 {
-		return nil, fmt.Errorf("download failed: %s", dlResp.Status)
-	}
-
-	if _, err := io.Copy(tmpFile, dlResp.Body); err != nil {
-		return nil, fmt.Errorf("failed to save download: %w", err)
-	}
-	tmpFile.Close()
+	defer os.Remove(archive)
 	result.Downloaded = true
 
 	// Extract to executable's directory
@@ -129,7 +151,11 @@ func Update(cfg Config) (*Result, error) {
 	exePath, _ = filepath.EvalSymlinks(exePath)
 	exeDir := filepath.Dir(exePath)
 
-	if err := extractArchive(tmpFile.Name(), exeDir); err != nil {
+	// Keep the current binary so StartCrashGuard can roll back a bad update
+	if err := Backup(exePath, release.TagName); err != nil {
+		return nil, err
+	}
+	if err := extractArchive(archive, exeDir); err != nil {
 		return nil, fmt.Errorf("failed to extract update: %w", err)
 	}
 
@@ -138,35 +164,42 @@ func Update(cfg Config) (*Result, error) {
 	return result, nil
 }
 
-type githubRelease struct {
-	TagName string `json:"tag_name"`
-	Assets  []struct {
-		Name               string `json:"name"`
-		BrowserDownloadURL string `json:"browser_download_url"`
-	} `json:"assets"`
-}
-
-func fetchLatestRelease(repo string) (*githubRelease, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check for updates: %w", err)
+// openSource is the update server at cfg.URL, or the source cfg.Repo
+// names, read with cfg.Token when set.
+func openSource(cfg Config) (source.Source, error) {
+	var src source.Source
+	if cfg.URL != "" {
+		src = source.NewUpdateServer(cfg.URL)
+	} else {
+		var err error
+		if src, err = source.Open(cfg.Repo); err != nil {
+			return nil, err
+		}
+	}
+	if gh, ok := src.(*source.GitHub); ok && cfg.Token != "" {
+		gh.Client.Token = cfg.Token
 	}
-	defer resp.Body.Close()
+	return src, nil
+}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to fetch release info: %s", resp.Status)
+// rollout is the staged rollout percentage; GitHub releases go to everyone.
+func rollout(r *ghapi.Release) int {
+	if r.RolloutPercentage == nil {
+		return 100
 	}
+	return *r.RolloutPercentage
+}
 
-	var release githubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to parse release info: %w", err)
+func fetchLatestRelease(src source.Source) (*ghapi.Release, error) {
+	release, err := src.Latest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates from %s: %w", src, err)
 	}
-	return &release, nil
+	return release, nil
 }
 
 // findAsset looks for an asset matching the prefix + current platform name.
-func findAsset(release *githubRelease, assetPrefix string) string {
+func findAsset(release *ghapi.Release, assetPrefix string) string {
 	wantPrefix := fmt.Sprintf("%s-%s", assetPrefix, platformName())
 	for _, a := range release.Assets {
 		if len(a.Name) >= len(wantPrefix) && a.Name[:len(wantPrefix)] == wantPrefix {
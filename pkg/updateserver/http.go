@@ -0,0 +1,333 @@
+package updateserver
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Handler serves the update API:
+//
+//	GET /healthz
+//	GET /manifest.json                 latest release with absolute URLs
+//	GET /releases                      every release, newest first
+//	GET /releases/latest               latest release in GitHub's API format
+//	GET /appcast.xml                   Sparkle / WinSparkle appcast
+//	GET /download/{version}/{file}     release file, with range support
+//	POST /events                       app reports, e.g. {"type": "rollback", "version": "v1.3.0"}
+//	GET /metrics                       download and event counts (Prometheus text)
+//	/fleet/...                         device registry, with Options.Fleet (see pkg/fleet)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /manifest.json", s.manifest)
+	mux.HandleFunc("GET /releases", s.releases)
+	mux.HandleFunc("GET /releases/latest", s.githubLatest)
+	mux.HandleFunc("GET /appcast.xml", s.appcast)
+	mux.HandleFunc("GET /download/{version}/{file}", s.download)
+	mux.HandleFunc("POST /events", s.event)
+	mux.HandleFunc("GET /metrics", s.metrics)
+	if s.fleet != nil {
+		s.fleet.Register(mux, s.opts.FleetTokens)
+	}
+	return mux
+}
+
+// baseURL is the configured public URL, or the one the client used.
+func (s *Server) baseURL(r *http.Request) string {
+	if s.opts.BaseURL != "" {
+		return s.opts.BaseURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if p := r.Header.Get("X-Forwarded-Proto"); p != "" {
+		scheme = p
+	}
+	return scheme + "://" + r.Host
+}
+
+// absolute rewrites a release's asset URLs for the client.
+func (s *Server) absolute(r *http.Request, rel Release) Release {
+	base := s.baseURL(r)
+	assets := make([]Asset, len(rel.Assets))
+	for i, a := range rel.Assets {
+		a.URL = base + a.URL
+		assets[i] = a
+	}
+	rel.Assets = assets
+	return rel
+}
+
+func (s *Server) manifest(w http.ResponseWriter, r *http.Request) {
+	latest, err := s.Latest()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if latest == nil {
+		http.Error(w, "no releases", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.absolute(r, *latest))
+}
+
+func (s *Server) releases(w http.ResponseWriter, r *http.Request) {
+	releases, err := s.Releases()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for i := range releases {
+		releases[i] = s.absolute(r, releases[i])
+	}
+	writeJSON(w, http.StatusOK, releases)
+}
+
+// githubRelease mirrors the fields of GitHub's release API that updaters
+// read.
+type githubRelease struct {
+	TagName     string        `json:"tag_name"`
+	Name        string        `json:"name"`
+	Body        string        `json:"body"`
+	PublishedAt string        `json:"published_at"`
+	Assets      []githubAsset `json:"assets"`
+
+	// RolloutPercentage is not in GitHub's API; clients treat a missing
+	// value as 100.
+	RolloutPercentage int `json:"rollout_percentage"`
+}
+
+type githubAsset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+func (s *Server) githubLatest(w http.ResponseWriter, r *http.Request) {
+	latest, err := s.Latest()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if latest == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	rel := s.absolute(r, *latest)
+	out := githubRelease{
+		TagName:     rel.Version,
+		Name:        rel.Version,
+		Body:        rel.Notes,
+		PublishedAt: rel.Published.Format("2006-01-02T15:04:05Z"),
+		Assets:      []githubAsset{},
+
+		RolloutPercentage: rel.Rollout,
+	}
+	for _, a := range rel.Assets {
+		out.Assets = append(out.Assets, githubAsset{Name: a.Name, Size: a.Size, BrowserDownloadURL: a.URL})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+type rss struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Sparkle string   `xml:"xmlns:sparkle,attr"`
+	Channel channel  `xml:"channel"`
+}
+
+type channel struct {
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+	Items []item `xml:"item"`
+}
+
+type item struct {
+	Title       string    `xml:"title"`
+	PubDate     string    `xml:"pubDate"`
+	Version     string    `xml:"sparkle:version"`
+	ShortVer    string    `xml:"sparkle:shortVersionString"`
+	Description cdata     `xml:"description"`
+	Enclosure   enclosure `xml:"enclosure"`
+}
+
+type cdata struct {
+	Text string `xml:",cdata"`
+}
+
+type enclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+	OS     string `xml:"sparkle:os,attr"`
+}
+
+// appcast lists the macOS and Windows files of every release, one item
+// each, as Sparkle and WinSparkle expect.
+func (s *Server) appcast(w http.ResponseWriter, r *http.Request) {
+	releases, err := s.Releases()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	feed := rss{
+		Version: "2.0",
+		Sparkle: "http://www.andymatuschak.org/xml-namespaces/sparkle",
+		Channel: channel{Title: "Updates", Link: s.baseURL(r) + "/appcast.xml"},
+	}
+	for _, rel := range releases {
+		rel = s.absolute(r, rel)
+		version := strings.TrimPrefix(rel.Version, "v")
+		for _, a := range rel.Assets {
+			platform := assetOS(a.Name)
+			if platform == "" {
+				continue
+			}
+			feed.Channel.Items = append(feed.Channel.Items, item{
+				Title:       "Version " + version,
+				PubDate:     rel.Published.Format(http.TimeFormat),
+				Version:     version,
+				ShortVer:    version,
+				Description: cdata{rel.Notes},
+				Enclosure:   enclosure{URL: a.URL, Length: a.Size, Type: "application/octet-stream", OS: platform},
+			})
+		}
+	}
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.Encode(feed)
+}
+
+// assetOS is the Sparkle OS of a file named like app-macos.zip.
+func assetOS(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "macos"), strings.Contains(name, "darwin"):
+		return "macos"
+	case strings.Contains(name, "windows"):
+		return "windows"
+	}
+	return ""
+}
+
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	version, file := r.PathValue("version"), r.PathValue("file")
+	if canonical(version) == "" || strings.HasPrefix(file, ".") || strings.ContainsAny(file, `/\`) {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(filepath.Join(s.opts.Dir, version, file))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	// Resumed downloads ask for a later range; only count the first request.
+	if rng := r.Header.Get("Range"); rng == "" || strings.HasPrefix(rng, "bytes=0-") {
+		s.countDownload(version, file)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file))
+	http.ServeContent(w, r, file, info.ModTime(), f)
+}
+
+// eventTypes are the events apps report (see updater.EventRollback).
+// /events is unauthenticated, so anything else is refused rather than
+// becoming a new metric label.
+var eventTypes = []string{"rollback"}
+
+func (s *Server) event(w http.ResponseWriter, r *http.Request) {
+	var e struct {
+		Type    string `json:"type"`
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&e); err != nil {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(eventTypes, e.Type) {
+		http.Error(w, "unknown event type", http.StatusBadRequest)
+		return
+	}
+	version, err := s.releaseVersion(e.Version)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if version == "" {
+		http.Error(w, "unknown version", http.StatusBadRequest)
+		return
+	}
+	s.countEvent(e.Type, version)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// releaseVersion returns the name of the release matching an app's
+// version ("1.3.0" or "v1.3.0"), or "" if the server has no such release.
+func (s *Server) releaseVersion(version string) (string, error) {
+	want := canonical(version)
+	if want == "" {
+		return "", nil
+	}
+	releases, err := s.Releases()
+	if err != nil {
+		return "", err
+	}
+	for _, r := range releases {
+		if canonical(r.Version) == want {
+			return r.Version, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *Server) metrics(w http.ResponseWriter, r *http.Request) {
+	counts := s.Downloads()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP goup_update_downloads_total Update downloads by version and file.")
+	fmt.Fprintln(w, "# TYPE goup_update_downloads_total counter")
+	for _, v := range slices.Sorted(maps.Keys(counts)) {
+		for _, f := range slices.Sorted(maps.Keys(counts[v])) {
+			fmt.Fprintf(w, "goup_update_downloads_total{version=%q,file=%q} %d\n", v, f, counts[v][f])
+		}
+	}
+	events := s.Events()
+	fmt.Fprintln(w, "# HELP goup_update_events_total Events reported by apps (e.g. rollback) by version.")
+	fmt.Fprintln(w, "# TYPE goup_update_events_total counter")
+	for _, t := range slices.Sorted(maps.Keys(events)) {
+		for _, v := range slices.Sorted(maps.Keys(events[t])) {
+			fmt.Fprintf(w, "goup_update_events_total{type=%q,version=%q} %d\n", t, v, events[t][v])
+		}
+	}
+	releases, err := s.Releases()
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(w, "# HELP goup_update_rollout_percent Share of clients offered each release.")
+	fmt.Fprintln(w, "# TYPE goup_update_rollout_percent gauge")
+	for _, rel := range releases {
+		fmt.Fprintf(w, "goup_update_rollout_percent{version=%q} %d\n", rel.Version, rel.Rollout)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
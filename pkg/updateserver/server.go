@@ -0,0 +1,365 @@
+// Package updateserver serves app updates from a plain directory, for
+// fleets that should not depend on the GitHub API:
+//
+//	releases/
+//	  v1.2.0/
+//	    NOTES.md
+//	    webviewer-shell-macos.zip
+//	    webviewer-shell-windows.zip
+//	  v1.3.0/
+//	    ...
+//
+// Each version directory is a release; the highest semantic version is the
+// latest. The server publishes a JSON manifest, a GitHub-compatible
+// releases/latest document (so existing updaters only change their base
+// URL), a Sparkle appcast, and the files themselves with range support. It
+// counts downloads and the events apps report, such as crash-loop
+// rollbacks.
+//
+// An optional rollout.json ({"percentage": 25}) in a version directory
+// stages the release: clients hash their machine ID into a bucket, and only
+// that share of the fleet installs it. 0 halts the rollout.
+package updateserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/goup-util/pkg/fleet"
+	"golang.org/x/mod/semver"
+)
+
+// NotesFile holds a release's notes, in its version directory.
+const NotesFile = "NOTES.md"
+
+// RolloutFile stages a release, in its version directory. Without it the
+// release goes to every client.
+const RolloutFile = "rollout.json"
+
+// metricsFile and eventsFile keep the download and event counts across
+// restarts.
+const (
+	metricsFile = ".downloads.json"
+	eventsFile  = ".events.json"
+)
+
+// IndexFile lists every release for a static copy of the directory (see
+// WriteIndex).
+const IndexFile = "releases.json"
+
+// FleetFile is the device registry in the release directory, when the
+// server keeps one.
+const FleetFile = ".fleet.json"
+
+// Options configure a Server.
+type Options struct {
+	Dir     string // Release directories
+	BaseURL string // Public URL of the server; derived from each request if empty
+
+	Fleet       bool // Keep a device registry (see pkg/fleet)
+	FleetTokens fleet.Tokens
+}
+
+// Asset is a downloadable file of a release.
+type Asset struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Release is one version directory.
+type Release struct {
+	Version   string    `json:"version"`
+	Notes     string    `json:"notes,omitempty"`
+	Published time.Time `json:"published"`
+	Rollout   int       `json:"rollout"` // Percentage of clients offered the release
+	Assets    []Asset   `json:"assets"`
+}
+
+// Server serves the releases in a directory.
+type Server struct {
+	opts Options
+
+	fleet *fleet.Registry // nil unless Options.Fleet
+
+	mu     sync.Mutex
+	hashes map[string]hashEntry
+	counts map[string]map[string]int64 // version → asset → downloads
+	events map[string]map[string]int64 // event type → version → reports
+}
+
+type hashEntry struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// NewServer checks the directory and loads earlier download counts.
+func NewServer(opts Options) (*Server, error) {
+	info, err := os.Stat(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("release directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", opts.Dir)
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	s := &Server{opts: opts, hashes: map[string]hashEntry{}, counts: map[string]map[string]int64{}, events: map[string]map[string]int64{}}
+	if err := loadCounts(filepath.Join(opts.Dir, metricsFile), s.counts); err != nil {
+		return nil, fmt.Errorf("failed to read download counts: %w", err)
+	}
+	if err := loadCounts(filepath.Join(opts.Dir, eventsFile), s.events); err != nil {
+		return nil, fmt.Errorf("failed to read event counts: %w", err)
+	}
+	if opts.Fleet {
+		if s.fleet, err = fleet.Open(filepath.Join(opts.Dir, FleetFile)); err != nil {
+			return nil, fmt.Errorf("failed to read fleet registry: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func loadCounts(path string, into map[string]map[string]int64) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &into)
+}
+
+// canonical returns the semver form of a directory name ("1.2.0" →
+// "v1.2.0"), or "" when it is not a version.
+func canonical(name string) string {
+	v := name
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return ""
+	}
+	return v
+}
+
+// Releases lists the releases, newest first. Asset URLs are relative to
+// the server root.
+func (s *Server) Releases() ([]Release, error) {
+	entries, err := os.ReadDir(s.opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var releases []Release
+	for _, e := range entries {
+		if !e.IsDir() || canonical(e.Name()) == "" {
+			continue
+		}
+		r, err := s.release(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		return semver.Compare(canonical(releases[i].Version), canonical(releases[j].Version)) > 0
+	})
+	return releases, nil
+}
+
+// Latest returns the newest release, or nil when there is none.
+func (s *Server) Latest() (*Release, error) {
+	releases, err := s.Releases()
+	if err != nil || len(releases) == 0 {
+		return nil, err
+	}
+	return &releases[0], nil
+}
+
+func (s *Server) release(version string) (Release, error) {
+	dir := filepath.Join(s.opts.Dir, version)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Release{}, err
+	}
+	r := Release{Version: version, Rollout: 100, Assets: []Asset{}}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			return Release{}, err
+		}
+		if info.ModTime().After(r.Published) {
+			r.Published = info.ModTime().UTC()
+		}
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if e.Name() == RolloutFile {
+			if r.Rollout, err = readRollout(filepath.Join(dir, e.Name())); err != nil {
+				return Release{}, err
+			}
+			continue
+		}
+		if e.Name() == NotesFile {
+			notes, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return Release{}, err
+			}
+			r.Notes = strings.TrimSpace(string(notes))
+			continue
+		}
+		sum, err := s.hash(filepath.Join(dir, e.Name()), info)
+		if err != nil {
+			return Release{}, err
+		}
+		r.Assets = append(r.Assets, Asset{
+			Name:   e.Name(),
+			URL:    "/download/" + version + "/" + e.Name(),
+			Size:   info.Size(),
+			SHA256: sum,
+		})
+	}
+	return r, nil
+}
+
+type rollout struct {
+	Percentage int `json:"percentage"`
+}
+
+func readRollout(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var r rollout
+	if err := json.Unmarshal(data, &r); err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if r.Percentage < 0 || r.Percentage > 100 {
+		return 0, fmt.Errorf("invalid %s: percentage must be 0-100", path)
+	}
+	return r.Percentage, nil
+}
+
+// SetRollout stages a release to a percentage of clients; 0 halts it and
+// 100 releases it to everyone.
+func (s *Server) SetRollout(version string, percentage int) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("rollout percentage must be 0-100, got %d", percentage)
+	}
+	dir := filepath.Join(s.opts.Dir, version)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() || canonical(version) == "" {
+		return fmt.Errorf("no release %s in %s", version, s.opts.Dir)
+	}
+	data, err := json.MarshalIndent(rollout{Percentage: percentage}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, RolloutFile), append(data, '\n'), 0644)
+}
+
+// WriteIndex writes IndexFile into the directory: every release, newest
+// first, with asset URLs relative to it. The directory can then be copied
+// to a bucket or web server and read without a server, with rollouts and
+// checksums (see pkg/self/source). Run it again after each change.
+func (s *Server) WriteIndex() ([]Release, error) {
+	releases, err := s.Releases()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		for i, a := range r.Assets {
+			r.Assets[i].URL = (&url.URL{Path: r.Version + "/" + a.Name}).EscapedPath()
+		}
+	}
+	data, err := json.MarshalIndent(releases, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.opts.Dir, IndexFile)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return releases, os.Rename(path+".tmp", path)
+}
+
+// hash returns the SHA-256 of a file, cached until it changes.
+func (s *Server) hash(path string, info os.FileInfo) (string, error) {
+	s.mu.Lock()
+	h, ok := s.hashes[path]
+	s.mu.Unlock()
+	if ok && h.size == info.Size() && h.modTime.Equal(info.ModTime()) {
+		return h.sum, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	d := sha256.New()
+	if _, err := io.Copy(d, f); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(d.Sum(nil))
+	s.mu.Lock()
+	s.hashes[path] = hashEntry{size: info.Size(), modTime: info.ModTime(), sum: sum}
+	s.mu.Unlock()
+	return sum, nil
+}
+
+// countDownload records a download and persists the counts.
+func (s *Server) countDownload(version, asset string) {
+	s.count(s.counts, metricsFile, version, asset)
+}
+
+// countEvent records an event reported by an app, such as a rollback.
+func (s *Server) countEvent(kind, version string) {
+	s.count(s.events, eventsFile, kind, version)
+}
+
+func (s *Server) count(counts map[string]map[string]int64, file, key, sub string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if counts[key] == nil {
+		counts[key] = map[string]int64{}
+	}
+	counts[key][sub]++
+	if data, err := json.MarshalIndent(counts, "", "  "); err == nil {
+		os.WriteFile(filepath.Join(s.opts.Dir, file), data, 0644)
+	}
+}
+
+// Downloads returns a copy of the download counts by version and asset.
+func (s *Server) Downloads() map[string]map[string]int64 {
+	return s.snapshot(s.counts)
+}
+
+// Events returns a copy of the event counts by type and version.
+func (s *Server) Events() map[string]map[string]int64 {
+	return s.snapshot(s.events)
+}
+
+func (s *Server) snapshot(counts map[string]map[string]int64) map[string]map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]map[string]int64, len(counts))
+	for k, sub := range counts {
+		out[k] = make(map[string]int64, len(sub))
+		for a, n := range sub {
+			out[k][a] = n
+		}
+	}
+	return out
+}
@@ -0,0 +1,210 @@
+package updateserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRelease(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, version), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, version, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	writeRelease(t, dir, "v1.9.0", map[string]string{"app-macos.zip": "old"})
+	writeRelease(t, dir, "1.10.0", map[string]string{"app-macos.zip": "0123456789", NotesFile: "Faster startup\n"})
+	writeRelease(t, dir, "nightly", map[string]string{"app-macos.zip": "ignored"})
+
+	srv, err := NewServer(Options{Dir: dir, BaseURL: "https://updates.example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/releases/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var latest githubRelease
+	json.NewDecoder(resp.Body).Decode(&latest)
+	resp.Body.Close()
+	if latest.TagName != "1.10.0" || latest.Body != "Faster startup" {
+		t.Errorf("latest = %q %q, want 1.10.0 with notes", latest.TagName, latest.Body)
+	}
+	if len(latest.Assets) != 1 || latest.Assets[0].BrowserDownloadURL != "https://updates.example.com/download/1.10.0/app-macos.zip" {
+		t.Errorf("assets = %+v", latest.Assets)
+	}
+
+	req, _ := http.NewRequest("GET", ts.URL+"/download/1.10.0/app-macos.zip", nil)
+	req.Header.Set("Range", "bytes=4-")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "456789" {
+		t.Errorf("range download = %d %q", resp.StatusCode, body)
+	}
+	for range 2 {
+		resp, err := http.Get(ts.URL + "/download/1.10.0/app-macos.zip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if n := srv.Downloads()["1.10.0"]["app-macos.zip"]; n != 2 {
+		t.Errorf("downloads = %d, want 2 (resumed requests are not counted)", n)
+	}
+
+	for _, path := range []string{"/download/1.10.0/.downloads.json", "/download/nightly/app-macos.zip", "/download/1.10.0/missing.zip"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, resp.StatusCode)
+		}
+	}
+
+	resp, err = http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `goup_update_downloads_total{version="1.10.0",file="app-macos.zip"} 2`) {
+		t.Errorf("metrics:\n%s", body)
+	}
+
+	// Counts survive a restart.
+	again, err := NewServer(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := again.Downloads()["1.10.0"]["app-macos.zip"]; n != 2 {
+		t.Errorf("downloads after restart = %d, want 2", n)
+	}
+}
+
+func TestAppcast(t *testing.T) {
+	dir := t.TempDir()
+	writeRelease(t, dir, "v2.0.0", map[string]string{"app-macos.zip": "m", "app-windows.zip": "w", "app-linux.tar.gz": "l"})
+	srv, err := NewServer(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/appcast.xml", nil))
+	out := rec.Body.String()
+	if strings.Count(out, "<item>") != 2 || !strings.Contains(out, `sparkle:os="windows"`) || !strings.Contains(out, "http://example.com/download/v2.0.0/app-macos.zip") {
+		t.Errorf("appcast:\n%s", out)
+	}
+}
+
+func TestRollout(t *testing.T) {
+	dir := t.TempDir()
+	writeRelease(t, dir, "v1.0.0", map[string]string{"app-macos.zip": "a"})
+	srv, err := NewServer(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest, _ := srv.Latest(); latest.Rollout != 100 {
+		t.Errorf("default rollout = %d, want 100", latest.Rollout)
+	}
+	if err := srv.SetRollout("v1.0.0", 101); err == nil {
+		t.Error("SetRollout accepted 101%")
+	}
+	if err := srv.SetRollout("v9.9.9", 10); err == nil {
+		t.Error("SetRollout accepted a missing release")
+	}
+	if err := srv.SetRollout("v1.0.0", 25); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/releases/latest", nil))
+	var latest githubRelease
+	json.NewDecoder(rec.Body).Decode(&latest)
+	if latest.RolloutPercentage != 25 {
+		t.Errorf("rollout_percentage = %d, want 25", latest.RolloutPercentage)
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeRelease(t, dir, "v1.0.0", map[string]string{"app macos.zip": "a"})
+	writeRelease(t, dir, "v1.1.0", map[string]string{"app macos.zip": "b", RolloutFile: `{"percentage": 10}`})
+	srv, err := NewServer(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.WriteIndex(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var releases []Release
+	if err := json.Unmarshal(data, &releases); err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 || releases[0].Version != "v1.1.0" || releases[0].Rollout != 10 {
+		t.Fatalf("index = %+v", releases)
+	}
+	if a := releases[0].Assets[0]; a.URL != "v1.1.0/app%20macos.zip" || a.SHA256 == "" {
+		t.Errorf("asset = %+v", a)
+	}
+	if latest, _ := srv.Latest(); latest.Version != "v1.1.0" {
+		t.Errorf("latest after writing the index = %s", latest.Version)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	dir := t.TempDir()
+	writeRelease(t, dir, "v1.3.0", map[string]string{"app-macos.zip": "new"})
+	srv, err := NewServer(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for body, want := range map[string]int{
+		`{"type": "rollback", "version": "v1.3.0", "machine_id": "m1"}`: http.StatusNoContent,
+		`{"type": "rollback", "version": "1.3.0"}`:                      http.StatusNoContent,
+		`{"type": "Rollback!", "version": "v1.3.0"}`:                    http.StatusBadRequest,
+		`{"type": "crash", "version": "v1.3.0"}`:                        http.StatusBadRequest,
+		`{"type": "rollback", "version": "latest"}`:                     http.StatusBadRequest,
+		`{"type": "rollback", "version": "v9.9.9"}`:                     http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest("POST", "/events", strings.NewReader(body)))
+		if rec.Code != want {
+			t.Errorf("POST %s = %d, want %d", body, rec.Code, want)
+		}
+	}
+	again, err := NewServer(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	again.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `goup_update_events_total{type="rollback",version="v1.3.0"} 2`) {
+		t.Errorf("metrics:\n%s", rec.Body)
+	}
+}
@@ -0,0 +1,174 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GitHub endpoints.
+const (
+	GitHubAPI     = "https://api.github.com"
+	GitHubUploads = "https://uploads.github.com"
+)
+
+// GitHub uploads release assets. The release for Tag is created, with
+// that name, when it does not exist yet; assets already on it with the
+// same name are replaced.
+type GitHub struct {
+	API     string
+	Uploads string
+	Repo    string // owner/name
+	Tag     string
+	Token   string
+	HTTP    *http.Client
+
+	release *ghRelease
+}
+
+// NewGitHub returns a client for the release tag of repo. The token needs
+// contents: write (GITHUB_TOKEN in Actions has it with that permission).
+func NewGitHub(repo, tag, token string) *GitHub {
+	return &GitHub{
+		API:     GitHubAPI,
+		Uploads: GitHubUploads,
+		Repo:    repo,
+		Tag:     tag,
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Minute},
+	}
+}
+
+func (g *GitHub) String() string { return fmt.Sprintf("%s release %s", g.Repo, g.Tag) }
+
+type ghRelease struct {
+	ID      int64     `json:"id"`
+	HTMLURL string    `json:"html_url"`
+	Assets  []ghAsset `json:"assets"`
+}
+
+type ghAsset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Put uploads an asset.
+func (g *GitHub) Put(name string, body io.ReadSeeker, size int64) (string, error) {
+	release, err := g.findRelease()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range release.Assets {
+		if a.Name == name {
+			if err := g.do("DELETE", fmt.Sprintf("%s/repos/%s/releases/assets/%d", g.API, g.Repo, a.ID), nil, nil); err != nil {
+				return "", fmt.Errorf("replace %s: %w", name, err)
+			}
+		}
+	}
+
+	u := fmt.Sprintf("%s/repos/%s/releases/%d/assets?name=%s", g.Uploads, g.Repo, release.ID, url.QueryEscape(name))
+	req, err := http.NewRequest("POST", u, io.NopCloser(body)) // Retries reuse body
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType(name))
+	var asset ghAsset
+	if err := g.send(req, &asset); err != nil {
+		g.release = nil // Look the assets up again before retrying
+		return "", err
+	}
+	g.release.Assets = append(g.release.Assets, asset)
+	return asset.BrowserDownloadURL, nil
+}
+
+// findRelease looks the release up by tag, creating it when missing.
+func (g *GitHub) findRelease() (*ghRelease, error) {
+	if g.release != nil {
+		return g.release, nil
+	}
+	var release ghRelease
+	err := g.do("GET", fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.API, g.Repo, url.PathEscape(g.Tag)), nil, &release)
+	if se, ok := err.(*StatusError); ok && se.Code == http.StatusNotFound {
+		err = g.do("POST", fmt.Sprintf("%s/repos/%s/releases", g.API, g.Repo),
+			map[string]any{"tag_name": g.Tag, "name": g.Tag}, &release)
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.release = &release
+	return g.release, nil
+}
+
+func (g *GitHub) do(method, u string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return g.send(req, out)
+}
+
+func (g *GitHub) send(req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+	resp, err := g.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(data, &e)
+		return &StatusError{Code: resp.StatusCode, Message: e.Message}
+	}
+	if out != nil && len(data) > 0 {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+var remoteRepo = regexp.MustCompile(`github\.com[:/]([^/]+/[^/]+?)(?:\.git)?/?$`)
+
+// RepoFromGit returns owner/name of the GitHub remote origin of the git
+// checkout at dir.
+func RepoFromGit(dir string) (string, error) {
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no git remote origin in %s", dir)
+	}
+	m := remoteRepo.FindStringSubmatch(strings.TrimSpace(string(out)))
+	if m == nil {
+		return "", fmt.Errorf("remote origin %s is not on GitHub", strings.TrimSpace(string(out)))
+	}
+	return m[1], nil
+}
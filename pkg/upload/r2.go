@@ -0,0 +1,228 @@
+package upload
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bucket uploads objects to, and downloads them from, R2 or another
+// S3-compatible store with AWS Signature Version 4, path-style
+// (<endpoint>/<bucket>/<key>).
+type Bucket struct {
+	Endpoint  string // https://<account-id>.r2.cloudflarestorage.com
+	Region    string // "auto" for R2
+	Name      string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	// PublicURL serves the bucket (an r2.dev or custom domain); results
+	// link to it instead of the endpoint.
+	PublicURL string
+	HTTP      *http.Client
+
+	now func() time.Time
+}
+
+// NewR2 returns a client for an R2 bucket of a Cloudflare account, with an
+// R2 API token's access key pair.
+func NewR2(accountID, bucket, prefix, accessKey, secretKey string) *Bucket {
+	return &Bucket{
+		Endpoint:  fmt.Sprintf("https://%s.r2.cloudflarestorage.com", accountID),
+		Region:    "auto",
+		Name:      bucket,
+		Prefix:    prefix,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		HTTP:      &http.Client{Timeout: 30 * time.Minute},
+		now:       time.Now,
+	}
+}
+
+func (b *Bucket) String() string { return "r2://" + strings.TrimSuffix(b.Name+"/"+b.Prefix, "/") }
+
+// Put uploads an object with a single PUT (up to 5 GB).
+func (b *Bucket) Put(name string, body io.ReadSeeker, size int64) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	key := strings.TrimPrefix(b.Prefix+"/"+name, "/")
+	u := strings.TrimSuffix(b.Endpoint, "/") + "/" + escapePath(b.Name+"/"+key)
+	req, err := http.NewRequest("PUT", u, io.NopCloser(body)) // Retries reuse body
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType(name))
+	b.sign(req, hex.EncodeToString(h.Sum(nil)))
+
+	resp, err := b.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", b.statusError(resp)
+	}
+	if b.PublicURL != "" {
+		return strings.TrimSuffix(b.PublicURL, "/") + "/" + escapePath(key), nil
+	}
+	return u, nil
+}
+
+// Get downloads the object name (under Prefix) to w. Without an access
+// key the request is not signed, for public buckets.
+func (b *Bucket) Get(name string, w io.Writer) error {
+	return b.GetFrom(name, 0, w)
+}
+
+// GetFrom downloads the object from byte offset on, to resume a download.
+func (b *Bucket) GetFrom(name string, offset int64, w io.Writer) error {
+	key := strings.TrimPrefix(b.Prefix+"/"+name, "/")
+	req, err := http.NewRequest("GET", strings.TrimSuffix(b.Endpoint, "/")+"/"+escapePath(b.Name+"/"+key), nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if b.AccessKey != "" {
+		b.sign(req, emptyHash)
+	}
+	resp, err := b.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return b.statusError(resp)
+	}
+	if offset > 0 && resp.StatusCode == http.StatusOK {
+		// The range was ignored; skip what the caller has
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			return err
+		}
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// emptyHash is the SHA-256 of an empty payload.
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func (b *Bucket) statusError(resp *http.Response) error {
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(resp.Body)
+	xml.Unmarshal(data, &e)
+	return &StatusError{Code: resp.StatusCode, Message: strings.TrimSpace(e.Code + " " + e.Message)}
+}
+
+// sign adds the x-amz-date, x-amz-content-sha256 and Authorization
+// headers, signing every header already set and the host.
+func (b *Bucket) sign(req *http.Request, payloadHash string) {
+	now := time.Now
+	if b.now != nil {
+		now = b.now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.Join(v, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + b.Region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	key := hmacSHA256([]byte("AWS4"+b.SecretKey), date)
+	key = hmacSHA256(key, b.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.AccessKey, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapePath escapes each segment of an object path as SigV4 expects.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape is RFC 3986 percent-encoding: everything but A-Z a-z 0-9 - _ . ~.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,178 @@
+// Package upload publishes build artifacts to a GitHub release or to an
+// R2 bucket (or another S3-compatible store), each next to a .sha256 file
+// in sha256sum format, retrying requests that fail for transient reasons.
+//
+// Targets are written as:
+//
+//	github-release:v1.2.3     the release for tag v1.2.3, created if missing
+//	r2://bucket/path/prefix   objects under path/prefix in bucket
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of target.
+const (
+	GitHubRelease = "github-release"
+	R2            = "r2"
+)
+
+// Spec is a parsed target.
+type Spec struct {
+	Kind   string
+	Tag    string // GitHubRelease
+	Bucket string // R2
+	Prefix string // R2, without leading or trailing slashes
+}
+
+// ParseSpec reads "github-release:<tag>" or "r2://<bucket>/<prefix>".
+func ParseSpec(s string) (Spec, error) {
+	if tag, ok := strings.CutPrefix(s, GitHubRelease+":"); ok {
+		if tag == "" {
+			return Spec{}, fmt.Errorf("%q names no tag (github-release:v1.2.3)", s)
+		}
+		return Spec{Kind: GitHubRelease, Tag: tag}, nil
+	}
+	if rest, ok := strings.CutPrefix(s, R2+"://"); ok {
+		bucket, prefix, _ := strings.Cut(rest, "/")
+		if bucket == "" {
+			return Spec{}, fmt.Errorf("%q names no bucket (r2://bucket/path)", s)
+		}
+		return Spec{Kind: R2, Bucket: bucket, Prefix: strings.Trim(prefix, "/")}, nil
+	}
+	return Spec{}, fmt.Errorf("unknown target %q (github-release:<tag> or r2://<bucket>/<path>)", s)
+}
+
+// Target stores uploaded files.
+type Target interface {
+	// Put stores size bytes from body under name and returns the URL the
+	// file is downloaded from. body is read from the start on each call.
+	Put(name string, body io.ReadSeeker, size int64) (string, error)
+	String() string
+}
+
+// Result is an uploaded artifact.
+type Result struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	Checksum string `json:"checksumUrl"`
+}
+
+// Attempts is how often a request is tried before Upload gives up.
+const Attempts = 4
+
+// sleep waits between attempts; replaced in tests.
+var sleep = time.Sleep
+
+// Upload puts each file and its .sha256 file on t. Directories (such as
+// .app bundles) must be packaged first.
+func Upload(t Target, files []string) ([]Result, error) {
+	var results []Result
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return results, err
+		}
+		info, err := f.Stat()
+		if err == nil && info.IsDir() {
+			err = fmt.Errorf("%s is a directory; package it first (goup-util package)", file)
+		}
+		var sum string
+		if err == nil {
+			sum, err = hashFile(f)
+		}
+		if err != nil {
+			f.Close()
+			return results, err
+		}
+
+		name := filepath.Base(file)
+		r := Result{Name: name, Size: info.Size(), SHA256: sum}
+		r.URL, err = put(t, name, f, info.Size())
+		f.Close()
+		if err != nil {
+			return results, err
+		}
+		line := sum + "  " + name + "\n"
+		r.Checksum, err = put(t, name+".sha256", strings.NewReader(line), int64(len(line)))
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// put retries t.Put with exponential backoff while it fails for reasons
+// that may pass.
+func put(t Target, name string, body io.ReadSeeker, size int64) (string, error) {
+	wait := time.Second
+	for attempt := 1; ; attempt++ {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		url, err := t.Put(name, body, size)
+		if err == nil || !temporary(err) || attempt == Attempts {
+			if err != nil {
+				return "", fmt.Errorf("upload %s to %s: %w", name, t, err)
+			}
+			return url, nil
+		}
+		fmt.Fprintf(os.Stderr, "⏳ %s: %v; retrying in %v\n", name, err, wait)
+		sleep(wait)
+		wait *= 2
+	}
+}
+
+// StatusError is an HTTP response that was not a success.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%d %s: %s", e.Code, http.StatusText(e.Code), e.Message)
+	}
+	return fmt.Sprintf("%d %s", e.Code, http.StatusText(e.Code))
+}
+
+// temporary reports whether err is a network error, a server error or
+// rate limiting.
+func temporary(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code >= 500 || se.Code == http.StatusTooManyRequests
+	}
+	var ne net.Error
+	return errors.As(err, &ne) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func hashFile(f *os.File) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func contentType(name string) string {
+	if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/joeblew999/goup-util/pkg/config"
 	"github.com/joeblew999/goup-util/pkg/installer"
+	"github.com/joeblew999/goup-util/pkg/schema"
 )
 
 // utils.go provides centralized utility functions to reduce code duplication
@@ -15,7 +16,7 @@ import (
 
 // Platform definitions - centralized list of supported platforms
 var (
-	IconPlatforms  = []string{"android", "ios", "macos", "windows", "windows-msix", "windows-ico"}
+	IconPlatforms  = schema.IconPlatforms
 	BuildPlatforms = []string{"macos", "android", "ios", "ios-simulator", "windows", "all"}
 )
 
@@ -51,9 +52,9 @@ func NewCacheWithDirectories() (*installer.Cache, error) {
 	return cache, nil
 }
 
-// ParseSDKFiles parses all SDK files (Android, iOS, Build Tools) and returns them
+// ParseSDKFiles parses all SDK files (Android, iOS, Build Tools, Windows) and returns them
 func ParseSDKFiles() ([]config.SdkFile, error) {
-	sdkFileContents := [][]byte{config.AndroidSdkList, config.IosSdkList, config.BuildToolsSdkList}
+	sdkFileContents := [][]byte{config.AndroidSdkList, config.IosSdkList, config.BuildToolsSdkList, config.WindowsSdkList}
 	var sdkFiles []config.SdkFile
 
 	for _, sdkFileContent := range sdkFileContents {
@@ -69,9 +70,9 @@ func ParseSDKFiles() ([]config.SdkFile, error) {
 	return sdkFiles, nil
 }
 
-// ParseMetaFiles parses all SDK files (Android, iOS, Build Tools) as MetaFiles for setup functionality
+// ParseMetaFiles parses all SDK files (Android, iOS, Build Tools, Windows) as MetaFiles for setup functionality
 func ParseMetaFiles() ([]config.MetaFile, error) {
-	sdkFileContents := [][]byte{config.AndroidSdkList, config.IosSdkList, config.BuildToolsSdkList}
+	sdkFileContents := [][]byte{config.AndroidSdkList, config.IosSdkList, config.BuildToolsSdkList, config.WindowsSdkList}
 	var metaFiles []config.MetaFile
 
 	for _, sdkFileContent := range sdkFileContents {
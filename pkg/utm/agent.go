@@ -0,0 +1,125 @@
+// Package utm provides UTM VM management functionality.
+// QEMU guest agent health check and repair.
+package utm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrGuestAgent means the VM is running but its QEMU guest agent does not
+// answer, so exec, file push/pull and ip-address cannot work.
+var ErrGuestAgent = errors.New("QEMU guest agent is not responding")
+
+// agentProbeTimeout bounds the probe; utmctl can hang on a dead agent.
+const agentProbeTimeout = 15 * time.Second
+
+// CheckGuestAgent verifies that the VM is running and its guest agent
+// answers. It returns ErrGuestAgent (wrapped) when only the agent is
+// missing.
+func CheckGuestAgent(vmName string) error {
+	status, err := GetVMStatus(vmName)
+	if err != nil {
+		return err
+	}
+	if status != "started" {
+		return fmt.Errorf("VM '%s' is %s; start it with: goup-util utm start \"%s\"", vmName, status, vmName)
+	}
+
+	// ip-address is answered by the guest agent and has no side effects.
+	ctx, cancel := context.WithTimeout(context.Background(), agentProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, GetUTMCtlPath(), "ip-address", vmName).CombinedOutput()
+	if err != nil {
+		detail := strings.TrimSpace(string(out))
+		if ctx.Err() != nil {
+			detail = "no answer within " + agentProbeTimeout.String()
+		}
+		return fmt.Errorf("%w in '%s': %s", ErrGuestAgent, vmName, detail)
+	}
+	return nil
+}
+
+// GuestOS guesses a VM's OS ("windows", "linux" or "macos") from the
+// gallery entry with the same name, else from the name itself.
+func GuestOS(vmName string) string {
+	if gallery, err := LoadGallery(); err == nil {
+		for _, vm := range gallery.VMs {
+			if vm.Name == vmName {
+				return vm.OS
+			}
+		}
+	}
+	name := strings.ToLower(vmName)
+	switch {
+	case strings.Contains(name, "windows"):
+		return "windows"
+	case strings.Contains(name, "macos"):
+		return "macos"
+	}
+	return "linux"
+}
+
+// GuestAgentFixes returns the steps that usually bring the agent back on
+// the given guest OS.
+func GuestAgentFixes(guestOS string) []string {
+	switch guestOS {
+	case "windows":
+		fixes := []string{
+			"In the VM (admin PowerShell): Set-Service QEMU-GA -StartupType Automatic; Start-Service QEMU-GA",
+			"If the service is missing, install the UTM guest tools: attach the ISO below in UTM (CD/DVD) and run utm-guest-tools-*.exe",
+		}
+		if iso, err := GetGuestToolsISOPath(); err == nil {
+			fixes = append(fixes, "Guest tools ISO: "+iso)
+		} else {
+			fixes = append(fixes, "Download the guest tools ISO in UTM: VM settings → CD/DVD → Install Windows Guest Tools")
+		}
+		return fixes
+	case "macos":
+		return []string{
+			"macOS guests use Apple Virtualization, which has no QEMU guest agent; use SSH instead (utm agent --ssh)",
+		}
+	default:
+		return []string{
+			"In the VM: sudo apt-get install -y qemu-guest-agent (or dnf install qemu-guest-agent)",
+			"Then: sudo systemctl enable --now qemu-guest-agent",
+			"The VM needs a QEMU backend; Apple Virtualization VMs have no guest agent",
+		}
+	}
+}
+
+// repairScripts are run over SSH to reinstall and start the agent.
+var repairScripts = map[string]string{
+	"windows": `powershell -NoProfile -Command "if (Get-Service QEMU-GA -ErrorAction SilentlyContinue) { Set-Service QEMU-GA -StartupType Automatic; Restart-Service QEMU-GA } else { Write-Error 'QEMU-GA service is not installed; install the UTM guest tools'; exit 1 }"`,
+	"linux":   `sudo sh -c 'if command -v apt-get >/dev/null; then apt-get update -q && apt-get install -y qemu-guest-agent; elif command -v dnf >/dev/null; then dnf install -y qemu-guest-agent; fi && systemctl enable qemu-guest-agent && systemctl restart qemu-guest-agent'`,
+}
+
+// RepairGuestAgent reinstalls (Linux) or restarts (Windows) the guest agent
+// over SSH, which keeps working when the agent is down, then checks it again.
+// target is an ssh destination such as user@192.168.64.5.
+func RepairGuestAgent(vmName, guestOS, target string) error {
+	script, ok := repairScripts[guestOS]
+	if !ok {
+		return fmt.Errorf("cannot repair the guest agent on %s guests", guestOS)
+	}
+	cmd := exec.Command("ssh", "-t", target, script)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("repair over SSH failed: %w", err)
+	}
+
+	// The agent needs a moment to open its channel.
+	var err error
+	for i := 0; i < 6; i++ {
+		time.Sleep(5 * time.Second)
+		if err = CheckGuestAgent(vmName); err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+// Package utm provides UTM VM management functionality.
+// Headless operation and VNC console access for build VMs.
+package utm
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// DefaultVNCPort is the host port for the VM console (QEMU display :1).
+const DefaultVNCPort = 5901
+
+// vncArg returns the QEMU argument serving the console on localhost only.
+// Remote viewers reach it through an SSH tunnel, so it needs no password.
+func vncArg(port int) string {
+	return fmt.Sprintf("-vnc 127.0.0.1:%d", port-5900)
+}
+
+// EnableVNC makes QEMU serve the VM display over VNC on 127.0.0.1:port.
+// The setting is saved in the VM configuration, which UTM only allows
+// while the VM is stopped. Apple Virtualization VMs have no QEMU and
+// cannot use it.
+func EnableVNC(vmName string, port int) error {
+	if port <= 5900 || port > 65535 {
+		return fmt.Errorf("invalid VNC port %d (must be above 5900)", port)
+	}
+	return setVNC(vmName, vncArg(port))
+}
+
+// DisableVNC removes the VNC server from the VM configuration.
+func DisableVNC(vmName string) error {
+	return setVNC(vmName, "")
+}
+
+func setVNC(vmName, arg string) error {
+	vmID, err := GetVMUUID(vmName)
+	if err != nil {
+		return fmt.Errorf("failed to get VM UUID: %w", err)
+	}
+	out, err := ExecuteOsaScript("set_qemu_vnc.applescript", vmID, arg)
+	if err != nil {
+		return fmt.Errorf("failed to configure VNC: %w", err)
+	}
+	if out == "running" {
+		return fmt.Errorf("VM '%s' is running; stop it to change its VNC console, or start it with 'utm start --headless --vnc'", vmName)
+	}
+	return nil
+}
+
+// StartVMHeadless starts a VM without bringing UTM or the VM window to the
+// front, for hosts nobody sits at. UTM still needs a logged-in GUI session
+// (enable automatic login on the build Mac).
+func StartVMHeadless(vmName string) error {
+	if !IsUTMRunning() {
+		// -g: don't activate, -j: launch hidden
+		if err := exec.Command("open", "-g", "-j", "-a", GetPaths().App).Run(); err != nil {
+			return fmt.Errorf("failed to launch UTM: %w", err)
+		}
+		time.Sleep(2 * time.Second)
+	}
+	if _, err := RunUTMCtl("start", vmName); err != nil {
+		return err
+	}
+	// Hide the VM window UTM opens on start
+	exec.Command("osascript", "-e", `tell application "System Events" to set visible of process "UTM" to false`).Run()
+	return nil
+}
+
+// WaitForPort waits until something accepts connections on 127.0.0.1:port.
+func WaitForPort(port int, timeout time.Duration) error {
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("nothing is listening on %s: %w", addr, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// OpenVNCViewer opens vnc://127.0.0.1:port in the system viewer (Screen
+// Sharing on macOS). It returns false if there is no known viewer.
+func OpenVNCViewer(port int) (bool, error) {
+	url := fmt.Sprintf("vnc://127.0.0.1:%d", port)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "linux":
+		cmd = exec.Command("xdg-open", url)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", url)
+	default:
+		return false, nil
+	}
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,300 @@
+// Package utm provides UTM VM management functionality.
+// Declarative provisioning of build VMs through the guest agent.
+package utm
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed recipes/*.yaml
+var builtinRecipes embed.FS
+
+// Recipe is a declarative list of steps that turn a fresh VM into a build
+// machine. Each step runs at most once per VM: a marker named after the
+// step's contents is written in the guest when it succeeds, so re-running
+// a recipe only runs new or changed steps.
+type Recipe struct {
+	Name  string `yaml:"name"`
+	OS    string `yaml:"os"` // windows or linux
+	Steps []Step `yaml:"steps"`
+}
+
+// Step does exactly one of: install tools, run a script, set persistent
+// environment variables, or reboot.
+type Step struct {
+	Name    string            `yaml:"name"`
+	Install []string          `yaml:"install,omitempty"` // go, git, task, goup-util
+	Run     string            `yaml:"run,omitempty"`     // PowerShell on Windows, sh on Linux
+	Env     map[string]string `yaml:"env,omitempty"`     // Machine-wide variables
+	Reboot  bool              `yaml:"reboot,omitempty"`
+
+	// Unless skips the step (and marks it done) when this command succeeds.
+	Unless string `yaml:"unless,omitempty"`
+}
+
+// installers maps tool names to install commands per guest OS.
+var installers = map[string]map[string]string{
+	"windows": {
+		"go":        wingetInstall("GoLang.Go"),
+		"git":       wingetInstall("Git.Git"),
+		"task":      wingetInstall("Task.Task"),
+		"goup-util": "go install github.com/joeblew999/goup-util@latest",
+	},
+	"linux": {
+		"go":        `curl -fsSL "https://go.dev/dl/$(curl -fsSL 'https://go.dev/VERSION?m=text' | head -1).linux-$(dpkg --print-architecture).tar.gz" | tar -C /usr/local -xz && echo 'export PATH=$PATH:/usr/local/go/bin:$HOME/go/bin' > /etc/profile.d/go.sh`,
+		"git":       "apt-get update && apt-get install -y git",
+		"task":      `sh -c "$(curl --location https://taskfile.dev/install.sh)" -- -d -b /usr/local/bin`,
+		"goup-util": "GOBIN=/usr/local/bin /usr/local/go/bin/go install github.com/joeblew999/goup-util@latest",
+	},
+}
+
+func wingetInstall(id string) string {
+	return fmt.Sprintf("winget install --id %s -e --silent --accept-package-agreements --accept-source-agreements", id)
+}
+
+// markerDir is where step markers live in the guest.
+var markerDir = map[string]string{
+	"windows": `C:\ProgramData\goup-util\provision`,
+	"linux":   "/var/lib/goup-util/provision",
+}
+
+// BuiltinRecipes lists the recipes shipped with goup-util.
+func BuiltinRecipes() []string {
+	entries, _ := builtinRecipes.ReadDir("recipes")
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	return names
+}
+
+// LoadRecipe reads a recipe file, or a built-in recipe by name.
+func LoadRecipe(nameOrPath string) (*Recipe, error) {
+	data, err := os.ReadFile(nameOrPath)
+	if os.IsNotExist(err) && !strings.ContainsAny(nameOrPath, `/\`) {
+		data, err = builtinRecipes.ReadFile("recipes/" + strings.TrimSuffix(nameOrPath, ".yaml") + ".yaml")
+		if err != nil {
+			return nil, fmt.Errorf("recipe not found: %s (built-in: %s)", nameOrPath, strings.Join(BuiltinRecipes(), ", "))
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read recipe: %w", err)
+	}
+	recipe, err := ParseRecipe(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", nameOrPath, err)
+	}
+	if recipe.Name == "" {
+		recipe.Name = strings.TrimSuffix(filepath.Base(nameOrPath), filepath.Ext(nameOrPath))
+	}
+	return recipe, nil
+}
+
+// ParseRecipe parses and validates a recipe.
+func ParseRecipe(data []byte) (*Recipe, error) {
+	var r Recipe
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid recipe: %w", err)
+	}
+	if _, ok := markerDir[r.OS]; !ok {
+		return nil, fmt.Errorf("recipe os must be windows or linux, got %q", r.OS)
+	}
+	if len(r.Steps) == 0 {
+		return nil, fmt.Errorf("recipe has no steps")
+	}
+	for i, s := range r.Steps {
+		actions := 0
+		for _, set := range []bool{len(s.Install) > 0, s.Run != "", len(s.Env) > 0, s.Reboot} {
+			if set {
+				actions++
+			}
+		}
+		if actions != 1 {
+			return nil, fmt.Errorf("step %d (%s): needs exactly one of install, run, env or reboot", i+1, s.Name)
+		}
+		for _, tool := range s.Install {
+			if _, ok := installers[r.OS][tool]; !ok {
+				return nil, fmt.Errorf("step %d (%s): unknown tool %q (known: go, git, task, goup-util)", i+1, s.Name, tool)
+			}
+		}
+	}
+	return &r, nil
+}
+
+// Key identifies a step by its contents, so an edited step runs again.
+func (s Step) Key() string {
+	data, _ := yaml.Marshal(s)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// Title is the step name, or a description of what it does.
+func (s Step) Title() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case len(s.Install) > 0:
+		return "install " + strings.Join(s.Install, ", ")
+	case s.Reboot:
+		return "reboot"
+	case len(s.Env) > 0:
+		return "set environment"
+	}
+	return s.Run
+}
+
+// Script returns the guest script for the step.
+func (s Step) Script(guestOS string) string {
+	var lines []string
+	switch {
+	case len(s.Install) > 0:
+		for _, tool := range s.Install {
+			lines = append(lines, installers[guestOS][tool])
+		}
+	case len(s.Env) > 0:
+		keys := make([]string, 0, len(s.Env))
+		for k := range s.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if guestOS == "windows" {
+				lines = append(lines, fmt.Sprintf("[Environment]::SetEnvironmentVariable(%s, %s, 'Machine')", psQuote(k), psQuote(s.Env[k])))
+			} else {
+				lines = append(lines, fmt.Sprintf("sed -i '/^%s=/d' /etc/environment && echo %s >> /etc/environment", k, shQuote(k+"="+s.Env[k])))
+			}
+		}
+	case s.Reboot:
+		if guestOS == "windows" {
+			return "shutdown /r /t 5"
+		}
+		return "(sleep 5; reboot) >/dev/null 2>&1 &"
+	default:
+		return s.Run
+	}
+	if guestOS == "windows" {
+		// Stop at the first failing native command
+		for i, l := range lines {
+			lines[i] = l + "; if ($LASTEXITCODE) { exit $LASTEXITCODE }"
+		}
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(lines, " && ")
+}
+
+// ProvisionOptions controls Provision.
+type ProvisionOptions struct {
+	Force  bool // Ignore markers and run every step
+	DryRun bool // Print the scripts without running them
+}
+
+// Provision runs the recipe's steps in the VM through the guest agent.
+func Provision(vmName string, r *Recipe, opts ProvisionOptions) error {
+	for i, step := range r.Steps {
+		marker := guestPath(r.OS, markerDir[r.OS], r.Name+"-"+step.Key())
+		prefix := fmt.Sprintf("[%d/%d] %s", i+1, len(r.Steps), step.Title())
+
+		if opts.DryRun {
+			fmt.Printf("%s\n%s\n\n", prefix, indent(step.Script(r.OS)))
+			continue
+		}
+		if !opts.Force && guestRun(vmName, r.OS, markerTest(r.OS, marker)) == nil {
+			fmt.Printf("✓ %s (already done)\n", prefix)
+			continue
+		}
+		if step.Unless != "" && guestRun(vmName, r.OS, step.Unless) == nil {
+			fmt.Printf("✓ %s (unless check passed)\n", prefix)
+			if err := guestRun(vmName, r.OS, markerWrite(r.OS, marker)); err != nil {
+				return fmt.Errorf("failed to write marker: %w", err)
+			}
+			continue
+		}
+
+		fmt.Printf("▶ %s\n", prefix)
+		if step.Reboot {
+			// Mark first: the reboot cuts the guest agent connection.
+			if err := guestRun(vmName, r.OS, markerWrite(r.OS, marker)); err != nil {
+				return fmt.Errorf("failed to write marker: %w", err)
+			}
+			guestRun(vmName, r.OS, step.Script(r.OS))
+			if err := waitForGuest(vmName, r.OS, 10*time.Minute); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := guestRun(vmName, r.OS, step.Script(r.OS)); err != nil {
+			return fmt.Errorf("step %q failed: %w", step.Title(), err)
+		}
+		if err := guestRun(vmName, r.OS, markerWrite(r.OS, marker)); err != nil {
+			return fmt.Errorf("failed to write marker: %w", err)
+		}
+	}
+	return nil
+}
+
+// guestRun runs a script in the guest shell and returns its exit status.
+func guestRun(vmName, guestOS, script string) error {
+	if guestOS == "windows" {
+		return ExecArgsInVM(vmName, "powershell.exe", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", script)
+	}
+	return ExecArgsInVM(vmName, "/bin/sh", "-c", script)
+}
+
+// waitForGuest waits for the guest agent to answer after a reboot.
+func waitForGuest(vmName, guestOS string, timeout time.Duration) error {
+	fmt.Println("  Waiting for the VM to come back...")
+	time.Sleep(20 * time.Second) // Let the shutdown start
+	deadline := time.Now().Add(timeout)
+	for {
+		if guestRun(vmName, guestOS, "exit 0") == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("VM '%s' did not come back within %s of rebooting", vmName, timeout)
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
+
+func markerTest(guestOS, marker string) string {
+	if guestOS == "windows" {
+		return fmt.Sprintf("if (Test-Path %s) { exit 0 } else { exit 1 }", psQuote(marker))
+	}
+	return "test -f " + shQuote(marker)
+}
+
+func markerWrite(guestOS, marker string) string {
+	stamp := time.Now().UTC().Format(time.RFC3339)
+	if guestOS == "windows" {
+		return fmt.Sprintf("New-Item -Force -ItemType File -Path %s -Value %s | Out-Null", psQuote(marker), psQuote(stamp))
+	}
+	return fmt.Sprintf("mkdir -p %s && echo %s > %s", shQuote(markerDir["linux"]), stamp, shQuote(marker))
+}
+
+func guestPath(guestOS, dir, name string) string {
+	if guestOS == "windows" {
+		return dir + `\` + name
+	}
+	return dir + "/" + name
+}
+
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(s, "\n", "\n    ")
+}
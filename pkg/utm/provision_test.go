@@ -0,0 +1,45 @@
+package utm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltinRecipes(t *testing.T) {
+	for _, name := range BuiltinRecipes() {
+		if _, err := LoadRecipe(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestParseRecipe(t *testing.T) {
+	r, err := ParseRecipe([]byte(`
+os: windows
+steps:
+  - install: [go, git]
+  - env: {GOFLAGS: "-mod=mod", NAME: "it's"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Steps[0].Script("windows"); strings.Count(got, "winget install") != 2 {
+		t.Errorf("install script = %q", got)
+	}
+	if got := r.Steps[1].Script("windows"); !strings.Contains(got, "'it''s'") {
+		t.Errorf("env value not quoted for PowerShell: %q", got)
+	}
+	if r.Steps[0].Key() == r.Steps[1].Key() {
+		t.Error("different steps share a marker key")
+	}
+
+	for _, bad := range []string{
+		"os: macos\nsteps: [{run: x}]",
+		"os: linux\nsteps: [{run: x, reboot: true}]",
+		"os: linux\nsteps: [{install: [node]}]",
+	} {
+		if _, err := ParseRecipe([]byte(bad)); err == nil {
+			t.Errorf("ParseRecipe(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -100,6 +100,13 @@ func ExecInVM(vmName string, command string) error {
 	return RunUTMCtlInteractive("exec", vmName, "--cmd", command)
 }
 
+// ExecArgsInVM runs a program with arguments in a VM and returns its exit
+// status. Unlike ExecInVM the arguments are passed as-is, without relying
+// on the guest to split a command line.
+func ExecArgsInVM(vmName string, program string, args ...string) error {
+	return RunUTMCtlInteractive(append([]string{"exec", vmName, "--cmd", program}, args...)...)
+}
+
 // CloneVM creates a clone of a VM
 func CloneVM(vmName, newName string) error {
 	return RunUTMCtlInteractive("clone", vmName, "--name", newName)
@@ -0,0 +1,65 @@
+package wintools
+
+import "strings"
+
+// Check is one line of the Windows doctor report.
+type Check struct {
+	Name     string `json:"name"`
+	OK       bool   `json:"ok"`
+	Required bool   `json:"required"`
+	Detail   string `json:"detail,omitempty"`
+	Fix      string `json:"fix,omitempty"`
+}
+
+// Doctor checks a Windows machine, such as a UTM VM or a CI runner, for
+// what building, running and packaging Gio and webview apps needs.
+// Missing required checks mean builds fail; the others only affect
+// running webview apps, packaging or signing.
+func Doctor() []Check {
+	var checks []Check
+	add := func(c Check) { checks = append(checks, c) }
+
+	if out, err := output("go", "version"); err == nil {
+		add(Check{Name: "go", OK: true, Required: true, Detail: strings.TrimSpace(out)})
+	} else {
+		add(Check{Name: "go", Required: true, Detail: "not found in PATH", Fix: "winget install GoLang.Go"})
+	}
+	if path, err := lookPath("gogio"); err == nil {
+		add(Check{Name: "gogio", OK: true, Required: true, Detail: path})
+	} else {
+		add(Check{Name: "gogio", Required: true, Detail: "not found in PATH", Fix: "go install gioui.org/cmd/gogio@latest"})
+	}
+	if path, err := lookPath("git"); err == nil {
+		add(Check{Name: "git", OK: true, Detail: path})
+	} else {
+		add(Check{Name: "git", Detail: "not found in PATH (needed for go get from git)", Fix: "winget install Git.Git"})
+	}
+
+	if v := WebView2Version(); v != "" {
+		add(Check{Name: "WebView2 runtime", OK: true, Detail: v})
+	} else {
+		add(Check{Name: "WebView2 runtime", Detail: "not installed; webview apps will not start", Fix: "goup-util install " + WebView2})
+	}
+	if path, err := FindSDKTool("signtool"); err == nil {
+		add(Check{Name: "signtool", OK: true, Detail: path})
+	} else {
+		add(Check{Name: "signtool", Detail: "not found; needed to sign .exe and .msix files", Fix: "goup-util install " + WindowsSDK})
+	}
+	if path, _, err := FindMSIXPacker(); err == nil {
+		add(Check{Name: "MSIX packer", OK: true, Detail: path})
+	} else {
+		add(Check{Name: "MSIX packer", Detail: "not found; needed for 'goup-util bundle windows' MSIX output", Fix: "goup-util install " + MSIX})
+	}
+
+	return checks
+}
+
+// Healthy reports whether every required check passed.
+func Healthy(checks []Check) bool {
+	for _, c := range checks {
+		if c.Required && !c.OK {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+// Package wintools finds and installs the Windows-side tools Gio and
+// webview apps need, without Visual Studio or MSVC: the WebView2 runtime,
+// an MSIX packer (MakeAppx or the msix toolkit) and signtool from the
+// Windows SDK. Gio builds with plain Go on Windows, so nothing else is
+// required.
+package wintools
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Tool names, as used by 'goup-util install' and the default-windows setup.
+const (
+	WebView2   = "webview2-runtime"
+	WindowsSDK = "windows-sdk"
+	MSIX       = "msix-packaging-tool"
+)
+
+// Names lists the installable tools.
+var Names = []string{WebView2, WindowsSDK, MSIX}
+
+// Tests replace these.
+var (
+	lookPath = exec.LookPath
+	output   = func(name string, args ...string) (string, error) {
+		out, err := exec.Command(name, args...).CombinedOutput()
+		return string(out), err
+	}
+	kitsBin = filepath.Join(os.Getenv("ProgramFiles(x86)"), "Windows Kits", "10", "bin")
+)
+
+// webView2Client is the EdgeUpdate client id of the Evergreen runtime.
+const webView2Client = `{F3017226-FE2A-4295-8BDF-00C3A9A7E4C5}`
+
+// webView2Keys are the registry keys Microsoft documents for detecting a
+// per-machine (32- and 64-bit views) or per-user runtime install.
+var webView2Keys = []string{
+	`HKLM\SOFTWARE\WOW6432Node\Microsoft\EdgeUpdate\Clients\` + webView2Client,
+	`HKLM\SOFTWARE\Microsoft\EdgeUpdate\Clients\` + webView2Client,
+	`HKCU\Software\Microsoft\EdgeUpdate\Clients\` + webView2Client,
+}
+
+// WebView2Version returns the installed WebView2 runtime version, or "".
+func WebView2Version() string {
+	for _, key := range webView2Keys {
+		out, err := output("reg", "query", key, "/v", "pv")
+		if err != nil {
+			continue
+		}
+		if v := regValue(out, "pv"); v != "" && v != "0.0.0.0" {
+			return v
+		}
+	}
+	return ""
+}
+
+// regValue extracts a value from 'reg query' output:
+//
+//	pv    REG_SZ    120.0.2210.91
+func regValue(out, name string) string {
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && strings.EqualFold(fields[0], name) && strings.HasPrefix(fields[1], "REG_") {
+			return fields[2]
+		}
+	}
+	return ""
+}
+
+// FindSDKTool finds a Windows SDK tool such as signtool or makeappx: in
+// PATH (e.g. a Developer Command Prompt) or else in the newest SDK under
+// Windows Kits\10\bin.
+func FindSDKTool(name string) (string, error) {
+	if path, err := lookPath(name); err == nil {
+		return path, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join(kitsBin, "10.*", "x64", name+".exe"))
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%s not found in PATH or %s; install it with: goup-util install %s", name, kitsBin, WindowsSDK)
+	}
+	// Versioned directories sort correctly as long as they share a major
+	slices.Sort(matches)
+	return matches[len(matches)-1], nil
+}
+
+// FindMSIXPacker returns the tool that packs MSIX files and whether it is
+// MakeAppx (otherwise it is the msix toolkit's msix command).
+func FindMSIXPacker() (path string, makeAppx bool, err error) {
+	if path, err := lookPath("msix"); err == nil {
+		return path, false, nil
+	}
+	if path, err := FindSDKTool("makeappx"); err == nil {
+		return path, true, nil
+	}
+	return "", false, fmt.Errorf("no MSIX packer found (msix or makeappx); install one with: goup-util install %s", MSIX)
+}
+
+// wingetIDs are the winget packages for each tool.
+var wingetIDs = map[string]string{
+	WindowsSDK: "Microsoft.WindowsSDK.10.0.22621",
+	MSIX:       "Microsoft.MsixPackagingTool",
+}
+
+// webView2Bootstrapper is Microsoft's Evergreen runtime bootstrapper.
+const webView2Bootstrapper = "https://go.microsoft.com/fwlink/p/?LinkId=2124703"
+
+// Install installs a tool. The WebView2 runtime comes from Microsoft's
+// bootstrapper, so it works without winget; the rest come from winget.
+func Install(name string) error {
+	switch name {
+	case WebView2:
+		return installWebView2()
+	case WindowsSDK, MSIX:
+		if _, err := lookPath("winget"); err != nil {
+			return fmt.Errorf("winget not found; install %s from the Microsoft Store or https://learn.microsoft.com/windows/apps/", wingetIDs[name])
+		}
+		return run("winget", "install", "--id", wingetIDs[name], "-e", "--silent", "--accept-package-agreements", "--accept-source-agreements")
+	default:
+		return fmt.Errorf("unknown Windows tool %q (available: %s)", name, strings.Join(Names, ", "))
+	}
+}
+
+// Installed reports whether a tool is present, with its version or path.
+func Installed(name string) (string, bool) {
+	switch name {
+	case WebView2:
+		v := WebView2Version()
+		return v, v != ""
+	case WindowsSDK:
+		path, err := FindSDKTool("signtool")
+		return path, err == nil
+	case MSIX:
+		path, _, err := FindMSIXPacker()
+		return path, err == nil
+	}
+	return "", false
+}
+
+// WebView2BootstrapperName is the file name Microsoft gives the bootstrapper.
+const WebView2BootstrapperName = "MicrosoftEdgeWebview2Setup.exe"
+
+// DownloadWebView2Bootstrapper saves the Evergreen runtime bootstrapper
+// (about 2 MB; it downloads the runtime itself when run) to dst.
+func DownloadWebView2Bootstrapper(dst string) error {
+	resp, err := http.Get(webView2Bootstrapper)
+	if err != nil {
+		return fmt.Errorf("failed to download WebView2 bootstrapper: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download WebView2 bootstrapper: %s", resp.Status)
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to download WebView2 bootstrapper: %w", err)
+	}
+	return nil
+}
+
+func installWebView2() error {
+	dir, err := os.MkdirTemp("", "webview2-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Println("📥 Downloading the WebView2 runtime bootstrapper...")
+	setup := filepath.Join(dir, WebView2BootstrapperName)
+	if err := DownloadWebView2Bootstrapper(setup); err != nil {
+		return err
+	}
+	return run(setup, "/silent", "/install")
+}
+
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", filepath.Base(name), err)
+	}
+	return nil
+}
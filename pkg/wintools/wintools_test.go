@@ -0,0 +1,86 @@
+package wintools
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func fake(t *testing.T, inPath []string, outputs map[string]string) {
+	t.Helper()
+	oldLook, oldOutput, oldKits := lookPath, output, kitsBin
+	lookPath = func(name string) (string, error) {
+		for _, p := range inPath {
+			if p == name {
+				return `C:\tools\` + name + ".exe", nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	output = func(name string, args ...string) (string, error) {
+		if out, ok := outputs[name+" "+strings.Join(args, " ")]; ok {
+			return out, nil
+		}
+		return "", errors.New("exit status 1")
+	}
+	kitsBin = t.TempDir()
+	t.Cleanup(func() { lookPath, output, kitsBin = oldLook, oldOutput, oldKits })
+}
+
+func TestWebView2Version(t *testing.T) {
+	fake(t, nil, map[string]string{
+		`reg query ` + webView2Keys[0] + ` /v pv`: "\r\n" + webView2Keys[0] + "\r\n    pv    REG_SZ    0.0.0.0\r\n",
+		`reg query ` + webView2Keys[2] + ` /v pv`: "\r\n" + webView2Keys[2] + "\r\n    pv    REG_SZ    120.0.2210.91\r\n",
+	})
+	// A 0.0.0.0 version means the runtime was uninstalled
+	if got := WebView2Version(); got != "120.0.2210.91" {
+		t.Errorf("WebView2Version = %q", got)
+	}
+	fake(t, nil, nil)
+	if got := WebView2Version(); got != "" {
+		t.Errorf("WebView2Version without runtime = %q", got)
+	}
+}
+
+func TestFindSDKTool(t *testing.T) {
+	fake(t, nil, nil)
+	if _, err := FindSDKTool("signtool"); err == nil || !strings.Contains(err.Error(), "goup-util install windows-sdk") {
+		t.Errorf("missing signtool: %v", err)
+	}
+	for _, v := range []string{"10.0.19041.0", "10.0.22621.0"} {
+		dir := filepath.Join(kitsBin, v, "x64")
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "signtool.exe"), nil, 0755)
+	}
+	if got, err := FindSDKTool("signtool"); err != nil || !strings.Contains(got, "10.0.22621.0") {
+		t.Errorf("FindSDKTool = %q, %v; want the newest SDK", got, err)
+	}
+
+	fake(t, []string{"signtool"}, nil)
+	if got, _ := FindSDKTool("signtool"); got != `C:\tools\signtool.exe` {
+		t.Errorf("signtool in PATH = %q", got)
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	fake(t, []string{"gogio", "msix"}, map[string]string{"go version": "go version go1.26.0 windows/amd64\n"})
+	checks := Doctor()
+	status := map[string]bool{}
+	for _, c := range checks {
+		status[c.Name] = c.OK
+	}
+	if !status["go"] || !status["gogio"] || status["git"] || status["WebView2 runtime"] || status["signtool"] || !status["MSIX packer"] {
+		t.Errorf("checks = %+v", checks)
+	}
+	if !Healthy(checks) {
+		t.Error("missing optional tools made the machine unhealthy")
+	}
+
+	fake(t, nil, nil)
+	if Healthy(Doctor()) {
+		t.Error("machine without Go is healthy")
+	}
+}
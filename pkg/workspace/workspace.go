@@ -51,6 +51,11 @@ func FindWorkspace(startPath string) (*Workspace, error) {
 	return &Workspace{Exists: false}, nil
 }
 
+// Load reads the go.work file at filePath
+func Load(filePath string) (*Workspace, error) {
+	return loadWorkspace(filePath)
+}
+
 // loadWorkspace reads and parses a go.work file
 func loadWorkspace(filePath string) (*Workspace, error) {
 	file, err := os.Open(filePath)
@@ -0,0 +1,94 @@
+package xcode
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SDK names as xcrun --sdk takes them.
+const (
+	MacOSX          = "macosx"
+	IPhoneOS        = "iphoneos"
+	IPhoneSimulator = "iphonesimulator"
+)
+
+// Problem is a missing Xcode component and how to get it.
+type Problem struct {
+	Component string
+	Detail    string
+	Fix       string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s\n   Fix: %s", p.Component, p.Detail, p.Fix)
+}
+
+// Preflight checks that a build for sdk can run: a developer directory is
+// selected, it is a full Xcode for iOS, the SDK is installed, and the
+// Xcode license and first-launch components are taken care of. It returns
+// nil when nothing is missing.
+func Preflight(sdk string) []Problem {
+	dir, err := ActiveDeveloperDir()
+	if err != nil {
+		return []Problem{{
+			Component: "Command Line Tools",
+			Detail:    err.Error(),
+			Fix:       "goup-util install xcode-clt",
+		}}
+	}
+
+	var problems []Problem
+	isCLT := filepath.Clean(dir) == CLTDir
+	ios := sdk == IPhoneOS || sdk == IPhoneSimulator
+	if ios && isCLT {
+		fix := "install Xcode from the App Store or developer.apple.com, then: goup-util xcode select <version>"
+		if list, _ := Installed(); len(list) > 0 {
+			fix = "goup-util xcode select " + list[0].Version
+		}
+		return []Problem{{
+			Component: "Xcode",
+			Detail:    "the Command Line Tools are selected (" + dir + "); iOS builds need a full Xcode",
+			Fix:       fix,
+		}}
+	}
+
+	if out, err := output("xcrun", "--sdk", sdk, "--show-sdk-path"); err != nil {
+		fix := "goup-util install xcode-clt"
+		switch {
+		case sdk == IPhoneSimulator:
+			fix = "xcodebuild -downloadPlatform iOS"
+		case ios:
+			fix = "reinstall Xcode, or select another one: goup-util xcode list"
+		}
+		problems = append(problems, Problem{
+			Component: sdk + " SDK",
+			Detail:    fmt.Sprintf("not found in %s (%s)", dir, firstLine(out)),
+			Fix:       fix,
+		})
+	}
+
+	if isCLT {
+		return problems
+	}
+	if out, err := output("xcodebuild", "-license", "check"); err != nil {
+		problems = append(problems, Problem{
+			Component: "Xcode license",
+			Detail:    "not accepted (" + firstLine(out) + ")",
+			Fix:       "sudo xcodebuild -license accept",
+		})
+	}
+	if _, err := output("xcodebuild", "-checkFirstLaunchStatus"); err != nil {
+		problems = append(problems, Problem{
+			Component: "Xcode first launch",
+			Detail:    "additional required components are not installed",
+			Fix:       "sudo xcodebuild -runFirstLaunch",
+		})
+	}
+	return problems
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
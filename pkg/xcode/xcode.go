@@ -0,0 +1,246 @@
+// Package xcode finds installed Xcode versions, switches the active
+// developer directory, installs the Command Line Tools without the GUI
+// prompt, and checks that the components an iOS or macOS build needs are
+// present.
+package xcode
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CLTDir is where the Command Line Tools are installed.
+const CLTDir = "/Library/Developer/CommandLineTools"
+
+// applicationsDir is searched for Xcode*.app; tests point it elsewhere.
+var applicationsDir = "/Applications"
+
+// output runs a command and returns its trimmed combined output; tests
+// replace it.
+var output = func(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Xcode is an installed Xcode.app.
+type Xcode struct {
+	Path    string // e.g. /Applications/Xcode-15.4.app
+	Version string // e.g. 15.4
+	Build   string // e.g. 15F31d
+}
+
+// DeveloperDir is the Contents/Developer directory xcode-select points at.
+func (x Xcode) DeveloperDir() string {
+	return filepath.Join(x.Path, "Contents", "Developer")
+}
+
+// Installed lists the Xcode versions in /Applications, newest first.
+func Installed() ([]Xcode, error) {
+	apps, err := filepath.Glob(filepath.Join(applicationsDir, "Xcode*.app"))
+	if err != nil {
+		return nil, err
+	}
+	var list []Xcode
+	for _, app := range apps {
+		version, build, err := readVersion(app)
+		if err != nil {
+			continue // Not a complete Xcode
+		}
+		list = append(list, Xcode{Path: app, Version: version, Build: build})
+	}
+	slices.SortFunc(list, func(a, b Xcode) int { return compareVersions(b.Version, a.Version) })
+	return list, nil
+}
+
+// readVersion reads the version and build from an Xcode's version.plist.
+func readVersion(app string) (version, build string, err error) {
+	data, err := os.ReadFile(filepath.Join(app, "Contents", "version.plist"))
+	if err != nil {
+		return "", "", err
+	}
+	values := plistStrings(data)
+	version = values["CFBundleShortVersionString"]
+	if version == "" {
+		return "", "", fmt.Errorf("%s: no version in version.plist", app)
+	}
+	return version, values["ProductBuildVersion"], nil
+}
+
+// plistStrings returns the top-level string values of an XML plist.
+func plistStrings(data []byte) map[string]string {
+	values := map[string]string{}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var key, elem string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF || err != nil {
+			return values
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			elem = t.Name.Local
+		case xml.CharData:
+			switch elem {
+			case "key":
+				key = string(t)
+			case "string":
+				values[key] = string(t)
+			}
+		case xml.EndElement:
+			elem = ""
+		}
+	}
+}
+
+// compareVersions compares dotted versions numerically.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(as), len(bs)) {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Find picks the newest installed Xcode matching version, where "15"
+// matches any 15.x and "15.4" matches 15.4 and 15.4.1. A path to an
+// Xcode.app is accepted too.
+func Find(list []Xcode, version string) (*Xcode, error) {
+	for i, x := range list {
+		if x.Path == filepath.Clean(version) {
+			return &list[i], nil
+		}
+	}
+	for i, x := range list {
+		if x.Version == version || strings.HasPrefix(x.Version, version+".") {
+			return &list[i], nil
+		}
+	}
+	var versions []string
+	for _, x := range list {
+		versions = append(versions, x.Version)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no Xcode found in %s; install it from the App Store or developer.apple.com", applicationsDir)
+	}
+	return nil, fmt.Errorf("Xcode %s is not installed (installed: %s)", version, strings.Join(versions, ", "))
+}
+
+// ActiveDeveloperDir returns the developer directory builds use:
+// DEVELOPER_DIR if set, else the one chosen with xcode-select.
+func ActiveDeveloperDir() (string, error) {
+	if dir := os.Getenv("DEVELOPER_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := output("xcode-select", "-p")
+	if err != nil {
+		return "", fmt.Errorf("no developer directory is selected (xcode-select -p failed)")
+	}
+	return dir, nil
+}
+
+// Active returns the selected Xcode, or nil when the Command Line Tools
+// are selected instead.
+func Active(list []Xcode) *Xcode {
+	dir, err := ActiveDeveloperDir()
+	if err != nil {
+		return nil
+	}
+	for i, x := range list {
+		if filepath.Clean(dir) == x.DeveloperDir() || filepath.Clean(dir) == x.Path {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+// Select makes x the active developer directory for the whole machine.
+// xcode-select needs root, so this runs it through sudo.
+func Select(x *Xcode) error {
+	cmd := exec.Command("sudo", "xcode-select", "--switch", x.DeveloperDir())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("xcode-select --switch %s failed: %w", x.DeveloperDir(), err)
+	}
+	return nil
+}
+
+// CLTInstalled reports whether the Command Line Tools are installed.
+func CLTInstalled() bool {
+	_, err := os.Stat(filepath.Join(CLTDir, "usr", "bin", "clang"))
+	return err == nil
+}
+
+// cltTrigger makes softwareupdate list the Command Line Tools, as the
+// xcode-select --install dialog does.
+const cltTrigger = "/tmp/.com.apple.dt.CommandLineTools.installondemand.in-progress"
+
+// InstallCLT installs the newest Command Line Tools with softwareupdate,
+// without the dialog xcode-select --install shows. It needs root, so it
+// runs softwareupdate through sudo.
+func InstallCLT() error {
+	if err := os.WriteFile(cltTrigger, nil, 0644); err != nil {
+		return err
+	}
+	defer os.Remove(cltTrigger)
+
+	fmt.Println("🔎 Looking up Command Line Tools with softwareupdate...")
+	list, err := output("softwareupdate", "--list")
+	if err != nil {
+		return fmt.Errorf("softwareupdate --list failed: %w\n%s", err, list)
+	}
+	label := cltLabel(list)
+	if label == "" {
+		return fmt.Errorf("softwareupdate offers no Command Line Tools")
+	}
+
+	fmt.Printf("📥 Installing %s...\n", label)
+	cmd := exec.Command("sudo", "softwareupdate", "--install", label, "--verbose")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("softwareupdate --install %q failed: %w", label, err)
+	}
+	return nil
+}
+
+// cltLabel picks the newest Command Line Tools label from softwareupdate
+// --list output, e.g. "Command Line Tools for Xcode-15.3".
+func cltLabel(list string) string {
+	var best, bestVersion string
+	for line := range strings.Lines(list) {
+		label, ok := strings.CutPrefix(strings.TrimSpace(line), "* Label: ")
+		if !ok {
+			// Older macOS: "* Command Line Tools (macOS ...) for Xcode-11.3"
+			label, ok = strings.CutPrefix(strings.TrimSpace(line), "* ")
+		}
+		if !ok || !strings.HasPrefix(label, "Command Line Tools") {
+			continue
+		}
+		version := label[strings.LastIndex(label, "-")+1:]
+		if best == "" || compareVersions(version, bestVersion) > 0 {
+			best, bestVersion = label, version
+		}
+	}
+	return best
+}
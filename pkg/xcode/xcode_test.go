@@ -0,0 +1,123 @@
+package xcode
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const versionPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleShortVersionString</key>
+	<string>%s</string>
+	<key>ProductBuildVersion</key>
+	<string>15F31d</string>
+</dict>
+</plist>`
+
+func fakeApplications(t *testing.T, versions map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for app, version := range versions {
+		contents := filepath.Join(dir, app, "Contents")
+		os.MkdirAll(contents, 0755)
+		if version != "" {
+			os.WriteFile(filepath.Join(contents, "version.plist"), []byte(strings.Replace(versionPlist, "%s", version, 1)), 0644)
+		}
+	}
+	old := applicationsDir
+	applicationsDir = dir
+	t.Cleanup(func() { applicationsDir = old })
+}
+
+func TestInstalledAndFind(t *testing.T) {
+	fakeApplications(t, map[string]string{
+		"Xcode.app":        "15.4",
+		"Xcode-15.2.app":   "15.2",
+		"Xcode-16.0.app":   "16.0",
+		"Xcode-broken.app": "",
+	})
+	list, err := Installed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []string
+	for _, x := range list {
+		versions = append(versions, x.Version)
+	}
+	if got := strings.Join(versions, " "); got != "16.0 15.4 15.2" || list[0].Build != "15F31d" {
+		t.Fatalf("Installed = %s (%+v)", got, list)
+	}
+
+	for query, want := range map[string]string{"15": "15.4", "15.2": "15.2", "16": "16.0", list[2].Path: "15.2"} {
+		if x, err := Find(list, query); err != nil || x.Version != want {
+			t.Errorf("Find(%s) = %+v, %v; want %s", query, x, err, want)
+		}
+	}
+	if _, err := Find(list, "14"); err == nil || !strings.Contains(err.Error(), "installed: 16.0, 15.4, 15.2") {
+		t.Errorf("Find(14) = %v", err)
+	}
+}
+
+func TestCLTLabel(t *testing.T) {
+	list := `Software Update Tool
+
+Finding available software
+Software Update found the following new or updated software:
+* Label: Command Line Tools for Xcode-15.3
+	Title: Command Line Tools for Xcode, Version: 15.3, Size: 707501KiB, Recommended: YES,
+* Label: Command Line Tools for Xcode-16.0
+	Title: Command Line Tools for Xcode, Version: 16.0, Size: 800000KiB, Recommended: YES,
+* Label: macOS Sonoma 14.6-23G80
+`
+	if got := cltLabel(list); got != "Command Line Tools for Xcode-16.0" {
+		t.Errorf("cltLabel = %q", got)
+	}
+	if got := cltLabel("No new software available.\n"); got != "" {
+		t.Errorf("cltLabel without tools = %q", got)
+	}
+}
+
+func fakeOutput(t *testing.T, failing ...string) {
+	t.Helper()
+	old := output
+	output = func(name string, args ...string) (string, error) {
+		cmd := name + " " + strings.Join(args, " ")
+		for _, f := range failing {
+			if strings.HasPrefix(cmd, f) {
+				return "error: " + f, errors.New("exit status 1")
+			}
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { output = old })
+}
+
+func TestPreflight(t *testing.T) {
+	fakeApplications(t, map[string]string{"Xcode.app": "15.4"})
+
+	t.Setenv("DEVELOPER_DIR", CLTDir)
+	fakeOutput(t)
+	if p := Preflight(IPhoneOS); len(p) != 1 || p[0].Component != "Xcode" || p[0].Fix != "goup-util xcode select 15.4" {
+		t.Errorf("iOS with Command Line Tools = %v", p)
+	}
+	if p := Preflight(MacOSX); len(p) != 0 {
+		t.Errorf("macOS with Command Line Tools = %v", p)
+	}
+
+	t.Setenv("DEVELOPER_DIR", "/Applications/Xcode.app/Contents/Developer")
+	fakeOutput(t, "xcrun --sdk iphonesimulator", "xcodebuild -license")
+	p := Preflight(IPhoneSimulator)
+	if len(p) != 2 || p[0].Fix != "xcodebuild -downloadPlatform iOS" || p[1].Fix != "sudo xcodebuild -license accept" {
+		t.Errorf("missing simulator SDK and license = %v", p)
+	}
+
+	fakeOutput(t)
+	if p := Preflight(IPhoneOS); p != nil {
+		t.Errorf("complete Xcode = %v", p)
+	}
+}